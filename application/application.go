@@ -3,84 +3,183 @@ package application
 import (
 	"errors"
 	"log"
+	"net/http"
 	"os"
 	"path"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/archive"
+	"github.com/cloudfoundry-incubator/notifications/cron"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/httpclient"
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/redis"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/cloudfoundry-incubator/notifications/util"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/info"
 	"github.com/cloudfoundry-incubator/notifications/web"
-	"github.com/pivotal-cf-experimental/warrant"
 	"github.com/pivotal-golang/lager"
 )
 
-const WorkerCount = 10
-
 type Application struct {
 	env        Environment
 	logger     lager.Logger
 	dbProvider *DBProvider
 	migrator   Migrator
+	fastLane   *postal.FastLane
+	workerPool *postal.WorkerPool
+
+	// httpClients share one pool of connections between the clients of the
+	// UAA and the Cloud Controller in the server and the workers.
+	httpClients httpclient.Shared
 }
 
 func New(env Environment, dbp *DBProvider) Application {
 	databaseMigrator := models.DatabaseMigrator{}
+	templatePackImporter := services.NewTemplatePackImporter(models.NewTemplatesRepo(), models.NewTemplatePartialsRepo())
 
 	l := lager.NewLogger("notifications")
 	l.RegisterSink(lager.NewWriterSink(os.Stdout, lager.DEBUG))
 
+	// The fast lane is shared by the server, which hands deliveries to it,
+	// and the workers that make them, so it only exists where both run.
+	var fastLane *postal.FastLane
+	if env.FastLaneWorkers > 0 && !env.ReadOnly {
+		fastLane = postal.NewFastLane()
+	}
+
+	// Likewise the worker pool, whose size the server reports.
+	var workerPool *postal.WorkerPool
+	if !env.ReadOnly {
+		workerPool = postal.NewWorkerPool(postal.WorkerPoolConfig{
+			Min:           env.WorkerPoolMin,
+			Max:           env.WorkerPoolMax,
+			JobsPerWorker: env.WorkerPoolJobsPerWorker,
+			MaxLatency:    time.Duration(env.WorkerPoolMaxLatency) * time.Second,
+			SendRate:      env.SendRate,
+			Clock:         util.NewClock(),
+		})
+	}
+
 	return Application{
 		env:        env,
 		logger:     l,
 		dbProvider: dbp,
-		migrator:   NewMigrator(dbp, databaseMigrator, env.VCAPApplication.InstanceIndex == 0, env.ModelMigrationsPath, env.GobbleMigrationsPath, path.Join(env.RootPath, "templates", "default.json")),
+		migrator:   NewMigrator(dbp, databaseMigrator, env.VCAPApplication.InstanceIndex == 0, env.ModelMigrationsPath, env.GobbleMigrationsPath, path.Join(env.RootPath, "templates", "default.json"), env.TemplatePackPath, templatePackImporter),
+		fastLane:   fastLane,
+		workerPool: workerPool,
+
+		httpClients: httpclient.NewShared(httpclient.Config{
+			SkipVerifySSL:       !env.VerifySSL,
+			Timeout:             time.Duration(env.ExternalHTTPTimeout) * time.Second,
+			MaxIdleConnsPerHost: env.ExternalHTTPMaxIdleConns,
+			Retries:             env.ExternalHTTPRetries,
+		}),
 	}
 }
 
 func (a Application) mailClient() *mail.Client {
 	return mail.NewClient(mail.Config{
-		User:              a.env.SMTPUser,
-		Pass:              a.env.SMTPPass,
-		Host:              a.env.SMTPHost,
-		Port:              a.env.SMTPPort,
-		Secret:            a.env.SMTPCRAMMD5Secret,
-		TestMode:          a.env.TestMode,
-		SkipVerifySSL:     !a.env.VerifySSL,
-		DisableTLS:        !a.env.SMTPTLS,
-		LoggingEnabled:    a.env.SMTPLoggingEnabled,
-		SMTPAuthMechanism: a.env.SMTPAuthMechanism,
+		User:               a.env.SMTPUser,
+		Pass:               a.env.SMTPPass,
+		Host:               a.env.SMTPHost,
+		Port:               a.env.SMTPPort,
+		Secret:             a.env.SMTPCRAMMD5Secret,
+		TestMode:           a.env.TestMode,
+		SkipVerifySSL:      !a.env.VerifySSL,
+		DisableTLS:         !a.env.SMTPTLS,
+		LoggingEnabled:     a.env.SMTPLoggingEnabled,
+		SMTPAuthMechanism:  a.env.SMTPAuthMechanism,
+		MXLookup:           a.env.SMTPMXLookup,
+		DomainPolicies:     a.env.SMTPMXDomainPolicies,
+		HelloHostname:      a.env.SMTPHeloHostname,
+		LocalAddress:       a.env.SMTPLocalAddress,
+		ClientCertificates: a.env.SMTPClientCertificates,
+		RequireTLS:         a.env.SMTPRequireTLS,
+		MinTLSVersion:      a.env.SMTPMinTLSVersion,
+		RootCAs:            a.env.SMTPRootCAs,
+		PinnedPublicKeys:   a.env.SMTPPinnedPublicKeys,
+		DKIM:               a.env.DKIMSigner,
+		DSNNotify:          a.env.SMTPDSNNotify,
 	})
 }
 
+func (a Application) mailSender() mail.Sender {
+	switch a.env.MailTransport {
+	case mail.TransportSendGrid:
+		return mail.NewSendGridClient(mail.SendGridConfig{
+			URL:      a.env.SendGridURL,
+			APIKey:   a.env.SendGridAPIKey,
+			TestMode: a.env.TestMode,
+		}, &http.Client{Timeout: 30 * time.Second})
+	case mail.TransportSES:
+		return mail.NewSESClient(mail.SESConfig{
+			Region:           a.env.SESRegion,
+			AccessKeyID:      a.env.SESAccessKeyID,
+			SecretAccessKey:  a.env.SESSecretAccessKey,
+			Endpoint:         a.env.SESEndpoint,
+			ConfigurationSet: a.env.SESConfigurationSet,
+			TestMode:         a.env.TestMode,
+		}, &http.Client{Timeout: 30 * time.Second}, util.NewClock())
+	default:
+		return a.mailClient()
+	}
+}
+
 func (a Application) Run() {
+	if a.env.ReadOnly {
+		a.RunReadOnly()
+		return
+	}
 
 	a.VerifySMTPConfiguration()
 
-	uaaClient := warrant.New(warrant.Config{
-		Host:          a.env.UAAHost,
-		SkipVerifySSL: !a.env.VerifySSL,
-	})
-
-	validator := uaa.NewTokenValidator(a.logger, &uaaClient.Tokens)
-
-	if err := validator.LoadSigningKeys(); err != nil {
-		a.logger.Fatal("uaa-get-token-key-errored", err)
-	}
+	validator := a.tokenValidator()
 
 	a.migrator.Migrate()
 
+	scheduler := a.scheduler()
+
+	a.StartTracing()
 	a.StartQueueGauge()
-	a.StartWorkers(validator)
-	a.StartMessageGC()
+	a.StartWorkers(validator, scheduler)
+	a.StartMessageGC(scheduler)
+	scheduler.Run()
+	a.StartHeartbeat()
 	a.StartKeyRefresher(validator)
 	a.StartServer(a.logger, validator)
 }
 
+// RunReadOnly serves the API without migrating the database or starting
+// any of the workers, which are left to the instances that send.
+func (a Application) RunReadOnly() {
+	validator := a.tokenValidator()
+
+	a.logger.Info("read-only")
+
+	a.StartTracing()
+	a.StartHeartbeat()
+	a.StartKeyRefresher(validator)
+	a.StartServer(a.logger, validator)
+}
+
+func (a Application) tokenValidator() *uaa.TokenValidator {
+	keys := uaa.NewSigningKeysFetcher(a.env.UAAHost, a.httpClients.Client("uaa"))
+	validator := uaa.NewTokenValidator(a.logger, keys)
+
+	if err := validator.LoadSigningKeys(); err != nil {
+		a.logger.Fatal("uaa-get-token-key-errored", err)
+	}
+
+	return validator
+}
+
 func (a Application) VerifySMTPConfiguration() {
-	if a.env.TestMode {
+	if a.env.TestMode || a.env.MailTransport != mail.TransportSMTP {
 		return
 	}
 
@@ -117,6 +216,42 @@ func (a Application) StartQueueGauge() {
 	go queueGauge.Run()
 }
 
+func (a Application) StartTracing() {
+	if a.env.OTLPEndpoint == "" {
+		return
+	}
+
+	exporter := tracing.NewOTLPExporter(a.env.OTLPEndpoint, "notifications", &http.Client{
+		Timeout: 10 * time.Second,
+	})
+	tracing.SetExporter(exporter)
+
+	go exporter.Run(time.Tick(5*time.Second), a.logger.Session("tracing"))
+}
+
+func (a Application) StartHeartbeat() {
+	workers := func() int {
+		if a.workerPool == nil {
+			return 0
+		}
+
+		return a.workerPool.Status().Size
+	}
+
+	cron.NewHeartbeat(cron.HeartbeatConfig{
+		Instance: a.env.VCAPApplication.InstanceID,
+		Index:    a.env.VCAPApplication.InstanceIndex,
+		ReadOnly: a.env.ReadOnly,
+		Version:  info.ReadBuild().Version,
+		Workers:  workers,
+
+		Database:   a.dbProvider.Database(),
+		Heartbeats: models.NewInstanceHeartbeatsRepo(),
+		Clock:      util.NewClock(),
+		Logger:     a.logger.Session("heartbeat"),
+	}).Run()
+}
+
 func (a Application) StartKeyRefresher(validator *uaa.TokenValidator) {
 	duration := time.Duration(a.env.UAAKeyRefreshInterval) * time.Millisecond
 
@@ -134,46 +269,185 @@ func (a Application) StartKeyRefresher(validator *uaa.TokenValidator) {
 	}()
 }
 
-func (a Application) StartWorkers(validator *uaa.TokenValidator) {
-	postal.Boot(a.mailClient, a.dbProvider.sqlDB, postal.Config{
-		UAAClientID:          a.env.UAAClientID,
-		UAAClientSecret:      a.env.UAAClientSecret,
-		UAATokenValidator:    validator,
-		UAAHost:              a.env.UAAHost,
-		VerifySSL:            a.env.VerifySSL,
-		InstanceIndex:        a.env.VCAPApplication.InstanceIndex,
-		WorkerCount:          WorkerCount,
-		RootPath:             a.env.RootPath,
-		EncryptionKey:        a.env.EncryptionKey,
-		DBLoggingEnabled:     a.env.DBLoggingEnabled,
-		Sender:               a.env.Sender,
-		Domain:               a.env.Domain,
-		QueueWaitMaxDuration: a.env.GobbleWaitMaxDuration,
-		CCHost:               a.env.CCHost,
+func (a Application) preferencesCache() *redis.Client {
+	client, err := redis.NewClient(a.env.RedisURL, 2*time.Second)
+	if err != nil {
+		a.logger.Fatal("redis-url-invalid", err)
+	}
+
+	return client
+}
+
+func (a Application) archiver() *archive.Archiver {
+	store := archive.NewS3Store(archive.S3Config{
+		Endpoint:        a.env.ArchiveS3Endpoint,
+		Bucket:          a.env.ArchiveS3Bucket,
+		Region:          a.env.ArchiveS3Region,
+		AccessKeyID:     a.env.ArchiveS3AccessKeyID,
+		SecretAccessKey: a.env.ArchiveS3SecretAccessKey,
+	}, &http.Client{Timeout: 30 * time.Second}, util.NewClock())
+
+	archiver, err := archive.NewArchiver(store, a.env.ArchiveS3PathTemplate, util.NewClock())
+	if err != nil {
+		a.logger.Fatal("archive-path-template-invalid", err)
+	}
+
+	return &archiver
+}
+
+// scheduler runs the periodic maintenance jobs on one instance at a time.
+func (a Application) scheduler() *cron.Scheduler {
+	return cron.NewScheduler(cron.Config{
+		Instance:  a.env.VCAPApplication.InstanceID,
+		Interval:  30 * time.Second,
+		Overrides: a.env.ScheduledJobs,
+
+		Database: a.dbProvider.Database(),
+		Jobs:     models.NewScheduledJobsRepo(),
+		Leases:   models.NewSchedulerLeasesRepo(),
+		Clock:    util.NewClock(),
+		Logger:   a.logger.Session("scheduler"),
 	})
 }
 
-func (a Application) StartMessageGC() {
-	messageLifetime := 24 * time.Hour
+func (a Application) StartWorkers(validator *uaa.TokenValidator, scheduler *cron.Scheduler) {
+	config := postal.Config{
+		UAAClientID:            a.env.UAAClientID,
+		UAAClientSecret:        a.env.UAAClientSecret,
+		UAATokenValidator:      validator,
+		UAAHost:                a.env.UAAHost,
+		VerifySSL:              a.env.VerifySSL,
+		HTTPClients:            a.httpClients,
+		InstanceIndex:          a.env.VCAPApplication.InstanceIndex,
+		InstanceID:             a.env.VCAPApplication.InstanceID,
+		WorkerPool:             a.workerPool,
+		RootPath:               a.env.RootPath,
+		EncryptionKey:          a.env.EncryptionKey,
+		PreviousEncryptionKeys: a.env.PreviousEncryptionKeys,
+		DBLoggingEnabled:       a.env.DBLoggingEnabled,
+		Sender:                 a.env.Sender,
+		Domain:                 a.env.Domain,
+		QueueWaitMaxDuration:   a.env.GobbleWaitMaxDuration,
+		QueueFreshWeight:       a.env.GobbleFreshWeight,
+		QueueRetryWeight:       a.env.GobbleRetryWeight,
+		QueueRedisURL:          a.env.GobbleRedisURL,
+		CCHost:                 a.env.CCHost,
+		WebhookSigningKey:      []byte(a.env.WebhookSigningKey),
+		RecordUserMessages:     a.env.UserMessageRetentionDays > 0,
+		UnsubscribeURL:         a.env.UnsubscribeURL,
+		TrackingURL:            a.env.TrackingURL,
+		HTMLSizeLimit:          a.env.HTMLSizeLimit,
+		HTMLTextFallback:       a.env.HTMLTextFallback,
+		ContentLint:            a.env.ContentLint,
+		ValidateRecipientMX:    a.env.ValidateRecipientMX,
+		Scheduler:              scheduler,
+
+		RecipientDomainRateLimit: a.env.RecipientDomainRateLimit,
+		RecipientDomainLimits:    a.env.RecipientDomainLimits,
+
+		RecipientDailyLimit:    a.env.RecipientDailyLimit,
+		RecipientLimitOverflow: a.env.RecipientLimitOverflow,
+
+		RetryBackoff:      a.env.RetryBackoff,
+		RetryErrorClasses: a.env.RetryErrorClasses,
+
+		QueueSLA:     a.env.QueueSLA,
+		SurgeWorkers: a.env.QueueSLASurgeWorkers,
+	}
+
+	if a.env.ArchiveS3Bucket != "" {
+		config.Archiver = a.archiver()
+	}
+
+	if a.env.RedisURL != "" {
+		config.PreferencesCache = a.preferencesCache()
+	}
+
+	if a.fastLane != nil {
+		config.FastLane = a.fastLane
+		config.FastLaneWorkers = a.env.FastLaneWorkers
+	}
+
+	postal.Boot(a.mailSender, a.dbProvider.sqlDB, config)
+}
+
+func (a Application) StartMessageGC(scheduler *cron.Scheduler) {
+	messageLifetime := time.Duration(a.env.MessageRetentionHours) * time.Hour
 	db := a.dbProvider.Database()
 	messagesRepo := a.dbProvider.MessagesRepo()
 	pollingInterval := 1 * time.Hour
+	batchSize := a.env.RetentionBatchSize
 
 	logger := log.New(os.Stdout, "", 0)
-	messageGC := postal.NewMessageGC(messageLifetime, db, messagesRepo, pollingInterval, logger)
-	messageGC.Run()
+	gcs := []postal.MessageGC{
+		postal.NewMessageGC(messageLifetime, batchSize, db, messagesRepo, pollingInterval, logger),
+		postal.NewMessageGC(messageLifetime, batchSize, db, a.dbProvider.PayloadSamplesRepo(), pollingInterval, logger),
+	}
+
+	if a.env.UserMessageRetentionDays > 0 {
+		userMessageLifetime := time.Duration(a.env.UserMessageRetentionDays) * 24 * time.Hour
+		gcs = append(gcs, postal.NewMessageGC(userMessageLifetime, batchSize, db, a.dbProvider.UserMessagesRepo(), pollingInterval, logger))
+	}
+
+	if a.env.ReceiptRetentionDays > 0 {
+		receiptLifetime := time.Duration(a.env.ReceiptRetentionDays) * 24 * time.Hour
+		gcs = append(gcs, postal.NewMessageGC(receiptLifetime, batchSize, db, a.dbProvider.ReceiptsRepo(), pollingInterval, logger))
+	}
+
+	scheduler.Add(cron.Job{
+		Name:  postal.RetentionJanitorJob,
+		Every: pollingInterval,
+		Run: func() error {
+			var errs []error
+			for _, gc := range gcs {
+				if err := gc.Collect(); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			return errors.Join(errs...)
+		},
+	})
 }
 
 func (a Application) StartServer(logger lager.Logger, validator *uaa.TokenValidator) {
-	web.NewServer().Run(web.Config{
+	config := web.Config{
 		DBLoggingEnabled:     a.env.DBLoggingEnabled,
-		SkipVerifySSL:        !a.env.VerifySSL,
+		HTTPClients:          a.httpClients,
 		Port:                 a.env.Port,
 		Logger:               logger,
+		ReadOnly:             a.env.ReadOnly,
 		CORSOrigin:           a.env.CORSOrigin,
 		SQLDB:                a.dbProvider.sqlDB,
+		SQLReplicas:          a.dbProvider.replicaDBs,
 		Queue:                a.dbProvider.Queue(),
 		QueueWaitMaxDuration: a.env.GobbleWaitMaxDuration,
+		QueueRedisURL:        a.env.GobbleRedisURL,
+
+		SyncUserDeliveryTimeout:      a.env.SyncUserDeliveryTimeout,
+		ClientRateLimit:              a.env.ClientRateLimit,
+		ClientRateLimitBurst:         a.env.ClientRateLimitBurst,
+		CriticalKindApproval:         a.env.CriticalKindApproval,
+		CriticalUnsubscribeGraceDays: a.env.CriticalUnsubscribeGraceDays,
+		IdempotencyWindowHours:       a.env.IdempotencyWindowHours,
+		EnqueueBatchSize:             a.env.EnqueueBatchSize,
+		EnqueueWorkers:               a.env.EnqueueWorkers,
+		SendingAnomalyFactor:         a.env.SendingAnomalyFactor,
+		SendingAnomalyMinRequests:    a.env.SendingAnomalyMinRequests,
+		SendingAnomalyReauthorize:    a.env.SendingAnomalyReauthorize,
+		Sender:                       a.env.Sender,
+		Domain:                       a.env.Domain,
+		EncryptionKey:                a.env.EncryptionKey,
+		PreviousEncryptionKeys:       a.env.PreviousEncryptionKeys,
+		PreferenceChangeRevertURL:    a.env.PreferenceChangeRevertURL,
+		UnsubscribeURL:               a.env.UnsubscribeURL,
+		TrackingURL:                  a.env.TrackingURL,
+		HTMLSanitizerMode:            a.env.HTMLSanitizerMode,
+		MailTransport:                a.env.MailTransport,
+		HTMLSizeLimit:                a.env.HTMLSizeLimit,
+		HTMLAllowedElements:          a.env.HTMLAllowedElements,
+		QueueSLA:                     a.env.QueueSLA,
+		NotifyAuthorizers:            a.notifyAuthorizers(),
 
 		UAATokenValidator: validator,
 		UAAHost:           a.env.UAAHost,
@@ -181,7 +455,21 @@ func (a Application) StartServer(logger lager.Logger, validator *uaa.TokenValida
 		UAAClientSecret:   a.env.UAAClientSecret,
 		DefaultUAAScopes:  a.env.DefaultUAAScopes,
 		CCHost:            a.env.CCHost,
-	})
+	}
+
+	if a.env.RedisURL != "" {
+		config.PreferencesCache = a.preferencesCache()
+	}
+
+	if a.fastLane != nil {
+		config.FastLane = a.fastLane
+	}
+
+	if a.workerPool != nil {
+		config.WorkerPool = a.workerPool
+	}
+
+	web.NewServer().Run(config)
 }
 
 // This is a hack to get the logs output to the loggregator before the process exits
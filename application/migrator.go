@@ -5,7 +5,9 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 )
 
 type persistenceProvider interface {
@@ -18,6 +20,10 @@ type dbMigrator interface {
 	Seed(db models.DatabaseInterface, defaultTemplatePath string)
 }
 
+type templatePackImporter interface {
+	Import(conn models.ConnectionInterface, pack templatepack.Pack, overwrite, dryRun bool) (services.TemplatePackReport, error)
+}
+
 type Migrator struct {
 	provider             persistenceProvider
 	dbMigrator           dbMigrator
@@ -25,9 +31,11 @@ type Migrator struct {
 	gobbleMigrationsPath string
 	migrationsPath       string
 	defaultTemplatePath  string
+	templatePackPath     string
+	templatePacks        templatePackImporter
 }
 
-func NewMigrator(provider persistenceProvider, dbMigrator dbMigrator, shouldMigrate bool, migrationsPath, gobbleMigrationsPath, defaultTemplatePath string) Migrator {
+func NewMigrator(provider persistenceProvider, dbMigrator dbMigrator, shouldMigrate bool, migrationsPath, gobbleMigrationsPath, defaultTemplatePath, templatePackPath string, templatePacks templatePackImporter) Migrator {
 	return Migrator{
 		provider:             provider,
 		dbMigrator:           dbMigrator,
@@ -35,6 +43,8 @@ func NewMigrator(provider persistenceProvider, dbMigrator dbMigrator, shouldMigr
 		gobbleMigrationsPath: gobbleMigrationsPath,
 		migrationsPath:       migrationsPath,
 		defaultTemplatePath:  defaultTemplatePath,
+		templatePackPath:     templatePackPath,
+		templatePacks:        templatePacks,
 	}
 }
 
@@ -42,6 +52,31 @@ func (m Migrator) Migrate() {
 	if m.shouldMigrate {
 		m.dbMigrator.Migrate(m.provider.Database().RawConnection(), m.migrationsPath)
 		m.dbMigrator.Seed(m.provider.Database(), m.defaultTemplatePath)
+		if m.templatePackPath != "" {
+			m.provisionTemplatePack()
+		}
 		m.provider.GobbleDatabase().Migrate(m.gobbleMigrationsPath)
 	}
 }
+
+// provisionTemplatePack fails the boot on a broken pack, as Seed does on a
+// broken default template, so that it is noticed with the release.
+func (m Migrator) provisionTemplatePack() {
+	pack, err := templatepack.LoadDir(m.templatePackPath)
+	if err != nil {
+		panic(err)
+	}
+
+	transaction := m.provider.Database().Connection().Transaction()
+	transaction.Begin()
+	_, err = m.templatePacks.Import(transaction, pack, false, false)
+	if err != nil {
+		transaction.Rollback()
+		panic(err)
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		panic(err)
+	}
+}
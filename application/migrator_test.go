@@ -1,6 +1,10 @@
 package application_test
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	"github.com/cloudfoundry-incubator/notifications/application"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 
@@ -16,21 +20,29 @@ var _ = Describe("Migrator", func() {
 			database       *mocks.Database
 			gobbleDatabase *mocks.GobbleDatabase
 			dbMigrator     *mocks.DatabaseMigrator
+			importer       *mocks.TemplatePackImporter
+			connection     *mocks.Connection
+			transaction    *mocks.Transaction
 		)
 
 		BeforeEach(func() {
+			transaction = mocks.NewTransaction()
+			connection = mocks.NewConnection()
+			connection.TransactionCall.Returns.Transaction = transaction
 			database = mocks.NewDatabase()
+			database.ConnectionCall.Returns.Connection = connection
 			gobbleDatabase = &mocks.GobbleDatabase{}
 			provider = mocks.NewPersistenceProvider()
 			provider.DatabaseCall.Returns.Database = database
 			provider.GobbleDatabaseCall.Returns.Database = gobbleDatabase
 
 			dbMigrator = mocks.NewDatabaseMigrator()
+			importer = mocks.NewTemplatePackImporter()
 		})
 
 		Context("when configured to run migrations", func() {
 			BeforeEach(func() {
-				migrator = application.NewMigrator(provider, dbMigrator, true, "/my-migrations/dir", "/my-gobble/dir", "/my-templates/dir")
+				migrator = application.NewMigrator(provider, dbMigrator, true, "/my-migrations/dir", "/my-gobble/dir", "/my-templates/dir", "", importer)
 				migrator.Migrate()
 			})
 
@@ -49,11 +61,50 @@ var _ = Describe("Migrator", func() {
 				Expect(dbMigrator.SeedCall.Receives.Database).To(Equal(database))
 				Expect(dbMigrator.SeedCall.Receives.DefaultTemplatePath).To(Equal("/my-templates/dir"))
 			})
+
+			It("does not import a template pack", func() {
+				Expect(importer.ImportCall.WasCalled).To(BeFalse())
+			})
+		})
+
+		Context("when configured with a template pack", func() {
+			var dir string
+
+			BeforeEach(func() {
+				var err error
+				dir, err = ioutil.TempDir("", "template-pack")
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			AfterEach(func() {
+				os.RemoveAll(dir)
+			})
+
+			It("provisions the pack in a transaction", func() {
+				err := ioutil.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"version": 1, "name": "acme", "templates": [{"id": "t", "name": "T", "html": "<p></p>"}]}`), 0644)
+				Expect(err).NotTo(HaveOccurred())
+
+				migrator = application.NewMigrator(provider, dbMigrator, true, "/my-migrations/dir", "/my-gobble/dir", "/my-templates/dir", dir, importer)
+				migrator.Migrate()
+
+				Expect(importer.ImportCall.Receives.Connection).To(Equal(transaction))
+				Expect(importer.ImportCall.Receives.Pack.Name).To(Equal("acme"))
+				Expect(importer.ImportCall.Receives.Overwrite).To(BeFalse())
+				Expect(importer.ImportCall.Receives.DryRun).To(BeFalse())
+				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			})
+
+			It("panics when the pack is invalid", func() {
+				migrator = application.NewMigrator(provider, dbMigrator, true, "/my-migrations/dir", "/my-gobble/dir", "/my-templates/dir", dir, importer)
+
+				Expect(migrator.Migrate).To(Panic())
+				Expect(importer.ImportCall.WasCalled).To(BeFalse())
+			})
 		})
 
 		Context("when configured to skip migrations", func() {
 			BeforeEach(func() {
-				migrator = application.NewMigrator(provider, dbMigrator, false, "these-dont-matter", "these-dont-matter", "these-dont-matter")
+				migrator = application.NewMigrator(provider, dbMigrator, false, "these-dont-matter", "these-dont-matter", "these-dont-matter", "these-dont-matter", importer)
 				migrator.Migrate()
 			})
 
@@ -20,15 +20,30 @@ import (
 )
 
 type DBProvider struct {
-	sqlDB *sql.DB
-	env   Environment
+	sqlDB      *sql.DB
+	replicaDBs []*sql.DB
+	env        Environment
 }
 
 func NewDBProvider(env Environment) *DBProvider {
-	var err error
-	databaseURL := env.DatabaseURL
 	if env.DatabaseCACertFile != "" {
 		registerTLSConfig(env)
+	}
+
+	var replicaDBs []*sql.DB
+	for _, replicaURL := range env.DatabaseReplicaURLs {
+		replicaDBs = append(replicaDBs, openDatabase(env, replicaURL))
+	}
+
+	return &DBProvider{
+		sqlDB:      openDatabase(env, env.DatabaseURL),
+		replicaDBs: replicaDBs,
+		env:        env,
+	}
+}
+
+func openDatabase(env Environment, databaseURL string) *sql.DB {
+	if env.DatabaseCACertFile != "" {
 		databaseURL += "&tls=custom"
 	}
 
@@ -42,10 +57,7 @@ func NewDBProvider(env Environment) *DBProvider {
 	}
 
 	sqlDB.SetMaxOpenConns(env.DBMaxOpenConns)
-	return &DBProvider{
-		sqlDB: sqlDB,
-		env:   env,
-	}
+	return sqlDB
 }
 
 func (d *DBProvider) GobbleDatabase() gobble.DatabaseInterface {
@@ -53,9 +65,17 @@ func (d *DBProvider) GobbleDatabase() gobble.DatabaseInterface {
 }
 
 func (d *DBProvider) Queue() gobble.QueueInterface {
-	return gobble.NewQueue(d.GobbleDatabase(), util.NewClock(), gobble.Config{
+	queue, err := gobble.NewBackend(d.sqlDB, util.NewClock(), gobble.Config{
 		WaitMaxDuration: time.Duration(d.env.GobbleWaitMaxDuration) * time.Millisecond,
+		FreshWeight:     d.env.GobbleFreshWeight,
+		RetryWeight:     d.env.GobbleRetryWeight,
+		RedisURL:        d.env.GobbleRedisURL,
 	})
+	if err != nil {
+		panic(err)
+	}
+
+	return queue
 }
 
 func (d *DBProvider) Database() db.DatabaseInterface {
@@ -74,6 +94,18 @@ func (d *DBProvider) MessagesRepo() v1models.MessagesRepo {
 	return v1models.NewMessagesRepo(util.NewIDGenerator(rand.Reader).Generate)
 }
 
+func (d *DBProvider) UserMessagesRepo() v1models.UserMessagesRepo {
+	return v1models.NewUserMessagesRepo()
+}
+
+func (d *DBProvider) ReceiptsRepo() v1models.ReceiptsRepo {
+	return v1models.NewReceiptsRepo()
+}
+
+func (d *DBProvider) PayloadSamplesRepo() v1models.PayloadSamplesRepo {
+	return v1models.NewPayloadSamplesRepo()
+}
+
 func registerTLSConfig(env Environment) {
 	ca, err := ioutil.ReadFile(env.DatabaseCACertFile)
 	if err != nil {
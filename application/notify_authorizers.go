@@ -0,0 +1,23 @@
+package application
+
+import "github.com/cloudfoundry-incubator/notifications/v1/web/notify"
+
+var registeredNotifyAuthorizers []notify.Authorizer
+
+// RegisterNotifyAuthorizer compiles a deployment's own policy into the
+// checks made on every notify request. Call it from an init function in a
+// file added to this package, or from main before the server starts;
+// authorizers are consulted in the order they were registered, after the
+// one built from NOTIFY_AUDIENCE_SCOPES.
+func RegisterNotifyAuthorizer(authorizer notify.Authorizer) {
+	registeredNotifyAuthorizers = append(registeredNotifyAuthorizers, authorizer)
+}
+
+func (a Application) notifyAuthorizers() []notify.Authorizer {
+	var authorizers []notify.Authorizer
+	if len(a.env.NotifyAudienceScopes) > 0 {
+		authorizers = append(authorizers, a.env.NotifyAudienceScopes)
+	}
+
+	return append(authorizers, registeredNotifyAuthorizers...)
+}
@@ -0,0 +1,61 @@
+package archive
+
+import (
+	"bytes"
+	"text/template"
+)
+
+const DefaultPathTemplate = "{{.Year}}/{{.Month}}/{{.Day}}/{{.ClientID}}/{{.MessageID}}.eml"
+
+type store interface {
+	Put(key, contentType string, body []byte) error
+}
+
+type pathContext struct {
+	Year      string
+	Month     string
+	Day       string
+	ClientID  string
+	MessageID string
+}
+
+type Archiver struct {
+	store        store
+	pathTemplate *template.Template
+	clock        clock
+}
+
+func NewArchiver(store store, pathTemplate string, clock clock) (Archiver, error) {
+	if pathTemplate == "" {
+		pathTemplate = DefaultPathTemplate
+	}
+
+	tmpl, err := template.New("path").Option("missingkey=error").Parse(pathTemplate)
+	if err != nil {
+		return Archiver{}, err
+	}
+
+	return Archiver{
+		store:        store,
+		pathTemplate: tmpl,
+		clock:        clock,
+	}, nil
+}
+
+func (a Archiver) Archive(clientID, messageID string, mime []byte) error {
+	now := a.clock.Now().UTC()
+
+	key := bytes.NewBuffer([]byte{})
+	err := a.pathTemplate.Execute(key, pathContext{
+		Year:      now.Format("2006"),
+		Month:     now.Format("01"),
+		Day:       now.Format("02"),
+		ClientID:  clientID,
+		MessageID: messageID,
+	})
+	if err != nil {
+		return err
+	}
+
+	return a.store.Put(key.String(), "message/rfc822", mime)
+}
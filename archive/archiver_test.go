@@ -0,0 +1,70 @@
+package archive_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/archive"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Archiver", func() {
+	var (
+		store *mocks.ArchiveStore
+		clock *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		store = mocks.NewArchiveStore()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2015, time.March, 7, 23, 59, 0, 0, time.UTC)
+	})
+
+	It("writes the message under a date, client and message ID path by default", func() {
+		archiver, err := archive.NewArchiver(store, "", clock)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = archiver.Archive("some-client", "some-message-id", []byte("the mime"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(store.PutCall.Receives.Key).To(Equal("2015/03/07/some-client/some-message-id.eml"))
+		Expect(store.PutCall.Receives.ContentType).To(Equal("message/rfc822"))
+		Expect(store.PutCall.Receives.Body).To(Equal([]byte("the mime")))
+	})
+
+	It("uses a custom path template", func() {
+		archiver, err := archive.NewArchiver(store, "mail/{{.ClientID}}/{{.Year}}-{{.Month}}/{{.MessageID}}", clock)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = archiver.Archive("some-client", "some-message-id", []byte("the mime"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(store.PutCall.Receives.Key).To(Equal("mail/some-client/2015-03/some-message-id"))
+	})
+
+	It("returns an error when the path template is malformed", func() {
+		_, err := archive.NewArchiver(store, "{{.ClientID", clock)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns an error when the path template references an unknown field", func() {
+		archiver, err := archive.NewArchiver(store, "{{.Banana}}", clock)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = archiver.Archive("some-client", "some-message-id", []byte("the mime"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("returns the error from the store", func() {
+		store.PutCall.Returns.Error = errors.New("bucket is gone")
+
+		archiver, err := archive.NewArchiver(store, "", clock)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = archiver.Archive("some-client", "some-message-id", []byte("the mime"))
+		Expect(err).To(MatchError(errors.New("bucket is gone")))
+	})
+})
@@ -0,0 +1,13 @@
+package archive_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestArchiveSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "archive")
+}
@@ -0,0 +1,88 @@
+package archive
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/util"
+)
+
+type S3Config struct {
+	Endpoint        string
+	Bucket          string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+// S3Store writes objects to an S3-compatible bucket using path-style URLs
+// and AWS Signature Version 4 request signing.
+type S3Store struct {
+	config     S3Config
+	httpClient *http.Client
+	clock      clock
+}
+
+func NewS3Store(config S3Config, httpClient *http.Client, clock clock) S3Store {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	return S3Store{
+		config:     config,
+		httpClient: httpClient,
+		clock:      clock,
+	}
+}
+
+func (s S3Store) Put(key, contentType string, body []byte) error {
+	endpoint, err := url.Parse(s.config.Endpoint)
+	if err != nil {
+		return err
+	}
+
+	endpoint.Path = "/" + s.config.Bucket + "/" + strings.TrimPrefix(key, "/")
+	endpoint.RawPath = escapePath(endpoint.Path)
+
+	request, err := http.NewRequest("PUT", endpoint.String(), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", contentType)
+	util.AWSSigner{
+		AccessKeyID:     s.config.AccessKeyID,
+		SecretAccessKey: s.config.SecretAccessKey,
+		Region:          s.config.Region,
+		Service:         "s3",
+	}.Sign(request, body, s.clock.Now())
+
+	response, err := s.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("archive: unexpected status %d writing %q", response.StatusCode, key)
+	}
+
+	return nil
+}
+
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = strings.Replace(url.QueryEscape(segment), "+", "%20", -1)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,73 @@
+package archive_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/archive"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("S3Store", func() {
+	var (
+		server  *httptest.Server
+		request *http.Request
+		body    []byte
+		status  int
+		store   archive.S3Store
+		clock   *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			var err error
+			request = req
+			body, err = ioutil.ReadAll(req.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			w.WriteHeader(status)
+		}))
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2015, time.March, 7, 12, 30, 0, 0, time.UTC)
+
+		store = archive.NewS3Store(archive.S3Config{
+			Endpoint:        server.URL,
+			Bucket:          "some-bucket",
+			Region:          "eu-west-1",
+			AccessKeyID:     "some-access-key",
+			SecretAccessKey: "some-secret-key",
+		}, http.DefaultClient, clock)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("puts the object into the bucket with a signed request", func() {
+		err := store.Put("2015/03/07/some client/message.eml", "message/rfc822", []byte("the mime"))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(request.Method).To(Equal("PUT"))
+		Expect(request.URL.EscapedPath()).To(Equal("/some-bucket/2015/03/07/some%20client/message.eml"))
+		Expect(body).To(Equal([]byte("the mime")))
+
+		Expect(request.Header.Get("Content-Type")).To(Equal("message/rfc822"))
+		Expect(request.Header.Get("X-Amz-Date")).To(Equal("20150307T123000Z"))
+		Expect(request.Header.Get("X-Amz-Content-Sha256")).To(Equal("79e361062922c63d671f9dcf0f4ad6a289fcfc7167f0b822f87890196733e6f7"))
+		Expect(request.Header.Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 Credential=some-access-key/20150307/eu-west-1/s3/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature="))
+	})
+
+	It("returns an error when the store responds with a failure status", func() {
+		status = http.StatusForbidden
+
+		err := store.Put("some-key", "message/rfc822", []byte("the mime"))
+		Expect(err).To(MatchError(`archive: unexpected status 403 writing "some-key"`))
+	})
+})
@@ -1,22 +1,115 @@
 package cf
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
-
-	"github.com/pivotal-cf-experimental/rainmaker"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
 )
 
+// errNotFound is returned by get for resources the Cloud Controller does not
+// have, so that callers can say which one was missing.
+var errNotFound = errors.New("resource not found")
+
 type CloudController struct {
-	client rainmaker.Client
+	host   string
+	client *http.Client
 }
 
-func NewCloudController(host string, skipVerifySSL bool) CloudController {
+// NewCloudController builds a client for the Cloud Controller at host. Its
+// requests are made with the given client, which is expected to be shared
+// with the other clients of the Cloud Controller.
+func NewCloudController(host string, client *http.Client) CloudController {
 	return CloudController{
-		client: rainmaker.NewClient(rainmaker.Config{
-			Host:          host,
-			SkipVerifySSL: skipVerifySSL,
-		}),
+		host:   host,
+		client: client,
+	}
+}
+
+type ccResource struct {
+	Metadata struct {
+		GUID string `json:"guid"`
+	} `json:"metadata"`
+	Entity json.RawMessage `json:"entity"`
+}
+
+// get fetches the resource at path, decoding its entity into v.
+func (cc CloudController) get(path, token string, v interface{}) (ccResource, error) {
+	var r ccResource
+
+	err := cc.fetch(path, token, &r)
+	if err != nil {
+		return r, err
+	}
+
+	return r, json.Unmarshal(r.Entity, v)
+}
+
+// listUsers reads every page of a list of users, following the next_url of
+// each page.
+func (cc CloudController) listUsers(path, token string) ([]CloudControllerUser, error) {
+	var ccUsers []CloudControllerUser
+
+	for path != "" {
+		var page struct {
+			NextURL   string       `json:"next_url"`
+			Resources []ccResource `json:"resources"`
+		}
+
+		err := cc.fetch(path, token, &page)
+		if err != nil {
+			return ccUsers, err
+		}
+
+		for _, user := range page.Resources {
+			ccUsers = append(ccUsers, CloudControllerUser{
+				GUID: user.Metadata.GUID,
+			})
+		}
+
+		path = page.NextURL
 	}
+
+	return ccUsers, nil
+}
+
+func (cc CloudController) fetch(path, token string, v interface{}) error {
+	request, err := http.NewRequest("GET", cc.host+path, nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Authorization", "Bearer "+token)
+	request.Header.Set("Accept", "application/json")
+
+	response, err := cc.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, 32<<20))
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case response.StatusCode == http.StatusNotFound:
+		return errNotFound
+	case response.StatusCode != http.StatusOK:
+		return fmt.Errorf("unexpected status %d from %s: %s", response.StatusCode, request.URL.Path, body)
+	}
+
+	return json.Unmarshal(body, v)
+}
+
+func spaceUsersPath(guid string) string {
+	query := url.Values{}
+	query.Set("q", "space_guid:"+guid)
+
+	return "/v2/users?" + query.Encode()
 }
 
 type CloudControllerUser struct {
@@ -29,6 +122,12 @@ type CloudControllerSpace struct {
 	OrganizationGUID string
 }
 
+type CloudControllerApp struct {
+	GUID      string
+	Name      string
+	SpaceGUID string
+}
+
 type CloudControllerOrganization struct {
 	GUID string
 	Name string
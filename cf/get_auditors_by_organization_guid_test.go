@@ -69,7 +69,7 @@ var _ = Describe("GetAuditorsByOrgGuid", func() {
 		})
 
 		CCServer = httptest.NewServer(AuditorsEndpoint)
-		cloudController = cf.NewCloudController(CCServer.URL, false)
+		cloudController = cf.NewCloudController(CCServer.URL, http.DefaultClient)
 	})
 
 	AfterEach(func() {
@@ -69,7 +69,7 @@ var _ = Describe("GetBillingManagersByOrgGuid", func() {
 		})
 
 		CCServer = httptest.NewServer(BillingManagersEndpoint)
-		cloudController = cf.NewCloudController(CCServer.URL, false)
+		cloudController = cf.NewCloudController(CCServer.URL, http.DefaultClient)
 	})
 
 	AfterEach(func() {
@@ -7,21 +7,14 @@ import (
 )
 
 func (cc CloudController) GetManagersByOrgGuid(guid, token string) ([]CloudControllerUser, error) {
-	var ccUsers []CloudControllerUser
 	then := time.Now()
 
-	list, err := cc.client.Organizations.ListManagers(guid, token)
+	ccUsers, err := cc.listUsers("/v2/organizations/"+guid+"/managers", token)
 	if err != nil {
 		return ccUsers, NewFailure(0, err.Error())
 	}
 
 	metrics.GetOrRegisterTimer("notifications.external-requests.cc.managers-by-org-guid", nil).Update(time.Since(then))
 
-	for _, user := range list.Users {
-		ccUsers = append(ccUsers, CloudControllerUser{
-			GUID: user.GUID,
-		})
-	}
-
 	return ccUsers, nil
 }
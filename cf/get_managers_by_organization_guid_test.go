@@ -70,7 +70,7 @@ var _ = Describe("GetManagersByOrgGuid", func() {
 		})
 
 		CCServer = httptest.NewServer(ManagersEndpoint)
-		cloudController = cf.NewCloudController(CCServer.URL, false)
+		cloudController = cf.NewCloudController(CCServer.URL, http.DefaultClient)
 	})
 
 	AfterEach(func() {
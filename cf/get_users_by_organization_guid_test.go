@@ -71,7 +71,7 @@ var _ = Describe("CloudController", func() {
 			})
 
 			CCServer = httptest.NewServer(UsersEndpoint)
-			cloudController = cf.NewCloudController(CCServer.URL, false)
+			cloudController = cf.NewCloudController(CCServer.URL, http.DefaultClient)
 		})
 
 		AfterEach(func() {
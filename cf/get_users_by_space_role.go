@@ -0,0 +1,33 @@
+package cf
+
+import (
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+func (cc CloudController) GetDevelopersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.getUsersBySpaceRole(guid, "developers", token)
+}
+
+func (cc CloudController) GetManagersBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.getUsersBySpaceRole(guid, "managers", token)
+}
+
+func (cc CloudController) GetAuditorsBySpaceGuid(guid, token string) ([]CloudControllerUser, error) {
+	return cc.getUsersBySpaceRole(guid, "auditors", token)
+}
+
+// getUsersBySpaceRole lists every page of /v2/spaces/:guid/:role.
+func (cc CloudController) getUsersBySpaceRole(guid, role, token string) ([]CloudControllerUser, error) {
+	then := time.Now()
+
+	ccUsers, err := cc.listUsers("/v2/spaces/"+guid+"/"+role, token)
+	if err != nil {
+		return ccUsers, NewFailure(0, err.Error())
+	}
+
+	metrics.GetOrRegisterTimer("notifications.external-requests.cc."+role+"-by-space-guid", nil).Update(time.Since(then))
+
+	return ccUsers, nil
+}
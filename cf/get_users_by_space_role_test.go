@@ -0,0 +1,113 @@
+package cf_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetUsersBySpaceRole", func() {
+	var (
+		CCServer        *httptest.Server
+		cloudController cf.CloudController
+		requestedPaths  []string
+	)
+
+	userPage := func(guid, nextURL string) string {
+		next := "null"
+		if nextURL != "" {
+			next = fmt.Sprintf("%q", nextURL)
+		}
+
+		return fmt.Sprintf(`{
+			"total_results": 2,
+			"total_pages": 2,
+			"prev_url": null,
+			"next_url": %s,
+			"resources": [
+				{
+					"metadata": {
+						"guid": %q,
+						"url": "/v2/users/%s",
+						"created_at": "2013-04-30T21:00:49+00:00",
+						"updated_at": null
+					},
+					"entity": {
+						"admin": false,
+						"active": true
+					}
+				}
+			]
+		}`, next, guid, guid)
+	}
+
+	BeforeEach(func() {
+		requestedPaths = []string{}
+
+		CCServer = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+			if token != testUAAToken {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"code":10002,"description":"Authentication error","error_code":"CF-NotAuthenticated"}`))
+				return
+			}
+
+			requestedPaths = append(requestedPaths, req.URL.Path)
+
+			role := strings.Split(req.URL.Path, "/")[4]
+			if req.URL.Query().Get("page") == "2" {
+				w.Write([]byte(userPage(role+"-2", "")))
+				return
+			}
+
+			w.Write([]byte(userPage(role+"-1", req.URL.Path+"?page=2")))
+		}))
+		cloudController = cf.NewCloudController(CCServer.URL, http.DefaultClient)
+	})
+
+	AfterEach(func() {
+		CCServer.Close()
+	})
+
+	It("returns every page of developers for the given space guid", func() {
+		users, err := cloudController.GetDevelopersBySpaceGuid(testSpaceGuid, testUAAToken)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(users).To(Equal([]cf.CloudControllerUser{
+			{GUID: "developers-1"},
+			{GUID: "developers-2"},
+		}))
+		Expect(requestedPaths).To(ConsistOf(
+			"/v2/spaces/"+testSpaceGuid+"/developers",
+			"/v2/spaces/"+testSpaceGuid+"/developers",
+		))
+	})
+
+	It("returns the managers for the given space guid", func() {
+		users, err := cloudController.GetManagersBySpaceGuid(testSpaceGuid, testUAAToken)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(users).To(ContainElement(cf.CloudControllerUser{GUID: "managers-1"}))
+		Expect(requestedPaths[0]).To(Equal("/v2/spaces/" + testSpaceGuid + "/managers"))
+	})
+
+	It("returns the auditors for the given space guid", func() {
+		users, err := cloudController.GetAuditorsBySpaceGuid(testSpaceGuid, testUAAToken)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(users).To(ContainElement(cf.CloudControllerUser{GUID: "auditors-1"}))
+		Expect(requestedPaths[0]).To(Equal("/v2/spaces/" + testSpaceGuid + "/auditors"))
+	})
+
+	It("returns an error when the Cloud Controller returns an error status code", func() {
+		_, err := cloudController.GetAuditorsBySpaceGuid(testSpaceGuid, "bad-token")
+
+		Expect(err).To(BeAssignableToTypeOf(cf.Failure{}))
+	})
+})
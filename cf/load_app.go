@@ -0,0 +1,34 @@
+package cf
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+func (cc CloudController) LoadApp(appGUID, token string) (CloudControllerApp, error) {
+	then := time.Now()
+
+	var app struct {
+		Name      string `json:"name"`
+		SpaceGUID string `json:"space_guid"`
+	}
+
+	resource, err := cc.get("/v2/apps/"+appGUID, token, &app)
+	if err != nil {
+		if err == errNotFound {
+			return CloudControllerApp{}, NotFoundError{fmt.Sprintf("App %q could not be found", appGUID)}
+		} else {
+			return CloudControllerApp{}, NewFailure(0, err.Error())
+		}
+	}
+
+	metrics.GetOrRegisterTimer("notifications.external-requests.cc.app", nil).Update(time.Since(then))
+
+	return CloudControllerApp{
+		GUID:      resource.Metadata.GUID,
+		Name:      app.Name,
+		SpaceGUID: app.SpaceGUID,
+	}, nil
+}
@@ -0,0 +1,74 @@
+package cf_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var AppsEndpoint = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/v2/apps/nacho-app" {
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"code":1234,"description":"This is not allowed.","error_code":"CF-NotAuthorized"}`))
+		return
+	} else if req.URL.Path != "/v2/apps/app-guid" {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":100004,"description":"The app could not be found: ` + strings.TrimPrefix(req.URL.Path, "/v2/apps/") + `","error_code":"CF-AppNotFound"}`))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{
+       "metadata": {
+          "guid": "app-guid",
+          "url": "/v2/apps/app-guid",
+          "created_at": "2014-06-25T18:25:05+00:00",
+          "updated_at": null
+       },
+       "entity": {
+          "name": "duh app",
+          "space_guid": "space-guid",
+          "space_url": "/v2/spaces/space-guid"
+       }
+    }`))
+})
+
+var _ = Describe("LoadApp", func() {
+	var CCServer *httptest.Server
+	var cc cf.CloudController
+
+	BeforeEach(func() {
+		CCServer = httptest.NewServer(AppsEndpoint)
+		cc = cf.NewCloudController(CCServer.URL, http.DefaultClient)
+	})
+
+	AfterEach(func() {
+		CCServer.Close()
+	})
+
+	It("loads the app from cloud controller", func() {
+		app, err := cc.LoadApp("app-guid", "notification-token")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(app.GUID).To(Equal("app-guid"))
+		Expect(app.Name).To(Equal("duh app"))
+		Expect(app.SpaceGUID).To(Equal("space-guid"))
+	})
+
+	It("returns a NotFoundError when the app cannot be found", func() {
+		_, err := cc.LoadApp("banana", "notification-token")
+		Expect(err).To(BeAssignableToTypeOf(cf.NotFoundError{}))
+		Expect(err.Error()).To(Equal(`CloudController Failure: App "banana" could not be found`))
+	})
+
+	It("returns a 0 error code for any other error", func() {
+		_, err := cc.LoadApp("nacho-app", "notification-token")
+		Expect(err).To(BeAssignableToTypeOf(cf.Failure{}))
+		Expect(err.(cf.Failure).Code).To(Equal(0))
+	})
+})
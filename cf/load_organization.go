@@ -4,17 +4,19 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/pivotal-cf-experimental/rainmaker"
 	metrics "github.com/rcrowley/go-metrics"
 )
 
 func (cc CloudController) LoadOrganization(guid, token string) (CloudControllerOrganization, error) {
 	then := time.Now()
 
-	org, err := cc.client.Organizations.Get(guid, token)
+	var org struct {
+		Name string `json:"name"`
+	}
+
+	resource, err := cc.get("/v2/organizations/"+guid, token, &org)
 	if err != nil {
-		_, ok := err.(rainmaker.NotFoundError)
-		if ok {
+		if err == errNotFound {
 			return CloudControllerOrganization{}, NotFoundError{fmt.Sprintf("Organization %q could not be found", guid)}
 		} else {
 			return CloudControllerOrganization{}, NewFailure(0, err.Error())
@@ -24,7 +26,7 @@ func (cc CloudController) LoadOrganization(guid, token string) (CloudControllerO
 	metrics.GetOrRegisterTimer("notifications.external-requests.cc.organization", nil).Update(time.Since(then))
 
 	return CloudControllerOrganization{
-		GUID: org.GUID,
+		GUID: resource.Metadata.GUID,
 		Name: org.Name,
 	}, nil
 }
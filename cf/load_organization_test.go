@@ -54,7 +54,7 @@ var _ = Describe("LoadOrganization", func() {
 
 	BeforeEach(func() {
 		CCServer = httptest.NewServer(OrganizationsEndpoint)
-		cc = cf.NewCloudController(CCServer.URL, false)
+		cc = cf.NewCloudController(CCServer.URL, http.DefaultClient)
 	})
 
 	AfterEach(func() {
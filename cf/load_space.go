@@ -4,17 +4,20 @@ import (
 	"fmt"
 	"time"
 
-	"github.com/pivotal-cf-experimental/rainmaker"
 	"github.com/rcrowley/go-metrics"
 )
 
 func (cc CloudController) LoadSpace(spaceGuid, token string) (CloudControllerSpace, error) {
 	then := time.Now()
 
-	space, err := cc.client.Spaces.Get(spaceGuid, token)
+	var space struct {
+		Name             string `json:"name"`
+		OrganizationGUID string `json:"organization_guid"`
+	}
+
+	resource, err := cc.get("/v2/spaces/"+spaceGuid, token, &space)
 	if err != nil {
-		_, ok := err.(rainmaker.NotFoundError)
-		if ok {
+		if err == errNotFound {
 			return CloudControllerSpace{}, NotFoundError{fmt.Sprintf("Space %q could not be found", spaceGuid)}
 		} else {
 			return CloudControllerSpace{}, NewFailure(0, err.Error())
@@ -24,7 +27,7 @@ func (cc CloudController) LoadSpace(spaceGuid, token string) (CloudControllerSpa
 	metrics.GetOrRegisterTimer("notifications.external-requests.cc.space", nil).Update(time.Since(then))
 
 	return CloudControllerSpace{
-		GUID:             space.GUID,
+		GUID:             resource.Metadata.GUID,
 		Name:             space.Name,
 		OrganizationGUID: space.OrganizationGUID,
 	}, nil
@@ -54,7 +54,7 @@ var _ = Describe("LoadSpace", func() {
 
 	BeforeEach(func() {
 		CCServer = httptest.NewServer(SpacesEndpoint)
-		cc = cf.NewCloudController(CCServer.URL, false)
+		cc = cf.NewCloudController(CCServer.URL, http.DefaultClient)
 	})
 
 	AfterEach(func() {
@@ -0,0 +1,99 @@
+package cron
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+)
+
+// HeartbeatInterval is how often each instance records its heartbeat. An
+// instance that misses a few in a row is reported as stale.
+const (
+	HeartbeatInterval = 30 * time.Second
+	HeartbeatTimeout  = 3 * HeartbeatInterval
+)
+
+// heartbeatRetention is how long an instance that stopped beating is still
+// listed, so that one that crashed shows up as stale before it is forgotten.
+const heartbeatRetention = time.Hour
+
+type heartbeatsRepo interface {
+	Upsert(conn models.ConnectionInterface, heartbeat models.InstanceHeartbeat) error
+	DeleteBefore(conn models.ConnectionInterface, before time.Time) (int, error)
+}
+
+type HeartbeatConfig struct {
+	Instance string
+	Index    int
+	ReadOnly bool
+	Version  string
+
+	// Workers reports how many delivery workers the instance runs.
+	Workers func() int
+
+	Database   db.DatabaseInterface
+	Heartbeats heartbeatsRepo
+	Clock      clock
+	Logger     lager.Logger
+}
+
+// Heartbeat records, on every instance, that the instance is running and
+// what it runs, so that the instances of a deployment can be listed.
+type Heartbeat struct {
+	config    HeartbeatConfig
+	startedAt time.Time
+}
+
+func NewHeartbeat(config HeartbeatConfig) *Heartbeat {
+	return &Heartbeat{
+		config:    config,
+		startedAt: config.Clock.Now(),
+	}
+}
+
+func (h *Heartbeat) Run() {
+	go func() {
+		for {
+			h.Beat()
+			time.Sleep(HeartbeatInterval)
+		}
+	}()
+}
+
+// Beat records the heartbeat of the instance and forgets the instances that
+// stopped beating long ago.
+func (h *Heartbeat) Beat() {
+	conn := h.config.Database.Connection()
+	now := h.config.Clock.Now()
+
+	workers := 0
+	if h.config.Workers != nil {
+		workers = h.config.Workers()
+	}
+
+	err := h.config.Heartbeats.Upsert(conn, models.InstanceHeartbeat{
+		InstanceID:    h.config.Instance,
+		InstanceIndex: h.config.Index,
+		ReadOnly:      h.config.ReadOnly,
+		Workers:       workers,
+		Version:       h.config.Version,
+		StartedAt:     h.startedAt,
+		HeartbeatAt:   now,
+	})
+	if err != nil {
+		h.config.Logger.Error("instance-heartbeat-failed", err)
+		return
+	}
+
+	count, err := h.config.Heartbeats.DeleteBefore(conn, now.Add(-heartbeatRetention))
+	if err != nil {
+		h.config.Logger.Error("instance-heartbeats-cleanup-failed", err)
+		return
+	}
+
+	if count > 0 {
+		h.config.Logger.Info("instance-heartbeats-forgotten", lager.Data{"count": count})
+	}
+}
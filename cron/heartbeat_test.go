@@ -0,0 +1,92 @@
+package cron_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cron"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Heartbeat", func() {
+	var (
+		heartbeat  *cron.Heartbeat
+		heartbeats *mocks.InstanceHeartbeatsRepo
+		clock      *mocks.Clock
+		connection *mocks.Connection
+		buffer     *bytes.Buffer
+		startedAt  time.Time
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		startedAt = time.Date(2026, 10, 17, 11, 0, 0, 0, time.UTC)
+		now = startedAt.Add(time.Hour)
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		heartbeats = mocks.NewInstanceHeartbeatsRepo()
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = startedAt
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		heartbeat = cron.NewHeartbeat(cron.HeartbeatConfig{
+			Instance: "instance-1",
+			Index:    1,
+			Version:  "v1.2.3",
+			Workers:  func() int { return 6 },
+
+			Database:   database,
+			Heartbeats: heartbeats,
+			Clock:      clock,
+			Logger:     logger,
+		})
+
+		clock.NowCall.Returns.Time = now
+	})
+
+	It("records what the instance runs and since when", func() {
+		heartbeat.Beat()
+
+		Expect(heartbeats.UpsertCall.Receives.Connection).To(Equal(connection))
+		Expect(heartbeats.UpsertCall.Receives.Heartbeat).To(Equal(models.InstanceHeartbeat{
+			InstanceID:    "instance-1",
+			InstanceIndex: 1,
+			Workers:       6,
+			Version:       "v1.2.3",
+			StartedAt:     startedAt,
+			HeartbeatAt:   now,
+		}))
+	})
+
+	It("forgets the instances that stopped beating an hour ago", func() {
+		heartbeats.DeleteBeforeCall.Returns.Count = 2
+
+		heartbeat.Beat()
+
+		Expect(heartbeats.DeleteBeforeCall.Receives.Connection).To(Equal(connection))
+		Expect(heartbeats.DeleteBeforeCall.Receives.Before).To(Equal(now.Add(-time.Hour)))
+		Expect(buffer).To(ContainSubstring("instance-heartbeats-forgotten"))
+	})
+
+	It("logs a heartbeat that could not be recorded", func() {
+		heartbeats.UpsertCall.Returns.Error = errors.New("database is down")
+
+		heartbeat.Beat()
+
+		Expect(buffer).To(ContainSubstring("instance-heartbeat-failed"))
+		Expect(heartbeats.DeleteBeforeCall.WasCalled).To(BeFalse())
+	})
+})
@@ -0,0 +1,219 @@
+package cron
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+// LeaseName is the lease that the instance running the jobs holds.
+const LeaseName = "scheduler"
+
+type jobsRepo interface {
+	Upsert(conn models.ConnectionInterface, job models.ScheduledJob) error
+	Start(conn models.ConnectionInterface, name, instance string, startedAt time.Time) error
+	Finish(conn models.ConnectionInterface, name string, finishedAt time.Time, lastError string) error
+	FindAll(conn models.ConnectionInterface) ([]models.ScheduledJob, error)
+}
+
+type leasesRepo interface {
+	Acquire(conn models.ConnectionInterface, name, holder string, now time.Time, ttl time.Duration) (bool, error)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type Job struct {
+	Name  string
+	Every time.Duration
+	Run   func() error
+}
+
+// Override changes how often a job runs, or turns it off.
+type Override struct {
+	Disabled bool
+	Every    time.Duration
+}
+
+type Config struct {
+	Instance  string
+	Interval  time.Duration
+	Overrides map[string]Override
+
+	Database db.DatabaseInterface
+	Jobs     jobsRepo
+	Leases   leasesRepo
+	Clock    clock
+	Logger   lager.Logger
+}
+
+type entry struct {
+	job     Job
+	enabled bool
+}
+
+// Scheduler runs periodic jobs on whichever instance holds the scheduler
+// lease. Every instance checks the lease each interval; the lease outlives
+// a few missed checks, so a crashed leader is replaced shortly after. When
+// a job is due is decided from its last recorded start, so a new leader
+// picks up the schedule where the old one left it.
+type Scheduler struct {
+	instance  string
+	interval  time.Duration
+	overrides map[string]Override
+
+	database db.DatabaseInterface
+	jobs     jobsRepo
+	leases   leasesRepo
+	clock    clock
+	logger   lager.Logger
+
+	entries []entry
+	leading bool
+}
+
+func NewScheduler(config Config) *Scheduler {
+	return &Scheduler{
+		instance:  config.Instance,
+		interval:  config.Interval,
+		overrides: config.Overrides,
+
+		database: config.Database,
+		jobs:     config.Jobs,
+		leases:   config.Leases,
+		clock:    config.Clock,
+		logger:   config.Logger,
+	}
+}
+
+// Add registers a job. It must be called before Run.
+func (s *Scheduler) Add(job Job) {
+	e := entry{job: job, enabled: true}
+
+	if override, ok := s.overrides[job.Name]; ok {
+		e.enabled = !override.Disabled
+		if override.Every > 0 {
+			e.job.Every = override.Every
+		}
+	}
+
+	s.entries = append(s.entries, e)
+}
+
+func (s *Scheduler) Run() {
+	go func() {
+		for {
+			s.Tick()
+			time.Sleep(s.interval)
+		}
+	}()
+}
+
+func (s *Scheduler) Tick() {
+	conn := s.database.Connection()
+
+	if !s.lead(conn) {
+		return
+	}
+
+	records, err := s.jobs.FindAll(conn)
+	if err != nil {
+		s.logger.Error("scheduled-jobs-lookup-failed", err)
+		return
+	}
+
+	lastStarts := map[string]time.Time{}
+	for _, record := range records {
+		if record.LastStartedAt.Valid {
+			lastStarts[record.Name] = record.LastStartedAt.Time
+		}
+	}
+
+	ran := false
+	for _, e := range s.entries {
+		if !e.enabled {
+			continue
+		}
+
+		if last, ok := lastStarts[e.job.Name]; ok && s.clock.Now().Sub(last) < e.job.Every {
+			continue
+		}
+
+		// A long job can outlast the lease, so renew it before the next one.
+		if ran && !s.lead(conn) {
+			return
+		}
+
+		s.run(conn, e.job)
+		ran = true
+	}
+}
+
+func (s *Scheduler) lead(conn models.ConnectionInterface) bool {
+	leading, err := s.leases.Acquire(conn, LeaseName, s.instance, s.clock.Now(), 3*s.interval)
+	if err != nil {
+		s.logger.Error("scheduler-lease-failed", err)
+		leading = false
+	}
+
+	if leading && !s.leading {
+		s.logger.Info("scheduler-leading", lager.Data{"instance": s.instance})
+		s.saveSchedules(conn)
+	}
+	s.leading = leading
+
+	return leading
+}
+
+func (s *Scheduler) saveSchedules(conn models.ConnectionInterface) {
+	for _, e := range s.entries {
+		err := s.jobs.Upsert(conn, models.ScheduledJob{
+			Name:            e.job.Name,
+			Enabled:         e.enabled,
+			IntervalSeconds: int(e.job.Every / time.Second),
+		})
+		if err != nil {
+			s.logger.Error("scheduled-job-save-failed", err, lager.Data{"job": e.job.Name})
+		}
+	}
+}
+
+func (s *Scheduler) run(conn models.ConnectionInterface, job Job) {
+	logger := s.logger.Session("job", lager.Data{"job": job.Name})
+
+	err := s.jobs.Start(conn, job.Name, s.instance, s.clock.Now())
+	if err != nil {
+		logger.Error("scheduled-job-start-failed", err)
+		return
+	}
+
+	lastError := ""
+	err = runJob(job)
+	if err != nil {
+		lastError = err.Error()
+		metrics.GetOrRegisterCounter("notifications.scheduler.failed", nil).Inc(1)
+		logger.Error("scheduled-job-failed", err)
+	} else {
+		logger.Info("scheduled-job-finished")
+	}
+
+	err = s.jobs.Finish(conn, job.Name, s.clock.Now(), lastError)
+	if err != nil {
+		logger.Error("scheduled-job-finish-failed", err)
+	}
+}
+
+func runJob(job Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return job.Run()
+}
@@ -0,0 +1,170 @@
+package cron_test
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cron"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Scheduler", func() {
+	var (
+		scheduler  *cron.Scheduler
+		jobs       *mocks.ScheduledJobsRepo
+		leases     *mocks.SchedulerLeasesRepo
+		clock      *mocks.Clock
+		connection *mocks.Connection
+		buffer     *bytes.Buffer
+		now        time.Time
+		runs       []string
+		overrides  map[string]cron.Override
+	)
+
+	job := func(name string, every time.Duration, err error) cron.Job {
+		return cron.Job{
+			Name:  name,
+			Every: every,
+			Run: func() error {
+				runs = append(runs, name)
+				return err
+			},
+		}
+	}
+
+	BeforeEach(func() {
+		now = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		runs = []string{}
+		overrides = map[string]cron.Override{}
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		jobs = mocks.NewScheduledJobsRepo()
+		leases = mocks.NewSchedulerLeasesRepo()
+		leases.AcquireCall.Returns.Acquired = true
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		scheduler = cron.NewScheduler(cron.Config{
+			Instance:  "instance-1",
+			Interval:  30 * time.Second,
+			Overrides: overrides,
+
+			Database: database,
+			Jobs:     jobs,
+			Leases:   leases,
+			Clock:    clock,
+			Logger:   logger,
+		})
+	})
+
+	It("runs the jobs that are due and records each run", func() {
+		scheduler.Add(job("retention_janitor", time.Hour, nil))
+		scheduler.Add(job("digest_dispatcher", time.Minute, errors.New("smtp is down")))
+
+		jobs.FindAllCall.Returns.Jobs = []models.ScheduledJob{
+			{Name: "retention_janitor", LastStartedAt: sql.NullTime{Time: now.Add(-10 * time.Minute), Valid: true}},
+			{Name: "digest_dispatcher", LastStartedAt: sql.NullTime{Time: now.Add(-2 * time.Minute), Valid: true}},
+		}
+
+		scheduler.Tick()
+
+		Expect(leases.AcquireCall.Receives.Connection).To(Equal(connection))
+		Expect(leases.AcquireCall.Receives.Name).To(Equal("scheduler"))
+		Expect(leases.AcquireCall.Receives.Holder).To(Equal("instance-1"))
+		Expect(leases.AcquireCall.Receives.Now).To(Equal(now))
+		Expect(leases.AcquireCall.Receives.TTL).To(Equal(90 * time.Second))
+
+		Expect(runs).To(Equal([]string{"digest_dispatcher"}))
+		Expect(jobs.StartCall.Receives.Names).To(Equal([]string{"digest_dispatcher"}))
+		Expect(jobs.StartCall.Receives.Instance).To(Equal("instance-1"))
+		Expect(jobs.StartCall.Receives.StartedAt).To(Equal(now))
+		Expect(jobs.FinishCall.Receives.Names).To(Equal([]string{"digest_dispatcher"}))
+		Expect(jobs.FinishCall.Receives.LastErrors).To(Equal([]string{"smtp is down"}))
+		Expect(buffer).To(ContainSubstring("scheduled-job-failed"))
+	})
+
+	It("runs jobs that have never run", func() {
+		scheduler.Add(job("retention_janitor", time.Hour, nil))
+
+		scheduler.Tick()
+
+		Expect(runs).To(Equal([]string{"retention_janitor"}))
+		Expect(jobs.FinishCall.Receives.LastErrors).To(Equal([]string{""}))
+	})
+
+	It("saves the schedules when it becomes the leader", func() {
+		overrides["digest_dispatcher"] = cron.Override{Disabled: true}
+		overrides["retention_janitor"] = cron.Override{Every: 2 * time.Hour}
+
+		scheduler.Add(job("retention_janitor", time.Hour, nil))
+		scheduler.Add(job("digest_dispatcher", time.Minute, nil))
+
+		scheduler.Tick()
+		scheduler.Tick()
+
+		Expect(jobs.UpsertCall.Receives.Jobs).To(Equal([]models.ScheduledJob{
+			{Name: "retention_janitor", Enabled: true, IntervalSeconds: 7200},
+			{Name: "digest_dispatcher", Enabled: false, IntervalSeconds: 60},
+		}))
+		Expect(runs).To(Equal([]string{"retention_janitor", "retention_janitor"}))
+	})
+
+	It("does nothing while another instance holds the lease", func() {
+		leases.AcquireCall.Returns.Acquired = false
+		scheduler.Add(job("retention_janitor", time.Hour, nil))
+
+		scheduler.Tick()
+
+		Expect(runs).To(BeEmpty())
+		Expect(jobs.UpsertCall.Receives.Jobs).To(BeEmpty())
+	})
+
+	It("does nothing when the lease cannot be checked", func() {
+		leases.AcquireCall.Returns.Error = errors.New("database is down")
+		scheduler.Add(job("retention_janitor", time.Hour, nil))
+
+		scheduler.Tick()
+
+		Expect(runs).To(BeEmpty())
+		Expect(buffer).To(ContainSubstring("scheduler-lease-failed"))
+	})
+
+	It("renews the lease between jobs", func() {
+		scheduler.Add(job("retention_janitor", time.Hour, nil))
+		scheduler.Add(job("digest_dispatcher", time.Minute, nil))
+
+		scheduler.Tick()
+
+		Expect(leases.AcquireCall.CallCount).To(Equal(2))
+		Expect(runs).To(Equal([]string{"retention_janitor", "digest_dispatcher"}))
+	})
+
+	It("records a panicking job as failed", func() {
+		scheduler.Add(cron.Job{
+			Name:  "retention_janitor",
+			Every: time.Hour,
+			Run: func() error {
+				panic("oh no")
+			},
+		})
+
+		scheduler.Tick()
+
+		Expect(jobs.FinishCall.Receives.LastErrors).To(Equal([]string{"panic: oh no"}))
+	})
+})
@@ -1,5 +1,8 @@
 package db
 
+import "database/sql"
+
 type Config struct {
 	DefaultTemplatePath string
+	Replicas            []*sql.DB
 }
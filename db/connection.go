@@ -20,6 +20,8 @@ type ConnectionInterface interface {
 
 type Connection struct {
 	*gorp.DbMap
+
+	replica bool
 }
 
 func (conn *Connection) Transaction() TransactionInterface {
@@ -29,3 +31,9 @@ func (conn *Connection) Transaction() TransactionInterface {
 func (conn *Connection) GetDbMap() *gorp.DbMap {
 	return conn.DbMap
 }
+
+// Replica reports whether the connection reads from a replica, whose rows
+// may lag behind the primary.
+func (conn *Connection) Replica() bool {
+	return conn.replica
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"database/sql"
+	"sync/atomic"
 
 	"gopkg.in/gorp.v1"
 
@@ -15,6 +16,7 @@ type DB struct {
 
 type DatabaseInterface interface {
 	Connection() ConnectionInterface
+	ReadConnection() ConnectionInterface
 	TraceOn(string, gorp.GorpLogger)
 	RawConnection() *sql.DB
 }
@@ -42,6 +44,27 @@ func (database *DB) Connection() ConnectionInterface {
 	return database.connection
 }
 
+// replicaCursor is shared by every DB so that the round robin spreads reads
+// across the replicas even though a DB is allocated per request.
+var replicaCursor uint32
+
+// ReadConnection returns a connection to one of the configured replicas,
+// taking them in turn, or the primary connection when there are none. Rows
+// written through Connection may not be visible on it yet, so it is only
+// for reads that can tolerate replication lag.
+func (database *DB) ReadConnection() ConnectionInterface {
+	if len(database.config.Replicas) == 0 {
+		return database.connection
+	}
+
+	index := atomic.AddUint32(&replicaCursor, 1) % uint32(len(database.config.Replicas))
+
+	dbMap := *database.connection.DbMap
+	dbMap.Db = database.config.Replicas[index]
+
+	return &Connection{DbMap: &dbMap, replica: true}
+}
+
 type TableMapInterface interface {
 	AddTableWithName(i interface{}, name string) *gorp.TableMap
 }
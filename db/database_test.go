@@ -1,6 +1,8 @@
 package db_test
 
 import (
+	"database/sql"
+
 	"github.com/cloudfoundry-incubator/notifications/db"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -20,4 +22,39 @@ var _ = Describe("Database", func() {
 			Expect(connection).To(BeAssignableToTypeOf(&db.Connection{}))
 		})
 	})
+	Describe("ReadConnection", func() {
+		It("returns the primary connection when there are no replicas", func() {
+			Expect(database.ReadConnection()).To(BeIdenticalTo(database.Connection()))
+		})
+
+		Context("when replicas are configured", func() {
+			var replicas []*sql.DB
+
+			BeforeEach(func() {
+				replicas = nil
+				for i := 0; i < 2; i++ {
+					replica, err := sql.Open("mysql", "user:pass@tcp(replica)/db")
+					Expect(err).NotTo(HaveOccurred())
+					replicas = append(replicas, replica)
+				}
+
+				database = db.NewDatabase(sqlDB, db.Config{Replicas: replicas})
+			})
+
+			It("takes the replicas in turn", func() {
+				first := database.ReadConnection().GetDbMap().Db
+				second := database.ReadConnection().GetDbMap().Db
+
+				Expect(replicas).To(ContainElement(first))
+				Expect(replicas).To(ContainElement(second))
+				Expect(first).NotTo(BeIdenticalTo(second))
+				Expect(database.Connection().GetDbMap().Db).To(BeIdenticalTo(sqlDB))
+			})
+
+			It("marks the replica connections", func() {
+				Expect(database.ReadConnection().(*db.Connection).Replica()).To(BeTrue())
+				Expect(database.Connection().(*db.Connection).Replica()).To(BeFalse())
+			})
+		})
+	})
 })
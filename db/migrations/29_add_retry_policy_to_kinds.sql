@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `kinds` ADD `retry_max_attempts` int(11) NOT NULL DEFAULT 0;
+ALTER TABLE `kinds` ADD `retry_interval` int(11) NOT NULL DEFAULT 0;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `kinds` DROP COLUMN `retry_max_attempts`;
+ALTER TABLE `kinds` DROP COLUMN `retry_interval`;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `organization_policies` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `organization_guid` varchar(255) NOT NULL,
+      `audit_critical_sends` tinyint(1) NOT NULL DEFAULT 0,
+      `created_at` datetime DEFAULT NULL,
+      `updated_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `organization_guid` (`organization_guid`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE organization_policies;
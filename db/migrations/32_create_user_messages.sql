@@ -0,0 +1,21 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `user_messages` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `message_id` varchar(255) NOT NULL,
+      `user_guid` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL,
+      `kind_id` varchar(255) NOT NULL DEFAULT '',
+      `subject` text,
+      `source_description` varchar(255) NOT NULL DEFAULT '',
+      `kind_description` varchar(255) NOT NULL DEFAULT '',
+      `request_received` datetime DEFAULT NULL,
+      `delivered_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `message_id` (`message_id`),
+      KEY `user_guid_delivered_at` (`user_guid`, `delivered_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE user_messages;
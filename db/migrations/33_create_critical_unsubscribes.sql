@@ -0,0 +1,31 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `critical_unsubscribes` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `user_id` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL,
+      `kind_id` varchar(255) NOT NULL,
+      `created_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `user_id` (`user_id`,`client_id`,`kind_id`),
+      KEY `client_id` (`client_id`,`kind_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- Unsubscribes already recorded against kinds that are critical today are
+-- parked until those kinds are downgraded.
+INSERT IGNORE INTO `critical_unsubscribes` (`user_id`, `client_id`, `kind_id`, `created_at`)
+      SELECT `unsubscribes`.`user_id`, `unsubscribes`.`client_id`, `unsubscribes`.`kind_id`, `unsubscribes`.`created_at`
+      FROM `unsubscribes`
+      JOIN `kinds` ON `kinds`.`id` = `unsubscribes`.`kind_id` AND `kinds`.`client_id` = `unsubscribes`.`client_id`
+      WHERE `kinds`.`critical` = 1;
+
+DELETE `unsubscribes` FROM `unsubscribes`
+      JOIN `kinds` ON `kinds`.`id` = `unsubscribes`.`kind_id` AND `kinds`.`client_id` = `unsubscribes`.`client_id`
+      WHERE `kinds`.`critical` = 1;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+INSERT IGNORE INTO `unsubscribes` (`user_id`, `client_id`, `kind_id`, `created_at`)
+      SELECT `user_id`, `client_id`, `kind_id`, `created_at` FROM `critical_unsubscribes`;
+
+DROP TABLE `critical_unsubscribes`;
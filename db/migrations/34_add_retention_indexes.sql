@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `messages` ADD KEY `updated_at` (`updated_at`);
+ALTER TABLE `receipts` ADD KEY `created_at` (`created_at`);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `messages` DROP KEY `updated_at`;
+ALTER TABLE `receipts` DROP KEY `created_at`;
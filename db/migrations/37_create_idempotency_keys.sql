@@ -0,0 +1,16 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `idempotency_keys` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `client_id` varchar(255) NOT NULL,
+      `idempotency_key` varchar(255) NOT NULL,
+      `request_hash` varchar(64) NOT NULL,
+      `response` longtext,
+      `created_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `client_id` (`client_id`,`idempotency_key`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `idempotency_keys`;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `client_suspensions` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `client_id` varchar(255) NOT NULL,
+      `reason` varchar(1024) NOT NULL DEFAULT '',
+      `created_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `client_id` (`client_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `client_suspensions`;
@@ -0,0 +1,35 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `digest_preferences` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `user_id` varchar(255) NOT NULL,
+      `frequency` varchar(16) NOT NULL,
+      `updated_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `user_id` (`user_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `digest_entries` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `message_id` varchar(255) NOT NULL,
+      `user_guid` varchar(255) NOT NULL,
+      `email` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL,
+      `kind_id` varchar(255) NOT NULL DEFAULT '',
+      `subject` text,
+      `text` longtext,
+      `html` longtext,
+      `source_description` varchar(255) NOT NULL DEFAULT '',
+      `kind_description` varchar(255) NOT NULL DEFAULT '',
+      `request_received` datetime DEFAULT NULL,
+      `due_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `message_id` (`message_id`),
+      KEY `user_guid` (`user_guid`),
+      KEY `due_at` (`due_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `digest_entries`;
+DROP TABLE `digest_preferences`;
@@ -0,0 +1,17 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `messages` ADD COLUMN `client_id` varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE `messages` ADD COLUMN `created_at` datetime DEFAULT NULL;
+UPDATE `messages` SET `created_at` = `updated_at`;
+ALTER TABLE `messages` MODIFY `created_at` datetime NOT NULL;
+ALTER TABLE `messages` ADD KEY `created_at` (`created_at`);
+ALTER TABLE `messages` ADD KEY `client_id_created_at` (`client_id`, `created_at`);
+ALTER TABLE `messages` ADD KEY `status_created_at` (`status`, `created_at`);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `messages` DROP KEY `status_created_at`;
+ALTER TABLE `messages` DROP KEY `client_id_created_at`;
+ALTER TABLE `messages` DROP KEY `created_at`;
+ALTER TABLE `messages` DROP COLUMN `created_at`;
+ALTER TABLE `messages` DROP COLUMN `client_id`;
@@ -0,0 +1,8 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `receipts` ADD COLUMN `updated_at` datetime DEFAULT NULL;
+UPDATE `receipts` SET `updated_at` = `created_at`;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `receipts` DROP COLUMN `updated_at`;
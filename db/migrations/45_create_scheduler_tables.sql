@@ -0,0 +1,24 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `scheduler_leases` (
+      `name` varchar(255) NOT NULL,
+      `holder` varchar(255) NOT NULL,
+      `expires_at` datetime NOT NULL,
+      PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+CREATE TABLE IF NOT EXISTS `scheduled_jobs` (
+      `name` varchar(255) NOT NULL,
+      `enabled` tinyint(1) NOT NULL DEFAULT 1,
+      `interval_seconds` int(11) NOT NULL,
+      `last_instance` varchar(255) NOT NULL DEFAULT '',
+      `last_started_at` datetime DEFAULT NULL,
+      `last_finished_at` datetime DEFAULT NULL,
+      `last_error` text,
+      PRIMARY KEY (`name`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `scheduled_jobs`;
+DROP TABLE `scheduler_leases`;
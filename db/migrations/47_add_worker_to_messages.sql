@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `messages` ADD `worker_id` varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE `messages` ADD `claimed_at` datetime DEFAULT NULL;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `messages` DROP COLUMN `claimed_at`;
+ALTER TABLE `messages` DROP COLUMN `worker_id`;
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `audit_events` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `action` varchar(255) NOT NULL,
+      `client_id` varchar(255) NOT NULL DEFAULT '',
+      `user_id` varchar(255) NOT NULL DEFAULT '',
+      `method` varchar(16) NOT NULL,
+      `path` varchar(1024) NOT NULL,
+      `details` text,
+      `vcap_request_id` varchar(255) NOT NULL DEFAULT '',
+      `created_at` datetime NOT NULL,
+      PRIMARY KEY (`primary`),
+      KEY `action` (`action`),
+      KEY `client_id` (`client_id`),
+      KEY `created_at` (`created_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `audit_events`;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `kinds` ADD `slack_webhook_url` varchar(2048) NOT NULL DEFAULT '';
+ALTER TABLE `kinds` ADD `slack_channel` varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE `kinds` ADD `slack_exclusive` bool NOT NULL DEFAULT false;
+ALTER TABLE `templates` ADD `slack` text;
+UPDATE `templates` SET `slack` = "" WHERE `slack` IS NULL;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `kinds` DROP COLUMN `slack_webhook_url`;
+ALTER TABLE `kinds` DROP COLUMN `slack_channel`;
+ALTER TABLE `kinds` DROP COLUMN `slack_exclusive`;
+ALTER TABLE `templates` DROP COLUMN `slack`;
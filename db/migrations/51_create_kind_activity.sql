@@ -0,0 +1,15 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `kind_activity` (
+      `client_id` varchar(255) NOT NULL,
+      `kind_id` varchar(255) NOT NULL,
+      `bucket` datetime NOT NULL,
+      `sent` int(11) NOT NULL DEFAULT 0,
+      `failed` int(11) NOT NULL DEFAULT 0,
+      `unsubscribed` int(11) NOT NULL DEFAULT 0,
+      PRIMARY KEY (`client_id`, `kind_id`, `bucket`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `kind_activity`;
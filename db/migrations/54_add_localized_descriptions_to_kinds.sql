@@ -0,0 +1,8 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `kinds` ADD `localized_descriptions` text;
+UPDATE `kinds` SET `localized_descriptions` = "" WHERE `localized_descriptions` IS NULL;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `kinds` DROP COLUMN `localized_descriptions`;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `messages` ADD COLUMN `recipient` varchar(255) NOT NULL DEFAULT '';
+ALTER TABLE `messages` ADD KEY `recipient_created_at` (`recipient`, `created_at`);
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `messages` DROP KEY `recipient_created_at`;
+ALTER TABLE `messages` DROP COLUMN `recipient`;
@@ -0,0 +1,10 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `clients` ADD COLUMN `simulation_sinks` text;
+UPDATE `clients` SET `simulation_sinks` = "" WHERE `simulation_sinks` IS NULL;
+ALTER TABLE `messages` ADD COLUMN `simulated` bool NOT NULL DEFAULT false;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `messages` DROP COLUMN `simulated`;
+ALTER TABLE `clients` DROP COLUMN `simulation_sinks`;
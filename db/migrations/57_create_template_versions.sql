@@ -0,0 +1,24 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `template_versions` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `template_id` varchar(255) NOT NULL,
+      `version` int(11) NOT NULL,
+      `name` varchar(255) NOT NULL,
+      `subject` text,
+      `text` text,
+      `html` text,
+      `slack` text,
+      `metadata` text,
+      `created_at` datetime NOT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `template_id_version` (`template_id`, `version`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+ALTER TABLE `templates` ADD COLUMN `version` int(11) NOT NULL DEFAULT 1;
+INSERT INTO `template_versions` (`template_id`, `version`, `name`, `subject`, `text`, `html`, `slack`, `metadata`, `created_at`)
+      SELECT `id`, 1, `name`, `subject`, `text`, `html`, `slack`, `metadata`, `updated_at` FROM `templates`;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `templates` DROP COLUMN `version`;
+DROP TABLE `template_versions`;
@@ -0,0 +1,12 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `recipient_daily_counts` (
+      `user_guid` varchar(255) NOT NULL,
+      `day` char(10) NOT NULL,
+      `sent` int(11) NOT NULL DEFAULT 0,
+      PRIMARY KEY (`user_guid`, `day`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `recipient_daily_counts`;
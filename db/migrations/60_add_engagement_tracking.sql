@@ -0,0 +1,15 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `clients` ADD COLUMN `engagement_tracking` bool NOT NULL DEFAULT false;
+ALTER TABLE `messages` ADD COLUMN `opens` int(11) NOT NULL DEFAULT 0;
+ALTER TABLE `messages` ADD COLUMN `clicks` int(11) NOT NULL DEFAULT 0;
+ALTER TABLE `kind_activity` ADD COLUMN `opened` int(11) NOT NULL DEFAULT 0;
+ALTER TABLE `kind_activity` ADD COLUMN `clicked` int(11) NOT NULL DEFAULT 0;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `kind_activity` DROP COLUMN `clicked`;
+ALTER TABLE `kind_activity` DROP COLUMN `opened`;
+ALTER TABLE `messages` DROP COLUMN `clicks`;
+ALTER TABLE `messages` DROP COLUMN `opens`;
+ALTER TABLE `clients` DROP COLUMN `engagement_tracking`;
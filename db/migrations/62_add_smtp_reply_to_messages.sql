@@ -0,0 +1,9 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `messages` ADD COLUMN `smtp_code` int(11) NOT NULL DEFAULT 0;
+ALTER TABLE `messages` ADD COLUMN `smtp_enhanced_status` varchar(16) NOT NULL DEFAULT '';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `messages` DROP COLUMN `smtp_enhanced_status`;
+ALTER TABLE `messages` DROP COLUMN `smtp_code`;
@@ -0,0 +1,7 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `templates` ADD COLUMN `zone_id` varchar(255) NOT NULL DEFAULT 'uaa';
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+ALTER TABLE `templates` DROP COLUMN `zone_id`;
@@ -0,0 +1,21 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+ALTER TABLE `kinds` ADD `severity` varchar(32) NOT NULL DEFAULT 'info';
+ALTER TABLE `messages` ADD `severity` varchar(32) NOT NULL DEFAULT '';
+ALTER TABLE `user_messages` ADD `severity` varchar(32) NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS `severity_unsubscribes` (
+      `primary` int(11) NOT NULL AUTO_INCREMENT,
+      `user_id` varchar(255) NOT NULL,
+      `severity` varchar(32) NOT NULL,
+      `created_at` datetime DEFAULT NULL,
+      PRIMARY KEY (`primary`),
+      UNIQUE KEY `user_id_severity` (`user_id`, `severity`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `severity_unsubscribes`;
+ALTER TABLE `user_messages` DROP COLUMN `severity`;
+ALTER TABLE `messages` DROP COLUMN `severity`;
+ALTER TABLE `kinds` DROP COLUMN `severity`;
@@ -0,0 +1,16 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `instance_heartbeats` (
+      `instance_id` varchar(255) NOT NULL,
+      `instance_index` int(11) NOT NULL DEFAULT 0,
+      `read_only` tinyint(1) NOT NULL DEFAULT 0,
+      `workers` int(11) NOT NULL DEFAULT 0,
+      `version` varchar(255) NOT NULL DEFAULT '',
+      `started_at` datetime NOT NULL,
+      `heartbeat_at` datetime NOT NULL,
+      PRIMARY KEY (`instance_id`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `instance_heartbeats`;
@@ -0,0 +1,14 @@
+-- +migrate Up
+-- SQL in section 'Up' is executed when this migration is applied
+CREATE TABLE IF NOT EXISTS `preference_reverts` (
+      `id` varchar(255) NOT NULL,
+      `user_guid` varchar(255) NOT NULL,
+      `expires_at` datetime NOT NULL,
+      `used_at` datetime NOT NULL,
+      PRIMARY KEY (`id`),
+      KEY `expires_at` (`expires_at`)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8;
+
+-- +migrate Down
+-- SQL section 'Down' is executed when this migration is rolled back
+DROP TABLE `preference_reverts`;
@@ -14,8 +14,9 @@ type TransactionInterface interface {
 }
 
 type Transaction struct {
-	txn  *gorp.Transaction
-	conn *Connection
+	txn         *gorp.Transaction
+	conn        *Connection
+	afterCommit []func()
 }
 
 func NewTransaction(conn *Connection) TransactionInterface {
@@ -39,7 +40,25 @@ func (transaction *Transaction) Transaction() TransactionInterface {
 }
 
 func (transaction *Transaction) Commit() error {
-	return transaction.txn.Commit()
+	err := transaction.txn.Commit()
+	if err != nil {
+		return err
+	}
+
+	callbacks := transaction.afterCommit
+	transaction.afterCommit = nil
+	for _, callback := range callbacks {
+		callback()
+	}
+
+	return nil
+}
+
+// AfterCommit runs callback once the transaction commits, for work that must
+// not see its writes before anybody else can. Nothing is run when the
+// transaction rolls back.
+func (transaction *Transaction) AfterCommit(callback func()) {
+	transaction.afterCommit = append(transaction.afterCommit, callback)
 }
 
 func (transaction *Transaction) Delete(v ...interface{}) (int64, error) {
@@ -55,6 +74,7 @@ func (transaction *Transaction) Insert(v ...interface{}) error {
 }
 
 func (transaction *Transaction) Rollback() error {
+	transaction.afterCommit = nil
 	return transaction.txn.Rollback()
 }
 
@@ -64,4 +64,27 @@ var _ = Describe("Transaction", func() {
 			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
 		})
 	})
+
+	Describe("AfterCommit", func() {
+		It("runs the callbacks once the transaction commits", func() {
+			Expect(transaction.Begin()).To(Succeed())
+
+			var ran bool
+			transaction.(*db.Transaction).AfterCommit(func() { ran = true })
+			Expect(ran).To(BeFalse())
+
+			Expect(transaction.Commit()).To(Succeed())
+			Expect(ran).To(BeTrue())
+		})
+
+		It("does not run the callbacks when the transaction rolls back", func() {
+			Expect(transaction.Begin()).To(Succeed())
+
+			var ran bool
+			transaction.(*db.Transaction).AfterCommit(func() { ran = true })
+
+			Expect(transaction.Rollback()).To(Succeed())
+			Expect(ran).To(BeFalse())
+		})
+	})
 })
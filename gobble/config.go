@@ -4,4 +4,38 @@ import "time"
 
 type Config struct {
 	WaitMaxDuration time.Duration
+
+	// FreshWeight and RetryWeight share reservations between jobs on their
+	// first attempt and jobs being retried: out of every FreshWeight +
+	// RetryWeight reservations, FreshWeight prefer a first attempt and the
+	// rest prefer a retry. Either kind is taken when the other is not
+	// waiting. When either weight is 0, jobs are reserved in the order they
+	// became active.
+	FreshWeight int
+	RetryWeight int
+
+	// RedisURL, when set, keeps the jobs in Redis instead of the jobs
+	// table of the database.
+	RedisURL string
+}
+
+// The kind of job a reservation prefers, when it prefers one.
+const (
+	preferFresh = "fresh"
+	preferRetry = "retry"
+)
+
+// preference says whether the given reservation, counting from zero,
+// prefers a first attempt or a retry. It is empty when the weights are not
+// set and jobs are reserved in the order they became active.
+func (c Config) preference(reservation uint64) string {
+	if c.FreshWeight <= 0 || c.RetryWeight <= 0 {
+		return ""
+	}
+
+	if reservation%uint64(c.FreshWeight+c.RetryWeight) < uint64(c.FreshWeight) {
+		return preferFresh
+	}
+
+	return preferRetry
 }
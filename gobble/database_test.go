@@ -73,5 +73,13 @@ var _ = Describe("Database", func() {
 			Field: "active_at",
 			Type:  "timestamp",
 		}))
+		Expect(columns).To(ContainElement(Column{
+			Field: "priority",
+			Type:  "int",
+		}))
+		Expect(columns).To(ContainElement(Column{
+			Field: "lease_id",
+			Type:  "varchar",
+		}))
 	})
 })
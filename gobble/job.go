@@ -2,17 +2,51 @@ package gobble
 
 import (
 	"encoding/json"
+	"strconv"
 	"time"
 )
 
+// Jobs with a higher priority are reserved before any job with a lower one,
+// regardless of how long the lower priority jobs have been waiting.
+const (
+	PriorityBulk     = -10
+	PriorityNormal   = 0
+	PriorityHigh     = 5
+	PriorityCritical = 10
+)
+
+// Priorities names the priorities for configuration and reporting.
+var Priorities = map[string]int{
+	"bulk":     PriorityBulk,
+	"normal":   PriorityNormal,
+	"high":     PriorityHigh,
+	"critical": PriorityCritical,
+}
+
+func PriorityName(priority int) string {
+	for name, p := range Priorities {
+		if p == priority {
+			return name
+		}
+	}
+
+	return strconv.Itoa(priority)
+}
+
 type Job struct {
 	ID          int       `db:"id"`
 	WorkerID    string    `db:"worker_id"`
+	LeaseID     string    `db:"lease_id"`
 	Payload     string    `db:"payload"`
 	Version     int64     `db:"version"`
 	RetryCount  int       `db:"retry_count"`
+	Priority    int       `db:"priority"`
 	ActiveAt    time.Time `db:"active_at"`
 	ShouldRetry bool      `db:"-"`
+
+	// ClaimedAt is when the current worker reserved the job. Unlike
+	// ActiveAt it is not moved by heartbeats.
+	ClaimedAt time.Time `db:"-"`
 }
 
 func NewJob(data interface{}) *Job {
@@ -37,6 +71,14 @@ func (job *Job) Retry(duration time.Duration) {
 	job.ShouldRetry = true
 }
 
+// Defer puts the job back in the queue until the duration has passed
+// without counting it as a failed attempt.
+func (job *Job) Defer(duration time.Duration) {
+	job.WorkerID = ""
+	job.ActiveAt = time.Now().Add(duration)
+	job.ShouldRetry = true
+}
+
 func (job *Job) State() (int, time.Time) {
 	return job.RetryCount, job.ActiveAt
 }
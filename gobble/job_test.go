@@ -57,6 +57,21 @@ var _ = Describe("Job", func() {
 		})
 	})
 
+	Describe("Defer", func() {
+		It("sets up the job to be tried again without counting a retry", func() {
+			job := gobble.NewJob("the data")
+			job.RetryCount = 1
+			job.WorkerID = "my-id"
+
+			job.Defer(2 * time.Second)
+
+			Expect(job.WorkerID).To(Equal(""))
+			Expect(job.RetryCount).To(Equal(1))
+			Expect(job.ActiveAt).To(BeTemporally("~", time.Now().Add(2*time.Second), time.Second))
+			Expect(job.ShouldRetry).To(BeTrue())
+		})
+	})
+
 	Describe("State", func() {
 		It("returns the current retry count and active at values", func() {
 			expectedActiveAt := time.Now().Add(-5 * time.Minute)
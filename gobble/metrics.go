@@ -1,6 +1,7 @@
 package gobble
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/rcrowley/go-metrics"
@@ -12,7 +13,7 @@ type QueueGauge struct {
 }
 
 type queue interface {
-	Len() (int, error)
+	Stats() (QueueStats, error)
 }
 
 func NewQueueGauge(queue queue, timer <-chan time.Time) QueueGauge {
@@ -23,9 +24,38 @@ func NewQueueGauge(queue queue, timer <-chan time.Time) QueueGauge {
 }
 
 func (g QueueGauge) Run() {
+	reported := map[string]bool{}
+
 	for range g.timer {
-		ql, _ := g.queue.Len()
+		stats, err := g.queue.Stats()
+		if err != nil {
+			continue
+		}
+
+		metrics.GetOrRegisterGauge("notifications.queue.length", nil).Update(int64(stats.Total))
+		metrics.GetOrRegisterGauge("notifications.queue.pending", nil).Update(int64(stats.Pending))
+		metrics.GetOrRegisterGauge("notifications.queue.oldest-age-seconds", nil).Update(int64(stats.OldestAge / time.Second))
+
+		// Gauges for retry counts and workers that have dropped out of the
+		// queue since the last tick go back to zero.
+		current := map[string]int64{}
+		for retryCount, count := range stats.ByRetryCount {
+			current["notifications.queue.retry-count."+strconv.Itoa(retryCount)] = int64(count)
+		}
+		for workerID, count := range stats.ClaimsByWorker {
+			current["notifications.queue.claims."+workerID] = int64(count)
+		}
+
+		for name := range reported {
+			if _, ok := current[name]; !ok {
+				metrics.GetOrRegisterGauge(name, nil).Update(0)
+				delete(reported, name)
+			}
+		}
 
-		metrics.GetOrRegisterGauge("notifications.queue.length", nil).Update(int64(ql))
+		for name, value := range current {
+			metrics.GetOrRegisterGauge(name, nil).Update(value)
+			reported[name] = true
+		}
 	}
 }
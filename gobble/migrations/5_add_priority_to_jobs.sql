@@ -0,0 +1,18 @@
+-- +migrate Up
+SET @preparedStatement = (SELECT IF(
+    (SELECT COUNT(*)
+        FROM INFORMATION_SCHEMA.COLUMNS
+        WHERE  table_name = 'jobs'
+        AND table_schema = DATABASE()
+        AND column_name = 'priority'
+    ) > 0,
+    "SELECT 1",
+    "ALTER TABLE `jobs` ADD `priority` INT(11) NOT NULL DEFAULT '0';"
+));
+
+PREPARE alterIfNotExists FROM @preparedStatement;
+EXECUTE alterIfNotExists;
+DEALLOCATE PREPARE alterIfNotExists;
+
+-- +migrate Down
+ALTER TABLE `jobs` DROP COLUMN priority;
@@ -0,0 +1,18 @@
+-- +migrate Up
+SET @preparedStatement = (SELECT IF(
+    (SELECT COUNT(*)
+        FROM INFORMATION_SCHEMA.COLUMNS
+        WHERE  table_name = 'jobs'
+        AND table_schema = DATABASE()
+        AND column_name = 'lease_id'
+    ) > 0,
+    "SELECT 1",
+    "ALTER TABLE `jobs` ADD `lease_id` VARCHAR(32) NOT NULL DEFAULT '', ADD INDEX `lease_id` (`lease_id`);"
+));
+
+PREPARE alterIfNotExists FROM @preparedStatement;
+EXECUTE alterIfNotExists;
+DEALLOCATE PREPARE alterIfNotExists;
+
+-- +migrate Down
+ALTER TABLE `jobs` DROP INDEX `lease_id`, DROP COLUMN `lease_id`;
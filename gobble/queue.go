@@ -1,9 +1,12 @@
 package gobble
 
 import (
+	crand "crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"math/rand"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"gopkg.in/gorp.v1"
@@ -17,6 +20,34 @@ type QueueInterface interface {
 	Dequeue(*Job)
 	Requeue(*Job)
 	Len() (int, error)
+	Stats() (QueueStats, error)
+}
+
+// Backend is a queue together with the operations the service needs besides
+// reserving and running jobs. Queue, which keeps the jobs in the database,
+// and RedisQueue both implement it.
+type Backend interface {
+	QueueInterface
+	Pending(afterID, limit int) ([]Job, error)
+	Jobs() ([]Job, error)
+	Reschedule(job *Job, activeAt time.Time) error
+	OldestByPriority() (map[int]time.Time, error)
+	Escalate(priority int, activeBefore time.Time, to int) (int, error)
+
+	// Transactional reports whether Enqueue takes part in the transaction
+	// of the connection it is given. When it does not, callers should only
+	// enqueue once their transaction is committed.
+	Transactional() bool
+}
+
+// NewBackend returns the queue the configuration selects: Redis when
+// config.RedisURL is set, and the jobs table of db otherwise.
+func NewBackend(db *sql.DB, clock clock, config Config) (Backend, error) {
+	if config.RedisURL == "" {
+		return NewQueue(NewDatabase(db), clock, config), nil
+	}
+
+	return NewRedisQueue(clock, config)
 }
 
 type clock interface {
@@ -24,10 +55,11 @@ type clock interface {
 }
 
 type Queue struct {
-	config   Config
-	database *DB
-	clock    clock
-	closed   bool
+	config       Config
+	database     *DB
+	clock        clock
+	closed       bool
+	reservations uint64
 }
 
 func NewQueue(database DatabaseInterface, clock clock, config Config) *Queue {
@@ -42,6 +74,10 @@ func NewQueue(database DatabaseInterface, clock clock, config Config) *Queue {
 	}
 }
 
+func (queue *Queue) Transactional() bool {
+	return true
+}
+
 func (queue *Queue) Enqueue(job *Job, connection ConnectionInterface) (*Job, error) {
 	if (job.ActiveAt == time.Time{}) {
 		job.ActiveAt = queue.clock.Now()
@@ -67,6 +103,122 @@ func (queue *Queue) Len() (int, error) {
 	return int(length), err
 }
 
+// QueueStats describes the backlog of the queue. OldestAge is how long the
+// longest waiting job has been ready to run without a worker taking it.
+type QueueStats struct {
+	Total          int
+	Pending        int
+	OldestAge      time.Duration
+	ByRetryCount   map[int]int
+	ClaimsByWorker map[string]int
+}
+
+func (queue *Queue) Stats() (QueueStats, error) {
+	stats := QueueStats{
+		ByRetryCount:   map[int]int{},
+		ClaimsByWorker: map[string]int{},
+	}
+
+	var retries []struct {
+		RetryCount int `db:"retry_count"`
+		Count      int `db:"count"`
+	}
+	_, err := queue.database.Connection.Select(&retries, "SELECT `retry_count`, COUNT(*) AS `count` FROM `jobs` GROUP BY `retry_count`")
+	if err != nil {
+		return stats, err
+	}
+
+	for _, row := range retries {
+		stats.ByRetryCount[row.RetryCount] = row.Count
+		stats.Total += row.Count
+	}
+
+	var claims []struct {
+		WorkerID string `db:"worker_id"`
+		Count    int    `db:"count"`
+	}
+	_, err = queue.database.Connection.Select(&claims, "SELECT `worker_id`, COUNT(*) AS `count` FROM `jobs` WHERE `worker_id` != \"\" GROUP BY `worker_id`")
+	if err != nil {
+		return stats, err
+	}
+
+	claimed := 0
+	for _, row := range claims {
+		stats.ClaimsByWorker[row.WorkerID] = row.Count
+		claimed += row.Count
+	}
+	stats.Pending = stats.Total - claimed
+
+	now := queue.clock.Now()
+	var oldest []Job
+	_, err = queue.database.Connection.Select(&oldest, "SELECT * FROM `jobs` WHERE `worker_id` = \"\" AND `active_at` <= ? ORDER BY `active_at` LIMIT 1", now)
+	if err != nil {
+		return stats, err
+	}
+
+	if len(oldest) > 0 {
+		stats.OldestAge = now.Sub(oldest[0].ActiveAt)
+	}
+
+	return stats, nil
+}
+
+// OldestByPriority returns, for each priority with jobs ready to run that no
+// worker has reserved, when the longest waiting of them became ready.
+func (queue *Queue) OldestByPriority() (map[int]time.Time, error) {
+	var rows []struct {
+		Priority int       `db:"priority"`
+		Oldest   time.Time `db:"oldest"`
+	}
+	_, err := queue.database.Connection.Select(&rows, "SELECT `priority`, MIN(`active_at`) AS `oldest` FROM `jobs` WHERE `worker_id` = \"\" AND `active_at` <= ? GROUP BY `priority`", queue.clock.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	oldest := map[int]time.Time{}
+	for _, row := range rows {
+		oldest[row.Priority] = row.Oldest
+	}
+
+	return oldest, nil
+}
+
+// Escalate moves the unreserved jobs of a priority that became ready
+// before activeBefore to another priority, and returns how many it moved.
+func (queue *Queue) Escalate(priority int, activeBefore time.Time, to int) (int, error) {
+	result, err := queue.database.Connection.Exec("UPDATE `jobs` SET `priority` = ?, `version` = `version` + 1 WHERE `worker_id` = \"\" AND `priority` = ? AND `active_at` < ?", to, priority, activeBefore)
+	if err != nil {
+		return 0, err
+	}
+
+	escalated, err := result.RowsAffected()
+	return int(escalated), err
+}
+
+// Pending returns up to limit of the jobs no worker has reserved whose IDs
+// follow afterID, ordered by ID, so that a large queue is read a page at a
+// time.
+func (queue *Queue) Pending(afterID, limit int) ([]Job, error) {
+	var jobs []Job
+	_, err := queue.database.Connection.Select(&jobs, "SELECT * FROM `jobs` WHERE `worker_id` = \"\" AND `id` > ? ORDER BY `id` LIMIT ?", afterID, limit)
+	return jobs, err
+}
+
+// Jobs returns every job in the queue, including those a worker has reserved.
+func (queue *Queue) Jobs() ([]Job, error) {
+	var jobs []Job
+	_, err := queue.database.Connection.Select(&jobs, "SELECT * FROM `jobs` ORDER BY `active_at`")
+	return jobs, err
+}
+
+// Reschedule moves a job that is waiting to the given time, along with any
+// change made to its priority.
+func (queue *Queue) Reschedule(job *Job, activeAt time.Time) error {
+	job.ActiveAt = activeAt
+	_, err := queue.database.Connection.Update(job)
+	return err
+}
+
 func (queue *Queue) Close() {
 	queue.closed = true
 }
@@ -79,28 +231,11 @@ func (queue *Queue) Reserve(workerID string) <-chan *Job {
 }
 
 func (queue *Queue) reserve(channel chan *Job, workerID string) {
-	var job *Job
-	for job == nil {
-		var err error
-
-		job = queue.findJob()
-		if queue.closed {
-			return
-		}
-
-		job, err = queue.updateJob(job, workerID)
-		if err != nil {
-			if _, ok := err.(gorp.OptimisticLockError); ok {
-				job = nil
-				continue
-			} else {
-				panic(err)
-			}
-		}
-	}
-
+	job := queue.claimJob(workerID)
 	if queue.closed {
-		queue.updateJob(job, "")
+		if job != nil {
+			queue.updateJob(job, "")
+		}
 		return
 	}
 
@@ -117,23 +252,69 @@ func (queue *Queue) Dequeue(job *Job) {
 	}
 }
 
-func (queue *Queue) findJob() *Job {
-	var job *Job
-	for job == nil {
-		job = &Job{}
+// claimJob leases the next active job to workerID. The job is claimed with a
+// single UPDATE so that workers in several processes sharing the database
+// never reserve the same job, and is then loaded by the random lease ID
+// written alongside the worker ID.
+func (queue *Queue) claimJob(workerID string) *Job {
+	order := queue.nextOrder()
+
+	for !queue.closed {
+		leaseID, err := newLeaseID()
+		if err != nil {
+			panic(err)
+		}
+
 		now := time.Now()
 		expired := now.Add(-2 * time.Minute)
-		err := queue.database.Connection.SelectOne(job, "SELECT * FROM `jobs` WHERE ( `worker_id` = \"\" AND `active_at` <= ? ) OR `active_at` <= ? LIMIT 1", now, expired)
+		result, err := queue.database.Connection.Exec("UPDATE `jobs` SET `worker_id` = ?, `lease_id` = ?, `active_at` = ?, `version` = `version` + 1 WHERE ( `worker_id` = \"\" AND `active_at` <= ? ) OR `active_at` <= ? ORDER BY "+order+" LIMIT 1", workerID, leaseID, now, now, expired)
 		if err != nil {
-			if err == sql.ErrNoRows {
-				job = nil
-				queue.waitUpTo(queue.config.WaitMaxDuration)
+			if strings.Contains(err.Error(), "Deadlock found") {
 				continue
 			}
 			panic(err)
 		}
+
+		claimed, err := result.RowsAffected()
+		if err != nil {
+			panic(err)
+		}
+
+		if claimed == 0 {
+			waitUpTo(queue.config.WaitMaxDuration)
+			continue
+		}
+
+		job := &Job{}
+		err = queue.database.Connection.SelectOne(job, "SELECT * FROM `jobs` WHERE `lease_id` = ?", leaseID)
+		if err != nil {
+			panic(err)
+		}
+		job.ActiveAt = now
+		job.ClaimedAt = now
+
+		return job
+	}
+
+	return nil
+}
+
+// nextOrder returns the ORDER BY clause for the next reservation. Priority
+// always comes first; within a priority, the weighted share decides whether
+// first attempts or retries go ahead, so that a backlog of retries cannot
+// hold up first attempts. Jobs are not weighted by client: a failing
+// client's own first attempts compete with everyone else's as usual.
+func (queue *Queue) nextOrder() string {
+	reservation := atomic.AddUint64(&queue.reservations, 1) - 1
+
+	switch queue.config.preference(reservation) {
+	case preferFresh:
+		return "`priority` DESC, `retry_count` > 0, `active_at`"
+	case preferRetry:
+		return "`priority` DESC, `retry_count` = 0, `active_at`"
+	default:
+		return "`priority` DESC, `active_at`"
 	}
-	return job
 }
 
 func (queue *Queue) updateJob(job *Job, workerID string) (*Job, error) {
@@ -150,7 +331,17 @@ func (queue *Queue) updateJob(job *Job, workerID string) (*Job, error) {
 	return job, nil
 }
 
-func (queue *Queue) waitUpTo(max time.Duration) {
+func newLeaseID() (string, error) {
+	id := make([]byte, 16)
+	_, err := crand.Read(id)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(id), nil
+}
+
+func waitUpTo(max time.Duration) {
 	rand.Seed(time.Now().UnixNano())
 	waitTime := rand.Int63n(int64(max))
 	<-time.After(time.Duration(waitTime))
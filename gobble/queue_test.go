@@ -6,6 +6,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"gopkg.in/gorp.v1"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -113,6 +114,8 @@ var _ = Describe("Queue", func() {
 
 			Expect(reservedJob.ID).To(Equal(job.ID))
 			Expect(reservedJob.ActiveAt).To(BeTemporally("~", time.Now(), 250*time.Millisecond))
+			Expect(reservedJob.ClaimedAt).To(Equal(reservedJob.ActiveAt))
+			Expect(reservedJob.WorkerID).To(Equal("workerId"))
 		})
 
 		It("keeps trying to reserve a job until one becomes available", func() {
@@ -158,6 +161,52 @@ var _ = Describe("Queue", func() {
 			Expect(results).To(HaveLen(0))
 		})
 
+		It("ensures a job is only reserved once by workers in different processes", func() {
+			otherQueue := gobble.NewQueue(gobble.NewDatabase(sqlDB), clock, gobble.Config{
+				WaitMaxDuration: 50 * time.Millisecond,
+			})
+			defer otherQueue.Close()
+
+			for i := 0; i < 20; i++ {
+				_, err := queue.Enqueue(&gobble.Job{}, database.Connection)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			reserved := make(chan *gobble.Job, 20)
+			reserveJobs := func(q *gobble.Queue, id string) {
+				for i := 0; i < 10; i++ {
+					reserved <- <-q.Reserve(id)
+				}
+			}
+
+			go reserveJobs(queue, "worker-1-instance-a-1")
+			go reserveJobs(otherQueue, "worker-1-instance-b-1")
+
+			ids := map[int]bool{}
+			for i := 0; i < 20; i++ {
+				var job *gobble.Job
+				Eventually(reserved, 30*time.Second).Should(Receive(&job))
+				Expect(ids).NotTo(HaveKey(job.ID))
+				ids[job.ID] = true
+			}
+		})
+
+		It("gives every reservation its own lease", func() {
+			first, err := queue.Enqueue(&gobble.Job{}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := queue.Enqueue(&gobble.Job{}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			firstReserved := <-queue.Reserve("worker-id")
+			secondReserved := <-queue.Reserve("worker-id")
+
+			Expect([]int{firstReserved.ID, secondReserved.ID}).To(ConsistOf(first.ID, second.ID))
+			Expect(firstReserved.WorkerID).To(Equal("worker-id"))
+			Expect(firstReserved.LeaseID).NotTo(BeEmpty())
+			Expect(secondReserved.LeaseID).NotTo(Equal(firstReserved.LeaseID))
+		})
+
 		It("picks the first job that is active", func() {
 			queue.Enqueue(&gobble.Job{
 				ActiveAt: time.Now().Add(1 * time.Hour),
@@ -170,6 +219,83 @@ var _ = Describe("Queue", func() {
 			Expect(job.ID).To(Equal(job2.ID))
 		})
 
+		It("picks an active job with a higher priority ahead of older jobs", func() {
+			_, err := queue.Enqueue(&gobble.Job{
+				ActiveAt: time.Now().Add(-1 * time.Minute),
+				Priority: gobble.PriorityBulk,
+			}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = queue.Enqueue(&gobble.Job{
+				ActiveAt: time.Now().Add(-30 * time.Second),
+			}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			critical, err := queue.Enqueue(&gobble.Job{
+				Priority: gobble.PriorityCritical,
+			}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			job := <-queue.Reserve("worker-id")
+
+			Expect(job.ID).To(Equal(critical.ID))
+			Expect(job.Priority).To(Equal(gobble.PriorityCritical))
+		})
+
+		Context("when first attempts and retries are weighted", func() {
+			var retries []*gobble.Job
+
+			BeforeEach(func() {
+				queue = gobble.NewQueue(database, clock, gobble.Config{
+					WaitMaxDuration: 50 * time.Millisecond,
+					FreshWeight:     1,
+					RetryWeight:     1,
+				})
+
+				retries = nil
+				for i := 0; i < 2; i++ {
+					retry, err := queue.Enqueue(&gobble.Job{
+						RetryCount: 3,
+						ActiveAt:   time.Now().Add(-time.Duration(2-i) * time.Minute / 4),
+					}, database.Connection)
+					Expect(err).NotTo(HaveOccurred())
+					retries = append(retries, retry)
+				}
+			})
+
+			It("interleaves first attempts with an older backlog of retries", func() {
+				fresh, err := queue.Enqueue(&gobble.Job{}, database.Connection)
+				Expect(err).NotTo(HaveOccurred())
+
+				first := <-queue.Reserve("worker-id")
+				second := <-queue.Reserve("worker-id")
+				third := <-queue.Reserve("worker-id")
+
+				Expect([]int{first.ID, second.ID, third.ID}).To(Equal([]int{fresh.ID, retries[0].ID, retries[1].ID}))
+			})
+
+			It("reserves retries when no first attempts are waiting", func() {
+				job := <-queue.Reserve("worker-id")
+
+				Expect(job.ID).To(Equal(retries[0].ID))
+			})
+
+			It("still reserves higher priority retries first", func() {
+				_, err := queue.Enqueue(&gobble.Job{}, database.Connection)
+				Expect(err).NotTo(HaveOccurred())
+
+				critical, err := queue.Enqueue(&gobble.Job{
+					RetryCount: 1,
+					Priority:   gobble.PriorityCritical,
+				}, database.Connection)
+				Expect(err).NotTo(HaveOccurred())
+
+				job := <-queue.Reserve("worker-id")
+
+				Expect(job.ID).To(Equal(critical.ID))
+			})
+		})
+
 		Context("when the worker id is set", func() {
 			Context("when active_at is in the future", func() {
 				It("should not grab the job", func() {
@@ -278,4 +404,128 @@ var _ = Describe("Queue", func() {
 			Expect(length).To(Equal(0))
 		})
 	})
+
+	Describe("Stats", func() {
+		It("describes the backlog of the queue", func() {
+			now := time.Now().UTC().Truncate(time.Second)
+			clock.NowCall.Returns.Time = now
+
+			_, err := queue.Enqueue(&gobble.Job{Payload: "oldest", ActiveAt: now.Add(-3 * time.Minute)}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = queue.Enqueue(&gobble.Job{Payload: "retried", RetryCount: 2, ActiveAt: now.Add(-time.Minute)}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = queue.Enqueue(&gobble.Job{Payload: "later", ActiveAt: now.Add(time.Hour)}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = queue.Enqueue(&gobble.Job{Payload: "reserved", WorkerID: "some-worker", ActiveAt: now.Add(-time.Hour)}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			stats, err := queue.Stats()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stats).To(Equal(gobble.QueueStats{
+				Total:          4,
+				Pending:        3,
+				OldestAge:      3 * time.Minute,
+				ByRetryCount:   map[int]int{0: 3, 2: 1},
+				ClaimsByWorker: map[string]int{"some-worker": 1},
+			}))
+		})
+	})
+
+	Describe("Pending", func() {
+		It("returns the unreserved jobs ordered by ID", func() {
+			now := time.Now().UTC().Truncate(time.Second)
+
+			first, err := queue.Enqueue(&gobble.Job{Payload: "first", ActiveAt: now.Add(time.Minute)}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = queue.Enqueue(&gobble.Job{Payload: "reserved", WorkerID: "some-worker", ActiveAt: now}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := queue.Enqueue(&gobble.Job{Payload: "second", ActiveAt: now}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			jobs, err := queue.Pending(0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jobs).To(HaveLen(2))
+			Expect(jobs[0].ID).To(Equal(first.ID))
+			Expect(jobs[1].ID).To(Equal(second.ID))
+		})
+
+		It("returns a page of the jobs after an ID", func() {
+			var ids []int
+			for i := 0; i < 3; i++ {
+				job, err := queue.Enqueue(&gobble.Job{}, database.Connection)
+				Expect(err).NotTo(HaveOccurred())
+				ids = append(ids, job.ID)
+			}
+
+			jobs, err := queue.Pending(ids[0], 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jobs).To(HaveLen(1))
+			Expect(jobs[0].ID).To(Equal(ids[1]))
+		})
+	})
+
+	Describe("Jobs", func() {
+		It("returns every job, reserved or not, ordered by active_at", func() {
+			now := time.Now().UTC().Truncate(time.Second)
+
+			later, err := queue.Enqueue(&gobble.Job{Payload: "later", ActiveAt: now.Add(time.Minute)}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			reserved, err := queue.Enqueue(&gobble.Job{Payload: "reserved", WorkerID: "some-worker", ActiveAt: now}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			jobs, err := queue.Jobs()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(jobs).To(HaveLen(2))
+			Expect(jobs[0].ID).To(Equal(reserved.ID))
+			Expect(jobs[1].ID).To(Equal(later.ID))
+		})
+	})
+
+	Describe("Reschedule", func() {
+		It("updates the active_at time of the job", func() {
+			job, err := queue.Enqueue(&gobble.Job{Payload: "something"}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			activeAt := time.Now().UTC().Add(10 * time.Minute).Truncate(time.Second)
+			err = queue.Reschedule(job, activeAt)
+			Expect(err).NotTo(HaveOccurred())
+
+			reloadedJob := gobble.Job{}
+			err = database.Connection.SelectOne(&reloadedJob, "SELECT * FROM `jobs` where id = ?", job.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reloadedJob.ActiveAt).To(BeTemporally("==", activeAt))
+		})
+
+		It("updates the priority of the job", func() {
+			job, err := queue.Enqueue(&gobble.Job{Payload: "something"}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			job.Priority = gobble.PriorityCritical
+			err = queue.Reschedule(job, time.Now())
+			Expect(err).NotTo(HaveOccurred())
+
+			reloadedJob := gobble.Job{}
+			err = database.Connection.SelectOne(&reloadedJob, "SELECT * FROM `jobs` where id = ?", job.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reloadedJob.Priority).To(Equal(gobble.PriorityCritical))
+		})
+
+		It("returns an error when the job has been modified by another worker", func() {
+			job, err := queue.Enqueue(&gobble.Job{Payload: "something"}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			stale := *job
+			job.WorkerID = "some-worker"
+			queue.Requeue(job)
+
+			err = queue.Reschedule(&stale, time.Now())
+			Expect(err).To(BeAssignableToTypeOf(gorp.OptimisticLockError{}))
+		})
+	})
 })
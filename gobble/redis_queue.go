@@ -0,0 +1,605 @@
+package gobble
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/redis"
+	"gopkg.in/gorp.v1"
+)
+
+// RedisQueue keeps the jobs in Redis rather than in the database, so that
+// reserving them does not poll the jobs table and their churn stays out of
+// it. Jobs that are ready wait in a sorted set per priority and kind of
+// attempt, scored by when they become active; reserved jobs move to a set
+// scored by their latest heartbeat. Every change is made by a script, so
+// that workers in several processes never reserve the same job.
+//
+// Unlike the jobs table, Redis does not take part in the transaction of the
+// connection given to Enqueue: a job is queued straight away, even if the
+// transaction is later rolled back.
+type RedisQueue struct {
+	client       *redis.Client
+	clock        clock
+	config       Config
+	closed       bool
+	reservations uint64
+}
+
+const redisPrefix = "gobble:"
+
+// How long connecting to Redis and each command may take.
+const redisTimeout = 10 * time.Second
+
+// The number of wake-ups kept for workers waiting for a job.
+const redisSignalLength = 100
+
+func NewRedisQueue(clock clock, config Config) (*RedisQueue, error) {
+	client, err := redis.NewClient(config.RedisURL, redisTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.WaitMaxDuration == 0 {
+		config.WaitMaxDuration = WaitMaxDuration
+	}
+
+	return &RedisQueue{
+		client: client,
+		clock:  clock,
+		config: config,
+	}, nil
+}
+
+const redisEnqueueScript = `
+local prefix, id, priority, score, kind, job = ARGV[1], ARGV[2], ARGV[3], ARGV[4], ARGV[5], ARGV[6]
+redis.call('SET', prefix .. 'job:' .. id, job)
+redis.call('SADD', prefix .. 'jobs', id)
+redis.call('HSET', prefix .. 'priority', id, priority)
+redis.call('ZADD', prefix .. 'priorities', priority, priority)
+redis.call('ZADD', prefix .. 'ready:' .. priority .. ':' .. kind, score, id)
+redis.call('LPUSH', prefix .. 'signal', id)
+redis.call('LTRIM', prefix .. 'signal', 0, tonumber(ARGV[7]) - 1)
+return 1
+`
+
+func (queue *RedisQueue) Transactional() bool {
+	return false
+}
+
+func (queue *RedisQueue) Enqueue(job *Job, connection ConnectionInterface) (*Job, error) {
+	if (job.ActiveAt == time.Time{}) {
+		job.ActiveAt = queue.clock.Now()
+	}
+
+	id, err := queue.client.Do(0, "INCR", redisPrefix+"next_id")
+	if err != nil {
+		return job, err
+	}
+	job.ID = int(id.(int64))
+
+	_, err = queue.client.Do(0, "EVAL", redisEnqueueScript, 0, redisPrefix, job.ID, job.Priority, redisScore(job.ActiveAt), redisKind(job), encodeRedisJob(job), redisSignalLength)
+	return job, err
+}
+
+const redisRequeueScript = `
+local prefix, id, worker, score, kind, job = ARGV[1], ARGV[2], ARGV[3], ARGV[4], ARGV[5], ARGV[6]
+if redis.call('EXISTS', prefix .. 'job:' .. id) == 0 then
+	return 0
+end
+if worker ~= '' then
+	if redis.call('HGET', prefix .. 'workers', id) ~= worker then
+		return 0
+	end
+	redis.call('ZADD', prefix .. 'reserved', score, id)
+	redis.call('SET', prefix .. 'job:' .. id, job)
+	return 1
+end
+local priority = redis.call('HGET', prefix .. 'priority', id)
+redis.call('ZREM', prefix .. 'reserved', id)
+redis.call('HDEL', prefix .. 'workers', id)
+redis.call('SET', prefix .. 'job:' .. id, job)
+redis.call('ZADD', prefix .. 'ready:' .. priority .. ':' .. kind, score, id)
+redis.call('LPUSH', prefix .. 'signal', id)
+redis.call('LTRIM', prefix .. 'signal', 0, tonumber(ARGV[7]) - 1)
+return 1
+`
+
+// Requeue renews the reservation of a job that still has a worker, as its
+// heartbeat does, and otherwise puts the job back to wait for its ActiveAt.
+// The heartbeat of a job another worker has since reserved is ignored.
+func (queue *RedisQueue) Requeue(job *Job) {
+	_, err := queue.client.Do(0, "EVAL", redisRequeueScript, 0, redisPrefix, job.ID, job.WorkerID, redisScore(job.ActiveAt), redisKind(job), encodeRedisJob(job), redisSignalLength)
+	if err != nil {
+		panic(err)
+	}
+}
+
+const redisDequeueScript = `
+local prefix, id = ARGV[1], ARGV[2]
+local priority = redis.call('HGET', prefix .. 'priority', id)
+if priority then
+	redis.call('ZREM', prefix .. 'ready:' .. priority .. ':fresh', id)
+	redis.call('ZREM', prefix .. 'ready:' .. priority .. ':retry', id)
+end
+redis.call('DEL', prefix .. 'job:' .. id)
+redis.call('SREM', prefix .. 'jobs', id)
+redis.call('HDEL', prefix .. 'priority', id)
+redis.call('HDEL', prefix .. 'workers', id)
+redis.call('ZREM', prefix .. 'reserved', id)
+return 1
+`
+
+func (queue *RedisQueue) Dequeue(job *Job) {
+	_, err := queue.client.Do(0, "EVAL", redisDequeueScript, 0, redisPrefix, job.ID)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func (queue *RedisQueue) Len() (int, error) {
+	length, err := queue.client.Do(0, "SCARD", redisPrefix+"jobs")
+	if err != nil {
+		return 0, err
+	}
+
+	return int(length.(int64)), nil
+}
+
+func (queue *RedisQueue) Stats() (QueueStats, error) {
+	stats := QueueStats{
+		ByRetryCount:   map[int]int{},
+		ClaimsByWorker: map[string]int{},
+	}
+
+	ids, err := queue.client.Do(0, "SMEMBERS", redisPrefix+"jobs")
+	if err != nil {
+		return stats, err
+	}
+
+	jobs, err := queue.load(ids.([]interface{}))
+	if err != nil {
+		return stats, err
+	}
+
+	for _, job := range jobs {
+		stats.ByRetryCount[job.RetryCount]++
+		stats.Total++
+	}
+
+	workers, err := queue.client.Do(0, "HGETALL", redisPrefix+"workers")
+	if err != nil {
+		return stats, err
+	}
+
+	claimed := 0
+	fields := workers.([]interface{})
+	for i := 1; i < len(fields); i += 2 {
+		stats.ClaimsByWorker[fields[i].(string)]++
+		claimed++
+	}
+	stats.Pending = stats.Total - claimed
+
+	oldest, err := queue.OldestByPriority()
+	if err != nil {
+		return stats, err
+	}
+
+	now := queue.clock.Now()
+	for _, activeAt := range oldest {
+		if age := now.Sub(activeAt); age > stats.OldestAge {
+			stats.OldestAge = age
+		}
+	}
+
+	return stats, nil
+}
+
+// OldestByPriority returns, for each priority with jobs ready to run that no
+// worker has reserved, when the longest waiting of them became ready.
+func (queue *RedisQueue) OldestByPriority() (map[int]time.Time, error) {
+	priorities, err := queue.priorities()
+	if err != nil {
+		return nil, err
+	}
+
+	now := redisScore(queue.clock.Now())
+	oldest := map[int]time.Time{}
+	for _, priority := range priorities {
+		for _, kind := range []string{preferFresh, preferRetry} {
+			head, err := queue.client.Do(0, "ZRANGEBYSCORE", redisReadyKey(priority, kind), "-inf", now, "WITHSCORES", "LIMIT", 0, 1)
+			if err != nil {
+				return nil, err
+			}
+
+			members := head.([]interface{})
+			if len(members) < 2 {
+				continue
+			}
+
+			activeAt, err := redisTime(members[1])
+			if err != nil {
+				return nil, err
+			}
+
+			if current, ok := oldest[priority]; !ok || activeAt.Before(current) {
+				oldest[priority] = activeAt
+			}
+		}
+	}
+
+	return oldest, nil
+}
+
+const redisEscalateScript = `
+local prefix, from, before, to = ARGV[1], ARGV[2], ARGV[3], ARGV[4]
+local moved = 0
+for _, kind in ipairs({'fresh', 'retry'}) do
+	local source = prefix .. 'ready:' .. from .. ':' .. kind
+	local target = prefix .. 'ready:' .. to .. ':' .. kind
+	local members = redis.call('ZRANGEBYSCORE', source, '-inf', '(' .. before, 'WITHSCORES')
+	for i = 1, #members, 2 do
+		redis.call('ZREM', source, members[i])
+		redis.call('ZADD', target, members[i + 1], members[i])
+		redis.call('HSET', prefix .. 'priority', members[i], to)
+		moved = moved + 1
+	end
+end
+if moved > 0 then
+	redis.call('ZADD', prefix .. 'priorities', to, to)
+end
+return moved
+`
+
+// Escalate moves the unreserved jobs of a priority that became ready
+// before activeBefore to another priority, and returns how many it moved.
+func (queue *RedisQueue) Escalate(priority int, activeBefore time.Time, to int) (int, error) {
+	moved, err := queue.client.Do(0, "EVAL", redisEscalateScript, 0, redisPrefix, priority, redisScore(activeBefore), to)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(moved.(int64)), nil
+}
+
+// Pending returns up to limit of the jobs no worker has reserved whose IDs
+// follow afterID, ordered by ID, so that a large queue is read a page at a
+// time.
+func (queue *RedisQueue) Pending(afterID, limit int) ([]Job, error) {
+	priorities, err := queue.priorities()
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []int
+	for _, priority := range priorities {
+		for _, kind := range []string{preferFresh, preferRetry} {
+			members, err := queue.client.Do(0, "ZRANGE", redisReadyKey(priority, kind), 0, -1)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, member := range members.([]interface{}) {
+				id, err := strconv.Atoi(member.(string))
+				if err != nil {
+					return nil, err
+				}
+
+				if id > afterID {
+					ids = append(ids, id)
+				}
+			}
+		}
+	}
+
+	sort.Ints(ids)
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	page := make([]interface{}, 0, len(ids))
+	for _, id := range ids {
+		page = append(page, strconv.Itoa(id))
+	}
+
+	return queue.load(page)
+}
+
+// Jobs returns every job in the queue, including those a worker has reserved.
+func (queue *RedisQueue) Jobs() ([]Job, error) {
+	ids, err := queue.client.Do(0, "SMEMBERS", redisPrefix+"jobs")
+	if err != nil {
+		return nil, err
+	}
+
+	jobs, err := queue.load(ids.([]interface{}))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(jobs, func(i, j int) bool {
+		return jobs[i].ActiveAt.Before(jobs[j].ActiveAt)
+	})
+
+	return jobs, nil
+}
+
+const redisRescheduleScript = `
+local prefix, id, score, kind, job, priority = ARGV[1], ARGV[2], ARGV[3], ARGV[4], ARGV[5], ARGV[7]
+if redis.call('EXISTS', prefix .. 'job:' .. id) == 0 or redis.call('HEXISTS', prefix .. 'workers', id) == 1 then
+	return 0
+end
+local current = redis.call('HGET', prefix .. 'priority', id)
+redis.call('ZREM', prefix .. 'ready:' .. current .. ':' .. kind, id)
+redis.call('SET', prefix .. 'job:' .. id, job)
+redis.call('HSET', prefix .. 'priority', id, priority)
+redis.call('ZADD', prefix .. 'priorities', priority, priority)
+redis.call('ZADD', prefix .. 'ready:' .. priority .. ':' .. kind, score, id)
+redis.call('LPUSH', prefix .. 'signal', id)
+redis.call('LTRIM', prefix .. 'signal', 0, tonumber(ARGV[6]) - 1)
+return 1
+`
+
+// Reschedule moves a job that is waiting to the given time, and to its
+// priority when that has changed. A job that a worker has reserved, or that
+// has left the queue, is reported with the same error the jobs table gives
+// for a job changed in the meantime.
+func (queue *RedisQueue) Reschedule(job *Job, activeAt time.Time) error {
+	job.ActiveAt = activeAt
+
+	rescheduled, err := queue.client.Do(0, "EVAL", redisRescheduleScript, 0, redisPrefix, job.ID, redisScore(activeAt), redisKind(job), encodeRedisJob(job), redisSignalLength, job.Priority)
+	if err != nil {
+		return err
+	}
+
+	if rescheduled.(int64) == 0 {
+		return gorp.OptimisticLockError{TableName: "jobs", Keys: []interface{}{job.ID}, RowExists: true, LocalVersion: job.Version}
+	}
+
+	return nil
+}
+
+func (queue *RedisQueue) Close() {
+	queue.closed = true
+}
+
+func (queue *RedisQueue) Reserve(workerID string) <-chan *Job {
+	channel := make(chan *Job)
+	go queue.reserve(channel, workerID)
+
+	return channel
+}
+
+func (queue *RedisQueue) reserve(channel chan *Job, workerID string) {
+	job := queue.claimJob(workerID)
+	if queue.closed {
+		if job != nil {
+			job.WorkerID = ""
+			queue.Requeue(job)
+		}
+		return
+	}
+
+	channel <- job
+}
+
+const redisReserveScript = `
+local prefix, now, expired, worker, prefer = ARGV[1], ARGV[2], ARGV[3], ARGV[4], ARGV[5]
+
+local function claim(id)
+	redis.call('ZADD', prefix .. 'reserved', now, id)
+	redis.call('HSET', prefix .. 'workers', id, worker)
+	return {redis.call('GET', prefix .. 'job:' .. id), redis.call('HGET', prefix .. 'priority', id)}
+end
+
+-- Reservations that have not been renewed for so long belong to workers
+-- that have gone, and their jobs are taken over.
+local stale = redis.call('ZRANGEBYSCORE', prefix .. 'reserved', '-inf', expired, 'LIMIT', 0, 1)
+if #stale > 0 then
+	return claim(stale[1])
+end
+
+for _, priority in ipairs(redis.call('ZREVRANGE', prefix .. 'priorities', 0, -1)) do
+	local fresh = prefix .. 'ready:' .. priority .. ':fresh'
+	local retry = prefix .. 'ready:' .. priority .. ':retry'
+	local freshHead = redis.call('ZRANGEBYSCORE', fresh, '-inf', now, 'WITHSCORES', 'LIMIT', 0, 1)
+	local retryHead = redis.call('ZRANGEBYSCORE', retry, '-inf', now, 'WITHSCORES', 'LIMIT', 0, 1)
+
+	local key, head
+	if #freshHead > 0 and #retryHead > 0 then
+		if prefer == 'fresh' then
+			key, head = fresh, freshHead
+		elseif prefer == 'retry' then
+			key, head = retry, retryHead
+		elseif tonumber(retryHead[2]) < tonumber(freshHead[2]) then
+			key, head = retry, retryHead
+		else
+			key, head = fresh, freshHead
+		end
+	elseif #freshHead > 0 then
+		key, head = fresh, freshHead
+	elseif #retryHead > 0 then
+		key, head = retry, retryHead
+	end
+
+	if key then
+		redis.call('ZREM', key, head[1])
+		return claim(head[1])
+	end
+end
+
+return false
+`
+
+// claimJob reserves the next active job for workerID. Between attempts it
+// waits for a job to be queued, for at most the configured wait.
+func (queue *RedisQueue) claimJob(workerID string) *Job {
+	for !queue.closed {
+		now := time.Now()
+		expired := now.Add(-2 * time.Minute)
+		reservation := atomic.AddUint64(&queue.reservations, 1) - 1
+
+		reply, err := queue.client.Do(0, "EVAL", redisReserveScript, 0, redisPrefix, redisScore(now), redisScore(expired), workerID, queue.config.preference(reservation))
+		if err != nil {
+			waitUpTo(queue.config.WaitMaxDuration)
+			continue
+		}
+
+		if reply == nil {
+			queue.waitForSignal()
+			continue
+		}
+
+		job, err := decodeRedisJob(reply.([]interface{}))
+		if err != nil {
+			panic(err)
+		}
+		job.WorkerID = workerID
+		job.ActiveAt = now
+		job.ClaimedAt = now
+
+		return job
+	}
+
+	return nil
+}
+
+// waitForSignal returns as soon as a job is queued or rescheduled, or once
+// the configured wait has passed.
+func (queue *RedisQueue) waitForSignal() {
+	wait := queue.config.WaitMaxDuration
+	seconds := math.Max(wait.Seconds(), 0.01)
+
+	_, err := queue.client.Do(wait, "BRPOP", redisPrefix+"signal", strconv.FormatFloat(seconds, 'f', 3, 64))
+	if err != nil {
+		waitUpTo(wait)
+	}
+}
+
+func (queue *RedisQueue) priorities() ([]int, error) {
+	members, err := queue.client.Do(0, "ZREVRANGE", redisPrefix+"priorities", 0, -1)
+	if err != nil {
+		return nil, err
+	}
+
+	var priorities []int
+	for _, member := range members.([]interface{}) {
+		priority, err := strconv.Atoi(member.(string))
+		if err != nil {
+			return nil, err
+		}
+		priorities = append(priorities, priority)
+	}
+
+	return priorities, nil
+}
+
+// load reads the jobs with the given IDs, leaving out any that have left
+// the queue since the IDs were read.
+func (queue *RedisQueue) load(ids []interface{}) ([]Job, error) {
+	var jobs []Job
+	if len(ids) == 0 {
+		return jobs, nil
+	}
+
+	keys := []interface{}{"MGET"}
+	priorityKeys := []interface{}{"HMGET", redisPrefix + "priority"}
+	for _, id := range ids {
+		keys = append(keys, redisPrefix+"job:"+id.(string))
+		priorityKeys = append(priorityKeys, id)
+	}
+
+	encoded, err := queue.client.Do(0, keys...)
+	if err != nil {
+		return nil, err
+	}
+
+	priorities, err := queue.client.Do(0, priorityKeys...)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, job := range encoded.([]interface{}) {
+		if job == nil {
+			continue
+		}
+
+		decoded, err := decodeRedisJob([]interface{}{job, priorities.([]interface{})[i]})
+		if err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, *decoded)
+	}
+
+	return jobs, nil
+}
+
+func redisReadyKey(priority int, kind string) string {
+	return redisPrefix + "ready:" + strconv.Itoa(priority) + ":" + kind
+}
+
+// redisKind sorts jobs on their first attempt apart from retries, so that
+// the fresh and retry weights can prefer one or the other.
+func redisKind(job *Job) string {
+	if job.RetryCount > 0 {
+		return preferRetry
+	}
+
+	return preferFresh
+}
+
+func redisScore(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+func redisTime(score interface{}) (time.Time, error) {
+	milliseconds, err := strconv.ParseFloat(fmt.Sprint(score), 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, int64(milliseconds)*int64(time.Millisecond)), nil
+}
+
+// encodeRedisJob stores what the job carries between attempts. Who has
+// reserved it, and its priority, are kept alongside it.
+func encodeRedisJob(job *Job) string {
+	stored := *job
+	stored.WorkerID = ""
+	stored.ShouldRetry = false
+	stored.ClaimedAt = time.Time{}
+
+	encoded, err := json.Marshal(stored)
+	if err != nil {
+		panic(err)
+	}
+
+	return string(encoded)
+}
+
+// decodeRedisJob reads a job and its priority as the scripts return them.
+func decodeRedisJob(reply []interface{}) (*Job, error) {
+	if len(reply) != 2 || reply[0] == nil {
+		return nil, fmt.Errorf("redis: job is missing from %v", reply)
+	}
+
+	job := &Job{}
+	err := json.Unmarshal([]byte(reply[0].(string)), job)
+	if err != nil {
+		return nil, err
+	}
+
+	if reply[1] != nil {
+		job.Priority, err = strconv.Atoi(reply[1].(string))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return job, nil
+}
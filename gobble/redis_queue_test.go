@@ -0,0 +1,174 @@
+package gobble_test
+
+import (
+	"os"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/redis"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"gopkg.in/gorp.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RedisQueue", func() {
+	var (
+		queue *gobble.RedisQueue
+		clock *mocks.Clock
+	)
+
+	BeforeEach(func() {
+		redisURL := os.Getenv("REDIS_URL")
+		if redisURL == "" {
+			Skip("REDIS_URL is not set")
+		}
+		flushRedis(redisURL)
+
+		clock = &mocks.Clock{}
+		clock.NowCall.Returns.Time = time.Now().UTC().Truncate(time.Second)
+
+		var err error
+		queue, err = gobble.NewRedisQueue(clock, gobble.Config{
+			WaitMaxDuration: 50 * time.Millisecond,
+			RedisURL:        redisURL,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		if queue != nil {
+			queue.Close()
+		}
+	})
+
+	It("reserves, retries and dequeues a job", func() {
+		job, err := queue.Enqueue(&gobble.Job{Payload: "hello", Priority: gobble.PriorityCritical}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		length, err := queue.Len()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(length).To(Equal(1))
+
+		var reserved *gobble.Job
+		Eventually(queue.Reserve("worker-1")).Should(Receive(&reserved))
+		Expect(reserved.ID).To(Equal(job.ID))
+		Expect(reserved.Payload).To(Equal("hello"))
+		Expect(reserved.Priority).To(Equal(gobble.PriorityCritical))
+		Expect(reserved.WorkerID).To(Equal("worker-1"))
+
+		stats, err := queue.Stats()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stats.ClaimsByWorker).To(Equal(map[string]int{"worker-1": 1}))
+		Expect(stats.Pending).To(Equal(0))
+
+		jobs, err := queue.Jobs()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].ID).To(Equal(job.ID))
+
+		reserved.Retry(0)
+		queue.Requeue(reserved)
+
+		Eventually(queue.Reserve("worker-2")).Should(Receive(&reserved))
+		Expect(reserved.ID).To(Equal(job.ID))
+		Expect(reserved.RetryCount).To(Equal(1))
+
+		queue.Dequeue(reserved)
+
+		length, err = queue.Len()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(length).To(Equal(0))
+	})
+
+	It("does not reserve a job before it is active", func() {
+		_, err := queue.Enqueue(&gobble.Job{ActiveAt: time.Now().Add(time.Hour)}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Consistently(queue.Reserve("worker-1")).ShouldNot(Receive())
+
+		pending, err := queue.Pending(0, 10)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pending).To(HaveLen(1))
+	})
+
+	It("reserves a job only once across queues", func() {
+		otherQueue, err := gobble.NewRedisQueue(clock, gobble.Config{
+			WaitMaxDuration: 50 * time.Millisecond,
+			RedisURL:        os.Getenv("REDIS_URL"),
+		})
+		Expect(err).NotTo(HaveOccurred())
+		defer otherQueue.Close()
+
+		for i := 0; i < 20; i++ {
+			_, err := queue.Enqueue(&gobble.Job{}, nil)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		reserved := make(chan *gobble.Job, 20)
+		reserveJobs := func(q *gobble.RedisQueue, id string) {
+			for i := 0; i < 10; i++ {
+				reserved <- <-q.Reserve(id)
+			}
+		}
+
+		go reserveJobs(queue, "worker-1-instance-a-1")
+		go reserveJobs(otherQueue, "worker-1-instance-b-1")
+
+		ids := map[int]bool{}
+		for i := 0; i < 20; i++ {
+			var job *gobble.Job
+			Eventually(reserved, 10*time.Second).Should(Receive(&job))
+			Expect(ids).NotTo(HaveKey(job.ID))
+			ids[job.ID] = true
+		}
+	})
+
+	It("refuses to reschedule a reserved job", func() {
+		_, err := queue.Enqueue(&gobble.Job{}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		var reserved *gobble.Job
+		Eventually(queue.Reserve("worker-1")).Should(Receive(&reserved))
+
+		err = queue.Reschedule(reserved, time.Now())
+		Expect(err).To(BeAssignableToTypeOf(gorp.OptimisticLockError{}))
+	})
+
+	It("moves a rescheduled job to its new priority", func() {
+		job, err := queue.Enqueue(&gobble.Job{ActiveAt: time.Now().Add(-time.Minute)}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		job.Priority = gobble.PriorityCritical
+		err = queue.Reschedule(job, time.Now().Add(-time.Minute))
+		Expect(err).NotTo(HaveOccurred())
+
+		oldest, err := queue.OldestByPriority()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(oldest).To(HaveKey(gobble.PriorityCritical))
+		Expect(oldest).NotTo(HaveKey(gobble.PriorityNormal))
+	})
+
+	It("escalates jobs that have waited too long", func() {
+		_, err := queue.Enqueue(&gobble.Job{ActiveAt: time.Now().Add(-time.Minute)}, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		escalated, err := queue.Escalate(gobble.PriorityNormal, time.Now(), gobble.PriorityCritical)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(escalated).To(Equal(1))
+
+		oldest, err := queue.OldestByPriority()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(oldest).To(HaveKey(gobble.PriorityCritical))
+		Expect(oldest).NotTo(HaveKey(gobble.PriorityNormal))
+	})
+})
+
+func flushRedis(redisURL string) {
+	client, err := redis.NewClient(redisURL, time.Second)
+	Expect(err).NotTo(HaveOccurred())
+
+	_, err = client.Do(0, "FLUSHDB")
+	Expect(err).NotTo(HaveOccurred())
+}
@@ -0,0 +1,144 @@
+package gobble
+
+import (
+	"sort"
+	"time"
+)
+
+type slaQueue interface {
+	OldestByPriority() (map[int]time.Time, error)
+	Escalate(priority int, activeBefore time.Time, to int) (int, error)
+}
+
+// SLAStatus compares how long the oldest ready job of a priority has been
+// waiting with the longest it is allowed to.
+type SLAStatus struct {
+	Priority  int
+	MaxAge    time.Duration
+	OldestAge time.Duration
+}
+
+func (s SLAStatus) Breached() bool {
+	return s.OldestAge > s.MaxAge
+}
+
+// Escalation records the jobs of a priority that waited longer than its
+// maximum age being moved up to the next priority. To equals Priority when
+// there is no higher priority to move them to.
+type Escalation struct {
+	SLAStatus
+	To   int
+	Jobs int
+}
+
+// SLAMonitor holds the queue to a maximum age for the jobs of each
+// priority. Priorities without a maximum age are not monitored.
+type SLAMonitor struct {
+	queue   slaQueue
+	clock   clock
+	maxAges map[int]time.Duration
+}
+
+func NewSLAMonitor(queue slaQueue, clock clock, maxAges map[int]time.Duration) SLAMonitor {
+	return SLAMonitor{
+		queue:   queue,
+		clock:   clock,
+		maxAges: maxAges,
+	}
+}
+
+// Status reports on every monitored priority, highest first.
+func (m SLAMonitor) Status() ([]SLAStatus, error) {
+	if len(m.maxAges) == 0 {
+		return nil, nil
+	}
+
+	oldest, err := m.queue.OldestByPriority()
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.clock.Now()
+
+	var statuses []SLAStatus
+	for priority, maxAge := range m.maxAges {
+		status := SLAStatus{
+			Priority: priority,
+			MaxAge:   maxAge,
+		}
+
+		if activeAt, ok := oldest[priority]; ok && now.After(activeAt) {
+			status.OldestAge = now.Sub(activeAt)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].Priority > statuses[j].Priority
+	})
+
+	return statuses, nil
+}
+
+func (m SLAMonitor) Breached() (bool, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return false, err
+	}
+
+	for _, status := range statuses {
+		if status.Breached() {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// Escalate moves the jobs that have waited longer than the maximum age of
+// their priority up to the next priority, where they are reserved sooner.
+// Jobs that are escalated again if they keep waiting, until they reach the
+// highest priority.
+func (m SLAMonitor) Escalate() ([]Escalation, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	now := m.clock.Now()
+
+	var escalations []Escalation
+	for _, status := range statuses {
+		if !status.Breached() {
+			continue
+		}
+
+		escalation := Escalation{
+			SLAStatus: status,
+			To:        nextPriority(status.Priority),
+		}
+
+		if escalation.To != status.Priority {
+			escalation.Jobs, err = m.queue.Escalate(status.Priority, now.Add(-status.MaxAge), escalation.To)
+			if err != nil {
+				return escalations, err
+			}
+		}
+
+		escalations = append(escalations, escalation)
+	}
+
+	return escalations, nil
+}
+
+func nextPriority(priority int) int {
+	switch {
+	case priority < PriorityNormal:
+		return PriorityNormal
+	case priority < PriorityCritical:
+		return PriorityCritical
+	default:
+		return priority
+	}
+}
@@ -0,0 +1,112 @@
+package gobble_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SLAMonitor", func() {
+	var (
+		monitor gobble.SLAMonitor
+		queue   *mocks.Queue
+		clock   *mocks.Clock
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		queue = mocks.NewQueue()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		monitor = gobble.NewSLAMonitor(queue, clock, map[int]time.Duration{
+			gobble.PriorityBulk:     time.Hour,
+			gobble.PriorityNormal:   10 * time.Minute,
+			gobble.PriorityCritical: time.Minute,
+		})
+	})
+
+	Describe("Status", func() {
+		It("compares the oldest ready job of each priority with its maximum age", func() {
+			queue.OldestByPriorityCall.Returns.Oldest = map[int]time.Time{
+				gobble.PriorityNormal:   now.Add(-15 * time.Minute),
+				gobble.PriorityCritical: now.Add(-30 * time.Second),
+			}
+
+			statuses, err := monitor.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses).To(Equal([]gobble.SLAStatus{
+				{Priority: gobble.PriorityCritical, MaxAge: time.Minute, OldestAge: 30 * time.Second},
+				{Priority: gobble.PriorityNormal, MaxAge: 10 * time.Minute, OldestAge: 15 * time.Minute},
+				{Priority: gobble.PriorityBulk, MaxAge: time.Hour},
+			}))
+
+			Expect(statuses[0].Breached()).To(BeFalse())
+			Expect(statuses[1].Breached()).To(BeTrue())
+		})
+
+		It("does not read the queue when no priority is monitored", func() {
+			queue.OldestByPriorityCall.Returns.Error = errors.New("should not be called")
+			monitor = gobble.NewSLAMonitor(queue, clock, nil)
+
+			statuses, err := monitor.Status()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(statuses).To(BeEmpty())
+		})
+	})
+
+	Describe("Breached", func() {
+		It("is true when any priority is over its maximum age", func() {
+			queue.OldestByPriorityCall.Returns.Oldest = map[int]time.Time{
+				gobble.PriorityBulk: now.Add(-2 * time.Hour),
+			}
+
+			breached, err := monitor.Breached()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(breached).To(BeTrue())
+
+			queue.OldestByPriorityCall.Returns.Oldest = map[int]time.Time{
+				gobble.PriorityBulk: now.Add(-time.Minute),
+			}
+
+			breached, err = monitor.Breached()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(breached).To(BeFalse())
+		})
+	})
+
+	Describe("Escalate", func() {
+		It("moves jobs that waited too long up one priority", func() {
+			queue.OldestByPriorityCall.Returns.Oldest = map[int]time.Time{
+				gobble.PriorityBulk:     now.Add(-2 * time.Hour),
+				gobble.PriorityNormal:   now.Add(-time.Minute),
+				gobble.PriorityCritical: now.Add(-5 * time.Minute),
+			}
+			queue.EscalateCall.Returns.Count = 4
+
+			escalations, err := monitor.Escalate()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(escalations).To(Equal([]gobble.Escalation{
+				{
+					SLAStatus: gobble.SLAStatus{Priority: gobble.PriorityCritical, MaxAge: time.Minute, OldestAge: 5 * time.Minute},
+					To:        gobble.PriorityCritical,
+				},
+				{
+					SLAStatus: gobble.SLAStatus{Priority: gobble.PriorityBulk, MaxAge: time.Hour, OldestAge: 2 * time.Hour},
+					To:        gobble.PriorityNormal,
+					Jobs:      4,
+				},
+			}))
+
+			Expect(queue.EscalateCall.Receives.Priorities).To(Equal([]int{gobble.PriorityBulk}))
+			Expect(queue.EscalateCall.Receives.ActiveBefores).To(Equal([]time.Time{now.Add(-time.Hour)}))
+			Expect(queue.EscalateCall.Receives.Tos).To(Equal([]int{gobble.PriorityNormal}))
+		})
+	})
+})
@@ -18,9 +18,12 @@ type Worker struct {
 	halt     chan bool
 }
 
-func NewWorker(id int, queue QueueInterface, callback func(*Job), beater heartbeater) Worker {
+// NewWorker names the worker after its index, the instance it runs on and
+// the process ID so that workers in processes sharing one database never
+// claim jobs under the same ID.
+func NewWorker(id int, instanceID string, queue QueueInterface, callback func(*Job), beater heartbeater) Worker {
 	return Worker{
-		ID:       fmt.Sprintf("worker-%d-%d", id, os.Getpid()),
+		ID:       fmt.Sprintf("worker-%d-%s-%d", id, instanceID, os.Getpid()),
 		queue:    queue,
 		callback: callback,
 		beater:   beater,
@@ -56,6 +59,25 @@ func (worker *Worker) Work() {
 	}()
 }
 
+// WorkUntil works like Work until stop is closed. A worker that is waiting
+// for a job when stop is closed finishes the next job it reserves before it
+// stops, so that no job is left reserved by a worker that has gone.
+func (worker *Worker) WorkUntil(stop <-chan struct{}) {
+	go func() {
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			if worker.Perform() != 0 {
+				return
+			}
+		}
+	}()
+}
+
 func (worker *Worker) Halt() {
 	worker.halt <- true
 }
@@ -1,6 +1,8 @@
 package gobble_test
 
 import (
+	"fmt"
+	"os"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/gobble"
@@ -53,13 +55,17 @@ var _ = Describe("Worker", func() {
 
 		queue = gobble.NewQueue(database, clock, gobble.Config{})
 		heartbeater = &MockHeartbeater{}
-		worker = gobble.NewWorker(1, queue, callback, heartbeater)
+		worker = gobble.NewWorker(1, "some-instance", queue, callback, heartbeater)
 	})
 
 	AfterEach(func() {
 		queue.Close()
 	})
 
+	It("is named after its index, instance and process", func() {
+		Expect(worker.ID).To(Equal(fmt.Sprintf("worker-1-some-instance-%d", os.Getpid())))
+	})
+
 	Describe("Perform", func() {
 		It("reserves a job, performs the callback, and then dequeues the completed job", func() {
 			job, err := queue.Enqueue(&gobble.Job{
@@ -80,7 +86,7 @@ var _ = Describe("Worker", func() {
 			callback = func(job *gobble.Job) {
 				job.Retry(1 * time.Minute)
 			}
-			worker = gobble.NewWorker(1, queue, callback, heartbeater)
+			worker = gobble.NewWorker(1, "some-instance", queue, callback, heartbeater)
 
 			job, err := queue.Enqueue(&gobble.Job{}, database.Connection)
 			Expect(err).NotTo(HaveOccurred())
@@ -108,7 +114,7 @@ var _ = Describe("Worker", func() {
 			callback = func(*gobble.Job) {
 				<-hold
 			}
-			worker = gobble.NewWorker(2, queue, callback, heartbeater)
+			worker = gobble.NewWorker(2, "some-instance", queue, callback, heartbeater)
 
 			go worker.Perform()
 
@@ -126,7 +132,7 @@ var _ = Describe("Worker", func() {
 
 	Describe("Work", func() {
 		It("works in a loop, and can be stopped", func() {
-			worker = gobble.NewWorker(1, queue, callback, &MockHeartbeater{})
+			worker = gobble.NewWorker(1, "some-instance", queue, callback, &MockHeartbeater{})
 
 			queue.Enqueue(&gobble.Job{
 				Payload: "the-payload",
@@ -150,4 +156,35 @@ var _ = Describe("Worker", func() {
 			worker.Halt()
 		})
 	})
+
+	Describe("WorkUntil", func() {
+		It("stops taking jobs once stop is closed", func() {
+			worker = gobble.NewWorker(1, "some-instance", queue, callback, &MockHeartbeater{})
+
+			_, err := queue.Enqueue(&gobble.Job{Payload: "the-payload"}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			stop := make(chan struct{})
+			worker.WorkUntil(stop)
+
+			Eventually(func() (int, error) {
+				results, err := database.Connection.Select(gobble.Job{}, "SELECT * FROM `jobs`")
+
+				return len(results), err
+			}).Should(Equal(0))
+
+			close(stop)
+
+			_, err = queue.Enqueue(&gobble.Job{Payload: "the-payload"}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+			_, err = queue.Enqueue(&gobble.Job{Payload: "the-payload"}, database.Connection)
+			Expect(err).NotTo(HaveOccurred())
+
+			Consistently(func() (int, error) {
+				results, err := database.Connection.Select(gobble.Job{}, "SELECT * FROM `jobs`")
+
+				return len(results), err
+			}, 200*time.Millisecond).Should(BeNumerically(">=", 1))
+		})
+	})
 })
@@ -0,0 +1,13 @@
+package httpclient_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestHTTPClientSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "httpclient")
+}
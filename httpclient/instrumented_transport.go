@@ -0,0 +1,120 @@
+package httpclient
+
+import (
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// InstrumentedTransport times and counts the requests made to a dependency,
+// and retries the idempotent ones that fail in a way that is likely to be
+// temporary. Retries wait an exponentially growing, jittered backoff, so
+// that instances that failed together do not retry together.
+type InstrumentedTransport struct {
+	Dependency   string
+	Transport    http.RoundTripper
+	Retries      int
+	RetryBackoff time.Duration
+}
+
+func (t InstrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	prefix := "notifications.external-requests." + t.Dependency
+
+	retries := t.Retries
+	if !idempotent(req) {
+		retries = 0
+	}
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 {
+			metrics.GetOrRegisterCounter(prefix+".retries", nil).Inc(1)
+
+			var err error
+			req, err = rewind(req)
+			if err != nil {
+				return nil, err
+			}
+
+			select {
+			case <-time.After(jitter(t.RetryBackoff << uint(attempt-1))):
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			}
+		}
+
+		then := time.Now()
+		response, err := t.Transport.RoundTrip(req)
+		metrics.GetOrRegisterTimer(prefix+".requests", nil).Update(time.Since(then))
+
+		if err != nil {
+			metrics.GetOrRegisterCounter(prefix+".errors", nil).Inc(1)
+			if attempt < retries && req.Context().Err() == nil {
+				continue
+			}
+
+			return nil, err
+		}
+
+		metrics.GetOrRegisterCounter(prefix+".responses."+strconv.Itoa(response.StatusCode/100)+"xx", nil).Inc(1)
+
+		if attempt < retries && temporaryStatus(response.StatusCode) {
+			io.Copy(ioutil.Discard, response.Body)
+			response.Body.Close()
+			continue
+		}
+
+		return response, nil
+	}
+}
+
+// idempotent reports whether the request can be repeated without changing
+// its outcome, and has a body that can be sent again.
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "GET", "HEAD", "OPTIONS", "PUT", "DELETE":
+	default:
+		return false
+	}
+
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+func rewind(req *http.Request) (*http.Request, error) {
+	if req.GetBody == nil {
+		return req, nil
+	}
+
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, err
+	}
+
+	retry := req.Clone(req.Context())
+	retry.Body = body
+
+	return retry, nil
+}
+
+func temporaryStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// jitter picks a wait between half of the backoff and all of it.
+func jitter(backoff time.Duration) time.Duration {
+	if backoff <= 0 {
+		return 0
+	}
+
+	half := backoff / 2
+	return half + time.Duration(rand.Int63n(int64(backoff-half)+1))
+}
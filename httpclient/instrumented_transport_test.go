@@ -0,0 +1,92 @@
+package httpclient_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/httpclient"
+	"github.com/rcrowley/go-metrics"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstrumentedTransport", func() {
+	var (
+		server   *httptest.Server
+		requests int32
+		failures int32
+		client   *http.Client
+	)
+
+	BeforeEach(func() {
+		requests = 0
+		failures = 0
+		metrics.DefaultRegistry.UnregisterAll()
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			if atomic.AddInt32(&requests, 1) <= atomic.LoadInt32(&failures) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.WriteHeader(http.StatusOK)
+		}))
+
+		client = &http.Client{
+			Transport: httpclient.InstrumentedTransport{
+				Dependency:   "uaa",
+				Transport:    http.DefaultTransport,
+				Retries:      2,
+				RetryBackoff: time.Millisecond,
+			},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("retries an idempotent request that gets a temporary failure", func() {
+		failures = 2
+
+		response, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusOK))
+		Expect(requests).To(Equal(int32(3)))
+
+		Expect(metrics.GetOrRegisterCounter("notifications.external-requests.uaa.retries", nil).Count()).To(Equal(int64(2)))
+		Expect(metrics.GetOrRegisterCounter("notifications.external-requests.uaa.responses.5xx", nil).Count()).To(Equal(int64(2)))
+		Expect(metrics.GetOrRegisterCounter("notifications.external-requests.uaa.responses.2xx", nil).Count()).To(Equal(int64(1)))
+		Expect(metrics.GetOrRegisterTimer("notifications.external-requests.uaa.requests", nil).Count()).To(Equal(int64(3)))
+	})
+
+	It("returns the last response once the retries run out", func() {
+		failures = 5
+
+		response, err := client.Get(server.URL)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(requests).To(Equal(int32(3)))
+	})
+
+	It("does not retry a request that is not idempotent", func() {
+		failures = 1
+
+		response, err := client.Post(server.URL, "application/x-www-form-urlencoded", strings.NewReader("grant_type=client_credentials"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(response.StatusCode).To(Equal(http.StatusServiceUnavailable))
+		Expect(requests).To(Equal(int32(1)))
+	})
+
+	It("counts requests that fail before a response arrives", func() {
+		server.Close()
+
+		_, err := client.Get(server.URL)
+		Expect(err).To(HaveOccurred())
+		Expect(metrics.GetOrRegisterCounter("notifications.external-requests.uaa.errors", nil).Count()).To(Equal(int64(3)))
+	})
+})
@@ -0,0 +1,99 @@
+package httpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+// ErrNonPublicAddress is returned for requests to URLs supplied by clients of
+// the service that would reach a loopback, private or link-local address.
+var ErrNonPublicAddress = errors.New("address is not public")
+
+// sharedAddressSpace is the carrier-grade NAT range of RFC 6598, which some
+// platforms use for internal services.
+var sharedAddressSpace = &net.IPNet{IP: net.IPv4(100, 64, 0, 0), Mask: net.CIDRMask(10, 32)}
+
+// PublicIP reports whether ip is reachable on the public internet, rather
+// than a loopback, private, link-local or otherwise internal address.
+func PublicIP(ip net.IP) bool {
+	switch {
+	case ip == nil,
+		ip.IsUnspecified(),
+		ip.IsLoopback(),
+		ip.IsPrivate(),
+		ip.IsLinkLocalUnicast(),
+		ip.IsLinkLocalMulticast(),
+		ip.IsInterfaceLocalMulticast(),
+		ip.IsMulticast(),
+		sharedAddressSpace.Contains(ip):
+		return false
+	}
+
+	return true
+}
+
+// CheckPublicHost resolves host and returns ErrNonPublicAddress if any of
+// its addresses is not public. A host that does not resolve is let through,
+// since a client built by NewPublic checks the address again as it connects.
+func CheckPublicHost(ctx context.Context, host string) error {
+	if ip := net.ParseIP(host); ip != nil {
+		if !PublicIP(ip) {
+			return ErrNonPublicAddress
+		}
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if !PublicIP(addr.IP) {
+			return ErrNonPublicAddress
+		}
+	}
+
+	return nil
+}
+
+// NewPublic returns a client for the URLs that clients of the service
+// supply, such as webhooks. It only connects to public addresses, checking
+// each address as it dials so that a name which later resolves elsewhere
+// cannot reach the internal network, and it always verifies certificates.
+func NewPublic(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   10 * time.Second,
+		KeepAlive: 30 * time.Second,
+		Control:   dialPublic,
+	}
+
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSClientConfig:     &tls.Config{MinVersion: tls.VersionTLS12},
+			TLSHandshakeTimeout: 10 * time.Second,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func dialPublic(network, address string, conn syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	if !PublicIP(net.ParseIP(host)) {
+		return fmt.Errorf("dial %s: %w", address, ErrNonPublicAddress)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package httpclient_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/httpclient"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PublicIP", func() {
+	It("accepts public addresses", func() {
+		Expect(httpclient.PublicIP(net.ParseIP("93.184.216.34"))).To(BeTrue())
+		Expect(httpclient.PublicIP(net.ParseIP("2606:2800:220:1:248:1893:25c8:1946"))).To(BeTrue())
+	})
+
+	It("refuses loopback, private, link-local and shared addresses", func() {
+		for _, address := range []string{
+			"127.0.0.1",
+			"::1",
+			"::ffff:127.0.0.1",
+			"10.1.2.3",
+			"172.16.0.1",
+			"192.168.1.1",
+			"fd00::1",
+			"169.254.169.254",
+			"fe80::1",
+			"100.100.100.200",
+			"0.0.0.0",
+			"224.0.0.1",
+		} {
+			Expect(httpclient.PublicIP(net.ParseIP(address))).To(BeFalse(), address)
+		}
+	})
+})
+
+var _ = Describe("CheckPublicHost", func() {
+	It("refuses addresses and names that are not public", func() {
+		Expect(httpclient.CheckPublicHost(context.Background(), "169.254.169.254")).To(MatchError(httpclient.ErrNonPublicAddress))
+		Expect(httpclient.CheckPublicHost(context.Background(), "localhost")).To(MatchError(httpclient.ErrNonPublicAddress))
+	})
+
+	It("accepts public addresses", func() {
+		Expect(httpclient.CheckPublicHost(context.Background(), "93.184.216.34")).To(Succeed())
+	})
+})
+
+var _ = Describe("NewPublic", func() {
+	It("does not connect to internal addresses", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+
+		_, err := httpclient.NewPublic(time.Second).Get(server.URL)
+		Expect(err).To(MatchError(ContainSubstring("address is not public")))
+	})
+
+	It("verifies certificates", func() {
+		client := httpclient.NewPublic(time.Second)
+		Expect(client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify).To(BeFalse())
+	})
+})
@@ -0,0 +1,93 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"time"
+)
+
+const (
+	DefaultTimeout             = 30 * time.Second
+	DefaultMaxIdleConnsPerHost = 32
+	DefaultRetries             = 2
+	DefaultRetryBackoff        = 100 * time.Millisecond
+)
+
+type Config struct {
+	SkipVerifySSL bool
+
+	// Timeout bounds the wait for the response to each attempt. A request,
+	// with its retries, may take as long as its attempts together.
+	Timeout time.Duration
+
+	// MaxIdleConnsPerHost is the number of connections to each dependency
+	// kept open between requests.
+	MaxIdleConnsPerHost int
+
+	// Retries is the number of times an idempotent request is retried after
+	// a network error or a 502, 503 or 504 response. RetryBackoff is the
+	// wait before the first retry; it doubles for each one after that.
+	Retries      int
+	RetryBackoff time.Duration
+}
+
+// Shared hands out clients for the services notifications depends on, such
+// as the UAA and the Cloud Controller. The clients share one pool of
+// connections, and each one reports metrics under the name of its
+// dependency. The zero value uses http.DefaultTransport and does not retry.
+type Shared struct {
+	transport http.RoundTripper
+	config    Config
+}
+
+func NewShared(config Config) Shared {
+	if config.Timeout == 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	if config.MaxIdleConnsPerHost == 0 {
+		config.MaxIdleConnsPerHost = DefaultMaxIdleConnsPerHost
+	}
+
+	if config.RetryBackoff == 0 {
+		config.RetryBackoff = DefaultRetryBackoff
+	}
+
+	return Shared{
+		transport: &http.Transport{
+			Proxy: http.ProxyFromEnvironment,
+			DialContext: (&net.Dialer{
+				Timeout:   10 * time.Second,
+				KeepAlive: 30 * time.Second,
+			}).DialContext,
+			TLSClientConfig:       &tls.Config{InsecureSkipVerify: config.SkipVerifySSL},
+			TLSHandshakeTimeout:   10 * time.Second,
+			MaxIdleConns:          config.MaxIdleConnsPerHost * 4,
+			MaxIdleConnsPerHost:   config.MaxIdleConnsPerHost,
+			IdleConnTimeout:       90 * time.Second,
+			ResponseHeaderTimeout: config.Timeout,
+			ExpectContinueTimeout: 1 * time.Second,
+		},
+		config: config,
+	}
+}
+
+// Client returns a client for the named dependency. Its requests are timed
+// and counted under notifications.external-requests.<dependency>.
+func (s Shared) Client(dependency string) *http.Client {
+	transport := s.transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+
+	return &http.Client{
+		Timeout: s.config.Timeout * time.Duration(s.config.Retries+1),
+		Transport: InstrumentedTransport{
+			Dependency:   dependency,
+			Transport:    transport,
+			Retries:      s.config.Retries,
+			RetryBackoff: s.config.RetryBackoff,
+		},
+	}
+}
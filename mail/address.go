@@ -0,0 +1,31 @@
+package mail
+
+import netmail "net/mail"
+
+// FormatAddress returns address as it belongs in a header, with a non-ASCII
+// display name encoded as an RFC 2047 encoded-word. Addresses that cannot be
+// parsed, and bare addresses, are returned unchanged.
+func FormatAddress(address string) string {
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil || parsed.Name == "" {
+		return address
+	}
+
+	return parsed.String()
+}
+
+// EnvelopeAddress returns the bare address of a "Display Name <address>"
+// value, as the SMTP envelope requires.
+func EnvelopeAddress(address string) string {
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil {
+		return address
+	}
+
+	return parsed.Address
+}
+
+// WithDisplayName replaces the display name of address with name.
+func WithDisplayName(address, name string) string {
+	return (&netmail.Address{Name: name, Address: EnvelopeAddress(address)}).String()
+}
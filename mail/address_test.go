@@ -0,0 +1,43 @@
+package mail_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/mail"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Addresses", func() {
+	Describe("FormatAddress", func() {
+		It("leaves bare addresses alone", func() {
+			Expect(mail.FormatAddress("no-reply@example.com")).To(Equal("no-reply@example.com"))
+		})
+
+		It("quotes ASCII display names as needed", func() {
+			Expect(mail.FormatAddress("Cloud Foundry <no-reply@example.com>")).To(Equal(`"Cloud Foundry" <no-reply@example.com>`))
+		})
+
+		It("encodes non-ASCII display names", func() {
+			Expect(mail.FormatAddress("Jürgen Müller <no-reply@example.com>")).To(Equal("=?utf-8?q?J=C3=BCrgen_M=C3=BCller?= <no-reply@example.com>"))
+		})
+
+		It("does not encode an encoded display name twice", func() {
+			formatted := mail.FormatAddress("Jürgen <no-reply@example.com>")
+			Expect(mail.FormatAddress(formatted)).To(Equal(formatted))
+		})
+	})
+
+	Describe("EnvelopeAddress", func() {
+		It("returns the bare address", func() {
+			Expect(mail.EnvelopeAddress("Cloud Foundry <no-reply@example.com>")).To(Equal("no-reply@example.com"))
+			Expect(mail.EnvelopeAddress("no-reply@example.com")).To(Equal("no-reply@example.com"))
+		})
+	})
+
+	Describe("WithDisplayName", func() {
+		It("replaces the display name", func() {
+			Expect(mail.WithDisplayName("Cloud Foundry <no-reply@example.com>", "Büro")).To(Equal("=?utf-8?q?B=C3=BCro?= <no-reply@example.com>"))
+			Expect(mail.WithDisplayName("no-reply@example.com", "Jurassic Park")).To(Equal(`"Jurassic Park" <no-reply@example.com>`))
+		})
+	})
+})
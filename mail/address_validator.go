@@ -0,0 +1,176 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// Length limits of RFC 5321 section 4.5.3.1, in octets.
+const (
+	maxLocalPartLength = 64
+	maxDomainLength    = 255
+	maxLabelLength     = 63
+	maxAddressLength   = 254
+)
+
+// AddressResolver looks up where mail for a domain goes. *net.Resolver
+// satisfies it.
+type AddressResolver interface {
+	Resolver
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+type InvalidAddressError struct {
+	Address string
+	Reason  string
+}
+
+func (e InvalidAddressError) Error() string {
+	return fmt.Sprintf("invalid email address %q: %s", e.Address, e.Reason)
+}
+
+// AddressValidator checks that recipient addresses can be delivered to
+// before anything is sent to them.
+type AddressValidator struct {
+	resolver AddressResolver
+}
+
+// NewAddressValidator returns a validator that only checks the syntax of
+// addresses when resolver is nil, and otherwise also checks that their
+// domains accept mail.
+func NewAddressValidator(resolver AddressResolver) AddressValidator {
+	return AddressValidator{
+		resolver: resolver,
+	}
+}
+
+// Validate returns the normalized form of address, or an
+// InvalidAddressError when it cannot be delivered to. Lookups that fail for
+// any reason other than the domain not existing do not make an address
+// invalid, so that a DNS outage does not drop mail.
+func (v AddressValidator) Validate(address string) (string, error) {
+	normalized, err := NormalizeAddress(address)
+	if err != nil || v.resolver == nil {
+		return normalized, err
+	}
+
+	domain := normalized[strings.LastIndex(normalized, "@")+1:]
+	if strings.HasPrefix(domain, "[") {
+		return normalized, nil
+	}
+
+	records, err := v.resolver.LookupMX(context.Background(), domain)
+	switch {
+	case err == nil:
+		if len(records) == 1 && strings.TrimSuffix(records[0].Host, ".") == "" {
+			return "", InvalidAddressError{Address: address, Reason: fmt.Sprintf("domain %q does not accept mail", domain)}
+		}
+	case isNotFound(err):
+		// A domain without MX records receives mail at its own address
+		// (RFC 5321 section 5.1).
+		_, err = v.resolver.LookupHost(context.Background(), domain)
+		if isNotFound(err) {
+			return "", InvalidAddressError{Address: address, Reason: fmt.Sprintf("domain %q does not exist", domain)}
+		}
+	}
+
+	return normalized, nil
+}
+
+// NormalizeAddress parses an RFC 5322 address, which may have a display
+// name, and returns its addr-spec with the domain lowercased and converted
+// to its ASCII (IDNA) form. Addresses that do not parse, or that break the
+// length limits of RFC 5321, return an InvalidAddressError.
+func NormalizeAddress(address string) (string, error) {
+	invalid := func(format string, args ...interface{}) (string, error) {
+		return "", InvalidAddressError{Address: address, Reason: fmt.Sprintf(format, args...)}
+	}
+
+	parsed, err := netmail.ParseAddress(strings.TrimSpace(address))
+	if err != nil {
+		return invalid("%s", strings.TrimPrefix(err.Error(), "mail: "))
+	}
+
+	at := strings.LastIndex(parsed.Address, "@")
+	local, domain := quoteLocalPart(parsed.Address[:at]), parsed.Address[at+1:]
+
+	if len(local) > maxLocalPartLength {
+		return invalid("the local part is longer than %d octets", maxLocalPartLength)
+	}
+
+	if !strings.HasPrefix(domain, "[") {
+		domain, err = asciiDomain(domain)
+		if err != nil {
+			return invalid("%s", err)
+		}
+	}
+
+	normalized := local + "@" + domain
+	if len(normalized) > maxAddressLength {
+		return invalid("the address is longer than %d octets", maxAddressLength)
+	}
+
+	return normalized, nil
+}
+
+// quoteLocalPart returns local as it has to be written in an address: as
+// it is when it is a dot-atom, and otherwise as a quoted string. net/mail
+// removes the quotes when it parses an address.
+func quoteLocalPart(local string) string {
+	dotAtom := local != "" && !strings.HasPrefix(local, ".") && !strings.HasSuffix(local, ".") && !strings.Contains(local, "..")
+	for _, c := range local {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c >= utf8.RuneSelf || strings.ContainsRune("!#$%&'*+-/=?^_`{|}~", c)) {
+			dotAtom = false
+		}
+	}
+
+	if dotAtom {
+		return local
+	}
+
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(local) + `"`
+}
+
+// asciiDomain lowercases domain and encodes each of its non-ASCII labels as
+// punycode, then checks that every label is a valid host name label.
+func asciiDomain(domain string) (string, error) {
+	labels := strings.Split(strings.ToLower(domain), ".")
+	for i, label := range labels {
+		if utf8.RuneCountInString(label) != len(label) {
+			label = "xn--" + punycode(label)
+			labels[i] = label
+		}
+
+		switch {
+		case label == "":
+			return "", errors.New("the domain has an empty label")
+		case len(label) > maxLabelLength:
+			return "", fmt.Errorf("the domain has a label longer than %d octets", maxLabelLength)
+		case label[0] == '-' || label[len(label)-1] == '-':
+			return "", fmt.Errorf("the domain label %q starts or ends with a hyphen", label)
+		}
+
+		for _, c := range label {
+			if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '-') {
+				return "", fmt.Errorf("the domain label %q has a character other than a letter, digit or hyphen", label)
+			}
+		}
+	}
+
+	ascii := strings.Join(labels, ".")
+	if len(ascii) > maxDomainLength {
+		return "", fmt.Errorf("the domain is longer than %d octets", maxDomainLength)
+	}
+
+	return ascii, nil
+}
+
+func isNotFound(err error) bool {
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr) && dnsErr.IsNotFound
+}
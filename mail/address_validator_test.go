@@ -0,0 +1,121 @@
+package mail_test
+
+import (
+	"errors"
+	"net"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AddressValidator", func() {
+	Describe("NormalizeAddress", func() {
+		DescribeTable("normalizes addresses",
+			func(address, normalized string) {
+				result, err := mail.NormalizeAddress(address)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result).To(Equal(normalized))
+			},
+			Entry("a bare address", "user@example.com", "user@example.com"),
+			Entry("surrounding whitespace", "  user@example.com ", "user@example.com"),
+			Entry("a display name", "Some User <user@example.com>", "user@example.com"),
+			Entry("an uppercase domain, keeping the case of the local part", "First.Last@Example.COM", "First.Last@example.com"),
+			Entry("a quoted local part", `"first last"@example.com`, `"first last"@example.com`),
+			Entry("a needlessly quoted local part", `"user"@example.com`, "user@example.com"),
+			Entry("an internationalized domain", "user@bücher.example", "user@xn--bcher-kva.example"),
+			Entry("an uppercase internationalized domain", "user@MÜNCHEN.de", "user@xn--mnchen-3ya.de"),
+			Entry("a domain with no ASCII characters", "user@例え.jp", "user@xn--r8jz45g.jp"),
+			Entry("a domain literal", "user@[192.0.2.1]", "user@[192.0.2.1]"),
+		)
+
+		DescribeTable("rejects addresses that cannot be delivered to",
+			func(address, reason string) {
+				_, err := mail.NormalizeAddress(address)
+				Expect(err).To(BeAssignableToTypeOf(mail.InvalidAddressError{}))
+				Expect(err.Error()).To(ContainSubstring(reason))
+			},
+			Entry("no at sign", "user.example.com", "missing '@'"),
+			Entry("no domain", "user@", "missing '@'"),
+			Entry("two at signs", "user@host@example.com", "expected single address"),
+			Entry("an empty label", "user@example..com", "missing '@'"),
+			Entry("a label starting with a hyphen", "user@-example.com", "starts or ends with a hyphen"),
+			Entry("an underscore in the domain", "user@exa_mple.com", "other than a letter, digit or hyphen"),
+			Entry("a long local part", strings.Repeat("a", 65)+"@example.com", "local part is longer than 64 octets"),
+			Entry("a long label", "user@"+strings.Repeat("a", 64)+".com", "label longer than 63 octets"),
+			Entry("a long address", strings.Repeat("a", 64)+"@"+strings.Repeat(strings.Repeat("a", 62)+".", 3)+"com", "address is longer than 254 octets"),
+		)
+	})
+
+	Describe("Validate", func() {
+		var (
+			resolver  *mocks.MXResolver
+			validator mail.AddressValidator
+		)
+
+		BeforeEach(func() {
+			resolver = &mocks.MXResolver{}
+			resolver.LookupMXCall.Returns.Records = []*net.MX{{Host: "mx.example.com.", Pref: 10}}
+			validator = mail.NewAddressValidator(resolver)
+		})
+
+		It("returns the normalized address of a domain with mail exchangers", func() {
+			address, err := validator.Validate("User <user@Example.com>")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal("user@example.com"))
+			Expect(resolver.LookupMXCall.Receives.Name).To(Equal("example.com"))
+		})
+
+		It("only checks the syntax without a resolver", func() {
+			address, err := mail.NewAddressValidator(nil).Validate("user@Example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal("user@example.com"))
+
+			_, err = mail.NewAddressValidator(nil).Validate("user")
+			Expect(err).To(BeAssignableToTypeOf(mail.InvalidAddressError{}))
+		})
+
+		It("rejects a domain with a null MX record", func() {
+			resolver.LookupMXCall.Returns.Records = []*net.MX{{Host: ".", Pref: 0}}
+
+			_, err := validator.Validate("user@example.com")
+			Expect(err).To(MatchError(mail.InvalidAddressError{Address: "user@example.com", Reason: `domain "example.com" does not accept mail`}))
+		})
+
+		It("accepts a domain without MX records that has an address", func() {
+			resolver.LookupMXCall.Returns.Error = &net.DNSError{Err: "no such host", Name: "example.com", IsNotFound: true}
+			resolver.LookupHostCall.Returns.Addresses = []string{"192.0.2.1"}
+
+			address, err := validator.Validate("user@example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal("user@example.com"))
+			Expect(resolver.LookupHostCall.Receives.Host).To(Equal("example.com"))
+		})
+
+		It("rejects a domain that does not exist", func() {
+			resolver.LookupMXCall.Returns.Error = &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}
+			resolver.LookupHostCall.Returns.Error = &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}
+
+			_, err := validator.Validate("user@example.invalid")
+			Expect(err).To(MatchError(mail.InvalidAddressError{Address: "user@example.invalid", Reason: `domain "example.invalid" does not exist`}))
+		})
+
+		It("accepts the address when the lookup fails", func() {
+			resolver.LookupMXCall.Returns.Error = errors.New("server misbehaving")
+
+			address, err := validator.Validate("user@example.com")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(address).To(Equal("user@example.com"))
+			Expect(resolver.LookupHostCall.CallCount).To(Equal(0))
+		})
+
+		It("does not look up domain literals", func() {
+			_, err := validator.Validate("user@[192.0.2.1]")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resolver.LookupMXCall.Receives.Name).To(BeEmpty())
+		})
+	})
+})
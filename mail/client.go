@@ -2,6 +2,7 @@ package mail
 
 import (
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -26,6 +27,7 @@ type AuthMechanism int
 type Client struct {
 	config Config
 	client *smtp.Client
+	host   string
 }
 
 type Config struct {
@@ -40,6 +42,39 @@ type Config struct {
 	DisableTLS        bool
 	ConnectTimeout    time.Duration
 	LoggingEnabled    bool
+	MXLookup          bool
+	Resolver          Resolver
+	DomainPolicies    map[string]DomainPolicy
+
+	// HelloHostname is the name sent with EHLO/HELO. Relays that match it
+	// against reverse DNS reject the "localhost" default.
+	HelloHostname string
+
+	// LocalAddress is the local IP outgoing SMTP connections are made from.
+	LocalAddress string
+
+	// ClientCertificates are presented to servers that request a client
+	// certificate during STARTTLS.
+	ClientCertificates []tls.Certificate
+
+	// RequireTLS fails deliveries to servers that do not offer STARTTLS
+	// instead of sending them in plaintext.
+	RequireTLS bool
+
+	// MinTLSVersion, RootCAs and PinnedPublicKeys restrict which STARTTLS
+	// connections are accepted. PinnedPublicKeys holds PublicKeyPin values;
+	// when set, the server certificate must match one of them.
+	MinTLSVersion    uint16
+	RootCAs          *x509.CertPool
+	PinnedPublicKeys []string
+
+	// DKIM signs each message before it is submitted, when set.
+	DKIM *DKIMSigner
+
+	// DSNNotify asks servers that offer the DSN extension to send delivery
+	// status notifications with these NOTIFY keywords, quoting the
+	// EnvelopeID of the message. Other servers are sent the message as usual.
+	DSNNotify []string
 }
 
 type connection struct {
@@ -54,6 +89,14 @@ func NewClient(config Config) *Client {
 		client.config.ConnectTimeout = 15 * time.Second
 	}
 
+	if client.config.HelloHostname == "" {
+		client.config.HelloHostname = "localhost"
+	}
+
+	if client.config.Resolver == nil {
+		client.config.Resolver = net.DefaultResolver
+	}
+
 	return client
 }
 
@@ -79,14 +122,24 @@ func (c *Client) Connect(logger lager.Logger) error {
 		return nil
 	}
 
+	if c.config.MXLookup {
+		c.PrintLog(logger, "mx-lookup-connects-on-send")
+		return nil
+	}
+
+	return c.connectTo(logger, c.config.Host, c.config.Port)
+}
+
+func (c *Client) connectTo(logger lager.Logger, host, port string) error {
 	select {
-	case connection := <-c.connect():
+	case connection := <-c.connect(net.JoinHostPort(host, port)):
 		c.PrintLog(logger, "connected")
 		if connection.err != nil {
 			return connection.err
 		}
 
 		c.client = connection.client
+		c.host = host
 	case <-time.After(c.config.ConnectTimeout):
 		c.PrintLog(logger, "connection-timeout", lager.Data{"timeout-duration": c.config.ConnectTimeout})
 		return errors.New("server timeout")
@@ -95,11 +148,11 @@ func (c *Client) Connect(logger lager.Logger) error {
 	return nil
 }
 
-func (c *Client) connect() chan connection {
+func (c *Client) connect(address string) chan connection {
 	channel := make(chan connection)
 
 	go func() {
-		client, err := smtp.Dial(net.JoinHostPort(c.config.Host, c.config.Port))
+		client, err := c.dial(address)
 		channel <- connection{
 			client: client,
 			err:    err,
@@ -109,6 +162,31 @@ func (c *Client) connect() chan connection {
 	return channel
 }
 
+func (c *Client) dial(address string) (*smtp.Client, error) {
+	dialer := net.Dialer{}
+	if c.config.LocalAddress != "" {
+		ip := net.ParseIP(c.config.LocalAddress)
+		if ip == nil {
+			return nil, fmt.Errorf("invalid local address %q", c.config.LocalAddress)
+		}
+		dialer.LocalAddr = &net.TCPAddr{IP: ip}
+	}
+
+	conn, err := dialer.Dial("tcp", address)
+	if err != nil {
+		return nil, err
+	}
+
+	host, _, _ := net.SplitHostPort(address)
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
 func (c *Client) Send(msg Message, logger lager.Logger) error {
 	logger = c.createLoggerSession(logger)
 
@@ -117,6 +195,10 @@ func (c *Client) Send(msg Message, logger lager.Logger) error {
 		return nil
 	}
 
+	if c.config.MXLookup {
+		return c.sendDirect(msg, logger)
+	}
+
 	err := c.Connect(logger)
 	if err != nil {
 		return c.Error(logger, err)
@@ -145,22 +227,27 @@ func (c *Client) Send(msg Message, logger lager.Logger) error {
 		c.PrintLog(logger, "authenticated")
 	}
 
-	c.PrintLog(logger, "setting-msg-from", lager.Data{"from": msg.From})
-	err = c.client.Mail(msg.From)
+	return c.transmit(msg, logger)
+}
+
+func (c *Client) transmit(msg Message, logger lager.Logger) error {
+	from := EnvelopeAddress(msg.From)
+	c.PrintLog(logger, "setting-msg-from", lager.Data{"from": from})
+	err := c.mail(from, msg.EnvelopeID)
 	if err != nil {
-		return c.Error(logger, err)
+		return c.Error(logger, RejectedError{Err: err})
 	}
 
 	c.PrintLog(logger, "setting-msg-to", lager.Data{"to": msg.To})
-	err = c.client.Rcpt(msg.To)
+	err = c.rcpt(msg.To)
 	if err != nil {
-		return c.Error(logger, err)
+		return c.Error(logger, RejectedError{Err: err})
 	}
 
 	c.PrintLog(logger, "setting-msg-data", lager.Data{"message-data": base64.StdEncoding.EncodeToString([]byte(msg.Data()))})
 	err = c.Data(msg)
 	if err != nil {
-		return c.Error(logger, err)
+		return c.Error(logger, RejectedError{Err: err})
 	}
 	c.PrintLog(logger, "msg-data-sent")
 
@@ -174,8 +261,65 @@ func (c *Client) Send(msg Message, logger lager.Logger) error {
 	return nil
 }
 
+// requestsDSN says whether delivery status notifications are asked for on
+// the current connection.
+func (c *Client) requestsDSN() bool {
+	if len(c.config.DSNNotify) == 0 {
+		return false
+	}
+
+	ok, _ := c.Extension("DSN")
+	return ok
+}
+
+// mail starts the message, with the RFC 3461 RET and ENVID parameters when
+// delivery status notifications are asked for. Only the headers of the
+// message are to be returned with them.
+func (c *Client) mail(from, envelopeID string) error {
+	if !c.requestsDSN() {
+		return c.client.Mail(from)
+	}
+
+	command := "MAIL FROM:<" + from + ">"
+	if ok, _ := c.Extension("8BITMIME"); ok {
+		command += " BODY=8BITMIME"
+	}
+	if ok, _ := c.Extension("SMTPUTF8"); ok {
+		command += " SMTPUTF8"
+	}
+	command += " RET=HDRS"
+	if envelopeID != "" {
+		command += " ENVID=" + encodeXText(envelopeID)
+	}
+
+	return c.command(250, command)
+}
+
+func (c *Client) rcpt(to string) error {
+	if !c.requestsDSN() {
+		return c.client.Rcpt(to)
+	}
+
+	return c.command(25, "RCPT TO:<"+to+"> NOTIFY="+strings.Join(c.config.DSNNotify, ",")+" ORCPT=rfc822;"+encodeXText(to))
+}
+
+// command sends a command that net/smtp has no parameters for, and reads
+// its reply like net/smtp does.
+func (c *Client) command(expectCode int, command string) error {
+	id, err := c.client.Text.Cmd("%s", command)
+	if err != nil {
+		return err
+	}
+
+	c.client.Text.StartResponse(id)
+	defer c.client.Text.EndResponse(id)
+
+	_, _, err = c.client.Text.ReadResponse(expectCode)
+	return err
+}
+
 func (c *Client) Hello() error {
-	err := c.client.Hello("localhost")
+	err := c.client.Hello(c.config.HelloHostname)
 	if err != nil {
 		return err
 	}
@@ -189,13 +333,12 @@ func (c *Client) Extension(name string) (bool, string) {
 
 func (c *Client) StartTLS() error {
 	if ok, _ := c.Extension("STARTTLS"); ok {
-		err := c.client.StartTLS(&tls.Config{
-			ServerName:         c.config.Host,
-			InsecureSkipVerify: c.config.SkipVerifySSL,
-		})
+		err := c.client.StartTLS(c.tlsConfig())
 		if err != nil {
-			return err
+			return TLSPolicyError{Err: err}
 		}
+	} else if c.config.RequireTLS {
+		return TLSPolicyError{Err: errors.New("server does not offer STARTTLS")}
 	}
 
 	return nil
@@ -229,12 +372,21 @@ func (c *Client) AuthMechanism(logger lager.Logger) smtp.Auth {
 }
 
 func (c *Client) Data(msg Message) error {
+	data := msg.Data()
+	if c.config.DKIM != nil {
+		var err error
+		data, err = c.config.DKIM.Sign(data)
+		if err != nil {
+			return err
+		}
+	}
+
 	wc, err := c.client.Data()
 	if err != nil {
 		return err
 	}
 
-	data := strings.Replace(string(msg.Data()), "%", "%%", -1)
+	data = strings.Replace(data, "%", "%%", -1)
 	_, err = fmt.Fprintf(wc, data)
 	if err != nil {
 		return err
@@ -260,9 +412,15 @@ func (c *Client) Quit() error {
 
 func (c *Client) Error(logger lager.Logger, err error) error {
 	if c.client != nil {
+		client := c.client
 		failure := c.Quit()
 		if failure != nil {
-			return failure
+			// A failed TLS handshake leaves nothing to quit cleanly; the
+			// policy failure is the error worth reporting.
+			if _, ok := err.(TLSPolicyError); !ok {
+				return failure
+			}
+			client.Close()
 		}
 	}
 
@@ -2,10 +2,16 @@ package mail_test
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"net"
 	"net/smtp"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -261,6 +267,72 @@ var _ = Describe("Mail", func() {
 			Expect(delivery.Data).To(Equal(strings.Split(secondMsg.Data(), "\n")))
 		})
 
+		It("reports a recipient the server refuses as a rejection with its reply", func() {
+			mailServer.RejectsRcpt = "550 5.1.1 no such user"
+
+			err := client.Send(mail.Message{
+				From:    "me@example.com",
+				To:      "nobody@example.com",
+				Subject: "Urgent! Read now!",
+				Body:    []mail.Part{{ContentType: "text/plain", Content: "Hello"}},
+			}, logger)
+
+			var rejectedErr mail.RejectedError
+			Expect(errors.As(err, &rejectedErr)).To(BeTrue())
+
+			var reply *textproto.Error
+			Expect(errors.As(err, &reply)).To(BeTrue())
+			Expect(reply.Code).To(Equal(550))
+			Expect(reply.Msg).To(Equal("5.1.1 no such user"))
+		})
+
+		Context("when delivery status notifications are requested", func() {
+			BeforeEach(func() {
+				config.DSNNotify = []string{mail.DSNNotifySuccess, mail.DSNNotifyFailure}
+				client = mail.NewClient(config)
+			})
+
+			It("passes the envelope ID and the NOTIFY keywords to a server that offers DSN", func() {
+				mailServer.SupportsDSN = true
+
+				err := client.Send(mail.Message{
+					From:       "me@example.com",
+					To:         "you@example.com",
+					Subject:    "Urgent! Read now!",
+					Body:       []mail.Part{{ContentType: "text/plain", Content: "Hello"}},
+					EnvelopeID: "message-id+1",
+				}, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				delivery := mailServer.Deliveries[0]
+
+				Expect(delivery.Sender).To(Equal("me@example.com"))
+				Expect(delivery.MailParams).To(Equal("RET=HDRS ENVID=message-id+2B1"))
+				Expect(delivery.Recipient).To(Equal("you@example.com"))
+				Expect(delivery.RcptParams).To(Equal("NOTIFY=SUCCESS,FAILURE ORCPT=rfc822;you@example.com"))
+			})
+
+			It("sends the message as usual to a server that does not", func() {
+				err := client.Send(mail.Message{
+					From:       "me@example.com",
+					To:         "you@example.com",
+					Subject:    "Urgent! Read now!",
+					Body:       []mail.Part{{ContentType: "text/plain", Content: "Hello"}},
+					EnvelopeID: "message-id",
+				}, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				Expect(mailServer.Deliveries[0].MailParams).To(BeEmpty())
+				Expect(mailServer.Deliveries[0].RcptParams).To(BeEmpty())
+			})
+		})
+
 		Context("when configured to use TLS", func() {
 			BeforeEach(func() {
 				config.SkipVerifySSL = true
@@ -291,6 +363,133 @@ var _ = Describe("Mail", func() {
 
 				delivery := mailServer.Deliveries[0]
 				Expect(delivery.UsedTLS).To(BeTrue())
+				Expect(delivery.ClientCertificate).To(BeNil())
+			})
+
+			It("presents the configured client certificate", func() {
+				cert, err := tls.X509KeyPair([]byte(certPEM), []byte(keyPEM))
+				Expect(err).NotTo(HaveOccurred())
+
+				config.ClientCertificates = []tls.Certificate{cert}
+				client = mail.NewClient(config)
+
+				err = client.Send(mail.Message{From: "me@example.com", To: "you@example.com"}, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+
+				delivery := mailServer.Deliveries[0]
+				Expect(delivery.ClientCertificate).NotTo(BeNil())
+				Expect(delivery.ClientCertificate.Subject.CommonName).To(Equal("development"))
+			})
+		})
+
+		Describe("TLS policy", func() {
+			var msg mail.Message
+
+			BeforeEach(func() {
+				msg = mail.Message{From: "me@example.com", To: "you@example.com"}
+			})
+
+			It("fails instead of sending plaintext when TLS is required but not offered", func() {
+				mailServer.SupportsTLS = false
+				config.RequireTLS = true
+				client = mail.NewClient(config)
+
+				err := client.Send(msg, logger)
+				Expect(err).To(MatchError(mail.TLSPolicyError{Err: errors.New("server does not offer STARTTLS")}))
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				Expect(mailServer.Deliveries[0].Sender).To(BeEmpty())
+			})
+
+			It("fails when the server cannot meet the minimum TLS version", func() {
+				mailServer.MaxTLSVersion = tls.VersionTLS12
+				config.MinTLSVersion = tls.VersionTLS13
+				client = mail.NewClient(config)
+
+				err := client.Send(msg, logger)
+				Expect(err).To(BeAssignableToTypeOf(mail.TLSPolicyError{}))
+			})
+
+			It("delivers when the server certificate matches a pinned public key", func() {
+				block, _ := pem.Decode([]byte(certPEM))
+				certificate, err := x509.ParseCertificate(block.Bytes)
+				Expect(err).NotTo(HaveOccurred())
+
+				config.PinnedPublicKeys = []string{"some-other-pin", mail.PublicKeyPin(certificate)}
+				client = mail.NewClient(config)
+
+				Expect(client.Send(msg, logger)).To(Succeed())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				Expect(mailServer.Deliveries[0].Recipient).To(Equal("you@example.com"))
+			})
+
+			It("fails when the server certificate does not match a pinned public key", func() {
+				config.PinnedPublicKeys = []string{"some-other-pin"}
+				client = mail.NewClient(config)
+
+				err := client.Send(msg, logger)
+				Expect(err).To(BeAssignableToTypeOf(mail.TLSPolicyError{}))
+				Expect(err.Error()).To(ContainSubstring("is not pinned"))
+			})
+		})
+
+		It("signs mail when DKIM is configured", func() {
+			key, err := rsa.GenerateKey(rand.Reader, 1024)
+			Expect(err).NotTo(HaveOccurred())
+
+			config.DKIM, err = mail.NewDKIMSigner("example.com", "mail", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+			Expect(err).NotTo(HaveOccurred())
+			client = mail.NewClient(config)
+
+			err = client.Send(mail.Message{From: "me@example.com", To: "you@example.com", Subject: "Signed"}, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Eventually(func() int {
+				return len(mailServer.Deliveries)
+			}).Should(Equal(1))
+			Expect(mailServer.Deliveries[0].Data[0]).To(HavePrefix("DKIM-Signature: v=1; a=rsa-sha256; c=relaxed/relaxed; d=example.com; s=mail;"))
+		})
+
+		Describe("client identity", func() {
+			It("says hello as localhost by default", func() {
+				err := client.Send(mail.Message{From: "me@example.com", To: "you@example.com"}, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				Expect(mailServer.Deliveries[0].HelloName).To(Equal("localhost"))
+			})
+
+			It("says hello with the configured hostname from the configured local address", func() {
+				config.HelloHostname = "notifications.example.com"
+				config.LocalAddress = "127.0.0.1"
+				client = mail.NewClient(config)
+
+				err := client.Send(mail.Message{From: "me@example.com", To: "you@example.com"}, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Eventually(func() int {
+					return len(mailServer.Deliveries)
+				}).Should(Equal(1))
+				Expect(mailServer.Deliveries[0].HelloName).To(Equal("notifications.example.com"))
+			})
+
+			It("returns an error when the local address is not an IP", func() {
+				config.LocalAddress = "banana"
+				client = mail.NewClient(config)
+
+				err := client.Send(mail.Message{From: "me@example.com", To: "you@example.com"}, logger)
+				Expect(err).To(MatchError(`invalid local address "banana"`))
 			})
 		})
 
@@ -0,0 +1,213 @@
+package mail
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// DKIMSignedHeaders are the header fields covered by a DKIM signature when
+// the message has them. From must always be signed.
+var DKIMSignedHeaders = []string{
+	"From",
+	"Reply-To",
+	"To",
+	"Subject",
+	"Date",
+	"Mime-Version",
+	"Content-Type",
+	"Content-Transfer-Encoding",
+	"List-Unsubscribe",
+	"List-Unsubscribe-Post",
+}
+
+// DKIMSigner adds a DKIM-Signature header to messages, so that mail passes
+// DMARC even when it is relayed by a server that does not sign it. It signs
+// with rsa-sha256 and relaxed canonicalization of both header and body,
+// which survives the rewrapping relays commonly do.
+type DKIMSigner struct {
+	Domain   string
+	Selector string
+	key      *rsa.PrivateKey
+}
+
+// NewDKIMSigner reads a PEM encoded RSA private key, in either PKCS #1 or
+// PKCS #8 form. The public half must be published in DNS at
+// <selector>._domainkey.<domain>.
+func NewDKIMSigner(domain, selector string, privateKey []byte) (*DKIMSigner, error) {
+	if domain == "" || selector == "" {
+		return nil, errors.New("a DKIM domain and selector are required")
+	}
+
+	block, _ := pem.Decode(privateKey)
+	if block == nil {
+		return nil, errors.New("the DKIM private key is not PEM encoded")
+	}
+
+	var key *rsa.PrivateKey
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		parsed, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		key = parsed
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		rsaKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, errors.New("the DKIM private key is not an RSA key")
+		}
+		key = rsaKey
+	default:
+		return nil, fmt.Errorf("the DKIM private key is a %q block, not an RSA private key", block.Type)
+	}
+
+	if key.N.BitLen() < 1024 {
+		return nil, fmt.Errorf("the DKIM private key has %d bits, receivers require at least 1024", key.N.BitLen())
+	}
+
+	return &DKIMSigner{
+		Domain:   strings.ToLower(domain),
+		Selector: selector,
+		key:      key,
+	}, nil
+}
+
+// Sign returns the message with a DKIM-Signature header added in front of
+// it. Line endings are normalized to CRLF, as they are on the wire, so that
+// the signed bytes are the ones the receiver sees.
+func (s *DKIMSigner) Sign(data string) (string, error) {
+	data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\n", "\r\n")
+
+	header, body, found := strings.Cut(data, "\r\n\r\n")
+	if !found {
+		header, body = strings.TrimSuffix(data, "\r\n"), ""
+	}
+
+	fields := splitHeaderFields(header)
+
+	bodyHash := sha256.Sum256([]byte(relaxedBody(body)))
+
+	var names []string
+	var signed strings.Builder
+	used := map[int]bool{}
+	for _, name := range DKIMSignedHeaders {
+		// When a field appears more than once, signers and verifiers both
+		// take the instances from the bottom of the header up.
+		for i := len(fields) - 1; i >= 0; i-- {
+			if used[i] || !strings.EqualFold(fieldName(fields[i]), name) {
+				continue
+			}
+
+			used[i] = true
+			names = append(names, name)
+			signed.WriteString(relaxedHeader(fields[i]))
+			signed.WriteString("\r\n")
+			break
+		}
+	}
+
+	if len(names) == 0 || !strings.EqualFold(names[0], "From") {
+		return "", errors.New("the message has no From header to sign")
+	}
+
+	signature := fmt.Sprintf("DKIM-Signature: v=1; a=rsa-sha256; c=relaxed/relaxed; d=%s; s=%s;\r\n\th=%s;\r\n\tbh=%s;\r\n\tb=",
+		s.Domain, s.Selector, strings.Join(names, ":"), base64.StdEncoding.EncodeToString(bodyHash[:]))
+	signed.WriteString(relaxedHeader(signature))
+
+	digest := sha256.Sum256([]byte(signed.String()))
+	b, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+
+	return signature + base64.StdEncoding.EncodeToString(b) + "\r\n" + data, nil
+}
+
+// splitHeaderFields splits a header block into its fields, keeping folded
+// continuation lines with the field they belong to.
+func splitHeaderFields(header string) []string {
+	var fields []string
+	for _, line := range strings.Split(header, "\r\n") {
+		if len(fields) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			fields[len(fields)-1] += "\r\n" + line
+			continue
+		}
+
+		fields = append(fields, line)
+	}
+
+	return fields
+}
+
+func fieldName(field string) string {
+	name, _, _ := strings.Cut(field, ":")
+	return strings.TrimSpace(name)
+}
+
+// relaxedHeader canonicalizes a header field as described in RFC 6376
+// section 3.4.2: the name is lowercased, the value unfolded, runs of
+// whitespace reduced to one space, and whitespace around the colon and at
+// the end removed.
+func relaxedHeader(field string) string {
+	name, value, _ := strings.Cut(field, ":")
+	value = strings.ReplaceAll(value, "\r\n", "")
+
+	return strings.ToLower(strings.TrimSpace(name)) + ":" + strings.TrimSpace(compressWhitespace(value))
+}
+
+// relaxedBody canonicalizes a body as described in RFC 6376 section 3.4.4:
+// runs of whitespace become one space, whitespace at the end of lines and
+// empty lines at the end of the body are removed, and a non-empty body
+// ends in CRLF.
+func relaxedBody(body string) string {
+	lines := strings.Split(body, "\r\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(compressWhitespace(line), " ")
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	if len(lines) == 0 {
+		return ""
+	}
+
+	return strings.Join(lines, "\r\n") + "\r\n"
+}
+
+func compressWhitespace(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range s {
+		if r == ' ' || r == '\t' {
+			space = true
+			continue
+		}
+
+		if space {
+			b.WriteByte(' ')
+			space = false
+		}
+		b.WriteRune(r)
+	}
+
+	if space {
+		b.WriteByte(' ')
+	}
+
+	return b.String()
+}
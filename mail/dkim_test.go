@@ -0,0 +1,132 @@
+package mail_test
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DKIMSigner", func() {
+	var (
+		key    *rsa.PrivateKey
+		signer *mail.DKIMSigner
+	)
+
+	BeforeEach(func() {
+		var err error
+		key, err = rsa.GenerateKey(rand.Reader, 1024)
+		Expect(err).NotTo(HaveOccurred())
+
+		privateKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+		signer, err = mail.NewDKIMSigner("Example.com", "mail", privateKey)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	tags := func(signature string) map[string]string {
+		values := map[string]string{}
+		for _, tag := range strings.Split(signature, ";") {
+			name, value, _ := strings.Cut(tag, "=")
+			values[strings.TrimSpace(name)] = strings.Join(strings.Fields(value), "")
+		}
+
+		return values
+	}
+
+	It("signs the headers and body with relaxed canonicalization", func() {
+		signed, err := signer.Sign("X-Custom: ignored\nFrom: Me <me@example.com>\nTo: you@example.com\nSubject:  Hello\n\tthere  \n\nSome   text \n\n\n")
+		Expect(err).NotTo(HaveOccurred())
+
+		header, body, found := strings.Cut(signed, "\r\n\r\n")
+		Expect(found).To(BeTrue())
+		Expect(body).To(Equal("Some   text \r\n\r\n\r\n"))
+
+		signature, rest, found := strings.Cut(header, "\r\nX-Custom: ignored")
+		Expect(found).To(BeTrue())
+		Expect(rest).To(Equal("\r\nFrom: Me <me@example.com>\r\nTo: you@example.com\r\nSubject:  Hello\r\n\tthere  "))
+		Expect(signature).To(HavePrefix("DKIM-Signature: "))
+
+		values := tags(strings.ReplaceAll(strings.TrimPrefix(signature, "DKIM-Signature:"), "\r\n", ""))
+		Expect(values["v"]).To(Equal("1"))
+		Expect(values["a"]).To(Equal("rsa-sha256"))
+		Expect(values["c"]).To(Equal("relaxed/relaxed"))
+		Expect(values["d"]).To(Equal("example.com"))
+		Expect(values["s"]).To(Equal("mail"))
+		Expect(values["h"]).To(Equal("From:To:Subject"))
+
+		bodyHash := sha256.Sum256([]byte("Some text\r\n"))
+		Expect(values["bh"]).To(Equal(base64.StdEncoding.EncodeToString(bodyHash[:])))
+
+		unsigned := signature[:strings.LastIndex(signature, "b=")+2]
+		canonical := "from:Me <me@example.com>\r\n" +
+			"to:you@example.com\r\n" +
+			"subject:Hello there\r\n" +
+			"dkim-signature:" + strings.TrimSpace(strings.Join(strings.Fields(strings.ReplaceAll(strings.TrimPrefix(unsigned, "DKIM-Signature:"), "\r\n", "")), " "))
+		digest := sha256.Sum256([]byte(canonical))
+
+		b, err := base64.StdEncoding.DecodeString(values["b"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], b)).To(Succeed())
+	})
+
+	It("signs the last instance of a repeated header", func() {
+		signed, err := signer.Sign("From: me@example.com\r\nTo: first@example.com\r\nTo: second@example.com\r\n\r\nbody\r\n")
+		Expect(err).NotTo(HaveOccurred())
+
+		signature, _, _ := strings.Cut(signed, "\r\nFrom:")
+		values := tags(strings.ReplaceAll(strings.TrimPrefix(signature, "DKIM-Signature:"), "\r\n", ""))
+		Expect(values["h"]).To(Equal("From:To"))
+
+		unsigned := signature[:strings.LastIndex(signature, "b=")+2]
+		canonical := "from:me@example.com\r\n" +
+			"to:second@example.com\r\n" +
+			"dkim-signature:" + strings.TrimSpace(strings.Join(strings.Fields(strings.ReplaceAll(strings.TrimPrefix(unsigned, "DKIM-Signature:"), "\r\n", "")), " "))
+		digest := sha256.Sum256([]byte(canonical))
+
+		b, err := base64.StdEncoding.DecodeString(values["b"])
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], b)).To(Succeed())
+	})
+
+	It("refuses to sign a message without a From header", func() {
+		_, err := signer.Sign("To: you@example.com\r\n\r\nbody\r\n")
+		Expect(err).To(MatchError("the message has no From header to sign"))
+	})
+
+	Describe("NewDKIMSigner", func() {
+		It("accepts a PKCS #8 key", func() {
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = mail.NewDKIMSigner("example.com", "mail", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("rejects a key that is not PEM encoded", func() {
+			_, err := mail.NewDKIMSigner("example.com", "mail", []byte("banana"))
+			Expect(err).To(MatchError("the DKIM private key is not PEM encoded"))
+		})
+
+		It("rejects a key that is too short", func() {
+			short, err := rsa.GenerateKey(rand.Reader, 512)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = mail.NewDKIMSigner("example.com", "mail", pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(short)}))
+			Expect(err).To(MatchError("the DKIM private key has 512 bits, receivers require at least 1024"))
+		})
+
+		It("requires a domain and selector", func() {
+			_, err := mail.NewDKIMSigner("", "mail", nil)
+			Expect(err).To(MatchError("a DKIM domain and selector are required"))
+		})
+	})
+})
@@ -0,0 +1,205 @@
+package mail
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+)
+
+// Keywords of the RFC 3461 NOTIFY parameter, which asks the mail servers a
+// message passes through to report back on it.
+const (
+	DSNNotifySuccess = "SUCCESS"
+	DSNNotifyFailure = "FAILURE"
+	DSNNotifyDelay   = "DELAY"
+	DSNNotifyNever   = "NEVER"
+)
+
+var DSNNotifyKeywords = []string{DSNNotifySuccess, DSNNotifyFailure, DSNNotifyDelay, DSNNotifyNever}
+
+// Actions a delivery status notification reports for a recipient.
+const (
+	DSNActionFailed    = "failed"
+	DSNActionDelayed   = "delayed"
+	DSNActionDelivered = "delivered"
+	DSNActionRelayed   = "relayed"
+	DSNActionExpanded  = "expanded"
+)
+
+// ValidateDSNNotify checks the keywords of a NOTIFY parameter: NEVER on its
+// own, or any of the others.
+func ValidateDSNNotify(keywords []string) error {
+	for _, keyword := range keywords {
+		if keyword == DSNNotifyNever && len(keywords) > 1 {
+			return errors.New("NEVER cannot be combined with other keywords")
+		}
+
+		if !containsTerm(DSNNotifyKeywords, keyword) {
+			return fmt.Errorf("%q is not one of the allowed keywords: %+v", keyword, DSNNotifyKeywords)
+		}
+	}
+
+	return nil
+}
+
+// DSN is a delivery status notification (RFC 3464) that a mail server sent
+// back about a message. EnvelopeID is the ENVID the message was sent with.
+type DSN struct {
+	EnvelopeID string
+	Recipients []DSNRecipient
+}
+
+type DSNRecipient struct {
+	FinalRecipient string
+	Action         string
+	Status         string
+	DiagnosticCode string
+}
+
+// SMTPCode is the reply code of the remote server quoted in the diagnostic
+// code, or 0 when there is none.
+func (r DSNRecipient) SMTPCode() int {
+	kind, diagnostic, found := strings.Cut(r.DiagnosticCode, ";")
+	if !found || !strings.EqualFold(strings.TrimSpace(kind), "smtp") {
+		return 0
+	}
+
+	fields := strings.Fields(diagnostic)
+	if len(fields) == 0 {
+		return 0
+	}
+
+	code, err := strconv.Atoi(strings.TrimRight(fields[0], "-"))
+	if err != nil || code < 200 || code > 599 {
+		return 0
+	}
+
+	return code
+}
+
+// ParseDSN reads a delivery status notification from the whole bounce
+// message: a multipart/report whose message/delivery-status part holds the
+// per-message fields followed by those of each recipient.
+func ParseDSN(r io.Reader) (DSN, error) {
+	message, err := netmail.ReadMessage(r)
+	if err != nil {
+		return DSN{}, err
+	}
+
+	mediaType, params, err := mime.ParseMediaType(message.Header.Get("Content-Type"))
+	if err != nil {
+		return DSN{}, err
+	}
+
+	if mediaType != "multipart/report" || !strings.EqualFold(params["report-type"], "delivery-status") {
+		return DSN{}, fmt.Errorf("the message is %s, not a multipart/report of delivery-status", mediaType)
+	}
+
+	parts := multipart.NewReader(message.Body, params["boundary"])
+	for {
+		part, err := parts.NextPart()
+		if err == io.EOF {
+			return DSN{}, errors.New("the report has no message/delivery-status part")
+		}
+		if err != nil {
+			return DSN{}, err
+		}
+
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		if partType == "message/delivery-status" || partType == "message/global-delivery-status" {
+			return parseDeliveryStatus(part)
+		}
+	}
+}
+
+func parseDeliveryStatus(r io.Reader) (DSN, error) {
+	reader := textproto.NewReader(bufio.NewReader(r))
+
+	var dsn DSN
+	for first := true; ; first = false {
+		// Some servers separate the groups with more than one blank line.
+		for {
+			line, err := reader.R.Peek(1)
+			if err != nil || (line[0] != '\r' && line[0] != '\n') {
+				break
+			}
+			reader.ReadLine()
+		}
+
+		fields, err := reader.ReadMIMEHeader()
+		if len(fields) > 0 {
+			if first {
+				dsn.EnvelopeID = decodeXText(fields.Get("Original-Envelope-Id"))
+			} else {
+				dsn.Recipients = append(dsn.Recipients, DSNRecipient{
+					FinalRecipient: addressField(fields.Get("Final-Recipient")),
+					Action:         strings.ToLower(fields.Get("Action")),
+					Status:         fields.Get("Status"),
+					DiagnosticCode: fields.Get("Diagnostic-Code"),
+				})
+			}
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return DSN{}, err
+		}
+	}
+
+	if len(dsn.Recipients) == 0 {
+		return DSN{}, errors.New("the report has no recipient fields")
+	}
+
+	return dsn, nil
+}
+
+// addressField strips the address type, such as "rfc822;", from a
+// recipient field.
+func addressField(value string) string {
+	if _, address, found := strings.Cut(value, ";"); found {
+		return strings.TrimSpace(address)
+	}
+
+	return strings.TrimSpace(value)
+}
+
+// encodeXText encodes a parameter value as the xtext of RFC 3461, in which
+// "+", "=" and characters outside printable ASCII are written as "+XX".
+func encodeXText(value string) string {
+	var encoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		if c < '!' || c > '~' || c == '+' || c == '=' {
+			fmt.Fprintf(&encoded, "+%02X", c)
+			continue
+		}
+		encoded.WriteByte(c)
+	}
+
+	return encoded.String()
+}
+
+func decodeXText(value string) string {
+	var decoded strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] == '+' && i+2 < len(value) {
+			if c, err := strconv.ParseUint(value[i+1:i+3], 16, 8); err == nil {
+				decoded.WriteByte(byte(c))
+				i += 2
+				continue
+			}
+		}
+		decoded.WriteByte(value[i])
+	}
+
+	return strings.TrimSpace(decoded.String())
+}
@@ -0,0 +1,74 @@
+package mail_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const failureReport = "From: MAILER-DAEMON@mx.example.com\r\n" +
+	"To: bounces@notifications.example.com\r\n" +
+	"Subject: Undelivered Mail Returned to Sender\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"report-boundary\"\r\n" +
+	"\r\n" +
+	"--report-boundary\r\n" +
+	"Content-Type: text/plain\r\n" +
+	"\r\n" +
+	"Your message could not be delivered.\r\n" +
+	"--report-boundary\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"Original-Envelope-Id: message-id+2B1\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; nobody@example.com\r\n" +
+	"Original-Recipient: rfc822;nobody@example.com\r\n" +
+	"Action: Failed\r\n" +
+	"Status: 5.1.1\r\n" +
+	"Diagnostic-Code: smtp; 550 5.1.1 <nobody@example.com>: Recipient address\r\n" +
+	"    rejected: User unknown\r\n" +
+	"\r\n" +
+	"--report-boundary\r\n" +
+	"Content-Type: text/rfc822-headers\r\n" +
+	"\r\n" +
+	"Subject: Urgent! Read now!\r\n" +
+	"--report-boundary--\r\n"
+
+var _ = Describe("ParseDSN", func() {
+	It("reads the envelope ID and the outcome for each recipient", func() {
+		dsn, err := mail.ParseDSN(strings.NewReader(failureReport))
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(dsn.EnvelopeID).To(Equal("message-id+1"))
+		Expect(dsn.Recipients).To(HaveLen(1))
+		Expect(dsn.Recipients[0].FinalRecipient).To(Equal("nobody@example.com"))
+		Expect(dsn.Recipients[0].Action).To(Equal(mail.DSNActionFailed))
+		Expect(dsn.Recipients[0].Status).To(Equal("5.1.1"))
+		Expect(dsn.Recipients[0].SMTPCode()).To(Equal(550))
+	})
+
+	It("errors when the message is not a delivery status report", func() {
+		_, err := mail.ParseDSN(strings.NewReader("From: someone@example.com\r\nContent-Type: text/plain\r\n\r\nHello\r\n"))
+		Expect(err).To(MatchError("the message is text/plain, not a multipart/report of delivery-status"))
+	})
+
+	It("errors when the report has no recipients", func() {
+		report := failureReport[:strings.Index(failureReport, "Final-Recipient")] + "--report-boundary--\r\n"
+
+		_, err := mail.ParseDSN(strings.NewReader(report))
+		Expect(err).To(MatchError("the report has no recipient fields"))
+	})
+})
+
+var _ = Describe("ValidateDSNNotify", func() {
+	It("allows NEVER only on its own", func() {
+		Expect(mail.ValidateDSNNotify([]string{"SUCCESS", "FAILURE", "DELAY"})).To(Succeed())
+		Expect(mail.ValidateDSNNotify([]string{"NEVER"})).To(Succeed())
+		Expect(mail.ValidateDSNNotify([]string{"NEVER", "FAILURE"})).To(MatchError("NEVER cannot be combined with other keywords"))
+		Expect(mail.ValidateDSNNotify([]string{"ALWAYS"})).To(MatchError(`"ALWAYS" is not one of the allowed keywords: [SUCCESS FAILURE DELAY NEVER]`))
+	})
+})
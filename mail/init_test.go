@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"crypto/rand"
 	"crypto/tls"
+	"crypto/x509"
 	"log"
 	"net"
 	"net/url"
@@ -82,17 +83,24 @@ type SMTPServer struct {
 	Deliveries      []Delivery
 	Listener        *net.TCPListener
 	SupportsTLS     bool
+	MaxTLSVersion   uint16
 	ConnectWait     time.Duration
 	halt            chan bool
 	ConnectionState string
 	FailsHello      bool
+	RejectsRcpt     string
+	SupportsDSN     bool
 }
 
 type Delivery struct {
-	Recipient string
-	Sender    string
-	Data      []string
-	UsedTLS   bool
+	Recipient         string
+	Sender            string
+	Data              []string
+	UsedTLS           bool
+	HelloName         string
+	ClientCertificate *x509.Certificate
+	MailParams        string
+	RcptParams        string
 }
 
 func NewSMTPServer(user, pass string) *SMTPServer {
@@ -161,9 +169,14 @@ func (server *SMTPServer) Respond(conn net.Conn) {
 
 Loop:
 	for {
-		msg, _ := input.ReadString('\n')
+		msg, err := input.ReadString('\n')
+		if err != nil {
+			break Loop
+		}
+
 		switch {
 		case strings.Contains(msg, "EHLO"):
+			server.CurrentDelivery.HelloName = strings.TrimSpace(strings.TrimPrefix(msg, "EHLO"))
 			server.RespondToEHLO(output)
 		case strings.Contains(msg, "STARTTLS"):
 			conn, input, output = server.RespondToStartTLS(conn, input, output)
@@ -199,6 +212,9 @@ func (server *SMTPServer) RespondToEHLO(output *bufio.Writer) {
 	}
 
 	output.WriteString("250-localhost Hello\n")
+	if server.SupportsDSN {
+		output.WriteString("250-DSN\n")
+	}
 	if server.SupportsTLS {
 		output.WriteString("250-STARTTLS\n")
 		output.WriteString("250 AUTH PLAIN LOGIN\r\n")
@@ -218,10 +234,16 @@ func (server *SMTPServer) RespondToStartTLS(conn net.Conn, input *bufio.Reader,
 	if err != nil {
 		log.Fatalf("server: loadkeys: %s", err)
 	}
-	config := tls.Config{Certificates: []tls.Certificate{cert}}
+	config := tls.Config{Certificates: []tls.Certificate{cert}, ClientAuth: tls.RequestClientCert, MaxVersion: server.MaxTLSVersion}
 	config.Rand = rand.Reader
 	tlsConn := tls.Server(conn, &config)
 
+	if err := tlsConn.Handshake(); err == nil {
+		if peers := tlsConn.ConnectionState().PeerCertificates; len(peers) > 0 {
+			server.CurrentDelivery.ClientCertificate = peers[0]
+		}
+	}
+
 	return tlsConn, bufio.NewReader(tlsConn), bufio.NewWriter(tlsConn)
 }
 
@@ -233,6 +255,7 @@ func (server *SMTPServer) RespondToAuthPlain(output *bufio.Writer) {
 func (server *SMTPServer) RespondToMailFrom(output *bufio.Writer, msg string) {
 	sender := strings.TrimSpace(msg)
 	sender = strings.TrimPrefix(sender, "MAIL FROM:")
+	sender, server.CurrentDelivery.MailParams, _ = strings.Cut(sender, " ")
 	sender = strings.Trim(sender, "<>")
 	server.CurrentDelivery.Sender = sender
 
@@ -243,9 +266,16 @@ func (server *SMTPServer) RespondToMailFrom(output *bufio.Writer, msg string) {
 func (server *SMTPServer) RespondToRcptTo(output *bufio.Writer, msg string) {
 	recipient := strings.TrimSpace(msg)
 	recipient = strings.TrimPrefix(recipient, "RCPT TO:")
+	recipient, server.CurrentDelivery.RcptParams, _ = strings.Cut(recipient, " ")
 	recipient = strings.Trim(recipient, "<>")
 	server.CurrentDelivery.Recipient = recipient
 
+	if server.RejectsRcpt != "" {
+		output.WriteString(server.RejectsRcpt + "\r\n")
+		output.Flush()
+		return
+	}
+
 	output.WriteString("250 OK\r\n")
 	output.Flush()
 }
@@ -34,6 +34,14 @@ type Message struct {
 	Body                    []Part
 	Headers                 []string
 	CompiledBody            string
+
+	// Categories tag the message for reporting by HTTP API transports.
+	// SMTP delivery leaves them out.
+	Categories []string
+
+	// EnvelopeID identifies the message in the delivery status
+	// notifications that SMTP servers send back about it.
+	EnvelopeID string
 }
 
 type Part struct {
@@ -0,0 +1,142 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const DefaultMXPort = "25"
+
+// Resolver looks up the mail exchangers of a domain. *net.Resolver
+// satisfies it and is used unless Config.Resolver is set.
+type Resolver interface {
+	LookupMX(ctx context.Context, name string) ([]*net.MX, error)
+}
+
+// DomainPolicy changes how mail for a single recipient domain is delivered
+// when sending directly to its mail exchangers.
+type DomainPolicy struct {
+	Port       string `json:"port"`
+	DisableTLS bool   `json:"disable_tls"`
+	RequireTLS bool   `json:"require_tls"`
+}
+
+type NullMXError struct {
+	Domain string
+}
+
+func (e NullMXError) Error() string {
+	return fmt.Sprintf("domain %q does not accept mail", e.Domain)
+}
+
+// sendDirect delivers msg to the mail exchangers of the recipient domain,
+// trying each in order of preference until one accepts the connection.
+func (c *Client) sendDirect(msg Message, logger lager.Logger) error {
+	domain, err := recipientDomain(msg.To)
+	if err != nil {
+		return c.Error(logger, err)
+	}
+
+	hosts, err := c.mailExchangers(domain)
+	if err != nil {
+		return c.Error(logger, err)
+	}
+
+	policy := c.config.DomainPolicies[domain]
+	port := policy.Port
+	if port == "" {
+		port = DefaultMXPort
+	}
+
+	var lastErr error
+	for _, host := range hosts {
+		c.PrintLog(logger, "mx-connecting", lager.Data{"domain": domain, "host": host, "port": port})
+
+		err = c.handshake(logger, host, port, policy)
+		if err == nil {
+			return c.transmit(msg, logger)
+		}
+
+		logger.Info("mx-unavailable", lager.Data{"domain": domain, "host": host, "error": err.Error()})
+		if c.client != nil {
+			client := c.client
+			if c.Quit() != nil {
+				client.Close()
+			}
+		}
+		lastErr = err
+	}
+
+	return c.Error(logger, fmt.Errorf("no mail exchanger for %q accepted the connection: %w", domain, lastErr))
+}
+
+func (c *Client) handshake(logger lager.Logger, host, port string, policy DomainPolicy) error {
+	err := c.connectTo(logger, host, port)
+	if err != nil {
+		return err
+	}
+
+	err = c.Hello()
+	if err != nil {
+		return err
+	}
+
+	if policy.DisableTLS {
+		return nil
+	}
+
+	if ok, _ := c.Extension("STARTTLS"); !ok && policy.RequireTLS {
+		return TLSPolicyError{Err: errors.New("server does not offer STARTTLS")}
+	}
+
+	return c.StartTLS()
+}
+
+// mailExchangers returns the hosts to try for domain, most preferred first.
+// A domain without MX records is its own mail exchanger (RFC 5321 5.1).
+func (c *Client) mailExchangers(domain string) ([]string, error) {
+	records, err := c.config.Resolver.LookupMX(context.Background(), domain)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return []string{domain}, nil
+		}
+		return nil, err
+	}
+
+	if len(records) == 0 {
+		return []string{domain}, nil
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Pref < records[j].Pref
+	})
+
+	var hosts []string
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Host, ".")
+		if host == "" {
+			return nil, NullMXError{Domain: domain}
+		}
+		hosts = append(hosts, host)
+	}
+
+	return hosts, nil
+}
+
+func recipientDomain(address string) (string, error) {
+	address = strings.Trim(strings.TrimSpace(address), "<>")
+
+	at := strings.LastIndex(address, "@")
+	if at < 0 || at == len(address)-1 {
+		return "", fmt.Errorf("recipient %q has no domain", address)
+	}
+
+	return strings.ToLower(address[at+1:]), nil
+}
@@ -0,0 +1,166 @@
+package mail_test
+
+import (
+	"bytes"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Direct MX delivery", func() {
+	var (
+		mailServer *SMTPServer
+		resolver   *mocks.MXResolver
+		client     *mail.Client
+		config     mail.Config
+		logger     lager.Logger
+		buffer     *bytes.Buffer
+		msg        mail.Message
+	)
+
+	BeforeEach(func() {
+		buffer = &bytes.Buffer{}
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, 0))
+
+		mailServer = NewSMTPServer("user", "pass")
+		mailServer.SupportsTLS = true
+
+		_, port, err := net.SplitHostPort(mailServer.URL.Host)
+		Expect(err).NotTo(HaveOccurred())
+
+		resolver = &mocks.MXResolver{}
+		resolver.LookupMXCall.Returns.Records = []*net.MX{
+			{Host: "127.0.0.1.", Pref: 10},
+		}
+
+		config = mail.Config{
+			Host:           "smtp.example.com",
+			Port:           "587",
+			SkipVerifySSL:  true,
+			ConnectTimeout: time.Second,
+			MXLookup:       true,
+			Resolver:       resolver,
+			DomainPolicies: map[string]mail.DomainPolicy{
+				"example.com": {Port: port},
+			},
+		}
+		client = mail.NewClient(config)
+
+		msg = mail.Message{
+			From:    "me@notifications.example.org",
+			To:      "you@Example.com",
+			Subject: "Urgent! Read now!",
+			Body: []mail.Part{
+				{
+					ContentType: "text/plain",
+					Content:     "delivered without a relay",
+				},
+			},
+		}
+	})
+
+	AfterEach(func() {
+		mailServer.Close()
+	})
+
+	It("delivers to the mail exchanger of the recipient domain", func() {
+		Expect(client.Send(msg, logger)).To(Succeed())
+
+		Eventually(func() int {
+			return len(mailServer.Deliveries)
+		}).Should(Equal(1))
+		Expect(mailServer.Deliveries[0].Recipient).To(Equal("you@Example.com"))
+		Expect(mailServer.Deliveries[0].UsedTLS).To(BeTrue())
+
+		Expect(resolver.LookupMXCall.Receives.Name).To(Equal("example.com"))
+	})
+
+	It("does not connect until there is a recipient", func() {
+		Expect(client.Connect(logger)).To(Succeed())
+		Expect(mailServer.ConnectionState).To(Equal(StateUnknown))
+	})
+
+	It("falls back to the next mail exchanger by preference", func() {
+		resolver.LookupMXCall.Returns.Records = []*net.MX{
+			{Host: "127.0.0.1.", Pref: 20},
+			{Host: "127.0.0.2.", Pref: 5},
+		}
+
+		Expect(client.Send(msg, logger)).To(Succeed())
+
+		Eventually(func() int {
+			return len(mailServer.Deliveries)
+		}).Should(Equal(1))
+		Expect(buffer.String()).To(ContainSubstring(`"host":"127.0.0.2"`))
+	})
+
+	It("delivers to the domain itself when it has no MX records", func() {
+		resolver.LookupMXCall.Returns.Records = nil
+		resolver.LookupMXCall.Returns.Error = &net.DNSError{Err: "no such host", Name: "127.0.0.1", IsNotFound: true}
+		config.DomainPolicies["127.0.0.1"] = config.DomainPolicies["example.com"]
+		client = mail.NewClient(config)
+		msg.To = "you@127.0.0.1"
+
+		Expect(client.Send(msg, logger)).To(Succeed())
+
+		Eventually(func() int {
+			return len(mailServer.Deliveries)
+		}).Should(Equal(1))
+	})
+
+	It("refuses domains that publish a null MX record", func() {
+		resolver.LookupMXCall.Returns.Records = []*net.MX{
+			{Host: ".", Pref: 0},
+		}
+
+		err := client.Send(msg, logger)
+		Expect(err).To(MatchError(mail.NullMXError{Domain: "example.com"}))
+	})
+
+	It("returns lookup errors", func() {
+		resolver.LookupMXCall.Returns.Error = &net.DNSError{Err: "server misbehaving", Name: "example.com", IsTemporary: true}
+
+		err := client.Send(msg, logger)
+		Expect(err).To(HaveOccurred())
+		Expect(mailServer.ConnectionState).To(Equal(StateUnknown))
+	})
+
+	Context("when the domain policy disables TLS", func() {
+		It("does not start TLS", func() {
+			policy := config.DomainPolicies["example.com"]
+			policy.DisableTLS = true
+			config.DomainPolicies["example.com"] = policy
+			client = mail.NewClient(config)
+
+			Expect(client.Send(msg, logger)).To(Succeed())
+
+			Eventually(func() int {
+				return len(mailServer.Deliveries)
+			}).Should(Equal(1))
+			Expect(mailServer.Deliveries[0].UsedTLS).To(BeFalse())
+		})
+	})
+
+	Context("when the domain policy requires TLS", func() {
+		It("fails when no mail exchanger offers STARTTLS", func() {
+			mailServer.SupportsTLS = false
+			policy := config.DomainPolicies["example.com"]
+			policy.RequireTLS = true
+			config.DomainPolicies["example.com"] = policy
+			client = mail.NewClient(config)
+
+			err := client.Send(msg, logger)
+			var policyErr mail.TLSPolicyError
+			Expect(errors.As(err, &policyErr)).To(BeTrue())
+			Expect(policyErr).To(MatchError("TLS policy not met: server does not offer STARTTLS"))
+		})
+	})
+})
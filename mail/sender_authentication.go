@@ -0,0 +1,248 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+)
+
+const (
+	SenderCheckSPF   = "spf"
+	SenderCheckDKIM  = "dkim"
+	SenderCheckDMARC = "dmarc"
+
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// TransportSPFIncludes names the domain that the SPF record of a sender
+// domain must include for mail sent through each API transport to pass.
+var TransportSPFIncludes = map[string]string{
+	TransportSendGrid: "sendgrid.net",
+	TransportSES:      "amazonses.com",
+}
+
+// TXTResolver looks up the TXT records of a name. *net.Resolver satisfies it.
+type TXTResolver interface {
+	LookupTXT(ctx context.Context, name string) ([]string, error)
+}
+
+// SenderExpectations are what a sender domain has to publish for mail sent
+// from this deployment to authenticate.
+type SenderExpectations struct {
+	SPFIncludes   []string
+	DKIMSelectors []string
+}
+
+type SenderFinding struct {
+	Check    string
+	Severity string
+	Message  string
+}
+
+type SenderReport struct {
+	Domain   string
+	SPF      string
+	DKIM     map[string]string
+	DMARC    string
+	Findings []SenderFinding
+}
+
+// Verified is true when none of the findings would keep mail from the
+// domain from authenticating.
+func (r SenderReport) Verified() bool {
+	for _, finding := range r.Findings {
+		if finding.Severity == SeverityError {
+			return false
+		}
+	}
+
+	return true
+}
+
+// SenderAuthentication checks the SPF, DKIM and DMARC records that a
+// sending domain publishes in DNS.
+type SenderAuthentication struct {
+	resolver TXTResolver
+}
+
+func NewSenderAuthentication(resolver TXTResolver) SenderAuthentication {
+	return SenderAuthentication{
+		resolver: resolver,
+	}
+}
+
+func (a SenderAuthentication) Check(domain string, expect SenderExpectations) SenderReport {
+	domain = strings.ToLower(strings.TrimSuffix(domain, "."))
+	report := SenderReport{
+		Domain: domain,
+		DKIM:   map[string]string{},
+	}
+
+	a.checkSPF(&report, expect.SPFIncludes)
+	a.checkDKIM(&report, expect.DKIMSelectors)
+	a.checkDMARC(&report)
+
+	return report
+}
+
+func (a SenderAuthentication) checkSPF(report *SenderReport, includes []string) {
+	records, ok := a.lookup(report, SenderCheckSPF, report.Domain, "v=spf1")
+	if !ok {
+		return
+	}
+
+	switch len(records) {
+	case 0:
+		suggestion := "v=spf1 ~all"
+		if len(includes) > 0 {
+			suggestion = fmt.Sprintf("v=spf1 include:%s ~all", strings.Join(includes, " include:"))
+		}
+		report.add(SenderCheckSPF, SeverityError, "%s has no SPF record; publish a TXT record such as %q", report.Domain, suggestion)
+		return
+	case 1:
+		report.SPF = records[0]
+	default:
+		report.SPF = records[0]
+		report.add(SenderCheckSPF, SeverityError, "%s has %d SPF records, which receivers treat as an error; merge them into one", report.Domain, len(records))
+	}
+
+	terms := strings.Fields(strings.ToLower(report.SPF))
+	for _, include := range includes {
+		if !containsTerm(terms, "include:"+strings.ToLower(include)) {
+			report.add(SenderCheckSPF, SeverityError, "the SPF record of %s does not include %s; add \"include:%s\" before its \"all\" term", report.Domain, include, include)
+		}
+	}
+
+	switch {
+	case containsTerm(terms, "+all"), containsTerm(terms, "all"):
+		report.add(SenderCheckSPF, SeverityError, "the SPF record of %s ends in \"+all\", which lets any server send as it; use \"~all\" or \"-all\"", report.Domain)
+	case containsTerm(terms, "?all"):
+		report.add(SenderCheckSPF, SeverityWarning, "the SPF record of %s ends in \"?all\", which asks receivers to ignore failures; use \"~all\" or \"-all\"", report.Domain)
+	case !containsTerm(terms, "~all") && !containsTerm(terms, "-all") && !hasRedirect(terms):
+		report.add(SenderCheckSPF, SeverityWarning, "the SPF record of %s has no \"all\" term, so it does not say how to treat other servers; end it with \"~all\" or \"-all\"", report.Domain)
+	}
+}
+
+func (a SenderAuthentication) checkDKIM(report *SenderReport, selectors []string) {
+	if len(selectors) == 0 {
+		report.add(SenderCheckDKIM, SeverityWarning, "no DKIM selectors were given, so the DKIM keys of %s were not checked", report.Domain)
+		return
+	}
+
+	for _, selector := range selectors {
+		name := selector + "._domainkey." + report.Domain
+		records, ok := a.lookup(report, SenderCheckDKIM, name, "")
+		if !ok {
+			continue
+		}
+
+		var key string
+		for _, record := range records {
+			if tagValue(record, "p") != "" || strings.Contains(strings.ToLower(record), "v=dkim1") {
+				key = record
+				break
+			}
+		}
+
+		if key == "" {
+			report.add(SenderCheckDKIM, SeverityError, "%s has no DKIM key; publish the public key of selector %q as a TXT record there", name, selector)
+			continue
+		}
+
+		report.DKIM[selector] = key
+		if tagValue(key, "p") == "" {
+			report.add(SenderCheckDKIM, SeverityError, "the DKIM key of selector %q at %s is empty, which means it was revoked", selector, name)
+		}
+	}
+}
+
+func (a SenderAuthentication) checkDMARC(report *SenderReport) {
+	name := "_dmarc." + report.Domain
+	records, ok := a.lookup(report, SenderCheckDMARC, name, "v=dmarc1")
+	if !ok {
+		return
+	}
+
+	if len(records) == 0 {
+		report.add(SenderCheckDMARC, SeverityError, "%s has no DMARC record; publish a TXT record at %s such as \"v=DMARC1; p=quarantine\"", report.Domain, name)
+		return
+	}
+
+	report.DMARC = records[0]
+	if len(records) > 1 {
+		report.add(SenderCheckDMARC, SeverityError, "%s has %d DMARC records, which receivers ignore; merge them into one", name, len(records))
+	}
+
+	if strings.ToLower(tagValue(report.DMARC, "p")) == "none" {
+		report.add(SenderCheckDMARC, SeverityWarning, "the DMARC policy of %s is \"none\", so receivers are not asked to act on mail that fails authentication", report.Domain)
+	}
+}
+
+// lookup returns the TXT records at name that start with prefix. A name
+// that does not exist has no records; any other failure is reported as a
+// finding and ok is false.
+func (a SenderAuthentication) lookup(report *SenderReport, check, name, prefix string) ([]string, bool) {
+	records, err := a.resolver.LookupTXT(context.Background(), name)
+	if err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+			return nil, true
+		}
+
+		report.add(check, SeverityError, "could not look up the TXT records of %s: %s", name, err)
+		return nil, false
+	}
+
+	var matches []string
+	for _, record := range records {
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(record)), prefix) {
+			matches = append(matches, strings.TrimSpace(record))
+		}
+	}
+
+	return matches, true
+}
+
+func (r *SenderReport) add(check, severity, format string, args ...interface{}) {
+	r.Findings = append(r.Findings, SenderFinding{
+		Check:    check,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func containsTerm(terms []string, term string) bool {
+	for _, t := range terms {
+		if t == term {
+			return true
+		}
+	}
+
+	return false
+}
+
+func hasRedirect(terms []string) bool {
+	for _, t := range terms {
+		if strings.HasPrefix(t, "redirect=") {
+			return true
+		}
+	}
+
+	return false
+}
+
+// tagValue returns the value of a tag in a DKIM or DMARC record, which is a
+// list of tag=value pairs separated by semicolons.
+func tagValue(record, tag string) string {
+	for _, pair := range strings.Split(record, ";") {
+		name, value, found := strings.Cut(pair, "=")
+		if found && strings.EqualFold(strings.TrimSpace(name), tag) {
+			return strings.Join(strings.Fields(value), "")
+		}
+	}
+
+	return ""
+}
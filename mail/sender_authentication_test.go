@@ -0,0 +1,174 @@
+package mail_test
+
+import (
+	"errors"
+	"net"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SenderAuthentication", func() {
+	var (
+		resolver       *mocks.TXTResolver
+		authentication mail.SenderAuthentication
+		expect         mail.SenderExpectations
+	)
+
+	BeforeEach(func() {
+		resolver = mocks.NewTXTResolver()
+		resolver.LookupTXTCall.Returns.Records = map[string][]string{
+			"example.com": {
+				"google-site-verification=abc",
+				"v=spf1 include:amazonses.com -all",
+			},
+			"mail._domainkey.example.com": {"v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC"},
+			"_dmarc.example.com":          {"v=DMARC1; p=reject; rua=mailto:dmarc@example.com"},
+		}
+
+		authentication = mail.NewSenderAuthentication(resolver)
+		expect = mail.SenderExpectations{
+			SPFIncludes:   []string{"amazonses.com"},
+			DKIMSelectors: []string{"mail"},
+		}
+	})
+
+	It("verifies a domain that publishes everything it needs", func() {
+		report := authentication.Check("Example.com.", expect)
+
+		Expect(report.Verified()).To(BeTrue())
+		Expect(report.Findings).To(BeEmpty())
+		Expect(report.Domain).To(Equal("example.com"))
+		Expect(report.SPF).To(Equal("v=spf1 include:amazonses.com -all"))
+		Expect(report.DKIM).To(Equal(map[string]string{
+			"mail": "v=DKIM1; k=rsa; p=MIGfMA0GCSqGSIb3DQEBAQUAA4GNADCBiQKBgQC",
+		}))
+		Expect(report.DMARC).To(Equal("v=DMARC1; p=reject; rua=mailto:dmarc@example.com"))
+		Expect(resolver.LookupTXTCall.Receives.Names).To(ConsistOf("example.com", "mail._domainkey.example.com", "_dmarc.example.com"))
+	})
+
+	It("reports a missing SPF record with a suggested one", func() {
+		resolver.LookupTXTCall.Returns.Records["example.com"] = []string{"google-site-verification=abc"}
+
+		report := authentication.Check("example.com", expect)
+
+		Expect(report.Verified()).To(BeFalse())
+		Expect(report.Findings).To(ConsistOf(mail.SenderFinding{
+			Check:    mail.SenderCheckSPF,
+			Severity: mail.SeverityError,
+			Message:  `example.com has no SPF record; publish a TXT record such as "v=spf1 include:amazonses.com ~all"`,
+		}))
+	})
+
+	It("reports an SPF record that does not include the sending service", func() {
+		resolver.LookupTXTCall.Returns.Records["example.com"] = []string{"v=spf1 include:_spf.google.com ~all"}
+
+		report := authentication.Check("example.com", expect)
+
+		Expect(report.Verified()).To(BeFalse())
+		Expect(report.Findings).To(ConsistOf(mail.SenderFinding{
+			Check:    mail.SenderCheckSPF,
+			Severity: mail.SeverityError,
+			Message:  `the SPF record of example.com does not include amazonses.com; add "include:amazonses.com" before its "all" term`,
+		}))
+	})
+
+	It("reports more than one SPF record", func() {
+		resolver.LookupTXTCall.Returns.Records["example.com"] = []string{
+			"v=spf1 include:amazonses.com -all",
+			"v=spf1 include:_spf.google.com -all",
+		}
+
+		report := authentication.Check("example.com", expect)
+
+		Expect(report.Verified()).To(BeFalse())
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Message).To(ContainSubstring("has 2 SPF records"))
+	})
+
+	It("reports SPF records that let any server send, or ask for failures to be ignored", func() {
+		resolver.LookupTXTCall.Returns.Records["example.com"] = []string{"v=spf1 include:amazonses.com +all"}
+
+		report := authentication.Check("example.com", expect)
+		Expect(report.Verified()).To(BeFalse())
+		Expect(report.Findings[0].Message).To(ContainSubstring(`ends in "+all"`))
+
+		resolver.LookupTXTCall.Returns.Records["example.com"] = []string{"v=spf1 include:amazonses.com ?all"}
+
+		report = authentication.Check("example.com", expect)
+		Expect(report.Verified()).To(BeTrue())
+		Expect(report.Findings).To(ConsistOf(mail.SenderFinding{
+			Check:    mail.SenderCheckSPF,
+			Severity: mail.SeverityWarning,
+			Message:  `the SPF record of example.com ends in "?all", which asks receivers to ignore failures; use "~all" or "-all"`,
+		}))
+	})
+
+	It("reports missing and revoked DKIM keys", func() {
+		resolver.LookupTXTCall.Returns.Records["old._domainkey.example.com"] = []string{"v=DKIM1; p="}
+		expect.DKIMSelectors = []string{"missing", "old"}
+
+		report := authentication.Check("example.com", expect)
+
+		Expect(report.Verified()).To(BeFalse())
+		Expect(report.Findings).To(ConsistOf(
+			mail.SenderFinding{
+				Check:    mail.SenderCheckDKIM,
+				Severity: mail.SeverityError,
+				Message:  `missing._domainkey.example.com has no DKIM key; publish the public key of selector "missing" as a TXT record there`,
+			},
+			mail.SenderFinding{
+				Check:    mail.SenderCheckDKIM,
+				Severity: mail.SeverityError,
+				Message:  `the DKIM key of selector "old" at old._domainkey.example.com is empty, which means it was revoked`,
+			},
+		))
+	})
+
+	It("warns that DKIM was not checked when no selectors are given", func() {
+		expect.DKIMSelectors = nil
+
+		report := authentication.Check("example.com", expect)
+
+		Expect(report.Verified()).To(BeTrue())
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Check).To(Equal(mail.SenderCheckDKIM))
+		Expect(report.Findings[0].Severity).To(Equal(mail.SeverityWarning))
+	})
+
+	It("reports a missing DMARC record, and warns about a policy of none", func() {
+		resolver.LookupTXTCall.Returns.Errors["_dmarc.example.com"] = &net.DNSError{Err: "no such host", Name: "_dmarc.example.com", IsNotFound: true}
+
+		report := authentication.Check("example.com", expect)
+		Expect(report.Verified()).To(BeFalse())
+		Expect(report.Findings).To(ConsistOf(mail.SenderFinding{
+			Check:    mail.SenderCheckDMARC,
+			Severity: mail.SeverityError,
+			Message:  `example.com has no DMARC record; publish a TXT record at _dmarc.example.com such as "v=DMARC1; p=quarantine"`,
+		}))
+
+		resolver.LookupTXTCall.Returns.Errors = map[string]error{}
+		resolver.LookupTXTCall.Returns.Records["_dmarc.example.com"] = []string{"v=DMARC1; p=none"}
+
+		report = authentication.Check("example.com", expect)
+		Expect(report.Verified()).To(BeTrue())
+		Expect(report.Findings).To(HaveLen(1))
+		Expect(report.Findings[0].Message).To(ContainSubstring(`the DMARC policy of example.com is "none"`))
+	})
+
+	It("reports lookups that fail", func() {
+		resolver.LookupTXTCall.Returns.Errors["example.com"] = errors.New("server misbehaving")
+
+		report := authentication.Check("example.com", expect)
+
+		Expect(report.Verified()).To(BeFalse())
+		Expect(report.Findings).To(ConsistOf(mail.SenderFinding{
+			Check:    mail.SenderCheckSPF,
+			Severity: mail.SeverityError,
+			Message:  "could not look up the TXT records of example.com: server misbehaving",
+		}))
+	})
+})
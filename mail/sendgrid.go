@@ -0,0 +1,209 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	netmail "net/mail"
+	"strings"
+
+	"github.com/pivotal-golang/lager"
+)
+
+const (
+	DefaultSendGridURL = "https://api.sendgrid.com"
+
+	// sendGridMaxCategories is the most categories the v3 API accepts on a
+	// single message.
+	sendGridMaxCategories = 10
+)
+
+type SendGridConfig struct {
+	URL      string
+	APIKey   string
+	TestMode bool
+}
+
+// SendGridClient sends messages through the SendGrid v3 mail send API
+// instead of over SMTP.
+type SendGridClient struct {
+	config     SendGridConfig
+	httpClient *http.Client
+}
+
+type SendGridError struct {
+	StatusCode int
+	Messages   []string
+}
+
+func (e SendGridError) Error() string {
+	if len(e.Messages) == 0 {
+		return fmt.Sprintf("sendgrid: unexpected status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("sendgrid: unexpected status %d: %s", e.StatusCode, strings.Join(e.Messages, "; "))
+}
+
+type sendGridAddress struct {
+	Email string `json:"email"`
+	Name  string `json:"name,omitempty"`
+}
+
+type sendGridPersonalization struct {
+	To []sendGridAddress `json:"to"`
+}
+
+type sendGridContent struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+type sendGridRequest struct {
+	Personalizations []sendGridPersonalization `json:"personalizations"`
+	From             sendGridAddress           `json:"from"`
+	ReplyTo          *sendGridAddress          `json:"reply_to,omitempty"`
+	Subject          string                    `json:"subject"`
+	Content          []sendGridContent         `json:"content"`
+	Headers          map[string]string         `json:"headers,omitempty"`
+	Categories       []string                  `json:"categories,omitempty"`
+}
+
+func NewSendGridClient(config SendGridConfig, httpClient *http.Client) *SendGridClient {
+	if config.URL == "" {
+		config.URL = DefaultSendGridURL
+	}
+
+	return &SendGridClient{
+		config:     config,
+		httpClient: httpClient,
+	}
+}
+
+// Connect has nothing to do, as every message is sent in its own request.
+func (c *SendGridClient) Connect(logger lager.Logger) error {
+	return nil
+}
+
+func (c *SendGridClient) Send(msg Message, logger lager.Logger) error {
+	logger = logger.Session("sendgrid")
+
+	if c.config.TestMode {
+		logger.Info("test-mode")
+		return nil
+	}
+
+	payload, err := sendGridPayload(msg)
+	if err != nil {
+		return err
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", strings.TrimSuffix(c.config.URL, "/")+"/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Authorization", "Bearer "+c.config.APIKey)
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		responseBody, _ := ioutil.ReadAll(response.Body)
+
+		var errorResponse struct {
+			Errors []struct {
+				Message string `json:"message"`
+			} `json:"errors"`
+		}
+		json.Unmarshal(responseBody, &errorResponse)
+
+		sendErr := SendGridError{StatusCode: response.StatusCode}
+		for _, e := range errorResponse.Errors {
+			sendErr.Messages = append(sendErr.Messages, e.Message)
+		}
+
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+			return UnavailableError{Err: sendErr}
+		}
+
+		return sendErr
+	}
+
+	logger.Info("accepted", lager.Data{"message_id": response.Header.Get("X-Message-Id")})
+
+	return nil
+}
+
+func sendGridPayload(msg Message) (sendGridRequest, error) {
+	from, err := sendGridParseAddress(msg.From)
+	if err != nil {
+		return sendGridRequest{}, fmt.Errorf("sendgrid: invalid sender %q: %s", msg.From, err)
+	}
+
+	to, err := sendGridParseAddress(msg.To)
+	if err != nil {
+		return sendGridRequest{}, fmt.Errorf("sendgrid: invalid recipient %q: %s", msg.To, err)
+	}
+
+	payload := sendGridRequest{
+		Personalizations: []sendGridPersonalization{{To: []sendGridAddress{to}}},
+		From:             from,
+		Subject:          msg.Subject,
+		Categories:       msg.Categories,
+	}
+
+	if msg.ReplyTo != "" {
+		replyTo, err := sendGridParseAddress(msg.ReplyTo)
+		if err != nil {
+			return sendGridRequest{}, fmt.Errorf("sendgrid: invalid reply-to %q: %s", msg.ReplyTo, err)
+		}
+		payload.ReplyTo = &replyTo
+	}
+
+	if len(payload.Categories) > sendGridMaxCategories {
+		payload.Categories = payload.Categories[:sendGridMaxCategories]
+	}
+
+	// The API requires text/plain to come before text/html, which is also
+	// the order the packager renders them in.
+	for _, part := range msg.Body {
+		payload.Content = append(payload.Content, sendGridContent{
+			Type:  part.ContentType,
+			Value: part.Content,
+		})
+	}
+
+	for _, header := range msg.Headers {
+		name, value, ok := strings.Cut(header, ":")
+		if !ok {
+			continue
+		}
+
+		if payload.Headers == nil {
+			payload.Headers = map[string]string{}
+		}
+		payload.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	return payload, nil
+}
+
+func sendGridParseAddress(address string) (sendGridAddress, error) {
+	parsed, err := netmail.ParseAddress(address)
+	if err != nil {
+		return sendGridAddress{}, err
+	}
+
+	return sendGridAddress{Email: parsed.Address, Name: parsed.Name}, nil
+}
@@ -0,0 +1,163 @@
+package mail_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type sendGridRequest struct {
+	Method        string
+	Path          string
+	Authorization string
+	ContentType   string
+	Body          []byte
+}
+
+var _ = Describe("SendGridClient", func() {
+	var (
+		client   *mail.SendGridClient
+		server   *httptest.Server
+		logger   lager.Logger
+		buffer   *bytes.Buffer
+		msg      mail.Message
+		status   int
+		response string
+		request  sendGridRequest
+	)
+
+	BeforeEach(func() {
+		status = http.StatusAccepted
+		response = ""
+		request = sendGridRequest{}
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			request.Method = req.Method
+			request.Path = req.URL.Path
+			request.Authorization = req.Header.Get("Authorization")
+			request.ContentType = req.Header.Get("Content-Type")
+			request.Body, _ = ioutil.ReadAll(req.Body)
+
+			w.Header().Set("X-Message-Id", "sendgrid-message-id")
+			w.WriteHeader(status)
+			w.Write([]byte(response))
+		}))
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		client = mail.NewSendGridClient(mail.SendGridConfig{
+			URL:    server.URL,
+			APIKey: "some-api-key",
+		}, http.DefaultClient)
+
+		msg = mail.Message{
+			From:    "Notifications <no-reply@example.com>",
+			ReplyTo: "sender@example.com",
+			To:      "user@example.com",
+			Subject: "Your build failed",
+			Body: []mail.Part{
+				{ContentType: "text/plain", Content: "plain body"},
+				{ContentType: "text/html", Content: "<p>html body</p>"},
+			},
+			Headers: []string{
+				"X-CF-Client-ID: some-client",
+				"List-Unsubscribe: <https://example.com/unsubscribe/token>",
+			},
+			Categories: []string{"build-failure"},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("does not need to connect", func() {
+		Expect(client.Connect(logger)).To(Succeed())
+	})
+
+	It("sends the message through the mail send API", func() {
+		err := client.Send(msg, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(request.Method).To(Equal("POST"))
+		Expect(request.Path).To(Equal("/v3/mail/send"))
+		Expect(request.Authorization).To(Equal("Bearer some-api-key"))
+		Expect(request.ContentType).To(Equal("application/json"))
+		Expect(request.Body).To(MatchJSON(`{
+			"personalizations": [{"to": [{"email": "user@example.com"}]}],
+			"from": {"email": "no-reply@example.com", "name": "Notifications"},
+			"reply_to": {"email": "sender@example.com"},
+			"subject": "Your build failed",
+			"content": [
+				{"type": "text/plain", "value": "plain body"},
+				{"type": "text/html", "value": "<p>html body</p>"}
+			],
+			"headers": {
+				"X-CF-Client-ID": "some-client",
+				"List-Unsubscribe": "<https://example.com/unsubscribe/token>"
+			},
+			"categories": ["build-failure"]
+		}`))
+
+		Expect(buffer.String()).To(ContainSubstring("sendgrid-message-id"))
+	})
+
+	It("sends at most ten categories", func() {
+		msg.Categories = []string{"1", "2", "3", "4", "5", "6", "7", "8", "9", "10", "11"}
+
+		Expect(client.Send(msg, logger)).To(Succeed())
+
+		var payload struct {
+			Categories []string `json:"categories"`
+		}
+		Expect(json.Unmarshal(request.Body, &payload)).To(Succeed())
+		Expect(payload.Categories).To(HaveLen(10))
+	})
+
+	It("does not send anything in test mode", func() {
+		client = mail.NewSendGridClient(mail.SendGridConfig{
+			URL:      server.URL,
+			TestMode: true,
+		}, http.DefaultClient)
+
+		Expect(client.Send(msg, logger)).To(Succeed())
+		Expect(request.Path).To(BeEmpty())
+	})
+
+	It("returns the errors reported by the API", func() {
+		status = http.StatusBadRequest
+		response = `{"errors":[{"message":"The from address does not match a verified Sender Identity."}]}`
+
+		err := client.Send(msg, logger)
+		Expect(err).To(Equal(mail.SendGridError{
+			StatusCode: http.StatusBadRequest,
+			Messages:   []string{"The from address does not match a verified Sender Identity."},
+		}))
+		Expect(err).To(MatchError("sendgrid: unexpected status 400: The from address does not match a verified Sender Identity."))
+	})
+
+	It("reports throttled requests as unavailable so they are retried", func() {
+		status = http.StatusTooManyRequests
+
+		err := client.Send(msg, logger)
+		Expect(err).To(Equal(mail.UnavailableError{Err: mail.SendGridError{StatusCode: http.StatusTooManyRequests}}))
+	})
+
+	It("rejects a recipient that is not an email address", func() {
+		msg.To = "not an address"
+
+		err := client.Send(msg, logger)
+		Expect(err).To(MatchError(ContainSubstring(`invalid recipient "not an address"`)))
+		Expect(request.Path).To(BeEmpty())
+	})
+})
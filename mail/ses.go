@@ -0,0 +1,176 @@
+package mail
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/util"
+	"github.com/pivotal-golang/lager"
+)
+
+// sesTagValueInvalidCharacters matches what SES does not allow in the value
+// of a message tag.
+var sesTagValueInvalidCharacters = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+type clock interface {
+	Now() time.Time
+}
+
+type SESConfig struct {
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+	Endpoint        string
+	TestMode        bool
+
+	// ConfigurationSet names the SES configuration set whose event
+	// destinations receive sending, delivery, bounce and complaint events.
+	ConfigurationSet string
+}
+
+// SESClient sends messages through the Amazon SES v2 SendEmail API as raw
+// MIME, so they carry the same headers as messages sent over SMTP.
+type SESClient struct {
+	config     SESConfig
+	httpClient *http.Client
+	clock      clock
+}
+
+type SESError struct {
+	StatusCode int
+	Type       string
+	Message    string
+}
+
+func (e SESError) Error() string {
+	return fmt.Sprintf("ses: unexpected status %d: %s: %s", e.StatusCode, e.Type, e.Message)
+}
+
+type sesTag struct {
+	Name  string `json:"Name"`
+	Value string `json:"Value"`
+}
+
+type sesRequest struct {
+	FromEmailAddress string `json:"FromEmailAddress"`
+	Destination      struct {
+		ToAddresses []string `json:"ToAddresses"`
+	} `json:"Destination"`
+	ReplyToAddresses []string `json:"ReplyToAddresses,omitempty"`
+	Content          struct {
+		Raw struct {
+			Data string `json:"Data"`
+		} `json:"Raw"`
+	} `json:"Content"`
+	ConfigurationSetName string   `json:"ConfigurationSetName,omitempty"`
+	EmailTags            []sesTag `json:"EmailTags,omitempty"`
+}
+
+func NewSESClient(config SESConfig, httpClient *http.Client, clock clock) *SESClient {
+	if config.Region == "" {
+		config.Region = "us-east-1"
+	}
+
+	if config.Endpoint == "" {
+		config.Endpoint = fmt.Sprintf("https://email.%s.amazonaws.com", config.Region)
+	}
+
+	return &SESClient{
+		config:     config,
+		httpClient: httpClient,
+		clock:      clock,
+	}
+}
+
+// Connect has nothing to do, as every message is sent in its own request.
+func (c *SESClient) Connect(logger lager.Logger) error {
+	return nil
+}
+
+func (c *SESClient) Send(msg Message, logger lager.Logger) error {
+	logger = logger.Session("ses")
+
+	if c.config.TestMode {
+		logger.Info("test-mode")
+		return nil
+	}
+
+	var payload sesRequest
+	payload.FromEmailAddress = msg.From
+	payload.Destination.ToAddresses = []string{msg.To}
+	payload.Content.Raw.Data = base64.StdEncoding.EncodeToString([]byte(msg.Data()))
+	payload.ConfigurationSetName = c.config.ConfigurationSet
+
+	if msg.ReplyTo != "" {
+		payload.ReplyToAddresses = []string{msg.ReplyTo}
+	}
+
+	if len(msg.Categories) > 0 {
+		payload.EmailTags = []sesTag{{
+			Name:  "kind_id",
+			Value: sesTagValueInvalidCharacters.ReplaceAllString(msg.Categories[0], "_"),
+		}}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	request, err := http.NewRequest("POST", strings.TrimSuffix(c.config.Endpoint, "/")+"/v2/email/outbound-emails", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	request.Header.Set("Content-Type", "application/json")
+	util.AWSSigner{
+		AccessKeyID:     c.config.AccessKeyID,
+		SecretAccessKey: c.config.SecretAccessKey,
+		Region:          c.config.Region,
+		Service:         "ses",
+	}.Sign(request, body, c.clock.Now())
+
+	response, err := c.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	responseBody, _ := ioutil.ReadAll(response.Body)
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		var errorResponse struct {
+			Message string `json:"message"`
+		}
+		json.Unmarshal(responseBody, &errorResponse)
+
+		sesErr := SESError{
+			StatusCode: response.StatusCode,
+			Type:       strings.SplitN(response.Header.Get("X-Amzn-Errortype"), ":", 2)[0],
+			Message:    errorResponse.Message,
+		}
+
+		// Throttled and unavailable requests succeed when tried again later.
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= 500 {
+			return UnavailableError{Err: sesErr}
+		}
+
+		return sesErr
+	}
+
+	var result struct {
+		MessageID string `json:"MessageId"`
+	}
+	json.Unmarshal(responseBody, &result)
+
+	logger.Info("accepted", lager.Data{"message_id": result.MessageID})
+
+	return nil
+}
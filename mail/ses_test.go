@@ -0,0 +1,149 @@
+package mail_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SESClient", func() {
+	var (
+		client   *mail.SESClient
+		server   *httptest.Server
+		clock    *mocks.Clock
+		logger   lager.Logger
+		buffer   *bytes.Buffer
+		msg      mail.Message
+		status   int
+		response string
+		headers  http.Header
+		request  *http.Request
+		body     []byte
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		response = `{"MessageId":"ses-message-id"}`
+		headers = http.Header{}
+		request = nil
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			request = req
+			body, _ = ioutil.ReadAll(req.Body)
+
+			for name, values := range headers {
+				w.Header()[name] = values
+			}
+			w.WriteHeader(status)
+			w.Write([]byte(response))
+		}))
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2015, time.March, 7, 12, 30, 0, 0, time.UTC)
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		client = mail.NewSESClient(mail.SESConfig{
+			Region:           "eu-west-1",
+			AccessKeyID:      "some-access-key",
+			SecretAccessKey:  "some-secret-key",
+			Endpoint:         server.URL,
+			ConfigurationSet: "notifications-events",
+		}, http.DefaultClient, clock)
+
+		msg = mail.Message{
+			From:    "no-reply@example.com",
+			ReplyTo: "sender@example.com",
+			To:      "user@example.com",
+			Subject: "Your build failed",
+			Body: []mail.Part{
+				{ContentType: "text/plain", Content: "plain body"},
+			},
+			Headers:    []string{"X-CF-Client-ID: some-client"},
+			Categories: []string{"build.failure"},
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("sends the raw message through the SendEmail API with a signed request", func() {
+		err := client.Send(msg, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(request.Method).To(Equal("POST"))
+		Expect(request.URL.Path).To(Equal("/v2/email/outbound-emails"))
+		Expect(request.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(request.Header.Get("X-Amz-Date")).To(Equal("20150307T123000Z"))
+		Expect(request.Header.Get("Authorization")).To(HavePrefix("AWS4-HMAC-SHA256 Credential=some-access-key/20150307/eu-west-1/ses/aws4_request, "))
+
+		var payload struct {
+			FromEmailAddress     string
+			Destination          struct{ ToAddresses []string }
+			ReplyToAddresses     []string
+			Content              struct{ Raw struct{ Data string } }
+			ConfigurationSetName string
+			EmailTags            []struct{ Name, Value string }
+		}
+		Expect(json.Unmarshal(body, &payload)).To(Succeed())
+
+		Expect(payload.FromEmailAddress).To(Equal("no-reply@example.com"))
+		Expect(payload.Destination.ToAddresses).To(Equal([]string{"user@example.com"}))
+		Expect(payload.ReplyToAddresses).To(Equal([]string{"sender@example.com"}))
+		Expect(payload.ConfigurationSetName).To(Equal("notifications-events"))
+		Expect(payload.EmailTags).To(HaveLen(1))
+		Expect(payload.EmailTags[0].Name).To(Equal("kind_id"))
+		Expect(payload.EmailTags[0].Value).To(Equal("build_failure"))
+
+		raw, err := base64.StdEncoding.DecodeString(payload.Content.Raw.Data)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(raw)).To(ContainSubstring("X-CF-Client-ID: some-client"))
+		Expect(string(raw)).To(ContainSubstring("Subject: Your build failed"))
+		Expect(string(raw)).To(ContainSubstring("plain body"))
+
+		Expect(buffer.String()).To(ContainSubstring("ses-message-id"))
+	})
+
+	It("does not send anything in test mode", func() {
+		client = mail.NewSESClient(mail.SESConfig{Endpoint: server.URL, TestMode: true}, http.DefaultClient, clock)
+
+		Expect(client.Send(msg, logger)).To(Succeed())
+		Expect(request).To(BeNil())
+	})
+
+	It("reports throttled requests as unavailable so they are retried", func() {
+		status = http.StatusTooManyRequests
+		response = `{"message":"Maximum sending rate exceeded."}`
+		headers.Set("X-Amzn-Errortype", "TooManyRequestsException:http://internal.amazon.com/coral/com.amazonaws.sesv2/")
+
+		err := client.Send(msg, logger)
+		Expect(err).To(Equal(mail.UnavailableError{Err: mail.SESError{
+			StatusCode: http.StatusTooManyRequests,
+			Type:       "TooManyRequestsException",
+			Message:    "Maximum sending rate exceeded.",
+		}}))
+	})
+
+	It("returns other errors as they are", func() {
+		status = http.StatusBadRequest
+		response = `{"message":"Email address is not verified."}`
+		headers.Set("X-Amzn-Errortype", "MessageRejected")
+
+		err := client.Send(msg, logger)
+		Expect(err).To(MatchError("ses: unexpected status 400: MessageRejected: Email address is not verified."))
+	})
+})
@@ -0,0 +1,70 @@
+package mail
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var TLSVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+// TLSPolicyError means a message was not sent because the connection to the
+// server could not meet the configured TLS policy.
+type TLSPolicyError struct {
+	Err error
+}
+
+func (e TLSPolicyError) Error() string {
+	return "TLS policy not met: " + e.Err.Error()
+}
+
+func (e TLSPolicyError) Unwrap() error {
+	return e.Err
+}
+
+// PublicKeyPin returns the pin of a certificate as accepted in
+// Config.PinnedPublicKeys: the base64 encoded SHA-256 digest of its
+// SubjectPublicKeyInfo.
+func PublicKeyPin(certificate *x509.Certificate) string {
+	digest := sha256.Sum256(certificate.RawSubjectPublicKeyInfo)
+	return base64.StdEncoding.EncodeToString(digest[:])
+}
+
+func (c *Client) tlsConfig() *tls.Config {
+	config := &tls.Config{
+		ServerName:         c.host,
+		InsecureSkipVerify: c.config.SkipVerifySSL,
+		Certificates:       c.config.ClientCertificates,
+		MinVersion:         c.config.MinTLSVersion,
+		RootCAs:            c.config.RootCAs,
+	}
+
+	if len(c.config.PinnedPublicKeys) > 0 {
+		config.VerifyConnection = c.verifyPin
+	}
+
+	return config
+}
+
+func (c *Client) verifyPin(state tls.ConnectionState) error {
+	if len(state.PeerCertificates) == 0 {
+		return errors.New("server presented no certificate")
+	}
+
+	pin := PublicKeyPin(state.PeerCertificates[0])
+	for _, pinned := range c.config.PinnedPublicKeys {
+		if pin == pinned {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("certificate public key %s is not pinned", pin)
+}
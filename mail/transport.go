@@ -0,0 +1,48 @@
+package mail
+
+import "github.com/pivotal-golang/lager"
+
+const (
+	TransportSMTP     = "smtp"
+	TransportSendGrid = "sendgrid"
+	TransportSES      = "ses"
+)
+
+var Transports = []string{TransportSMTP, TransportSendGrid, TransportSES}
+
+// Sender delivers rendered messages. The SMTP Client and the HTTP API
+// transports all satisfy it.
+type Sender interface {
+	Connect(logger lager.Logger) error
+	Send(msg Message, logger lager.Logger) error
+}
+
+// UnavailableError means the transport turned a message away for now, for
+// example because the sending rate was throttled, and it can be sent again
+// later.
+type UnavailableError struct {
+	Err error
+}
+
+func (e UnavailableError) Error() string {
+	return "transport unavailable: " + e.Err.Error()
+}
+
+func (e UnavailableError) Unwrap() error {
+	return e.Err
+}
+
+// RejectedError means the mail server refused the message itself, when it
+// was given the sender, the recipient or the data, rather than failing the
+// session it was sent in. It wraps the server's reply.
+type RejectedError struct {
+	Err error
+}
+
+func (e RejectedError) Error() string {
+	return "message rejected: " + e.Err.Error()
+}
+
+func (e RejectedError) Unwrap() error {
+	return e.Err
+}
@@ -4,37 +4,101 @@ import (
 	"crypto/rand"
 	"database/sql"
 	"log"
+	"net"
 	"os"
 	"path"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/cron"
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/httpclient"
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/postal/v1"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/util"
 	v1models "github.com/cloudfoundry-incubator/notifications/v1/models"
-	"github.com/pivotal-golang/conceal"
 	"github.com/pivotal-golang/lager"
 )
 
+type messageArchiver interface {
+	Archive(clientID, messageID string, mime []byte) error
+}
+
+type jobScheduler interface {
+	Add(job cron.Job)
+}
+
+type preferencesCache interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string, ttl time.Duration) error
+	Del(key string) error
+}
+
 type Config struct {
-	UAAClientID          string
-	UAAClientSecret      string
-	UAATokenValidator    *uaa.TokenValidator
-	UAAHost              string
-	VerifySSL            bool
-	InstanceIndex        int
-	WorkerCount          int
-	EncryptionKey        []byte
-	DBLoggingEnabled     bool
-	RootPath             string
-	Sender               string
-	Domain               string
-	QueueWaitMaxDuration int
-	CCHost               string
+	UAAClientID            string
+	UAAClientSecret        string
+	UAATokenValidator      *uaa.TokenValidator
+	UAAHost                string
+	VerifySSL              bool
+	HTTPClients            httpclient.Shared
+	InstanceIndex          int
+	InstanceID             string
+	EncryptionKey          []byte
+	PreviousEncryptionKeys [][]byte
+	DBLoggingEnabled       bool
+	RootPath               string
+	Sender                 string
+	Domain                 string
+	QueueWaitMaxDuration   int
+	QueueFreshWeight       int
+	QueueRetryWeight       int
+	QueueRedisURL          string
+	CCHost                 string
+	Archiver               messageArchiver
+	WebhookSigningKey      []byte
+	RecordUserMessages     bool
+	UnsubscribeURL         string
+	TrackingURL            string
+	HTMLSizeLimit          int
+	HTMLTextFallback       bool
+	ContentLint            bool
+	ValidateRecipientMX    bool
+	PreferencesCache       preferencesCache
+	Scheduler              jobScheduler
+
+	// WorkerPool runs the delivery workers, resizing itself to the queue
+	// when it autoscales.
+	WorkerPool *WorkerPool
+
+	// FastLane, when set, is served by FastLaneWorkers workers of its own
+	// alongside the queue workers.
+	FastLane        *FastLane
+	FastLaneWorkers int
+
+	// RecipientDomainRateLimit is the number of messages per second each
+	// instance sends to a recipient domain, unless RecipientDomainLimits
+	// overrides it. 0 leaves domains unlimited.
+	RecipientDomainRateLimit int
+	RecipientDomainLimits    map[string]int
+
+	// RecipientDailyLimit is the number of non-critical messages each user
+	// is sent per day, across all clients. Messages over it are held for a
+	// digest or dropped, as RecipientLimitOverflow says. 0 disables it.
+	RecipientDailyLimit    int
+	RecipientLimitOverflow string
+
+	// RetryBackoff is the schedule failed deliveries are retried on, unless
+	// the class of the failure has its own in RetryErrorClasses.
+	RetryBackoff      common.Backoff
+	RetryErrorClasses map[string]common.Backoff
+
+	// QueueSLA is the longest the jobs of each priority may wait before
+	// they are escalated. SurgeWorkers is the number of workers an instance
+	// may add while the queue is in breach of it.
+	QueueSLA     map[int]time.Duration
+	SurgeWorkers int
 }
 
 func database(db *sql.DB, dbLoggingEnabled bool, rootPath string) db.DatabaseInterface {
@@ -49,8 +113,8 @@ func database(db *sql.DB, dbLoggingEnabled bool, rootPath string) db.DatabaseInt
 	return database
 }
 
-func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
-	uaaClient := uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.VerifySSL, config.UAATokenValidator)
+func Boot(mailClient func() mail.Sender, db *sql.DB, config Config) {
+	uaaClient := uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.HTTPClients.Client("uaa"), config.UAATokenValidator)
 
 	logger := lager.NewLogger("notifications")
 	logger.RegisterSink(lager.NewWriterSink(os.Stdout, lager.DEBUG))
@@ -60,11 +124,17 @@ func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
 	database := database(db, config.DBLoggingEnabled, config.RootPath)
 
 	gobbleDatabase := gobble.NewDatabase(db)
-	gobbleQueue := gobble.NewQueue(gobbleDatabase, clock, gobble.Config{
+	gobbleQueue, err := gobble.NewBackend(db, clock, gobble.Config{
 		WaitMaxDuration: time.Duration(config.QueueWaitMaxDuration) * time.Millisecond,
+		FreshWeight:     config.QueueFreshWeight,
+		RetryWeight:     config.QueueRetryWeight,
+		RedisURL:        config.QueueRedisURL,
 	})
+	if err != nil {
+		panic(err)
+	}
 
-	cloak, err := conceal.NewCloak(config.EncryptionKey)
+	cloak, err := common.NewKeyring(config.EncryptionKey, config.PreviousEncryptionKeys...)
 	if err != nil {
 		panic(err)
 	}
@@ -75,27 +145,66 @@ func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
 	receiptsRepo := v1models.NewReceiptsRepo()
 	unsubscribesRepo := v1models.NewUnsubscribesRepo()
 	globalUnsubscribesRepo := v1models.NewGlobalUnsubscribesRepo()
+	subscriptionsRepo := v1models.NewSubscriptionsRepo()
 	messagesRepo := v1models.NewMessagesRepo(guidGenerator.Generate)
 	clientsRepo := v1models.NewClientsRepo()
 	kindsRepo := v1models.NewKindsRepo()
 	templatesRepo := v1models.NewTemplatesRepo()
-	v1TemplateLoader := v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo)
-	deliveryFailureHandler := common.NewDeliveryFailureHandler()
+	templateTranslationsRepo := v1models.NewTemplateTranslationsRepo()
+	templatePartialsRepo := v1models.NewTemplatePartialsRepo()
+	v1TemplateLoader := v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo, templateTranslationsRepo, templatePartialsRepo)
+	deliveryFailureHandler := common.NewDeliveryFailureHandler(config.RetryBackoff, config.RetryErrorClasses)
 	messageStatusUpdater := v1.NewMessageStatusUpdater(messagesRepo)
 	userLoader := common.NewUserLoader(uaaClient)
 	tokenLoader := uaa.NewTokenLoader(uaaClient)
 	packager := common.NewPackager(v1TemplateLoader, cloak)
+	unsubscribeTokens := common.NewUnsubscribeTokens(cloak, cloak.Keys()...)
+	userMessagesRepo := v1models.NewUserMessagesRepo()
+	digestPreferencesRepo := v1models.NewDigestPreferencesRepo()
+	digestEntriesRepo := v1models.NewDigestEntriesRepo()
+	kindActivityRepo := v1models.NewKindActivityRepo()
+	deliveryEventPublisher := v1.NewDeliveryEventPublisher(gobbleQueue, gobbleDatabase.Connection, clock)
 
-	WorkerGenerator{
-		InstanceIndex: config.InstanceIndex,
-		Count:         config.WorkerCount,
-	}.Work(func(index int) Worker {
+	// Webhooks and Slack routes post to URLs that clients choose, so they
+	// are kept off the internal network and always verify certificates.
+	webhookJobProcessor := v1.NewWebhookJobProcessor(httpclient.NewPublic(10*time.Second), config.WebhookSigningKey, clock, deliveryFailureHandler)
+	slackJobProcessor := v1.NewSlackJobProcessor(v1.SlackJobProcessorConfig{
+		Sender: config.Sender,
+		Domain: config.Domain,
 
-		v1DeliveryJobProcessor := v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
-			DBTrace: config.DBLoggingEnabled,
-			UAAHost: config.UAAHost,
-			Sender:  config.Sender,
-			Domain:  config.Domain,
+		Client:   httpclient.NewPublic(10 * time.Second),
+		Packager: packager,
+		Database: database,
+
+		KindsRepo:              kindsRepo,
+		ClientsRepo:            clientsRepo,
+		MessageStatusUpdater:   messageStatusUpdater,
+		DeliveryFailureHandler: deliveryFailureHandler,
+		DeliveryEventPublisher: deliveryEventPublisher,
+		KindActivityRepo:       kindActivityRepo,
+	})
+
+	var domainThrottle *common.DomainThrottle
+	if config.RecipientDomainRateLimit > 0 || len(config.RecipientDomainLimits) > 0 {
+		throttle := common.NewDomainThrottle(config.RecipientDomainRateLimit, config.RecipientDomainLimits, clock)
+		domainThrottle = &throttle
+	}
+
+	newDeliveryJobProcessor := func() v1.DeliveryJobProcessor {
+		processorConfig := v1.DeliveryJobProcessorConfig{
+			DBTrace:        config.DBLoggingEnabled,
+			UAAHost:        config.UAAHost,
+			Sender:         config.Sender,
+			Domain:         config.Domain,
+			UnsubscribeURL: config.UnsubscribeURL,
+
+			HTMLSizeLimit:    config.HTMLSizeLimit,
+			HTMLTextFallback: config.HTMLTextFallback,
+			ContentLint:      config.ContentLint,
+
+			RecipientDailyLimit: config.RecipientDailyLimit,
+			RecipientOverflow:   config.RecipientLimitOverflow,
+			RecipientCountsRepo: v1models.NewRecipientDailyCountsRepo(),
 
 			Packager:    packager,
 			MailClient:  mailClient(),
@@ -104,24 +213,133 @@ func Boot(mailClient func() *mail.Client, db *sql.DB, config Config) {
 			UserLoader:  userLoader,
 
 			KindsRepo:              kindsRepo,
+			ClientsRepo:            clientsRepo,
 			ReceiptsRepo:           receiptsRepo,
 			UnsubscribesRepo:       unsubscribesRepo,
 			GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+			SubscriptionsRepo:      subscriptionsRepo,
 			MessageStatusUpdater:   messageStatusUpdater,
 			DeliveryFailureHandler: deliveryFailureHandler,
-		})
+			DeliveryEventPublisher: deliveryEventPublisher,
+			MessagesRepo:           messagesRepo,
+			UnsubscribeTokens:      unsubscribeTokens,
+			Archiver:               config.Archiver,
+			KindActivityRepo:       kindActivityRepo,
+			DigestPreferencesRepo:  digestPreferencesRepo,
+			DigestEntriesRepo:      digestEntriesRepo,
 
-		worker := NewDeliveryWorker(v1DeliveryJobProcessor, DeliveryWorkerConfig{
-			ID:      index,
-			UAAHost: config.UAAHost,
-			DBTrace: config.DBLoggingEnabled,
+			SeverityUnsubscribesRepo: v1models.NewSeverityUnsubscribesRepo(),
+		}
+
+		if domainThrottle != nil {
+			processorConfig.DomainThrottle = domainThrottle
+		}
+
+		if config.TrackingURL != "" {
+			processorConfig.Tracker = common.NewTracker(common.NewTrackingTokens(cloak, cloak.Keys()...), config.TrackingURL)
+		}
+
+		if config.ValidateRecipientMX {
+			processorConfig.AddressValidator = mail.NewAddressValidator(net.DefaultResolver)
+		}
+
+		if config.RecordUserMessages {
+			processorConfig.UserMessagesRepo = userMessagesRepo
+		}
+
+		if config.PreferencesCache != nil {
+			processorConfig.UnsubscribesRepo = v1models.NewCachedUnsubscribesRepo(unsubscribesRepo, config.PreferencesCache)
+			processorConfig.GlobalUnsubscribesRepo = v1models.NewCachedGlobalUnsubscribesRepo(globalUnsubscribesRepo, config.PreferencesCache)
+		}
+
+		return v1.NewDeliveryJobProcessor(processorConfig)
+	}
+
+	newDeliveryWorker := func(index int) DeliveryWorker {
+		return NewDeliveryWorker(newDeliveryJobProcessor(), DeliveryWorkerConfig{
+			ID:         index,
+			InstanceID: config.InstanceID,
+			UAAHost:    config.UAAHost,
+			DBTrace:    config.DBLoggingEnabled,
 
 			DeliveryFailureHandler: deliveryFailureHandler,
+			WebhookJobProcessor:    webhookJobProcessor,
+			SlackJobProcessor:      slackJobProcessor,
 
 			Logger: logger.Session("worker", lager.Data{"worker_id": index}),
 			Queue:  gobbleQueue,
 		})
+	}
+
+	// Workers are numbered within the capacity of the pool, so that no two
+	// instances share an ID however large their pools grow.
+	firstWorkerID := config.InstanceIndex*config.WorkerPool.Capacity() + 1
+	config.WorkerPool.Start(gobbleQueue, func(index int, stop <-chan struct{}) {
+		worker := newDeliveryWorker(firstWorkerID + index)
+		worker.WorkUntil(stop)
+	}, logger.Session("worker-pool"))
+
+	if config.WorkerPool.Autoscaling() || config.WorkerPool.Shaped() {
+		go config.WorkerPool.Run(time.Tick(WorkerPoolInterval))
+	}
 
-		return &worker
+	if len(config.QueueSLA) > 0 {
+		slaMonitor := gobble.NewSLAMonitor(gobbleQueue, clock, config.QueueSLA)
+
+		config.Scheduler.Add(cron.Job{
+			Name:  QueueSLAJob,
+			Every: time.Minute,
+			Run:   NewQueueSLAEscalator(slaMonitor, logger.Session("queue-sla")).Run,
+		})
+
+		// Surge workers are numbered after the regular workers of this
+		// instance.
+		if config.SurgeWorkers > 0 {
+			firstID := (config.InstanceIndex+1)*config.WorkerPool.Capacity() + 1
+			surge := NewSurgeWorkers(slaMonitor, config.SurgeWorkers, func(index int, stop <-chan struct{}) {
+				worker := newDeliveryWorker(firstID + index)
+				worker.WorkUntil(stop)
+			}, logger.Session("queue-sla"))
+
+			go surge.Run(time.Tick(time.Minute))
+		}
+	}
+
+	if config.FastLane != nil {
+		WorkerGenerator{
+			InstanceIndex: config.InstanceIndex,
+			Count:         config.FastLaneWorkers,
+		}.Work(func(index int) Worker {
+			return NewFastLaneWorker(FastLaneWorkerConfig{
+				ID:         index,
+				InstanceID: config.InstanceID,
+				Lane:       config.FastLane,
+				Processor:  newDeliveryJobProcessor(),
+				Queue:      gobbleQueue,
+				Connection: gobbleDatabase.Connection,
+				Clock:      clock,
+				Logger:     logger.Session("fast-lane", lager.Data{"worker_id": index}),
+			})
+		})
+	}
+
+	// The scheduler runs its jobs on one instance, so that no user gets
+	// their digest twice.
+	digestScheduler := NewDigestScheduler(DigestSchedulerConfig{
+		Sender:    config.Sender,
+		BatchSize: 100,
+
+		Database:      database,
+		MailClient:    mailClient(),
+		Entries:       digestEntriesRepo,
+		Templates:     templatesRepo,
+		StatusUpdater: messageStatusUpdater,
+		Clock:         clock,
+		Logger:        logger.Session("digest"),
+	})
+	config.Scheduler.Add(cron.Job{
+		Name:  DigestDispatcherJob,
+		Every: time.Minute,
+		Run:   digestScheduler.Send,
 	})
 }
@@ -0,0 +1,54 @@
+package common
+
+import (
+	"errors"
+	"net/textproto"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+)
+
+var enhancedStatusFormat = regexp.MustCompile(`^[245]\.\d{1,3}\.\d{1,3}\b`)
+
+// SMTPReply is what a mail server answered when it rejected a message: the
+// reply code and, when the server gave one, the RFC 3463 enhanced status
+// code, such as "5.1.1".
+type SMTPReply struct {
+	Code           int
+	EnhancedStatus string
+}
+
+// Permanent tells a hard bounce, which will fail the same way every time,
+// from a soft bounce that may succeed later.
+func (r SMTPReply) Permanent() bool {
+	return r.Code >= 500
+}
+
+// Bounce returns the reply of a mail server that rejected the message. It
+// returns false for other failures, such as a lost connection or a server
+// that refused to authenticate, which say nothing about the message.
+func Bounce(err error) (SMTPReply, bool) {
+	var rejectedErr mail.RejectedError
+	if !errors.As(err, &rejectedErr) {
+		return SMTPReply{}, false
+	}
+
+	var smtpErr *textproto.Error
+	if !errors.As(err, &smtpErr) || smtpErr.Code < 400 || smtpErr.Code >= 600 {
+		return SMTPReply{}, false
+	}
+
+	return SMTPReply{
+		Code:           smtpErr.Code,
+		EnhancedStatus: enhancedStatusFormat.FindString(smtpErr.Msg),
+	}, true
+}
+
+// DSNReply returns the reply that a delivery status notification reports
+// for a recipient. The code is 0 when the report does not quote one.
+func DSNReply(recipient mail.DSNRecipient) SMTPReply {
+	return SMTPReply{
+		Code:           recipient.SMTPCode(),
+		EnhancedStatus: enhancedStatusFormat.FindString(recipient.Status),
+	}
+}
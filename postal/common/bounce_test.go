@@ -0,0 +1,62 @@
+package common_test
+
+import (
+	"errors"
+	"net/textproto"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bounce", func() {
+	It("returns the reply of a server that rejected the message", func() {
+		reply, ok := common.Bounce(mail.RejectedError{Err: &textproto.Error{Code: 550, Msg: "5.1.1 <nobody@example.com>: Recipient address rejected"}})
+		Expect(ok).To(BeTrue())
+		Expect(reply).To(Equal(common.SMTPReply{Code: 550, EnhancedStatus: "5.1.1"}))
+		Expect(reply.Permanent()).To(BeTrue())
+	})
+
+	It("tells soft bounces from hard ones", func() {
+		reply, ok := common.Bounce(mail.RejectedError{Err: &textproto.Error{Code: 452, Msg: "4.2.2 mailbox full"}})
+		Expect(ok).To(BeTrue())
+		Expect(reply).To(Equal(common.SMTPReply{Code: 452, EnhancedStatus: "4.2.2"}))
+		Expect(reply.Permanent()).To(BeFalse())
+	})
+
+	It("leaves the enhanced status empty when the server gave none", func() {
+		reply, ok := common.Bounce(mail.RejectedError{Err: &textproto.Error{Code: 554, Msg: "Transaction failed"}})
+		Expect(ok).To(BeTrue())
+		Expect(reply).To(Equal(common.SMTPReply{Code: 554}))
+	})
+
+	It("does not count failures of the session as bounces", func() {
+		_, ok := common.Bounce(&textproto.Error{Code: 535, Msg: "5.7.8 authentication failed"})
+		Expect(ok).To(BeFalse())
+
+		_, ok = common.Bounce(mail.RejectedError{Err: errors.New("connection reset by peer")})
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("DSNReply", func() {
+	It("returns the reply a delivery status notification quotes", func() {
+		reply := common.DSNReply(mail.DSNRecipient{
+			Action:         mail.DSNActionFailed,
+			Status:         "5.2.2 (mailbox full)",
+			DiagnosticCode: "smtp; 552 5.2.2 Mailbox full",
+		})
+		Expect(reply).To(Equal(common.SMTPReply{Code: 552, EnhancedStatus: "5.2.2"}))
+	})
+
+	It("leaves the code out when the report does not quote an SMTP reply", func() {
+		reply := common.DSNReply(mail.DSNRecipient{
+			Action:         mail.DSNActionFailed,
+			Status:         "5.4.7",
+			DiagnosticCode: "X-Postfix; delivery temporarily suspended",
+		})
+		Expect(reply).To(Equal(common.SMTPReply{EnhancedStatus: "5.4.7"}))
+	})
+})
@@ -0,0 +1,74 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+)
+
+var (
+	// Placeholders that survived rendering, such as {{name}} in text a client
+	// expected to be substituted, or the <no value> that a missing map key
+	// renders as. The escaped and URL encoded forms cover HTML and links.
+	unreplacedPlaceholder = regexp.MustCompile(`(?i)\{\{[^{}]*\}\}|%7B%7B.*?%7D%7D|<no value>|&lt;no value&gt;`)
+
+	invisibleHTML = regexp.MustCompile(`(?is)<head.*?</head>|<style.*?</style>|<script.*?</script>|<!--.*?-->`)
+	htmlTag       = regexp.MustCompile(`(?s)<[^>]*>`)
+	htmlImage     = regexp.MustCompile(`(?i)<img\b`)
+)
+
+// ContentError reports a rendered message that should not be sent because
+// it would reach its recipient visibly broken.
+type ContentError struct {
+	Reason string
+	Detail string
+}
+
+func (e ContentError) Error() string {
+	return fmt.Sprintf("message content is broken (%s): %s", e.Reason, e.Detail)
+}
+
+// LintMessage checks the rendered message for an empty subject, an empty
+// body, or placeholders that were not replaced.
+func LintMessage(message mail.Message) error {
+	if strings.TrimSpace(message.Subject) == "" {
+		return ContentError{Reason: ReasonEmptySubject, Detail: "the subject is empty"}
+	}
+
+	if placeholder := unreplacedPlaceholder.FindString(message.Subject); placeholder != "" {
+		return ContentError{Reason: ReasonUnreplacedPlaceholder, Detail: fmt.Sprintf("the subject contains %q", placeholder)}
+	}
+
+	empty := true
+	for _, part := range message.Body {
+		if placeholder := unreplacedPlaceholder.FindString(part.Content); placeholder != "" {
+			return ContentError{Reason: ReasonUnreplacedPlaceholder, Detail: fmt.Sprintf("the %s part contains %q", part.ContentType, placeholder)}
+		}
+
+		if !blankPart(part) {
+			empty = false
+		}
+	}
+
+	if empty {
+		return ContentError{Reason: ReasonEmptyBody, Detail: "the body is empty"}
+	}
+
+	return nil
+}
+
+func blankPart(part mail.Part) bool {
+	if part.ContentType != "text/html" {
+		return strings.TrimSpace(part.Content) == ""
+	}
+
+	content := invisibleHTML.ReplaceAllString(part.Content, "")
+	if htmlImage.MatchString(content) {
+		return false
+	}
+
+	text := strings.ReplaceAll(htmlTag.ReplaceAllString(content, ""), "&nbsp;", "")
+	return strings.TrimSpace(text) == ""
+}
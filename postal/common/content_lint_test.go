@@ -0,0 +1,91 @@
+package common_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("LintMessage", func() {
+	var message mail.Message
+
+	BeforeEach(func() {
+		message = mail.Message{
+			Subject: "Your app is down",
+			Body: []mail.Part{
+				{ContentType: "text/plain", Content: "The app stopped responding."},
+				{ContentType: "text/html", Content: "<html><body><p>The app stopped responding.</p></body></html>"},
+			},
+		}
+	})
+
+	It("passes a message that rendered completely", func() {
+		Expect(common.LintMessage(message)).To(Succeed())
+	})
+
+	It("rejects an empty subject", func() {
+		message.Subject = "  "
+
+		Expect(common.LintMessage(message)).To(MatchError(common.ContentError{
+			Reason: common.ReasonEmptySubject,
+			Detail: "the subject is empty",
+		}))
+	})
+
+	DescribeTable("rejects placeholders that were not replaced",
+		func(content string) {
+			message.Body[0].Content = content
+
+			err := common.LintMessage(message)
+			Expect(err).To(BeAssignableToTypeOf(common.ContentError{}))
+			Expect(err.(common.ContentError).Reason).To(Equal(common.ReasonUnreplacedPlaceholder))
+		},
+		Entry("a mustache placeholder", "Hello {{name}},"),
+		Entry("a missing template value", "Hello <no value>,"),
+		Entry("an escaped missing template value", "Hello &lt;no value&gt;,"),
+		Entry("a URL encoded placeholder", "https://example.com/apps/%7B%7Bapp_guid%7D%7D"),
+	)
+
+	It("rejects a placeholder in the subject", func() {
+		message.Subject = "{{app}} is down"
+
+		Expect(common.LintMessage(message)).To(MatchError(common.ContentError{
+			Reason: common.ReasonUnreplacedPlaceholder,
+			Detail: `the subject contains "{{app}}"`,
+		}))
+	})
+
+	Describe("empty bodies", func() {
+		It("rejects a message without parts", func() {
+			message.Body = nil
+
+			Expect(common.LintMessage(message)).To(MatchError(common.ContentError{
+				Reason: common.ReasonEmptyBody,
+				Detail: "the body is empty",
+			}))
+		})
+
+		It("rejects blank text with HTML that shows nothing", func() {
+			message.Body[0].Content = "\n"
+			message.Body[1].Content = "<html><head><style>p { color: red; }</style></head><body><p>&nbsp;</p></body></html>"
+
+			err := common.LintMessage(message)
+			Expect(err).To(BeAssignableToTypeOf(common.ContentError{}))
+			Expect(err.(common.ContentError).Reason).To(Equal(common.ReasonEmptyBody))
+		})
+
+		It("accepts HTML that only shows an image", func() {
+			message.Body = []mail.Part{{ContentType: "text/html", Content: `<p><img src="https://example.com/outage.png"></p>`}}
+
+			Expect(common.LintMessage(message)).To(Succeed())
+		})
+
+		It("accepts a message when only one of its parts has content", func() {
+			message.Body[1].Content = "<p></p>"
+
+			Expect(common.LintMessage(message)).To(Succeed())
+		})
+	})
+})
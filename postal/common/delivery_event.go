@@ -0,0 +1,22 @@
+package common
+
+import "time"
+
+const DeliveryEventJobType = "delivery-event"
+
+// DeliveryEvent records a change in the status of a message so that it can
+// be posted to the callback URL of the client that sent it.
+type DeliveryEvent struct {
+	JobType         string
+	CallbackURL     string
+	MessageID       string
+	ClientID        string
+	KindID          string
+	Recipient       string
+	Status          string
+	Simulated       bool
+	Severity        string
+	Metadata        map[string]string
+	RequestReceived time.Time
+	OccurredAt      time.Time
+}
@@ -1,31 +1,121 @@
 package common
 
 import (
+	"errors"
 	"math"
+	"math/rand"
+	"net/textproto"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/pivotal-golang/lager"
 	"github.com/rcrowley/go-metrics"
 )
 
+const DefaultMaxRetries = 10
+
+const (
+	ErrorClassSMTPTemporary = "smtp_4xx"
+	ErrorClassSMTPPermanent = "smtp_5xx"
+	ErrorClassTLSPolicy     = "tls_policy"
+	ErrorClassUnavailable   = "unavailable"
+)
+
 type Retryable interface {
 	Retry(duration time.Duration)
 	State() (retryCount int, activeAt time.Time)
 }
 
-type DeliveryFailureHandler struct{}
+// RetryPolicy overrides the default exponential backoff. A zero MaxAttempts
+// keeps the default number of retries and a zero Interval keeps the
+// exponential backoff. ErrorClass selects the backoff configured for that
+// class of failure, if there is one.
+type RetryPolicy struct {
+	MaxAttempts int
+	Interval    time.Duration
+	ErrorClass  string
+}
+
+// Backoff retries a job after Interval, multiplying the wait by Multiplier
+// on each attempt, until MaxRetries attempts have been made. Jitter moves
+// each wait up or down by at most that fraction of it, so that jobs that
+// failed together are not all retried together. Zero fields keep the
+// defaults of 10 retries starting at one minute and doubling.
+type Backoff struct {
+	MaxRetries int
+	Interval   time.Duration
+	Multiplier float64
+	Jitter     float64
+}
+
+func (b Backoff) withDefaults(defaults Backoff) Backoff {
+	if b.MaxRetries <= 0 {
+		b.MaxRetries = defaults.MaxRetries
+	}
+
+	if b.Interval <= 0 {
+		b.Interval = defaults.Interval
+	}
+
+	if b.Multiplier <= 0 {
+		b.Multiplier = defaults.Multiplier
+	}
+
+	if b.Jitter <= 0 {
+		b.Jitter = defaults.Jitter
+	}
+
+	return b
+}
+
+type DeliveryFailureHandler struct {
+	backoff   Backoff
+	overrides map[string]Backoff
+	random    func() float64
+}
+
+func NewDeliveryFailureHandler(backoff Backoff, overrides map[string]Backoff) DeliveryFailureHandler {
+	backoff = backoff.withDefaults(Backoff{
+		MaxRetries: DefaultMaxRetries,
+		Interval:   time.Minute,
+		Multiplier: 2,
+	})
 
-func NewDeliveryFailureHandler() DeliveryFailureHandler {
-	return DeliveryFailureHandler{}
+	return DeliveryFailureHandler{
+		backoff:   backoff,
+		overrides: overrides,
+		random:    rand.Float64,
+	}
 }
 
 func (h DeliveryFailureHandler) Handle(job Retryable, logger lager.Logger) {
+	h.HandleWithPolicy(job, RetryPolicy{}, logger)
+}
+
+func (h DeliveryFailureHandler) HandleWithPolicy(job Retryable, policy RetryPolicy, logger lager.Logger) {
+	backoff := h.backoff
+	if override, ok := h.overrides[policy.ErrorClass]; ok {
+		backoff = override.withDefaults(h.backoff)
+	}
+
+	maxRetries := backoff.MaxRetries
+	if policy.MaxAttempts > 0 {
+		maxRetries = policy.MaxAttempts
+	}
+
 	retryCount, _ := job.State()
-	if retryCount > 9 {
+	if retryCount >= maxRetries {
 		return
 	}
 
-	duration := time.Duration(int64(math.Pow(2, float64(retryCount)))) * time.Minute
+	duration := policy.Interval
+	if duration <= 0 {
+		duration = time.Duration(float64(backoff.Interval) * math.Pow(backoff.Multiplier, float64(retryCount)))
+	}
+
+	if backoff.Jitter > 0 {
+		duration += time.Duration(float64(duration) * backoff.Jitter * (2*h.random() - 1))
+	}
 	job.Retry(duration)
 
 	retryCount, activeAt := job.State()
@@ -36,3 +126,30 @@ func (h DeliveryFailureHandler) Handle(job Retryable, logger lager.Logger) {
 
 	metrics.GetOrRegisterCounter("notifications.worker.retry", nil).Inc(1)
 }
+
+// ErrorClass names the kind of delivery failure err is, so that each kind
+// can be retried on its own schedule. Failures that fit none of the classes
+// return an empty string and use the default backoff.
+func ErrorClass(err error) string {
+	var policyErr mail.TLSPolicyError
+	if errors.As(err, &policyErr) {
+		return ErrorClassTLSPolicy
+	}
+
+	var unavailableErr mail.UnavailableError
+	if errors.As(err, &unavailableErr) {
+		return ErrorClassUnavailable
+	}
+
+	var smtpErr *textproto.Error
+	if errors.As(err, &smtpErr) {
+		switch {
+		case smtpErr.Code >= 400 && smtpErr.Code < 500:
+			return ErrorClassSMTPTemporary
+		case smtpErr.Code >= 500 && smtpErr.Code < 600:
+			return ErrorClassSMTPPermanent
+		}
+	}
+
+	return ""
+}
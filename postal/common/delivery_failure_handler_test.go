@@ -2,8 +2,12 @@ package common_test
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
+	"net/textproto"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/pivotal-golang/lager"
@@ -26,7 +30,7 @@ var _ = Describe("DeliveryFailureHandler", func() {
 		logger = lager.NewLogger("notifications")
 		logger.RegisterSink(lager.NewWriterSink(buffer, lager.INFO))
 
-		handler = common.NewDeliveryFailureHandler()
+		handler = common.NewDeliveryFailureHandler(common.Backoff{}, nil)
 	})
 
 	It("retries the job using an exponential backoff algorithm", func() {
@@ -82,4 +86,125 @@ var _ = Describe("DeliveryFailureHandler", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(activeAt.UTC()).To(Equal(expectedActiveAt.UTC()))
 	})
+
+	Context("when the kind has a retry policy", func() {
+		It("retries at the fixed interval", func() {
+			job.StateCall.Returns.Count = 7
+
+			handler.HandleWithPolicy(job, common.RetryPolicy{Interval: 5 * time.Minute}, logger)
+
+			Expect(job.RetryCall.Receives.Duration).To(Equal(5 * time.Minute))
+		})
+
+		It("gives up after the maximum number of attempts", func() {
+			job.StateCall.Returns.Count = 3
+
+			handler.HandleWithPolicy(job, common.RetryPolicy{MaxAttempts: 3}, logger)
+
+			Expect(job.RetryCall.WasCalled).To(BeFalse())
+		})
+
+		It("keeps retrying beyond the default limit when allowed", func() {
+			job.StateCall.Returns.Count = 100
+
+			handler.HandleWithPolicy(job, common.RetryPolicy{MaxAttempts: 288, Interval: 5 * time.Minute}, logger)
+
+			Expect(job.RetryCall.WasCalled).To(BeTrue())
+			Expect(job.RetryCall.Receives.Duration).To(Equal(5 * time.Minute))
+		})
+	})
+
+	Context("when the backoff is configured", func() {
+		BeforeEach(func() {
+			handler = common.NewDeliveryFailureHandler(common.Backoff{
+				MaxRetries: 4,
+				Interval:   30 * time.Second,
+				Multiplier: 3,
+			}, map[string]common.Backoff{
+				common.ErrorClassSMTPTemporary: {
+					MaxRetries: 6,
+					Interval:   10 * time.Second,
+				},
+			})
+		})
+
+		It("retries on the configured schedule", func() {
+			job.StateCall.Returns.Count = 2
+
+			handler.Handle(job, logger)
+
+			Expect(job.RetryCall.Receives.Duration).To(Equal(270 * time.Second))
+		})
+
+		It("gives up after the configured number of retries", func() {
+			job.StateCall.Returns.Count = 4
+
+			handler.Handle(job, logger)
+
+			Expect(job.RetryCall.WasCalled).To(BeFalse())
+		})
+
+		It("uses the schedule of the error class, filling in what it leaves out", func() {
+			job.StateCall.Returns.Count = 5
+
+			handler.HandleWithPolicy(job, common.RetryPolicy{ErrorClass: common.ErrorClassSMTPTemporary}, logger)
+
+			Expect(job.RetryCall.Receives.Duration).To(Equal(10 * time.Second * 243))
+		})
+
+		It("uses the configured schedule for error classes without one", func() {
+			job.StateCall.Returns.Count = 4
+
+			handler.HandleWithPolicy(job, common.RetryPolicy{ErrorClass: common.ErrorClassTLSPolicy}, logger)
+
+			Expect(job.RetryCall.WasCalled).To(BeFalse())
+		})
+
+		It("lets the kind's retry policy take precedence", func() {
+			job.StateCall.Returns.Count = 5
+
+			handler.HandleWithPolicy(job, common.RetryPolicy{
+				MaxAttempts: 8,
+				Interval:    time.Minute,
+				ErrorClass:  common.ErrorClassSMTPTemporary,
+			}, logger)
+
+			Expect(job.RetryCall.Receives.Duration).To(Equal(time.Minute))
+		})
+	})
+
+	Context("when the backoff has jitter", func() {
+		It("spreads the retries around the backoff", func() {
+			handler = common.NewDeliveryFailureHandler(common.Backoff{Jitter: 0.5}, nil)
+			job.StateCall.Returns.Count = 2
+
+			durations := map[time.Duration]bool{}
+			for i := 0; i < 20; i++ {
+				handler.Handle(job, logger)
+
+				duration := job.RetryCall.Receives.Duration
+				Expect(duration).To(BeNumerically(">=", 2*time.Minute))
+				Expect(duration).To(BeNumerically("<=", 6*time.Minute))
+				durations[duration] = true
+			}
+
+			Expect(len(durations)).To(BeNumerically(">", 1))
+		})
+	})
+
+	Describe("ErrorClass", func() {
+		It("classifies SMTP replies by their code", func() {
+			Expect(common.ErrorClass(&textproto.Error{Code: 421, Msg: "try again later"})).To(Equal(common.ErrorClassSMTPTemporary))
+			Expect(common.ErrorClass(&textproto.Error{Code: 550, Msg: "no such user"})).To(Equal(common.ErrorClassSMTPPermanent))
+		})
+
+		It("classifies TLS policy failures and unavailable transports", func() {
+			Expect(common.ErrorClass(mail.TLSPolicyError{Err: errors.New("no STARTTLS")})).To(Equal(common.ErrorClassTLSPolicy))
+			Expect(common.ErrorClass(fmt.Errorf("wrapped: %w", mail.UnavailableError{Err: errors.New("throttled")}))).To(Equal(common.ErrorClassUnavailable))
+		})
+
+		It("leaves other errors unclassified", func() {
+			Expect(common.ErrorClass(errors.New("connection refused"))).To(BeEmpty())
+		})
+	})
 })
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+)
+
+type DigestEntry struct {
+	MessageID         string
+	Subject           string
+	Text              string
+	HTML              string
+	KindDescription   string
+	SourceDescription string
+	RequestReceived   time.Time
+}
+
+// DigestContext is what the digest template is rendered with. Count is the
+// number of Entries, which are ordered oldest first.
+type DigestContext struct {
+	From    string
+	To      string
+	Count   int
+	Entries []DigestEntry
+}
+
+// Escape prepares the entries for the HTML part. The HTML of each entry is
+// trusted as it is for single messages, and entries sent as text only get
+// their escaped text instead.
+func (context *DigestContext) Escape() {
+	entries := make([]DigestEntry, len(context.Entries))
+	for i, entry := range context.Entries {
+		entry.Subject = html.EscapeString(entry.Subject)
+		entry.Text = html.EscapeString(entry.Text)
+		entry.KindDescription = html.EscapeString(entry.KindDescription)
+		entry.SourceDescription = html.EscapeString(entry.SourceDescription)
+		if entry.HTML == "" {
+			entry.HTML = "<p>" + entry.Text + "</p>"
+		}
+		entries[i] = entry
+	}
+
+	context.From = html.EscapeString(context.From)
+	context.To = html.EscapeString(context.To)
+	context.Entries = entries
+}
+
+// PackDigest renders the collected entries of a user into a single message
+// with the digest template.
+func PackDigest(context DigestContext, templates Templates) (mail.Message, error) {
+	subject, err := compileDigestTemplate(templates.Subject, context)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	text, err := compileDigestTemplate(templates.Text, context)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	escaped := context
+	escaped.Escape()
+
+	htmlBody, err := compileDigestTemplate(templates.HTML, escaped)
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	return mail.Message{
+		From:    mail.FormatAddress(context.From),
+		To:      context.To,
+		Subject: subject,
+		Body: []mail.Part{
+			{
+				ContentType: "text/plain",
+				Content:     text,
+			},
+			{
+				ContentType: "text/html",
+				Content:     "<!DOCTYPE html>\n<html>\n\t<body>\n\t\t" + htmlBody + "\n\t</body>\n</html>",
+			},
+		},
+		Headers: []string{
+			fmt.Sprintf("X-CF-Notification-Digest-Count: %d", context.Count),
+			fmt.Sprintf("X-CF-Notification-Timestamp: %s", time.Now().Format(time.RFC3339Nano)),
+		},
+		Categories: []string{"digest"},
+	}, nil
+}
+
+func compileDigestTemplate(theTemplate string, context DigestContext) (string, error) {
+	source, err := template.New("digest").Parse(theTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err = source.Execute(buffer, context)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSuffix(buffer.String(), "\n"), nil
+}
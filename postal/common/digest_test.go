@@ -0,0 +1,79 @@
+package common_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PackDigest", func() {
+	var (
+		context   common.DigestContext
+		templates common.Templates
+	)
+
+	BeforeEach(func() {
+		context = common.DigestContext{
+			From:  "Cloud Foundry <no-reply@example.com>",
+			To:    "user@example.com",
+			Count: 2,
+			Entries: []common.DigestEntry{
+				{
+					MessageID:         "message-1",
+					Subject:           "Fences & gates",
+					Text:              "The <fence> is down",
+					SourceDescription: "Jurassic Park",
+					KindDescription:   "Perimeter",
+				},
+				{
+					MessageID:         "message-2",
+					Subject:           "Feeding time",
+					Text:              "text only",
+					HTML:              "<p>Raptors are <b>hungry</b></p>",
+					SourceDescription: "Jurassic Park",
+					KindDescription:   "Feeding",
+				},
+			},
+		}
+
+		templates = common.Templates{
+			Subject: "{{.Count}} notifications for {{.To}}",
+			Text:    "{{range .Entries}}{{.Subject}}: {{.Text}}\n{{end}}",
+			HTML:    "{{range .Entries}}<h3>{{.Subject}}</h3>{{.HTML}}{{end}}",
+		}
+	})
+
+	It("renders every entry into a single message", func() {
+		message, err := common.PackDigest(context, templates)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(message.From).To(Equal("\"Cloud Foundry\" <no-reply@example.com>"))
+		Expect(message.To).To(Equal("user@example.com"))
+		Expect(message.Subject).To(Equal("2 notifications for user@example.com"))
+		Expect(message.Categories).To(Equal([]string{"digest"}))
+		Expect(message.Headers).To(ContainElement("X-CF-Notification-Digest-Count: 2"))
+		Expect(message.Body).To(HaveLen(2))
+		Expect(message.Body[0]).To(Equal(mail.Part{
+			ContentType: "text/plain",
+			Content:     "Fences & gates: The <fence> is down\nFeeding time: text only",
+		}))
+	})
+
+	It("escapes the entries in the HTML part, except for their own HTML", func() {
+		message, err := common.PackDigest(context, templates)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(message.Body[1].ContentType).To(Equal("text/html"))
+		Expect(message.Body[1].Content).To(ContainSubstring("<h3>Fences &amp; gates</h3><p>The &lt;fence&gt; is down</p>"))
+		Expect(message.Body[1].Content).To(ContainSubstring("<h3>Feeding time</h3><p>Raptors are <b>hungry</b></p>"))
+	})
+
+	It("returns an error when the template cannot be parsed", func() {
+		templates.Subject = "{{.Count"
+
+		_, err := common.PackDigest(context, templates)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,86 @@
+package common
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"time"
+)
+
+type clock interface {
+	Now() time.Time
+}
+
+type domainBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// DomainThrottle limits how many messages per second are sent to each
+// recipient domain, since some providers tempfail senders that burst. Each
+// domain may send up to one second's worth of messages at once. Buckets
+// live in memory, so each instance enforces the limits independently.
+type DomainThrottle struct {
+	rate      float64
+	overrides map[string]float64
+	clock     clock
+	mutex     *sync.Mutex
+	buckets   map[string]*domainBucket
+}
+
+// NewDomainThrottle limits every domain to perSecond messages, except the
+// domains in overrides. A rate of 0 leaves the domain unlimited.
+func NewDomainThrottle(perSecond int, overrides map[string]int, clock clock) DomainThrottle {
+	normalized := map[string]float64{}
+	for domain, rate := range overrides {
+		normalized[strings.ToLower(domain)] = float64(rate)
+	}
+
+	return DomainThrottle{
+		rate:      float64(perSecond),
+		overrides: normalized,
+		clock:     clock,
+		mutex:     &sync.Mutex{},
+		buckets:   map[string]*domainBucket{},
+	}
+}
+
+// Take reserves a send to the domain of the email address. When the domain
+// is over its limit, it returns how long to wait before trying again.
+func (t DomainThrottle) Take(email string) (time.Duration, bool) {
+	domain := strings.ToLower(email[strings.LastIndex(email, "@")+1:])
+
+	rate, ok := t.overrides[domain]
+	if !ok {
+		rate = t.rate
+	}
+
+	if rate <= 0 {
+		return 0, true
+	}
+
+	burst := rate
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	now := t.clock.Now()
+	bucket, ok := t.buckets[domain]
+	if !ok {
+		bucket = &domainBucket{tokens: burst, updatedAt: now}
+		t.buckets[domain] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(burst, bucket.tokens+elapsed*rate)
+		bucket.updatedAt = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - bucket.tokens) / rate * float64(time.Second)), false
+}
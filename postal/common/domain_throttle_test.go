@@ -0,0 +1,88 @@
+package common_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DomainThrottle", func() {
+	var (
+		throttle common.DomainThrottle
+		clock    *mocks.Clock
+		now      time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		throttle = common.NewDomainThrottle(2, map[string]int{
+			"Gmail.com":       10,
+			"unlimited.local": 0,
+		}, clock)
+	})
+
+	It("allows a second's worth of messages to a domain before throttling it", func() {
+		_, ok := throttle.Take("first@example.com")
+		Expect(ok).To(BeTrue())
+
+		_, ok = throttle.Take("second@EXAMPLE.com")
+		Expect(ok).To(BeTrue())
+
+		wait, ok := throttle.Take("third@example.com")
+		Expect(ok).To(BeFalse())
+		Expect(wait).To(Equal(500 * time.Millisecond))
+
+		clock.NowCall.Returns.Time = now.Add(500 * time.Millisecond)
+
+		_, ok = throttle.Take("third@example.com")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("limits each domain separately", func() {
+		throttle.Take("first@example.com")
+		throttle.Take("second@example.com")
+
+		_, ok := throttle.Take("someone@example.org")
+		Expect(ok).To(BeTrue())
+	})
+
+	It("uses the override for the domain", func() {
+		for i := 0; i < 10; i++ {
+			_, ok := throttle.Take("user@gmail.com")
+			Expect(ok).To(BeTrue())
+		}
+
+		wait, ok := throttle.Take("user@gmail.com")
+		Expect(ok).To(BeFalse())
+		Expect(wait).To(Equal(100 * time.Millisecond))
+	})
+
+	It("does not limit domains with a rate of 0", func() {
+		for i := 0; i < 100; i++ {
+			_, ok := throttle.Take("user@unlimited.local")
+			Expect(ok).To(BeTrue())
+		}
+	})
+
+	Context("when there is no default rate", func() {
+		It("only limits the overridden domains", func() {
+			throttle = common.NewDomainThrottle(0, map[string]int{"gmail.com": 1}, clock)
+
+			for i := 0; i < 100; i++ {
+				_, ok := throttle.Take("user@example.com")
+				Expect(ok).To(BeTrue())
+			}
+
+			throttle.Take("user@gmail.com")
+			_, ok := throttle.Take("user@gmail.com")
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
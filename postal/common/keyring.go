@@ -0,0 +1,60 @@
+package common
+
+import (
+	"errors"
+
+	"github.com/pivotal-golang/conceal"
+)
+
+// Keyring encrypts with its primary key and decrypts with whichever of its
+// keys works, so that unsubscribe and revert links sent before the
+// ENCRYPTION_KEY was rotated keep working. The cloak does not authenticate
+// what it decrypts, but its plaintext is base64, which a wrong key turns
+// into something that fails to decode.
+type Keyring struct {
+	keys   [][]byte
+	cloaks []conceal.Cloak
+}
+
+func NewKeyring(primary []byte, previous ...[]byte) (Keyring, error) {
+	keyring := Keyring{}
+
+	for _, key := range append([][]byte{primary}, previous...) {
+		cloak, err := conceal.NewCloak(key)
+		if err != nil {
+			return Keyring{}, err
+		}
+
+		keyring.keys = append(keyring.keys, key)
+		keyring.cloaks = append(keyring.cloaks, cloak)
+	}
+
+	return keyring, nil
+}
+
+// Keys lists the primary key first.
+func (k Keyring) Keys() [][]byte {
+	return k.keys
+}
+
+func (k Keyring) Veil(data []byte) ([]byte, error) {
+	if len(k.cloaks) == 0 {
+		return nil, errors.New("the keyring has no keys")
+	}
+
+	return k.cloaks[0].Veil(data)
+}
+
+func (k Keyring) Unveil(data []byte) ([]byte, error) {
+	err := errors.New("the keyring has no keys")
+
+	for _, cloak := range k.cloaks {
+		var plainText []byte
+		plainText, err = cloak.Unveil(data)
+		if err == nil {
+			return plainText, nil
+		}
+	}
+
+	return nil, err
+}
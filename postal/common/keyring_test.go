@@ -0,0 +1,63 @@
+package common_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/pivotal-golang/conceal"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Keyring", func() {
+	var (
+		keyring     common.Keyring
+		oldCloak    conceal.Cloak
+		primaryOnly common.Keyring
+	)
+
+	BeforeEach(func() {
+		var err error
+		keyring, err = common.NewKeyring([]byte("new-secret"), []byte("old-secret"), []byte("older-secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		primaryOnly, err = common.NewKeyring([]byte("new-secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		oldCloak, err = conceal.NewCloak([]byte("old-secret"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("encrypts with the primary key", func() {
+		cipherText, err := keyring.Veil([]byte("user-123|raptors|feeding-time"))
+		Expect(err).NotTo(HaveOccurred())
+
+		plainText, err := primaryOnly.Unveil(cipherText)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plainText)).To(Equal("user-123|raptors|feeding-time"))
+	})
+
+	It("decrypts what a previous key encrypted", func() {
+		cipherText, err := oldCloak.Veil([]byte("user-123|raptors|feeding-time"))
+		Expect(err).NotTo(HaveOccurred())
+
+		plainText, err := keyring.Unveil(cipherText)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(plainText)).To(Equal("user-123|raptors|feeding-time"))
+	})
+
+	It("fails to decrypt what none of its keys encrypted", func() {
+		cipherText, err := oldCloak.Veil([]byte("user-123|raptors|feeding-time"))
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = primaryOnly.Unveil(cipherText)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("lists the primary key first", func() {
+		Expect(keyring.Keys()).To(Equal([][]byte{
+			[]byte("new-secret"),
+			[]byte("old-secret"),
+			[]byte("older-secret"),
+		}))
+	})
+})
@@ -0,0 +1,27 @@
+package common
+
+import (
+	"regexp"
+	"strings"
+)
+
+var linkHostPattern = regexp.MustCompile(`(?i)\b(https?://)([a-z0-9.-]+)`)
+
+// RewriteLinkDomains replaces the host of every http and https URL in content
+// that appears in domains with the branded host it maps to. Hosts are matched
+// case-insensitively; ports, paths and query strings are left untouched.
+func RewriteLinkDomains(content string, domains map[string]string) string {
+	if len(domains) == 0 {
+		return content
+	}
+
+	return linkHostPattern.ReplaceAllStringFunc(content, func(link string) string {
+		match := linkHostPattern.FindStringSubmatch(link)
+		branded, ok := domains[strings.ToLower(match[2])]
+		if !ok {
+			return link
+		}
+
+		return match[1] + branded
+	})
+}
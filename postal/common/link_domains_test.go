@@ -0,0 +1,43 @@
+package common_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RewriteLinkDomains", func() {
+	var domains map[string]string
+
+	BeforeEach(func() {
+		domains = map[string]string{
+			"login.sys.example.com": "login.brand.example.com",
+			"apps.example.com":      "apps.brand.example.com",
+		}
+	})
+
+	It("rewrites the host of mapped links, keeping the scheme, port, path and query", func() {
+		content := `Visit http://apps.example.com:8080/dashboard?org=banana or <a href="https://LOGIN.sys.example.com/reset">reset</a>`
+
+		Expect(common.RewriteLinkDomains(content, domains)).To(Equal(
+			`Visit http://apps.brand.example.com:8080/dashboard?org=banana or <a href="https://login.brand.example.com/reset">reset</a>`,
+		))
+	})
+
+	It("leaves links to other hosts alone", func() {
+		content := "See https://docs.example.com/apps.example.com and https://sub.apps.example.com/"
+
+		Expect(common.RewriteLinkDomains(content, domains)).To(Equal(content))
+	})
+
+	It("leaves hostnames outside of links alone", func() {
+		content := "Mail support@apps.example.com about apps.example.com"
+
+		Expect(common.RewriteLinkDomains(content, domains)).To(Equal(content))
+	})
+
+	It("returns the content unchanged when there are no mappings", func() {
+		Expect(common.RewriteLinkDomains("https://apps.example.com", nil)).To(Equal("https://apps.example.com"))
+	})
+})
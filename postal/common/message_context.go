@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/pivotal-golang/conceal"
 )
 
@@ -20,13 +21,22 @@ type Options struct {
 	Role              string
 	Endorsement       string
 	TemplateID        string
+	CallbackURL       string
+	TraceParent       string
+	Priority          int
+	Severity          string
+	SimulationSinks   []string
+	Deadline          time.Time
+	Metadata          map[string]string
 }
 
 type Delivery struct {
+	JobType         string
 	MessageID       string
 	Options         Options
 	UserGUID        string
 	Email           string
+	Locale          string
 	Space           cf.CloudControllerSpace
 	Organization    cf.CloudControllerOrganization
 	ClientID        string
@@ -35,13 +45,41 @@ type Delivery struct {
 	VCAPRequestID   string
 	RequestReceived time.Time
 	CampaignID      string
+
+	// WorkerID and ClaimedAt come from the job being processed rather than
+	// its payload.
+	WorkerID  string    `json:"-"`
+	ClaimedAt time.Time `json:"-"`
+}
+
+// Simulated reports whether the delivery belongs to a client in simulation
+// mode, and is to be sent to the client's sinks instead of its recipient.
+func (d Delivery) Simulated() bool {
+	return len(d.Options.SimulationSinks) > 0
+}
+
+// Expired reports whether the delivery was picked up after the deadline of
+// the notification it belongs to. Deliveries without a deadline never expire.
+func (d Delivery) Expired(now time.Time) bool {
+	return !d.Options.Deadline.IsZero() && now.After(d.Options.Deadline)
 }
 
 type Templates struct {
-	Name    string
-	Subject string
-	Text    string
-	HTML    string
+	Name     string
+	Subject  string
+	Text     string
+	HTML     string
+	Slack    string
+	Partials []Partial
+}
+
+// Partial is a shared fragment that templates include with
+// {{template "name" .}}. HTML bodies include its HTML; the text body and
+// the subject include its Text.
+type Partial struct {
+	Name string
+	Text string
+	HTML string
 }
 
 type HTML struct {
@@ -62,10 +100,12 @@ type MessageContext struct {
 	TextTemplate      string
 	HTMLTemplate      string
 	SubjectTemplate   string
+	SlackTemplate     string
 	KindDescription   string
 	SourceDescription string
 	UserGUID          string
 	ClientID          string
+	KindID            string
 	MessageID         string
 	Space             string
 	SpaceGUID         string
@@ -77,6 +117,20 @@ type MessageContext struct {
 	OrganizationRole  string
 	RequestReceived   time.Time
 	Domain            string
+
+	// Severity is the severity of the notification, and SeverityBadge the
+	// label that prefixes the subject of those that are more than
+	// informational. Templates may show either.
+	Severity      string
+	SeverityBadge string
+
+	// Partials are defined alongside every template compiled for this
+	// message.
+	Partials []Partial
+
+	// LinkDomains rewrites links in the rendered bodies to the client's
+	// branded domains. See RewriteLinkDomains.
+	LinkDomains map[string]string
 }
 
 func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.CloakInterface, templates Templates) MessageContext {
@@ -107,10 +161,13 @@ func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.C
 		TextTemplate:      templates.Text,
 		HTMLTemplate:      templates.HTML,
 		SubjectTemplate:   templates.Subject,
+		SlackTemplate:     templates.Slack,
+		Partials:          templates.Partials,
 		KindDescription:   kindDescription,
 		SourceDescription: sourceDescription,
 		UserGUID:          delivery.UserGUID,
 		ClientID:          delivery.ClientID,
+		KindID:            options.KindID,
 		MessageID:         delivery.MessageID,
 		Space:             delivery.Space.Name,
 		SpaceGUID:         delivery.Space.GUID,
@@ -121,12 +178,20 @@ func NewMessageContext(delivery Delivery, sender, domain string, cloak conceal.C
 		OrganizationRole:  options.Role,
 		RequestReceived:   delivery.RequestReceived,
 		Domain:            domain,
+		Severity:          options.Severity,
+		SeverityBadge:     models.SeverityBadges[options.Severity],
 	}
 
 	if messageContext.Subject == "" {
 		messageContext.Subject = "[no subject]"
 	}
 
+	// A sink of a simulated delivery must not be able to unsubscribe the
+	// user it stands in for.
+	if delivery.Simulated() {
+		return messageContext
+	}
+
 	unsubscribeID, err := cloak.Veil([]byte(delivery.UserGUID + "|" + delivery.ClientID + "|" + options.KindID))
 	if err != nil {
 		panic(err)
@@ -104,6 +104,14 @@ var _ = Describe("MessageContext", func() {
 			Expect(context.Domain).To(Equal(domain))
 		})
 
+		It("leaves out the unsubscribe ID of simulated deliveries", func() {
+			delivery.Options.SimulationSinks = []string{"qa@partner.example.com"}
+			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
+
+			Expect(cloak.VeilCall.Receives.PlainText).To(BeNil())
+			Expect(context.UnsubscribeID).To(BeEmpty())
+		})
+
 		It("falls back to Kind if KindDescription is missing", func() {
 			delivery.Options.KindDescription = ""
 			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
@@ -118,6 +126,18 @@ var _ = Describe("MessageContext", func() {
 			Expect(context.SourceDescription).To(Equal("the-client-id"))
 		})
 
+		It("badges notifications by their severity", func() {
+			delivery.Options.Severity = "critical-action-required"
+			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			Expect(context.Severity).To(Equal("critical-action-required"))
+			Expect(context.SeverityBadge).To(Equal("[Action Required]"))
+
+			delivery.Options.Severity = "info"
+			context = common.NewMessageContext(delivery, sender, domain, cloak, templates)
+			Expect(context.Severity).To(Equal("info"))
+			Expect(context.SeverityBadge).To(BeEmpty())
+		})
+
 		It("fills in subject when subject is not specified", func() {
 			delivery.Options.Subject = ""
 			context := common.NewMessageContext(delivery, sender, domain, cloak, templates)
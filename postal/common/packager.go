@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strings"
 	"text/template"
+	"text/template/parse"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/mail"
@@ -20,12 +21,13 @@ const HTMLWrapperTemplate = `{{.HTMLComponents.Doctype}}
 </html>`
 
 type templatesLoader interface {
-	LoadTemplates(clientID, kindID, templateID string) (Templates, error)
+	LoadTemplates(clientID, kindID, templateID, locale string) (Templates, error)
 }
 
 type Packager struct {
 	templates templatesLoader
 	cloak     conceal.CloakInterface
+	strict    bool
 }
 
 func NewPackager(templates templatesLoader, cloak conceal.CloakInterface) Packager {
@@ -35,8 +37,16 @@ func NewPackager(templates templatesLoader, cloak conceal.CloakInterface) Packag
 	}
 }
 
+// Strict has templates that fail while rendering, such as by reading a
+// variable that does not exist, return the error rather than the output
+// rendered up to that point.
+func (packager Packager) Strict() Packager {
+	packager.strict = true
+	return packager
+}
+
 func (packager Packager) PrepareContext(delivery Delivery, sender, domain string) (MessageContext, error) {
-	templates, err := packager.templates.LoadTemplates(delivery.ClientID, delivery.Options.KindID, delivery.Options.TemplateID)
+	templates, err := packager.templates.LoadTemplates(delivery.ClientID, delivery.Options.KindID, delivery.Options.TemplateID, delivery.Locale)
 	if err != nil {
 		return MessageContext{}, err
 	}
@@ -55,8 +65,13 @@ func (packager Packager) Pack(context MessageContext) (mail.Message, error) {
 		return mail.Message{}, err
 	}
 
-	return mail.Message{
-		From:    context.From,
+	// Templates that show the badge themselves do not get it twice.
+	if context.SeverityBadge != "" && !strings.Contains(compiledSubject, context.SeverityBadge) {
+		compiledSubject = context.SeverityBadge + " " + compiledSubject
+	}
+
+	message := mail.Message{
+		From:    mail.FormatAddress(context.From),
 		ReplyTo: context.ReplyTo,
 		To:      context.To,
 		Subject: compiledSubject,
@@ -67,7 +82,40 @@ func (packager Packager) Pack(context MessageContext) (mail.Message, error) {
 			fmt.Sprintf("X-CF-Notification-Timestamp: %s", time.Now().Format(time.RFC3339Nano)),
 			fmt.Sprintf("X-CF-Notification-Request-Received: %s", context.RequestReceived.Format(time.RFC3339Nano)),
 		},
-	}, nil
+	}
+
+	if context.KindID != "" {
+		message.Categories = []string{context.KindID}
+	}
+
+	if context.Severity != "" {
+		message.Headers = append(message.Headers, fmt.Sprintf("X-CF-Notification-Severity: %s", context.Severity))
+	}
+
+	return message, nil
+}
+
+// PackSlack renders the text of the Slack post for a message.
+func (packager Packager) PackSlack(context MessageContext) (string, error) {
+	slackTemplate := context.SlackTemplate
+	if slackTemplate == "" {
+		slackTemplate = DefaultSlackTemplate
+	}
+
+	var err error
+	context.Endorsement, err = packager.compileTemplate(context, context.Endorsement, false)
+	if err != nil {
+		return "", err
+	}
+
+	context.EscapeSlack()
+
+	text, err := packager.compileTemplate(context, slackTemplate, false)
+	if err != nil {
+		return "", err
+	}
+
+	return RewriteLinkDomains(text, context.LinkDomains), nil
 }
 
 func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, error) {
@@ -87,7 +135,7 @@ func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, erro
 
 		parts = append(parts, mail.Part{
 			ContentType: "text/plain",
-			Content:     plainText,
+			Content:     RewriteLinkDomains(plainText, context.LinkDomains),
 		})
 
 	}
@@ -107,7 +155,7 @@ func (packager Packager) CompileParts(context MessageContext) ([]mail.Part, erro
 
 		parts = append(parts, mail.Part{
 			ContentType: "text/html",
-			Content:     htmlPart,
+			Content:     RewriteLinkDomains(htmlPart, context.LinkDomains),
 		})
 	}
 
@@ -122,12 +170,82 @@ func (packager Packager) compileTemplate(context MessageContext, theTemplate str
 		return "", err
 	}
 
+	for _, partial := range context.Partials {
+		body := partial.Text
+		if escapeContext {
+			body = partial.HTML
+		}
+
+		_, err = source.New(partial.Name).Parse(body)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	// Execute errors are not reported, so a missing partial would silently
+	// cut the message short; refuse to render it instead.
+	if name := undefinedTemplate(source, source.Tree.Root); name != "" {
+		return "", fmt.Errorf("template includes partial %q, which does not exist", name)
+	}
+
 	if escapeContext {
 		context.Escape()
 	}
 
-	source.Execute(buffer, context)
+	err = source.Execute(buffer, context)
+	if err != nil && packager.strict {
+		return "", err
+	}
 	compiledTemplate := strings.TrimSuffix(buffer.String(), "\n")
 
 	return compiledTemplate, nil
 }
+
+// undefinedTemplate returns the first template that node includes, directly
+// or through other partials, without it being defined.
+func undefinedTemplate(source *template.Template, node parse.Node) string {
+	return undefinedIncludes(source, node, map[string]bool{})
+}
+
+func undefinedIncludes(source *template.Template, node parse.Node, visited map[string]bool) string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return ""
+		}
+
+		for _, child := range n.Nodes {
+			if name := undefinedIncludes(source, child, visited); name != "" {
+				return name
+			}
+		}
+	case *parse.IfNode:
+		return undefinedInBranch(source, n.BranchNode, visited)
+	case *parse.RangeNode:
+		return undefinedInBranch(source, n.BranchNode, visited)
+	case *parse.WithNode:
+		return undefinedInBranch(source, n.BranchNode, visited)
+	case *parse.TemplateNode:
+		if visited[n.Name] {
+			return ""
+		}
+		visited[n.Name] = true
+
+		included := source.Lookup(n.Name)
+		if included == nil || included.Tree == nil {
+			return n.Name
+		}
+
+		return undefinedIncludes(source, included.Tree.Root, visited)
+	}
+
+	return ""
+}
+
+func undefinedInBranch(source *template.Template, branch parse.BranchNode, visited map[string]bool) string {
+	if name := undefinedIncludes(source, branch.List, visited); name != "" {
+		return name
+	}
+
+	return undefinedIncludes(source, branch.ElseList, visited)
+}
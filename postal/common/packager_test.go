@@ -29,6 +29,7 @@ var _ = Describe("Packager", func() {
 		delivery = common.Delivery{
 			UserGUID: "some-user-guid",
 			ClientID: "some-client-id",
+			Locale:   "fr-CA",
 			Options: common.Options{
 				Subject:    "Some crazy subject",
 				TemplateID: "some-template-id",
@@ -53,6 +54,7 @@ var _ = Describe("Packager", func() {
 			To:        "endless monkeys",
 			Subject:   "we will be eaten",
 			ClientID:  "3&3",
+			KindID:    "some-kind-id",
 			MessageID: "4'4",
 			Text:      "User <supplied> \"banana\" text",
 			UserGUID:  "user-123",
@@ -93,6 +95,7 @@ var _ = Describe("Packager", func() {
 			Expect(templatesLoader.LoadTemplatesCall.Receives.ClientID).To(Equal("some-client-id"))
 			Expect(templatesLoader.LoadTemplatesCall.Receives.KindID).To(Equal("some-kind-id"))
 			Expect(templatesLoader.LoadTemplatesCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(templatesLoader.LoadTemplatesCall.Receives.Locale).To(Equal("fr-CA"))
 
 			Expect(cloak.VeilCall.Receives.PlainText).To(Equal([]byte("some-user-guid|some-client-id|some-kind-id")))
 
@@ -103,6 +106,7 @@ var _ = Describe("Packager", func() {
 				Subject:       "Some crazy subject",
 				UserGUID:      "some-user-guid",
 				ClientID:      "some-client-id",
+				KindID:        "some-kind-id",
 				Text:          "some-text",
 				HTML:          "<p>user supplied banana html</p>",
 				HTMLComponents: common.HTML{
@@ -147,6 +151,7 @@ var _ = Describe("Packager", func() {
 					Content:     "<!DOCTYPE html>\n<head><title>The title</title></head>\n<html>\n\t<body class=\"bananaBody\">\n\t\t<header>This is an endorsement for the development space and banana org.</header>\nBanana preamble <p>user supplied banana html</p> User &lt;supplied&gt; &#34;banana&#34; text 3&amp;3 4&#39;4 user-123\n\t</body>\n</html>",
 				},
 			}))
+			Expect(msg.Categories).To(Equal([]string{"some-kind-id"}))
 			Expect(msg.Headers).To(ContainElement("X-CF-Client-ID: 3&3"))
 			Expect(msg.Headers).To(ContainElement("X-CF-Notification-ID: 4'4"))
 			Expect(msg.Headers).To(ContainElement("X-CF-Notification-Request-Received: 2015-06-08T14:38:03.180764129-07:00"))
@@ -167,6 +172,71 @@ var _ = Describe("Packager", func() {
 		})
 	})
 
+	It("leaves messages without a kind uncategorized", func() {
+		context.KindID = ""
+
+		msg, err := packager.Pack(context)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Categories).To(BeEmpty())
+	})
+
+	It("badges the subject of notifications that are more than informational", func() {
+		context.Severity = "warning"
+		context.SeverityBadge = "[Warning]"
+
+		msg, err := packager.Pack(context)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Subject).To(Equal("[Warning] The Subject: we will be eaten"))
+		Expect(msg.Headers).To(ContainElement("X-CF-Notification-Severity: warning"))
+
+		context.SubjectTemplate = "{{.SeverityBadge}} {{.Subject}} ({{.Severity}})"
+
+		msg, err = packager.Pack(context)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.Subject).To(Equal("[Warning] we will be eaten (warning)"))
+	})
+
+	It("encodes a non-ASCII sender display name", func() {
+		context.From = "Jürgen Müller <banana@example.com>"
+
+		msg, err := packager.Pack(context)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(msg.From).To(Equal("=?utf-8?q?J=C3=BCrgen_M=C3=BCller?= <banana@example.com>"))
+	})
+
+	Describe("PackSlack", func() {
+		It("renders the Slack template, escaping Slack control characters in variables", func() {
+			context.SlackTemplate = "*{{.Subject}}* {{.Text}} {{.ClientID}}\n{{.Endorsement}}"
+			context.Subject = "<!channel> fire & smoke"
+
+			text, err := packager.PackSlack(context)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(text).To(Equal("*&lt;!channel&gt; fire &amp; smoke* User &lt;supplied&gt; \"banana\" text 3&3\nThis is an endorsement for the development space and banana org."))
+		})
+
+		It("uses the default Slack template when the template has none", func() {
+			text, err := packager.PackSlack(context)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(text).To(Equal("*we will be eaten*\nUser &lt;supplied&gt; \"banana\" text"))
+		})
+
+		It("rewrites links to the client's branded domains", func() {
+			context.Text = "visit https://login.sys.example.com/reset"
+			context.LinkDomains = map[string]string{"login.sys.example.com": "login.example.com"}
+
+			text, err := packager.PackSlack(context)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(text).To(ContainSubstring("https://login.example.com/reset"))
+		})
+
+		It("returns template errors", func() {
+			context.SlackTemplate = "{{template \"missing\" .}}"
+
+			_, err := packager.PackSlack(context)
+			Expect(err).To(MatchError(`template includes partial "missing", which does not exist`))
+		})
+	})
+
 	Describe("CompileParts", func() {
 		It("returns the compiled parts containing both the plaintext and html portions, escaping variables for the html portion only", func() {
 			parts, err := packager.CompileParts(context)
@@ -240,5 +310,80 @@ Banana preamble <p>user supplied banana html</p>  3&amp;3 4&#39;4 user-123
 				}))
 			})
 		})
+
+		Context("when the templates include partials", func() {
+			BeforeEach(func() {
+				context.Text = "Hello"
+				context.HTML = "<p>Hello</p>"
+				context.TextTemplate = `{{.Text}}{{template "footer" .}}`
+				context.HTMLTemplate = `{{.HTML}}{{template "footer" .}}`
+				context.Partials = []common.Partial{
+					{
+						Name: "footer",
+						Text: "\n-- {{.ClientID}}",
+						HTML: `<footer>{{.ClientID}}{{template "button" .}}</footer>`,
+					},
+					{
+						Name: "button",
+						HTML: `<a class="button">Unsubscribe</a>`,
+					},
+				}
+			})
+
+			It("renders the text partial in the text portion and the html partial in the html portion", func() {
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts[0].Content).To(Equal("Hello\n-- 3&3"))
+				Expect(parts[1].Content).To(ContainSubstring(`<p>Hello</p><footer>3&amp;3<a class="button">Unsubscribe</a></footer>`))
+			})
+
+			It("returns an error when a template includes a partial that does not exist", func() {
+				context.HTMLTemplate = `{{.HTML}}{{if .Text}}{{template "header" .}}{{end}}`
+
+				_, err := packager.CompileParts(context)
+				Expect(err).To(MatchError(`template includes partial "header", which does not exist`))
+			})
+
+			It("returns an error when a partial includes a partial that does not exist", func() {
+				context.Partials = context.Partials[:1]
+
+				_, err := packager.CompileParts(context)
+				Expect(err).To(MatchError(`template includes partial "button", which does not exist`))
+			})
+		})
+
+		Context("when a template fails while rendering", func() {
+			BeforeEach(func() {
+				context.TextTemplate = "Banana preamble {{.Text}} {{.Recipient}}"
+			})
+
+			It("returns the output rendered up to the failure", func() {
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parts[0].Content).To(Equal("Banana preamble User <supplied> \"banana\" text "))
+			})
+
+			It("returns the error when the packager is strict", func() {
+				_, err := packager.Strict().CompileParts(context)
+				Expect(err).To(MatchError(ContainSubstring("can't evaluate field Recipient")))
+			})
+		})
+
+		Context("when the client has link domains", func() {
+			It("rewrites links in both portions to the branded domains", func() {
+				context.Text = "Log in at https://login.sys.example.com/login"
+				context.HTML = `<a href="https://login.sys.example.com/login">Log in</a>`
+				context.TextTemplate = "{{.Text}}"
+				context.HTMLTemplate = "{{.HTML}}"
+				context.LinkDomains = map[string]string{"login.sys.example.com": "login.brand.example.com"}
+
+				parts, err := packager.CompileParts(context)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(parts[0].Content).To(Equal("Log in at https://login.brand.example.com/login"))
+				Expect(parts[1].Content).To(ContainSubstring(`<a href="https://login.brand.example.com/login">Log in</a>`))
+			})
+		})
 	})
 })
@@ -0,0 +1,11 @@
+package common
+
+// What happens to a non-critical message once its recipient has been sent
+// their daily limit: it is held for a digest sent when the day is over, or
+// it is marked undeliverable.
+const (
+	RecipientOverflowDigest = "digest"
+	RecipientOverflowDrop   = "drop"
+)
+
+var RecipientOverflowModes = []string{RecipientOverflowDigest, RecipientOverflowDrop}
@@ -0,0 +1,32 @@
+package common
+
+import "time"
+
+const RegistrationEventJobType = "registration-event"
+
+// RegistrationEvent records a change to the kinds or templates registered
+// by a client so that it can be posted to the client's registration webhook.
+type RegistrationEvent struct {
+	JobType     string
+	CallbackURL string
+	ClientID    string
+	Actor       RegistrationActor
+	Changes     []RegistrationChange
+	OccurredAt  time.Time
+}
+
+// RegistrationActor identifies the token that made the change. UserID is
+// empty when the change was made with a client credentials token.
+type RegistrationActor struct {
+	ClientID string `json:"client_id"`
+	UserID   string `json:"user_id,omitempty"`
+}
+
+// RegistrationChange is a single field that changed. Path names the field,
+// such as "source_name" or "notifications.some-kind.critical". From is nil
+// when the field was added and To is nil when it was removed.
+type RegistrationChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
@@ -0,0 +1,28 @@
+package common
+
+import "strings"
+
+const SlackJobType = "slack"
+
+// DefaultSlackTemplate renders Slack posts for templates that do not have a
+// Slack body of their own.
+const DefaultSlackTemplate = "*{{.Subject}}*\n{{.Text}}"
+
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeSlack escapes the characters Slack treats as control sequences in
+// message text, so that user supplied values cannot ping channels or forge
+// links.
+func (context *MessageContext) EscapeSlack() {
+	for _, field := range []*string{
+		&context.Subject,
+		&context.Text,
+		&context.KindDescription,
+		&context.SourceDescription,
+		&context.Space,
+		&context.Organization,
+		&context.Endorsement,
+	} {
+		*field = slackEscaper.Replace(*field)
+	}
+}
@@ -1,9 +1,31 @@
 package common
 
 const (
-	StatusFailed        = "failed"
-	StatusRetry         = "retry"
-	StatusDelivered     = "delivered"
-	StatusQueued        = "queued"
-	StatusUndeliverable = "undeliverable"
+	StatusFailed          = "failed"
+	StatusTLSPolicyFailed = "tls_policy_failed"
+	StatusUnavailable     = "unavailable"
+	StatusRetry           = "retry"
+	StatusDelivered       = "delivered"
+	StatusQueued          = "queued"
+	StatusUndeliverable   = "undeliverable"
+	StatusCanceled        = "canceled"
+	StatusDigested        = "digested"
+	StatusExpired         = "expired"
+	StatusSoftBounced     = "soft_bounced"
+	StatusHardBounced     = "hard_bounced"
+	StatusConfirmed       = "confirmed"
+)
+
+// Reasons recorded for undeliverable messages.
+const (
+	ReasonUnsubscribed   = "unsubscribed"
+	ReasonNoAddress      = "no_address"
+	ReasonInvalidAddress = "invalid_address"
+	ReasonNoSlackWebhook = "no_slack_webhook"
+
+	ReasonEmptySubject          = "empty_subject"
+	ReasonEmptyBody             = "empty_body"
+	ReasonUnreplacedPlaceholder = "unreplaced_placeholder"
+
+	ReasonRecipientLimit = "recipient_limit"
 )
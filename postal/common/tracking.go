@@ -0,0 +1,174 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"html"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/conceal"
+)
+
+var (
+	trackedLinkPattern = regexp.MustCompile(`(?i)(<a\b[^>]*?\bhref\s*=\s*)(["'])(https?://[^"']+)(["'])`)
+	bodyClosePattern   = regexp.MustCompile(`(?i)</body\s*>`)
+)
+
+type InvalidTrackingTokenError struct{}
+
+func (e InvalidTrackingTokenError) Error() string {
+	return "The tracking link is invalid"
+}
+
+// TrackingEvent is what a tracking link records when it is requested: an
+// open of the message, or a click through to Target.
+type TrackingEvent struct {
+	Event     string
+	MessageID string
+	ClientID  string
+	KindID    string
+	Target    string
+}
+
+// TrackingTokens produces the tokens carried in tracking links. Like the
+// unsubscribe tokens, they are encrypted and signed, so that a link cannot
+// be altered to count against another message or to redirect elsewhere.
+type TrackingTokens struct {
+	cloak conceal.CloakInterface
+	keys  [][]byte
+}
+
+func NewTrackingTokens(cloak conceal.CloakInterface, keys ...[]byte) TrackingTokens {
+	return TrackingTokens{
+		cloak: cloak,
+		keys:  keys,
+	}
+}
+
+func (t TrackingTokens) Generate(event TrackingEvent) (string, error) {
+	payload := strings.Join([]string{event.Event, event.MessageID, event.ClientID, event.KindID, event.Target}, "|")
+	veiled, err := t.cloak.Veil([]byte(payload))
+	if err != nil {
+		return "", err
+	}
+
+	return string(veiled) + "." + t.sign(t.keys[0], veiled), nil
+}
+
+func (t TrackingTokens) Parse(token string) (TrackingEvent, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 || !t.signed(parts[0], parts[1]) {
+		return TrackingEvent{}, InvalidTrackingTokenError{}
+	}
+
+	plainText, err := t.cloak.Unveil([]byte(parts[0]))
+	if err != nil {
+		return TrackingEvent{}, InvalidTrackingTokenError{}
+	}
+
+	// The target is last, so that a "|" in it is kept.
+	fields := strings.SplitN(string(plainText), "|", 5)
+	if len(fields) != 5 || fields[1] == "" || fields[2] == "" {
+		return TrackingEvent{}, InvalidTrackingTokenError{}
+	}
+
+	event := TrackingEvent{
+		Event:     fields[0],
+		MessageID: fields[1],
+		ClientID:  fields[2],
+		KindID:    fields[3],
+		Target:    fields[4],
+	}
+
+	switch {
+	case event.Event == models.EngagementOpen && event.Target == "":
+	case event.Event == models.EngagementClick && event.Target != "":
+	default:
+		return TrackingEvent{}, InvalidTrackingTokenError{}
+	}
+
+	return event, nil
+}
+
+func (t TrackingTokens) signed(payload, signature string) bool {
+	for _, key := range t.keys {
+		if hmac.Equal([]byte(signature), []byte(t.sign(key, []byte(payload)))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t TrackingTokens) sign(key, payload []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(payload)
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+type trackingTokenGenerator interface {
+	Generate(event TrackingEvent) (string, error)
+}
+
+// Tracker instruments the HTML of a message so that opening it and
+// following its links are recorded: each http and https link is sent
+// through a tracking link that redirects to it, and a tracking pixel is
+// added to the end of the body. Text parts are left alone, since their
+// links are read as well as followed.
+type Tracker struct {
+	tokens trackingTokenGenerator
+	url    string
+}
+
+func NewTracker(tokens trackingTokenGenerator, url string) Tracker {
+	return Tracker{
+		tokens: tokens,
+		url:    url,
+	}
+}
+
+func (t Tracker) Instrument(body string, messageID, clientID, kindID string) (string, error) {
+	var err error
+	event := TrackingEvent{MessageID: messageID, ClientID: clientID, KindID: kindID}
+
+	body = trackedLinkPattern.ReplaceAllStringFunc(body, func(link string) string {
+		match := trackedLinkPattern.FindStringSubmatch(link)
+		target := html.UnescapeString(match[3])
+		if err != nil || match[2] != match[4] || strings.HasPrefix(target, t.url) {
+			return link
+		}
+
+		click := event
+		click.Event = models.EngagementClick
+		click.Target = target
+
+		var token string
+		token, err = t.tokens.Generate(click)
+
+		return match[1] + match[2] + html.EscapeString(t.url+token) + match[4]
+	})
+	if err != nil {
+		return "", err
+	}
+
+	open := event
+	open.Event = models.EngagementOpen
+	token, err := t.tokens.Generate(open)
+	if err != nil {
+		return "", err
+	}
+
+	pixel := `<img src="` + html.EscapeString(t.url+token) + `" width="1" height="1" alt="" style="display:none;border:0">`
+	locations := bodyClosePattern.FindAllStringIndex(body, -1)
+	if len(locations) == 0 {
+		return body + pixel, nil
+	}
+
+	end := locations[len(locations)-1][0]
+
+	return body[:end] + pixel + body[end:], nil
+}
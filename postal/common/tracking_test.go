@@ -0,0 +1,121 @@
+package common_test
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/conceal"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TrackingTokens", func() {
+	var tokens common.TrackingTokens
+
+	BeforeEach(func() {
+		cloak, err := conceal.NewCloak([]byte("super-secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		tokens = common.NewTrackingTokens(cloak, []byte("super-secret"))
+	})
+
+	It("parses the tokens it generates", func() {
+		click := common.TrackingEvent{
+			Event:     models.EngagementClick,
+			MessageID: "message-123",
+			ClientID:  "raptors",
+			KindID:    "feeding-time",
+			Target:    "https://example.com/feeding?a=1|2",
+		}
+
+		token, err := tokens.Generate(click)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).NotTo(ContainSubstring("/"))
+
+		event, err := tokens.Parse(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event).To(Equal(click))
+	})
+
+	It("rejects tokens whose signature does not match", func() {
+		token, err := tokens.Generate(common.TrackingEvent{Event: models.EngagementOpen, MessageID: "message-123", ClientID: "raptors"})
+		Expect(err).NotTo(HaveOccurred())
+
+		otherToken, err := tokens.Generate(common.TrackingEvent{Event: models.EngagementOpen, MessageID: "message-456", ClientID: "raptors"})
+		Expect(err).NotTo(HaveOccurred())
+
+		forged := strings.Split(otherToken, ".")[0] + "." + strings.Split(token, ".")[1]
+
+		_, err = tokens.Parse(forged)
+		Expect(err).To(MatchError(common.InvalidTrackingTokenError{}))
+	})
+
+	It("rejects clicks without a target", func() {
+		token, err := tokens.Generate(common.TrackingEvent{Event: models.EngagementClick, MessageID: "message-123", ClientID: "raptors"})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = tokens.Parse(token)
+		Expect(err).To(MatchError(common.InvalidTrackingTokenError{}))
+	})
+
+	It("rejects tokens that are not tokens at all", func() {
+		_, err := tokens.Parse("nonsense")
+		Expect(err).To(MatchError(common.InvalidTrackingTokenError{}))
+	})
+})
+
+var _ = Describe("Tracker", func() {
+	var (
+		tracker common.Tracker
+		tokens  *mocks.TrackingTokens
+	)
+
+	BeforeEach(func() {
+		tokens = mocks.NewTrackingTokens()
+		tokens.GenerateCall.Returns.Token = "some-token"
+
+		tracker = common.NewTracker(tokens, "https://notifications.example.com/t/")
+	})
+
+	It("sends links through the tracking link and adds a pixel before the end of the body", func() {
+		body, err := tracker.Instrument(`<html><body><a href="https://example.com/feeding?a=1&amp;b=2">Feed</a></body></html>`, "message-123", "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(Equal(`<html><body><a href="https://notifications.example.com/t/some-token">Feed</a>` +
+			`<img src="https://notifications.example.com/t/some-token" width="1" height="1" alt="" style="display:none;border:0"></body></html>`))
+
+		Expect(tokens.GenerateCall.Receives.Events).To(Equal([]common.TrackingEvent{
+			{
+				Event:     models.EngagementClick,
+				MessageID: "message-123",
+				ClientID:  "raptors",
+				KindID:    "feeding-time",
+				Target:    "https://example.com/feeding?a=1&b=2",
+			},
+			{
+				Event:     models.EngagementOpen,
+				MessageID: "message-123",
+				ClientID:  "raptors",
+				KindID:    "feeding-time",
+			},
+		}))
+	})
+
+	It("leaves mailto links and links that are already tracked alone", func() {
+		body, err := tracker.Instrument(`<a href="mailto:keeper@example.com">Mail</a><a href='https://notifications.example.com/t/other'>Tracked</a>`, "message-123", "raptors", "")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(body).To(HavePrefix(`<a href="mailto:keeper@example.com">Mail</a><a href='https://notifications.example.com/t/other'>Tracked</a><img `))
+
+		Expect(tokens.GenerateCall.CallCount).To(Equal(1))
+	})
+
+	It("returns errors from generating tokens", func() {
+		tokens.GenerateCall.Returns.Error = errors.New("cloak failed")
+
+		_, err := tracker.Instrument(`<a href="https://example.com">Home</a>`, "message-123", "raptors", "")
+		Expect(err).To(MatchError("cloak failed"))
+	})
+})
@@ -0,0 +1,98 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+
+	"github.com/pivotal-golang/conceal"
+)
+
+type InvalidUnsubscribeTokenError struct{}
+
+func (e InvalidUnsubscribeTokenError) Error() string {
+	return "The unsubscribe link is invalid"
+}
+
+// UnsubscribeTokens produces the tokens carried in List-Unsubscribe links.
+// The token is an unsubscribe ID followed by an HMAC of it, so that a link
+// can be acted on without a UAA token but cannot be altered to unsubscribe
+// somebody else. Tokens are signed with the first key and accepted when
+// signed with any of them. Seal and Open sign other payloads the same way.
+type UnsubscribeTokens struct {
+	cloak conceal.CloakInterface
+	keys  [][]byte
+}
+
+func NewUnsubscribeTokens(cloak conceal.CloakInterface, keys ...[]byte) UnsubscribeTokens {
+	return UnsubscribeTokens{
+		cloak: cloak,
+		keys:  keys,
+	}
+}
+
+func (t UnsubscribeTokens) Generate(userGUID, clientID, kindID string) (string, error) {
+	return t.Seal([]byte(userGUID + "|" + clientID + "|" + kindID))
+}
+
+func (t UnsubscribeTokens) Parse(token string) (userGUID, clientID, kindID string, err error) {
+	plainText, err := t.Open(token)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	fields := strings.Split(string(plainText), "|")
+	if len(fields) != 3 || fields[0] == "" || fields[1] == "" || fields[2] == "" {
+		return "", "", "", InvalidUnsubscribeTokenError{}
+	}
+
+	return fields[0], fields[1], fields[2], nil
+}
+
+// Seal encrypts the plain text and signs the result.
+func (t UnsubscribeTokens) Seal(plainText []byte) (string, error) {
+	cipherText, err := t.cloak.Veil(plainText)
+	if err != nil {
+		return "", err
+	}
+
+	return string(cipherText) + "." + t.sign(t.keys[0], cipherText), nil
+}
+
+// Open returns the plain text of a token made by Seal, once its signature
+// has been checked.
+func (t UnsubscribeTokens) Open(token string) ([]byte, error) {
+	parts := strings.SplitN(token, ".", 2)
+	if len(parts) != 2 {
+		return nil, InvalidUnsubscribeTokenError{}
+	}
+
+	if !t.signed(parts[0], parts[1]) {
+		return nil, InvalidUnsubscribeTokenError{}
+	}
+
+	plainText, err := t.cloak.Unveil([]byte(parts[0]))
+	if err != nil {
+		return nil, InvalidUnsubscribeTokenError{}
+	}
+
+	return plainText, nil
+}
+
+func (t UnsubscribeTokens) signed(unsubscribeID, signature string) bool {
+	for _, key := range t.keys {
+		if hmac.Equal([]byte(signature), []byte(t.sign(key, []byte(unsubscribeID)))) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (t UnsubscribeTokens) sign(key, unsubscribeID []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(unsubscribeID)
+
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
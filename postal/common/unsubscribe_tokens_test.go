@@ -0,0 +1,110 @@
+package common_test
+
+import (
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/pivotal-golang/conceal"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UnsubscribeTokens", func() {
+	var tokens common.UnsubscribeTokens
+
+	BeforeEach(func() {
+		cloak, err := conceal.NewCloak([]byte("super-secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		tokens = common.NewUnsubscribeTokens(cloak, []byte("super-secret"))
+	})
+
+	It("parses the tokens it generates", func() {
+		token, err := tokens.Generate("user-123", "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).NotTo(ContainSubstring("/"))
+
+		userGUID, clientID, kindID, err := tokens.Parse(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(userGUID).To(Equal("user-123"))
+		Expect(clientID).To(Equal("raptors"))
+		Expect(kindID).To(Equal("feeding-time"))
+	})
+
+	It("rejects tokens whose signature does not match", func() {
+		token, err := tokens.Generate("user-123", "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+
+		otherToken, err := tokens.Generate("user-456", "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+
+		forged := strings.Split(otherToken, ".")[0] + "." + strings.Split(token, ".")[1]
+
+		_, _, _, err = tokens.Parse(forged)
+		Expect(err).To(MatchError(common.InvalidUnsubscribeTokenError{}))
+	})
+
+	It("rejects tokens signed with a different key", func() {
+		cloak, err := conceal.NewCloak([]byte("super-secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err := common.NewUnsubscribeTokens(cloak, []byte("another-secret")).Generate("user-123", "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, _, _, err = tokens.Parse(token)
+		Expect(err).To(MatchError(common.InvalidUnsubscribeTokenError{}))
+	})
+
+	It("accepts tokens issued before the key was rotated", func() {
+		oldCloak, err := conceal.NewCloak([]byte("old-secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		token, err := common.NewUnsubscribeTokens(oldCloak, []byte("old-secret")).Generate("user-123", "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+
+		keyring, err := common.NewKeyring([]byte("new-secret"), []byte("old-secret"))
+		Expect(err).NotTo(HaveOccurred())
+		rotated := common.NewUnsubscribeTokens(keyring, keyring.Keys()...)
+
+		userGUID, _, _, err := rotated.Parse(token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(userGUID).To(Equal("user-123"))
+
+		newToken, err := rotated.Generate("user-123", "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+
+		newCloak, err := conceal.NewCloak([]byte("new-secret"))
+		Expect(err).NotTo(HaveOccurred())
+		_, _, _, err = common.NewUnsubscribeTokens(newCloak, []byte("new-secret")).Parse(newToken)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("rejects tokens without a signature", func() {
+		_, _, _, err := tokens.Parse("not-a-token")
+		Expect(err).To(MatchError(common.InvalidUnsubscribeTokenError{}))
+	})
+
+	Describe("Seal and Open", func() {
+		It("opens the payloads it seals", func() {
+			token, err := tokens.Seal([]byte(`{"user_guid":"user-123"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			plainText, err := tokens.Open(token)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(plainText)).To(Equal(`{"user_guid":"user-123"}`))
+		})
+
+		It("rejects payloads that were altered after sealing", func() {
+			token, err := tokens.Seal([]byte(`{"user_guid":"user-123"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			parts := strings.Split(token, ".")
+			altered := []byte(parts[0])
+			altered[len(altered)/2] ^= 1
+
+			_, err = tokens.Open(string(altered) + "." + parts[1])
+			Expect(err).To(MatchError(common.InvalidUnsubscribeTokenError{}))
+		})
+	})
+})
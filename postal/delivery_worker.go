@@ -24,7 +24,7 @@ type campaignJobProcessor interface {
 }
 
 type messageStatusUpdater interface {
-	Update(conn db.ConnectionInterface, messageID, messageStatus, campaignID string, logger lager.Logger)
+	Update(conn db.ConnectionInterface, messageID, messageStatus, reason, campaignID, workerID string, claimedAt time.Time, logger lager.Logger)
 }
 
 type deliveryFailureHandler interface {
@@ -33,6 +33,7 @@ type deliveryFailureHandler interface {
 
 type DeliveryWorkerConfig struct {
 	ID                     int
+	InstanceID             string
 	UAAHost                string
 	Logger                 lager.Logger
 	Queue                  gobble.QueueInterface
@@ -41,6 +42,8 @@ type DeliveryWorkerConfig struct {
 	CampaignJobProcessor   campaignJobProcessor
 	DeliveryFailureHandler deliveryFailureHandler
 	MessageStatusUpdater   messageStatusUpdater
+	WebhookJobProcessor    DeliveryJobProcessor
+	SlackJobProcessor      DeliveryJobProcessor
 }
 
 type DeliveryWorker struct {
@@ -54,6 +57,8 @@ type DeliveryWorker struct {
 	campaignJobProcessor   campaignJobProcessor
 	deliveryFailureHandler deliveryFailureHandler
 	messageStatusUpdater   messageStatusUpdater
+	webhookJobProcessor    DeliveryJobProcessor
+	slackJobProcessor      DeliveryJobProcessor
 }
 
 func NewDeliveryWorker(v1DeliveryJobProcessor DeliveryJobProcessor, config DeliveryWorkerConfig) DeliveryWorker {
@@ -65,10 +70,12 @@ func NewDeliveryWorker(v1DeliveryJobProcessor DeliveryJobProcessor, config Deliv
 		campaignJobProcessor:   config.CampaignJobProcessor,
 		deliveryFailureHandler: config.DeliveryFailureHandler,
 		messageStatusUpdater:   config.MessageStatusUpdater,
+		webhookJobProcessor:    config.WebhookJobProcessor,
+		slackJobProcessor:      config.SlackJobProcessor,
 	}
 	ticker := gobble.NewTicker(time.NewTicker, 30*time.Second)
 	heartbeater := gobble.NewHeartbeater(config.Queue, ticker)
-	worker.Worker = gobble.NewWorker(config.ID, config.Queue, worker.Deliver, heartbeater)
+	worker.Worker = gobble.NewWorker(config.ID, config.InstanceID, config.Queue, worker.Deliver, heartbeater)
 
 	return worker
 }
@@ -86,5 +93,16 @@ func (worker DeliveryWorker) Deliver(job *gobble.Job) {
 		return
 	}
 
+	isWebhook := typedJob.JobType == common.DeliveryEventJobType || typedJob.JobType == common.RegistrationEventJobType
+	if isWebhook && worker.webhookJobProcessor != nil {
+		worker.webhookJobProcessor.Process(job, worker.logger)
+		return
+	}
+
+	if typedJob.JobType == common.SlackJobType && worker.slackJobProcessor != nil {
+		worker.slackJobProcessor.Process(job, worker.logger)
+		return
+	}
+
 	worker.DeliveryJobProcessor.Process(job, worker.logger)
 }
@@ -23,6 +23,8 @@ var _ = Describe("DeliveryWorker", func() {
 		queue                  *mocks.Queue
 		deliveryFailureHandler *mocks.DeliveryFailureHandler
 		v1DeliveryJobProcessor *mocks.V1DeliveryJobProcessor
+		webhookJobProcessor    *mocks.V1DeliveryJobProcessor
+		slackJobProcessor      *mocks.V1DeliveryJobProcessor
 		connection             *mocks.Connection
 		messageStatusUpdater   *mocks.MessageStatusUpdater
 	)
@@ -37,6 +39,8 @@ var _ = Describe("DeliveryWorker", func() {
 		database := mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = connection
 		messageStatusUpdater = mocks.NewMessageStatusUpdater()
+		webhookJobProcessor = mocks.NewV1DeliveryJobProcessor()
+		slackJobProcessor = mocks.NewV1DeliveryJobProcessor()
 
 		config := postal.DeliveryWorkerConfig{
 			ID:                     42,
@@ -46,6 +50,8 @@ var _ = Describe("DeliveryWorker", func() {
 			Database:               database,
 			UAAHost:                "my-uaa-host",
 			MessageStatusUpdater:   messageStatusUpdater,
+			WebhookJobProcessor:    webhookJobProcessor,
+			SlackJobProcessor:      slackJobProcessor,
 		}
 
 		v1DeliveryJobProcessor = mocks.NewV1DeliveryJobProcessor()
@@ -92,6 +98,44 @@ var _ = Describe("DeliveryWorker", func() {
 
 			Expect(v1DeliveryJobProcessor.ProcessCall.Receives.Job).To(Equal(job))
 			Expect(v1DeliveryJobProcessor.ProcessCall.Receives.Logger).ToNot(BeNil())
+			Expect(webhookJobProcessor.ProcessCall.CallCount).To(Equal(0))
+			Expect(slackJobProcessor.ProcessCall.CallCount).To(Equal(0))
+		})
+
+		It("should hand Slack posts to the slack processor", func() {
+			job = gobble.NewJob(common.Delivery{
+				JobType:   common.SlackJobType,
+				MessageID: "message-123",
+			})
+
+			worker.Deliver(job)
+
+			Expect(slackJobProcessor.ProcessCall.Receives.Job).To(Equal(job))
+			Expect(v1DeliveryJobProcessor.ProcessCall.CallCount).To(Equal(0))
+		})
+
+		It("should hand delivery events to the webhook processor", func() {
+			job = gobble.NewJob(common.DeliveryEvent{
+				JobType:   common.DeliveryEventJobType,
+				MessageID: "message-123",
+			})
+
+			worker.Deliver(job)
+
+			Expect(webhookJobProcessor.ProcessCall.Receives.Job).To(Equal(job))
+			Expect(v1DeliveryJobProcessor.ProcessCall.CallCount).To(Equal(0))
+		})
+
+		It("should hand registration events to the webhook processor", func() {
+			job = gobble.NewJob(common.RegistrationEvent{
+				JobType:  common.RegistrationEventJobType,
+				ClientID: "some-client",
+			})
+
+			worker.Deliver(job)
+
+			Expect(webhookJobProcessor.ProcessCall.Receives.Job).To(Equal(job))
+			Expect(v1DeliveryJobProcessor.ProcessCall.CallCount).To(Equal(0))
 		})
 
 		Context("when the job cannot be unmarshalled", func() {
@@ -0,0 +1,174 @@
+package postal
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+type digestEntriesRepo interface {
+	FindDueUserGUIDs(conn models.ConnectionInterface, now time.Time, limit int) ([]string, error)
+	FindAllByUserGUID(conn models.ConnectionInterface, userGUID string) ([]models.DigestEntry, error)
+	DeleteThrough(conn models.ConnectionInterface, userGUID string, primary int) (int, error)
+}
+
+type digestTemplateFinder interface {
+	FindByID(conn models.ConnectionInterface, templateID string) (models.Template, error)
+}
+
+type digestStatusUpdater interface {
+	Update(conn db.ConnectionInterface, messageID, messageStatus, reason, campaignID, workerID string, claimedAt time.Time, logger lager.Logger)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+// DigestDispatcherJob is the name the digest scheduler runs under in the
+// maintenance job scheduler.
+const DigestDispatcherJob = "digest_dispatcher"
+
+type DigestSchedulerConfig struct {
+	Sender    string
+	BatchSize int
+
+	Database      db.DatabaseInterface
+	MailClient    mail.Sender
+	Entries       digestEntriesRepo
+	Templates     digestTemplateFinder
+	StatusUpdater digestStatusUpdater
+	Clock         clock
+	Logger        lager.Logger
+}
+
+// DigestScheduler sends the notifications collected for each user as one
+// message once the digest window of the oldest has passed. A digest that
+// cannot be sent keeps its entries and is tried again on the next run.
+type DigestScheduler struct {
+	sender    string
+	batchSize int
+
+	database      db.DatabaseInterface
+	mailClient    mail.Sender
+	entries       digestEntriesRepo
+	templates     digestTemplateFinder
+	statusUpdater digestStatusUpdater
+	clock         clock
+	logger        lager.Logger
+}
+
+func NewDigestScheduler(config DigestSchedulerConfig) DigestScheduler {
+	return DigestScheduler{
+		sender:    config.Sender,
+		batchSize: config.BatchSize,
+
+		database:      config.Database,
+		mailClient:    config.MailClient,
+		entries:       config.Entries,
+		templates:     config.Templates,
+		statusUpdater: config.StatusUpdater,
+		clock:         config.Clock,
+		logger:        config.Logger,
+	}
+}
+
+// Send returns an error when no digests could be looked up. Digests that
+// fail to send on their own are only logged.
+func (s DigestScheduler) Send() error {
+	conn := s.database.Connection()
+
+	userGUIDs, err := s.entries.FindDueUserGUIDs(conn, s.clock.Now(), s.batchSize)
+	if err != nil {
+		s.logger.Error("digest-lookup-failed", err)
+		return err
+	}
+
+	if len(userGUIDs) == 0 {
+		return nil
+	}
+
+	template, err := s.templates.FindByID(conn, models.DigestTemplateID)
+	if err != nil {
+		s.logger.Error("digest-template-load-failed", err)
+		return err
+	}
+
+	templates := common.Templates{
+		Name:    template.Name,
+		Subject: template.Subject,
+		Text:    template.Text,
+		HTML:    template.HTML,
+	}
+
+	for _, userGUID := range userGUIDs {
+		s.sendDigest(conn, userGUID, templates)
+	}
+
+	return nil
+}
+
+func (s DigestScheduler) sendDigest(conn db.ConnectionInterface, userGUID string, templates common.Templates) {
+	logger := s.logger.WithData(lager.Data{"user_guid": userGUID})
+
+	entries, err := s.entries.FindAllByUserGUID(conn, userGUID)
+	if err != nil {
+		logger.Error("digest-entries-load-failed", err)
+		return
+	}
+
+	if len(entries) == 0 {
+		return
+	}
+
+	last := entries[len(entries)-1]
+	context := common.DigestContext{
+		From:  s.sender,
+		To:    last.Email,
+		Count: len(entries),
+	}
+
+	for _, entry := range entries {
+		context.Entries = append(context.Entries, common.DigestEntry{
+			MessageID:         entry.MessageID,
+			Subject:           entry.Subject,
+			Text:              entry.Text,
+			HTML:              entry.HTML,
+			KindDescription:   entry.KindDescription,
+			SourceDescription: entry.SourceDescription,
+			RequestReceived:   entry.RequestReceived,
+		})
+	}
+
+	message, err := common.PackDigest(context, templates)
+	if err != nil {
+		logger.Error("digest-pack-failed", err)
+		return
+	}
+
+	err = s.mailClient.Connect(logger)
+	if err == nil {
+		err = s.mailClient.Send(message, logger)
+	}
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.digest.failed", nil).Inc(1)
+		logger.Error("digest-send-failed", err)
+		return
+	}
+
+	for _, entry := range entries {
+		s.statusUpdater.Update(conn, entry.MessageID, common.StatusDelivered, "", "", "", time.Time{}, logger)
+	}
+
+	_, err = s.entries.DeleteThrough(conn, userGUID, last.Primary)
+	if err != nil {
+		logger.Error("digest-entries-delete-failed", err)
+	}
+
+	metrics.GetOrRegisterCounter("notifications.digest.sent", nil).Inc(1)
+	logger.Info("digest-sent", lager.Data{"count": len(entries)})
+}
@@ -0,0 +1,128 @@
+package postal_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DigestScheduler", func() {
+	var (
+		scheduler     postal.DigestScheduler
+		entries       *mocks.DigestEntriesRepo
+		templates     *mocks.TemplatesRepo
+		statusUpdater *mocks.MessageStatusUpdater
+		mailClient    *mocks.MailClient
+		clock         *mocks.Clock
+		conn          *mocks.Connection
+		buffer        *bytes.Buffer
+		now           time.Time
+	)
+
+	BeforeEach(func() {
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		now = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		entries = mocks.NewDigestEntriesRepo()
+		entries.FindDueUserGUIDsCall.Returns.UserGUIDs = []string{"user-123"}
+		entries.FindAllByUserGUIDCall.Returns.Entries = map[string][]models.DigestEntry{
+			"user-123": {
+				{Primary: 4, MessageID: "message-1", Email: "old@example.com", Subject: "Fence down", Text: "The fence is down"},
+				{Primary: 9, MessageID: "message-2", Email: "user-123@example.com", Subject: "Feeding time", Text: "Raptors are hungry"},
+			},
+		}
+
+		templates = mocks.NewTemplatesRepo()
+		templates.FindByIDCall.Returns.Template = models.Template{
+			ID:      models.DigestTemplateID,
+			Subject: "{{.Count}} notifications",
+			Text:    "{{range .Entries}}{{.Subject}}\n{{end}}",
+			HTML:    "{{range .Entries}}<p>{{.Subject}}</p>{{end}}",
+		}
+
+		statusUpdater = mocks.NewMessageStatusUpdater()
+		mailClient = mocks.NewMailClient()
+
+		scheduler = postal.NewDigestScheduler(postal.DigestSchedulerConfig{
+			Sender:    "no-reply@example.com",
+			BatchSize: 50,
+
+			Database:      database,
+			MailClient:    mailClient,
+			Entries:       entries,
+			Templates:     templates,
+			StatusUpdater: statusUpdater,
+			Clock:         clock,
+			Logger:        logger,
+		})
+	})
+
+	It("sends the due digests and forgets their entries", func() {
+		scheduler.Send()
+
+		Expect(entries.FindDueUserGUIDsCall.Receives.Connection).To(Equal(conn))
+		Expect(entries.FindDueUserGUIDsCall.Receives.Now).To(Equal(now))
+		Expect(entries.FindDueUserGUIDsCall.Receives.Limit).To(Equal(50))
+		Expect(templates.FindByIDCall.Receives.TemplateID).To(Equal(models.DigestTemplateID))
+
+		Expect(mailClient.SendCall.CallCount).To(Equal(1))
+		message := mailClient.SendCall.Receives.Message
+		Expect(message.From).To(Equal("no-reply@example.com"))
+		Expect(message.To).To(Equal("user-123@example.com"))
+		Expect(message.Subject).To(Equal("2 notifications"))
+		Expect(message.Body[0].Content).To(Equal("Fence down\nFeeding time"))
+
+		Expect(statusUpdater.UpdateCall.Receives.MessageID).To(Equal("message-2"))
+		Expect(statusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+
+		Expect(entries.DeleteThroughCall.Receives.UserGUIDs).To(Equal([]string{"user-123"}))
+		Expect(entries.DeleteThroughCall.Receives.Primaries).To(Equal([]int{9}))
+	})
+
+	It("does nothing when no digest is due", func() {
+		entries.FindDueUserGUIDsCall.Returns.UserGUIDs = []string{}
+
+		scheduler.Send()
+
+		Expect(templates.FindByIDCall.Receives.TemplateID).To(BeEmpty())
+		Expect(mailClient.SendCall.CallCount).To(Equal(0))
+	})
+
+	It("keeps the entries when the digest cannot be sent", func() {
+		mailClient.SendCall.Returns.Error = errors.New("connection refused")
+
+		scheduler.Send()
+
+		Expect(buffer.String()).To(ContainSubstring("digest-send-failed"))
+		Expect(statusUpdater.UpdateCall.Receives.MessageID).To(BeEmpty())
+		Expect(entries.DeleteThroughCall.Receives.UserGUIDs).To(BeEmpty())
+	})
+
+	It("sends nothing when the digest template cannot be loaded", func() {
+		templates.FindByIDCall.Returns.Error = errors.New("database is down")
+
+		err := scheduler.Send()
+		Expect(err).To(MatchError(errors.New("database is down")))
+
+		Expect(buffer.String()).To(ContainSubstring("digest-template-load-failed"))
+		Expect(mailClient.SendCall.CallCount).To(Equal(0))
+	})
+})
@@ -0,0 +1,115 @@
+package postal
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+// FastLane hands deliveries of transactional kinds, such as password resets,
+// straight to an idle worker in this process instead of writing them to the
+// queue for a worker to poll for. It never holds on to a delivery: when every
+// worker is busy, Submit refuses the job and the caller queues it as usual.
+type FastLane struct {
+	jobs chan *gobble.Job
+}
+
+func NewFastLane() *FastLane {
+	return &FastLane{
+		jobs: make(chan *gobble.Job),
+	}
+}
+
+func (lane *FastLane) Submit(job *gobble.Job) bool {
+	select {
+	case lane.jobs <- job:
+		metrics.GetOrRegisterCounter("notifications.fast_lane.submitted", nil).Inc(1)
+		return true
+	default:
+		metrics.GetOrRegisterCounter("notifications.fast_lane.busy", nil).Inc(1)
+		return false
+	}
+}
+
+type fastLaneQueue interface {
+	Enqueue(job *gobble.Job, connection gobble.ConnectionInterface) (*gobble.Job, error)
+}
+
+type FastLaneWorkerConfig struct {
+	ID         int
+	InstanceID string
+	Lane       *FastLane
+	Processor  DeliveryJobProcessor
+	Queue      fastLaneQueue
+	Connection gobble.ConnectionInterface
+	Clock      clock
+	Logger     lager.Logger
+}
+
+type FastLaneWorker struct {
+	ID string
+
+	lane       *FastLane
+	processor  DeliveryJobProcessor
+	queue      fastLaneQueue
+	connection gobble.ConnectionInterface
+	clock      clock
+	logger     lager.Logger
+	halt       chan bool
+}
+
+func NewFastLaneWorker(config FastLaneWorkerConfig) FastLaneWorker {
+	return FastLaneWorker{
+		ID:         fmt.Sprintf("fast-lane-%d-%s-%d", config.ID, config.InstanceID, os.Getpid()),
+		lane:       config.Lane,
+		processor:  config.Processor,
+		queue:      config.Queue,
+		connection: config.Connection,
+		clock:      config.Clock,
+		logger:     config.Logger,
+		halt:       make(chan bool),
+	}
+}
+
+func (worker FastLaneWorker) Work() {
+	go func() {
+		for {
+			select {
+			case job := <-worker.lane.jobs:
+				worker.Deliver(job)
+			case <-worker.halt:
+				return
+			}
+		}
+	}()
+}
+
+func (worker FastLaneWorker) Halt() {
+	worker.halt <- true
+}
+
+// Deliver makes the first attempt at the delivery. A delivery that is to be
+// tried again, whether it failed or was throttled, goes to the queue right
+// away, where the queue workers retry it on the usual schedule.
+func (worker FastLaneWorker) Deliver(job *gobble.Job) {
+	job.WorkerID = worker.ID
+	job.ClaimedAt = worker.clock.Now()
+
+	worker.processor.Process(job, worker.logger)
+	if !job.ShouldRetry {
+		return
+	}
+
+	job.WorkerID = ""
+	_, err := worker.queue.Enqueue(job, worker.connection)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.fast_lane.fallback_failed", nil).Inc(1)
+		worker.logger.Error("fast-lane-fallback-failed", err)
+		return
+	}
+
+	metrics.GetOrRegisterCounter("notifications.fast_lane.fallback", nil).Inc(1)
+}
@@ -0,0 +1,106 @@
+package postal_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FastLane", func() {
+	var (
+		lane       *postal.FastLane
+		worker     postal.FastLaneWorker
+		processor  *mocks.V1DeliveryJobProcessor
+		queue      *mocks.Queue
+		connection *mocks.Connection
+		buffer     *bytes.Buffer
+		job        *gobble.Job
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		now = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+		clock := mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		lane = postal.NewFastLane()
+		processor = mocks.NewV1DeliveryJobProcessor()
+		queue = mocks.NewQueue()
+		connection = mocks.NewConnection()
+
+		worker = postal.NewFastLaneWorker(postal.FastLaneWorkerConfig{
+			ID:         3,
+			InstanceID: "some-instance",
+			Lane:       lane,
+			Processor:  processor,
+			Queue:      queue,
+			Connection: connection,
+			Clock:      clock,
+			Logger:     logger,
+		})
+
+		job = gobble.NewJob(map[string]string{"message_id": "some-message"})
+	})
+
+	Describe("Submit", func() {
+		It("refuses the job when no worker is idle", func() {
+			Expect(lane.Submit(job)).To(BeFalse())
+		})
+
+		It("hands the job to an idle worker", func() {
+			worker.Work()
+			defer worker.Halt()
+
+			Eventually(func() bool { return lane.Submit(job) }).Should(BeTrue())
+			Eventually(func() int { return processor.ProcessCall.CallCount }).Should(Equal(1))
+		})
+	})
+
+	Describe("Deliver", func() {
+		It("delivers the job without queueing it", func() {
+			worker.Deliver(job)
+
+			Expect(processor.ProcessCall.Receives.Job).To(Equal(job))
+			Expect(job.WorkerID).To(HavePrefix("fast-lane-3-some-instance-"))
+			Expect(job.ClaimedAt).To(Equal(now))
+			Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+		})
+
+		It("queues the job when the delivery is to be retried", func() {
+			processor.ProcessCall.Hook = func() {
+				job.Retry(time.Minute)
+			}
+
+			worker.Deliver(job)
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(Equal([]*gobble.Job{job}))
+			Expect(queue.EnqueueCall.Receives.Connection).To(Equal(connection))
+			Expect(job.WorkerID).To(BeEmpty())
+			Expect(job.RetryCount).To(Equal(1))
+		})
+
+		It("logs when the job cannot be queued", func() {
+			processor.ProcessCall.Hook = func() {
+				job.Retry(time.Minute)
+			}
+			queue.EnqueueCall.Returns.Error = errors.New("database is down")
+
+			worker.Deliver(job)
+
+			Expect(buffer.String()).To(ContainSubstring("fast-lane-fallback-failed"))
+			Expect(buffer.String()).To(ContainSubstring("database is down"))
+		})
+	})
+})
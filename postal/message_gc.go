@@ -8,35 +8,50 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 )
 
+// RetentionJanitorJob is the name the message GCs run under in the
+// maintenance job scheduler.
+const RetentionJanitorJob = "retention_janitor"
+
 type messagesDeleter interface {
-	DeleteBefore(models.ConnectionInterface, time.Time) (int, error)
+	DeleteBefore(conn models.ConnectionInterface, threshold time.Time, limit int) (int, error)
 }
 
+// MessageGC deletes rows older than their lifetime. Rows are deleted in
+// batches so that a large backlog does not hold locks for long.
 type MessageGC struct {
 	messages        messagesDeleter
 	db              db.DatabaseInterface
 	lifetime        time.Duration
+	batchSize       int
 	logger          *log.Logger
 	timer           <-chan time.Time
 	pollingInterval time.Duration
 }
 
-func NewMessageGC(lifetime time.Duration, db db.DatabaseInterface, messages messagesDeleter, pollingInterval time.Duration, logger *log.Logger) MessageGC {
+func NewMessageGC(lifetime time.Duration, batchSize int, db db.DatabaseInterface, messages messagesDeleter, pollingInterval time.Duration, logger *log.Logger) MessageGC {
 	return MessageGC{
 		messages:        messages,
 		db:              db,
 		lifetime:        lifetime,
+		batchSize:       batchSize,
 		logger:          logger,
 		pollingInterval: pollingInterval,
 		timer:           time.After(0),
 	}
 }
 
-func (gc MessageGC) Collect() {
+func (gc MessageGC) Collect() error {
 	threshold := time.Now().Add(-1 * gc.lifetime)
-	_, err := gc.messages.DeleteBefore(gc.db.Connection(), threshold)
-	if err != nil {
-		gc.logger.Printf("MessageGC.Collect() failed: " + err.Error())
+	for {
+		count, err := gc.messages.DeleteBefore(gc.db.Connection(), threshold, gc.batchSize)
+		if err != nil {
+			gc.logger.Printf("MessageGC.Collect() failed: " + err.Error())
+			return err
+		}
+
+		if count == 0 || count < gc.batchSize {
+			return nil
+		}
 	}
 }
 
@@ -38,7 +38,7 @@ var _ = Describe("MessageGC", func() {
 		lifetime = 2 * time.Minute
 		pollingInterval = 500 * time.Millisecond
 
-		messageGC = postal.NewMessageGC(lifetime, database, repo, pollingInterval, logger)
+		messageGC = postal.NewMessageGC(lifetime, 10, database, repo, pollingInterval, logger)
 	})
 
 	Describe("Run", func() {
@@ -62,15 +62,26 @@ var _ = Describe("MessageGC", func() {
 
 			Expect(repo.DeleteBeforeCall.Receives.Connection).To(Equal(conn))
 			Expect(repo.DeleteBeforeCall.Receives.ThresholdTime).To(BeTemporally("~", time.Now().Add(-2*time.Minute), 10*time.Second))
+			Expect(repo.DeleteBeforeCall.Receives.Limit).To(Equal(10))
+		})
+
+		It("keeps deleting batches until a batch is not full", func() {
+			repo.DeleteBeforeCall.Returns.RowsAffected = []int{10, 10, 3}
+
+			messageGC.Collect()
+
+			Expect(repo.DeleteBeforeCall.CallCount).To(Equal(3))
 		})
 
 		Context("When the repo errors unexpectantly", func() {
-			It("logs the error", func() {
+			It("logs and returns the error", func() {
 				repo.DeleteBeforeCall.Returns.Error = errors.New("messages table is totally corrupt")
 
-				messageGC.Collect()
+				err := messageGC.Collect()
+				Expect(err).To(MatchError(errors.New("messages table is totally corrupt")))
 
 				Expect(loggerBuffer.String()).To(ContainSubstring("messages table is totally corrupt"))
+				Expect(repo.DeleteBeforeCall.CallCount).To(Equal(1))
 			})
 		})
 
@@ -0,0 +1,114 @@
+package postal
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+// QueueSLAJob is the name the queue SLA escalator runs under in the
+// maintenance job scheduler.
+const QueueSLAJob = "queue_sla"
+
+type slaEscalator interface {
+	Escalate() ([]gobble.Escalation, error)
+}
+
+type slaBreachChecker interface {
+	Breached() (bool, error)
+}
+
+// QueueSLAEscalator moves jobs that have waited longer than their priority
+// allows up to the next priority, and raises an alert for each priority in
+// breach. It runs on one instance at a time, so that jobs are not moved up
+// twice for the same breach.
+type QueueSLAEscalator struct {
+	monitor slaEscalator
+	logger  lager.Logger
+}
+
+func NewQueueSLAEscalator(monitor slaEscalator, logger lager.Logger) QueueSLAEscalator {
+	return QueueSLAEscalator{
+		monitor: monitor,
+		logger:  logger,
+	}
+}
+
+func (e QueueSLAEscalator) Run() error {
+	escalations, err := e.monitor.Escalate()
+	for _, escalation := range escalations {
+		priority := gobble.PriorityName(escalation.Priority)
+
+		e.logger.Error("queue-sla-breached", nil, lager.Data{
+			"priority":           priority,
+			"max_age_seconds":    int64(escalation.MaxAge / time.Second),
+			"oldest_age_seconds": int64(escalation.OldestAge / time.Second),
+			"escalated_to":       gobble.PriorityName(escalation.To),
+			"escalated_jobs":     escalation.Jobs,
+		})
+
+		metrics.GetOrRegisterCounter("notifications.queue.sla."+priority+".breaches", nil).Inc(1)
+		metrics.GetOrRegisterCounter("notifications.queue.sla."+priority+".escalated", nil).Inc(int64(escalation.Jobs))
+	}
+
+	return err
+}
+
+// SurgeWorkers adds delivery workers to an instance while the queue is in
+// breach of its SLA, one each time it is adjusted, up to a maximum. Once
+// the queue is back within its SLA they are stopped again one at a time,
+// each after finishing the job it is working on.
+type SurgeWorkers struct {
+	monitor slaBreachChecker
+	max     int
+	start   func(index int, stop <-chan struct{})
+	logger  lager.Logger
+
+	stops []chan struct{}
+}
+
+func NewSurgeWorkers(monitor slaBreachChecker, max int, start func(index int, stop <-chan struct{}), logger lager.Logger) *SurgeWorkers {
+	return &SurgeWorkers{
+		monitor: monitor,
+		max:     max,
+		start:   start,
+		logger:  logger,
+	}
+}
+
+func (s *SurgeWorkers) Run(ticks <-chan time.Time) {
+	for range ticks {
+		s.Adjust()
+	}
+}
+
+func (s *SurgeWorkers) Adjust() {
+	breached, err := s.monitor.Breached()
+	if err != nil {
+		s.logger.Error("surge-check-failed", err)
+		return
+	}
+
+	switch {
+	case breached && len(s.stops) < s.max:
+		stop := make(chan struct{})
+		s.start(len(s.stops), stop)
+		s.stops = append(s.stops, stop)
+
+		s.logger.Info("surge-worker-started", lager.Data{"surge_workers": len(s.stops)})
+	case !breached && len(s.stops) > 0:
+		last := len(s.stops) - 1
+		close(s.stops[last])
+		s.stops = s.stops[:last]
+
+		s.logger.Info("surge-worker-stopped", lager.Data{"surge_workers": len(s.stops)})
+	}
+
+	metrics.GetOrRegisterGauge("notifications.queue.sla.surge-workers", nil).Update(int64(len(s.stops)))
+}
+
+func (s *SurgeWorkers) Count() int {
+	return len(s.stops)
+}
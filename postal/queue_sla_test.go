@@ -0,0 +1,114 @@
+package postal_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QueueSLAEscalator", func() {
+	var (
+		escalator postal.QueueSLAEscalator
+		monitor   *mocks.SLAMonitor
+		buffer    *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		metrics.DefaultRegistry.UnregisterAll()
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		monitor = mocks.NewSLAMonitor()
+		escalator = postal.NewQueueSLAEscalator(monitor, logger)
+	})
+
+	It("raises an alert for each priority in breach", func() {
+		monitor.EscalateCall.Returns.Escalations = []gobble.Escalation{
+			{
+				SLAStatus: gobble.SLAStatus{Priority: gobble.PriorityBulk, MaxAge: time.Hour, OldestAge: 2 * time.Hour},
+				To:        gobble.PriorityNormal,
+				Jobs:      12,
+			},
+		}
+
+		Expect(escalator.Run()).To(Succeed())
+
+		Expect(buffer.String()).To(ContainSubstring(`"message":"notifications.queue-sla-breached"`))
+		Expect(buffer.String()).To(ContainSubstring(`"priority":"bulk"`))
+		Expect(buffer.String()).To(ContainSubstring(`"escalated_to":"normal"`))
+		Expect(buffer.String()).To(ContainSubstring(`"escalated_jobs":12`))
+
+		Expect(metrics.GetOrRegisterCounter("notifications.queue.sla.bulk.breaches", nil).Count()).To(Equal(int64(1)))
+		Expect(metrics.GetOrRegisterCounter("notifications.queue.sla.bulk.escalated", nil).Count()).To(Equal(int64(12)))
+	})
+
+	It("returns the error when the queue cannot be escalated", func() {
+		monitor.EscalateCall.Returns.Error = errors.New("database is down")
+
+		Expect(escalator.Run()).To(MatchError("database is down"))
+	})
+})
+
+var _ = Describe("SurgeWorkers", func() {
+	var (
+		surge   *postal.SurgeWorkers
+		monitor *mocks.SLAMonitor
+		started []int
+		stops   []<-chan struct{}
+	)
+
+	BeforeEach(func() {
+		started = nil
+		stops = nil
+		monitor = mocks.NewSLAMonitor()
+
+		surge = postal.NewSurgeWorkers(monitor, 2, func(index int, stop <-chan struct{}) {
+			started = append(started, index)
+			stops = append(stops, stop)
+		}, lager.NewLogger("notifications"))
+	})
+
+	It("adds a worker each time the queue is in breach, up to the maximum", func() {
+		monitor.BreachedCall.Returns.Breached = []bool{true}
+
+		surge.Adjust()
+		surge.Adjust()
+		surge.Adjust()
+
+		Expect(started).To(Equal([]int{0, 1}))
+		Expect(surge.Count()).To(Equal(2))
+	})
+
+	It("stops the workers one at a time once the queue is within its SLA", func() {
+		monitor.BreachedCall.Returns.Breached = []bool{true, true, false}
+
+		surge.Adjust()
+		surge.Adjust()
+		surge.Adjust()
+
+		Expect(surge.Count()).To(Equal(1))
+		Expect(stops[1]).To(BeClosed())
+		Expect(stops[0]).NotTo(BeClosed())
+	})
+
+	It("leaves the workers alone when the queue cannot be read", func() {
+		monitor.BreachedCall.Returns.Breached = []bool{true}
+		surge.Adjust()
+
+		monitor.BreachedCall.Returns.Error = errors.New("database is down")
+		surge.Adjust()
+
+		Expect(surge.Count()).To(Equal(1))
+	})
+})
@@ -0,0 +1,82 @@
+package postal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SendRateCurve is the share of its workers, in percent, that the worker pool
+// may run in each hour of the day, UTC. It lets operators slow bulk sending
+// down while shared mail infrastructure is busy, such as during backups.
+type SendRateCurve [24]int
+
+// NewSendRateCurve reads a schedule of hour ranges, such as "22-6" for ten
+// at night until six in the morning, to the percentage of workers that may
+// run during them. Hours the schedule leaves out run at full speed.
+func NewSendRateCurve(schedule map[string]int) (*SendRateCurve, error) {
+	var (
+		curve SendRateCurve
+		set   [24]string
+	)
+	for hour := range curve {
+		curve[hour] = 100
+	}
+
+	for hours, percent := range schedule {
+		start, end, err := parseHourRange(hours)
+		if err != nil {
+			return nil, err
+		}
+
+		if percent < 1 || percent > 100 {
+			return nil, fmt.Errorf("the rate of %q must be a percentage from 1 to 100", hours)
+		}
+
+		for h := start; h < end; h++ {
+			hour := h % 24
+			if set[hour] != "" {
+				return nil, fmt.Errorf("%q and %q both include hour %d", set[hour], hours, hour)
+			}
+
+			set[hour] = hours
+			curve[hour] = percent
+		}
+	}
+
+	return &curve, nil
+}
+
+// At returns the percentage of workers that may run at the time.
+func (c SendRateCurve) At(t time.Time) int {
+	return c[t.UTC().Hour()]
+}
+
+// parseHourRange reads "start-end" in whole hours, where the range includes
+// its start but not its end and may wrap around midnight. A range that wraps
+// is returned with an end past 24.
+func parseHourRange(hours string) (int, int, error) {
+	invalid := fmt.Errorf("%q is not a range of hours such as \"8-18\"", hours)
+
+	parts := strings.Split(hours, "-")
+	if len(parts) != 2 {
+		return 0, 0, invalid
+	}
+
+	start, err := strconv.Atoi(parts[0])
+	if err != nil || start < 0 || start > 23 {
+		return 0, 0, invalid
+	}
+
+	end, err := strconv.Atoi(parts[1])
+	if err != nil || end < 0 || end > 24 || end == start {
+		return 0, 0, invalid
+	}
+
+	if end < start {
+		end += 24
+	}
+
+	return start, end, nil
+}
@@ -0,0 +1,61 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+)
+
+type jobEnqueuer interface {
+	Enqueue(job *gobble.Job, connection gobble.ConnectionInterface) (*gobble.Job, error)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type DeliveryEventPublisher struct {
+	queue      jobEnqueuer
+	connection gobble.ConnectionInterface
+	clock      clock
+}
+
+func NewDeliveryEventPublisher(queue jobEnqueuer, connection gobble.ConnectionInterface, clock clock) DeliveryEventPublisher {
+	return DeliveryEventPublisher{
+		queue:      queue,
+		connection: connection,
+		clock:      clock,
+	}
+}
+
+func (p DeliveryEventPublisher) Publish(delivery common.Delivery, status string) error {
+	if delivery.Options.CallbackURL == "" {
+		return nil
+	}
+
+	recipient := delivery.Email
+	if recipient == "" {
+		recipient = delivery.UserGUID
+	}
+	if delivery.JobType == common.SlackJobType {
+		recipient = common.SlackJobType
+	}
+
+	_, err := p.queue.Enqueue(gobble.NewJob(common.DeliveryEvent{
+		JobType:         common.DeliveryEventJobType,
+		CallbackURL:     delivery.Options.CallbackURL,
+		MessageID:       delivery.MessageID,
+		ClientID:        delivery.ClientID,
+		KindID:          delivery.Options.KindID,
+		Recipient:       recipient,
+		Status:          status,
+		Simulated:       delivery.Simulated(),
+		Severity:        delivery.Options.Severity,
+		Metadata:        delivery.Options.Metadata,
+		RequestReceived: delivery.RequestReceived,
+		OccurredAt:      p.clock.Now().UTC(),
+	}), p.connection)
+
+	return err
+}
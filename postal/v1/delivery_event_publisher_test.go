@@ -0,0 +1,142 @@
+package v1_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/postal/v1"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DeliveryEventPublisher", func() {
+	var (
+		publisher  v1.DeliveryEventPublisher
+		queue      *mocks.Queue
+		connection *mocks.Connection
+		clock      *mocks.Clock
+		delivery   common.Delivery
+		now        time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2015, time.June, 8, 14, 32, 11, 0, time.UTC)
+		queue = mocks.NewQueue()
+		connection = mocks.NewConnection()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		delivery = common.Delivery{
+			MessageID:       "message-123",
+			ClientID:        "some-client",
+			UserGUID:        "user-123",
+			RequestReceived: now.Add(-time.Minute),
+			Options: common.Options{
+				KindID:      "some-kind",
+				CallbackURL: "https://example.com/callback",
+			},
+		}
+
+		publisher = v1.NewDeliveryEventPublisher(queue, connection, clock)
+	})
+
+	It("enqueues an event for the callback URL", func() {
+		err := publisher.Publish(delivery, common.StatusDelivered)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+		Expect(queue.EnqueueCall.Receives.Connection).To(Equal(connection))
+
+		var event common.DeliveryEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event).To(Equal(common.DeliveryEvent{
+			JobType:         common.DeliveryEventJobType,
+			CallbackURL:     "https://example.com/callback",
+			MessageID:       "message-123",
+			ClientID:        "some-client",
+			KindID:          "some-kind",
+			Recipient:       "user-123",
+			Status:          common.StatusDelivered,
+			RequestReceived: now.Add(-time.Minute),
+			OccurredAt:      now,
+		}))
+	})
+
+	It("prefers the email address as the recipient", func() {
+		delivery.Email = "user@example.com"
+
+		err := publisher.Publish(delivery, common.StatusFailed)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event common.DeliveryEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.Recipient).To(Equal("user@example.com"))
+	})
+
+	It("reports Slack posts as sent to slack", func() {
+		delivery.JobType = common.SlackJobType
+		delivery.UserGUID = ""
+
+		err := publisher.Publish(delivery, common.StatusDelivered)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event common.DeliveryEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.Recipient).To(Equal("slack"))
+	})
+
+	It("marks the events of simulated deliveries", func() {
+		delivery.Options.SimulationSinks = []string{"qa@partner.example.com"}
+
+		err := publisher.Publish(delivery, common.StatusDelivered)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event common.DeliveryEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.Simulated).To(BeTrue())
+	})
+
+	It("carries the metadata of the notification", func() {
+		delivery.Options.Metadata = map[string]string{"ticket": "INC-1234"}
+
+		err := publisher.Publish(delivery, common.StatusDelivered)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event common.DeliveryEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.Metadata).To(Equal(map[string]string{"ticket": "INC-1234"}))
+	})
+
+	It("carries the severity of the notification", func() {
+		delivery.Options.Severity = "critical-action-required"
+
+		err := publisher.Publish(delivery, common.StatusDelivered)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event common.DeliveryEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.Severity).To(Equal("critical-action-required"))
+	})
+
+	Context("when the delivery has no callback URL", func() {
+		It("does not enqueue anything", func() {
+			delivery.Options.CallbackURL = ""
+
+			err := publisher.Publish(delivery, common.StatusDelivered)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+		})
+	})
+
+	Context("when the event cannot be enqueued", func() {
+		It("returns the error", func() {
+			queue.EnqueueCall.Returns.Error = errors.New("queue is down")
+
+			err := publisher.Publish(delivery, common.StatusDelivered)
+			Expect(err).To(MatchError(errors.New("queue is down")))
+		})
+	})
+})
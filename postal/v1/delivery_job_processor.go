@@ -1,12 +1,15 @@
 package v1
 
 import (
-	"strings"
+	"errors"
+	"fmt"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/mail"
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/pivotal-golang/lager"
@@ -27,11 +30,12 @@ type userLoader interface {
 }
 
 type messageStatusUpdater interface {
-	Update(conn db.ConnectionInterface, messageID, messageStatus, campaignID string, logger lager.Logger)
+	Update(conn db.ConnectionInterface, messageID, messageStatus, reason, campaignID, workerID string, claimedAt time.Time, logger lager.Logger)
+	UpdateBounce(conn db.ConnectionInterface, messageID, messageStatus string, reply common.SMTPReply, workerID string, claimedAt time.Time, logger lager.Logger)
 }
 
 type deliveryFailureHandler interface {
-	Handle(job common.Retryable, logger lager.Logger)
+	HandleWithPolicy(job common.Retryable, policy common.RetryPolicy, logger lager.Logger)
 }
 
 type kindsFinder interface {
@@ -46,15 +50,86 @@ type unsubscribesGetter interface {
 	Get(connection models.ConnectionInterface, userGUID string, clientID string, kindID string) (bool, error)
 }
 
+type subscriptionsGetter interface {
+	Get(connection models.ConnectionInterface, userGUID string, clientID string, kindID string) (bool, error)
+}
+
 type globalUnsubscribesGetter interface {
 	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
 }
 
+type severityUnsubscribesGetter interface {
+	Get(conn models.ConnectionInterface, userID, severity string) (bool, error)
+}
+
+type messageArchiver interface {
+	Archive(clientID, messageID string, mime []byte) error
+}
+
+type userMessageRecorder interface {
+	Create(conn models.ConnectionInterface, message models.UserMessage) (models.UserMessage, error)
+}
+
+type deliveryEventPublisher interface {
+	Publish(delivery common.Delivery, status string) error
+}
+
+type messageFinder interface {
+	FindByID(conn models.ConnectionInterface, messageID string) (models.Message, error)
+}
+
+type digestPreferencesGetter interface {
+	Get(conn models.ConnectionInterface, userID string) (string, error)
+}
+
+type digestEntryCreator interface {
+	Create(conn models.ConnectionInterface, entry models.DigestEntry) (models.DigestEntry, error)
+}
+
+type domainThrottle interface {
+	Take(email string) (time.Duration, bool)
+}
+
+type recipientCounter interface {
+	Take(conn models.ConnectionInterface, userGUID string, limit int, at time.Time) (bool, error)
+}
+
+type addressValidator interface {
+	Validate(address string) (string, error)
+}
+
+type unsubscribeTokenGenerator interface {
+	Generate(userGUID, clientID, kindID string) (string, error)
+}
+
+type engagementTracker interface {
+	Instrument(body string, messageID, clientID, kindID string) (string, error)
+}
+
 type DeliveryJobProcessorConfig struct {
-	DBTrace bool
-	UAAHost string
-	Sender  string
-	Domain  string
+	DBTrace        bool
+	UAAHost        string
+	Sender         string
+	Domain         string
+	UnsubscribeURL string
+
+	// HTMLSizeLimit is the largest HTML part, in bytes, that is sent without
+	// a warning. With HTMLTextFallback set, an oversized HTML part is dropped
+	// so the message goes out as text only. 0 disables the check.
+	HTMLSizeLimit    int
+	HTMLTextFallback bool
+
+	// ContentLint marks a message undeliverable, rather than sending it,
+	// when it renders with an empty subject or body or with placeholders
+	// that were not replaced.
+	ContentLint bool
+
+	// RecipientDailyLimit caps the non-critical messages each user is sent
+	// per day, counted in RecipientCountsRepo. Messages over the cap are
+	// handled as RecipientOverflow says. 0 disables the cap.
+	RecipientDailyLimit int
+	RecipientOverflow   string
+	RecipientCountsRepo recipientCounter
 
 	Packager    common.Packager
 	MailClient  mailSender
@@ -63,18 +138,58 @@ type DeliveryJobProcessorConfig struct {
 	UserLoader  userLoader
 
 	KindsRepo              kindsFinder
+	ClientsRepo            clientFinder
 	ReceiptsRepo           receiptsCreator
 	UnsubscribesRepo       unsubscribesGetter
 	GlobalUnsubscribesRepo globalUnsubscribesGetter
+	SubscriptionsRepo      subscriptionsGetter
 	MessageStatusUpdater   messageStatusUpdater
 	DeliveryFailureHandler deliveryFailureHandler
+	DeliveryEventPublisher deliveryEventPublisher
+	UserMessagesRepo       userMessageRecorder
+	MessagesRepo           messageFinder
+	UnsubscribeTokens      unsubscribeTokenGenerator
+	Archiver               messageArchiver
+
+	// KindActivityRepo rolls up sends and failed attempts for each kind.
+	KindActivityRepo kindActivityRecorder
+
+	// SeverityUnsubscribesRepo holds the severities users no longer want
+	// notifications of. Without one, every severity is sent.
+	SeverityUnsubscribesRepo severityUnsubscribesGetter
+
+	// DigestPreferencesRepo and DigestEntriesRepo collect the non-critical
+	// notifications of users who asked for digests instead of sending them.
+	DigestPreferencesRepo digestPreferencesGetter
+	DigestEntriesRepo     digestEntryCreator
+
+	// DomainThrottle defers messages to recipient domains that are over
+	// their sending rate, instead of letting the provider tempfail them.
+	DomainThrottle domainThrottle
+
+	// AddressValidator checks and normalizes the address of each recipient
+	// before anything is sent. Without one, only the syntax is checked.
+	AddressValidator addressValidator
+
+	// Tracker instruments the HTML of messages from clients that opted in
+	// to engagement tracking. Without one, nothing is tracked.
+	Tracker engagementTracker
 }
 
 type DeliveryJobProcessor struct {
-	dbTrace bool
-	uaaHost string
-	sender  string
-	domain  string
+	dbTrace        bool
+	uaaHost        string
+	sender         string
+	domain         string
+	unsubscribeURL string
+
+	htmlSizeLimit    int
+	htmlTextFallback bool
+	contentLint      bool
+
+	recipientDailyLimit int
+	recipientOverflow   string
+	recipientCounts     recipientCounter
 
 	packager    common.Packager
 	mailClient  mailSender
@@ -83,19 +198,50 @@ type DeliveryJobProcessor struct {
 	userLoader  userLoader
 
 	kindsRepo              kindsFinder
+	clientsRepo            clientFinder
 	receiptsRepo           receiptsCreator
 	unsubscribesRepo       unsubscribesGetter
 	globalUnsubscribesRepo globalUnsubscribesGetter
+	subscriptionsRepo      subscriptionsGetter
 	messageStatusUpdater   messageStatusUpdater
 	deliveryFailureHandler deliveryFailureHandler
+	deliveryEventPublisher deliveryEventPublisher
+	userMessagesRepo       userMessageRecorder
+	messagesRepo           messageFinder
+	unsubscribeTokens      unsubscribeTokenGenerator
+	archiver               messageArchiver
+
+	kindActivityRepo         kindActivityRecorder
+	severityUnsubscribesRepo severityUnsubscribesGetter
+
+	digestPreferencesRepo digestPreferencesGetter
+	digestEntriesRepo     digestEntryCreator
+
+	domainThrottle   domainThrottle
+	addressValidator addressValidator
+	tracker          engagementTracker
 }
 
 func NewDeliveryJobProcessor(config DeliveryJobProcessorConfig) DeliveryJobProcessor {
+	var addresses addressValidator = mail.NewAddressValidator(nil)
+	if config.AddressValidator != nil {
+		addresses = config.AddressValidator
+	}
+
 	return DeliveryJobProcessor{
-		dbTrace: config.DBTrace,
-		uaaHost: config.UAAHost,
-		sender:  config.Sender,
-		domain:  config.Domain,
+		dbTrace:        config.DBTrace,
+		uaaHost:        config.UAAHost,
+		sender:         config.Sender,
+		domain:         config.Domain,
+		unsubscribeURL: config.UnsubscribeURL,
+
+		htmlSizeLimit:    config.HTMLSizeLimit,
+		htmlTextFallback: config.HTMLTextFallback,
+		contentLint:      config.ContentLint,
+
+		recipientDailyLimit: config.RecipientDailyLimit,
+		recipientOverflow:   config.RecipientOverflow,
+		recipientCounts:     config.RecipientCountsRepo,
 
 		packager:    config.Packager,
 		mailClient:  config.MailClient,
@@ -104,11 +250,28 @@ func NewDeliveryJobProcessor(config DeliveryJobProcessorConfig) DeliveryJobProce
 		userLoader:  config.UserLoader,
 
 		kindsRepo:              config.KindsRepo,
+		clientsRepo:            config.ClientsRepo,
 		receiptsRepo:           config.ReceiptsRepo,
 		unsubscribesRepo:       config.UnsubscribesRepo,
 		globalUnsubscribesRepo: config.GlobalUnsubscribesRepo,
+		subscriptionsRepo:      config.SubscriptionsRepo,
 		messageStatusUpdater:   config.MessageStatusUpdater,
 		deliveryFailureHandler: config.DeliveryFailureHandler,
+		deliveryEventPublisher: config.DeliveryEventPublisher,
+		userMessagesRepo:       config.UserMessagesRepo,
+		messagesRepo:           config.MessagesRepo,
+		unsubscribeTokens:      config.UnsubscribeTokens,
+		archiver:               config.Archiver,
+
+		kindActivityRepo:         config.KindActivityRepo,
+		severityUnsubscribesRepo: config.SeverityUnsubscribesRepo,
+
+		digestPreferencesRepo: config.DigestPreferencesRepo,
+		digestEntriesRepo:     config.DigestEntriesRepo,
+
+		domainThrottle:   config.DomainThrottle,
+		addressValidator: addresses,
+		tracker:          config.Tracker,
 	}
 }
 
@@ -118,37 +281,69 @@ func (p DeliveryJobProcessor) Process(job *gobble.Job, logger lager.Logger) erro
 	if err != nil {
 		metrics.GetOrRegisterCounter("notifications.worker.panic.json", nil).Inc(1)
 
-		p.deliveryFailureHandler.Handle(job, logger)
+		p.deliveryFailureHandler.HandleWithPolicy(job, common.RetryPolicy{}, logger)
 		return nil
 	}
+	delivery.WorkerID = job.WorkerID
+	delivery.ClaimedAt = job.ClaimedAt
 
 	logger = logger.WithData(lager.Data{
 		"message_id":      delivery.MessageID,
 		"vcap_request_id": delivery.VCAPRequestID,
 	})
 
+	span := tracing.Start("notifications.deliver", tracing.KindConsumer, tracing.ParseTraceparent(delivery.Options.TraceParent))
+	span.SetAttribute("vcap_request_id", delivery.VCAPRequestID)
+	span.SetAttribute("message_id", delivery.MessageID)
+	defer span.End()
+
 	if p.dbTrace {
 		p.database.TraceOn("", gorpCompatibleLogger{logger})
 	}
 
-	err = p.receiptsRepo.CreateReceipts(p.database.Connection(), []string{delivery.UserGUID}, delivery.ClientID, delivery.Options.KindID)
-	if err != nil {
-		p.deliveryFailureHandler.Handle(job, logger)
+	if p.isCanceled(delivery.MessageID, logger) {
+		logger.Info("message-canceled")
+		span.SetAttribute("status", common.StatusCanceled)
+		metrics.GetOrRegisterCounter("notifications.worker.canceled", nil).Inc(1)
+		return nil
+	}
+
+	if delivery.Expired(time.Now()) {
+		logger.Info("message-expired", lager.Data{"deadline": delivery.Options.Deadline})
+		p.updateStatus(delivery, common.StatusExpired, logger)
+		span.SetAttribute("status", common.StatusExpired)
+		metrics.GetOrRegisterCounter("notifications.worker.expired", nil).Inc(1)
 		return nil
 	}
 
+	kind := p.findKind(p.database.Connection(), delivery.Options.KindID, delivery.ClientID)
+	policy := common.RetryPolicy{
+		MaxAttempts: kind.RetryMaxAttempts,
+		Interval:    time.Duration(kind.RetryInterval) * time.Second,
+	}
+
+	// Simulated deliveries leave no trace in the preferences of the user
+	// they were addressed to.
+	if !delivery.Simulated() {
+		err = p.receiptsRepo.CreateReceipts(p.database.Connection(), []string{delivery.UserGUID}, delivery.ClientID, delivery.Options.KindID)
+		if err != nil {
+			p.deliveryFailureHandler.HandleWithPolicy(job, policy, logger)
+			return nil
+		}
+	}
+
 	if delivery.Email == "" {
 		var token string
 
 		token, err = p.tokenLoader.Load(p.uaaHost)
 		if err != nil {
-			p.deliveryFailureHandler.Handle(job, logger)
+			p.deliveryFailureHandler.HandleWithPolicy(job, policy, logger)
 			return nil
 		}
 
 		users, err := p.userLoader.Load([]string{delivery.UserGUID}, token)
 		if err != nil || len(users) < 1 {
-			p.deliveryFailureHandler.Handle(job, logger)
+			p.deliveryFailureHandler.HandleWithPolicy(job, policy, logger)
 			return nil
 		}
 
@@ -156,87 +351,459 @@ func (p DeliveryJobProcessor) Process(job *gobble.Job, logger lager.Logger) erro
 		if len(emails) > 0 {
 			delivery.Email = emails[0]
 		}
+		delivery.Locale = users[delivery.UserGUID].Locale
 	}
 
 	logger = logger.WithData(lager.Data{
 		"recipient": delivery.Email,
 	})
 
-	if p.shouldDeliver(delivery, logger) {
-		status := p.process(delivery, logger)
+	reason := p.undeliverableReason(&delivery, kind, logger)
+	if reason == "" {
+		if p.collectForDigest(delivery, kind, logger) {
+			span.SetAttribute("status", common.StatusDigested)
+			metrics.GetOrRegisterCounter("notifications.worker.digested", nil).Inc(1)
+			return nil
+		}
+
+		if p.isThrottled(job, delivery, logger) {
+			span.SetAttribute("status", common.StatusQueued)
+			return nil
+		}
+
+		if status := p.holdOverRecipientLimit(job, delivery, kind, logger); status != "" {
+			span.SetAttribute("status", status)
+			return nil
+		}
+
+		status, errorClass := p.process(delivery, kind, span.Context, logger)
+		span.SetAttribute("status", status)
+
+		if status == common.StatusUndeliverable {
+			return nil
+		}
+
+		// A hard bounce would fail the same way again, so it is not retried.
+		if status == common.StatusHardBounced {
+			metrics.GetOrRegisterCounter("notifications.worker.hard_bounced", nil).Inc(1)
+			return nil
+		}
 
 		if status != common.StatusDelivered {
-			p.deliveryFailureHandler.Handle(job, logger)
+			span.RecordError(fmt.Errorf("delivery %s", status))
+			policy.ErrorClass = errorClass
+			p.deliveryFailureHandler.HandleWithPolicy(job, policy, logger)
 			return nil
 		} else {
 			metrics.GetOrRegisterCounter("notifications.worker.delivered", nil).Inc(1)
 		}
 	} else {
-		metrics.GetOrRegisterCounter("notifications.worker.unsubscribed", nil).Inc(1)
+		span.SetAttribute("status", common.StatusUndeliverable)
+		if reason == common.ReasonUnsubscribed {
+			metrics.GetOrRegisterCounter("notifications.worker.unsubscribed", nil).Inc(1)
+		} else {
+			metrics.GetOrRegisterCounter("notifications.worker.invalid_address", nil).Inc(1)
+		}
 	}
 
 	return nil
 }
 
-func (p DeliveryJobProcessor) process(delivery common.Delivery, logger lager.Logger) string {
+// process returns the status of the delivery and, when sending failed, the
+// class of the error so that it can be retried on that class's schedule.
+func (p DeliveryJobProcessor) process(delivery common.Delivery, kind models.Kind, trace tracing.SpanContext, logger lager.Logger) (string, string) {
+	renderSpan := tracing.Start("notifications.render", tracing.KindInternal, trace)
 	context, err := p.packager.PrepareContext(delivery, p.sender, p.domain)
 	if err != nil {
 		panic(err)
 	}
+	client := p.findClient(delivery.ClientID, logger)
+	context.LinkDomains = client.LinkDomains
+	if client.SenderName != "" {
+		context.From = mail.WithDisplayName(context.From, client.SenderName)
+	}
 
 	message, err := p.packager.Pack(context)
+	renderSpan.RecordError(err)
+	renderSpan.End()
 	if err != nil {
 		logger.Info("template-pack-failed")
-		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusFailed, "", logger)
-		return common.StatusFailed
+		p.updateStatus(delivery, common.StatusFailed, logger)
+		return common.StatusFailed, ""
+	}
+
+	if p.contentLint {
+		if err, ok := common.LintMessage(message).(common.ContentError); ok {
+			logger.Info("content-lint-failed", lager.Data{"reason": err.Reason, "detail": err.Detail})
+			metrics.GetOrRegisterCounter("notifications.worker.content_rejected", nil).Inc(1)
+			p.markUndeliverable(delivery, err.Reason, logger)
+			return common.StatusUndeliverable, ""
+		}
+	}
+
+	message.EnvelopeID = delivery.MessageID
+	message.Headers = append(message.Headers, p.listUnsubscribeHeaders(delivery, kind, logger)...)
+	message.Body = p.trackEngagement(delivery, client, message.Body, logger)
+	message.Body = p.enforceHTMLSizeLimit(message.Body, logger)
+
+	// The user may have changed their preferences since the job started, so
+	// they are checked again right before the message goes out.
+	if p.optedOut(delivery, kind, logger) {
+		logger.Info("opted-out-before-send")
+		p.markUndeliverable(delivery, common.ReasonUnsubscribed, logger)
+		metrics.GetOrRegisterCounter("notifications.worker.unsubscribed", nil).Inc(1)
+		return common.StatusUndeliverable, ""
+	}
+
+	sendSpan := tracing.Start("notifications.smtp_send", tracing.KindClient, trace)
+	if delivery.Simulated() {
+		err = p.sendToSinks(delivery, message, logger)
+	} else {
+		err = p.sendMail(message, logger)
+	}
+	status, errorClass := common.StatusDelivered, ""
+	if err != nil {
+		status, errorClass = failureStatus(err), common.ErrorClass(err)
+		sendSpan.RecordError(fmt.Errorf("delivery %s", status))
+	}
+	sendSpan.End()
+
+	if reply, ok := common.Bounce(err); ok {
+		p.markBounced(delivery, status, reply, logger)
+	} else {
+		p.updateStatus(delivery, status, logger)
+	}
+
+	if status == common.StatusDelivered && !delivery.Simulated() {
+		p.recordUserMessage(delivery, logger)
+	}
+
+	if status == common.StatusDelivered && p.archiver != nil {
+		err = p.archiver.Archive(delivery.ClientID, delivery.MessageID, []byte(message.Data()))
+		if err != nil {
+			metrics.GetOrRegisterCounter("notifications.worker.archive.failed", nil).Inc(1)
+			logger.Error("message-archive-failed", err)
+		}
+	}
+
+	return status, errorClass
+}
+
+// trackEngagement instruments the HTML part for clients that opted in. The
+// sinks of simulated messages are not tracked, and a message that cannot be
+// instrumented is sent as it is.
+func (p DeliveryJobProcessor) trackEngagement(delivery common.Delivery, client models.Client, parts []mail.Part, logger lager.Logger) []mail.Part {
+	if p.tracker == nil || !client.EngagementTracking || delivery.Simulated() {
+		return parts
+	}
+
+	tracked := make([]mail.Part, len(parts))
+	for i, part := range parts {
+		tracked[i] = part
+		if part.ContentType != "text/html" {
+			continue
+		}
+
+		content, err := p.tracker.Instrument(part.Content, delivery.MessageID, delivery.ClientID, delivery.Options.KindID)
+		if err != nil {
+			logger.Error("engagement-tracking-failed", err)
+			return parts
+		}
+		tracked[i].Content = content
+	}
+
+	return tracked
+}
+
+// enforceHTMLSizeLimit keeps messages from being clipped by mail clients,
+// which hides whatever follows the cut, including the unsubscribe link.
+// The HTML part is only dropped when there is a text part to fall back on.
+func (p DeliveryJobProcessor) enforceHTMLSizeLimit(parts []mail.Part, logger lager.Logger) []mail.Part {
+	if p.htmlSizeLimit <= 0 {
+		return parts
+	}
+
+	var hasText bool
+	htmlIndex := -1
+	for i, part := range parts {
+		switch part.ContentType {
+		case "text/plain":
+			hasText = true
+		case "text/html":
+			htmlIndex = i
+		}
 	}
 
-	status := p.sendMail(delivery.MessageID, message, logger)
-	p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, status, "", logger)
+	if htmlIndex < 0 || len(parts[htmlIndex].Content) <= p.htmlSizeLimit {
+		return parts
+	}
+
+	fallback := p.htmlTextFallback && hasText
+
+	metrics.GetOrRegisterCounter("notifications.worker.html_oversized", nil).Inc(1)
+	logger.Info("html-size-limit-exceeded", lager.Data{
+		"html_bytes":    len(parts[htmlIndex].Content),
+		"limit_bytes":   p.htmlSizeLimit,
+		"text_fallback": fallback,
+	})
+
+	if !fallback {
+		return parts
+	}
+
+	return append(append([]mail.Part{}, parts[:htmlIndex]...), parts[htmlIndex+1:]...)
+}
+
+// findClient loads the branding of the client: its link domains and sender
+// name. A client that cannot be loaded gets its message sent unbranded.
+func (p DeliveryJobProcessor) findClient(clientID string, logger lager.Logger) models.Client {
+	if p.clientsRepo == nil {
+		return models.Client{}
+	}
+
+	client, err := p.clientsRepo.Find(p.database.Connection(), clientID)
+	if err != nil {
+		logger.Error("client-branding-load-failed", err)
+		return models.Client{}
+	}
 
-	return status
+	return client
 }
 
-func (p DeliveryJobProcessor) shouldDeliver(delivery common.Delivery, logger lager.Logger) bool {
+// collectForDigest holds the message for the next digest of a user who asked
+// for hourly or daily digests. Critical notifications, and those that
+// require action, are always sent right away, and a message that cannot be
+// collected is sent right away too.
+func (p DeliveryJobProcessor) collectForDigest(delivery common.Delivery, kind models.Kind, logger lager.Logger) bool {
+	if p.digestPreferencesRepo == nil || p.digestEntriesRepo == nil || kind.Critical || delivery.UserGUID == "" || delivery.Simulated() {
+		return false
+	}
+
+	if delivery.Options.Severity == models.SeverityCriticalActionRequired {
+		return false
+	}
+
 	conn := p.database.Connection()
-	if p.isCritical(conn, delivery.Options.KindID, delivery.ClientID) {
-		return true
+	frequency, err := p.digestPreferencesRepo.Get(conn, delivery.UserGUID)
+	if err != nil {
+		logger.Error("digest-preference-lookup-failed", err)
+		return false
 	}
 
-	globallyUnsubscribed, err := p.globalUnsubscribesRepo.Get(conn, delivery.UserGUID)
-	if err != nil || globallyUnsubscribed {
-		logger.Info("user-unsubscribed")
-		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
+	window, ok := models.DigestWindows[frequency]
+	if !ok {
 		return false
 	}
 
-	isUnsubscribed, err := p.unsubscribesRepo.Get(conn, delivery.UserGUID, delivery.ClientID, delivery.Options.KindID)
-	if err != nil || isUnsubscribed {
-		logger.Info("user-unsubscribed")
-		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
+	entry := p.digestEntry(delivery, time.Now().Truncate(1*time.Second).UTC().Add(window), logger)
+	_, err = p.digestEntriesRepo.Create(conn, entry)
+	if err != nil {
+		logger.Error("digest-collect-failed", err)
 		return false
 	}
 
+	logger.Info("message-digested", lager.Data{"frequency": frequency})
+	p.updateStatus(delivery, common.StatusDigested, logger)
+
+	return true
+}
+
+// digestEntry turns the delivery into an entry of a digest that is due at
+// dueAt.
+func (p DeliveryJobProcessor) digestEntry(delivery common.Delivery, dueAt time.Time, logger lager.Logger) models.DigestEntry {
+	subject := delivery.Options.Subject
+	if subject == "" {
+		subject = "[no subject]"
+	}
+
+	kindDescription := delivery.Options.KindDescription
+	if kindDescription == "" {
+		kindDescription = delivery.Options.KindID
+	}
+
+	sourceDescription := delivery.Options.SourceDescription
+	if sourceDescription == "" {
+		sourceDescription = delivery.ClientID
+	}
+
+	linkDomains := p.findClient(delivery.ClientID, logger).LinkDomains
+
+	return models.DigestEntry{
+		MessageID:         delivery.MessageID,
+		UserGUID:          delivery.UserGUID,
+		Email:             delivery.Email,
+		ClientID:          delivery.ClientID,
+		KindID:            delivery.Options.KindID,
+		Subject:           subject,
+		Text:              common.RewriteLinkDomains(delivery.Options.Text, linkDomains),
+		HTML:              common.RewriteLinkDomains(delivery.Options.HTML.BodyContent, linkDomains),
+		SourceDescription: sourceDescription,
+		KindDescription:   kindDescription,
+		RequestReceived:   delivery.RequestReceived.UTC(),
+		DueAt:             dueAt,
+	}
+}
+
+// holdOverRecipientLimit counts the message against the daily limit of its
+// recipient and, once they are over it, holds the message back instead of
+// sending it, returning the status it was given. Only the first attempt is
+// counted, so that retries of a message the recipient already counted do
+// not count again. Critical notifications are never held back, and a
+// message is sent when the count cannot be checked.
+func (p DeliveryJobProcessor) holdOverRecipientLimit(job *gobble.Job, delivery common.Delivery, kind models.Kind, logger lager.Logger) string {
+	if p.recipientCounts == nil || p.recipientDailyLimit <= 0 || kind.Critical || delivery.UserGUID == "" || delivery.Simulated() || job.RetryCount > 0 {
+		return ""
+	}
+
+	conn := p.database.Connection()
+	now := time.Now()
+	ok, err := p.recipientCounts.Take(conn, delivery.UserGUID, p.recipientDailyLimit, now)
+	if err != nil {
+		logger.Error("recipient-limit-check-failed", err)
+		return ""
+	}
+
+	if ok {
+		return ""
+	}
+
+	metrics.GetOrRegisterCounter("notifications.worker.recipient_limited", nil).Inc(1)
+
+	if p.recipientOverflow == common.RecipientOverflowDigest && p.digestEntriesRepo != nil {
+		_, err = p.digestEntriesRepo.Create(conn, p.digestEntry(delivery, models.RecipientDayEndsAt(now), logger))
+		if err == nil {
+			logger.Info("recipient-limit-digested", lager.Data{"limit": p.recipientDailyLimit})
+			p.updateStatus(delivery, common.StatusDigested, logger)
+			return common.StatusDigested
+		}
+
+		logger.Error("digest-collect-failed", err)
+	}
+
+	logger.Info("recipient-limit-dropped", lager.Data{"limit": p.recipientDailyLimit})
+	p.markUndeliverable(delivery, common.ReasonRecipientLimit, logger)
+
+	return common.StatusUndeliverable
+}
+
+// isThrottled defers the job when the domain of the recipient is over its
+// sending rate. Deferred jobs keep their status and do not use up a retry.
+func (p DeliveryJobProcessor) isThrottled(job *gobble.Job, delivery common.Delivery, logger lager.Logger) bool {
+	if p.domainThrottle == nil {
+		return false
+	}
+
+	wait, ok := p.domainThrottle.Take(delivery.Email)
+	if ok {
+		return false
+	}
+
+	if wait < time.Second {
+		wait = time.Second
+	}
+	job.Defer(wait)
+
+	metrics.GetOrRegisterCounter("notifications.worker.domain_throttled", nil).Inc(1)
+	logger.Info("domain-throttled", lager.Data{"retry_in": wait.String()})
+
+	return true
+}
+
+// isCanceled reports whether an admin canceled the message while it was
+// waiting in the queue. A message that cannot be loaded is still sent.
+func (p DeliveryJobProcessor) isCanceled(messageID string, logger lager.Logger) bool {
+	if p.messagesRepo == nil {
+		return false
+	}
+
+	message, err := p.messagesRepo.FindByID(p.database.Connection(), messageID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); !ok {
+			logger.Error("message-status-lookup-failed", err)
+		}
+		return false
+	}
+
+	return message.Status == common.StatusCanceled
+}
+
+// undeliverableReason marks the message undeliverable and returns why when
+// the recipient opted out or has no address that can be delivered to.
+// Otherwise it replaces the address of the delivery with its normalized
+// form and returns an empty string. Critical notifications ignore
+// preferences, but not bad addresses.
+func (p DeliveryJobProcessor) undeliverableReason(delivery *common.Delivery, kind models.Kind, logger lager.Logger) string {
+	if p.optedOut(*delivery, kind, logger) {
+		p.markUndeliverable(*delivery, common.ReasonUnsubscribed, logger)
+		return common.ReasonUnsubscribed
+	}
+
 	if delivery.Email == "" {
 		logger.Info("no-email-address-for-user")
-		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
-		return false
+		p.markUndeliverable(*delivery, common.ReasonNoAddress, logger)
+		return common.ReasonNoAddress
 	}
 
-	if !strings.Contains(delivery.Email, "@") {
+	address, err := p.addressValidator.Validate(delivery.Email)
+	if err != nil {
 		logger.Info("malformatted-email-address")
-		p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, common.StatusUndeliverable, "", logger)
+		p.markUndeliverable(*delivery, common.ReasonInvalidAddress, logger)
+		return common.ReasonInvalidAddress
+	}
+	delivery.Email = address
+
+	return ""
+}
+
+// optedOut reports whether the user has unsubscribed from the kind or its
+// severity, or has not subscribed to an opt-in kind. A lookup that fails
+// counts as opted out.
+func (p DeliveryJobProcessor) optedOut(delivery common.Delivery, kind models.Kind, logger lager.Logger) bool {
+	if kind.Critical {
 		return false
 	}
 
-	return true
+	conn := p.database.Connection()
+
+	globallyUnsubscribed, err := p.globalUnsubscribesRepo.Get(conn, delivery.UserGUID)
+	if err != nil || globallyUnsubscribed {
+		logger.Info("user-unsubscribed")
+		return true
+	}
+
+	severity := delivery.Options.Severity
+	if p.severityUnsubscribesRepo != nil && delivery.UserGUID != "" && models.UnsubscribableSeverity(severity) {
+		unsubscribed, err := p.severityUnsubscribesRepo.Get(conn, delivery.UserGUID, severity)
+		if err != nil || unsubscribed {
+			logger.Info("user-unsubscribed-from-severity", lager.Data{"severity": severity})
+			return true
+		}
+	}
+
+	isUnsubscribed, err := p.unsubscribesRepo.Get(conn, delivery.UserGUID, delivery.ClientID, delivery.Options.KindID)
+	if err != nil || isUnsubscribed {
+		logger.Info("user-unsubscribed")
+		return true
+	}
+
+	// Opt-in kinds only reach users who subscribed to them. Messages sent
+	// straight to an email address have no user to have subscribed.
+	if kind.OptIn && delivery.UserGUID != "" {
+		isSubscribed, err := p.subscriptionsRepo.Get(conn, delivery.UserGUID, delivery.ClientID, delivery.Options.KindID)
+		if err != nil || !isSubscribed {
+			logger.Info("user-not-subscribed")
+			return true
+		}
+	}
+
+	return false
 }
 
-func (p DeliveryJobProcessor) sendMail(messageID string, message mail.Message, logger lager.Logger) string {
+func (p DeliveryJobProcessor) sendMail(message mail.Message, logger lager.Logger) error {
 	err := p.mailClient.Connect(logger)
 	if err != nil {
 		logger.Error("smtp-connection-error", err)
-		return common.StatusFailed
+		return err
 	}
 
 	logger.Info("delivery-start")
@@ -244,19 +811,151 @@ func (p DeliveryJobProcessor) sendMail(messageID string, message mail.Message, l
 	err = p.mailClient.Send(message, logger)
 	if err != nil {
 		logger.Error("delivery-failed-smtp-error", err)
-		return common.StatusFailed
+		return err
 	}
 
 	logger.Info("message-sent")
 
-	return common.StatusDelivered
+	return nil
+}
+
+// sendToSinks sends the message of a client in simulation mode to each of
+// the client's sinks, noting who it would have gone to. A sink that fails
+// fails the delivery, and the retry sends it to every sink again.
+func (p DeliveryJobProcessor) sendToSinks(delivery common.Delivery, message mail.Message, logger lager.Logger) error {
+	logger.Info("simulated-delivery", lager.Data{"sinks": delivery.Options.SimulationSinks})
+
+	message.Headers = append(message.Headers, "X-Notifications-Simulated-Recipient: "+message.To)
+	for _, sink := range delivery.Options.SimulationSinks {
+		message.To = sink
+
+		err := p.sendMail(message, logger)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// failureStatus distinguishes servers that could not meet the configured TLS
+// policy, transports that throttled the message, and servers that bounced
+// it, from other delivery failures. All of them but hard bounces are
+// retried.
+func failureStatus(err error) string {
+	var policyErr mail.TLSPolicyError
+	if errors.As(err, &policyErr) {
+		return common.StatusTLSPolicyFailed
+	}
+
+	var unavailableErr mail.UnavailableError
+	if errors.As(err, &unavailableErr) {
+		return common.StatusUnavailable
+	}
+
+	if reply, ok := common.Bounce(err); ok {
+		if reply.Permanent() {
+			return common.StatusHardBounced
+		}
+		return common.StatusSoftBounced
+	}
+
+	return common.StatusFailed
+}
+
+func (p DeliveryJobProcessor) updateStatus(delivery common.Delivery, status string, logger lager.Logger) {
+	p.updateStatusWithReason(delivery, status, "", logger)
+}
+
+// markUndeliverable records why the message will never be sent, so that bad
+// addresses can be told apart from users who opted out.
+func (p DeliveryJobProcessor) markUndeliverable(delivery common.Delivery, reason string, logger lager.Logger) {
+	p.updateStatusWithReason(delivery, common.StatusUndeliverable, reason, logger)
+}
+
+func (p DeliveryJobProcessor) updateStatusWithReason(delivery common.Delivery, status, reason string, logger lager.Logger) {
+	p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, status, reason, "", delivery.WorkerID, delivery.ClaimedAt, logger)
+	p.statusChanged(delivery, status, logger)
+}
+
+// markBounced keeps the reply of the mail server that bounced the message,
+// so that a full mailbox can be told apart from an address that does not
+// exist.
+func (p DeliveryJobProcessor) markBounced(delivery common.Delivery, status string, reply common.SMTPReply, logger lager.Logger) {
+	p.messageStatusUpdater.UpdateBounce(p.database.Connection(), delivery.MessageID, status, reply, delivery.WorkerID, delivery.ClaimedAt, logger)
+	p.statusChanged(delivery, status, logger)
+}
+
+func (p DeliveryJobProcessor) statusChanged(delivery common.Delivery, status string, logger lager.Logger) {
+	recordKindActivity(p.kindActivityRepo, p.database.Connection(), delivery, status, logger)
+
+	if p.deliveryEventPublisher == nil {
+		return
+	}
+
+	err := p.deliveryEventPublisher.Publish(delivery, status)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.webhook.enqueue.failed", nil).Inc(1)
+		logger.Error("delivery-event-enqueue-failed", err)
+	}
+}
+
+// listUnsubscribeHeaders lets mail clients offer the RFC 8058 one-click
+// unsubscribe. Critical notifications cannot be unsubscribed from,
+// messages sent straight to an email address have no user to unsubscribe,
+// and the sinks of simulated messages must not unsubscribe the user.
+func (p DeliveryJobProcessor) listUnsubscribeHeaders(delivery common.Delivery, kind models.Kind, logger lager.Logger) []string {
+	if p.unsubscribeURL == "" || p.unsubscribeTokens == nil || kind.Critical {
+		return nil
+	}
+
+	if delivery.UserGUID == "" || delivery.Options.KindID == "" || delivery.Simulated() {
+		return nil
+	}
+
+	token, err := p.unsubscribeTokens.Generate(delivery.UserGUID, delivery.ClientID, delivery.Options.KindID)
+	if err != nil {
+		logger.Error("unsubscribe-token-failed", err)
+		return nil
+	}
+
+	return []string{
+		fmt.Sprintf("List-Unsubscribe: <%s%s>", p.unsubscribeURL, token),
+		"List-Unsubscribe-Post: List-Unsubscribe=One-Click",
+	}
 }
 
-func (p DeliveryJobProcessor) isCritical(conn db.ConnectionInterface, kindID, clientID string) bool {
+// recordUserMessage adds the delivery to the history users can see through
+// GET /user_messages. Messages sent straight to an email address have no
+// user to record them against.
+func (p DeliveryJobProcessor) recordUserMessage(delivery common.Delivery, logger lager.Logger) {
+	if p.userMessagesRepo == nil || delivery.UserGUID == "" {
+		return
+	}
+
+	_, err := p.userMessagesRepo.Create(p.database.Connection(), models.UserMessage{
+		MessageID:         delivery.MessageID,
+		UserGUID:          delivery.UserGUID,
+		ClientID:          delivery.ClientID,
+		KindID:            delivery.Options.KindID,
+		Subject:           delivery.Options.Subject,
+		SourceDescription: delivery.Options.SourceDescription,
+		KindDescription:   delivery.Options.KindDescription,
+		Severity:          delivery.Options.Severity,
+		RequestReceived:   delivery.RequestReceived.UTC(),
+		DeliveredAt:       time.Now().Truncate(1 * time.Second).UTC(),
+	})
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.worker.user_message.failed", nil).Inc(1)
+		logger.Error("user-message-record-failed", err)
+	}
+}
+
+func (p DeliveryJobProcessor) findKind(conn db.ConnectionInterface, kindID, clientID string) models.Kind {
 	kind, err := p.kindsRepo.Find(conn, kindID, clientID)
-	if _, ok := err.(models.NotFoundError); ok {
-		return false
+	if err != nil {
+		return models.Kind{}
 	}
 
-	return kind.Critical
+	return kind
 }
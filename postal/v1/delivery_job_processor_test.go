@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"crypto/md5"
 	"errors"
+	"net"
+	"net/textproto"
 	"strings"
 	"time"
 
@@ -12,6 +14,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/postal/v1"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/pivotal-golang/conceal"
@@ -30,7 +33,9 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		delivery               common.Delivery
 		unsubscribesRepo       *mocks.UnsubscribesRepo
 		globalUnsubscribesRepo *mocks.GlobalUnsubscribesRepo
+		subscriptionsRepo      *mocks.SubscriptionsRepo
 		kindsRepo              *mocks.KindsRepo
+		clientsRepo            *mocks.ClientsRepository
 		database               *mocks.Database
 		conn                   *mocks.Connection
 		userLoader             *mocks.UserLoader
@@ -42,6 +47,14 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		messageID              string
 		messageStatusUpdater   *mocks.MessageStatusUpdater
 		deliveryFailureHandler *mocks.DeliveryFailureHandler
+		deliveryEventPublisher *mocks.DeliveryEventPublisher
+		kindActivityRepo       *mocks.KindActivityRepo
+		userMessagesRepo       *mocks.UserMessagesRepo
+		messagesRepo           *mocks.MessagesRepo
+		digestPreferencesRepo  *mocks.DigestPreferencesRepo
+		digestEntriesRepo      *mocks.DigestEntriesRepo
+		domainThrottle         *mocks.DomainThrottle
+		severityRepo           *mocks.SeverityUnsubscribesRepo
 	)
 
 	BeforeEach(func() {
@@ -54,6 +67,7 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		mailClient = mocks.NewMailClient()
 		unsubscribesRepo = mocks.NewUnsubscribesRepo()
 		globalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepo()
+		subscriptionsRepo = mocks.NewSubscriptionsRepo()
 
 		kindsRepo = mocks.NewKindsRepo()
 		kindsRepo.FindCall.Returns.Kinds = []models.Kind{
@@ -64,6 +78,8 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			},
 		}
 
+		clientsRepo = mocks.NewClientsRepository()
+
 		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = conn
@@ -74,7 +90,7 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		fakeUserEmail = "user-123@example.com"
 		userLoader = mocks.NewUserLoader()
 		userLoader.LoadCall.Returns.Users = map[string]uaa.User{
-			"user-123": {Emails: []string{fakeUserEmail}},
+			"user-123": {Emails: []string{fakeUserEmail}, Locale: "fr-CA"},
 			"user-456": {Emails: []string{"user-456@example.com"}},
 		}
 		tokenLoader = mocks.NewTokenLoader()
@@ -87,6 +103,17 @@ var _ = Describe("DeliveryJobProcessor", func() {
 		receiptsRepo = mocks.NewReceiptsRepo()
 		messageStatusUpdater = mocks.NewMessageStatusUpdater()
 		deliveryFailureHandler = mocks.NewDeliveryFailureHandler()
+		deliveryEventPublisher = mocks.NewDeliveryEventPublisher()
+		kindActivityRepo = mocks.NewKindActivityRepo()
+		userMessagesRepo = mocks.NewUserMessagesRepo()
+		messagesRepo = mocks.NewMessagesRepo()
+		messagesRepo.FindByIDCall.Returns.Message = models.Message{Status: common.StatusQueued}
+		digestPreferencesRepo = mocks.NewDigestPreferencesRepo()
+		digestPreferencesRepo.GetCall.Returns.Frequency = models.DigestImmediate
+		digestEntriesRepo = mocks.NewDigestEntriesRepo()
+		domainThrottle = mocks.NewDomainThrottle()
+		domainThrottle.TakeCall.Returns.OK = true
+		severityRepo = mocks.NewSeverityUnsubscribesRepo()
 
 		cloak, err := conceal.NewCloak(encryptionKey)
 		Expect(err).NotTo(HaveOccurred())
@@ -104,11 +131,22 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			UserLoader:  userLoader,
 
 			KindsRepo:              kindsRepo,
+			ClientsRepo:            clientsRepo,
 			ReceiptsRepo:           receiptsRepo,
 			UnsubscribesRepo:       unsubscribesRepo,
 			GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+			SubscriptionsRepo:      subscriptionsRepo,
 			MessageStatusUpdater:   messageStatusUpdater,
 			DeliveryFailureHandler: deliveryFailureHandler,
+			DeliveryEventPublisher: deliveryEventPublisher,
+			KindActivityRepo:       kindActivityRepo,
+			UserMessagesRepo:       userMessagesRepo,
+			MessagesRepo:           messagesRepo,
+			DigestPreferencesRepo:  digestPreferencesRepo,
+			DigestEntriesRepo:      digestEntriesRepo,
+			DomainThrottle:         domainThrottle,
+
+			SeverityUnsubscribesRepo: severityRepo,
 		})
 
 		messageID = "randomly-generated-guid"
@@ -116,11 +154,13 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			ClientID: "some-client",
 			UserGUID: userGUID,
 			Options: common.Options{
-				Subject:    "the subject",
-				Text:       "body content",
-				ReplyTo:    "thesender@example.com",
-				KindID:     "some-kind",
-				TemplateID: "some-template-id",
+				Subject:           "the subject",
+				Text:              "body content",
+				ReplyTo:           "thesender@example.com",
+				KindID:            "some-kind",
+				KindDescription:   "Some Kind",
+				SourceDescription: "Some Client",
+				TemplateID:        "some-template-id",
 			},
 			MessageID:     messageID,
 			VCAPRequestID: "some-request-id",
@@ -160,6 +200,7 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			Expect(templateLoader.LoadTemplatesCall.Receives.ClientID).To(Equal("some-client"))
 			Expect(templateLoader.LoadTemplatesCall.Receives.KindID).To(Equal("some-kind"))
 			Expect(templateLoader.LoadTemplatesCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(templateLoader.LoadTemplatesCall.Receives.Locale).To(Equal("fr-CA"))
 		})
 
 		It("logs successful delivery", func() {
@@ -212,89 +253,942 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			Expect(database.TraceOnCall.Receives.Logger).NotTo(BeNil())
 		})
 
-		It("does not log database operations when database traces are disabled", func() {
-			processor.Process(job, logger)
-			Expect(database.TraceOnCall.Receives.Prefix).To(BeEmpty())
-			Expect(database.TraceOnCall.Receives.Logger).To(BeNil())
-		})
+		It("does not log database operations when database traces are disabled", func() {
+			processor.Process(job, logger)
+			Expect(database.TraceOnCall.Receives.Prefix).To(BeEmpty())
+			Expect(database.TraceOnCall.Receives.Logger).To(BeNil())
+		})
+
+		It("updates the message status as delivered", func() {
+			processor.Process(job, logger)
+
+			Expect(messageStatusUpdater.UpdateCall.Receives.Connection).To(Equal(conn))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+			Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+		})
+
+		It("records the worker that claimed the job with the message status", func() {
+			claimedAt := time.Now().Add(-time.Second)
+			job.WorkerID = "worker-1-some-instance-42"
+			job.ClaimedAt = claimedAt
+
+			processor.Process(job, logger)
+
+			Expect(messageStatusUpdater.UpdateCall.Receives.WorkerID).To(Equal("worker-1-some-instance-42"))
+			Expect(messageStatusUpdater.UpdateCall.Receives.ClaimedAt).To(Equal(claimedAt))
+		})
+
+		It("records the message in the user's history", func() {
+			processor.Process(job, logger)
+
+			Expect(userMessagesRepo.CreateCall.CallCount).To(Equal(1))
+			Expect(userMessagesRepo.CreateCall.Receives.Connection).To(Equal(conn))
+
+			message := userMessagesRepo.CreateCall.Receives.Message
+			Expect(message.DeliveredAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+			message.DeliveredAt = time.Time{}
+			Expect(message).To(Equal(models.UserMessage{
+				MessageID:         messageID,
+				UserGUID:          userGUID,
+				ClientID:          "some-client",
+				KindID:            "some-kind",
+				Subject:           "the subject",
+				SourceDescription: "Some Client",
+				KindDescription:   "Some Kind",
+				RequestReceived:   time.Time{}.UTC(),
+			}))
+		})
+
+		Context("when the message history cannot be recorded", func() {
+			It("logs the error without retrying the delivery", func() {
+				userMessagesRepo.CreateCall.Returns.Error = errors.New("database is down")
+
+				processor.Process(job, logger)
+
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				Expect(buffer.String()).To(ContainSubstring("user-message-record-failed"))
+			})
+		})
+
+		It("publishes a delivery event", func() {
+			processor.Process(job, logger)
+
+			Expect(deliveryEventPublisher.PublishCall.CallCount).To(Equal(1))
+			Expect(deliveryEventPublisher.PublishCall.Receives.Delivery.MessageID).To(Equal(messageID))
+			Expect(deliveryEventPublisher.PublishCall.Receives.Delivery.Email).To(Equal(fakeUserEmail))
+			Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusDelivered))
+		})
+
+		It("counts the message as sent for its kind", func() {
+			processor.Process(job, logger)
+
+			Expect(kindActivityRepo.IncrementCall.CallCount).To(Equal(1))
+			Expect(kindActivityRepo.IncrementCall.Receives.Connection).To(Equal(conn))
+			Expect(kindActivityRepo.IncrementCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(kindActivityRepo.IncrementCall.Receives.KindID).To(Equal("some-kind"))
+			Expect(kindActivityRepo.IncrementCall.Receives.Counter).To(Equal(models.KindActivitySent))
+		})
+
+		Context("when the kind activity cannot be recorded", func() {
+			It("logs the error without retrying the delivery", func() {
+				kindActivityRepo.IncrementCall.Returns.Error = errors.New("database is down")
+
+				processor.Process(job, logger)
+
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				Expect(buffer.String()).To(ContainSubstring("kind-activity-record-failed"))
+			})
+		})
+
+		Context("when the delivery event cannot be published", func() {
+			It("logs the error without retrying the delivery", func() {
+				deliveryEventPublisher.PublishCall.Returns.Error = errors.New("queue is down")
+
+				processor.Process(job, logger)
+
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				Expect(buffer.String()).To(ContainSubstring("delivery-event-enqueue-failed"))
+			})
+		})
+
+		It("creates a reciept for the delivery", func() {
+			processor.Process(job, logger)
+
+			Expect(receiptsRepo.CreateReceiptsCall.Receives.Connection).To(Equal(conn))
+			Expect(receiptsRepo.CreateReceiptsCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(receiptsRepo.CreateReceiptsCall.Receives.KindID).To(Equal("some-kind"))
+			Expect(receiptsRepo.CreateReceiptsCall.Receives.UserGUIDs).To(Equal([]string{"user-123"}))
+		})
+
+		Context("when the receipt fails to be created", func() {
+			It("retries the job", func() {
+				receiptsRepo.CreateReceiptsCall.Returns.Error = errors.New("something happened")
+				processor.Process(job, logger)
+
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+			})
+		})
+
+		Context("when loading a zoned token fails", func() {
+			It("retries the job", func() {
+				job := gobble.NewJob(delivery)
+
+				tokenLoader.LoadCall.Returns.Error = errors.New("failed to load a zoned UAA token")
+				processor.Process(job, logger)
+
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+			})
+		})
+
+		It("ensures message delivery", func() {
+			processor.Process(job, logger)
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			msg := mailClient.SendCall.Receives.Message
+			Expect(msg.From).To(Equal("from@example.com"))
+			Expect(msg.ReplyTo).To(Equal("thesender@example.com"))
+			Expect(msg.To).To(Equal(fakeUserEmail))
+			Expect(msg.Subject).To(Equal("the subject"))
+			Expect(msg.Body).To(ConsistOf([]mail.Part{
+				{
+					ContentType: "text/plain",
+					Content:     "body content example.com",
+				},
+			}))
+			Expect(msg.Headers).To(ContainElement("X-CF-Client-ID: some-client"))
+			Expect(msg.Headers).To(ContainElement("X-CF-Notification-ID: randomly-generated-guid"))
+			Expect(msg.EnvelopeID).To(Equal(messageID))
+
+			var formattedTimestamp string
+			prefix := "X-CF-Notification-Timestamp: "
+			for _, header := range msg.Headers {
+				if strings.Contains(header, prefix) {
+					formattedTimestamp = strings.TrimPrefix(header, prefix)
+					break
+				}
+			}
+			Expect(formattedTimestamp).NotTo(BeEmpty())
+
+			timestamp, err := time.Parse(time.RFC3339, formattedTimestamp)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timestamp).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		Context("when the message has been canceled", func() {
+			BeforeEach(func() {
+				messagesRepo.FindByIDCall.Returns.Message = models.Message{
+					ID:     messageID,
+					Status: common.StatusCanceled,
+				}
+			})
+
+			It("drops the job without sending the message", func() {
+				err := processor.Process(job, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(messagesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+				Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(Equal(messageID))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(BeEmpty())
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				Expect(buffer.String()).To(ContainSubstring("message-canceled"))
+			})
+		})
+
+		Context("when the deadline of the message has passed", func() {
+			BeforeEach(func() {
+				delivery.Options.Deadline = time.Now().Add(-1 * time.Minute)
+				job = gobble.NewJob(delivery)
+			})
+
+			It("marks the message expired without sending it", func() {
+				err := processor.Process(job, logger)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(receiptsRepo.CreateReceiptsCall.Receives.UserGUIDs).To(BeEmpty())
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusExpired))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				Expect(buffer.String()).To(ContainSubstring("message-expired"))
+			})
+		})
+
+		It("sends the message when its deadline has not passed", func() {
+			delivery.Options.Deadline = time.Now().Add(1 * time.Hour)
+
+			processor.Process(gobble.NewJob(delivery), logger)
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+		})
+
+		It("sends the message when its status cannot be loaded", func() {
+			messagesRepo.FindByIDCall.Returns.Error = errors.New("database is down")
+
+			processor.Process(job, logger)
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			Expect(buffer.String()).To(ContainSubstring("message-status-lookup-failed"))
+		})
+
+		Context("when the client has branded link domains", func() {
+			BeforeEach(func() {
+				delivery.Options.Text = "reset at https://login.sys.example.com/reset"
+				job = gobble.NewJob(delivery)
+
+				clientsRepo.FindCall.Returns.Client = models.Client{
+					ID:          "some-client",
+					LinkDomains: models.LinkDomains{"login.sys.example.com": "login.brand.example.com"},
+				}
+			})
+
+			It("rewrites links in the message to the branded domains", func() {
+				processor.Process(job, logger)
+
+				Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(conn))
+				Expect(clientsRepo.FindCall.Receives.ClientID).To(Equal("some-client"))
+				Expect(mailClient.SendCall.Receives.Message.Body).To(ConsistOf([]mail.Part{
+					{
+						ContentType: "text/plain",
+						Content:     "reset at https://login.brand.example.com/reset example.com",
+					},
+				}))
+			})
+
+			It("sends the links unchanged when the client cannot be loaded", func() {
+				clientsRepo.FindCall.Returns.Error = errors.New("database is down")
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.Receives.Message.Body[0].Content).To(Equal("reset at https://login.sys.example.com/reset example.com"))
+			})
+		})
+
+		It("sends from the sender name of the client", func() {
+			clientsRepo.FindCall.Returns.Client = models.Client{
+				ID:         "some-client",
+				SenderName: "Jürgen Müller",
+			}
+
+			processor.Process(job, logger)
+
+			Expect(mailClient.SendCall.Receives.Message.From).To(Equal("=?utf-8?q?J=C3=BCrgen_M=C3=BCller?= <from@example.com>"))
+		})
+
+		Context("when the delivery belongs to a client in simulation mode", func() {
+			BeforeEach(func() {
+				delivery.Options.SimulationSinks = []string{"qa@partner.example.com", "audit@partner.example.com"}
+				job = gobble.NewJob(delivery)
+			})
+
+			It("sends the message to each sink instead of the recipient", func() {
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(2))
+
+				message := mailClient.SendCall.Receives.Message
+				Expect(message.To).To(Equal("audit@partner.example.com"))
+				Expect(message.Headers).To(ContainElement("X-Notifications-Simulated-Recipient: user-123@example.com"))
+
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+				Expect(buffer.String()).To(ContainSubstring("simulated-delivery"))
+			})
+
+			It("leaves no receipt, history or digest entry for the user", func() {
+				digestPreferencesRepo.GetCall.Returns.Frequency = models.DigestHourly
+
+				processor.Process(job, logger)
+
+				Expect(receiptsRepo.CreateReceiptsCall.Receives.UserGUIDs).To(BeNil())
+				Expect(userMessagesRepo.CreateCall.CallCount).To(Equal(0))
+				Expect(digestEntriesRepo.CreateCall.CallCount).To(Equal(0))
+				Expect(mailClient.SendCall.CallCount).To(Equal(2))
+			})
+
+			It("retries the delivery when a sink fails", func() {
+				mailClient.SendCall.Returns.Error = errors.New("connection reset")
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeTrue())
+			})
+		})
+
+		Context("when the user asked for digests", func() {
+			BeforeEach(func() {
+				digestPreferencesRepo.GetCall.Returns.Frequency = models.DigestHourly
+			})
+
+			It("collects the message for the next digest instead of sending it", func() {
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(digestPreferencesRepo.GetCall.Receives.UserID).To(Equal("user-123"))
+
+				entry := digestEntriesRepo.CreateCall.Receives.Entry
+				Expect(entry.MessageID).To(Equal(messageID))
+				Expect(entry.UserGUID).To(Equal("user-123"))
+				Expect(entry.Email).To(Equal("user-123@example.com"))
+				Expect(entry.Subject).To(Equal("the subject"))
+				Expect(entry.Text).To(Equal("body content"))
+				Expect(entry.KindDescription).To(Equal("Some Kind"))
+				Expect(entry.SourceDescription).To(Equal("Some Client"))
+				Expect(entry.DueAt).To(BeTemporally("~", time.Now().Add(time.Hour), 2*time.Second))
+
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDigested))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+			})
+
+			It("sends critical notifications right away", func() {
+				kindsRepo.FindCall.Returns.Kinds[0].Critical = true
+
+				processor.Process(job, logger)
+
+				Expect(digestEntriesRepo.CreateCall.CallCount).To(Equal(0))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+
+			It("sends notifications that require action right away", func() {
+				delivery.Options.Severity = models.SeverityCriticalActionRequired
+				job = gobble.NewJob(delivery)
+
+				processor.Process(job, logger)
+
+				Expect(digestEntriesRepo.CreateCall.CallCount).To(Equal(0))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+
+			It("sends the message right away when it cannot be collected", func() {
+				digestEntriesRepo.CreateCall.Returns.Error = errors.New("database is down")
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring("digest-collect-failed"))
+			})
+		})
+
+		Context("when the domain of the recipient is over its sending rate", func() {
+			BeforeEach(func() {
+				domainThrottle.TakeCall.Returns.OK = false
+				domainThrottle.TakeCall.Returns.Wait = 200 * time.Millisecond
+			})
+
+			It("defers the job without sending it or counting a retry", func() {
+				processor.Process(job, logger)
+
+				Expect(domainThrottle.TakeCall.Receives.Email).To(Equal("user-123@example.com"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(BeEmpty())
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+
+				Expect(job.ShouldRetry).To(BeTrue())
+				Expect(job.RetryCount).To(Equal(0))
+				Expect(job.ActiveAt).To(BeTemporally("~", time.Now().Add(time.Second), 500*time.Millisecond))
+				Expect(buffer.String()).To(ContainSubstring("domain-throttled"))
+			})
+		})
+
+		Context("when a recipient daily limit is configured", func() {
+			var (
+				recipientCounts *mocks.RecipientDailyCountsRepo
+				newProcessor    func(overflow string) v1.DeliveryJobProcessor
+			)
+
+			BeforeEach(func() {
+				recipientCounts = mocks.NewRecipientDailyCountsRepo()
+				recipientCounts.TakeCall.Returns.OK = true
+
+				newProcessor = func(overflow string) v1.DeliveryJobProcessor {
+					cloak, err := conceal.NewCloak([]byte("12345678901234567890123456789012"))
+					Expect(err).NotTo(HaveOccurred())
+
+					return v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
+						Sender: "from@example.com",
+						Domain: "example.com",
+
+						RecipientDailyLimit: 20,
+						RecipientOverflow:   overflow,
+						RecipientCountsRepo: recipientCounts,
+
+						Packager:    common.NewPackager(templateLoader, cloak),
+						MailClient:  mailClient,
+						Database:    database,
+						TokenLoader: tokenLoader,
+						UserLoader:  userLoader,
+
+						KindsRepo:              kindsRepo,
+						ReceiptsRepo:           receiptsRepo,
+						UnsubscribesRepo:       unsubscribesRepo,
+						GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+						MessageStatusUpdater:   messageStatusUpdater,
+						DeliveryFailureHandler: deliveryFailureHandler,
+						DeliveryEventPublisher: deliveryEventPublisher,
+						DigestPreferencesRepo:  digestPreferencesRepo,
+						DigestEntriesRepo:      digestEntriesRepo,
+					})
+				}
+			})
+
+			It("counts the message against the limit of the recipient and sends it", func() {
+				newProcessor(common.RecipientOverflowDigest).Process(job, logger)
+
+				Expect(recipientCounts.TakeCall.Receives.UserGUID).To(Equal("user-123"))
+				Expect(recipientCounts.TakeCall.Receives.Limit).To(Equal(20))
+				Expect(recipientCounts.TakeCall.Receives.At).To(BeTemporally("~", time.Now(), 2*time.Second))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+
+			Context("when the recipient is over the limit", func() {
+				BeforeEach(func() {
+					recipientCounts.TakeCall.Returns.OK = false
+				})
+
+				It("holds the message for a digest sent when the day is over", func() {
+					newProcessor(common.RecipientOverflowDigest).Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+
+					entry := digestEntriesRepo.CreateCall.Receives.Entry
+					Expect(entry.MessageID).To(Equal(messageID))
+					Expect(entry.UserGUID).To(Equal("user-123"))
+					Expect(entry.DueAt).To(Equal(models.RecipientDayEndsAt(time.Now())))
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDigested))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+					Expect(buffer.String()).To(ContainSubstring("recipient-limit-digested"))
+				})
+
+				It("marks the message undeliverable when overflow is dropped", func() {
+					newProcessor(common.RecipientOverflowDrop).Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+					Expect(digestEntriesRepo.CreateCall.CallCount).To(Equal(0))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+					Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonRecipientLimit))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				})
+
+				It("drops the message when it cannot be held for a digest", func() {
+					digestEntriesRepo.CreateCall.Returns.Error = errors.New("database is down")
+
+					newProcessor(common.RecipientOverflowDigest).Process(job, logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+					Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonRecipientLimit))
+				})
+
+				It("sends critical notifications without counting them", func() {
+					kindsRepo.FindCall.Returns.Kinds[0].Critical = true
+
+					newProcessor(common.RecipientOverflowDigest).Process(job, logger)
+
+					Expect(recipientCounts.TakeCall.CallCount).To(Equal(0))
+					Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				})
+
+				It("does not count retries of a message again", func() {
+					job.RetryCount = 1
+
+					newProcessor(common.RecipientOverflowDigest).Process(job, logger)
+
+					Expect(recipientCounts.TakeCall.CallCount).To(Equal(0))
+					Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				})
+			})
+
+			It("sends the message when the count cannot be checked", func() {
+				recipientCounts.TakeCall.Returns.Error = errors.New("database is down")
+
+				newProcessor(common.RecipientOverflowDrop).Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				Expect(buffer.String()).To(ContainSubstring("recipient-limit-check-failed"))
+			})
+		})
+
+		It("should connect and send the message with the worker's logger session", func() {
+			processor.Process(job, logger)
+			Expect(mailClient.ConnectCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+			Expect(mailClient.SendCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+		})
+
+		Context("when an HTML size limit is configured", func() {
+			var newProcessor func(fallback bool) v1.DeliveryJobProcessor
+
+			BeforeEach(func() {
+				delivery.Options.HTML = common.HTML{BodyContent: strings.Repeat("<p>long</p>", 20)}
+				job = gobble.NewJob(delivery)
+
+				newProcessor = func(fallback bool) v1.DeliveryJobProcessor {
+					cloak, err := conceal.NewCloak([]byte("12345678901234567890123456789012"))
+					Expect(err).NotTo(HaveOccurred())
+
+					return v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
+						Sender: "from@example.com",
+						Domain: "example.com",
+
+						HTMLSizeLimit:    100,
+						HTMLTextFallback: fallback,
+
+						Packager:    common.NewPackager(templateLoader, cloak),
+						MailClient:  mailClient,
+						Database:    database,
+						TokenLoader: tokenLoader,
+						UserLoader:  userLoader,
+
+						KindsRepo:              kindsRepo,
+						ReceiptsRepo:           receiptsRepo,
+						UnsubscribesRepo:       unsubscribesRepo,
+						GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+						MessageStatusUpdater:   messageStatusUpdater,
+						DeliveryFailureHandler: deliveryFailureHandler,
+					})
+				}
+			})
+
+			It("warns about an oversized HTML part and still sends it", func() {
+				newProcessor(false).Process(job, logger)
+
+				Expect(mailClient.SendCall.Receives.Message.Body).To(HaveLen(2))
+				Expect(buffer.String()).To(ContainSubstring("html-size-limit-exceeded"))
+				Expect(buffer.String()).To(ContainSubstring(`"text_fallback":false`))
+			})
+
+			It("sends the message as text only when the fallback is enabled", func() {
+				newProcessor(true).Process(job, logger)
+
+				Expect(mailClient.SendCall.Receives.Message.Body).To(Equal([]mail.Part{
+					{ContentType: "text/plain", Content: "body content example.com"},
+				}))
+				Expect(buffer.String()).To(ContainSubstring(`"text_fallback":true`))
+			})
+
+			It("keeps the HTML part when there is no text to fall back on", func() {
+				delivery.Options.Text = ""
+				job = gobble.NewJob(delivery)
+
+				newProcessor(true).Process(job, logger)
+
+				Expect(mailClient.SendCall.Receives.Message.Body).To(HaveLen(1))
+				Expect(mailClient.SendCall.Receives.Message.Body[0].ContentType).To(Equal("text/html"))
+			})
+
+			It("leaves HTML parts within the limit alone", func() {
+				delivery.Options.HTML = common.HTML{BodyContent: "<p>short</p>"}
+				templateLoader.LoadTemplatesCall.Returns.Templates.HTML = "{{.HTML}}"
+				job = gobble.NewJob(delivery)
+
+				newProcessor(true).Process(job, logger)
+
+				Expect(mailClient.SendCall.Receives.Message.Body).To(HaveLen(2))
+				Expect(buffer.String()).NotTo(ContainSubstring("html-size-limit-exceeded"))
+			})
+		})
+
+		Context("when engagement tracking is configured", func() {
+			var tracker *mocks.EngagementTracker
+
+			BeforeEach(func() {
+				delivery.Options.HTML = common.HTML{BodyContent: "<p>feeding time</p>"}
+				templateLoader.LoadTemplatesCall.Returns.Templates.HTML = "{{.HTML}}"
+				job = gobble.NewJob(delivery)
+
+				clientsRepo.FindCall.Returns.Client = models.Client{
+					ID:                 "some-client",
+					EngagementTracking: true,
+				}
+
+				tracker = mocks.NewEngagementTracker()
+				tracker.InstrumentCall.Returns.Body = "<p>feeding time</p><img>"
+
+				cloak, err := conceal.NewCloak([]byte("12345678901234567890123456789012"))
+				Expect(err).NotTo(HaveOccurred())
+
+				processor = v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
+					Sender: "from@example.com",
+					Domain: "example.com",
+
+					Packager:    common.NewPackager(templateLoader, cloak),
+					MailClient:  mailClient,
+					Database:    database,
+					TokenLoader: tokenLoader,
+					UserLoader:  userLoader,
+
+					KindsRepo:              kindsRepo,
+					ClientsRepo:            clientsRepo,
+					ReceiptsRepo:           receiptsRepo,
+					UnsubscribesRepo:       unsubscribesRepo,
+					GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+					MessageStatusUpdater:   messageStatusUpdater,
+					DeliveryFailureHandler: deliveryFailureHandler,
+					Tracker:                tracker,
+				})
+			})
+
+			It("instruments the HTML part of messages from clients that opted in", func() {
+				processor.Process(job, logger)
+
+				Expect(tracker.InstrumentCall.Receives.Body).To(ContainSubstring("<p>feeding time</p>"))
+				Expect(tracker.InstrumentCall.Receives.MessageID).To(Equal(messageID))
+				Expect(tracker.InstrumentCall.Receives.ClientID).To(Equal("some-client"))
+				Expect(tracker.InstrumentCall.Receives.KindID).To(Equal("some-kind"))
+
+				Expect(mailClient.SendCall.Receives.Message.Body).To(ConsistOf([]mail.Part{
+					{ContentType: "text/plain", Content: "body content example.com"},
+					{ContentType: "text/html", Content: "<p>feeding time</p><img>"},
+				}))
+			})
+
+			It("does not track clients that have not opted in", func() {
+				clientsRepo.FindCall.Returns.Client.EngagementTracking = false
+
+				processor.Process(job, logger)
+
+				Expect(tracker.InstrumentCall.CallCount).To(Equal(0))
+			})
+
+			It("does not track the sinks of simulated messages", func() {
+				delivery.Options.SimulationSinks = []string{"qa@partner.example.com"}
+				job = gobble.NewJob(delivery)
+
+				processor.Process(job, logger)
+
+				Expect(tracker.InstrumentCall.CallCount).To(Equal(0))
+			})
+
+			It("sends the message untracked when it cannot be instrumented", func() {
+				tracker.InstrumentCall.Returns.Error = errors.New("cloak failed")
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.Receives.Message.Body[1].Content).To(Equal(tracker.InstrumentCall.Receives.Body))
+				Expect(buffer.String()).To(ContainSubstring("engagement-tracking-failed"))
+			})
+		})
+
+		Context("when content linting is enabled", func() {
+			BeforeEach(func() {
+				cloak, err := conceal.NewCloak([]byte("12345678901234567890123456789012"))
+				Expect(err).NotTo(HaveOccurred())
+
+				processor = v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
+					Sender:      "from@example.com",
+					Domain:      "example.com",
+					ContentLint: true,
+
+					Packager:    common.NewPackager(templateLoader, cloak),
+					MailClient:  mailClient,
+					Database:    database,
+					TokenLoader: tokenLoader,
+					UserLoader:  userLoader,
+
+					KindsRepo:              kindsRepo,
+					ReceiptsRepo:           receiptsRepo,
+					UnsubscribesRepo:       unsubscribesRepo,
+					GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+					MessageStatusUpdater:   messageStatusUpdater,
+					DeliveryFailureHandler: deliveryFailureHandler,
+					DeliveryEventPublisher: deliveryEventPublisher,
+				})
+			})
+
+			It("sends messages that render completely", func() {
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+
+			It("marks a message with an unreplaced placeholder undeliverable without retrying it", func() {
+				delivery.Options.Text = "Hello {{name}}, your app is down"
+				job = gobble.NewJob(delivery)
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonUnreplacedPlaceholder))
+				Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusUndeliverable))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				Expect(buffer.String()).To(ContainSubstring("notifications.worker.content-lint-failed"))
+			})
+
+			It("marks a message with an empty subject undeliverable", func() {
+				templateLoader.LoadTemplatesCall.Returns.Templates.Subject = ""
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonEmptySubject))
+			})
+
+			It("marks a message with an empty body undeliverable", func() {
+				templateLoader.LoadTemplatesCall.Returns.Templates.Text = " "
+				templateLoader.LoadTemplatesCall.Returns.Templates.HTML = "<p></p>"
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonEmptyBody))
+			})
+		})
+
+		Context("when an archiver is configured", func() {
+			var archiver *mocks.MessageArchiver
+
+			BeforeEach(func() {
+				archiver = mocks.NewMessageArchiver()
+
+				cloak, err := conceal.NewCloak([]byte("12345678901234567890123456789012"))
+				Expect(err).NotTo(HaveOccurred())
+
+				processor = v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
+					UAAHost: "https://uaa.example.com",
+					Sender:  "from@example.com",
+					Domain:  "example.com",
+
+					Packager:    common.NewPackager(templateLoader, cloak),
+					MailClient:  mailClient,
+					Database:    database,
+					TokenLoader: tokenLoader,
+					UserLoader:  userLoader,
+
+					KindsRepo:              kindsRepo,
+					ReceiptsRepo:           receiptsRepo,
+					UnsubscribesRepo:       unsubscribesRepo,
+					GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+					MessageStatusUpdater:   messageStatusUpdater,
+					DeliveryFailureHandler: deliveryFailureHandler,
+					Archiver:               archiver,
+				})
+			})
+
+			It("archives the rendered message after it is delivered", func() {
+				processor.Process(job, logger)
+
+				Expect(archiver.ArchiveCall.CallCount).To(Equal(1))
+				Expect(archiver.ArchiveCall.Receives.ClientID).To(Equal("some-client"))
+				Expect(archiver.ArchiveCall.Receives.MessageID).To(Equal("randomly-generated-guid"))
+				Expect(string(archiver.ArchiveCall.Receives.MIME)).To(ContainSubstring("Subject: the subject"))
+				Expect(string(archiver.ArchiveCall.Receives.MIME)).To(ContainSubstring("body content example.com"))
+			})
+
+			It("does not archive messages that fail to send", func() {
+				mailClient.SendCall.Returns.Error = errors.New("BOOM!")
+
+				processor.Process(job, logger)
+
+				Expect(archiver.ArchiveCall.CallCount).To(Equal(0))
+			})
+
+			It("logs but does not retry when archiving fails", func() {
+				archiver.ArchiveCall.Returns.Error = errors.New("bucket is gone")
+
+				processor.Process(job, logger)
+
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				Expect(buffer.String()).To(ContainSubstring("message-archive-failed"))
+			})
+		})
+
+		It("does not offer a one-click unsubscribe by default", func() {
+			processor.Process(job, logger)
+
+			for _, header := range mailClient.SendCall.Receives.Message.Headers {
+				Expect(header).NotTo(HavePrefix("List-Unsubscribe"))
+			}
+		})
+
+		Context("when an unsubscribe URL is configured", func() {
+			var tokens common.UnsubscribeTokens
+
+			BeforeEach(func() {
+				cloak, err := conceal.NewCloak([]byte("12345678901234567890123456789012"))
+				Expect(err).NotTo(HaveOccurred())
+
+				tokens = common.NewUnsubscribeTokens(cloak, []byte("12345678901234567890123456789012"))
+
+				processor = v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
+					UAAHost:        "https://uaa.example.com",
+					Sender:         "from@example.com",
+					Domain:         "example.com",
+					UnsubscribeURL: "https://notifications.example.com/unsubscribe/",
+
+					Packager:    common.NewPackager(templateLoader, cloak),
+					MailClient:  mailClient,
+					Database:    database,
+					TokenLoader: tokenLoader,
+					UserLoader:  userLoader,
+
+					KindsRepo:              kindsRepo,
+					ReceiptsRepo:           receiptsRepo,
+					UnsubscribesRepo:       unsubscribesRepo,
+					GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+					MessageStatusUpdater:   messageStatusUpdater,
+					DeliveryFailureHandler: deliveryFailureHandler,
+					UnsubscribeTokens:      tokens,
+				})
+			})
+
+			It("adds one-click List-Unsubscribe headers", func() {
+				processor.Process(job, logger)
+
+				headers := mailClient.SendCall.Receives.Message.Headers
+				Expect(headers).To(ContainElement("List-Unsubscribe-Post: List-Unsubscribe=One-Click"))
+
+				var link string
+				for _, header := range headers {
+					if strings.HasPrefix(header, "List-Unsubscribe: ") {
+						link = strings.TrimPrefix(header, "List-Unsubscribe: ")
+					}
+				}
+				Expect(link).To(HavePrefix("<https://notifications.example.com/unsubscribe/"))
+				Expect(link).To(HaveSuffix(">"))
+
+				token := strings.TrimSuffix(strings.TrimPrefix(link, "<https://notifications.example.com/unsubscribe/"), ">")
+				userGUID, clientID, kindID, err := tokens.Parse(token)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(userGUID).To(Equal("user-123"))
+				Expect(clientID).To(Equal("some-client"))
+				Expect(kindID).To(Equal("some-kind"))
+			})
 
-		It("updates the message status as delivered", func() {
-			processor.Process(job, logger)
+			It("does not add them to critical notifications", func() {
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+					{ID: "some-kind", ClientID: "some-client", Critical: true},
+				}
 
-			Expect(messageStatusUpdater.UpdateCall.Receives.Connection).To(Equal(conn))
-			Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
-			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
-			Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
-		})
+				processor.Process(job, logger)
 
-		It("creates a reciept for the delivery", func() {
-			processor.Process(job, logger)
+				for _, header := range mailClient.SendCall.Receives.Message.Headers {
+					Expect(header).NotTo(HavePrefix("List-Unsubscribe"))
+				}
+			})
 
-			Expect(receiptsRepo.CreateReceiptsCall.Receives.Connection).To(Equal(conn))
-			Expect(receiptsRepo.CreateReceiptsCall.Receives.ClientID).To(Equal("some-client"))
-			Expect(receiptsRepo.CreateReceiptsCall.Receives.KindID).To(Equal("some-kind"))
-			Expect(receiptsRepo.CreateReceiptsCall.Receives.UserGUIDs).To(Equal([]string{"user-123"}))
-		})
+			It("does not add them to messages sent to an email address", func() {
+				delivery.UserGUID = ""
+				delivery.Email = "someone@example.com"
+				job = gobble.NewJob(delivery)
 
-		Context("when the receipt fails to be created", func() {
-			It("retries the job", func() {
-				receiptsRepo.CreateReceiptsCall.Returns.Error = errors.New("something happened")
 				processor.Process(job, logger)
 
-				Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
-				Expect(deliveryFailureHandler.HandleCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				for _, header := range mailClient.SendCall.Receives.Message.Headers {
+					Expect(header).NotTo(HavePrefix("List-Unsubscribe"))
+				}
 			})
-		})
 
-		Context("when loading a zoned token fails", func() {
-			It("retries the job", func() {
-				job := gobble.NewJob(delivery)
+			It("does not add them to simulated messages", func() {
+				delivery.Options.SimulationSinks = []string{"qa@partner.example.com"}
+				job = gobble.NewJob(delivery)
 
-				tokenLoader.LoadCall.Returns.Error = errors.New("failed to load a zoned UAA token")
 				processor.Process(job, logger)
 
-				Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
-				Expect(deliveryFailureHandler.HandleCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				for _, header := range mailClient.SendCall.Receives.Message.Headers {
+					Expect(header).NotTo(HavePrefix("List-Unsubscribe"))
+				}
 			})
 		})
 
-		It("ensures message delivery", func() {
-			processor.Process(job, logger)
+		Context("when the delivery carries a trace", func() {
+			var (
+				exporter *mocks.TraceExporter
+				parent   tracing.SpanContext
+			)
 
-			Expect(mailClient.SendCall.CallCount).To(Equal(1))
-			msg := mailClient.SendCall.Receives.Message
-			Expect(msg.From).To(Equal("from@example.com"))
-			Expect(msg.ReplyTo).To(Equal("thesender@example.com"))
-			Expect(msg.To).To(Equal(fakeUserEmail))
-			Expect(msg.Subject).To(Equal("the subject"))
-			Expect(msg.Body).To(ConsistOf([]mail.Part{
-				{
-					ContentType: "text/plain",
-					Content:     "body content example.com",
-				},
-			}))
-			Expect(msg.Headers).To(ContainElement("X-CF-Client-ID: some-client"))
-			Expect(msg.Headers).To(ContainElement("X-CF-Notification-ID: randomly-generated-guid"))
+			BeforeEach(func() {
+				exporter = mocks.NewTraceExporter()
+				tracing.SetExporter(exporter)
 
-			var formattedTimestamp string
-			prefix := "X-CF-Notification-Timestamp: "
-			for _, header := range msg.Headers {
-				if strings.Contains(header, prefix) {
-					formattedTimestamp = strings.TrimPrefix(header, prefix)
-					break
+				parent = tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+				delivery.Options.TraceParent = parent.Traceparent()
+				job = gobble.NewJob(delivery)
+			})
+
+			AfterEach(func() {
+				tracing.SetExporter(nil)
+			})
+
+			It("continues the trace through rendering and sending", func() {
+				processor.Process(job, logger)
+
+				deliver, ok := exporter.Span("notifications.deliver")
+				Expect(ok).To(BeTrue())
+				Expect(deliver.Kind).To(Equal(tracing.KindConsumer))
+				Expect(deliver.Context.TraceID).To(Equal(parent.TraceID))
+				Expect(deliver.ParentSpanID).To(Equal(parent.SpanID))
+				Expect(deliver.Attributes).To(Equal(map[string]string{
+					"vcap_request_id": "some-request-id",
+					"message_id":      messageID,
+					"status":          common.StatusDelivered,
+				}))
+
+				for _, name := range []string{"notifications.render", "notifications.smtp_send"} {
+					span, ok := exporter.Span(name)
+					Expect(ok).To(BeTrue())
+					Expect(span.Context.TraceID).To(Equal(parent.TraceID))
+					Expect(span.ParentSpanID).To(Equal(deliver.Context.SpanID))
+					Expect(span.Err).NotTo(HaveOccurred())
 				}
-			}
-			Expect(formattedTimestamp).NotTo(BeEmpty())
+			})
 
-			timestamp, err := time.Parse(time.RFC3339, formattedTimestamp)
-			Expect(err).NotTo(HaveOccurred())
-			Expect(timestamp).To(BeTemporally("~", time.Now(), 2*time.Second))
-		})
+			It("marks the spans as failed when sending fails", func() {
+				mailClient.SendCall.Returns.Error = errors.New("Error sending message!!!")
 
-		It("should connect and send the message with the worker's logger session", func() {
-			processor.Process(job, logger)
-			Expect(mailClient.ConnectCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
-			Expect(mailClient.SendCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+				processor.Process(job, logger)
+
+				deliver, _ := exporter.Span("notifications.deliver")
+				Expect(deliver.Err).To(MatchError("delivery failed"))
+
+				send, _ := exporter.Span("notifications.smtp_send")
+				Expect(send.Err).To(MatchError("delivery failed"))
+			})
 		})
 
 		Context("when the delivery fails to be sent", func() {
@@ -306,8 +1200,28 @@ var _ = Describe("DeliveryJobProcessor", func() {
 				It("marks the job for retry", func() {
 					processor.Process(job, logger)
 
-					Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
-					Expect(deliveryFailureHandler.HandleCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Policy).To(Equal(common.RetryPolicy{}))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+				})
+
+				It("retries using the retry policy of the kind", func() {
+					kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+						{
+							ID:               "some-kind",
+							ClientID:         "some-client",
+							RetryMaxAttempts: 3,
+							RetryInterval:    30,
+						},
+					}
+
+					processor.Process(job, logger)
+
+					Expect(kindsRepo.FindCall.CallCount).To(Equal(1))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Policy).To(Equal(common.RetryPolicy{
+						MaxAttempts: 3,
+						Interval:    30 * time.Second,
+					}))
 				})
 
 				It("logs an SMTP send error", func() {
@@ -339,6 +1253,24 @@ var _ = Describe("DeliveryJobProcessor", func() {
 					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
 					Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 				})
+
+				It("publishes a failed delivery event", func() {
+					processor.Process(job, logger)
+
+					Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusFailed))
+				})
+
+				It("counts the failed attempt for the kind", func() {
+					processor.Process(job, logger)
+
+					Expect(kindActivityRepo.IncrementCall.Receives.Counter).To(Equal(models.KindActivityFailed))
+				})
+
+				It("does not record the message in the user's history", func() {
+					processor.Process(job, logger)
+
+					Expect(userMessagesRepo.CreateCall.CallCount).To(Equal(0))
+				})
 			})
 
 			Context("and the error is a connect error", func() {
@@ -381,6 +1313,88 @@ var _ = Describe("DeliveryJobProcessor", func() {
 					Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 				})
 			})
+
+			Context("because the server cannot meet the TLS policy", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.TLSPolicyError{Err: errors.New("server does not offer STARTTLS")}
+				})
+
+				It("updates the message status as tls_policy_failed", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusTLSPolicyFailed))
+					Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusTLSPolicyFailed))
+				})
+
+				It("marks the job for retry", func() {
+					processor.Process(job, logger)
+
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Policy.ErrorClass).To(Equal(common.ErrorClassTLSPolicy))
+				})
+			})
+
+			Context("because the server answered with a temporary SMTP error", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = &textproto.Error{Code: 451, Msg: "4.7.1 Greylisted, try again later"}
+				})
+
+				It("retries on the schedule of the error class", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Policy).To(Equal(common.RetryPolicy{
+						ErrorClass: common.ErrorClassSMTPTemporary,
+					}))
+				})
+			})
+
+			Context("because the server bounced the message with a temporary error", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.RejectedError{Err: &textproto.Error{Code: 452, Msg: "4.2.2 Mailbox full"}}
+				})
+
+				It("records a soft bounce with the server's reply and marks the job for retry", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateBounceCall.Receives.MessageStatus).To(Equal(common.StatusSoftBounced))
+					Expect(messageStatusUpdater.UpdateBounceCall.Receives.Reply).To(Equal(common.SMTPReply{Code: 452, EnhancedStatus: "4.2.2"}))
+					Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusSoftBounced))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Policy.ErrorClass).To(Equal(common.ErrorClassSMTPTemporary))
+				})
+			})
+
+			Context("because the server bounced the message with a permanent error", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.RejectedError{Err: &textproto.Error{Code: 550, Msg: "5.1.1 No such user"}}
+				})
+
+				It("records a hard bounce with the server's reply and does not retry", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateBounceCall.Receives.MessageID).To(Equal(messageID))
+					Expect(messageStatusUpdater.UpdateBounceCall.Receives.MessageStatus).To(Equal(common.StatusHardBounced))
+					Expect(messageStatusUpdater.UpdateBounceCall.Receives.Reply).To(Equal(common.SMTPReply{Code: 550, EnhancedStatus: "5.1.1"}))
+					Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusHardBounced))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				})
+			})
+
+			Context("because the transport throttled the message", func() {
+				BeforeEach(func() {
+					mailClient.SendCall.Returns.Error = mail.UnavailableError{Err: errors.New("Maximum sending rate exceeded.")}
+				})
+
+				It("updates the message status as unavailable and marks the job for retry", func() {
+					processor.Process(job, logger)
+
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUnavailable))
+					Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusUnavailable))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+				})
+			})
 		})
 
 		Context("when recipient has globally unsubscribed", func() {
@@ -418,6 +1432,86 @@ var _ = Describe("DeliveryJobProcessor", func() {
 				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
 				Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 			})
+
+			It("publishes an undeliverable delivery event", func() {
+				Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusUndeliverable))
+			})
+
+			It("does not count it towards the activity of the kind", func() {
+				Expect(kindActivityRepo.IncrementCall.CallCount).To(Equal(0))
+			})
+		})
+
+		Context("when the recipient unsubscribed from the severity of the notification", func() {
+			BeforeEach(func() {
+				severityRepo.GetCall.Returns.Unsubscribed = true
+			})
+
+			It("does not send it", func() {
+				delivery.Options.Severity = models.SeverityInfo
+				job = gobble.NewJob(delivery)
+
+				processor.Process(job, logger)
+
+				Expect(severityRepo.GetCall.Receives.UserID).To(Equal("user-123"))
+				Expect(severityRepo.GetCall.Receives.Severity).To(Equal(models.SeverityInfo))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonUnsubscribed))
+				Expect(buffer.String()).To(ContainSubstring("user-unsubscribed-from-severity"))
+			})
+
+			It("still sends notifications that require action", func() {
+				delivery.Options.Severity = models.SeverityCriticalActionRequired
+				job = gobble.NewJob(delivery)
+
+				processor.Process(job, logger)
+
+				Expect(severityRepo.GetCall.CallCount).To(Equal(0))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+		})
+
+		Context("when the recipient unsubscribes while the message is being prepared", func() {
+			BeforeEach(func() {
+				globalUnsubscribesRepo.GetCall.Returns.UnsubscribedByCall = []bool{false, true}
+
+				processor.Process(job, logger)
+			})
+
+			It("checks the preferences again right before sending", func() {
+				Expect(globalUnsubscribesRepo.GetCall.CallCount).To(Equal(2))
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			})
+
+			It("marks the message undeliverable without retrying it", func() {
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusUndeliverable))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+			})
+
+			It("logs that the user opted out before the send", func() {
+				Expect(buffer.String()).To(ContainSubstring("notifications.worker.opted-out-before-send"))
+			})
+		})
+
+		Context("when the recipient unsubscribes while the job waits to be retried", func() {
+			It("does not send the message on the retry", func() {
+				kind := kindsRepo.FindCall.Returns.Kinds[0]
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{kind, kind}
+				mailClient.SendCall.Returns.Error = errors.New("connection reset")
+
+				processor.Process(job, logger)
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeTrue())
+
+				globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+				mailClient.SendCall.Returns.Error = nil
+
+				processor.Process(job, logger)
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+			})
 		})
 
 		Context("when the recipient hasn't unsubscribed, but doesn't have a valid email address", func() {
@@ -454,6 +1548,7 @@ var _ = Describe("DeliveryJobProcessor", func() {
 					Expect(messageStatusUpdater.UpdateCall.Receives.Connection).To(Equal(conn))
 					Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
 					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+					Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonNoAddress))
 					Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 				})
 			})
@@ -488,9 +1583,63 @@ var _ = Describe("DeliveryJobProcessor", func() {
 					Expect(messageStatusUpdater.UpdateCall.Receives.Connection).To(Equal(conn))
 					Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
 					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+					Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonInvalidAddress))
 					Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 				})
 			})
+
+			Context("when the address does not parse", func() {
+				It("does not send critical notifications to it either", func() {
+					kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+						{ID: "some-kind", ClientID: "some-client", Critical: true},
+					}
+					delivery.Email = "user@@example.com"
+
+					processor.Process(gobble.NewJob(delivery), logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+					Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+					Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonInvalidAddress))
+					Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+				})
+			})
+
+			Context("when the domain of the address does not exist", func() {
+				It("marks the message undeliverable with the validator that was configured", func() {
+					resolver := &mocks.MXResolver{}
+					resolver.LookupMXCall.Returns.Error = &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}
+					resolver.LookupHostCall.Returns.Error = &net.DNSError{Err: "no such host", Name: "example.invalid", IsNotFound: true}
+
+					processor = v1.NewDeliveryJobProcessor(v1.DeliveryJobProcessorConfig{
+						Database:               database,
+						KindsRepo:              kindsRepo,
+						ReceiptsRepo:           receiptsRepo,
+						UnsubscribesRepo:       unsubscribesRepo,
+						GlobalUnsubscribesRepo: globalUnsubscribesRepo,
+						SubscriptionsRepo:      subscriptionsRepo,
+						MessageStatusUpdater:   messageStatusUpdater,
+						DeliveryFailureHandler: deliveryFailureHandler,
+						MailClient:             mailClient,
+						AddressValidator:       mail.NewAddressValidator(resolver),
+					})
+					delivery.Email = "user@example.invalid"
+
+					processor.Process(gobble.NewJob(delivery), logger)
+
+					Expect(mailClient.SendCall.CallCount).To(Equal(0))
+					Expect(resolver.LookupMXCall.Receives.Name).To(Equal("example.invalid"))
+					Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonInvalidAddress))
+				})
+			})
+		})
+
+		It("sends to the normalized address of the recipient", func() {
+			delivery.Email = "Some User <Some.User@Example.COM>"
+
+			processor.Process(gobble.NewJob(delivery), logger)
+
+			Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			Expect(mailClient.SendCall.Receives.Message.To).To(Equal("Some.User@example.com"))
 		})
 
 		Context("when recipient has unsubscribed", func() {
@@ -524,6 +1673,7 @@ var _ = Describe("DeliveryJobProcessor", func() {
 				Expect(messageStatusUpdater.UpdateCall.Receives.Connection).To(Equal(conn))
 				Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal(messageID))
 				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+				Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonUnsubscribed))
 				Expect(messageStatusUpdater.UpdateCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 			})
 
@@ -572,6 +1722,61 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			})
 		})
 
+		Context("when the notification is opt-in", func() {
+			BeforeEach(func() {
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+					{
+						ID:       "some-kind",
+						ClientID: "some-client",
+						OptIn:    true,
+					},
+				}
+			})
+
+			It("sends the email to users who subscribed", func() {
+				subscriptionsRepo.GetCall.Returns.Subscribed = true
+
+				processor.Process(job, logger)
+
+				Expect(subscriptionsRepo.GetCall.Receives.Connection).To(Equal(conn))
+				Expect(subscriptionsRepo.GetCall.Receives.UserID).To(Equal("user-123"))
+				Expect(subscriptionsRepo.GetCall.Receives.ClientID).To(Equal("some-client"))
+				Expect(subscriptionsRepo.GetCall.Receives.KindID).To(Equal("some-kind"))
+				Expect(mailClient.SendCall.CallCount).To(Equal(1))
+			})
+
+			It("does not send the email to users who have not subscribed", func() {
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+				Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+
+				lines, err := parseLogLines(buffer.Bytes())
+				Expect(err).NotTo(HaveOccurred())
+				Expect(lines).To(ContainElement(logLine{
+					Source:   "notifications",
+					Message:  "notifications.worker.user-not-subscribed",
+					LogLevel: int(lager.INFO),
+					Data: map[string]interface{}{
+						"session":         "1",
+						"recipient":       "user-123@example.com",
+						"worker_id":       float64(1234),
+						"message_id":      "randomly-generated-guid",
+						"vcap_request_id": "some-request-id",
+					},
+				}))
+			})
+
+			It("does not send the email when the subscription cannot be looked up", func() {
+				subscriptionsRepo.GetCall.Returns.Subscribed = true
+				subscriptionsRepo.GetCall.Returns.Error = errors.New("subscriptions db error")
+
+				processor.Process(job, logger)
+
+				Expect(mailClient.SendCall.CallCount).To(Equal(0))
+			})
+		})
+
 		Context("when the template contains syntax errors", func() {
 			BeforeEach(func() {
 				templateLoader.LoadTemplatesCall.Returns.Templates = common.Templates{
@@ -591,8 +1796,8 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			It("marks the job for retry later", func() {
 				processor.Process(job, logger)
 
-				Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
-				Expect(deliveryFailureHandler.HandleCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 			})
 
 			It("logs that the packer errored", func() {
@@ -639,8 +1844,8 @@ var _ = Describe("DeliveryJobProcessor", func() {
 			It("marks the job for retry later", func() {
 				processor.Process(job, logger)
 
-				Expect(deliveryFailureHandler.HandleCall.Receives.Job).To(Equal(job))
-				Expect(deliveryFailureHandler.HandleCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+				Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Logger.SessionName()).To(Equal("notifications.worker"))
 			})
 		})
 	})
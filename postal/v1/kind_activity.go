@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+)
+
+type kindActivityRecorder interface {
+	Increment(conn models.ConnectionInterface, clientID, kindID, counter string, at time.Time) error
+}
+
+// recordKindActivity counts sends and failed attempts towards the activity
+// report of the delivery's kind. Other statuses are not counted.
+func recordKindActivity(recorder kindActivityRecorder, conn models.ConnectionInterface, delivery common.Delivery, status string, logger lager.Logger) {
+	if recorder == nil || delivery.Options.KindID == "" {
+		return
+	}
+
+	var counter string
+	switch status {
+	case common.StatusDelivered:
+		counter = models.KindActivitySent
+	case common.StatusFailed, common.StatusTLSPolicyFailed, common.StatusUnavailable, common.StatusSoftBounced, common.StatusHardBounced:
+		counter = models.KindActivityFailed
+	default:
+		return
+	}
+
+	err := recorder.Increment(conn, delivery.ClientID, delivery.Options.KindID, counter, time.Now())
+	if err != nil {
+		logger.Error("kind-activity-record-failed", err)
+	}
+}
@@ -1,7 +1,11 @@
 package v1
 
 import (
+	"database/sql"
+	"time"
+
 	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/pivotal-golang/lager"
 )
@@ -20,10 +24,13 @@ func NewMessageStatusUpdater(messagesRepo MessageUpserter) MessageStatusUpdater
 	}
 }
 
-func (mu MessageStatusUpdater) Update(conn db.ConnectionInterface, messageID, messageStatus, campaignID string, logger lager.Logger) {
+func (mu MessageStatusUpdater) Update(conn db.ConnectionInterface, messageID, messageStatus, reason, campaignID, workerID string, claimedAt time.Time, logger lager.Logger) {
 	_, err := mu.messagesRepo.Upsert(conn, models.Message{
 		ID:         messageID,
 		Status:     messageStatus,
+		Reason:     reason,
+		WorkerID:   workerID,
+		ClaimedAt:  sql.NullTime{Time: claimedAt, Valid: !claimedAt.IsZero()},
 	})
 	if err != nil {
 		logger.Session("message-updater").Error("failed-message-status-upsert", err, lager.Data{
@@ -31,3 +38,22 @@ func (mu MessageStatusUpdater) Update(conn db.ConnectionInterface, messageID, me
 		})
 	}
 }
+
+// UpdateBounce records the status of a message a mail server bounced, along
+// with the server's reply.
+func (mu MessageStatusUpdater) UpdateBounce(conn db.ConnectionInterface, messageID, messageStatus string, reply common.SMTPReply, workerID string, claimedAt time.Time, logger lager.Logger) {
+	_, err := mu.messagesRepo.Upsert(conn, models.Message{
+		ID:                 messageID,
+		Status:             messageStatus,
+		SMTPCode:           reply.Code,
+		SMTPEnhancedStatus: reply.EnhancedStatus,
+		WorkerID:           workerID,
+		ClaimedAt:          sql.NullTime{Time: claimedAt, Valid: !claimedAt.IsZero()},
+	})
+	if err != nil {
+		logger.Session("message-updater").Error("failed-message-status-upsert", err, lager.Data{
+			"status":    messageStatus,
+			"smtp_code": reply.Code,
+		})
+	}
+}
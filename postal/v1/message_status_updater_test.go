@@ -2,8 +2,11 @@ package v1_test
 
 import (
 	"bytes"
+	"database/sql"
 	"errors"
+	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/postal/v1"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
@@ -40,7 +43,7 @@ var _ = Describe("MessageStatusUpdater", func() {
 	})
 
 	It("updates the status of the message", func() {
-		updater.Update(conn, "some-message-id", "message-status", "campaign-id", logger)
+		updater.Update(conn, "some-message-id", "message-status", "", "campaign-id", "", time.Time{}, logger)
 
 		Expect(messagesRepo.UpsertCall.Receives.Connection).To(Equal(conn))
 		Expect(messagesRepo.UpsertCall.Receives.Messages[0]).To(Equal(models.Message{
@@ -49,11 +52,48 @@ var _ = Describe("MessageStatusUpdater", func() {
 		}))
 	})
 
+	It("records the worker that claimed the message", func() {
+		claimedAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+		updater.Update(conn, "some-message-id", "message-status", "", "", "worker-1-some-instance-42", claimedAt, logger)
+
+		Expect(messagesRepo.UpsertCall.Receives.Messages[0]).To(Equal(models.Message{
+			ID:        "some-message-id",
+			Status:    "message-status",
+			WorkerID:  "worker-1-some-instance-42",
+			ClaimedAt: sql.NullTime{Time: claimedAt, Valid: true},
+		}))
+	})
+
+	It("records why an undeliverable message was not sent", func() {
+		updater.Update(conn, "some-message-id", "undeliverable", "invalid_address", "", "", time.Time{}, logger)
+
+		Expect(messagesRepo.UpsertCall.Receives.Messages[0]).To(Equal(models.Message{
+			ID:     "some-message-id",
+			Status: "undeliverable",
+			Reason: "invalid_address",
+		}))
+	})
+
+	It("records the reply of the server that bounced the message", func() {
+		claimedAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+		updater.UpdateBounce(conn, "some-message-id", "hard_bounced", common.SMTPReply{Code: 550, EnhancedStatus: "5.1.1"}, "worker-1-some-instance-42", claimedAt, logger)
+
+		Expect(messagesRepo.UpsertCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.UpsertCall.Receives.Messages[0]).To(Equal(models.Message{
+			ID:                 "some-message-id",
+			Status:             "hard_bounced",
+			SMTPCode:           550,
+			SMTPEnhancedStatus: "5.1.1",
+			WorkerID:           "worker-1-some-instance-42",
+			ClaimedAt:          sql.NullTime{Time: claimedAt, Valid: true},
+		}))
+	})
+
 	Context("failure cases", func() {
 		It("logs the error when the repository fails to upsert", func() {
 			messagesRepo.UpsertCall.Returns.Error = errors.New("failed to upsert")
 
-			updater.Update(conn, "some-message-id", "message-status", "campaign-id", logger)
+			updater.Update(conn, "some-message-id", "message-status", "", "campaign-id", "", time.Time{}, logger)
 
 			lines, err := parseLogLines(buffer.Bytes())
 			Expect(err).NotTo(HaveOccurred())
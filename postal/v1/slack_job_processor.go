@@ -0,0 +1,211 @@
+package v1
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+type SlackJobProcessorConfig struct {
+	Sender string
+	Domain string
+
+	Client   httpDoer
+	Packager common.Packager
+	Database db.DatabaseInterface
+
+	KindsRepo              kindsFinder
+	ClientsRepo            clientFinder
+	MessageStatusUpdater   messageStatusUpdater
+	DeliveryFailureHandler deliveryFailureHandler
+	DeliveryEventPublisher deliveryEventPublisher
+	KindActivityRepo       kindActivityRecorder
+}
+
+// SlackJobProcessor posts notifications to the Slack incoming webhook of
+// their kind. The webhook is looked up when the job runs, so that a changed
+// or removed webhook applies to posts that are already queued.
+type SlackJobProcessor struct {
+	sender string
+	domain string
+
+	client   httpDoer
+	packager common.Packager
+	database db.DatabaseInterface
+
+	kindsRepo              kindsFinder
+	clientsRepo            clientFinder
+	messageStatusUpdater   messageStatusUpdater
+	deliveryFailureHandler deliveryFailureHandler
+	deliveryEventPublisher deliveryEventPublisher
+	kindActivityRepo       kindActivityRecorder
+}
+
+type slackPayload struct {
+	Text    string `json:"text"`
+	Channel string `json:"channel,omitempty"`
+}
+
+func NewSlackJobProcessor(config SlackJobProcessorConfig) SlackJobProcessor {
+	return SlackJobProcessor{
+		sender: config.Sender,
+		domain: config.Domain,
+
+		client:   config.Client,
+		packager: config.Packager,
+		database: config.Database,
+
+		kindsRepo:              config.KindsRepo,
+		clientsRepo:            config.ClientsRepo,
+		messageStatusUpdater:   config.MessageStatusUpdater,
+		deliveryFailureHandler: config.DeliveryFailureHandler,
+		deliveryEventPublisher: config.DeliveryEventPublisher,
+		kindActivityRepo:       config.KindActivityRepo,
+	}
+}
+
+func (p SlackJobProcessor) Process(job *gobble.Job, logger lager.Logger) error {
+	var delivery common.Delivery
+	err := job.Unmarshal(&delivery)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.worker.panic.json", nil).Inc(1)
+		return nil
+	}
+	delivery.WorkerID = job.WorkerID
+	delivery.ClaimedAt = job.ClaimedAt
+
+	logger = logger.Session("slack", lager.Data{
+		"message_id":      delivery.MessageID,
+		"vcap_request_id": delivery.VCAPRequestID,
+	})
+
+	if delivery.Expired(time.Now()) {
+		logger.Info("message-expired", lager.Data{"deadline": delivery.Options.Deadline})
+		p.updateStatus(delivery, common.StatusExpired, "", logger)
+		metrics.GetOrRegisterCounter("notifications.worker.expired", nil).Inc(1)
+		return nil
+	}
+
+	conn := p.database.Connection()
+	kind, err := p.kindsRepo.Find(conn, delivery.Options.KindID, delivery.ClientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); !ok {
+			logger.Error("kind-lookup-failed", err)
+			p.deliveryFailureHandler.HandleWithPolicy(job, common.RetryPolicy{}, logger)
+			return nil
+		}
+	}
+
+	if kind.SlackWebhookURL == "" {
+		logger.Info("no-slack-webhook")
+		p.updateStatus(delivery, common.StatusUndeliverable, common.ReasonNoSlackWebhook, logger)
+		return nil
+	}
+
+	policy := common.RetryPolicy{
+		MaxAttempts: kind.RetryMaxAttempts,
+		Interval:    time.Duration(kind.RetryInterval) * time.Second,
+	}
+
+	context, err := p.packager.PrepareContext(delivery, p.sender, p.domain)
+	if err != nil {
+		logger.Error("template-load-failed", err)
+		p.fail(job, delivery, policy, logger)
+		return nil
+	}
+	context.LinkDomains = p.linkDomains(delivery.ClientID, logger)
+
+	text, err := p.packager.PackSlack(context)
+	if err != nil {
+		logger.Info("template-pack-failed")
+		p.updateStatus(delivery, common.StatusFailed, "", logger)
+		return nil
+	}
+
+	// Sinks are email addresses, so the post of a client in simulation
+	// mode is rendered but goes nowhere.
+	if delivery.Simulated() {
+		logger.Info("simulated-delivery")
+		p.updateStatus(delivery, common.StatusDelivered, "", logger)
+		return nil
+	}
+
+	body, err := json.Marshal(slackPayload{
+		Text:    text,
+		Channel: kind.SlackChannel,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	request, err := http.NewRequest("POST", kind.SlackWebhookURL, bytes.NewReader(body))
+	if err != nil {
+		logger.Error("invalid-slack-webhook-url", err)
+		p.updateStatus(delivery, common.StatusFailed, "", logger)
+		return nil
+	}
+	request.Header.Set("Content-Type", "application/json")
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		logger.Info("failed", lager.Data{"error": err.Error()})
+		p.fail(job, delivery, policy, logger)
+		return nil
+	}
+	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		logger.Info("failed", lager.Data{"status_code": response.StatusCode})
+		p.fail(job, delivery, policy, logger)
+		return nil
+	}
+
+	metrics.GetOrRegisterCounter("notifications.slack.delivered", nil).Inc(1)
+	logger.Info("delivered")
+	p.updateStatus(delivery, common.StatusDelivered, "", logger)
+
+	return nil
+}
+
+func (p SlackJobProcessor) fail(job *gobble.Job, delivery common.Delivery, policy common.RetryPolicy, logger lager.Logger) {
+	metrics.GetOrRegisterCounter("notifications.slack.failed", nil).Inc(1)
+	p.updateStatus(delivery, common.StatusFailed, "", logger)
+	p.deliveryFailureHandler.HandleWithPolicy(job, policy, logger)
+}
+
+func (p SlackJobProcessor) linkDomains(clientID string, logger lager.Logger) map[string]string {
+	if p.clientsRepo == nil {
+		return nil
+	}
+
+	client, err := p.clientsRepo.Find(p.database.Connection(), clientID)
+	if err != nil {
+		logger.Error("client-branding-load-failed", err)
+		return nil
+	}
+
+	return client.LinkDomains
+}
+
+func (p SlackJobProcessor) updateStatus(delivery common.Delivery, status, reason string, logger lager.Logger) {
+	p.messageStatusUpdater.Update(p.database.Connection(), delivery.MessageID, status, reason, "", delivery.WorkerID, delivery.ClaimedAt, logger)
+	recordKindActivity(p.kindActivityRepo, p.database.Connection(), delivery, status, logger)
+
+	if p.deliveryEventPublisher == nil {
+		return
+	}
+
+	err := p.deliveryEventPublisher.Publish(delivery, status)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.webhook.enqueue.failed", nil).Inc(1)
+		logger.Error("delivery-event-enqueue-failed", err)
+	}
+}
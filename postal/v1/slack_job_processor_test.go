@@ -0,0 +1,269 @@
+package v1_test
+
+import (
+	"bytes"
+	"crypto/md5"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/postal/v1"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/conceal"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SlackJobProcessor", func() {
+	var (
+		processor              v1.SlackJobProcessor
+		kindsRepo              *mocks.KindsRepo
+		clientsRepo            *mocks.ClientsRepository
+		templateLoader         *mocks.TemplatesLoader
+		messageStatusUpdater   *mocks.MessageStatusUpdater
+		deliveryFailureHandler *mocks.DeliveryFailureHandler
+		deliveryEventPublisher *mocks.DeliveryEventPublisher
+		kindActivityRepo       *mocks.KindActivityRepo
+		database               *mocks.Database
+		conn                   *mocks.Connection
+		logger                 lager.Logger
+		server                 *httptest.Server
+		received               *http.Request
+		receivedBody           []byte
+		responseCode           int
+		job                    *gobble.Job
+		claimedAt              time.Time
+	)
+
+	BeforeEach(func() {
+		responseCode = http.StatusOK
+		received = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received = req
+			receivedBody, _ = ioutil.ReadAll(req.Body)
+			w.WriteHeader(responseCode)
+		}))
+
+		kindsRepo = mocks.NewKindsRepo()
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+			{
+				ID:               "some-kind",
+				ClientID:         "some-client",
+				SlackWebhookURL:  server.URL + "/services/T000/B000/XXXX",
+				SlackChannel:     "#ops",
+				RetryMaxAttempts: 3,
+				RetryInterval:    60,
+			},
+		}
+		clientsRepo = mocks.NewClientsRepository()
+		templateLoader = mocks.NewTemplatesLoader()
+		templateLoader.LoadTemplatesCall.Returns.Templates = common.Templates{
+			Slack: "*{{.Subject}}* {{.Text}}",
+		}
+		messageStatusUpdater = mocks.NewMessageStatusUpdater()
+		deliveryFailureHandler = mocks.NewDeliveryFailureHandler()
+		deliveryEventPublisher = mocks.NewDeliveryEventPublisher()
+		kindActivityRepo = mocks.NewKindActivityRepo()
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+
+		sum := md5.Sum([]byte("banana's are so very tasty"))
+		cloak, err := conceal.NewCloak(sum[:])
+		Expect(err).NotTo(HaveOccurred())
+
+		claimedAt = time.Date(2015, time.June, 8, 14, 32, 11, 0, time.UTC)
+		job = gobble.NewJob(common.Delivery{
+			JobType:   common.SlackJobType,
+			MessageID: "message-123",
+			ClientID:  "some-client",
+			Options: common.Options{
+				KindID:  "some-kind",
+				Subject: "Disk <full>",
+				Text:    "Clean up & retry",
+			},
+		})
+		job.WorkerID = "worker-1"
+		job.ClaimedAt = claimedAt
+
+		processor = v1.NewSlackJobProcessor(v1.SlackJobProcessorConfig{
+			Sender: "from@example.com",
+			Domain: "example.com",
+
+			Client:   http.DefaultClient,
+			Packager: common.NewPackager(templateLoader, cloak),
+			Database: database,
+
+			KindsRepo:              kindsRepo,
+			ClientsRepo:            clientsRepo,
+			MessageStatusUpdater:   messageStatusUpdater,
+			DeliveryFailureHandler: deliveryFailureHandler,
+			DeliveryEventPublisher: deliveryEventPublisher,
+			KindActivityRepo:       kindActivityRepo,
+		})
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts the rendered template to the webhook of the kind", func() {
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(kindsRepo.FindCall.Receives.KindID).To(Equal("some-kind"))
+		Expect(kindsRepo.FindCall.Receives.ClientID).To(Equal("some-client"))
+
+		Expect(received.Method).To(Equal("POST"))
+		Expect(received.URL.Path).To(Equal("/services/T000/B000/XXXX"))
+		Expect(received.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(receivedBody).To(MatchJSON(`{
+			"text": "*Disk &lt;full&gt;* Clean up &amp; retry",
+			"channel": "#ops"
+		}`))
+	})
+
+	It("marks the message as delivered", func() {
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(messageStatusUpdater.UpdateCall.Receives.Connection).To(Equal(conn))
+		Expect(messageStatusUpdater.UpdateCall.Receives.MessageID).To(Equal("message-123"))
+		Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+		Expect(messageStatusUpdater.UpdateCall.Receives.WorkerID).To(Equal("worker-1"))
+		Expect(messageStatusUpdater.UpdateCall.Receives.ClaimedAt).To(Equal(claimedAt))
+		Expect(deliveryEventPublisher.PublishCall.Receives.Status).To(Equal(common.StatusDelivered))
+		Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+	})
+
+	It("counts the post as sent for the kind", func() {
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(kindActivityRepo.IncrementCall.CallCount).To(Equal(1))
+		Expect(kindActivityRepo.IncrementCall.Receives.Connection).To(Equal(conn))
+		Expect(kindActivityRepo.IncrementCall.Receives.ClientID).To(Equal("some-client"))
+		Expect(kindActivityRepo.IncrementCall.Receives.KindID).To(Equal("some-kind"))
+		Expect(kindActivityRepo.IncrementCall.Receives.Counter).To(Equal(models.KindActivitySent))
+	})
+
+	Context("when the kind has no channel", func() {
+		It("leaves the channel to the webhook", func() {
+			kindsRepo.FindCall.Returns.Kinds[0].SlackChannel = ""
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(receivedBody).To(MatchJSON(`{"text": "*Disk &lt;full&gt;* Clean up &amp; retry"}`))
+		})
+	})
+
+	Context("when the kind no longer has a webhook", func() {
+		It("marks the message as undeliverable without posting", func() {
+			kindsRepo.FindCall.Returns.Kinds[0].SlackWebhookURL = ""
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(received).To(BeNil())
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusUndeliverable))
+			Expect(messageStatusUpdater.UpdateCall.Receives.Reason).To(Equal(common.ReasonNoSlackWebhook))
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the client is in simulation mode", func() {
+		It("marks the message as delivered without posting", func() {
+			job = gobble.NewJob(common.Delivery{
+				JobType:   common.SlackJobType,
+				MessageID: "message-123",
+				ClientID:  "some-client",
+				Options: common.Options{
+					KindID:          "some-kind",
+					Subject:         "Disk <full>",
+					SimulationSinks: []string{"qa@partner.example.com"},
+				},
+			})
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(received).To(BeNil())
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusDelivered))
+		})
+	})
+
+	Context("when the deadline of the message has passed", func() {
+		It("marks the message expired without posting", func() {
+			job = gobble.NewJob(common.Delivery{
+				JobType:   common.SlackJobType,
+				MessageID: "message-123",
+				ClientID:  "some-client",
+				Options: common.Options{
+					KindID:   "some-kind",
+					Subject:  "Disk <full>",
+					Deadline: time.Now().Add(-1 * time.Minute),
+				},
+			})
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(received).To(BeNil())
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusExpired))
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the kind cannot be loaded", func() {
+		It("retries the job", func() {
+			kindsRepo.FindCall.Returns.Error = errors.New("database is down")
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(received).To(BeNil())
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+		})
+	})
+
+	Context("when the webhook responds with an error", func() {
+		It("marks the message as failed and retries with the policy of the kind", func() {
+			responseCode = http.StatusInternalServerError
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
+			Expect(kindActivityRepo.IncrementCall.Receives.Counter).To(Equal(models.KindActivityFailed))
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Policy).To(Equal(common.RetryPolicy{
+				MaxAttempts: 3,
+				Interval:    time.Minute,
+			}))
+		})
+	})
+
+	Context("when the template cannot be rendered", func() {
+		It("marks the message as failed without retrying", func() {
+			templateLoader.LoadTemplatesCall.Returns.Templates.Slack = `{{template "missing" .}}`
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(received).To(BeNil())
+			Expect(messageStatusUpdater.UpdateCall.Receives.MessageStatus).To(Equal(common.StatusFailed))
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+		})
+	})
+})
@@ -18,24 +18,36 @@ type templateFinder interface {
 	FindByID(connection models.ConnectionInterface, templateID string) (models.Template, error)
 }
 
+type translationFinder interface {
+	Find(connection models.ConnectionInterface, templateID, locale string) (models.TemplateTranslation, error)
+}
+
+type partialLister interface {
+	FindAll(connection models.ConnectionInterface) ([]models.TemplatePartial, error)
+}
+
 type TemplatesLoader struct {
 	database db.DatabaseInterface
 
-	clientsRepo   clientFinder
-	kindsRepo     kindFinder
-	templatesRepo templateFinder
+	clientsRepo      clientFinder
+	kindsRepo        kindFinder
+	templatesRepo    templateFinder
+	translationsRepo translationFinder
+	partialsRepo     partialLister
 }
 
-func NewTemplatesLoader(database db.DatabaseInterface, clientsRepo clientFinder, kindsRepo kindFinder, templatesRepo templateFinder) TemplatesLoader {
+func NewTemplatesLoader(database db.DatabaseInterface, clientsRepo clientFinder, kindsRepo kindFinder, templatesRepo templateFinder, translationsRepo translationFinder, partialsRepo partialLister) TemplatesLoader {
 	return TemplatesLoader{
-		database:      database,
-		clientsRepo:   clientsRepo,
-		kindsRepo:     kindsRepo,
-		templatesRepo: templatesRepo,
+		database:         database,
+		clientsRepo:      clientsRepo,
+		kindsRepo:        kindsRepo,
+		templatesRepo:    templatesRepo,
+		translationsRepo: translationsRepo,
+		partialsRepo:     partialsRepo,
 	}
 }
 
-func (loader TemplatesLoader) LoadTemplates(clientID, kindID, templateID string) (common.Templates, error) {
+func (loader TemplatesLoader) LoadTemplates(clientID, kindID, templateID, locale string) (common.Templates, error) {
 	conn := loader.database.Connection()
 
 	if kindID != "" {
@@ -45,7 +57,7 @@ func (loader TemplatesLoader) LoadTemplates(clientID, kindID, templateID string)
 		}
 
 		if kind.TemplateID != models.DefaultTemplateID {
-			return loader.loadTemplate(conn, kind.TemplateID)
+			return loader.loadTemplate(conn, kind.TemplateID, locale)
 		}
 	}
 
@@ -54,18 +66,68 @@ func (loader TemplatesLoader) LoadTemplates(clientID, kindID, templateID string)
 		return common.Templates{}, err
 	}
 
-	return loader.loadTemplate(conn, client.TemplateID)
+	return loader.loadTemplate(conn, client.TemplateID, locale)
 }
 
-func (loader TemplatesLoader) loadTemplate(conn db.ConnectionInterface, templateID string) (common.Templates, error) {
+// loadTemplate attaches every partial to the template. Partials are
+// shared by all locales; translate a partial's contents in the template
+// that includes it instead. The template row always holds its active
+// version, so a rolled back template is rendered as it was.
+func (loader TemplatesLoader) loadTemplate(conn db.ConnectionInterface, templateID, locale string) (common.Templates, error) {
 	template, err := loader.templatesRepo.FindByID(conn, templateID)
 	if err != nil {
 		return common.Templates{}, err
 	}
 
+	partials, err := loader.loadPartials(conn)
+	if err != nil {
+		return common.Templates{}, err
+	}
+
+	for _, candidate := range models.LocaleFallbacks(locale) {
+		translation, err := loader.translationsRepo.Find(conn, templateID, candidate)
+		if err != nil {
+			if _, ok := err.(models.NotFoundError); ok {
+				continue
+			}
+
+			return common.Templates{}, err
+		}
+
+		// Slack posts go to a channel rather than a user, so they are not
+		// translated.
+		return common.Templates{
+			Subject:  translation.Subject,
+			Text:     translation.Text,
+			HTML:     translation.HTML,
+			Slack:    template.Slack,
+			Partials: partials,
+		}, nil
+	}
+
 	return common.Templates{
-		Subject: template.Subject,
-		Text:    template.Text,
-		HTML:    template.HTML,
+		Subject:  template.Subject,
+		Text:     template.Text,
+		HTML:     template.HTML,
+		Slack:    template.Slack,
+		Partials: partials,
 	}, nil
 }
+
+func (loader TemplatesLoader) loadPartials(conn db.ConnectionInterface) ([]common.Partial, error) {
+	records, err := loader.partialsRepo.FindAll(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var partials []common.Partial
+	for _, record := range records {
+		partials = append(partials, common.Partial{
+			Name: record.Name,
+			Text: record.Text,
+			HTML: record.HTML,
+		})
+	}
+
+	return partials, nil
+}
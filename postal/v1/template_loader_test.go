@@ -15,24 +15,28 @@ import (
 
 var _ = Describe("TemplateLoader", func() {
 	var (
-		loader        v1.TemplatesLoader
-		clientsRepo   *mocks.ClientsRepository
-		kindsRepo     *mocks.KindsRepo
-		templatesRepo *mocks.TemplatesRepo
-		conn          db.ConnectionInterface
-		database      *mocks.Database
+		loader           v1.TemplatesLoader
+		clientsRepo      *mocks.ClientsRepository
+		kindsRepo        *mocks.KindsRepo
+		templatesRepo    *mocks.TemplatesRepo
+		translationsRepo *mocks.TemplateTranslationsRepo
+		partialsRepo     *mocks.TemplatePartialsRepo
+		conn             db.ConnectionInterface
+		database         *mocks.Database
 	)
 
 	BeforeEach(func() {
 		clientsRepo = mocks.NewClientsRepository()
 		kindsRepo = mocks.NewKindsRepo()
 		templatesRepo = mocks.NewTemplatesRepo()
+		translationsRepo = mocks.NewTemplateTranslationsRepo()
+		partialsRepo = mocks.NewTemplatePartialsRepo()
 
 		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = conn
 
-		loader = v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo)
+		loader = v1.NewTemplatesLoader(database, clientsRepo, kindsRepo, templatesRepo, translationsRepo, partialsRepo)
 	})
 
 	Describe("LoadTemplates", func() {
@@ -79,7 +83,7 @@ var _ = Describe("TemplateLoader", func() {
 			})
 
 			It("returns the template belonging to the kind", func() {
-				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(templates).To(Equal(common.Templates{
 					HTML:    "<p>kind template</p>",
@@ -100,6 +104,7 @@ var _ = Describe("TemplateLoader", func() {
 					HTML:    "<p>client template</p>",
 					Text:    "some client template text",
 					Subject: "client subject",
+					Slack:   "*client* slack template",
 				}
 
 				clientsRepo.FindCall.Returns.Client = models.Client{
@@ -109,12 +114,13 @@ var _ = Describe("TemplateLoader", func() {
 			})
 
 			It("returns the template belonging to the client", func() {
-				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(templates).To(Equal(common.Templates{
 					HTML:    "<p>client template</p>",
 					Text:    "some client template text",
 					Subject: "client subject",
+					Slack:   "*client* slack template",
 				}))
 
 				Expect(templatesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
@@ -124,7 +130,7 @@ var _ = Describe("TemplateLoader", func() {
 
 		Context("when the neither client nor kind has a template", func() {
 			It("returns the default template", func() {
-				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(templates).To(Equal(common.Templates{
 					HTML:    "<p>The default template</p>",
@@ -136,7 +142,7 @@ var _ = Describe("TemplateLoader", func() {
 
 		Context("when kindID is an empty string", func() {
 			It("does not look for a template belonging to the kind", func() {
-				templates, err := loader.LoadTemplates("my-client-id", "", "")
+				templates, err := loader.LoadTemplates("my-client-id", "", "", "")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(templates).To(Equal(common.Templates{
 					HTML:    "<p>The default template</p>",
@@ -146,11 +152,90 @@ var _ = Describe("TemplateLoader", func() {
 			})
 		})
 
+		Context("when the recipient has a locale", func() {
+			BeforeEach(func() {
+				translationsRepo.FindCall.Returns.Translations = map[string]models.TemplateTranslation{
+					"pt": {
+						TemplateID: models.DefaultTemplateID,
+						Locale:     "pt",
+						HTML:       "<p>O modelo padrão</p>",
+						Text:       "O modelo padrão",
+						Subject:    "assunto padrão",
+					},
+				}
+			})
+
+			It("returns the best matching translation", func() {
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "pt_BR")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(templates).To(Equal(common.Templates{
+					HTML:    "<p>O modelo padrão</p>",
+					Text:    "O modelo padrão",
+					Subject: "assunto padrão",
+				}))
+
+				Expect(translationsRepo.FindCall.Receives.Connection).To(Equal(conn))
+				Expect(translationsRepo.FindCall.Receives.TemplateID).To(Equal(models.DefaultTemplateID))
+				Expect(translationsRepo.FindCall.Receives.Locales).To(Equal([]string{"pt-br", "pt"}))
+			})
+
+			It("falls back to the template when there is no matching translation", func() {
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "fr-CA")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(templates).To(Equal(common.Templates{
+					HTML:    "<p>The default template</p>",
+					Text:    "The default template",
+					Subject: "default subject",
+				}))
+			})
+
+			It("bubbles up other errors from the translations repo", func() {
+				translationsRepo.FindCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "pt-BR")
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+			})
+		})
+
+		Context("when partials have been defined", func() {
+			BeforeEach(func() {
+				partialsRepo.FindAllCall.Returns.Partials = []models.TemplatePartial{
+					{Name: "footer", Text: "-- {{.ClientID}}", HTML: "<footer>{{.ClientID}}</footer>", Version: 3},
+				}
+				translationsRepo.FindCall.Returns.Translations = map[string]models.TemplateTranslation{
+					"pt": {TemplateID: models.DefaultTemplateID, Locale: "pt", HTML: "<p>O modelo padrão</p>"},
+				}
+			})
+
+			It("attaches them to the template", func() {
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(templates.Partials).To(Equal([]common.Partial{
+					{Name: "footer", Text: "-- {{.ClientID}}", HTML: "<footer>{{.ClientID}}</footer>"},
+				}))
+				Expect(partialsRepo.FindAllCall.Receives.Connection).To(Equal(conn))
+			})
+
+			It("attaches them to a translation", func() {
+				templates, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "pt")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(templates.HTML).To(Equal("<p>O modelo padrão</p>"))
+				Expect(templates.Partials).To(HaveLen(1))
+			})
+
+			It("bubbles up errors from the partials repo", func() {
+				partialsRepo.FindAllCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "")
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+			})
+		})
+
 		Context("when the kinds repo has an error", func() {
 			It("bubbles up the error", func() {
 				kindsRepo.FindCall.Returns.Error = errors.New("BOOM!")
 
-				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "")
 				Expect(err).To(HaveOccurred())
 			})
 
@@ -160,7 +245,7 @@ var _ = Describe("TemplateLoader", func() {
 			It("bubbles up the error", func() {
 				clientsRepo.FindCall.Returns.Error = errors.New("BOOM!")
 
-				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "")
+				_, err := loader.LoadTemplates("my-client-id", "my-kind-id", "", "")
 				Expect(err).To(HaveOccurred())
 			})
 		})
@@ -0,0 +1,191 @@
+package v1
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+const (
+	WebhookTimestampHeader = "X-Notifications-Timestamp"
+	WebhookSignatureHeader = "X-Notifications-Signature"
+)
+
+type httpDoer interface {
+	Do(*http.Request) (*http.Response, error)
+}
+
+type deliveryEventPayload struct {
+	MessageID       string            `json:"message_id"`
+	ClientID        string            `json:"client_id"`
+	KindID          string            `json:"kind_id,omitempty"`
+	Recipient       string            `json:"recipient"`
+	Status          string            `json:"status"`
+	Simulated       bool              `json:"simulated,omitempty"`
+	Severity        string            `json:"severity,omitempty"`
+	Metadata        map[string]string `json:"metadata,omitempty"`
+	RequestReceived time.Time         `json:"request_received"`
+	OccurredAt      time.Time         `json:"occurred_at"`
+}
+
+type registrationEventPayload struct {
+	Event      string                      `json:"event"`
+	ClientID   string                      `json:"client_id"`
+	Actor      common.RegistrationActor    `json:"actor"`
+	Changes    []common.RegistrationChange `json:"changes"`
+	OccurredAt time.Time                   `json:"occurred_at"`
+}
+
+// WebhookJobProcessor posts delivery and registration events to client
+// callback URLs. Events that cannot be posted are retried with the default
+// delivery backoff.
+type WebhookJobProcessor struct {
+	client                 httpDoer
+	signingKey             []byte
+	clock                  clock
+	deliveryFailureHandler deliveryFailureHandler
+}
+
+func NewWebhookJobProcessor(client httpDoer, signingKey []byte, clock clock, deliveryFailureHandler deliveryFailureHandler) WebhookJobProcessor {
+	return WebhookJobProcessor{
+		client:                 client,
+		signingKey:             signingKey,
+		clock:                  clock,
+		deliveryFailureHandler: deliveryFailureHandler,
+	}
+}
+
+func (p WebhookJobProcessor) Process(job *gobble.Job, logger lager.Logger) error {
+	var typedJob struct {
+		JobType string
+	}
+
+	err := job.Unmarshal(&typedJob)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.worker.panic.json", nil).Inc(1)
+		return nil
+	}
+
+	if typedJob.JobType == common.RegistrationEventJobType {
+		return p.processRegistrationEvent(job, logger)
+	}
+
+	return p.processDeliveryEvent(job, logger)
+}
+
+func (p WebhookJobProcessor) processDeliveryEvent(job *gobble.Job, logger lager.Logger) error {
+	var event common.DeliveryEvent
+	err := job.Unmarshal(&event)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.worker.panic.json", nil).Inc(1)
+		return nil
+	}
+
+	logger = logger.Session("webhook", lager.Data{
+		"message_id":   event.MessageID,
+		"callback_url": event.CallbackURL,
+		"status":       event.Status,
+	})
+
+	p.post(job, logger, event.CallbackURL, deliveryEventPayload{
+		MessageID:       event.MessageID,
+		ClientID:        event.ClientID,
+		KindID:          event.KindID,
+		Recipient:       event.Recipient,
+		Status:          event.Status,
+		Simulated:       event.Simulated,
+		Severity:        event.Severity,
+		Metadata:        event.Metadata,
+		RequestReceived: event.RequestReceived,
+		OccurredAt:      event.OccurredAt,
+	})
+
+	return nil
+}
+
+func (p WebhookJobProcessor) processRegistrationEvent(job *gobble.Job, logger lager.Logger) error {
+	var event common.RegistrationEvent
+	err := job.Unmarshal(&event)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.worker.panic.json", nil).Inc(1)
+		return nil
+	}
+
+	logger = logger.Session("registration-webhook", lager.Data{
+		"client_id":    event.ClientID,
+		"callback_url": event.CallbackURL,
+		"changes":      len(event.Changes),
+	})
+
+	p.post(job, logger, event.CallbackURL, registrationEventPayload{
+		Event:      "registration_changed",
+		ClientID:   event.ClientID,
+		Actor:      event.Actor,
+		Changes:    event.Changes,
+		OccurredAt: event.OccurredAt,
+	})
+
+	return nil
+}
+
+func (p WebhookJobProcessor) post(job *gobble.Job, logger lager.Logger, callbackURL string, payload interface{}) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		panic(err)
+	}
+
+	request, err := http.NewRequest("POST", callbackURL, bytes.NewReader(body))
+	if err != nil {
+		logger.Error("invalid-callback-url", err)
+		return
+	}
+
+	timestamp := strconv.FormatInt(p.clock.Now().Unix(), 10)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set(WebhookTimestampHeader, timestamp)
+	if len(p.signingKey) > 0 {
+		request.Header.Set(WebhookSignatureHeader, "sha256="+SignWebhook(p.signingKey, timestamp, body))
+	}
+
+	response, err := p.client.Do(request)
+	if err != nil {
+		p.fail(job, logger, lager.Data{"error": err.Error()})
+		return
+	}
+	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		p.fail(job, logger, lager.Data{"status_code": response.StatusCode})
+		return
+	}
+
+	metrics.GetOrRegisterCounter("notifications.webhook.delivered", nil).Inc(1)
+	logger.Info("delivered")
+}
+
+func (p WebhookJobProcessor) fail(job *gobble.Job, logger lager.Logger, data lager.Data) {
+	metrics.GetOrRegisterCounter("notifications.webhook.failed", nil).Inc(1)
+	logger.Info("failed", data)
+
+	p.deliveryFailureHandler.HandleWithPolicy(job, common.RetryPolicy{}, logger)
+}
+
+// SignWebhook returns the hex encoded HMAC-SHA256 of the timestamp and body,
+// joined by a period, so that receivers can reject replayed events.
+func SignWebhook(key []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,241 @@
+package v1_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/postal/v1"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WebhookJobProcessor", func() {
+	var (
+		processor              v1.WebhookJobProcessor
+		deliveryFailureHandler *mocks.DeliveryFailureHandler
+		clock                  *mocks.Clock
+		logger                 lager.Logger
+		server                 *httptest.Server
+		received               *http.Request
+		receivedBody           []byte
+		responseCode           int
+		job                    *gobble.Job
+		now                    time.Time
+	)
+
+	BeforeEach(func() {
+		responseCode = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received = req
+			receivedBody, _ = ioutil.ReadAll(req.Body)
+			w.WriteHeader(responseCode)
+		}))
+
+		now = time.Date(2015, time.June, 8, 14, 32, 11, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+		deliveryFailureHandler = mocks.NewDeliveryFailureHandler()
+		logger = lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(bytes.NewBuffer([]byte{}), lager.DEBUG))
+
+		job = gobble.NewJob(common.DeliveryEvent{
+			JobType:         common.DeliveryEventJobType,
+			CallbackURL:     server.URL + "/deliveries",
+			MessageID:       "message-123",
+			ClientID:        "some-client",
+			KindID:          "some-kind",
+			Recipient:       "user@example.com",
+			Status:          common.StatusDelivered,
+			RequestReceived: now.Add(-time.Minute),
+			OccurredAt:      now.Add(-time.Second),
+		})
+
+		processor = v1.NewWebhookJobProcessor(http.DefaultClient, []byte("some-key"), clock, deliveryFailureHandler)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts a signed event to the callback URL", func() {
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(received.Method).To(Equal("POST"))
+		Expect(received.URL.Path).To(Equal("/deliveries"))
+		Expect(received.Header.Get("Content-Type")).To(Equal("application/json"))
+		Expect(receivedBody).To(MatchJSON(`{
+			"message_id": "message-123",
+			"client_id": "some-client",
+			"kind_id": "some-kind",
+			"recipient": "user@example.com",
+			"status": "delivered",
+			"request_received": "2015-06-08T14:31:11Z",
+			"occurred_at": "2015-06-08T14:32:10Z"
+		}`))
+
+		Expect(received.Header.Get(v1.WebhookTimestampHeader)).To(Equal("1433773931"))
+		Expect(received.Header.Get(v1.WebhookSignatureHeader)).To(Equal("sha256=" + v1.SignWebhook([]byte("some-key"), "1433773931", receivedBody)))
+		Expect(deliveryFailureHandler.HandleWithPolicyCall.WasCalled).To(BeFalse())
+	})
+
+	It("marks events of simulated deliveries", func() {
+		job = gobble.NewJob(common.DeliveryEvent{
+			JobType:         common.DeliveryEventJobType,
+			CallbackURL:     server.URL + "/deliveries",
+			MessageID:       "message-123",
+			ClientID:        "some-client",
+			Recipient:       "user@example.com",
+			Status:          common.StatusDelivered,
+			Simulated:       true,
+			RequestReceived: now.Add(-time.Minute),
+			OccurredAt:      now.Add(-time.Second),
+		})
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(receivedBody).To(MatchJSON(`{
+			"message_id": "message-123",
+			"client_id": "some-client",
+			"recipient": "user@example.com",
+			"status": "delivered",
+			"simulated": true,
+			"request_received": "2015-06-08T14:31:11Z",
+			"occurred_at": "2015-06-08T14:32:10Z"
+		}`))
+	})
+
+	It("echoes the metadata of the notification", func() {
+		job = gobble.NewJob(common.DeliveryEvent{
+			JobType:         common.DeliveryEventJobType,
+			CallbackURL:     server.URL + "/deliveries",
+			MessageID:       "message-123",
+			ClientID:        "some-client",
+			Recipient:       "user@example.com",
+			Status:          common.StatusDelivered,
+			Metadata:        map[string]string{"ticket": "INC-1234"},
+			RequestReceived: now.Add(-time.Minute),
+			OccurredAt:      now.Add(-time.Second),
+		})
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(receivedBody).To(MatchJSON(`{
+			"message_id": "message-123",
+			"client_id": "some-client",
+			"recipient": "user@example.com",
+			"status": "delivered",
+			"metadata": {"ticket": "INC-1234"},
+			"request_received": "2015-06-08T14:31:11Z",
+			"occurred_at": "2015-06-08T14:32:10Z"
+		}`))
+	})
+
+	It("includes the severity of the notification", func() {
+		job = gobble.NewJob(common.DeliveryEvent{
+			JobType:         common.DeliveryEventJobType,
+			CallbackURL:     server.URL + "/deliveries",
+			MessageID:       "message-123",
+			ClientID:        "some-client",
+			Recipient:       "user@example.com",
+			Status:          common.StatusDelivered,
+			Severity:        "warning",
+			RequestReceived: now.Add(-time.Minute),
+			OccurredAt:      now.Add(-time.Second),
+		})
+
+		err := processor.Process(job, logger)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(receivedBody).To(MatchJSON(`{
+			"message_id": "message-123",
+			"client_id": "some-client",
+			"recipient": "user@example.com",
+			"status": "delivered",
+			"severity": "warning",
+			"request_received": "2015-06-08T14:31:11Z",
+			"occurred_at": "2015-06-08T14:32:10Z"
+		}`))
+	})
+
+	It("signs the timestamp and body with HMAC-SHA256", func() {
+		Expect(v1.SignWebhook([]byte("key"), "1", []byte("body"))).To(Equal("91b5374b153842ad05b2c4eab9349b8321b14703165bd3fb8b034dfb8be98ae5"))
+	})
+
+	Context("when the job is a registration event", func() {
+		It("posts the changes and the actor to the callback URL", func() {
+			job = gobble.NewJob(common.RegistrationEvent{
+				JobType:     common.RegistrationEventJobType,
+				CallbackURL: server.URL + "/registrations",
+				ClientID:    "some-client",
+				Actor:       common.RegistrationActor{ClientID: "other-client", UserID: "some-user"},
+				Changes: []common.RegistrationChange{
+					{Path: "notifications.some-kind.critical", From: false, To: true},
+					{Path: "notifications.old-kind", From: map[string]interface{}{"description": "Old"}, To: nil},
+				},
+				OccurredAt: now.Add(-time.Second),
+			})
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(received.URL.Path).To(Equal("/registrations"))
+			Expect(receivedBody).To(MatchJSON(`{
+				"event": "registration_changed",
+				"client_id": "some-client",
+				"actor": {"client_id": "other-client", "user_id": "some-user"},
+				"changes": [
+					{"path": "notifications.some-kind.critical", "from": false, "to": true},
+					{"path": "notifications.old-kind", "from": {"description": "Old"}, "to": null}
+				],
+				"occurred_at": "2015-06-08T14:32:10Z"
+			}`))
+			Expect(received.Header.Get(v1.WebhookSignatureHeader)).To(Equal("sha256=" + v1.SignWebhook([]byte("some-key"), "1433773931", receivedBody)))
+		})
+	})
+
+	Context("when no signing key is configured", func() {
+		It("does not sign the event", func() {
+			processor = v1.NewWebhookJobProcessor(http.DefaultClient, nil, clock, deliveryFailureHandler)
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(received.Header.Get(v1.WebhookTimestampHeader)).To(Equal("1433773931"))
+			Expect(received.Header).NotTo(HaveKey(v1.WebhookSignatureHeader))
+		})
+	})
+
+	Context("when the callback responds with an error", func() {
+		It("retries the job", func() {
+			responseCode = http.StatusInternalServerError
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Policy).To(Equal(common.RetryPolicy{}))
+		})
+	})
+
+	Context("when the callback cannot be reached", func() {
+		It("retries the job", func() {
+			server.Close()
+
+			err := processor.Process(job, logger)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(deliveryFailureHandler.HandleWithPolicyCall.Receives.Job).To(Equal(job))
+		})
+	})
+})
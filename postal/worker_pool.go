@@ -0,0 +1,216 @@
+package postal
+
+import (
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+)
+
+// WorkerPoolInterval is how often an autoscaling pool reads the queue to
+// decide on its size.
+const WorkerPoolInterval = 15 * time.Second
+
+type queueStatsReader interface {
+	Stats() (gobble.QueueStats, error)
+}
+
+// WorkerPoolConfig bounds the delivery workers of an instance. A Max of 0,
+// or one no larger than Min, keeps the pool at Min workers. Otherwise the
+// pool aims for a worker for every JobsPerWorker pending jobs, and adds one
+// more whenever the oldest pending job has waited longer than MaxLatency.
+//
+// A SendRate curve further limits the pool to its share of Max in each hour,
+// even below Min, but always to at least one worker.
+type WorkerPoolConfig struct {
+	Min           int
+	Max           int
+	JobsPerWorker int
+	MaxLatency    time.Duration
+	SendRate      *SendRateCurve
+	Clock         clock
+}
+
+// WorkerPoolStatus reports SendRatePercent only when the pool follows a send
+// rate curve.
+type WorkerPoolStatus struct {
+	Size            int
+	Min             int
+	Max             int
+	Autoscaling     bool
+	SendRatePercent int
+}
+
+// WorkerPool supervises the delivery workers of an instance, growing the
+// pool as soon as the queue needs more workers and shrinking it one worker
+// at a time, each after finishing the job it is working on, once the queue
+// needs fewer. It is shared with the server, which reports its size.
+type WorkerPool struct {
+	config WorkerPoolConfig
+	queue  queueStatsReader
+	start  func(index int, stop <-chan struct{})
+	logger lager.Logger
+
+	mutex sync.Mutex
+	stops []chan struct{}
+}
+
+func NewWorkerPool(config WorkerPoolConfig) *WorkerPool {
+	if config.Max < config.Min {
+		config.Max = config.Min
+	}
+
+	return &WorkerPool{
+		config: config,
+	}
+}
+
+// Start starts the minimum number of workers, reading the queue to decide
+// on any more each time the pool is adjusted.
+func (p *WorkerPool) Start(queue queueStatsReader, start func(index int, stop <-chan struct{}), logger lager.Logger) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.queue = queue
+	p.start = start
+	p.logger = logger
+
+	want, _ := p.limit()
+	if want > p.config.Min {
+		want = p.config.Min
+	}
+
+	p.grow(want)
+	p.updateGauge()
+}
+
+func (p *WorkerPool) Autoscaling() bool {
+	return p.config.Max > p.config.Min
+}
+
+// Shaped reports whether the pool follows a send rate curve, which it has to
+// be adjusted for as the hours pass even when it does not autoscale.
+func (p *WorkerPool) Shaped() bool {
+	return p.config.SendRate != nil
+}
+
+// Capacity is the most workers the pool will run, which worker IDs are
+// numbered within.
+func (p *WorkerPool) Capacity() int {
+	return p.config.Max
+}
+
+func (p *WorkerPool) Run(ticks <-chan time.Time) {
+	for range ticks {
+		p.Adjust()
+	}
+}
+
+func (p *WorkerPool) Adjust() {
+	stats, err := p.queue.Stats()
+	if err != nil {
+		p.logger.Error("worker-pool-check-failed", err)
+		return
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	size := len(p.stops)
+	want := size
+	if p.config.JobsPerWorker > 0 {
+		want = (stats.Pending + p.config.JobsPerWorker - 1) / p.config.JobsPerWorker
+	}
+	if p.config.MaxLatency > 0 && stats.OldestAge > p.config.MaxLatency && want <= size {
+		want = size + 1
+	}
+
+	if want < p.config.Min {
+		want = p.config.Min
+	}
+	limit, percent := p.limit()
+	if want > limit {
+		want = limit
+	}
+
+	data := lager.Data{
+		"from":               size,
+		"pending":            stats.Pending,
+		"oldest_age_seconds": int64(stats.OldestAge / time.Second),
+	}
+	if p.Shaped() {
+		data["send_rate_percent"] = percent
+	}
+
+	switch {
+	case want > size:
+		p.grow(want)
+
+		data["to"] = len(p.stops)
+		p.logger.Info("worker-pool-grown", data)
+	case want < size:
+		// Workers over the send rate stop at once, so that the quieter
+		// hours are not spent winding the pool down.
+		keep := size - 1
+		if size > limit {
+			keep = limit
+		}
+
+		for _, stop := range p.stops[keep:] {
+			close(stop)
+		}
+		p.stops = p.stops[:keep]
+
+		data["to"] = len(p.stops)
+		p.logger.Info("worker-pool-shrunk", data)
+	}
+
+	p.updateGauge()
+}
+
+func (p *WorkerPool) Status() WorkerPoolStatus {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	status := WorkerPoolStatus{
+		Size:        len(p.stops),
+		Min:         p.config.Min,
+		Max:         p.config.Max,
+		Autoscaling: p.Autoscaling(),
+	}
+	if p.Shaped() {
+		_, status.SendRatePercent = p.limit()
+	}
+
+	return status
+}
+
+// limit is the most workers the pool may run at the moment, with the
+// percentage of the send rate curve it was worked out from.
+func (p *WorkerPool) limit() (int, int) {
+	if !p.Shaped() {
+		return p.config.Max, 100
+	}
+
+	percent := p.config.SendRate.At(p.config.Clock.Now())
+	limit := (p.config.Max*percent + 99) / 100
+	if limit < 1 {
+		limit = 1
+	}
+
+	return limit, percent
+}
+
+func (p *WorkerPool) grow(size int) {
+	for len(p.stops) < size {
+		stop := make(chan struct{})
+		p.start(len(p.stops), stop)
+		p.stops = append(p.stops, stop)
+	}
+}
+
+func (p *WorkerPool) updateGauge() {
+	metrics.GetOrRegisterGauge("notifications.worker.pool_size", nil).Update(int64(len(p.stops)))
+}
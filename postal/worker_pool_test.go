@@ -0,0 +1,213 @@
+package postal_test
+
+import (
+	"bytes"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WorkerPool", func() {
+	var (
+		pool    *postal.WorkerPool
+		queue   *mocks.Queue
+		buffer  *bytes.Buffer
+		started []int
+		stops   []<-chan struct{}
+	)
+
+	BeforeEach(func() {
+		metrics.DefaultRegistry.UnregisterAll()
+
+		started = nil
+		stops = nil
+		queue = mocks.NewQueue()
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		pool = postal.NewWorkerPool(postal.WorkerPoolConfig{
+			Min:           2,
+			Max:           5,
+			JobsPerWorker: 10,
+			MaxLatency:    time.Minute,
+		})
+		pool.Start(queue, func(index int, stop <-chan struct{}) {
+			started = append(started, index)
+			stops = append(stops, stop)
+		}, logger)
+	})
+
+	It("starts the minimum number of workers", func() {
+		Expect(started).To(Equal([]int{0, 1}))
+		Expect(pool.Status()).To(Equal(postal.WorkerPoolStatus{Size: 2, Min: 2, Max: 5, Autoscaling: true}))
+		Expect(metrics.GetOrRegisterGauge("notifications.worker.pool_size", nil).Value()).To(Equal(int64(2)))
+	})
+
+	It("grows to a worker for every few pending jobs, up to the maximum", func() {
+		queue.StatsCall.Returns.Stats = gobble.QueueStats{Pending: 35}
+		pool.Adjust()
+
+		Expect(started).To(Equal([]int{0, 1, 2, 3}))
+		Expect(pool.Status().Size).To(Equal(4))
+		Expect(buffer.String()).To(ContainSubstring(`"message":"notifications.worker-pool-grown"`))
+		Expect(buffer.String()).To(ContainSubstring(`"from":2`))
+		Expect(buffer.String()).To(ContainSubstring(`"to":4`))
+		Expect(buffer.String()).To(ContainSubstring(`"pending":35`))
+
+		queue.StatsCall.Returns.Stats = gobble.QueueStats{Pending: 500}
+		pool.Adjust()
+
+		Expect(pool.Status().Size).To(Equal(5))
+		Expect(metrics.GetOrRegisterGauge("notifications.worker.pool_size", nil).Value()).To(Equal(int64(5)))
+	})
+
+	It("adds a worker while jobs wait longer than the latency allows", func() {
+		queue.StatsCall.Returns.Stats = gobble.QueueStats{Pending: 3, OldestAge: 2 * time.Minute}
+		pool.Adjust()
+		pool.Adjust()
+
+		Expect(pool.Status().Size).To(Equal(4))
+		Expect(buffer.String()).To(ContainSubstring(`"oldest_age_seconds":120`))
+	})
+
+	It("shrinks one worker at a time, down to the minimum", func() {
+		queue.StatsCall.Returns.Stats = gobble.QueueStats{Pending: 50}
+		pool.Adjust()
+		Expect(pool.Status().Size).To(Equal(5))
+
+		queue.StatsCall.Returns.Stats = gobble.QueueStats{}
+		pool.Adjust()
+
+		Expect(pool.Status().Size).To(Equal(4))
+		Expect(stops[4]).To(BeClosed())
+		Expect(stops[3]).NotTo(BeClosed())
+		Expect(buffer.String()).To(ContainSubstring(`"message":"notifications.worker-pool-shrunk"`))
+
+		pool.Adjust()
+		pool.Adjust()
+		pool.Adjust()
+
+		Expect(pool.Status().Size).To(Equal(2))
+		Expect(stops[1]).NotTo(BeClosed())
+	})
+
+	It("leaves the workers alone when the queue cannot be read", func() {
+		queue.StatsCall.Returns.Error = errors.New("database is down")
+		pool.Adjust()
+
+		Expect(pool.Status().Size).To(Equal(2))
+		Expect(buffer.String()).To(ContainSubstring(`"message":"notifications.worker-pool-check-failed"`))
+	})
+
+	It("keeps a pool without a larger maximum at its minimum", func() {
+		fixed := postal.NewWorkerPool(postal.WorkerPoolConfig{Min: 3})
+		fixed.Start(queue, func(int, <-chan struct{}) {}, lager.NewLogger("notifications"))
+
+		Expect(fixed.Status()).To(Equal(postal.WorkerPoolStatus{Size: 3, Min: 3, Max: 3}))
+		Expect(fixed.Autoscaling()).To(BeFalse())
+		Expect(fixed.Capacity()).To(Equal(3))
+	})
+
+	Context("when the pool follows a send rate curve", func() {
+		var (
+			clock  *mocks.Clock
+			shaped *postal.WorkerPool
+		)
+
+		BeforeEach(func() {
+			curve, err := postal.NewSendRateCurve(map[string]int{"22-6": 20})
+			Expect(err).NotTo(HaveOccurred())
+
+			clock = mocks.NewClock()
+			clock.NowCall.Returns.Time = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+			started = nil
+			stops = nil
+			shaped = postal.NewWorkerPool(postal.WorkerPoolConfig{
+				Min:      10,
+				SendRate: curve,
+				Clock:    clock,
+			})
+			shaped.Start(queue, func(index int, stop <-chan struct{}) {
+				started = append(started, index)
+				stops = append(stops, stop)
+			}, lager.NewLogger("notifications"))
+		})
+
+		It("runs at full size outside of the hours it slows down", func() {
+			Expect(shaped.Shaped()).To(BeTrue())
+			Expect(shaped.Status()).To(Equal(postal.WorkerPoolStatus{Size: 10, Min: 10, Max: 10, SendRatePercent: 100}))
+		})
+
+		It("stops the workers over the rate as soon as a slower hour starts", func() {
+			clock.NowCall.Returns.Time = time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC)
+			shaped.Adjust()
+
+			Expect(shaped.Status()).To(Equal(postal.WorkerPoolStatus{Size: 2, Min: 10, Max: 10, SendRatePercent: 20}))
+			Expect(stops[1]).NotTo(BeClosed())
+			Expect(stops[2]).To(BeClosed())
+			Expect(stops[9]).To(BeClosed())
+
+			clock.NowCall.Returns.Time = time.Date(2026, 10, 19, 6, 0, 0, 0, time.UTC)
+			shaped.Adjust()
+
+			Expect(shaped.Status().Size).To(Equal(10))
+		})
+
+		It("keeps at least one worker running", func() {
+			curve, err := postal.NewSendRateCurve(map[string]int{"0-24": 1})
+			Expect(err).NotTo(HaveOccurred())
+
+			small := postal.NewWorkerPool(postal.WorkerPoolConfig{Min: 3, SendRate: curve, Clock: clock})
+			small.Start(queue, func(int, <-chan struct{}) {}, lager.NewLogger("notifications"))
+
+			Expect(small.Status().Size).To(Equal(1))
+		})
+	})
+})
+
+var _ = Describe("SendRateCurve", func() {
+	It("runs the hours left out of the schedule at full speed", func() {
+		curve, err := postal.NewSendRateCurve(map[string]int{"8-18": 50})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(curve.At(time.Date(2026, 10, 18, 7, 59, 0, 0, time.UTC))).To(Equal(100))
+		Expect(curve.At(time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC))).To(Equal(50))
+		Expect(curve.At(time.Date(2026, 10, 18, 17, 59, 0, 0, time.UTC))).To(Equal(50))
+		Expect(curve.At(time.Date(2026, 10, 18, 18, 0, 0, 0, time.UTC))).To(Equal(100))
+	})
+
+	It("reads the hours in UTC, wrapping around midnight", func() {
+		curve, err := postal.NewSendRateCurve(map[string]int{"22-2": 20})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(curve.At(time.Date(2026, 10, 18, 23, 0, 0, 0, time.UTC))).To(Equal(20))
+		Expect(curve.At(time.Date(2026, 10, 19, 1, 0, 0, 0, time.UTC))).To(Equal(20))
+		Expect(curve.At(time.Date(2026, 10, 19, 2, 0, 0, 0, time.UTC))).To(Equal(100))
+		Expect(curve.At(time.Date(2026, 10, 18, 18, 0, 0, 0, time.FixedZone("EST", -5*60*60)))).To(Equal(20))
+	})
+
+	It("rejects schedules it cannot follow", func() {
+		_, err := postal.NewSendRateCurve(map[string]int{"night": 20})
+		Expect(err).To(MatchError(`"night" is not a range of hours such as "8-18"`))
+
+		_, err = postal.NewSendRateCurve(map[string]int{"8-8": 20})
+		Expect(err).To(MatchError(`"8-8" is not a range of hours such as "8-18"`))
+
+		_, err = postal.NewSendRateCurve(map[string]int{"8-18": 0})
+		Expect(err).To(MatchError(`the rate of "8-18" must be a percentage from 1 to 100`))
+
+		_, err = postal.NewSendRateCurve(map[string]int{"8-18": 50, "17-20": 20})
+		Expect(err).To(MatchError(ContainSubstring("both include hour 17")))
+	})
+})
@@ -0,0 +1,301 @@
+package redis
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const maxIdleConnections = 16
+
+type ReplyError struct {
+	Message string
+}
+
+func (e ReplyError) Error() string {
+	return "redis: " + e.Message
+}
+
+// Options are read from a redis:// or rediss:// URL, such as
+// "redis://:secret@localhost:6379/2". The path selects the database.
+type Options struct {
+	Address  string
+	Username string
+	Password string
+	Database int
+	TLS      bool
+}
+
+func ParseURL(rawURL string) (Options, error) {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return Options{}, err
+	}
+
+	var options Options
+	switch parsed.Scheme {
+	case "redis":
+	case "rediss":
+		options.TLS = true
+	default:
+		return Options{}, fmt.Errorf("the scheme must be redis or rediss, not %q", parsed.Scheme)
+	}
+
+	if parsed.Hostname() == "" {
+		return Options{}, errors.New("the URL has no host")
+	}
+
+	options.Address = parsed.Host
+	if parsed.Port() == "" {
+		options.Address = net.JoinHostPort(parsed.Hostname(), "6379")
+	}
+
+	if parsed.User != nil {
+		options.Username = parsed.User.Username()
+		options.Password, _ = parsed.User.Password()
+	}
+
+	if database := strings.Trim(parsed.Path, "/"); database != "" {
+		options.Database, err = strconv.Atoi(database)
+		if err != nil || options.Database < 0 {
+			return Options{}, fmt.Errorf("the database %q is not a number", database)
+		}
+	}
+
+	return options, nil
+}
+
+type connection struct {
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+// Client speaks just enough of the Redis protocol for the preferences cache
+// and the Redis job queue. Connections are reused between commands and
+// dropped after any network or protocol error.
+type Client struct {
+	options Options
+	timeout time.Duration
+	idle    chan *connection
+}
+
+// NewClient takes a URL that ParseURL accepts. The timeout bounds both
+// connecting and each command.
+func NewClient(rawURL string, timeout time.Duration) (*Client, error) {
+	options, err := ParseURL(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %s", err)
+	}
+
+	return &Client{
+		options: options,
+		timeout: timeout,
+		idle:    make(chan *connection, maxIdleConnections),
+	}, nil
+}
+
+// Get returns the value of the key, and false when the key does not exist.
+func (c *Client) Get(key string) (string, bool, error) {
+	reply, err := c.Do(0, "GET", key)
+	if err != nil {
+		return "", false, err
+	}
+
+	value, ok := reply.(string)
+	return value, ok, nil
+}
+
+// Set stores the value under the key, expiring it after the TTL when the TTL
+// is not zero.
+func (c *Client) Set(key, value string, ttl time.Duration) error {
+	args := []interface{}{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", int64(ttl/time.Millisecond))
+	}
+
+	_, err := c.Do(0, args...)
+	return err
+}
+
+func (c *Client) Del(key string) error {
+	_, err := c.Do(0, "DEL", key)
+	return err
+}
+
+// Do runs a command and returns its reply: a string, an int64, nil, or a
+// []interface{} of those, in which an element the server failed is a
+// ReplyError. Replies that may take a while to arrive, such as those of
+// BRPOP, are waited for up to wait longer than the timeout.
+func (c *Client) Do(wait time.Duration, args ...interface{}) (interface{}, error) {
+	conn, err := c.connection()
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := conn.command(c.timeout+wait, args...)
+	if err != nil {
+		if _, ok := err.(ReplyError); !ok {
+			conn.conn.Close()
+			return nil, err
+		}
+	}
+
+	c.release(conn)
+	return reply, err
+}
+
+func (c *Client) connection() (*connection, error) {
+	select {
+	case conn := <-c.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := &net.Dialer{Timeout: c.timeout}
+
+	var netConn net.Conn
+	var err error
+	if c.options.TLS {
+		netConn, err = tls.DialWithDialer(dialer, "tcp", c.options.Address, &tls.Config{MinVersion: tls.VersionTLS12})
+	} else {
+		netConn, err = dialer.Dial("tcp", c.options.Address)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn := &connection{
+		conn:   netConn,
+		reader: bufio.NewReader(netConn),
+	}
+
+	if c.options.Password != "" {
+		args := []interface{}{"AUTH", c.options.Password}
+		if c.options.Username != "" {
+			args = []interface{}{"AUTH", c.options.Username, c.options.Password}
+		}
+
+		_, err = conn.command(c.timeout, args...)
+		if err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+
+	if c.options.Database != 0 {
+		_, err = conn.command(c.timeout, "SELECT", c.options.Database)
+		if err != nil {
+			netConn.Close()
+			return nil, err
+		}
+	}
+
+	return conn, nil
+}
+
+func (c *Client) release(conn *connection) {
+	select {
+	case c.idle <- conn:
+	default:
+		conn.conn.Close()
+	}
+}
+
+func (conn *connection) command(timeout time.Duration, args ...interface{}) (interface{}, error) {
+	if timeout > 0 {
+		conn.conn.SetDeadline(time.Now().Add(timeout))
+	}
+
+	request := []byte("*" + strconv.Itoa(len(args)) + "\r\n")
+	for _, arg := range args {
+		var value string
+		switch arg := arg.(type) {
+		case string:
+			value = arg
+		case []byte:
+			value = string(arg)
+		case int:
+			value = strconv.Itoa(arg)
+		case int64:
+			value = strconv.FormatInt(arg, 10)
+		default:
+			value = fmt.Sprint(arg)
+		}
+
+		request = append(request, "$"+strconv.Itoa(len(value))+"\r\n"+value+"\r\n"...)
+	}
+
+	_, err := conn.conn.Write(request)
+	if err != nil {
+		return nil, err
+	}
+
+	return readReply(conn.reader)
+}
+
+func readReply(reader *bufio.Reader) (interface{}, error) {
+	line, err := reader.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+
+	if len(line) < 3 || !strings.HasSuffix(line, "\r\n") {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return nil, ReplyError{Message: body}
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		length, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed reply %q", line)
+		}
+		if length < 0 {
+			return nil, nil
+		}
+
+		data := make([]byte, length+2)
+		_, err = io.ReadFull(reader, data)
+		if err != nil {
+			return nil, err
+		}
+
+		return string(data[:length]), nil
+	case '*':
+		length, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed reply %q", line)
+		}
+		if length < 0 {
+			return nil, nil
+		}
+
+		elements := make([]interface{}, length)
+		for i := range elements {
+			elements[i], err = readReply(reader)
+			if err != nil {
+				if _, ok := err.(ReplyError); !ok {
+					return nil, err
+				}
+				elements[i] = err
+			}
+		}
+
+		return elements, nil
+	default:
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+}
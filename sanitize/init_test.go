@@ -0,0 +1,13 @@
+package sanitize_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestSanitizeSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "sanitize")
+}
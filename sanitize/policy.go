@@ -0,0 +1,84 @@
+package sanitize
+
+const (
+	ModeOff    = "off"
+	ModeClean  = "clean"
+	ModeStrict = "strict"
+)
+
+var Modes = []string{ModeOff, ModeClean, ModeStrict}
+
+// Policy maps each allowed element to the attributes it may carry. The "*"
+// entry lists attributes allowed on every element.
+type Policy map[string][]string
+
+// DefaultPolicy allows the markup commonly used to lay out emails, but no
+// scripts, frames, forms or embedded objects.
+var DefaultPolicy = Policy{
+	"*":          {"align", "class", "dir", "id", "lang", "style", "title", "valign"},
+	"a":          {"href", "name", "target"},
+	"abbr":       {},
+	"b":          {},
+	"blockquote": {"cite"},
+	"body":       {"bgcolor"},
+	"br":         {},
+	"caption":    {},
+	"center":     {},
+	"code":       {},
+	"col":        {"span", "width"},
+	"colgroup":   {"span", "width"},
+	"dd":         {},
+	"div":        {},
+	"dl":         {},
+	"dt":         {},
+	"em":         {},
+	"font":       {"color", "face", "size"},
+	"h1":         {},
+	"h2":         {},
+	"h3":         {},
+	"h4":         {},
+	"h5":         {},
+	"h6":         {},
+	"hr":         {"width"},
+	"i":          {},
+	"img":        {"alt", "border", "height", "src", "width"},
+	"li":         {},
+	"meta":       {"charset", "content", "name"},
+	"ol":         {"start", "type"},
+	"p":          {},
+	"pre":        {},
+	"s":          {},
+	"small":      {},
+	"span":       {},
+	"strong":     {},
+	"style":      {"type"},
+	"sub":        {},
+	"sup":        {},
+	"table":      {"bgcolor", "border", "cellpadding", "cellspacing", "width"},
+	"tbody":      {},
+	"td":         {"bgcolor", "colspan", "height", "rowspan", "width"},
+	"tfoot":      {},
+	"th":         {"bgcolor", "colspan", "height", "rowspan", "width"},
+	"thead":      {},
+	"title":      {},
+	"tr":         {"bgcolor"},
+	"u":          {},
+	"ul":         {},
+}
+
+func (p Policy) allowsElement(element string) bool {
+	_, ok := p[element]
+	return ok
+}
+
+func (p Policy) allowsAttribute(element, attribute string) bool {
+	for _, key := range []string{element, "*"} {
+		for _, allowed := range p[key] {
+			if allowed == attribute {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,208 @@
+package sanitize
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// These elements are removed along with everything inside them when the
+// policy does not allow them; other elements are unwrapped to their content.
+var dropContent = map[string]bool{
+	"applet":   true,
+	"embed":    true,
+	"iframe":   true,
+	"math":     true,
+	"noscript": true,
+	"object":   true,
+	"script":   true,
+	"style":    true,
+	"svg":      true,
+	"template": true,
+	"title":    true,
+}
+
+var urlAttributes = map[string]bool{
+	"action":     true,
+	"background": true,
+	"cite":       true,
+	"formaction": true,
+	"href":       true,
+	"longdesc":   true,
+	"poster":     true,
+	"src":        true,
+}
+
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+var unsafeCSS = []string{"url(", "expression(", "javascript:", "behavior:", "-moz-binding", "@import"}
+
+type Sanitizer struct {
+	policy Policy
+}
+
+func NewSanitizer(policy Policy) Sanitizer {
+	return Sanitizer{
+		policy: policy,
+	}
+}
+
+// Sanitize returns the HTML fragment without the elements and attributes
+// the policy does not allow, and describes each kind of markup it removed.
+// Comments and doctypes are dropped without being reported.
+func (s Sanitizer) Sanitize(fragment string) (string, []string) {
+	var (
+		output  bytes.Buffer
+		removed removals
+		skip    string
+		depth   int
+		rawText string
+	)
+
+	tokenizer := html.NewTokenizer(strings.NewReader(fragment))
+	for {
+		if tokenizer.Next() == html.ErrorToken {
+			if tokenizer.Err() != io.EOF {
+				removed.add("malformed markup")
+			}
+			break
+		}
+
+		token := tokenizer.Token()
+
+		if skip != "" {
+			switch {
+			case token.Type == html.StartTagToken && token.Data == skip:
+				depth++
+			case token.Type == html.EndTagToken && token.Data == skip:
+				depth--
+				if depth == 0 {
+					skip = ""
+				}
+			}
+			continue
+		}
+
+		switch token.Type {
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if !s.policy.allowsElement(token.Data) {
+				removed.add(fmt.Sprintf("<%s>", token.Data))
+				if dropContent[token.Data] && token.Type == html.StartTagToken {
+					skip = token.Data
+					depth = 1
+				}
+				continue
+			}
+
+			token.Attr = s.attributes(token.Data, token.Attr, &removed)
+			output.WriteString(token.String())
+
+			if token.Type == html.StartTagToken && token.Data == "style" {
+				rawText = token.Data
+			}
+		case html.EndTagToken:
+			if s.policy.allowsElement(token.Data) {
+				output.WriteString(token.String())
+			}
+			rawText = ""
+		case html.TextToken:
+			if rawText == "" {
+				output.WriteString(token.String())
+				continue
+			}
+
+			if unsafeStyle(token.Data) {
+				removed.add(fmt.Sprintf("<%s> content that loads resources or runs code", rawText))
+				continue
+			}
+
+			// The tokenizer ends raw text at the closing tag, so the
+			// stylesheet can be written back without escaping it.
+			output.WriteString(token.Data)
+		}
+	}
+
+	return output.String(), removed
+}
+
+// SanitizeAttributes cleans the attributes of a single element, given in
+// the form they appear inside its start tag.
+func (s Sanitizer) SanitizeAttributes(element, attributes string) (string, []string) {
+	var removed removals
+
+	tokenizer := html.NewTokenizer(strings.NewReader("<" + element + " " + attributes + ">"))
+	tokenizer.Next()
+	token := tokenizer.Token()
+
+	var rendered []string
+	for _, attribute := range s.attributes(element, token.Attr, &removed) {
+		rendered = append(rendered, attribute.Key+`="`+html.EscapeString(attribute.Val)+`"`)
+	}
+
+	return strings.Join(rendered, " "), removed
+}
+
+func (s Sanitizer) attributes(element string, attributes []html.Attribute, removed *removals) []html.Attribute {
+	var kept []html.Attribute
+
+	for _, attribute := range attributes {
+		switch {
+		case strings.HasPrefix(attribute.Key, "on"), !s.policy.allowsAttribute(element, attribute.Key):
+			removed.add(fmt.Sprintf("%q on <%s>", attribute.Key, element))
+		case urlAttributes[attribute.Key] && !safeURL(attribute.Val):
+			removed.add(fmt.Sprintf("%q on <%s> with a URL that is not http, https or mailto", attribute.Key, element))
+		case attribute.Key == "style" && unsafeStyle(attribute.Val):
+			removed.add(fmt.Sprintf("%q on <%s> that loads resources or runs code", attribute.Key, element))
+		default:
+			kept = append(kept, attribute)
+		}
+	}
+
+	return kept
+}
+
+func safeURL(value string) bool {
+	value = strings.ToLower(strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, value))
+
+	colon := strings.Index(value, ":")
+	if colon == -1 || strings.ContainsAny(value[:colon], "/?#") {
+		return true
+	}
+
+	return allowedSchemes[value[:colon]]
+}
+
+func unsafeStyle(value string) bool {
+	value = strings.ToLower(strings.Join(strings.Fields(value), ""))
+	for _, pattern := range unsafeCSS {
+		if strings.Contains(value, pattern) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type removals []string
+
+func (r *removals) add(description string) {
+	for _, existing := range *r {
+		if existing == description {
+			return
+		}
+	}
+
+	*r = append(*r, description)
+}
@@ -0,0 +1,103 @@
+package sanitize_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/sanitize"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Sanitizer", func() {
+	var sanitizer sanitize.Sanitizer
+
+	BeforeEach(func() {
+		sanitizer = sanitize.NewSanitizer(sanitize.DefaultPolicy)
+	})
+
+	Describe("Sanitize", func() {
+		It("keeps markup the policy allows", func() {
+			fragment := `<table width="100%"><tr><td style="color: red">Hi &amp; <a href="https://example.com/?a=1&amp;b=2">there</a></td></tr></table><br/>`
+
+			output, removed := sanitizer.Sanitize(fragment)
+			Expect(output).To(Equal(fragment))
+			Expect(removed).To(BeEmpty())
+		})
+
+		It("removes scripts and their content", func() {
+			output, removed := sanitizer.Sanitize(`<p>before</p><script>alert("<p>hi</p>")</script><p>after</p>`)
+
+			Expect(output).To(Equal(`<p>before</p><p>after</p>`))
+			Expect(removed).To(Equal([]string{"<script>"}))
+		})
+
+		It("unwraps other elements the policy does not allow", func() {
+			output, removed := sanitizer.Sanitize(`<form action="https://example.com"><p>Hello <blink>world</blink></p></form>`)
+
+			Expect(output).To(Equal(`<p>Hello world</p>`))
+			Expect(removed).To(Equal([]string{"<form>", "<blink>"}))
+		})
+
+		It("removes event handlers and attributes the policy does not allow", func() {
+			output, removed := sanitizer.Sanitize(`<p onclick="steal()" data-id="1" class="intro">Hello</p><p onclick="steal()">again</p>`)
+
+			Expect(output).To(Equal(`<p class="intro">Hello</p><p>again</p>`))
+			Expect(removed).To(Equal([]string{`"onclick" on <p>`, `"data-id" on <p>`}))
+		})
+
+		It("removes URLs that could run code", func() {
+			output, removed := sanitizer.Sanitize(`<a href=" JaVa&#09;script:steal()">click</a><a href="mailto:ops@example.com">mail</a><a href="#top">top</a>`)
+
+			Expect(output).To(Equal(`<a>click</a><a href="mailto:ops@example.com">mail</a><a href="#top">top</a>`))
+			Expect(removed).To(Equal([]string{`"href" on <a> with a URL that is not http, https or mailto`}))
+		})
+
+		It("removes styles that load resources", func() {
+			output, removed := sanitizer.Sanitize(`<div style="background: URL (https://tracker.example.com/pixel.gif)">Hi</div>`)
+
+			Expect(output).To(Equal(`<div>Hi</div>`))
+			Expect(removed).To(Equal([]string{`"style" on <div> that loads resources or runs code`}))
+		})
+
+		It("keeps stylesheets unescaped unless they load resources", func() {
+			output, removed := sanitizer.Sanitize(`<style>td > p { color: red; }</style>`)
+			Expect(output).To(Equal(`<style>td > p { color: red; }</style>`))
+			Expect(removed).To(BeEmpty())
+
+			output, removed = sanitizer.Sanitize(`<style>@import "https://tracker.example.com/a.css";</style>`)
+			Expect(output).To(Equal(`<style></style>`))
+			Expect(removed).To(Equal([]string{"<style> content that loads resources or runs code"}))
+		})
+
+		It("drops comments silently", func() {
+			output, removed := sanitizer.Sanitize(`<p>Hi<!-- note --></p>`)
+
+			Expect(output).To(Equal(`<p>Hi</p>`))
+			Expect(removed).To(BeEmpty())
+		})
+
+		It("uses the given policy", func() {
+			sanitizer = sanitize.NewSanitizer(sanitize.Policy{"p": {}})
+
+			output, removed := sanitizer.Sanitize(`<p>Look: <img src="https://example.com/cat.png"></p>`)
+
+			Expect(output).To(Equal(`<p>Look: </p>`))
+			Expect(removed).To(Equal([]string{"<img>"}))
+		})
+	})
+
+	Describe("SanitizeAttributes", func() {
+		It("cleans the attributes of a single element", func() {
+			output, removed := sanitizer.SanitizeAttributes("body", `bgcolor="#fff" onload="steal()" class="a&quot;b"`)
+
+			Expect(output).To(Equal(`bgcolor="#fff" class="a&#34;b"`))
+			Expect(removed).To(Equal([]string{`"onload" on <body>`}))
+		})
+
+		It("returns nothing for an element without attributes", func() {
+			output, removed := sanitizer.SanitizeAttributes("body", "")
+
+			Expect(output).To(BeEmpty())
+			Expect(removed).To(BeEmpty())
+		})
+	})
+})
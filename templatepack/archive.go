@@ -0,0 +1,90 @@
+package templatepack
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+)
+
+// MaxArchiveSize caps the total size of the files extracted from an
+// archive, so that an upload cannot exhaust memory.
+const MaxArchiveSize = 16 << 20
+
+// LoadArchive loads a pack from a gzipped tarball of its directory. The
+// manifest may sit at the root of the archive or inside a single top-level
+// directory, as produced by tar -czf pack.tgz my-pack/.
+func LoadArchive(r io.Reader) (Pack, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return Pack{}, FormatError{errors.New("the archive must be a gzipped tarball")}
+	}
+	defer gz.Close()
+
+	files := map[string][]byte{}
+	var total int64
+
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return Pack{}, FormatError{fmt.Errorf("the archive is corrupt: %s", err)}
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := strings.TrimPrefix(path.Clean(header.Name), "./")
+		if !fs.ValidPath(name) {
+			return Pack{}, FormatError{fmt.Errorf("archive entry %q is outside of the pack", header.Name)}
+		}
+
+		total += header.Size
+		if total > MaxArchiveSize {
+			return Pack{}, FormatError{fmt.Errorf("the archive is larger than %d bytes", MaxArchiveSize)}
+		}
+
+		contents, err := io.ReadAll(io.LimitReader(archive, header.Size))
+		if err != nil {
+			return Pack{}, FormatError{fmt.Errorf("the archive is corrupt: %s", err)}
+		}
+		files[name] = contents
+	}
+
+	root := archiveRoot(files)
+
+	return Load(func(name string) ([]byte, error) {
+		contents, ok := files[path.Join(root, name)]
+		if !ok {
+			return nil, fs.ErrNotExist
+		}
+		return contents, nil
+	})
+}
+
+func archiveRoot(files map[string][]byte) string {
+	if _, ok := files[ManifestName]; ok {
+		return ""
+	}
+
+	var root string
+	for name := range files {
+		dir, file := path.Split(name)
+		if file == ManifestName && strings.Count(dir, "/") == 1 {
+			if root != "" {
+				return ""
+			}
+			root = strings.TrimSuffix(dir, "/")
+		}
+	}
+
+	return root
+}
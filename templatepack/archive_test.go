@@ -0,0 +1,72 @@
+package templatepack_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func tarball(files map[string]string) *bytes.Buffer {
+	buffer := bytes.NewBuffer([]byte{})
+	gz := gzip.NewWriter(buffer)
+	archive := tar.NewWriter(gz)
+
+	for name, contents := range files {
+		err := archive.WriteHeader(&tar.Header{
+			Name:     name,
+			Mode:     0644,
+			Size:     int64(len(contents)),
+			Typeflag: tar.TypeReg,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = archive.Write([]byte(contents))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(archive.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+
+	return buffer
+}
+
+var _ = Describe("LoadArchive", func() {
+	const manifest = `{"version": 1, "templates": [{"id": "t", "name": "T", "html_file": "t.html"}]}`
+
+	It("loads a pack from the root of the archive", func() {
+		pack, err := templatepack.LoadArchive(tarball(map[string]string{
+			"manifest.json": manifest,
+			"t.html":        "<p>{{.HTML}}</p>",
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pack.Templates[0].HTML).To(Equal("<p>{{.HTML}}</p>"))
+	})
+
+	It("loads a pack from a single top-level directory", func() {
+		pack, err := templatepack.LoadArchive(tarball(map[string]string{
+			"./acme/manifest.json": manifest,
+			"./acme/t.html":        "<p>{{.HTML}}</p>",
+		}))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pack.Templates[0].HTML).To(Equal("<p>{{.HTML}}</p>"))
+	})
+
+	It("rejects entries outside of the pack", func() {
+		_, err := templatepack.LoadArchive(tarball(map[string]string{
+			"manifest.json": manifest,
+			"../t.html":     "<p></p>",
+		}))
+		Expect(err).To(MatchError(templatepack.FormatError{Err: errors.New(`archive entry "../t.html" is outside of the pack`)}))
+	})
+
+	It("rejects bodies that are not gzipped", func() {
+		_, err := templatepack.LoadArchive(bytes.NewBufferString(manifest))
+		Expect(err).To(MatchError(templatepack.FormatError{Err: errors.New("the archive must be a gzipped tarball")}))
+	})
+})
@@ -0,0 +1,13 @@
+package templatepack_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestTemplatePackSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "templatepack")
+}
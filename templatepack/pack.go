@@ -0,0 +1,204 @@
+// Package templatepack reads template packs: a directory holding a
+// manifest.json and the template bodies it names, so that a platform can
+// ship its templates alongside its release.
+package templatepack
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+const (
+	// Version is the only manifest version this package understands.
+	Version = 1
+
+	ManifestName = "manifest.json"
+)
+
+type Pack struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	Templates []Template `json:"templates"`
+	Partials  []Partial  `json:"partials"`
+}
+
+// Template is a template of the pack. Each body is given inline or as the
+// path of a file relative to the manifest, but not both.
+type Template struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Subject   string          `json:"subject"`
+	Text      string          `json:"text"`
+	TextFile  string          `json:"text_file"`
+	HTML      string          `json:"html"`
+	HTMLFile  string          `json:"html_file"`
+	Slack     string          `json:"slack"`
+	SlackFile string          `json:"slack_file"`
+	Metadata  json.RawMessage `json:"metadata"`
+}
+
+type Partial struct {
+	Name     string `json:"name"`
+	Text     string `json:"text"`
+	TextFile string `json:"text_file"`
+	HTML     string `json:"html"`
+	HTMLFile string `json:"html_file"`
+}
+
+// FormatError reports a pack that cannot be loaded because of its contents,
+// rather than because it could not be read.
+type FormatError struct {
+	Err error
+}
+
+func (e FormatError) Error() string {
+	return "invalid template pack: " + e.Err.Error()
+}
+
+// LoadDir loads the pack in dir.
+func LoadDir(dir string) (Pack, error) {
+	return Load(func(name string) ([]byte, error) {
+		return os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	})
+}
+
+// Load reads the manifest and every file it names with readFile, which is
+// given slash separated paths relative to the root of the pack. The bodies
+// of the returned pack are all inline.
+func Load(readFile func(name string) ([]byte, error)) (Pack, error) {
+	var pack Pack
+
+	manifest, err := readFile(ManifestName)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return pack, FormatError{fmt.Errorf("%s is missing", ManifestName)}
+		}
+		return pack, err
+	}
+
+	err = json.Unmarshal(manifest, &pack)
+	if err != nil {
+		return pack, FormatError{fmt.Errorf("%s is not valid JSON: %s", ManifestName, err)}
+	}
+
+	if pack.Version != Version {
+		return pack, FormatError{fmt.Errorf("version %d is not supported, expected %d", pack.Version, Version)}
+	}
+
+	ids := map[string]bool{}
+	for i := range pack.Templates {
+		t := &pack.Templates[i]
+		if t.ID == "" || t.Name == "" {
+			return pack, FormatError{fmt.Errorf("template %d must have an id and a name", i+1)}
+		}
+
+		if len(t.ID) > 255 || len(t.Name) > 255 || len(t.Subject) > 255 {
+			return pack, FormatError{fmt.Errorf("template %q: id, name and subject must be at most 255 characters", t.ID)}
+		}
+
+		if ids[t.ID] {
+			return pack, FormatError{fmt.Errorf("template %q is listed more than once", t.ID)}
+		}
+		ids[t.ID] = true
+
+		for _, body := range []struct {
+			field  string
+			inline *string
+			file   string
+		}{
+			{"text", &t.Text, t.TextFile},
+			{"html", &t.HTML, t.HTMLFile},
+			{"slack", &t.Slack, t.SlackFile},
+		} {
+			err = resolveBody(readFile, body.inline, body.file)
+			if err != nil {
+				return pack, bodyError("template", t.ID, body.field, err)
+			}
+		}
+		t.TextFile, t.HTMLFile, t.SlackFile = "", "", ""
+
+		if t.HTML == "" {
+			return pack, FormatError{fmt.Errorf("template %q must have an html body", t.ID)}
+		}
+
+		if len(t.Metadata) == 0 {
+			t.Metadata = json.RawMessage("{}")
+		}
+
+		for _, body := range []struct{ field, source string }{
+			{"subject", t.Subject},
+			{"text", t.Text},
+			{"html", t.HTML},
+			{"slack", t.Slack},
+		} {
+			if _, err := template.New(body.field).Parse(body.source); err != nil {
+				return pack, FormatError{fmt.Errorf("template %q: %s syntax is malformed please check your braces", t.ID, body.field)}
+			}
+		}
+	}
+
+	names := map[string]bool{}
+	for i := range pack.Partials {
+		p := &pack.Partials[i]
+		if p.Name == "" || len(p.Name) > 255 {
+			return pack, FormatError{fmt.Errorf("partial %d must have a name of at most 255 characters", i+1)}
+		}
+
+		if names[p.Name] {
+			return pack, FormatError{fmt.Errorf("partial %q is listed more than once", p.Name)}
+		}
+		names[p.Name] = true
+
+		err = resolveBody(readFile, &p.Text, p.TextFile)
+		if err != nil {
+			return pack, bodyError("partial", p.Name, "text", err)
+		}
+
+		err = resolveBody(readFile, &p.HTML, p.HTMLFile)
+		if err != nil {
+			return pack, bodyError("partial", p.Name, "html", err)
+		}
+		p.TextFile, p.HTMLFile = "", ""
+	}
+
+	return pack, nil
+}
+
+func resolveBody(readFile func(string) ([]byte, error), inline *string, file string) error {
+	if file == "" {
+		return nil
+	}
+
+	if *inline != "" {
+		return FormatError{errors.New("is given both inline and as a file")}
+	}
+
+	// Packs are also extracted from uploaded archives, so a file must not
+	// reach outside of the pack.
+	if !fs.ValidPath(file) {
+		return FormatError{fmt.Errorf("file %q must be a relative path inside the pack", file)}
+	}
+
+	contents, err := readFile(file)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return FormatError{fmt.Errorf("file %q is missing", file)}
+		}
+		return err
+	}
+
+	*inline = string(contents)
+	return nil
+}
+
+func bodyError(kind, name, field string, err error) error {
+	if formatErr, ok := err.(FormatError); ok {
+		return FormatError{fmt.Errorf("%s %q: %s %s", kind, name, field, formatErr.Err)}
+	}
+	return err
+}
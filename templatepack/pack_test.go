@@ -0,0 +1,118 @@
+package templatepack_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Load", func() {
+	var dir string
+
+	writeFile := func(name, contents string) {
+		err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(contents), 0644)
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "template-pack")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("loads the templates and partials, reading the files they name", func() {
+		writeFile("manifest.json", `{
+			"version": 1,
+			"name": "acme-platform",
+			"templates": [
+				{
+					"id": "password-reset",
+					"name": "Password reset",
+					"subject": "Reset: {{.Subject}}",
+					"text_file": "password-reset/body.txt",
+					"html_file": "password-reset/body.html",
+					"slack": "*{{.Subject}}*",
+					"metadata": {"owner": "identity"}
+				}
+			],
+			"partials": [
+				{"name": "footer", "text": "-- ACME", "html_file": "footer.html"}
+			]
+		}`)
+		writeFile("password-reset/body.txt", "{{.Text}}")
+		writeFile("password-reset/body.html", "<p>{{.HTML}}</p>")
+		writeFile("footer.html", "<footer>ACME</footer>")
+
+		pack, err := templatepack.LoadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(pack).To(Equal(templatepack.Pack{
+			Version: 1,
+			Name:    "acme-platform",
+			Templates: []templatepack.Template{
+				{
+					ID:       "password-reset",
+					Name:     "Password reset",
+					Subject:  "Reset: {{.Subject}}",
+					Text:     "{{.Text}}",
+					HTML:     "<p>{{.HTML}}</p>",
+					Slack:    "*{{.Subject}}*",
+					Metadata: json.RawMessage(`{"owner": "identity"}`),
+				},
+			},
+			Partials: []templatepack.Partial{
+				{Name: "footer", Text: "-- ACME", HTML: "<footer>ACME</footer>"},
+			},
+		}))
+	})
+
+	It("defaults the metadata to an empty object", func() {
+		writeFile("manifest.json", `{"version": 1, "templates": [{"id": "t", "name": "T", "html": "<p></p>"}]}`)
+
+		pack, err := templatepack.LoadDir(dir)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pack.Templates[0].Metadata).To(MatchJSON(`{}`))
+	})
+
+	DescribeTable("rejects invalid packs",
+		func(manifest, message string) {
+			if manifest != "" {
+				writeFile("manifest.json", manifest)
+			}
+
+			_, err := templatepack.LoadDir(dir)
+			Expect(err).To(MatchError(templatepack.FormatError{Err: errors.New(message)}))
+		},
+		Entry("without a manifest", "", "manifest.json is missing"),
+		Entry("with an unsupported version", `{"version": 2}`, "version 2 is not supported, expected 1"),
+		Entry("with a template without an id", `{"version": 1, "templates": [{"name": "T", "html": "x"}]}`, "template 1 must have an id and a name"),
+		Entry("with a duplicate template", `{"version": 1, "templates": [{"id": "t", "name": "T", "html": "x"}, {"id": "t", "name": "T", "html": "x"}]}`, `template "t" is listed more than once`),
+		Entry("without an html body", `{"version": 1, "templates": [{"id": "t", "name": "T", "text": "x"}]}`, `template "t" must have an html body`),
+		Entry("with a body given twice", `{"version": 1, "templates": [{"id": "t", "name": "T", "html": "x", "html_file": "t.html"}]}`, `template "t": html is given both inline and as a file`),
+		Entry("with a missing file", `{"version": 1, "templates": [{"id": "t", "name": "T", "html_file": "t.html"}]}`, `template "t": html file "t.html" is missing`),
+		Entry("with a file outside of the pack", `{"version": 1, "templates": [{"id": "t", "name": "T", "html_file": "../t.html"}]}`, `template "t": html file "../t.html" must be a relative path inside the pack`),
+		Entry("with malformed syntax", `{"version": 1, "templates": [{"id": "t", "name": "T", "html": "x", "slack": "{{.bad}"}]}`, `template "t": slack syntax is malformed please check your braces`),
+		Entry("with a partial without a name", `{"version": 1, "partials": [{"text": "x"}]}`, "partial 1 must have a name of at most 255 characters"),
+	)
+
+	It("rejects a manifest that is not JSON", func() {
+		writeFile("manifest.json", `{`)
+
+		_, err := templatepack.LoadDir(dir)
+		Expect(err).To(BeAssignableToTypeOf(templatepack.FormatError{}))
+	})
+})
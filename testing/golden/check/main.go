@@ -0,0 +1,53 @@
+// Command check renders a directory of templates against canned variable
+// sets and compares the output to golden files, so a template pack can be
+// validated before it is imported.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/golden"
+)
+
+func main() {
+	templatesDir := flag.String("templates", "templates", "directory of template JSON files")
+	variablesDir := flag.String("variables", "testing/fixtures/golden/variables", "directory of variable set JSON files")
+	goldenDir := flag.String("golden", "testing/fixtures/golden", "directory of golden files")
+	update := flag.Bool("update", false, "rewrite the golden files instead of comparing against them")
+	flag.Parse()
+
+	templates, err := golden.LoadTemplates(*templatesDir)
+	if err != nil {
+		fail(err)
+	}
+
+	variables, err := golden.LoadVariables(*variablesDir)
+	if err != nil {
+		fail(err)
+	}
+
+	harness := golden.NewHarness(*goldenDir)
+	harness.Update = *update
+
+	mismatches, err := harness.Check(templates, variables)
+	if err != nil {
+		fail(err)
+	}
+
+	for _, mismatch := range mismatches {
+		fmt.Printf("MISMATCH %s (template %q, variables %q)\n--- expected\n%s\n--- actual\n%s\n", mismatch.Path, mismatch.Template, mismatch.Variables, mismatch.Expected, mismatch.Actual)
+	}
+
+	if len(mismatches) > 0 {
+		os.Exit(1)
+	}
+
+	fmt.Printf("%d templates rendered against %d variable sets\n", len(templates), len(variables))
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
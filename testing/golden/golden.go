@@ -0,0 +1,306 @@
+package golden
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+const (
+	DefaultSender = "no-reply@notifications.example.com"
+	DefaultDomain = "notifications.example.com"
+)
+
+var digestRequestReceived = time.Date(2015, time.March, 20, 12, 0, 0, 0, time.UTC)
+
+// Template is a stored template in the same JSON format accepted by
+// POST /templates. ID names its directory of golden files.
+type Template struct {
+	ID      string `json:"-"`
+	Name    string `json:"name"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+func TemplateFromModel(template models.Template) Template {
+	return Template{
+		ID:      template.ID,
+		Name:    template.Name,
+		Subject: template.Subject,
+		Text:    template.Text,
+		HTML:    template.HTML,
+	}
+}
+
+// Variables is a canned set of values that a template is rendered against.
+// Name identifies its golden file within each template directory.
+type Variables struct {
+	Name              string `json:"-"`
+	Subject           string `json:"subject"`
+	Text              string `json:"text"`
+	HTML              string `json:"html"`
+	ReplyTo           string `json:"reply_to"`
+	KindID            string `json:"kind_id"`
+	KindDescription   string `json:"kind_description"`
+	SourceDescription string `json:"source_description"`
+	Endorsement       string `json:"endorsement"`
+	Role              string `json:"role"`
+	ClientID          string `json:"client_id"`
+	UserGUID          string `json:"user_guid"`
+	Email             string `json:"email"`
+	MessageID         string `json:"message_id"`
+	Space             string `json:"space"`
+	Organization      string `json:"organization"`
+}
+
+func (v Variables) delivery() common.Delivery {
+	return common.Delivery{
+		MessageID: v.MessageID,
+		UserGUID:  v.UserGUID,
+		Email:     v.Email,
+		ClientID:  v.ClientID,
+		Space: cf.CloudControllerSpace{
+			Name: v.Space,
+		},
+		Organization: cf.CloudControllerOrganization{
+			Name: v.Organization,
+		},
+		Options: common.Options{
+			ReplyTo:           v.ReplyTo,
+			Subject:           v.Subject,
+			Text:              v.Text,
+			HTML:              common.HTML{BodyContent: v.HTML},
+			KindID:            v.KindID,
+			KindDescription:   v.KindDescription,
+			SourceDescription: v.SourceDescription,
+			Endorsement:       v.Endorsement,
+			Role:              v.Role,
+		},
+	}
+}
+
+// LoadTemplates reads every *.json file in dir, using the file name without
+// its extension as the template ID.
+func LoadTemplates(dir string) ([]Template, error) {
+	var templates []Template
+	err := loadJSONFiles(dir, func(name string, contents []byte) error {
+		var template Template
+		if err := json.Unmarshal(contents, &template); err != nil {
+			return err
+		}
+		template.ID = name
+		templates = append(templates, template)
+		return nil
+	})
+
+	return templates, err
+}
+
+// LoadVariables reads every *.json file in dir, using the file name without
+// its extension as the variable set name.
+func LoadVariables(dir string) ([]Variables, error) {
+	var sets []Variables
+	err := loadJSONFiles(dir, func(name string, contents []byte) error {
+		var variables Variables
+		if err := json.Unmarshal(contents, &variables); err != nil {
+			return err
+		}
+		variables.Name = name
+		sets = append(sets, variables)
+		return nil
+	})
+
+	return sets, err
+}
+
+func loadJSONFiles(dir string, load func(name string, contents []byte) error) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		contents, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		err = load(strings.TrimSuffix(filepath.Base(path), ".json"), contents)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+	}
+
+	return nil
+}
+
+type Mismatch struct {
+	Template  string
+	Variables string
+	Path      string
+	Expected  string
+	Actual    string
+}
+
+// Harness renders templates the way the worker does and compares the
+// output to golden files stored at <Dir>/<template ID>/<variables name>.golden.
+// When Update is set the golden files are rewritten instead.
+type Harness struct {
+	Dir    string
+	Update bool
+	Sender string
+	Domain string
+}
+
+func NewHarness(dir string) Harness {
+	return Harness{
+		Dir:    dir,
+		Sender: DefaultSender,
+		Domain: DefaultDomain,
+	}
+}
+
+// Render returns the subject and every body part of the message built from
+// template and variables. Headers carrying timestamps are left out so the
+// output is stable between runs.
+func (h Harness) Render(template Template, variables Variables) (string, error) {
+	if template.ID == models.DigestTemplateID {
+		return h.renderDigest(template, variables)
+	}
+
+	packager := common.NewPackager(staticLoader{template}, plainCloak{})
+
+	context, err := packager.PrepareContext(variables.delivery(), h.Sender, h.Domain)
+	if err != nil {
+		return "", err
+	}
+
+	message, err := packager.Pack(context)
+	if err != nil {
+		return "", err
+	}
+
+	return format(message), nil
+}
+
+// renderDigest renders the digest template with the variables as the only
+// entry, the way the digest scheduler does.
+func (h Harness) renderDigest(template Template, variables Variables) (string, error) {
+	templates, err := staticLoader{template}.LoadTemplates("", "", template.ID, "")
+	if err != nil {
+		return "", err
+	}
+
+	message, err := common.PackDigest(common.DigestContext{
+		From:  h.Sender,
+		To:    variables.Email,
+		Count: 1,
+		Entries: []common.DigestEntry{
+			{
+				Subject:           variables.Subject,
+				Text:              variables.Text,
+				HTML:              variables.HTML,
+				KindDescription:   variables.KindDescription,
+				SourceDescription: variables.SourceDescription,
+				RequestReceived:   digestRequestReceived,
+			},
+		},
+	}, templates)
+	if err != nil {
+		return "", err
+	}
+
+	return format(message), nil
+}
+
+func format(message mail.Message) string {
+	output := fmt.Sprintf("From: %s\nReply-To: %s\nTo: %s\nSubject: %s\n", message.From, message.ReplyTo, message.To, message.Subject)
+	for _, part := range message.Body {
+		output += fmt.Sprintf("\n--- %s ---\n%s\n", part.ContentType, part.Content)
+	}
+
+	return output
+}
+
+// Check renders every template against every variable set. Missing golden
+// files are reported as mismatches with an empty Expected value.
+func (h Harness) Check(templates []Template, sets []Variables) ([]Mismatch, error) {
+	var mismatches []Mismatch
+
+	for _, template := range templates {
+		for _, variables := range sets {
+			actual, err := h.Render(template, variables)
+			if err != nil {
+				return nil, fmt.Errorf("rendering template %q with %q: %s", template.ID, variables.Name, err)
+			}
+
+			path := filepath.Join(h.Dir, template.ID, variables.Name+".golden")
+
+			if h.Update {
+				if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+					return nil, err
+				}
+
+				if err := ioutil.WriteFile(path, []byte(actual), 0644); err != nil {
+					return nil, err
+				}
+				continue
+			}
+
+			expected, err := ioutil.ReadFile(path)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+
+			if string(expected) != actual {
+				mismatches = append(mismatches, Mismatch{
+					Template:  template.ID,
+					Variables: variables.Name,
+					Path:      path,
+					Expected:  string(expected),
+					Actual:    actual,
+				})
+			}
+		}
+	}
+
+	return mismatches, nil
+}
+
+type staticLoader struct {
+	template Template
+}
+
+func (l staticLoader) LoadTemplates(clientID, kindID, templateID, locale string) (common.Templates, error) {
+	return common.Templates{
+		Name:    l.template.Name,
+		Subject: l.template.Subject,
+		Text:    l.template.Text,
+		HTML:    l.template.HTML,
+	}, nil
+}
+
+// plainCloak stands in for the encrypting cloak so unsubscribe IDs come out
+// the same on every run.
+type plainCloak struct{}
+
+func (plainCloak) Veil(plaintext []byte) ([]byte, error) {
+	return []byte(hex.EncodeToString(plaintext)), nil
+}
+
+func (plainCloak) Unveil(ciphertext []byte) ([]byte, error) {
+	return hex.DecodeString(string(ciphertext))
+}
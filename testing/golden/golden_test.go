@@ -0,0 +1,149 @@
+package golden_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/golden"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Harness", func() {
+	var (
+		harness   golden.Harness
+		template  golden.Template
+		variables golden.Variables
+		dir       string
+	)
+
+	BeforeEach(func() {
+		var err error
+		dir, err = ioutil.TempDir("", "golden")
+		Expect(err).NotTo(HaveOccurred())
+
+		harness = golden.NewHarness(dir)
+		template = golden.Template{
+			ID:      "some-template",
+			Name:    "Some Template",
+			Subject: "Hello: {{.Subject}}",
+			Text:    "{{.Text}} ({{.UnsubscribeID}})",
+			HTML:    "<p>{{.HTML}}</p>",
+		}
+		variables = golden.Variables{
+			Name:      "greeting",
+			Subject:   "greetings",
+			Text:      "hi <there>",
+			HTML:      "<b>hi</b>",
+			ClientID:  "some-client",
+			KindID:    "some-kind",
+			UserGUID:  "user-123",
+			Email:     "user-123@example.com",
+			MessageID: "message-123",
+		}
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(dir)
+	})
+
+	It("matches the golden files for the templates shipped with the repo", func() {
+		templates, err := golden.LoadTemplates("../../templates")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(templates).NotTo(BeEmpty())
+
+		sets, err := golden.LoadVariables("../fixtures/golden/variables")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(sets).NotTo(BeEmpty())
+
+		mismatches, err := golden.NewHarness("../fixtures/golden").Check(templates, sets)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mismatches).To(BeEmpty())
+	})
+
+	Describe("Render", func() {
+		It("renders the subject and body parts without escaping the text part", func() {
+			output, err := harness.Render(template, variables)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(output).To(ContainSubstring("To: user-123@example.com\nSubject: Hello: greetings\n"))
+			Expect(output).To(ContainSubstring("--- text/plain ---\nhi <there> (757365722d3132337c736f6d652d636c69656e747c736f6d652d6b696e64)\n"))
+			Expect(output).To(ContainSubstring("<p><b>hi</b></p>"))
+		})
+
+		It("renders the same output every time", func() {
+			first, err := harness.Render(template, variables)
+			Expect(err).NotTo(HaveOccurred())
+
+			second, err := harness.Render(template, variables)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(second).To(Equal(first))
+		})
+
+		It("returns an error when the template does not compile", func() {
+			template.Subject = "{{.Subject"
+
+			_, err := harness.Render(template, variables)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	Describe("Check", func() {
+		It("writes the golden files when updating", func() {
+			harness.Update = true
+
+			mismatches, err := harness.Check([]golden.Template{template}, []golden.Variables{variables})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mismatches).To(BeEmpty())
+
+			contents, err := ioutil.ReadFile(filepath.Join(dir, "some-template", "greeting.golden"))
+			Expect(err).NotTo(HaveOccurred())
+
+			output, err := harness.Render(template, variables)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(contents)).To(Equal(output))
+		})
+
+		It("reports output that differs from the golden file", func() {
+			Expect(os.MkdirAll(filepath.Join(dir, "some-template"), 0755)).To(Succeed())
+			Expect(ioutil.WriteFile(filepath.Join(dir, "some-template", "greeting.golden"), []byte("stale"), 0644)).To(Succeed())
+
+			mismatches, err := harness.Check([]golden.Template{template}, []golden.Variables{variables})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mismatches).To(HaveLen(1))
+			Expect(mismatches[0].Template).To(Equal("some-template"))
+			Expect(mismatches[0].Variables).To(Equal("greeting"))
+			Expect(mismatches[0].Expected).To(Equal("stale"))
+			Expect(mismatches[0].Actual).To(ContainSubstring("Subject: Hello: greetings"))
+		})
+
+		It("reports a missing golden file", func() {
+			mismatches, err := harness.Check([]golden.Template{template}, []golden.Variables{variables})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mismatches).To(HaveLen(1))
+			Expect(mismatches[0].Expected).To(BeEmpty())
+			Expect(mismatches[0].Path).To(Equal(filepath.Join(dir, "some-template", "greeting.golden")))
+		})
+	})
+
+	Describe("TemplateFromModel", func() {
+		It("uses the stored template ID", func() {
+			t := golden.TemplateFromModel(models.Template{
+				ID:      "template-id",
+				Name:    "Stored",
+				Subject: "subject",
+				Text:    "text",
+				HTML:    "html",
+			})
+			Expect(t).To(Equal(golden.Template{
+				ID:      "template-id",
+				Name:    "Stored",
+				Subject: "subject",
+				Text:    "text",
+				HTML:    "html",
+			}))
+		})
+	})
+})
@@ -0,0 +1,13 @@
+package golden_test
+
+import (
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func TestGoldenSuite(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "testing/golden")
+}
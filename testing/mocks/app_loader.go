@@ -0,0 +1,29 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/cf"
+
+type AppLoader struct {
+	LoadCall struct {
+		CallCount int
+		Receives  struct {
+			AppGUID string
+			Token   string
+		}
+		Returns struct {
+			App   cf.CloudControllerApp
+			Error error
+		}
+	}
+}
+
+func NewAppLoader() *AppLoader {
+	return &AppLoader{}
+}
+
+func (al *AppLoader) Load(appGUID, token string) (cf.CloudControllerApp, error) {
+	al.LoadCall.Receives.AppGUID = appGUID
+	al.LoadCall.Receives.Token = token
+	al.LoadCall.CallCount++
+
+	return al.LoadCall.Returns.App, al.LoadCall.Returns.Error
+}
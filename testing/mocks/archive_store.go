@@ -0,0 +1,26 @@
+package mocks
+
+type ArchiveStore struct {
+	PutCall struct {
+		Receives struct {
+			Key         string
+			ContentType string
+			Body        []byte
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewArchiveStore() *ArchiveStore {
+	return &ArchiveStore{}
+}
+
+func (s *ArchiveStore) Put(key, contentType string, body []byte) error {
+	s.PutCall.Receives.Key = key
+	s.PutCall.Receives.ContentType = contentType
+	s.PutCall.Receives.Body = body
+
+	return s.PutCall.Returns.Error
+}
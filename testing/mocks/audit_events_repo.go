@@ -0,0 +1,69 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type AuditEventsRepo struct {
+	CreateCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Event      models.AuditEvent
+		}
+		Returns struct {
+			Event models.AuditEvent
+			Error error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Filter     models.AuditEventFilter
+			Offset     int
+			Limit      int
+		}
+		Returns struct {
+			Events []models.AuditEvent
+			Error  error
+		}
+	}
+
+	CountCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Filter     models.AuditEventFilter
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewAuditEventsRepo() *AuditEventsRepo {
+	return &AuditEventsRepo{}
+}
+
+func (r *AuditEventsRepo) Create(conn models.ConnectionInterface, event models.AuditEvent) (models.AuditEvent, error) {
+	r.CreateCall.CallCount++
+	r.CreateCall.Receives.Connection = conn
+	r.CreateCall.Receives.Event = event
+
+	return r.CreateCall.Returns.Event, r.CreateCall.Returns.Error
+}
+
+func (r *AuditEventsRepo) List(conn models.ConnectionInterface, filter models.AuditEventFilter, offset, limit int) ([]models.AuditEvent, error) {
+	r.ListCall.Receives.Connection = conn
+	r.ListCall.Receives.Filter = filter
+	r.ListCall.Receives.Offset = offset
+	r.ListCall.Receives.Limit = limit
+
+	return r.ListCall.Returns.Events, r.ListCall.Returns.Error
+}
+
+func (r *AuditEventsRepo) Count(conn models.ConnectionInterface, filter models.AuditEventFilter) (int, error) {
+	r.CountCall.Receives.Connection = conn
+	r.CountCall.Receives.Filter = filter
+
+	return r.CountCall.Returns.Count, r.CountCall.Returns.Error
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/web/notify"
+
+type Authorizer struct {
+	AuthorizeCall struct {
+		CallCount int
+		Receives  struct {
+			Request notify.AuthorizationRequest
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewAuthorizer() *Authorizer {
+	return &Authorizer{}
+}
+
+func (a *Authorizer) Authorize(request notify.AuthorizationRequest) error {
+	a.AuthorizeCall.CallCount++
+	a.AuthorizeCall.Receives.Request = request
+
+	return a.AuthorizeCall.Returns.Error
+}
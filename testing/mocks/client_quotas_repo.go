@@ -0,0 +1,113 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type ClientQuotasRepo struct {
+	FindCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Quota models.ClientQuota
+			Error error
+		}
+	}
+
+	UpsertCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Quota      models.ClientQuota
+		}
+		Returns struct {
+			Quota models.ClientQuota
+			Error error
+		}
+	}
+
+	DeleteCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	UsageCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			At         time.Time
+		}
+		Returns struct {
+			Used  int
+			Error error
+		}
+	}
+
+	ConsumeCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			Count      int
+			At         time.Time
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewClientQuotasRepo() *ClientQuotasRepo {
+	return &ClientQuotasRepo{}
+}
+
+func (r *ClientQuotasRepo) Find(conn models.ConnectionInterface, clientID string) (models.ClientQuota, error) {
+	r.FindCall.Receives.Connection = conn
+	r.FindCall.Receives.ClientID = clientID
+
+	return r.FindCall.Returns.Quota, r.FindCall.Returns.Error
+}
+
+func (r *ClientQuotasRepo) Upsert(conn models.ConnectionInterface, quota models.ClientQuota) (models.ClientQuota, error) {
+	r.UpsertCall.WasCalled = true
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.Quota = quota
+
+	return r.UpsertCall.Returns.Quota, r.UpsertCall.Returns.Error
+}
+
+func (r *ClientQuotasRepo) Delete(conn models.ConnectionInterface, clientID string) error {
+	r.DeleteCall.WasCalled = true
+	r.DeleteCall.Receives.Connection = conn
+	r.DeleteCall.Receives.ClientID = clientID
+
+	return r.DeleteCall.Returns.Error
+}
+
+func (r *ClientQuotasRepo) Usage(conn models.ConnectionInterface, clientID string, at time.Time) (int, error) {
+	r.UsageCall.Receives.Connection = conn
+	r.UsageCall.Receives.ClientID = clientID
+	r.UsageCall.Receives.At = at
+
+	return r.UsageCall.Returns.Used, r.UsageCall.Returns.Error
+}
+
+func (r *ClientQuotasRepo) Consume(conn models.ConnectionInterface, clientID string, count int, at time.Time) error {
+	r.ConsumeCall.CallCount++
+	r.ConsumeCall.Receives.Connection = conn
+	r.ConsumeCall.Receives.ClientID = clientID
+	r.ConsumeCall.Receives.Count = count
+	r.ConsumeCall.Receives.At = at
+
+	return r.ConsumeCall.Returns.Error
+}
@@ -0,0 +1,66 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type ClientSuspensionsRepo struct {
+	FindCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Suspension models.ClientSuspension
+			Error      error
+		}
+	}
+
+	CreateCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Suspension models.ClientSuspension
+		}
+		Returns struct {
+			Suspension models.ClientSuspension
+			Error      error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewClientSuspensionsRepo() *ClientSuspensionsRepo {
+	return &ClientSuspensionsRepo{}
+}
+
+func (r *ClientSuspensionsRepo) Find(conn models.ConnectionInterface, clientID string) (models.ClientSuspension, error) {
+	r.FindCall.CallCount++
+	r.FindCall.Receives.Connection = conn
+	r.FindCall.Receives.ClientID = clientID
+
+	return r.FindCall.Returns.Suspension, r.FindCall.Returns.Error
+}
+
+func (r *ClientSuspensionsRepo) Create(conn models.ConnectionInterface, suspension models.ClientSuspension) (models.ClientSuspension, error) {
+	r.CreateCall.WasCalled = true
+	r.CreateCall.Receives.Connection = conn
+	r.CreateCall.Receives.Suspension = suspension
+
+	return r.CreateCall.Returns.Suspension, r.CreateCall.Returns.Error
+}
+
+func (r *ClientSuspensionsRepo) Delete(conn models.ConnectionInterface, clientID string) error {
+	r.DeleteCall.Receives.Connection = conn
+	r.DeleteCall.Receives.ClientID = clientID
+
+	return r.DeleteCall.Returns.Error
+}
@@ -14,6 +14,17 @@ type CloudController struct {
 		}
 	}
 
+	GetAuditorsBySpaceGuidCall struct {
+		Receives struct {
+			SpaceGUID string
+			Token     string
+		}
+		Returns struct {
+			Users []cf.CloudControllerUser
+			Error error
+		}
+	}
+
 	GetBillingManagersByOrgGuidCall struct {
 		Receives struct {
 			OrgGUID string
@@ -25,6 +36,17 @@ type CloudController struct {
 		}
 	}
 
+	GetDevelopersBySpaceGuidCall struct {
+		Receives struct {
+			SpaceGUID string
+			Token     string
+		}
+		Returns struct {
+			Users []cf.CloudControllerUser
+			Error error
+		}
+	}
+
 	GetManagersByOrgGuidCall struct {
 		Receives struct {
 			OrgGUID string
@@ -36,6 +58,17 @@ type CloudController struct {
 		}
 	}
 
+	GetManagersBySpaceGuidCall struct {
+		Receives struct {
+			SpaceGUID string
+			Token     string
+		}
+		Returns struct {
+			Users []cf.CloudControllerUser
+			Error error
+		}
+	}
+
 	GetUsersByOrgGuidCall struct {
 		Receives struct {
 			OrgGUID string
@@ -58,6 +91,17 @@ type CloudController struct {
 		}
 	}
 
+	LoadAppCall struct {
+		Receives struct {
+			AppGUID string
+			Token   string
+		}
+		Returns struct {
+			App   cf.CloudControllerApp
+			Error error
+		}
+	}
+
 	LoadOrganizationCall struct {
 		Receives struct {
 			OrgGUID string
@@ -92,6 +136,13 @@ func (cc *CloudController) GetAuditorsByOrgGuid(orgGUID, token string) ([]cf.Clo
 	return cc.GetAuditorsByOrgGuidCall.Returns.Users, cc.GetAuditorsByOrgGuidCall.Returns.Error
 }
 
+func (cc *CloudController) GetAuditorsBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error) {
+	cc.GetAuditorsBySpaceGuidCall.Receives.SpaceGUID = spaceGUID
+	cc.GetAuditorsBySpaceGuidCall.Receives.Token = token
+
+	return cc.GetAuditorsBySpaceGuidCall.Returns.Users, cc.GetAuditorsBySpaceGuidCall.Returns.Error
+}
+
 func (cc *CloudController) GetBillingManagersByOrgGuid(orgGUID, token string) ([]cf.CloudControllerUser, error) {
 	cc.GetBillingManagersByOrgGuidCall.Receives.OrgGUID = orgGUID
 	cc.GetBillingManagersByOrgGuidCall.Receives.Token = token
@@ -99,6 +150,13 @@ func (cc *CloudController) GetBillingManagersByOrgGuid(orgGUID, token string) ([
 	return cc.GetBillingManagersByOrgGuidCall.Returns.Users, cc.GetBillingManagersByOrgGuidCall.Returns.Error
 }
 
+func (cc *CloudController) GetDevelopersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error) {
+	cc.GetDevelopersBySpaceGuidCall.Receives.SpaceGUID = spaceGUID
+	cc.GetDevelopersBySpaceGuidCall.Receives.Token = token
+
+	return cc.GetDevelopersBySpaceGuidCall.Returns.Users, cc.GetDevelopersBySpaceGuidCall.Returns.Error
+}
+
 func (cc *CloudController) GetManagersByOrgGuid(orgGUID, token string) ([]cf.CloudControllerUser, error) {
 	cc.GetManagersByOrgGuidCall.Receives.OrgGUID = orgGUID
 	cc.GetManagersByOrgGuidCall.Receives.Token = token
@@ -106,6 +164,13 @@ func (cc *CloudController) GetManagersByOrgGuid(orgGUID, token string) ([]cf.Clo
 	return cc.GetManagersByOrgGuidCall.Returns.Users, cc.GetManagersByOrgGuidCall.Returns.Error
 }
 
+func (cc *CloudController) GetManagersBySpaceGuid(spaceGUID, token string) ([]cf.CloudControllerUser, error) {
+	cc.GetManagersBySpaceGuidCall.Receives.SpaceGUID = spaceGUID
+	cc.GetManagersBySpaceGuidCall.Receives.Token = token
+
+	return cc.GetManagersBySpaceGuidCall.Returns.Users, cc.GetManagersBySpaceGuidCall.Returns.Error
+}
+
 func (cc *CloudController) GetUsersByOrgGuid(orgGUID, token string) ([]cf.CloudControllerUser, error) {
 	cc.GetUsersByOrgGuidCall.Receives.OrgGUID = orgGUID
 	cc.GetUsersByOrgGuidCall.Receives.Token = token
@@ -120,6 +185,13 @@ func (cc *CloudController) GetUsersBySpaceGuid(spaceGUID, token string) ([]cf.Cl
 	return cc.GetUsersBySpaceGuidCall.Returns.Users, cc.GetUsersBySpaceGuidCall.Returns.Error
 }
 
+func (cc *CloudController) LoadApp(appGUID, token string) (cf.CloudControllerApp, error) {
+	cc.LoadAppCall.Receives.AppGUID = appGUID
+	cc.LoadAppCall.Receives.Token = token
+
+	return cc.LoadAppCall.Returns.App, cc.LoadAppCall.Returns.Error
+}
+
 func (cc *CloudController) LoadOrganization(orgGUID, token string) (cf.CloudControllerOrganization, error) {
 	cc.LoadOrganizationCall.Receives.OrgGUID = orgGUID
 	cc.LoadOrganizationCall.Receives.Token = token
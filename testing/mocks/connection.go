@@ -94,6 +94,12 @@ type Connection struct {
 			DbMap *gorp.DbMap
 		}
 	}
+
+	ReplicaCall struct {
+		Returns struct {
+			Replica bool
+		}
+	}
 }
 
 func NewConnection() *Connection {
@@ -152,6 +158,10 @@ func (c *Connection) Update(list ...interface{}) (int64, error) {
 	return c.UpdateCall.Returns.Count, c.UpdateCall.Returns.Error
 }
 
+func (c *Connection) Replica() bool {
+	return c.ReplicaCall.Returns.Replica
+}
+
 func (c *Connection) GetDbMap() *gorp.DbMap {
 	c.GetDbMapCall.WasCalled = true
 	return c.GetDbMapCall.Returns.DbMap
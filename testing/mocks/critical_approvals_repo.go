@@ -0,0 +1,94 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type CriticalApprovalsRepo struct {
+	FindAllByStatusCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Status     string
+		}
+		Returns struct {
+			Approvals []models.CriticalApproval
+			Error     error
+		}
+	}
+
+	RequestCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+			Status     string
+		}
+		Returns struct {
+			Approval models.CriticalApproval
+			Error    error
+		}
+	}
+
+	SetStatusCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+			Status     string
+		}
+		Returns struct {
+			Approval models.CriticalApproval
+			Error    error
+		}
+	}
+
+	WithdrawCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewCriticalApprovalsRepo() *CriticalApprovalsRepo {
+	return &CriticalApprovalsRepo{}
+}
+
+func (r *CriticalApprovalsRepo) FindAllByStatus(conn models.ConnectionInterface, status string) ([]models.CriticalApproval, error) {
+	r.FindAllByStatusCall.Receives.Connection = conn
+	r.FindAllByStatusCall.Receives.Status = status
+
+	return r.FindAllByStatusCall.Returns.Approvals, r.FindAllByStatusCall.Returns.Error
+}
+
+func (r *CriticalApprovalsRepo) Request(conn models.ConnectionInterface, clientID, kindID, status string) (models.CriticalApproval, error) {
+	r.RequestCall.CallCount++
+	r.RequestCall.Receives.Connection = conn
+	r.RequestCall.Receives.ClientID = clientID
+	r.RequestCall.Receives.KindID = kindID
+	r.RequestCall.Receives.Status = status
+
+	return r.RequestCall.Returns.Approval, r.RequestCall.Returns.Error
+}
+
+func (r *CriticalApprovalsRepo) SetStatus(conn models.ConnectionInterface, clientID, kindID, status string) (models.CriticalApproval, error) {
+	r.SetStatusCall.Receives.Connection = conn
+	r.SetStatusCall.Receives.ClientID = clientID
+	r.SetStatusCall.Receives.KindID = kindID
+	r.SetStatusCall.Receives.Status = status
+
+	return r.SetStatusCall.Returns.Approval, r.SetStatusCall.Returns.Error
+}
+
+func (r *CriticalApprovalsRepo) Withdraw(conn models.ConnectionInterface, clientID, kindID string) error {
+	r.WithdrawCall.CallCount++
+	r.WithdrawCall.Receives.Connection = conn
+	r.WithdrawCall.Receives.ClientID = clientID
+	r.WithdrawCall.Receives.KindID = kindID
+
+	return r.WithdrawCall.Returns.Error
+}
@@ -0,0 +1,74 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type CriticalApprover struct {
+	ListCall struct {
+		Receives struct {
+			Database services.DatabaseInterface
+			Status   string
+		}
+		Returns struct {
+			Approvals []models.CriticalApproval
+			Error     error
+		}
+	}
+
+	ApproveCall struct {
+		WasCalled bool
+		Receives  struct {
+			Database services.DatabaseInterface
+			ClientID string
+			KindID   string
+		}
+		Returns struct {
+			Approval models.CriticalApproval
+			Error    error
+		}
+	}
+
+	DenyCall struct {
+		WasCalled bool
+		Receives  struct {
+			Database services.DatabaseInterface
+			ClientID string
+			KindID   string
+		}
+		Returns struct {
+			Approval models.CriticalApproval
+			Error    error
+		}
+	}
+}
+
+func NewCriticalApprover() *CriticalApprover {
+	return &CriticalApprover{}
+}
+
+func (a *CriticalApprover) List(database services.DatabaseInterface, status string) ([]models.CriticalApproval, error) {
+	a.ListCall.Receives.Database = database
+	a.ListCall.Receives.Status = status
+
+	return a.ListCall.Returns.Approvals, a.ListCall.Returns.Error
+}
+
+func (a *CriticalApprover) Approve(database services.DatabaseInterface, clientID, kindID string) (models.CriticalApproval, error) {
+	a.ApproveCall.WasCalled = true
+	a.ApproveCall.Receives.Database = database
+	a.ApproveCall.Receives.ClientID = clientID
+	a.ApproveCall.Receives.KindID = kindID
+
+	return a.ApproveCall.Returns.Approval, a.ApproveCall.Returns.Error
+}
+
+func (a *CriticalApprover) Deny(database services.DatabaseInterface, clientID, kindID string) (models.CriticalApproval, error) {
+	a.DenyCall.WasCalled = true
+	a.DenyCall.Receives.Database = database
+	a.DenyCall.Receives.ClientID = clientID
+	a.DenyCall.Receives.KindID = kindID
+
+	return a.DenyCall.Returns.Approval, a.DenyCall.Returns.Error
+}
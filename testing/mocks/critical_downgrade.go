@@ -0,0 +1,29 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type CriticalDowngrade struct {
+	ApplyCall struct {
+		Receives struct {
+			Connection services.ConnectionInterface
+			Kinds      []models.Kind
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewCriticalDowngrade() *CriticalDowngrade {
+	return &CriticalDowngrade{}
+}
+
+func (d *CriticalDowngrade) Apply(conn services.ConnectionInterface, kind models.Kind) error {
+	d.ApplyCall.Receives.Connection = conn
+	d.ApplyCall.Receives.Kinds = append(d.ApplyCall.Receives.Kinds, kind)
+
+	return d.ApplyCall.Returns.Error
+}
@@ -0,0 +1,96 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type CriticalUnsubscribesRepo struct {
+	GetCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserID     string
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Unsubscribed bool
+			Error        error
+		}
+	}
+
+	SetCall struct {
+		Receives struct {
+			Connection  models.ConnectionInterface
+			UserID      string
+			ClientID    string
+			KindID      string
+			Unsubscribe bool
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FindAllByKindCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Unsubscribes []models.CriticalUnsubscribe
+			Error        error
+		}
+	}
+
+	DeleteAllByKindCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewCriticalUnsubscribesRepo() *CriticalUnsubscribesRepo {
+	return &CriticalUnsubscribesRepo{}
+}
+
+func (r *CriticalUnsubscribesRepo) Get(conn models.ConnectionInterface, userID, clientID, kindID string) (bool, error) {
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.UserID = userID
+	r.GetCall.Receives.ClientID = clientID
+	r.GetCall.Receives.KindID = kindID
+
+	return r.GetCall.Returns.Unsubscribed, r.GetCall.Returns.Error
+}
+
+func (r *CriticalUnsubscribesRepo) Set(conn models.ConnectionInterface, userID, clientID, kindID string, unsubscribe bool) error {
+	r.SetCall.Receives.Connection = conn
+	r.SetCall.Receives.UserID = userID
+	r.SetCall.Receives.ClientID = clientID
+	r.SetCall.Receives.KindID = kindID
+	r.SetCall.Receives.Unsubscribe = unsubscribe
+
+	return r.SetCall.Returns.Error
+}
+
+func (r *CriticalUnsubscribesRepo) FindAllByKind(conn models.ConnectionInterface, clientID, kindID string) ([]models.CriticalUnsubscribe, error) {
+	r.FindAllByKindCall.Receives.Connection = conn
+	r.FindAllByKindCall.Receives.ClientID = clientID
+	r.FindAllByKindCall.Receives.KindID = kindID
+
+	return r.FindAllByKindCall.Returns.Unsubscribes, r.FindAllByKindCall.Returns.Error
+}
+
+func (r *CriticalUnsubscribesRepo) DeleteAllByKind(conn models.ConnectionInterface, clientID, kindID string) (int, error) {
+	r.DeleteAllByKindCall.WasCalled = true
+	r.DeleteAllByKindCall.Receives.Connection = conn
+	r.DeleteAllByKindCall.Receives.ClientID = clientID
+	r.DeleteAllByKindCall.Receives.KindID = kindID
+
+	return r.DeleteAllByKindCall.Returns.Count, r.DeleteAllByKindCall.Returns.Error
+}
@@ -14,6 +14,13 @@ type Database struct {
 		}
 	}
 
+	ReadConnectionCall struct {
+		CallCount int
+		Returns   struct {
+			Connection db.ConnectionInterface
+		}
+	}
+
 	RawConnectionCall struct {
 		Returns struct {
 			DB *sql.DB
@@ -36,6 +43,11 @@ func (d *Database) Connection() db.ConnectionInterface {
 	return d.ConnectionCall.Returns.Connection
 }
 
+func (d *Database) ReadConnection() db.ConnectionInterface {
+	d.ReadConnectionCall.CallCount++
+	return d.ReadConnectionCall.Returns.Connection
+}
+
 func (d *Database) RawConnection() *sql.DB {
 	return d.RawConnectionCall.Returns.DB
 }
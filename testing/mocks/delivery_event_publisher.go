@@ -0,0 +1,28 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/postal/common"
+
+type DeliveryEventPublisher struct {
+	PublishCall struct {
+		CallCount int
+		Receives  struct {
+			Delivery common.Delivery
+			Status   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewDeliveryEventPublisher() *DeliveryEventPublisher {
+	return &DeliveryEventPublisher{}
+}
+
+func (p *DeliveryEventPublisher) Publish(delivery common.Delivery, status string) error {
+	p.PublishCall.CallCount++
+	p.PublishCall.Receives.Delivery = delivery
+	p.PublishCall.Receives.Status = status
+
+	return p.PublishCall.Returns.Error
+}
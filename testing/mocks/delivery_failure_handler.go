@@ -13,6 +13,15 @@ type DeliveryFailureHandler struct {
 			Logger lager.Logger
 		}
 	}
+
+	HandleWithPolicyCall struct {
+		WasCalled bool
+		Receives  struct {
+			Job    common.Retryable
+			Policy common.RetryPolicy
+			Logger lager.Logger
+		}
+	}
 }
 
 func NewDeliveryFailureHandler() *DeliveryFailureHandler {
@@ -24,3 +33,10 @@ func (h *DeliveryFailureHandler) Handle(job common.Retryable, logger lager.Logge
 	h.HandleCall.Receives.Job = job
 	h.HandleCall.Receives.Logger = logger
 }
+
+func (h *DeliveryFailureHandler) HandleWithPolicy(job common.Retryable, policy common.RetryPolicy, logger lager.Logger) {
+	h.HandleWithPolicyCall.WasCalled = true
+	h.HandleWithPolicyCall.Receives.Job = job
+	h.HandleWithPolicyCall.Receives.Policy = policy
+	h.HandleWithPolicyCall.Receives.Logger = logger
+}
@@ -0,0 +1,91 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type DigestEntriesRepo struct {
+	CreateCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Entry      models.DigestEntry
+		}
+		Returns struct {
+			Entry models.DigestEntry
+			Error error
+		}
+	}
+
+	FindDueUserGUIDsCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Now        time.Time
+			Limit      int
+		}
+		Returns struct {
+			UserGUIDs []string
+			Error     error
+		}
+	}
+
+	FindAllByUserGUIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUIDs  []string
+		}
+		Returns struct {
+			Entries map[string][]models.DigestEntry
+			Error   error
+		}
+	}
+
+	DeleteThroughCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUIDs  []string
+			Primaries  []int
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewDigestEntriesRepo() *DigestEntriesRepo {
+	return &DigestEntriesRepo{}
+}
+
+func (r *DigestEntriesRepo) Create(conn models.ConnectionInterface, entry models.DigestEntry) (models.DigestEntry, error) {
+	r.CreateCall.CallCount++
+	r.CreateCall.Receives.Connection = conn
+	r.CreateCall.Receives.Entry = entry
+
+	return r.CreateCall.Returns.Entry, r.CreateCall.Returns.Error
+}
+
+func (r *DigestEntriesRepo) FindDueUserGUIDs(conn models.ConnectionInterface, now time.Time, limit int) ([]string, error) {
+	r.FindDueUserGUIDsCall.Receives.Connection = conn
+	r.FindDueUserGUIDsCall.Receives.Now = now
+	r.FindDueUserGUIDsCall.Receives.Limit = limit
+
+	return r.FindDueUserGUIDsCall.Returns.UserGUIDs, r.FindDueUserGUIDsCall.Returns.Error
+}
+
+func (r *DigestEntriesRepo) FindAllByUserGUID(conn models.ConnectionInterface, userGUID string) ([]models.DigestEntry, error) {
+	r.FindAllByUserGUIDCall.Receives.Connection = conn
+	r.FindAllByUserGUIDCall.Receives.UserGUIDs = append(r.FindAllByUserGUIDCall.Receives.UserGUIDs, userGUID)
+
+	return r.FindAllByUserGUIDCall.Returns.Entries[userGUID], r.FindAllByUserGUIDCall.Returns.Error
+}
+
+func (r *DigestEntriesRepo) DeleteThrough(conn models.ConnectionInterface, userGUID string, primary int) (int, error) {
+	r.DeleteThroughCall.Receives.Connection = conn
+	r.DeleteThroughCall.Receives.UserGUIDs = append(r.DeleteThroughCall.Receives.UserGUIDs, userGUID)
+	r.DeleteThroughCall.Receives.Primaries = append(r.DeleteThroughCall.Receives.Primaries, primary)
+
+	return r.DeleteThroughCall.Returns.Count, r.DeleteThroughCall.Returns.Error
+}
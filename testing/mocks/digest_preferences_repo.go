@@ -0,0 +1,48 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type DigestPreferencesRepo struct {
+	GetCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserID     string
+		}
+		Returns struct {
+			Frequency string
+			Error     error
+		}
+	}
+
+	SetCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			UserID     string
+			Frequency  string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewDigestPreferencesRepo() *DigestPreferencesRepo {
+	return &DigestPreferencesRepo{}
+}
+
+func (r *DigestPreferencesRepo) Get(conn models.ConnectionInterface, userID string) (string, error) {
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.UserID = userID
+
+	return r.GetCall.Returns.Frequency, r.GetCall.Returns.Error
+}
+
+func (r *DigestPreferencesRepo) Set(conn models.ConnectionInterface, userID, frequency string) error {
+	r.SetCall.WasCalled = true
+	r.SetCall.Receives.Connection = conn
+	r.SetCall.Receives.UserID = userID
+	r.SetCall.Receives.Frequency = frequency
+
+	return r.SetCall.Returns.Error
+}
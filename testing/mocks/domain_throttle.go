@@ -0,0 +1,25 @@
+package mocks
+
+import "time"
+
+type DomainThrottle struct {
+	TakeCall struct {
+		Receives struct {
+			Email string
+		}
+		Returns struct {
+			Wait time.Duration
+			OK   bool
+		}
+	}
+}
+
+func NewDomainThrottle() *DomainThrottle {
+	return &DomainThrottle{}
+}
+
+func (t *DomainThrottle) Take(email string) (time.Duration, bool) {
+	t.TakeCall.Receives.Email = email
+
+	return t.TakeCall.Returns.Wait, t.TakeCall.Returns.OK
+}
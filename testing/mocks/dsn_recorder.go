@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type DSNRecorder struct {
+	RecordCall struct {
+		CallCount int
+		Receives  struct {
+			Connection services.ConnectionInterface
+			Report     mail.DSN
+		}
+		Returns struct {
+			Message models.Message
+			Error   error
+		}
+	}
+}
+
+func NewDSNRecorder() *DSNRecorder {
+	return &DSNRecorder{}
+}
+
+func (r *DSNRecorder) Record(conn services.ConnectionInterface, report mail.DSN) (models.Message, error) {
+	r.RecordCall.CallCount++
+	r.RecordCall.Receives.Connection = conn
+	r.RecordCall.Receives.Report = report
+
+	return r.RecordCall.Returns.Message, r.RecordCall.Returns.Error
+}
@@ -0,0 +1,32 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type EngagementRecorder struct {
+	RecordCall struct {
+		CallCount int
+		Receives  struct {
+			Connection services.ConnectionInterface
+			Token      string
+		}
+		Returns struct {
+			Event common.TrackingEvent
+			Error error
+		}
+	}
+}
+
+func NewEngagementRecorder() *EngagementRecorder {
+	return &EngagementRecorder{}
+}
+
+func (r *EngagementRecorder) Record(conn services.ConnectionInterface, token string) (common.TrackingEvent, error) {
+	r.RecordCall.CallCount++
+	r.RecordCall.Receives.Connection = conn
+	r.RecordCall.Receives.Token = token
+
+	return r.RecordCall.Returns.Event, r.RecordCall.Returns.Error
+}
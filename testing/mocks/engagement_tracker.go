@@ -0,0 +1,31 @@
+package mocks
+
+type EngagementTracker struct {
+	InstrumentCall struct {
+		CallCount int
+		Receives  struct {
+			Body      string
+			MessageID string
+			ClientID  string
+			KindID    string
+		}
+		Returns struct {
+			Body  string
+			Error error
+		}
+	}
+}
+
+func NewEngagementTracker() *EngagementTracker {
+	return &EngagementTracker{}
+}
+
+func (t *EngagementTracker) Instrument(body string, messageID, clientID, kindID string) (string, error) {
+	t.InstrumentCall.CallCount++
+	t.InstrumentCall.Receives.Body = body
+	t.InstrumentCall.Receives.MessageID = messageID
+	t.InstrumentCall.Receives.ClientID = clientID
+	t.InstrumentCall.Receives.KindID = kindID
+
+	return t.InstrumentCall.Returns.Body, t.InstrumentCall.Returns.Error
+}
@@ -27,6 +27,22 @@ type Enqueuer struct {
 			Err       error
 		}
 	}
+
+	EnqueueSlackCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection      services.ConnectionInterface
+			Options         services.Options
+			Client          string
+			UAAHost         string
+			VCAPRequestID   string
+			RequestReceived time.Time
+		}
+		Returns struct {
+			Response services.Response
+			Err      error
+		}
+	}
 }
 
 func NewEnqueuer() *Enqueuer {
@@ -59,3 +75,15 @@ func (m *Enqueuer) Enqueue(
 	m.EnqueueCall.WasCalled = true
 	return m.EnqueueCall.Returns.Responses, m.EnqueueCall.Returns.Err
 }
+
+func (m *Enqueuer) EnqueueSlack(conn services.ConnectionInterface, options services.Options, client, uaaHost, vcapRequestID string, reqReceived time.Time) (services.Response, error) {
+	m.EnqueueSlackCall.Receives.Connection = conn
+	m.EnqueueSlackCall.Receives.Options = options
+	m.EnqueueSlackCall.Receives.Client = client
+	m.EnqueueSlackCall.Receives.UAAHost = uaaHost
+	m.EnqueueSlackCall.Receives.VCAPRequestID = vcapRequestID
+	m.EnqueueSlackCall.Receives.RequestReceived = reqReceived
+
+	m.EnqueueSlackCall.WasCalled = true
+	return m.EnqueueSlackCall.Returns.Response, m.EnqueueSlackCall.Returns.Err
+}
@@ -0,0 +1,29 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/gobble"
+
+type FastLane struct {
+	SubmitCall struct {
+		Receives struct {
+			Jobs []*gobble.Job
+		}
+		Returns struct {
+			Accepted bool
+		}
+		Hook func()
+	}
+}
+
+func NewFastLane() *FastLane {
+	return &FastLane{}
+}
+
+func (l *FastLane) Submit(job *gobble.Job) bool {
+	l.SubmitCall.Receives.Jobs = append(l.SubmitCall.Receives.Jobs, job)
+
+	if l.SubmitCall.Hook != nil {
+		l.SubmitCall.Hook()
+	}
+
+	return l.SubmitCall.Returns.Accepted
+}
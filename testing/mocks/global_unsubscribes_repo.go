@@ -4,13 +4,15 @@ import "github.com/cloudfoundry-incubator/notifications/v1/models"
 
 type GlobalUnsubscribesRepo struct {
 	GetCall struct {
-		Receives struct {
+		CallCount int
+		Receives  struct {
 			Connection models.ConnectionInterface
 			UserID     string
 		}
 		Returns struct {
-			Unsubscribed bool
-			Error        error
+			Unsubscribed       bool
+			UnsubscribedByCall []bool
+			Error              error
 		}
 	}
 
@@ -34,7 +36,13 @@ func (r *GlobalUnsubscribesRepo) Get(conn models.ConnectionInterface, userID str
 	r.GetCall.Receives.Connection = conn
 	r.GetCall.Receives.UserID = userID
 
-	return r.GetCall.Returns.Unsubscribed, r.GetCall.Returns.Error
+	unsubscribed := r.GetCall.Returns.Unsubscribed
+	if r.GetCall.CallCount < len(r.GetCall.Returns.UnsubscribedByCall) {
+		unsubscribed = r.GetCall.Returns.UnsubscribedByCall[r.GetCall.CallCount]
+	}
+	r.GetCall.CallCount++
+
+	return unsubscribed, r.GetCall.Returns.Error
 }
 
 func (r *GlobalUnsubscribesRepo) Set(conn models.ConnectionInterface, userID string, unsubscribed bool) error {
@@ -0,0 +1,49 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type IdempotencyKeysRepo struct {
+	FindCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			Key        string
+		}
+		Returns struct {
+			IdempotencyKey models.IdempotencyKey
+			Error          error
+		}
+	}
+
+	UpsertCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection     models.ConnectionInterface
+			IdempotencyKey models.IdempotencyKey
+		}
+		Returns struct {
+			IdempotencyKey models.IdempotencyKey
+			Error          error
+		}
+	}
+}
+
+func NewIdempotencyKeysRepo() *IdempotencyKeysRepo {
+	return &IdempotencyKeysRepo{}
+}
+
+func (r *IdempotencyKeysRepo) Find(conn models.ConnectionInterface, clientID, key string) (models.IdempotencyKey, error) {
+	r.FindCall.Receives.Connection = conn
+	r.FindCall.Receives.ClientID = clientID
+	r.FindCall.Receives.Key = key
+
+	return r.FindCall.Returns.IdempotencyKey, r.FindCall.Returns.Error
+}
+
+func (r *IdempotencyKeysRepo) Upsert(conn models.ConnectionInterface, idempotencyKey models.IdempotencyKey) (models.IdempotencyKey, error) {
+	r.UpsertCall.WasCalled = true
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.IdempotencyKey = idempotencyKey
+
+	return r.UpsertCall.Returns.IdempotencyKey, r.UpsertCall.Returns.Error
+}
@@ -0,0 +1,68 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type InstanceHeartbeatsRepo struct {
+	UpsertCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Heartbeat  models.InstanceHeartbeat
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FindAllCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+		}
+		Returns struct {
+			Heartbeats []models.InstanceHeartbeat
+			Error      error
+		}
+	}
+
+	DeleteBeforeCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Before     time.Time
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewInstanceHeartbeatsRepo() *InstanceHeartbeatsRepo {
+	return &InstanceHeartbeatsRepo{}
+}
+
+func (r *InstanceHeartbeatsRepo) Upsert(conn models.ConnectionInterface, heartbeat models.InstanceHeartbeat) error {
+	r.UpsertCall.CallCount++
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.Heartbeat = heartbeat
+
+	return r.UpsertCall.Returns.Error
+}
+
+func (r *InstanceHeartbeatsRepo) FindAll(conn models.ConnectionInterface) ([]models.InstanceHeartbeat, error) {
+	r.FindAllCall.Receives.Connection = conn
+
+	return r.FindAllCall.Returns.Heartbeats, r.FindAllCall.Returns.Error
+}
+
+func (r *InstanceHeartbeatsRepo) DeleteBefore(conn models.ConnectionInterface, before time.Time) (int, error) {
+	r.DeleteBeforeCall.WasCalled = true
+	r.DeleteBeforeCall.Receives.Connection = conn
+	r.DeleteBeforeCall.Receives.Before = before
+
+	return r.DeleteBeforeCall.Returns.Count, r.DeleteBeforeCall.Returns.Error
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type JobReprioritizer struct {
+	ReprioritizeCall struct {
+		Receives struct {
+			Filter services.ReprioritizeFilter
+			Action string
+			Delay  time.Duration
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewJobReprioritizer() *JobReprioritizer {
+	return &JobReprioritizer{}
+}
+
+func (r *JobReprioritizer) Reprioritize(filter services.ReprioritizeFilter, action string, delay time.Duration) (int, error) {
+	r.ReprioritizeCall.Receives.Filter = filter
+	r.ReprioritizeCall.Receives.Action = action
+	r.ReprioritizeCall.Receives.Delay = delay
+
+	return r.ReprioritizeCall.Returns.Count, r.ReprioritizeCall.Returns.Error
+}
@@ -0,0 +1,62 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type KindActivityRepo struct {
+	IncrementCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+			Counter    string
+			At         time.Time
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+			Start      time.Time
+			End        time.Time
+		}
+		Returns struct {
+			Activity []models.KindActivity
+			Error    error
+		}
+	}
+}
+
+func NewKindActivityRepo() *KindActivityRepo {
+	return &KindActivityRepo{}
+}
+
+func (r *KindActivityRepo) Increment(conn models.ConnectionInterface, clientID, kindID, counter string, at time.Time) error {
+	r.IncrementCall.CallCount++
+	r.IncrementCall.Receives.Connection = conn
+	r.IncrementCall.Receives.ClientID = clientID
+	r.IncrementCall.Receives.KindID = kindID
+	r.IncrementCall.Receives.Counter = counter
+	r.IncrementCall.Receives.At = at
+
+	return r.IncrementCall.Returns.Error
+}
+
+func (r *KindActivityRepo) List(conn models.ConnectionInterface, clientID, kindID string, start, end time.Time) ([]models.KindActivity, error) {
+	r.ListCall.Receives.Connection = conn
+	r.ListCall.Receives.ClientID = clientID
+	r.ListCall.Receives.KindID = kindID
+	r.ListCall.Receives.Start = start
+	r.ListCall.Receives.End = end
+
+	return r.ListCall.Returns.Activity, r.ListCall.Returns.Error
+}
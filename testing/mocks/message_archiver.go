@@ -0,0 +1,28 @@
+package mocks
+
+type MessageArchiver struct {
+	ArchiveCall struct {
+		CallCount int
+		Receives  struct {
+			ClientID  string
+			MessageID string
+			MIME      []byte
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewMessageArchiver() *MessageArchiver {
+	return &MessageArchiver{}
+}
+
+func (a *MessageArchiver) Archive(clientID, messageID string, mime []byte) error {
+	a.ArchiveCall.CallCount++
+	a.ArchiveCall.Receives.ClientID = clientID
+	a.ArchiveCall.Receives.MessageID = messageID
+	a.ArchiveCall.Receives.MIME = mime
+
+	return a.ArchiveCall.Returns.Error
+}
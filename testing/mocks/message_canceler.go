@@ -0,0 +1,26 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/services"
+
+type MessageCanceler struct {
+	CancelCall struct {
+		Receives struct {
+			Database  services.DatabaseInterface
+			MessageID string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewMessageCanceler() *MessageCanceler {
+	return &MessageCanceler{}
+}
+
+func (c *MessageCanceler) Cancel(database services.DatabaseInterface, messageID string) error {
+	c.CancelCall.Receives.Database = database
+	c.CancelCall.Receives.MessageID = messageID
+
+	return c.CancelCall.Returns.Error
+}
@@ -0,0 +1,59 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type MessageLister struct {
+	ListCall struct {
+		Receives struct {
+			Database services.DatabaseInterface
+			Filter   models.MessageFilter
+			Page     int
+			PerPage  int
+		}
+		Returns struct {
+			MessageList services.MessageList
+			Error       error
+		}
+	}
+
+	EachCall struct {
+		Receives struct {
+			Database services.DatabaseInterface
+			Filter   models.MessageFilter
+		}
+		Returns struct {
+			Messages []services.Message
+			Error    error
+		}
+	}
+}
+
+func NewMessageLister() *MessageLister {
+	return &MessageLister{}
+}
+
+func (l *MessageLister) List(database services.DatabaseInterface, filter models.MessageFilter, page, perPage int) (services.MessageList, error) {
+	l.ListCall.Receives.Database = database
+	l.ListCall.Receives.Filter = filter
+	l.ListCall.Receives.Page = page
+	l.ListCall.Receives.PerPage = perPage
+
+	return l.ListCall.Returns.MessageList, l.ListCall.Returns.Error
+}
+
+func (l *MessageLister) Each(database services.DatabaseInterface, filter models.MessageFilter, fn func(services.Message) error) error {
+	l.EachCall.Receives.Database = database
+	l.EachCall.Receives.Filter = filter
+
+	for _, message := range l.EachCall.Returns.Messages {
+		err := fn(message)
+		if err != nil {
+			return err
+		}
+	}
+
+	return l.EachCall.Returns.Error
+}
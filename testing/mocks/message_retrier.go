@@ -0,0 +1,26 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/services"
+
+type MessageRetrier struct {
+	RetryCall struct {
+		Receives struct {
+			Database  services.DatabaseInterface
+			MessageID string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewMessageRetrier() *MessageRetrier {
+	return &MessageRetrier{}
+}
+
+func (r *MessageRetrier) Retry(database services.DatabaseInterface, messageID string) error {
+	r.RetryCall.Receives.Database = database
+	r.RetryCall.Receives.MessageID = messageID
+
+	return r.RetryCall.Returns.Error
+}
@@ -1,7 +1,10 @@
 package mocks
 
 import (
+	"time"
+
 	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/pivotal-golang/lager"
 )
 
@@ -11,7 +14,23 @@ type MessageStatusUpdater struct {
 			Connection    db.ConnectionInterface
 			MessageID     string
 			MessageStatus string
+			Reason        string
 			CampaignID    string
+			WorkerID      string
+			ClaimedAt     time.Time
+			Logger        lager.Logger
+		}
+	}
+
+	UpdateBounceCall struct {
+		CallCount int
+		Receives  struct {
+			Connection    db.ConnectionInterface
+			MessageID     string
+			MessageStatus string
+			Reply         common.SMTPReply
+			WorkerID      string
+			ClaimedAt     time.Time
 			Logger        lager.Logger
 		}
 	}
@@ -21,10 +40,24 @@ func NewMessageStatusUpdater() *MessageStatusUpdater {
 	return &MessageStatusUpdater{}
 }
 
-func (msu *MessageStatusUpdater) Update(conn db.ConnectionInterface, messageID, messageStatus, campaignID string, logger lager.Logger) {
+func (msu *MessageStatusUpdater) Update(conn db.ConnectionInterface, messageID, messageStatus, reason, campaignID, workerID string, claimedAt time.Time, logger lager.Logger) {
 	msu.UpdateCall.Receives.Connection = conn
 	msu.UpdateCall.Receives.MessageID = messageID
 	msu.UpdateCall.Receives.MessageStatus = messageStatus
+	msu.UpdateCall.Receives.Reason = reason
 	msu.UpdateCall.Receives.CampaignID = campaignID
+	msu.UpdateCall.Receives.WorkerID = workerID
+	msu.UpdateCall.Receives.ClaimedAt = claimedAt
 	msu.UpdateCall.Receives.Logger = logger
 }
+
+func (msu *MessageStatusUpdater) UpdateBounce(conn db.ConnectionInterface, messageID, messageStatus string, reply common.SMTPReply, workerID string, claimedAt time.Time, logger lager.Logger) {
+	msu.UpdateBounceCall.CallCount++
+	msu.UpdateBounceCall.Receives.Connection = conn
+	msu.UpdateBounceCall.Receives.MessageID = messageID
+	msu.UpdateBounceCall.Receives.MessageStatus = messageStatus
+	msu.UpdateBounceCall.Receives.Reply = reply
+	msu.UpdateBounceCall.Receives.WorkerID = workerID
+	msu.UpdateBounceCall.Receives.ClaimedAt = claimedAt
+	msu.UpdateBounceCall.Receives.Logger = logger
+}
@@ -41,15 +41,63 @@ type MessagesRepo struct {
 		}
 	}
 
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Filter     models.MessageFilter
+			Offset     int
+			Limit      int
+		}
+		Returns struct {
+			Messages []models.Message
+			Error    error
+		}
+	}
+
+	CountCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Filter     models.MessageFilter
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+
+	FindQueuedBeforeCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Threshold  time.Time
+		}
+		Returns struct {
+			Messages []models.Message
+			Error    error
+		}
+	}
+
+	RecordEngagementCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			MessageID  string
+			Event      string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
 	DeleteBeforeCall struct {
 		InvocationTimes []time.Time
 		CallCount       int
 		Receives        struct {
 			Connection    models.ConnectionInterface
 			ThresholdTime time.Time
+			Limit         int
 		}
 		Returns struct {
-			RowsAffected int
+			RowsAffected []int
 			Error        error
 		}
 	}
@@ -83,11 +131,49 @@ func (mr *MessagesRepo) FindByID(conn models.ConnectionInterface, messageID stri
 	return mr.FindByIDCall.Returns.Message, mr.FindByIDCall.Returns.Error
 }
 
-func (mr *MessagesRepo) DeleteBefore(conn models.ConnectionInterface, thresholdTime time.Time) (int, error) {
+func (mr *MessagesRepo) DeleteBefore(conn models.ConnectionInterface, thresholdTime time.Time, limit int) (int, error) {
 	mr.DeleteBeforeCall.Receives.Connection = conn
 	mr.DeleteBeforeCall.Receives.ThresholdTime = thresholdTime
+	mr.DeleteBeforeCall.Receives.Limit = limit
 	mr.DeleteBeforeCall.InvocationTimes = append(mr.DeleteBeforeCall.InvocationTimes, time.Now())
+
+	var rowsAffected int
+	if mr.DeleteBeforeCall.CallCount < len(mr.DeleteBeforeCall.Returns.RowsAffected) {
+		rowsAffected = mr.DeleteBeforeCall.Returns.RowsAffected[mr.DeleteBeforeCall.CallCount]
+	}
 	mr.DeleteBeforeCall.CallCount++
 
-	return mr.DeleteBeforeCall.Returns.RowsAffected, mr.DeleteBeforeCall.Returns.Error
+	return rowsAffected, mr.DeleteBeforeCall.Returns.Error
+}
+
+func (mr *MessagesRepo) List(conn models.ConnectionInterface, filter models.MessageFilter, offset, limit int) ([]models.Message, error) {
+	mr.ListCall.Receives.Connection = conn
+	mr.ListCall.Receives.Filter = filter
+	mr.ListCall.Receives.Offset = offset
+	mr.ListCall.Receives.Limit = limit
+
+	return mr.ListCall.Returns.Messages, mr.ListCall.Returns.Error
+}
+
+func (mr *MessagesRepo) Count(conn models.ConnectionInterface, filter models.MessageFilter) (int, error) {
+	mr.CountCall.Receives.Connection = conn
+	mr.CountCall.Receives.Filter = filter
+
+	return mr.CountCall.Returns.Count, mr.CountCall.Returns.Error
+}
+
+func (mr *MessagesRepo) FindQueuedBefore(conn models.ConnectionInterface, threshold time.Time) ([]models.Message, error) {
+	mr.FindQueuedBeforeCall.Receives.Connection = conn
+	mr.FindQueuedBeforeCall.Receives.Threshold = threshold
+
+	return mr.FindQueuedBeforeCall.Returns.Messages, mr.FindQueuedBeforeCall.Returns.Error
+}
+
+func (mr *MessagesRepo) RecordEngagement(conn models.ConnectionInterface, messageID, event string) error {
+	mr.RecordEngagementCall.CallCount++
+	mr.RecordEngagementCall.Receives.Connection = conn
+	mr.RecordEngagementCall.Receives.MessageID = messageID
+	mr.RecordEngagementCall.Receives.Event = event
+
+	return mr.RecordEngagementCall.Returns.Error
 }
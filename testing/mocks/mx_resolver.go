@@ -0,0 +1,40 @@
+package mocks
+
+import (
+	"context"
+	"net"
+)
+
+type MXResolver struct {
+	LookupMXCall struct {
+		Receives struct {
+			Name string
+		}
+		Returns struct {
+			Records []*net.MX
+			Error   error
+		}
+	}
+
+	LookupHostCall struct {
+		CallCount int
+		Receives  struct {
+			Host string
+		}
+		Returns struct {
+			Addresses []string
+			Error     error
+		}
+	}
+}
+
+func (r *MXResolver) LookupMX(ctx context.Context, name string) ([]*net.MX, error) {
+	r.LookupMXCall.Receives.Name = name
+	return r.LookupMXCall.Returns.Records, r.LookupMXCall.Returns.Error
+}
+
+func (r *MXResolver) LookupHost(ctx context.Context, host string) ([]string, error) {
+	r.LookupHostCall.CallCount++
+	r.LookupHostCall.Receives.Host = host
+	return r.LookupHostCall.Returns.Addresses, r.LookupHostCall.Returns.Error
+}
@@ -0,0 +1,26 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/services"
+
+type OneClickUnsubscriber struct {
+	UnsubscribeCall struct {
+		Receives struct {
+			Connection services.ConnectionInterface
+			Token      string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewOneClickUnsubscriber() *OneClickUnsubscriber {
+	return &OneClickUnsubscriber{}
+}
+
+func (u *OneClickUnsubscriber) Unsubscribe(conn services.ConnectionInterface, token string) error {
+	u.UnsubscribeCall.Receives.Connection = conn
+	u.UnsubscribeCall.Receives.Token = token
+
+	return u.UnsubscribeCall.Returns.Error
+}
@@ -0,0 +1,47 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type OrganizationPoliciesRepo struct {
+	FindCall struct {
+		Receives struct {
+			Connection       models.ConnectionInterface
+			OrganizationGUID string
+		}
+		Returns struct {
+			Policy models.OrganizationPolicy
+			Error  error
+		}
+	}
+
+	UpsertCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Policy     models.OrganizationPolicy
+		}
+		Returns struct {
+			Policy models.OrganizationPolicy
+			Error  error
+		}
+	}
+}
+
+func NewOrganizationPoliciesRepo() *OrganizationPoliciesRepo {
+	return &OrganizationPoliciesRepo{}
+}
+
+func (r *OrganizationPoliciesRepo) Find(conn models.ConnectionInterface, orgGUID string) (models.OrganizationPolicy, error) {
+	r.FindCall.Receives.Connection = conn
+	r.FindCall.Receives.OrganizationGUID = orgGUID
+
+	return r.FindCall.Returns.Policy, r.FindCall.Returns.Error
+}
+
+func (r *OrganizationPoliciesRepo) Upsert(conn models.ConnectionInterface, policy models.OrganizationPolicy) (models.OrganizationPolicy, error) {
+	r.UpsertCall.WasCalled = true
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.Policy = policy
+
+	return r.UpsertCall.Returns.Policy, r.UpsertCall.Returns.Error
+}
@@ -0,0 +1,74 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type PayloadSamplesRepo struct {
+	CreateCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Sample     models.PayloadSample
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FindRecentCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+			KindID     string
+			Limit      int
+		}
+		Returns struct {
+			Samples []models.PayloadSample
+			Error   error
+		}
+	}
+
+	DeleteBeforeCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Threshold  time.Time
+			Limit      int
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewPayloadSamplesRepo() *PayloadSamplesRepo {
+	return &PayloadSamplesRepo{}
+}
+
+func (r *PayloadSamplesRepo) Create(conn models.ConnectionInterface, sample models.PayloadSample) error {
+	r.CreateCall.WasCalled = true
+	r.CreateCall.Receives.Connection = conn
+	r.CreateCall.Receives.Sample = sample
+
+	return r.CreateCall.Returns.Error
+}
+
+func (r *PayloadSamplesRepo) FindRecent(conn models.ConnectionInterface, clientID, kindID string, limit int) ([]models.PayloadSample, error) {
+	r.FindRecentCall.Receives.Connection = conn
+	r.FindRecentCall.Receives.ClientID = clientID
+	r.FindRecentCall.Receives.KindID = kindID
+	r.FindRecentCall.Receives.Limit = limit
+
+	return r.FindRecentCall.Returns.Samples, r.FindRecentCall.Returns.Error
+}
+
+func (r *PayloadSamplesRepo) DeleteBefore(conn models.ConnectionInterface, threshold time.Time, limit int) (int, error) {
+	r.DeleteBeforeCall.Receives.Connection = conn
+	r.DeleteBeforeCall.Receives.Threshold = threshold
+	r.DeleteBeforeCall.Receives.Limit = limit
+
+	return r.DeleteBeforeCall.Returns.Count, r.DeleteBeforeCall.Returns.Error
+}
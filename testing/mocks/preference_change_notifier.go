@@ -0,0 +1,60 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/services"
+
+type PreferenceChangeNotifier struct {
+	NotifyCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection services.ConnectionInterface
+			Change     services.PreferenceChange
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	VerifyCall struct {
+		Receives struct {
+			Token string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	RevertCall struct {
+		Receives struct {
+			Connection services.ConnectionInterface
+			Token      string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewPreferenceChangeNotifier() *PreferenceChangeNotifier {
+	return &PreferenceChangeNotifier{}
+}
+
+func (n *PreferenceChangeNotifier) Notify(conn services.ConnectionInterface, change services.PreferenceChange) error {
+	n.NotifyCall.WasCalled = true
+	n.NotifyCall.Receives.Connection = conn
+	n.NotifyCall.Receives.Change = change
+
+	return n.NotifyCall.Returns.Error
+}
+
+func (n *PreferenceChangeNotifier) Verify(token string) error {
+	n.VerifyCall.Receives.Token = token
+
+	return n.VerifyCall.Returns.Error
+}
+
+func (n *PreferenceChangeNotifier) Revert(conn services.ConnectionInterface, token string) error {
+	n.RevertCall.Receives.Connection = conn
+	n.RevertCall.Receives.Token = token
+
+	return n.RevertCall.Returns.Error
+}
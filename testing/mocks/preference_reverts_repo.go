@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type PreferenceRevertsRepo struct {
+	ClaimCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Revert     models.PreferenceRevert
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DeleteBeforeCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Threshold  time.Time
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewPreferenceRevertsRepo() *PreferenceRevertsRepo {
+	return &PreferenceRevertsRepo{}
+}
+
+func (r *PreferenceRevertsRepo) Claim(conn models.ConnectionInterface, revert models.PreferenceRevert) error {
+	r.ClaimCall.WasCalled = true
+	r.ClaimCall.Receives.Connection = conn
+	r.ClaimCall.Receives.Revert = revert
+
+	return r.ClaimCall.Returns.Error
+}
+
+func (r *PreferenceRevertsRepo) DeleteBefore(conn models.ConnectionInterface, threshold time.Time) (int, error) {
+	r.DeleteBeforeCall.WasCalled = true
+	r.DeleteBeforeCall.Receives.Connection = conn
+	r.DeleteBeforeCall.Receives.Threshold = threshold
+
+	return r.DeleteBeforeCall.Returns.Count, r.DeleteBeforeCall.Returns.Error
+}
@@ -17,6 +17,30 @@ type PreferenceUpdater struct {
 			Error error
 		}
 	}
+
+	SetDigestCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection services.ConnectionInterface
+			UserID     string
+			Frequency  string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	SetSeveritiesCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection services.ConnectionInterface
+			UserID     string
+			Severities map[string]bool
+		}
+		Returns struct {
+			Error error
+		}
+	}
 }
 
 func NewPreferenceUpdater() *PreferenceUpdater {
@@ -31,3 +55,21 @@ func (pu *PreferenceUpdater) Update(conn services.ConnectionInterface, preferenc
 
 	return pu.UpdateCall.Returns.Error
 }
+
+func (pu *PreferenceUpdater) SetDigest(conn services.ConnectionInterface, userID, frequency string) error {
+	pu.SetDigestCall.WasCalled = true
+	pu.SetDigestCall.Receives.Connection = conn
+	pu.SetDigestCall.Receives.UserID = userID
+	pu.SetDigestCall.Receives.Frequency = frequency
+
+	return pu.SetDigestCall.Returns.Error
+}
+
+func (pu *PreferenceUpdater) SetSeverities(conn services.ConnectionInterface, userID string, severities map[string]bool) error {
+	pu.SetSeveritiesCall.WasCalled = true
+	pu.SetSeveritiesCall.Receives.Connection = conn
+	pu.SetSeveritiesCall.Receives.UserID = userID
+	pu.SetSeveritiesCall.Receives.Severities = severities
+
+	return pu.SetSeveritiesCall.Returns.Error
+}
@@ -0,0 +1,66 @@
+package mocks
+
+import "time"
+
+type PreferencesCache struct {
+	GetCall struct {
+		Receives struct {
+			Key string
+		}
+		Returns struct {
+			Value string
+			Found bool
+			Error error
+		}
+	}
+
+	SetCall struct {
+		WasCalled bool
+		Receives  struct {
+			Key   string
+			Value string
+			TTL   time.Duration
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DelCall struct {
+		WasCalled bool
+		CallCount int
+		Receives  struct {
+			Key string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewPreferencesCache() *PreferencesCache {
+	return &PreferencesCache{}
+}
+
+func (c *PreferencesCache) Get(key string) (string, bool, error) {
+	c.GetCall.Receives.Key = key
+
+	return c.GetCall.Returns.Value, c.GetCall.Returns.Found, c.GetCall.Returns.Error
+}
+
+func (c *PreferencesCache) Set(key, value string, ttl time.Duration) error {
+	c.SetCall.WasCalled = true
+	c.SetCall.Receives.Key = key
+	c.SetCall.Receives.Value = value
+	c.SetCall.Receives.TTL = ttl
+
+	return c.SetCall.Returns.Error
+}
+
+func (c *PreferencesCache) Del(key string) error {
+	c.DelCall.WasCalled = true
+	c.DelCall.CallCount++
+	c.DelCall.Receives.Key = key
+
+	return c.DelCall.Returns.Error
+}
@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type PreferencesPorter struct {
+	ExportCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserID     string
+		}
+		Returns struct {
+			Export services.PreferencesExport
+			Error  error
+		}
+	}
+
+	ExportAllCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			After      string
+			Limit      int
+		}
+		Returns struct {
+			Exports []services.PreferencesExport
+			Error   error
+		}
+	}
+
+	ImportCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Exports    []services.PreferencesExport
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewPreferencesPorter() *PreferencesPorter {
+	return &PreferencesPorter{}
+}
+
+func (p *PreferencesPorter) Export(conn models.ConnectionInterface, userID string) (services.PreferencesExport, error) {
+	p.ExportCall.Receives.Connection = conn
+	p.ExportCall.Receives.UserID = userID
+
+	return p.ExportCall.Returns.Export, p.ExportCall.Returns.Error
+}
+
+func (p *PreferencesPorter) ExportAll(conn models.ConnectionInterface, after string, limit int) ([]services.PreferencesExport, error) {
+	p.ExportAllCall.Receives.Connection = conn
+	p.ExportAllCall.Receives.After = after
+	p.ExportAllCall.Receives.Limit = limit
+
+	return p.ExportAllCall.Returns.Exports, p.ExportAllCall.Returns.Error
+}
+
+func (p *PreferencesPorter) Import(conn models.ConnectionInterface, exports []services.PreferencesExport) error {
+	p.ImportCall.WasCalled = true
+	p.ImportCall.Receives.Connection = conn
+	p.ImportCall.Receives.Exports = exports
+
+	return p.ImportCall.Returns.Error
+}
@@ -13,6 +13,29 @@ type PreferencesRepo struct {
 			Error       error
 		}
 	}
+
+	FindStoredCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+		}
+		Returns struct {
+			Stored models.StoredPreferences
+			Error  error
+		}
+	}
+
+	FindUserIDsCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			After      string
+			Limit      int
+		}
+		Returns struct {
+			UserIDs []string
+			Error   error
+		}
+	}
 }
 
 func NewPreferencesRepo() *PreferencesRepo {
@@ -25,3 +48,18 @@ func (pr *PreferencesRepo) FindNonCriticalPreferences(conn models.ConnectionInte
 
 	return pr.FindNonCriticalPreferencesCall.Returns.Preferences, pr.FindNonCriticalPreferencesCall.Returns.Error
 }
+
+func (pr *PreferencesRepo) FindStored(conn models.ConnectionInterface, userGUID string) (models.StoredPreferences, error) {
+	pr.FindStoredCall.Receives.Connection = conn
+	pr.FindStoredCall.Receives.UserGUID = userGUID
+
+	return pr.FindStoredCall.Returns.Stored, pr.FindStoredCall.Returns.Error
+}
+
+func (pr *PreferencesRepo) FindUserIDs(conn models.ConnectionInterface, after string, limit int) ([]string, error) {
+	pr.FindUserIDsCall.Receives.Connection = conn
+	pr.FindUserIDsCall.Receives.After = after
+	pr.FindUserIDsCall.Receives.Limit = limit
+
+	return pr.FindUserIDsCall.Returns.UserIDs, pr.FindUserIDsCall.Returns.Error
+}
@@ -1,6 +1,10 @@
 package mocks
 
-import "github.com/cloudfoundry-incubator/notifications/gobble"
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+)
 
 type Queue struct {
 	EnqueueCall struct {
@@ -16,6 +20,12 @@ type Queue struct {
 		Hook func()
 	}
 
+	TransactionalCall struct {
+		Returns struct {
+			Transactional bool
+		}
+	}
+
 	RequeueCall struct {
 		Receives struct {
 			Job *gobble.Job
@@ -44,6 +54,60 @@ type Queue struct {
 		}
 	}
 
+	PendingCall struct {
+		Receives struct {
+			AfterIDs []int
+			Limit    int
+		}
+		Returns struct {
+			Jobs  []gobble.Job
+			Error error
+		}
+	}
+
+	JobsCall struct {
+		Returns struct {
+			Jobs  []gobble.Job
+			Error error
+		}
+	}
+
+	RescheduleCall struct {
+		Receives struct {
+			Jobs      []*gobble.Job
+			ActiveAts []time.Time
+		}
+		Returns struct {
+			Errors []error
+		}
+	}
+
+	StatsCall struct {
+		Returns struct {
+			Stats gobble.QueueStats
+			Error error
+		}
+	}
+
+	OldestByPriorityCall struct {
+		Returns struct {
+			Oldest map[int]time.Time
+			Error  error
+		}
+	}
+
+	EscalateCall struct {
+		Receives struct {
+			Priorities    []int
+			ActiveBefores []time.Time
+			Tos           []int
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+
 	RetryQueueLengthsCall struct {
 		Returns struct {
 			Lengths map[int]int
@@ -53,7 +117,14 @@ type Queue struct {
 }
 
 func NewQueue() *Queue {
-	return &Queue{}
+	queue := &Queue{}
+	queue.TransactionalCall.Returns.Transactional = true
+
+	return queue
+}
+
+func (q *Queue) Transactional() bool {
+	return q.TransactionalCall.Returns.Transactional
 }
 
 func (q *Queue) Enqueue(job *gobble.Job, connection gobble.ConnectionInterface) (*gobble.Job, error) {
@@ -85,6 +156,53 @@ func (q *Queue) Reserve(id string) <-chan *gobble.Job {
 	return q.ReserveCall.Returns.Chan
 }
 
+func (q *Queue) Stats() (gobble.QueueStats, error) {
+	return q.StatsCall.Returns.Stats, q.StatsCall.Returns.Error
+}
+
+func (q *Queue) OldestByPriority() (map[int]time.Time, error) {
+	return q.OldestByPriorityCall.Returns.Oldest, q.OldestByPriorityCall.Returns.Error
+}
+
+func (q *Queue) Escalate(priority int, activeBefore time.Time, to int) (int, error) {
+	q.EscalateCall.Receives.Priorities = append(q.EscalateCall.Receives.Priorities, priority)
+	q.EscalateCall.Receives.ActiveBefores = append(q.EscalateCall.Receives.ActiveBefores, activeBefore)
+	q.EscalateCall.Receives.Tos = append(q.EscalateCall.Receives.Tos, to)
+
+	return q.EscalateCall.Returns.Count, q.EscalateCall.Returns.Error
+}
+
+// Pending pages through PendingCall.Returns.Jobs as the queue would, by ID.
+func (q *Queue) Pending(afterID, limit int) ([]gobble.Job, error) {
+	q.PendingCall.Receives.AfterIDs = append(q.PendingCall.Receives.AfterIDs, afterID)
+	q.PendingCall.Receives.Limit = limit
+
+	var page []gobble.Job
+	for _, job := range q.PendingCall.Returns.Jobs {
+		if job.ID > afterID && len(page) < limit {
+			page = append(page, job)
+		}
+	}
+
+	return page, q.PendingCall.Returns.Error
+}
+
+func (q *Queue) Jobs() ([]gobble.Job, error) {
+	return q.JobsCall.Returns.Jobs, q.JobsCall.Returns.Error
+}
+
+func (q *Queue) Reschedule(job *gobble.Job, activeAt time.Time) error {
+	call := len(q.RescheduleCall.Receives.Jobs)
+	q.RescheduleCall.Receives.Jobs = append(q.RescheduleCall.Receives.Jobs, job)
+	q.RescheduleCall.Receives.ActiveAts = append(q.RescheduleCall.Receives.ActiveAts, activeAt)
+
+	if call < len(q.RescheduleCall.Returns.Errors) {
+		return q.RescheduleCall.Returns.Errors[call]
+	}
+
+	return nil
+}
+
 func (q *Queue) RetryQueueLengths() (map[int]int, error) {
 	return q.RetryQueueLengthsCall.Returns.Lengths, q.RetryQueueLengthsCall.Returns.Error
 }
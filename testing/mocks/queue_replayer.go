@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type QueueReplayer struct {
+	ReplayCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			DryRun     bool
+		}
+		Returns struct {
+			Report services.QueueReplayReport
+			Error  error
+		}
+	}
+}
+
+func NewQueueReplayer() *QueueReplayer {
+	return &QueueReplayer{}
+}
+
+func (r *QueueReplayer) Replay(conn models.ConnectionInterface, dryRun bool) (services.QueueReplayReport, error) {
+	r.ReplayCall.Receives.Connection = conn
+	r.ReplayCall.Receives.DryRun = dryRun
+
+	return r.ReplayCall.Returns.Report, r.ReplayCall.Returns.Error
+}
@@ -14,6 +14,19 @@ type ReceiptsRepo struct {
 			Error error
 		}
 	}
+
+	FindCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Receipt models.Receipt
+			Error   error
+		}
+	}
 }
 
 func NewReceiptsRepo() *ReceiptsRepo {
@@ -28,3 +41,12 @@ func (rr *ReceiptsRepo) CreateReceipts(conn models.ConnectionInterface, userGUID
 
 	return rr.CreateReceiptsCall.Returns.Error
 }
+
+func (rr *ReceiptsRepo) Find(conn models.ConnectionInterface, userGUID, clientID, kindID string) (models.Receipt, error) {
+	rr.FindCall.Receives.Connection = conn
+	rr.FindCall.Receives.UserGUID = userGUID
+	rr.FindCall.Receives.ClientID = clientID
+	rr.FindCall.Receives.KindID = kindID
+
+	return rr.FindCall.Returns.Receipt, rr.FindCall.Returns.Error
+}
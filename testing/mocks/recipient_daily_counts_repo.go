@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type RecipientDailyCountsRepo struct {
+	TakeCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			UserGUID   string
+			Limit      int
+			At         time.Time
+		}
+		Returns struct {
+			OK    bool
+			Error error
+		}
+	}
+}
+
+func NewRecipientDailyCountsRepo() *RecipientDailyCountsRepo {
+	return &RecipientDailyCountsRepo{}
+}
+
+func (r *RecipientDailyCountsRepo) Take(conn models.ConnectionInterface, userGUID string, limit int, at time.Time) (bool, error) {
+	r.TakeCall.CallCount++
+	r.TakeCall.Receives.Connection = conn
+	r.TakeCall.Receives.UserGUID = userGUID
+	r.TakeCall.Receives.Limit = limit
+	r.TakeCall.Receives.At = at
+
+	return r.TakeCall.Returns.OK, r.TakeCall.Returns.Error
+}
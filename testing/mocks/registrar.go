@@ -17,6 +17,17 @@ type Registrar struct {
 		}
 	}
 
+	RefreshCall struct {
+		Receives struct {
+			Connection services.ConnectionInterface
+			Client     models.Client
+			Kinds      []models.Kind
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
 	PruneCall struct {
 		Called   bool
 		Receives struct {
@@ -42,6 +53,14 @@ func (r *Registrar) Register(conn services.ConnectionInterface, client models.Cl
 	return r.RegisterCall.Returns.Error
 }
 
+func (r *Registrar) Refresh(conn services.ConnectionInterface, client models.Client, kinds []models.Kind) error {
+	r.RefreshCall.Receives.Connection = conn
+	r.RefreshCall.Receives.Client = client
+	r.RefreshCall.Receives.Kinds = kinds
+
+	return r.RefreshCall.Returns.Error
+}
+
 func (r *Registrar) Prune(conn services.ConnectionInterface, client models.Client, kinds []models.Kind) error {
 	r.PruneCall.Called = true
 	r.PruneCall.Receives.Connection = conn
@@ -0,0 +1,51 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type RegistrationAuditor struct {
+	SnapshotCall struct {
+		Receives struct {
+			Connection services.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Snapshot services.RegistrationSnapshot
+			Error    error
+		}
+	}
+
+	ReportCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection services.ConnectionInterface
+			Snapshot   services.RegistrationSnapshot
+			Actor      common.RegistrationActor
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewRegistrationAuditor() *RegistrationAuditor {
+	return &RegistrationAuditor{}
+}
+
+func (a *RegistrationAuditor) Snapshot(conn services.ConnectionInterface, clientID string) (services.RegistrationSnapshot, error) {
+	a.SnapshotCall.Receives.Connection = conn
+	a.SnapshotCall.Receives.ClientID = clientID
+
+	return a.SnapshotCall.Returns.Snapshot, a.SnapshotCall.Returns.Error
+}
+
+func (a *RegistrationAuditor) Report(conn services.ConnectionInterface, before services.RegistrationSnapshot, actor common.RegistrationActor) error {
+	a.ReportCall.WasCalled = true
+	a.ReportCall.Receives.Connection = conn
+	a.ReportCall.Receives.Snapshot = before
+	a.ReportCall.Receives.Actor = actor
+
+	return a.ReportCall.Returns.Error
+}
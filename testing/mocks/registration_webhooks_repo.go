@@ -0,0 +1,64 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type RegistrationWebhooksRepo struct {
+	FindCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Webhook models.RegistrationWebhook
+			Error   error
+		}
+	}
+
+	UpsertCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Webhook    models.RegistrationWebhook
+		}
+		Returns struct {
+			Webhook models.RegistrationWebhook
+			Error   error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			ClientID   string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewRegistrationWebhooksRepo() *RegistrationWebhooksRepo {
+	return &RegistrationWebhooksRepo{}
+}
+
+func (r *RegistrationWebhooksRepo) Find(conn models.ConnectionInterface, clientID string) (models.RegistrationWebhook, error) {
+	r.FindCall.CallCount++
+	r.FindCall.Receives.Connection = conn
+	r.FindCall.Receives.ClientID = clientID
+
+	return r.FindCall.Returns.Webhook, r.FindCall.Returns.Error
+}
+
+func (r *RegistrationWebhooksRepo) Upsert(conn models.ConnectionInterface, webhook models.RegistrationWebhook) (models.RegistrationWebhook, error) {
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.Webhook = webhook
+
+	return r.UpsertCall.Returns.Webhook, r.UpsertCall.Returns.Error
+}
+
+func (r *RegistrationWebhooksRepo) Delete(conn models.ConnectionInterface, clientID string) error {
+	r.DeleteCall.Receives.Connection = conn
+	r.DeleteCall.Receives.ClientID = clientID
+
+	return r.DeleteCall.Returns.Error
+}
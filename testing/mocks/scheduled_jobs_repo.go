@@ -0,0 +1,88 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type ScheduledJobsRepo struct {
+	UpsertCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Jobs       []models.ScheduledJob
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	StartCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Names      []string
+			Instance   string
+			StartedAt  time.Time
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FinishCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Names      []string
+			FinishedAt time.Time
+			LastErrors []string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FindAllCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+		}
+		Returns struct {
+			Jobs  []models.ScheduledJob
+			Error error
+		}
+	}
+}
+
+func NewScheduledJobsRepo() *ScheduledJobsRepo {
+	return &ScheduledJobsRepo{}
+}
+
+func (r *ScheduledJobsRepo) Upsert(conn models.ConnectionInterface, job models.ScheduledJob) error {
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.Jobs = append(r.UpsertCall.Receives.Jobs, job)
+
+	return r.UpsertCall.Returns.Error
+}
+
+func (r *ScheduledJobsRepo) Start(conn models.ConnectionInterface, name, instance string, startedAt time.Time) error {
+	r.StartCall.Receives.Connection = conn
+	r.StartCall.Receives.Names = append(r.StartCall.Receives.Names, name)
+	r.StartCall.Receives.Instance = instance
+	r.StartCall.Receives.StartedAt = startedAt
+
+	return r.StartCall.Returns.Error
+}
+
+func (r *ScheduledJobsRepo) Finish(conn models.ConnectionInterface, name string, finishedAt time.Time, lastError string) error {
+	r.FinishCall.Receives.Connection = conn
+	r.FinishCall.Receives.Names = append(r.FinishCall.Receives.Names, name)
+	r.FinishCall.Receives.FinishedAt = finishedAt
+	r.FinishCall.Receives.LastErrors = append(r.FinishCall.Receives.LastErrors, lastError)
+
+	return r.FinishCall.Returns.Error
+}
+
+func (r *ScheduledJobsRepo) FindAll(conn models.ConnectionInterface) ([]models.ScheduledJob, error) {
+	r.FindAllCall.Receives.Connection = conn
+
+	return r.FindAllCall.Returns.Jobs, r.FindAllCall.Returns.Error
+}
@@ -0,0 +1,57 @@
+package mocks
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type SchedulerLeasesRepo struct {
+	AcquireCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Name       string
+			Holder     string
+			Now        time.Time
+			TTL        time.Duration
+		}
+		Returns struct {
+			Acquired bool
+			Error    error
+		}
+	}
+
+	FindCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Name       string
+		}
+		Returns struct {
+			Lease models.SchedulerLease
+			Error error
+		}
+	}
+}
+
+func NewSchedulerLeasesRepo() *SchedulerLeasesRepo {
+	return &SchedulerLeasesRepo{}
+}
+
+func (r *SchedulerLeasesRepo) Acquire(conn models.ConnectionInterface, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	r.AcquireCall.CallCount++
+	r.AcquireCall.Receives.Connection = conn
+	r.AcquireCall.Receives.Name = name
+	r.AcquireCall.Receives.Holder = holder
+	r.AcquireCall.Receives.Now = now
+	r.AcquireCall.Receives.TTL = ttl
+
+	return r.AcquireCall.Returns.Acquired, r.AcquireCall.Returns.Error
+}
+
+func (r *SchedulerLeasesRepo) Find(conn models.ConnectionInterface, name string) (models.SchedulerLease, error) {
+	r.FindCall.Receives.Connection = conn
+	r.FindCall.Receives.Name = name
+
+	return r.FindCall.Returns.Lease, r.FindCall.Returns.Error
+}
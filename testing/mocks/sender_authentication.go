@@ -0,0 +1,28 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/mail"
+
+type SenderAuthentication struct {
+	CheckCall struct {
+		CallCount int
+		Receives  struct {
+			Domain string
+			Expect mail.SenderExpectations
+		}
+		Returns struct {
+			Report mail.SenderReport
+		}
+	}
+}
+
+func NewSenderAuthentication() *SenderAuthentication {
+	return &SenderAuthentication{}
+}
+
+func (a *SenderAuthentication) Check(domain string, expect mail.SenderExpectations) mail.SenderReport {
+	a.CheckCall.CallCount++
+	a.CheckCall.Receives.Domain = domain
+	a.CheckCall.Receives.Expect = expect
+
+	return a.CheckCall.Returns.Report
+}
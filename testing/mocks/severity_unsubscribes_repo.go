@@ -0,0 +1,72 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type SeverityUnsubscribesRepo struct {
+	GetCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			UserID     string
+			Severity   string
+		}
+		Returns struct {
+			Unsubscribed bool
+			Error        error
+		}
+	}
+
+	SetCall struct {
+		CallCount int
+		Receives  struct {
+			Connection  models.ConnectionInterface
+			UserID      string
+			Severity    string
+			Unsubscribe bool
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	FindAllCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserID     string
+		}
+		Returns struct {
+			Severities []string
+			Error      error
+		}
+	}
+}
+
+func NewSeverityUnsubscribesRepo() *SeverityUnsubscribesRepo {
+	return &SeverityUnsubscribesRepo{}
+}
+
+func (r *SeverityUnsubscribesRepo) Get(conn models.ConnectionInterface, userID, severity string) (bool, error) {
+	r.GetCall.CallCount++
+	r.GetCall.Receives.Connection = conn
+	r.GetCall.Receives.UserID = userID
+	r.GetCall.Receives.Severity = severity
+
+	return r.GetCall.Returns.Unsubscribed, r.GetCall.Returns.Error
+}
+
+func (r *SeverityUnsubscribesRepo) Set(conn models.ConnectionInterface, userID, severity string, unsubscribe bool) error {
+	r.SetCall.CallCount++
+	r.SetCall.Receives.Connection = conn
+	r.SetCall.Receives.UserID = userID
+	r.SetCall.Receives.Severity = severity
+	r.SetCall.Receives.Unsubscribe = unsubscribe
+
+	return r.SetCall.Returns.Error
+}
+
+func (r *SeverityUnsubscribesRepo) FindAll(conn models.ConnectionInterface, userID string) ([]string, error) {
+	r.FindAllCall.Receives.Connection = conn
+	r.FindAllCall.Receives.UserID = userID
+
+	return r.FindAllCall.Returns.Severities, r.FindAllCall.Returns.Error
+}
@@ -0,0 +1,60 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/gobble"
+
+type SLAMonitor struct {
+	StatusCall struct {
+		Returns struct {
+			Statuses []gobble.SLAStatus
+			Error    error
+		}
+	}
+
+	BreachedCall struct {
+		CallCount int
+		Returns   struct {
+			Breached []bool
+			Error    error
+		}
+	}
+
+	EscalateCall struct {
+		CallCount int
+		Returns   struct {
+			Escalations []gobble.Escalation
+			Error       error
+		}
+	}
+}
+
+func NewSLAMonitor() *SLAMonitor {
+	return &SLAMonitor{}
+}
+
+func (m *SLAMonitor) Status() ([]gobble.SLAStatus, error) {
+	return m.StatusCall.Returns.Statuses, m.StatusCall.Returns.Error
+}
+
+// Breached returns the next of the configured answers, repeating the last
+// one once they run out.
+func (m *SLAMonitor) Breached() (bool, error) {
+	call := m.BreachedCall.CallCount
+	m.BreachedCall.CallCount++
+
+	answers := m.BreachedCall.Returns.Breached
+	if len(answers) == 0 {
+		return false, m.BreachedCall.Returns.Error
+	}
+
+	if call >= len(answers) {
+		call = len(answers) - 1
+	}
+
+	return answers[call], m.BreachedCall.Returns.Error
+}
+
+func (m *SLAMonitor) Escalate() ([]gobble.Escalation, error) {
+	m.EscalateCall.CallCount++
+
+	return m.EscalateCall.Returns.Escalations, m.EscalateCall.Returns.Error
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/services"
+
+type Subscriber struct {
+	SubscribeCall struct {
+		Receives struct {
+			Connection services.ConnectionInterface
+			UserID     string
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewSubscriber() *Subscriber {
+	return &Subscriber{}
+}
+
+func (s *Subscriber) Subscribe(conn services.ConnectionInterface, userID, clientID, kindID string) error {
+	s.SubscribeCall.Receives.Connection = conn
+	s.SubscribeCall.Receives.UserID = userID
+	s.SubscribeCall.Receives.ClientID = clientID
+	s.SubscribeCall.Receives.KindID = kindID
+
+	return s.SubscribeCall.Returns.Error
+}
@@ -0,0 +1,54 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type SubscriptionsRepo struct {
+	GetCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserID     string
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			Subscribed bool
+			Error      error
+		}
+	}
+
+	SetCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			UserID     string
+			ClientID   string
+			KindID     string
+			Subscribe  bool
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewSubscriptionsRepo() *SubscriptionsRepo {
+	return &SubscriptionsRepo{}
+}
+
+func (sr *SubscriptionsRepo) Get(conn models.ConnectionInterface, userID, clientID, kindID string) (bool, error) {
+	sr.GetCall.Receives.Connection = conn
+	sr.GetCall.Receives.UserID = userID
+	sr.GetCall.Receives.ClientID = clientID
+	sr.GetCall.Receives.KindID = kindID
+
+	return sr.GetCall.Returns.Subscribed, sr.GetCall.Returns.Error
+}
+
+func (sr *SubscriptionsRepo) Set(conn models.ConnectionInterface, userID, clientID, kindID string, subscribe bool) error {
+	sr.SetCall.Receives.Connection = conn
+	sr.SetCall.Receives.UserID = userID
+	sr.SetCall.Receives.ClientID = clientID
+	sr.SetCall.Receives.KindID = kindID
+	sr.SetCall.Receives.Subscribe = subscribe
+
+	return sr.SetCall.Returns.Error
+}
@@ -0,0 +1,33 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type SuppressionsRepo struct {
+	FindUnsubscribedCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			UserIDs    []string
+			ClientID   string
+			KindID     string
+		}
+		Returns struct {
+			UserIDs []string
+			Error   error
+		}
+	}
+}
+
+func NewSuppressionsRepo() *SuppressionsRepo {
+	return &SuppressionsRepo{}
+}
+
+func (r *SuppressionsRepo) FindUnsubscribed(conn models.ConnectionInterface, userIDs []string, clientID, kindID string) ([]string, error) {
+	r.FindUnsubscribedCall.WasCalled = true
+	r.FindUnsubscribedCall.Receives.Connection = conn
+	r.FindUnsubscribedCall.Receives.UserIDs = userIDs
+	r.FindUnsubscribedCall.Receives.ClientID = clientID
+	r.FindUnsubscribedCall.Receives.KindID = kindID
+
+	return r.FindUnsubscribedCall.Returns.UserIDs, r.FindUnsubscribedCall.Returns.Error
+}
@@ -6,6 +6,7 @@ type TemplateAssigner struct {
 	AssignToClientCall struct {
 		Receives struct {
 			Connection collections.ConnectionInterface
+			ZoneID     string
 			ClientID   string
 			TemplateID string
 		}
@@ -17,6 +18,7 @@ type TemplateAssigner struct {
 	AssignToNotificationCall struct {
 		Receives struct {
 			Connection     collections.ConnectionInterface
+			ZoneID         string
 			ClientID       string
 			NotificationID string
 			TemplateID     string
@@ -31,16 +33,18 @@ func NewTemplateAssigner() *TemplateAssigner {
 	return &TemplateAssigner{}
 }
 
-func (a *TemplateAssigner) AssignToClient(connection collections.ConnectionInterface, clientID, templateID string) error {
+func (a *TemplateAssigner) AssignToClient(connection collections.ConnectionInterface, zoneID, clientID, templateID string) error {
 	a.AssignToClientCall.Receives.Connection = connection
+	a.AssignToClientCall.Receives.ZoneID = zoneID
 	a.AssignToClientCall.Receives.ClientID = clientID
 	a.AssignToClientCall.Receives.TemplateID = templateID
 
 	return a.AssignToClientCall.Returns.Error
 }
 
-func (a *TemplateAssigner) AssignToNotification(connection collections.ConnectionInterface, clientID, notificationID, templateID string) error {
+func (a *TemplateAssigner) AssignToNotification(connection collections.ConnectionInterface, zoneID, clientID, notificationID, templateID string) error {
 	a.AssignToNotificationCall.Receives.Connection = connection
+	a.AssignToNotificationCall.Receives.ZoneID = zoneID
 	a.AssignToNotificationCall.Receives.ClientID = clientID
 	a.AssignToNotificationCall.Receives.NotificationID = notificationID
 	a.AssignToNotificationCall.Receives.TemplateID = templateID
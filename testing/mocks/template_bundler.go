@@ -0,0 +1,52 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type TemplateBundler struct {
+	ExportCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+		}
+		Returns struct {
+			Bundle services.TemplateBundle
+			Error  error
+		}
+	}
+
+	ImportCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+			Bundle     services.TemplateBundle
+		}
+		Returns struct {
+			Bundle services.TemplateBundle
+			Error  error
+		}
+	}
+}
+
+func NewTemplateBundler() *TemplateBundler {
+	return &TemplateBundler{}
+}
+
+func (b *TemplateBundler) Export(conn models.ConnectionInterface, templateID string) (services.TemplateBundle, error) {
+	b.ExportCall.Receives.Connection = conn
+	b.ExportCall.Receives.TemplateID = templateID
+
+	return b.ExportCall.Returns.Bundle, b.ExportCall.Returns.Error
+}
+
+func (b *TemplateBundler) Import(conn models.ConnectionInterface, templateID string, bundle services.TemplateBundle) (services.TemplateBundle, error) {
+	b.ImportCall.CallCount++
+	b.ImportCall.Receives.Connection = conn
+	b.ImportCall.Receives.TemplateID = templateID
+	b.ImportCall.Receives.Bundle = bundle
+
+	return b.ImportCall.Returns.Bundle, b.ImportCall.Returns.Error
+}
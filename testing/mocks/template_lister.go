@@ -6,6 +6,7 @@ type TemplateLister struct {
 	ListCall struct {
 		Receives struct {
 			Database services.DatabaseInterface
+			ZoneID   string
 		}
 		Returns struct {
 			TemplateSummaries map[string]services.TemplateSummary
@@ -18,8 +19,9 @@ func NewTemplateLister() *TemplateLister {
 	return &TemplateLister{}
 }
 
-func (tl *TemplateLister) List(database services.DatabaseInterface) (map[string]services.TemplateSummary, error) {
+func (tl *TemplateLister) List(database services.DatabaseInterface, zoneID string) (map[string]services.TemplateSummary, error) {
 	tl.ListCall.Receives.Database = database
+	tl.ListCall.Receives.ZoneID = zoneID
 
 	return tl.ListCall.Returns.TemplateSummaries, tl.ListCall.Returns.Error
 }
@@ -0,0 +1,37 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type TemplatePackImporter struct {
+	ImportCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Pack       templatepack.Pack
+			Overwrite  bool
+			DryRun     bool
+		}
+		Returns struct {
+			Report services.TemplatePackReport
+			Error  error
+		}
+	}
+}
+
+func NewTemplatePackImporter() *TemplatePackImporter {
+	return &TemplatePackImporter{}
+}
+
+func (i *TemplatePackImporter) Import(conn models.ConnectionInterface, pack templatepack.Pack, overwrite, dryRun bool) (services.TemplatePackReport, error) {
+	i.ImportCall.WasCalled = true
+	i.ImportCall.Receives.Connection = conn
+	i.ImportCall.Receives.Pack = pack
+	i.ImportCall.Receives.Overwrite = overwrite
+	i.ImportCall.Receives.DryRun = dryRun
+
+	return i.ImportCall.Returns.Report, i.ImportCall.Returns.Error
+}
@@ -0,0 +1,30 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type TemplatePreviewer struct {
+	PreviewCall struct {
+		Receives struct {
+			Templates common.Templates
+			Variables services.PreviewVariables
+		}
+		Returns struct {
+			Preview services.TemplatePreview
+			Error   error
+		}
+	}
+}
+
+func NewTemplatePreviewer() *TemplatePreviewer {
+	return &TemplatePreviewer{}
+}
+
+func (p *TemplatePreviewer) Preview(templates common.Templates, variables services.PreviewVariables) (services.TemplatePreview, error) {
+	p.PreviewCall.Receives.Templates = templates
+	p.PreviewCall.Receives.Variables = variables
+
+	return p.PreviewCall.Returns.Preview, p.PreviewCall.Returns.Error
+}
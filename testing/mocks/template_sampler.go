@@ -0,0 +1,38 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type TemplateSampler struct {
+	PreviewCall struct {
+		WasCalled bool
+		Receives  struct {
+			Database  services.DatabaseInterface
+			Templates common.Templates
+			ClientID  string
+			KindID    string
+			Count     int
+		}
+		Returns struct {
+			Previews []services.TemplateSamplePreview
+			Error    error
+		}
+	}
+}
+
+func NewTemplateSampler() *TemplateSampler {
+	return &TemplateSampler{}
+}
+
+func (s *TemplateSampler) Preview(database services.DatabaseInterface, proposed common.Templates, clientID, kindID string, count int) ([]services.TemplateSamplePreview, error) {
+	s.PreviewCall.WasCalled = true
+	s.PreviewCall.Receives.Database = database
+	s.PreviewCall.Receives.Templates = proposed
+	s.PreviewCall.Receives.ClientID = clientID
+	s.PreviewCall.Receives.KindID = kindID
+	s.PreviewCall.Receives.Count = count
+
+	return s.PreviewCall.Returns.Previews, s.PreviewCall.Returns.Error
+}
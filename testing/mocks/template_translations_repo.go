@@ -0,0 +1,118 @@
+package mocks
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type TemplateTranslationsRepo struct {
+	FindCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+			Locales    []string
+		}
+		Returns struct {
+			Translations map[string]models.TemplateTranslation
+			Error        error
+		}
+	}
+
+	FindAllByTemplateIDCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+		}
+		Returns struct {
+			Translations []models.TemplateTranslation
+			Error        error
+		}
+	}
+
+	UpsertCall struct {
+		Receives struct {
+			Connection   models.ConnectionInterface
+			Translation  models.TemplateTranslation
+			Translations []models.TemplateTranslation
+		}
+		Returns struct {
+			Translation models.TemplateTranslation
+			Error       error
+		}
+	}
+
+	DestroyCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+			Locale     string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+
+	DestroyAllByTemplateIDCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewTemplateTranslationsRepo() *TemplateTranslationsRepo {
+	return &TemplateTranslationsRepo{}
+}
+
+func (r *TemplateTranslationsRepo) Find(conn models.ConnectionInterface, templateID, locale string) (models.TemplateTranslation, error) {
+	r.FindCall.Receives.Connection = conn
+	r.FindCall.Receives.TemplateID = templateID
+	r.FindCall.Receives.Locales = append(r.FindCall.Receives.Locales, locale)
+
+	if r.FindCall.Returns.Error != nil {
+		return models.TemplateTranslation{}, r.FindCall.Returns.Error
+	}
+
+	translation, ok := r.FindCall.Returns.Translations[locale]
+	if !ok {
+		return models.TemplateTranslation{}, models.NotFoundError{Err: fmt.Errorf("Translation %q of template with ID %q could not be found", locale, templateID), Code: "template_translation_not_found"}
+	}
+
+	return translation, nil
+}
+
+func (r *TemplateTranslationsRepo) Upsert(conn models.ConnectionInterface, translation models.TemplateTranslation) (models.TemplateTranslation, error) {
+	r.UpsertCall.Receives.Connection = conn
+	r.UpsertCall.Receives.Translation = translation
+	r.UpsertCall.Receives.Translations = append(r.UpsertCall.Receives.Translations, translation)
+
+	return r.UpsertCall.Returns.Translation, r.UpsertCall.Returns.Error
+}
+
+func (r *TemplateTranslationsRepo) Destroy(conn models.ConnectionInterface, templateID, locale string) error {
+	r.DestroyCall.Receives.Connection = conn
+	r.DestroyCall.Receives.TemplateID = templateID
+	r.DestroyCall.Receives.Locale = locale
+
+	return r.DestroyCall.Returns.Error
+}
+
+func (r *TemplateTranslationsRepo) FindAllByTemplateID(conn models.ConnectionInterface, templateID string) ([]models.TemplateTranslation, error) {
+	r.FindAllByTemplateIDCall.Receives.Connection = conn
+	r.FindAllByTemplateIDCall.Receives.TemplateID = templateID
+
+	return r.FindAllByTemplateIDCall.Returns.Translations, r.FindAllByTemplateIDCall.Returns.Error
+}
+
+func (r *TemplateTranslationsRepo) DestroyAllByTemplateID(conn models.ConnectionInterface, templateID string) error {
+	r.DestroyAllByTemplateIDCall.CallCount++
+	r.DestroyAllByTemplateIDCall.Receives.Connection = conn
+	r.DestroyAllByTemplateIDCall.Receives.TemplateID = templateID
+
+	return r.DestroyAllByTemplateIDCall.Returns.Error
+}
@@ -0,0 +1,69 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type TemplateTranslator struct {
+	SetCall struct {
+		Receives struct {
+			Database    services.DatabaseInterface
+			Translation models.TemplateTranslation
+		}
+		Returns struct {
+			Translation models.TemplateTranslation
+			Error       error
+		}
+	}
+
+	FindCall struct {
+		Receives struct {
+			Database   services.DatabaseInterface
+			TemplateID string
+			Locale     string
+		}
+		Returns struct {
+			Translation models.TemplateTranslation
+			Error       error
+		}
+	}
+
+	DeleteCall struct {
+		Receives struct {
+			Database   services.DatabaseInterface
+			TemplateID string
+			Locale     string
+		}
+		Returns struct {
+			Error error
+		}
+	}
+}
+
+func NewTemplateTranslator() *TemplateTranslator {
+	return &TemplateTranslator{}
+}
+
+func (t *TemplateTranslator) Set(database services.DatabaseInterface, translation models.TemplateTranslation) (models.TemplateTranslation, error) {
+	t.SetCall.Receives.Database = database
+	t.SetCall.Receives.Translation = translation
+
+	return t.SetCall.Returns.Translation, t.SetCall.Returns.Error
+}
+
+func (t *TemplateTranslator) Find(database services.DatabaseInterface, templateID, locale string) (models.TemplateTranslation, error) {
+	t.FindCall.Receives.Database = database
+	t.FindCall.Receives.TemplateID = templateID
+	t.FindCall.Receives.Locale = locale
+
+	return t.FindCall.Returns.Translation, t.FindCall.Returns.Error
+}
+
+func (t *TemplateTranslator) Delete(database services.DatabaseInterface, templateID, locale string) error {
+	t.DeleteCall.Receives.Database = database
+	t.DeleteCall.Receives.TemplateID = templateID
+	t.DeleteCall.Receives.Locale = locale
+
+	return t.DeleteCall.Returns.Error
+}
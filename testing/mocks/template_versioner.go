@@ -0,0 +1,50 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type TemplateVersioner struct {
+	ListCall struct {
+		Receives struct {
+			Database   services.DatabaseInterface
+			TemplateID string
+		}
+		Returns struct {
+			Versions services.TemplateVersions
+			Error    error
+		}
+	}
+
+	ActivateCall struct {
+		Receives struct {
+			Database   services.DatabaseInterface
+			TemplateID string
+			Version    int
+		}
+		Returns struct {
+			Template models.Template
+			Error    error
+		}
+	}
+}
+
+func NewTemplateVersioner() *TemplateVersioner {
+	return &TemplateVersioner{}
+}
+
+func (v *TemplateVersioner) List(database services.DatabaseInterface, templateID string) (services.TemplateVersions, error) {
+	v.ListCall.Receives.Database = database
+	v.ListCall.Receives.TemplateID = templateID
+
+	return v.ListCall.Returns.Versions, v.ListCall.Returns.Error
+}
+
+func (v *TemplateVersioner) Activate(database services.DatabaseInterface, templateID string, version int) (models.Template, error) {
+	v.ActivateCall.Receives.Database = database
+	v.ActivateCall.Receives.TemplateID = templateID
+	v.ActivateCall.Receives.Version = version
+
+	return v.ActivateCall.Returns.Template, v.ActivateCall.Returns.Error
+}
@@ -56,6 +56,40 @@ type TemplatesRepo struct {
 			Error    error
 		}
 	}
+
+	FindVersionsCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+		}
+		Returns struct {
+			Versions []models.TemplateVersion
+			Error    error
+		}
+	}
+
+	ActivateVersionCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			TemplateID string
+			Version    int
+		}
+		Returns struct {
+			Template models.Template
+			Error    error
+		}
+	}
+
+	ProvisionCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Templates  []models.Template
+		}
+		Returns struct {
+			Error error
+		}
+	}
 }
 
 func NewTemplatesRepo() *TemplatesRepo {
@@ -96,3 +130,26 @@ func (tr *TemplatesRepo) Update(conn models.ConnectionInterface, templateID stri
 
 	return tr.UpdateCall.Returns.Template, tr.UpdateCall.Returns.Error
 }
+
+func (tr *TemplatesRepo) Provision(conn models.ConnectionInterface, template models.Template) (models.Template, error) {
+	tr.ProvisionCall.CallCount++
+	tr.ProvisionCall.Receives.Connection = conn
+	tr.ProvisionCall.Receives.Templates = append(tr.ProvisionCall.Receives.Templates, template)
+
+	return template, tr.ProvisionCall.Returns.Error
+}
+
+func (tr *TemplatesRepo) FindVersions(conn models.ConnectionInterface, templateID string) ([]models.TemplateVersion, error) {
+	tr.FindVersionsCall.Receives.Connection = conn
+	tr.FindVersionsCall.Receives.TemplateID = templateID
+
+	return tr.FindVersionsCall.Returns.Versions, tr.FindVersionsCall.Returns.Error
+}
+
+func (tr *TemplatesRepo) ActivateVersion(conn models.ConnectionInterface, templateID string, version int) (models.Template, error) {
+	tr.ActivateVersionCall.Receives.Connection = conn
+	tr.ActivateVersionCall.Receives.TemplateID = templateID
+	tr.ActivateVersionCall.Receives.Version = version
+
+	return tr.ActivateVersionCall.Returns.Template, tr.ActivateVersionCall.Returns.Error
+}
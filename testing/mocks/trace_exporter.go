@@ -0,0 +1,42 @@
+package mocks
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+)
+
+type TraceExporter struct {
+	mutex sync.Mutex
+
+	ExportCall struct {
+		Receives struct {
+			Spans []tracing.Span
+		}
+	}
+}
+
+func NewTraceExporter() *TraceExporter {
+	return &TraceExporter{}
+}
+
+func (e *TraceExporter) Export(span tracing.Span) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	e.ExportCall.Receives.Spans = append(e.ExportCall.Receives.Spans, span)
+}
+
+// Span returns the last exported span with the given name.
+func (e *TraceExporter) Span(name string) (tracing.Span, bool) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	for i := len(e.ExportCall.Receives.Spans) - 1; i >= 0; i-- {
+		if e.ExportCall.Receives.Spans[i].Name == name {
+			return e.ExportCall.Receives.Spans[i], true
+		}
+	}
+
+	return tracing.Span{}, false
+}
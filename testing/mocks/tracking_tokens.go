@@ -0,0 +1,43 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/postal/common"
+
+type TrackingTokens struct {
+	GenerateCall struct {
+		CallCount int
+		Receives  struct {
+			Events []common.TrackingEvent
+		}
+		Returns struct {
+			Token string
+			Error error
+		}
+	}
+
+	ParseCall struct {
+		Receives struct {
+			Token string
+		}
+		Returns struct {
+			Event common.TrackingEvent
+			Error error
+		}
+	}
+}
+
+func NewTrackingTokens() *TrackingTokens {
+	return &TrackingTokens{}
+}
+
+func (t *TrackingTokens) Generate(event common.TrackingEvent) (string, error) {
+	t.GenerateCall.CallCount++
+	t.GenerateCall.Receives.Events = append(t.GenerateCall.Receives.Events, event)
+
+	return t.GenerateCall.Returns.Token, t.GenerateCall.Returns.Error
+}
+
+func (t *TrackingTokens) Parse(token string) (common.TrackingEvent, error) {
+	t.ParseCall.Receives.Token = token
+
+	return t.ParseCall.Returns.Event, t.ParseCall.Returns.Error
+}
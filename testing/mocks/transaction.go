@@ -22,6 +22,12 @@ type Transaction struct {
 		}
 	}
 
+	AfterCommitCall struct {
+		Receives struct {
+			Callbacks []func()
+		}
+	}
+
 	*Connection
 }
 
@@ -36,7 +42,19 @@ func (t *Transaction) Begin() error {
 
 func (t *Transaction) Commit() error {
 	t.CommitCall.WasCalled = true
-	return t.CommitCall.Returns.Error
+	if t.CommitCall.Returns.Error != nil {
+		return t.CommitCall.Returns.Error
+	}
+
+	for _, callback := range t.AfterCommitCall.Receives.Callbacks {
+		callback()
+	}
+
+	return nil
+}
+
+func (t *Transaction) AfterCommit(callback func()) {
+	t.AfterCommitCall.Receives.Callbacks = append(t.AfterCommitCall.Receives.Callbacks, callback)
 }
 
 func (t *Transaction) Rollback() error {
@@ -0,0 +1,28 @@
+package mocks
+
+import "context"
+
+type TXTResolver struct {
+	LookupTXTCall struct {
+		Receives struct {
+			Names []string
+		}
+		Returns struct {
+			Records map[string][]string
+			Errors  map[string]error
+		}
+	}
+}
+
+func NewTXTResolver() *TXTResolver {
+	resolver := &TXTResolver{}
+	resolver.LookupTXTCall.Returns.Records = map[string][]string{}
+	resolver.LookupTXTCall.Returns.Errors = map[string]error{}
+
+	return resolver
+}
+
+func (r *TXTResolver) LookupTXT(ctx context.Context, name string) ([]string, error) {
+	r.LookupTXTCall.Receives.Names = append(r.LookupTXTCall.Receives.Names, name)
+	return r.LookupTXTCall.Returns.Records[name], r.LookupTXTCall.Returns.Errors[name]
+}
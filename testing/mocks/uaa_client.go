@@ -24,6 +24,28 @@ type ZonedUAAClient struct {
 		}
 	}
 
+	UsersGUIDsByGroupCall struct {
+		Receives struct {
+			Token string
+			Group string
+		}
+		Returns struct {
+			UserGUIDs []string
+			Error     error
+		}
+	}
+
+	UsersGUIDsByEmailCall struct {
+		Receives struct {
+			Token string
+			Email string
+		}
+		Returns struct {
+			UserGUIDs []string
+			Error     error
+		}
+	}
+
 	GetClientTokenCall struct {
 		Receives struct {
 			Host string
@@ -63,6 +85,20 @@ func (c *ZonedUAAClient) UsersGUIDsByScope(token, scope string) ([]string, error
 	return c.UsersGUIDsByScopeCall.Returns.UserGUIDs, c.UsersGUIDsByScopeCall.Returns.Error
 }
 
+func (c *ZonedUAAClient) UsersGUIDsByGroup(token, group string) ([]string, error) {
+	c.UsersGUIDsByGroupCall.Receives.Token = token
+	c.UsersGUIDsByGroupCall.Receives.Group = group
+
+	return c.UsersGUIDsByGroupCall.Returns.UserGUIDs, c.UsersGUIDsByGroupCall.Returns.Error
+}
+
+func (c *ZonedUAAClient) UsersGUIDsByEmail(token, email string) ([]string, error) {
+	c.UsersGUIDsByEmailCall.Receives.Token = token
+	c.UsersGUIDsByEmailCall.Receives.Email = email
+
+	return c.UsersGUIDsByEmailCall.Returns.UserGUIDs, c.UsersGUIDsByEmailCall.Returns.Error
+}
+
 func (c *ZonedUAAClient) GetClientToken(host string) (string, error) {
 	c.GetClientTokenCall.Receives.Host = host
 
@@ -0,0 +1,36 @@
+package mocks
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+)
+
+type UnsubscribeImporter struct {
+	ImportCall struct {
+		WasCalled bool
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Entries    []services.UnsubscribeImportEntry
+			UAAHost    string
+			DryRun     bool
+		}
+		Returns struct {
+			Report services.UnsubscribeImportReport
+			Error  error
+		}
+	}
+}
+
+func NewUnsubscribeImporter() *UnsubscribeImporter {
+	return &UnsubscribeImporter{}
+}
+
+func (i *UnsubscribeImporter) Import(conn models.ConnectionInterface, entries []services.UnsubscribeImportEntry, uaaHost string, dryRun bool) (services.UnsubscribeImportReport, error) {
+	i.ImportCall.WasCalled = true
+	i.ImportCall.Receives.Connection = conn
+	i.ImportCall.Receives.Entries = entries
+	i.ImportCall.Receives.UAAHost = uaaHost
+	i.ImportCall.Receives.DryRun = dryRun
+
+	return i.ImportCall.Returns.Report, i.ImportCall.Returns.Error
+}
@@ -0,0 +1,45 @@
+package mocks
+
+type UnsubscribeTokens struct {
+	GenerateCall struct {
+		Receives struct {
+			UserGUID string
+			ClientID string
+			KindID   string
+		}
+		Returns struct {
+			Token string
+			Error error
+		}
+	}
+
+	ParseCall struct {
+		Receives struct {
+			Token string
+		}
+		Returns struct {
+			UserGUID string
+			ClientID string
+			KindID   string
+			Error    error
+		}
+	}
+}
+
+func NewUnsubscribeTokens() *UnsubscribeTokens {
+	return &UnsubscribeTokens{}
+}
+
+func (t *UnsubscribeTokens) Generate(userGUID, clientID, kindID string) (string, error) {
+	t.GenerateCall.Receives.UserGUID = userGUID
+	t.GenerateCall.Receives.ClientID = clientID
+	t.GenerateCall.Receives.KindID = kindID
+
+	return t.GenerateCall.Returns.Token, t.GenerateCall.Returns.Error
+}
+
+func (t *UnsubscribeTokens) Parse(token string) (string, string, string, error) {
+	t.ParseCall.Receives.Token = token
+
+	return t.ParseCall.Returns.UserGUID, t.ParseCall.Returns.ClientID, t.ParseCall.Returns.KindID, t.ParseCall.Returns.Error
+}
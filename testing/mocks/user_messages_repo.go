@@ -0,0 +1,69 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type UserMessagesRepo struct {
+	CreateCall struct {
+		CallCount int
+		Receives  struct {
+			Connection models.ConnectionInterface
+			Message    models.UserMessage
+		}
+		Returns struct {
+			Message models.UserMessage
+			Error   error
+		}
+	}
+
+	ListCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Filter     models.UserMessageFilter
+			Offset     int
+			Limit      int
+		}
+		Returns struct {
+			Messages []models.UserMessage
+			Error    error
+		}
+	}
+
+	CountCall struct {
+		Receives struct {
+			Connection models.ConnectionInterface
+			Filter     models.UserMessageFilter
+		}
+		Returns struct {
+			Count int
+			Error error
+		}
+	}
+}
+
+func NewUserMessagesRepo() *UserMessagesRepo {
+	return &UserMessagesRepo{}
+}
+
+func (r *UserMessagesRepo) Create(conn models.ConnectionInterface, message models.UserMessage) (models.UserMessage, error) {
+	r.CreateCall.CallCount++
+	r.CreateCall.Receives.Connection = conn
+	r.CreateCall.Receives.Message = message
+
+	return r.CreateCall.Returns.Message, r.CreateCall.Returns.Error
+}
+
+func (r *UserMessagesRepo) List(conn models.ConnectionInterface, filter models.UserMessageFilter, offset, limit int) ([]models.UserMessage, error) {
+	r.ListCall.Receives.Connection = conn
+	r.ListCall.Receives.Filter = filter
+	r.ListCall.Receives.Offset = offset
+	r.ListCall.Receives.Limit = limit
+
+	return r.ListCall.Returns.Messages, r.ListCall.Returns.Error
+}
+
+func (r *UserMessagesRepo) Count(conn models.ConnectionInterface, filter models.UserMessageFilter) (int, error) {
+	r.CountCall.Receives.Connection = conn
+	r.CountCall.Receives.Filter = filter
+
+	return r.CountCall.Returns.Count, r.CountCall.Returns.Error
+}
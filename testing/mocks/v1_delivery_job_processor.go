@@ -15,6 +15,7 @@ type V1DeliveryJobProcessor struct {
 		Returns struct {
 			Error error
 		}
+		Hook func()
 	}
 }
 
@@ -27,5 +28,9 @@ func (p *V1DeliveryJobProcessor) Process(job *gobble.Job, logger lager.Logger) e
 	p.ProcessCall.Receives.Logger = logger
 	p.ProcessCall.CallCount++
 
+	if p.ProcessCall.Hook != nil {
+		p.ProcessCall.Hook()
+	}
+
 	return p.ProcessCall.Returns.Error
 }
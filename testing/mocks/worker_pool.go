@@ -0,0 +1,22 @@
+package mocks
+
+import "github.com/cloudfoundry-incubator/notifications/postal"
+
+type WorkerPool struct {
+	StatusCall struct {
+		CallCount int
+		Returns   struct {
+			Status postal.WorkerPoolStatus
+		}
+	}
+}
+
+func NewWorkerPool() *WorkerPool {
+	return &WorkerPool{}
+}
+
+func (p *WorkerPool) Status() postal.WorkerPoolStatus {
+	p.StatusCall.CallCount++
+
+	return p.StatusCall.Returns.Status
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+type Handler struct {
+	next     http.Handler
+	redacted []string
+}
+
+// NewHandler starts a server span for every request, continuing the trace
+// of an incoming traceparent header. Handlers reach the span through
+// FromContext(req.Context()).
+func NewHandler(next http.Handler) Handler {
+	return Handler{
+		next: next,
+	}
+}
+
+// WithRedactedPaths has the rest of any path that starts with one of the
+// prefixes, such as the token of "/unsubscribe/", recorded as "{token}", so
+// that credentials carried in paths are kept out of the traces.
+func (h Handler) WithRedactedPaths(prefixes ...string) Handler {
+	h.redacted = prefixes
+	return h
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	span := Start("HTTP "+req.Method, KindServer, ParseTraceparent(req.Header.Get("traceparent")))
+	span.SetAttribute("http.method", req.Method)
+	span.SetAttribute("http.target", h.target(req.URL.Path))
+	if requestID := req.Header.Get("X-Vcap-Request-Id"); requestID != "" {
+		span.SetAttribute("vcap_request_id", requestID)
+	}
+
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.next.ServeHTTP(recorder, req.WithContext(ContextWithSpan(req.Context(), span)))
+
+	span.SetAttribute("http.status_code", strconv.Itoa(recorder.status))
+	if recorder.status >= http.StatusInternalServerError {
+		span.RecordError(fmt.Errorf("HTTP %d", recorder.status))
+	}
+	span.End()
+}
+
+func (h Handler) target(path string) string {
+	for _, prefix := range h.redacted {
+		if strings.HasPrefix(path, prefix) && len(path) > len(prefix) {
+			return prefix + "{token}"
+		}
+	}
+
+	return path
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath, so that
+// streamed responses can still be flushed.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
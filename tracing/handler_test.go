@@ -0,0 +1,109 @@
+package tracing_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Handler", func() {
+	var (
+		exporter *mocks.TraceExporter
+		received tracing.SpanContext
+		status   int
+		handler  tracing.Handler
+	)
+
+	BeforeEach(func() {
+		exporter = mocks.NewTraceExporter()
+		tracing.SetExporter(exporter)
+
+		status = http.StatusOK
+		handler = tracing.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			received = tracing.FromContext(req.Context())
+			w.WriteHeader(status)
+		}))
+	})
+
+	AfterEach(func() {
+		tracing.SetExporter(nil)
+	})
+
+	It("records a server span that continues the incoming trace", func() {
+		request, err := http.NewRequest("POST", "/users/some-user-guid", nil)
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		request.Header.Set("X-Vcap-Request-Id", "some-request-id")
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		spans := exporter.ExportCall.Receives.Spans
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Name).To(Equal("HTTP POST"))
+		Expect(spans[0].Kind).To(Equal(tracing.KindServer))
+		Expect(spans[0].Context).To(Equal(received))
+		Expect(spans[0].Context.Traceparent()).To(HavePrefix("00-4bf92f3577b34da6a3ce929d0e0e4736-"))
+		Expect(spans[0].Attributes).To(Equal(map[string]string{
+			"http.method":      "POST",
+			"http.target":      "/users/some-user-guid",
+			"http.status_code": "200",
+			"vcap_request_id":  "some-request-id",
+		}))
+		Expect(spans[0].Err).NotTo(HaveOccurred())
+	})
+
+	It("records the paths that carry a token without the token", func() {
+		handler = handler.WithRedactedPaths("/unsubscribe/", "/t/")
+
+		for path, target := range map[string]string{
+			"/unsubscribe/some-secret.signature": "/unsubscribe/{token}",
+			"/t/some-secret":                     "/t/{token}",
+			"/unsubscribe/":                      "/unsubscribe/",
+			"/templates/some-template":           "/templates/some-template",
+		} {
+			request, err := http.NewRequest("GET", path, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(httptest.NewRecorder(), request)
+
+			spans := exporter.ExportCall.Receives.Spans
+			Expect(spans[len(spans)-1].Attributes["http.target"]).To(Equal(target), path)
+		}
+	})
+
+	It("lets the handler flush the response it streams", func() {
+		var flushErr error
+		handler = tracing.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			flushErr = http.NewResponseController(w).Flush()
+		}))
+
+		request, err := http.NewRequest("GET", "/messages", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		writer := httptest.NewRecorder()
+		handler.ServeHTTP(writer, request)
+
+		Expect(flushErr).NotTo(HaveOccurred())
+		Expect(writer.Flushed).To(BeTrue())
+	})
+
+	It("marks the span as failed on server errors", func() {
+		status = http.StatusInternalServerError
+
+		request, err := http.NewRequest("GET", "/info", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(httptest.NewRecorder(), request)
+
+		spans := exporter.ExportCall.Receives.Spans
+		Expect(spans).To(HaveLen(1))
+		Expect(spans[0].Attributes["http.status_code"]).To(Equal("500"))
+		Expect(spans[0].Err).To(MatchError("HTTP 500"))
+	})
+})
@@ -0,0 +1,180 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pivotal-golang/lager"
+)
+
+// MaxPendingSpans bounds the spans held between flushes; spans finished
+// while the buffer is full are dropped.
+const MaxPendingSpans = 10000
+
+// OTLPExporter buffers finished spans and sends them to an OpenTelemetry
+// collector using the OTLP/HTTP JSON encoding.
+type OTLPExporter struct {
+	endpoint    string
+	serviceName string
+	client      *http.Client
+
+	mutex   sync.Mutex
+	pending []Span
+}
+
+func NewOTLPExporter(endpoint, serviceName string, client *http.Client) *OTLPExporter {
+	return &OTLPExporter{
+		endpoint:    strings.TrimSuffix(endpoint, "/") + "/v1/traces",
+		serviceName: serviceName,
+		client:      client,
+	}
+}
+
+func (e *OTLPExporter) Export(span Span) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if len(e.pending) < MaxPendingSpans {
+		e.pending = append(e.pending, span)
+	}
+}
+
+func (e *OTLPExporter) Run(timer <-chan time.Time, logger lager.Logger) {
+	for range timer {
+		err := e.Flush()
+		if err != nil {
+			logger.Error("trace-export-failed", err)
+		}
+	}
+}
+
+// Flush sends every buffered span in a single request.
+func (e *OTLPExporter) Flush() error {
+	e.mutex.Lock()
+	spans := e.pending
+	e.pending = nil
+	e.mutex.Unlock()
+
+	if len(spans) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	response, err := e.client.Post(e.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("OTLP collector responded with status %d", response.StatusCode)
+	}
+
+	return nil
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            *otlpStatus     `json:"status,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func (e *OTLPExporter) request(spans []Span) otlpRequest {
+	var scopeSpans otlpScopeSpans
+	scopeSpans.Scope.Name = "github.com/cloudfoundry-incubator/notifications"
+
+	for _, span := range spans {
+		s := otlpSpan{
+			TraceID:           hex.EncodeToString(span.Context.TraceID[:]),
+			SpanID:            hex.EncodeToString(span.Context.SpanID[:]),
+			Name:              span.Name,
+			Kind:              span.Kind,
+			StartTimeUnixNano: strconv.FormatInt(span.StartTime.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(span.EndTime.UnixNano(), 10),
+			Attributes:        otlpAttributes(span.Attributes),
+		}
+
+		if span.ParentSpanID != [8]byte{} {
+			s.ParentSpanID = hex.EncodeToString(span.ParentSpanID[:])
+		}
+
+		if span.Err != nil {
+			s.Status = &otlpStatus{Code: 2, Message: span.Err.Error()}
+		}
+
+		scopeSpans.Spans = append(scopeSpans.Spans, s)
+	}
+
+	var resourceSpans otlpResourceSpans
+	resourceSpans.Resource.Attributes = otlpAttributes(map[string]string{"service.name": e.serviceName})
+	resourceSpans.ScopeSpans = []otlpScopeSpans{scopeSpans}
+
+	return otlpRequest{
+		ResourceSpans: []otlpResourceSpans{resourceSpans},
+	}
+}
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	var keys []string
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var result []otlpAttribute
+	for _, key := range keys {
+		attribute := otlpAttribute{Key: key}
+		attribute.Value.StringValue = attributes[key]
+		result = append(result, attribute)
+	}
+
+	return result
+}
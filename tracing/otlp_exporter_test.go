@@ -0,0 +1,126 @@
+package tracing_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OTLPExporter", func() {
+	var (
+		server   *httptest.Server
+		requests chan *http.Request
+		bodies   chan []byte
+		status   int
+		exporter *tracing.OTLPExporter
+	)
+
+	BeforeEach(func() {
+		requests = make(chan *http.Request, 10)
+		bodies = make(chan []byte, 10)
+		status = http.StatusOK
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			body, err := io.ReadAll(req.Body)
+			Expect(err).NotTo(HaveOccurred())
+
+			requests <- req
+			bodies <- body
+			w.WriteHeader(status)
+		}))
+
+		exporter = tracing.NewOTLPExporter(server.URL+"/", "notifications", http.DefaultClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("posts buffered spans to the collector as OTLP JSON", func() {
+		parent := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+		start := time.Unix(1500000000, 0)
+
+		exporter.Export(tracing.Span{
+			Name:         "notifications.deliver",
+			Kind:         tracing.KindConsumer,
+			Context:      tracing.SpanContext{TraceID: parent.TraceID, SpanID: [8]byte{1, 2, 3, 4, 5, 6, 7, 8}},
+			ParentSpanID: parent.SpanID,
+			StartTime:    start,
+			EndTime:      start.Add(time.Second),
+			Attributes:   map[string]string{"vcap_request_id": "some-request-id"},
+			Err:          errors.New("smtp failed"),
+		})
+
+		Expect(exporter.Flush()).To(Succeed())
+
+		var request *http.Request
+		Eventually(requests).Should(Receive(&request))
+		Expect(request.Method).To(Equal("POST"))
+		Expect(request.URL.Path).To(Equal("/v1/traces"))
+		Expect(request.Header.Get("Content-Type")).To(Equal("application/json"))
+
+		var body []byte
+		Eventually(bodies).Should(Receive(&body))
+		Expect(body).To(MatchJSON(`{
+			"resourceSpans": [{
+				"resource": {
+					"attributes": [{"key": "service.name", "value": {"stringValue": "notifications"}}]
+				},
+				"scopeSpans": [{
+					"scope": {"name": "github.com/cloudfoundry-incubator/notifications"},
+					"spans": [{
+						"traceId": "4bf92f3577b34da6a3ce929d0e0e4736",
+						"spanId": "0102030405060708",
+						"parentSpanId": "00f067aa0ba902b7",
+						"name": "notifications.deliver",
+						"kind": 5,
+						"startTimeUnixNano": "1500000000000000000",
+						"endTimeUnixNano": "1500000001000000000",
+						"attributes": [{"key": "vcap_request_id", "value": {"stringValue": "some-request-id"}}],
+						"status": {"code": 2, "message": "smtp failed"}
+					}]
+				}]
+			}]
+		}`))
+
+		Expect(exporter.Flush()).To(Succeed())
+		Consistently(requests).ShouldNot(Receive())
+	})
+
+	It("returns an error when the collector rejects the spans", func() {
+		status = http.StatusBadRequest
+
+		exporter.Export(tracing.Span{Name: "some-span"})
+
+		Expect(exporter.Flush()).To(MatchError("OTLP collector responded with status 400"))
+	})
+
+	It("drops spans once the buffer is full", func() {
+		for i := 0; i < tracing.MaxPendingSpans+1; i++ {
+			exporter.Export(tracing.Span{Name: "some-span"})
+		}
+
+		Expect(exporter.Flush()).To(Succeed())
+
+		var body []byte
+		Eventually(bodies).Should(Receive(&body))
+
+		var request struct {
+			ResourceSpans []struct {
+				ScopeSpans []struct {
+					Spans []json.RawMessage `json:"spans"`
+				} `json:"scopeSpans"`
+			} `json:"resourceSpans"`
+		}
+		Expect(json.Unmarshal(body, &request)).To(Succeed())
+		Expect(request.ResourceSpans[0].ScopeSpans[0].Spans).To(HaveLen(tracing.MaxPendingSpans))
+	})
+})
@@ -0,0 +1,154 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SpanKind int
+
+// The values match the SpanKind enum of the OTLP protocol.
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+	KindProducer SpanKind = 4
+	KindConsumer SpanKind = 5
+)
+
+type Exporter interface {
+	Export(span Span)
+}
+
+var (
+	exporterMutex sync.RWMutex
+	exporter      Exporter
+)
+
+// SetExporter sets where finished spans are sent. Spans are still created
+// and propagated when no exporter is set, they are just dropped on End.
+func SetExporter(e Exporter) {
+	exporterMutex.Lock()
+	defer exporterMutex.Unlock()
+
+	exporter = e
+}
+
+type SpanContext struct {
+	TraceID [16]byte
+	SpanID  [8]byte
+}
+
+func (c SpanContext) IsValid() bool {
+	return c.TraceID != [16]byte{} && c.SpanID != [8]byte{}
+}
+
+// Traceparent formats the context as a W3C traceparent header value.
+func (c SpanContext) Traceparent() string {
+	if !c.IsValid() {
+		return ""
+	}
+
+	return "00-" + hex.EncodeToString(c.TraceID[:]) + "-" + hex.EncodeToString(c.SpanID[:]) + "-01"
+}
+
+// ParseTraceparent reads a W3C traceparent header value, returning an
+// invalid context when the value is missing or malformed.
+func ParseTraceparent(value string) SpanContext {
+	var c SpanContext
+
+	parts := strings.Split(strings.TrimSpace(value), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || len(parts[3]) != 2 {
+		return SpanContext{}
+	}
+
+	if len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return SpanContext{}
+	}
+
+	if _, err := hex.Decode(c.TraceID[:], []byte(parts[1])); err != nil {
+		return SpanContext{}
+	}
+
+	if _, err := hex.Decode(c.SpanID[:], []byte(parts[2])); err != nil {
+		return SpanContext{}
+	}
+
+	if !c.IsValid() {
+		return SpanContext{}
+	}
+
+	return c
+}
+
+type Span struct {
+	Name         string
+	Kind         SpanKind
+	Context      SpanContext
+	ParentSpanID [8]byte
+	StartTime    time.Time
+	EndTime      time.Time
+	Attributes   map[string]string
+	Err          error
+}
+
+// Start begins a span. It joins the trace of parent when parent is valid and
+// starts a new trace otherwise.
+func Start(name string, kind SpanKind, parent SpanContext) *Span {
+	span := &Span{
+		Name:       name,
+		Kind:       kind,
+		StartTime:  time.Now(),
+		Attributes: map[string]string{},
+	}
+
+	if parent.IsValid() {
+		span.Context.TraceID = parent.TraceID
+		span.ParentSpanID = parent.SpanID
+	} else {
+		rand.Read(span.Context.TraceID[:])
+	}
+	rand.Read(span.Context.SpanID[:])
+
+	return span
+}
+
+func (s *Span) SetAttribute(key, value string) {
+	s.Attributes[key] = value
+}
+
+// RecordError marks the span as failed. A nil error is ignored so callers
+// can pass along whatever error they got back.
+func (s *Span) RecordError(err error) {
+	if err != nil {
+		s.Err = err
+	}
+}
+
+func (s *Span) End() {
+	s.EndTime = time.Now()
+
+	exporterMutex.RLock()
+	e := exporter
+	exporterMutex.RUnlock()
+
+	if e != nil {
+		e.Export(*s)
+	}
+}
+
+type contextKey struct{}
+
+func ContextWithSpan(ctx context.Context, span *Span) context.Context {
+	return context.WithValue(ctx, contextKey{}, span.Context)
+}
+
+// FromContext returns the context of the span stored by ContextWithSpan.
+func FromContext(ctx context.Context) SpanContext {
+	c, _ := ctx.Value(contextKey{}).(SpanContext)
+	return c
+}
@@ -0,0 +1,95 @@
+package tracing_test
+
+import (
+	"context"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Span", func() {
+	var exporter *mocks.TraceExporter
+
+	BeforeEach(func() {
+		exporter = mocks.NewTraceExporter()
+		tracing.SetExporter(exporter)
+	})
+
+	AfterEach(func() {
+		tracing.SetExporter(nil)
+	})
+
+	Describe("traceparent", func() {
+		It("round trips a span context", func() {
+			span := tracing.Start("some-span", tracing.KindInternal, tracing.SpanContext{})
+
+			traceparent := span.Context.Traceparent()
+			Expect(traceparent).To(MatchRegexp(`^00-[0-9a-f]{32}-[0-9a-f]{16}-01$`))
+			Expect(tracing.ParseTraceparent(traceparent)).To(Equal(span.Context))
+		})
+
+		It("rejects malformed values", func() {
+			Expect(tracing.ParseTraceparent("").IsValid()).To(BeFalse())
+			Expect(tracing.ParseTraceparent("00-abc-def-01").IsValid()).To(BeFalse())
+			Expect(tracing.ParseTraceparent("00-00000000000000000000000000000000-00f067aa0ba902b7-01").IsValid()).To(BeFalse())
+			Expect(tracing.ParseTraceparent("ff-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01").IsValid()).To(BeFalse())
+			Expect(tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902zz-01").IsValid()).To(BeFalse())
+		})
+
+		It("formats an invalid context as an empty string", func() {
+			Expect(tracing.SpanContext{}.Traceparent()).To(Equal(""))
+		})
+	})
+
+	Describe("Start", func() {
+		It("joins the trace of a valid parent", func() {
+			parent := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+			span := tracing.Start("child", tracing.KindConsumer, parent)
+			Expect(span.Context.TraceID).To(Equal(parent.TraceID))
+			Expect(span.ParentSpanID).To(Equal(parent.SpanID))
+			Expect(span.Context.SpanID).NotTo(Equal(parent.SpanID))
+		})
+
+		It("starts a new trace without a parent", func() {
+			first := tracing.Start("first", tracing.KindInternal, tracing.SpanContext{})
+			second := tracing.Start("second", tracing.KindInternal, tracing.SpanContext{})
+
+			Expect(first.Context.IsValid()).To(BeTrue())
+			Expect(first.ParentSpanID).To(Equal([8]byte{}))
+			Expect(first.Context.TraceID).NotTo(Equal(second.Context.TraceID))
+		})
+	})
+
+	Describe("End", func() {
+		It("exports the finished span", func() {
+			span := tracing.Start("some-span", tracing.KindProducer, tracing.SpanContext{})
+			span.SetAttribute("vcap_request_id", "some-request-id")
+			span.RecordError(nil)
+			span.RecordError(errors.New("boom"))
+			span.End()
+
+			spans := exporter.ExportCall.Receives.Spans
+			Expect(spans).To(HaveLen(1))
+			Expect(spans[0].Name).To(Equal("some-span"))
+			Expect(spans[0].Kind).To(Equal(tracing.KindProducer))
+			Expect(spans[0].Attributes).To(Equal(map[string]string{"vcap_request_id": "some-request-id"}))
+			Expect(spans[0].Err).To(MatchError("boom"))
+			Expect(spans[0].EndTime).NotTo(BeTemporally("<", spans[0].StartTime))
+		})
+	})
+
+	Describe("FromContext", func() {
+		It("returns the context of the stored span", func() {
+			span := tracing.Start("some-span", tracing.KindServer, tracing.SpanContext{})
+
+			ctx := tracing.ContextWithSpan(context.Background(), span)
+			Expect(tracing.FromContext(ctx)).To(Equal(span.Context))
+			Expect(tracing.FromContext(context.Background()).IsValid()).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,47 @@
+package uaa
+
+import (
+	"net/http"
+
+	"github.com/pivotal-cf-experimental/warrant"
+)
+
+// SigningKeysFetcher reads the keys the UAA at host signs tokens with, for
+// the token validator.
+type SigningKeysFetcher struct {
+	host   string
+	client ZonedUAAClient
+}
+
+func NewSigningKeysFetcher(host string, client *http.Client) SigningKeysFetcher {
+	return SigningKeysFetcher{
+		host:   host,
+		client: ZonedUAAClient{client: client},
+	}
+}
+
+func (f SigningKeysFetcher) GetSigningKeys() ([]warrant.SigningKey, error) {
+	var response struct {
+		Keys []struct {
+			Kid   string `json:"kid"`
+			Alg   string `json:"alg"`
+			Value string `json:"value"`
+		} `json:"keys"`
+	}
+
+	err := f.client.get(f.host, "/token_keys", "", &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []warrant.SigningKey
+	for _, key := range response.Keys {
+		keys = append(keys, warrant.SigningKey{
+			KeyId:     key.Kid,
+			Algorithm: key.Alg,
+			Value:     key.Value,
+		})
+	}
+
+	return keys, nil
+}
@@ -1,73 +1,173 @@
 package uaa
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"net/url"
-
-	"github.com/pivotal-cf-experimental/warrant"
-	uaaSSOGolang "github.com/pivotal-cf/uaa-sso-golang/uaa"
+	"strconv"
+	"strings"
 )
 
+// maxQueryLength is the longest URL the UAA accepts, which bounds the number
+// of users that can be looked up in one request.
+const maxQueryLength = 8000
+
 type ZonedUAAClient struct {
 	clientID       string
 	clientSecret   string
-	verifySSL      bool
+	client         *http.Client
 	tokenValidator *TokenValidator
 }
 
-func NewZonedUAAClient(clientID, clientSecret string, verifySSL bool, validator *TokenValidator) (client ZonedUAAClient) {
+// NewZonedUAAClient builds a client for the UAA zone that issued each token
+// it is given. Its requests are made with the given client, which is
+// expected to be shared with the other clients of the UAA.
+func NewZonedUAAClient(clientID, clientSecret string, client *http.Client, validator *TokenValidator) ZonedUAAClient {
 	return ZonedUAAClient{
 		clientID:       clientID,
 		clientSecret:   clientSecret,
-		verifySSL:      verifySSL,
+		client:         client,
 		tokenValidator: validator,
 	}
 }
 
 func (z ZonedUAAClient) GetTokenKey(uaaHost string) (string, error) {
-	uaaClient := warrant.New(warrant.Config{
-		Host:          uaaHost,
-		SkipVerifySSL: !z.verifySSL,
-	})
+	var response struct {
+		Value string `json:"value"`
+	}
 
-	signingKey, err := uaaClient.Tokens.GetSigningKey()
+	err := z.get(uaaHost, "/token_key", "", &response)
 	if err != nil {
 		return "", err
 	}
 
-	return signingKey.Value, nil
+	return response.Value, nil
 }
 
 func (z ZonedUAAClient) GetClientToken(host string) (string, error) {
-	uaaClient := warrant.New(warrant.Config{
-		Host:          host,
-		SkipVerifySSL: !z.verifySSL,
-	})
+	form := url.Values{
+		"client_id":  {z.clientID},
+		"grant_type": {"client_credentials"},
+	}
+
+	request, err := http.NewRequest("POST", host+"/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.SetBasicAuth(z.clientID, z.clientSecret)
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var response struct {
+		AccessToken string `json:"access_token"`
+	}
+
+	err = z.do(request, &response)
+	if err != nil {
+		return "", err
+	}
+
+	return response.AccessToken, nil
+}
+
+// get makes a GET request to the UAA and decodes its JSON response into v.
+// The request carries the token, when there is one.
+func (z ZonedUAAClient) get(host, path, token string, v interface{}) error {
+	request, err := http.NewRequest("GET", host+path, nil)
+	if err != nil {
+		return err
+	}
+
+	if token != "" {
+		request.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	return z.do(request, v)
+}
+
+func (z ZonedUAAClient) do(request *http.Request, v interface{}) error {
+	request.Header.Set("Accept", "application/json")
+
+	response, err := z.client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(response.Body, 32<<20))
+	if err != nil {
+		return err
+	}
 
-	return uaaClient.Clients.GetToken(z.clientID, z.clientSecret)
+	if response.StatusCode > 399 {
+		return NewFailure(response.StatusCode, body)
+	}
+
+	return json.Unmarshal(body, v)
 }
 
+// UsersEmailsByIDs looks up the emails and locale of each user. The query is
+// asked for explicitly, since the UAA leaves the locale out by default.
 func (z ZonedUAAClient) UsersEmailsByIDs(token string, ids ...string) ([]User, error) {
 	uaaHost, err := z.tokenHost(token)
 	if err != nil {
 		return nil, err
 	}
 
-	uaaClient := uaaSSOGolang.NewUAA("", uaaHost, z.clientID, z.clientSecret, "")
-	uaaClient.VerifySSL = z.verifySSL
-	uaaClient.SetToken(token)
-
 	var myUsers []User
-	users, err := uaaClient.UsersEmailsByIDs(ids...)
-	if err != nil {
-		return myUsers, err
+	for _, path := range usersEmailsQueryPaths(uaaHost, ids) {
+		var response struct {
+			Resources []struct {
+				ID     string `json:"id"`
+				Locale string `json:"locale"`
+				Emails []struct {
+					Value string `json:"value"`
+				} `json:"emails"`
+			} `json:"resources"`
+		}
+		err = z.get(uaaHost, path, token, &response)
+		if err != nil {
+			return myUsers, err
+		}
+
+		for _, resource := range response.Resources {
+			user := User{
+				ID:     resource.ID,
+				Locale: resource.Locale,
+			}
+			for _, email := range resource.Emails {
+				user.Emails = append(user.Emails, email.Value)
+			}
+
+			myUsers = append(myUsers, user)
+		}
+	}
+
+	return myUsers, nil
+}
+
+func usersEmailsQueryPaths(host string, ids []string) []string {
+	query := func(filters []string) string {
+		return "/Users?attributes=emails,id,locale&filter=" + url.QueryEscape(strings.Join(filters, " or "))
 	}
 
-	for _, user := range users {
-		myUsers = append(myUsers, newUserFromSSOGolangUser(user))
+	var filters []string
+	for _, id := range ids {
+		filters = append(filters, fmt.Sprintf(`Id eq "%s"`, id))
 	}
 
-	return myUsers, nil
+	var paths []string
+	start := 0
+	for i := range filters {
+		if len(host+query(filters[start:i+1])) > maxQueryLength && i > start {
+			paths = append(paths, query(filters[start:i]))
+			start = i
+		}
+	}
+
+	return append(paths, query(filters[start:]))
 }
 
 func (z ZonedUAAClient) tokenHost(token string) (string, error) {
@@ -85,22 +185,46 @@ func (z ZonedUAAClient) tokenHost(token string) (string, error) {
 	return tokenIssuerURL.Scheme + "://" + tokenIssuerURL.Host, nil
 }
 
+// AllUsers reads every page of the users of the UAA zone.
 func (z ZonedUAAClient) AllUsers(token string) ([]User, error) {
 	uaaHost, err := z.tokenHost(token)
 	if err != nil {
 		return nil, err
 	}
 
-	uaaSSOGolangClient := uaaSSOGolang.NewUAA("", uaaHost, z.clientID, z.clientSecret, "")
-	uaaSSOGolangClient.VerifySSL = z.verifySSL
-	users, err := uaaSSOGolangClient.AllUsers()
-
 	var myUsers []User
-	for _, user := range users {
-		myUsers = append(myUsers, newUserFromSSOGolangUser(user))
+	for {
+		query := url.Values{}
+		query.Set("attributes", "emails,id")
+		query.Set("startIndex", strconv.Itoa(len(myUsers)+1))
+
+		var response struct {
+			TotalResults int `json:"totalResults"`
+			Resources    []struct {
+				ID     string `json:"id"`
+				Emails []struct {
+					Value string `json:"value"`
+				} `json:"emails"`
+			} `json:"resources"`
+		}
+		err = z.get(uaaHost, "/Users?"+query.Encode(), token, &response)
+		if err != nil {
+			return myUsers, err
+		}
+
+		for _, resource := range response.Resources {
+			user := User{ID: resource.ID}
+			for _, email := range resource.Emails {
+				user.Emails = append(user.Emails, email.Value)
+			}
+
+			myUsers = append(myUsers, user)
+		}
+
+		if len(response.Resources) == 0 || len(myUsers) >= response.TotalResults {
+			return myUsers, nil
+		}
 	}
-
-	return myUsers, err
 }
 
 func (z ZonedUAAClient) UsersGUIDsByScope(token string, scope string) ([]string, error) {
@@ -109,31 +233,126 @@ func (z ZonedUAAClient) UsersGUIDsByScope(token string, scope string) ([]string,
 		return nil, err
 	}
 
-	uaaSSOGolangClient := uaaSSOGolang.NewUAA("", uaaHost, z.clientID, z.clientSecret, "")
-	uaaSSOGolangClient.VerifySSL = z.verifySSL
+	query := url.Values{}
+	query.Set("attributes", "members")
+	query.Set("filter", fmt.Sprintf(`displayName eq "%s"`, scope))
+
+	var response struct {
+		Resources []struct {
+			Members []struct {
+				Value string `json:"value"`
+			} `json:"members"`
+		} `json:"resources"`
+	}
+	err = z.get(uaaHost, "/Groups?"+query.Encode(), token, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	guids := []string{}
+	for _, resource := range response.Resources {
+		for _, member := range resource.Members {
+			guids = append(guids, member.Value)
+		}
+	}
 
-	return uaaSSOGolangClient.UsersGUIDsByScope(scope)
+	return guids, nil
 }
 
-func newUserFromWarrantUser(warrantUser warrant.User) User {
-	user := User{}
-	user.ID = warrantUser.ID
-	user.Emails = warrantUser.Emails
+// groupsPageSize is the number of groups asked for in each page of a SCIM
+// group listing.
+const groupsPageSize = 100
+
+// UsersGUIDsByGroup returns the users that are members of the UAA group with
+// the given display name, reading every page of the SCIM results. Members
+// that are groups themselves are left out.
+func (z ZonedUAAClient) UsersGUIDsByGroup(token, group string) ([]string, error) {
+	uaaHost, err := z.tokenHost(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var guids []string
+	found := false
+	for startIndex := 1; ; {
+		var response struct {
+			TotalResults int `json:"totalResults"`
+			Resources    []struct {
+				Members []struct {
+					Value string `json:"value"`
+					Type  string `json:"type"`
+				} `json:"members"`
+			} `json:"resources"`
+		}
+		err = z.get(uaaHost, groupMembersQueryPath(group, startIndex), token, &response)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, resource := range response.Resources {
+			found = true
+			for _, member := range resource.Members {
+				if member.Type == "" || strings.EqualFold(member.Type, "USER") {
+					guids = append(guids, member.Value)
+				}
+			}
+		}
+
+		startIndex += len(response.Resources)
+		if len(response.Resources) == 0 || startIndex > response.TotalResults {
+			break
+		}
+	}
+
+	if !found {
+		return nil, GroupNotFoundError{Group: group}
+	}
 
-	return user
+	return guids, nil
 }
 
-func newUserFromSSOGolangUser(uaaUser uaaSSOGolang.User) User {
-	user := User{}
-	user.ID = uaaUser.ID
-	user.Emails = uaaUser.Emails
+func groupMembersQueryPath(group string, startIndex int) string {
+	query := url.Values{}
+	query.Set("attributes", "members")
+	query.Set("filter", fmt.Sprintf("displayName eq %q", group))
+	query.Set("startIndex", strconv.Itoa(startIndex))
+	query.Set("count", strconv.Itoa(groupsPageSize))
 
-	return user
+	return "/Groups?" + query.Encode()
+}
+
+func (z ZonedUAAClient) UsersGUIDsByEmail(token string, email string) ([]string, error) {
+	uaaHost, err := z.tokenHost(token)
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("attributes", "id")
+	query.Set("filter", fmt.Sprintf("email eq %q", email))
+
+	var response struct {
+		Resources []struct {
+			ID string `json:"id"`
+		} `json:"resources"`
+	}
+	err = z.get(uaaHost, "/Users?"+query.Encode(), token, &response)
+	if err != nil {
+		return nil, err
+	}
+
+	var guids []string
+	for _, user := range response.Resources {
+		guids = append(guids, user.ID)
+	}
+
+	return guids, nil
 }
 
 type User struct {
 	ID     string
 	Emails []string
+	Locale string
 }
 
 type Failure struct {
@@ -159,3 +378,13 @@ func (failure Failure) Message() string {
 func (failure Failure) Error() string {
 	return fmt.Sprintf("UAA Wrapper Failure: %d %s", failure.code, failure.message)
 }
+
+// GroupNotFoundError is returned when the UAA has no group with the name
+// that was asked for.
+type GroupNotFoundError struct {
+	Group string
+}
+
+func (e GroupNotFoundError) Error() string {
+	return fmt.Sprintf("UAA group %q could not be found", e.Group)
+}
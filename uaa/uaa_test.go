@@ -0,0 +1,190 @@
+package uaa_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/pivotal-cf-experimental/warrant"
+	"github.com/pivotal-golang/lager"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ZonedUAAClient", func() {
+	var (
+		server   *httptest.Server
+		client   uaa.ZonedUAAClient
+		token    string
+		request  *http.Request
+		requests []*http.Request
+		status   int
+		body     func(*http.Request) string
+	)
+
+	BeforeEach(func() {
+		status = http.StatusOK
+		body = func(*http.Request) string {
+			return `{
+				"resources": [
+					{"id": "user-123", "locale": "fr-CA", "emails": [{"value": "user-123@example.com"}]},
+					{"id": "user-456", "emails": [{"value": "user-456@example.com"}]}
+				]
+			}`
+		}
+		requests = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			request = req
+			requests = append(requests, req)
+			w.WriteHeader(status)
+			w.Write([]byte(body(req)))
+		}))
+
+		keyFetcher := &mocks.KeyFetcher{}
+		keyFetcher.GetSigningKeysCall.Returns.Keys = []warrant.SigningKey{
+			{
+				KeyId:     "some-key",
+				Algorithm: "RS256",
+				Value:     helpers.UAAPublicKey,
+			},
+		}
+		validator := uaa.NewTokenValidator(lager.NewLogger("test"), keyFetcher)
+		Expect(validator.LoadSigningKeys()).To(Succeed())
+
+		token = helpers.BuildToken(map[string]interface{}{
+			"alg": "RS256",
+			"kid": "some-key",
+		}, map[string]interface{}{
+			"client_id": "mister-client",
+			"exp":       3404281214,
+			"iss":       server.URL + "/oauth/token",
+			"scope":     []string{"scim.read"},
+		})
+
+		client = uaa.NewZonedUAAClient("client-id", "client-secret", http.DefaultClient, validator)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	Describe("GetClientToken", func() {
+		It("requests a token with the client credentials", func() {
+			body = func(*http.Request) string {
+				return `{"access_token": "client-token"}`
+			}
+
+			clientToken, err := client.GetClientToken(server.URL)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientToken).To(Equal("client-token"))
+
+			Expect(request.Method).To(Equal("POST"))
+			Expect(request.URL.Path).To(Equal("/oauth/token"))
+
+			id, secret, ok := request.BasicAuth()
+			Expect(ok).To(BeTrue())
+			Expect(id).To(Equal("client-id"))
+			Expect(secret).To(Equal("client-secret"))
+		})
+	})
+
+	Describe("UsersEmailsByIDs", func() {
+		It("returns the emails and locale of each user", func() {
+			users, err := client.UsersEmailsByIDs(token, "user-123", "user-456")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(users).To(Equal([]uaa.User{
+				{ID: "user-123", Emails: []string{"user-123@example.com"}, Locale: "fr-CA"},
+				{ID: "user-456", Emails: []string{"user-456@example.com"}},
+			}))
+
+			Expect(request.URL.Path).To(Equal("/Users"))
+			Expect(request.URL.Query().Get("attributes")).To(Equal("emails,id,locale"))
+			Expect(request.URL.Query().Get("filter")).To(Equal(`Id eq "user-123" or Id eq "user-456"`))
+			Expect(request.Header.Get("Authorization")).To(Equal("Bearer " + token))
+		})
+
+		Context("when the UAA responds with an error", func() {
+			It("returns a failure", func() {
+				status = http.StatusNotFound
+
+				_, err := client.UsersEmailsByIDs(token, "user-123")
+				Expect(err).To(BeAssignableToTypeOf(uaa.Failure{}))
+				Expect(err.(uaa.Failure).Code()).To(Equal(http.StatusNotFound))
+			})
+		})
+	})
+	Describe("UsersGUIDsByGroup", func() {
+		BeforeEach(func() {
+			body = func(req *http.Request) string {
+				if req.URL.Query().Get("startIndex") == "1" {
+					return `{
+						"totalResults": 2,
+						"resources": [
+							{"members": [
+								{"value": "user-123", "type": "USER"},
+								{"value": "nested-group", "type": "GROUP"}
+							]}
+						]
+					}`
+				}
+
+				return `{
+					"totalResults": 2,
+					"resources": [
+						{"members": [{"value": "user-456", "type": "USER"}]}
+					]
+				}`
+			}
+		})
+
+		It("returns the users of the group from every page of results", func() {
+			guids, err := client.UsersGUIDsByGroup(token, "raptor.keepers")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(guids).To(Equal([]string{"user-123", "user-456"}))
+
+			Expect(requests).To(HaveLen(2))
+			Expect(requests[0].URL.Path).To(Equal("/Groups"))
+			Expect(requests[0].URL.Query().Get("attributes")).To(Equal("members"))
+			Expect(requests[0].URL.Query().Get("filter")).To(Equal(`displayName eq "raptor.keepers"`))
+			Expect(requests[0].URL.Query().Get("count")).To(Equal("100"))
+			Expect(requests[1].URL.Query().Get("startIndex")).To(Equal("2"))
+			Expect(requests[1].Header.Get("Authorization")).To(Equal("Bearer " + token))
+		})
+
+		It("returns an error when there is no such group", func() {
+			body = func(*http.Request) string {
+				return `{"totalResults": 0, "resources": []}`
+			}
+
+			_, err := client.UsersGUIDsByGroup(token, "raptor.keepers")
+			Expect(err).To(MatchError(uaa.GroupNotFoundError{Group: "raptor.keepers"}))
+		})
+
+		It("returns a failure when the UAA responds with an error", func() {
+			status = http.StatusForbidden
+
+			_, err := client.UsersGUIDsByGroup(token, "raptor.keepers")
+			Expect(err).To(BeAssignableToTypeOf(uaa.Failure{}))
+			Expect(err.(uaa.Failure).Code()).To(Equal(http.StatusForbidden))
+		})
+	})
+})
+
+var _ = Describe("SigningKeysFetcher", func() {
+	It("returns the keys the UAA signs tokens with", func() {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			Expect(req.URL.Path).To(Equal("/token_keys"))
+			w.Write([]byte(`{"keys": [{"kid": "some-key", "alg": "RS256", "value": "some-public-key"}]}`))
+		}))
+		defer server.Close()
+
+		keys, err := uaa.NewSigningKeysFetcher(server.URL, http.DefaultClient).GetSigningKeys()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(keys).To(Equal([]warrant.SigningKey{
+			{KeyId: "some-key", Algorithm: "RS256", Value: "some-public-key"},
+		}))
+	})
+})
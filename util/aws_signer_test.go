@@ -0,0 +1,52 @@
+package util_test
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/util"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AWSSigner", func() {
+	var (
+		signer  util.AWSSigner
+		request *http.Request
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		signer = util.AWSSigner{
+			AccessKeyID:     "some-access-key",
+			SecretAccessKey: "some-secret-key",
+			Region:          "eu-west-1",
+			Service:         "ses",
+		}
+
+		var err error
+		request, err = http.NewRequest("POST", "https://email.eu-west-1.amazonaws.com/v2/email/outbound-emails", strings.NewReader("{}"))
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Content-Type", "application/json")
+
+		now = time.Date(2015, time.March, 7, 12, 30, 0, 0, time.FixedZone("PST", -8*60*60))
+	})
+
+	It("signs the request with Signature Version 4", func() {
+		signer.Sign(request, []byte("{}"), now)
+
+		Expect(request.Header.Get("X-Amz-Date")).To(Equal("20150307T203000Z"))
+		Expect(request.Header.Get("X-Amz-Content-Sha256")).To(Equal("44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"))
+		Expect(request.Header.Get("Authorization")).To(Equal("AWS4-HMAC-SHA256 Credential=some-access-key/20150307/eu-west-1/ses/aws4_request, SignedHeaders=content-type;host;x-amz-content-sha256;x-amz-date, Signature=7610690e0cd0c1b84bd517ca56afd134cfc3c2946a1a35096b03bbe25a6b3715"))
+	})
+
+	It("signs different payloads differently", func() {
+		signer.Sign(request, []byte("{}"), now)
+		first := request.Header.Get("Authorization")
+
+		signer.Sign(request, []byte(`{"other":true}`), now)
+		Expect(request.Header.Get("Authorization")).NotTo(Equal(first))
+	})
+})
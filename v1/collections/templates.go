@@ -50,8 +50,10 @@ type Template struct {
 	Name     string
 	Text     string
 	HTML     string
+	Slack    string
 	Subject  string
 	Metadata string
+	ZoneID   string
 }
 
 type TemplatesCollection struct {
@@ -68,7 +70,9 @@ func NewTemplatesCollection(clientsRepo clientsRepository, kindsRepo kindsReposi
 	}
 }
 
-func (c TemplatesCollection) AssignToClient(conn ConnectionInterface, clientID, templateID string) error {
+// AssignToClient has the client rendered with the template, which must be
+// one the UAA zone of the request may see.
+func (c TemplatesCollection) AssignToClient(conn ConnectionInterface, zoneID, clientID, templateID string) error {
 	if templateID == "" {
 		templateID = models.DefaultTemplateID
 	}
@@ -78,7 +82,7 @@ func (c TemplatesCollection) AssignToClient(conn ConnectionInterface, clientID,
 		return err
 	}
 
-	err = c.findTemplate(conn, templateID)
+	err = c.findTemplate(conn, zoneID, templateID)
 	if err != nil {
 		return err
 	}
@@ -93,7 +97,7 @@ func (c TemplatesCollection) AssignToClient(conn ConnectionInterface, clientID,
 	return nil
 }
 
-func (c TemplatesCollection) AssignToNotification(conn ConnectionInterface, clientID, notificationID, templateID string) error {
+func (c TemplatesCollection) AssignToNotification(conn ConnectionInterface, zoneID, clientID, notificationID, templateID string) error {
 	if templateID == "" {
 		templateID = models.DefaultTemplateID
 	}
@@ -108,7 +112,7 @@ func (c TemplatesCollection) AssignToNotification(conn ConnectionInterface, clie
 		return err
 	}
 
-	err = c.findTemplate(conn, templateID)
+	err = c.findTemplate(conn, zoneID, templateID)
 	if err != nil {
 		return err
 	}
@@ -123,12 +127,12 @@ func (c TemplatesCollection) AssignToNotification(conn ConnectionInterface, clie
 	return nil
 }
 
-func (c TemplatesCollection) findTemplate(conn ConnectionInterface, templateID string) error {
+func (c TemplatesCollection) findTemplate(conn ConnectionInterface, zoneID, templateID string) error {
 	if templateID == "" {
 		return nil
 	}
 
-	_, err := c.templatesRepo.FindByID(conn, templateID)
+	template, err := c.templatesRepo.FindByID(conn, templateID)
 	if err != nil {
 		if _, ok := err.(models.NotFoundError); ok {
 			return TemplateAssignmentError{fmt.Errorf("No template with id %q", templateID)}
@@ -136,6 +140,10 @@ func (c TemplatesCollection) findTemplate(conn ConnectionInterface, templateID s
 		return err
 	}
 
+	if !models.TemplateInZone(zoneID, template) {
+		return TemplateAssignmentError{fmt.Errorf("No template with id %q", templateID)}
+	}
+
 	return nil
 }
 
@@ -178,8 +186,10 @@ func (c TemplatesCollection) Create(connection ConnectionInterface, template Tem
 		Name:     template.Name,
 		Text:     template.Text,
 		HTML:     template.HTML,
+		Slack:    template.Slack,
 		Subject:  template.Subject,
 		Metadata: template.Metadata,
+		ZoneID:   template.ZoneID,
 	})
 	if err != nil {
 		return Template{}, err
@@ -190,8 +200,10 @@ func (c TemplatesCollection) Create(connection ConnectionInterface, template Tem
 		Name:     tmpl.Name,
 		Text:     tmpl.Text,
 		HTML:     tmpl.HTML,
+		Slack:    tmpl.Slack,
 		Subject:  tmpl.Subject,
 		Metadata: tmpl.Metadata,
+		ZoneID:   tmpl.ZoneID,
 	}, nil
 }
 
@@ -39,7 +39,7 @@ var _ = Describe("TemplatesCollection", func() {
 		})
 
 		It("assigns the template to the given client", func() {
-			err := collection.AssignToClient(conn, "my-client", "my-template")
+			err := collection.AssignToClient(conn, "uaa", "my-client", "my-template")
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(conn))
@@ -56,16 +56,27 @@ var _ = Describe("TemplatesCollection", func() {
 			It("reports that the client cannot be found", func() {
 				clientsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
 
-				err := collection.AssignToClient(conn, "missing-client", "my-template")
+				err := collection.AssignToClient(conn, "uaa", "missing-client", "my-template")
 				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
 			})
 
 			It("reports that the template cannot be found", func() {
 				templatesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
 
-				err := collection.AssignToClient(conn, "my-client", "non-existant-template")
+				err := collection.AssignToClient(conn, "uaa", "my-client", "non-existant-template")
 				Expect(err).To(MatchError(collections.TemplateAssignmentError{Err: errors.New("No template with id \"non-existant-template\"")}))
 			})
+
+			It("reports that a template of another zone cannot be found", func() {
+				templatesRepo.FindByIDCall.Returns.Template = models.Template{
+					ID:     "other-template",
+					ZoneID: "tenant-b",
+				}
+
+				err := collection.AssignToClient(conn, "tenant-a", "tenant-a:my-client", "other-template")
+				Expect(err).To(MatchError(collections.TemplateAssignmentError{Err: errors.New("No template with id \"other-template\"")}))
+				Expect(clientsRepo.UpdateCall.Receives.Client).To(Equal(models.Client{}))
+			})
 		})
 
 		Context("when the request should reset the template assignment", func() {
@@ -77,7 +88,7 @@ var _ = Describe("TemplatesCollection", func() {
 			})
 
 			It("allows template id of empty string to reset the assignment", func() {
-				err := collection.AssignToClient(conn, "my-client", "")
+				err := collection.AssignToClient(conn, "uaa", "my-client", "")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(conn))
@@ -91,7 +102,7 @@ var _ = Describe("TemplatesCollection", func() {
 			})
 
 			It("allows template id of default template id to reset the assignment", func() {
-				err := collection.AssignToClient(conn, "my-client", models.DefaultTemplateID)
+				err := collection.AssignToClient(conn, "uaa", "my-client", models.DefaultTemplateID)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(clientsRepo.FindCall.Receives.Connection).To(Equal(conn))
@@ -110,7 +121,7 @@ var _ = Describe("TemplatesCollection", func() {
 				It("returns any errors it doesn't understand", func() {
 					clientsRepo.FindCall.Returns.Error = errors.New("database connection failure")
 
-					err := collection.AssignToClient(conn, "my-client", "my-template")
+					err := collection.AssignToClient(conn, "uaa", "my-client", "my-template")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(Equal("database connection failure"))
 				})
@@ -119,7 +130,7 @@ var _ = Describe("TemplatesCollection", func() {
 				It("returns any errors it doesn't understand (part 2)", func() {
 					templatesRepo.FindByIDCall.Returns.Error = errors.New("database failure")
 
-					err := collection.AssignToClient(conn, "my-client", "my-template")
+					err := collection.AssignToClient(conn, "uaa", "my-client", "my-template")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(Equal("database failure"))
 
@@ -130,7 +141,7 @@ var _ = Describe("TemplatesCollection", func() {
 				It("Returns the error", func() {
 					clientsRepo.UpdateCall.Returns.Error = errors.New("database fail")
 
-					err := collection.AssignToClient(conn, "my-client", "my-template")
+					err := collection.AssignToClient(conn, "uaa", "my-client", "my-template")
 					Expect(err).To(HaveOccurred())
 				})
 			})
@@ -155,7 +166,7 @@ var _ = Describe("TemplatesCollection", func() {
 		})
 
 		It("assigns the template to the given kind", func() {
-			err := collection.AssignToNotification(conn, "my-client", "my-kind", "my-template")
+			err := collection.AssignToNotification(conn, "uaa", "my-client", "my-kind", "my-template")
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(kindsRepo.UpdateCall.Receives.Kind).To(Equal(models.Kind{
@@ -169,14 +180,14 @@ var _ = Describe("TemplatesCollection", func() {
 			It("reports that the client cannot be found", func() {
 				kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
 
-				err := collection.AssignToNotification(conn, "bad-client", "my-kind", "my-template")
+				err := collection.AssignToNotification(conn, "uaa", "bad-client", "my-kind", "my-template")
 				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
 			})
 
 			It("reports that the kind cannot be found", func() {
 				kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
 
-				err := collection.AssignToNotification(conn, "my-client", "bad-kind", "my-template")
+				err := collection.AssignToNotification(conn, "uaa", "my-client", "bad-kind", "my-template")
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{Err: errors.New("not found")}))
 			})
@@ -184,7 +195,7 @@ var _ = Describe("TemplatesCollection", func() {
 			It("reports that the template cannot be found", func() {
 				templatesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
 
-				err := collection.AssignToNotification(conn, "my-client", "my-kind", "non-existant-template")
+				err := collection.AssignToNotification(conn, "uaa", "my-client", "my-kind", "non-existant-template")
 				Expect(err).To(MatchError(collections.TemplateAssignmentError{Err: errors.New("No template with id \"non-existant-template\"")}))
 			})
 		})
@@ -195,7 +206,7 @@ var _ = Describe("TemplatesCollection", func() {
 			})
 
 			It("allows template id of empty string to reset the assignment", func() {
-				err := collection.AssignToNotification(conn, "my-client", "my-kind", "")
+				err := collection.AssignToNotification(conn, "uaa", "my-client", "my-kind", "")
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(kindsRepo.UpdateCall.Receives.Kind).To(Equal(models.Kind{
@@ -206,7 +217,7 @@ var _ = Describe("TemplatesCollection", func() {
 			})
 
 			It("allows template id of default template id to reset the assignment", func() {
-				err := collection.AssignToNotification(conn, "my-client", "my-kind", models.DefaultTemplateID)
+				err := collection.AssignToNotification(conn, "uaa", "my-client", "my-kind", models.DefaultTemplateID)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(kindsRepo.UpdateCall.Receives.Kind).To(Equal(models.Kind{
@@ -222,7 +233,7 @@ var _ = Describe("TemplatesCollection", func() {
 				It("returns any errors it doesn't understand", func() {
 					clientsRepo.FindCall.Returns.Error = errors.New("database connection failure")
 
-					err := collection.AssignToNotification(conn, "my-client", "my-kind", "my-template")
+					err := collection.AssignToNotification(conn, "uaa", "my-client", "my-kind", "my-template")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(Equal("database connection failure"))
 				})
@@ -231,7 +242,7 @@ var _ = Describe("TemplatesCollection", func() {
 				It("returns any errors it doesn't understand (part 2)", func() {
 					templatesRepo.FindByIDCall.Returns.Error = errors.New("database failure")
 
-					err := collection.AssignToNotification(conn, "my-client", "my-kind", "my-template")
+					err := collection.AssignToNotification(conn, "uaa", "my-client", "my-kind", "my-template")
 					Expect(err).To(HaveOccurred())
 					Expect(err.Error()).To(Equal("database failure"))
 
@@ -242,7 +253,7 @@ var _ = Describe("TemplatesCollection", func() {
 				It("Returns the error", func() {
 					kindsRepo.UpdateCall.Returns.Error = errors.New("database fail")
 
-					err := collection.AssignToNotification(conn, "my-client", "my-kind", "my-template")
+					err := collection.AssignToNotification(conn, "uaa", "my-client", "my-kind", "my-template")
 					Expect(err).To(HaveOccurred())
 				})
 			})
@@ -334,6 +345,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				ZoneID:   "tenant-a",
 			}
 
 			template, err := collection.Create(conn, collections.Template{
@@ -342,6 +354,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				ZoneID:   "tenant-a",
 			})
 			Expect(err).ToNot(HaveOccurred())
 			Expect(template).To(Equal(collections.Template{
@@ -351,6 +364,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				ZoneID:   "tenant-a",
 			}))
 
 			Expect(templatesRepo.CreateCall.Receives.Connection).To(Equal(conn))
@@ -360,6 +374,7 @@ var _ = Describe("TemplatesCollection", func() {
 				HTML:     "some-html",
 				Subject:  "some-subject",
 				Metadata: "some-metadata",
+				ZoneID:   "tenant-a",
 			}))
 		})
 
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+// AuditEvent records an administrative action: who took it, through which
+// request, and when. Events are only ever appended.
+type AuditEvent struct {
+	Primary       int       `db:"primary"`
+	Action        string    `db:"action"`
+	ClientID      string    `db:"client_id"`
+	UserID        string    `db:"user_id"`
+	Method        string    `db:"method"`
+	Path          string    `db:"path"`
+	Details       string    `db:"details"`
+	VCAPRequestID string    `db:"vcap_request_id"`
+	CreatedAt     time.Time `db:"created_at"`
+}
+
+// AuditEventFilter narrows a listing of audit events. Empty fields match
+// every event.
+type AuditEventFilter struct {
+	Action   string
+	ClientID string
+	UserID   string
+	Since    time.Time
+	Until    time.Time
+}
+
+func (e *AuditEvent) PreInsert(s gorp.SqlExecutor) error {
+	if e.CreatedAt.IsZero() {
+		e.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	}
+
+	return nil
+}
@@ -0,0 +1,79 @@
+package models
+
+import "strings"
+
+type AuditEventsRepo struct{}
+
+func NewAuditEventsRepo() AuditEventsRepo {
+	return AuditEventsRepo{}
+}
+
+func (repo AuditEventsRepo) Create(conn ConnectionInterface, event AuditEvent) (AuditEvent, error) {
+	err := conn.Insert(&event)
+	if err != nil {
+		return AuditEvent{}, err
+	}
+
+	return event, nil
+}
+
+// List returns a page of the events matching filter, newest first.
+func (repo AuditEventsRepo) List(conn ConnectionInterface, filter AuditEventFilter, offset, limit int) ([]AuditEvent, error) {
+	where, args := auditEventFilterClause(filter)
+
+	events := []AuditEvent{}
+	_, err := conn.Select(&events, "SELECT * FROM `audit_events`"+where+" ORDER BY `primary` DESC LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return []AuditEvent{}, err
+	}
+
+	return events, nil
+}
+
+func (repo AuditEventsRepo) Count(conn ConnectionInterface, filter AuditEventFilter) (int, error) {
+	where, args := auditEventFilterClause(filter)
+
+	var count int
+	err := conn.SelectOne(&count, "SELECT COUNT(*) FROM `audit_events`"+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func auditEventFilterClause(filter AuditEventFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.Action != "" {
+		conditions = append(conditions, "`action` = ?")
+		args = append(args, filter.Action)
+	}
+
+	if filter.ClientID != "" {
+		conditions = append(conditions, "`client_id` = ?")
+		args = append(args, filter.ClientID)
+	}
+
+	if filter.UserID != "" {
+		conditions = append(conditions, "`user_id` = ?")
+		args = append(args, filter.UserID)
+	}
+
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "`created_at` >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+
+	if !filter.Until.IsZero() {
+		conditions = append(conditions, "`created_at` < ?")
+		args = append(args, filter.Until.UTC())
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
@@ -0,0 +1,100 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AuditEventsRepo", func() {
+	var (
+		repo models.AuditEventsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewAuditEventsRepo()
+	})
+
+	Describe("Create", func() {
+		It("records the event and when it happened", func() {
+			event, err := repo.Create(conn, models.AuditEvent{
+				Action:        "template.update",
+				ClientID:      "some-client",
+				UserID:        "some-user",
+				Method:        "PUT",
+				Path:          "/templates/some-template",
+				VCAPRequestID: "some-request-id",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(event.Primary).NotTo(BeZero())
+			Expect(event.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+
+			events, err := repo.List(conn, models.AuditEventFilter{}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal("template.update"))
+			Expect(events[0].UserID).To(Equal("some-user"))
+			Expect(events[0].Path).To(Equal("/templates/some-template"))
+		})
+	})
+
+	Describe("List and Count", func() {
+		BeforeEach(func() {
+			createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+			for i, event := range []models.AuditEvent{
+				{Action: "registration.update", ClientID: "client-a", Method: "PUT", Path: "/notifications"},
+				{Action: "template.create", ClientID: "client-a", UserID: "user-1", Method: "POST", Path: "/templates"},
+				{Action: "preferences.update", ClientID: "client-b", UserID: "user-1", Method: "PATCH", Path: "/user_preferences"},
+			} {
+				event.CreatedAt = createdAt.Add(time.Duration(i) * time.Hour)
+				_, err := repo.Create(conn, event)
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("lists every event, newest first", func() {
+			events, err := repo.List(conn, models.AuditEventFilter{}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(3))
+			Expect(events[0].Action).To(Equal("preferences.update"))
+			Expect(events[2].Action).To(Equal("registration.update"))
+
+			count, err := repo.Count(conn, models.AuditEventFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(3))
+		})
+
+		It("filters by action, actor and time", func() {
+			filter := models.AuditEventFilter{
+				UserID: "user-1",
+				Since:  time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC),
+				Until:  time.Date(2026, 10, 1, 13, 30, 0, 0, time.UTC),
+			}
+
+			events, err := repo.List(conn, filter, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal("template.create"))
+
+			count, err := repo.Count(conn, models.AuditEventFilter{ClientID: "client-a", Action: "registration.update"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+
+		It("pages through the events", func() {
+			events, err := repo.List(conn, models.AuditEventFilter{ClientID: "client-a"}, 1, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Action).To(Equal("registration.update"))
+		})
+	})
+})
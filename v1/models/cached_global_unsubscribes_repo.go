@@ -0,0 +1,48 @@
+package models
+
+type globalUnsubscribesStore interface {
+	Get(conn ConnectionInterface, userGUID string) (bool, error)
+	Set(conn ConnectionInterface, userGUID string, unsubscribe bool) error
+}
+
+// CachedGlobalUnsubscribesRepo is the global counterpart of
+// CachedUnsubscribesRepo.
+type CachedGlobalUnsubscribesRepo struct {
+	repo  globalUnsubscribesStore
+	cache preferencesCache
+}
+
+func NewCachedGlobalUnsubscribesRepo(repo globalUnsubscribesStore, cache preferencesCache) CachedGlobalUnsubscribesRepo {
+	return CachedGlobalUnsubscribesRepo{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+func (repo CachedGlobalUnsubscribesRepo) Get(conn ConnectionInterface, userGUID string) (bool, error) {
+	key := cacheKey("global_unsubscribes", userGUID)
+
+	if unsubscribed, ok := getCached(repo.cache, key); ok {
+		return unsubscribed, nil
+	}
+
+	unsubscribed, err := repo.repo.Get(conn, userGUID)
+	if err != nil {
+		return false, err
+	}
+
+	if cacheable(conn) {
+		setCached(repo.cache, key, unsubscribed)
+	}
+
+	return unsubscribed, nil
+}
+
+func (repo CachedGlobalUnsubscribesRepo) Set(conn ConnectionInterface, userGUID string, unsubscribe bool) error {
+	err := repo.repo.Set(conn, userGUID, unsubscribe)
+	if err != nil {
+		return err
+	}
+
+	return invalidate(conn, repo.cache, cacheKey("global_unsubscribes", userGUID))
+}
@@ -0,0 +1,75 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachedGlobalUnsubscribesRepo", func() {
+	var (
+		repo                   models.CachedGlobalUnsubscribesRepo
+		globalUnsubscribesRepo *mocks.GlobalUnsubscribesRepo
+		cache                  *mocks.PreferencesCache
+		conn                   *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		globalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepo()
+		cache = mocks.NewPreferencesCache()
+
+		repo = models.NewCachedGlobalUnsubscribesRepo(globalUnsubscribesRepo, cache)
+	})
+
+	It("returns cached values without querying the database", func() {
+		cache.GetCall.Returns.Value = "0"
+		cache.GetCall.Returns.Found = true
+		globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+		unsubscribed, err := repo.Get(conn, "user-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeFalse())
+
+		Expect(cache.GetCall.Receives.Key).To(Equal("notifications:global_unsubscribes:user-123"))
+		Expect(globalUnsubscribesRepo.GetCall.Receives.UserID).To(BeEmpty())
+	})
+
+	It("loads and caches values that are not cached", func() {
+		globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+		unsubscribed, err := repo.Get(conn, "user-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeTrue())
+
+		Expect(globalUnsubscribesRepo.GetCall.Receives.UserID).To(Equal("user-123"))
+		Expect(cache.SetCall.Receives.Key).To(Equal("notifications:global_unsubscribes:user-123"))
+		Expect(cache.SetCall.Receives.Value).To(Equal("1"))
+	})
+
+	It("does not cache values read from a replica", func() {
+		conn.ReplicaCall.Returns.Replica = true
+
+		_, err := repo.Get(conn, "user-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cache.SetCall.WasCalled).To(BeFalse())
+	})
+
+	It("removes the cached value once the transaction commits", func() {
+		transaction := mocks.NewTransaction()
+		transaction.Connection = conn
+
+		err := repo.Set(transaction, "user-123", true)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(globalUnsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+		Expect(globalUnsubscribesRepo.SetCall.Receives.Unsubscribed).To(BeTrue())
+		Expect(cache.SetCall.WasCalled).To(BeFalse())
+
+		Expect(transaction.Commit()).To(Succeed())
+		Expect(cache.DelCall.CallCount).To(Equal(2))
+		Expect(cache.DelCall.Receives.Key).To(Equal("notifications:global_unsubscribes:user-123"))
+	})
+})
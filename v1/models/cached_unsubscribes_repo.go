@@ -0,0 +1,157 @@
+package models
+
+import (
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rcrowley/go-metrics"
+)
+
+// PreferencesCacheTTL bounds how long a cached unsubscribe can disagree with
+// MySQL, for instance when its key could not be removed after a write.
+const PreferencesCacheTTL = time.Hour
+
+type preferencesCache interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string, ttl time.Duration) error
+	Del(key string) error
+}
+
+type afterCommitter interface {
+	AfterCommit(callback func())
+}
+
+type transactionCommitter interface {
+	Commit() error
+}
+
+type replicaConnection interface {
+	Replica() bool
+}
+
+type unsubscribesStore interface {
+	Get(conn ConnectionInterface, userID, clientID, kindID string) (bool, error)
+	Set(conn ConnectionInterface, userID, clientID, kindID string, unsubscribe bool) error
+}
+
+// CachedUnsubscribesRepo serves unsubscribe lookups from a cache, falling
+// back to MySQL when the cache misses or is unavailable. Only reads from the
+// primary outside a transaction fill the cache, and writes remove the key
+// once they are committed, so the next read loads the new value.
+type CachedUnsubscribesRepo struct {
+	repo  unsubscribesStore
+	cache preferencesCache
+}
+
+func NewCachedUnsubscribesRepo(repo unsubscribesStore, cache preferencesCache) CachedUnsubscribesRepo {
+	return CachedUnsubscribesRepo{
+		repo:  repo,
+		cache: cache,
+	}
+}
+
+func (repo CachedUnsubscribesRepo) Get(conn ConnectionInterface, userID, clientID, kindID string) (bool, error) {
+	key := cacheKey("unsubscribes", userID, clientID, kindID)
+
+	if unsubscribed, ok := getCached(repo.cache, key); ok {
+		return unsubscribed, nil
+	}
+
+	unsubscribed, err := repo.repo.Get(conn, userID, clientID, kindID)
+	if err != nil {
+		return false, err
+	}
+
+	if cacheable(conn) {
+		setCached(repo.cache, key, unsubscribed)
+	}
+
+	return unsubscribed, nil
+}
+
+func (repo CachedUnsubscribesRepo) Set(conn ConnectionInterface, userID, clientID, kindID string, unsubscribe bool) error {
+	err := repo.repo.Set(conn, userID, clientID, kindID, unsubscribe)
+	if err != nil {
+		return err
+	}
+
+	return invalidate(conn, repo.cache, cacheKey("unsubscribes", userID, clientID, kindID))
+}
+
+func cacheKey(kind string, parts ...string) string {
+	escaped := []string{"notifications", kind}
+	for _, part := range parts {
+		escaped = append(escaped, url.QueryEscape(part))
+	}
+
+	return strings.Join(escaped, ":")
+}
+
+func getCached(cache preferencesCache, key string) (bool, bool) {
+	value, ok, err := cache.Get(key)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.preferences.cache.error", nil).Inc(1)
+		return false, false
+	}
+
+	if !ok {
+		metrics.GetOrRegisterCounter("notifications.preferences.cache.miss", nil).Inc(1)
+		return false, false
+	}
+
+	metrics.GetOrRegisterCounter("notifications.preferences.cache.hit", nil).Inc(1)
+	return value == "1", true
+}
+
+func setCached(cache preferencesCache, key string, unsubscribed bool) {
+	err := cache.Set(key, cacheValue(unsubscribed), PreferencesCacheTTL)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.preferences.cache.error", nil).Inc(1)
+	}
+}
+
+// cacheable reports whether a value read on conn may be cached. A replica can
+// lag behind the primary, and a transaction can read its own writes before
+// they are committed or rolled back.
+func cacheable(conn ConnectionInterface) bool {
+	if _, ok := conn.(transactionCommitter); ok {
+		return false
+	}
+
+	if replica, ok := conn.(replicaConnection); ok && replica.Replica() {
+		return false
+	}
+
+	return true
+}
+
+// invalidate must not leave the old value behind, or users would keep
+// receiving mail they unsubscribed from. The key is removed straight away,
+// failing the write when the cache cannot be reached, and removed again once
+// the transaction commits, in case a read cached the old value in between.
+func invalidate(conn ConnectionInterface, cache preferencesCache, key string) error {
+	err := cache.Del(key)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.preferences.cache.error", nil).Inc(1)
+		return err
+	}
+
+	if transaction, ok := conn.(afterCommitter); ok {
+		transaction.AfterCommit(func() {
+			if cache.Del(key) != nil {
+				metrics.GetOrRegisterCounter("notifications.preferences.cache.error", nil).Inc(1)
+			}
+		})
+	}
+
+	return nil
+}
+
+func cacheValue(unsubscribed bool) string {
+	if unsubscribed {
+		return "1"
+	}
+
+	return "0"
+}
@@ -0,0 +1,156 @@
+package models_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CachedUnsubscribesRepo", func() {
+	var (
+		repo             models.CachedUnsubscribesRepo
+		unsubscribesRepo *mocks.UnsubscribesRepo
+		cache            *mocks.PreferencesCache
+		conn             *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		unsubscribesRepo = mocks.NewUnsubscribesRepo()
+		cache = mocks.NewPreferencesCache()
+
+		repo = models.NewCachedUnsubscribesRepo(unsubscribesRepo, cache)
+	})
+
+	Describe("Get", func() {
+		It("returns cached values without querying the database", func() {
+			cache.GetCall.Returns.Value = "1"
+			cache.GetCall.Returns.Found = true
+
+			unsubscribed, err := repo.Get(conn, "user-123", "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+
+			Expect(cache.GetCall.Receives.Key).To(Equal("notifications:unsubscribes:user-123:raptors:feeding-time"))
+			Expect(unsubscribesRepo.GetCall.Receives.UserID).To(BeEmpty())
+		})
+
+		It("loads and caches values that are not cached", func() {
+			unsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+			unsubscribed, err := repo.Get(conn, "user-123", "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+
+			Expect(unsubscribesRepo.GetCall.Receives.Connection).To(Equal(conn))
+			Expect(unsubscribesRepo.GetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(unsubscribesRepo.GetCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(unsubscribesRepo.GetCall.Receives.KindID).To(Equal("feeding-time"))
+
+			Expect(cache.SetCall.Receives.Key).To(Equal("notifications:unsubscribes:user-123:raptors:feeding-time"))
+			Expect(cache.SetCall.Receives.Value).To(Equal("1"))
+			Expect(cache.SetCall.Receives.TTL).To(Equal(models.PreferencesCacheTTL))
+		})
+
+		It("escapes the parts of the key", func() {
+			_, err := repo.Get(conn, "user:123", "raptors", "feeding time")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(cache.GetCall.Receives.Key).To(Equal("notifications:unsubscribes:user%3A123:raptors:feeding+time"))
+		})
+
+		It("falls back to the database when the cache is unavailable", func() {
+			cache.GetCall.Returns.Error = errors.New("connection refused")
+			unsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+			unsubscribed, err := repo.Get(conn, "user-123", "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+		})
+
+		It("does not cache values read from a replica", func() {
+			conn.ReplicaCall.Returns.Replica = true
+			unsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+			unsubscribed, err := repo.Get(conn, "user-123", "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+			Expect(cache.SetCall.WasCalled).To(BeFalse())
+		})
+
+		It("does not cache values read in a transaction", func() {
+			transaction := mocks.NewTransaction()
+			transaction.Connection = conn
+
+			_, err := repo.Get(transaction, "user-123", "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cache.SetCall.WasCalled).To(BeFalse())
+		})
+
+		It("returns database errors without caching anything", func() {
+			unsubscribesRepo.GetCall.Returns.Error = errors.New("database is down")
+
+			_, err := repo.Get(conn, "user-123", "raptors", "feeding-time")
+			Expect(err).To(MatchError(errors.New("database is down")))
+			Expect(cache.SetCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Describe("Set", func() {
+		It("removes the cached value instead of writing the new one", func() {
+			err := repo.Set(conn, "user-123", "raptors", "feeding-time", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(unsubscribesRepo.SetCall.Receives.Connection).To(Equal(conn))
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeFalse())
+
+			Expect(cache.DelCall.Receives.Key).To(Equal("notifications:unsubscribes:user-123:raptors:feeding-time"))
+			Expect(cache.SetCall.WasCalled).To(BeFalse())
+		})
+
+		It("removes the cached value again once the transaction commits", func() {
+			transaction := mocks.NewTransaction()
+			transaction.Connection = conn
+
+			err := repo.Set(transaction, "user-123", "raptors", "feeding-time", true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cache.DelCall.CallCount).To(Equal(1))
+
+			Expect(transaction.Commit()).To(Succeed())
+			Expect(cache.DelCall.CallCount).To(Equal(2))
+			Expect(cache.DelCall.Receives.Key).To(Equal("notifications:unsubscribes:user-123:raptors:feeding-time"))
+		})
+
+		It("does not remove the cached value again when the transaction fails to commit", func() {
+			transaction := mocks.NewTransaction()
+			transaction.Connection = conn
+			transaction.CommitCall.Returns.Error = errors.New("deadlock")
+
+			err := repo.Set(transaction, "user-123", "raptors", "feeding-time", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(transaction.Commit()).To(MatchError("deadlock"))
+			Expect(cache.DelCall.CallCount).To(Equal(1))
+		})
+
+		It("does not touch the cache when the database write fails", func() {
+			unsubscribesRepo.SetCall.Returns.Error = errors.New("database is down")
+
+			err := repo.Set(conn, "user-123", "raptors", "feeding-time", true)
+			Expect(err).To(MatchError(errors.New("database is down")))
+			Expect(cache.DelCall.WasCalled).To(BeFalse())
+		})
+
+		It("fails when the stale value cannot be removed", func() {
+			cache.DelCall.Returns.Error = errors.New("connection refused")
+
+			err := repo.Set(conn, "user-123", "raptors", "feeding-time", true)
+			Expect(err).To(MatchError(errors.New("connection refused")))
+		})
+	})
+})
@@ -1,17 +1,106 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gopkg.in/gorp.v1"
 )
 
 type Client struct {
-	Primary     int       `db:"primary"`
-	ID          string    `db:"id"`
-	Description string    `db:"description"`
-	CreatedAt   time.Time `db:"created_at"`
-	TemplateID  string    `db:"template_id"`
+	Primary     int         `db:"primary"`
+	ID          string      `db:"id"`
+	Description string      `db:"description"`
+	CreatedAt   time.Time   `db:"created_at"`
+	TemplateID  string      `db:"template_id"`
+	CallbackURL string      `db:"callback_url"`
+	LinkDomains LinkDomains `db:"link_domains"`
+	SenderName  string      `db:"sender_name"`
+
+	// SimulationSinks puts the client in simulation mode when it is not
+	// empty: its messages are processed as usual, but only ever sent to
+	// these addresses.
+	SimulationSinks SimulationSinks `db:"simulation_sinks"`
+
+	// EngagementTracking opts the client in to recording when its HTML
+	// messages are opened and their links followed.
+	EngagementTracking bool `db:"engagement_tracking"`
+}
+
+// LinkDomains maps platform hostnames to the branded hostnames that links
+// in a client's messages are rewritten to. It is stored as a JSON object.
+type LinkDomains map[string]string
+
+func (d LinkDomains) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(encoded), nil
+}
+
+func (d *LinkDomains) Scan(src interface{}) error {
+	var encoded []byte
+	switch value := src.(type) {
+	case nil:
+	case string:
+		encoded = []byte(value)
+	case []byte:
+		encoded = value
+	default:
+		return fmt.Errorf("cannot scan %T into LinkDomains", src)
+	}
+
+	if len(encoded) == 0 {
+		*d = nil
+		return nil
+	}
+
+	return json.Unmarshal(encoded, d)
+}
+
+// SimulationSinks lists the email addresses that receive the messages of a
+// client in simulation mode. It is stored as a JSON array.
+type SimulationSinks []string
+
+func (s SimulationSinks) Value() (driver.Value, error) {
+	if len(s) == 0 {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(s)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(encoded), nil
+}
+
+func (s *SimulationSinks) Scan(src interface{}) error {
+	var encoded []byte
+	switch value := src.(type) {
+	case nil:
+	case string:
+		encoded = []byte(value)
+	case []byte:
+		encoded = value
+	default:
+		return fmt.Errorf("cannot scan %T into SimulationSinks", src)
+	}
+
+	if len(encoded) == 0 {
+		*s = nil
+		return nil
+	}
+
+	return json.Unmarshal(encoded, s)
 }
 
 func (c Client) TemplateToUse() string {
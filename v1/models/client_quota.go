@@ -0,0 +1,47 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ClientQuota caps how many notifications a client may send in each
+// calendar month, counted in UTC.
+type ClientQuota struct {
+	Primary      int       `db:"primary"`
+	ClientID     string    `db:"client_id"`
+	MonthlyLimit int       `db:"monthly_limit"`
+	CreatedAt    time.Time `db:"created_at"`
+	UpdatedAt    time.Time `db:"updated_at"`
+}
+
+// ClientQuotaUsage is how many notifications a client has sent in a month,
+// whether or not it has a quota.
+type ClientQuotaUsage struct {
+	ClientID string `db:"client_id"`
+	Month    string `db:"month"`
+	Used     int    `db:"used"`
+}
+
+// QuotaMonth names the month that at counts towards, such as "2026-10".
+func QuotaMonth(at time.Time) string {
+	return at.UTC().Format("2006-01")
+}
+
+// QuotaResetsAt is when the month that at counts towards ends.
+func QuotaResetsAt(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+type QuotaExceededError struct {
+	ClientID  string
+	Limit     int
+	Used      int
+	Requested int
+	ResetsAt  time.Time
+}
+
+func (e QuotaExceededError) Error() string {
+	return fmt.Sprintf("Client %q has sent %d of its %d notifications this month and cannot send %d more until the quota resets at %s", e.ClientID, e.Used, e.Limit, e.Requested, e.ResetsAt.Format(time.RFC3339))
+}
@@ -0,0 +1,129 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type ClientQuotasRepo struct{}
+
+func NewClientQuotasRepo() ClientQuotasRepo {
+	return ClientQuotasRepo{}
+}
+
+func (repo ClientQuotasRepo) Find(conn ConnectionInterface, clientID string) (ClientQuota, error) {
+	quota := ClientQuota{}
+	err := conn.SelectOne(&quota, "SELECT * FROM `client_quotas` WHERE `client_id` = ?", clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return quota, NotFoundError{Err: fmt.Errorf("Client %q does not have a quota", clientID), Code: "client_quota_not_found"}
+		}
+		return quota, err
+	}
+
+	return quota, nil
+}
+
+func (repo ClientQuotasRepo) Upsert(conn ConnectionInterface, quota ClientQuota) (ClientQuota, error) {
+	existing, err := repo.Find(conn, quota.ClientID)
+	if err != nil {
+		if _, ok := err.(NotFoundError); !ok {
+			return quota, err
+		}
+	}
+
+	now := time.Now().Truncate(1 * time.Second).UTC()
+	quota.Primary = existing.Primary
+	quota.CreatedAt = existing.CreatedAt
+	quota.UpdatedAt = now
+
+	if quota.Primary == 0 {
+		quota.CreatedAt = now
+		err = conn.Insert(&quota)
+	} else {
+		_, err = conn.Update(&quota)
+	}
+	if err != nil {
+		return quota, err
+	}
+
+	return quota, nil
+}
+
+func (repo ClientQuotasRepo) Delete(conn ConnectionInterface, clientID string) error {
+	result, err := conn.Exec("DELETE FROM `client_quotas` WHERE `client_id` = ?", clientID)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return NotFoundError{Err: fmt.Errorf("Client %q does not have a quota", clientID), Code: "client_quota_not_found"}
+	}
+
+	return nil
+}
+
+// Usage returns how many notifications the client has sent in the month
+// that at falls in.
+func (repo ClientQuotasRepo) Usage(conn ConnectionInterface, clientID string, at time.Time) (int, error) {
+	usage := ClientQuotaUsage{}
+	err := conn.SelectOne(&usage, "SELECT * FROM `client_quota_usage` WHERE `client_id` = ? AND `month` = ?", clientID, QuotaMonth(at))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return usage.Used, nil
+}
+
+// Consume counts count notifications against the client's usage for the
+// month that at falls in. When the client has a quota that they would take
+// it over, nothing is counted and a QuotaExceededError is returned. The
+// usage row of a client with a quota is locked until the connection's
+// transaction ends, so that concurrent requests cannot overrun the quota
+// together; the usage of other clients is only added to.
+func (repo ClientQuotasRepo) Consume(conn ConnectionInterface, clientID string, count int, at time.Time) error {
+	month := QuotaMonth(at)
+
+	quota, err := repo.Find(conn, clientID)
+	if err != nil {
+		if _, ok := err.(NotFoundError); !ok {
+			return err
+		}
+
+		_, err = conn.Exec("INSERT INTO `client_quota_usage` (`client_id`, `month`, `used`) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE `used` = `used` + VALUES(`used`)", clientID, month, count)
+		return err
+	}
+
+	_, err = conn.Exec("INSERT INTO `client_quota_usage` (`client_id`, `month`, `used`) VALUES (?, ?, 0) ON DUPLICATE KEY UPDATE `used` = `used`", clientID, month)
+	if err != nil {
+		return err
+	}
+
+	usage := ClientQuotaUsage{}
+	err = conn.SelectOne(&usage, "SELECT * FROM `client_quota_usage` WHERE `client_id` = ? AND `month` = ? FOR UPDATE", clientID, month)
+	if err != nil {
+		return err
+	}
+
+	if usage.Used+count > quota.MonthlyLimit {
+		return QuotaExceededError{
+			ClientID:  clientID,
+			Limit:     quota.MonthlyLimit,
+			Used:      usage.Used,
+			Requested: count,
+			ResetsAt:  QuotaResetsAt(at),
+		}
+	}
+
+	_, err = conn.Exec("UPDATE `client_quota_usage` SET `used` = `used` + ? WHERE `client_id` = ? AND `month` = ?", count, clientID, month)
+	return err
+}
@@ -0,0 +1,116 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientQuotasRepo", func() {
+	var (
+		repo models.ClientQuotasRepo
+		conn *db.Connection
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		repo = models.NewClientQuotasRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+
+		conn = database.Connection().(*db.Connection)
+		now = time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	})
+
+	Describe("Upsert", func() {
+		It("creates and then updates the quota of the client", func() {
+			created, err := repo.Upsert(conn, models.ClientQuota{ClientID: "some-client", MonthlyLimit: 100})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(created.Primary).NotTo(BeZero())
+
+			updated, err := repo.Upsert(conn, models.ClientQuota{ClientID: "some-client", MonthlyLimit: 200})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Primary).To(Equal(created.Primary))
+
+			quota, err := repo.Find(conn, "some-client")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(quota.MonthlyLimit).To(Equal(200))
+		})
+	})
+
+	Describe("Find", func() {
+		It("returns a NotFoundError when the client has no quota", func() {
+			_, err := repo.Find(conn, "some-client")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("removes the quota of the client", func() {
+			_, err := repo.Upsert(conn, models.ClientQuota{ClientID: "some-client", MonthlyLimit: 100})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(repo.Delete(conn, "some-client")).To(Succeed())
+
+			_, err = repo.Find(conn, "some-client")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("returns a NotFoundError when the client has no quota", func() {
+			err := repo.Delete(conn, "some-client")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("Consume", func() {
+		It("counts usage by month", func() {
+			Expect(repo.Consume(conn, "some-client", 3, now)).To(Succeed())
+			Expect(repo.Consume(conn, "some-client", 2, now)).To(Succeed())
+			Expect(repo.Consume(conn, "some-client", 7, now.AddDate(0, 1, 0))).To(Succeed())
+
+			used, err := repo.Usage(conn, "some-client", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(used).To(Equal(5))
+
+			used, err = repo.Usage(conn, "some-client", now.AddDate(0, 1, 0))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(used).To(Equal(7))
+		})
+
+		It("refuses usage that would go over the quota", func() {
+			_, err := repo.Upsert(conn, models.ClientQuota{ClientID: "some-client", MonthlyLimit: 10})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(repo.Consume(conn, "some-client", 8, now)).To(Succeed())
+
+			err = repo.Consume(conn, "some-client", 3, now)
+			Expect(err).To(Equal(models.QuotaExceededError{
+				ClientID:  "some-client",
+				Limit:     10,
+				Used:      8,
+				Requested: 3,
+				ResetsAt:  time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+			}))
+
+			Expect(repo.Consume(conn, "some-client", 2, now)).To(Succeed())
+
+			used, err := repo.Usage(conn, "some-client", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(used).To(Equal(10))
+		})
+	})
+
+	Describe("Usage", func() {
+		It("is zero for a month without any notifications", func() {
+			used, err := repo.Usage(conn, "some-client", now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(used).To(BeZero())
+		})
+	})
+})
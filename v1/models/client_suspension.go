@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+// ClientSuspension blocks a client from sending notifications until an
+// admin reauthorizes it, after its sending pattern was flagged as anomalous.
+type ClientSuspension struct {
+	Primary   int       `db:"primary"`
+	ClientID  string    `db:"client_id"`
+	Reason    string    `db:"reason"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (s *ClientSuspension) PreInsert(e gorp.SqlExecutor) error {
+	if (s.CreatedAt == time.Time{}) {
+		s.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	}
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+)
+
+type ClientSuspensionsRepo struct{}
+
+func NewClientSuspensionsRepo() ClientSuspensionsRepo {
+	return ClientSuspensionsRepo{}
+}
+
+func (repo ClientSuspensionsRepo) Find(conn ConnectionInterface, clientID string) (ClientSuspension, error) {
+	suspension := ClientSuspension{}
+	err := conn.SelectOne(&suspension, "SELECT * FROM `client_suspensions` WHERE `client_id` = ?", clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return suspension, NotFoundError{Err: fmt.Errorf("Client %q is not suspended", clientID), Code: "client_suspension_not_found"}
+		}
+		return suspension, err
+	}
+
+	return suspension, nil
+}
+
+// Create suspends the client. Suspending a client that is already suspended
+// keeps the original suspension.
+func (repo ClientSuspensionsRepo) Create(conn ConnectionInterface, suspension ClientSuspension) (ClientSuspension, error) {
+	err := conn.Insert(&suspension)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return repo.Find(conn, suspension.ClientID)
+		}
+		return suspension, err
+	}
+
+	return suspension, nil
+}
+
+func (repo ClientSuspensionsRepo) Delete(conn ConnectionInterface, clientID string) error {
+	result, err := conn.Exec("DELETE FROM `client_suspensions` WHERE `client_id` = ?", clientID)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return NotFoundError{Err: fmt.Errorf("Client %q is not suspended", clientID), Code: "client_suspension_not_found"}
+	}
+
+	return nil
+}
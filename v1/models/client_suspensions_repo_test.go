@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClientSuspensionsRepo", func() {
+	var (
+		repo models.ClientSuspensionsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewClientSuspensionsRepo()
+	})
+
+	It("suspends a client until the suspension is deleted", func() {
+		_, err := repo.Find(conn, "some-client")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+
+		suspension, err := repo.Create(conn, models.ClientSuspension{ClientID: "some-client", Reason: "spike"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(suspension.CreatedAt).NotTo(BeZero())
+
+		found, err := repo.Find(conn, "some-client")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.Reason).To(Equal("spike"))
+
+		Expect(repo.Delete(conn, "some-client")).To(Succeed())
+
+		_, err = repo.Find(conn, "some-client")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+
+	It("keeps the original suspension when the client is suspended again", func() {
+		first, err := repo.Create(conn, models.ClientSuspension{ClientID: "some-client", Reason: "first spike"})
+		Expect(err).NotTo(HaveOccurred())
+
+		second, err := repo.Create(conn, models.ClientSuspension{ClientID: "some-client", Reason: "second spike"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.Primary).To(Equal(first.Primary))
+		Expect(second.Reason).To(Equal("first spike"))
+	})
+
+	It("returns a not found error when deleting a suspension that does not exist", func() {
+		err := repo.Delete(conn, "some-client")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+})
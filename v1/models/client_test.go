@@ -31,4 +31,51 @@ var _ = Describe("Client", func() {
 			})
 		})
 	})
+
+	Describe("LinkDomains", func() {
+		It("round-trips through its JSON column value", func() {
+			domains := models.LinkDomains{"login.sys.example.com": "login.brand.example.com"}
+
+			value, err := domains.Value()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(`{"login.sys.example.com":"login.brand.example.com"}`))
+
+			var scanned models.LinkDomains
+			Expect(scanned.Scan([]byte(value.(string)))).To(Succeed())
+			Expect(scanned).To(Equal(domains))
+		})
+
+		It("is stored as an empty string when there are no mappings", func() {
+			value, err := models.LinkDomains(nil).Value()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(""))
+
+			scanned := models.LinkDomains{"stale.example.com": "brand.example.com"}
+			Expect(scanned.Scan("")).To(Succeed())
+			Expect(scanned).To(BeNil())
+		})
+	})
+	Describe("SimulationSinks", func() {
+		It("round-trips through its JSON column value", func() {
+			sinks := models.SimulationSinks{"qa@partner.example.com", "audit@partner.example.com"}
+
+			value, err := sinks.Value()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(`["qa@partner.example.com","audit@partner.example.com"]`))
+
+			var scanned models.SimulationSinks
+			Expect(scanned.Scan([]byte(value.(string)))).To(Succeed())
+			Expect(scanned).To(Equal(sinks))
+		})
+
+		It("is stored as an empty string when the client is not simulating", func() {
+			value, err := models.SimulationSinks(nil).Value()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(""))
+
+			scanned := models.SimulationSinks{"stale@example.com"}
+			Expect(scanned.Scan(nil)).To(Succeed())
+			Expect(scanned).To(BeNil())
+		})
+	})
 })
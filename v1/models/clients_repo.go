@@ -18,7 +18,7 @@ func (repo ClientsRepo) Find(conn ConnectionInterface, id string) (Client, error
 	err := conn.SelectOne(&client, "SELECT * FROM `clients` WHERE `id` = ?", id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			err = NotFoundError{fmt.Errorf("Client with ID %q could not be found", id)}
+			err = NotFoundError{Err: fmt.Errorf("Client with ID %q could not be found", id), Code: "client_not_found"}
 		}
 		return client, err
 	}
@@ -95,7 +95,7 @@ var _ = Describe("ClientsRepo", func() {
 				}
 
 				_, err := repo.Update(conn, client)
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Client with ID \"my-client\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Client with ID \"my-client\" could not be found"), Code: "client_not_found"}))
 			})
 		})
 
@@ -134,7 +134,7 @@ var _ = Describe("ClientsRepo", func() {
 				}
 
 				_, err := repo.Update(conn, client)
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Client with ID \"my-client\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Client with ID \"my-client\" could not be found"), Code: "client_not_found"}))
 			})
 		})
 	})
@@ -156,6 +156,32 @@ var _ = Describe("ClientsRepo", func() {
 				Expect(client.Description).To(Equal("My Client"))
 				Expect(client.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
 			})
+
+			It("stores the link domains", func() {
+				client := models.Client{
+					ID:          "my-client",
+					LinkDomains: models.LinkDomains{"login.sys.example.com": "login.brand.example.com"},
+				}
+
+				_, err := repo.Upsert(conn, client)
+				Expect(err).NotTo(HaveOccurred())
+
+				client, err = repo.Find(conn, "my-client")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.LinkDomains).To(Equal(models.LinkDomains{"login.sys.example.com": "login.brand.example.com"}))
+			})
+
+			It("stores the sender name", func() {
+				_, err := repo.Upsert(conn, models.Client{
+					ID:         "my-client",
+					SenderName: "Jurassic Park",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				client, err := repo.Find(conn, "my-client")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(client.SenderName).To(Equal("Jurassic Park"))
+			})
 		})
 
 		Context("when the record exists", func() {
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+const (
+	CriticalApprovalPending  = "pending"
+	CriticalApprovalApproved = "approved"
+	CriticalApprovalDenied   = "denied"
+)
+
+// CriticalApproval records an operator's decision about a client's request
+// to make one of its kinds critical. Until it is approved, the kind is
+// registered as non-critical.
+type CriticalApproval struct {
+	Primary   int       `db:"primary"`
+	ClientID  string    `db:"client_id"`
+	KindID    string    `db:"kind_id"`
+	Status    string    `db:"status"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (a *CriticalApproval) PreInsert(s gorp.SqlExecutor) error {
+	if (a.CreatedAt == time.Time{}) {
+		a.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	}
+	a.UpdatedAt = a.CreatedAt
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type CriticalApprovalsRepo struct{}
+
+func NewCriticalApprovalsRepo() CriticalApprovalsRepo {
+	return CriticalApprovalsRepo{}
+}
+
+func (repo CriticalApprovalsRepo) Find(conn ConnectionInterface, clientID, kindID string) (CriticalApproval, error) {
+	approval := CriticalApproval{}
+	err := conn.SelectOne(&approval, "SELECT * FROM `critical_approvals` WHERE `client_id` = ? AND `kind_id` = ?", clientID, kindID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return approval, NotFoundError{Err: fmt.Errorf("Notification with ID %q belonging to client %q has not asked to be critical", kindID, clientID), Code: "critical_approval_not_found"}
+		}
+		return approval, err
+	}
+
+	return approval, nil
+}
+
+// FindAllByStatus lists the approvals with the status, oldest first.
+func (repo CriticalApprovalsRepo) FindAllByStatus(conn ConnectionInterface, status string) ([]CriticalApproval, error) {
+	approvals := []CriticalApproval{}
+	_, err := conn.Select(&approvals, "SELECT * FROM `critical_approvals` WHERE `status` = ? ORDER BY `created_at`, `primary`", status)
+	if err != nil {
+		return []CriticalApproval{}, err
+	}
+
+	return approvals, nil
+}
+
+// Request records that the kind asked to be critical, with the given status,
+// unless it already has. The existing approval is returned when it has.
+func (repo CriticalApprovalsRepo) Request(conn ConnectionInterface, clientID, kindID, status string) (CriticalApproval, error) {
+	approval, err := repo.Find(conn, clientID, kindID)
+	if err == nil {
+		return approval, nil
+	}
+
+	if _, ok := err.(NotFoundError); !ok {
+		return approval, err
+	}
+
+	approval = CriticalApproval{
+		ClientID: clientID,
+		KindID:   kindID,
+		Status:   status,
+	}
+
+	err = conn.Insert(&approval)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return repo.Find(conn, clientID, kindID)
+		}
+		return CriticalApproval{}, err
+	}
+
+	return approval, nil
+}
+
+func (repo CriticalApprovalsRepo) SetStatus(conn ConnectionInterface, clientID, kindID, status string) (CriticalApproval, error) {
+	approval, err := repo.Find(conn, clientID, kindID)
+	if err != nil {
+		return approval, err
+	}
+
+	approval.Status = status
+	approval.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	_, err = conn.Update(&approval)
+	if err != nil {
+		return CriticalApproval{}, err
+	}
+
+	return approval, nil
+}
+
+// Withdraw forgets the request of the kind to be critical, whatever was
+// decided about it, so that asking again needs a new approval.
+func (repo CriticalApprovalsRepo) Withdraw(conn ConnectionInterface, clientID, kindID string) error {
+	_, err := conn.Exec("DELETE FROM `critical_approvals` WHERE `client_id` = ? AND `kind_id` = ?", clientID, kindID)
+	return err
+}
@@ -0,0 +1,77 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CriticalApprovalsRepo", func() {
+	var (
+		repo models.CriticalApprovalsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewCriticalApprovalsRepo()
+	})
+
+	It("records a request until it is withdrawn", func() {
+		_, err := repo.Find(conn, "some-client", "some-kind")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+
+		approval, err := repo.Request(conn, "some-client", "some-kind", models.CriticalApprovalPending)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(approval.Status).To(Equal(models.CriticalApprovalPending))
+		Expect(approval.CreatedAt).NotTo(BeZero())
+
+		found, err := repo.Find(conn, "some-client", "some-kind")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.Status).To(Equal(models.CriticalApprovalPending))
+
+		Expect(repo.Withdraw(conn, "some-client", "some-kind")).To(Succeed())
+
+		_, err = repo.Find(conn, "some-client", "some-kind")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+
+	It("keeps the decision when the kind asks again", func() {
+		_, err := repo.Request(conn, "some-client", "some-kind", models.CriticalApprovalPending)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = repo.SetStatus(conn, "some-client", "some-kind", models.CriticalApprovalDenied)
+		Expect(err).NotTo(HaveOccurred())
+
+		approval, err := repo.Request(conn, "some-client", "some-kind", models.CriticalApprovalPending)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(approval.Status).To(Equal(models.CriticalApprovalDenied))
+	})
+
+	It("lists the approvals with a status", func() {
+		_, err := repo.Request(conn, "some-client", "first-kind", models.CriticalApprovalPending)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = repo.Request(conn, "some-client", "second-kind", models.CriticalApprovalApproved)
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = repo.Request(conn, "other-client", "third-kind", models.CriticalApprovalPending)
+		Expect(err).NotTo(HaveOccurred())
+
+		approvals, err := repo.FindAllByStatus(conn, models.CriticalApprovalPending)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(approvals).To(HaveLen(2))
+		Expect(approvals[0].KindID).To(Equal("first-kind"))
+		Expect(approvals[1].KindID).To(Equal("third-kind"))
+	})
+
+	It("returns a not found error when setting the status of a kind that has not asked", func() {
+		_, err := repo.SetStatus(conn, "some-client", "some-kind", models.CriticalApprovalApproved)
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+})
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+// CriticalUnsubscribe is an unsubscribe recorded against a kind while it was
+// critical. It has no effect until the kind is downgraded.
+type CriticalUnsubscribe struct {
+	Primary   int       `db:"primary"`
+	UserID    string    `db:"user_id"`
+	ClientID  string    `db:"client_id"`
+	KindID    string    `db:"kind_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (u *CriticalUnsubscribe) PreInsert(s gorp.SqlExecutor) error {
+	u.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	return nil
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+)
+
+type CriticalUnsubscribesRepo struct{}
+
+func NewCriticalUnsubscribesRepo() CriticalUnsubscribesRepo {
+	return CriticalUnsubscribesRepo{}
+}
+
+func (repo CriticalUnsubscribesRepo) Get(conn ConnectionInterface, userID, clientID, kindID string) (bool, error) {
+	err := conn.SelectOne(&CriticalUnsubscribe{}, "SELECT * FROM `critical_unsubscribes` WHERE `client_id` = ? AND `kind_id` = ? AND `user_id` = ?", clientID, kindID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Set records or withdraws a user's request to be unsubscribed from a
+// critical kind. Recording the same request twice keeps the original time.
+func (repo CriticalUnsubscribesRepo) Set(conn ConnectionInterface, userID, clientID, kindID string, unsubscribe bool) error {
+	if !unsubscribe {
+		_, err := conn.Exec("DELETE FROM `critical_unsubscribes` WHERE `client_id` = ? AND `kind_id` = ? AND `user_id` = ?", clientID, kindID, userID)
+		return err
+	}
+
+	err := conn.Insert(&CriticalUnsubscribe{
+		UserID:   userID,
+		ClientID: clientID,
+		KindID:   kindID,
+	})
+	if err != nil && !strings.Contains(err.Error(), "Duplicate entry") {
+		return err
+	}
+
+	return nil
+}
+
+func (repo CriticalUnsubscribesRepo) FindAllByKind(conn ConnectionInterface, clientID, kindID string) ([]CriticalUnsubscribe, error) {
+	unsubscribes := []CriticalUnsubscribe{}
+	_, err := conn.Select(&unsubscribes, "SELECT * FROM `critical_unsubscribes` WHERE `client_id` = ? AND `kind_id` = ? ORDER BY `primary`", clientID, kindID)
+	if err != nil {
+		return []CriticalUnsubscribe{}, err
+	}
+
+	return unsubscribes, nil
+}
+
+func (repo CriticalUnsubscribesRepo) DeleteAllByKind(conn ConnectionInterface, clientID, kindID string) (int, error) {
+	result, err := conn.Exec("DELETE FROM `critical_unsubscribes` WHERE `client_id` = ? AND `kind_id` = ?", clientID, kindID)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,68 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CriticalUnsubscribesRepo", func() {
+	var (
+		repo models.CriticalUnsubscribesRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewCriticalUnsubscribesRepo()
+	})
+
+	Describe("Set and Get", func() {
+		It("records and withdraws a request", func() {
+			err := repo.Set(conn, "user-123", "raptors", "feeding-time", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Set(conn, "user-123", "raptors", "feeding-time", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			unsubscribed, err := repo.Get(conn, "user-123", "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+
+			err = repo.Set(conn, "user-123", "raptors", "feeding-time", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			unsubscribed, err = repo.Get(conn, "user-123", "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeFalse())
+		})
+	})
+
+	Describe("FindAllByKind and DeleteAllByKind", func() {
+		It("finds and removes only the requests for the kind", func() {
+			Expect(repo.Set(conn, "user-123", "raptors", "feeding-time", true)).To(Succeed())
+			Expect(repo.Set(conn, "user-456", "raptors", "feeding-time", true)).To(Succeed())
+			Expect(repo.Set(conn, "user-123", "raptors", "door-opening", true)).To(Succeed())
+
+			unsubscribes, err := repo.FindAllByKind(conn, "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribes).To(HaveLen(2))
+			Expect(unsubscribes[0].UserID).To(Equal("user-123"))
+			Expect(unsubscribes[1].UserID).To(Equal("user-456"))
+			Expect(unsubscribes[0].CreatedAt).NotTo(BeZero())
+
+			count, err := repo.DeleteAllByKind(conn, "raptors", "feeding-time")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+
+			unsubscribed, err := repo.Get(conn, "user-123", "raptors", "door-opening")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(unsubscribed).To(BeTrue())
+		})
+	})
+})
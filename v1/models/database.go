@@ -16,11 +16,13 @@ type ConnectionInterface interface {
 
 type Config struct {
 	DefaultTemplatePath string
+	Replicas            []*sql.DB
 }
 
 func NewDatabase(sqlDB *sql.DB, config Config) DatabaseInterface {
 	database := db.NewDatabase(sqlDB, db.Config{
 		DefaultTemplatePath: config.DefaultTemplatePath,
+		Replicas:            config.Replicas,
 	})
 	Setup(database)
 
@@ -33,6 +35,30 @@ func Setup(database *db.DB) {
 	database.TableMap().AddTableWithName(Receipt{}, "receipts").SetKeys(true, "Primary").SetUniqueTogether("user_guid", "client_id", "kind_id")
 	database.TableMap().AddTableWithName(Unsubscribe{}, "unsubscribes").SetKeys(true, "Primary").SetUniqueTogether("user_id", "client_id", "kind_id")
 	database.TableMap().AddTableWithName(GlobalUnsubscribe{}, "global_unsubscribes").SetKeys(true, "Primary").ColMap("UserID").SetUnique(true)
+	database.TableMap().AddTableWithName(CriticalUnsubscribe{}, "critical_unsubscribes").SetKeys(true, "Primary").SetUniqueTogether("user_id", "client_id", "kind_id")
+	database.TableMap().AddTableWithName(CriticalApproval{}, "critical_approvals").SetKeys(true, "Primary").SetUniqueTogether("client_id", "kind_id")
+	database.TableMap().AddTableWithName(Subscription{}, "subscriptions").SetKeys(true, "Primary").SetUniqueTogether("user_id", "client_id", "kind_id")
 	database.TableMap().AddTableWithName(Template{}, "templates").SetKeys(true, "Primary").ColMap("Name").SetUnique(true)
+	database.TableMap().AddTableWithName(TemplateTranslation{}, "template_translations").SetKeys(true, "Primary").SetUniqueTogether("template_id", "locale")
+	database.TableMap().AddTableWithName(TemplateVersion{}, "template_versions").SetKeys(true, "Primary").SetUniqueTogether("template_id", "version")
+	database.TableMap().AddTableWithName(ClientSuspension{}, "client_suspensions").SetKeys(true, "Primary").ColMap("ClientID").SetUnique(true)
+	database.TableMap().AddTableWithName(IdempotencyKey{}, "idempotency_keys").SetKeys(true, "Primary").SetUniqueTogether("client_id", "idempotency_key")
 	database.TableMap().AddTableWithName(Message{}, "messages").SetKeys(false, "ID")
+	database.TableMap().AddTableWithName(OrganizationPolicy{}, "organization_policies").SetKeys(true, "Primary").ColMap("OrganizationGUID").SetUnique(true)
+	database.TableMap().AddTableWithName(UserMessage{}, "user_messages").SetKeys(true, "Primary").ColMap("MessageID").SetUnique(true)
+	database.TableMap().AddTableWithName(DigestPreference{}, "digest_preferences").SetKeys(true, "Primary").ColMap("UserID").SetUnique(true)
+	database.TableMap().AddTableWithName(SeverityUnsubscribe{}, "severity_unsubscribes").SetKeys(true, "Primary").SetUniqueTogether("user_id", "severity")
+	database.TableMap().AddTableWithName(DigestEntry{}, "digest_entries").SetKeys(true, "Primary").ColMap("MessageID").SetUnique(true)
+	database.TableMap().AddTableWithName(RegistrationWebhook{}, "registration_webhooks").SetKeys(true, "Primary").ColMap("ClientID").SetUnique(true)
+	database.TableMap().AddTableWithName(ScheduledJob{}, "scheduled_jobs").SetKeys(false, "Name")
+	database.TableMap().AddTableWithName(SchedulerLease{}, "scheduler_leases").SetKeys(false, "Name")
+	database.TableMap().AddTableWithName(TemplatePartial{}, "template_partials").SetKeys(true, "Primary").ColMap("Name").SetUnique(true)
+	database.TableMap().AddTableWithName(AuditEvent{}, "audit_events").SetKeys(true, "Primary")
+	database.TableMap().AddTableWithName(KindActivity{}, "kind_activity").SetKeys(false, "ClientID", "KindID", "Bucket")
+	database.TableMap().AddTableWithName(ClientQuota{}, "client_quotas").SetKeys(true, "Primary").ColMap("ClientID").SetUnique(true)
+	database.TableMap().AddTableWithName(ClientQuotaUsage{}, "client_quota_usage").SetKeys(false, "ClientID", "Month")
+	database.TableMap().AddTableWithName(RecipientDailyCount{}, "recipient_daily_counts").SetKeys(false, "UserGUID", "Day")
+	database.TableMap().AddTableWithName(PayloadSample{}, "payload_samples").SetKeys(true, "Primary")
+	database.TableMap().AddTableWithName(InstanceHeartbeat{}, "instance_heartbeats").SetKeys(false, "InstanceID")
+	database.TableMap().AddTableWithName(PreferenceRevert{}, "preference_reverts").SetKeys(false, "ID")
 }
@@ -4,6 +4,8 @@ import (
 	"database/sql"
 	"encoding/json"
 	"io/ioutil"
+	"os"
+	"path"
 	"time"
 
 	sql_migrate "github.com/rubenv/sql-migrate"
@@ -25,9 +27,21 @@ func (d DatabaseMigrator) Migrate(db *sql.DB, migrationsPath string) {
 	}
 }
 
+// Seed loads the default template and, when digest.json sits next to it,
+// the digest template. Templates that were overridden are left alone.
 func (d DatabaseMigrator) Seed(database DatabaseInterface, defaultTemplatePath string) {
+	conn := database.Connection()
+	seedTemplate(conn, DefaultTemplateID, defaultTemplatePath)
+
+	digestTemplatePath := path.Join(path.Dir(defaultTemplatePath), "digest.json")
+	if _, err := os.Stat(digestTemplatePath); err == nil {
+		seedTemplate(conn, DigestTemplateID, digestTemplatePath)
+	}
+}
+
+func seedTemplate(conn ConnectionInterface, templateID, templatePath string) {
 	repo := NewTemplatesRepo()
-	bytes, err := ioutil.ReadFile(defaultTemplatePath)
+	bytes, err := ioutil.ReadFile(templatePath)
 	if err != nil {
 		panic(err)
 	}
@@ -37,6 +51,7 @@ func (d DatabaseMigrator) Seed(database DatabaseInterface, defaultTemplatePath s
 		Subject  string          `json:"subject"`
 		Text     string          `json:"text"`
 		HTML     string          `json:"html"`
+		Slack    string          `json:"slack"`
 		Metadata json.RawMessage `json:"metadata"`
 	}
 
@@ -45,19 +60,19 @@ func (d DatabaseMigrator) Seed(database DatabaseInterface, defaultTemplatePath s
 		panic(err)
 	}
 
-	conn := database.Connection()
-	existingTemplate, err := repo.FindByID(conn, DefaultTemplateID)
+	existingTemplate, err := repo.FindByID(conn, templateID)
 	if err != nil {
 		if _, ok := err.(NotFoundError); !ok {
 			panic(err)
 		}
 
 		_, err = repo.Create(conn, Template{
-			ID:       DefaultTemplateID,
+			ID:       templateID,
 			Name:     template.Name,
 			Subject:  template.Subject,
 			HTML:     template.HTML,
 			Text:     template.Text,
+			Slack:    template.Slack,
 			Metadata: string(template.Metadata),
 		})
 		if err != nil {
@@ -72,8 +87,14 @@ func (d DatabaseMigrator) Seed(database DatabaseInterface, defaultTemplatePath s
 		existingTemplate.Subject = template.Subject
 		existingTemplate.HTML = template.HTML
 		existingTemplate.Text = template.Text
+		existingTemplate.Slack = template.Slack
 		existingTemplate.Metadata = string(template.Metadata)
 		existingTemplate.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+		existingTemplate.Version, err = repo.recordVersion(conn, existingTemplate)
+		if err != nil {
+			panic(err)
+		}
+
 		_, err = conn.Update(&existingTemplate)
 		if err != nil {
 			panic(err)
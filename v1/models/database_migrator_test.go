@@ -75,7 +75,7 @@ var _ = Describe("DatabaseMigrator", func() {
 
 		It("has the default template pre-seeded", func() {
 			_, err := repo.FindByID(connection, models.DefaultTemplateID)
-			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"default\" could not be found")}))
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"default\" could not be found"), Code: "template_not_found"}))
 
 			dbMigrator.Seed(database, defaultTemplatePath)
 			template, err := repo.FindByID(connection, models.DefaultTemplateID)
@@ -87,6 +87,14 @@ var _ = Describe("DatabaseMigrator", func() {
 			Expect(template.Metadata).To(Equal("{}"))
 		})
 
+		It("has the digest template pre-seeded", func() {
+			dbMigrator.Seed(database, defaultTemplatePath)
+			template, err := repo.FindByID(connection, models.DigestTemplateID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.Name).To(Equal("Digest Template"))
+			Expect(template.Subject).To(Equal("CF Notification Digest: {{.Count}} notifications"))
+		})
+
 		It("can be called multiple times without panicking", func() {
 			Expect(func() {
 				dbMigrator.Seed(database, defaultTemplatePath)
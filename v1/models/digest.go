@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	DigestImmediate = "immediate"
+	DigestHourly    = "hourly"
+	DigestDaily     = "daily"
+
+	DigestTemplateID = "digest"
+)
+
+var DigestFrequencies = []string{DigestImmediate, DigestHourly, DigestDaily}
+
+// DigestWindows is how long the first notification collected for a digest
+// waits for others to join it before the digest is sent.
+var DigestWindows = map[string]time.Duration{
+	DigestHourly: time.Hour,
+	DigestDaily:  24 * time.Hour,
+}
+
+type DigestPreference struct {
+	Primary   int       `db:"primary"`
+	UserID    string    `db:"user_id"`
+	Frequency string    `db:"frequency"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type DigestEntry struct {
+	Primary           int       `db:"primary"`
+	MessageID         string    `db:"message_id"`
+	UserGUID          string    `db:"user_guid"`
+	Email             string    `db:"email"`
+	ClientID          string    `db:"client_id"`
+	KindID            string    `db:"kind_id"`
+	Subject           string    `db:"subject"`
+	Text              string    `db:"text"`
+	HTML              string    `db:"html"`
+	SourceDescription string    `db:"source_description"`
+	KindDescription   string    `db:"kind_description"`
+	RequestReceived   time.Time `db:"request_received"`
+	DueAt             time.Time `db:"due_at"`
+}
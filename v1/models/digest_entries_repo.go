@@ -0,0 +1,66 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type DigestEntriesRepo struct{}
+
+func NewDigestEntriesRepo() DigestEntriesRepo {
+	return DigestEntriesRepo{}
+}
+
+// Create collects a message for the next digest of its recipient. Collecting
+// the same message twice, as happens when a delivery is retried, is not an
+// error.
+func (repo DigestEntriesRepo) Create(conn ConnectionInterface, entry DigestEntry) (DigestEntry, error) {
+	err := conn.Insert(&entry)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return entry, nil
+		}
+
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+// FindDueUserGUIDs returns the users whose oldest collected message has
+// waited out its digest window.
+func (repo DigestEntriesRepo) FindDueUserGUIDs(conn ConnectionInterface, now time.Time, limit int) ([]string, error) {
+	userGUIDs := []string{}
+	_, err := conn.Select(&userGUIDs, "SELECT `user_guid` FROM `digest_entries` GROUP BY `user_guid` HAVING MIN(`due_at`) <= ? LIMIT ?", now.UTC(), limit)
+	if err != nil {
+		return []string{}, err
+	}
+
+	return userGUIDs, nil
+}
+
+func (repo DigestEntriesRepo) FindAllByUserGUID(conn ConnectionInterface, userGUID string) ([]DigestEntry, error) {
+	entries := []DigestEntry{}
+	_, err := conn.Select(&entries, "SELECT * FROM `digest_entries` WHERE `user_guid` = ? ORDER BY `primary`", userGUID)
+	if err != nil {
+		return []DigestEntry{}, err
+	}
+
+	return entries, nil
+}
+
+// DeleteThrough removes the entries of a user up to and including primary,
+// leaving any collected while their digest was being sent.
+func (repo DigestEntriesRepo) DeleteThrough(conn ConnectionInterface, userGUID string, primary int) (int, error) {
+	result, err := conn.Exec("DELETE FROM `digest_entries` WHERE `user_guid` = ? AND `primary` <= ?", userGUID, primary)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,99 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DigestEntriesRepo", func() {
+	var (
+		repo models.DigestEntriesRepo
+		conn *db.Connection
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewDigestEntriesRepo()
+		now = time.Now().Truncate(time.Second).UTC()
+	})
+
+	create := func(messageID, userGUID string, dueAt time.Time) models.DigestEntry {
+		entry, err := repo.Create(conn, models.DigestEntry{
+			MessageID: messageID,
+			UserGUID:  userGUID,
+			Email:     userGUID + "@example.com",
+			ClientID:  "some-client",
+			Subject:   "subject of " + messageID,
+			DueAt:     dueAt,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		return entry
+	}
+
+	Describe("FindDueUserGUIDs", func() {
+		It("returns the users whose oldest entry is due", func() {
+			create("message-1", "user-123", now.Add(-time.Minute))
+			create("message-2", "user-123", now.Add(time.Hour))
+			create("message-3", "user-456", now.Add(time.Minute))
+
+			userGUIDs, err := repo.FindDueUserGUIDs(conn, now, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(userGUIDs).To(Equal([]string{"user-123"}))
+		})
+	})
+
+	Describe("FindAllByUserGUID", func() {
+		It("returns the entries of the user in the order they were collected", func() {
+			create("message-1", "user-123", now)
+			create("message-2", "user-456", now)
+			create("message-3", "user-123", now)
+
+			entries, err := repo.FindAllByUserGUID(conn, "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].MessageID).To(Equal("message-1"))
+			Expect(entries[1].MessageID).To(Equal("message-3"))
+			Expect(entries[1].Subject).To(Equal("subject of message-3"))
+		})
+
+		It("ignores a message that was already collected", func() {
+			create("message-1", "user-123", now)
+			create("message-1", "user-123", now)
+
+			entries, err := repo.FindAllByUserGUID(conn, "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+	})
+
+	Describe("DeleteThrough", func() {
+		It("deletes the entries of the user up to the given one", func() {
+			create("message-1", "user-123", now)
+			last := create("message-2", "user-123", now)
+			create("message-3", "user-123", now)
+			create("message-4", "user-456", now)
+
+			count, err := repo.DeleteThrough(conn, "user-123", last.Primary)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+
+			entries, err := repo.FindAllByUserGUID(conn, "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].MessageID).To(Equal("message-3"))
+
+			entries, err = repo.FindAllByUserGUID(conn, "user-456")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+		})
+	})
+})
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type DigestPreferencesRepo struct{}
+
+func NewDigestPreferencesRepo() DigestPreferencesRepo {
+	return DigestPreferencesRepo{}
+}
+
+// Get returns how often the user wants their notifications delivered.
+// Users who never chose get each notification as it is sent.
+func (repo DigestPreferencesRepo) Get(conn ConnectionInterface, userID string) (string, error) {
+	preference, err := repo.find(conn, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return DigestImmediate, nil
+		}
+		return "", err
+	}
+
+	return preference.Frequency, nil
+}
+
+func (repo DigestPreferencesRepo) Set(conn ConnectionInterface, userID, frequency string) error {
+	preference, err := repo.find(conn, userID)
+	if err != nil && err != sql.ErrNoRows {
+		return err
+	}
+
+	if frequency == DigestImmediate {
+		if preference.Primary != 0 {
+			_, err = conn.Delete(&preference)
+		}
+		return err
+	}
+
+	preference.UserID = userID
+	preference.Frequency = frequency
+	preference.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	if preference.Primary == 0 {
+		return conn.Insert(&preference)
+	}
+
+	_, err = conn.Update(&preference)
+	return err
+}
+
+func (repo DigestPreferencesRepo) find(conn ConnectionInterface, userID string) (DigestPreference, error) {
+	preference := DigestPreference{}
+	err := conn.SelectOne(&preference, "SELECT * FROM `digest_preferences` WHERE `user_id` = ?", userID)
+	if err != nil {
+		return DigestPreference{}, err
+	}
+
+	return preference, nil
+}
@@ -0,0 +1,58 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DigestPreferencesRepo", func() {
+	var (
+		repo models.DigestPreferencesRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewDigestPreferencesRepo()
+	})
+
+	It("delivers immediately to users who have not chosen a frequency", func() {
+		frequency, err := repo.Get(conn, "user-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(frequency).To(Equal(models.DigestImmediate))
+	})
+
+	It("stores and changes the frequency of a user", func() {
+		Expect(repo.Set(conn, "user-123", models.DigestHourly)).To(Succeed())
+		Expect(repo.Set(conn, "user-456", models.DigestDaily)).To(Succeed())
+
+		frequency, err := repo.Get(conn, "user-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(frequency).To(Equal(models.DigestHourly))
+
+		Expect(repo.Set(conn, "user-123", models.DigestDaily)).To(Succeed())
+
+		frequency, err = repo.Get(conn, "user-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(frequency).To(Equal(models.DigestDaily))
+	})
+
+	It("forgets the frequency when the user goes back to immediate delivery", func() {
+		Expect(repo.Set(conn, "user-123", models.DigestHourly)).To(Succeed())
+		Expect(repo.Set(conn, "user-123", models.DigestImmediate)).To(Succeed())
+
+		count, err := conn.SelectInt("SELECT COUNT(*) FROM `digest_preferences`")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(int64(0)))
+
+		frequency, err := repo.Get(conn, "user-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(frequency).To(Equal(models.DigestImmediate))
+	})
+})
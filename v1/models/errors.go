@@ -1,13 +1,20 @@
 package models
 
+// NotFoundError reports a record that does not exist. Code names what kind
+// of record it was, such as "template_not_found", for API clients.
 type NotFoundError struct {
-	Err error
+	Err  error
+	Code string
 }
 
 func (e NotFoundError) Error() string {
 	return e.Err.Error()
 }
 
+func (e NotFoundError) ErrorCode() string {
+	return e.Code
+}
+
 type DuplicateError struct {
 	Err error
 }
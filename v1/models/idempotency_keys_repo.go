@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+type IdempotencyKeysRepo struct{}
+
+func NewIdempotencyKeysRepo() IdempotencyKeysRepo {
+	return IdempotencyKeysRepo{}
+}
+
+func (repo IdempotencyKeysRepo) Find(conn ConnectionInterface, clientID, key string) (IdempotencyKey, error) {
+	idempotencyKey := IdempotencyKey{}
+	err := conn.SelectOne(&idempotencyKey, "SELECT * FROM `idempotency_keys` WHERE `client_id` = ? AND `idempotency_key` = ?", clientID, key)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return idempotencyKey, NotFoundError{Err: fmt.Errorf("Idempotency key %q for client %q could not be found", key, clientID)}
+		}
+		return idempotencyKey, err
+	}
+
+	return idempotencyKey, nil
+}
+
+// Upsert stores the key, replacing a previous use of the same key by the
+// client. Expired keys are reused this way rather than being purged.
+func (repo IdempotencyKeysRepo) Upsert(conn ConnectionInterface, idempotencyKey IdempotencyKey) (IdempotencyKey, error) {
+	existing, err := repo.Find(conn, idempotencyKey.ClientID, idempotencyKey.Key)
+	if err != nil {
+		if _, ok := err.(NotFoundError); !ok {
+			return idempotencyKey, err
+		}
+
+		err = conn.Insert(&idempotencyKey)
+		if err != nil {
+			return IdempotencyKey{}, err
+		}
+
+		return idempotencyKey, nil
+	}
+
+	idempotencyKey.Primary = existing.Primary
+
+	_, err = conn.Update(&idempotencyKey)
+	if err != nil {
+		return IdempotencyKey{}, err
+	}
+
+	return idempotencyKey, nil
+}
@@ -0,0 +1,68 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IdempotencyKeysRepo", func() {
+	var (
+		repo models.IdempotencyKeysRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewIdempotencyKeysRepo()
+	})
+
+	Describe("Upsert and Find", func() {
+		It("creates and then replaces the key for a client", func() {
+			createdAt := time.Now().Add(-48 * time.Hour).Truncate(time.Second).UTC()
+
+			key, err := repo.Upsert(conn, models.IdempotencyKey{
+				ClientID:    "some-client",
+				Key:         "retry-me",
+				RequestHash: "first-hash",
+				Response:    `[{"notification_id":"message-1"}]`,
+				CreatedAt:   createdAt,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			found, err := repo.Find(conn, "some-client", "retry-me")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found.Response).To(Equal(`[{"notification_id":"message-1"}]`))
+			Expect(found.CreatedAt).To(Equal(createdAt))
+
+			_, err = repo.Upsert(conn, models.IdempotencyKey{
+				ClientID:    "some-client",
+				Key:         "retry-me",
+				RequestHash: "second-hash",
+				Response:    `[{"notification_id":"message-2"}]`,
+				CreatedAt:   time.Now().Truncate(time.Second).UTC(),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			found, err = repo.Find(conn, "some-client", "retry-me")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found.Primary).To(Equal(key.Primary))
+			Expect(found.RequestHash).To(Equal("second-hash"))
+		})
+
+		It("scopes keys to the client", func() {
+			_, err := repo.Upsert(conn, models.IdempotencyKey{ClientID: "some-client", Key: "retry-me", RequestHash: "hash"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Find(conn, "other-client", "retry-me")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+})
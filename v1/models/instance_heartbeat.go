@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// InstanceHeartbeat is what an instance of the service last reported about
+// itself. Every instance writes its own row each time its heartbeat beats.
+type InstanceHeartbeat struct {
+	InstanceID    string    `db:"instance_id"`
+	InstanceIndex int       `db:"instance_index"`
+	ReadOnly      bool      `db:"read_only"`
+	Workers       int       `db:"workers"`
+	Version       string    `db:"version"`
+	StartedAt     time.Time `db:"started_at"`
+	HeartbeatAt   time.Time `db:"heartbeat_at"`
+}
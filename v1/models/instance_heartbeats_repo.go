@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+type InstanceHeartbeatsRepo struct{}
+
+func NewInstanceHeartbeatsRepo() InstanceHeartbeatsRepo {
+	return InstanceHeartbeatsRepo{}
+}
+
+// Upsert saves the heartbeat as the latest one of its instance.
+func (repo InstanceHeartbeatsRepo) Upsert(conn ConnectionInterface, heartbeat InstanceHeartbeat) error {
+	query := "INSERT INTO `instance_heartbeats` (`instance_id`, `instance_index`, `read_only`, `workers`, `version`, `started_at`, `heartbeat_at`) VALUES (?, ?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `instance_index`=VALUES(`instance_index`), `read_only`=VALUES(`read_only`), `workers`=VALUES(`workers`), `version`=VALUES(`version`), `started_at`=VALUES(`started_at`), `heartbeat_at`=VALUES(`heartbeat_at`)"
+	_, err := conn.Exec(query, heartbeat.InstanceID, heartbeat.InstanceIndex, heartbeat.ReadOnly, heartbeat.Workers, heartbeat.Version,
+		heartbeat.StartedAt.UTC(), heartbeat.HeartbeatAt.UTC())
+	return err
+}
+
+func (repo InstanceHeartbeatsRepo) FindAll(conn ConnectionInterface) ([]InstanceHeartbeat, error) {
+	heartbeats := []InstanceHeartbeat{}
+	_, err := conn.Select(&heartbeats, "SELECT * FROM `instance_heartbeats` ORDER BY `instance_index`, `instance_id`")
+	if err != nil {
+		return heartbeats, err
+	}
+
+	return heartbeats, nil
+}
+
+// DeleteBefore forgets the instances that have not beaten since the time,
+// returning how many there were.
+func (repo InstanceHeartbeatsRepo) DeleteBefore(conn ConnectionInterface, before time.Time) (int, error) {
+	result, err := conn.Exec("DELETE FROM `instance_heartbeats` WHERE `heartbeat_at` < ?", before.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
@@ -0,0 +1,107 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("InstanceHeartbeatsRepo", func() {
+	var (
+		repo models.InstanceHeartbeatsRepo
+		conn *db.Connection
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		repo = models.NewInstanceHeartbeatsRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+
+		now = time.Now().Truncate(time.Second).UTC()
+	})
+
+	It("keeps the latest heartbeat of each instance, ordered by index", func() {
+		err := repo.Upsert(conn, models.InstanceHeartbeat{
+			InstanceID:    "instance-b",
+			InstanceIndex: 1,
+			Workers:       4,
+			Version:       "v1.2.3",
+			StartedAt:     now.Add(-time.Hour),
+			HeartbeatAt:   now.Add(-time.Minute),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = repo.Upsert(conn, models.InstanceHeartbeat{
+			InstanceID:    "instance-a",
+			InstanceIndex: 0,
+			ReadOnly:      true,
+			StartedAt:     now.Add(-time.Hour),
+			HeartbeatAt:   now,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = repo.Upsert(conn, models.InstanceHeartbeat{
+			InstanceID:    "instance-b",
+			InstanceIndex: 1,
+			Workers:       8,
+			Version:       "v1.2.3",
+			StartedAt:     now.Add(-time.Hour),
+			HeartbeatAt:   now,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		heartbeats, err := repo.FindAll(conn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(heartbeats).To(Equal([]models.InstanceHeartbeat{
+			{
+				InstanceID:    "instance-a",
+				InstanceIndex: 0,
+				ReadOnly:      true,
+				StartedAt:     now.Add(-time.Hour),
+				HeartbeatAt:   now,
+			},
+			{
+				InstanceID:    "instance-b",
+				InstanceIndex: 1,
+				Workers:       8,
+				Version:       "v1.2.3",
+				StartedAt:     now.Add(-time.Hour),
+				HeartbeatAt:   now,
+			},
+		}))
+	})
+
+	It("deletes the heartbeats older than a time", func() {
+		err := repo.Upsert(conn, models.InstanceHeartbeat{
+			InstanceID:  "gone",
+			StartedAt:   now.Add(-3 * time.Hour),
+			HeartbeatAt: now.Add(-2 * time.Hour),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = repo.Upsert(conn, models.InstanceHeartbeat{
+			InstanceID:    "alive",
+			InstanceIndex: 1,
+			StartedAt:     now.Add(-3 * time.Hour),
+			HeartbeatAt:   now.Add(-time.Minute),
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		count, err := repo.DeleteBefore(conn, now.Add(-time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+
+		heartbeats, err := repo.FindAll(conn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(heartbeats).To(HaveLen(1))
+		Expect(heartbeats[0].InstanceID).To(Equal("alive"))
+	})
+})
@@ -1,20 +1,94 @@
 package models
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gopkg.in/gorp.v1"
 )
 
 type Kind struct {
-	Primary     int       `db:"primary"`
-	ID          string    `db:"id"`
-	Description string    `db:"description"`
-	Critical    bool      `db:"critical"`
-	ClientID    string    `db:"client_id"`
-	CreatedAt   time.Time `db:"created_at"`
-	UpdatedAt   time.Time `db:"updated_at"`
-	TemplateID  string    `db:"template_id"`
+	Primary          int       `db:"primary"`
+	ID               string    `db:"id"`
+	Description      string    `db:"description"`
+	Critical         bool      `db:"critical"`
+	OptIn            bool      `db:"opt_in"`
+	ClientID         string    `db:"client_id"`
+	CreatedAt        time.Time `db:"created_at"`
+	UpdatedAt        time.Time `db:"updated_at"`
+	TemplateID       string    `db:"template_id"`
+	RetryMaxAttempts int       `db:"retry_max_attempts"`
+	RetryInterval    int       `db:"retry_interval"`
+
+	// SlackWebhookURL is an incoming webhook the notification is also
+	// posted to, or only posted to when SlackExclusive is set.
+	SlackWebhookURL string `db:"slack_webhook_url"`
+	SlackChannel    string `db:"slack_channel"`
+	SlackExclusive  bool   `db:"slack_exclusive"`
+
+	// Transactional kinds, such as password resets, are delivered through
+	// the fast lane when they are sent to a single recipient.
+	Transactional bool `db:"transactional"`
+
+	// Severity is the severity of the kind's notifications unless a notify
+	// request gives them another. See Severities.
+	Severity string `db:"severity"`
+
+	LocalizedDescriptions LocalizedDescriptions `db:"localized_descriptions"`
+}
+
+// LocalizedDescriptions maps normalized locales to translations of a kind's
+// description, for preference UIs to show. It is stored as a JSON object.
+type LocalizedDescriptions map[string]string
+
+func (d LocalizedDescriptions) Value() (driver.Value, error) {
+	if len(d) == 0 {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(d)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(encoded), nil
+}
+
+func (d *LocalizedDescriptions) Scan(src interface{}) error {
+	var encoded []byte
+	switch value := src.(type) {
+	case nil:
+	case string:
+		encoded = []byte(value)
+	case []byte:
+		encoded = value
+	default:
+		return fmt.Errorf("cannot scan %T into LocalizedDescriptions", src)
+	}
+
+	if len(encoded) == 0 {
+		*d = nil
+		return nil
+	}
+
+	return json.Unmarshal(encoded, d)
+}
+
+// Describe returns the description for the first of the locales, in order
+// of preference, that has one, falling back from a regional locale to its
+// language.
+func (d LocalizedDescriptions) Describe(locales []string) (string, bool) {
+	for _, locale := range locales {
+		for _, fallback := range LocaleFallbacks(locale) {
+			if description, ok := d[fallback]; ok {
+				return description, true
+			}
+		}
+	}
+
+	return "", false
 }
 
 func (k Kind) TemplateToUse() string {
@@ -34,5 +108,9 @@ func (k *Kind) PreInsert(s gorp.SqlExecutor) error {
 		k.TemplateID = DefaultTemplateID
 	}
 
+	if k.Severity == "" {
+		k.Severity = SeverityInfo
+	}
+
 	return nil
 }
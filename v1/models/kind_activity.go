@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// The counters kept for each kind in every hour of activity.
+const (
+	KindActivitySent         = "sent"
+	KindActivityFailed       = "failed"
+	KindActivityUnsubscribed = "unsubscribed"
+	KindActivityOpened       = "opened"
+	KindActivityClicked      = "clicked"
+)
+
+// KindActivity rolls up what happened to one kind of notification over the
+// hour starting at Bucket. Failed counts failed delivery attempts, so a
+// message that is retried may be counted more than once. Opened and Clicked
+// count every open and followed link, in the hour they happened.
+type KindActivity struct {
+	ClientID     string    `db:"client_id"`
+	KindID       string    `db:"kind_id"`
+	Bucket       time.Time `db:"bucket"`
+	Sent         int       `db:"sent"`
+	Failed       int       `db:"failed"`
+	Unsubscribed int       `db:"unsubscribed"`
+	Opened       int       `db:"opened"`
+	Clicked      int       `db:"clicked"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+type KindActivityRepo struct{}
+
+func NewKindActivityRepo() KindActivityRepo {
+	return KindActivityRepo{}
+}
+
+// Increment adds one to the counter for the hour containing at.
+func (repo KindActivityRepo) Increment(conn ConnectionInterface, clientID, kindID, counter string, at time.Time) error {
+	switch counter {
+	case KindActivitySent, KindActivityFailed, KindActivityUnsubscribed, KindActivityOpened, KindActivityClicked:
+	default:
+		return fmt.Errorf("unknown kind activity counter %q", counter)
+	}
+
+	bucket := at.UTC().Truncate(time.Hour)
+	query := fmt.Sprintf("INSERT INTO `kind_activity` (`client_id`, `kind_id`, `bucket`, `%[1]s`) VALUES (?, ?, ?, 1) ON DUPLICATE KEY UPDATE `%[1]s` = `%[1]s` + 1", counter)
+	_, err := conn.Exec(query, clientID, kindID, bucket)
+
+	return err
+}
+
+// List returns the hours of activity recorded for the kind between start and
+// end, oldest first. Hours without any activity are left out.
+func (repo KindActivityRepo) List(conn ConnectionInterface, clientID, kindID string, start, end time.Time) ([]KindActivity, error) {
+	activity := []KindActivity{}
+	_, err := conn.Select(&activity, "SELECT * FROM `kind_activity` WHERE `client_id` = ? AND `kind_id` = ? AND `bucket` >= ? AND `bucket` < ? ORDER BY `bucket`", clientID, kindID, start.UTC().Truncate(time.Hour), end.UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return activity, nil
+}
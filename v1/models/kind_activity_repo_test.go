@@ -0,0 +1,82 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KindActivityRepo", func() {
+	var (
+		repo models.KindActivityRepo
+		conn *db.Connection
+		hour time.Time
+	)
+
+	BeforeEach(func() {
+		repo = models.NewKindActivityRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+
+		conn = database.Connection().(*db.Connection)
+		hour = time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+	})
+
+	Describe("Increment", func() {
+		It("counts activity in the hour it happened", func() {
+			Expect(repo.Increment(conn, "some-client", "some-kind", models.KindActivitySent, hour.Add(5*time.Minute))).To(Succeed())
+			Expect(repo.Increment(conn, "some-client", "some-kind", models.KindActivitySent, hour.Add(55*time.Minute))).To(Succeed())
+			Expect(repo.Increment(conn, "some-client", "some-kind", models.KindActivityFailed, hour.Add(30*time.Minute))).To(Succeed())
+			Expect(repo.Increment(conn, "some-client", "some-kind", models.KindActivityUnsubscribed, hour.Add(90*time.Minute))).To(Succeed())
+
+			activity, err := repo.List(conn, "some-client", "some-kind", hour, hour.Add(24*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(activity).To(HaveLen(2))
+
+			Expect(activity[0].Bucket.UTC()).To(Equal(hour))
+			Expect(activity[0].Sent).To(Equal(2))
+			Expect(activity[0].Failed).To(Equal(1))
+			Expect(activity[0].Unsubscribed).To(Equal(0))
+
+			Expect(activity[1].Bucket.UTC()).To(Equal(hour.Add(time.Hour)))
+			Expect(activity[1].Unsubscribed).To(Equal(1))
+		})
+
+		It("rejects unknown counters", func() {
+			err := repo.Increment(conn, "some-client", "some-kind", "opened", hour)
+			Expect(err).To(MatchError(`unknown kind activity counter "opened"`))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns only the activity for the kind within the range", func() {
+			Expect(repo.Increment(conn, "some-client", "some-kind", models.KindActivitySent, hour.Add(-time.Hour))).To(Succeed())
+			Expect(repo.Increment(conn, "some-client", "some-kind", models.KindActivitySent, hour)).To(Succeed())
+			Expect(repo.Increment(conn, "some-client", "other-kind", models.KindActivitySent, hour)).To(Succeed())
+			Expect(repo.Increment(conn, "some-client", "some-kind", models.KindActivitySent, hour.Add(2*time.Hour))).To(Succeed())
+
+			activity, err := repo.List(conn, "some-client", "some-kind", hour, hour.Add(2*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(activity).To(HaveLen(1))
+			Expect(activity[0].Bucket.UTC()).To(Equal(hour))
+		})
+	})
+
+	It("counts new unsubscribes", func() {
+		unsubscribes := models.NewUnsubscribesRepo()
+		Expect(unsubscribes.Set(conn, "some-user", "some-client", "some-kind", true)).To(Succeed())
+		Expect(unsubscribes.Set(conn, "some-user", "some-client", "some-kind", true)).To(Succeed())
+
+		now := time.Now()
+		activity, err := repo.List(conn, "some-client", "some-kind", now.Add(-time.Hour), now.Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(activity).To(HaveLen(1))
+		Expect(activity[0].Unsubscribed).To(Equal(1))
+	})
+})
@@ -31,4 +31,53 @@ var _ = Describe("Kind", func() {
 			})
 		})
 	})
+
+	Describe("LocalizedDescriptions", func() {
+		var descriptions models.LocalizedDescriptions
+
+		BeforeEach(func() {
+			descriptions = models.LocalizedDescriptions{
+				"fr":    "Réinitialisation du mot de passe",
+				"pt-br": "Redefinição de senha",
+			}
+		})
+
+		It("round-trips through its JSON column value", func() {
+			value, err := descriptions.Value()
+			Expect(err).NotTo(HaveOccurred())
+
+			var scanned models.LocalizedDescriptions
+			Expect(scanned.Scan([]byte(value.(string)))).To(Succeed())
+			Expect(scanned).To(Equal(descriptions))
+		})
+
+		It("is stored as an empty string when there are no translations", func() {
+			value, err := models.LocalizedDescriptions(nil).Value()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(value).To(Equal(""))
+
+			var scanned models.LocalizedDescriptions
+			Expect(scanned.Scan(nil)).To(Succeed())
+			Expect(scanned).To(BeNil())
+		})
+
+		Describe("Describe", func() {
+			It("returns the description for the first locale that has one", func() {
+				description, ok := descriptions.Describe([]string{"de", "pt_BR", "fr"})
+				Expect(ok).To(BeTrue())
+				Expect(description).To(Equal("Redefinição de senha"))
+			})
+
+			It("falls back from a regional locale to its language", func() {
+				description, ok := descriptions.Describe([]string{"fr-CA"})
+				Expect(ok).To(BeTrue())
+				Expect(description).To(Equal("Réinitialisation du mot de passe"))
+			})
+
+			It("reports when none of the locales has a description", func() {
+				_, ok := descriptions.Describe([]string{"de", "pt"})
+				Expect(ok).To(BeFalse())
+			})
+		})
+	})
 })
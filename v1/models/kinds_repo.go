@@ -41,7 +41,7 @@ func (repo KindsRepo) Find(conn ConnectionInterface, id, clientID string) (Kind,
 	err := conn.SelectOne(&kind, "SELECT * FROM `kinds` WHERE `id` = ? AND `client_id` = ?", id, clientID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			err = NotFoundError{fmt.Errorf("Notification with ID %q belonging to client %q could not be found", id, clientID)}
+			err = NotFoundError{Err: fmt.Errorf("Notification with ID %q belonging to client %q could not be found", id, clientID), Code: "notification_not_found"}
 		}
 		return kind, err
 	}
@@ -71,6 +71,9 @@ func (repo KindsRepo) Update(conn ConnectionInterface, kind Kind) (Kind, error)
 	if kind.TemplateID == DoNotSetTemplateID {
 		kind.TemplateID = existingKind.TemplateID
 	}
+	if kind.Severity == "" {
+		kind.Severity = existingKind.Severity
+	}
 
 	_, err = conn.Update(&kind)
 	if err != nil {
@@ -77,7 +77,7 @@ var _ = Describe("KindsRepo", func() {
 					TemplateID: "my-template",
 				}
 				_, err := repo.Update(conn, kind)
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Notification with ID \"my-kind\" belonging to client \"my-client\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Notification with ID \"my-kind\" belonging to client \"my-client\" could not be found"), Code: "notification_not_found"}))
 			})
 		})
 
@@ -129,7 +129,7 @@ var _ = Describe("KindsRepo", func() {
 					ClientID: "my-client",
 				}
 				_, err := repo.Update(conn, kind)
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Notification with ID \"my-kind\" belonging to client \"my-client\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Notification with ID \"my-kind\" belonging to client \"my-client\" could not be found"), Code: "notification_not_found"}))
 			})
 		})
 	})
@@ -218,6 +218,20 @@ var _ = Describe("KindsRepo", func() {
 				Expect(kind.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
 				Expect(kind.UpdatedAt).To(Equal(kind.CreatedAt))
 			})
+
+			It("gives kinds without a severity the info severity", func() {
+				kind, err := repo.Upsert(conn, models.Kind{
+					ID:       "my-kind",
+					ClientID: "my-client",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(kind.Severity).To(Equal(models.SeverityInfo))
+
+				kind.Severity = ""
+				kind, err = repo.Update(conn, kind)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(kind.Severity).To(Equal(models.SeverityInfo))
+			})
 		})
 
 		Context("when the record exists", func() {
@@ -318,7 +332,7 @@ var _ = Describe("KindsRepo", func() {
 			Expect(count).To(Equal(1))
 
 			_, err = repo.Find(conn, "my-kind", "the-client-id")
-			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Notification with ID \"my-kind\" belonging to client \"the-client-id\" could not be found")}))
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Notification with ID \"my-kind\" belonging to client \"the-client-id\" could not be found"), Code: "notification_not_found"}))
 
 			_, err = repo.Find(conn, "ignored-kind", "other-client-id")
 			if err != nil {
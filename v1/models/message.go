@@ -1,19 +1,123 @@
 package models
 
 import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
 	"time"
 
 	"gopkg.in/gorp.v1"
 )
 
 type Message struct {
-	ID         string    `db:"id"`
-	Status     string    `db:"status"`
-	UpdatedAt  time.Time `db:"updated_at"`
+	ID        string    `db:"id"`
+	Status    string    `db:"status"`
+	ClientID  string    `db:"client_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+
+	// WorkerID and ClaimedAt identify the worker that last picked up the
+	// delivery job for this message, and when it did.
+	WorkerID  string       `db:"worker_id"`
+	ClaimedAt sql.NullTime `db:"claimed_at"`
+
+	// Reason says why an undeliverable message was not sent.
+	Reason string `db:"reason"`
+
+	// SMTPCode and SMTPEnhancedStatus are the reply of the mail server that
+	// bounced the message, if it did.
+	SMTPCode           int    `db:"smtp_code"`
+	SMTPEnhancedStatus string `db:"smtp_enhanced_status"`
+
+	// Recipient is the email address or user GUID the message was sent
+	// to, kept so operators can search for it.
+	Recipient string `db:"recipient"`
+
+	// Simulated marks messages of a client in simulation mode, which were
+	// sent to its sink addresses instead of their recipient.
+	Simulated bool `db:"simulated"`
+
+	// Severity is the severity the notification was sent with.
+	Severity string `db:"severity"`
+
+	// Opens and Clicks count how often the message was opened and its
+	// links followed, for clients that track engagement.
+	Opens  int `db:"opens"`
+	Clicks int `db:"clicks"`
+
+	// Metadata holds the key/value pairs the sender attached to the notify
+	// request, echoed back so it can correlate the message with its own
+	// records.
+	Metadata MessageMetadata `db:"metadata"`
+
+	// Delivery is the job payload the message was queued with, kept while
+	// the message waits for a worker so that a job lost from the queue can
+	// be rebuilt. Status updates clear it.
+	Delivery sql.NullString `db:"delivery"`
+}
+
+// MessageMetadata is the sender's metadata of a message. It is stored as a
+// JSON object.
+type MessageMetadata map[string]string
+
+func (m MessageMetadata) Value() (driver.Value, error) {
+	if len(m) == 0 {
+		return "", nil
+	}
+
+	encoded, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+
+	return string(encoded), nil
+}
+
+func (m *MessageMetadata) Scan(src interface{}) error {
+	var encoded []byte
+	switch value := src.(type) {
+	case nil:
+	case string:
+		encoded = []byte(value)
+	case []byte:
+		encoded = value
+	default:
+		return fmt.Errorf("cannot scan %T into MessageMetadata", src)
+	}
+
+	if len(encoded) == 0 {
+		*m = nil
+		return nil
+	}
+
+	return json.Unmarshal(encoded, m)
+}
+
+// The engagement events recorded for messages of clients that track them.
+const (
+	EngagementOpen  = "open"
+	EngagementClick = "click"
+)
+
+// MessageFilter narrows a listing of messages. Empty fields match every
+// message.
+type MessageFilter struct {
+	ClientID  string
+	Recipient string
+	Status    string
+	Since     time.Time
+
+	// ZoneID narrows the messages to those of the clients of the UAA zone,
+	// unless it is the default zone.
+	ZoneID string
 }
 
 func (m *Message) PreInsert(s gorp.SqlExecutor) error {
 	m.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	if m.CreatedAt.IsZero() {
+		m.CreatedAt = m.UpdatedAt
+	}
 
 	return nil
 }
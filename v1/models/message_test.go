@@ -0,0 +1,32 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MessageMetadata", func() {
+	It("round-trips through its JSON column value", func() {
+		metadata := models.MessageMetadata{"ticket": "INC-1234"}
+
+		value, err := metadata.Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(`{"ticket":"INC-1234"}`))
+
+		var scanned models.MessageMetadata
+		Expect(scanned.Scan([]byte(value.(string)))).To(Succeed())
+		Expect(scanned).To(Equal(metadata))
+	})
+
+	It("is stored as an empty string when the message has none", func() {
+		value, err := models.MessageMetadata(nil).Value()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(value).To(Equal(""))
+
+		scanned := models.MessageMetadata{"stale": "value"}
+		Expect(scanned.Scan(nil)).To(Succeed())
+		Expect(scanned).To(BeNil())
+	})
+})
@@ -3,6 +3,7 @@ package models
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -39,7 +40,7 @@ func (repo MessagesRepo) FindByID(conn ConnectionInterface, messageID string) (M
 	err := conn.SelectOne(&message, "SELECT * FROM `messages` WHERE `id`=?", messageID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return Message{}, NotFoundError{fmt.Errorf("Message with ID %q could not be found", messageID)}
+			return Message{}, NotFoundError{Err: fmt.Errorf("Message with ID %q could not be found", messageID), Code: "message_not_found"}
 		}
 		return Message{}, err
 	}
@@ -55,21 +56,145 @@ func (repo MessagesRepo) Update(conn ConnectionInterface, message Message) (Mess
 	return repo.FindByID(conn, message.ID)
 }
 
+// Upsert keeps the client, recipient, simulation mark, metadata, creation
+// time and worker of an existing message when the given message leaves them out, as
+// status updates do. Its engagement counts are always kept, since only
+// RecordEngagement changes them.
 func (repo MessagesRepo) Upsert(conn ConnectionInterface, message Message) (Message, error) {
-	_, err := repo.FindByID(conn, message.ID)
+	existing, err := repo.FindByID(conn, message.ID)
 
 	switch err.(type) {
 	case NotFoundError:
 		return repo.Create(conn, message)
 	case nil:
+		if message.ClientID == "" {
+			message.ClientID = existing.ClientID
+		}
+		if message.Recipient == "" {
+			message.Recipient = existing.Recipient
+		}
+		if !message.Simulated {
+			message.Simulated = existing.Simulated
+		}
+		if message.Severity == "" {
+			message.Severity = existing.Severity
+		}
+		if len(message.Metadata) == 0 {
+			message.Metadata = existing.Metadata
+		}
+		if message.CreatedAt.IsZero() {
+			message.CreatedAt = existing.CreatedAt
+		}
+		if message.WorkerID == "" {
+			message.WorkerID = existing.WorkerID
+			message.ClaimedAt = existing.ClaimedAt
+		}
+		message.Opens = existing.Opens
+		message.Clicks = existing.Clicks
 		return repo.Update(conn, message)
 	default:
 		return message, err
 	}
 }
 
-func (repo MessagesRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time) (int, error) {
-	result, err := conn.Exec("DELETE FROM `messages` WHERE `updated_at` < ?", threshold.UTC())
+// RecordEngagement counts an open or a click of the message. A message that
+// has been deleted since it was sent is not an error.
+func (repo MessagesRepo) RecordEngagement(conn ConnectionInterface, messageID, event string) error {
+	var column string
+	switch event {
+	case EngagementOpen:
+		column = "opens"
+	case EngagementClick:
+		column = "clicks"
+	default:
+		return fmt.Errorf("unknown engagement event %q", event)
+	}
+
+	_, err := conn.Exec(fmt.Sprintf("UPDATE `messages` SET `%[1]s` = `%[1]s` + 1 WHERE `id` = ?", column), messageID)
+
+	return err
+}
+
+// List returns a page of the messages matching filter, newest first.
+func (repo MessagesRepo) List(conn ConnectionInterface, filter MessageFilter, offset, limit int) ([]Message, error) {
+	where, args := messageFilterClause(filter)
+
+	messages := []Message{}
+	_, err := conn.Select(&messages, "SELECT * FROM `messages`"+where+" ORDER BY `created_at` DESC, `id` DESC LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return []Message{}, err
+	}
+
+	return messages, nil
+}
+
+func (repo MessagesRepo) Count(conn ConnectionInterface, filter MessageFilter) (int, error) {
+	where, args := messageFilterClause(filter)
+
+	var count int
+	err := conn.SelectOne(&count, "SELECT COUNT(*) FROM `messages`"+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func messageFilterClause(filter MessageFilter) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if filter.ClientID != "" {
+		conditions = append(conditions, "`client_id` = ?")
+		args = append(args, filter.ClientID)
+	}
+
+	if filter.Recipient != "" {
+		conditions = append(conditions, "`recipient` = ?")
+		args = append(args, filter.Recipient)
+	}
+
+	if filter.Status != "" {
+		conditions = append(conditions, "`status` = ?")
+		args = append(args, filter.Status)
+	}
+
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "`created_at` >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+
+	if filter.ZoneID != "" && filter.ZoneID != DefaultZoneID {
+		conditions = append(conditions, "`client_id` LIKE ?")
+		args = append(args, likePrefix(ZonedClientID(filter.ZoneID, "")))
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// likePrefix is the LIKE pattern of the values that start with prefix.
+func likePrefix(prefix string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(prefix) + "%"
+}
+
+// FindQueuedBefore returns the messages that have been queued since before
+// threshold, oldest first.
+func (repo MessagesRepo) FindQueuedBefore(conn ConnectionInterface, threshold time.Time) ([]Message, error) {
+	messages := []Message{}
+	_, err := conn.Select(&messages, "SELECT * FROM `messages` WHERE `status` = ? AND `updated_at` < ? ORDER BY `updated_at`", "queued", threshold.UTC())
+	if err != nil {
+		return []Message{}, err
+	}
+
+	return messages, nil
+}
+
+func (repo MessagesRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time, limit int) (int, error) {
+	result, err := conn.Exec("DELETE FROM `messages` WHERE `updated_at` < ? LIMIT ?", threshold.UTC(), limit)
 	if err != nil {
 		return 0, err
 	}
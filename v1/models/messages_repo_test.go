@@ -1,6 +1,7 @@
 package models_test
 
 import (
+	"database/sql"
 	"errors"
 	"fmt"
 	"time"
@@ -69,7 +70,7 @@ var _ = Describe("MessagesRepo", func() {
 		Context("When the message does not exists", func() {
 			It("FindByID returns a models.RecordNotFoundError", func() {
 				_, err := repo.FindByID(conn, "missing-id")
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Message with ID \"missing-id\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Message with ID \"missing-id\" could not be found"), Code: "message_not_found"}))
 			})
 		})
 	})
@@ -109,6 +110,176 @@ var _ = Describe("MessagesRepo", func() {
 				Expect(messageFound.ID).To(Equal(message.ID))
 				Expect(messageFound.Status).To(Equal(message.Status))
 			})
+
+			It("keeps the client, recipient, simulation mark, metadata and creation time when the update leaves them out", func() {
+				message.ClientID = "some-client"
+				message.Recipient = "user@example.com"
+				message.Simulated = true
+				message.Metadata = models.MessageMetadata{"ticket": "INC-1234"}
+				message.CreatedAt = time.Now().Add(-2 * time.Hour).Truncate(time.Second).UTC()
+				message, err := repo.Create(conn, message)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = repo.Upsert(conn, models.Message{
+					ID:     message.ID,
+					Status: common.StatusFailed,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				messageFound, err := repo.FindByID(conn, message.ID)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(messageFound.Status).To(Equal(common.StatusFailed))
+				Expect(messageFound.ClientID).To(Equal("some-client"))
+				Expect(messageFound.Recipient).To(Equal("user@example.com"))
+				Expect(messageFound.Simulated).To(BeTrue())
+				Expect(messageFound.Metadata).To(Equal(models.MessageMetadata{"ticket": "INC-1234"}))
+				Expect(messageFound.CreatedAt).To(Equal(message.CreatedAt))
+			})
+
+			It("records the worker that claimed the message, keeping it when the update leaves it out", func() {
+				message, err := repo.Create(conn, message)
+				Expect(err).NotTo(HaveOccurred())
+
+				claimedAt := time.Now().Add(-time.Minute).Truncate(time.Second).UTC()
+				_, err = repo.Upsert(conn, models.Message{
+					ID:        message.ID,
+					Status:    common.StatusFailed,
+					WorkerID:  "worker-1-some-instance-42",
+					ClaimedAt: sql.NullTime{Time: claimedAt, Valid: true},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = repo.Upsert(conn, models.Message{
+					ID:     message.ID,
+					Status: common.StatusDelivered,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				messageFound, err := repo.FindByID(conn, message.ID)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(messageFound.Status).To(Equal(common.StatusDelivered))
+				Expect(messageFound.WorkerID).To(Equal("worker-1-some-instance-42"))
+				Expect(messageFound.ClaimedAt).To(Equal(sql.NullTime{Time: claimedAt, Valid: true}))
+			})
+
+			It("clears the queued delivery when the status is updated", func() {
+				message.Delivery = sql.NullString{String: `{"UserGUID":"user-1"}`, Valid: true}
+				message, err := repo.Create(conn, message)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = repo.Upsert(conn, models.Message{
+					ID:     message.ID,
+					Status: common.StatusDelivered,
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				messageFound, err := repo.FindByID(conn, message.ID)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(messageFound.Delivery.Valid).To(BeFalse())
+			})
+		})
+	})
+
+	Describe("FindQueuedBefore", func() {
+		It("returns the messages queued since before the threshold, oldest first", func() {
+			now := time.Now().Truncate(time.Second).UTC()
+			guidGenerator.GenerateCall.Returns.IDs = []string{"message-1", "message-2", "message-3", "message-4"}
+
+			for _, m := range []models.Message{
+				{Status: common.StatusQueued, UpdatedAt: now.Add(-time.Hour)},
+				{Status: common.StatusQueued, UpdatedAt: now.Add(-2 * time.Hour)},
+				{Status: common.StatusQueued, UpdatedAt: now},
+				{Status: common.StatusDelivered, UpdatedAt: now.Add(-time.Hour)},
+			} {
+				message, err := repo.Create(conn, m)
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = conn.Exec("UPDATE `messages` SET `updated_at` = ? WHERE `id` = ?", m.UpdatedAt, message.ID)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			messages, err := repo.FindQueuedBefore(conn, now.Add(-time.Minute))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[0].ID).To(Equal("message-2"))
+			Expect(messages[1].ID).To(Equal("message-1"))
+		})
+	})
+
+	Describe("List and Count", func() {
+		var now time.Time
+
+		BeforeEach(func() {
+			now = time.Now().Truncate(time.Second).UTC()
+			guidGenerator.GenerateCall.Returns.IDs = []string{"message-1", "message-2", "message-3", "message-4"}
+
+			for i, m := range []models.Message{
+				{ClientID: "client-a", Recipient: "user-1", Status: common.StatusDelivered, CreatedAt: now.Add(-3 * time.Hour)},
+				{ClientID: "client-a", Recipient: "user-2", Status: common.StatusFailed, CreatedAt: now.Add(-2 * time.Hour)},
+				{ClientID: "client-b", Recipient: "user-1", Status: common.StatusDelivered, CreatedAt: now.Add(-1 * time.Hour)},
+				{ClientID: "client-a", Recipient: "user-1", Status: common.StatusDelivered, CreatedAt: now},
+			} {
+				_, err := repo.Create(conn, m)
+				Expect(err).NotTo(HaveOccurred(), fmt.Sprintf("message %d", i))
+			}
+		})
+
+		ids := func(messages []models.Message) []string {
+			var found []string
+			for _, m := range messages {
+				found = append(found, m.ID)
+			}
+			return found
+		}
+
+		It("lists every message newest first when the filter is empty", func() {
+			messages, err := repo.List(conn, models.MessageFilter{}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids(messages)).To(Equal([]string{"message-4", "message-3", "message-2", "message-1"}))
+
+			count, err := repo.Count(conn, models.MessageFilter{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(4))
+		})
+
+		It("filters by client, status and creation time", func() {
+			filter := models.MessageFilter{
+				ClientID: "client-a",
+				Status:   common.StatusDelivered,
+				Since:    now.Add(-90 * time.Minute),
+			}
+
+			messages, err := repo.List(conn, filter, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids(messages)).To(Equal([]string{"message-4"}))
+
+			count, err := repo.Count(conn, filter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+
+		It("filters by recipient", func() {
+			filter := models.MessageFilter{Recipient: "user-1"}
+
+			messages, err := repo.List(conn, filter, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids(messages)).To(Equal([]string{"message-4", "message-3", "message-1"}))
+
+			count, err := repo.Count(conn, filter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(3))
+		})
+
+		It("pages through the messages", func() {
+			messages, err := repo.List(conn, models.MessageFilter{ClientID: "client-a"}, 1, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ids(messages)).To(Equal([]string{"message-2"}))
+
+			count, err := repo.Count(conn, models.MessageFilter{ClientID: "client-a"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(3))
 		})
 	})
 
@@ -117,12 +288,12 @@ var _ = Describe("MessagesRepo", func() {
 			message, err := repo.Create(conn, message)
 			Expect(err).NotTo(HaveOccurred())
 
-			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(1*time.Hour))
+			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 100)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(itemsDeleted).To(Equal(1))
 
 			_, err = repo.FindByID(conn, message.ID)
-			Expect(err).To(MatchError(models.NotFoundError{Err: fmt.Errorf("Message with ID %q could not be found", message.ID)}))
+			Expect(err).To(MatchError(models.NotFoundError{Err: fmt.Errorf("Message with ID %q could not be found", message.ID), Code: "message_not_found"}))
 
 		})
 
@@ -130,7 +301,7 @@ var _ = Describe("MessagesRepo", func() {
 			message, err := repo.Create(conn, message)
 			Expect(err).NotTo(HaveOccurred())
 
-			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(-1*time.Hour))
+			itemsDeleted, err := repo.DeleteBefore(conn, time.Now().Add(-1*time.Hour), 100)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(itemsDeleted).To(Equal(0))
 
@@ -0,0 +1,52 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type OrganizationPoliciesRepo struct{}
+
+func NewOrganizationPoliciesRepo() OrganizationPoliciesRepo {
+	return OrganizationPoliciesRepo{}
+}
+
+// Find returns the policy of the organization. Organizations without a
+// stored policy get the default one, which has every option turned off.
+func (repo OrganizationPoliciesRepo) Find(conn ConnectionInterface, orgGUID string) (OrganizationPolicy, error) {
+	policy := OrganizationPolicy{}
+	err := conn.SelectOne(&policy, "SELECT * FROM `organization_policies` WHERE `organization_guid` = ?", orgGUID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return OrganizationPolicy{OrganizationGUID: orgGUID}, nil
+		}
+
+		return OrganizationPolicy{}, err
+	}
+
+	return policy, nil
+}
+
+func (repo OrganizationPoliciesRepo) Upsert(conn ConnectionInterface, policy OrganizationPolicy) (OrganizationPolicy, error) {
+	existing, err := repo.Find(conn, policy.OrganizationGUID)
+	if err != nil {
+		return policy, err
+	}
+
+	now := time.Now().Truncate(1 * time.Second).UTC()
+	policy.Primary = existing.Primary
+	policy.CreatedAt = existing.CreatedAt
+	policy.UpdatedAt = now
+
+	if policy.Primary == 0 {
+		policy.CreatedAt = now
+		err = conn.Insert(&policy)
+	} else {
+		_, err = conn.Update(&policy)
+	}
+	if err != nil {
+		return policy, err
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,50 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrganizationPoliciesRepo", func() {
+	var repo models.OrganizationPoliciesRepo
+	var conn *db.Connection
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewOrganizationPoliciesRepo()
+	})
+
+	Describe("Find", func() {
+		It("returns the default policy for an organization without one", func() {
+			policy, err := repo.Find(conn, "some-org")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy).To(Equal(models.OrganizationPolicy{OrganizationGUID: "some-org"}))
+		})
+	})
+
+	Describe("Upsert", func() {
+		It("creates and then updates the policy of an organization", func() {
+			_, err := repo.Upsert(conn, models.OrganizationPolicy{OrganizationGUID: "some-org", AuditCriticalSends: true})
+			Expect(err).NotTo(HaveOccurred())
+
+			policy, err := repo.Find(conn, "some-org")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(policy.Primary).NotTo(BeZero())
+			Expect(policy.AuditCriticalSends).To(BeTrue())
+
+			_, err = repo.Upsert(conn, models.OrganizationPolicy{OrganizationGUID: "some-org", AuditCriticalSends: false})
+			Expect(err).NotTo(HaveOccurred())
+
+			updated, err := repo.Find(conn, "some-org")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Primary).To(Equal(policy.Primary))
+			Expect(updated.AuditCriticalSends).To(BeFalse())
+		})
+	})
+})
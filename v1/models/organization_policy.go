@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type OrganizationPolicy struct {
+	Primary            int       `db:"primary"`
+	OrganizationGUID   string    `db:"organization_guid"`
+	AuditCriticalSends bool      `db:"audit_critical_sends"`
+	CreatedAt          time.Time `db:"created_at"`
+	UpdatedAt          time.Time `db:"updated_at"`
+}
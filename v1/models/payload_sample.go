@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// PayloadSample is the delivery a notification of a kind was queued with,
+// kept so that changes to its template can be tried against real payloads.
+type PayloadSample struct {
+	Primary   int       `db:"primary"`
+	ClientID  string    `db:"client_id"`
+	KindID    string    `db:"kind_id"`
+	Delivery  string    `db:"delivery"`
+	CreatedAt time.Time `db:"created_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// PayloadSamplesKept is how many of the latest samples of each kind are
+// kept. Older ones are deleted as new ones are created.
+const PayloadSamplesKept = 100
+
+type PayloadSamplesRepo struct{}
+
+func NewPayloadSamplesRepo() PayloadSamplesRepo {
+	return PayloadSamplesRepo{}
+}
+
+func (repo PayloadSamplesRepo) Create(conn ConnectionInterface, sample PayloadSample) error {
+	sample.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	err := conn.Insert(&sample)
+	if err != nil {
+		return err
+	}
+
+	// The derived table lets MySQL read the table it is deleting from.
+	_, err = conn.Exec("DELETE FROM `payload_samples` WHERE `client_id` = ? AND `kind_id` = ? AND `primary` < "+
+		"(SELECT `primary` FROM (SELECT `primary` FROM `payload_samples` WHERE `client_id` = ? AND `kind_id` = ? ORDER BY `primary` DESC LIMIT 1 OFFSET ?) AS `kept`)",
+		sample.ClientID, sample.KindID, sample.ClientID, sample.KindID, PayloadSamplesKept-1)
+
+	return err
+}
+
+// FindRecent returns up to limit of the latest samples of the kind, newest
+// first.
+func (repo PayloadSamplesRepo) FindRecent(conn ConnectionInterface, clientID, kindID string, limit int) ([]PayloadSample, error) {
+	samples := []PayloadSample{}
+	_, err := conn.Select(&samples, "SELECT * FROM `payload_samples` WHERE `client_id` = ? AND `kind_id` = ? ORDER BY `primary` DESC LIMIT ?", clientID, kindID, limit)
+	if err != nil {
+		return []PayloadSample{}, err
+	}
+
+	return samples, nil
+}
+
+func (repo PayloadSamplesRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time, limit int) (int, error) {
+	result, err := conn.Exec("DELETE FROM `payload_samples` WHERE `created_at` < ? LIMIT ?", threshold.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
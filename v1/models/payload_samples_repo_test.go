@@ -0,0 +1,81 @@
+package models_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PayloadSamplesRepo", func() {
+	var (
+		repo models.PayloadSamplesRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		repo = models.NewPayloadSamplesRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+
+		conn = database.Connection().(*db.Connection)
+	})
+
+	Describe("FindRecent", func() {
+		It("returns the latest samples of the kind, newest first", func() {
+			for _, delivery := range []string{"first", "second", "third"} {
+				Expect(repo.Create(conn, models.PayloadSample{ClientID: "raptors", KindID: "feeding-time", Delivery: delivery})).To(Succeed())
+			}
+			Expect(repo.Create(conn, models.PayloadSample{ClientID: "raptors", KindID: "door-opening", Delivery: "other"})).To(Succeed())
+
+			samples, err := repo.FindRecent(conn, "raptors", "feeding-time", 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(HaveLen(2))
+			Expect(samples[0].Delivery).To(Equal("third"))
+			Expect(samples[1].Delivery).To(Equal("second"))
+			Expect(samples[0].CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+	})
+
+	Describe("Create", func() {
+		It("keeps only the latest samples of each kind", func() {
+			for i := 0; i < models.PayloadSamplesKept+5; i++ {
+				Expect(repo.Create(conn, models.PayloadSample{ClientID: "raptors", KindID: "feeding-time", Delivery: fmt.Sprintf("delivery-%d", i)})).To(Succeed())
+			}
+			Expect(repo.Create(conn, models.PayloadSample{ClientID: "raptors", KindID: "door-opening", Delivery: "other"})).To(Succeed())
+
+			samples, err := repo.FindRecent(conn, "raptors", "feeding-time", 2*models.PayloadSamplesKept)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(HaveLen(models.PayloadSamplesKept))
+			Expect(samples[len(samples)-1].Delivery).To(Equal("delivery-5"))
+
+			samples, err = repo.FindRecent(conn, "raptors", "door-opening", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(HaveLen(1))
+		})
+	})
+
+	Describe("DeleteBefore", func() {
+		It("deletes the samples created before the threshold", func() {
+			Expect(repo.Create(conn, models.PayloadSample{ClientID: "raptors", KindID: "feeding-time", Delivery: "old"})).To(Succeed())
+			_, err := conn.Exec("UPDATE `payload_samples` SET `created_at` = ?", time.Now().Add(-48*time.Hour).UTC())
+			Expect(err).NotTo(HaveOccurred())
+			Expect(repo.Create(conn, models.PayloadSample{ClientID: "raptors", KindID: "feeding-time", Delivery: "new"})).To(Succeed())
+
+			count, err := repo.DeleteBefore(conn, time.Now().Add(-24*time.Hour), 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+
+			samples, err := repo.FindRecent(conn, "raptors", "feeding-time", 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(samples).To(HaveLen(1))
+			Expect(samples[0].Delivery).To(Equal("new"))
+		})
+	})
+})
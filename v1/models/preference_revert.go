@@ -0,0 +1,12 @@
+package models
+
+import "time"
+
+// PreferenceRevert records that a preference revert link was followed, so
+// that it cannot be followed again before it expires.
+type PreferenceRevert struct {
+	ID        string    `db:"id"`
+	UserGUID  string    `db:"user_guid"`
+	ExpiresAt time.Time `db:"expires_at"`
+	UsedAt    time.Time `db:"used_at"`
+}
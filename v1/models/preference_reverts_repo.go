@@ -0,0 +1,46 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type PreferenceRevertsRepo struct{}
+
+func NewPreferenceRevertsRepo() PreferenceRevertsRepo {
+	return PreferenceRevertsRepo{}
+}
+
+// Claim records the revert as used. A DuplicateError is returned when it
+// already has been.
+func (repo PreferenceRevertsRepo) Claim(conn ConnectionInterface, revert PreferenceRevert) error {
+	revert.ExpiresAt = revert.ExpiresAt.UTC()
+	revert.UsedAt = revert.UsedAt.UTC()
+
+	err := conn.Insert(&revert)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return DuplicateError{Err: errors.New("The revert link has already been used")}
+		}
+		return err
+	}
+
+	return nil
+}
+
+// DeleteBefore forgets the reverts whose links expired before the time,
+// since those links can no longer be followed anyway.
+func (repo PreferenceRevertsRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time) (int, error) {
+	result, err := conn.Exec("DELETE FROM `preference_reverts` WHERE `expires_at` < ?", threshold.UTC())
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
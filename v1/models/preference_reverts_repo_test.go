@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreferenceRevertsRepo", func() {
+	var (
+		repo models.PreferenceRevertsRepo
+		conn *db.Connection
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		repo = models.NewPreferenceRevertsRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+
+		now = time.Now().Truncate(time.Second).UTC()
+	})
+
+	It("only lets a revert be claimed once", func() {
+		revert := models.PreferenceRevert{
+			ID:        "revert-123",
+			UserGUID:  "user-123",
+			ExpiresAt: now.Add(time.Hour),
+			UsedAt:    now,
+		}
+
+		Expect(repo.Claim(conn, revert)).To(Succeed())
+
+		err := repo.Claim(conn, revert)
+		Expect(err).To(BeAssignableToTypeOf(models.DuplicateError{}))
+		Expect(err).To(MatchError("The revert link has already been used"))
+	})
+
+	It("deletes the reverts that have expired", func() {
+		Expect(repo.Claim(conn, models.PreferenceRevert{ID: "expired", UserGUID: "user-123", ExpiresAt: now.Add(-time.Hour), UsedAt: now.Add(-2 * time.Hour)})).To(Succeed())
+		Expect(repo.Claim(conn, models.PreferenceRevert{ID: "current", UserGUID: "user-123", ExpiresAt: now.Add(time.Hour), UsedAt: now})).To(Succeed())
+
+		count, err := repo.DeleteBefore(conn, now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(1))
+
+		Expect(repo.Claim(conn, models.PreferenceRevert{ID: "expired", UserGUID: "user-123", ExpiresAt: now.Add(time.Hour), UsedAt: now})).To(Succeed())
+		Expect(repo.Claim(conn, models.PreferenceRevert{ID: "current", UserGUID: "user-123", ExpiresAt: now.Add(time.Hour), UsedAt: now})).NotTo(Succeed())
+	})
+})
@@ -1,9 +1,18 @@
 package models
 
 type Preference struct {
-	ClientID          string `db:"client_id"`
-	KindID            string `db:"kind_id"`
-	KindDescription   string `db:"kind_description"`
-	SourceDescription string `db:"source_description"`
-	Email             bool
+	ClientID              string                `db:"client_id"`
+	KindID                string                `db:"kind_id"`
+	KindDescription       string                `db:"kind_description"`
+	LocalizedDescriptions LocalizedDescriptions `db:"localized_descriptions"`
+	SourceDescription     string                `db:"source_description"`
+	OptIn                 bool                  `db:"opt_in"`
+	Email                 bool
+}
+
+// StoredPreferences are the choices a user has made about individual kinds,
+// as opposed to the preferences they have by default.
+type StoredPreferences struct {
+	Unsubscribes  Unsubscribes
+	Subscriptions Subscriptions
 }
@@ -1,7 +1,8 @@
 package models
 
 type PreferencesRepo struct {
-	unsubscribesRepo UnsubscribesRepo
+	unsubscribesRepo  UnsubscribesRepo
+	subscriptionsRepo SubscriptionsRepo
 }
 
 func NewPreferencesRepo() PreferencesRepo {
@@ -13,14 +14,16 @@ func (repo PreferencesRepo) FindNonCriticalPreferences(conn ConnectionInterface,
 	sql := `SELECT DISTINCT kinds.id AS kind_id,
 				clients.id AS client_id,
 				kinds.description AS kind_description,
-				clients.description AS source_description
+				kinds.localized_descriptions AS localized_descriptions,
+				clients.description AS source_description,
+				kinds.opt_in AS opt_in
 			FROM kinds
 			JOIN clients on kinds.client_id = clients.id
-			WHERE kinds.client_id IN (
+			WHERE (kinds.client_id IN (
 				SELECT client_id
 				FROM receipts
 				WHERE user_guid = ?
-			)
+			) OR kinds.opt_in = true)
 			AND kinds.critical = false`
 
 	_, err := conn.Select(&preferences, sql, userGUID)
@@ -33,10 +36,58 @@ func (repo PreferencesRepo) FindNonCriticalPreferences(conn ConnectionInterface,
 		return preferences, err
 	}
 
+	subs, err := repo.subscriptionsRepo.FindAllByUserID(conn, userGUID)
+	if err != nil {
+		return preferences, err
+	}
+
 	unsubscribes := Unsubscribes(unsubs)
+	subscriptions := Subscriptions(subs)
 	for index, preference := range preferences {
 		preferences[index].Email = !unsubscribes.Contains(preference.ClientID, preference.KindID)
+
+		if preference.OptIn && !subscriptions.Contains(preference.ClientID, preference.KindID) {
+			preferences[index].Email = false
+		}
 	}
 
 	return preferences, nil
 }
+
+func (repo PreferencesRepo) FindStored(conn ConnectionInterface, userGUID string) (StoredPreferences, error) {
+	unsubscribes, err := repo.unsubscribesRepo.FindAllByUserID(conn, userGUID)
+	if err != nil {
+		return StoredPreferences{}, err
+	}
+
+	subscriptions, err := repo.subscriptionsRepo.FindAllByUserID(conn, userGUID)
+	if err != nil {
+		return StoredPreferences{}, err
+	}
+
+	return StoredPreferences{
+		Unsubscribes:  unsubscribes,
+		Subscriptions: subscriptions,
+	}, nil
+}
+
+// FindUserIDs pages through the users who have stored any preference, in
+// order of their IDs, starting after the given ID.
+func (repo PreferencesRepo) FindUserIDs(conn ConnectionInterface, after string, limit int) ([]string, error) {
+	var userIDs []string
+	_, err := conn.Select(&userIDs, `SELECT user_id FROM (
+				SELECT user_id FROM unsubscribes
+				UNION SELECT user_id FROM subscriptions
+				UNION SELECT user_id FROM global_unsubscribes
+				UNION SELECT user_id FROM digest_preferences
+				UNION SELECT user_id FROM severity_unsubscribes
+			) AS users
+			WHERE user_id > ?
+			ORDER BY user_id
+			LIMIT ?`, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return userIDs, nil
+}
@@ -57,10 +57,11 @@ var _ = Describe("PreferencesRepo", func() {
 				}
 
 				secondNonCriticalKind := models.Kind{
-					ID:          "dead",
-					Description: "dead description",
-					ClientID:    "raptors",
-					Critical:    false,
+					ID:                    "dead",
+					Description:           "dead description",
+					ClientID:              "raptors",
+					Critical:              false,
+					LocalizedDescriptions: models.LocalizedDescriptions{"fr": "description morte"},
 				}
 
 				nonCriticalKindThatUserHasNotReceived := models.Kind{
@@ -142,11 +143,12 @@ var _ = Describe("PreferencesRepo", func() {
 				}))
 
 				Expect(results).To(ContainElement(models.Preference{
-					ClientID:          "raptors",
-					KindID:            "dead",
-					Email:             true,
-					KindDescription:   "dead description",
-					SourceDescription: "raptors description",
+					ClientID:              "raptors",
+					KindID:                "dead",
+					Email:                 true,
+					KindDescription:       "dead description",
+					LocalizedDescriptions: models.LocalizedDescriptions{"fr": "description morte"},
+					SourceDescription:     "raptors description",
 				}))
 
 				Expect(results).To(ContainElement(models.Preference{
@@ -157,6 +159,95 @@ var _ = Describe("PreferencesRepo", func() {
 					SourceDescription: "raptors description",
 				}))
 			})
+
+			Context("when there are opt-in notifications", func() {
+				BeforeEach(func() {
+					_, err := clients.Upsert(conn, models.Client{
+						ID:          "newsletters",
+						Description: "newsletters description",
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = kinds.Upsert(conn, models.Kind{
+						ID:          "weekly",
+						Description: "weekly description",
+						ClientID:    "newsletters",
+						OptIn:       true,
+					})
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = kinds.Upsert(conn, models.Kind{
+						ID:          "monthly",
+						Description: "monthly description",
+						ClientID:    "newsletters",
+						OptIn:       true,
+					})
+					Expect(err).NotTo(HaveOccurred())
+				})
+
+				It("includes them even before anything has been received, subscribed only when the user opted in", func() {
+					err := models.NewSubscriptionsRepo().Set(conn, "correct-user", "newsletters", "weekly", true)
+					Expect(err).NotTo(HaveOccurred())
+
+					results, err := repo.FindNonCriticalPreferences(conn, "correct-user")
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(results).To(HaveLen(5))
+
+					Expect(results).To(ContainElement(models.Preference{
+						ClientID:          "newsletters",
+						KindID:            "weekly",
+						Email:             true,
+						OptIn:             true,
+						KindDescription:   "weekly description",
+						SourceDescription: "newsletters description",
+					}))
+
+					Expect(results).To(ContainElement(models.Preference{
+						ClientID:          "newsletters",
+						KindID:            "monthly",
+						Email:             false,
+						OptIn:             true,
+						KindDescription:   "monthly description",
+						SourceDescription: "newsletters description",
+					}))
+				})
+			})
+		})
+	})
+
+	Describe("FindStored", func() {
+		It("returns the kinds the user unsubscribed from and subscribed to", func() {
+			Expect(unsubscribeRepo.Set(conn, "some-user", "raptors", "sleepy", true)).To(Succeed())
+			Expect(unsubscribeRepo.Set(conn, "other-user", "raptors", "dead", true)).To(Succeed())
+			Expect(models.NewSubscriptionsRepo().Set(conn, "some-user", "newsletters", "weekly", true)).To(Succeed())
+
+			stored, err := repo.FindStored(conn, "some-user")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stored.Unsubscribes).To(HaveLen(1))
+			Expect(stored.Unsubscribes.Contains("raptors", "sleepy")).To(BeTrue())
+			Expect(stored.Subscriptions).To(HaveLen(1))
+			Expect(stored.Subscriptions.Contains("newsletters", "weekly")).To(BeTrue())
+		})
+	})
+
+	Describe("FindUserIDs", func() {
+		BeforeEach(func() {
+			Expect(unsubscribeRepo.Set(conn, "user-d", "raptors", "sleepy", true)).To(Succeed())
+			Expect(unsubscribeRepo.Set(conn, "user-a", "raptors", "sleepy", true)).To(Succeed())
+			Expect(models.NewSubscriptionsRepo().Set(conn, "user-a", "newsletters", "weekly", true)).To(Succeed())
+			Expect(models.NewGlobalUnsubscribesRepo().Set(conn, "user-c", true)).To(Succeed())
+			Expect(models.NewDigestPreferencesRepo().Set(conn, "user-b", models.DigestDaily)).To(Succeed())
+		})
+
+		It("pages through every user with a stored preference once, in order", func() {
+			userIDs, err := repo.FindUserIDs(conn, "", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(userIDs).To(Equal([]string{"user-a", "user-b", "user-c"}))
+
+			userIDs, err = repo.FindUserIDs(conn, "user-c", 3)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(userIDs).To(Equal([]string{"user-d"}))
 		})
 	})
 })
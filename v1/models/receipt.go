@@ -13,10 +13,12 @@ type Receipt struct {
 	KindID    string    `db:"kind_id"`
 	Count     int       `db:"count"`
 	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
 }
 
 func (r *Receipt) PreInsert(s gorp.SqlExecutor) error {
 	r.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	r.UpdatedAt = r.CreatedAt
 
 	if r.Count == 0 {
 		r.Count = 1
@@ -1,6 +1,10 @@
 package models
 
-import "time"
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
 
 type ReceiptsRepo struct{}
 
@@ -9,8 +13,9 @@ func NewReceiptsRepo() ReceiptsRepo {
 }
 
 func (repo ReceiptsRepo) upsert(conn ConnectionInterface, receipt Receipt) error {
-	query := "INSERT INTO `receipts` (`user_guid`, `client_id`, `kind_id`, `count`, `created_at`) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `count`=`count`+1"
-	_, err := conn.Exec(query, receipt.UserGUID, receipt.ClientID, receipt.KindID, 1, time.Now().Truncate(1*time.Second).UTC())
+	now := time.Now().Truncate(1 * time.Second).UTC()
+	query := "INSERT INTO `receipts` (`user_guid`, `client_id`, `kind_id`, `count`, `created_at`, `updated_at`) VALUES (?, ?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE `count`=`count`+1, `updated_at`=VALUES(`updated_at`)"
+	_, err := conn.Exec(query, receipt.UserGUID, receipt.ClientID, receipt.KindID, 1, now, now)
 	if err != nil {
 		return err
 	}
@@ -32,3 +37,30 @@ func (repo ReceiptsRepo) CreateReceipts(conn ConnectionInterface, userGUIDs []st
 	}
 	return nil
 }
+
+func (repo ReceiptsRepo) Find(conn ConnectionInterface, userGUID, clientID, kindID string) (Receipt, error) {
+	receipt := Receipt{}
+	err := conn.SelectOne(&receipt, "SELECT * FROM `receipts` WHERE `user_guid` = ? AND `client_id` = ? AND `kind_id` = ?", userGUID, clientID, kindID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = NotFoundError{Err: fmt.Errorf("Receipt for user %q of client %q and kind %q could not be found", userGUID, clientID, kindID), Code: "receipt_not_found"}
+		}
+		return receipt, err
+	}
+
+	return receipt, nil
+}
+
+func (repo ReceiptsRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time, limit int) (int, error) {
+	result, err := conn.Exec("DELETE FROM `receipts` WHERE `created_at` < ? LIMIT ?", threshold.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
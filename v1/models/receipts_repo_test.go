@@ -1,6 +1,9 @@
 package models_test
 
 import (
+	"errors"
+	"time"
+
 	"github.com/cloudfoundry-incubator/notifications/db"
 	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
@@ -166,4 +169,69 @@ var _ = Describe("Receipts Repo", func() {
 			Expect(firstReceipt.Primary).ToNot(Equal(differentKindReceipt.Primary))
 		})
 	})
+
+	Describe("Find", func() {
+		It("finds the receipt for the user, client and kind", func() {
+			err := repo.CreateReceipts(conn, []string{"user-123"}, "client-abc", "be-kind")
+			Expect(err).NotTo(HaveOccurred())
+
+			receipt, err := repo.Find(conn, "user-123", "client-abc", "be-kind")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receipt.UserGUID).To(Equal("user-123"))
+			Expect(receipt.ClientID).To(Equal("client-abc"))
+			Expect(receipt.KindID).To(Equal("be-kind"))
+			Expect(receipt.Count).To(Equal(1))
+			Expect(receipt.CreatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+			Expect(receipt.UpdatedAt).To(Equal(receipt.CreatedAt))
+		})
+
+		It("moves the updated_at time forward when the user is notified again", func() {
+			_, err := createReceipt(conn, models.Receipt{
+				UserGUID: "user-123",
+				ClientID: "client-abc",
+				KindID:   "be-kind",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = conn.Exec("UPDATE `receipts` SET `created_at` = ?, `updated_at` = ?", time.Now().Add(-48*time.Hour).UTC(), time.Now().Add(-48*time.Hour).UTC())
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.CreateReceipts(conn, []string{"user-123"}, "client-abc", "be-kind")
+			Expect(err).NotTo(HaveOccurred())
+
+			receipt, err := repo.Find(conn, "user-123", "client-abc", "be-kind")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(receipt.Count).To(Equal(2))
+			Expect(receipt.CreatedAt).To(BeTemporally("~", time.Now().Add(-48*time.Hour), 2*time.Second))
+			Expect(receipt.UpdatedAt).To(BeTemporally("~", time.Now(), 2*time.Second))
+		})
+
+		It("returns a not found error when the user has no receipt", func() {
+			_, err := repo.Find(conn, "user-123", "client-abc", "be-kind")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New(`Receipt for user "user-123" of client "client-abc" and kind "be-kind" could not be found`), Code: "receipt_not_found"}))
+		})
+	})
+
+	Describe("DeleteBefore", func() {
+		BeforeEach(func() {
+			err := repo.CreateReceipts(conn, []string{"user-123", "user-456", "user-789"}, "client-abc", "be-kind")
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("deletes receipts created before the threshold, up to the limit", func() {
+			count, err := repo.DeleteBefore(conn, time.Now().Add(1*time.Hour), 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+
+			rowCount, err := conn.SelectInt("SELECT COUNT(*) FROM `receipts`")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(int(rowCount)).To(Equal(1))
+		})
+
+		It("does not delete receipts created after the threshold", func() {
+			count, err := repo.DeleteBefore(conn, time.Now().Add(-1*time.Hour), 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(0))
+		})
+	})
 })
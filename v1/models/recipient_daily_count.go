@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// RecipientDailyCount is how many non-critical notifications a user has been
+// sent on a day, counted in UTC.
+type RecipientDailyCount struct {
+	UserGUID string `db:"user_guid"`
+	Day      string `db:"day"`
+	Sent     int    `db:"sent"`
+}
+
+// RecipientDay names the day that at counts towards, such as "2026-10-18".
+func RecipientDay(at time.Time) string {
+	return at.UTC().Format("2006-01-02")
+}
+
+// RecipientDayEndsAt is when the day that at counts towards ends.
+func RecipientDayEndsAt(at time.Time) time.Time {
+	at = at.UTC()
+	return time.Date(at.Year(), at.Month(), at.Day()+1, 0, 0, 0, 0, time.UTC)
+}
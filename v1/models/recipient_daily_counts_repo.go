@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+type RecipientDailyCountsRepo struct{}
+
+func NewRecipientDailyCountsRepo() RecipientDailyCountsRepo {
+	return RecipientDailyCountsRepo{}
+}
+
+// Take counts a send to the user against the day that at falls in, unless
+// the user has already been sent limit messages that day. The check and the
+// increment are a single statement, so workers on other instances cannot
+// both take the last send.
+func (repo RecipientDailyCountsRepo) Take(conn ConnectionInterface, userGUID string, limit int, at time.Time) (bool, error) {
+	day := RecipientDay(at)
+
+	_, err := conn.Exec("INSERT INTO `recipient_daily_counts` (`user_guid`, `day`, `sent`) VALUES (?, ?, 0) ON DUPLICATE KEY UPDATE `sent` = `sent`", userGUID, day)
+	if err != nil {
+		return false, err
+	}
+
+	result, err := conn.Exec("UPDATE `recipient_daily_counts` SET `sent` = `sent` + 1 WHERE `user_guid` = ? AND `day` = ? AND `sent` < ?", userGUID, day, limit)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count == 1, nil
+}
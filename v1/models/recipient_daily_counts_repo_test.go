@@ -0,0 +1,73 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RecipientDailyCountsRepo", func() {
+	var (
+		repo models.RecipientDailyCountsRepo
+		conn *db.Connection
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		repo = models.NewRecipientDailyCountsRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+
+		conn = database.Connection().(*db.Connection)
+		now = time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	})
+
+	Describe("Take", func() {
+		It("allows sends up to the limit for the day", func() {
+			for i := 0; i < 2; i++ {
+				ok, err := repo.Take(conn, "some-user", 2, now)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(ok).To(BeTrue())
+			}
+
+			ok, err := repo.Take(conn, "some-user", 2, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+
+		It("counts each user separately", func() {
+			ok, err := repo.Take(conn, "some-user", 1, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ok, err = repo.Take(conn, "other-user", 1, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+
+		It("starts over on the next day", func() {
+			ok, err := repo.Take(conn, "some-user", 1, now)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+
+			ok, err = repo.Take(conn, "some-user", 1, now.Add(12*time.Hour))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		})
+	})
+})
+
+var _ = Describe("RecipientDayEndsAt", func() {
+	It("returns midnight UTC after the day", func() {
+		at := time.Date(2026, time.December, 31, 23, 0, 0, 0, time.FixedZone("PST", -8*60*60))
+
+		Expect(models.RecipientDay(at)).To(Equal("2027-01-01"))
+		Expect(models.RecipientDayEndsAt(at)).To(Equal(time.Date(2027, time.January, 2, 0, 0, 0, 0, time.UTC)))
+	})
+})
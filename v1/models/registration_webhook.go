@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// RegistrationWebhook is the URL that is told whenever the registered kinds
+// or templates of a client change.
+type RegistrationWebhook struct {
+	Primary   int       `db:"primary"`
+	ClientID  string    `db:"client_id"`
+	URL       string    `db:"url"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
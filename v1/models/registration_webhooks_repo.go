@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type RegistrationWebhooksRepo struct{}
+
+func NewRegistrationWebhooksRepo() RegistrationWebhooksRepo {
+	return RegistrationWebhooksRepo{}
+}
+
+func (repo RegistrationWebhooksRepo) Find(conn ConnectionInterface, clientID string) (RegistrationWebhook, error) {
+	webhook := RegistrationWebhook{}
+	err := conn.SelectOne(&webhook, "SELECT * FROM `registration_webhooks` WHERE `client_id` = ?", clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return webhook, NotFoundError{Err: fmt.Errorf("Client %q has no registration webhook", clientID), Code: "registration_webhook_not_found"}
+		}
+		return webhook, err
+	}
+
+	return webhook, nil
+}
+
+func (repo RegistrationWebhooksRepo) Upsert(conn ConnectionInterface, webhook RegistrationWebhook) (RegistrationWebhook, error) {
+	existing, err := repo.Find(conn, webhook.ClientID)
+	if err != nil {
+		if _, ok := err.(NotFoundError); !ok {
+			return webhook, err
+		}
+	}
+
+	now := time.Now().Truncate(1 * time.Second).UTC()
+	webhook.Primary = existing.Primary
+	webhook.CreatedAt = existing.CreatedAt
+	webhook.UpdatedAt = now
+
+	if webhook.Primary == 0 {
+		webhook.CreatedAt = now
+		err = conn.Insert(&webhook)
+	} else {
+		_, err = conn.Update(&webhook)
+	}
+	if err != nil {
+		return webhook, err
+	}
+
+	return webhook, nil
+}
+
+func (repo RegistrationWebhooksRepo) Delete(conn ConnectionInterface, clientID string) error {
+	result, err := conn.Exec("DELETE FROM `registration_webhooks` WHERE `client_id` = ?", clientID)
+	if err != nil {
+		return err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return NotFoundError{Err: fmt.Errorf("Client %q has no registration webhook", clientID), Code: "registration_webhook_not_found"}
+	}
+
+	return nil
+}
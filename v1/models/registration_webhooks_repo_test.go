@@ -0,0 +1,52 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RegistrationWebhooksRepo", func() {
+	var (
+		repo models.RegistrationWebhooksRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewRegistrationWebhooksRepo()
+	})
+
+	It("stores one webhook per client until it is deleted", func() {
+		_, err := repo.Find(conn, "some-client")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+
+		first, err := repo.Upsert(conn, models.RegistrationWebhook{ClientID: "some-client", URL: "https://example.com/first"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(first.CreatedAt).NotTo(BeZero())
+
+		second, err := repo.Upsert(conn, models.RegistrationWebhook{ClientID: "some-client", URL: "https://example.com/second"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(second.Primary).To(Equal(first.Primary))
+		Expect(second.CreatedAt).To(Equal(first.CreatedAt))
+
+		found, err := repo.Find(conn, "some-client")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.URL).To(Equal("https://example.com/second"))
+
+		Expect(repo.Delete(conn, "some-client")).To(Succeed())
+
+		_, err = repo.Find(conn, "some-client")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+
+	It("returns a not found error when deleting a webhook that does not exist", func() {
+		err := repo.Delete(conn, "some-client")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+})
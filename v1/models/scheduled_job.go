@@ -0,0 +1,24 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// ScheduledJob is the schedule of a periodic maintenance job and how its
+// last run went. Only the instance holding the scheduler lease writes it.
+type ScheduledJob struct {
+	Name            string       `db:"name"`
+	Enabled         bool         `db:"enabled"`
+	IntervalSeconds int          `db:"interval_seconds"`
+	LastInstance    string       `db:"last_instance"`
+	LastStartedAt   sql.NullTime `db:"last_started_at"`
+	LastFinishedAt  sql.NullTime `db:"last_finished_at"`
+	LastError       string       `db:"last_error"`
+}
+
+type SchedulerLease struct {
+	Name      string    `db:"name"`
+	Holder    string    `db:"holder"`
+	ExpiresAt time.Time `db:"expires_at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+type ScheduledJobsRepo struct{}
+
+func NewScheduledJobsRepo() ScheduledJobsRepo {
+	return ScheduledJobsRepo{}
+}
+
+// Upsert saves the schedule of the job, keeping the record of its last run.
+func (repo ScheduledJobsRepo) Upsert(conn ConnectionInterface, job ScheduledJob) error {
+	query := "INSERT INTO `scheduled_jobs` (`name`, `enabled`, `interval_seconds`, `last_error`) VALUES (?, ?, ?, '') ON DUPLICATE KEY UPDATE `enabled`=VALUES(`enabled`), `interval_seconds`=VALUES(`interval_seconds`)"
+	_, err := conn.Exec(query, job.Name, job.Enabled, job.IntervalSeconds)
+	return err
+}
+
+func (repo ScheduledJobsRepo) Start(conn ConnectionInterface, name, instance string, startedAt time.Time) error {
+	query := "UPDATE `scheduled_jobs` SET `last_instance` = ?, `last_started_at` = ?, `last_finished_at` = NULL, `last_error` = '' WHERE `name` = ?"
+	_, err := conn.Exec(query, instance, startedAt.UTC(), name)
+	return err
+}
+
+func (repo ScheduledJobsRepo) Finish(conn ConnectionInterface, name string, finishedAt time.Time, lastError string) error {
+	query := "UPDATE `scheduled_jobs` SET `last_finished_at` = ?, `last_error` = ? WHERE `name` = ?"
+	_, err := conn.Exec(query, finishedAt.UTC(), lastError, name)
+	return err
+}
+
+func (repo ScheduledJobsRepo) FindAll(conn ConnectionInterface) ([]ScheduledJob, error) {
+	jobs := []ScheduledJob{}
+	_, err := conn.Select(&jobs, "SELECT * FROM `scheduled_jobs` ORDER BY `name`")
+	if err != nil {
+		return jobs, err
+	}
+
+	return jobs, nil
+}
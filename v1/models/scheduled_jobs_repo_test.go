@@ -0,0 +1,126 @@
+package models_test
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ScheduledJobsRepo", func() {
+	var (
+		repo models.ScheduledJobsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		repo = models.NewScheduledJobsRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+
+		err := repo.Upsert(conn, models.ScheduledJob{
+			Name:            "retention_janitor",
+			Enabled:         true,
+			IntervalSeconds: 3600,
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("records the start and finish of a run", func() {
+		startedAt := time.Now().Add(-1 * time.Minute).Truncate(time.Second).UTC()
+		finishedAt := startedAt.Add(30 * time.Second)
+
+		err := repo.Start(conn, "retention_janitor", "instance-1", startedAt)
+		Expect(err).NotTo(HaveOccurred())
+
+		jobs, err := repo.FindAll(conn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].LastInstance).To(Equal("instance-1"))
+		Expect(jobs[0].LastStartedAt.Time).To(Equal(startedAt))
+		Expect(jobs[0].LastFinishedAt.Valid).To(BeFalse())
+
+		err = repo.Finish(conn, "retention_janitor", finishedAt, "disk is full")
+		Expect(err).NotTo(HaveOccurred())
+
+		jobs, err = repo.FindAll(conn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs[0].LastFinishedAt.Time).To(Equal(finishedAt))
+		Expect(jobs[0].LastError).To(Equal("disk is full"))
+	})
+
+	It("keeps the last run when the schedule changes", func() {
+		startedAt := time.Now().Truncate(time.Second).UTC()
+		err := repo.Start(conn, "retention_janitor", "instance-1", startedAt)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = repo.Upsert(conn, models.ScheduledJob{
+			Name:            "retention_janitor",
+			Enabled:         false,
+			IntervalSeconds: 60,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		jobs, err := repo.FindAll(conn)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(jobs).To(HaveLen(1))
+		Expect(jobs[0].Enabled).To(BeFalse())
+		Expect(jobs[0].IntervalSeconds).To(Equal(60))
+		Expect(jobs[0].LastStartedAt.Time).To(Equal(startedAt))
+	})
+})
+
+var _ = Describe("SchedulerLeasesRepo", func() {
+	var (
+		repo models.SchedulerLeasesRepo
+		conn *db.Connection
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		repo = models.NewSchedulerLeasesRepo()
+
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+
+		now = time.Now().Truncate(time.Second).UTC()
+	})
+
+	It("gives the lease to the first holder and renews it", func() {
+		acquired, err := repo.Acquire(conn, "scheduler", "instance-1", now, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+
+		acquired, err = repo.Acquire(conn, "scheduler", "instance-1", now.Add(30*time.Second), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+
+		lease, err := repo.Find(conn, "scheduler")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lease.ExpiresAt).To(Equal(now.Add(90 * time.Second)))
+	})
+
+	It("keeps the lease from other holders until it expires", func() {
+		_, err := repo.Acquire(conn, "scheduler", "instance-1", now, time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+
+		acquired, err := repo.Acquire(conn, "scheduler", "instance-2", now.Add(30*time.Second), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeFalse())
+
+		acquired, err = repo.Acquire(conn, "scheduler", "instance-2", now.Add(2*time.Minute), time.Minute)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(acquired).To(BeTrue())
+
+		lease, err := repo.Find(conn, "scheduler")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lease.Holder).To(Equal("instance-2"))
+	})
+})
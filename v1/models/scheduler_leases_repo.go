@@ -0,0 +1,50 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type SchedulerLeasesRepo struct{}
+
+func NewSchedulerLeasesRepo() SchedulerLeasesRepo {
+	return SchedulerLeasesRepo{}
+}
+
+// Acquire takes or renews the named lease for the holder until now plus
+// the ttl. It reports false while another holder's lease has not expired.
+func (repo SchedulerLeasesRepo) Acquire(conn ConnectionInterface, name, holder string, now time.Time, ttl time.Duration) (bool, error) {
+	now = now.UTC()
+	expiresAt := now.Add(ttl)
+
+	_, err := conn.Exec("INSERT IGNORE INTO `scheduler_leases` (`name`, `holder`, `expires_at`) VALUES (?, ?, ?)", name, holder, expiresAt)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = conn.Exec("UPDATE `scheduler_leases` SET `holder` = ?, `expires_at` = ? WHERE `name` = ? AND (`holder` = ? OR `expires_at` < ?)", holder, expiresAt, name, holder, now)
+	if err != nil {
+		return false, err
+	}
+
+	lease, err := repo.Find(conn, name)
+	if err != nil {
+		return false, err
+	}
+
+	return lease.Holder == holder, nil
+}
+
+func (repo SchedulerLeasesRepo) Find(conn ConnectionInterface, name string) (SchedulerLease, error) {
+	lease := SchedulerLease{}
+	err := conn.SelectOne(&lease, "SELECT * FROM `scheduler_leases` WHERE `name` = ?", name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			err = NotFoundError{Err: fmt.Errorf("Lease %q could not be found", name)}
+		}
+		return lease, err
+	}
+
+	return lease, nil
+}
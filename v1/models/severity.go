@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Severities tell recipients how urgent a notification is. Kinds have one,
+// which a notify request can override.
+const (
+	SeverityInfo                   = "info"
+	SeverityWarning                = "warning"
+	SeverityCriticalActionRequired = "critical-action-required"
+)
+
+var Severities = []string{SeverityInfo, SeverityWarning, SeverityCriticalActionRequired}
+
+// SeverityBadges prefix the subject of messages that are more than
+// informational.
+var SeverityBadges = map[string]string{
+	SeverityWarning:                "[Warning]",
+	SeverityCriticalActionRequired: "[Action Required]",
+}
+
+func ValidSeverity(severity string) bool {
+	for _, valid := range Severities {
+		if severity == valid {
+			return true
+		}
+	}
+
+	return false
+}
+
+// UnsubscribableSeverity reports whether users may stop receiving the
+// notifications of a severity. Those that require action always reach them.
+func UnsubscribableSeverity(severity string) bool {
+	return ValidSeverity(severity) && severity != SeverityCriticalActionRequired
+}
+
+// SeverityUnsubscribe records that a user does not want the notifications of
+// a severity.
+type SeverityUnsubscribe struct {
+	Primary   int       `db:"primary"`
+	UserID    string    `db:"user_id"`
+	Severity  string    `db:"severity"`
+	CreatedAt time.Time `db:"created_at"`
+}
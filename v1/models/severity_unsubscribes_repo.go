@@ -0,0 +1,70 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+type SeverityUnsubscribesRepo struct{}
+
+func NewSeverityUnsubscribesRepo() SeverityUnsubscribesRepo {
+	return SeverityUnsubscribesRepo{}
+}
+
+func (repo SeverityUnsubscribesRepo) Set(conn ConnectionInterface, userID, severity string, unsubscribe bool) error {
+	severityUnsubscribe, err := repo.find(conn, userID, severity)
+	if err != nil {
+		if err != sql.ErrNoRows {
+			return err
+		}
+
+		severityUnsubscribe = SeverityUnsubscribe{
+			UserID:    userID,
+			Severity:  severity,
+			CreatedAt: time.Now().Truncate(1 * time.Second).UTC(),
+		}
+	}
+
+	switch {
+	case unsubscribe && severityUnsubscribe.Primary == 0:
+		return conn.Insert(&severityUnsubscribe)
+	case !unsubscribe && severityUnsubscribe.Primary != 0:
+		_, err = conn.Delete(&severityUnsubscribe)
+		return err
+	}
+
+	return nil
+}
+
+func (repo SeverityUnsubscribesRepo) Get(conn ConnectionInterface, userID, severity string) (bool, error) {
+	_, err := repo.find(conn, userID, severity)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// FindAll returns the severities the user unsubscribed from.
+func (repo SeverityUnsubscribesRepo) FindAll(conn ConnectionInterface, userID string) ([]string, error) {
+	var severities []string
+	_, err := conn.Select(&severities, "SELECT `severity` FROM `severity_unsubscribes` WHERE `user_id` = ? ORDER BY `severity`", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return severities, nil
+}
+
+func (repo SeverityUnsubscribesRepo) find(conn ConnectionInterface, userID, severity string) (SeverityUnsubscribe, error) {
+	severityUnsubscribe := SeverityUnsubscribe{}
+	err := conn.SelectOne(&severityUnsubscribe, "SELECT * FROM `severity_unsubscribes` WHERE `user_id` = ? AND `severity` = ?", userID, severity)
+	if err != nil {
+		return SeverityUnsubscribe{}, err
+	}
+
+	return severityUnsubscribe, nil
+}
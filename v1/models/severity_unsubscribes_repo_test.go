@@ -0,0 +1,55 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SeverityUnsubscribesRepo", func() {
+	var repo models.SeverityUnsubscribesRepo
+	var conn *db.Connection
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewSeverityUnsubscribesRepo()
+	})
+
+	It("unsubscribes a user from a severity, and subscribes them again", func() {
+		err := repo.Set(conn, "my-user", models.SeverityInfo, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = repo.Set(conn, "my-user", models.SeverityInfo, true)
+		Expect(err).NotTo(HaveOccurred())
+
+		unsubscribed, err := repo.Get(conn, "my-user", models.SeverityInfo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeTrue())
+
+		unsubscribed, err = repo.Get(conn, "my-user", models.SeverityWarning)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeFalse())
+
+		err = repo.Set(conn, "my-user", models.SeverityInfo, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		unsubscribed, err = repo.Get(conn, "my-user", models.SeverityInfo)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(BeFalse())
+	})
+
+	It("finds the severities a user unsubscribed from", func() {
+		Expect(repo.Set(conn, "my-user", models.SeverityWarning, true)).To(Succeed())
+		Expect(repo.Set(conn, "my-user", models.SeverityInfo, true)).To(Succeed())
+		Expect(repo.Set(conn, "other-user", models.SeverityInfo, true)).To(Succeed())
+
+		severities, err := repo.FindAll(conn, "my-user")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(severities).To(Equal([]string{models.SeverityInfo, models.SeverityWarning}))
+	})
+})
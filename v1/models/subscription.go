@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+// Subscription records that a user asked to receive an opt-in kind.
+type Subscription struct {
+	Primary   int       `db:"primary"`
+	UserID    string    `db:"user_id"`
+	ClientID  string    `db:"client_id"`
+	KindID    string    `db:"kind_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (s *Subscription) PreInsert(sqlExecutor gorp.SqlExecutor) error {
+	s.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	return nil
+}
+
+type Subscriptions []Subscription
+
+func (subscriptions Subscriptions) Contains(clientID, kindID string) bool {
+	for _, subscription := range subscriptions {
+		if subscription.ClientID == clientID && subscription.KindID == kindID {
+			return true
+		}
+	}
+	return false
+}
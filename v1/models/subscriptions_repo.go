@@ -0,0 +1,53 @@
+package models
+
+import (
+	"database/sql"
+	"strings"
+)
+
+type SubscriptionsRepo struct{}
+
+func NewSubscriptionsRepo() SubscriptionsRepo {
+	return SubscriptionsRepo{}
+}
+
+func (repo SubscriptionsRepo) Get(conn ConnectionInterface, userID, clientID, kindID string) (bool, error) {
+	err := conn.SelectOne(&Subscription{}, "SELECT * FROM `subscriptions` WHERE `client_id` = ? AND `kind_id` = ? AND `user_id` = ?", clientID, kindID, userID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo SubscriptionsRepo) Set(conn ConnectionInterface, userID, clientID, kindID string, subscribe bool) error {
+	if !subscribe {
+		_, err := conn.Exec("DELETE FROM `subscriptions` WHERE `client_id` = ? AND `kind_id` = ? AND `user_id` = ?", clientID, kindID, userID)
+		return err
+	}
+
+	err := conn.Insert(&Subscription{
+		UserID:   userID,
+		ClientID: clientID,
+		KindID:   kindID,
+	})
+	if err != nil && !strings.Contains(err.Error(), "Duplicate entry") {
+		return err
+	}
+
+	return nil
+}
+
+func (repo SubscriptionsRepo) FindAllByUserID(conn ConnectionInterface, userID string) ([]Subscription, error) {
+	subscriptions := []Subscription{}
+	_, err := conn.Select(&subscriptions, "SELECT * FROM `subscriptions` WHERE `user_id` = ?", userID)
+	if err != nil {
+		return []Subscription{}, err
+	}
+
+	return subscriptions, nil
+}
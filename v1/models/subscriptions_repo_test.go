@@ -0,0 +1,63 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SubscriptionsRepo", func() {
+	var (
+		repo models.SubscriptionsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewSubscriptionsRepo()
+	})
+
+	Describe("Set and Get", func() {
+		It("subscribes and unsubscribes a user", func() {
+			err := repo.Set(conn, "user-123", "raptors", "newsletter", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			err = repo.Set(conn, "user-123", "raptors", "newsletter", true)
+			Expect(err).NotTo(HaveOccurred())
+
+			subscribed, err := repo.Get(conn, "user-123", "raptors", "newsletter")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subscribed).To(BeTrue())
+
+			subscribed, err = repo.Get(conn, "user-456", "raptors", "newsletter")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subscribed).To(BeFalse())
+
+			err = repo.Set(conn, "user-123", "raptors", "newsletter", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			subscribed, err = repo.Get(conn, "user-123", "raptors", "newsletter")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subscribed).To(BeFalse())
+		})
+	})
+
+	Describe("FindAllByUserID", func() {
+		It("finds only the subscriptions for the user", func() {
+			Expect(repo.Set(conn, "user-123", "raptors", "newsletter", true)).To(Succeed())
+			Expect(repo.Set(conn, "user-123", "dinosaurs", "digest", true)).To(Succeed())
+			Expect(repo.Set(conn, "user-456", "raptors", "newsletter", true)).To(Succeed())
+
+			subscriptions, err := repo.FindAllByUserID(conn, "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(subscriptions).To(HaveLen(2))
+			Expect(models.Subscriptions(subscriptions).Contains("raptors", "newsletter")).To(BeTrue())
+			Expect(models.Subscriptions(subscriptions).Contains("dinosaurs", "digest")).To(BeTrue())
+		})
+	})
+})
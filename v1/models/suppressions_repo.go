@@ -0,0 +1,49 @@
+package models
+
+import "strings"
+
+// suppressionsBatchSize bounds the user IDs looked up in each query, so that
+// large organizations do not build statements the database refuses.
+const suppressionsBatchSize = 1000
+
+// SuppressionsRepo finds in bulk the users that the workers would discard a
+// notification for, so that it need not be queued for them at all.
+type SuppressionsRepo struct{}
+
+func NewSuppressionsRepo() SuppressionsRepo {
+	return SuppressionsRepo{}
+}
+
+// FindUnsubscribed returns those of the users who unsubscribed from every
+// notification, or from the kind of the client.
+func (repo SuppressionsRepo) FindUnsubscribed(conn ConnectionInterface, userIDs []string, clientID, kindID string) ([]string, error) {
+	unsubscribed := []string{}
+	for start := 0; start < len(userIDs); start += suppressionsBatchSize {
+		end := start + suppressionsBatchSize
+		if end > len(userIDs) {
+			end = len(userIDs)
+		}
+		batch := userIDs[start:end]
+
+		in := "(" + strings.TrimSuffix(strings.Repeat("?, ", len(batch)), ", ") + ")"
+		args := []interface{}{}
+		for _, userID := range batch {
+			args = append(args, userID)
+		}
+		args = append(args, clientID, kindID)
+		for _, userID := range batch {
+			args = append(args, userID)
+		}
+
+		var found []string
+		_, err := conn.Select(&found, "SELECT `user_id` FROM `global_unsubscribes` WHERE `user_id` IN "+in+
+			" UNION SELECT `user_id` FROM `unsubscribes` WHERE `client_id` = ? AND `kind_id` = ? AND `user_id` IN "+in, args...)
+		if err != nil {
+			return nil, err
+		}
+
+		unsubscribed = append(unsubscribed, found...)
+	}
+
+	return unsubscribed, nil
+}
@@ -0,0 +1,48 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SuppressionsRepo", func() {
+	var (
+		repo models.SuppressionsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewSuppressionsRepo()
+
+		Expect(models.NewGlobalUnsubscribesRepo().Set(conn, "user-global", true)).To(Succeed())
+
+		unsubscribes := models.NewUnsubscribesRepo()
+		Expect(unsubscribes.Set(conn, "user-kind", "raptors", "feeding-time", true)).To(Succeed())
+		Expect(unsubscribes.Set(conn, "user-other-kind", "raptors", "door-opening", true)).To(Succeed())
+	})
+
+	It("finds the users unsubscribed from everything or from the kind", func() {
+		unsubscribed, err := repo.FindUnsubscribed(conn, []string{"user-global", "user-kind", "user-other-kind", "user-subscribed"}, "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(ConsistOf("user-global", "user-kind"))
+	})
+
+	It("looks up more users than fit in a single query", func() {
+		userIDs := []string{"user-kind"}
+		for i := 0; i < 2500; i++ {
+			userIDs = append(userIDs, "someone-else")
+		}
+		userIDs = append(userIDs, "user-global")
+
+		unsubscribed, err := repo.FindUnsubscribed(conn, userIDs, "raptors", "feeding-time")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unsubscribed).To(ConsistOf("user-kind", "user-global"))
+	})
+})
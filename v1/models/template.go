@@ -20,10 +20,16 @@ type Template struct {
 	Subject    string    `db:"subject"`
 	Text       string    `db:"text"`
 	HTML       string    `db:"html"`
+	Slack      string    `db:"slack"`
 	Metadata   string    `db:"metadata"`
 	CreatedAt  time.Time `db:"created_at"`
 	UpdatedAt  time.Time `db:"updated_at"`
 	Overridden bool      `db:"overridden"`
+	Version    int       `db:"version"`
+
+	// ZoneID is the UAA zone the template was created in, which is the only
+	// one that may see it, besides the default zone.
+	ZoneID string `db:"zone_id"`
 }
 
 func (t *Template) PreInsert(s gorp.SqlExecutor) error {
@@ -40,5 +46,9 @@ func (t *Template) PreInsert(s gorp.SqlExecutor) error {
 	}
 	t.UpdatedAt = t.CreatedAt
 
+	if t.ZoneID == "" {
+		t.ZoneID = DefaultZoneID
+	}
+
 	return nil
 }
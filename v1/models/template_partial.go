@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+// TemplatePartial is a named fragment, such as a header or a footer, that
+// templates include with {{template "name" .}}. Version counts the saves.
+type TemplatePartial struct {
+	Primary   int       `db:"primary"`
+	Name      string    `db:"name"`
+	Text      string    `db:"text"`
+	HTML      string    `db:"html"`
+	Version   int       `db:"version"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (p *TemplatePartial) PreInsert(s gorp.SqlExecutor) error {
+	if (p.CreatedAt == time.Time{}) {
+		p.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	}
+	p.UpdatedAt = p.CreatedAt
+
+	return nil
+}
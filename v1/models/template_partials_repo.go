@@ -0,0 +1,78 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type TemplatePartialsRepo struct{}
+
+func NewTemplatePartialsRepo() TemplatePartialsRepo {
+	return TemplatePartialsRepo{}
+}
+
+func (repo TemplatePartialsRepo) Find(conn ConnectionInterface, name string) (TemplatePartial, error) {
+	partial := TemplatePartial{}
+	err := conn.SelectOne(&partial, "SELECT * FROM `template_partials` WHERE `name` = ?", name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return partial, NotFoundError{Err: fmt.Errorf("Partial %q could not be found", name), Code: "template_partial_not_found"}
+		}
+		return partial, err
+	}
+
+	return partial, nil
+}
+
+func (repo TemplatePartialsRepo) FindAll(conn ConnectionInterface) ([]TemplatePartial, error) {
+	partials := []TemplatePartial{}
+	_, err := conn.Select(&partials, "SELECT * FROM `template_partials` ORDER BY `name`")
+	if err != nil {
+		return []TemplatePartial{}, err
+	}
+
+	return partials, nil
+}
+
+// Upsert saves the partial under its name, bumping the version when it
+// replaces an existing one.
+func (repo TemplatePartialsRepo) Upsert(conn ConnectionInterface, partial TemplatePartial) (TemplatePartial, error) {
+	existing, err := repo.Find(conn, partial.Name)
+	if err != nil {
+		if _, ok := err.(NotFoundError); !ok {
+			return partial, err
+		}
+
+		partial.Version = 1
+		err = conn.Insert(&partial)
+		if err != nil {
+			return TemplatePartial{}, err
+		}
+
+		return partial, nil
+	}
+
+	partial.Primary = existing.Primary
+	partial.Version = existing.Version + 1
+	partial.CreatedAt = existing.CreatedAt
+	partial.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	_, err = conn.Update(&partial)
+	if err != nil {
+		return TemplatePartial{}, err
+	}
+
+	return partial, nil
+}
+
+func (repo TemplatePartialsRepo) Destroy(conn ConnectionInterface, name string) error {
+	partial, err := repo.Find(conn, name)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Delete(&partial)
+
+	return err
+}
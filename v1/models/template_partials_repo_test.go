@@ -0,0 +1,90 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplatePartialsRepo", func() {
+	var (
+		repo models.TemplatePartialsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewTemplatePartialsRepo()
+	})
+
+	Describe("Upsert and Find", func() {
+		It("creates a partial and bumps its version on every save", func() {
+			partial, err := repo.Upsert(conn, models.TemplatePartial{
+				Name: "footer",
+				Text: "Sent by {{.SourceDescription}}",
+				HTML: "<footer>{{.SourceDescription}}</footer>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(partial.Version).To(Equal(1))
+
+			updated, err := repo.Upsert(conn, models.TemplatePartial{
+				Name: "footer",
+				HTML: "<footer>Sent by {{.SourceDescription}}</footer>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Version).To(Equal(2))
+
+			found, err := repo.Find(conn, "footer")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found.Primary).To(Equal(partial.Primary))
+			Expect(found.HTML).To(Equal("<footer>Sent by {{.SourceDescription}}</footer>"))
+			Expect(found.Text).To(BeEmpty())
+			Expect(found.Version).To(Equal(2))
+		})
+
+		It("returns a NotFoundError when the partial does not exist", func() {
+			_, err := repo.Find(conn, "footer")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("FindAll", func() {
+		It("lists the partials by name", func() {
+			for _, name := range []string{"header", "button", "footer"} {
+				_, err := repo.Upsert(conn, models.TemplatePartial{Name: name, HTML: "<p>" + name + "</p>"})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			partials, err := repo.FindAll(conn)
+			Expect(err).NotTo(HaveOccurred())
+
+			var names []string
+			for _, partial := range partials {
+				names = append(names, partial.Name)
+			}
+			Expect(names).To(Equal([]string{"button", "footer", "header"}))
+		})
+	})
+
+	Describe("Destroy", func() {
+		It("deletes the partial", func() {
+			_, err := repo.Upsert(conn, models.TemplatePartial{Name: "footer", HTML: "<footer></footer>"})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(repo.Destroy(conn, "footer")).To(Succeed())
+
+			_, err = repo.Find(conn, "footer")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("returns a NotFoundError when the partial does not exist", func() {
+			err := repo.Destroy(conn, "footer")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+})
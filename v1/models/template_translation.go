@@ -0,0 +1,56 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+// TemplateTranslation overrides the subject, text and html of a template for
+// recipients in a particular locale.
+type TemplateTranslation struct {
+	Primary    int       `db:"primary"`
+	TemplateID string    `db:"template_id"`
+	Locale     string    `db:"locale"`
+	Subject    string    `db:"subject"`
+	Text       string    `db:"text"`
+	HTML       string    `db:"html"`
+	CreatedAt  time.Time `db:"created_at"`
+	UpdatedAt  time.Time `db:"updated_at"`
+}
+
+func (t *TemplateTranslation) PreInsert(s gorp.SqlExecutor) error {
+	if (t.CreatedAt == time.Time{}) {
+		t.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	}
+	t.UpdatedAt = t.CreatedAt
+
+	return nil
+}
+
+// NormalizeLocale lowercases a locale tag and uses "-" as the separator, so
+// that "pt_BR" and "pt-br" name the same translation.
+func NormalizeLocale(locale string) string {
+	return strings.ToLower(strings.Replace(strings.TrimSpace(locale), "_", "-", -1))
+}
+
+// LocaleFallbacks lists the locales to try for a recipient, most specific
+// first: "zh-hant-tw" yields "zh-hant-tw", "zh-hant" and "zh".
+func LocaleFallbacks(locale string) []string {
+	locale = NormalizeLocale(locale)
+	if locale == "" {
+		return nil
+	}
+
+	var fallbacks []string
+	for {
+		fallbacks = append(fallbacks, locale)
+
+		index := strings.LastIndex(locale, "-")
+		if index <= 0 {
+			return fallbacks
+		}
+		locale = locale[:index]
+	}
+}
@@ -0,0 +1,21 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateTranslation", func() {
+	Describe("LocaleFallbacks", func() {
+		It("lists the locale and its parents, most specific first", func() {
+			Expect(models.LocaleFallbacks("zh_Hant_TW")).To(Equal([]string{"zh-hant-tw", "zh-hant", "zh"}))
+			Expect(models.LocaleFallbacks("fr")).To(Equal([]string{"fr"}))
+		})
+
+		It("returns nothing for an empty locale", func() {
+			Expect(models.LocaleFallbacks(" ")).To(BeEmpty())
+		})
+	})
+})
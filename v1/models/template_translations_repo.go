@@ -0,0 +1,83 @@
+package models
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type TemplateTranslationsRepo struct{}
+
+func NewTemplateTranslationsRepo() TemplateTranslationsRepo {
+	return TemplateTranslationsRepo{}
+}
+
+func (repo TemplateTranslationsRepo) Find(conn ConnectionInterface, templateID, locale string) (TemplateTranslation, error) {
+	translation := TemplateTranslation{}
+	err := conn.SelectOne(&translation, "SELECT * FROM `template_translations` WHERE `template_id` = ? AND `locale` = ?", templateID, NormalizeLocale(locale))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return translation, NotFoundError{Err: fmt.Errorf("Translation %q of template with ID %q could not be found", locale, templateID), Code: "template_translation_not_found"}
+		}
+		return translation, err
+	}
+
+	return translation, nil
+}
+
+// FindAllByTemplateID lists the translations of the template by locale.
+func (repo TemplateTranslationsRepo) FindAllByTemplateID(conn ConnectionInterface, templateID string) ([]TemplateTranslation, error) {
+	translations := []TemplateTranslation{}
+	_, err := conn.Select(&translations, "SELECT * FROM `template_translations` WHERE `template_id` = ? ORDER BY `locale`", templateID)
+	if err != nil {
+		return []TemplateTranslation{}, err
+	}
+
+	return translations, nil
+}
+
+func (repo TemplateTranslationsRepo) Upsert(conn ConnectionInterface, translation TemplateTranslation) (TemplateTranslation, error) {
+	translation.Locale = NormalizeLocale(translation.Locale)
+
+	existing, err := repo.Find(conn, translation.TemplateID, translation.Locale)
+	if err != nil {
+		if _, ok := err.(NotFoundError); !ok {
+			return translation, err
+		}
+
+		err = conn.Insert(&translation)
+		if err != nil {
+			return TemplateTranslation{}, err
+		}
+
+		return translation, nil
+	}
+
+	translation.Primary = existing.Primary
+	translation.CreatedAt = existing.CreatedAt
+	translation.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+
+	_, err = conn.Update(&translation)
+	if err != nil {
+		return TemplateTranslation{}, err
+	}
+
+	return translation, nil
+}
+
+func (repo TemplateTranslationsRepo) Destroy(conn ConnectionInterface, templateID, locale string) error {
+	translation, err := repo.Find(conn, templateID, locale)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Delete(&translation)
+
+	return err
+}
+
+func (repo TemplateTranslationsRepo) DestroyAllByTemplateID(conn ConnectionInterface, templateID string) error {
+	_, err := conn.Exec("DELETE FROM `template_translations` WHERE `template_id` = ?", templateID)
+
+	return err
+}
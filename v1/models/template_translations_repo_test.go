@@ -0,0 +1,80 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateTranslationsRepo", func() {
+	var (
+		repo models.TemplateTranslationsRepo
+		conn *db.Connection
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewTemplateTranslationsRepo()
+	})
+
+	Describe("Upsert and Find", func() {
+		It("creates and then updates a translation", func() {
+			translation, err := repo.Upsert(conn, models.TemplateTranslation{
+				TemplateID: "template-id",
+				Locale:     "pt_BR",
+				Subject:    "Olá {{.Subject}}",
+				HTML:       "<p>Olá</p>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(translation.Locale).To(Equal("pt-br"))
+
+			found, err := repo.Find(conn, "template-id", "pt-BR")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found.Subject).To(Equal("Olá {{.Subject}}"))
+			Expect(found.HTML).To(Equal("<p>Olá</p>"))
+
+			_, err = repo.Upsert(conn, models.TemplateTranslation{
+				TemplateID: "template-id",
+				Locale:     "pt-br",
+				HTML:       "<p>Oi</p>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			found, err = repo.Find(conn, "template-id", "pt-br")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found.HTML).To(Equal("<p>Oi</p>"))
+			Expect(found.Primary).To(Equal(translation.Primary))
+		})
+
+		It("returns a NotFoundError when the translation does not exist", func() {
+			_, err := repo.Find(conn, "template-id", "fr")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("Destroy", func() {
+		It("deletes the translation", func() {
+			_, err := repo.Upsert(conn, models.TemplateTranslation{
+				TemplateID: "template-id",
+				Locale:     "fr",
+				HTML:       "<p>Bonjour</p>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(repo.Destroy(conn, "template-id", "fr")).To(Succeed())
+
+			_, err = repo.Find(conn, "template-id", "fr")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+
+		It("returns a NotFoundError when the translation does not exist", func() {
+			err := repo.Destroy(conn, "template-id", "fr")
+			Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+})
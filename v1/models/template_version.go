@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"gopkg.in/gorp.v1"
+)
+
+// TemplateVersion is the content a template had after one of its saves.
+// Versions are never changed once they are recorded; rolling a template
+// back makes an earlier version active again instead of recording a new one.
+type TemplateVersion struct {
+	Primary    int       `db:"primary"`
+	TemplateID string    `db:"template_id"`
+	Version    int       `db:"version"`
+	Name       string    `db:"name"`
+	Subject    string    `db:"subject"`
+	Text       string    `db:"text"`
+	HTML       string    `db:"html"`
+	Slack      string    `db:"slack"`
+	Metadata   string    `db:"metadata"`
+	CreatedAt  time.Time `db:"created_at"`
+}
+
+func newTemplateVersion(template Template) TemplateVersion {
+	return TemplateVersion{
+		TemplateID: template.ID,
+		Name:       template.Name,
+		Subject:    template.Subject,
+		Text:       template.Text,
+		HTML:       template.HTML,
+		Slack:      template.Slack,
+		Metadata:   template.Metadata,
+	}
+}
+
+func (v TemplateVersion) sameContent(other TemplateVersion) bool {
+	return v.Name == other.Name &&
+		v.Subject == other.Subject &&
+		v.Text == other.Text &&
+		v.HTML == other.HTML &&
+		v.Slack == other.Slack &&
+		v.Metadata == other.Metadata
+}
+
+func (v *TemplateVersion) PreInsert(s gorp.SqlExecutor) error {
+	if (v.CreatedAt == time.Time{}) {
+		v.CreatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	}
+
+	return nil
+}
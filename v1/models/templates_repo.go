@@ -17,7 +17,7 @@ func (repo TemplatesRepo) FindByID(conn ConnectionInterface, templateID string)
 	err := conn.SelectOne(&template, "SELECT * FROM `templates` WHERE `id`=?", templateID)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return template, NotFoundError{fmt.Errorf("Template with ID %q could not be found", templateID)}
+			return template, NotFoundError{Err: fmt.Errorf("Template with ID %q could not be found", templateID), Code: "template_not_found"}
 		}
 		return template, err
 	}
@@ -33,9 +33,48 @@ func (repo TemplatesRepo) Update(conn ConnectionInterface, templateID string, te
 	template.Primary = existingTemplate.Primary
 	template.ID = existingTemplate.ID
 	template.CreatedAt = existingTemplate.CreatedAt
+	template.ZoneID = existingTemplate.ZoneID
 	template.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
 	template.Overridden = true
 
+	template.Version, err = repo.recordVersion(conn, template)
+	if err != nil {
+		return Template{}, err
+	}
+
+	_, err = conn.Update(&template)
+	if err != nil {
+		return Template{}, TemplateUpdateError{err}
+	}
+
+	return template, nil
+}
+
+// Provision saves a template that ships with the platform, such as one from
+// a template pack, under its own ID. Unlike Update, it does not mark the
+// template as overridden, so that a later release may replace it again.
+func (repo TemplatesRepo) Provision(conn ConnectionInterface, template Template) (Template, error) {
+	existingTemplate, err := repo.FindByID(conn, template.ID)
+	if err != nil {
+		if _, ok := err.(NotFoundError); !ok {
+			return Template{}, err
+		}
+
+		template.Overridden = false
+		return repo.Create(conn, template)
+	}
+
+	template.Primary = existingTemplate.Primary
+	template.CreatedAt = existingTemplate.CreatedAt
+	template.ZoneID = existingTemplate.ZoneID
+	template.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	template.Overridden = false
+
+	template.Version, err = repo.recordVersion(conn, template)
+	if err != nil {
+		return Template{}, err
+	}
+
 	_, err = conn.Update(&template)
 	if err != nil {
 		return Template{}, TemplateUpdateError{err}
@@ -46,7 +85,7 @@ func (repo TemplatesRepo) Update(conn ConnectionInterface, templateID string, te
 
 func (repo TemplatesRepo) ListIDsAndNames(conn ConnectionInterface) ([]Template, error) {
 	templates := []Template{}
-	_, err := conn.Select(&templates, "SELECT ID, Name FROM `templates`")
+	_, err := conn.Select(&templates, "SELECT ID, Name, zone_id FROM `templates`")
 	if err != nil {
 		return []Template{}, err
 	}
@@ -54,11 +93,17 @@ func (repo TemplatesRepo) ListIDsAndNames(conn ConnectionInterface) ([]Template,
 }
 
 func (repo TemplatesRepo) Create(conn ConnectionInterface, template Template) (Template, error) {
+	template.Version = 1
 	err := conn.Insert(&template)
 	if err != nil {
 		return Template{}, err
 	}
 
+	_, err = repo.recordVersion(conn, template)
+	if err != nil {
+		return Template{}, err
+	}
+
 	return template, nil
 }
 
@@ -69,6 +114,91 @@ func (repo TemplatesRepo) Destroy(conn ConnectionInterface, templateID string) e
 	}
 
 	_, err = conn.Delete(&template)
+	if err != nil {
+		return err
+	}
+
+	_, err = conn.Exec("DELETE FROM `template_versions` WHERE `template_id` = ?", templateID)
+	if err != nil {
+		return err
+	}
+
+	return NewTemplateTranslationsRepo().DestroyAllByTemplateID(conn, templateID)
+}
+
+// FindVersions lists every version of the template, newest first.
+func (repo TemplatesRepo) FindVersions(conn ConnectionInterface, templateID string) ([]TemplateVersion, error) {
+	_, err := repo.FindByID(conn, templateID)
+	if err != nil {
+		return []TemplateVersion{}, err
+	}
+
+	versions := []TemplateVersion{}
+	_, err = conn.Select(&versions, "SELECT * FROM `template_versions` WHERE `template_id` = ? ORDER BY `version` DESC", templateID)
+	if err != nil {
+		return []TemplateVersion{}, err
+	}
+
+	return versions, nil
+}
+
+// ActivateVersion puts the content of an earlier version back into the
+// template, which is what notifications are rendered with. No new version
+// is recorded, so the history is left as it was.
+func (repo TemplatesRepo) ActivateVersion(conn ConnectionInterface, templateID string, version int) (Template, error) {
+	template, err := repo.FindByID(conn, templateID)
+	if err != nil {
+		return Template{}, err
+	}
+
+	templateVersion := TemplateVersion{}
+	err = conn.SelectOne(&templateVersion, "SELECT * FROM `template_versions` WHERE `template_id` = ? AND `version` = ?", templateID, version)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return Template{}, NotFoundError{Err: fmt.Errorf("Version %d of template with ID %q could not be found", version, templateID), Code: "template_version_not_found"}
+		}
+		return Template{}, err
+	}
+
+	template.Name = templateVersion.Name
+	template.Subject = templateVersion.Subject
+	template.Text = templateVersion.Text
+	template.HTML = templateVersion.HTML
+	template.Slack = templateVersion.Slack
+	template.Metadata = templateVersion.Metadata
+	template.Version = templateVersion.Version
+	template.UpdatedAt = time.Now().Truncate(1 * time.Second).UTC()
+	template.Overridden = true
+
+	_, err = conn.Update(&template)
+	if err != nil {
+		return Template{}, TemplateUpdateError{err}
+	}
+
+	return template, nil
+}
+
+// recordVersion saves the content of the template as its next version and
+// returns the number of that version. Saving the same content as the latest
+// version again does not record another one.
+func (repo TemplatesRepo) recordVersion(conn ConnectionInterface, template Template) (int, error) {
+	version := newTemplateVersion(template)
+
+	latest := TemplateVersion{}
+	err := conn.SelectOne(&latest, "SELECT * FROM `template_versions` WHERE `template_id` = ? ORDER BY `version` DESC LIMIT 1", template.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	if err == nil && latest.sameContent(version) {
+		return latest.Version, nil
+	}
+
+	version.Version = latest.Version + 1
+	err = conn.Insert(&version)
+	if err != nil {
+		return 0, err
+	}
 
-	return err
+	return version.Version, nil
 }
@@ -55,7 +55,7 @@ var _ = Describe("TemplatesRepo", func() {
 				sillyTemplate, err := repo.FindByID(conn, "silly_template")
 
 				Expect(sillyTemplate).To(Equal(models.Template{}))
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"silly_template\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"silly_template\" could not be found"), Code: "template_not_found"}))
 			})
 		})
 	})
@@ -86,6 +86,26 @@ var _ = Describe("TemplatesRepo", func() {
 			Expect(foundTemplate.HTML).To(Equal(newTemplate.HTML))
 			Expect(foundTemplate.CreatedAt).To(BeTemporally("~", time.Now().UTC(), 2*time.Second))
 			Expect(foundTemplate.UpdatedAt).To(BeTemporally("~", time.Now().UTC(), 2*time.Second))
+			Expect(foundTemplate.ZoneID).To(Equal(models.DefaultZoneID))
+		})
+
+		It("keeps the zone the template was created in", func() {
+			createdTemplate, err := repo.Create(conn, models.Template{
+				Name:   "A Tenant Template",
+				ZoneID: "tenant-a",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			foundTemplate, err := repo.FindByID(conn, createdTemplate.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundTemplate.ZoneID).To(Equal("tenant-a"))
+
+			_, err = repo.Update(conn, createdTemplate.ID, models.Template{Name: "A Renamed Template"})
+			Expect(err).ToNot(HaveOccurred())
+
+			foundTemplate, err = repo.FindByID(conn, createdTemplate.ID)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundTemplate.ZoneID).To(Equal("tenant-a"))
 		})
 	})
 
@@ -127,14 +147,129 @@ var _ = Describe("TemplatesRepo", func() {
 		Context("the template does not exist in the database", func() {
 			It("bubbles up the error", func() {
 				_, err := repo.Update(conn, "a-bad-id", aNewTemplate)
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"a-bad-id\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"a-bad-id\" could not be found"), Code: "template_not_found"}))
 			})
 		})
 	})
 
+	Describe("Provision", func() {
+		It("creates the template under its ID when it does not exist", func() {
+			_, err := repo.Provision(conn, models.Template{
+				ID:       "pack-template",
+				Name:     "From a pack",
+				HTML:     "<p>packed</p>",
+				Metadata: "{}",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			foundTemplate, err := repo.FindByID(conn, "pack-template")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(foundTemplate.Name).To(Equal("From a pack"))
+			Expect(foundTemplate.Overridden).To(BeFalse())
+		})
+
+		It("replaces an existing template without marking it as overridden", func() {
+			_, err := repo.Update(conn, template.ID, models.Template{Name: "edited", HTML: "<p>edited</p>", Metadata: "{}"})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Provision(conn, models.Template{
+				ID:       template.ID,
+				Name:     "From a pack",
+				HTML:     "<p>packed</p>",
+				Metadata: "{}",
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			foundTemplate, err := repo.FindByID(conn, template.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(foundTemplate.Name).To(Equal("From a pack"))
+			Expect(foundTemplate.HTML).To(Equal("<p>packed</p>"))
+			Expect(foundTemplate.CreatedAt).To(Equal(createdAt))
+			Expect(foundTemplate.Overridden).To(BeFalse())
+		})
+	})
+
+	Describe("versions", func() {
+		var created models.Template
+
+		BeforeEach(func() {
+			var err error
+			created, err = repo.Create(conn, models.Template{Name: "Versioned", HTML: "<p>first</p>", Metadata: "{}"})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("records each save as a new version", func() {
+			Expect(created.Version).To(Equal(1))
+
+			updated, err := repo.Update(conn, created.ID, models.Template{Name: "Versioned", HTML: "<p>second</p>", Metadata: "{}"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Version).To(Equal(2))
+
+			versions, err := repo.FindVersions(conn, created.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(HaveLen(2))
+			Expect(versions[0].Version).To(Equal(2))
+			Expect(versions[0].HTML).To(Equal("<p>second</p>"))
+			Expect(versions[1].Version).To(Equal(1))
+			Expect(versions[1].HTML).To(Equal("<p>first</p>"))
+		})
+
+		It("does not record a save that changes nothing", func() {
+			updated, err := repo.Update(conn, created.ID, models.Template{Name: "Versioned", HTML: "<p>first</p>", Metadata: "{}"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Version).To(Equal(1))
+
+			versions, err := repo.FindVersions(conn, created.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(HaveLen(1))
+		})
+
+		It("activates an earlier version without changing the history", func() {
+			_, err := repo.Update(conn, created.ID, models.Template{Name: "Versioned", HTML: "<p>second</p>", Metadata: "{}"})
+			Expect(err).NotTo(HaveOccurred())
+
+			activated, err := repo.ActivateVersion(conn, created.ID, 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(activated.Version).To(Equal(1))
+
+			foundTemplate, err := repo.FindByID(conn, created.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(foundTemplate.HTML).To(Equal("<p>first</p>"))
+			Expect(foundTemplate.Version).To(Equal(1))
+
+			versions, err := repo.FindVersions(conn, created.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(HaveLen(2))
+
+			updated, err := repo.Update(conn, created.ID, models.Template{Name: "Versioned", HTML: "<p>third</p>", Metadata: "{}"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updated.Version).To(Equal(3))
+		})
+
+		It("returns a not found error for versions that do not exist", func() {
+			_, err := repo.ActivateVersion(conn, created.ID, 7)
+			Expect(err).To(MatchError(models.NotFoundError{Err: fmt.Errorf("Version 7 of template with ID %q could not be found", created.ID), Code: "template_version_not_found"}))
+		})
+
+		It("returns a not found error for templates that do not exist", func() {
+			_, err := repo.FindVersions(conn, "missing")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"missing\" could not be found"), Code: "template_not_found"}))
+		})
+
+		It("deletes the versions with the template", func() {
+			err := repo.Destroy(conn, created.ID)
+			Expect(err).NotTo(HaveOccurred())
+
+			versions := []models.TemplateVersion{}
+			_, err = conn.Select(&versions, "SELECT * FROM `template_versions` WHERE `template_id` = ?", created.ID)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(BeEmpty())
+		})
+	})
+
 	Describe("#ListIDsAndNames", func() {
 		Context("there are templates in the database", func() {
-			It("returns a list of templates - ID and Name only", func() {
+			It("returns a list of templates - ID, Name and zone only", func() {
 				secondTemplate := models.Template{
 					ID:        "star_template",
 					Name:      "Shooting Stars",
@@ -147,12 +282,14 @@ var _ = Describe("TemplatesRepo", func() {
 
 				expectedMetadata := []models.Template{
 					{
-						ID:   "raptor_template",
-						Name: "Raptors On The Run",
+						ID:     "raptor_template",
+						Name:   "Raptors On The Run",
+						ZoneID: models.DefaultZoneID,
 					},
 					{
-						ID:   "star_template",
-						Name: "Shooting Stars",
+						ID:     "star_template",
+						Name:   "Shooting Stars",
+						ZoneID: models.DefaultZoneID,
 					},
 				}
 				templatesMetadata, err := repo.ListIDsAndNames(conn)
@@ -178,14 +315,32 @@ var _ = Describe("TemplatesRepo", func() {
 				Expect(err).ToNot(HaveOccurred())
 
 				_, err = repo.FindByID(conn, template.ID)
-				Expect(err).To(MatchError(models.NotFoundError{Err: fmt.Errorf("Template with ID %q could not be found", template.ID)}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: fmt.Errorf("Template with ID %q could not be found", template.ID), Code: "template_not_found"}))
+			})
+		})
+
+		Context("the template has translations", func() {
+			It("deletes the translations too", func() {
+				translationsRepo := models.NewTemplateTranslationsRepo()
+				_, err := translationsRepo.Upsert(conn, models.TemplateTranslation{
+					TemplateID: template.ID,
+					Locale:     "fr",
+					HTML:       "<p>Bonjour</p>",
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				err = repo.Destroy(conn, template.ID)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = translationsRepo.Find(conn, template.ID, "fr")
+				Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
 			})
 		})
 
 		Context("the template does not exist in the database", func() {
 			It("returns an RecordNotFoundError", func() {
 				err := repo.Destroy(conn, "knockknock")
-				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"knockknock\" could not be found")}))
+				Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("Template with ID \"knockknock\" could not be found"), Code: "template_not_found"}))
 			})
 		})
 	})
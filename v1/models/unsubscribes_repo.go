@@ -65,6 +65,7 @@ func (repo UnsubscribesRepo) create(conn ConnectionInterface, unsubscribe Unsubs
 		}
 		return unsubscribe, err
 	}
+
 	return unsubscribe, nil
 }
 
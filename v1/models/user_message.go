@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type UserMessage struct {
+	Primary           int       `db:"primary"`
+	MessageID         string    `db:"message_id"`
+	UserGUID          string    `db:"user_guid"`
+	ClientID          string    `db:"client_id"`
+	KindID            string    `db:"kind_id"`
+	Subject           string    `db:"subject"`
+	SourceDescription string    `db:"source_description"`
+	KindDescription   string    `db:"kind_description"`
+	Severity          string    `db:"severity"`
+	RequestReceived   time.Time `db:"request_received"`
+	DeliveredAt       time.Time `db:"delivered_at"`
+}
+
+// UserMessageFilter narrows the messages delivered to a user.
+type UserMessageFilter struct {
+	UserGUID string
+	Since    time.Time
+
+	// ZoneID narrows the messages to those of the clients of the UAA zone,
+	// unless it is the default zone.
+	ZoneID string
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+type UserMessagesRepo struct{}
+
+func NewUserMessagesRepo() UserMessagesRepo {
+	return UserMessagesRepo{}
+}
+
+// Create records that a message was delivered to a user. Recording the same
+// message twice, as happens when a delivery is retried, is not an error.
+func (repo UserMessagesRepo) Create(conn ConnectionInterface, message UserMessage) (UserMessage, error) {
+	err := conn.Insert(&message)
+	if err != nil {
+		if strings.Contains(err.Error(), "Duplicate entry") {
+			return message, nil
+		}
+
+		return message, err
+	}
+
+	return message, nil
+}
+
+// List returns a page of the messages delivered to the user of the filter,
+// newest first.
+func (repo UserMessagesRepo) List(conn ConnectionInterface, filter UserMessageFilter, offset, limit int) ([]UserMessage, error) {
+	where, args := userMessageFilterClause(filter)
+
+	messages := []UserMessage{}
+	_, err := conn.Select(&messages, "SELECT * FROM `user_messages`"+where+" ORDER BY `delivered_at` DESC, `primary` DESC LIMIT ? OFFSET ?", append(args, limit, offset)...)
+	if err != nil {
+		return []UserMessage{}, err
+	}
+
+	return messages, nil
+}
+
+func (repo UserMessagesRepo) Count(conn ConnectionInterface, filter UserMessageFilter) (int, error) {
+	where, args := userMessageFilterClause(filter)
+
+	var count int
+	err := conn.SelectOne(&count, "SELECT COUNT(*) FROM `user_messages`"+where, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func userMessageFilterClause(filter UserMessageFilter) (string, []interface{}) {
+	conditions := []string{"`user_guid` = ?"}
+	args := []interface{}{filter.UserGUID}
+
+	if !filter.Since.IsZero() {
+		conditions = append(conditions, "`delivered_at` >= ?")
+		args = append(args, filter.Since.UTC())
+	}
+
+	if filter.ZoneID != "" && filter.ZoneID != DefaultZoneID {
+		conditions = append(conditions, "`client_id` LIKE ?")
+		args = append(args, likePrefix(ZonedClientID(filter.ZoneID, "")))
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func (repo UserMessagesRepo) DeleteBefore(conn ConnectionInterface, threshold time.Time, limit int) (int, error) {
+	result, err := conn.Exec("DELETE FROM `user_messages` WHERE `delivered_at` < ? LIMIT ?", threshold.UTC(), limit)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(count), nil
+}
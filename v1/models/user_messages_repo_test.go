@@ -0,0 +1,133 @@
+package models_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/db"
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UserMessagesRepo", func() {
+	var (
+		repo models.UserMessagesRepo
+		conn *db.Connection
+		now  time.Time
+	)
+
+	BeforeEach(func() {
+		database := db.NewDatabase(sqlDB, db.Config{})
+		helpers.TruncateTables(database)
+		conn = database.Connection().(*db.Connection)
+		repo = models.NewUserMessagesRepo()
+		now = time.Now().Truncate(time.Second).UTC()
+	})
+
+	Describe("Create and List", func() {
+		It("returns the messages delivered to the user, newest first", func() {
+			_, err := repo.Create(conn, models.UserMessage{MessageID: "message-1", UserGUID: "user-123", ClientID: "some-client", Subject: "older", DeliveredAt: now.Add(-time.Hour)})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.UserMessage{MessageID: "message-2", UserGUID: "user-123", ClientID: "some-client", Subject: "newer", DeliveredAt: now})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.UserMessage{MessageID: "message-3", UserGUID: "user-456", ClientID: "some-client", DeliveredAt: now})
+			Expect(err).NotTo(HaveOccurred())
+
+			messages, err := repo.List(conn, models.UserMessageFilter{UserGUID: "user-123"}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[0].Subject).To(Equal("newer"))
+			Expect(messages[1].Subject).To(Equal("older"))
+		})
+
+		It("ignores a message that was already recorded", func() {
+			_, err := repo.Create(conn, models.UserMessage{MessageID: "message-1", UserGUID: "user-123", ClientID: "some-client", DeliveredAt: now})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.UserMessage{MessageID: "message-1", UserGUID: "user-123", ClientID: "some-client", DeliveredAt: now})
+			Expect(err).NotTo(HaveOccurred())
+
+			messages, err := repo.List(conn, models.UserMessageFilter{UserGUID: "user-123"}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+		})
+	})
+
+	Describe("List and Count", func() {
+		BeforeEach(func() {
+			for i, clientID := range []string{"some-client", "zone-1:some-client", "some-client", "zone-1:other-client"} {
+				_, err := repo.Create(conn, models.UserMessage{
+					MessageID:   fmt.Sprintf("message-%d", i),
+					UserGUID:    "user-123",
+					ClientID:    clientID,
+					DeliveredAt: now.Add(time.Duration(-i) * time.Hour),
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		It("pages through the messages, newest first", func() {
+			messages, err := repo.List(conn, models.UserMessageFilter{UserGUID: "user-123"}, 1, 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[0].MessageID).To(Equal("message-1"))
+			Expect(messages[1].MessageID).To(Equal("message-2"))
+
+			count, err := repo.Count(conn, models.UserMessageFilter{UserGUID: "user-123"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(4))
+		})
+
+		It("narrows the messages by delivery time and zone", func() {
+			filter := models.UserMessageFilter{UserGUID: "user-123", Since: now.Add(-2 * time.Hour), ZoneID: "zone-1"}
+
+			messages, err := repo.List(conn, filter, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].MessageID).To(Equal("message-1"))
+
+			count, err := repo.Count(conn, filter)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+		})
+	})
+
+	Describe("DeleteBefore", func() {
+		It("deletes messages delivered before the threshold", func() {
+			_, err := repo.Create(conn, models.UserMessage{MessageID: "message-1", UserGUID: "user-123", ClientID: "some-client", DeliveredAt: now.Add(-48 * time.Hour)})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = repo.Create(conn, models.UserMessage{MessageID: "message-2", UserGUID: "user-123", ClientID: "some-client", DeliveredAt: now})
+			Expect(err).NotTo(HaveOccurred())
+
+			count, err := repo.DeleteBefore(conn, now.Add(-24*time.Hour), 100)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+
+			messages, err := repo.List(conn, models.UserMessageFilter{UserGUID: "user-123"}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].MessageID).To(Equal("message-2"))
+		})
+
+		It("deletes no more than the limit", func() {
+			for _, id := range []string{"message-1", "message-2", "message-3"} {
+				_, err := repo.Create(conn, models.UserMessage{MessageID: id, UserGUID: "user-123", ClientID: "some-client", DeliveredAt: now.Add(-48 * time.Hour)})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			count, err := repo.DeleteBefore(conn, now.Add(-24*time.Hour), 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+
+			messages, err := repo.List(conn, models.UserMessageFilter{UserGUID: "user-123"}, 0, 10)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+		})
+	})
+})
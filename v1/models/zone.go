@@ -0,0 +1,55 @@
+package models
+
+import "strings"
+
+// DefaultZoneID is the zone of the UAA itself, which the operators of the
+// deployment belong to.
+const DefaultZoneID = "uaa"
+
+const zoneSeparator = ":"
+
+// ZonedClientID is the ID a client of the zone is stored under. Clients of
+// the default zone keep their own ID, and those of any other zone are
+// prefixed with it, so that the kinds, unsubscribes and messages of a client
+// ID used in two zones stay apart.
+func ZonedClientID(zoneID, clientID string) string {
+	if zoneID == "" || zoneID == DefaultZoneID {
+		return clientID
+	}
+
+	return zoneID + zoneSeparator + clientID
+}
+
+// UnzonedClientID is the ID the zone knows a stored client by.
+func UnzonedClientID(zoneID, clientID string) string {
+	if zoneID == "" || zoneID == DefaultZoneID {
+		return clientID
+	}
+
+	return strings.TrimPrefix(clientID, zoneID+zoneSeparator)
+}
+
+// ClientInZone reports whether the stored client ID belongs to the zone.
+// The default zone sees the clients of every zone, under their stored IDs.
+func ClientInZone(zoneID, clientID string) bool {
+	if zoneID == "" || zoneID == DefaultZoneID {
+		return true
+	}
+
+	return strings.HasPrefix(clientID, zoneID+zoneSeparator)
+}
+
+// TemplateInZone reports whether the zone may see the template. The default
+// and digest templates are shared by every zone, and the default zone sees
+// the templates of every zone.
+func TemplateInZone(zoneID string, template Template) bool {
+	if zoneID == "" || zoneID == DefaultZoneID {
+		return true
+	}
+
+	if template.ID == DefaultTemplateID || template.ID == DigestTemplateID {
+		return true
+	}
+
+	return template.ZoneID == zoneID
+}
@@ -0,0 +1,42 @@
+package models_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Zoned client IDs", func() {
+	It("keeps the IDs of the default zone as they are", func() {
+		Expect(models.ZonedClientID("uaa", "login")).To(Equal("login"))
+		Expect(models.ZonedClientID("", "login")).To(Equal("login"))
+		Expect(models.UnzonedClientID("uaa", "tenant-a:login")).To(Equal("tenant-a:login"))
+	})
+
+	It("prefixes the IDs of other zones with the zone", func() {
+		Expect(models.ZonedClientID("tenant-a", "login")).To(Equal("tenant-a:login"))
+		Expect(models.UnzonedClientID("tenant-a", "tenant-a:login")).To(Equal("login"))
+	})
+
+	It("shows every client to the default zone, and only their own to the others", func() {
+		Expect(models.ClientInZone("uaa", "tenant-a:login")).To(BeTrue())
+		Expect(models.ClientInZone("tenant-a", "tenant-a:login")).To(BeTrue())
+		Expect(models.ClientInZone("tenant-a", "login")).To(BeFalse())
+		Expect(models.ClientInZone("tenant-a", "tenant-b:login")).To(BeFalse())
+	})
+})
+
+var _ = Describe("TemplateInZone", func() {
+	It("shows every template to the default zone", func() {
+		Expect(models.TemplateInZone("uaa", models.Template{ID: "some-template", ZoneID: "tenant-a"})).To(BeTrue())
+	})
+
+	It("shows the templates of the zone, and the shared ones, to the others", func() {
+		Expect(models.TemplateInZone("tenant-a", models.Template{ID: "some-template", ZoneID: "tenant-a"})).To(BeTrue())
+		Expect(models.TemplateInZone("tenant-a", models.Template{ID: "some-template", ZoneID: "tenant-b"})).To(BeFalse())
+		Expect(models.TemplateInZone("tenant-a", models.Template{ID: "some-template", ZoneID: "uaa"})).To(BeFalse())
+		Expect(models.TemplateInZone("tenant-a", models.Template{ID: models.DefaultTemplateID, ZoneID: "uaa"})).To(BeTrue())
+		Expect(models.TemplateInZone("tenant-a", models.Template{ID: models.DigestTemplateID, ZoneID: "uaa"})).To(BeTrue())
+	})
+})
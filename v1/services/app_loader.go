@@ -0,0 +1,26 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/cf"
+
+type appLoaderCC interface {
+	LoadApp(appGUID, token string) (cf.CloudControllerApp, error)
+}
+
+type AppLoader struct {
+	cc appLoaderCC
+}
+
+func NewAppLoader(cc appLoaderCC) AppLoader {
+	return AppLoader{
+		cc: cc,
+	}
+}
+
+func (loader AppLoader) Load(appGUID string, token string) (cf.CloudControllerApp, error) {
+	app, err := loader.cc.LoadApp(appGUID, token)
+	if err != nil {
+		return cf.CloudControllerApp{}, CCErrorFor(err)
+	}
+
+	return app, nil
+}
@@ -0,0 +1,59 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AppLoader", func() {
+	Describe("Load", func() {
+		var (
+			loader services.AppLoader
+			cc     *mocks.CloudController
+		)
+
+		BeforeEach(func() {
+			cc = mocks.NewCloudController()
+			cc.LoadAppCall.Returns.App = cf.CloudControllerApp{
+				GUID:      "app-001",
+				Name:      "app-name",
+				SpaceGUID: "space-001",
+			}
+
+			loader = services.NewAppLoader(cc)
+		})
+
+		It("returns the app", func() {
+			app, err := loader.Load("app-001", "some-token")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(app).To(Equal(cf.CloudControllerApp{
+				GUID:      "app-001",
+				Name:      "app-name",
+				SpaceGUID: "space-001",
+			}))
+
+			Expect(cc.LoadAppCall.Receives.AppGUID).To(Equal("app-001"))
+			Expect(cc.LoadAppCall.Receives.Token).To(Equal("some-token"))
+		})
+
+		It("returns a CCNotFoundError when the app cannot be found", func() {
+			cc.LoadAppCall.Returns.Error = cf.NewFailure(404, "not found")
+
+			_, err := loader.Load("missing-app", "some-token")
+			Expect(err).To(MatchError(services.CCNotFoundError{Err: cf.NewFailure(404, "not found")}))
+		})
+
+		It("returns other errors as they are", func() {
+			cc.LoadAppCall.Returns.Error = errors.New("BOOM!")
+
+			_, err := loader.Load("app-001", "some-token")
+			Expect(err).To(Equal(errors.New("BOOM!")))
+		})
+	})
+})
@@ -0,0 +1,69 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type criticalDecisionsRepo interface {
+	FindAllByStatus(conn models.ConnectionInterface, status string) ([]models.CriticalApproval, error)
+	SetStatus(conn models.ConnectionInterface, clientID, kindID, status string) (models.CriticalApproval, error)
+}
+
+// CriticalApprover lets operators decide on the requests of kinds to be
+// critical. Approving a request makes the kind critical straight away;
+// denying it makes an approved kind non-critical again.
+type CriticalApprover struct {
+	approvalsRepo criticalDecisionsRepo
+	kindsRepo     KindsRepo
+	downgrade     criticalDowngrader
+}
+
+func NewCriticalApprover(approvalsRepo criticalDecisionsRepo, kindsRepo KindsRepo, downgrade criticalDowngrader) CriticalApprover {
+	return CriticalApprover{
+		approvalsRepo: approvalsRepo,
+		kindsRepo:     kindsRepo,
+		downgrade:     downgrade,
+	}
+}
+
+func (approver CriticalApprover) List(database DatabaseInterface, status string) ([]models.CriticalApproval, error) {
+	return approver.approvalsRepo.FindAllByStatus(database.Connection(), status)
+}
+
+func (approver CriticalApprover) Approve(database DatabaseInterface, clientID, kindID string) (models.CriticalApproval, error) {
+	return approver.decide(database, clientID, kindID, models.CriticalApprovalApproved)
+}
+
+func (approver CriticalApprover) Deny(database DatabaseInterface, clientID, kindID string) (models.CriticalApproval, error) {
+	return approver.decide(database, clientID, kindID, models.CriticalApprovalDenied)
+}
+
+func (approver CriticalApprover) decide(database DatabaseInterface, clientID, kindID, status string) (models.CriticalApproval, error) {
+	conn := database.Connection()
+
+	approval, err := approver.approvalsRepo.SetStatus(conn, clientID, kindID, status)
+	if err != nil {
+		return models.CriticalApproval{}, err
+	}
+
+	kind, err := approver.kindsRepo.Find(conn, kindID, clientID)
+	if err != nil {
+		return models.CriticalApproval{}, err
+	}
+
+	critical := status == models.CriticalApprovalApproved
+	if kind.Critical == critical {
+		return approval, nil
+	}
+
+	kind.Critical = critical
+	_, err = approver.kindsRepo.Update(conn, kind)
+	if err != nil {
+		return models.CriticalApproval{}, err
+	}
+
+	err = approver.downgrade.Apply(conn, kind)
+	if err != nil {
+		return models.CriticalApproval{}, err
+	}
+
+	return approval, nil
+}
@@ -0,0 +1,96 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CriticalApprover", func() {
+	var (
+		approver  services.CriticalApprover
+		approvals *mocks.CriticalApprovalsRepo
+		kindsRepo *mocks.KindsRepo
+		downgrade *mocks.CriticalDowngrade
+		conn      *mocks.Connection
+		database  *mocks.Database
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		approvals = mocks.NewCriticalApprovalsRepo()
+		kindsRepo = mocks.NewKindsRepo()
+		downgrade = mocks.NewCriticalDowngrade()
+
+		approver = services.NewCriticalApprover(approvals, kindsRepo, downgrade)
+	})
+
+	Describe("List", func() {
+		It("lists the approvals with the status", func() {
+			approvals.FindAllByStatusCall.Returns.Approvals = []models.CriticalApproval{{ClientID: "raptors", KindID: "hungry"}}
+
+			list, err := approver.List(database, models.CriticalApprovalPending)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(list).To(HaveLen(1))
+
+			Expect(approvals.FindAllByStatusCall.Receives.Connection).To(Equal(conn))
+			Expect(approvals.FindAllByStatusCall.Receives.Status).To(Equal(models.CriticalApprovalPending))
+		})
+	})
+
+	Describe("Approve", func() {
+		It("makes the kind critical", func() {
+			approvals.SetStatusCall.Returns.Approval = models.CriticalApproval{ClientID: "raptors", KindID: "hungry", Status: models.CriticalApprovalApproved}
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "hungry", ClientID: "raptors"}}
+
+			approval, err := approver.Approve(database, "raptors", "hungry")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(approval.Status).To(Equal(models.CriticalApprovalApproved))
+
+			Expect(approvals.SetStatusCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(approvals.SetStatusCall.Receives.KindID).To(Equal("hungry"))
+			Expect(approvals.SetStatusCall.Receives.Status).To(Equal(models.CriticalApprovalApproved))
+
+			Expect(kindsRepo.UpdateCall.Receives.Kind).To(Equal(models.Kind{ID: "hungry", ClientID: "raptors", Critical: true}))
+		})
+
+		It("returns the error when the kind has not asked to be critical", func() {
+			approvals.SetStatusCall.Returns.Error = models.NotFoundError{Err: errors.New("not asked")}
+
+			_, err := approver.Approve(database, "raptors", "hungry")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not asked")}))
+			Expect(kindsRepo.UpdateCall.Receives.Kind).To(Equal(models.Kind{}))
+		})
+	})
+
+	Describe("Deny", func() {
+		It("leaves a non-critical kind alone", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "hungry", ClientID: "raptors"}}
+
+			_, err := approver.Deny(database, "raptors", "hungry")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(approvals.SetStatusCall.Receives.Status).To(Equal(models.CriticalApprovalDenied))
+			Expect(kindsRepo.UpdateCall.Receives.Kind).To(Equal(models.Kind{}))
+			Expect(downgrade.ApplyCall.Receives.Kinds).To(BeEmpty())
+		})
+
+		It("makes an approved kind non-critical again", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "hungry", ClientID: "raptors", Critical: true}}
+
+			_, err := approver.Deny(database, "raptors", "hungry")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(kindsRepo.UpdateCall.Receives.Kind.Critical).To(BeFalse())
+			Expect(downgrade.ApplyCall.Receives.Kinds).To(Equal([]models.Kind{{ID: "hungry", ClientID: "raptors"}}))
+		})
+	})
+})
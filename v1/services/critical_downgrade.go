@@ -0,0 +1,60 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/rcrowley/go-metrics"
+)
+
+// CriticalDowngrade honors the unsubscribes that were parked while a kind was
+// critical once the kind is saved as non-critical. Requests older than the
+// grace period are dropped instead; a zero grace period honors them all.
+type CriticalDowngrade struct {
+	criticalUnsubscribesRepo CriticalUnsubscribesRepo
+	unsubscribesRepo         UnsubscribesRepo
+	clock                    clock
+	gracePeriod              time.Duration
+}
+
+func NewCriticalDowngrade(criticalUnsubscribesRepo CriticalUnsubscribesRepo, unsubscribesRepo UnsubscribesRepo, clock clock, gracePeriod time.Duration) CriticalDowngrade {
+	return CriticalDowngrade{
+		criticalUnsubscribesRepo: criticalUnsubscribesRepo,
+		unsubscribesRepo:         unsubscribesRepo,
+		clock:                    clock,
+		gracePeriod:              gracePeriod,
+	}
+}
+
+func (downgrade CriticalDowngrade) Apply(conn ConnectionInterface, kind models.Kind) error {
+	if kind.Critical {
+		return nil
+	}
+
+	parked, err := downgrade.criticalUnsubscribesRepo.FindAllByKind(conn, kind.ClientID, kind.ID)
+	if err != nil {
+		return err
+	}
+
+	if len(parked) == 0 {
+		return nil
+	}
+
+	cutoff := downgrade.clock.Now().Add(-downgrade.gracePeriod)
+	for _, unsubscribe := range parked {
+		if downgrade.gracePeriod > 0 && unsubscribe.CreatedAt.Before(cutoff) {
+			metrics.GetOrRegisterCounter("notifications.kinds.downgrade.unsubscribes_expired", nil).Inc(1)
+			continue
+		}
+
+		err = downgrade.unsubscribesRepo.Set(conn, unsubscribe.UserID, unsubscribe.ClientID, unsubscribe.KindID, true)
+		if err != nil {
+			return err
+		}
+
+		metrics.GetOrRegisterCounter("notifications.kinds.downgrade.unsubscribes_honored", nil).Inc(1)
+	}
+
+	_, err = downgrade.criticalUnsubscribesRepo.DeleteAllByKind(conn, kind.ClientID, kind.ID)
+	return err
+}
@@ -0,0 +1,106 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CriticalDowngrade", func() {
+	var (
+		downgrade                services.CriticalDowngrade
+		criticalUnsubscribesRepo *mocks.CriticalUnsubscribesRepo
+		unsubscribesRepo         *mocks.UnsubscribesRepo
+		clock                    *mocks.Clock
+		conn                     *mocks.Connection
+		kind                     models.Kind
+		now                      time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		conn = mocks.NewConnection()
+		criticalUnsubscribesRepo = mocks.NewCriticalUnsubscribesRepo()
+		criticalUnsubscribesRepo.FindAllByKindCall.Returns.Unsubscribes = []models.CriticalUnsubscribe{
+			{UserID: "user-123", ClientID: "raptors", KindID: "feeding-time", CreatedAt: now.Add(-24 * time.Hour)},
+		}
+		unsubscribesRepo = mocks.NewUnsubscribesRepo()
+
+		kind = models.Kind{ID: "feeding-time", ClientID: "raptors"}
+
+		downgrade = services.NewCriticalDowngrade(criticalUnsubscribesRepo, unsubscribesRepo, clock, 0)
+	})
+
+	Describe("Apply", func() {
+		It("turns the parked unsubscribes into unsubscribes", func() {
+			err := downgrade.Apply(conn, kind)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(criticalUnsubscribesRepo.FindAllByKindCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(criticalUnsubscribesRepo.FindAllByKindCall.Receives.KindID).To(Equal("feeding-time"))
+
+			Expect(unsubscribesRepo.SetCall.Receives.Connection).To(Equal(conn))
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(unsubscribesRepo.SetCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(unsubscribesRepo.SetCall.Receives.KindID).To(Equal("feeding-time"))
+			Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+
+			Expect(criticalUnsubscribesRepo.DeleteAllByKindCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(criticalUnsubscribesRepo.DeleteAllByKindCall.Receives.KindID).To(Equal("feeding-time"))
+		})
+
+		It("leaves critical kinds alone", func() {
+			kind.Critical = true
+
+			err := downgrade.Apply(conn, kind)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(criticalUnsubscribesRepo.FindAllByKindCall.Receives.KindID).To(BeEmpty())
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+		})
+
+		It("does nothing when no unsubscribes are parked", func() {
+			criticalUnsubscribesRepo.FindAllByKindCall.Returns.Unsubscribes = []models.CriticalUnsubscribe{}
+
+			err := downgrade.Apply(conn, kind)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(criticalUnsubscribesRepo.DeleteAllByKindCall.WasCalled).To(BeFalse())
+		})
+
+		Context("when a grace period is configured", func() {
+			It("drops the unsubscribes recorded before it", func() {
+				criticalUnsubscribesRepo.FindAllByKindCall.Returns.Unsubscribes = []models.CriticalUnsubscribe{
+					{UserID: "user-123", ClientID: "raptors", KindID: "feeding-time", CreatedAt: now.Add(-24 * time.Hour)},
+					{UserID: "user-456", ClientID: "raptors", KindID: "feeding-time", CreatedAt: now.Add(-10 * 24 * time.Hour)},
+				}
+				downgrade = services.NewCriticalDowngrade(criticalUnsubscribesRepo, unsubscribesRepo, clock, 7*24*time.Hour)
+
+				err := downgrade.Apply(conn, kind)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+				Expect(criticalUnsubscribesRepo.DeleteAllByKindCall.WasCalled).To(BeTrue())
+			})
+		})
+
+		Context("when an unsubscribe cannot be saved", func() {
+			It("returns the error and keeps the parked unsubscribes", func() {
+				unsubscribesRepo.SetCall.Returns.Error = errors.New("database is down")
+
+				err := downgrade.Apply(conn, kind)
+				Expect(err).To(MatchError(errors.New("database is down")))
+				Expect(criticalUnsubscribesRepo.DeleteAllByKindCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+})
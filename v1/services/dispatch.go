@@ -1,6 +1,11 @@
 package services
 
-import "time"
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
 
 type Dispatch struct {
 	JobType    string
@@ -11,6 +16,30 @@ type Dispatch struct {
 	TemplateID string
 	CampaignID string
 
+	// AppGUID names the app the notification is about, so that its payload
+	// can refer to the app and the space and organization it belongs to.
+	AppGUID string
+
+	// CallbackURL receives a webhook each time the status of a message
+	// created by this dispatch changes.
+	CallbackURL string
+
+	// TraceParent is the W3C traceparent of the span dispatching the
+	// notification.
+	TraceParent string
+
+	// Deadline is the time after which the notification must not be sent.
+	// The zero time means it is sent no matter how late.
+	Deadline time.Time
+
+	// Metadata is the sender's key/value pairs, kept with the messages so
+	// that it can correlate them with its own records.
+	Metadata map[string]string
+
+	// Severity is the severity of the notification, as the notify request
+	// gave it or else as its kind has it.
+	Severity string
+
 	VCAPRequest DispatchVCAPRequest
 	Message     DispatchMessage
 	Kind        DispatchKind
@@ -40,9 +69,34 @@ type DispatchMessage struct {
 type DispatchClient struct {
 	ID          string
 	Description string
+
+	// SimulationSinks are the addresses that receive the messages of a
+	// client in simulation mode.
+	SimulationSinks []string
 }
 
 type DispatchKind struct {
-	ID          string
-	Description string
+	ID            string
+	Description   string
+	Critical      bool
+	Transactional bool
+}
+
+// priority is the queue priority of the deliveries for this dispatch.
+// Critical kinds go ahead of everything else, and bulk sends, such as those
+// to every user, wait behind regular ones whatever their severity. Any
+// client may set the severity of a request, so notifications that require
+// action or warn only go ahead of the informational ones, and only the
+// critical kinds, which need the critical scope, reach the critical lane.
+func (d Dispatch) priority(bulk bool) int {
+	switch {
+	case d.Kind.Critical:
+		return gobble.PriorityCritical
+	case bulk:
+		return gobble.PriorityBulk
+	case d.Severity == models.SeverityCriticalActionRequired, d.Severity == models.SeverityWarning:
+		return gobble.PriorityHigh
+	default:
+		return gobble.PriorityNormal
+	}
 }
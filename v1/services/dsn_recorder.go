@@ -0,0 +1,62 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+// DSNRecorder applies the delivery status notifications that mail servers
+// send back to the messages they report on, found by the envelope ID each
+// message was sent with.
+type DSNRecorder struct {
+	repo messagesRepoUpdater
+}
+
+func NewDSNRecorder(repo messagesRepoUpdater) DSNRecorder {
+	return DSNRecorder{
+		repo: repo,
+	}
+}
+
+// Record confirms a message that the report says reached its recipient, and
+// marks one that the report says could not be delivered as hard bounced.
+// Reports of delays, and those about a message whose outcome is already
+// settled, leave it as it is.
+func (r DSNRecorder) Record(conn ConnectionInterface, report mail.DSN) (models.Message, error) {
+	if report.EnvelopeID == "" {
+		return models.Message{}, DSNReportError{errors.New("The report has no Original-Envelope-Id, so it cannot be matched to a message")}
+	}
+
+	if len(report.Recipients) == 0 {
+		return models.Message{}, DSNReportError{errors.New("The report has no recipient fields")}
+	}
+
+	message, err := r.repo.FindByID(conn, report.EnvelopeID)
+	if err != nil {
+		return models.Message{}, err
+	}
+
+	if message.Status != common.StatusDelivered {
+		return message, nil
+	}
+
+	// Each message is sent to a single recipient.
+	recipient := report.Recipients[0]
+
+	switch recipient.Action {
+	case mail.DSNActionDelivered, mail.DSNActionExpanded:
+		message.Status = common.StatusConfirmed
+	case mail.DSNActionFailed:
+		reply := common.DSNReply(recipient)
+		message.Status = common.StatusHardBounced
+		message.SMTPCode = reply.Code
+		message.SMTPEnhancedStatus = reply.EnhancedStatus
+	default:
+		return message, nil
+	}
+
+	return r.repo.Update(conn, message)
+}
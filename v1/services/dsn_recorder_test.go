@@ -0,0 +1,101 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("DSNRecorder", func() {
+	var (
+		recorder     services.DSNRecorder
+		messagesRepo *mocks.MessagesRepo
+		conn         *mocks.Connection
+		report       mail.DSN
+	)
+
+	BeforeEach(func() {
+		messagesRepo = mocks.NewMessagesRepo()
+		messagesRepo.FindByIDCall.Returns.Message = models.Message{
+			ID:     "message-123",
+			Status: common.StatusDelivered,
+		}
+		conn = mocks.NewConnection()
+
+		report = mail.DSN{
+			EnvelopeID: "message-123",
+			Recipients: []mail.DSNRecipient{{
+				FinalRecipient: "user@example.com",
+				Action:         mail.DSNActionDelivered,
+				Status:         "2.0.0",
+			}},
+		}
+
+		recorder = services.NewDSNRecorder(messagesRepo)
+	})
+
+	It("confirms a message the report says was delivered", func() {
+		messagesRepo.UpdateCall.Returns.Message = models.Message{ID: "message-123", Status: common.StatusConfirmed}
+
+		message, err := recorder.Record(conn, report)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(message.Status).To(Equal(common.StatusConfirmed))
+
+		Expect(messagesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(Equal("message-123"))
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(Equal([]models.Message{
+			{ID: "message-123", Status: common.StatusConfirmed},
+		}))
+	})
+
+	It("marks a message the report says failed as hard bounced, with the reply", func() {
+		report.Recipients[0].Action = mail.DSNActionFailed
+		report.Recipients[0].Status = "5.1.1"
+		report.Recipients[0].DiagnosticCode = "smtp; 550 5.1.1 User unknown"
+
+		_, err := recorder.Record(conn, report)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(Equal([]models.Message{
+			{ID: "message-123", Status: common.StatusHardBounced, SMTPCode: 550, SMTPEnhancedStatus: "5.1.1"},
+		}))
+	})
+
+	It("leaves the message alone when delivery is only delayed", func() {
+		report.Recipients[0].Action = mail.DSNActionDelayed
+
+		message, err := recorder.Record(conn, report)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(message.Status).To(Equal(common.StatusDelivered))
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+	})
+
+	It("leaves a message whose outcome is settled alone", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusHardBounced
+
+		_, err := recorder.Record(conn, report)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+	})
+
+	It("errors when the report has no envelope ID", func() {
+		report.EnvelopeID = ""
+
+		_, err := recorder.Record(conn, report)
+		Expect(err).To(MatchError(services.DSNReportError{errors.New("The report has no Original-Envelope-Id, so it cannot be matched to a message")}))
+		Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(BeEmpty())
+	})
+
+	It("returns the error when the message cannot be found", func() {
+		messagesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		_, err := recorder.Record(conn, report)
+		Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
+	})
+})
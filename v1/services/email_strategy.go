@@ -43,6 +43,14 @@ func (strategy EmailStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		Endorsement:       EmailEndorsement,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		CallbackURL:       dispatch.CallbackURL,
+		TraceParent:       dispatch.TraceParent,
+		SimulationSinks:   dispatch.Client.SimulationSinks,
+		Deadline:          dispatch.Deadline,
+		Metadata:          dispatch.Metadata,
+		Priority:          dispatch.priority(false),
+		Severity:          dispatch.Severity,
+		Transactional:     dispatch.Kind.Transactional,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
 			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
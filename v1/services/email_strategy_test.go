@@ -91,5 +91,20 @@ var _ = Describe("EmailStrategy", func() {
 				Expect(enqueuer.EnqueueCall.Receives.UAAHost).To(Equal("uaahost"))
 			})
 		})
+
+		It("marks deliveries of transactional kinds", func() {
+			emailStrategy.Dispatch(services.Dispatch{
+				Connection: conn,
+				Kind: services.DispatchKind{
+					ID:            "password_reset",
+					Transactional: true,
+				},
+				Message: services.DispatchMessage{
+					To: "dr@strangelove.com",
+				},
+			})
+
+			Expect(enqueuer.EnqueueCall.Receives.Options.Transactional).To(BeTrue())
+		})
 	})
 })
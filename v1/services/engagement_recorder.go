@@ -0,0 +1,63 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type trackingTokenParser interface {
+	Parse(token string) (common.TrackingEvent, error)
+}
+
+type engagementMessagesRepo interface {
+	RecordEngagement(conn models.ConnectionInterface, messageID, event string) error
+}
+
+type kindActivityIncrementer interface {
+	Increment(conn models.ConnectionInterface, clientID, kindID, counter string, at time.Time) error
+}
+
+// EngagementRecorder counts the opens and clicks reported by the tracking
+// links of delivered mail, against the message and against its kind.
+type EngagementRecorder struct {
+	tokens       trackingTokenParser
+	messagesRepo engagementMessagesRepo
+	kindActivity kindActivityIncrementer
+	clock        clock
+}
+
+func NewEngagementRecorder(tokens trackingTokenParser, messagesRepo engagementMessagesRepo, kindActivity kindActivityIncrementer, clock clock) EngagementRecorder {
+	return EngagementRecorder{
+		tokens:       tokens,
+		messagesRepo: messagesRepo,
+		kindActivity: kindActivity,
+		clock:        clock,
+	}
+}
+
+// Record returns the event of a valid token even when it cannot be counted,
+// so that the reader still gets the pixel or the page they clicked through to.
+func (r EngagementRecorder) Record(conn ConnectionInterface, token string) (common.TrackingEvent, error) {
+	event, err := r.tokens.Parse(token)
+	if err != nil {
+		return common.TrackingEvent{}, TrackingLinkError{err}
+	}
+
+	err = r.messagesRepo.RecordEngagement(conn, event.MessageID, event.Event)
+	if err != nil {
+		return event, err
+	}
+
+	if event.KindID == "" {
+		return event, nil
+	}
+
+	counter := models.KindActivityOpened
+	if event.Event == models.EngagementClick {
+		counter = models.KindActivityClicked
+	}
+
+	return event, r.kindActivity.Increment(conn, event.ClientID, event.KindID, counter, r.clock.Now())
+}
@@ -0,0 +1,102 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("EngagementRecorder", func() {
+	var (
+		recorder     services.EngagementRecorder
+		tokens       *mocks.TrackingTokens
+		messagesRepo *mocks.MessagesRepo
+		kindActivity *mocks.KindActivityRepo
+		clock        *mocks.Clock
+		conn         *mocks.Connection
+		now          time.Time
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		now = time.Date(2026, 3, 14, 9, 30, 0, 0, time.UTC)
+
+		tokens = mocks.NewTrackingTokens()
+		tokens.ParseCall.Returns.Event = common.TrackingEvent{
+			Event:     models.EngagementOpen,
+			MessageID: "message-123",
+			ClientID:  "raptors",
+			KindID:    "feeding-time",
+		}
+
+		messagesRepo = mocks.NewMessagesRepo()
+		kindActivity = mocks.NewKindActivityRepo()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		recorder = services.NewEngagementRecorder(tokens, messagesRepo, kindActivity, clock)
+	})
+
+	It("counts an open against the message and its kind", func() {
+		event, err := recorder.Record(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.MessageID).To(Equal("message-123"))
+
+		Expect(tokens.ParseCall.Receives.Token).To(Equal("some-token"))
+
+		Expect(messagesRepo.RecordEngagementCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.RecordEngagementCall.Receives.MessageID).To(Equal("message-123"))
+		Expect(messagesRepo.RecordEngagementCall.Receives.Event).To(Equal(models.EngagementOpen))
+
+		Expect(kindActivity.IncrementCall.Receives.ClientID).To(Equal("raptors"))
+		Expect(kindActivity.IncrementCall.Receives.KindID).To(Equal("feeding-time"))
+		Expect(kindActivity.IncrementCall.Receives.Counter).To(Equal(models.KindActivityOpened))
+		Expect(kindActivity.IncrementCall.Receives.At).To(Equal(now))
+	})
+
+	It("counts a click as a click", func() {
+		tokens.ParseCall.Returns.Event.Event = models.EngagementClick
+		tokens.ParseCall.Returns.Event.Target = "https://example.com/feeding"
+
+		event, err := recorder.Record(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(event.Target).To(Equal("https://example.com/feeding"))
+
+		Expect(messagesRepo.RecordEngagementCall.Receives.Event).To(Equal(models.EngagementClick))
+		Expect(kindActivity.IncrementCall.Receives.Counter).To(Equal(models.KindActivityClicked))
+	})
+
+	It("does not count kind activity for messages without a kind", func() {
+		tokens.ParseCall.Returns.Event.KindID = ""
+
+		_, err := recorder.Record(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(messagesRepo.RecordEngagementCall.CallCount).To(Equal(1))
+		Expect(kindActivity.IncrementCall.CallCount).To(Equal(0))
+	})
+
+	It("rejects tokens that cannot be parsed", func() {
+		tokens.ParseCall.Returns.Error = errors.New("The tracking link is invalid")
+
+		_, err := recorder.Record(conn, "some-token")
+		Expect(err).To(MatchError(services.TrackingLinkError{Err: errors.New("The tracking link is invalid")}))
+
+		Expect(messagesRepo.RecordEngagementCall.CallCount).To(Equal(0))
+	})
+
+	It("returns the event along with errors from counting it", func() {
+		messagesRepo.RecordEngagementCall.Returns.Error = errors.New("database is down")
+
+		event, err := recorder.Record(conn, "some-token")
+		Expect(err).To(MatchError("database is down"))
+		Expect(event.MessageID).To(Equal("message-123"))
+	})
+})
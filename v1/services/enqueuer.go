@@ -1,16 +1,28 @@
 package services
 
 import (
+	"database/sql"
+	"encoding/json"
+	"strconv"
+	"sync"
 	"time"
 
 	"gopkg.in/gorp.v1"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
 )
 
-const StatusQueued = "queued"
+const (
+	StatusQueued       = "queued"
+	StatusFailed       = "failed"
+	StatusUnsubscribed = "unsubscribed"
+)
 
 type Options struct {
 	ReplyTo           string
@@ -24,9 +36,40 @@ type Options struct {
 	Role              string
 	Endorsement       string
 	TemplateID        string
+	CallbackURL       string
+
+	// Priority is the gobble job priority of the delivery.
+	Priority int
+
+	// Severity is passed through to the messages, their templates and
+	// webhooks. See models.Severities.
+	Severity string
+
+	// Transactional deliveries to a single recipient skip the queue when
+	// the enqueuer has a fast lane with an idle worker.
+	Transactional bool
+
+	// TraceParent is the W3C traceparent of the span that enqueued the
+	// delivery, so the worker can continue the trace.
+	TraceParent string
+
+	// SimulationSinks, when not empty, receive the deliveries in place of
+	// their recipients, and the messages are marked simulated.
+	SimulationSinks []string
+
+	// Deadline, when set, is the time after which the deliveries are no
+	// longer worth sending, and are marked expired by the worker instead.
+	Deadline time.Time
+
+	// Metadata is passed through to the messages and their webhooks.
+	Metadata map[string]string
 }
 
 type Delivery struct {
+	// JobType is empty for email deliveries and names the channel of
+	// deliveries that go elsewhere, such as common.SlackJobType.
+	JobType string
+
 	MessageID       string
 	Options         Options
 	UserGUID        string
@@ -46,26 +89,75 @@ type messagesRepoUpserter interface {
 
 type queueInterface interface {
 	Enqueue(job *gobble.Job, transaction gobble.ConnectionInterface) (*gobble.Job, error)
+	Transactional() bool
 }
 
 type gobbleInitializer interface {
 	InitializeDBMap(*gorp.DbMap)
 }
 
+type fastLane interface {
+	Submit(job *gobble.Job) bool
+}
+
+type quotaConsumer interface {
+	Consume(conn models.ConnectionInterface, clientID string, count int, at time.Time) error
+}
+
+type payloadSampler interface {
+	Create(conn models.ConnectionInterface, sample models.PayloadSample) error
+}
+
 type Enqueuer struct {
 	queue             queueInterface
 	messagesRepo      messagesRepoUpserter
 	gobbleInitializer gobbleInitializer
+	quotas            quotaConsumer
+	fastLane          fastLane
+	samples           payloadSampler
+	logger            lager.Logger
+	batchSize         int
+	workers           int
 }
 
-func NewEnqueuer(queue queueInterface, messagesRepo messagesRepoUpserter, gobbleInitializer gobbleInitializer) Enqueuer {
+// NewEnqueuer builds an enqueuer. When lane is not nil, transactional
+// deliveries to a single recipient are handed to it once they are committed,
+// and are queued only when it has no idle worker.
+func NewEnqueuer(queue queueInterface, messagesRepo messagesRepoUpserter, gobbleInitializer gobbleInitializer, quotas quotaConsumer, lane fastLane) Enqueuer {
 	return Enqueuer{
 		queue:             queue,
 		messagesRepo:      messagesRepo,
 		gobbleInitializer: gobbleInitializer,
+		quotas:            quotas,
+		fastLane:          lane,
 	}
 }
 
+// WithFanOut has audiences of more than batchSize users enqueued in batches
+// of that size, each in its own transaction, with up to workers batches
+// being enqueued at once. The quota of the whole audience is consumed before
+// the batches start, and a batch that fails does not stop the others.
+func (enqueuer Enqueuer) WithFanOut(batchSize, workers int) Enqueuer {
+	enqueuer.batchSize = batchSize
+	enqueuer.workers = workers
+	return enqueuer
+}
+
+// WithPayloadSamples keeps the delivery to the first recipient of each
+// notification that is queued, so that template changes can be previewed
+// against it.
+func (enqueuer Enqueuer) WithPayloadSamples(samples payloadSampler) Enqueuer {
+	enqueuer.samples = samples
+	return enqueuer
+}
+
+// WithLogger logs the jobs that could not be queued once their messages were
+// committed. Those messages stay queued until the queue is replayed.
+func (enqueuer Enqueuer) WithLogger(logger lager.Logger) Enqueuer {
+	enqueuer.logger = logger
+	return enqueuer
+}
+
 func (enqueuer Enqueuer) Enqueue(
 	conn ConnectionInterface,
 	users []User,
@@ -78,60 +170,366 @@ func (enqueuer Enqueuer) Enqueue(
 	vcapRequestID string,
 	reqReceived time.Time) ([]Response, error) {
 
+	span := tracing.Start("notifications.enqueue", tracing.KindProducer, tracing.ParseTraceparent(options.TraceParent))
+	span.SetAttribute("vcap_request_id", vcapRequestID)
+	span.SetAttribute("recipients", strconv.Itoa(len(users)))
+	defer span.End()
+
+	options.TraceParent = span.Context.Traceparent()
+
+	delivery := Delivery{
+		Options:         options,
+		Space:           space,
+		Organization:    organization,
+		ClientID:        clientID,
+		UAAHost:         uaaHost,
+		Scope:           scope,
+		VCAPRequestID:   vcapRequestID,
+		RequestReceived: reqReceived,
+	}
+
+	var responses []Response
+	var err error
+	if enqueuer.batchSize > 0 && len(users) > enqueuer.batchSize {
+		responses, err = enqueuer.fanOut(conn, users, delivery, span)
+		if err != nil {
+			return responses, err
+		}
+	} else {
+		fast := enqueuer.fastLane != nil && options.Transactional && len(users) == 1
+		span.SetAttribute("fast_lane", strconv.FormatBool(fast))
+
+		responses, err = enqueuer.enqueue(conn, users, delivery, fast)
+		if err != nil {
+			span.RecordError(err)
+			return []Response{}, err
+		}
+	}
+
+	enqueuer.sample(conn, users, delivery, responses, span)
+
+	return responses, nil
+}
+
+// sample keeps the delivery to the first of the users. The notifications are
+// already queued, so failing to keep it is only recorded on the span.
+func (enqueuer Enqueuer) sample(conn ConnectionInterface, users []User, delivery Delivery, responses []Response, span *tracing.Span) {
+	if enqueuer.samples == nil || len(users) == 0 || len(responses) == 0 {
+		return
+	}
+
+	delivery.UserGUID = users[0].GUID
+	delivery.Email = users[0].Email
+	delivery.MessageID = responses[0].NotificationID
+
+	encoded, err := json.Marshal(delivery)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	span.RecordError(enqueuer.samples.Create(conn, models.PayloadSample{
+		ClientID: delivery.ClientID,
+		KindID:   delivery.Options.KindID,
+		Delivery: string(encoded),
+	}))
+}
+
+// fanOut enqueues the users in batches. Recipients in a batch that could not
+// be enqueued are reported as failed alongside those that were, unless every
+// batch failed, in which case the error of the first is returned.
+func (enqueuer Enqueuer) fanOut(conn ConnectionInterface, users []User, delivery Delivery, span *tracing.Span) ([]Response, error) {
+	// The quota is consumed for every user at once, so that a request over
+	// the quota is rejected as a whole instead of being partly sent.
+	err := enqueuer.consumeQuota(conn, delivery.ClientID, len(users), delivery.RequestReceived)
+	if err != nil {
+		span.RecordError(err)
+		return []Response{}, err
+	}
+
+	var batches [][]User
+	for start := 0; start < len(users); start += enqueuer.batchSize {
+		end := start + enqueuer.batchSize
+		if end > len(users) {
+			end = len(users)
+		}
+
+		batches = append(batches, users[start:end])
+	}
+	span.SetAttribute("batches", strconv.Itoa(len(batches)))
+
+	// The batches share the DbMap, so it is set up once before they start
+	// rather than by each of them at the same time.
+	enqueuer.gobbleInitializer.InitializeDBMap(conn.GetDbMap())
+	batcher := enqueuer
+	batcher.gobbleInitializer = initializedDBMap{}
+	batcher.quotas = consumedQuota{}
+
+	results := make([]enqueueResult, len(batches))
+	workers := make(chan struct{}, enqueuer.workers)
+	var wg sync.WaitGroup
+	for i, batch := range batches {
+		workers <- struct{}{}
+		wg.Add(1)
+
+		go func(i int, batch []User) {
+			defer wg.Done()
+			defer func() { <-workers }()
+
+			results[i].responses, results[i].err = batcher.enqueue(conn, batch, delivery, false)
+		}(i, batch)
+	}
+	wg.Wait()
+
+	var responses []Response
+	var failures []error
+	for i, result := range results {
+		if result.err == nil {
+			responses = append(responses, result.responses...)
+			continue
+		}
+
+		span.RecordError(result.err)
+		failures = append(failures, result.err)
+		metrics.GetOrRegisterCounter("notifications.enqueue.failed_batches", nil).Inc(1)
+
+		for _, user := range batches[i] {
+			responses = append(responses, Response{
+				Status:        StatusFailed,
+				Recipient:     user.recipient(),
+				VCAPRequestID: delivery.VCAPRequestID,
+				Error:         result.err.Error(),
+			})
+		}
+	}
+
+	if len(failures) == len(batches) {
+		return []Response{}, failures[0]
+	}
+
+	return responses, nil
+}
+
+type enqueueResult struct {
+	responses []Response
+	err       error
+}
+
+type initializedDBMap struct{}
+
+func (initializedDBMap) InitializeDBMap(*gorp.DbMap) {}
+
+type consumedQuota struct{}
+
+func (consumedQuota) Consume(models.ConnectionInterface, string, int, time.Time) error { return nil }
+
+func (enqueuer Enqueuer) consumeQuota(conn ConnectionInterface, clientID string, count int, at time.Time) error {
+	transaction := conn.Transaction()
+	if err := transaction.Begin(); err != nil {
+		return err
+	}
+
+	if err := enqueuer.quotas.Consume(transaction, clientID, count, at); err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	return transaction.Commit()
+}
+
+// enqueue queues a delivery to each of the users in one transaction. When
+// fast is set, the deliveries are handed to the fast lane instead.
+func (enqueuer Enqueuer) enqueue(conn ConnectionInterface, users []User, delivery Delivery, fast bool) ([]Response, error) {
 	var responses []Response
+	var fastJobs, committedJobs []*gobble.Job
 
 	transaction := conn.Transaction()
 	enqueuer.gobbleInitializer.InitializeDBMap(transaction.GetDbMap())
 
 	if err := transaction.Begin(); err != nil {
-		return []Response{}, err
+		return nil, err
+	}
+
+	// The quota is consumed in the same transaction as the deliveries, so a
+	// request that fails to enqueue does not count against it.
+	if len(users) > 0 {
+		if err := enqueuer.quotas.Consume(transaction, delivery.ClientID, len(users), delivery.RequestReceived); err != nil {
+			transaction.Rollback()
+			return nil, err
+		}
 	}
 
 	for _, user := range users {
+		recipient := user.recipient()
+
+		userDelivery := delivery
+		userDelivery.UserGUID = user.GUID
+		userDelivery.Email = user.Email
+
 		message, err := enqueuer.messagesRepo.Upsert(transaction, models.Message{
-			Status: StatusQueued,
+			Status:    StatusQueued,
+			ClientID:  delivery.ClientID,
+			Recipient: recipient,
+			Simulated: len(delivery.Options.SimulationSinks) > 0,
+			Severity:  delivery.Options.Severity,
+			Metadata:  delivery.Options.Metadata,
+			Delivery:  queuedDelivery(userDelivery),
 		})
 		if err != nil {
 			transaction.Rollback()
-			return []Response{}, err
+			return nil, err
 		}
 
-		job := gobble.NewJob(Delivery{
-			Options:         options,
-			UserGUID:        user.GUID,
-			Email:           user.Email,
-			Space:           space,
-			Organization:    organization,
-			ClientID:        clientID,
-			MessageID:       message.ID,
-			UAAHost:         uaaHost,
-			Scope:           scope,
-			VCAPRequestID:   vcapRequestID,
-			RequestReceived: reqReceived,
-		})
+		userDelivery.MessageID = message.ID
 
-		_, err = enqueuer.queue.Enqueue(job, transaction)
-		if err != nil {
-			transaction.Rollback()
-			return []Response{}, err
-		}
+		job := gobble.NewJob(userDelivery)
+		job.Priority = delivery.Options.Priority
 
-		recipient := user.Email
-		if recipient == "" {
-			recipient = user.GUID
+		if fast {
+			fastJobs = append(fastJobs, job)
+		} else if !enqueuer.queue.Transactional() {
+			committedJobs = append(committedJobs, job)
+		} else {
+			_, err = enqueuer.queue.Enqueue(job, transaction)
+			if err != nil {
+				transaction.Rollback()
+				return nil, err
+			}
 		}
 
 		responses = append(responses, Response{
 			Status:         message.Status,
 			NotificationID: message.ID,
 			Recipient:      recipient,
-			VCAPRequestID:  vcapRequestID,
+			VCAPRequestID:  delivery.VCAPRequestID,
 		})
 	}
 
 	if err := transaction.Commit(); err != nil {
-		return []Response{}, err
+		return nil, err
+	}
+
+	// The jobs are only handed over once their messages are committed, so
+	// that the worker never finds a message missing. A job the fast lane
+	// refuses is queued on its own, after the fact, as are the jobs of a
+	// queue that would not have been rolled back with the transaction.
+	for _, job := range fastJobs {
+		if enqueuer.fastLane.Submit(job) {
+			continue
+		}
+
+		committedJobs = append(committedJobs, job)
+	}
+
+	for _, job := range committedJobs {
+		enqueuer.enqueueCommitted(conn, job)
 	}
 
 	return responses, nil
 }
+
+// SlackRecipient is the recipient reported for the Slack post of a
+// notification.
+const SlackRecipient = "slack"
+
+// EnqueueSlack queues a single post of the notification to the Slack
+// channel of its kind, rather than a delivery for each user.
+func (enqueuer Enqueuer) EnqueueSlack(conn ConnectionInterface, options Options, clientID, uaaHost, vcapRequestID string, reqReceived time.Time) (Response, error) {
+	transaction := conn.Transaction()
+	enqueuer.gobbleInitializer.InitializeDBMap(transaction.GetDbMap())
+
+	if err := transaction.Begin(); err != nil {
+		return Response{}, err
+	}
+
+	if err := enqueuer.quotas.Consume(transaction, clientID, 1, reqReceived); err != nil {
+		transaction.Rollback()
+		return Response{}, err
+	}
+
+	delivery := Delivery{
+		JobType:         common.SlackJobType,
+		Options:         options,
+		ClientID:        clientID,
+		UAAHost:         uaaHost,
+		VCAPRequestID:   vcapRequestID,
+		RequestReceived: reqReceived,
+	}
+
+	message, err := enqueuer.messagesRepo.Upsert(transaction, models.Message{
+		Status:    StatusQueued,
+		ClientID:  clientID,
+		Recipient: SlackRecipient,
+		Simulated: len(options.SimulationSinks) > 0,
+		Severity:  options.Severity,
+		Metadata:  options.Metadata,
+		Delivery:  queuedDelivery(delivery),
+	})
+	if err != nil {
+		transaction.Rollback()
+		return Response{}, err
+	}
+
+	delivery.MessageID = message.ID
+
+	job := gobble.NewJob(delivery)
+	job.Priority = options.Priority
+
+	if enqueuer.queue.Transactional() {
+		_, err = enqueuer.queue.Enqueue(job, transaction)
+		if err != nil {
+			transaction.Rollback()
+			return Response{}, err
+		}
+	}
+
+	if err := transaction.Commit(); err != nil {
+		return Response{}, err
+	}
+
+	if !enqueuer.queue.Transactional() {
+		enqueuer.enqueueCommitted(conn, job)
+	}
+
+	return Response{
+		Status:         message.Status,
+		NotificationID: message.ID,
+		Recipient:      SlackRecipient,
+		VCAPRequestID:  vcapRequestID,
+	}, nil
+}
+
+// enqueueCommitted queues the job of a message that is already committed. The
+// message is reported as queued either way, so a job that cannot be queued is
+// logged and counted, and left for the queue replay to rebuild, rather than
+// failing a request whose notifications were stored.
+func (enqueuer Enqueuer) enqueueCommitted(conn ConnectionInterface, job *gobble.Job) {
+	_, err := enqueuer.queue.Enqueue(job, conn)
+	if err == nil {
+		return
+	}
+
+	metrics.GetOrRegisterCounter("notifications.enqueue.stranded_jobs", nil).Inc(1)
+
+	if enqueuer.logger != nil {
+		var delivery Delivery
+		job.Unmarshal(&delivery)
+
+		enqueuer.logger.Error("enqueue-after-commit-failed", err, lager.Data{
+			"message_id":      delivery.MessageID,
+			"vcap_request_id": delivery.VCAPRequestID,
+		})
+	}
+}
+
+// queuedDelivery encodes the delivery kept with its message while it is
+// queued. The message ID is left out, since the message has none until it
+// is stored, and is filled in again when the delivery is replayed.
+func queuedDelivery(delivery Delivery) sql.NullString {
+	encoded, err := json.Marshal(delivery)
+	if err != nil {
+		return sql.NullString{}
+	}
+
+	return sql.NullString{String: string(encoded), Valid: true}
+}
@@ -1,13 +1,20 @@
 package services_test
 
 import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
 	"errors"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/pivotal-golang/lager"
+	"gopkg.in/gorp.v1"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -24,6 +31,7 @@ var _ = Describe("Enqueuer", func() {
 		org               cf.CloudControllerOrganization
 		reqReceived       time.Time
 		messagesRepo      *mocks.MessagesRepo
+		quotas            *mocks.ClientQuotasRepo
 	)
 
 	BeforeEach(func() {
@@ -61,7 +69,9 @@ var _ = Describe("Enqueuer", func() {
 			},
 		}
 
-		enqueuer = services.NewEnqueuer(queue, messagesRepo, gobbleInitializer)
+		quotas = mocks.NewClientQuotasRepo()
+
+		enqueuer = services.NewEnqueuer(queue, messagesRepo, gobbleInitializer, quotas, nil)
 	})
 
 	Describe("Enqueue", func() {
@@ -115,6 +125,10 @@ var _ = Describe("Enqueuer", func() {
 				if err != nil {
 					panic(err)
 				}
+
+				Expect(tracing.ParseTraceparent(delivery.Options.TraceParent).IsValid()).To(BeTrue())
+				delivery.Options.TraceParent = ""
+
 				deliveries = append(deliveries, delivery)
 			}
 
@@ -171,17 +185,93 @@ var _ = Describe("Enqueuer", func() {
 			}))
 		})
 
-		It("upserts a StatusQueued for each of the jobs", func() {
-			users := []services.User{{GUID: "user-1"}, {GUID: "user-2"}, {GUID: "user-3"}, {GUID: "user-4"}}
+		It("continues the dispatch trace in the enqueued deliveries", func() {
+			parent := tracing.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+			users := []services.User{{GUID: "user-1"}, {GUID: "user-2"}}
+
+			enqueuer.Enqueue(conn, users, services.Options{TraceParent: parent.Traceparent()}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(2))
+			for _, job := range queue.EnqueueCall.Receives.Jobs {
+				var delivery services.Delivery
+				Expect(job.Unmarshal(&delivery)).To(Succeed())
+
+				traceParent := tracing.ParseTraceparent(delivery.Options.TraceParent)
+				Expect(traceParent.TraceID).To(Equal(parent.TraceID))
+				Expect(traceParent.SpanID).NotTo(Equal(parent.SpanID))
+			}
+		})
+
+		It("sets the priority of the jobs from the options", func() {
+			users := []services.User{{GUID: "user-1"}, {GUID: "user-2"}}
+
+			enqueuer.Enqueue(conn, users, services.Options{Priority: gobble.PriorityCritical}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(2))
+			for _, job := range queue.EnqueueCall.Receives.Jobs {
+				Expect(job.Priority).To(Equal(gobble.PriorityCritical))
+			}
+		})
+
+		It("upserts a StatusQueued with the client and recipient for each of the jobs", func() {
+			users := []services.User{{GUID: "user-1"}, {Email: "user-2@example.com"}, {GUID: "user-3"}, {GUID: "user-4"}}
 			enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
 
-			messages := messagesRepo.UpsertCall.Receives.Messages
+			messages := withoutDeliveries(messagesRepo.UpsertCall.Receives.Messages)
 			Expect(messages).To(HaveLen(4))
 			Expect(messages).To(Equal([]models.Message{
-				{Status: services.StatusQueued},
-				{Status: services.StatusQueued},
-				{Status: services.StatusQueued},
-				{Status: services.StatusQueued},
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: "user-1"},
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: "user-2@example.com"},
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: "user-3"},
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: "user-4"},
+			}))
+		})
+
+		It("keeps the delivery of each job with its message, without the message ID", func() {
+			users := []services.User{{GUID: "user-1"}, {Email: "user-2@example.com"}}
+			enqueuer.Enqueue(conn, users, services.Options{KindID: "the-kind"}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(messagesRepo.UpsertCall.Receives.Messages).To(HaveLen(2))
+			for i, message := range messagesRepo.UpsertCall.Receives.Messages {
+				Expect(message.Delivery.Valid).To(BeTrue())
+
+				var kept, queued services.Delivery
+				Expect(json.Unmarshal([]byte(message.Delivery.String), &kept)).To(Succeed())
+				Expect(queue.EnqueueCall.Receives.Jobs[i].Unmarshal(&queued)).To(Succeed())
+				Expect(kept.MessageID).To(BeEmpty())
+
+				kept.MessageID = queued.MessageID
+				Expect(kept).To(Equal(queued))
+			}
+		})
+
+		It("marks the messages simulated when the deliveries go to simulation sinks", func() {
+			users := []services.User{{GUID: "user-1"}}
+			options := services.Options{SimulationSinks: []string{"qa@partner.example.com"}}
+			enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(withoutDeliveries(messagesRepo.UpsertCall.Receives.Messages)).To(Equal([]models.Message{
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: "user-1", Simulated: true},
+			}))
+		})
+
+		It("keeps the severity of the notification with the messages", func() {
+			users := []services.User{{GUID: "user-1"}}
+			options := services.Options{Severity: models.SeverityWarning}
+			enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(withoutDeliveries(messagesRepo.UpsertCall.Receives.Messages)).To(Equal([]models.Message{
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: "user-1", Severity: models.SeverityWarning},
+			}))
+		})
+
+		It("keeps the metadata of the notification with the messages", func() {
+			users := []services.User{{GUID: "user-1"}}
+			options := services.Options{Metadata: map[string]string{"ticket": "INC-1234"}}
+			enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(withoutDeliveries(messagesRepo.UpsertCall.Receives.Messages)).To(Equal([]models.Message{
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: "user-1", Metadata: models.MessageMetadata{"ticket": "INC-1234"}},
 			}))
 		})
 
@@ -252,6 +342,99 @@ var _ = Describe("Enqueuer", func() {
 				enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
 			})
 
+			Context("when the queue does not take part in the transaction", func() {
+				BeforeEach(func() {
+					queue.TransactionalCall.Returns.Transactional = false
+				})
+
+				It("queues the jobs once the transaction is committed", func() {
+					queue.EnqueueCall.Hook = func() {
+						Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+					}
+
+					_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(4))
+					Expect(queue.EnqueueCall.Receives.Connection).To(Equal(conn))
+				})
+
+				It("queues nothing when the transaction is rolled back", func() {
+					messagesRepo.UpsertCall.Returns.Error = errors.New("BOOM!")
+
+					_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+					Expect(err).To(HaveOccurred())
+
+					Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+				})
+
+				It("queues nothing when the transaction cannot be committed", func() {
+					transaction.CommitCall.Returns.Error = errors.New("the commit blew up")
+
+					_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+					Expect(err).To(HaveOccurred())
+
+					Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+				})
+
+				It("still returns the responses when the jobs cannot be queued after the commit", func() {
+					buffer := bytes.NewBuffer([]byte{})
+					logger := lager.NewLogger("notifications")
+					logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+					enqueuer = enqueuer.WithLogger(logger)
+
+					queue.EnqueueCall.Returns.Error = errors.New("redis is down")
+
+					responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(responses).To(HaveLen(4))
+					Expect(responses[3]).To(Equal(services.Response{
+						Status:         "queued",
+						Recipient:      "user-4",
+						NotificationID: "fourth-random-guid",
+						VCAPRequestID:  "some-request-id",
+					}))
+
+					Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+					Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
+					Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(4))
+
+					Expect(buffer.String()).To(ContainSubstring("enqueue-after-commit-failed"))
+					Expect(buffer.String()).To(ContainSubstring("redis is down"))
+					Expect(buffer.String()).To(ContainSubstring("first-random-guid"))
+				})
+			})
+
+			It("consumes the quota of the client for each user in the transaction", func() {
+				_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(quotas.ConsumeCall.CallCount).To(Equal(1))
+				Expect(quotas.ConsumeCall.Receives.Connection).To(Equal(transaction))
+				Expect(quotas.ConsumeCall.Receives.ClientID).To(Equal("the-client"))
+				Expect(quotas.ConsumeCall.Receives.Count).To(Equal(4))
+				Expect(quotas.ConsumeCall.Receives.At).To(Equal(reqReceived))
+			})
+
+			It("does not consume the quota when there are no users", func() {
+				_, err := enqueuer.Enqueue(conn, []services.User{}, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(quotas.ConsumeCall.CallCount).To(Equal(0))
+			})
+
+			It("rolls back the transaction without queueing anything when the quota is exceeded", func() {
+				quotas.ConsumeCall.Returns.Error = models.QuotaExceededError{ClientID: "the-client", Limit: 3, Requested: 4}
+
+				responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+				Expect(err).To(Equal(models.QuotaExceededError{ClientID: "the-client", Limit: 3, Requested: 4}))
+				Expect(responses).To(Equal([]services.Response{}))
+
+				Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+			})
+
 			It("returns an empty slice of Response if transaction fails", func() {
 				transaction.CommitCall.Returns.Error = errors.New("the commit blew up")
 				responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
@@ -265,4 +448,352 @@ var _ = Describe("Enqueuer", func() {
 			})
 		})
 	})
+
+	Describe("Enqueue with a fast lane", func() {
+		var (
+			lane    *mocks.FastLane
+			users   []services.User
+			options services.Options
+		)
+
+		BeforeEach(func() {
+			lane = mocks.NewFastLane()
+			lane.SubmitCall.Returns.Accepted = true
+
+			users = []services.User{{GUID: "user-1"}}
+			options = services.Options{KindID: "password-reset", Transactional: true}
+
+			enqueuer = services.NewEnqueuer(queue, messagesRepo, gobbleInitializer, quotas, lane)
+		})
+
+		It("hands a transactional delivery to a single user to the fast lane once it is committed", func() {
+			lane.SubmitCall.Hook = func() {
+				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			}
+
+			responses, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(Equal([]services.Response{{
+				Status:         "queued",
+				Recipient:      "user-1",
+				NotificationID: "first-random-guid",
+				VCAPRequestID:  "some-request-id",
+			}}))
+
+			Expect(lane.SubmitCall.Receives.Jobs).To(HaveLen(1))
+			Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+
+			var delivery services.Delivery
+			Expect(lane.SubmitCall.Receives.Jobs[0].Unmarshal(&delivery)).To(Succeed())
+			Expect(delivery.UserGUID).To(Equal("user-1"))
+			Expect(delivery.MessageID).To(Equal("first-random-guid"))
+			Expect(delivery.Options.Transactional).To(BeTrue())
+		})
+
+		It("queues the delivery when the fast lane has no idle worker", func() {
+			lane.SubmitCall.Returns.Accepted = false
+
+			_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+			Expect(queue.EnqueueCall.Receives.Jobs[0]).To(Equal(lane.SubmitCall.Receives.Jobs[0]))
+			Expect(queue.EnqueueCall.Receives.Connection).To(Equal(conn))
+		})
+
+		It("still returns the response when the refused delivery cannot be queued", func() {
+			lane.SubmitCall.Returns.Accepted = false
+			queue.EnqueueCall.Returns.Error = errors.New("BOOM!")
+
+			responses, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(Equal([]services.Response{{
+				Status:         "queued",
+				Recipient:      "user-1",
+				NotificationID: "first-random-guid",
+				VCAPRequestID:  "some-request-id",
+			}}))
+		})
+
+		It("does not hand over anything when the transaction fails", func() {
+			transaction.CommitCall.Returns.Error = errors.New("the commit blew up")
+
+			_, err := enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).To(HaveOccurred())
+			Expect(lane.SubmitCall.Receives.Jobs).To(BeEmpty())
+		})
+
+		It("queues deliveries of kinds that are not transactional", func() {
+			options.Transactional = false
+
+			enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(lane.SubmitCall.Receives.Jobs).To(BeEmpty())
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+			Expect(queue.EnqueueCall.Receives.Connection).To(Equal(transaction))
+		})
+
+		It("queues transactional deliveries to more than one user", func() {
+			users = append(users, services.User{GUID: "user-2"})
+
+			enqueuer.Enqueue(conn, users, options, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(lane.SubmitCall.Receives.Jobs).To(BeEmpty())
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(2))
+		})
+	})
+
+	Describe("Enqueue in batches", func() {
+		var users []services.User
+
+		BeforeEach(func() {
+			users = []services.User{{GUID: "user-1"}, {GUID: "user-2"}, {GUID: "user-3"}, {Email: "user-4@example.com"}}
+			conn.GetDbMapCall.Returns.DbMap = &gorp.DbMap{}
+
+			enqueuer = services.NewEnqueuer(queue, messagesRepo, gobbleInitializer, quotas, nil).WithFanOut(2, 1)
+		})
+
+		It("enqueues each batch in its own transaction", func() {
+			responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(4))
+			for _, response := range responses {
+				Expect(response.Status).To(Equal(services.StatusQueued))
+			}
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(4))
+		})
+
+		It("consumes the quota of every user once, before the batches start", func() {
+			_, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(quotas.ConsumeCall.CallCount).To(Equal(1))
+			Expect(quotas.ConsumeCall.Receives.Connection).To(Equal(transaction))
+			Expect(quotas.ConsumeCall.Receives.ClientID).To(Equal("the-client"))
+			Expect(quotas.ConsumeCall.Receives.Count).To(Equal(4))
+			Expect(quotas.ConsumeCall.Receives.At).To(Equal(reqReceived))
+		})
+
+		It("rejects the whole request when it is over the quota", func() {
+			quotas.ConsumeCall.Returns.Error = models.QuotaExceededError{ClientID: "the-client", Limit: 3, Requested: 4}
+
+			responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).To(Equal(models.QuotaExceededError{ClientID: "the-client", Limit: 3, Requested: 4}))
+			Expect(responses).To(Equal([]services.Response{}))
+
+			Expect(quotas.ConsumeCall.CallCount).To(Equal(1))
+			Expect(messagesRepo.UpsertCall.CallCount).To(Equal(0))
+			Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		})
+
+		It("sets up the database map once for all of the batches", func() {
+			enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(conn.GetDbMapCall.WasCalled).To(BeTrue())
+			Expect(gobbleInitializer.InitializeDBMapCall.Receives.DbMap).To(BeIdenticalTo(conn.GetDbMapCall.Returns.DbMap))
+		})
+
+		It("does not batch audiences that fit in a single batch", func() {
+			enqueuer.Enqueue(conn, users[:2], services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+
+			Expect(quotas.ConsumeCall.CallCount).To(Equal(1))
+		})
+
+		It("reports the recipients of a failed batch without failing the others", func() {
+			failing := &failingMessagesRepo{messages: messagesRepo.UpsertCall.Returns.Messages, failOn: 3, err: errors.New("database is down")}
+			enqueuer = services.NewEnqueuer(queue, failing, gobbleInitializer, quotas, nil).WithFanOut(2, 1)
+
+			responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(Equal([]services.Response{
+				{
+					Status:         "queued",
+					Recipient:      "user-1",
+					NotificationID: "first-random-guid",
+					VCAPRequestID:  "some-request-id",
+				},
+				{
+					Status:         "queued",
+					Recipient:      "user-2",
+					NotificationID: "second-random-guid",
+					VCAPRequestID:  "some-request-id",
+				},
+				{
+					Status:        "failed",
+					Recipient:     "user-3",
+					VCAPRequestID: "some-request-id",
+					Error:         "database is down",
+				},
+				{
+					Status:        "failed",
+					Recipient:     "user-4@example.com",
+					VCAPRequestID: "some-request-id",
+					Error:         "database is down",
+				},
+			}))
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(2))
+		})
+
+		It("returns the error when every batch fails", func() {
+			messagesRepo.UpsertCall.Returns.Error = errors.New("database is down")
+
+			responses, err := enqueuer.Enqueue(conn, users, services.Options{}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).To(MatchError("database is down"))
+			Expect(responses).To(Equal([]services.Response{}))
+		})
+	})
+
+	Describe("Enqueue with payload samples", func() {
+		var samples *mocks.PayloadSamplesRepo
+
+		BeforeEach(func() {
+			samples = mocks.NewPayloadSamplesRepo()
+			enqueuer = enqueuer.WithPayloadSamples(samples)
+		})
+
+		It("keeps the delivery to the first recipient", func() {
+			users := []services.User{{GUID: "user-1"}, {GUID: "user-2"}}
+			_, err := enqueuer.Enqueue(conn, users, services.Options{KindID: "the-kind", Subject: "the-subject"}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(samples.CreateCall.Receives.Connection).To(Equal(conn))
+			Expect(samples.CreateCall.Receives.Sample.ClientID).To(Equal("the-client"))
+			Expect(samples.CreateCall.Receives.Sample.KindID).To(Equal("the-kind"))
+
+			var delivery services.Delivery
+			Expect(json.Unmarshal([]byte(samples.CreateCall.Receives.Sample.Delivery), &delivery)).To(Succeed())
+			Expect(delivery.MessageID).To(Equal("first-random-guid"))
+			Expect(delivery.UserGUID).To(Equal("user-1"))
+			Expect(delivery.Options.Subject).To(Equal("the-subject"))
+			Expect(delivery.Space).To(Equal(space))
+			Expect(delivery.Organization).To(Equal(org))
+		})
+
+		It("still returns the responses when the sample cannot be kept", func() {
+			samples.CreateCall.Returns.Error = errors.New("database is down")
+
+			responses, err := enqueuer.Enqueue(conn, []services.User{{GUID: "user-1"}}, services.Options{KindID: "the-kind"}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(HaveLen(1))
+		})
+
+		It("does not keep a sample when nothing was queued", func() {
+			quotas.ConsumeCall.Returns.Error = errors.New("quota exceeded")
+
+			_, err := enqueuer.Enqueue(conn, []services.User{{GUID: "user-1"}}, services.Options{KindID: "the-kind"}, space, org, "the-client", "my-uaa-host", "my.scope", "some-request-id", reqReceived)
+			Expect(err).To(HaveOccurred())
+			Expect(samples.CreateCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Describe("EnqueueSlack", func() {
+		It("queues a single Slack job for the notification", func() {
+			options := services.Options{KindID: "the-kind", Subject: "the subject", Priority: gobble.PriorityCritical}
+
+			response, err := enqueuer.EnqueueSlack(conn, options, "the-client", "my-uaa-host", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response).To(Equal(services.Response{
+				Status:         "queued",
+				Recipient:      "slack",
+				NotificationID: "first-random-guid",
+				VCAPRequestID:  "some-request-id",
+			}))
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+			job := queue.EnqueueCall.Receives.Jobs[0]
+			Expect(job.Priority).To(Equal(gobble.PriorityCritical))
+
+			var delivery services.Delivery
+			Expect(job.Unmarshal(&delivery)).To(Succeed())
+			Expect(delivery).To(Equal(services.Delivery{
+				JobType:         "slack",
+				Options:         options,
+				ClientID:        "the-client",
+				MessageID:       "first-random-guid",
+				UAAHost:         "my-uaa-host",
+				VCAPRequestID:   "some-request-id",
+				RequestReceived: reqReceived,
+			}))
+
+			Expect(messagesRepo.UpsertCall.Receives.Connection).To(Equal(transaction))
+			Expect(withoutDeliveries(messagesRepo.UpsertCall.Receives.Messages)).To(Equal([]models.Message{
+				{Status: services.StatusQueued, ClientID: "the-client", Recipient: services.SlackRecipient},
+			}))
+			Expect(queue.EnqueueCall.Receives.Connection).To(Equal(transaction))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+
+			Expect(quotas.ConsumeCall.Receives.Connection).To(Equal(transaction))
+			Expect(quotas.ConsumeCall.Receives.ClientID).To(Equal("the-client"))
+			Expect(quotas.ConsumeCall.Receives.Count).To(Equal(1))
+		})
+
+		It("rolls back the transaction when the quota is exceeded", func() {
+			quotas.ConsumeCall.Returns.Error = models.QuotaExceededError{ClientID: "the-client"}
+
+			_, err := enqueuer.EnqueueSlack(conn, services.Options{}, "the-client", "my-uaa-host", "some-request-id", reqReceived)
+			Expect(err).To(Equal(models.QuotaExceededError{ClientID: "the-client"}))
+			Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+		})
+
+		It("queues the job once the transaction is committed when the queue does not take part in it", func() {
+			queue.TransactionalCall.Returns.Transactional = false
+			queue.EnqueueCall.Hook = func() {
+				Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+			}
+
+			_, err := enqueuer.EnqueueSlack(conn, services.Options{}, "the-client", "my-uaa-host", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+			Expect(queue.EnqueueCall.Receives.Connection).To(Equal(conn))
+		})
+
+		It("still returns the response when the job cannot be queued after the commit", func() {
+			queue.TransactionalCall.Returns.Transactional = false
+			queue.EnqueueCall.Returns.Error = errors.New("redis is down")
+
+			response, err := enqueuer.EnqueueSlack(conn, services.Options{}, "the-client", "my-uaa-host", "some-request-id", reqReceived)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(response.Status).To(Equal(services.StatusQueued))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		It("rolls back the transaction when the job cannot be queued", func() {
+			queue.EnqueueCall.Returns.Error = errors.New("BOOM!")
+
+			_, err := enqueuer.EnqueueSlack(conn, services.Options{}, "the-client", "my-uaa-host", "some-request-id", reqReceived)
+			Expect(err).To(MatchError("BOOM!"))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
 })
+
+func withoutDeliveries(messages []models.Message) []models.Message {
+	var stripped []models.Message
+	for _, message := range messages {
+		message.Delivery = sql.NullString{}
+		stripped = append(stripped, message)
+	}
+
+	return stripped
+}
+
+type failingMessagesRepo struct {
+	messages []models.Message
+	calls    int
+	failOn   int
+	err      error
+}
+
+func (r *failingMessagesRepo) Upsert(conn models.ConnectionInterface, message models.Message) (models.Message, error) {
+	r.calls++
+	if r.calls == r.failOn {
+		return models.Message{}, r.err
+	}
+
+	return r.messages[r.calls-1], nil
+}
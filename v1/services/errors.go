@@ -22,6 +22,14 @@ func (e CriticalKindError) Error() string {
 	return e.Err.Error()
 }
 
+type NotOptInKindError struct {
+	Err error
+}
+
+func (e NotOptInKindError) Error() string {
+	return e.Err.Error()
+}
+
 type ClientMissingError struct {
 	Err error
 }
@@ -48,6 +56,14 @@ func CCErrorFor(err error) error {
 	return err
 }
 
+type MessageStateError struct {
+	Err error
+}
+
+func (e MessageStateError) Error() string {
+	return e.Err.Error()
+}
+
 type CCNotFoundError struct {
 	Err error
 }
@@ -69,3 +85,75 @@ type DefaultScopeError struct{}
 func (d DefaultScopeError) Error() string {
 	return "You cannot send a notification to a default scope"
 }
+
+type UnsubscribeImportError struct {
+	Err error
+}
+
+func (e UnsubscribeImportError) Error() string {
+	return e.Err.Error()
+}
+
+type TemplatePreviewError struct {
+	Err error
+}
+
+func (e TemplatePreviewError) Error() string {
+	return e.Err.Error()
+}
+
+type PreferencesImportError struct {
+	Err error
+}
+
+func (e PreferencesImportError) Error() string {
+	return e.Err.Error()
+}
+
+type PreferenceRevertError struct {
+	Err error
+}
+
+func (e PreferenceRevertError) Error() string {
+	return e.Err.Error()
+}
+
+type UnsubscribeLinkError struct {
+	Err error
+}
+
+func (e UnsubscribeLinkError) Error() string {
+	return e.Err.Error()
+}
+
+type TrackingLinkError struct {
+	Err error
+}
+
+func (e TrackingLinkError) Error() string {
+	return e.Err.Error()
+}
+
+type DSNReportError struct {
+	Err error
+}
+
+func (e DSNReportError) Error() string {
+	return e.Err.Error()
+}
+
+type TemplateBundleError struct {
+	Err error
+}
+
+func (e TemplateBundleError) Error() string {
+	return e.Err.Error()
+}
+
+type PayloadTemplateError struct {
+	Err error
+}
+
+func (e PayloadTemplateError) Error() string {
+	return e.Err.Error()
+}
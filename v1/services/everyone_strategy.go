@@ -13,9 +13,10 @@ type loadsTokens interface {
 }
 
 type EveryoneStrategy struct {
-	tokenLoader loadsTokens
-	allUsers    allUserGUIDsGetter
-	enqueuer    enqueuer
+	tokenLoader  loadsTokens
+	allUsers     allUserGUIDsGetter
+	enqueuer     enqueuer
+	suppressions suppressionsRepo
 }
 
 func NewEveryoneStrategy(tokenLoader loadsTokens, allUsers allUserGUIDsGetter, enqueuer enqueuer) EveryoneStrategy {
@@ -26,6 +27,13 @@ func NewEveryoneStrategy(tokenLoader loadsTokens, allUsers allUserGUIDsGetter, e
 	}
 }
 
+// WithUnsubscribeFiltering leaves out the users who unsubscribed, as
+// OrganizationStrategy.WithUnsubscribeFiltering does.
+func (strategy EveryoneStrategy) WithUnsubscribeFiltering(suppressions suppressionsRepo) EveryoneStrategy {
+	strategy.suppressions = suppressions
+	return strategy
+}
+
 func (strategy EveryoneStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 	var responses []Response
 
@@ -39,6 +47,13 @@ func (strategy EveryoneStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		SourceDescription: dispatch.Client.Description,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		CallbackURL:       dispatch.CallbackURL,
+		TraceParent:       dispatch.TraceParent,
+		SimulationSinks:   dispatch.Client.SimulationSinks,
+		Deadline:          dispatch.Deadline,
+		Metadata:          dispatch.Metadata,
+		Priority:          dispatch.priority(true),
+		Severity:          dispatch.Severity,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
 			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
@@ -58,12 +73,16 @@ func (strategy EveryoneStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		return responses, err
 	}
 
-	var users []User
-	for _, guid := range userGUIDs {
-		users = append(users, User{GUID: guid})
+	users, unsubscribed, err := filterUnsubscribed(strategy.suppressions, dispatch, userGUIDs)
+	if err != nil {
+		return responses, err
 	}
 
-	return strategy.enqueuer.Enqueue(
+	if len(users) == 0 && len(unsubscribed) > 0 {
+		return unsubscribed, nil
+	}
+
+	queued, err := strategy.enqueuer.Enqueue(
 		dispatch.Connection,
 		users,
 		options,
@@ -74,4 +93,9 @@ func (strategy EveryoneStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		"",
 		dispatch.VCAPRequest.ID,
 		dispatch.VCAPRequest.ReceiptTime)
+	if err != nil {
+		return queued, err
+	}
+
+	return append(queued, unsubscribed...), nil
 }
@@ -5,8 +5,10 @@ import (
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -103,6 +105,7 @@ var _ = Describe("Everyone Strategy", func() {
 						Doctype:        "<html>",
 					},
 					Endorsement: services.EveryoneEndorsement,
+					Priority:    gobble.PriorityBulk,
 				}))
 				Expect(enqueuer.EnqueueCall.Receives.Space).To(Equal(cf.CloudControllerSpace{}))
 				Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{}))
@@ -115,6 +118,103 @@ var _ = Describe("Everyone Strategy", func() {
 
 				Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("my-uaa-host"))
 			})
+
+			It("queues critical kinds ahead of other deliveries", func() {
+				_, err := strategy.Dispatch(services.Dispatch{
+					Connection: conn,
+					Kind: services.DispatchKind{
+						ID:       "outage",
+						Critical: true,
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(enqueuer.EnqueueCall.Receives.Options.Priority).To(Equal(gobble.PriorityCritical))
+			})
+
+			It("keeps notifications that require action in the bulk lane", func() {
+				_, err := strategy.Dispatch(services.Dispatch{
+					Connection: conn,
+					Severity:   models.SeverityCriticalActionRequired,
+					Kind: services.DispatchKind{
+						ID: "outage",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(enqueuer.EnqueueCall.Receives.Options.Priority).To(Equal(gobble.PriorityBulk))
+				Expect(enqueuer.EnqueueCall.Receives.Options.Severity).To(Equal(models.SeverityCriticalActionRequired))
+			})
+		})
+	})
+
+	Context("when unsubscribed users are filtered out", func() {
+		var (
+			suppressions *mocks.SuppressionsRepo
+			dispatch     services.Dispatch
+		)
+
+		BeforeEach(func() {
+			suppressions = mocks.NewSuppressionsRepo()
+			suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-319"}
+			strategy = strategy.WithUnsubscribeFiltering(suppressions)
+
+			enqueuer.EnqueueCall.Returns.Responses = []services.Response{
+				{Status: services.StatusQueued, Recipient: "user-380", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+			}
+
+			dispatch = services.Dispatch{
+				Connection: conn,
+				Kind:       services.DispatchKind{ID: "welcome_user"},
+				Client:     services.DispatchClient{ID: "mister-client"},
+				VCAPRequest: services.DispatchVCAPRequest{
+					ID: "some-vcap-request-id",
+				},
+			}
+		})
+
+		It("only enqueues the users who are still subscribed", func() {
+			responses, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(suppressions.FindUnsubscribedCall.Receives.Connection).To(Equal(conn))
+			Expect(suppressions.FindUnsubscribedCall.Receives.UserIDs).To(Equal([]string{"user-380", "user-319"}))
+			Expect(suppressions.FindUnsubscribedCall.Receives.ClientID).To(Equal("mister-client"))
+			Expect(suppressions.FindUnsubscribedCall.Receives.KindID).To(Equal("welcome_user"))
+
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-380"}}))
+			Expect(responses).To(Equal([]services.Response{
+				{Status: services.StatusQueued, Recipient: "user-380", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+				{Status: services.StatusUnsubscribed, Recipient: "user-319", VCAPRequestID: "some-vcap-request-id"},
+			}))
+		})
+
+		It("does not enqueue anything when every user unsubscribed", func() {
+			suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-380", "user-319"}
+
+			responses, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+			Expect(responses).To(HaveLen(2))
+		})
+
+		It("enqueues critical notifications for every user", func() {
+			dispatch.Kind.Critical = true
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(suppressions.FindUnsubscribedCall.WasCalled).To(BeFalse())
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-380"}, {GUID: "user-319"}}))
+		})
+
+		It("returns the error when the unsubscribes cannot be found", func() {
+			suppressions.FindUnsubscribedCall.Returns.Error = errors.New("BOOM!")
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(Equal(errors.New("BOOM!")))
+			Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
 		})
 	})
 
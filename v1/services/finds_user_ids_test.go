@@ -49,6 +49,19 @@ var _ = Describe("FindsUserIDs", func() {
 		})
 	})
 
+	Context("UserIDsBelongingToGroup", func() {
+		It("returns the userIDs of the members of the group", func() {
+			uaa.UsersGUIDsByGroupCall.Returns.UserGUIDs = []string{"user-402", "user-525"}
+
+			guids, err := finder.UserIDsBelongingToGroup("token", "raptor.keepers")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(guids).To(Equal([]string{"user-402", "user-525"}))
+
+			Expect(uaa.UsersGUIDsByGroupCall.Receives.Token).To(Equal("token"))
+			Expect(uaa.UsersGUIDsByGroupCall.Receives.Group).To(Equal("raptor.keepers"))
+		})
+	})
+
 	Context("UserIDsBelongingToSpace", func() {
 		BeforeEach(func() {
 			cc.GetUsersBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
@@ -58,7 +71,7 @@ var _ = Describe("FindsUserIDs", func() {
 		})
 
 		It("returns the user IDs for the space", func() {
-			guids, err := finder.UserIDsBelongingToSpace("space-001", "token")
+			guids, err := finder.UserIDsBelongingToSpace("space-001", "", "token")
 			Expect(err).NotTo(HaveOccurred())
 			Expect(guids).To(Equal([]string{"user-123", "user-789"}))
 
@@ -70,10 +83,94 @@ var _ = Describe("FindsUserIDs", func() {
 			It("returns the error", func() {
 				cc.GetUsersBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
 
-				_, err := finder.UserIDsBelongingToSpace("space-001", "token")
+				_, err := finder.UserIDsBelongingToSpace("space-001", "", "token")
 				Expect(err).To(MatchError(errors.New("BOOM!")))
 			})
 		})
+
+		Context("when the role is SpaceDeveloper", func() {
+			BeforeEach(func() {
+				cc.GetDevelopersBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
+					{GUID: "user-246"},
+					{GUID: "user-357"},
+				}
+			})
+
+			It("returns the users holding that role in the space", func() {
+				guids, err := finder.UserIDsBelongingToSpace("space-001", "SpaceDeveloper", "token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(guids).To(Equal([]string{"user-246", "user-357"}))
+
+				Expect(cc.GetDevelopersBySpaceGuidCall.Receives.SpaceGUID).To(Equal("space-001"))
+				Expect(cc.GetDevelopersBySpaceGuidCall.Receives.Token).To(Equal("token"))
+				Expect(cc.GetUsersBySpaceGuidCall.Receives.SpaceGUID).To(BeEmpty())
+			})
+
+			Context("when CloudController causes an error", func() {
+				It("returns the error", func() {
+					cc.GetDevelopersBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
+
+					_, err := finder.UserIDsBelongingToSpace("space-001", "SpaceDeveloper", "token")
+					Expect(err).To(MatchError(errors.New("BOOM!")))
+				})
+			})
+		})
+
+		Context("when the role is SpaceManager", func() {
+			BeforeEach(func() {
+				cc.GetManagersBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
+					{GUID: "user-468"},
+					{GUID: "user-579"},
+				}
+			})
+
+			It("returns the users holding that role in the space", func() {
+				guids, err := finder.UserIDsBelongingToSpace("space-001", "SpaceManager", "token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(guids).To(Equal([]string{"user-468", "user-579"}))
+
+				Expect(cc.GetManagersBySpaceGuidCall.Receives.SpaceGUID).To(Equal("space-001"))
+				Expect(cc.GetManagersBySpaceGuidCall.Receives.Token).To(Equal("token"))
+				Expect(cc.GetUsersBySpaceGuidCall.Receives.SpaceGUID).To(BeEmpty())
+			})
+
+			Context("when CloudController causes an error", func() {
+				It("returns the error", func() {
+					cc.GetManagersBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
+
+					_, err := finder.UserIDsBelongingToSpace("space-001", "SpaceManager", "token")
+					Expect(err).To(MatchError(errors.New("BOOM!")))
+				})
+			})
+		})
+
+		Context("when the role is SpaceAuditor", func() {
+			BeforeEach(func() {
+				cc.GetAuditorsBySpaceGuidCall.Returns.Users = []cf.CloudControllerUser{
+					{GUID: "user-680"},
+					{GUID: "user-791"},
+				}
+			})
+
+			It("returns the users holding that role in the space", func() {
+				guids, err := finder.UserIDsBelongingToSpace("space-001", "SpaceAuditor", "token")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(guids).To(Equal([]string{"user-680", "user-791"}))
+
+				Expect(cc.GetAuditorsBySpaceGuidCall.Receives.SpaceGUID).To(Equal("space-001"))
+				Expect(cc.GetAuditorsBySpaceGuidCall.Receives.Token).To(Equal("token"))
+				Expect(cc.GetUsersBySpaceGuidCall.Receives.SpaceGUID).To(BeEmpty())
+			})
+
+			Context("when CloudController causes an error", func() {
+				It("returns the error", func() {
+					cc.GetAuditorsBySpaceGuidCall.Returns.Error = errors.New("BOOM!")
+
+					_, err := finder.UserIDsBelongingToSpace("space-001", "SpaceAuditor", "token")
+					Expect(err).To(MatchError(errors.New("BOOM!")))
+				})
+			})
+		})
 	})
 
 	Context("UserIDsBelongingToOrganization", func() {
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"math"
+	"time"
+
+	"gopkg.in/gorp.v1"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+)
+
+const (
+	ReprioritizeBump  = "bump"
+	ReprioritizeDefer = "defer"
+)
+
+// pendingPageSize is how many pending jobs are read from the queue at a
+// time, so that a large backlog is never held in memory at once.
+const pendingPageSize = 500
+
+type pendingJobsQueue interface {
+	Pending(afterID, limit int) ([]gobble.Job, error)
+	Reschedule(job *gobble.Job, activeAt time.Time) error
+}
+
+type reprioritizableQueue interface {
+	pendingJobsQueue
+	OldestByPriority() (map[int]time.Time, error)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type ReprioritizeFilter struct {
+	ClientID string
+	KindID   string
+}
+
+func (f ReprioritizeFilter) matches(delivery Delivery) bool {
+	if f.ClientID != "" && f.ClientID != delivery.ClientID {
+		return false
+	}
+
+	if f.KindID != "" && f.KindID != delivery.Options.KindID {
+		return false
+	}
+
+	return true
+}
+
+type JobReprioritizer struct {
+	queue reprioritizableQueue
+	clock clock
+}
+
+func NewJobReprioritizer(queue reprioritizableQueue, clock clock) JobReprioritizer {
+	return JobReprioritizer{
+		queue: queue,
+		clock: clock,
+	}
+}
+
+// Reprioritize moves the pending jobs matching the filter either ahead of
+// every other pending job (bump) or out by the given delay (defer). Workers
+// take jobs by priority before age, so bumped jobs are raised to the highest
+// priority that has jobs waiting as well as moved ahead of the oldest of
+// them. Jobs reserved by a worker while the update runs are left alone. It
+// returns the number of jobs that were rescheduled.
+func (r JobReprioritizer) Reprioritize(filter ReprioritizeFilter, action string, delay time.Duration) (int, error) {
+	now := r.clock.Now()
+	activeAt := now.Add(delay)
+	topPriority := math.MinInt
+	if action == ReprioritizeBump {
+		oldest, err := r.queue.OldestByPriority()
+		if err != nil {
+			return 0, err
+		}
+
+		activeAt = now
+		for priority, readyAt := range oldest {
+			if readyAt.Before(activeAt) {
+				activeAt = readyAt
+			}
+
+			if priority > topPriority {
+				topPriority = priority
+			}
+		}
+		activeAt = activeAt.Add(-1 * time.Second)
+	}
+
+	count := 0
+	err := eachPendingJob(r.queue, func(job *gobble.Job) (bool, error) {
+		var delivery Delivery
+		err := job.Unmarshal(&delivery)
+		if err != nil || !filter.matches(delivery) {
+			return true, nil
+		}
+
+		if job.Priority < topPriority {
+			job.Priority = topPriority
+		}
+
+		err = r.queue.Reschedule(job, activeAt)
+		if err != nil {
+			if _, ok := err.(gorp.OptimisticLockError); ok {
+				return true, nil
+			}
+			return false, err
+		}
+
+		count++
+		return true, nil
+	})
+
+	return count, err
+}
+
+// eachPendingJob calls visit with each pending job in order of ID, reading
+// the queue a page at a time, until visit returns false or an error.
+func eachPendingJob(queue pendingJobsQueue, visit func(job *gobble.Job) (bool, error)) error {
+	afterID := 0
+	for {
+		jobs, err := queue.Pending(afterID, pendingPageSize)
+		if err != nil {
+			return err
+		}
+
+		for i := range jobs {
+			more, err := visit(&jobs[i])
+			if err != nil || !more {
+				return err
+			}
+		}
+
+		if len(jobs) < pendingPageSize {
+			return nil
+		}
+		afterID = jobs[len(jobs)-1].ID
+	}
+}
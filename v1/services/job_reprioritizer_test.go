@@ -0,0 +1,166 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"gopkg.in/gorp.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("JobReprioritizer", func() {
+	var (
+		reprioritizer services.JobReprioritizer
+		queue         *mocks.Queue
+		clock         *mocks.Clock
+		now           time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		newJob := func(id int, clientID, kindID string, activeAt time.Time) gobble.Job {
+			job := gobble.NewJob(services.Delivery{
+				ClientID: clientID,
+				Options:  services.Options{KindID: kindID},
+			})
+			job.ID = id
+			job.ActiveAt = activeAt
+			return *job
+		}
+
+		queue = mocks.NewQueue()
+		queue.PendingCall.Returns.Jobs = []gobble.Job{
+			newJob(1, "some-client", "some-kind", now.Add(-5*time.Minute)),
+			newJob(2, "some-client", "other-kind", now.Add(-1*time.Minute)),
+			newJob(3, "other-client", "some-kind", now),
+		}
+		queue.OldestByPriorityCall.Returns.Oldest = map[int]time.Time{
+			gobble.PriorityNormal: now.Add(-5 * time.Minute),
+			gobble.PriorityBulk:   now.Add(-1 * time.Minute),
+		}
+
+		reprioritizer = services.NewJobReprioritizer(queue, clock)
+	})
+
+	Describe("Reprioritize", func() {
+		It("bumps matching jobs ahead of the oldest pending job", func() {
+			count, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{ClientID: "some-client"}, services.ReprioritizeBump, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+
+			Expect(queue.RescheduleCall.Receives.Jobs).To(HaveLen(2))
+			Expect(queue.RescheduleCall.Receives.Jobs[0].ID).To(Equal(1))
+			Expect(queue.RescheduleCall.Receives.Jobs[1].ID).To(Equal(2))
+			Expect(queue.RescheduleCall.Receives.ActiveAts).To(Equal([]time.Time{
+				now.Add(-5*time.Minute - time.Second),
+				now.Add(-5*time.Minute - time.Second),
+			}))
+		})
+
+		It("raises bumped jobs to the highest priority that has jobs waiting", func() {
+			queue.OldestByPriorityCall.Returns.Oldest = map[int]time.Time{
+				gobble.PriorityNormal: now.Add(-5 * time.Minute),
+				gobble.PriorityHigh:   now.Add(-1 * time.Minute),
+			}
+			queue.PendingCall.Returns.Jobs[0].Priority = gobble.PriorityBulk
+			queue.PendingCall.Returns.Jobs[1].Priority = gobble.PriorityCritical
+
+			_, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{ClientID: "some-client"}, services.ReprioritizeBump, 0)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(queue.RescheduleCall.Receives.Jobs).To(HaveLen(2))
+			Expect(queue.RescheduleCall.Receives.Jobs[0].Priority).To(Equal(gobble.PriorityHigh))
+			Expect(queue.RescheduleCall.Receives.Jobs[1].Priority).To(Equal(gobble.PriorityCritical))
+		})
+
+		It("defers matching jobs by the given delay", func() {
+			count, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{KindID: "some-kind"}, services.ReprioritizeDefer, 10*time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+
+			Expect(queue.RescheduleCall.Receives.Jobs[0].ID).To(Equal(1))
+			Expect(queue.RescheduleCall.Receives.Jobs[1].ID).To(Equal(3))
+			Expect(queue.RescheduleCall.Receives.ActiveAts).To(Equal([]time.Time{
+				now.Add(10 * time.Minute),
+				now.Add(10 * time.Minute),
+			}))
+		})
+
+		It("matches on both client and kind when both are given", func() {
+			count, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{ClientID: "some-client", KindID: "some-kind"}, services.ReprioritizeDefer, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1))
+			Expect(queue.RescheduleCall.Receives.Jobs[0].ID).To(Equal(1))
+		})
+
+		It("skips jobs that were reserved while rescheduling", func() {
+			queue.RescheduleCall.Returns.Errors = []error{gorp.OptimisticLockError{}, nil, nil}
+
+			count, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{}, services.ReprioritizeDefer, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(2))
+		})
+
+		It("reads the pending jobs a page at a time", func() {
+			var jobs []gobble.Job
+			for id := 1; id <= 1200; id++ {
+				job := gobble.NewJob(services.Delivery{ClientID: "some-client"})
+				job.ID = id
+				jobs = append(jobs, *job)
+			}
+			queue.PendingCall.Returns.Jobs = jobs
+
+			count, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{ClientID: "some-client"}, services.ReprioritizeDefer, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(count).To(Equal(1200))
+			Expect(queue.PendingCall.Receives.AfterIDs).To(Equal([]int{0, 500, 1000}))
+			Expect(queue.PendingCall.Receives.Limit).To(Equal(500))
+		})
+
+		It("bumps jobs ahead of now when nothing older is waiting", func() {
+			queue.OldestByPriorityCall.Returns.Oldest = map[int]time.Time{}
+
+			_, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{ClientID: "some-client"}, services.ReprioritizeBump, 0)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queue.RescheduleCall.Receives.ActiveAts[0]).To(Equal(now.Add(-time.Second)))
+			Expect(queue.RescheduleCall.Receives.Jobs[0].Priority).To(Equal(gobble.PriorityNormal))
+		})
+
+		It("leaves the priority of deferred jobs alone", func() {
+			_, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{ClientID: "some-client"}, services.ReprioritizeDefer, time.Minute)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queue.RescheduleCall.Receives.Jobs[0].Priority).To(Equal(gobble.PriorityNormal))
+		})
+
+		Context("when the queue errors", func() {
+			It("returns the error from finding the oldest pending job", func() {
+				queue.OldestByPriorityCall.Returns.Error = errors.New("queue is down")
+
+				_, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{}, services.ReprioritizeBump, 0)
+				Expect(err).To(MatchError(errors.New("queue is down")))
+			})
+
+			It("returns the error from listing pending jobs", func() {
+				queue.PendingCall.Returns.Error = errors.New("queue is down")
+
+				_, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{}, services.ReprioritizeBump, 0)
+				Expect(err).To(MatchError(errors.New("queue is down")))
+			})
+
+			It("returns the error from rescheduling a job", func() {
+				queue.RescheduleCall.Returns.Errors = []error{errors.New("update failed")}
+
+				_, err := reprioritizer.Reprioritize(services.ReprioritizeFilter{}, services.ReprioritizeBump, 0)
+				Expect(err).To(MatchError(errors.New("update failed")))
+			})
+		})
+	})
+})
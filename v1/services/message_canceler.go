@@ -0,0 +1,44 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type messagesRepoUpdater interface {
+	FindByID(models.ConnectionInterface, string) (models.Message, error)
+	Update(models.ConnectionInterface, models.Message) (models.Message, error)
+}
+
+type MessageCanceler struct {
+	repo messagesRepoUpdater
+}
+
+func NewMessageCanceler(repo messagesRepoUpdater) MessageCanceler {
+	return MessageCanceler{
+		repo: repo,
+	}
+}
+
+// Cancel marks a message that has not yet been delivered as canceled. Its job
+// stays in the queue and is dropped by the worker that reserves it.
+func (c MessageCanceler) Cancel(database DatabaseInterface, messageID string) error {
+	conn := database.Connection()
+
+	message, err := c.repo.FindByID(conn, messageID)
+	if err != nil {
+		return err
+	}
+
+	switch message.Status {
+	case common.StatusDelivered, common.StatusUndeliverable, common.StatusCanceled, common.StatusDigested, common.StatusExpired, common.StatusHardBounced, common.StatusConfirmed:
+		return MessageStateError{fmt.Errorf("Message %q is %s and can no longer be canceled", messageID, message.Status)}
+	}
+
+	message.Status = common.StatusCanceled
+	_, err = c.repo.Update(conn, message)
+
+	return err
+}
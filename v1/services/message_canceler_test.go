@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MessageCanceler", func() {
+	var (
+		canceler     services.MessageCanceler
+		messagesRepo *mocks.MessagesRepo
+		database     *mocks.Database
+		conn         *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		messagesRepo = mocks.NewMessagesRepo()
+		messagesRepo.FindByIDCall.Returns.Message = models.Message{
+			ID:     "message-123",
+			Status: common.StatusQueued,
+		}
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		canceler = services.NewMessageCanceler(messagesRepo)
+	})
+
+	It("marks a queued message as canceled", func() {
+		err := canceler.Cancel(database, "message-123")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(messagesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(Equal("message-123"))
+		Expect(messagesRepo.UpdateCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(Equal([]models.Message{
+			{ID: "message-123", Status: common.StatusCanceled},
+		}))
+	})
+
+	It("cancels a failed message that is waiting to be retried", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusFailed
+
+		err := canceler.Cancel(database, "message-123")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(messagesRepo.UpdateCall.Receives.Messages[0].Status).To(Equal(common.StatusCanceled))
+	})
+
+	It("refuses to cancel a message that has been delivered", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusDelivered
+
+		err := canceler.Cancel(database, "message-123")
+		Expect(err).To(MatchError(services.MessageStateError{Err: errors.New(`Message "message-123" is delivered and can no longer be canceled`)}))
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+	})
+
+	It("refuses to cancel a message that has been collected into a digest", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusDigested
+
+		err := canceler.Cancel(database, "message-123")
+		Expect(err).To(MatchError(services.MessageStateError{Err: errors.New(`Message "message-123" is digested and can no longer be canceled`)}))
+	})
+
+	It("refuses to cancel a message that has expired", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusExpired
+
+		err := canceler.Cancel(database, "message-123")
+		Expect(err).To(MatchError(services.MessageStateError{Err: errors.New(`Message "message-123" is expired and can no longer be canceled`)}))
+	})
+
+	It("refuses to cancel a message that bounced hard", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusHardBounced
+
+		err := canceler.Cancel(database, "message-123")
+		Expect(err).To(MatchError(services.MessageStateError{Err: errors.New(`Message "message-123" is hard_bounced and can no longer be canceled`)}))
+	})
+
+	It("returns the error when the message cannot be found", func() {
+		messagesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		err := canceler.Cancel(database, "message-123")
+		Expect(err).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+})
@@ -1,9 +1,49 @@
 package services
 
-import "github.com/cloudfoundry-incubator/notifications/v1/models"
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
 
 type Message struct {
-	Status string
+	ID                 string
+	ClientID           string
+	Recipient          string
+	Simulated          bool
+	Severity           string
+	Status             string
+	Reason             string
+	SMTPCode           int
+	SMTPEnhancedStatus string
+	CreatedAt          time.Time
+	UpdatedAt          time.Time
+	WorkerID           string
+	ClaimedAt          time.Time
+	Opens              int
+	Clicks             int
+	Metadata           map[string]string
+}
+
+func newMessage(message models.Message) Message {
+	return Message{
+		ID:                 message.ID,
+		ClientID:           message.ClientID,
+		Recipient:          message.Recipient,
+		Simulated:          message.Simulated,
+		Severity:           message.Severity,
+		Status:             message.Status,
+		Reason:             message.Reason,
+		SMTPCode:           message.SMTPCode,
+		SMTPEnhancedStatus: message.SMTPEnhancedStatus,
+		CreatedAt:          message.CreatedAt,
+		UpdatedAt:          message.UpdatedAt,
+		WorkerID:           message.WorkerID,
+		ClaimedAt:          message.ClaimedAt.Time,
+		Opens:              message.Opens,
+		Clicks:             message.Clicks,
+		Metadata:           message.Metadata,
+	}
 }
 
 type messagesRepoFinder interface {
@@ -21,10 +61,10 @@ func NewMessageFinder(repo messagesRepoFinder) MessageFinder {
 }
 
 func (finder MessageFinder) Find(database DatabaseInterface, messageID string) (Message, error) {
-	message, err := finder.repo.FindByID(database.Connection(), messageID)
+	message, err := finder.repo.FindByID(database.ReadConnection(), messageID)
 	if err != nil {
 		return Message{}, err
 	}
 
-	return Message{Status: message.Status}, nil
+	return newMessage(message), nil
 }
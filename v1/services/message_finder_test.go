@@ -24,19 +24,20 @@ var _ = Describe("MessageFinder.Find", func() {
 		messagesRepo = mocks.NewMessagesRepo()
 		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
-		database.ConnectionCall.Returns.Connection = conn
+		database.ReadConnectionCall.Returns.Connection = conn
 
 		finder = services.NewMessageFinder(messagesRepo)
 	})
 
 	Context("when a message exists with the given id", func() {
 		It("returns the right Message struct", func() {
-			messagesRepo.FindByIDCall.Returns.Message = models.Message{Status: common.StatusDelivered}
+			messagesRepo.FindByIDCall.Returns.Message = models.Message{Status: common.StatusUndeliverable, Reason: common.ReasonInvalidAddress}
 
 			message, err := finder.Find(database, "a-message-id")
 
 			Expect(err).NotTo(HaveOccurred())
-			Expect(message.Status).To(Equal(common.StatusDelivered))
+			Expect(message.Status).To(Equal(common.StatusUndeliverable))
+			Expect(message.Reason).To(Equal(common.ReasonInvalidAddress))
 
 			Expect(messagesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
 			Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(Equal("a-message-id"))
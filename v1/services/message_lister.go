@@ -0,0 +1,79 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type messagesRepoLister interface {
+	List(conn models.ConnectionInterface, filter models.MessageFilter, offset, limit int) ([]models.Message, error)
+	Count(conn models.ConnectionInterface, filter models.MessageFilter) (int, error)
+}
+
+// MessageList is one page of the messages matching a filter, along with the
+// number of messages that match it on every page.
+type MessageList struct {
+	Messages []Message
+	Total    int
+}
+
+type MessageLister struct {
+	repo messagesRepoLister
+}
+
+func NewMessageLister(repo messagesRepoLister) MessageLister {
+	return MessageLister{
+		repo: repo,
+	}
+}
+
+// List returns the given page of messages, counting pages from 1.
+func (lister MessageLister) List(database DatabaseInterface, filter models.MessageFilter, page, perPage int) (MessageList, error) {
+	conn := database.ReadConnection()
+
+	total, err := lister.repo.Count(conn, filter)
+	if err != nil {
+		return MessageList{}, err
+	}
+
+	messages, err := lister.repo.List(conn, filter, (page-1)*perPage, perPage)
+	if err != nil {
+		return MessageList{}, err
+	}
+
+	list := MessageList{
+		Messages: []Message{},
+		Total:    total,
+	}
+	for _, message := range messages {
+		list.Messages = append(list.Messages, newMessage(message))
+	}
+
+	return list, nil
+}
+
+// MessageBatchSize is the number of messages Each reads from the database
+// at a time.
+const MessageBatchSize = 500
+
+// Each calls fn with every message matching the filter, newest first,
+// reading them a batch at a time so that no more than one batch is held in
+// memory. It stops at the first error fn returns.
+func (lister MessageLister) Each(database DatabaseInterface, filter models.MessageFilter, fn func(Message) error) error {
+	conn := database.ReadConnection()
+
+	for offset := 0; ; offset += MessageBatchSize {
+		messages, err := lister.repo.List(conn, filter, offset, MessageBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			err = fn(newMessage(message))
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(messages) < MessageBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MessageLister.List", func() {
+	var (
+		lister       services.MessageLister
+		messagesRepo *mocks.MessagesRepo
+		database     *mocks.Database
+		conn         *mocks.Connection
+		filter       models.MessageFilter
+	)
+
+	BeforeEach(func() {
+		messagesRepo = mocks.NewMessagesRepo()
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ReadConnectionCall.Returns.Connection = conn
+
+		filter = models.MessageFilter{ClientID: "some-client", Status: common.StatusFailed}
+		lister = services.NewMessageLister(messagesRepo)
+	})
+
+	It("returns the requested page of messages and the total", func() {
+		createdAt := time.Now().Add(-time.Hour)
+		messagesRepo.CountCall.Returns.Count = 42
+		messagesRepo.ListCall.Returns.Messages = []models.Message{
+			{ID: "message-1", ClientID: "some-client", Status: common.StatusFailed, CreatedAt: createdAt},
+		}
+
+		list, err := lister.List(database, filter, 3, 20)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(list).To(Equal(services.MessageList{
+			Messages: []services.Message{
+				{ID: "message-1", ClientID: "some-client", Status: common.StatusFailed, CreatedAt: createdAt},
+			},
+			Total: 42,
+		}))
+
+		Expect(messagesRepo.CountCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.CountCall.Receives.Filter).To(Equal(filter))
+		Expect(messagesRepo.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.ListCall.Receives.Filter).To(Equal(filter))
+		Expect(messagesRepo.ListCall.Receives.Offset).To(Equal(40))
+		Expect(messagesRepo.ListCall.Receives.Limit).To(Equal(20))
+	})
+
+	It("returns an empty page when nothing matches", func() {
+		list, err := lister.List(database, filter, 1, 20)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(list.Messages).To(BeEmpty())
+		Expect(list.Messages).NotTo(BeNil())
+	})
+
+	Context("when the repo errors", func() {
+		It("returns the count error", func() {
+			messagesRepo.CountCall.Returns.Error = errors.New("count failed")
+
+			_, err := lister.List(database, filter, 1, 20)
+			Expect(err).To(MatchError("count failed"))
+		})
+
+		It("returns the list error", func() {
+			messagesRepo.ListCall.Returns.Error = errors.New("list failed")
+
+			_, err := lister.List(database, filter, 1, 20)
+			Expect(err).To(MatchError("list failed"))
+		})
+	})
+})
+
+var _ = Describe("MessageLister.Each", func() {
+	var (
+		lister       services.MessageLister
+		messagesRepo *mocks.MessagesRepo
+		database     *mocks.Database
+		conn         *mocks.Connection
+		filter       models.MessageFilter
+	)
+
+	BeforeEach(func() {
+		messagesRepo = mocks.NewMessagesRepo()
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ReadConnectionCall.Returns.Connection = conn
+
+		filter = models.MessageFilter{ClientID: "some-client"}
+		lister = services.NewMessageLister(messagesRepo)
+	})
+
+	It("calls back with every matching message, reading them in batches", func() {
+		messagesRepo.ListCall.Returns.Messages = []models.Message{
+			{ID: "message-1", Status: common.StatusDelivered},
+			{ID: "message-2", Status: common.StatusFailed},
+		}
+
+		var ids []string
+		err := lister.Each(database, filter, func(message services.Message) error {
+			ids = append(ids, message.ID)
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ids).To(Equal([]string{"message-1", "message-2"}))
+
+		Expect(messagesRepo.ListCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.ListCall.Receives.Filter).To(Equal(filter))
+		Expect(messagesRepo.ListCall.Receives.Offset).To(Equal(0))
+		Expect(messagesRepo.ListCall.Receives.Limit).To(Equal(services.MessageBatchSize))
+	})
+
+	It("stops at the first error from the callback", func() {
+		messagesRepo.ListCall.Returns.Messages = []models.Message{{ID: "message-1"}, {ID: "message-2"}}
+
+		calls := 0
+		err := lister.Each(database, filter, func(services.Message) error {
+			calls++
+			return errors.New("client went away")
+		})
+		Expect(err).To(MatchError("client went away"))
+		Expect(calls).To(Equal(1))
+	})
+
+	It("returns the list error", func() {
+		messagesRepo.ListCall.Returns.Error = errors.New("list failed")
+
+		err := lister.Each(database, filter, func(services.Message) error { return nil })
+		Expect(err).To(MatchError("list failed"))
+	})
+})
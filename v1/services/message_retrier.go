@@ -0,0 +1,75 @@
+package services
+
+import (
+	"fmt"
+
+	"gopkg.in/gorp.v1"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+)
+
+type MessageRetrier struct {
+	repo  messagesRepoUpdater
+	queue pendingJobsQueue
+	clock clock
+}
+
+func NewMessageRetrier(repo messagesRepoUpdater, queue pendingJobsQueue, clock clock) MessageRetrier {
+	return MessageRetrier{
+		repo:  repo,
+		queue: queue,
+		clock: clock,
+	}
+}
+
+// Retry moves the job of a failed message that is waiting out its backoff to
+// the front of the queue and gives it a fresh set of retries. A message that
+// has used up its retries has left the queue and cannot be retried.
+func (r MessageRetrier) Retry(database DatabaseInterface, messageID string) error {
+	conn := database.Connection()
+
+	message, err := r.repo.FindByID(conn, messageID)
+	if err != nil {
+		return err
+	}
+
+	switch message.Status {
+	case common.StatusFailed, common.StatusTLSPolicyFailed, common.StatusUnavailable, common.StatusSoftBounced:
+	default:
+		return MessageStateError{fmt.Errorf("Message %q is %s and cannot be retried", messageID, message.Status)}
+	}
+
+	retried := false
+	err = eachPendingJob(r.queue, func(job *gobble.Job) (bool, error) {
+		var delivery Delivery
+		err := job.Unmarshal(&delivery)
+		if err != nil || delivery.MessageID != messageID {
+			return true, nil
+		}
+
+		job.RetryCount = 0
+		err = r.queue.Reschedule(job, r.clock.Now())
+		if err != nil {
+			if _, ok := err.(gorp.OptimisticLockError); ok {
+				return false, MessageStateError{fmt.Errorf("Message %q is already being retried", messageID)}
+			}
+			return false, err
+		}
+
+		retried = true
+		return false, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !retried {
+		return MessageStateError{fmt.Errorf("Message %q has no retries left and cannot be retried", messageID)}
+	}
+
+	message.Status = common.StatusQueued
+	_, err = r.repo.Update(conn, message)
+
+	return err
+}
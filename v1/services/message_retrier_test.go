@@ -0,0 +1,118 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"gopkg.in/gorp.v1"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("MessageRetrier", func() {
+	var (
+		retrier      services.MessageRetrier
+		messagesRepo *mocks.MessagesRepo
+		queue        *mocks.Queue
+		clock        *mocks.Clock
+		database     *mocks.Database
+		conn         *mocks.Connection
+		now          time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		messagesRepo = mocks.NewMessagesRepo()
+		messagesRepo.FindByIDCall.Returns.Message = models.Message{
+			ID:     "message-123",
+			Status: common.StatusFailed,
+		}
+
+		newJob := func(id int, messageID string) gobble.Job {
+			job := gobble.NewJob(services.Delivery{MessageID: messageID})
+			job.ID = id
+			job.RetryCount = 6
+			job.ActiveAt = now.Add(time.Hour)
+			return *job
+		}
+
+		queue = mocks.NewQueue()
+		queue.PendingCall.Returns.Jobs = []gobble.Job{
+			newJob(1, "message-456"),
+			newJob(2, "message-123"),
+		}
+
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		retrier = services.NewMessageRetrier(messagesRepo, queue, clock)
+	})
+
+	It("reschedules the job of the message now with a fresh set of retries", func() {
+		err := retrier.Retry(database, "message-123")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(queue.RescheduleCall.Receives.Jobs).To(HaveLen(1))
+		Expect(queue.RescheduleCall.Receives.Jobs[0].ID).To(Equal(2))
+		Expect(queue.RescheduleCall.Receives.Jobs[0].RetryCount).To(Equal(0))
+		Expect(queue.RescheduleCall.Receives.ActiveAts).To(Equal([]time.Time{now}))
+
+		Expect(messagesRepo.UpdateCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(Equal([]models.Message{
+			{ID: "message-123", Status: common.StatusQueued},
+		}))
+	})
+
+	It("refuses to retry a message that has not failed", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusQueued
+
+		err := retrier.Retry(database, "message-123")
+		Expect(err).To(MatchError(services.MessageStateError{Err: errors.New(`Message "message-123" is queued and cannot be retried`)}))
+		Expect(queue.RescheduleCall.Receives.Jobs).To(BeEmpty())
+	})
+
+	It("retries a message that bounced softly, but not one that bounced hard", func() {
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusSoftBounced
+
+		err := retrier.Retry(database, "message-123")
+		Expect(err).NotTo(HaveOccurred())
+
+		messagesRepo.FindByIDCall.Returns.Message.Status = common.StatusHardBounced
+
+		err = retrier.Retry(database, "message-123")
+		Expect(err).To(MatchError(services.MessageStateError{Err: errors.New(`Message "message-123" is hard_bounced and cannot be retried`)}))
+	})
+
+	It("refuses to retry a message that has left the queue", func() {
+		queue.PendingCall.Returns.Jobs = queue.PendingCall.Returns.Jobs[:1]
+
+		err := retrier.Retry(database, "message-123")
+		Expect(err).To(MatchError(services.MessageStateError{Err: errors.New(`Message "message-123" has no retries left and cannot be retried`)}))
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+	})
+
+	It("reports a message whose job was reserved by a worker in the meantime", func() {
+		queue.RescheduleCall.Returns.Errors = []error{gorp.OptimisticLockError{}}
+
+		err := retrier.Retry(database, "message-123")
+		Expect(err).To(BeAssignableToTypeOf(services.MessageStateError{}))
+		Expect(messagesRepo.UpdateCall.Receives.Messages).To(BeEmpty())
+	})
+
+	It("returns the error when the pending jobs cannot be loaded", func() {
+		queue.PendingCall.Returns.Error = errors.New("database is down")
+
+		err := retrier.Retry(database, "message-123")
+		Expect(err).To(MatchError("database is down"))
+	})
+})
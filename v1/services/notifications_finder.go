@@ -22,12 +22,12 @@ func (finder NotificationsFinder) AllClientsAndNotifications(database DatabaseIn
 		err           error
 	)
 
-	clients, err = finder.clientsRepo.FindAll(database.Connection())
+	clients, err = finder.clientsRepo.FindAll(database.ReadConnection())
 	if err != nil {
 		return clients, notifications, err
 	}
 
-	notifications, err = finder.kindsRepo.FindAll(database.Connection())
+	notifications, err = finder.kindsRepo.FindAll(database.ReadConnection())
 	if err != nil {
 		return clients, notifications, err
 	}
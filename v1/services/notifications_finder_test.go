@@ -168,5 +168,15 @@ var _ = Describe("NotificationsFinder", func() {
 			Expect(notifications).To(ContainElement(milleniumFalcon))
 			Expect(notifications).To(ContainElement(robots))
 		})
+		It("reads them from a replica", func() {
+			replica := mocks.NewConnection()
+			database.ReadConnectionCall.Returns.Connection = replica
+
+			_, _, err := finder.AllClientsAndNotifications(database)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(clientsRepo.FindAllCall.Receives.Connection).To(Equal(replica))
+			Expect(kindsRepo.FindAllCall.Receives.Connection).To(Equal(replica))
+		})
 	})
 })
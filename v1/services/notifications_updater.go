@@ -4,19 +4,23 @@ import "github.com/cloudfoundry-incubator/notifications/v1/models"
 
 type NotificationsUpdater struct {
 	kindsRepo KindsRepo
+	downgrade criticalDowngrader
 }
 
-func NewNotificationsUpdater(kindsRepo KindsRepo) NotificationsUpdater {
+func NewNotificationsUpdater(kindsRepo KindsRepo, downgrade criticalDowngrader) NotificationsUpdater {
 	return NotificationsUpdater{
 		kindsRepo: kindsRepo,
+		downgrade: downgrade,
 	}
 }
 
 func (updater NotificationsUpdater) Update(database DatabaseInterface, notification models.Kind) error {
-	_, err := updater.kindsRepo.Update(database.Connection(), notification)
+	connection := database.Connection()
+
+	_, err := updater.kindsRepo.Update(connection, notification)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	return updater.downgrade.Apply(connection, notification)
 }
@@ -15,6 +15,7 @@ var _ = Describe("NotificationUpdater", func() {
 	var (
 		notificationsUpdater services.NotificationsUpdater
 		kindsRepo            *mocks.KindsRepo
+		downgrade            *mocks.CriticalDowngrade
 		database             *mocks.Database
 		conn                 *mocks.Connection
 	)
@@ -25,7 +26,9 @@ var _ = Describe("NotificationUpdater", func() {
 		database = mocks.NewDatabase()
 		database.ConnectionCall.Returns.Connection = conn
 
-		notificationsUpdater = services.NewNotificationsUpdater(kindsRepo)
+		downgrade = mocks.NewCriticalDowngrade()
+
+		notificationsUpdater = services.NewNotificationsUpdater(kindsRepo, downgrade)
 	})
 
 	Describe("Update", func() {
@@ -57,6 +60,27 @@ var _ = Describe("NotificationUpdater", func() {
 			}))
 		})
 
+		It("honors parked unsubscribes when the kind is no longer critical", func() {
+			kind := models.Kind{
+				ID:       "my-current-kind-id",
+				ClientID: "my-current-client-id",
+				Critical: false,
+			}
+
+			err := notificationsUpdater.Update(database, kind)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(downgrade.ApplyCall.Receives.Connection).To(Equal(conn))
+			Expect(downgrade.ApplyCall.Receives.Kinds).To(Equal([]models.Kind{kind}))
+		})
+
+		It("propagates errors returned by the downgrade", func() {
+			downgrade.ApplyCall.Returns.Error = errors.New("Boom")
+
+			err := notificationsUpdater.Update(database, models.Kind{})
+			Expect(err).To(MatchError(errors.New("Boom")))
+		})
+
 		It("propagates errors returned by the repo", func() {
 			kindsRepo.UpdateCall.Returns.Error = errors.New("Boom")
 
@@ -0,0 +1,50 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type unsubscribeTokenParser interface {
+	Parse(token string) (userGUID, clientID, kindID string, err error)
+}
+
+// OneClickUnsubscriber acts on the List-Unsubscribe links added to delivered
+// mail. A kind that has become critical since the mail was sent cannot be
+// unsubscribed from, so the request is parked until it is downgraded again.
+type OneClickUnsubscriber struct {
+	tokens                   unsubscribeTokenParser
+	kindsRepo                KindsRepo
+	unsubscribesRepo         UnsubscribesRepo
+	criticalUnsubscribesRepo CriticalUnsubscribesRepo
+	kindActivity             kindActivityIncrementer
+	clock                    clock
+}
+
+func NewOneClickUnsubscriber(tokens unsubscribeTokenParser, kindsRepo KindsRepo, unsubscribesRepo UnsubscribesRepo, criticalUnsubscribesRepo CriticalUnsubscribesRepo, kindActivity kindActivityIncrementer, clock clock) OneClickUnsubscriber {
+	return OneClickUnsubscriber{
+		tokens:                   tokens,
+		kindsRepo:                kindsRepo,
+		unsubscribesRepo:         unsubscribesRepo,
+		criticalUnsubscribesRepo: criticalUnsubscribesRepo,
+		kindActivity:             kindActivity,
+		clock:                    clock,
+	}
+}
+
+func (u OneClickUnsubscriber) Unsubscribe(conn ConnectionInterface, token string) error {
+	userGUID, clientID, kindID, err := u.tokens.Parse(token)
+	if err != nil {
+		return UnsubscribeLinkError{err}
+	}
+
+	kind, err := u.kindsRepo.Find(conn, kindID, clientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); !ok {
+			return err
+		}
+	}
+
+	if kind.Critical {
+		return u.criticalUnsubscribesRepo.Set(conn, userGUID, clientID, kindID, true)
+	}
+
+	return setUnsubscribe(conn, u.unsubscribesRepo, u.kindActivity, u.clock.Now(), userGUID, clientID, kindID, true)
+}
@@ -0,0 +1,121 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OneClickUnsubscriber", func() {
+	var (
+		unsubscriber             services.OneClickUnsubscriber
+		tokens                   *mocks.UnsubscribeTokens
+		kindsRepo                *mocks.KindsRepo
+		unsubscribesRepo         *mocks.UnsubscribesRepo
+		criticalUnsubscribesRepo *mocks.CriticalUnsubscribesRepo
+		kindActivity             *mocks.KindActivityRepo
+		clock                    *mocks.Clock
+		conn                     *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+
+		tokens = mocks.NewUnsubscribeTokens()
+		tokens.ParseCall.Returns.UserGUID = "user-123"
+		tokens.ParseCall.Returns.ClientID = "raptors"
+		tokens.ParseCall.Returns.KindID = "feeding-time"
+
+		kindsRepo = mocks.NewKindsRepo()
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "feeding-time", ClientID: "raptors"}}
+
+		unsubscribesRepo = mocks.NewUnsubscribesRepo()
+		criticalUnsubscribesRepo = mocks.NewCriticalUnsubscribesRepo()
+		kindActivity = mocks.NewKindActivityRepo()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2015, time.June, 8, 14, 0, 0, 0, time.UTC)
+
+		unsubscriber = services.NewOneClickUnsubscriber(tokens, kindsRepo, unsubscribesRepo, criticalUnsubscribesRepo, kindActivity, clock)
+	})
+
+	It("unsubscribes the user named in the token from the kind", func() {
+		err := unsubscriber.Unsubscribe(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(tokens.ParseCall.Receives.Token).To(Equal("some-token"))
+		Expect(kindsRepo.FindCall.Receives.KindID).To(Equal("feeding-time"))
+		Expect(kindsRepo.FindCall.Receives.ClientID).To(Equal("raptors"))
+
+		Expect(unsubscribesRepo.SetCall.Receives.Connection).To(Equal(conn))
+		Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+		Expect(unsubscribesRepo.SetCall.Receives.ClientID).To(Equal("raptors"))
+		Expect(unsubscribesRepo.SetCall.Receives.KindID).To(Equal("feeding-time"))
+		Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+	})
+
+	It("counts the unsubscribe towards the kind activity", func() {
+		err := unsubscriber.Unsubscribe(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(kindActivity.IncrementCall.Receives.Connection).To(Equal(conn))
+		Expect(kindActivity.IncrementCall.Receives.ClientID).To(Equal("raptors"))
+		Expect(kindActivity.IncrementCall.Receives.KindID).To(Equal("feeding-time"))
+		Expect(kindActivity.IncrementCall.Receives.Counter).To(Equal(models.KindActivityUnsubscribed))
+		Expect(kindActivity.IncrementCall.Receives.At).To(Equal(time.Date(2015, time.June, 8, 14, 0, 0, 0, time.UTC)))
+	})
+
+	It("does not count a user who was already unsubscribed", func() {
+		unsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+		err := unsubscriber.Unsubscribe(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(kindActivity.IncrementCall.CallCount).To(Equal(0))
+	})
+
+	It("unsubscribes from kinds that are not registered", func() {
+		kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		err := unsubscriber.Unsubscribe(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+	})
+
+	It("parks the unsubscribe when the kind is critical", func() {
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "feeding-time", ClientID: "raptors", Critical: true}}
+
+		err := unsubscriber.Unsubscribe(conn, "some-token")
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(criticalUnsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+		Expect(criticalUnsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+		Expect(unsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+		Expect(kindActivity.IncrementCall.CallCount).To(Equal(0))
+	})
+
+	Context("when the token is invalid", func() {
+		It("returns an unsubscribe link error", func() {
+			tokens.ParseCall.Returns.Error = errors.New("The unsubscribe link is invalid")
+
+			err := unsubscriber.Unsubscribe(conn, "some-token")
+			Expect(err).To(MatchError(services.UnsubscribeLinkError{Err: errors.New("The unsubscribe link is invalid")}))
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+		})
+	})
+
+	Context("when the kind cannot be loaded", func() {
+		It("returns the error", func() {
+			kindsRepo.FindCall.Returns.Error = errors.New("database is down")
+
+			err := unsubscriber.Unsubscribe(conn, "some-token")
+			Expect(err).To(MatchError(errors.New("database is down")))
+		})
+	})
+})
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/rcrowley/go-metrics"
+)
+
+const OrganizationAuditSubjectPrefix = "Audit: "
+
+type organizationPolicyFinder interface {
+	Find(conn models.ConnectionInterface, orgGUID string) (models.OrganizationPolicy, error)
+}
+
+// OrganizationAuditStrategy wraps a strategy that targets an organization.
+// After a critical notification is dispatched to an organization whose
+// policy asks for it, the organization managers are sent a summary of who
+// was notified and why.
+type OrganizationAuditStrategy struct {
+	strategy dispatcher
+	managers dispatcher
+	policies organizationPolicyFinder
+}
+
+func NewOrganizationAuditStrategy(strategy, managers dispatcher, policies organizationPolicyFinder) OrganizationAuditStrategy {
+	return OrganizationAuditStrategy{
+		strategy: strategy,
+		managers: managers,
+		policies: policies,
+	}
+}
+
+func (strategy OrganizationAuditStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	responses, err := strategy.strategy.Dispatch(dispatch)
+	if err != nil || !dispatch.Kind.Critical {
+		return responses, err
+	}
+
+	// The original notifications are already queued, so a failed audit must
+	// not fail the request and invite the client to send them again.
+	err = strategy.audit(dispatch, responses)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.audit.failed", nil).Inc(1)
+	}
+
+	return responses, nil
+}
+
+func (strategy OrganizationAuditStrategy) audit(dispatch Dispatch, responses []Response) error {
+	policy, err := strategy.policies.Find(dispatch.Connection, dispatch.GUID)
+	if err != nil {
+		return err
+	}
+
+	if !policy.AuditCriticalSends {
+		return nil
+	}
+
+	summary := dispatch
+	summary.Role = ""
+	summary.CallbackURL = ""
+	summary.Message = DispatchMessage{
+		Subject: OrganizationAuditSubjectPrefix + dispatch.Message.Subject,
+		Text:    auditSummary(dispatch, responses),
+	}
+
+	_, err = strategy.managers.Dispatch(summary)
+	return err
+}
+
+func auditSummary(dispatch Dispatch, responses []Response) string {
+	text := bytes.NewBuffer([]byte{})
+
+	fmt.Fprintln(text, "A critical notification was sent to members of your organization.")
+	fmt.Fprintln(text)
+	fmt.Fprintf(text, "Sent by: %s (%s)\n", dispatch.Client.Description, dispatch.Client.ID)
+	fmt.Fprintf(text, "Notification: %s (%s)\n", dispatch.Kind.Description, dispatch.Kind.ID)
+	if dispatch.Role != "" {
+		fmt.Fprintf(text, "Role: %s\n", dispatch.Role)
+	}
+	fmt.Fprintf(text, "Subject: %s\n", dispatch.Message.Subject)
+	fmt.Fprintln(text)
+	fmt.Fprintf(text, "Recipients (%d):\n", len(responses))
+	for _, response := range responses {
+		fmt.Fprintf(text, "  %s (%s)\n", response.Recipient, response.Status)
+	}
+
+	return text.String()
+}
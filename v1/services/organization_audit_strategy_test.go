@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrganizationAuditStrategy", func() {
+	var (
+		strategy             services.OrganizationAuditStrategy
+		organizationStrategy *mocks.Strategy
+		managerStrategy      *mocks.Strategy
+		policiesRepo         *mocks.OrganizationPoliciesRepo
+		conn                 *mocks.Connection
+		dispatch             services.Dispatch
+		responses            []services.Response
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		responses = []services.Response{
+			{Status: "queued", Recipient: "user-123", NotificationID: "message-1"},
+			{Status: "queued", Recipient: "user-456", NotificationID: "message-2"},
+		}
+
+		organizationStrategy = mocks.NewStrategy()
+		organizationStrategy.DispatchCalls = []mocks.StrategyDispatchCall{
+			mocks.NewStrategyDispatchCall(responses, nil),
+		}
+		managerStrategy = mocks.NewStrategy()
+
+		policiesRepo = mocks.NewOrganizationPoliciesRepo()
+		policiesRepo.FindCall.Returns.Policy = models.OrganizationPolicy{
+			OrganizationGUID:   "org-001",
+			AuditCriticalSends: true,
+		}
+
+		dispatch = services.Dispatch{
+			GUID:        "org-001",
+			Role:        "OrgAuditor",
+			Connection:  conn,
+			TemplateID:  "some-template-id",
+			CallbackURL: "https://example.com/callback",
+			Client: services.DispatchClient{
+				ID:          "health-monitor",
+				Description: "Health Monitor",
+			},
+			Kind: services.DispatchKind{
+				ID:          "instance-down",
+				Description: "Instance Down",
+				Critical:    true,
+			},
+			Message: services.DispatchMessage{
+				Subject: "Your instance is down",
+				Text:    "some text",
+				ReplyTo: "reply@example.com",
+			},
+		}
+
+		strategy = services.NewOrganizationAuditStrategy(organizationStrategy, managerStrategy, policiesRepo)
+	})
+
+	It("returns the responses of the wrapped strategy", func() {
+		actual, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(actual).To(Equal(responses))
+		Expect(organizationStrategy.DispatchCalls[0].Receives.Dispatch).To(Equal(dispatch))
+	})
+
+	It("sends a summary of critical sends to the organization managers", func() {
+		_, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(policiesRepo.FindCall.Receives.Connection).To(Equal(conn))
+		Expect(policiesRepo.FindCall.Receives.OrganizationGUID).To(Equal("org-001"))
+
+		Expect(managerStrategy.DispatchCallsCount).To(Equal(1))
+		summary := managerStrategy.DispatchCalls[0].Receives.Dispatch
+		Expect(summary.GUID).To(Equal("org-001"))
+		Expect(summary.Role).To(BeEmpty())
+		Expect(summary.CallbackURL).To(BeEmpty())
+		Expect(summary.Client).To(Equal(dispatch.Client))
+		Expect(summary.Kind).To(Equal(dispatch.Kind))
+		Expect(summary.TemplateID).To(Equal("some-template-id"))
+		Expect(summary.Message.Subject).To(Equal("Audit: Your instance is down"))
+		Expect(summary.Message.ReplyTo).To(BeEmpty())
+		Expect(summary.Message.Text).To(Equal(`A critical notification was sent to members of your organization.
+
+Sent by: Health Monitor (health-monitor)
+Notification: Instance Down (instance-down)
+Role: OrgAuditor
+Subject: Your instance is down
+
+Recipients (2):
+  user-123 (queued)
+  user-456 (queued)
+`))
+	})
+
+	Context("when the notification is not critical", func() {
+		It("does not send a summary", func() {
+			dispatch.Kind.Critical = false
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(managerStrategy.DispatchCallsCount).To(Equal(0))
+		})
+	})
+
+	Context("when the organization does not audit critical sends", func() {
+		It("does not send a summary", func() {
+			policiesRepo.FindCall.Returns.Policy = models.OrganizationPolicy{OrganizationGUID: "org-001"}
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(managerStrategy.DispatchCallsCount).To(Equal(0))
+		})
+	})
+
+	Context("when the wrapped strategy errors", func() {
+		It("returns the error without sending a summary", func() {
+			organizationStrategy.DispatchCalls[0].Returns.Error = errors.New("cc is down")
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError(errors.New("cc is down")))
+			Expect(managerStrategy.DispatchCallsCount).To(Equal(0))
+		})
+	})
+
+	Context("when the audit fails", func() {
+		It("still returns the responses of the wrapped strategy", func() {
+			policiesRepo.FindCall.Returns.Error = errors.New("database is down")
+
+			actual, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(actual).To(Equal(responses))
+		})
+	})
+})
@@ -0,0 +1,48 @@
+package services
+
+const (
+	OrganizationManagerEndorsement        = `You received this message because you are a manager of the "{{.Organization}}" organization.`
+	OrganizationAuditorEndorsement        = `You received this message because you are an auditor of the "{{.Organization}}" organization.`
+	OrganizationBillingManagerEndorsement = `You received this message because you are a billing manager of the "{{.Organization}}" organization.`
+)
+
+type OrganizationRoleStrategy struct {
+	organizationStrategy OrganizationStrategy
+	role                 string
+	endorsement          string
+}
+
+func NewOrganizationRoleStrategy(tokenLoader loadsTokens, organizationLoader loadsOrganizations, findsUserIDs orgUserIDFinder, queue enqueuer, role, endorsement string) OrganizationRoleStrategy {
+	return OrganizationRoleStrategy{
+		organizationStrategy: NewOrganizationStrategy(tokenLoader, organizationLoader, findsUserIDs, queue),
+		role:                 role,
+		endorsement:          endorsement,
+	}
+}
+
+func NewOrganizationManagerStrategy(tokenLoader loadsTokens, organizationLoader loadsOrganizations, findsUserIDs orgUserIDFinder, queue enqueuer) OrganizationRoleStrategy {
+	return NewOrganizationRoleStrategy(tokenLoader, organizationLoader, findsUserIDs, queue, "OrgManager", OrganizationManagerEndorsement)
+}
+
+func NewOrganizationAuditorStrategy(tokenLoader loadsTokens, organizationLoader loadsOrganizations, findsUserIDs orgUserIDFinder, queue enqueuer) OrganizationRoleStrategy {
+	return NewOrganizationRoleStrategy(tokenLoader, organizationLoader, findsUserIDs, queue, "OrgAuditor", OrganizationAuditorEndorsement)
+}
+
+func NewOrganizationBillingManagerStrategy(tokenLoader loadsTokens, organizationLoader loadsOrganizations, findsUserIDs orgUserIDFinder, queue enqueuer) OrganizationRoleStrategy {
+	return NewOrganizationRoleStrategy(tokenLoader, organizationLoader, findsUserIDs, queue, "BillingManager", OrganizationBillingManagerEndorsement)
+}
+
+// WithUnsubscribeFiltering leaves out the role holders who unsubscribed, as
+// OrganizationStrategy.WithUnsubscribeFiltering does.
+func (strategy OrganizationRoleStrategy) WithUnsubscribeFiltering(suppressions suppressionsRepo) OrganizationRoleStrategy {
+	strategy.organizationStrategy = strategy.organizationStrategy.WithUnsubscribeFiltering(suppressions)
+	return strategy
+}
+
+// Dispatch sends the message to the users holding the strategy's role in the
+// organization, regardless of any role given in the request.
+func (strategy OrganizationRoleStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	dispatch.Role = strategy.role
+
+	return strategy.organizationStrategy.dispatch(dispatch, strategy.endorsement)
+}
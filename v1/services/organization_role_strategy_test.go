@@ -0,0 +1,145 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("OrganizationRoleStrategy", func() {
+	var (
+		tokenLoader        *mocks.TokenLoader
+		organizationLoader *mocks.OrganizationLoader
+		enqueuer           *mocks.Enqueuer
+		conn               *mocks.Connection
+		findsUserIDs       *mocks.FindsUserIDs
+		dispatch           services.Dispatch
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "some-token"
+		enqueuer = mocks.NewEnqueuer()
+
+		findsUserIDs = mocks.NewFindsUserIDs()
+		findsUserIDs.UserIDsBelongingToOrganizationCall.Returns.UserIDs = []string{"user-123", "user-456"}
+
+		organizationLoader = mocks.NewOrganizationLoader()
+		organizationLoader.LoadCall.Returns.Organizations = []cf.CloudControllerOrganization{
+			{
+				Name: "my-org",
+				GUID: "org-001",
+			},
+		}
+
+		dispatch = services.Dispatch{
+			GUID:       "org-001",
+			Connection: conn,
+			UAAHost:    "testzone1",
+			Message: services.DispatchMessage{
+				Subject: "this is the subject",
+				Text:    "some text",
+			},
+			Kind: services.DispatchKind{
+				ID:          "forgot_password",
+				Description: "Password reminder",
+			},
+			Client: services.DispatchClient{
+				ID:          "mister-client",
+				Description: "Login system",
+			},
+		}
+	})
+
+	Context("when targeting managers", func() {
+		It("enqueues the message for the organization managers", func() {
+			strategy := services.NewOrganizationManagerStrategy(tokenLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(findsUserIDs.UserIDsBelongingToOrganizationCall.Receives.OrgGUID).To(Equal("org-001"))
+			Expect(findsUserIDs.UserIDsBelongingToOrganizationCall.Receives.Role).To(Equal("OrgManager"))
+			Expect(findsUserIDs.UserIDsBelongingToOrganizationCall.Receives.Token).To(Equal("some-token"))
+
+			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(conn))
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{
+				{GUID: "user-123"},
+				{GUID: "user-456"},
+			}))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Role).To(Equal("OrgManager"))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Endorsement).To(Equal(services.OrganizationManagerEndorsement))
+			Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{
+				Name: "my-org",
+				GUID: "org-001",
+			}))
+			Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("mister-client"))
+		})
+	})
+
+	Context("when targeting auditors", func() {
+		It("enqueues the message for the organization auditors", func() {
+			strategy := services.NewOrganizationAuditorStrategy(tokenLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(findsUserIDs.UserIDsBelongingToOrganizationCall.Receives.Role).To(Equal("OrgAuditor"))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Role).To(Equal("OrgAuditor"))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Endorsement).To(Equal(services.OrganizationAuditorEndorsement))
+		})
+	})
+
+	Context("when targeting billing managers", func() {
+		It("enqueues the message for the organization billing managers", func() {
+			strategy := services.NewOrganizationBillingManagerStrategy(tokenLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(findsUserIDs.UserIDsBelongingToOrganizationCall.Receives.Role).To(Equal("BillingManager"))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Role).To(Equal("BillingManager"))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Endorsement).To(Equal(services.OrganizationBillingManagerEndorsement))
+		})
+	})
+
+	It("ignores any role given in the dispatch", func() {
+		dispatch.Role = "OrgAuditor"
+		strategy := services.NewOrganizationManagerStrategy(tokenLoader, organizationLoader, findsUserIDs, enqueuer)
+
+		_, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(findsUserIDs.UserIDsBelongingToOrganizationCall.Receives.Role).To(Equal("OrgManager"))
+	})
+
+	It("leaves out the role holders who unsubscribed", func() {
+		suppressions := mocks.NewSuppressionsRepo()
+		suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-123"}
+		strategy := services.NewOrganizationAuditorStrategy(tokenLoader, organizationLoader, findsUserIDs, enqueuer).WithUnsubscribeFiltering(suppressions)
+
+		responses, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(suppressions.FindUnsubscribedCall.Receives.UserIDs).To(Equal([]string{"user-123", "user-456"}))
+		Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-456"}}))
+		Expect(responses).To(ContainElement(services.Response{Status: services.StatusUnsubscribed, Recipient: "user-123"}))
+	})
+
+	Context("when the users cannot be found", func() {
+		It("returns the error", func() {
+			findsUserIDs.UserIDsBelongingToOrganizationCall.Returns.Error = errors.New("cc is down")
+			strategy := services.NewOrganizationAuditorStrategy(tokenLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError(errors.New("cc is down")))
+			Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+		})
+	})
+})
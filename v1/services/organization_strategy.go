@@ -20,6 +20,7 @@ type OrganizationStrategy struct {
 	organizationLoader loadsOrganizations
 	findsUserIDs       orgUserIDFinder
 	enqueuer           enqueuer
+	suppressions       suppressionsRepo
 }
 
 func NewOrganizationStrategy(tokenLoader loadsTokens, organizationLoader loadsOrganizations, findsUserIDs orgUserIDFinder, queue enqueuer) OrganizationStrategy {
@@ -31,7 +32,24 @@ func NewOrganizationStrategy(tokenLoader loadsTokens, organizationLoader loadsOr
 	}
 }
 
+// WithUnsubscribeFiltering leaves out the members who unsubscribed from the
+// kind, or from everything, rather than queueing jobs the workers would
+// discard. They are reported with the status "unsubscribed".
+func (strategy OrganizationStrategy) WithUnsubscribeFiltering(suppressions suppressionsRepo) OrganizationStrategy {
+	strategy.suppressions = suppressions
+	return strategy
+}
+
 func (strategy OrganizationStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	endorsement := OrganizationEndorsement
+	if dispatch.Role != "" {
+		endorsement = OrganizationRoleEndorsement
+	}
+
+	return strategy.dispatch(dispatch, endorsement)
+}
+
+func (strategy OrganizationStrategy) dispatch(dispatch Dispatch, endorsement string) ([]Response, error) {
 	responses := []Response{}
 	options := Options{
 		To:                dispatch.Message.To,
@@ -40,9 +58,16 @@ func (strategy OrganizationStrategy) Dispatch(dispatch Dispatch) ([]Response, er
 		KindID:            dispatch.Kind.ID,
 		KindDescription:   dispatch.Kind.Description,
 		SourceDescription: dispatch.Client.Description,
-		Endorsement:       OrganizationEndorsement,
+		Endorsement:       endorsement,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		CallbackURL:       dispatch.CallbackURL,
+		TraceParent:       dispatch.TraceParent,
+		SimulationSinks:   dispatch.Client.SimulationSinks,
+		Deadline:          dispatch.Deadline,
+		Metadata:          dispatch.Metadata,
+		Priority:          dispatch.priority(false),
+		Severity:          dispatch.Severity,
 		Role:              dispatch.Role,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
@@ -52,10 +77,6 @@ func (strategy OrganizationStrategy) Dispatch(dispatch Dispatch) ([]Response, er
 		},
 	}
 
-	if dispatch.Role != "" {
-		options.Endorsement = OrganizationRoleEndorsement
-	}
-
 	token, err := strategy.tokenLoader.Load(dispatch.UAAHost)
 	if err != nil {
 		return responses, err
@@ -71,12 +92,16 @@ func (strategy OrganizationStrategy) Dispatch(dispatch Dispatch) ([]Response, er
 		return responses, err
 	}
 
-	var users []User
-	for _, guid := range userGUIDs {
-		users = append(users, User{GUID: guid})
+	users, unsubscribed, err := filterUnsubscribed(strategy.suppressions, dispatch, userGUIDs)
+	if err != nil {
+		return responses, err
+	}
+
+	if len(users) == 0 && len(unsubscribed) > 0 {
+		return unsubscribed, nil
 	}
 
-	return strategy.enqueuer.Enqueue(
+	queued, err := strategy.enqueuer.Enqueue(
 		dispatch.Connection,
 		users,
 		options,
@@ -87,4 +112,9 @@ func (strategy OrganizationStrategy) Dispatch(dispatch Dispatch) ([]Response, er
 		"",
 		dispatch.VCAPRequest.ID,
 		dispatch.VCAPRequest.ReceiptTime)
+	if err != nil {
+		return queued, err
+	}
+
+	return append(queued, unsubscribed...), nil
 }
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"strings"
+	"text/template"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+)
+
+// The kind of resource named by the GUID of a dispatch, which is where the
+// space and organization of a payload come from when no app is given.
+const (
+	PayloadTargetSpace        = "space"
+	PayloadTargetOrganization = "organization"
+)
+
+type loadsApps interface {
+	Load(appGUID, token string) (cf.CloudControllerApp, error)
+}
+
+// PayloadMetadataStrategy wraps a strategy, rendering the subject, text and
+// HTML of each payload as a template that can refer to the {{.Space}},
+// {{.Organization}} and {{.App}} it is about, as loaded from the cloud
+// controller. Payloads that contain no template actions are dispatched as
+// they are, without asking the cloud controller for anything.
+type PayloadMetadataStrategy struct {
+	strategy           dispatcher
+	target             string
+	tokenLoader        loadsTokens
+	spaceLoader        loadsSpaces
+	organizationLoader loadsOrganizations
+	appLoader          loadsApps
+}
+
+func NewPayloadMetadataStrategy(strategy dispatcher, target string, tokenLoader loadsTokens, spaceLoader loadsSpaces, organizationLoader loadsOrganizations, appLoader loadsApps) PayloadMetadataStrategy {
+	return PayloadMetadataStrategy{
+		strategy:           strategy,
+		target:             target,
+		tokenLoader:        tokenLoader,
+		spaceLoader:        spaceLoader,
+		organizationLoader: organizationLoader,
+		appLoader:          appLoader,
+	}
+}
+
+func (strategy PayloadMetadataStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	message := dispatch.Message
+	if !hasActions(message.Subject) && !hasActions(message.Text) && !hasActions(message.HTML.BodyContent) {
+		return strategy.strategy.Dispatch(dispatch)
+	}
+
+	metadata, err := strategy.load(dispatch)
+	if err != nil {
+		return []Response{}, err
+	}
+
+	escaped := map[string]interface{}{}
+	for key, value := range metadata {
+		escaped[key] = escapeMetadata(value)
+	}
+
+	for _, field := range []struct {
+		name  string
+		value *string
+		data  map[string]interface{}
+	}{
+		{"subject", &dispatch.Message.Subject, metadata},
+		{"text", &dispatch.Message.Text, metadata},
+		{"html", &dispatch.Message.HTML.BodyContent, escaped},
+	} {
+		*field.value, err = renderPayload(*field.value, field.data)
+		if err != nil {
+			return []Response{}, PayloadTemplateError{fmt.Errorf("the %s of the notification could not be rendered: %s", field.name, err)}
+		}
+	}
+
+	return strategy.strategy.Dispatch(dispatch)
+}
+
+// load returns the app, space and organization the dispatch is about. Only
+// the ones that can be found are included, so that a payload referring to
+// one that is not fails to render rather than rendering blank.
+func (strategy PayloadMetadataStrategy) load(dispatch Dispatch) (map[string]interface{}, error) {
+	metadata := map[string]interface{}{}
+
+	var spaceGUID, orgGUID string
+	switch strategy.target {
+	case PayloadTargetSpace:
+		spaceGUID = dispatch.GUID
+	case PayloadTargetOrganization:
+		orgGUID = dispatch.GUID
+	}
+
+	if spaceGUID == "" && orgGUID == "" && dispatch.AppGUID == "" {
+		return metadata, nil
+	}
+
+	token, err := strategy.tokenLoader.Load(dispatch.UAAHost)
+	if err != nil {
+		return nil, err
+	}
+
+	if dispatch.AppGUID != "" {
+		app, err := strategy.appLoader.Load(dispatch.AppGUID, token)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata["App"] = app
+		spaceGUID = app.SpaceGUID
+	}
+
+	if spaceGUID != "" {
+		space, err := strategy.spaceLoader.Load(spaceGUID, token)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata["Space"] = space
+		orgGUID = space.OrganizationGUID
+	}
+
+	if orgGUID != "" {
+		organization, err := strategy.organizationLoader.Load(orgGUID, token)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata["Organization"] = organization
+	}
+
+	return metadata, nil
+}
+
+func hasActions(payload string) bool {
+	return strings.Contains(payload, "{{")
+}
+
+func renderPayload(payload string, data map[string]interface{}) (string, error) {
+	if !hasActions(payload) {
+		return payload, nil
+	}
+
+	source, err := template.New("payload").Option("missingkey=error").Parse(payload)
+	if err != nil {
+		return "", err
+	}
+
+	buffer := bytes.NewBuffer([]byte{})
+	err = source.Execute(buffer, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buffer.String(), nil
+}
+
+func escapeMetadata(value interface{}) interface{} {
+	switch v := value.(type) {
+	case cf.CloudControllerApp:
+		v.Name = html.EscapeString(v.Name)
+		return v
+	case cf.CloudControllerSpace:
+		v.Name = html.EscapeString(v.Name)
+		return v
+	case cf.CloudControllerOrganization:
+		v.Name = html.EscapeString(v.Name)
+		return v
+	}
+
+	return value
+}
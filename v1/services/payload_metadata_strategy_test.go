@@ -0,0 +1,152 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PayloadMetadataStrategy", func() {
+	var (
+		innerStrategy      *mocks.Strategy
+		tokenLoader        *mocks.TokenLoader
+		spaceLoader        *mocks.SpaceLoader
+		organizationLoader *mocks.OrganizationLoader
+		appLoader          *mocks.AppLoader
+		dispatch           services.Dispatch
+		responses          []services.Response
+	)
+
+	BeforeEach(func() {
+		responses = []services.Response{{Status: "queued", Recipient: "user-123", NotificationID: "message-1"}}
+		innerStrategy = mocks.NewStrategy()
+		innerStrategy.DispatchCalls = []mocks.StrategyDispatchCall{
+			mocks.NewStrategyDispatchCall(responses, nil),
+		}
+
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "some-token"
+
+		spaceLoader = mocks.NewSpaceLoader()
+		spaceLoader.LoadCall.Returns.Spaces = []cf.CloudControllerSpace{
+			{GUID: "space-001", Name: "production", OrganizationGUID: "org-001"},
+		}
+
+		organizationLoader = mocks.NewOrganizationLoader()
+		organizationLoader.LoadCall.Returns.Organizations = []cf.CloudControllerOrganization{
+			{GUID: "org-001", Name: "R&D"},
+		}
+
+		appLoader = mocks.NewAppLoader()
+		appLoader.LoadCall.Returns.App = cf.CloudControllerApp{GUID: "app-001", Name: "billing", SpaceGUID: "space-001"}
+
+		dispatch = services.Dispatch{
+			GUID:    "space-001",
+			UAAHost: "uaa",
+			Message: services.DispatchMessage{
+				Subject: "{{.App.Name}} crashed",
+				Text:    "{{.App.Name}} in {{.Space.Name}} of {{.Organization.Name}} crashed",
+				HTML: services.HTML{
+					BodyContent: "<p>{{.App.Name}} in <b>{{.Organization.Name}}</b> crashed</p>",
+				},
+			},
+			AppGUID: "app-001",
+		}
+	})
+
+	newStrategy := func(target string) services.PayloadMetadataStrategy {
+		return services.NewPayloadMetadataStrategy(innerStrategy, target, tokenLoader, spaceLoader, organizationLoader, appLoader)
+	}
+
+	It("renders the payload with the app, and the space and organization it belongs to", func() {
+		result, err := newStrategy("").Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(responses))
+
+		message := innerStrategy.DispatchCalls[0].Receives.Dispatch.Message
+		Expect(message.Subject).To(Equal("billing crashed"))
+		Expect(message.Text).To(Equal("billing in production of R&D crashed"))
+		Expect(message.HTML.BodyContent).To(Equal("<p>billing in <b>R&amp;D</b> crashed</p>"))
+
+		Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("uaa"))
+		Expect(appLoader.LoadCall.Receives.AppGUID).To(Equal("app-001"))
+		Expect(appLoader.LoadCall.Receives.Token).To(Equal("some-token"))
+		Expect(spaceLoader.LoadCall.Receives.SpaceGUID).To(Equal("space-001"))
+		Expect(organizationLoader.LoadCall.Receives.OrganizationGUID).To(Equal("org-001"))
+	})
+
+	It("finds the space and organization from the GUID of a space dispatch", func() {
+		dispatch.AppGUID = ""
+		dispatch.Message = services.DispatchMessage{
+			Subject: "News for {{.Space.Name}}",
+			Text:    "{{.Space.Name}} in {{.Organization.Name}}",
+		}
+
+		_, err := newStrategy(services.PayloadTargetSpace).Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(appLoader.LoadCall.CallCount).To(Equal(0))
+		Expect(spaceLoader.LoadCall.Receives.SpaceGUID).To(Equal("space-001"))
+
+		message := innerStrategy.DispatchCalls[0].Receives.Dispatch.Message
+		Expect(message.Subject).To(Equal("News for production"))
+		Expect(message.Text).To(Equal("production in R&D"))
+	})
+
+	It("finds the organization from the GUID of an organization dispatch", func() {
+		dispatch.AppGUID = ""
+		dispatch.GUID = "org-001"
+		dispatch.Message = services.DispatchMessage{Text: "Hello {{.Organization.Name}}"}
+
+		_, err := newStrategy(services.PayloadTargetOrganization).Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spaceLoader.LoadCall.CallCount).To(Equal(0))
+
+		Expect(innerStrategy.DispatchCalls[0].Receives.Dispatch.Message.Text).To(Equal("Hello R&D"))
+	})
+
+	It("dispatches payloads without template actions as they are", func() {
+		dispatch.Message = services.DispatchMessage{Subject: "Hi", Text: "Plain text"}
+
+		_, err := newStrategy(services.PayloadTargetSpace).Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(innerStrategy.DispatchCalls[0].Receives.Dispatch).To(Equal(dispatch))
+		Expect(tokenLoader.LoadCall.Receives.UAAHost).To(BeEmpty())
+		Expect(appLoader.LoadCall.CallCount).To(Equal(0))
+		Expect(spaceLoader.LoadCall.CallCount).To(Equal(0))
+	})
+
+	Context("when the payload cannot be rendered", func() {
+		It("returns a PayloadTemplateError for metadata that is not available", func() {
+			dispatch.AppGUID = ""
+
+			_, err := newStrategy("").Dispatch(dispatch)
+			Expect(err).To(BeAssignableToTypeOf(services.PayloadTemplateError{}))
+			Expect(err.Error()).To(ContainSubstring(`the subject of the notification could not be rendered`))
+			Expect(innerStrategy.DispatchCallsCount).To(Equal(0))
+		})
+
+		It("returns a PayloadTemplateError for a template that does not parse", func() {
+			dispatch.Message.Text = "{{.App.Name"
+
+			_, err := newStrategy("").Dispatch(dispatch)
+			Expect(err).To(BeAssignableToTypeOf(services.PayloadTemplateError{}))
+			Expect(err.Error()).To(ContainSubstring("the text of the notification could not be rendered"))
+		})
+	})
+
+	Context("when the cloud controller cannot be reached", func() {
+		It("returns the error", func() {
+			appLoader.LoadCall.Returns.Error = services.CCDownError{Err: errors.New("BOOM!")}
+
+			_, err := newStrategy("").Dispatch(dispatch)
+			Expect(err).To(MatchError(services.CCDownError{Err: errors.New("BOOM!")}))
+			Expect(innerStrategy.DispatchCallsCount).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,291 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+const (
+	PreferenceChangeKindID   = "preference-change"
+	PreferenceChangeSubject  = "Your notification preferences were changed"
+	PreferenceRevertLifetime = 7 * 24 * time.Hour
+)
+
+type clientKindRegistrar interface {
+	Register(conn ConnectionInterface, client models.Client, kinds []models.Kind) error
+}
+
+type preferencesSetter interface {
+	Update(conn ConnectionInterface, preferences []models.Preference, globalUnsubscribe bool, userID string) error
+}
+
+type revertTokens interface {
+	Seal(plainText []byte) (string, error)
+	Open(token string) ([]byte, error)
+}
+
+type preferenceRevertsRepo interface {
+	Claim(conn models.ConnectionInterface, revert models.PreferenceRevert) error
+	DeleteBefore(conn models.ConnectionInterface, threshold time.Time) (int, error)
+}
+
+type idGenerator interface {
+	Generate() (string, error)
+}
+
+type PreferenceChange struct {
+	UserGUID          string
+	ClientID          string
+	UAAHost           string
+	Previous          PreferencesBuilder
+	Preferences       []models.Preference
+	GlobalUnsubscribe bool
+	VCAPRequest       DispatchVCAPRequest
+}
+
+type preferenceRevertKind struct {
+	ClientID string `json:"client_id"`
+	KindID   string `json:"kind_id"`
+	Email    bool   `json:"email"`
+}
+
+type preferenceRevert struct {
+	ID                string                 `json:"id"`
+	UserGUID          string                 `json:"user_guid"`
+	GlobalUnsubscribe bool                   `json:"global_unsubscribe"`
+	Preferences       []preferenceRevertKind `json:"preferences"`
+	ExpiresAt         time.Time              `json:"expires_at"`
+}
+
+// PreferenceChangeNotifier emails users when their preferences are changed
+// so that a change they did not make can be noticed and undone. The revert
+// link carries the previous preferences, sealed and signed like the
+// unsubscribe links, so nothing needs to be stored until it is followed.
+// Following it records its ID so that it only works once.
+type PreferenceChangeNotifier struct {
+	registrar   clientKindRegistrar
+	strategy    dispatcher
+	updater     preferencesSetter
+	tokens      revertTokens
+	reverts     preferenceRevertsRepo
+	idGenerator idGenerator
+	clock       clock
+	clientID    string
+	revertURL   string
+}
+
+func NewPreferenceChangeNotifier(registrar clientKindRegistrar, strategy dispatcher, updater preferencesSetter,
+	tokens revertTokens, reverts preferenceRevertsRepo, idGenerator idGenerator, clock clock, clientID, revertURL string) PreferenceChangeNotifier {
+
+	return PreferenceChangeNotifier{
+		registrar:   registrar,
+		strategy:    strategy,
+		updater:     updater,
+		tokens:      tokens,
+		reverts:     reverts,
+		idGenerator: idGenerator,
+		clock:       clock,
+		clientID:    clientID,
+		revertURL:   revertURL,
+	}
+}
+
+func (notifier PreferenceChangeNotifier) Notify(conn ConnectionInterface, change PreferenceChange) error {
+	revert := preferenceRevert{
+		UserGUID:          change.UserGUID,
+		GlobalUnsubscribe: change.Previous.GlobalUnsubscribe,
+		Preferences:       []preferenceRevertKind{},
+		ExpiresAt:         notifier.clock.Now().Add(PreferenceRevertLifetime).UTC(),
+	}
+
+	text := bytes.NewBuffer([]byte{})
+	fmt.Fprintf(text, "Your notification preferences were changed by the %q client.\n", change.ClientID)
+	fmt.Fprintln(text)
+
+	changed := false
+	if change.GlobalUnsubscribe != change.Previous.GlobalUnsubscribe {
+		changed = true
+		fmt.Fprintf(text, "  Unsubscribed from all notifications: %s\n", yesNo(change.GlobalUnsubscribe))
+	}
+
+	preferences := append([]models.Preference{}, change.Preferences...)
+	sort.Sort(preferencesByClientAndKind(preferences))
+
+	for _, preference := range preferences {
+		previous, ok := change.Previous.Clients[preference.ClientID][preference.KindID]
+		if !ok {
+			previous = Kind{SourceDescription: preference.ClientID, KindDescription: preference.KindID}
+		}
+
+		wasSubscribed := previous.Email == nil || *previous.Email
+		if wasSubscribed == preference.Email {
+			continue
+		}
+
+		changed = true
+		revert.Preferences = append(revert.Preferences, preferenceRevertKind{
+			ClientID: preference.ClientID,
+			KindID:   preference.KindID,
+			Email:    wasSubscribed,
+		})
+		fmt.Fprintf(text, "  %s / %s: %s\n", previous.SourceDescription, previous.KindDescription, subscribedUnsubscribed(preference.Email))
+	}
+
+	if !changed {
+		return nil
+	}
+
+	id, err := notifier.idGenerator.Generate()
+	if err != nil {
+		return err
+	}
+	revert.ID = id
+
+	token, err := notifier.seal(revert)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintln(text)
+	fmt.Fprintln(text, "If you did not make this change, you can undo it within 7 days by visiting:")
+	fmt.Fprintln(text, notifier.revertURL+token)
+
+	client := models.Client{ID: notifier.clientID, Description: "Notifications"}
+	kind := models.Kind{
+		ID:          PreferenceChangeKindID,
+		ClientID:    notifier.clientID,
+		Description: "Notification preferences changed",
+		Critical:    true,
+	}
+
+	err = notifier.registrar.Register(conn, client, []models.Kind{kind})
+	if err != nil {
+		return err
+	}
+
+	_, err = notifier.strategy.Dispatch(Dispatch{
+		GUID:        change.UserGUID,
+		Connection:  conn,
+		UAAHost:     change.UAAHost,
+		VCAPRequest: change.VCAPRequest,
+		Client: DispatchClient{
+			ID:          client.ID,
+			Description: client.Description,
+		},
+		Kind: DispatchKind{
+			ID:          kind.ID,
+			Description: kind.Description,
+			Critical:    kind.Critical,
+		},
+		Message: DispatchMessage{
+			Subject: PreferenceChangeSubject,
+			Text:    text.String(),
+		},
+	})
+
+	return err
+}
+
+// Verify reports whether the token could be used to revert, without using
+// it. A revert that was already made is only noticed by Revert.
+func (notifier PreferenceChangeNotifier) Verify(token string) error {
+	_, err := notifier.open(token)
+	return err
+}
+
+func (notifier PreferenceChangeNotifier) Revert(conn ConnectionInterface, token string) error {
+	revert, err := notifier.open(token)
+	if err != nil {
+		return err
+	}
+
+	now := notifier.clock.Now()
+	_, err = notifier.reverts.DeleteBefore(conn, now)
+	if err != nil {
+		return err
+	}
+
+	err = notifier.reverts.Claim(conn, models.PreferenceRevert{
+		ID:        revert.ID,
+		UserGUID:  revert.UserGUID,
+		ExpiresAt: revert.ExpiresAt,
+		UsedAt:    now,
+	})
+	if err != nil {
+		if _, ok := err.(models.DuplicateError); ok {
+			return PreferenceRevertError{errors.New("The revert link has already been used")}
+		}
+		return err
+	}
+
+	preferences := []models.Preference{}
+	for _, kind := range revert.Preferences {
+		preferences = append(preferences, models.Preference{
+			ClientID: kind.ClientID,
+			KindID:   kind.KindID,
+			Email:    kind.Email,
+		})
+	}
+
+	return notifier.updater.Update(conn, preferences, revert.GlobalUnsubscribe, revert.UserGUID)
+}
+
+func (notifier PreferenceChangeNotifier) seal(revert preferenceRevert) (string, error) {
+	plainText, err := json.Marshal(revert)
+	if err != nil {
+		return "", err
+	}
+
+	return notifier.tokens.Seal(plainText)
+}
+
+func (notifier PreferenceChangeNotifier) open(token string) (preferenceRevert, error) {
+	var revert preferenceRevert
+
+	plainText, err := notifier.tokens.Open(token)
+	if err != nil {
+		return revert, PreferenceRevertError{errors.New("The revert link is invalid")}
+	}
+
+	err = json.Unmarshal(plainText, &revert)
+	if err != nil || revert.ID == "" || revert.UserGUID == "" {
+		return revert, PreferenceRevertError{errors.New("The revert link is invalid")}
+	}
+
+	if notifier.clock.Now().After(revert.ExpiresAt) {
+		return revert, PreferenceRevertError{errors.New("The revert link has expired")}
+	}
+
+	return revert, nil
+}
+
+type preferencesByClientAndKind []models.Preference
+
+func (p preferencesByClientAndKind) Len() int      { return len(p) }
+func (p preferencesByClientAndKind) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p preferencesByClientAndKind) Less(i, j int) bool {
+	if p[i].ClientID != p[j].ClientID {
+		return p[i].ClientID < p[j].ClientID
+	}
+	return p[i].KindID < p[j].KindID
+}
+
+func yesNo(value bool) string {
+	if value {
+		return "yes"
+	}
+	return "no"
+}
+
+func subscribedUnsubscribed(email bool) string {
+	if email {
+		return "subscribed"
+	}
+	return "unsubscribed"
+}
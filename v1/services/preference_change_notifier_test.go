@@ -0,0 +1,254 @@
+package services_test
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/pivotal-golang/conceal"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreferenceChangeNotifier", func() {
+	var (
+		notifier  services.PreferenceChangeNotifier
+		registrar *mocks.Registrar
+		strategy  *mocks.Strategy
+		updater   *mocks.PreferenceUpdater
+		reverts   *mocks.PreferenceRevertsRepo
+		ids       *mocks.IDGenerator
+		clock     *mocks.Clock
+		conn      *mocks.Connection
+		change    services.PreferenceChange
+		now       time.Time
+	)
+
+	revertToken := func() string {
+		text := strategy.DispatchCalls[0].Receives.Dispatch.Message.Text
+		index := strings.Index(text, "https://notifications.example.com/revert/")
+		Expect(index).To(BeNumerically(">=", 0))
+
+		return strings.TrimSpace(text[index+len("https://notifications.example.com/revert/"):])
+	}
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		registrar = mocks.NewRegistrar()
+		strategy = mocks.NewStrategy()
+		updater = mocks.NewPreferenceUpdater()
+		reverts = mocks.NewPreferenceRevertsRepo()
+		ids = mocks.NewIDGenerator()
+		ids.GenerateCall.Returns.IDs = []string{"revert-123"}
+
+		now = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		cloak, err := conceal.NewCloak([]byte("super-secret"))
+		Expect(err).NotTo(HaveOccurred())
+
+		previous := services.NewPreferencesBuilder()
+		previous.Add(models.Preference{
+			ClientID:          "raptors",
+			KindID:            "feeding-time",
+			KindDescription:   "Feeding Time",
+			SourceDescription: "Raptor Enclosure",
+			Email:             true,
+		})
+		previous.Add(models.Preference{
+			ClientID: "raptors",
+			KindID:   "door-opening",
+			Email:    false,
+		})
+
+		change = services.PreferenceChange{
+			UserGUID: "user-123",
+			ClientID: "admin-client",
+			UAAHost:  "https://uaa.example.com",
+			Previous: previous,
+			Preferences: []models.Preference{
+				{ClientID: "raptors", KindID: "feeding-time", Email: false},
+				{ClientID: "raptors", KindID: "door-opening", Email: false},
+			},
+			GlobalUnsubscribe: true,
+			VCAPRequest: services.DispatchVCAPRequest{
+				ID:          "some-request-id",
+				ReceiptTime: now,
+			},
+		}
+
+		tokens := common.NewUnsubscribeTokens(cloak, []byte("super-secret"))
+
+		notifier = services.NewPreferenceChangeNotifier(registrar, strategy, updater, tokens, reverts, ids, clock, "notifications-client", "https://notifications.example.com/revert/")
+	})
+
+	Describe("Notify", func() {
+		It("emails the user a summary of what changed with a revert link", func() {
+			err := notifier.Notify(conn, change)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(registrar.RegisterCall.Receives.Connection).To(Equal(conn))
+			Expect(registrar.RegisterCall.Receives.Client).To(Equal(models.Client{ID: "notifications-client", Description: "Notifications"}))
+			Expect(registrar.RegisterCall.Receives.Kinds).To(Equal([]models.Kind{{
+				ID:          services.PreferenceChangeKindID,
+				ClientID:    "notifications-client",
+				Description: "Notification preferences changed",
+				Critical:    true,
+			}}))
+
+			Expect(strategy.DispatchCalls).To(HaveLen(1))
+			dispatch := strategy.DispatchCalls[0].Receives.Dispatch
+			Expect(dispatch.GUID).To(Equal("user-123"))
+			Expect(dispatch.Connection).To(Equal(conn))
+			Expect(dispatch.UAAHost).To(Equal("https://uaa.example.com"))
+			Expect(dispatch.VCAPRequest.ID).To(Equal("some-request-id"))
+			Expect(dispatch.Client.ID).To(Equal("notifications-client"))
+			Expect(dispatch.Kind.ID).To(Equal(services.PreferenceChangeKindID))
+			Expect(dispatch.Kind.Critical).To(BeTrue())
+			Expect(dispatch.Message.Subject).To(Equal(services.PreferenceChangeSubject))
+			Expect(dispatch.Message.Text).To(HavePrefix(`Your notification preferences were changed by the "admin-client" client.
+
+  Unsubscribed from all notifications: yes
+  Raptor Enclosure / Feeding Time: unsubscribed
+
+If you did not make this change, you can undo it within 7 days by visiting:
+https://notifications.example.com/revert/`))
+		})
+
+		It("does not send anything when nothing changed", func() {
+			change.GlobalUnsubscribe = false
+			change.Preferences = []models.Preference{
+				{ClientID: "raptors", KindID: "feeding-time", Email: true},
+			}
+
+			err := notifier.Notify(conn, change)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(strategy.DispatchCalls).To(BeEmpty())
+		})
+
+		Context("when the dispatch fails", func() {
+			It("returns the error", func() {
+				strategy.DispatchCalls = []mocks.StrategyDispatchCall{
+					mocks.NewStrategyDispatchCall(nil, errors.New("queue is down")),
+				}
+
+				err := notifier.Notify(conn, change)
+				Expect(err).To(MatchError(errors.New("queue is down")))
+			})
+		})
+	})
+
+	Describe("Revert", func() {
+		It("restores the preferences from before the change", func() {
+			Expect(notifier.Notify(conn, change)).To(Succeed())
+
+			err := notifier.Revert(conn, revertToken())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(updater.UpdateCall.Receives.Connection).To(Equal(conn))
+			Expect(updater.UpdateCall.Receives.UserID).To(Equal("user-123"))
+			Expect(updater.UpdateCall.Receives.GlobalUnsubscribe).To(BeFalse())
+			Expect(updater.UpdateCall.Receives.Preferences).To(Equal([]models.Preference{
+				{ClientID: "raptors", KindID: "feeding-time", Email: true},
+			}))
+		})
+
+		It("records the revert so that the link only works once", func() {
+			Expect(notifier.Notify(conn, change)).To(Succeed())
+			clock.NowCall.Returns.Time = now.Add(time.Hour)
+
+			err := notifier.Revert(conn, revertToken())
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(reverts.DeleteBeforeCall.Receives.Connection).To(Equal(conn))
+			Expect(reverts.DeleteBeforeCall.Receives.Threshold).To(Equal(now.Add(time.Hour)))
+			Expect(reverts.ClaimCall.Receives.Connection).To(Equal(conn))
+			Expect(reverts.ClaimCall.Receives.Revert).To(Equal(models.PreferenceRevert{
+				ID:        "revert-123",
+				UserGUID:  "user-123",
+				ExpiresAt: now.Add(services.PreferenceRevertLifetime),
+				UsedAt:    now.Add(time.Hour),
+			}))
+		})
+
+		Context("when the link has already been used", func() {
+			It("returns a revert error without changing the preferences", func() {
+				Expect(notifier.Notify(conn, change)).To(Succeed())
+				reverts.ClaimCall.Returns.Error = models.DuplicateError{Err: errors.New("The revert link has already been used")}
+
+				err := notifier.Revert(conn, revertToken())
+				Expect(err).To(MatchError(services.PreferenceRevertError{Err: errors.New("The revert link has already been used")}))
+				Expect(updater.UpdateCall.Receives.UserID).To(BeEmpty())
+			})
+		})
+
+		Context("when the revert cannot be recorded", func() {
+			It("returns the error without changing the preferences", func() {
+				Expect(notifier.Notify(conn, change)).To(Succeed())
+				reverts.ClaimCall.Returns.Error = errors.New("database is down")
+
+				err := notifier.Revert(conn, revertToken())
+				Expect(err).To(MatchError(errors.New("database is down")))
+				Expect(updater.UpdateCall.Receives.UserID).To(BeEmpty())
+			})
+		})
+
+		Context("when the token was altered", func() {
+			It("returns a revert error", func() {
+				Expect(notifier.Notify(conn, change)).To(Succeed())
+
+				parts := strings.Split(revertToken(), ".")
+				altered := []byte(parts[0])
+				altered[len(altered)/2] ^= 1
+
+				err := notifier.Revert(conn, string(altered)+"."+parts[1])
+				Expect(err).To(MatchError(services.PreferenceRevertError{Err: errors.New("The revert link is invalid")}))
+				Expect(reverts.ClaimCall.WasCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the link has expired", func() {
+			It("returns a revert error", func() {
+				Expect(notifier.Notify(conn, change)).To(Succeed())
+				clock.NowCall.Returns.Time = now.Add(services.PreferenceRevertLifetime + time.Second)
+
+				err := notifier.Revert(conn, revertToken())
+				Expect(err).To(MatchError(services.PreferenceRevertError{Err: errors.New("The revert link has expired")}))
+				Expect(updater.UpdateCall.Receives.UserID).To(BeEmpty())
+				Expect(reverts.ClaimCall.WasCalled).To(BeFalse())
+			})
+		})
+
+		Context("when the token is not one we issued", func() {
+			It("returns a revert error", func() {
+				err := notifier.Revert(conn, "not-a-token")
+				Expect(err).To(MatchError(services.PreferenceRevertError{Err: errors.New("The revert link is invalid")}))
+			})
+		})
+	})
+
+	Describe("Verify", func() {
+		It("accepts a token that could be used to revert", func() {
+			Expect(notifier.Notify(conn, change)).To(Succeed())
+
+			Expect(notifier.Verify(revertToken())).To(Succeed())
+			Expect(reverts.ClaimCall.WasCalled).To(BeFalse())
+			Expect(updater.UpdateCall.Receives.UserID).To(BeEmpty())
+		})
+
+		It("rejects a token that has expired", func() {
+			Expect(notifier.Notify(conn, change)).To(Succeed())
+			clock.NowCall.Returns.Time = now.Add(services.PreferenceRevertLifetime + time.Second)
+
+			err := notifier.Verify(revertToken())
+			Expect(err).To(MatchError(services.PreferenceRevertError{Err: errors.New("The revert link has expired")}))
+		})
+	})
+})
@@ -9,14 +9,24 @@ import (
 type PreferenceUpdater struct {
 	globalUnsubscribesRepo GlobalUnsubscribesRepo
 	unsubscribesRepo       UnsubscribesRepo
+	subscriptionsRepo      SubscriptionsRepo
 	kindsRepo              KindsRepo
+	digestPreferencesRepo  DigestPreferencesRepo
+	severityRepo           SeverityUnsubscribesRepo
+	kindActivity           kindActivityIncrementer
+	clock                  clock
 }
 
-func NewPreferenceUpdater(globalUnsubscribesRepo GlobalUnsubscribesRepo, unsubscribesRepo UnsubscribesRepo, kindsRepo KindsRepo) PreferenceUpdater {
+func NewPreferenceUpdater(globalUnsubscribesRepo GlobalUnsubscribesRepo, unsubscribesRepo UnsubscribesRepo, subscriptionsRepo SubscriptionsRepo, kindsRepo KindsRepo, digestPreferencesRepo DigestPreferencesRepo, severityRepo SeverityUnsubscribesRepo, kindActivity kindActivityIncrementer, clock clock) PreferenceUpdater {
 	return PreferenceUpdater{
 		globalUnsubscribesRepo: globalUnsubscribesRepo,
 		unsubscribesRepo:       unsubscribesRepo,
+		subscriptionsRepo:      subscriptionsRepo,
 		kindsRepo:              kindsRepo,
+		digestPreferencesRepo:  digestPreferencesRepo,
+		severityRepo:           severityRepo,
+		kindActivity:           kindActivity,
+		clock:                  clock,
 	}
 }
 
@@ -36,10 +46,40 @@ func (updater PreferenceUpdater) Update(conn ConnectionInterface, preferences []
 			return CriticalKindError{fmt.Errorf("The kind '%s' for the '%s' client is critical and cannot be unsubscribed from", preference.KindID, preference.ClientID)}
 		}
 
-		err = updater.unsubscribesRepo.Set(conn, userID, preference.ClientID, preference.KindID, !preference.Email)
+		err = setUnsubscribe(conn, updater.unsubscribesRepo, updater.kindActivity, updater.clock.Now(), userID, preference.ClientID, preference.KindID, !preference.Email)
+		if err != nil {
+			return err
+		}
+
+		if kind.OptIn {
+			err = updater.subscriptionsRepo.Set(conn, userID, preference.ClientID, preference.KindID, preference.Email)
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SetDigest changes how often the user receives their non-critical
+// notifications: one at a time, or collected into an hourly or daily digest.
+func (updater PreferenceUpdater) SetDigest(conn ConnectionInterface, userID, frequency string) error {
+	return updater.digestPreferencesRepo.Set(conn, userID, frequency)
+}
+
+// SetSeverities subscribes the user to, or unsubscribes them from, the
+// notifications of each severity.
+func (updater PreferenceUpdater) SetSeverities(conn ConnectionInterface, userID string, severities map[string]bool) error {
+	for severity, receive := range severities {
+		if !models.UnsubscribableSeverity(severity) {
+			return CriticalKindError{fmt.Errorf("The severity '%s' cannot be unsubscribed from", severity)}
+		}
+
+		err := updater.severityRepo.Set(conn, userID, severity, !receive)
 		if err != nil {
 			return err
 		}
 	}
+
 	return nil
 }
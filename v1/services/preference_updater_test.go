@@ -2,6 +2,7 @@ package services_test
 
 import (
 	"errors"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
@@ -15,8 +16,13 @@ var _ = Describe("PreferenceUpdater", func() {
 	Describe("Update", func() {
 		var (
 			unsubscribesRepo           *mocks.UnsubscribesRepo
+			subscriptionsRepo          *mocks.SubscriptionsRepo
 			kindsRepo                  *mocks.KindsRepo
 			fakeGlobalUnsubscribesRepo *mocks.GlobalUnsubscribesRepo
+			digestRepo                 *mocks.DigestPreferencesRepo
+			severityRepo               *mocks.SeverityUnsubscribesRepo
+			kindActivity               *mocks.KindActivityRepo
+			clock                      *mocks.Clock
 			conn                       *mocks.Connection
 			updater                    services.PreferenceUpdater
 		)
@@ -24,9 +30,15 @@ var _ = Describe("PreferenceUpdater", func() {
 		BeforeEach(func() {
 			conn = mocks.NewConnection()
 			unsubscribesRepo = mocks.NewUnsubscribesRepo()
+			subscriptionsRepo = mocks.NewSubscriptionsRepo()
 			kindsRepo = mocks.NewKindsRepo()
 			fakeGlobalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepo()
-			updater = services.NewPreferenceUpdater(fakeGlobalUnsubscribesRepo, unsubscribesRepo, kindsRepo)
+			digestRepo = mocks.NewDigestPreferencesRepo()
+			severityRepo = mocks.NewSeverityUnsubscribesRepo()
+			kindActivity = mocks.NewKindActivityRepo()
+			clock = mocks.NewClock()
+			clock.NowCall.Returns.Time = time.Date(2015, time.June, 8, 14, 0, 0, 0, time.UTC)
+			updater = services.NewPreferenceUpdater(fakeGlobalUnsubscribesRepo, unsubscribesRepo, subscriptionsRepo, kindsRepo, digestRepo, severityRepo, kindActivity, clock)
 		})
 
 		Context("when globally unsubscribing", func() {
@@ -77,6 +89,41 @@ var _ = Describe("PreferenceUpdater", func() {
 				Expect(unsubscribesRepo.SetCall.Receives.ClientID).To(Equal("raptors"))
 				Expect(unsubscribesRepo.SetCall.Receives.KindID).To(Equal("door-open"))
 				Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+
+				Expect(kindActivity.IncrementCall.Receives.Connection).To(Equal(conn))
+				Expect(kindActivity.IncrementCall.Receives.ClientID).To(Equal("raptors"))
+				Expect(kindActivity.IncrementCall.Receives.KindID).To(Equal("door-open"))
+				Expect(kindActivity.IncrementCall.Receives.Counter).To(Equal(models.KindActivityUnsubscribed))
+				Expect(kindActivity.IncrementCall.Receives.At).To(Equal(time.Date(2015, time.June, 8, 14, 0, 0, 0, time.UTC)))
+			})
+
+			It("does not count users who were already unsubscribed towards the kind activity", func() {
+				unsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    false,
+					},
+				}, false, "the-user")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+				Expect(kindActivity.IncrementCall.CallCount).To(Equal(0))
+			})
+
+			It("returns the errors from recording the kind activity", func() {
+				kindActivity.IncrementCall.Returns.Error = errors.New("BOOM!")
+
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    false,
+					},
+				}, false, "the-user")
+				Expect(err).To(MatchError(errors.New("BOOM!")))
 			})
 
 			It("does not add resubscriptions to the unsubscribes Repo", func() {
@@ -109,6 +156,65 @@ var _ = Describe("PreferenceUpdater", func() {
 				unsubscribed, err := unsubscribesRepo.Get(conn, "my-user", "raptors", "door-open")
 				Expect(err).NotTo(HaveOccurred())
 				Expect(unsubscribed).To(BeFalse())
+				Expect(kindActivity.IncrementCall.CallCount).To(Equal(0))
+			})
+
+			It("leaves subscriptions alone for kinds that are not opt-in", func() {
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "door-open",
+						Email:    true,
+					},
+				}, false, "my-user")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(subscriptionsRepo.SetCall.Receives.UserID).To(BeEmpty())
+			})
+		})
+
+		Context("when updating an opt-in kind", func() {
+			BeforeEach(func() {
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+					{
+						ID:       "newsletter",
+						ClientID: "raptors",
+						OptIn:    true,
+					},
+				}
+			})
+
+			It("records the subscription alongside the unsubscribe", func() {
+				err := updater.Update(conn, []models.Preference{
+					{
+						ClientID: "raptors",
+						KindID:   "newsletter",
+						Email:    true,
+					},
+				}, false, "my-user")
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeFalse())
+				Expect(subscriptionsRepo.SetCall.Receives.Connection).To(Equal(conn))
+				Expect(subscriptionsRepo.SetCall.Receives.UserID).To(Equal("my-user"))
+				Expect(subscriptionsRepo.SetCall.Receives.ClientID).To(Equal("raptors"))
+				Expect(subscriptionsRepo.SetCall.Receives.KindID).To(Equal("newsletter"))
+				Expect(subscriptionsRepo.SetCall.Receives.Subscribe).To(BeTrue())
+			})
+
+			Context("when the subscriptions repo errors", func() {
+				It("returns the error", func() {
+					subscriptionsRepo.SetCall.Returns.Error = errors.New("subscriptions db error")
+
+					err := updater.Update(conn, []models.Preference{
+						{
+							ClientID: "raptors",
+							KindID:   "newsletter",
+							Email:    false,
+						},
+					}, false, "my-user")
+					Expect(err).To(MatchError(errors.New("subscriptions db error")))
+				})
 			})
 		})
 
@@ -204,5 +310,42 @@ var _ = Describe("PreferenceUpdater", func() {
 				Expect(err).To(Equal(services.CriticalKindError{Err: errors.New("The kind 'hungry' for the 'raptors' client is critical and cannot be unsubscribed from")}))
 			})
 		})
+
+		Describe("SetDigest", func() {
+			It("stores the digest frequency of the user", func() {
+				err := updater.SetDigest(conn, "the-user", models.DigestHourly)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(digestRepo.SetCall.Receives.Connection).To(Equal(conn))
+				Expect(digestRepo.SetCall.Receives.UserID).To(Equal("the-user"))
+				Expect(digestRepo.SetCall.Receives.Frequency).To(Equal(models.DigestHourly))
+			})
+		})
+
+		Describe("SetSeverities", func() {
+			It("unsubscribes the user from the severities they no longer receive", func() {
+				err := updater.SetSeverities(conn, "the-user", map[string]bool{models.SeverityWarning: false})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(severityRepo.SetCall.Receives.Connection).To(Equal(conn))
+				Expect(severityRepo.SetCall.Receives.UserID).To(Equal("the-user"))
+				Expect(severityRepo.SetCall.Receives.Severity).To(Equal(models.SeverityWarning))
+				Expect(severityRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+			})
+
+			It("refuses to unsubscribe the user from notifications that require action", func() {
+				err := updater.SetSeverities(conn, "the-user", map[string]bool{models.SeverityCriticalActionRequired: false})
+				Expect(err).To(Equal(services.CriticalKindError{Err: errors.New("The severity 'critical-action-required' cannot be unsubscribed from")}))
+
+				Expect(severityRepo.SetCall.CallCount).To(Equal(0))
+			})
+
+			It("returns the errors of the repo", func() {
+				severityRepo.SetCall.Returns.Error = errors.New("BOOM!")
+
+				err := updater.SetSeverities(conn, "the-user", map[string]bool{models.SeverityInfo: true})
+				Expect(err).To(MatchError("BOOM!"))
+			})
+		})
 	})
 })
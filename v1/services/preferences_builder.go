@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 )
@@ -10,6 +11,8 @@ type Kind struct {
 	Email             *bool  `json:"email"`
 	KindDescription   string `json:"kind_description"`
 	SourceDescription string `json:"source_description"`
+
+	LocalizedDescriptions models.LocalizedDescriptions `json:"-"`
 }
 
 type ClientMap map[string]Kind
@@ -17,7 +20,12 @@ type ClientsMap map[string]ClientMap
 
 type PreferencesBuilder struct {
 	GlobalUnsubscribe bool       `json:"global_unsubscribe"`
+	Digest            string     `json:"digest,omitempty"`
 	Clients           ClientsMap `json:"clients"`
+
+	// Severities tell whether the user receives the notifications of each
+	// severity they may unsubscribe from.
+	Severities map[string]bool `json:"severities,omitempty"`
 }
 
 func NewPreferencesBuilder() PreferencesBuilder {
@@ -36,9 +44,10 @@ func (pref PreferencesBuilder) Add(preference models.Preference) {
 	}
 
 	data := Kind{
-		Email:             &preference.Email,
-		KindDescription:   preference.KindDescription,
-		SourceDescription: preference.SourceDescription,
+		Email:                 &preference.Email,
+		KindDescription:       preference.KindDescription,
+		SourceDescription:     preference.SourceDescription,
+		LocalizedDescriptions: preference.LocalizedDescriptions,
 	}
 
 	if clientMap, ok := pref.Clients[preference.ClientID]; ok {
@@ -50,8 +59,77 @@ func (pref PreferencesBuilder) Add(preference models.Preference) {
 	}
 }
 
+// Localize replaces each kind description with its translation into the
+// first of the locales the kind has one for.
+func (pref PreferencesBuilder) Localize(locales []string) {
+	for _, kinds := range pref.Clients {
+		for kindID, kind := range kinds {
+			if description, ok := kind.LocalizedDescriptions.Describe(locales); ok {
+				kind.KindDescription = description
+				kinds[kindID] = kind
+			}
+		}
+	}
+}
+
+// InZone keeps only the clients of the UAA zone, under the IDs the zone
+// knows them by.
+func (pref PreferencesBuilder) InZone(zoneID string) PreferencesBuilder {
+	if zoneID == "" || zoneID == models.DefaultZoneID {
+		return pref
+	}
+
+	inZone := pref
+	inZone.Clients = ClientsMap{}
+	for clientID, kinds := range pref.Clients {
+		if !models.ClientInZone(zoneID, clientID) {
+			continue
+		}
+
+		// Clients without a description are described by their ID, which
+		// is also shown as the zone knows it.
+		unzoned := models.UnzonedClientID(zoneID, clientID)
+		clientMap := ClientMap{}
+		for kindID, kind := range kinds {
+			if kind.SourceDescription == clientID {
+				kind.SourceDescription = unzoned
+			}
+			clientMap[kindID] = kind
+		}
+		inZone.Clients[unzoned] = clientMap
+	}
+
+	return inZone
+}
+
+// Zoned qualifies the client IDs given by the UAA zone with the zone, which
+// is what they are stored under.
+func (pref PreferencesBuilder) Zoned(zoneID string) PreferencesBuilder {
+	zoned := pref
+	zoned.Clients = ClientsMap{}
+	for clientID, kinds := range pref.Clients {
+		zoned.Clients[models.ZonedClientID(zoneID, clientID)] = kinds
+	}
+
+	return zoned
+}
+
 func (pref PreferencesBuilder) ToPreferences() ([]models.Preference, error) {
 	preferences := []models.Preference{}
+	if pref.Digest != "" && !validDigestFrequency(pref.Digest) {
+		return preferences, fmt.Errorf("The digest frequency %q is not one of %v", pref.Digest, models.DigestFrequencies)
+	}
+
+	for severity := range pref.Severities {
+		if !models.ValidSeverity(severity) {
+			return preferences, fmt.Errorf("The severity %q is not one of %v", severity, models.Severities)
+		}
+
+		if !models.UnsubscribableSeverity(severity) {
+			return preferences, fmt.Errorf("The severity %q cannot be unsubscribed from", severity)
+		}
+	}
+
 	for clientID, kinds := range pref.Clients {
 		if len(kinds) == 0 {
 			return preferences, errors.New("Missing kinds")
@@ -73,3 +151,13 @@ func (pref PreferencesBuilder) ToPreferences() ([]models.Preference, error) {
 
 	return preferences, nil
 }
+
+func validDigestFrequency(frequency string) bool {
+	for _, valid := range models.DigestFrequencies {
+		if frequency == valid {
+			return true
+		}
+	}
+
+	return false
+}
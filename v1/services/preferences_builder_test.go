@@ -130,6 +130,70 @@ var _ = Describe("NotificationsPreferences", func() {
 		})
 	})
 
+	Describe("Localize", func() {
+		BeforeEach(func() {
+			builder = services.NewPreferencesBuilder()
+			builder.Add(models.Preference{
+				ClientID:              "raptors",
+				KindID:                "hungry",
+				Email:                 true,
+				KindDescription:       "hungry raptors",
+				LocalizedDescriptions: models.LocalizedDescriptions{"fr": "raptors affamés"},
+				SourceDescription:     "raptors description",
+			})
+			builder.Add(models.Preference{
+				ClientID:          "raptors",
+				KindID:            "sleepy",
+				Email:             true,
+				KindDescription:   "sleepy raptors",
+				SourceDescription: "raptors description",
+			})
+		})
+
+		It("translates the descriptions of kinds that have a translation", func() {
+			builder.Localize([]string{"fr-CA", "en"})
+
+			Expect(builder.Clients["raptors"]["hungry"].KindDescription).To(Equal("raptors affamés"))
+			Expect(builder.Clients["raptors"]["sleepy"].KindDescription).To(Equal("sleepy raptors"))
+		})
+
+		It("leaves the descriptions alone when no locale matches", func() {
+			builder.Localize([]string{"de"})
+
+			Expect(builder.Clients["raptors"]["hungry"].KindDescription).To(Equal("hungry raptors"))
+		})
+	})
+
+	Describe("zones", func() {
+		BeforeEach(func() {
+			builder = services.NewPreferencesBuilder()
+			builder.GlobalUnsubscribe = true
+			builder.Add(models.Preference{ClientID: "raptors", KindID: "feeding-time", Email: true})
+			builder.Add(models.Preference{ClientID: "tenant-a:raptors", KindID: "hatching", Email: true})
+			builder.Add(models.Preference{ClientID: "tenant-b:raptors", KindID: "escape", Email: false})
+		})
+
+		It("keeps only the clients of the zone, under the IDs the zone knows", func() {
+			inZone := builder.InZone("tenant-a")
+
+			Expect(inZone.GlobalUnsubscribe).To(BeTrue())
+			Expect(inZone.Clients).To(HaveLen(1))
+			Expect(inZone.Clients["raptors"]).To(HaveKey("hatching"))
+			Expect(inZone.Clients["raptors"]["hatching"].SourceDescription).To(Equal("raptors"))
+		})
+
+		It("keeps every client for the default zone", func() {
+			Expect(builder.InZone("uaa").Clients).To(HaveLen(3))
+		})
+
+		It("qualifies the client IDs with the zone", func() {
+			zoned := builder.InZone("tenant-a").Zoned("tenant-a")
+
+			Expect(zoned.Clients).To(HaveLen(1))
+			Expect(zoned.Clients["tenant-a:raptors"]).To(HaveKey("hatching"))
+		})
+	})
+
 	Describe("ToPreferences", func() {
 		BeforeEach(func() {
 			builder = services.NewPreferencesBuilder()
@@ -213,6 +277,30 @@ var _ = Describe("NotificationsPreferences", func() {
 
 				Expect(err).ToNot(BeNil())
 			})
+
+			It("returns an error when the digest frequency is unknown", func() {
+				badBuilder.Digest = "weekly"
+
+				_, err := badBuilder.ToPreferences()
+
+				Expect(err).To(MatchError(`The digest frequency "weekly" is not one of [immediate hourly daily]`))
+			})
+
+			It("returns an error when the severity is unknown", func() {
+				badBuilder.Severities = map[string]bool{"dire": false}
+
+				_, err := badBuilder.ToPreferences()
+
+				Expect(err).To(MatchError(`The severity "dire" is not one of [info warning critical-action-required]`))
+			})
+
+			It("returns an error when unsubscribing from notifications that require action", func() {
+				badBuilder.Severities = map[string]bool{models.SeverityCriticalActionRequired: false}
+
+				_, err := badBuilder.ToPreferences()
+
+				Expect(err).To(MatchError(`The severity "critical-action-required" cannot be unsubscribed from`))
+			})
 		})
 	})
 })
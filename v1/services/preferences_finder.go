@@ -1,19 +1,25 @@
 package services
 
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
 type PreferencesFinder struct {
 	preferencesRepo        PreferencesRepo
 	globalUnsubscribesRepo GlobalUnsubscribesRepo
+	digestPreferencesRepo  DigestPreferencesRepo
+	severityRepo           SeverityUnsubscribesRepo
 }
 
-func NewPreferencesFinder(preferencesRepo PreferencesRepo, globalUnsubscribesRepo GlobalUnsubscribesRepo) *PreferencesFinder {
+func NewPreferencesFinder(preferencesRepo PreferencesRepo, globalUnsubscribesRepo GlobalUnsubscribesRepo, digestPreferencesRepo DigestPreferencesRepo, severityRepo SeverityUnsubscribesRepo) *PreferencesFinder {
 	return &PreferencesFinder{
 		preferencesRepo:        preferencesRepo,
 		globalUnsubscribesRepo: globalUnsubscribesRepo,
+		digestPreferencesRepo:  digestPreferencesRepo,
+		severityRepo:           severityRepo,
 	}
 }
 
 func (finder PreferencesFinder) Find(database DatabaseInterface, userGUID string) (PreferencesBuilder, error) {
-	conn := database.Connection()
+	conn := database.ReadConnection()
 	builder := NewPreferencesBuilder()
 
 	globallyUnsubscribed, err := finder.globalUnsubscribesRepo.Get(conn, userGUID)
@@ -21,12 +27,34 @@ func (finder PreferencesFinder) Find(database DatabaseInterface, userGUID string
 		return builder, err
 	}
 
+	digest, err := finder.digestPreferencesRepo.Get(conn, userGUID)
+	if err != nil {
+		return builder, err
+	}
+
+	unsubscribedSeverities, err := finder.severityRepo.FindAll(conn, userGUID)
+	if err != nil {
+		return builder, err
+	}
+
 	preferences, err := finder.preferencesRepo.FindNonCriticalPreferences(conn, userGUID)
 	if err != nil {
 		return builder, err
 	}
 
 	builder.GlobalUnsubscribe = globallyUnsubscribed
+	builder.Digest = digest
+	builder.Severities = map[string]bool{}
+	for _, severity := range models.Severities {
+		if models.UnsubscribableSeverity(severity) {
+			builder.Severities[severity] = true
+		}
+	}
+	for _, severity := range unsubscribedSeverities {
+		if _, ok := builder.Severities[severity]; ok {
+			builder.Severities[severity] = false
+		}
+	}
 	for _, preference := range preferences {
 		builder.Add(preference)
 	}
@@ -15,6 +15,8 @@ var _ = Describe("PreferencesFinder", func() {
 	var (
 		finder          *services.PreferencesFinder
 		preferencesRepo *mocks.PreferencesRepo
+		digestRepo      *mocks.DigestPreferencesRepo
+		severityRepo    *mocks.SeverityUnsubscribesRepo
 		preferences     []models.Preference
 		database        *mocks.Database
 		conn            *mocks.Connection
@@ -46,9 +48,15 @@ var _ = Describe("PreferencesFinder", func() {
 
 		conn = mocks.NewConnection()
 		database = mocks.NewDatabase()
-		database.ConnectionCall.Returns.Connection = conn
+		database.ReadConnectionCall.Returns.Connection = conn
 
-		finder = services.NewPreferencesFinder(preferencesRepo, fakeGlobalUnsubscribesRepo)
+		digestRepo = mocks.NewDigestPreferencesRepo()
+		digestRepo.GetCall.Returns.Frequency = models.DigestDaily
+
+		severityRepo = mocks.NewSeverityUnsubscribesRepo()
+		severityRepo.FindAllCall.Returns.Severities = []string{models.SeverityWarning}
+
+		finder = services.NewPreferencesFinder(preferencesRepo, fakeGlobalUnsubscribesRepo, digestRepo, severityRepo)
 	})
 
 	Describe("Find", func() {
@@ -57,6 +65,11 @@ var _ = Describe("PreferencesFinder", func() {
 			expectedResult.Add(preferences[0])
 			expectedResult.Add(preferences[1])
 			expectedResult.GlobalUnsubscribe = true
+			expectedResult.Digest = models.DigestDaily
+			expectedResult.Severities = map[string]bool{
+				models.SeverityInfo:    true,
+				models.SeverityWarning: false,
+			}
 
 			resultPreferences, err := finder.Find(database, "correct-user")
 			Expect(err).NotTo(HaveOccurred())
@@ -64,6 +77,26 @@ var _ = Describe("PreferencesFinder", func() {
 
 			Expect(preferencesRepo.FindNonCriticalPreferencesCall.Receives.Connection).To(Equal(conn))
 			Expect(preferencesRepo.FindNonCriticalPreferencesCall.Receives.UserGUID).To(Equal("correct-user"))
+			Expect(digestRepo.GetCall.Receives.UserID).To(Equal("correct-user"))
+			Expect(severityRepo.FindAllCall.Receives.UserID).To(Equal("correct-user"))
+		})
+
+		Context("when the digest preference cannot be loaded", func() {
+			It("should propagate the error", func() {
+				digestRepo.GetCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := finder.Find(database, "correct-user")
+				Expect(err).To(MatchError("BOOM!"))
+			})
+		})
+
+		Context("when the severity unsubscribes cannot be loaded", func() {
+			It("should propagate the error", func() {
+				severityRepo.FindAllCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := finder.Find(database, "correct-user")
+				Expect(err).To(MatchError("BOOM!"))
+			})
 		})
 
 		Context("when the preferences repo returns an error", func() {
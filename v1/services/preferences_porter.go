@@ -0,0 +1,264 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type storedPreferencesRepo interface {
+	FindStored(connection models.ConnectionInterface, userGUID string) (models.StoredPreferences, error)
+	FindUserIDs(connection models.ConnectionInterface, after string, limit int) ([]string, error)
+}
+
+type PreferenceKind struct {
+	ClientID string `json:"client_id"`
+	KindID   string `json:"kind_id"`
+}
+
+// PreferencesExport is everything a user has chosen about the notifications
+// they receive. Unlike the preferences shown to the user, it names only the
+// kinds they made a choice about, so that it can be imported into another
+// deployment whatever the user has received there.
+type PreferencesExport struct {
+	UserID            string           `json:"user_id"`
+	GlobalUnsubscribe bool             `json:"global_unsubscribe"`
+	Digest            string           `json:"digest"`
+	Unsubscribes      []PreferenceKind `json:"unsubscribes"`
+	Subscriptions     []PreferenceKind `json:"subscriptions"`
+}
+
+type PreferencesPorter struct {
+	preferencesRepo        storedPreferencesRepo
+	kindsRepo              KindsRepo
+	globalUnsubscribesRepo GlobalUnsubscribesRepo
+	unsubscribesRepo       UnsubscribesRepo
+	subscriptionsRepo      SubscriptionsRepo
+	digestPreferencesRepo  DigestPreferencesRepo
+}
+
+func NewPreferencesPorter(preferencesRepo storedPreferencesRepo, kindsRepo KindsRepo, globalUnsubscribesRepo GlobalUnsubscribesRepo, unsubscribesRepo UnsubscribesRepo, subscriptionsRepo SubscriptionsRepo, digestPreferencesRepo DigestPreferencesRepo) PreferencesPorter {
+	return PreferencesPorter{
+		preferencesRepo:        preferencesRepo,
+		kindsRepo:              kindsRepo,
+		globalUnsubscribesRepo: globalUnsubscribesRepo,
+		unsubscribesRepo:       unsubscribesRepo,
+		subscriptionsRepo:      subscriptionsRepo,
+		digestPreferencesRepo:  digestPreferencesRepo,
+	}
+}
+
+func (porter PreferencesPorter) Export(conn models.ConnectionInterface, userID string) (PreferencesExport, error) {
+	export := PreferencesExport{
+		UserID:        userID,
+		Unsubscribes:  []PreferenceKind{},
+		Subscriptions: []PreferenceKind{},
+	}
+
+	var err error
+	export.GlobalUnsubscribe, err = porter.globalUnsubscribesRepo.Get(conn, userID)
+	if err != nil {
+		return export, err
+	}
+
+	export.Digest, err = porter.digestPreferencesRepo.Get(conn, userID)
+	if err != nil {
+		return export, err
+	}
+
+	stored, err := porter.preferencesRepo.FindStored(conn, userID)
+	if err != nil {
+		return export, err
+	}
+
+	for _, unsubscribe := range stored.Unsubscribes {
+		export.Unsubscribes = append(export.Unsubscribes, PreferenceKind{ClientID: unsubscribe.ClientID, KindID: unsubscribe.KindID})
+	}
+
+	for _, subscription := range stored.Subscriptions {
+		export.Subscriptions = append(export.Subscriptions, PreferenceKind{ClientID: subscription.ClientID, KindID: subscription.KindID})
+	}
+
+	return export, nil
+}
+
+// ExportAll exports the preferences of up to limit users who have stored
+// any, in order of their IDs, starting after the given ID.
+func (porter PreferencesPorter) ExportAll(conn models.ConnectionInterface, after string, limit int) ([]PreferencesExport, error) {
+	userIDs, err := porter.preferencesRepo.FindUserIDs(conn, after, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	exports := []PreferencesExport{}
+	for _, userID := range userIDs {
+		export, err := porter.Export(conn, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		exports = append(exports, export)
+	}
+
+	return exports, nil
+}
+
+// Import replaces the preferences of each user with those exported, so
+// that kinds the export does not name go back to their defaults. Every
+// export is validated before anything is written; conn should be a
+// transaction so that a failure part way leaves no user half imported.
+func (porter PreferencesPorter) Import(conn models.ConnectionInterface, exports []PreferencesExport) error {
+	err := porter.validate(conn, exports)
+	if err != nil {
+		return err
+	}
+
+	for _, export := range exports {
+		err = porter.replace(conn, export)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (porter PreferencesPorter) validate(conn models.ConnectionInterface, exports []PreferencesExport) error {
+	var errs []string
+	seen := map[string]bool{}
+	for _, export := range exports {
+		if export.UserID == "" {
+			errs = append(errs, "user_id is required")
+			continue
+		}
+
+		if seen[export.UserID] {
+			errs = append(errs, fmt.Sprintf("%s: the user appears more than once", export.UserID))
+			continue
+		}
+		seen[export.UserID] = true
+
+		if export.Digest != "" && !validDigestFrequency(export.Digest) {
+			errs = append(errs, fmt.Sprintf("%s: the digest frequency %q is not one of %v", export.UserID, export.Digest, models.DigestFrequencies))
+		}
+
+		for _, preference := range export.Unsubscribes {
+			kind, problem, err := porter.findKind(conn, preference)
+			if err != nil {
+				return err
+			}
+
+			if problem == "" && kind.Critical {
+				problem = fmt.Sprintf("the kind %q for client %q is critical and cannot be unsubscribed from", preference.KindID, preference.ClientID)
+			}
+
+			if problem != "" {
+				errs = append(errs, export.UserID+": "+problem)
+			}
+		}
+
+		for _, preference := range export.Subscriptions {
+			kind, problem, err := porter.findKind(conn, preference)
+			if err != nil {
+				return err
+			}
+
+			if problem == "" && !kind.OptIn {
+				problem = fmt.Sprintf("the kind %q for client %q is not opt-in and cannot be subscribed to", preference.KindID, preference.ClientID)
+			}
+
+			if problem != "" {
+				errs = append(errs, export.UserID+": "+problem)
+			}
+		}
+	}
+
+	if len(errs) > 0 {
+		return PreferencesImportError{errors.New(strings.Join(errs, ", "))}
+	}
+
+	return nil
+}
+
+func (porter PreferencesPorter) findKind(conn models.ConnectionInterface, preference PreferenceKind) (models.Kind, string, error) {
+	kind, err := porter.kindsRepo.Find(conn, preference.KindID, preference.ClientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return kind, fmt.Sprintf("the kind %q for client %q is not registered", preference.KindID, preference.ClientID), nil
+		}
+
+		return kind, "", err
+	}
+
+	return kind, "", nil
+}
+
+func (porter PreferencesPorter) replace(conn models.ConnectionInterface, export PreferencesExport) error {
+	err := porter.globalUnsubscribesRepo.Set(conn, export.UserID, export.GlobalUnsubscribe)
+	if err != nil {
+		return err
+	}
+
+	digest := export.Digest
+	if digest == "" {
+		digest = models.DigestImmediate
+	}
+
+	err = porter.digestPreferencesRepo.Set(conn, export.UserID, digest)
+	if err != nil {
+		return err
+	}
+
+	stored, err := porter.preferencesRepo.FindStored(conn, export.UserID)
+	if err != nil {
+		return err
+	}
+
+	unsubscribes := map[PreferenceKind]bool{}
+	for _, preference := range export.Unsubscribes {
+		unsubscribes[preference] = true
+	}
+
+	for _, unsubscribe := range stored.Unsubscribes {
+		preference := PreferenceKind{ClientID: unsubscribe.ClientID, KindID: unsubscribe.KindID}
+		if !unsubscribes[preference] {
+			err = porter.unsubscribesRepo.Set(conn, export.UserID, preference.ClientID, preference.KindID, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for preference := range unsubscribes {
+		err = porter.unsubscribesRepo.Set(conn, export.UserID, preference.ClientID, preference.KindID, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	subscriptions := map[PreferenceKind]bool{}
+	for _, preference := range export.Subscriptions {
+		subscriptions[preference] = true
+	}
+
+	for _, subscription := range stored.Subscriptions {
+		preference := PreferenceKind{ClientID: subscription.ClientID, KindID: subscription.KindID}
+		if !subscriptions[preference] {
+			err = porter.subscriptionsRepo.Set(conn, export.UserID, preference.ClientID, preference.KindID, false)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for preference := range subscriptions {
+		err = porter.subscriptionsRepo.Set(conn, export.UserID, preference.ClientID, preference.KindID, true)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreferencesPorter", func() {
+	var (
+		porter                 services.PreferencesPorter
+		preferencesRepo        *mocks.PreferencesRepo
+		kindsRepo              *mocks.KindsRepo
+		globalUnsubscribesRepo *mocks.GlobalUnsubscribesRepo
+		unsubscribesRepo       *mocks.UnsubscribesRepo
+		subscriptionsRepo      *mocks.SubscriptionsRepo
+		digestRepo             *mocks.DigestPreferencesRepo
+		conn                   *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		preferencesRepo = mocks.NewPreferencesRepo()
+		kindsRepo = mocks.NewKindsRepo()
+		globalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepo()
+		unsubscribesRepo = mocks.NewUnsubscribesRepo()
+		subscriptionsRepo = mocks.NewSubscriptionsRepo()
+		digestRepo = mocks.NewDigestPreferencesRepo()
+
+		porter = services.NewPreferencesPorter(preferencesRepo, kindsRepo, globalUnsubscribesRepo, unsubscribesRepo, subscriptionsRepo, digestRepo)
+	})
+
+	Describe("Export", func() {
+		It("exports the choices the user has made", func() {
+			globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+			digestRepo.GetCall.Returns.Frequency = models.DigestDaily
+			preferencesRepo.FindStoredCall.Returns.Stored = models.StoredPreferences{
+				Unsubscribes:  models.Unsubscribes{{UserID: "user-123", ClientID: "raptors", KindID: "sleepy"}},
+				Subscriptions: models.Subscriptions{{UserID: "user-123", ClientID: "newsletters", KindID: "weekly"}},
+			}
+
+			export, err := porter.Export(conn, "user-123")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(export).To(Equal(services.PreferencesExport{
+				UserID:            "user-123",
+				GlobalUnsubscribe: true,
+				Digest:            models.DigestDaily,
+				Unsubscribes:      []services.PreferenceKind{{ClientID: "raptors", KindID: "sleepy"}},
+				Subscriptions:     []services.PreferenceKind{{ClientID: "newsletters", KindID: "weekly"}},
+			}))
+
+			Expect(preferencesRepo.FindStoredCall.Receives.Connection).To(Equal(conn))
+			Expect(preferencesRepo.FindStoredCall.Receives.UserGUID).To(Equal("user-123"))
+		})
+
+		It("returns repository errors", func() {
+			preferencesRepo.FindStoredCall.Returns.Error = errors.New("database is down")
+
+			_, err := porter.Export(conn, "user-123")
+			Expect(err).To(MatchError("database is down"))
+		})
+	})
+
+	Describe("ExportAll", func() {
+		It("exports a page of users", func() {
+			preferencesRepo.FindUserIDsCall.Returns.UserIDs = []string{"user-456", "user-789"}
+
+			exports, err := porter.ExportAll(conn, "user-123", 2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(exports).To(HaveLen(2))
+			Expect(exports[0].UserID).To(Equal("user-456"))
+			Expect(exports[1].UserID).To(Equal("user-789"))
+
+			Expect(preferencesRepo.FindUserIDsCall.Receives.After).To(Equal("user-123"))
+			Expect(preferencesRepo.FindUserIDsCall.Receives.Limit).To(Equal(2))
+		})
+	})
+
+	Describe("Import", func() {
+		It("replaces the preferences of the user", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+				{ID: "sleepy", ClientID: "raptors"},
+				{ID: "weekly", ClientID: "newsletters", OptIn: true},
+			}
+
+			err := porter.Import(conn, []services.PreferencesExport{{
+				UserID:            "user-123",
+				GlobalUnsubscribe: true,
+				Unsubscribes:      []services.PreferenceKind{{ClientID: "raptors", KindID: "sleepy"}},
+				Subscriptions:     []services.PreferenceKind{{ClientID: "newsletters", KindID: "weekly"}},
+			}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(globalUnsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(globalUnsubscribesRepo.SetCall.Receives.Unsubscribed).To(BeTrue())
+			Expect(digestRepo.SetCall.Receives.Frequency).To(Equal(models.DigestImmediate))
+
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(unsubscribesRepo.SetCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(unsubscribesRepo.SetCall.Receives.KindID).To(Equal("sleepy"))
+			Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+
+			Expect(subscriptionsRepo.SetCall.Receives.ClientID).To(Equal("newsletters"))
+			Expect(subscriptionsRepo.SetCall.Receives.KindID).To(Equal("weekly"))
+			Expect(subscriptionsRepo.SetCall.Receives.Subscribe).To(BeTrue())
+		})
+
+		It("returns stored choices the export does not name to their defaults", func() {
+			preferencesRepo.FindStoredCall.Returns.Stored = models.StoredPreferences{
+				Unsubscribes:  models.Unsubscribes{{UserID: "user-123", ClientID: "raptors", KindID: "sleepy"}},
+				Subscriptions: models.Subscriptions{{UserID: "user-123", ClientID: "newsletters", KindID: "weekly"}},
+			}
+
+			err := porter.Import(conn, []services.PreferencesExport{{UserID: "user-123", Digest: models.DigestHourly}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(digestRepo.SetCall.Receives.Frequency).To(Equal(models.DigestHourly))
+
+			Expect(unsubscribesRepo.SetCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(unsubscribesRepo.SetCall.Receives.KindID).To(Equal("sleepy"))
+			Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeFalse())
+
+			Expect(subscriptionsRepo.SetCall.Receives.ClientID).To(Equal("newsletters"))
+			Expect(subscriptionsRepo.SetCall.Receives.KindID).To(Equal("weekly"))
+			Expect(subscriptionsRepo.SetCall.Receives.Subscribe).To(BeFalse())
+		})
+
+		It("rejects the whole import when any of it is invalid", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+				{ID: "alarm", ClientID: "raptors", Critical: true},
+				{ID: "sleepy", ClientID: "raptors"},
+			}
+
+			err := porter.Import(conn, []services.PreferencesExport{
+				{UserID: "user-123", Digest: "weekly"},
+				{UserID: "user-123"},
+				{},
+				{
+					UserID:        "user-456",
+					Unsubscribes:  []services.PreferenceKind{{ClientID: "raptors", KindID: "alarm"}},
+					Subscriptions: []services.PreferenceKind{{ClientID: "raptors", KindID: "sleepy"}},
+				},
+			})
+			Expect(err).To(MatchError(services.PreferencesImportError{Err: errors.New(`user-123: the digest frequency "weekly" is not one of [immediate hourly daily], ` +
+				`user-123: the user appears more than once, ` +
+				`user_id is required, ` +
+				`user-456: the kind "alarm" for client "raptors" is critical and cannot be unsubscribed from, ` +
+				`user-456: the kind "sleepy" for client "raptors" is not opt-in and cannot be subscribed to`)}))
+
+			Expect(globalUnsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+		})
+
+		It("rejects kinds that are not registered", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{}}
+			kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			err := porter.Import(conn, []services.PreferencesExport{{
+				UserID:       "user-123",
+				Unsubscribes: []services.PreferenceKind{{ClientID: "raptors", KindID: "sleepy"}},
+			}})
+			Expect(err).To(MatchError(services.PreferencesImportError{Err: errors.New(`user-123: the kind "sleepy" for client "raptors" is not registered`)}))
+		})
+	})
+})
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+// QueueReplayGracePeriod is how long a message must have been queued before
+// a missing job counts as lost, which leaves time for jobs that are handed
+// to the fast lane or queued after their transaction commits.
+const QueueReplayGracePeriod = 10 * time.Minute
+
+type queuedMessagesFinder interface {
+	FindQueuedBefore(conn models.ConnectionInterface, threshold time.Time) ([]models.Message, error)
+}
+
+type replayQueue interface {
+	Jobs() ([]gobble.Job, error)
+	Enqueue(job *gobble.Job, transaction gobble.ConnectionInterface) (*gobble.Job, error)
+}
+
+// QueueReplayReport counts the queued messages that had no job: those whose
+// job was rebuilt, and those that were queued without keeping their
+// delivery and cannot be.
+type QueueReplayReport struct {
+	Orphaned      int `json:"orphaned"`
+	Repaired      int `json:"repaired"`
+	Unrecoverable int `json:"unrecoverable"`
+}
+
+type QueueReplayer struct {
+	repo  queuedMessagesFinder
+	queue replayQueue
+	clock clock
+}
+
+func NewQueueReplayer(repo queuedMessagesFinder, queue replayQueue, clock clock) QueueReplayer {
+	return QueueReplayer{
+		repo:  repo,
+		queue: queue,
+		clock: clock,
+	}
+}
+
+// Replay finds the messages that are still queued but have no job, such as
+// those of fast lane jobs held by a process that crashed, or of jobs a Redis
+// queue lost, and queues their job again from the delivery kept with the
+// message. With dryRun set, the orphans are only counted.
+func (r QueueReplayer) Replay(conn models.ConnectionInterface, dryRun bool) (QueueReplayReport, error) {
+	// The jobs are read before the messages: a worker updates the status of
+	// a message before it removes the job, so a message that is still
+	// queued once the jobs were read, and has none among them, has lost it.
+	jobs, err := r.queue.Jobs()
+	if err != nil {
+		return QueueReplayReport{}, err
+	}
+
+	queued := map[string]bool{}
+	for _, job := range jobs {
+		var delivery Delivery
+		if job.Unmarshal(&delivery) == nil && delivery.MessageID != "" {
+			queued[delivery.MessageID] = true
+		}
+	}
+
+	messages, err := r.repo.FindQueuedBefore(conn, r.clock.Now().Add(-QueueReplayGracePeriod))
+	if err != nil {
+		return QueueReplayReport{}, err
+	}
+
+	var report QueueReplayReport
+	for _, message := range messages {
+		if queued[message.ID] {
+			continue
+		}
+		report.Orphaned++
+
+		var delivery Delivery
+		if !message.Delivery.Valid || json.Unmarshal([]byte(message.Delivery.String), &delivery) != nil {
+			report.Unrecoverable++
+			continue
+		}
+
+		if dryRun {
+			continue
+		}
+
+		delivery.MessageID = message.ID
+		job := gobble.NewJob(delivery)
+		job.Priority = delivery.Options.Priority
+
+		_, err := r.queue.Enqueue(job, conn)
+		if err != nil {
+			return report, err
+		}
+		report.Repaired++
+	}
+
+	return report, nil
+}
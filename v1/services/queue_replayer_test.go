@@ -0,0 +1,124 @@
+package services_test
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("QueueReplayer", func() {
+	var (
+		replayer     services.QueueReplayer
+		messagesRepo *mocks.MessagesRepo
+		queue        *mocks.Queue
+		clock        *mocks.Clock
+		conn         *mocks.Connection
+		now          time.Time
+	)
+
+	keptDelivery := func(delivery services.Delivery) sql.NullString {
+		encoded, err := json.Marshal(delivery)
+		Expect(err).NotTo(HaveOccurred())
+
+		return sql.NullString{String: string(encoded), Valid: true}
+	}
+
+	BeforeEach(func() {
+		now = time.Now().UTC().Truncate(time.Second)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		queue = mocks.NewQueue()
+		queue.JobsCall.Returns.Jobs = []gobble.Job{
+			*gobble.NewJob(services.Delivery{MessageID: "message-with-job"}),
+		}
+
+		messagesRepo = mocks.NewMessagesRepo()
+		messagesRepo.FindQueuedBeforeCall.Returns.Messages = []models.Message{
+			{
+				ID:       "message-with-job",
+				Status:   common.StatusQueued,
+				Delivery: keptDelivery(services.Delivery{UserGUID: "user-1"}),
+			},
+			{
+				ID:       "orphaned-message",
+				Status:   common.StatusQueued,
+				Delivery: keptDelivery(services.Delivery{UserGUID: "user-2", Options: services.Options{KindID: "some-kind", Priority: gobble.PriorityCritical}}),
+			},
+			{
+				ID:     "message-queued-before-deliveries-were-kept",
+				Status: common.StatusQueued,
+			},
+		}
+
+		conn = mocks.NewConnection()
+
+		replayer = services.NewQueueReplayer(messagesRepo, queue, clock)
+	})
+
+	It("queues the jobs of the orphaned messages again from their kept delivery", func() {
+		report, err := replayer.Replay(conn, false)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report).To(Equal(services.QueueReplayReport{
+			Orphaned:      2,
+			Repaired:      1,
+			Unrecoverable: 1,
+		}))
+
+		Expect(messagesRepo.FindQueuedBeforeCall.Receives.Connection).To(Equal(conn))
+		Expect(messagesRepo.FindQueuedBeforeCall.Receives.Threshold).To(Equal(now.Add(-services.QueueReplayGracePeriod)))
+
+		Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+		job := queue.EnqueueCall.Receives.Jobs[0]
+		Expect(job.Priority).To(Equal(gobble.PriorityCritical))
+
+		var delivery services.Delivery
+		Expect(job.Unmarshal(&delivery)).To(Succeed())
+		Expect(delivery).To(Equal(services.Delivery{
+			MessageID: "orphaned-message",
+			UserGUID:  "user-2",
+			Options:   services.Options{KindID: "some-kind", Priority: gobble.PriorityCritical},
+		}))
+		Expect(queue.EnqueueCall.Receives.Connection).To(Equal(conn))
+	})
+
+	It("only counts the orphaned messages on a dry run", func() {
+		report, err := replayer.Replay(conn, true)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(report).To(Equal(services.QueueReplayReport{
+			Orphaned:      2,
+			Unrecoverable: 1,
+		}))
+		Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+	})
+
+	Context("when the jobs cannot be read", func() {
+		It("returns the error without looking for messages", func() {
+			queue.JobsCall.Returns.Error = errors.New("queue unavailable")
+
+			_, err := replayer.Replay(conn, false)
+			Expect(err).To(MatchError("queue unavailable"))
+			Expect(messagesRepo.FindQueuedBeforeCall.Receives.Connection).To(BeNil())
+		})
+	})
+
+	Context("when a job cannot be queued", func() {
+		It("returns the error with what was repaired so far", func() {
+			queue.EnqueueCall.Returns.Error = errors.New("queue unavailable")
+
+			report, err := replayer.Replay(conn, false)
+			Expect(err).To(MatchError("queue unavailable"))
+			Expect(report.Repaired).To(Equal(0))
+		})
+	})
+})
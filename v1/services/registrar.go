@@ -2,20 +2,50 @@ package services
 
 import "github.com/cloudfoundry-incubator/notifications/v1/models"
 
+type criticalDowngrader interface {
+	Apply(conn ConnectionInterface, kind models.Kind) error
+}
+
+type criticalApprovalsRepo interface {
+	Request(conn models.ConnectionInterface, clientID, kindID, status string) (models.CriticalApproval, error)
+	Withdraw(conn models.ConnectionInterface, clientID, kindID string) error
+}
+
 type Registrar struct {
 	clientsRepo ClientsRepo
 	kindsRepo   KindsRepo
+	downgrade   criticalDowngrader
+	approvals   criticalApprovalsRepo
 }
 
-func NewRegistrar(clientsRepo ClientsRepo, kindsRepo KindsRepo) Registrar {
+func NewRegistrar(clientsRepo ClientsRepo, kindsRepo KindsRepo, downgrade criticalDowngrader) Registrar {
 	return Registrar{
 		clientsRepo: clientsRepo,
 		kindsRepo:   kindsRepo,
+		downgrade:   downgrade,
 	}
 
 }
 
+// WithCriticalApproval has kinds registered as critical wait for an
+// operator to approve them, being registered as non-critical until then.
+func (registrar Registrar) WithCriticalApproval(approvals criticalApprovalsRepo) Registrar {
+	registrar.approvals = approvals
+	return registrar
+}
+
 func (registrar Registrar) Register(conn ConnectionInterface, client models.Client, kinds []models.Kind) error {
+	return registrar.register(conn, client, kinds, registrar.approvals != nil)
+}
+
+// Refresh saves the client and kinds as they were found in the database,
+// such as when a notification is sent. They are not the client's own
+// registration, so a kind waiting for critical approval keeps waiting.
+func (registrar Registrar) Refresh(conn ConnectionInterface, client models.Client, kinds []models.Kind) error {
+	return registrar.register(conn, client, kinds, false)
+}
+
+func (registrar Registrar) register(conn ConnectionInterface, client models.Client, kinds []models.Kind, gate bool) error {
 	_, err := registrar.clientsRepo.Upsert(conn, client)
 	if err != nil {
 		return err
@@ -26,14 +56,70 @@ func (registrar Registrar) Register(conn ConnectionInterface, client models.Clie
 			continue
 		}
 
-		_, err := registrar.kindsRepo.Upsert(conn, kind)
+		var existing models.Kind
+		if gate || !kind.Critical {
+			existing, err = registrar.findExisting(conn, kind)
+			if err != nil {
+				return err
+			}
+		}
+
+		if gate {
+			kind, err = registrar.gateCritical(conn, kind, existing)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = registrar.kindsRepo.Upsert(conn, kind)
 		if err != nil {
 			return err
 		}
+
+		if existing.Critical && !kind.Critical {
+			err = registrar.downgrade.Apply(conn, kind)
+			if err != nil {
+				return err
+			}
+		}
 	}
 	return nil
 }
 
+// findExisting returns the kind as it is stored, or an empty kind when it has
+// not been registered yet.
+func (registrar Registrar) findExisting(conn ConnectionInterface, kind models.Kind) (models.Kind, error) {
+	existing, err := registrar.kindsRepo.Find(conn, kind.ID, kind.ClientID)
+	if _, ok := err.(models.NotFoundError); ok {
+		return models.Kind{}, nil
+	}
+
+	return existing, err
+}
+
+// gateCritical registers the kind as non-critical unless its request to be
+// critical was approved. Kinds that were already critical when approval was
+// first required are approved without asking, and a client registering the
+// kind as non-critical withdraws its request.
+func (registrar Registrar) gateCritical(conn ConnectionInterface, kind, existing models.Kind) (models.Kind, error) {
+	if !kind.Critical {
+		return kind, registrar.approvals.Withdraw(conn, kind.ClientID, kind.ID)
+	}
+
+	status := models.CriticalApprovalPending
+	if existing.Critical {
+		status = models.CriticalApprovalApproved
+	}
+
+	approval, err := registrar.approvals.Request(conn, kind.ClientID, kind.ID, status)
+	if err != nil {
+		return kind, err
+	}
+
+	kind.Critical = approval.Status == models.CriticalApprovalApproved
+	return kind, nil
+}
+
 func (registrar Registrar) Prune(conn ConnectionInterface, client models.Client, kinds []models.Kind) error {
 	kindIDs := []string{}
 	for _, kind := range kinds {
@@ -16,6 +16,7 @@ var _ = Describe("Registrar", func() {
 		registrar   services.Registrar
 		clientsRepo *mocks.ClientsRepository
 		kindsRepo   *mocks.KindsRepo
+		downgrade   *mocks.CriticalDowngrade
 		conn        *mocks.Connection
 		kinds       []models.Kind
 	)
@@ -23,7 +24,8 @@ var _ = Describe("Registrar", func() {
 	BeforeEach(func() {
 		clientsRepo = mocks.NewClientsRepository()
 		kindsRepo = mocks.NewKindsRepo()
-		registrar = services.NewRegistrar(clientsRepo, kindsRepo)
+		downgrade = mocks.NewCriticalDowngrade()
+		registrar = services.NewRegistrar(clientsRepo, kindsRepo, downgrade)
 		conn = mocks.NewConnection()
 	})
 
@@ -49,6 +51,7 @@ var _ = Describe("Registrar", func() {
 			}
 
 			kinds = []models.Kind{hungry, sleepy}
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{sleepy}
 
 			err := registrar.Register(conn, client, kinds)
 			Expect(err).NotTo(HaveOccurred())
@@ -57,6 +60,33 @@ var _ = Describe("Registrar", func() {
 			Expect(clientsRepo.UpsertCall.Receives.Client).To(Equal(client))
 
 			Expect(kindsRepo.UpsertCall.Receives.Kinds).To(Equal([]models.Kind{hungry, sleepy}))
+
+			Expect(kindsRepo.FindCall.CallCount).To(Equal(1))
+			Expect(kindsRepo.FindCall.Receives.KindID).To(Equal("sleepy"))
+			Expect(kindsRepo.FindCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(downgrade.ApplyCall.Receives.Kinds).To(BeEmpty())
+		})
+
+		It("applies the downgrade when a critical kind is registered as non-critical", func() {
+			sleepy := models.Kind{ID: "sleepy", ClientID: "raptors"}
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "sleepy", ClientID: "raptors", Critical: true}}
+
+			err := registrar.Register(conn, models.Client{ID: "raptors"}, []models.Kind{sleepy})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(downgrade.ApplyCall.Receives.Connection).To(Equal(conn))
+			Expect(downgrade.ApplyCall.Receives.Kinds).To(Equal([]models.Kind{sleepy}))
+		})
+
+		It("does not apply the downgrade to kinds that were not registered before", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{}}
+			kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			err := registrar.Register(conn, models.Client{ID: "raptors"}, []models.Kind{{ID: "sleepy", ClientID: "raptors"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(kindsRepo.UpsertCall.Receives.Kinds).To(HaveLen(1))
+			Expect(downgrade.ApplyCall.Receives.Kinds).To(BeEmpty())
 		})
 
 		Context("when kinds is an empty set", func() {
@@ -75,7 +105,29 @@ var _ = Describe("Registrar", func() {
 				Expect(err).To(MatchError(errors.New("BOOM!")))
 			})
 
+			It("returns the errors from applying a downgrade", func() {
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "something", Critical: true}}
+				downgrade.ApplyCall.Returns.Error = errors.New("BOOM!")
+
+				err := registrar.Register(conn, models.Client{}, []models.Kind{
+					{ID: "something"},
+				})
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+			})
+
+			It("returns the errors from finding the existing kind", func() {
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{{}}
+				kindsRepo.FindCall.Returns.Error = errors.New("BOOM!")
+
+				err := registrar.Register(conn, models.Client{}, []models.Kind{
+					{ID: "something"},
+				})
+				Expect(err).To(MatchError(errors.New("BOOM!")))
+				Expect(kindsRepo.UpsertCall.Receives.Kinds).To(BeEmpty())
+			})
+
 			It("returns the errors from the kinds repo", func() {
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{{}}
 				kindsRepo.UpsertCall.Returns.Error = errors.New("BOOM!")
 
 				err := registrar.Register(conn, models.Client{}, []models.Kind{
@@ -86,6 +138,89 @@ var _ = Describe("Registrar", func() {
 		})
 	})
 
+	Describe("Register with critical approval", func() {
+		var (
+			approvals *mocks.CriticalApprovalsRepo
+			hungry    models.Kind
+		)
+
+		BeforeEach(func() {
+			approvals = mocks.NewCriticalApprovalsRepo()
+			registrar = registrar.WithCriticalApproval(approvals)
+
+			hungry = models.Kind{ID: "hungry", ClientID: "raptors", Critical: true}
+		})
+
+		It("registers a new critical kind as non-critical until it is approved", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{}}
+			kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+			approvals.RequestCall.Returns.Approval = models.CriticalApproval{Status: models.CriticalApprovalPending}
+
+			err := registrar.Register(conn, models.Client{ID: "raptors"}, []models.Kind{hungry})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(approvals.RequestCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(approvals.RequestCall.Receives.KindID).To(Equal("hungry"))
+			Expect(approvals.RequestCall.Receives.Status).To(Equal(models.CriticalApprovalPending))
+
+			Expect(kindsRepo.UpsertCall.Receives.Kinds).To(HaveLen(1))
+			Expect(kindsRepo.UpsertCall.Receives.Kinds[0].Critical).To(BeFalse())
+			Expect(downgrade.ApplyCall.Receives.Kinds).To(BeEmpty())
+		})
+
+		It("registers an approved kind as critical", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "hungry", ClientID: "raptors"}}
+			approvals.RequestCall.Returns.Approval = models.CriticalApproval{Status: models.CriticalApprovalApproved}
+
+			err := registrar.Register(conn, models.Client{ID: "raptors"}, []models.Kind{hungry})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(kindsRepo.UpsertCall.Receives.Kinds[0].Critical).To(BeTrue())
+		})
+
+		It("approves kinds that were already critical", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "hungry", ClientID: "raptors", Critical: true}}
+			approvals.RequestCall.Returns.Approval = models.CriticalApproval{Status: models.CriticalApprovalApproved}
+
+			err := registrar.Register(conn, models.Client{ID: "raptors"}, []models.Kind{hungry})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(approvals.RequestCall.Receives.Status).To(Equal(models.CriticalApprovalApproved))
+		})
+
+		It("withdraws the request of kinds registered as non-critical", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "sleepy", ClientID: "raptors"}}
+
+			err := registrar.Register(conn, models.Client{ID: "raptors"}, []models.Kind{{ID: "sleepy", ClientID: "raptors"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(approvals.WithdrawCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(approvals.WithdrawCall.Receives.KindID).To(Equal("sleepy"))
+			Expect(approvals.RequestCall.CallCount).To(Equal(0))
+		})
+
+		It("leaves the request pending when a notification of a kind waiting for approval is sent", func() {
+			pending := models.Kind{ID: "hungry", ClientID: "raptors", Critical: false}
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{pending}
+
+			err := registrar.Refresh(conn, models.Client{ID: "raptors"}, []models.Kind{pending})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(approvals.WithdrawCall.CallCount).To(Equal(0))
+			Expect(approvals.RequestCall.CallCount).To(Equal(0))
+			Expect(kindsRepo.UpsertCall.Receives.Kinds).To(Equal([]models.Kind{pending}))
+		})
+
+		It("returns the errors from the approvals repo", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "hungry", ClientID: "raptors"}}
+			approvals.RequestCall.Returns.Error = errors.New("BOOM!")
+
+			err := registrar.Register(conn, models.Client{ID: "raptors"}, []models.Kind{hungry})
+			Expect(err).To(MatchError("BOOM!"))
+			Expect(kindsRepo.UpsertCall.Receives.Kinds).To(BeEmpty())
+		})
+	})
+
 	Describe("Prune", func() {
 		It("Removes kinds from the database that are not passed in", func() {
 			client := models.Client{
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type registrationWebhooksFinder interface {
+	Find(conn models.ConnectionInterface, clientID string) (models.RegistrationWebhook, error)
+}
+
+// RegistrationSnapshot is the registration of a client at one point in time,
+// flattened so that two snapshots can be compared field by field.
+type RegistrationSnapshot struct {
+	clientID string
+	client   map[string]interface{}
+	kinds    map[string]map[string]interface{}
+}
+
+// RegistrationAuditor tells the registration webhook of a client what changed
+// whenever its kinds or templates are updated, so that teams that depend on
+// a shared registration notice when somebody else changes it.
+type RegistrationAuditor struct {
+	clientsRepo       ClientsRepo
+	kindsRepo         KindsRepo
+	webhooksRepo      registrationWebhooksFinder
+	queue             queueInterface
+	gobbleInitializer gobbleInitializer
+	clock             clock
+}
+
+func NewRegistrationAuditor(clientsRepo ClientsRepo, kindsRepo KindsRepo, webhooksRepo registrationWebhooksFinder,
+	queue queueInterface, gobbleInitializer gobbleInitializer, clock clock) RegistrationAuditor {
+
+	return RegistrationAuditor{
+		clientsRepo:       clientsRepo,
+		kindsRepo:         kindsRepo,
+		webhooksRepo:      webhooksRepo,
+		queue:             queue,
+		gobbleInitializer: gobbleInitializer,
+		clock:             clock,
+	}
+}
+
+func (auditor RegistrationAuditor) Snapshot(conn ConnectionInterface, clientID string) (RegistrationSnapshot, error) {
+	snapshot := RegistrationSnapshot{
+		clientID: clientID,
+		kinds:    map[string]map[string]interface{}{},
+	}
+
+	client, err := auditor.clientsRepo.Find(conn, clientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return snapshot, nil
+		}
+		return snapshot, err
+	}
+
+	var linkDomains map[string]string
+	if len(client.LinkDomains) > 0 {
+		linkDomains = client.LinkDomains
+	}
+
+	var simulationSinks []string
+	if len(client.SimulationSinks) > 0 {
+		simulationSinks = client.SimulationSinks
+	}
+
+	snapshot.client = map[string]interface{}{
+		"source_name":         client.Description,
+		"template":            client.TemplateID,
+		"callback_url":        client.CallbackURL,
+		"sender_name":         client.SenderName,
+		"link_domains":        linkDomains,
+		"simulation_sinks":    simulationSinks,
+		"engagement_tracking": client.EngagementTracking,
+	}
+
+	kinds, err := auditor.kindsRepo.FindAll(conn)
+	if err != nil {
+		return snapshot, err
+	}
+
+	for _, kind := range kinds {
+		if kind.ClientID != clientID {
+			continue
+		}
+
+		snapshot.kinds[kind.ID] = map[string]interface{}{
+			"description": kind.Description,
+			"critical":    kind.Critical,
+			"opt_in":      kind.OptIn,
+			"template":    kind.TemplateID,
+			"retry_policy": map[string]int{
+				"max_attempts": kind.RetryMaxAttempts,
+				"interval":     kind.RetryInterval,
+			},
+		}
+	}
+
+	return snapshot, nil
+}
+
+// Report compares the registration with the snapshot taken before it was
+// changed and queues an event for the registration webhook of the client,
+// if it has one. Nothing is queued when the registration did not change.
+func (auditor RegistrationAuditor) Report(conn ConnectionInterface, before RegistrationSnapshot, actor common.RegistrationActor) error {
+	webhook, err := auditor.webhooksRepo.Find(conn, before.clientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return nil
+		}
+		return err
+	}
+
+	after, err := auditor.Snapshot(conn, before.clientID)
+	if err != nil {
+		return err
+	}
+
+	changes := diffRegistration(before, after)
+	if len(changes) == 0 {
+		return nil
+	}
+
+	auditor.gobbleInitializer.InitializeDBMap(conn.GetDbMap())
+
+	_, err = auditor.queue.Enqueue(gobble.NewJob(common.RegistrationEvent{
+		JobType:     common.RegistrationEventJobType,
+		CallbackURL: webhook.URL,
+		ClientID:    before.clientID,
+		Actor:       actor,
+		Changes:     changes,
+		OccurredAt:  auditor.clock.Now().UTC(),
+	}), conn)
+
+	return err
+}
+
+func diffRegistration(before, after RegistrationSnapshot) []common.RegistrationChange {
+	changes := diffFields("", before.client, after.client)
+
+	for _, id := range kindIDs(before, after) {
+		from, hadKind := before.kinds[id]
+		to, hasKind := after.kinds[id]
+
+		switch {
+		case !hadKind:
+			changes = append(changes, common.RegistrationChange{Path: "notifications." + id, To: to})
+		case !hasKind:
+			changes = append(changes, common.RegistrationChange{Path: "notifications." + id, From: from})
+		default:
+			changes = append(changes, diffFields("notifications."+id+".", from, to)...)
+		}
+	}
+
+	return changes
+}
+
+func diffFields(prefix string, before, after map[string]interface{}) []common.RegistrationChange {
+	names := []string{}
+	for name := range before {
+		names = append(names, name)
+	}
+	for name := range after {
+		if _, ok := before[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	changes := []common.RegistrationChange{}
+	for _, name := range names {
+		if !reflect.DeepEqual(before[name], after[name]) {
+			changes = append(changes, common.RegistrationChange{Path: prefix + name, From: before[name], To: after[name]})
+		}
+	}
+
+	return changes
+}
+
+func kindIDs(before, after RegistrationSnapshot) []string {
+	ids := []string{}
+	for id := range before.kinds {
+		ids = append(ids, id)
+	}
+	for id := range after.kinds {
+		if _, ok := before.kinds[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	return ids
+}
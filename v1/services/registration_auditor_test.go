@@ -0,0 +1,177 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RegistrationAuditor", func() {
+	var (
+		auditor      services.RegistrationAuditor
+		clientsRepo  *mocks.ClientsRepository
+		kindsRepo    *mocks.KindsRepo
+		webhooksRepo *mocks.RegistrationWebhooksRepo
+		queue        *mocks.Queue
+		initializer  *mocks.GobbleInitializer
+		clock        *mocks.Clock
+		conn         *mocks.Connection
+		actor        common.RegistrationActor
+		now          time.Time
+	)
+
+	BeforeEach(func() {
+		clientsRepo = mocks.NewClientsRepository()
+		clientsRepo.FindCall.Returns.Client = models.Client{
+			ID:          "raptors",
+			Description: "Raptor Enclosure",
+			TemplateID:  "default",
+		}
+
+		kindsRepo = mocks.NewKindsRepo()
+		kindsRepo.FindAllCall.Returns.Kinds = []models.Kind{
+			{ID: "feeding-time", ClientID: "raptors", Description: "Feeding Time"},
+			{ID: "door-opening", ClientID: "raptors", Description: "Door Opening", Critical: true},
+			{ID: "feeding-time", ClientID: "trex", Description: "T-Rex Feeding Time"},
+		}
+
+		webhooksRepo = mocks.NewRegistrationWebhooksRepo()
+		webhooksRepo.FindCall.Returns.Webhook = models.RegistrationWebhook{
+			ClientID: "raptors",
+			URL:      "https://platform.example.com/registrations",
+		}
+
+		now = time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		queue = mocks.NewQueue()
+		initializer = mocks.NewGobbleInitializer()
+		conn = mocks.NewConnection()
+		actor = common.RegistrationActor{ClientID: "admin-client", UserID: "some-user"}
+
+		auditor = services.NewRegistrationAuditor(clientsRepo, kindsRepo, webhooksRepo, queue, initializer, clock)
+	})
+
+	It("queues an event with the changes and the actor for the registration webhook", func() {
+		before, err := auditor.Snapshot(conn, "raptors")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clientsRepo.FindCall.Receives.ClientID).To(Equal("raptors"))
+
+		clientsRepo.FindCall.Returns.Client.Description = "Raptor Paddock"
+		kindsRepo.FindAllCall.Returns.Kinds = []models.Kind{
+			{ID: "feeding-time", ClientID: "raptors", Description: "Feeding Time", Critical: true},
+			{ID: "escape", ClientID: "raptors", Description: "Escape"},
+		}
+
+		err = auditor.Report(conn, before, actor)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(webhooksRepo.FindCall.Receives.ClientID).To(Equal("raptors"))
+		Expect(initializer.InitializeDBMapCall.Receives.DbMap).To(Equal(conn.GetDbMapCall.Returns.DbMap))
+		Expect(queue.EnqueueCall.Receives.Connection).To(Equal(conn))
+		Expect(queue.EnqueueCall.Receives.Jobs).To(HaveLen(1))
+
+		var event common.RegistrationEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.JobType).To(Equal(common.RegistrationEventJobType))
+		Expect(event.CallbackURL).To(Equal("https://platform.example.com/registrations"))
+		Expect(event.ClientID).To(Equal("raptors"))
+		Expect(event.Actor).To(Equal(actor))
+		Expect(event.OccurredAt).To(Equal(now))
+
+		paths := []string{}
+		for _, change := range event.Changes {
+			paths = append(paths, change.Path)
+		}
+		Expect(paths).To(Equal([]string{
+			"source_name",
+			"notifications.door-opening",
+			"notifications.escape",
+			"notifications.feeding-time.critical",
+		}))
+
+		Expect(event.Changes[0].From).To(Equal("Raptor Enclosure"))
+		Expect(event.Changes[0].To).To(Equal("Raptor Paddock"))
+		Expect(event.Changes[1].From).To(HaveKeyWithValue("description", "Door Opening"))
+		Expect(event.Changes[1].To).To(BeNil())
+		Expect(event.Changes[2].From).To(BeNil())
+		Expect(event.Changes[2].To).To(HaveKeyWithValue("description", "Escape"))
+		Expect(event.Changes[3].From).To(Equal(false))
+		Expect(event.Changes[3].To).To(Equal(true))
+	})
+
+	It("reports every field of a client that was registered for the first time", func() {
+		clientsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		before, err := auditor.Snapshot(conn, "raptors")
+		Expect(err).NotTo(HaveOccurred())
+
+		clientsRepo.FindCall.Returns.Error = nil
+
+		err = auditor.Report(conn, before, actor)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event common.RegistrationEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.Changes).To(ContainElement(common.RegistrationChange{Path: "source_name", To: "Raptor Enclosure"}))
+	})
+
+	It("reports a client entering simulation mode", func() {
+		before, err := auditor.Snapshot(conn, "raptors")
+		Expect(err).NotTo(HaveOccurred())
+
+		clientsRepo.FindCall.Returns.Client.SimulationSinks = models.SimulationSinks{"qa@raptors.example.com"}
+
+		err = auditor.Report(conn, before, actor)
+		Expect(err).NotTo(HaveOccurred())
+
+		var event common.RegistrationEvent
+		Expect(queue.EnqueueCall.Receives.Jobs[0].Unmarshal(&event)).To(Succeed())
+		Expect(event.Changes).To(HaveLen(1))
+		Expect(event.Changes[0].Path).To(Equal("simulation_sinks"))
+		Expect(event.Changes[0].From).To(BeNil())
+		Expect(event.Changes[0].To).To(Equal([]interface{}{"qa@raptors.example.com"}))
+	})
+
+	It("does not queue an event when nothing changed", func() {
+		before, err := auditor.Snapshot(conn, "raptors")
+		Expect(err).NotTo(HaveOccurred())
+
+		err = auditor.Report(conn, before, actor)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+	})
+
+	It("does not queue an event when the client has no registration webhook", func() {
+		before, err := auditor.Snapshot(conn, "raptors")
+		Expect(err).NotTo(HaveOccurred())
+
+		webhooksRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+		clientsRepo.FindCall.Returns.Client.Description = "Raptor Paddock"
+
+		err = auditor.Report(conn, before, actor)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(queue.EnqueueCall.Receives.Jobs).To(BeEmpty())
+	})
+
+	Context("when the event cannot be queued", func() {
+		It("returns the error", func() {
+			before, err := auditor.Snapshot(conn, "raptors")
+			Expect(err).NotTo(HaveOccurred())
+
+			clientsRepo.FindCall.Returns.Client.Description = "Raptor Paddock"
+			queue.EnqueueCall.Returns.Error = errors.New("queue is down")
+
+			err = auditor.Report(conn, before, actor)
+			Expect(err).To(MatchError(errors.New("queue is down")))
+		})
+	})
+})
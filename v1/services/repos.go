@@ -31,10 +31,40 @@ type TemplatesRepo interface {
 	Update(connection models.ConnectionInterface, templateID string, template models.Template) (models.Template, error)
 }
 
+type TemplateTranslationsRepo interface {
+	Destroy(connection models.ConnectionInterface, templateID, locale string) error
+	Find(connection models.ConnectionInterface, templateID, locale string) (models.TemplateTranslation, error)
+	Upsert(connection models.ConnectionInterface, translation models.TemplateTranslation) (models.TemplateTranslation, error)
+}
+
 type UnsubscribesRepo interface {
+	Get(connection models.ConnectionInterface, userID string, clientID string, kindID string) (bool, error)
 	Set(connection models.ConnectionInterface, userID string, clientID string, kindID string, unsubscribe bool) error
 }
 
+type SubscriptionsRepo interface {
+	Get(connection models.ConnectionInterface, userID, clientID, kindID string) (bool, error)
+	Set(connection models.ConnectionInterface, userID, clientID, kindID string, subscribe bool) error
+}
+
+type CriticalUnsubscribesRepo interface {
+	Get(connection models.ConnectionInterface, userID, clientID, kindID string) (bool, error)
+	Set(connection models.ConnectionInterface, userID, clientID, kindID string, unsubscribe bool) error
+	FindAllByKind(connection models.ConnectionInterface, clientID, kindID string) ([]models.CriticalUnsubscribe, error)
+	DeleteAllByKind(connection models.ConnectionInterface, clientID, kindID string) (int, error)
+}
+
+type DigestPreferencesRepo interface {
+	Get(connection models.ConnectionInterface, userID string) (string, error)
+	Set(connection models.ConnectionInterface, userID, frequency string) error
+}
+
+type SeverityUnsubscribesRepo interface {
+	Get(connection models.ConnectionInterface, userID, severity string) (bool, error)
+	Set(connection models.ConnectionInterface, userID, severity string, unsubscribe bool) error
+	FindAll(connection models.ConnectionInterface, userID string) ([]string, error)
+}
+
 type GlobalUnsubscribesRepo interface {
 	Get(connection models.ConnectionInterface, userGUID string) (bool, error)
 	Set(connection models.ConnectionInterface, userGUID string, unsubscribe bool) error
@@ -5,4 +5,8 @@ type Response struct {
 	Recipient      string `json:"recipient"`
 	NotificationID string `json:"notification_id"`
 	VCAPRequestID  string `json:"vcap_request_id"`
+
+	// Error says why the notification to the recipient was not queued, when
+	// others from the same request were.
+	Error string `json:"error,omitempty"`
 }
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/rcrowley/go-metrics"
+)
+
+type slackKindFinder interface {
+	Find(connection models.ConnectionInterface, kindID string, clientID string) (models.Kind, error)
+}
+
+type slackEnqueuer interface {
+	EnqueueSlack(conn ConnectionInterface, options Options, clientID, uaaHost, vcapRequestID string, reqReceived time.Time) (Response, error)
+}
+
+// SlackStrategy wraps a strategy, posting notifications of kinds routed to
+// Slack to the kind's channel as well as, or instead of, emailing the users
+// the wrapped strategy would.
+type SlackStrategy struct {
+	strategy dispatcher
+	kinds    slackKindFinder
+	enqueuer slackEnqueuer
+}
+
+func NewSlackStrategy(strategy dispatcher, kinds slackKindFinder, enqueuer slackEnqueuer) SlackStrategy {
+	return SlackStrategy{
+		strategy: strategy,
+		kinds:    kinds,
+		enqueuer: enqueuer,
+	}
+}
+
+func (strategy SlackStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	if dispatch.Kind.ID == "" {
+		return strategy.strategy.Dispatch(dispatch)
+	}
+
+	kind, err := strategy.kinds.Find(dispatch.Connection, dispatch.Kind.ID, dispatch.Client.ID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return strategy.strategy.Dispatch(dispatch)
+		}
+
+		return []Response{}, err
+	}
+
+	if kind.SlackWebhookURL == "" {
+		return strategy.strategy.Dispatch(dispatch)
+	}
+
+	if kind.SlackExclusive {
+		response, err := strategy.post(dispatch)
+		if err != nil {
+			return []Response{}, err
+		}
+
+		return []Response{response}, nil
+	}
+
+	responses, err := strategy.strategy.Dispatch(dispatch)
+	if err != nil {
+		return responses, err
+	}
+
+	// The emails are already queued, so a failure to queue the Slack post
+	// must not fail the request and invite the client to send them again.
+	response, err := strategy.post(dispatch)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.slack.enqueue.failed", nil).Inc(1)
+		return responses, nil
+	}
+
+	return append(responses, response), nil
+}
+
+func (strategy SlackStrategy) post(dispatch Dispatch) (Response, error) {
+	options := Options{
+		ReplyTo:           dispatch.Message.ReplyTo,
+		Subject:           dispatch.Message.Subject,
+		KindID:            dispatch.Kind.ID,
+		KindDescription:   dispatch.Kind.Description,
+		SourceDescription: dispatch.Client.Description,
+		Text:              dispatch.Message.Text,
+		TemplateID:        dispatch.TemplateID,
+		CallbackURL:       dispatch.CallbackURL,
+		TraceParent:       dispatch.TraceParent,
+		SimulationSinks:   dispatch.Client.SimulationSinks,
+		Deadline:          dispatch.Deadline,
+		Metadata:          dispatch.Metadata,
+		Priority:          dispatch.priority(false),
+		Severity:          dispatch.Severity,
+		HTML: HTML{
+			BodyContent:    dispatch.Message.HTML.BodyContent,
+			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
+			Head:           dispatch.Message.HTML.Head,
+			Doctype:        dispatch.Message.HTML.Doctype,
+		},
+	}
+
+	return strategy.enqueuer.EnqueueSlack(dispatch.Connection, options, dispatch.Client.ID, dispatch.UAAHost, dispatch.VCAPRequest.ID, dispatch.VCAPRequest.ReceiptTime)
+}
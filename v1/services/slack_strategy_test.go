@@ -0,0 +1,174 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SlackStrategy", func() {
+	var (
+		strategy      services.SlackStrategy
+		emailStrategy *mocks.Strategy
+		kindsRepo     *mocks.KindsRepo
+		enqueuer      *mocks.Enqueuer
+		conn          *mocks.Connection
+		dispatch      services.Dispatch
+		emails        []services.Response
+		slackPost     services.Response
+		receivedAt    time.Time
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		emails = []services.Response{
+			{Status: "queued", Recipient: "user-123", NotificationID: "message-1"},
+		}
+		slackPost = services.Response{Status: "queued", Recipient: "slack", NotificationID: "message-2"}
+
+		emailStrategy = mocks.NewStrategy()
+		emailStrategy.DispatchCalls = []mocks.StrategyDispatchCall{
+			mocks.NewStrategyDispatchCall(emails, nil),
+		}
+
+		kindsRepo = mocks.NewKindsRepo()
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+			{ID: "instance-down", ClientID: "health-monitor", SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x"},
+		}
+
+		enqueuer = mocks.NewEnqueuer()
+		enqueuer.EnqueueSlackCall.Returns.Response = slackPost
+
+		receivedAt = time.Date(2015, 3, 20, 12, 0, 0, 0, time.UTC)
+		dispatch = services.Dispatch{
+			GUID:        "space-001",
+			Connection:  conn,
+			UAAHost:     "uaa.example.com",
+			TemplateID:  "some-template-id",
+			CallbackURL: "https://example.com/callback",
+			Client: services.DispatchClient{
+				ID:          "health-monitor",
+				Description: "Health Monitor",
+			},
+			Kind: services.DispatchKind{
+				ID:          "instance-down",
+				Description: "Instance Down",
+				Critical:    true,
+			},
+			Message: services.DispatchMessage{
+				Subject: "Your instance is down",
+				Text:    "instance 3 stopped",
+			},
+			VCAPRequest: services.DispatchVCAPRequest{
+				ID:          "some-request-id",
+				ReceiptTime: receivedAt,
+			},
+		}
+
+		strategy = services.NewSlackStrategy(emailStrategy, kindsRepo, enqueuer)
+	})
+
+	It("posts to Slack as well as emailing users", func() {
+		responses, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responses).To(Equal(append(emails, slackPost)))
+
+		Expect(emailStrategy.DispatchCallsCount).To(Equal(1))
+		Expect(kindsRepo.FindCall.Receives.Connection).To(Equal(conn))
+		Expect(kindsRepo.FindCall.Receives.KindID).To(Equal("instance-down"))
+		Expect(kindsRepo.FindCall.Receives.ClientID).To(Equal("health-monitor"))
+
+		Expect(enqueuer.EnqueueSlackCall.Receives.Connection).To(Equal(conn))
+		Expect(enqueuer.EnqueueSlackCall.Receives.Client).To(Equal("health-monitor"))
+		Expect(enqueuer.EnqueueSlackCall.Receives.UAAHost).To(Equal("uaa.example.com"))
+		Expect(enqueuer.EnqueueSlackCall.Receives.VCAPRequestID).To(Equal("some-request-id"))
+		Expect(enqueuer.EnqueueSlackCall.Receives.RequestReceived).To(Equal(receivedAt))
+		Expect(enqueuer.EnqueueSlackCall.Receives.Options).To(Equal(services.Options{
+			Subject:           "Your instance is down",
+			Text:              "instance 3 stopped",
+			KindID:            "instance-down",
+			KindDescription:   "Instance Down",
+			SourceDescription: "Health Monitor",
+			TemplateID:        "some-template-id",
+			CallbackURL:       "https://example.com/callback",
+			Priority:          gobble.PriorityCritical,
+		}))
+	})
+
+	It("only posts to Slack when the kind is routed there exclusively", func() {
+		kindsRepo.FindCall.Returns.Kinds[0].SlackExclusive = true
+
+		responses, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responses).To(Equal([]services.Response{slackPost}))
+		Expect(emailStrategy.DispatchCallsCount).To(Equal(0))
+	})
+
+	It("only emails users when the kind is not routed to Slack", func() {
+		kindsRepo.FindCall.Returns.Kinds[0].SlackWebhookURL = ""
+
+		responses, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responses).To(Equal(emails))
+		Expect(enqueuer.EnqueueSlackCall.WasCalled).To(BeFalse())
+	})
+
+	It("only emails users when the notification has no registered kind", func() {
+		kindsRepo.FindCall.Returns.Error = models.NotFoundError{}
+
+		responses, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(responses).To(Equal(emails))
+		Expect(enqueuer.EnqueueSlackCall.WasCalled).To(BeFalse())
+	})
+
+	It("does not look up a kind for notifications without one", func() {
+		dispatch.Kind = services.DispatchKind{}
+
+		_, err := strategy.Dispatch(dispatch)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(kindsRepo.FindCall.CallCount).To(Equal(0))
+		Expect(emailStrategy.DispatchCallsCount).To(Equal(1))
+	})
+
+	Context("when something fails", func() {
+		It("returns errors finding the kind", func() {
+			kindsRepo.FindCall.Returns.Error = errors.New("database is down")
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError("database is down"))
+			Expect(emailStrategy.DispatchCallsCount).To(Equal(0))
+		})
+
+		It("returns errors from the wrapped strategy without posting to Slack", func() {
+			emailStrategy.DispatchCalls[0].Returns.Error = errors.New("uaa is down")
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError("uaa is down"))
+			Expect(enqueuer.EnqueueSlackCall.WasCalled).To(BeFalse())
+		})
+
+		It("still reports the queued emails when the Slack post cannot be queued", func() {
+			enqueuer.EnqueueSlackCall.Returns.Err = errors.New("queue is full")
+
+			responses, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(Equal(emails))
+		})
+
+		It("returns the error when the notification only goes to Slack", func() {
+			kindsRepo.FindCall.Returns.Kinds[0].SlackExclusive = true
+			enqueuer.EnqueueSlackCall.Returns.Err = errors.New("queue is full")
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError("queue is full"))
+		})
+	})
+})
@@ -0,0 +1,46 @@
+package services
+
+const (
+	SpaceDeveloperEndorsement = `You received this message because you are a developer in the "{{.Space}}" space in the "{{.Organization}}" organization.`
+	SpaceManagerEndorsement   = `You received this message because you are a manager of the "{{.Space}}" space in the "{{.Organization}}" organization.`
+	SpaceAuditorEndorsement   = `You received this message because you are an auditor of the "{{.Space}}" space in the "{{.Organization}}" organization.`
+)
+
+type SpaceRoleStrategy struct {
+	spaceStrategy SpaceStrategy
+	role          string
+	endorsement   string
+}
+
+func NewSpaceRoleStrategy(tokenLoader loadsTokens, spaceLoader loadsSpaces, organizationLoader loadsOrganizations, findsUserIDs spaceUserIDFinder, enqueuer enqueuer, role, endorsement string) SpaceRoleStrategy {
+	return SpaceRoleStrategy{
+		spaceStrategy: NewSpaceStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer),
+		role:          role,
+		endorsement:   endorsement,
+	}
+}
+
+func NewSpaceDeveloperStrategy(tokenLoader loadsTokens, spaceLoader loadsSpaces, organizationLoader loadsOrganizations, findsUserIDs spaceUserIDFinder, enqueuer enqueuer) SpaceRoleStrategy {
+	return NewSpaceRoleStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer, "SpaceDeveloper", SpaceDeveloperEndorsement)
+}
+
+func NewSpaceManagerStrategy(tokenLoader loadsTokens, spaceLoader loadsSpaces, organizationLoader loadsOrganizations, findsUserIDs spaceUserIDFinder, enqueuer enqueuer) SpaceRoleStrategy {
+	return NewSpaceRoleStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer, "SpaceManager", SpaceManagerEndorsement)
+}
+
+func NewSpaceAuditorStrategy(tokenLoader loadsTokens, spaceLoader loadsSpaces, organizationLoader loadsOrganizations, findsUserIDs spaceUserIDFinder, enqueuer enqueuer) SpaceRoleStrategy {
+	return NewSpaceRoleStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer, "SpaceAuditor", SpaceAuditorEndorsement)
+}
+
+// WithUnsubscribeFiltering leaves out the role holders who unsubscribed, as
+// SpaceStrategy.WithUnsubscribeFiltering does.
+func (strategy SpaceRoleStrategy) WithUnsubscribeFiltering(suppressions suppressionsRepo) SpaceRoleStrategy {
+	strategy.spaceStrategy = strategy.spaceStrategy.WithUnsubscribeFiltering(suppressions)
+	return strategy
+}
+
+// Dispatch sends the message to the users holding the strategy's role in the
+// space.
+func (strategy SpaceRoleStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	return strategy.spaceStrategy.dispatch(dispatch, strategy.role, strategy.endorsement)
+}
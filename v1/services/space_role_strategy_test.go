@@ -0,0 +1,151 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SpaceRoleStrategy", func() {
+	var (
+		tokenLoader        *mocks.TokenLoader
+		spaceLoader        *mocks.SpaceLoader
+		organizationLoader *mocks.OrganizationLoader
+		enqueuer           *mocks.Enqueuer
+		conn               *mocks.Connection
+		findsUserIDs       *mocks.FindsUserIDs
+		dispatch           services.Dispatch
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "some-token"
+		enqueuer = mocks.NewEnqueuer()
+
+		findsUserIDs = mocks.NewFindsUserIDs()
+		findsUserIDs.UserIDsBelongingToSpaceCall.Returns.UserIDs = []string{"user-123", "user-456"}
+
+		spaceLoader = mocks.NewSpaceLoader()
+		spaceLoader.LoadCall.Returns.Spaces = []cf.CloudControllerSpace{
+			{
+				Name:             "production",
+				GUID:             "space-001",
+				OrganizationGUID: "org-001",
+			},
+		}
+
+		organizationLoader = mocks.NewOrganizationLoader()
+		organizationLoader.LoadCall.Returns.Organizations = []cf.CloudControllerOrganization{
+			{
+				Name: "my-org",
+				GUID: "org-001",
+			},
+		}
+
+		dispatch = services.Dispatch{
+			GUID:       "space-001",
+			Connection: conn,
+			UAAHost:    "testzone1",
+			Message: services.DispatchMessage{
+				Subject: "this is the subject",
+				Text:    "some text",
+			},
+			Kind: services.DispatchKind{
+				ID:          "deploy_failed",
+				Description: "Deployment failures",
+			},
+			Client: services.DispatchClient{
+				ID:          "mister-client",
+				Description: "Deployment system",
+			},
+		}
+	})
+
+	Context("when targeting developers", func() {
+		It("enqueues the message for the space developers", func() {
+			strategy := services.NewSpaceDeveloperStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.SpaceGUID).To(Equal("space-001"))
+			Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Role).To(Equal("SpaceDeveloper"))
+			Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Token).To(Equal("some-token"))
+
+			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(conn))
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{
+				{GUID: "user-123"},
+				{GUID: "user-456"},
+			}))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Endorsement).To(Equal(services.SpaceDeveloperEndorsement))
+			Expect(enqueuer.EnqueueCall.Receives.Space).To(Equal(cf.CloudControllerSpace{
+				Name:             "production",
+				GUID:             "space-001",
+				OrganizationGUID: "org-001",
+			}))
+			Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{
+				Name: "my-org",
+				GUID: "org-001",
+			}))
+			Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("mister-client"))
+		})
+	})
+
+	Context("when targeting managers", func() {
+		It("enqueues the message for the space managers", func() {
+			strategy := services.NewSpaceManagerStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Role).To(Equal("SpaceManager"))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Endorsement).To(Equal(services.SpaceManagerEndorsement))
+		})
+	})
+
+	Context("when targeting auditors", func() {
+		It("enqueues the message for the space auditors", func() {
+			strategy := services.NewSpaceAuditorStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Role).To(Equal("SpaceAuditor"))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Endorsement).To(Equal(services.SpaceAuditorEndorsement))
+		})
+	})
+
+	Context("when unsubscribed users are filtered out", func() {
+		It("only enqueues the role holders who are still subscribed", func() {
+			suppressions := mocks.NewSuppressionsRepo()
+			suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-456"}
+			strategy := services.NewSpaceManagerStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer).WithUnsubscribeFiltering(suppressions)
+
+			responses, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(suppressions.FindUnsubscribedCall.Receives.UserIDs).To(Equal([]string{"user-123", "user-456"}))
+			Expect(suppressions.FindUnsubscribedCall.Receives.KindID).To(Equal("deploy_failed"))
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-123"}}))
+			Expect(enqueuer.EnqueueCall.Receives.Options.Endorsement).To(Equal(services.SpaceManagerEndorsement))
+			Expect(responses).To(ContainElement(services.Response{Status: services.StatusUnsubscribed, Recipient: "user-456"}))
+		})
+	})
+
+	Context("when the users cannot be found", func() {
+		It("returns the error", func() {
+			findsUserIDs.UserIDsBelongingToSpaceCall.Returns.Error = errors.New("cc is down")
+			strategy := services.NewSpaceAuditorStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, enqueuer)
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError(errors.New("cc is down")))
+			Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+		})
+	})
+})
@@ -5,7 +5,7 @@ import "github.com/cloudfoundry-incubator/notifications/cf"
 const SpaceEndorsement = `You received this message because you belong to the "{{.Space}}" space in the "{{.Organization}}" organization.`
 
 type spaceUserIDFinder interface {
-	UserIDsBelongingToSpace(spaceGUID, token string) (userIDs []string, err error)
+	UserIDsBelongingToSpace(spaceGUID, role, token string) (userIDs []string, err error)
 }
 
 type loadsSpaces interface {
@@ -18,6 +18,7 @@ type SpaceStrategy struct {
 	organizationLoader loadsOrganizations
 	findsUserIDs       spaceUserIDFinder
 	enqueuer           enqueuer
+	suppressions       suppressionsRepo
 }
 
 func NewSpaceStrategy(tokenLoader loadsTokens, spaceLoader loadsSpaces, organizationLoader loadsOrganizations, findsUserIDs spaceUserIDFinder, enqueuer enqueuer) SpaceStrategy {
@@ -30,7 +31,18 @@ func NewSpaceStrategy(tokenLoader loadsTokens, spaceLoader loadsSpaces, organiza
 	}
 }
 
+// WithUnsubscribeFiltering leaves out the members of the space who
+// unsubscribed, as OrganizationStrategy.WithUnsubscribeFiltering does.
+func (strategy SpaceStrategy) WithUnsubscribeFiltering(suppressions suppressionsRepo) SpaceStrategy {
+	strategy.suppressions = suppressions
+	return strategy
+}
+
 func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	return strategy.dispatch(dispatch, "", SpaceEndorsement)
+}
+
+func (strategy SpaceStrategy) dispatch(dispatch Dispatch, spaceRole, endorsement string) ([]Response, error) {
 	var responses []Response
 
 	options := Options{
@@ -40,9 +52,16 @@ func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		KindID:            dispatch.Kind.ID,
 		KindDescription:   dispatch.Kind.Description,
 		SourceDescription: dispatch.Client.Description,
-		Endorsement:       SpaceEndorsement,
+		Endorsement:       endorsement,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		CallbackURL:       dispatch.CallbackURL,
+		TraceParent:       dispatch.TraceParent,
+		SimulationSinks:   dispatch.Client.SimulationSinks,
+		Deadline:          dispatch.Deadline,
+		Metadata:          dispatch.Metadata,
+		Priority:          dispatch.priority(false),
+		Severity:          dispatch.Severity,
 		Role:              dispatch.Role,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
@@ -57,14 +76,18 @@ func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		return responses, err
 	}
 
-	userGUIDs, err := strategy.findsUserIDs.UserIDsBelongingToSpace(dispatch.GUID, token)
+	userGUIDs, err := strategy.findsUserIDs.UserIDsBelongingToSpace(dispatch.GUID, spaceRole, token)
 	if err != nil {
 		return responses, err
 	}
 
-	var users []User
-	for _, guid := range userGUIDs {
-		users = append(users, User{GUID: guid})
+	users, unsubscribed, err := filterUnsubscribed(strategy.suppressions, dispatch, userGUIDs)
+	if err != nil {
+		return responses, err
+	}
+
+	if len(users) == 0 && len(unsubscribed) > 0 {
+		return unsubscribed, nil
 	}
 
 	space, err := strategy.spaceLoader.Load(dispatch.GUID, token)
@@ -77,7 +100,7 @@ func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		return responses, err
 	}
 
-	return strategy.enqueuer.Enqueue(
+	queued, err := strategy.enqueuer.Enqueue(
 		dispatch.Connection,
 		users,
 		options,
@@ -88,4 +111,9 @@ func (strategy SpaceStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 		"",
 		dispatch.VCAPRequest.ID,
 		dispatch.VCAPRequest.ReceiptTime)
+	if err != nil {
+		return queued, err
+	}
+
+	return append(queued, unsubscribed...), nil
 }
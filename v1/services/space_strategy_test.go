@@ -146,11 +146,83 @@ var _ = Describe("Space Strategy", func() {
 					Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("uaa"))
 
 					Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.SpaceGUID).To(Equal("space-001"))
+					Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Role).To(BeEmpty())
 					Expect(findsUserIDs.UserIDsBelongingToSpaceCall.Receives.Token).To(Equal(token))
 				})
 			})
 		})
 
+		Context("when unsubscribed users are filtered out", func() {
+			var (
+				suppressions *mocks.SuppressionsRepo
+				dispatch     services.Dispatch
+			)
+
+			BeforeEach(func() {
+				suppressions = mocks.NewSuppressionsRepo()
+				suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-456"}
+				strategy = strategy.WithUnsubscribeFiltering(suppressions)
+
+				enqueuer.EnqueueCall.Returns.Responses = []services.Response{
+					{Status: services.StatusQueued, Recipient: "user-123", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+				}
+
+				dispatch = services.Dispatch{
+					GUID:       "space-001",
+					Connection: conn,
+					Kind:       services.DispatchKind{ID: "forgot_password"},
+					Client:     services.DispatchClient{ID: "mister-client"},
+					VCAPRequest: services.DispatchVCAPRequest{
+						ID: "some-vcap-request-id",
+					},
+				}
+			})
+
+			It("only enqueues the users who are still subscribed", func() {
+				responses, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(suppressions.FindUnsubscribedCall.Receives.Connection).To(Equal(conn))
+				Expect(suppressions.FindUnsubscribedCall.Receives.UserIDs).To(Equal([]string{"user-123", "user-456"}))
+				Expect(suppressions.FindUnsubscribedCall.Receives.ClientID).To(Equal("mister-client"))
+				Expect(suppressions.FindUnsubscribedCall.Receives.KindID).To(Equal("forgot_password"))
+
+				Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-123"}}))
+				Expect(responses).To(Equal([]services.Response{
+					{Status: services.StatusQueued, Recipient: "user-123", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+					{Status: services.StatusUnsubscribed, Recipient: "user-456", VCAPRequestID: "some-vcap-request-id"},
+				}))
+			})
+
+			It("does not enqueue anything when every user unsubscribed", func() {
+				suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-123", "user-456"}
+
+				responses, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+				Expect(responses).To(HaveLen(2))
+			})
+
+			It("enqueues critical notifications for every user", func() {
+				dispatch.Kind.Critical = true
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(suppressions.FindUnsubscribedCall.WasCalled).To(BeFalse())
+				Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-123"}, {GUID: "user-456"}}))
+			})
+
+			It("returns the error when the unsubscribes cannot be found", func() {
+				suppressions.FindUnsubscribedCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).To(Equal(errors.New("BOOM!")))
+				Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+			})
+		})
+
 		Context("failure cases", func() {
 			Context("when token loader fails to return a token", func() {
 				It("returns an error", func() {
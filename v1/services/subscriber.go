@@ -0,0 +1,45 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+// Subscriber opts users in to kinds that are only delivered to users who
+// have asked for them. Subscribing also withdraws any earlier unsubscribe
+// so that the user starts receiving the kind straight away.
+type Subscriber struct {
+	kindsRepo         KindsRepo
+	subscriptionsRepo SubscriptionsRepo
+	unsubscribesRepo  UnsubscribesRepo
+}
+
+func NewSubscriber(kindsRepo KindsRepo, subscriptionsRepo SubscriptionsRepo, unsubscribesRepo UnsubscribesRepo) Subscriber {
+	return Subscriber{
+		kindsRepo:         kindsRepo,
+		subscriptionsRepo: subscriptionsRepo,
+		unsubscribesRepo:  unsubscribesRepo,
+	}
+}
+
+func (s Subscriber) Subscribe(conn ConnectionInterface, userID, clientID, kindID string) error {
+	kind, err := s.kindsRepo.Find(conn, kindID, clientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return MissingKindOrClientError{fmt.Errorf("The kind '%s' cannot be found for client '%s'", kindID, clientID)}
+		}
+		return err
+	}
+
+	if !kind.OptIn {
+		return NotOptInKindError{fmt.Errorf("The kind '%s' for the '%s' client is not opt-in", kindID, clientID)}
+	}
+
+	err = s.subscriptionsRepo.Set(conn, userID, clientID, kindID, true)
+	if err != nil {
+		return err
+	}
+
+	return s.unsubscribesRepo.Set(conn, userID, clientID, kindID, false)
+}
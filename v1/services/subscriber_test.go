@@ -0,0 +1,85 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Subscriber", func() {
+	var (
+		subscriber        services.Subscriber
+		kindsRepo         *mocks.KindsRepo
+		subscriptionsRepo *mocks.SubscriptionsRepo
+		unsubscribesRepo  *mocks.UnsubscribesRepo
+		conn              *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		kindsRepo = mocks.NewKindsRepo()
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+			{ID: "newsletter", ClientID: "raptors", OptIn: true},
+		}
+		subscriptionsRepo = mocks.NewSubscriptionsRepo()
+		unsubscribesRepo = mocks.NewUnsubscribesRepo()
+
+		subscriber = services.NewSubscriber(kindsRepo, subscriptionsRepo, unsubscribesRepo)
+	})
+
+	Describe("Subscribe", func() {
+		It("subscribes the user and withdraws any unsubscribe", func() {
+			err := subscriber.Subscribe(conn, "user-123", "raptors", "newsletter")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(kindsRepo.FindCall.Receives.KindID).To(Equal("newsletter"))
+			Expect(kindsRepo.FindCall.Receives.ClientID).To(Equal("raptors"))
+
+			Expect(subscriptionsRepo.SetCall.Receives.Connection).To(Equal(conn))
+			Expect(subscriptionsRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(subscriptionsRepo.SetCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(subscriptionsRepo.SetCall.Receives.KindID).To(Equal("newsletter"))
+			Expect(subscriptionsRepo.SetCall.Receives.Subscribe).To(BeTrue())
+
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(unsubscribesRepo.SetCall.Receives.KindID).To(Equal("newsletter"))
+			Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeFalse())
+		})
+
+		Context("when the kind is not opt-in", func() {
+			It("returns an error without subscribing", func() {
+				kindsRepo.FindCall.Returns.Kinds = []models.Kind{
+					{ID: "door-opening", ClientID: "raptors"},
+				}
+
+				err := subscriber.Subscribe(conn, "user-123", "raptors", "door-opening")
+				Expect(err).To(MatchError(services.NotOptInKindError{Err: errors.New("The kind 'door-opening' for the 'raptors' client is not opt-in")}))
+				Expect(subscriptionsRepo.SetCall.Receives.UserID).To(BeEmpty())
+			})
+		})
+
+		Context("when the kind cannot be found", func() {
+			It("returns a missing kind error", func() {
+				kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+				err := subscriber.Subscribe(conn, "user-123", "raptors", "newsletter")
+				Expect(err).To(MatchError(services.MissingKindOrClientError{Err: errors.New("The kind 'newsletter' cannot be found for client 'raptors'")}))
+			})
+		})
+
+		Context("when the subscriptions repo errors", func() {
+			It("returns the error", func() {
+				subscriptionsRepo.SetCall.Returns.Error = errors.New("subscriptions db error")
+
+				err := subscriber.Subscribe(conn, "user-123", "raptors", "newsletter")
+				Expect(err).To(MatchError(errors.New("subscriptions db error")))
+				Expect(unsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+			})
+		})
+	})
+})
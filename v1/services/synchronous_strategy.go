@@ -0,0 +1,61 @@
+package services
+
+import "time"
+
+const SynchronousPollInterval = 100 * time.Millisecond
+
+type dispatcher interface {
+	Dispatch(dispatch Dispatch) ([]Response, error)
+}
+
+type SynchronousStrategy struct {
+	strategy     dispatcher
+	messagesRepo messagesRepoFinder
+	timeout      time.Duration
+}
+
+func NewSynchronousStrategy(strategy dispatcher, messagesRepo messagesRepoFinder, timeout time.Duration) SynchronousStrategy {
+	return SynchronousStrategy{
+		strategy:     strategy,
+		messagesRepo: messagesRepo,
+		timeout:      timeout,
+	}
+}
+
+// Dispatch enqueues the delivery using the wrapped strategy and then waits,
+// up to the configured timeout, for each message to leave the queued state.
+// The delivery is still made by whichever worker takes the job, so it does
+// not jump a backlog: messages still queued when the timeout elapses are
+// reported as queued.
+func (strategy SynchronousStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	responses, err := strategy.strategy.Dispatch(dispatch)
+	if err != nil || strategy.timeout <= 0 {
+		return responses, err
+	}
+
+	deadline := time.Now().Add(strategy.timeout)
+	for {
+		pending := 0
+		for i, response := range responses {
+			if response.Status != StatusQueued {
+				continue
+			}
+
+			message, err := strategy.messagesRepo.FindByID(dispatch.Connection, response.NotificationID)
+			if err != nil {
+				return responses, err
+			}
+
+			responses[i].Status = message.Status
+			if message.Status == StatusQueued {
+				pending++
+			}
+		}
+
+		if pending == 0 || !time.Now().Before(deadline) {
+			return responses, nil
+		}
+
+		time.Sleep(SynchronousPollInterval)
+	}
+}
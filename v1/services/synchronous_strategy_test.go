@@ -0,0 +1,114 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SynchronousStrategy", func() {
+	var (
+		strategy     services.SynchronousStrategy
+		userStrategy *mocks.Strategy
+		messagesRepo *mocks.MessagesRepo
+		conn         *mocks.Connection
+		dispatch     services.Dispatch
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		messagesRepo = mocks.NewMessagesRepo()
+
+		userStrategy = mocks.NewStrategy()
+		userStrategy.DispatchCalls = []mocks.StrategyDispatchCall{
+			mocks.NewStrategyDispatchCall([]services.Response{
+				{
+					Status:         services.StatusQueued,
+					Recipient:      "user-123",
+					NotificationID: "some-message-id",
+					VCAPRequestID:  "some-vcap-request-id",
+				},
+			}, nil),
+		}
+
+		dispatch = services.Dispatch{
+			GUID:       "user-123",
+			Connection: conn,
+		}
+
+		strategy = services.NewSynchronousStrategy(userStrategy, messagesRepo, 500*time.Millisecond)
+	})
+
+	Describe("Dispatch", func() {
+		It("passes the dispatch through to the wrapped strategy", func() {
+			messagesRepo.FindByIDCall.Returns.Message = models.Message{ID: "some-message-id", Status: "delivered"}
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(userStrategy.DispatchCallsCount).To(Equal(1))
+			Expect(userStrategy.DispatchCalls[0].Receives.Dispatch).To(Equal(dispatch))
+		})
+
+		It("returns the final status of the delivered message", func() {
+			messagesRepo.FindByIDCall.Returns.Message = models.Message{ID: "some-message-id", Status: "delivered"}
+
+			responses, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses).To(Equal([]services.Response{
+				{
+					Status:         "delivered",
+					Recipient:      "user-123",
+					NotificationID: "some-message-id",
+					VCAPRequestID:  "some-vcap-request-id",
+				},
+			}))
+
+			Expect(messagesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+			Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(Equal("some-message-id"))
+		})
+
+		It("returns the queued status when the timeout elapses before delivery", func() {
+			messagesRepo.FindByIDCall.Returns.Message = models.Message{ID: "some-message-id", Status: services.StatusQueued}
+
+			start := time.Now()
+			responses, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(time.Since(start)).To(BeNumerically(">=", 500*time.Millisecond))
+			Expect(responses[0].Status).To(Equal(services.StatusQueued))
+		})
+
+		It("does not wait when the timeout is not positive", func() {
+			strategy = services.NewSynchronousStrategy(userStrategy, messagesRepo, 0)
+
+			responses, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(responses[0].Status).To(Equal(services.StatusQueued))
+			Expect(messagesRepo.FindByIDCall.Receives.MessageID).To(BeEmpty())
+		})
+
+		Context("when the wrapped strategy errors", func() {
+			It("returns the error", func() {
+				userStrategy.DispatchCalls[0].Returns.Error = errors.New("dispatch failed")
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).To(MatchError(errors.New("dispatch failed")))
+			})
+		})
+
+		Context("when the message cannot be found", func() {
+			It("returns the error", func() {
+				messagesRepo.FindByIDCall.Returns.Error = errors.New("not found")
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).To(MatchError(errors.New("not found")))
+			})
+		})
+	})
+})
@@ -0,0 +1,264 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"text/template"
+	"text/template/parse"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type bundleTemplatesRepo interface {
+	Create(conn models.ConnectionInterface, template models.Template) (models.Template, error)
+	FindByID(conn models.ConnectionInterface, templateID string) (models.Template, error)
+	Update(conn models.ConnectionInterface, templateID string, template models.Template) (models.Template, error)
+}
+
+type bundleTranslationsRepo interface {
+	FindAllByTemplateID(conn models.ConnectionInterface, templateID string) ([]models.TemplateTranslation, error)
+	DestroyAllByTemplateID(conn models.ConnectionInterface, templateID string) error
+	Upsert(conn models.ConnectionInterface, translation models.TemplateTranslation) (models.TemplateTranslation, error)
+}
+
+type bundlePartialsRepo interface {
+	FindAll(conn models.ConnectionInterface) ([]models.TemplatePartial, error)
+	Upsert(conn models.ConnectionInterface, partial models.TemplatePartial) (models.TemplatePartial, error)
+}
+
+// TemplateBundle is everything the worker needs to render a template: the
+// template, its translations, and the partials they include, directly or
+// through other partials.
+type TemplateBundle struct {
+	Template     models.Template
+	Translations []models.TemplateTranslation
+	Partials     []models.TemplatePartial
+}
+
+type TemplateBundler struct {
+	templatesRepo    bundleTemplatesRepo
+	translationsRepo bundleTranslationsRepo
+	partialsRepo     bundlePartialsRepo
+}
+
+func NewTemplateBundler(templatesRepo bundleTemplatesRepo, translationsRepo bundleTranslationsRepo, partialsRepo bundlePartialsRepo) TemplateBundler {
+	return TemplateBundler{
+		templatesRepo:    templatesRepo,
+		translationsRepo: translationsRepo,
+		partialsRepo:     partialsRepo,
+	}
+}
+
+// Export bundles the template. Partials it includes that do not exist are
+// left out, just as the worker would fail to find them.
+func (bundler TemplateBundler) Export(conn models.ConnectionInterface, templateID string) (TemplateBundle, error) {
+	template, err := bundler.templatesRepo.FindByID(conn, templateID)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	translations, err := bundler.translationsRepo.FindAllByTemplateID(conn, templateID)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	partials, err := bundler.savedPartials(conn)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	included, _, err := includedPartials(bundleSources(template, translations), partials)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	return TemplateBundle{
+		Template:     template,
+		Translations: translations,
+		Partials:     included,
+	}, nil
+}
+
+// Import saves the bundle under templateID, creating the template when it
+// does not exist. Its translations replace those of the template, and its
+// partials replace the saved ones of the same name. A bundle that includes
+// a partial that is neither in it nor saved is refused, since the worker
+// could not render it.
+func (bundler TemplateBundler) Import(conn models.ConnectionInterface, templateID string, bundle TemplateBundle) (TemplateBundle, error) {
+	saved, err := bundler.savedPartials(conn)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	partials := map[string]models.TemplatePartial{}
+	for name, partial := range saved {
+		partials[name] = partial
+	}
+	for _, partial := range bundle.Partials {
+		partials[partial.Name] = partial
+	}
+
+	_, missing, err := includedPartials(bundleSources(bundle.Template, bundle.Translations), partials)
+	if err != nil {
+		return TemplateBundle{}, TemplateBundleError{err}
+	}
+	if len(missing) > 0 {
+		return TemplateBundle{}, TemplateBundleError{fmt.Errorf("template includes partial %q, which is neither in the bundle nor saved", missing[0])}
+	}
+
+	template := bundle.Template
+	template.ID = templateID
+
+	_, err = bundler.templatesRepo.FindByID(conn, templateID)
+	switch err.(type) {
+	case nil:
+		_, err = bundler.templatesRepo.Update(conn, templateID, template)
+	case models.NotFoundError:
+		_, err = bundler.templatesRepo.Create(conn, template)
+	}
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	err = bundler.translationsRepo.DestroyAllByTemplateID(conn, templateID)
+	if err != nil {
+		return TemplateBundle{}, err
+	}
+
+	for _, translation := range bundle.Translations {
+		translation.TemplateID = templateID
+		_, err = bundler.translationsRepo.Upsert(conn, translation)
+		if err != nil {
+			return TemplateBundle{}, err
+		}
+	}
+
+	for _, partial := range bundle.Partials {
+		existing, ok := saved[partial.Name]
+		if ok && existing.Text == partial.Text && existing.HTML == partial.HTML {
+			continue
+		}
+
+		_, err = bundler.partialsRepo.Upsert(conn, models.TemplatePartial{
+			Name: partial.Name,
+			Text: partial.Text,
+			HTML: partial.HTML,
+		})
+		if err != nil {
+			return TemplateBundle{}, err
+		}
+	}
+
+	return bundler.Export(conn, templateID)
+}
+
+func (bundler TemplateBundler) savedPartials(conn models.ConnectionInterface) (map[string]models.TemplatePartial, error) {
+	partials, err := bundler.partialsRepo.FindAll(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := map[string]models.TemplatePartial{}
+	for _, partial := range partials {
+		byName[partial.Name] = partial
+	}
+
+	return byName, nil
+}
+
+func bundleSources(template models.Template, translations []models.TemplateTranslation) []string {
+	sources := []string{template.Subject, template.Text, template.HTML, template.Slack}
+	for _, translation := range translations {
+		sources = append(sources, translation.Subject, translation.Text, translation.HTML)
+	}
+
+	return sources
+}
+
+// includedPartials follows the includes of sources through the partials,
+// returning the partials that are included, sorted by name, and the names
+// of those that are missing.
+func includedPartials(sources []string, partials map[string]models.TemplatePartial) ([]models.TemplatePartial, []string, error) {
+	var (
+		included []models.TemplatePartial
+		missing  []string
+		seen     = map[string]bool{}
+	)
+
+	for len(sources) > 0 {
+		names, err := includes(sources[0])
+		if err != nil {
+			return nil, nil, err
+		}
+		sources = sources[1:]
+
+		for _, name := range names {
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			partial, ok := partials[name]
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+
+			included = append(included, partial)
+			sources = append(sources, partial.Text, partial.HTML)
+		}
+	}
+
+	sort.Slice(included, func(i, j int) bool { return included[i].Name < included[j].Name })
+	sort.Strings(missing)
+
+	return included, missing, nil
+}
+
+// includes returns the names of the templates that source includes without
+// defining them itself.
+func includes(source string) ([]string, error) {
+	t, err := template.New("bundle").Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, defined := range t.Templates() {
+		if defined.Tree != nil {
+			names = appendIncludes(names, defined.Tree.Root)
+		}
+	}
+
+	var undefined []string
+	for _, name := range names {
+		if t.Lookup(name) == nil {
+			undefined = append(undefined, name)
+		}
+	}
+
+	return undefined, nil
+}
+
+func appendIncludes(names []string, node parse.Node) []string {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return names
+		}
+
+		for _, child := range n.Nodes {
+			names = appendIncludes(names, child)
+		}
+	case *parse.IfNode:
+		names = appendIncludes(appendIncludes(names, n.List), n.ElseList)
+	case *parse.RangeNode:
+		names = appendIncludes(appendIncludes(names, n.List), n.ElseList)
+	case *parse.WithNode:
+		names = appendIncludes(appendIncludes(names, n.List), n.ElseList)
+	case *parse.TemplateNode:
+		names = append(names, n.Name)
+	}
+
+	return names
+}
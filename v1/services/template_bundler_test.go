@@ -0,0 +1,161 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateBundler", func() {
+	var (
+		bundler          services.TemplateBundler
+		templatesRepo    *mocks.TemplatesRepo
+		translationsRepo *mocks.TemplateTranslationsRepo
+		partialsRepo     *mocks.TemplatePartialsRepo
+		conn             *mocks.Connection
+		template         models.Template
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+
+		template = models.Template{
+			ID:       "template-id",
+			Name:     "Raptor Alert",
+			Subject:  "{{.Subject}}",
+			Text:     `{{template "footer" .}}`,
+			HTML:     `<p>{{.HTML}}</p>{{if .Endorsement}}{{template "signature" .}}{{end}}`,
+			Metadata: "{}",
+		}
+
+		templatesRepo = mocks.NewTemplatesRepo()
+		templatesRepo.FindByIDCall.Returns.Template = template
+
+		translationsRepo = mocks.NewTemplateTranslationsRepo()
+		translationsRepo.FindAllByTemplateIDCall.Returns.Translations = []models.TemplateTranslation{
+			{TemplateID: "template-id", Locale: "fr", HTML: `{{template "banner" .}}`},
+		}
+
+		partialsRepo = mocks.NewTemplatePartialsRepo()
+		partialsRepo.FindAllCall.Returns.Partials = []models.TemplatePartial{
+			{Name: "banner", HTML: "<h1>Park</h1>"},
+			{Name: "footer", Text: `-- {{template "signature" .}}`},
+			{Name: "signature", Text: "Containment"},
+			{Name: "unused", Text: "not included"},
+		}
+
+		bundler = services.NewTemplateBundler(templatesRepo, translationsRepo, partialsRepo)
+	})
+
+	Describe("Export", func() {
+		It("bundles the template with its translations and the partials they include", func() {
+			bundle, err := bundler.Export(conn, "template-id")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(bundle.Template).To(Equal(template))
+			Expect(bundle.Translations).To(Equal(translationsRepo.FindAllByTemplateIDCall.Returns.Translations))
+			Expect(bundle.Partials).To(Equal([]models.TemplatePartial{
+				{Name: "banner", HTML: "<h1>Park</h1>"},
+				{Name: "footer", Text: `-- {{template "signature" .}}`},
+				{Name: "signature", Text: "Containment"},
+			}))
+
+			Expect(templatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("template-id"))
+			Expect(translationsRepo.FindAllByTemplateIDCall.Receives.TemplateID).To(Equal("template-id"))
+		})
+
+		It("leaves out partials that do not exist", func() {
+			partialsRepo.FindAllCall.Returns.Partials = []models.TemplatePartial{{Name: "banner", HTML: "<h1>Park</h1>"}}
+
+			bundle, err := bundler.Export(conn, "template-id")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(bundle.Partials).To(Equal([]models.TemplatePartial{{Name: "banner", HTML: "<h1>Park</h1>"}}))
+		})
+
+		It("returns errors from finding the template", func() {
+			templatesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			_, err := bundler.Export(conn, "template-id")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
+		})
+	})
+
+	Describe("Import", func() {
+		var bundle services.TemplateBundle
+
+		BeforeEach(func() {
+			bundle = services.TemplateBundle{
+				Template: models.Template{
+					Name:     "Raptor Alert",
+					Subject:  "{{.Subject}}",
+					HTML:     `{{template "banner" .}}{{template "legal" .}}`,
+					Metadata: "{}",
+				},
+				Translations: []models.TemplateTranslation{
+					{Locale: "fr", HTML: "<p>Alerte</p>"},
+				},
+				Partials: []models.TemplatePartial{
+					{Name: "banner", HTML: "<h1>Park</h1>"},
+					{Name: "legal", HTML: "<small>InGen</small>"},
+				},
+			}
+		})
+
+		It("updates the template and replaces its translations and partials", func() {
+			_, err := bundler.Import(conn, "template-id", bundle)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(templatesRepo.UpdateCall.Receives.TemplateID).To(Equal("template-id"))
+			Expect(templatesRepo.UpdateCall.Receives.Template.ID).To(Equal("template-id"))
+			Expect(templatesRepo.UpdateCall.Receives.Template.HTML).To(Equal(`{{template "banner" .}}{{template "legal" .}}`))
+
+			Expect(translationsRepo.DestroyAllByTemplateIDCall.Receives.TemplateID).To(Equal("template-id"))
+			Expect(translationsRepo.UpsertCall.Receives.Translations).To(Equal([]models.TemplateTranslation{
+				{TemplateID: "template-id", Locale: "fr", HTML: "<p>Alerte</p>"},
+			}))
+
+			Expect(partialsRepo.UpsertCall.Receives.Partials).To(Equal([]models.TemplatePartial{
+				{Name: "legal", HTML: "<small>InGen</small>"},
+			}))
+		})
+
+		It("creates the template when it does not exist", func() {
+			templatesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			bundler.Import(conn, "template-id", bundle)
+
+			Expect(templatesRepo.CreateCall.Receives.Template.ID).To(Equal("template-id"))
+			Expect(templatesRepo.UpdateCall.Receives.TemplateID).To(BeEmpty())
+		})
+
+		It("accepts partials that are already saved", func() {
+			bundle.Partials = []models.TemplatePartial{{Name: "legal", HTML: "<small>InGen</small>"}}
+
+			_, err := bundler.Import(conn, "template-id", bundle)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("refuses bundles that include missing partials", func() {
+			bundle.Partials = nil
+
+			_, err := bundler.Import(conn, "template-id", bundle)
+			Expect(err).To(MatchError(services.TemplateBundleError{Err: errors.New(`template includes partial "legal", which is neither in the bundle nor saved`)}))
+
+			Expect(templatesRepo.UpdateCall.Receives.TemplateID).To(BeEmpty())
+		})
+
+		It("returns errors from saving the template", func() {
+			templatesRepo.UpdateCall.Returns.Error = errors.New("database is down")
+
+			_, err := bundler.Import(conn, "template-id", bundle)
+			Expect(err).To(MatchError("database is down"))
+
+			Expect(translationsRepo.DestroyAllByTemplateIDCall.CallCount).To(Equal(0))
+		})
+	})
+})
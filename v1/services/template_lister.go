@@ -16,7 +16,9 @@ func NewTemplateLister(templatesRepo TemplatesRepo) TemplateLister {
 	}
 }
 
-func (lister TemplateLister) List(database DatabaseInterface) (map[string]TemplateSummary, error) {
+// List summarizes the templates the UAA zone may see, leaving out the default
+// and digest templates.
+func (lister TemplateLister) List(database DatabaseInterface, zoneID string) (map[string]TemplateSummary, error) {
 	templates, err := lister.templatesRepo.ListIDsAndNames(database.Connection())
 	if err != nil {
 		return map[string]TemplateSummary{}, err
@@ -24,7 +26,11 @@ func (lister TemplateLister) List(database DatabaseInterface) (map[string]Templa
 
 	templatesMap := map[string]TemplateSummary{}
 	for _, template := range templates {
-		if template.ID != models.DefaultTemplateID {
+		if template.ID == models.DefaultTemplateID || template.ID == models.DigestTemplateID {
+			continue
+		}
+
+		if models.TemplateInZone(zoneID, template) {
 			templatesMap[template.ID] = TemplateSummary{Name: template.Name}
 		}
 	}
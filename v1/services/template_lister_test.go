@@ -46,6 +46,13 @@ var _ = Describe("TemplateLister", func() {
 						HTML:    "<h1>default</h1>",
 						Text:    "defaults!",
 					},
+					{
+						ID:      models.DigestTemplateID,
+						Name:    "digest name",
+						Subject: "digest subject",
+						HTML:    "<h1>digest</h1>",
+						Text:    "digest!",
+					},
 					{
 						ID:      "robot-guid",
 						Name:    "Big Hero 6",
@@ -67,28 +74,42 @@ var _ = Describe("TemplateLister", func() {
 						HTML:    "<h1>Sad</h1>",
 						Text:    "Run!!",
 					},
+					{
+						ID:     "tenant-guid",
+						Name:   "Tenant Notice",
+						ZoneID: "tenant-a",
+					},
 				}
 			})
 
 			It("returns a list of guids and template names", func() {
-				templates, err := lister.List(database)
+				templates, err := lister.List(database, models.DefaultZoneID)
 				Expect(err).ToNot(HaveOccurred())
 				Expect(templates).To(Equal(map[string]services.TemplateSummary{
 					"starwarr-guid":   {Name: "Star Wars"},
 					"robot-guid":      {Name: "Big Hero 6"},
 					"boring-guid":     {Name: "Blah"},
 					"starvation-guid": {Name: "Hungry Play"},
+					"tenant-guid":     {Name: "Tenant Notice"},
 				}))
 
 				Expect(templatesRepo.ListIDsAndNamesCall.Receives.Connection).To(Equal(conn))
 			})
+
+			It("lists only the templates of the zone to the other zones", func() {
+				templates, err := lister.List(database, "tenant-a")
+				Expect(err).ToNot(HaveOccurred())
+				Expect(templates).To(Equal(map[string]services.TemplateSummary{
+					"tenant-guid": {Name: "Tenant Notice"},
+				}))
+			})
 		})
 
 		Context("the lister has an error", func() {
 			It("propagates the error", func() {
 				templatesRepo.ListIDsAndNamesCall.Returns.Error = errors.New("some-error")
 
-				_, err := lister.List(database)
+				_, err := lister.List(database, models.DefaultZoneID)
 				Expect(err).To(MatchError(errors.New("some-error")))
 			})
 		})
@@ -0,0 +1,146 @@
+package services
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type templateProvisioner interface {
+	FindByID(conn models.ConnectionInterface, templateID string) (models.Template, error)
+	Provision(conn models.ConnectionInterface, template models.Template) (models.Template, error)
+}
+
+type partialsUpserter interface {
+	Find(conn models.ConnectionInterface, name string) (models.TemplatePartial, error)
+	Upsert(conn models.ConnectionInterface, partial models.TemplatePartial) (models.TemplatePartial, error)
+}
+
+type TemplatePackChanges struct {
+	Created   []string `json:"created"`
+	Updated   []string `json:"updated"`
+	Unchanged []string `json:"unchanged"`
+	Skipped   []string `json:"skipped,omitempty"`
+}
+
+type TemplatePackReport struct {
+	DryRun    bool                `json:"dry_run"`
+	Name      string              `json:"name"`
+	Version   int                 `json:"version"`
+	Templates TemplatePackChanges `json:"templates"`
+	Partials  TemplatePackChanges `json:"partials"`
+}
+
+type TemplatePackImporter struct {
+	templatesRepo templateProvisioner
+	partialsRepo  partialsUpserter
+}
+
+func NewTemplatePackImporter(templatesRepo templateProvisioner, partialsRepo partialsUpserter) TemplatePackImporter {
+	return TemplatePackImporter{
+		templatesRepo: templatesRepo,
+		partialsRepo:  partialsRepo,
+	}
+}
+
+// Import saves the templates and partials of the pack. Templates that were
+// edited through the API since they were provisioned are skipped unless
+// overwrite is set; partials are always replaced. Nothing that is already
+// up to date is written, and nothing at all is written during a dry run.
+func (importer TemplatePackImporter) Import(conn models.ConnectionInterface, pack templatepack.Pack, overwrite, dryRun bool) (TemplatePackReport, error) {
+	report := TemplatePackReport{
+		DryRun:    dryRun,
+		Name:      pack.Name,
+		Version:   pack.Version,
+		Templates: newTemplatePackChanges(),
+		Partials:  newTemplatePackChanges(),
+	}
+
+	for _, t := range pack.Templates {
+		template := models.Template{
+			ID:       t.ID,
+			Name:     t.Name,
+			Subject:  t.Subject,
+			Text:     t.Text,
+			HTML:     t.HTML,
+			Slack:    t.Slack,
+			Metadata: string(t.Metadata),
+		}
+
+		existing, err := importer.templatesRepo.FindByID(conn, t.ID)
+		if err != nil {
+			if _, ok := err.(models.NotFoundError); !ok {
+				return report, err
+			}
+
+			report.Templates.Created = append(report.Templates.Created, t.ID)
+		} else {
+			switch {
+			case sameTemplate(existing, template):
+				report.Templates.Unchanged = append(report.Templates.Unchanged, t.ID)
+				continue
+			case existing.Overridden && !overwrite:
+				report.Templates.Skipped = append(report.Templates.Skipped, t.ID)
+				continue
+			default:
+				report.Templates.Updated = append(report.Templates.Updated, t.ID)
+			}
+		}
+
+		if dryRun {
+			continue
+		}
+
+		_, err = importer.templatesRepo.Provision(conn, template)
+		if err != nil {
+			return report, err
+		}
+	}
+
+	for _, p := range pack.Partials {
+		existing, err := importer.partialsRepo.Find(conn, p.Name)
+		if err != nil {
+			if _, ok := err.(models.NotFoundError); !ok {
+				return report, err
+			}
+
+			report.Partials.Created = append(report.Partials.Created, p.Name)
+		} else if existing.Text == p.Text && existing.HTML == p.HTML {
+			report.Partials.Unchanged = append(report.Partials.Unchanged, p.Name)
+			continue
+		} else {
+			report.Partials.Updated = append(report.Partials.Updated, p.Name)
+		}
+
+		if dryRun {
+			continue
+		}
+
+		_, err = importer.partialsRepo.Upsert(conn, models.TemplatePartial{
+			Name: p.Name,
+			Text: p.Text,
+			HTML: p.HTML,
+		})
+		if err != nil {
+			return report, err
+		}
+	}
+
+	return report, nil
+}
+
+func newTemplatePackChanges() TemplatePackChanges {
+	return TemplatePackChanges{
+		Created:   []string{},
+		Updated:   []string{},
+		Unchanged: []string{},
+	}
+}
+
+func sameTemplate(a, b models.Template) bool {
+	return a.Name == b.Name &&
+		a.Subject == b.Subject &&
+		a.Text == b.Text &&
+		a.HTML == b.HTML &&
+		a.Slack == b.Slack &&
+		a.Metadata == b.Metadata
+}
@@ -0,0 +1,164 @@
+package services_test
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplatePackImporter", func() {
+	var (
+		importer      services.TemplatePackImporter
+		templatesRepo *mocks.TemplatesRepo
+		partialsRepo  *mocks.TemplatePartialsRepo
+		conn          *mocks.Connection
+		pack          templatepack.Pack
+		packed        models.Template
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		templatesRepo = mocks.NewTemplatesRepo()
+		templatesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+		partialsRepo = mocks.NewTemplatePartialsRepo()
+		partialsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		pack = templatepack.Pack{
+			Version: 1,
+			Name:    "acme-platform",
+			Templates: []templatepack.Template{
+				{
+					ID:       "password-reset",
+					Name:     "Password reset",
+					Subject:  "Reset: {{.Subject}}",
+					Text:     "{{.Text}}",
+					HTML:     "<p>{{.HTML}}</p>",
+					Slack:    "*{{.Subject}}*",
+					Metadata: json.RawMessage(`{"owner":"identity"}`),
+				},
+			},
+			Partials: []templatepack.Partial{
+				{Name: "footer", Text: "-- ACME", HTML: "<footer>ACME</footer>"},
+			},
+		}
+
+		packed = models.Template{
+			ID:       "password-reset",
+			Name:     "Password reset",
+			Subject:  "Reset: {{.Subject}}",
+			Text:     "{{.Text}}",
+			HTML:     "<p>{{.HTML}}</p>",
+			Slack:    "*{{.Subject}}*",
+			Metadata: `{"owner":"identity"}`,
+		}
+
+		importer = services.NewTemplatePackImporter(templatesRepo, partialsRepo)
+	})
+
+	It("provisions templates and partials that do not exist yet", func() {
+		report, err := importer.Import(conn, pack, false, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(templatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("password-reset"))
+		Expect(templatesRepo.ProvisionCall.Receives.Connection).To(Equal(conn))
+		Expect(templatesRepo.ProvisionCall.Receives.Templates).To(Equal([]models.Template{packed}))
+		Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.TemplatePartial{
+			Name: "footer",
+			Text: "-- ACME",
+			HTML: "<footer>ACME</footer>",
+		}))
+
+		Expect(report).To(Equal(services.TemplatePackReport{
+			Name:    "acme-platform",
+			Version: 1,
+			Templates: services.TemplatePackChanges{
+				Created:   []string{"password-reset"},
+				Updated:   []string{},
+				Unchanged: []string{},
+			},
+			Partials: services.TemplatePackChanges{
+				Created:   []string{"footer"},
+				Updated:   []string{},
+				Unchanged: []string{},
+			},
+		}))
+	})
+
+	It("does not write what is already up to date", func() {
+		templatesRepo.FindByIDCall.Returns.Error = nil
+		templatesRepo.FindByIDCall.Returns.Template = packed
+		partialsRepo.FindCall.Returns.Error = nil
+		partialsRepo.FindCall.Returns.Partial = models.TemplatePartial{Name: "footer", Text: "-- ACME", HTML: "<footer>ACME</footer>"}
+
+		report, err := importer.Import(conn, pack, false, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(templatesRepo.ProvisionCall.CallCount).To(Equal(0))
+		Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.TemplatePartial{}))
+		Expect(report.Templates.Unchanged).To(Equal([]string{"password-reset"}))
+		Expect(report.Partials.Unchanged).To(Equal([]string{"footer"}))
+	})
+
+	It("updates templates that were not edited through the API", func() {
+		templatesRepo.FindByIDCall.Returns.Error = nil
+		templatesRepo.FindByIDCall.Returns.Template = models.Template{ID: "password-reset", Name: "Old"}
+
+		report, err := importer.Import(conn, pack, false, false)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(templatesRepo.ProvisionCall.Receives.Templates).To(Equal([]models.Template{packed}))
+		Expect(report.Templates.Updated).To(Equal([]string{"password-reset"}))
+	})
+
+	Context("when a template was edited through the API", func() {
+		BeforeEach(func() {
+			templatesRepo.FindByIDCall.Returns.Error = nil
+			templatesRepo.FindByIDCall.Returns.Template = models.Template{ID: "password-reset", Name: "Edited", Overridden: true}
+		})
+
+		It("skips it", func() {
+			report, err := importer.Import(conn, pack, false, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(templatesRepo.ProvisionCall.CallCount).To(Equal(0))
+			Expect(report.Templates.Skipped).To(Equal([]string{"password-reset"}))
+		})
+
+		It("replaces it when asked to overwrite", func() {
+			report, err := importer.Import(conn, pack, true, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(templatesRepo.ProvisionCall.Receives.Templates).To(Equal([]models.Template{packed}))
+			Expect(report.Templates.Updated).To(Equal([]string{"password-reset"}))
+		})
+	})
+
+	Context("during a dry run", func() {
+		It("reports the changes without writing them", func() {
+			report, err := importer.Import(conn, pack, false, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(templatesRepo.ProvisionCall.CallCount).To(Equal(0))
+			Expect(partialsRepo.UpsertCall.Receives.Partial).To(Equal(models.TemplatePartial{}))
+			Expect(report.DryRun).To(BeTrue())
+			Expect(report.Templates.Created).To(Equal([]string{"password-reset"}))
+			Expect(report.Partials.Created).To(Equal([]string{"footer"}))
+		})
+	})
+
+	Context("when a template cannot be saved", func() {
+		It("returns the error", func() {
+			templatesRepo.ProvisionCall.Returns.Error = errors.New("database is down")
+
+			_, err := importer.Import(conn, pack, false, false)
+			Expect(err).To(MatchError(errors.New("database is down")))
+		})
+	})
+})
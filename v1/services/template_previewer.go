@@ -0,0 +1,98 @@
+package services
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/pivotal-golang/conceal"
+)
+
+type packer interface {
+	Pack(context common.MessageContext) (mail.Message, error)
+}
+
+type PreviewVariables struct {
+	Subject           string
+	Text              string
+	HTML              string
+	KindID            string
+	KindDescription   string
+	SourceDescription string
+	ClientID          string
+	MessageID         string
+	UserGUID          string
+	Email             string
+	ReplyTo           string
+	Space             string
+	Organization      string
+	OrganizationRole  string
+	Scope             string
+	Endorsement       string
+}
+
+type TemplatePreview struct {
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type TemplatePreviewer struct {
+	packager packer
+	cloak    conceal.CloakInterface
+	sender   string
+	domain   string
+}
+
+func NewTemplatePreviewer(packager packer, cloak conceal.CloakInterface, sender, domain string) TemplatePreviewer {
+	return TemplatePreviewer{
+		packager: packager,
+		cloak:    cloak,
+		sender:   sender,
+		domain:   domain,
+	}
+}
+
+func (p TemplatePreviewer) Preview(templates common.Templates, variables PreviewVariables) (TemplatePreview, error) {
+	delivery := common.Delivery{
+		MessageID:    variables.MessageID,
+		UserGUID:     variables.UserGUID,
+		Email:        variables.Email,
+		ClientID:     variables.ClientID,
+		Scope:        variables.Scope,
+		Space:        cf.CloudControllerSpace{Name: variables.Space},
+		Organization: cf.CloudControllerOrganization{Name: variables.Organization},
+		Options: common.Options{
+			ReplyTo:           variables.ReplyTo,
+			Subject:           variables.Subject,
+			KindID:            variables.KindID,
+			KindDescription:   variables.KindDescription,
+			SourceDescription: variables.SourceDescription,
+			Text:              variables.Text,
+			HTML:              common.HTML{BodyContent: variables.HTML},
+			Role:              variables.OrganizationRole,
+			Endorsement:       variables.Endorsement,
+		},
+	}
+
+	context := common.NewMessageContext(delivery, p.sender, p.domain, p.cloak, templates)
+
+	message, err := p.packager.Pack(context)
+	if err != nil {
+		return TemplatePreview{}, TemplatePreviewError{err}
+	}
+
+	preview := TemplatePreview{
+		Subject: message.Subject,
+	}
+
+	for _, part := range message.Body {
+		switch part.ContentType {
+		case "text/plain":
+			preview.Text = part.Content
+		case "text/html":
+			preview.HTML = part.Content
+		}
+	}
+
+	return preview, nil
+}
@@ -0,0 +1,97 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplatePreviewer", func() {
+	var (
+		previewer services.TemplatePreviewer
+		packager  *mocks.Packager
+		cloak     *mocks.Cloak
+		templates common.Templates
+		variables services.PreviewVariables
+	)
+
+	BeforeEach(func() {
+		packager = mocks.NewPackager()
+		packager.PackCall.Returns.Message = mail.Message{
+			Subject: "rendered subject",
+			Body: []mail.Part{
+				{ContentType: "text/plain", Content: "rendered text"},
+				{ContentType: "text/html", Content: "<p>rendered html</p>"},
+			},
+		}
+
+		cloak = mocks.NewCloak()
+		cloak.VeilCall.Returns.CipherText = []byte("some-unsubscribe-id")
+
+		templates = common.Templates{
+			Subject: "{{.Subject}}",
+			Text:    "{{.Text}}",
+			HTML:    "<p>{{.HTML}}</p>",
+		}
+
+		variables = services.PreviewVariables{
+			Subject:         "the subject",
+			Text:            "the text",
+			HTML:            "the html",
+			KindID:          "some-kind",
+			KindDescription: "Some Kind",
+			ClientID:        "some-client",
+			UserGUID:        "some-user",
+			Email:           "user@example.com",
+			Space:           "some-space",
+			Organization:    "some-org",
+		}
+
+		previewer = services.NewTemplatePreviewer(packager, cloak, "sender@example.com", "example.com")
+	})
+
+	It("packs a message context built from the templates and variables", func() {
+		_, err := previewer.Preview(templates, variables)
+		Expect(err).NotTo(HaveOccurred())
+
+		context := packager.PackCall.Receives.MessageContext
+		Expect(context.From).To(Equal("sender@example.com"))
+		Expect(context.Domain).To(Equal("example.com"))
+		Expect(context.To).To(Equal("user@example.com"))
+		Expect(context.Subject).To(Equal("the subject"))
+		Expect(context.Text).To(Equal("the text"))
+		Expect(context.HTML).To(Equal("the html"))
+		Expect(context.KindDescription).To(Equal("Some Kind"))
+		Expect(context.Space).To(Equal("some-space"))
+		Expect(context.Organization).To(Equal("some-org"))
+		Expect(context.SubjectTemplate).To(Equal("{{.Subject}}"))
+		Expect(context.TextTemplate).To(Equal("{{.Text}}"))
+		Expect(context.HTMLTemplate).To(Equal("<p>{{.HTML}}</p>"))
+		Expect(context.UnsubscribeID).To(Equal("some-unsubscribe-id"))
+	})
+
+	It("returns the rendered subject and parts", func() {
+		preview, err := previewer.Preview(templates, variables)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(preview).To(Equal(services.TemplatePreview{
+			Subject: "rendered subject",
+			Text:    "rendered text",
+			HTML:    "<p>rendered html</p>",
+		}))
+	})
+
+	Context("when the packager fails", func() {
+		It("returns a template preview error", func() {
+			packager.PackCall.Returns.Error = errors.New("template: compileTemplate:1: unexpected \"}\" in operand")
+
+			_, err := previewer.Preview(templates, variables)
+			Expect(err).To(Equal(services.TemplatePreviewError{errors.New("template: compileTemplate:1: unexpected \"}\" in operand")}))
+		})
+	})
+})
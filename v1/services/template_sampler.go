@@ -0,0 +1,278 @@
+package services
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/pivotal-golang/conceal"
+)
+
+const (
+	SampleUnchanged = "unchanged"
+	SampleChanged   = "changed"
+	SampleFailed    = "failed"
+)
+
+// maxDiffCells bounds the work of diffing two renderings line by line. Larger
+// renderings are reported as replaced in full.
+const maxDiffCells = 1000000
+
+type payloadSamplesFinder interface {
+	FindRecent(conn models.ConnectionInterface, clientID, kindID string, limit int) ([]models.PayloadSample, error)
+}
+
+type samplePartialsLister interface {
+	FindAll(conn models.ConnectionInterface) ([]models.TemplatePartial, error)
+}
+
+// TemplateSamplePreview is how a proposed template renders a notification
+// that was sent, compared with the template it was sent with. Diff has the
+// lines of the subject, text and html that changed, removed lines prefixed
+// with "-" and added ones with "+".
+type TemplateSamplePreview struct {
+	MessageID     string
+	VCAPRequestID string
+	SampledAt     time.Time
+	Status        string
+	Error         string
+	Diff          map[string][]string
+}
+
+type TemplateSampler struct {
+	samplesRepo   payloadSamplesFinder
+	clientsRepo   ClientsRepo
+	kindsRepo     KindsRepo
+	templatesRepo TemplatesRepo
+	partialsRepo  samplePartialsLister
+	packager      common.Packager
+	cloak         conceal.CloakInterface
+	sender        string
+	domain        string
+}
+
+func NewTemplateSampler(samplesRepo payloadSamplesFinder, clientsRepo ClientsRepo, kindsRepo KindsRepo, templatesRepo TemplatesRepo,
+	partialsRepo samplePartialsLister, packager common.Packager, cloak conceal.CloakInterface, sender, domain string) TemplateSampler {
+	return TemplateSampler{
+		samplesRepo:   samplesRepo,
+		clientsRepo:   clientsRepo,
+		kindsRepo:     kindsRepo,
+		templatesRepo: templatesRepo,
+		partialsRepo:  partialsRepo,
+		packager:      packager,
+		cloak:         cloak,
+		sender:        sender,
+		domain:        domain,
+	}
+}
+
+// Preview renders the latest count notifications of the kind with the
+// proposed templates. The proposed templates are rendered strictly, so that
+// a variable one of the notifications does not have fails its preview
+// instead of cutting the message short.
+func (sampler TemplateSampler) Preview(database DatabaseInterface, proposed common.Templates, clientID, kindID string, count int) ([]TemplateSamplePreview, error) {
+	conn := database.Connection()
+
+	current, err := sampler.currentTemplates(conn, clientID, kindID)
+	if err != nil {
+		return nil, err
+	}
+
+	partials, err := sampler.partialsRepo.FindAll(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, partial := range partials {
+		proposed.Partials = append(proposed.Partials, common.Partial{
+			Name: partial.Name,
+			Text: partial.Text,
+			HTML: partial.HTML,
+		})
+	}
+	current.Partials = proposed.Partials
+
+	samples, err := sampler.samplesRepo.FindRecent(conn, clientID, kindID, count)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := []TemplateSamplePreview{}
+	for _, sample := range samples {
+		previews = append(previews, sampler.preview(sample, current, proposed))
+	}
+
+	return previews, nil
+}
+
+// currentTemplates finds the templates the kind is sent with, as the worker
+// does.
+func (sampler TemplateSampler) currentTemplates(conn models.ConnectionInterface, clientID, kindID string) (common.Templates, error) {
+	kind, err := sampler.kindsRepo.Find(conn, kindID, clientID)
+	if err != nil {
+		return common.Templates{}, err
+	}
+
+	templateID := kind.TemplateID
+	if templateID == models.DefaultTemplateID {
+		client, err := sampler.clientsRepo.Find(conn, clientID)
+		if err != nil {
+			return common.Templates{}, err
+		}
+
+		templateID = client.TemplateID
+	}
+
+	template, err := sampler.templatesRepo.FindByID(conn, templateID)
+	if err != nil {
+		return common.Templates{}, err
+	}
+
+	return common.Templates{
+		Name:    template.Name,
+		Subject: template.Subject,
+		Text:    template.Text,
+		HTML:    template.HTML,
+	}, nil
+}
+
+func (sampler TemplateSampler) preview(sample models.PayloadSample, current, proposed common.Templates) TemplateSamplePreview {
+	preview := TemplateSamplePreview{
+		SampledAt: sample.CreatedAt,
+		Status:    SampleUnchanged,
+	}
+
+	var delivery common.Delivery
+	err := json.Unmarshal([]byte(sample.Delivery), &delivery)
+	if err != nil {
+		preview.Status = SampleFailed
+		preview.Error = err.Error()
+		return preview
+	}
+	preview.MessageID = delivery.MessageID
+	preview.VCAPRequestID = delivery.VCAPRequestID
+
+	// Both renderings share one context, so that values it derives anew each
+	// time, such as the unsubscribe ID, do not show up as changes.
+	context := common.NewMessageContext(delivery, sampler.sender, sampler.domain, sampler.cloak, current)
+
+	// A notification the current template cannot render is compared with
+	// nothing, so that all of the proposed rendering shows as added.
+	before, _ := sampler.render(sampler.packager, context, current)
+
+	after, err := sampler.render(sampler.packager.Strict(), context, proposed)
+	if err != nil {
+		preview.Status = SampleFailed
+		preview.Error = err.Error()
+		return preview
+	}
+
+	for _, part := range []string{"subject", "text", "html"} {
+		if diff := lineDiff(before[part], after[part]); len(diff) > 0 {
+			if preview.Diff == nil {
+				preview.Diff = map[string][]string{}
+			}
+			preview.Diff[part] = diff
+			preview.Status = SampleChanged
+		}
+	}
+
+	return preview
+}
+
+func (sampler TemplateSampler) render(packager common.Packager, context common.MessageContext, templates common.Templates) (map[string]string, error) {
+	context.SubjectTemplate = templates.Subject
+	context.TextTemplate = templates.Text
+	context.HTMLTemplate = templates.HTML
+	context.Partials = templates.Partials
+
+	message, err := packager.Pack(context)
+	if err != nil {
+		return map[string]string{}, err
+	}
+
+	rendered := map[string]string{"subject": message.Subject}
+	for _, part := range message.Body {
+		switch part.ContentType {
+		case "text/plain":
+			rendered["text"] = part.Content
+		case "text/html":
+			rendered["html"] = part.Content
+		}
+	}
+
+	return rendered, nil
+}
+
+// lineDiff lists the lines removed from before and added in after, in the
+// order they appear, leaving out the lines the two have in common.
+func lineDiff(before, after string) []string {
+	if before == after {
+		return nil
+	}
+
+	a, b := splitLines(before), splitLines(after)
+
+	var diff []string
+	if len(a)*len(b) > maxDiffCells {
+		for _, line := range a {
+			diff = append(diff, "-"+line)
+		}
+		for _, line := range b {
+			diff = append(diff, "+"+line)
+		}
+		return diff
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			case lcs[i+1][j] >= lcs[i][j+1]:
+				lcs[i][j] = lcs[i+1][j]
+			default:
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, "-"+a[i])
+			i++
+		default:
+			diff = append(diff, "+"+b[j])
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, "-"+a[i])
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, "+"+b[j])
+	}
+
+	return diff
+}
+
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
@@ -0,0 +1,153 @@
+package services_test
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateSampler", func() {
+	var (
+		sampler       services.TemplateSampler
+		samplesRepo   *mocks.PayloadSamplesRepo
+		clientsRepo   *mocks.ClientsRepository
+		kindsRepo     *mocks.KindsRepo
+		templatesRepo *mocks.TemplatesRepo
+		partialsRepo  *mocks.TemplatePartialsRepo
+		database      *mocks.Database
+		conn          *mocks.Connection
+		sampledAt     time.Time
+		proposed      common.Templates
+	)
+
+	sample := func(delivery services.Delivery) models.PayloadSample {
+		encoded, err := json.Marshal(delivery)
+		Expect(err).NotTo(HaveOccurred())
+
+		return models.PayloadSample{ClientID: "some-client", KindID: "some-kind", Delivery: string(encoded), CreatedAt: sampledAt}
+	}
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		sampledAt = time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+
+		samplesRepo = mocks.NewPayloadSamplesRepo()
+		samplesRepo.FindRecentCall.Returns.Samples = []models.PayloadSample{
+			sample(services.Delivery{
+				MessageID:     "message-with-space",
+				VCAPRequestID: "some-request-id",
+				ClientID:      "some-client",
+				Space:         cf.CloudControllerSpace{Name: "the-space"},
+				Options:       services.Options{KindID: "some-kind", Subject: "the subject", Text: "the text"},
+			}),
+			sample(services.Delivery{
+				MessageID: "message-without-space",
+				ClientID:  "some-client",
+				Options:   services.Options{KindID: "some-kind", Subject: "the subject", Text: "the text"},
+			}),
+		}
+
+		kindsRepo = mocks.NewKindsRepo()
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "some-kind", TemplateID: models.DefaultTemplateID}}
+
+		clientsRepo = mocks.NewClientsRepository()
+		clientsRepo.FindCall.Returns.Client = models.Client{ID: "some-client", TemplateID: "client-template"}
+
+		templatesRepo = mocks.NewTemplatesRepo()
+		templatesRepo.FindByIDCall.Returns.Template = models.Template{
+			ID:      "client-template",
+			Subject: "{{.Subject}}",
+			Text:    "{{.Text}}\n{{template \"footer\" .}}",
+		}
+
+		partialsRepo = mocks.NewTemplatePartialsRepo()
+		partialsRepo.FindAllCall.Returns.Partials = []models.TemplatePartial{{Name: "footer", Text: "-- sent by {{.ClientID}}"}}
+
+		proposed = common.Templates{
+			Subject: "{{.Subject}}",
+			Text:    "{{.Text}}\n{{template \"footer\" .}}",
+		}
+
+		cloak := mocks.NewCloak()
+		sampler = services.NewTemplateSampler(samplesRepo, clientsRepo, kindsRepo, templatesRepo, partialsRepo,
+			common.NewPackager(nil, cloak), cloak, "sender@example.com", "example.com")
+	})
+
+	It("finds the latest samples of the kind", func() {
+		_, err := sampler.Preview(database, proposed, "some-client", "some-kind", 5)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(samplesRepo.FindRecentCall.Receives.Connection).To(Equal(conn))
+		Expect(samplesRepo.FindRecentCall.Receives.ClientID).To(Equal("some-client"))
+		Expect(samplesRepo.FindRecentCall.Receives.KindID).To(Equal("some-kind"))
+		Expect(samplesRepo.FindRecentCall.Receives.Limit).To(Equal(5))
+	})
+
+	It("reports the samples that render as they were sent as unchanged", func() {
+		previews, err := sampler.Preview(database, proposed, "some-client", "some-kind", 5)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(templatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("client-template"))
+		Expect(previews).To(Equal([]services.TemplateSamplePreview{
+			{MessageID: "message-with-space", VCAPRequestID: "some-request-id", SampledAt: sampledAt, Status: services.SampleUnchanged},
+			{MessageID: "message-without-space", SampledAt: sampledAt, Status: services.SampleUnchanged},
+		}))
+	})
+
+	It("reports the lines the proposed templates change", func() {
+		proposed.Text = "Hello,\n{{.Text}}\n{{template \"footer\" .}}"
+
+		previews, err := sampler.Preview(database, proposed, "some-client", "some-kind", 5)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(previews[0].Status).To(Equal(services.SampleChanged))
+		Expect(previews[0].Diff).To(Equal(map[string][]string{"text": {"+Hello,"}}))
+	})
+
+	It("fails the samples that do not have a value the proposed templates rely on", func() {
+		proposed.Text = "{{.Text}} in {{slice .Space 0 3}}"
+
+		previews, err := sampler.Preview(database, proposed, "some-client", "some-kind", 5)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(previews[0].Status).To(Equal(services.SampleChanged))
+		Expect(previews[0].Diff["text"]).To(Equal([]string{"-the text", "--- sent by some-client", "+the text in the"}))
+		Expect(previews[1].Status).To(Equal(services.SampleFailed))
+		Expect(previews[1].Error).To(ContainSubstring("index out of range"))
+	})
+
+	It("renders the kind with its own template when it has one", func() {
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "some-kind", TemplateID: "kind-template"}}
+
+		_, err := sampler.Preview(database, proposed, "some-client", "some-kind", 5)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(templatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("kind-template"))
+	})
+
+	It("returns the error when the kind cannot be found", func() {
+		kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("kind not found")}
+
+		_, err := sampler.Preview(database, proposed, "some-client", "some-kind", 5)
+		Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("kind not found")}))
+	})
+
+	It("returns the error when the samples cannot be found", func() {
+		samplesRepo.FindRecentCall.Returns.Error = errors.New("database is down")
+
+		_, err := sampler.Preview(database, proposed, "some-client", "some-kind", 5)
+		Expect(err).To(MatchError("database is down"))
+	})
+})
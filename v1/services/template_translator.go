@@ -0,0 +1,34 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type TemplateTranslator struct {
+	templatesRepo    TemplatesRepo
+	translationsRepo TemplateTranslationsRepo
+}
+
+func NewTemplateTranslator(templatesRepo TemplatesRepo, translationsRepo TemplateTranslationsRepo) TemplateTranslator {
+	return TemplateTranslator{
+		templatesRepo:    templatesRepo,
+		translationsRepo: translationsRepo,
+	}
+}
+
+func (translator TemplateTranslator) Set(database DatabaseInterface, translation models.TemplateTranslation) (models.TemplateTranslation, error) {
+	conn := database.Connection()
+
+	_, err := translator.templatesRepo.FindByID(conn, translation.TemplateID)
+	if err != nil {
+		return models.TemplateTranslation{}, err
+	}
+
+	return translator.translationsRepo.Upsert(conn, translation)
+}
+
+func (translator TemplateTranslator) Find(database DatabaseInterface, templateID, locale string) (models.TemplateTranslation, error) {
+	return translator.translationsRepo.Find(database.Connection(), templateID, locale)
+}
+
+func (translator TemplateTranslator) Delete(database DatabaseInterface, templateID, locale string) error {
+	return translator.translationsRepo.Destroy(database.Connection(), templateID, locale)
+}
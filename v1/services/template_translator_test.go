@@ -0,0 +1,92 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateTranslator", func() {
+	var (
+		conn             *mocks.Connection
+		database         *mocks.Database
+		templatesRepo    *mocks.TemplatesRepo
+		translationsRepo *mocks.TemplateTranslationsRepo
+		translator       services.TemplateTranslator
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+		templatesRepo = mocks.NewTemplatesRepo()
+		translationsRepo = mocks.NewTemplateTranslationsRepo()
+
+		translator = services.NewTemplateTranslator(templatesRepo, translationsRepo)
+	})
+
+	Describe("Set", func() {
+		It("upserts the translation of an existing template", func() {
+			translationsRepo.UpsertCall.Returns.Translation = models.TemplateTranslation{
+				TemplateID: "template-id",
+				Locale:     "fr",
+				HTML:       "<p>Bonjour</p>",
+			}
+
+			translation, err := translator.Set(database, models.TemplateTranslation{
+				TemplateID: "template-id",
+				Locale:     "FR",
+				HTML:       "<p>Bonjour</p>",
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(translation.Locale).To(Equal("fr"))
+
+			Expect(templatesRepo.FindByIDCall.Receives.Connection).To(Equal(conn))
+			Expect(templatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("template-id"))
+			Expect(translationsRepo.UpsertCall.Receives.Connection).To(Equal(conn))
+			Expect(translationsRepo.UpsertCall.Receives.Translation).To(Equal(models.TemplateTranslation{
+				TemplateID: "template-id",
+				Locale:     "FR",
+				HTML:       "<p>Bonjour</p>",
+			}))
+		})
+
+		It("returns an error when the template cannot be found", func() {
+			templatesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			_, err := translator.Set(database, models.TemplateTranslation{TemplateID: "missing"})
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
+			Expect(translationsRepo.UpsertCall.Receives.Translation).To(Equal(models.TemplateTranslation{}))
+		})
+	})
+
+	Describe("Find", func() {
+		It("finds the translation", func() {
+			translationsRepo.FindCall.Returns.Translations = map[string]models.TemplateTranslation{
+				"fr": {TemplateID: "template-id", Locale: "fr"},
+			}
+
+			translation, err := translator.Find(database, "template-id", "fr")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(translation).To(Equal(models.TemplateTranslation{TemplateID: "template-id", Locale: "fr"}))
+			Expect(translationsRepo.FindCall.Receives.Connection).To(Equal(conn))
+		})
+	})
+
+	Describe("Delete", func() {
+		It("destroys the translation", func() {
+			translationsRepo.DestroyCall.Returns.Error = errors.New("boom")
+
+			err := translator.Delete(database, "template-id", "fr")
+			Expect(err).To(MatchError(errors.New("boom")))
+			Expect(translationsRepo.DestroyCall.Receives.Connection).To(Equal(conn))
+			Expect(translationsRepo.DestroyCall.Receives.TemplateID).To(Equal("template-id"))
+			Expect(translationsRepo.DestroyCall.Receives.Locale).To(Equal("fr"))
+		})
+	})
+})
@@ -0,0 +1,49 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type templateVersionsRepo interface {
+	FindByID(connection models.ConnectionInterface, templateID string) (models.Template, error)
+	FindVersions(connection models.ConnectionInterface, templateID string) ([]models.TemplateVersion, error)
+	ActivateVersion(connection models.ConnectionInterface, templateID string, version int) (models.Template, error)
+}
+
+// TemplateVersions is the history of a template along with the version
+// that notifications are currently rendered with.
+type TemplateVersions struct {
+	Active   int
+	Versions []models.TemplateVersion
+}
+
+type TemplateVersioner struct {
+	templatesRepo templateVersionsRepo
+}
+
+func NewTemplateVersioner(templatesRepo templateVersionsRepo) TemplateVersioner {
+	return TemplateVersioner{
+		templatesRepo: templatesRepo,
+	}
+}
+
+func (versioner TemplateVersioner) List(database DatabaseInterface, templateID string) (TemplateVersions, error) {
+	conn := database.Connection()
+
+	template, err := versioner.templatesRepo.FindByID(conn, templateID)
+	if err != nil {
+		return TemplateVersions{}, err
+	}
+
+	versions, err := versioner.templatesRepo.FindVersions(conn, templateID)
+	if err != nil {
+		return TemplateVersions{}, err
+	}
+
+	return TemplateVersions{
+		Active:   template.Version,
+		Versions: versions,
+	}, nil
+}
+
+func (versioner TemplateVersioner) Activate(database DatabaseInterface, templateID string, version int) (models.Template, error) {
+	return versioner.templatesRepo.ActivateVersion(database.Connection(), templateID, version)
+}
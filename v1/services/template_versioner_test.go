@@ -0,0 +1,78 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TemplateVersioner", func() {
+	var (
+		conn          *mocks.Connection
+		database      *mocks.Database
+		templatesRepo *mocks.TemplatesRepo
+		versioner     services.TemplateVersioner
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+		templatesRepo = mocks.NewTemplatesRepo()
+
+		versioner = services.NewTemplateVersioner(templatesRepo)
+	})
+
+	Describe("List", func() {
+		It("lists the versions of the template", func() {
+			templatesRepo.FindByIDCall.Returns.Template = models.Template{ID: "some-template", Version: 1}
+			templatesRepo.FindVersionsCall.Returns.Versions = []models.TemplateVersion{
+				{TemplateID: "some-template", Version: 2},
+				{TemplateID: "some-template", Version: 1},
+			}
+
+			versions, err := versioner.List(database, "some-template")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions.Active).To(Equal(1))
+			Expect(versions.Versions).To(HaveLen(2))
+
+			Expect(templatesRepo.FindByIDCall.Receives.TemplateID).To(Equal("some-template"))
+
+			Expect(templatesRepo.FindVersionsCall.Receives.Connection).To(Equal(conn))
+			Expect(templatesRepo.FindVersionsCall.Receives.TemplateID).To(Equal("some-template"))
+		})
+
+		It("returns an error when the template cannot be found", func() {
+			templatesRepo.FindByIDCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			_, err := versioner.List(database, "some-template")
+			Expect(err).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
+		})
+	})
+
+	Describe("Activate", func() {
+		It("activates the version of the template", func() {
+			templatesRepo.ActivateVersionCall.Returns.Template = models.Template{ID: "some-template", Version: 1}
+
+			template, err := versioner.Activate(database, "some-template", 1)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(template.Version).To(Equal(1))
+
+			Expect(templatesRepo.ActivateVersionCall.Receives.Connection).To(Equal(conn))
+			Expect(templatesRepo.ActivateVersionCall.Receives.TemplateID).To(Equal("some-template"))
+			Expect(templatesRepo.ActivateVersionCall.Receives.Version).To(Equal(1))
+		})
+
+		It("returns errors from the repo", func() {
+			templatesRepo.ActivateVersionCall.Returns.Error = errors.New("boom")
+
+			_, err := versioner.Activate(database, "some-template", 1)
+			Expect(err).To(MatchError("boom"))
+		})
+	})
+})
@@ -0,0 +1,107 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/cf"
+
+// GroupEndorsement is rendered with the name of the group as its scope: UAA
+// grants the members of a group the scope of the same name.
+const GroupEndorsement = "You received this message because you are a member of the {{.Scope}} group."
+
+type groupUserIDFinder interface {
+	UserIDsBelongingToGroup(token, group string) (userIDs []string, err error)
+}
+
+type UAAGroupStrategy struct {
+	findsUserIDs  groupUserIDFinder
+	tokenLoader   loadsTokens
+	enqueuer      enqueuer
+	defaultGroups []string
+	suppressions  suppressionsRepo
+}
+
+// NewUAAGroupStrategy builds a strategy that sends to the members of a UAA
+// group. The groups every user belongs to, which are the default scopes,
+// cannot be sent to.
+func NewUAAGroupStrategy(tokenLoader loadsTokens, findsUserIDs groupUserIDFinder, enqueuer enqueuer, defaultGroups []string) UAAGroupStrategy {
+	return UAAGroupStrategy{
+		findsUserIDs:  findsUserIDs,
+		tokenLoader:   tokenLoader,
+		enqueuer:      enqueuer,
+		defaultGroups: defaultGroups,
+	}
+}
+
+// WithUnsubscribeFiltering leaves out the members of the group who
+// unsubscribed, as OrganizationStrategy.WithUnsubscribeFiltering does.
+func (strategy UAAGroupStrategy) WithUnsubscribeFiltering(suppressions suppressionsRepo) UAAGroupStrategy {
+	strategy.suppressions = suppressions
+	return strategy
+}
+
+func (strategy UAAGroupStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
+	options := Options{
+		ReplyTo:           dispatch.Message.ReplyTo,
+		Subject:           dispatch.Message.Subject,
+		To:                dispatch.Message.To,
+		Endorsement:       GroupEndorsement,
+		KindID:            dispatch.Kind.ID,
+		KindDescription:   dispatch.Kind.Description,
+		SourceDescription: dispatch.Client.Description,
+		Text:              dispatch.Message.Text,
+		TemplateID:        dispatch.TemplateID,
+		CallbackURL:       dispatch.CallbackURL,
+		TraceParent:       dispatch.TraceParent,
+		SimulationSinks:   dispatch.Client.SimulationSinks,
+		Deadline:          dispatch.Deadline,
+		Metadata:          dispatch.Metadata,
+		Priority:          dispatch.priority(false),
+		Severity:          dispatch.Severity,
+		HTML: HTML{
+			BodyContent:    dispatch.Message.HTML.BodyContent,
+			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
+			Head:           dispatch.Message.HTML.Head,
+			Doctype:        dispatch.Message.HTML.Doctype,
+		},
+	}
+
+	for _, group := range strategy.defaultGroups {
+		if dispatch.GUID == group {
+			return []Response{}, DefaultScopeError{}
+		}
+	}
+
+	token, err := strategy.tokenLoader.Load(dispatch.UAAHost)
+	if err != nil {
+		return []Response{}, err
+	}
+
+	userGUIDs, err := strategy.findsUserIDs.UserIDsBelongingToGroup(token, dispatch.GUID)
+	if err != nil {
+		return []Response{}, err
+	}
+
+	users, unsubscribed, err := filterUnsubscribed(strategy.suppressions, dispatch, userGUIDs)
+	if err != nil {
+		return []Response{}, err
+	}
+
+	if len(users) == 0 && len(unsubscribed) > 0 {
+		return unsubscribed, nil
+	}
+
+	queued, err := strategy.enqueuer.Enqueue(
+		dispatch.Connection,
+		users,
+		options,
+		cf.CloudControllerSpace{},
+		cf.CloudControllerOrganization{},
+		dispatch.Client.ID,
+		dispatch.UAAHost,
+		dispatch.GUID,
+		dispatch.VCAPRequest.ID,
+		dispatch.VCAPRequest.ReceiptTime)
+	if err != nil {
+		return queued, err
+	}
+
+	return append(queued, unsubscribed...), nil
+}
@@ -0,0 +1,175 @@
+package services_test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UAA Group Strategy", func() {
+	var (
+		strategy        services.UAAGroupStrategy
+		tokenLoader     *mocks.TokenLoader
+		enqueuer        *mocks.Enqueuer
+		conn            *mocks.Connection
+		findsUserIDs    *mocks.FindsUserIDs
+		requestReceived time.Time
+		dispatch        services.Dispatch
+	)
+
+	BeforeEach(func() {
+		requestReceived, _ = time.Parse(time.RFC3339Nano, "2015-06-08T14:37:35.181067085-07:00")
+		conn = mocks.NewConnection()
+
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "some-token"
+		enqueuer = mocks.NewEnqueuer()
+
+		findsUserIDs = mocks.NewFindsUserIDs()
+		findsUserIDs.UserIDsBelongingToGroupCall.Returns.UserIDs = []string{"user-311", "user-312"}
+
+		strategy = services.NewUAAGroupStrategy(tokenLoader, findsUserIDs, enqueuer, []string{"openid", "uaa.user"})
+
+		dispatch = services.Dispatch{
+			GUID:       "raptor.keepers",
+			Connection: conn,
+			Message: services.DispatchMessage{
+				ReplyTo: "reply-to@example.com",
+				Subject: "this is the subject",
+				Text:    "The raptors are restless",
+			},
+			TemplateID: "some-template-id",
+			Kind: services.DispatchKind{
+				ID:          "raptor_alert",
+				Description: "Raptor Alert",
+			},
+			Client: services.DispatchClient{
+				ID:          "mister-client",
+				Description: "Raptor Containment",
+			},
+			VCAPRequest: services.DispatchVCAPRequest{
+				ID:          "some-vcap-request-id",
+				ReceiptTime: requestReceived,
+			},
+			UAAHost: "uaa",
+		}
+	})
+
+	Describe("Dispatch", func() {
+		It("enqueues a delivery for each member of the group", func() {
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("uaa"))
+			Expect(findsUserIDs.UserIDsBelongingToGroupCall.Receives.Token).To(Equal("some-token"))
+			Expect(findsUserIDs.UserIDsBelongingToGroupCall.Receives.Group).To(Equal("raptor.keepers"))
+
+			Expect(enqueuer.EnqueueCall.Receives.Connection).To(Equal(conn))
+			Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-311"}, {GUID: "user-312"}}))
+			Expect(enqueuer.EnqueueCall.Receives.Options).To(Equal(services.Options{
+				ReplyTo:           "reply-to@example.com",
+				Subject:           "this is the subject",
+				KindID:            "raptor_alert",
+				KindDescription:   "Raptor Alert",
+				SourceDescription: "Raptor Containment",
+				Text:              "The raptors are restless",
+				TemplateID:        "some-template-id",
+				Endorsement:       services.GroupEndorsement,
+			}))
+			Expect(enqueuer.EnqueueCall.Receives.Space).To(Equal(cf.CloudControllerSpace{}))
+			Expect(enqueuer.EnqueueCall.Receives.Org).To(Equal(cf.CloudControllerOrganization{}))
+			Expect(enqueuer.EnqueueCall.Receives.Client).To(Equal("mister-client"))
+			Expect(enqueuer.EnqueueCall.Receives.Scope).To(Equal("raptor.keepers"))
+			Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
+			Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
+		})
+
+		It("refuses to send to a group every user belongs to", func() {
+			dispatch.GUID = "openid"
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(Equal(services.DefaultScopeError{}))
+			Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+		})
+
+		It("returns the error when the token cannot be loaded", func() {
+			tokenLoader.LoadCall.Returns.Error = errors.New("BOOM!")
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError(errors.New("BOOM!")))
+		})
+
+		It("returns the error when the members cannot be found", func() {
+			findsUserIDs.UserIDsBelongingToGroupCall.Returns.Error = errors.New("BOOM!")
+
+			_, err := strategy.Dispatch(dispatch)
+			Expect(err).To(MatchError(errors.New("BOOM!")))
+			Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+		})
+
+		Context("when unsubscribed users are filtered out", func() {
+			var suppressions *mocks.SuppressionsRepo
+
+			BeforeEach(func() {
+				suppressions = mocks.NewSuppressionsRepo()
+				suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-312"}
+				strategy = strategy.WithUnsubscribeFiltering(suppressions)
+
+				enqueuer.EnqueueCall.Returns.Responses = []services.Response{
+					{Status: services.StatusQueued, Recipient: "user-311", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+				}
+
+			})
+
+			It("only enqueues the users who are still subscribed", func() {
+				responses, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(suppressions.FindUnsubscribedCall.Receives.Connection).To(Equal(conn))
+				Expect(suppressions.FindUnsubscribedCall.Receives.UserIDs).To(Equal([]string{"user-311", "user-312"}))
+				Expect(suppressions.FindUnsubscribedCall.Receives.ClientID).To(Equal("mister-client"))
+				Expect(suppressions.FindUnsubscribedCall.Receives.KindID).To(Equal("raptor_alert"))
+
+				Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-311"}}))
+				Expect(responses).To(Equal([]services.Response{
+					{Status: services.StatusQueued, Recipient: "user-311", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+					{Status: services.StatusUnsubscribed, Recipient: "user-312", VCAPRequestID: "some-vcap-request-id"},
+				}))
+			})
+
+			It("does not enqueue anything when every user unsubscribed", func() {
+				suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-311", "user-312"}
+
+				responses, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+				Expect(responses).To(HaveLen(2))
+			})
+
+			It("enqueues critical notifications for every user", func() {
+				dispatch.Kind.Critical = true
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(suppressions.FindUnsubscribedCall.WasCalled).To(BeFalse())
+				Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-311"}, {GUID: "user-312"}}))
+			})
+
+			It("returns the error when the unsubscribes cannot be found", func() {
+				suppressions.FindUnsubscribedCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).To(Equal(errors.New("BOOM!")))
+				Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+})
@@ -13,6 +13,7 @@ type UAAScopeStrategy struct {
 	tokenLoader   loadsTokens
 	enqueuer      enqueuer
 	defaultScopes []string
+	suppressions  suppressionsRepo
 }
 
 func NewUAAScopeStrategy(tokenLoader loadsTokens, findsUserIDs scopeUserIDFinder, enqueuer enqueuer, defaultScopes []string) UAAScopeStrategy {
@@ -24,6 +25,13 @@ func NewUAAScopeStrategy(tokenLoader loadsTokens, findsUserIDs scopeUserIDFinder
 	}
 }
 
+// WithUnsubscribeFiltering leaves out the users with the scope who
+// unsubscribed, as OrganizationStrategy.WithUnsubscribeFiltering does.
+func (strategy UAAScopeStrategy) WithUnsubscribeFiltering(suppressions suppressionsRepo) UAAScopeStrategy {
+	strategy.suppressions = suppressions
+	return strategy
+}
+
 func (strategy UAAScopeStrategy) Dispatch(dispatch Dispatch) ([]Response, error) {
 	responses := []Response{}
 	options := Options{
@@ -36,6 +44,13 @@ func (strategy UAAScopeStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		SourceDescription: dispatch.Client.Description,
 		Text:              dispatch.Message.Text,
 		TemplateID:        dispatch.TemplateID,
+		CallbackURL:       dispatch.CallbackURL,
+		TraceParent:       dispatch.TraceParent,
+		SimulationSinks:   dispatch.Client.SimulationSinks,
+		Deadline:          dispatch.Deadline,
+		Metadata:          dispatch.Metadata,
+		Priority:          dispatch.priority(false),
+		Severity:          dispatch.Severity,
 		HTML: HTML{
 			BodyContent:    dispatch.Message.HTML.BodyContent,
 			BodyAttributes: dispatch.Message.HTML.BodyAttributes,
@@ -58,12 +73,16 @@ func (strategy UAAScopeStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		return responses, err
 	}
 
-	var users []User
-	for _, guid := range userGUIDs {
-		users = append(users, User{GUID: guid})
+	users, unsubscribed, err := filterUnsubscribed(strategy.suppressions, dispatch, userGUIDs)
+	if err != nil {
+		return responses, err
 	}
 
-	return strategy.enqueuer.Enqueue(
+	if len(users) == 0 && len(unsubscribed) > 0 {
+		return unsubscribed, nil
+	}
+
+	queued, err := strategy.enqueuer.Enqueue(
 		dispatch.Connection,
 		users,
 		options,
@@ -74,6 +93,11 @@ func (strategy UAAScopeStrategy) Dispatch(dispatch Dispatch) ([]Response, error)
 		dispatch.GUID,
 		dispatch.VCAPRequest.ID,
 		dispatch.VCAPRequest.ReceiptTime)
+	if err != nil {
+		return queued, err
+	}
+
+	return append(queued, unsubscribed...), nil
 }
 
 func (strategy UAAScopeStrategy) scopeIsDefault(scope string) bool {
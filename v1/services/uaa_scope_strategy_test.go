@@ -133,6 +133,78 @@ var _ = Describe("UAA Scope Strategy", func() {
 			})
 		})
 
+		Context("when unsubscribed users are filtered out", func() {
+			var (
+				suppressions *mocks.SuppressionsRepo
+				dispatch     services.Dispatch
+			)
+
+			BeforeEach(func() {
+				findsUserIDs.UserIDsBelongingToScopeCall.Returns.UserIDs = []string{"user-311", "user-312"}
+				suppressions = mocks.NewSuppressionsRepo()
+				suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-312"}
+				strategy = strategy.WithUnsubscribeFiltering(suppressions)
+
+				enqueuer.EnqueueCall.Returns.Responses = []services.Response{
+					{Status: services.StatusQueued, Recipient: "user-311", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+				}
+
+				dispatch = services.Dispatch{
+					GUID:       "great.scope",
+					Connection: conn,
+					Kind:       services.DispatchKind{ID: "forgot_password"},
+					Client:     services.DispatchClient{ID: "mister-client"},
+					VCAPRequest: services.DispatchVCAPRequest{
+						ID: "some-vcap-request-id",
+					},
+				}
+			})
+
+			It("only enqueues the users who are still subscribed", func() {
+				responses, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(suppressions.FindUnsubscribedCall.Receives.Connection).To(Equal(conn))
+				Expect(suppressions.FindUnsubscribedCall.Receives.UserIDs).To(Equal([]string{"user-311", "user-312"}))
+				Expect(suppressions.FindUnsubscribedCall.Receives.ClientID).To(Equal("mister-client"))
+				Expect(suppressions.FindUnsubscribedCall.Receives.KindID).To(Equal("forgot_password"))
+
+				Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-311"}}))
+				Expect(responses).To(Equal([]services.Response{
+					{Status: services.StatusQueued, Recipient: "user-311", NotificationID: "notification-123", VCAPRequestID: "some-vcap-request-id"},
+					{Status: services.StatusUnsubscribed, Recipient: "user-312", VCAPRequestID: "some-vcap-request-id"},
+				}))
+			})
+
+			It("does not enqueue anything when every user unsubscribed", func() {
+				suppressions.FindUnsubscribedCall.Returns.UserIDs = []string{"user-311", "user-312"}
+
+				responses, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+				Expect(responses).To(HaveLen(2))
+			})
+
+			It("enqueues critical notifications for every user", func() {
+				dispatch.Kind.Critical = true
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(suppressions.FindUnsubscribedCall.WasCalled).To(BeFalse())
+				Expect(enqueuer.EnqueueCall.Receives.Users).To(Equal([]services.User{{GUID: "user-311"}, {GUID: "user-312"}}))
+			})
+
+			It("returns the error when the unsubscribes cannot be found", func() {
+				suppressions.FindUnsubscribedCall.Returns.Error = errors.New("BOOM!")
+
+				_, err := strategy.Dispatch(dispatch)
+				Expect(err).To(Equal(errors.New("BOOM!")))
+				Expect(enqueuer.EnqueueCall.WasCalled).To(BeFalse())
+			})
+		})
+
 		Context("failure cases", func() {
 			Context("when token loader fails to return a token", func() {
 				It("returns an error", func() {
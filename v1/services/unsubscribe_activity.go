@@ -0,0 +1,31 @@
+package services
+
+import (
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+// setUnsubscribe saves whether the user is unsubscribed from the kind. Only a
+// user newly unsubscribing is counted towards the kind's activity.
+func setUnsubscribe(conn ConnectionInterface, unsubscribesRepo UnsubscribesRepo, kindActivity kindActivityIncrementer, at time.Time, userID, clientID, kindID string, unsubscribe bool) error {
+	var unsubscribed bool
+	if unsubscribe {
+		var err error
+		unsubscribed, err = unsubscribesRepo.Get(conn, userID, clientID, kindID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := unsubscribesRepo.Set(conn, userID, clientID, kindID, unsubscribe)
+	if err != nil {
+		return err
+	}
+
+	if !unsubscribe || unsubscribed {
+		return nil
+	}
+
+	return kindActivity.Increment(conn, clientID, kindID, models.KindActivityUnsubscribed, at)
+}
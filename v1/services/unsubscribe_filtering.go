@@ -0,0 +1,42 @@
+package services
+
+import "github.com/cloudfoundry-incubator/notifications/v1/models"
+
+type suppressionsRepo interface {
+	FindUnsubscribed(conn models.ConnectionInterface, userIDs []string, clientID, kindID string) ([]string, error)
+}
+
+// filterUnsubscribed splits the users into those to queue and the responses
+// of those who unsubscribed from the kind, or from everything, so that the
+// strategies do not queue jobs the workers would discard. Critical kinds
+// reach everyone, and nothing is left out without a suppressions repo.
+func filterUnsubscribed(suppressions suppressionsRepo, dispatch Dispatch, userGUIDs []string) ([]User, []Response, error) {
+	suppressed := map[string]bool{}
+	if suppressions != nil && !dispatch.Kind.Critical && len(userGUIDs) > 0 {
+		unsubscribed, err := suppressions.FindUnsubscribed(dispatch.Connection, userGUIDs, dispatch.Client.ID, dispatch.Kind.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		for _, guid := range unsubscribed {
+			suppressed[guid] = true
+		}
+	}
+
+	var users []User
+	var responses []Response
+	for _, guid := range userGUIDs {
+		if suppressed[guid] {
+			responses = append(responses, Response{
+				Status:        StatusUnsubscribed,
+				Recipient:     guid,
+				VCAPRequestID: dispatch.VCAPRequest.ID,
+			})
+			continue
+		}
+
+		users = append(users, User{GUID: guid})
+	}
+
+	return users, responses, nil
+}
@@ -0,0 +1,186 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+)
+
+type usersGUIDsByEmail interface {
+	UsersGUIDsByEmail(token, email string) ([]string, error)
+}
+
+type unsubscribesGetSetter interface {
+	Get(connection models.ConnectionInterface, userID, clientID, kindID string) (bool, error)
+	Set(connection models.ConnectionInterface, userID, clientID, kindID string, unsubscribe bool) error
+}
+
+type UnsubscribeImportEntry struct {
+	Line     int
+	User     string
+	ClientID string
+	KindID   string
+}
+
+type UnsubscribeImportChange struct {
+	UserID   string `json:"user_id"`
+	ClientID string `json:"client_id,omitempty"`
+	KindID   string `json:"kind_id,omitempty"`
+}
+
+type UnsubscribeImportReport struct {
+	DryRun    bool                      `json:"dry_run"`
+	Rows      int                       `json:"rows"`
+	Unchanged int                       `json:"unchanged"`
+	Changes   []UnsubscribeImportChange `json:"changes"`
+}
+
+type UnsubscribeImporter struct {
+	tokenLoader              loadsTokens
+	uaa                      usersGUIDsByEmail
+	kindsRepo                KindsRepo
+	unsubscribesRepo         unsubscribesGetSetter
+	criticalUnsubscribesRepo unsubscribesGetSetter
+	globalUnsubscribesRepo   GlobalUnsubscribesRepo
+}
+
+func NewUnsubscribeImporter(tokenLoader loadsTokens, uaa usersGUIDsByEmail, kindsRepo KindsRepo, unsubscribesRepo, criticalUnsubscribesRepo unsubscribesGetSetter, globalUnsubscribesRepo GlobalUnsubscribesRepo) UnsubscribeImporter {
+	return UnsubscribeImporter{
+		tokenLoader:              tokenLoader,
+		uaa:                      uaa,
+		kindsRepo:                kindsRepo,
+		unsubscribesRepo:         unsubscribesRepo,
+		criticalUnsubscribesRepo: criticalUnsubscribesRepo,
+		globalUnsubscribesRepo:   globalUnsubscribesRepo,
+	}
+}
+
+// Import unsubscribes the users named in the entries, either from a single
+// kind or, when no client and kind are given, from everything. Every entry
+// is validated before anything is written, and nothing is written at all
+// during a dry run. Users may be given by GUID or by email address.
+func (importer UnsubscribeImporter) Import(conn models.ConnectionInterface, entries []UnsubscribeImportEntry, uaaHost string, dryRun bool) (UnsubscribeImportReport, error) {
+	report := UnsubscribeImportReport{
+		DryRun:  dryRun,
+		Rows:    len(entries),
+		Changes: []UnsubscribeImportChange{},
+	}
+
+	var token string
+	var errs []string
+	var candidates []UnsubscribeImportChange
+	criticalKinds := map[UnsubscribeImportChange]bool{}
+	for _, entry := range entries {
+		if entry.User == "" {
+			errs = append(errs, fmt.Sprintf("line %d: user is required", entry.Line))
+			continue
+		}
+
+		if (entry.ClientID == "") != (entry.KindID == "") {
+			errs = append(errs, fmt.Sprintf("line %d: client_id and kind_id must be given together", entry.Line))
+			continue
+		}
+
+		if entry.KindID != "" {
+			kind, err := importer.kindsRepo.Find(conn, entry.KindID, entry.ClientID)
+			if err != nil {
+				if _, ok := err.(models.NotFoundError); !ok {
+					return report, err
+				}
+
+				errs = append(errs, fmt.Sprintf("line %d: kind %q for client %q is not registered", entry.Line, entry.KindID, entry.ClientID))
+				continue
+			}
+
+			criticalKinds[UnsubscribeImportChange{ClientID: entry.ClientID, KindID: entry.KindID}] = kind.Critical
+		}
+
+		userIDs := []string{entry.User}
+		if strings.Contains(entry.User, "@") {
+			var err error
+			if token == "" {
+				token, err = importer.tokenLoader.Load(uaaHost)
+				if err != nil {
+					return report, err
+				}
+			}
+
+			userIDs, err = importer.uaa.UsersGUIDsByEmail(token, entry.User)
+			if err != nil {
+				return report, err
+			}
+
+			if len(userIDs) == 0 {
+				errs = append(errs, fmt.Sprintf("line %d: no user found with email %q", entry.Line, entry.User))
+				continue
+			}
+		}
+
+		for _, userID := range userIDs {
+			candidates = append(candidates, UnsubscribeImportChange{
+				UserID:   userID,
+				ClientID: entry.ClientID,
+				KindID:   entry.KindID,
+			})
+		}
+	}
+
+	if len(errs) > 0 {
+		return report, UnsubscribeImportError{errors.New(strings.Join(errs, ", "))}
+	}
+
+	seen := map[UnsubscribeImportChange]bool{}
+	for _, change := range candidates {
+		if seen[change] {
+			report.Unchanged++
+			continue
+		}
+		seen[change] = true
+
+		// Unsubscribes from a critical kind are parked until the kind is
+		// downgraded, since critical notifications are always delivered.
+		repo := importer.unsubscribesRepo
+		if criticalKinds[UnsubscribeImportChange{ClientID: change.ClientID, KindID: change.KindID}] {
+			repo = importer.criticalUnsubscribesRepo
+		}
+
+		unsubscribed, err := importer.isUnsubscribed(conn, repo, change)
+		if err != nil {
+			return report, err
+		}
+
+		if unsubscribed {
+			report.Unchanged++
+			continue
+		}
+
+		if !dryRun {
+			err = importer.unsubscribe(conn, repo, change)
+			if err != nil {
+				return report, err
+			}
+		}
+
+		report.Changes = append(report.Changes, change)
+	}
+
+	return report, nil
+}
+
+func (importer UnsubscribeImporter) isUnsubscribed(conn models.ConnectionInterface, repo unsubscribesGetSetter, change UnsubscribeImportChange) (bool, error) {
+	if change.KindID == "" {
+		return importer.globalUnsubscribesRepo.Get(conn, change.UserID)
+	}
+
+	return repo.Get(conn, change.UserID, change.ClientID, change.KindID)
+}
+
+func (importer UnsubscribeImporter) unsubscribe(conn models.ConnectionInterface, repo unsubscribesGetSetter, change UnsubscribeImportChange) error {
+	if change.KindID == "" {
+		return importer.globalUnsubscribesRepo.Set(conn, change.UserID, true)
+	}
+
+	return repo.Set(conn, change.UserID, change.ClientID, change.KindID, true)
+}
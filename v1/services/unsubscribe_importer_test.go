@@ -0,0 +1,172 @@
+package services_test
+
+import (
+	"errors"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UnsubscribeImporter", func() {
+	var (
+		importer                 services.UnsubscribeImporter
+		tokenLoader              *mocks.TokenLoader
+		uaaClient                *mocks.ZonedUAAClient
+		kindsRepo                *mocks.KindsRepo
+		unsubscribesRepo         *mocks.UnsubscribesRepo
+		criticalUnsubscribesRepo *mocks.CriticalUnsubscribesRepo
+		globalUnsubscribesRepo   *mocks.GlobalUnsubscribesRepo
+		conn                     *mocks.Connection
+	)
+
+	BeforeEach(func() {
+		conn = mocks.NewConnection()
+		tokenLoader = mocks.NewTokenLoader()
+		tokenLoader.LoadCall.Returns.Token = "some-token"
+		uaaClient = mocks.NewZonedUAAClient()
+		uaaClient.UsersGUIDsByEmailCall.Returns.UserGUIDs = []string{"user-456"}
+		kindsRepo = mocks.NewKindsRepo()
+		kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "some-kind", ClientID: "some-client"}}
+		unsubscribesRepo = mocks.NewUnsubscribesRepo()
+		criticalUnsubscribesRepo = mocks.NewCriticalUnsubscribesRepo()
+		globalUnsubscribesRepo = mocks.NewGlobalUnsubscribesRepo()
+
+		importer = services.NewUnsubscribeImporter(tokenLoader, uaaClient, kindsRepo, unsubscribesRepo, criticalUnsubscribesRepo, globalUnsubscribesRepo)
+	})
+
+	Describe("Import", func() {
+		It("unsubscribes a user from a kind", func() {
+			report, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+				{Line: 2, User: "user-123", ClientID: "some-client", KindID: "some-kind"},
+			}, "uaa.example.com", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report).To(Equal(services.UnsubscribeImportReport{
+				Rows: 1,
+				Changes: []services.UnsubscribeImportChange{
+					{UserID: "user-123", ClientID: "some-client", KindID: "some-kind"},
+				},
+			}))
+
+			Expect(kindsRepo.FindCall.Receives.KindID).To(Equal("some-kind"))
+			Expect(kindsRepo.FindCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(unsubscribesRepo.SetCall.Receives.Connection).To(Equal(conn))
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(unsubscribesRepo.SetCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(unsubscribesRepo.SetCall.Receives.KindID).To(Equal("some-kind"))
+			Expect(unsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+		})
+
+		It("parks unsubscribes from a critical kind until it is downgraded", func() {
+			kindsRepo.FindCall.Returns.Kinds = []models.Kind{{ID: "some-kind", ClientID: "some-client", Critical: true}}
+
+			report, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+				{Line: 2, User: "user-123", ClientID: "some-client", KindID: "some-kind"},
+			}, "uaa.example.com", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Changes).To(HaveLen(1))
+			Expect(criticalUnsubscribesRepo.GetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(criticalUnsubscribesRepo.SetCall.Receives.Connection).To(Equal(conn))
+			Expect(criticalUnsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-123"))
+			Expect(criticalUnsubscribesRepo.SetCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(criticalUnsubscribesRepo.SetCall.Receives.KindID).To(Equal("some-kind"))
+			Expect(criticalUnsubscribesRepo.SetCall.Receives.Unsubscribe).To(BeTrue())
+			Expect(unsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+		})
+
+		It("globally unsubscribes a user identified by email", func() {
+			report, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+				{Line: 2, User: "someone@example.com"},
+			}, "uaa.example.com", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Changes).To(Equal([]services.UnsubscribeImportChange{
+				{UserID: "user-456"},
+			}))
+
+			Expect(tokenLoader.LoadCall.Receives.UAAHost).To(Equal("uaa.example.com"))
+			Expect(uaaClient.UsersGUIDsByEmailCall.Receives.Token).To(Equal("some-token"))
+			Expect(uaaClient.UsersGUIDsByEmailCall.Receives.Email).To(Equal("someone@example.com"))
+			Expect(globalUnsubscribesRepo.SetCall.Receives.UserID).To(Equal("user-456"))
+			Expect(globalUnsubscribesRepo.SetCall.Receives.Unsubscribed).To(BeTrue())
+		})
+
+		It("reports users who are already unsubscribed as unchanged", func() {
+			globalUnsubscribesRepo.GetCall.Returns.Unsubscribed = true
+
+			report, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+				{Line: 2, User: "user-123"},
+				{Line: 3, User: "user-123"},
+			}, "uaa.example.com", false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(report.Unchanged).To(Equal(2))
+			Expect(report.Changes).To(BeEmpty())
+			Expect(globalUnsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+		})
+
+		Context("when performing a dry run", func() {
+			It("reports the changes without making them", func() {
+				report, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+					{Line: 2, User: "user-123", ClientID: "some-client", KindID: "some-kind"},
+				}, "uaa.example.com", true)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(report.DryRun).To(BeTrue())
+				Expect(report.Changes).To(Equal([]services.UnsubscribeImportChange{
+					{UserID: "user-123", ClientID: "some-client", KindID: "some-kind"},
+				}))
+				Expect(unsubscribesRepo.GetCall.Receives.UserID).To(Equal("user-123"))
+				Expect(unsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+			})
+		})
+
+		Context("when entries are invalid", func() {
+			It("reports every invalid line and changes nothing", func() {
+				kindsRepo.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+				uaaClient.UsersGUIDsByEmailCall.Returns.UserGUIDs = nil
+
+				_, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+					{Line: 2, User: ""},
+					{Line: 3, User: "user-123", ClientID: "some-client"},
+					{Line: 4, User: "user-123", ClientID: "some-client", KindID: "missing-kind"},
+					{Line: 5, User: "nobody@example.com"},
+					{Line: 6, User: "user-123"},
+				}, "uaa.example.com", false)
+
+				Expect(err).To(MatchError(services.UnsubscribeImportError{Err: errors.New(`line 2: user is required, ` +
+					`line 3: client_id and kind_id must be given together, ` +
+					`line 4: kind "missing-kind" for client "some-client" is not registered, ` +
+					`line 5: no user found with email "nobody@example.com"`)}))
+				Expect(globalUnsubscribesRepo.SetCall.Receives.UserID).To(BeEmpty())
+			})
+		})
+
+		Context("when a repo errors", func() {
+			It("returns the error", func() {
+				unsubscribesRepo.SetCall.Returns.Error = errors.New("database is down")
+
+				_, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+					{Line: 2, User: "user-123", ClientID: "some-client", KindID: "some-kind"},
+				}, "uaa.example.com", false)
+				Expect(err).To(MatchError(errors.New("database is down")))
+			})
+		})
+
+		Context("when the users cannot be looked up", func() {
+			It("returns the error", func() {
+				uaaClient.UsersGUIDsByEmailCall.Returns.Error = errors.New("uaa is down")
+
+				_, err := importer.Import(conn, []services.UnsubscribeImportEntry{
+					{Line: 2, User: "someone@example.com"},
+				}, "uaa.example.com", false)
+				Expect(err).To(MatchError(errors.New("uaa is down")))
+			})
+		})
+	})
+})
@@ -4,3 +4,12 @@ type User struct {
 	GUID  string
 	Email string
 }
+
+// recipient is what the messages and responses for the user name them by.
+func (user User) recipient() string {
+	if user.Email != "" {
+		return user.Email
+	}
+
+	return user.GUID
+}
@@ -5,7 +5,9 @@ import (
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/gobble"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -44,8 +46,10 @@ var _ = Describe("UserStrategy", func() {
 						Doctype:        "<html>",
 					},
 				},
-				TemplateID: "some-template-id",
-				UAAHost:    "uaa",
+				TemplateID:  "some-template-id",
+				CallbackURL: "https://example.com/callback",
+				TraceParent: "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				UAAHost:     "uaa",
 				Kind: services.DispatchKind{
 					ID:          "forgot_waterbottle",
 					Description: "Water Bottle Reminder",
@@ -74,6 +78,8 @@ var _ = Describe("UserStrategy", func() {
 				SourceDescription: "The Water Bottle System",
 				Text:              "Please make sure to leave your bottle in a place that is safe and dry",
 				TemplateID:        "some-template-id",
+				CallbackURL:       "https://example.com/callback",
+				TraceParent:       "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
 				HTML: services.HTML{
 					BodyContent:    "<p>The water bottle needs to be safe and dry</p>",
 					BodyAttributes: "some-html-body-attributes",
@@ -90,5 +96,92 @@ var _ = Describe("UserStrategy", func() {
 			Expect(enqueuer.EnqueueCall.Receives.VCAPRequestID).To(Equal("some-vcap-request-id"))
 			Expect(enqueuer.EnqueueCall.Receives.RequestReceived).To(Equal(requestReceived))
 		})
+
+		It("queues critical kinds ahead of other deliveries", func() {
+			_, err := strategy.Dispatch(services.Dispatch{
+				GUID:       "user-123",
+				Connection: conn,
+				Kind: services.DispatchKind{
+					ID:       "password_reset",
+					Critical: true,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.Receives.Options.Priority).To(Equal(gobble.PriorityCritical))
+		})
+
+		It("queues notifications by their severity", func() {
+			for severity, priority := range map[string]int{
+				models.SeverityInfo:                   gobble.PriorityNormal,
+				models.SeverityWarning:                gobble.PriorityHigh,
+				models.SeverityCriticalActionRequired: gobble.PriorityHigh,
+			} {
+				_, err := strategy.Dispatch(services.Dispatch{
+					GUID:       "user-123",
+					Connection: conn,
+					Severity:   severity,
+					Kind: services.DispatchKind{
+						ID: "password_reset",
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(enqueuer.EnqueueCall.Receives.Options.Priority).To(Equal(priority), severity)
+				Expect(enqueuer.EnqueueCall.Receives.Options.Severity).To(Equal(severity))
+			}
+		})
+
+		It("marks deliveries of transactional kinds", func() {
+			_, err := strategy.Dispatch(services.Dispatch{
+				GUID:       "user-123",
+				Connection: conn,
+				Kind: services.DispatchKind{
+					ID:            "password_reset",
+					Transactional: true,
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.Receives.Options.Transactional).To(BeTrue())
+		})
+
+		It("sends the deliveries of a client in simulation mode to its sinks", func() {
+			_, err := strategy.Dispatch(services.Dispatch{
+				GUID:       "user-123",
+				Connection: conn,
+				Client: services.DispatchClient{
+					ID:              "partner-client",
+					SimulationSinks: []string{"qa@partner.example.com"},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.Receives.Options.SimulationSinks).To(Equal([]string{"qa@partner.example.com"}))
+		})
+
+		It("passes the deadline of the notification to the deliveries", func() {
+			deadline := time.Date(2015, time.June, 8, 22, 30, 0, 0, time.UTC)
+
+			_, err := strategy.Dispatch(services.Dispatch{
+				GUID:       "user-123",
+				Connection: conn,
+				Deadline:   deadline,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.Receives.Options.Deadline).To(Equal(deadline))
+		})
+
+		It("passes the metadata of the notification to the deliveries", func() {
+			_, err := strategy.Dispatch(services.Dispatch{
+				GUID:       "user-123",
+				Connection: conn,
+				Metadata:   map[string]string{"ticket": "INC-1234"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(enqueuer.EnqueueCall.Receives.Options.Metadata).To(Equal(map[string]string{"ticket": "INC-1234"}))
+		})
 	})
 })
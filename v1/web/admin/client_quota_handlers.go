@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var clientQuotaPath = regexp.MustCompile(".*/admin/clients/(.*)/quota")
+
+type clientQuotasRepo interface {
+	Find(conn models.ConnectionInterface, clientID string) (models.ClientQuota, error)
+	Upsert(conn models.ConnectionInterface, quota models.ClientQuota) (models.ClientQuota, error)
+	Delete(conn models.ConnectionInterface, clientID string) error
+	Usage(conn models.ConnectionInterface, clientID string, at time.Time) (int, error)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+type clientQuotaDocument struct {
+	ClientID     string    `json:"client_id"`
+	MonthlyLimit *int      `json:"monthly_limit"`
+	Month        string    `json:"month"`
+	Used         int       `json:"used"`
+	Remaining    *int      `json:"remaining"`
+	ResetsAt     time.Time `json:"resets_at"`
+}
+
+// clientQuotaReport describes the consumption of the client in the month
+// of now. Clients without a quota report their usage with a null limit.
+func clientQuotaReport(conn models.ConnectionInterface, quotas clientQuotasRepo, clientID string, now time.Time) (clientQuotaDocument, error) {
+	document := clientQuotaDocument{
+		ClientID: clientID,
+		Month:    models.QuotaMonth(now),
+		ResetsAt: models.QuotaResetsAt(now),
+	}
+
+	used, err := quotas.Usage(conn, clientID, now)
+	if err != nil {
+		return document, err
+	}
+	document.Used = used
+
+	quota, err := quotas.Find(conn, clientID)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return document, nil
+		}
+
+		return document, err
+	}
+
+	remaining := quota.MonthlyLimit - used
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	document.MonthlyLimit = &quota.MonthlyLimit
+	document.Remaining = &remaining
+
+	return document, nil
+}
+
+type GetClientQuotaHandler struct {
+	quotas      clientQuotasRepo
+	clock       clock
+	errorWriter errorWriter
+}
+
+func NewGetClientQuotaHandler(quotas clientQuotasRepo, clock clock, errWriter errorWriter) GetClientQuotaHandler {
+	return GetClientQuotaHandler{
+		quotas:      quotas,
+		clock:       clock,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetClientQuotaHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := clientQuotaPath.FindStringSubmatch(req.URL.Path)[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	document, err := clientQuotaReport(connection, h.quotas, clientID, h.clock.Now())
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
+
+type UpdateClientQuotaHandler struct {
+	quotas      clientQuotasRepo
+	clock       clock
+	errorWriter errorWriter
+}
+
+func NewUpdateClientQuotaHandler(quotas clientQuotasRepo, clock clock, errWriter errorWriter) UpdateClientQuotaHandler {
+	return UpdateClientQuotaHandler{
+		quotas:      quotas,
+		clock:       clock,
+		errorWriter: errWriter,
+	}
+}
+
+type clientQuotaParams struct {
+	MonthlyLimit *int `json:"monthly_limit"`
+}
+
+func (h UpdateClientQuotaHandler) RequestBody() interface{} {
+	return clientQuotaParams{}
+}
+
+func (h UpdateClientQuotaHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := clientQuotaPath.FindStringSubmatch(req.URL.Path)[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	var params clientQuotaParams
+
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	if params.MonthlyLimit == nil || *params.MonthlyLimit < 0 {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"monthly_limit" must be a number that is not negative`)})
+		return
+	}
+
+	_, err = h.quotas.Upsert(connection, models.ClientQuota{
+		ClientID:     clientID,
+		MonthlyLimit: *params.MonthlyLimit,
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	document, err := clientQuotaReport(connection, h.quotas, clientID, h.clock.Now())
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
+
+// DeleteClientQuotaHandler lifts the quota of a client. Its usage keeps
+// being counted, so a quota assigned later in the month starts from it.
+type DeleteClientQuotaHandler struct {
+	quotas      clientQuotasRepo
+	errorWriter errorWriter
+}
+
+func NewDeleteClientQuotaHandler(quotas clientQuotasRepo, errWriter errorWriter) DeleteClientQuotaHandler {
+	return DeleteClientQuotaHandler{
+		quotas:      quotas,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DeleteClientQuotaHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := clientQuotaPath.FindStringSubmatch(req.URL.Path)[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	err := h.quotas.Delete(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
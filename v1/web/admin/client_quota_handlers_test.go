@@ -0,0 +1,202 @@
+package admin_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client quota handlers", func() {
+	var (
+		quotas      *mocks.ClientQuotasRepo
+		clock       *mocks.Clock
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		quotas = mocks.NewClientQuotasRepo()
+		quotas.UsageCall.Returns.Used = 1500
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+	})
+
+	Describe("GetClientQuotaHandler", func() {
+		var handler admin.GetClientQuotaHandler
+
+		BeforeEach(func() {
+			handler = admin.NewGetClientQuotaHandler(quotas, clock, errorWriter)
+		})
+
+		It("returns the quota and consumption of the client this month", func() {
+			quotas.FindCall.Returns.Quota = models.ClientQuota{ClientID: "some-client", MonthlyLimit: 100000}
+
+			request, err := http.NewRequest("GET", "/admin/clients/some-client/quota", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "some-client",
+				"monthly_limit": 100000,
+				"month": "2026-10",
+				"used": 1500,
+				"remaining": 98500,
+				"resets_at": "2026-11-01T00:00:00Z"
+			}`))
+			Expect(quotas.FindCall.Receives.Connection).To(Equal(connection))
+			Expect(quotas.FindCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(quotas.UsageCall.Receives.ClientID).To(Equal("some-client"))
+			Expect(quotas.UsageCall.Receives.At).To(Equal(time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)))
+		})
+
+		It("reports the consumption of a client without a quota", func() {
+			quotas.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("no quota")}
+
+			request, err := http.NewRequest("GET", "/admin/clients/some-client/quota", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "some-client",
+				"monthly_limit": null,
+				"month": "2026-10",
+				"used": 1500,
+				"remaining": null,
+				"resets_at": "2026-11-01T00:00:00Z"
+			}`))
+		})
+
+		It("writes the error when the usage cannot be read", func() {
+			quotas.UsageCall.Returns.Error = errors.New("database is down")
+
+			request, err := http.NewRequest("GET", "/admin/clients/some-client/quota", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+		})
+	})
+
+	Describe("UpdateClientQuotaHandler", func() {
+		var handler admin.UpdateClientQuotaHandler
+
+		BeforeEach(func() {
+			handler = admin.NewUpdateClientQuotaHandler(quotas, clock, errorWriter)
+		})
+
+		It("assigns the quota of the client", func() {
+			quotas.FindCall.Returns.Quota = models.ClientQuota{ClientID: "some-client", MonthlyLimit: 1000}
+
+			request, err := http.NewRequest("PUT", "/admin/clients/some-client/quota", bytes.NewBufferString(`{"monthly_limit": 1000}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(quotas.UpsertCall.Receives.Connection).To(Equal(connection))
+			Expect(quotas.UpsertCall.Receives.Quota).To(Equal(models.ClientQuota{ClientID: "some-client", MonthlyLimit: 1000}))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "some-client",
+				"monthly_limit": 1000,
+				"month": "2026-10",
+				"used": 1500,
+				"remaining": 0,
+				"resets_at": "2026-11-01T00:00:00Z"
+			}`))
+		})
+
+		It("writes a parse error for an invalid body", func() {
+			request, err := http.NewRequest("PUT", "/admin/clients/some-client/quota", bytes.NewBufferString(`{`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ParseError{}))
+			Expect(quotas.UpsertCall.WasCalled).To(BeFalse())
+		})
+
+		DescribeTable("writes a validation error for an invalid limit",
+			func(body string) {
+				request, err := http.NewRequest("PUT", "/admin/clients/some-client/quota", bytes.NewBufferString(body))
+				Expect(err).NotTo(HaveOccurred())
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+				Expect(quotas.UpsertCall.WasCalled).To(BeFalse())
+			},
+			Entry("missing", `{}`),
+			Entry("negative", `{"monthly_limit": -1}`),
+		)
+
+		It("writes the error when the quota cannot be saved", func() {
+			quotas.UpsertCall.Returns.Error = errors.New("database is down")
+
+			request, err := http.NewRequest("PUT", "/admin/clients/some-client/quota", bytes.NewBufferString(`{"monthly_limit": 1000}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+		})
+	})
+
+	Describe("DeleteClientQuotaHandler", func() {
+		var handler admin.DeleteClientQuotaHandler
+
+		BeforeEach(func() {
+			handler = admin.NewDeleteClientQuotaHandler(quotas, errorWriter)
+		})
+
+		It("lifts the quota of the client", func() {
+			request, err := http.NewRequest("DELETE", "/admin/clients/some-client/quota", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(quotas.DeleteCall.Receives.Connection).To(Equal(connection))
+			Expect(quotas.DeleteCall.Receives.ClientID).To(Equal("some-client"))
+		})
+
+		It("writes the error when the client has no quota", func() {
+			quotas.DeleteCall.Returns.Error = models.NotFoundError{Err: errors.New("no quota")}
+
+			request, err := http.NewRequest("DELETE", "/admin/clients/some-client/quota", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+})
@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var clientSuspensionPath = regexp.MustCompile(".*/admin/clients/(.*)/suspension")
+
+type clientSuspensionsRepo interface {
+	Find(conn models.ConnectionInterface, clientID string) (models.ClientSuspension, error)
+	Delete(conn models.ConnectionInterface, clientID string) error
+}
+
+type clientSuspensionDocument struct {
+	ClientID  string    `json:"client_id"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type GetClientSuspensionHandler struct {
+	suspensions clientSuspensionsRepo
+	errorWriter errorWriter
+}
+
+func NewGetClientSuspensionHandler(suspensions clientSuspensionsRepo, errWriter errorWriter) GetClientSuspensionHandler {
+	return GetClientSuspensionHandler{
+		suspensions: suspensions,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetClientSuspensionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := clientSuspensionPath.FindStringSubmatch(req.URL.Path)[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	suspension, err := h.suspensions.Find(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, clientSuspensionDocument{
+		ClientID:  suspension.ClientID,
+		Reason:    suspension.Reason,
+		CreatedAt: suspension.CreatedAt,
+	})
+}
+
+// DeleteClientSuspensionHandler reauthorizes a client that was suspended
+// for unusual sending activity.
+type DeleteClientSuspensionHandler struct {
+	suspensions clientSuspensionsRepo
+	errorWriter errorWriter
+}
+
+func NewDeleteClientSuspensionHandler(suspensions clientSuspensionsRepo, errWriter errorWriter) DeleteClientSuspensionHandler {
+	return DeleteClientSuspensionHandler{
+		suspensions: suspensions,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DeleteClientSuspensionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := clientSuspensionPath.FindStringSubmatch(req.URL.Path)[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	err := h.suspensions.Delete(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,110 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Client suspension handlers", func() {
+	var (
+		suspensions *mocks.ClientSuspensionsRepo
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		suspensions = mocks.NewClientSuspensionsRepo()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+	})
+
+	Describe("GetClientSuspensionHandler", func() {
+		var handler admin.GetClientSuspensionHandler
+
+		BeforeEach(func() {
+			handler = admin.NewGetClientSuspensionHandler(suspensions, errorWriter)
+		})
+
+		It("returns the suspension of the client", func() {
+			suspensions.FindCall.Returns.Suspension = models.ClientSuspension{
+				ClientID:  "some-client",
+				Reason:    "500 requests in one minute against a baseline of 10.0 per minute",
+				CreatedAt: time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+			}
+
+			request, err := http.NewRequest("GET", "/admin/clients/some-client/suspension", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "some-client",
+				"reason": "500 requests in one minute against a baseline of 10.0 per minute",
+				"created_at": "2026-10-17T12:00:00Z"
+			}`))
+			Expect(suspensions.FindCall.Receives.Connection).To(Equal(connection))
+			Expect(suspensions.FindCall.Receives.ClientID).To(Equal("some-client"))
+		})
+
+		It("writes the error when the client is not suspended", func() {
+			suspensions.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not suspended")}
+
+			request, err := http.NewRequest("GET", "/admin/clients/some-client/suspension", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("DeleteClientSuspensionHandler", func() {
+		var handler admin.DeleteClientSuspensionHandler
+
+		BeforeEach(func() {
+			handler = admin.NewDeleteClientSuspensionHandler(suspensions, errorWriter)
+		})
+
+		It("reauthorizes the client", func() {
+			request, err := http.NewRequest("DELETE", "/admin/clients/some-client/suspension", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(suspensions.DeleteCall.Receives.Connection).To(Equal(connection))
+			Expect(suspensions.DeleteCall.Receives.ClientID).To(Equal("some-client"))
+		})
+
+		It("writes the error when the suspension cannot be deleted", func() {
+			suspensions.DeleteCall.Returns.Error = errors.New("database is down")
+
+			request, err := http.NewRequest("DELETE", "/admin/clients/some-client/suspension", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+		})
+	})
+})
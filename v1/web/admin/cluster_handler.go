@@ -0,0 +1,103 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cron"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	RoleActive   = "active"
+	RoleStandby  = "standby"
+	RoleReadOnly = "read-only"
+)
+
+type instanceHeartbeatsRepo interface {
+	FindAll(conn models.ConnectionInterface) ([]models.InstanceHeartbeat, error)
+}
+
+// GetClusterHandler lists the instances of the deployment as their
+// heartbeats last described them. The active instance is the one holding
+// the scheduler lease; the others that send are on standby for it.
+type GetClusterHandler struct {
+	heartbeats  instanceHeartbeatsRepo
+	leases      schedulerLeasesRepo
+	clock       clock
+	errorWriter errorWriter
+}
+
+func NewGetClusterHandler(heartbeats instanceHeartbeatsRepo, leases schedulerLeasesRepo, clock clock, errWriter errorWriter) GetClusterHandler {
+	return GetClusterHandler{
+		heartbeats:  heartbeats,
+		leases:      leases,
+		clock:       clock,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetClusterHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+	now := h.clock.Now()
+
+	type instance struct {
+		InstanceID      string    `json:"instance_id"`
+		InstanceIndex   int       `json:"instance_index"`
+		Role            string    `json:"role"`
+		Workers         int       `json:"workers"`
+		Version         string    `json:"version"`
+		StartedAt       time.Time `json:"started_at"`
+		LastHeartbeatAt time.Time `json:"last_heartbeat_at"`
+		Stale           bool      `json:"stale"`
+	}
+
+	leader := ""
+	lease, err := h.leases.Find(connection, cron.LeaseName)
+	switch err.(type) {
+	case nil:
+		if lease.ExpiresAt.After(now) {
+			leader = lease.Holder
+		}
+	case models.NotFoundError:
+	default:
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	heartbeats, err := h.heartbeats.FindAll(connection)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	instances := []instance{}
+	for _, heartbeat := range heartbeats {
+		role := RoleStandby
+		switch {
+		case heartbeat.ReadOnly:
+			role = RoleReadOnly
+		case heartbeat.InstanceID == leader:
+			role = RoleActive
+		}
+
+		instances = append(instances, instance{
+			InstanceID:      heartbeat.InstanceID,
+			InstanceIndex:   heartbeat.InstanceIndex,
+			Role:            role,
+			Workers:         heartbeat.Workers,
+			Version:         heartbeat.Version,
+			StartedAt:       heartbeat.StartedAt,
+			LastHeartbeatAt: heartbeat.HeartbeatAt,
+			Stale:           now.Sub(heartbeat.HeartbeatAt) > cron.HeartbeatTimeout,
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, struct {
+		Instances []instance `json:"instances"`
+	}{
+		Instances: instances,
+	})
+}
@@ -0,0 +1,167 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetClusterHandler", func() {
+	var (
+		handler     admin.GetClusterHandler
+		heartbeats  *mocks.InstanceHeartbeatsRepo
+		leases      *mocks.SchedulerLeasesRepo
+		clock       *mocks.Clock
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		request     *http.Request
+		now         time.Time
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		now = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		startedAt := now.Add(-time.Hour)
+
+		heartbeats = mocks.NewInstanceHeartbeatsRepo()
+		heartbeats.FindAllCall.Returns.Heartbeats = []models.InstanceHeartbeat{
+			{
+				InstanceID:    "instance-0",
+				InstanceIndex: 0,
+				Workers:       8,
+				Version:       "v1.2.3",
+				StartedAt:     startedAt,
+				HeartbeatAt:   now.Add(-10 * time.Second),
+			},
+			{
+				InstanceID:    "instance-1",
+				InstanceIndex: 1,
+				Workers:       4,
+				Version:       "v1.2.2",
+				StartedAt:     startedAt,
+				HeartbeatAt:   now.Add(-5 * time.Minute),
+			},
+			{
+				InstanceID:    "reader-0",
+				InstanceIndex: 0,
+				ReadOnly:      true,
+				Version:       "v1.2.3",
+				StartedAt:     startedAt,
+				HeartbeatAt:   now.Add(-20 * time.Second),
+			},
+		}
+
+		leases = mocks.NewSchedulerLeasesRepo()
+		leases.FindCall.Returns.Lease = models.SchedulerLease{
+			Name:      "scheduler",
+			Holder:    "instance-0",
+			ExpiresAt: now.Add(80 * time.Second),
+		}
+
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+		handler = admin.NewGetClusterHandler(heartbeats, leases, clock, errorWriter)
+
+		var err error
+		request, err = http.NewRequest("GET", "/admin/cluster", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("lists the instances with their roles", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"instances": [
+				{
+					"instance_id": "instance-0",
+					"instance_index": 0,
+					"role": "active",
+					"workers": 8,
+					"version": "v1.2.3",
+					"started_at": "2026-10-17T11:00:00Z",
+					"last_heartbeat_at": "2026-10-17T11:59:50Z",
+					"stale": false
+				},
+				{
+					"instance_id": "instance-1",
+					"instance_index": 1,
+					"role": "standby",
+					"workers": 4,
+					"version": "v1.2.2",
+					"started_at": "2026-10-17T11:00:00Z",
+					"last_heartbeat_at": "2026-10-17T11:55:00Z",
+					"stale": true
+				},
+				{
+					"instance_id": "reader-0",
+					"instance_index": 0,
+					"role": "read-only",
+					"workers": 0,
+					"version": "v1.2.3",
+					"started_at": "2026-10-17T11:00:00Z",
+					"last_heartbeat_at": "2026-10-17T11:59:40Z",
+					"stale": false
+				}
+			]
+		}`))
+		Expect(leases.FindCall.Receives.Connection).To(Equal(connection))
+		Expect(leases.FindCall.Receives.Name).To(Equal("scheduler"))
+		Expect(heartbeats.FindAllCall.Receives.Connection).To(Equal(connection))
+	})
+
+	It("has no active instance when the lease has expired", func() {
+		leases.FindCall.Returns.Lease.ExpiresAt = now.Add(-time.Second)
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).NotTo(ContainSubstring(`"active"`))
+	})
+
+	It("has no active instance before any instance has run the jobs", func() {
+		leases.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("no lease")}
+		heartbeats.FindAllCall.Returns.Heartbeats = []models.InstanceHeartbeat{}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{"instances": []}`))
+	})
+
+	It("writes the error when the lease cannot be loaded", func() {
+		leases.FindCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+	})
+
+	It("writes the error when the heartbeats cannot be loaded", func() {
+		heartbeats.FindAllCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+	})
+})
@@ -0,0 +1,110 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var criticalApprovalPath = regexp.MustCompile(".*/admin/critical_kinds/(.*)/(.*)/(approve|deny)$")
+
+type criticalApprover interface {
+	List(database services.DatabaseInterface, status string) ([]models.CriticalApproval, error)
+	Approve(database services.DatabaseInterface, clientID, kindID string) (models.CriticalApproval, error)
+	Deny(database services.DatabaseInterface, clientID, kindID string) (models.CriticalApproval, error)
+}
+
+type criticalApprovalDocument struct {
+	ClientID    string    `json:"client_id"`
+	KindID      string    `json:"kind_id"`
+	Status      string    `json:"status"`
+	RequestedAt time.Time `json:"requested_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func newCriticalApprovalDocument(approval models.CriticalApproval) criticalApprovalDocument {
+	return criticalApprovalDocument{
+		ClientID:    approval.ClientID,
+		KindID:      approval.KindID,
+		Status:      approval.Status,
+		RequestedAt: approval.CreatedAt,
+		UpdatedAt:   approval.UpdatedAt,
+	}
+}
+
+type ListCriticalApprovalsHandler struct {
+	approver    criticalApprover
+	errorWriter errorWriter
+}
+
+func NewListCriticalApprovalsHandler(approver criticalApprover, errWriter errorWriter) ListCriticalApprovalsHandler {
+	return ListCriticalApprovalsHandler{
+		approver:    approver,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListCriticalApprovalsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	status := req.URL.Query().Get("status")
+	switch status {
+	case "":
+		status = models.CriticalApprovalPending
+	case models.CriticalApprovalPending, models.CriticalApprovalApproved, models.CriticalApprovalDenied:
+	default:
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"status" must be one of "pending", "approved" or "denied"`)})
+		return
+	}
+
+	approvals, err := h.approver.List(context.Get("database").(DatabaseInterface), status)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	documents := []criticalApprovalDocument{}
+	for _, approval := range approvals {
+		documents = append(documents, newCriticalApprovalDocument(approval))
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"critical_kinds": documents,
+	})
+}
+
+// DecideCriticalApprovalHandler approves or denies the request of a kind to
+// be critical, depending on the last segment of the path.
+type DecideCriticalApprovalHandler struct {
+	approver    criticalApprover
+	errorWriter errorWriter
+}
+
+func NewDecideCriticalApprovalHandler(approver criticalApprover, errWriter errorWriter) DecideCriticalApprovalHandler {
+	return DecideCriticalApprovalHandler{
+		approver:    approver,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DecideCriticalApprovalHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	matches := criticalApprovalPath.FindStringSubmatch(req.URL.Path)
+	database := context.Get("database").(DatabaseInterface)
+
+	decide := h.approver.Approve
+	if matches[3] == "deny" {
+		decide = h.approver.Deny
+	}
+
+	approval, err := decide(database, matches[1], matches[2])
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, newCriticalApprovalDocument(approval))
+}
@@ -0,0 +1,157 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Critical approval handlers", func() {
+	var (
+		approver    *mocks.CriticalApprover
+		errorWriter *mocks.ErrorWriter
+		database    *mocks.Database
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		requestedAt time.Time
+	)
+
+	BeforeEach(func() {
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		approver = mocks.NewCriticalApprover()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+		requestedAt = time.Date(2015, 6, 8, 14, 31, 11, 0, time.UTC)
+	})
+
+	Describe("ListCriticalApprovalsHandler", func() {
+		var handler admin.ListCriticalApprovalsHandler
+
+		BeforeEach(func() {
+			handler = admin.NewListCriticalApprovalsHandler(approver, errorWriter)
+		})
+
+		It("lists the pending requests by default", func() {
+			approver.ListCall.Returns.Approvals = []models.CriticalApproval{{
+				ClientID:  "raptors",
+				KindID:    "hungry",
+				Status:    models.CriticalApprovalPending,
+				CreatedAt: requestedAt,
+				UpdatedAt: requestedAt,
+			}}
+
+			request, err := http.NewRequest("GET", "/admin/critical_kinds", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"critical_kinds": [{
+					"client_id": "raptors",
+					"kind_id": "hungry",
+					"status": "pending",
+					"requested_at": "2015-06-08T14:31:11Z",
+					"updated_at": "2015-06-08T14:31:11Z"
+				}]
+			}`))
+
+			Expect(approver.ListCall.Receives.Database).To(Equal(database))
+			Expect(approver.ListCall.Receives.Status).To(Equal("pending"))
+		})
+
+		It("lists the requests with the given status", func() {
+			request, err := http.NewRequest("GET", "/admin/critical_kinds?status=denied", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{"critical_kinds": []}`))
+			Expect(approver.ListCall.Receives.Status).To(Equal("denied"))
+		})
+
+		It("rejects unknown statuses", func() {
+			request, err := http.NewRequest("GET", "/admin/critical_kinds?status=maybe", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(approver.ListCall.Receives.Status).To(BeEmpty())
+		})
+	})
+
+	Describe("DecideCriticalApprovalHandler", func() {
+		var handler admin.DecideCriticalApprovalHandler
+
+		BeforeEach(func() {
+			handler = admin.NewDecideCriticalApprovalHandler(approver, errorWriter)
+		})
+
+		It("approves the request", func() {
+			approver.ApproveCall.Returns.Approval = models.CriticalApproval{
+				ClientID:  "raptors",
+				KindID:    "hungry",
+				Status:    models.CriticalApprovalApproved,
+				CreatedAt: requestedAt,
+				UpdatedAt: requestedAt.Add(time.Hour),
+			}
+
+			request, err := http.NewRequest("PUT", "/admin/critical_kinds/raptors/hungry/approve", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "raptors",
+				"kind_id": "hungry",
+				"status": "approved",
+				"requested_at": "2015-06-08T14:31:11Z",
+				"updated_at": "2015-06-08T15:31:11Z"
+			}`))
+
+			Expect(approver.ApproveCall.Receives.Database).To(Equal(database))
+			Expect(approver.ApproveCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(approver.ApproveCall.Receives.KindID).To(Equal("hungry"))
+			Expect(approver.DenyCall.WasCalled).To(BeFalse())
+		})
+
+		It("denies the request", func() {
+			request, err := http.NewRequest("PUT", "/admin/critical_kinds/raptors/hungry/deny", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(approver.DenyCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(approver.DenyCall.Receives.KindID).To(Equal("hungry"))
+			Expect(approver.ApproveCall.WasCalled).To(BeFalse())
+		})
+
+		It("writes errors from the approver", func() {
+			approver.ApproveCall.Returns.Error = errors.New("boom")
+
+			request, err := http.NewRequest("PUT", "/admin/critical_kinds/raptors/hungry/approve", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("boom"))
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+// MaxTemplatePackUpload is the largest compressed template pack accepted.
+const MaxTemplatePackUpload = 4 << 20
+
+type templatePackImporter interface {
+	Import(conn models.ConnectionInterface, pack templatepack.Pack, overwrite, dryRun bool) (services.TemplatePackReport, error)
+}
+
+type ImportTemplatePackHandler struct {
+	importer    templatePackImporter
+	errorWriter errorWriter
+}
+
+func NewImportTemplatePackHandler(importer templatePackImporter, errWriter errorWriter) ImportTemplatePackHandler {
+	return ImportTemplatePackHandler{
+		importer:    importer,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ImportTemplatePackHandler) RequestBody() interface{} {
+	return docs.RawBody{ContentType: "application/gzip"}
+}
+
+func (h ImportTemplatePackHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	pack, err := templatepack.LoadArchive(http.MaxBytesReader(w, req.Body, MaxTemplatePackUpload))
+	if err != nil {
+		if _, ok := err.(templatepack.FormatError); ok {
+			err = webutil.ValidationError{Err: err}
+		}
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	query := req.URL.Query()
+	overwrite := query.Get("overwrite") == "true"
+	dryRun := query.Get("dry_run") == "true"
+
+	transaction := context.Get("database").(DatabaseInterface).Connection().Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	report, err := h.importer.Import(transaction, pack, overwrite, dryRun)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, report)
+}
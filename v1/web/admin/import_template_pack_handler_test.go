@@ -0,0 +1,163 @@
+package admin_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/templatepack"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+func templatePackArchive(files map[string]string) *bytes.Buffer {
+	buffer := bytes.NewBuffer([]byte{})
+	gz := gzip.NewWriter(buffer)
+	archive := tar.NewWriter(gz)
+
+	for name, contents := range files {
+		Expect(archive.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(contents))})).To(Succeed())
+		_, err := archive.Write([]byte(contents))
+		Expect(err).NotTo(HaveOccurred())
+	}
+
+	Expect(archive.Close()).To(Succeed())
+	Expect(gz.Close()).To(Succeed())
+
+	return buffer
+}
+
+var _ = Describe("ImportTemplatePackHandler", func() {
+	var (
+		handler     admin.ImportTemplatePackHandler
+		importer    *mocks.TemplatePackImporter
+		errorWriter *mocks.ErrorWriter
+		transaction *mocks.Transaction
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		body        *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		transaction = mocks.NewTransaction()
+		connection := mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		importer = mocks.NewTemplatePackImporter()
+		importer.ImportCall.Returns.Report = services.TemplatePackReport{
+			Name:    "acme-platform",
+			Version: 1,
+			Templates: services.TemplatePackChanges{
+				Created:   []string{"password-reset"},
+				Updated:   []string{},
+				Unchanged: []string{},
+			},
+			Partials: services.TemplatePackChanges{
+				Created:   []string{},
+				Updated:   []string{},
+				Unchanged: []string{},
+			},
+		}
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		body = templatePackArchive(map[string]string{
+			"manifest.json":       `{"version": 1, "name": "acme-platform", "templates": [{"id": "password-reset", "name": "Password reset", "html_file": "password-reset.html"}]}`,
+			"password-reset.html": "<p>{{.HTML}}</p>",
+		})
+
+		handler = admin.NewImportTemplatePackHandler(importer, errorWriter)
+	})
+
+	It("imports the pack in the archive", func() {
+		request, err := http.NewRequest("POST", "/admin/template_packs/import", body)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"dry_run": false,
+			"name": "acme-platform",
+			"version": 1,
+			"templates": {"created": ["password-reset"], "updated": [], "unchanged": []},
+			"partials": {"created": [], "updated": [], "unchanged": []}
+		}`))
+
+		Expect(importer.ImportCall.Receives.Connection).To(Equal(transaction))
+		Expect(importer.ImportCall.Receives.Pack.Name).To(Equal("acme-platform"))
+		Expect(importer.ImportCall.Receives.Pack.Templates[0].HTML).To(Equal("<p>{{.HTML}}</p>"))
+		Expect(importer.ImportCall.Receives.Overwrite).To(BeFalse())
+		Expect(importer.ImportCall.Receives.DryRun).To(BeFalse())
+
+		Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+	})
+
+	It("passes the overwrite and dry run flags to the importer", func() {
+		request, err := http.NewRequest("POST", "/admin/template_packs/import?overwrite=true&dry_run=true", body)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(importer.ImportCall.Receives.Overwrite).To(BeTrue())
+		Expect(importer.ImportCall.Receives.DryRun).To(BeTrue())
+	})
+
+	Context("when the pack is invalid", func() {
+		It("writes a validation error", func() {
+			request, err := http.NewRequest("POST", "/admin/template_packs/import", strings.NewReader("not an archive"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ValidationError{
+				Err: templatepack.FormatError{Err: errors.New("the archive must be a gzipped tarball")},
+			}))
+			Expect(importer.ImportCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the import fails", func() {
+		It("rolls back the transaction and writes the error", func() {
+			importer.ImportCall.Returns.Error = errors.New("database is down")
+			request, err := http.NewRequest("POST", "/admin/template_packs/import", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be committed", func() {
+		It("writes a transaction commit error", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+			request, err := http.NewRequest("POST", "/admin/template_packs/import", body)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.TransactionCommitError{Err: errors.New("commit failed")}))
+		})
+	})
+})
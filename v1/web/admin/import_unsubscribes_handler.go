@@ -0,0 +1,119 @@
+package admin
+
+import (
+	"encoding/csv"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+var unsubscribesImportHeader = []string{"user", "client_id", "kind_id"}
+
+type unsubscribeImporter interface {
+	Import(conn models.ConnectionInterface, entries []services.UnsubscribeImportEntry, uaaHost string, dryRun bool) (services.UnsubscribeImportReport, error)
+}
+
+type ImportUnsubscribesHandler struct {
+	importer    unsubscribeImporter
+	errorWriter errorWriter
+}
+
+func NewImportUnsubscribesHandler(importer unsubscribeImporter, errWriter errorWriter) ImportUnsubscribesHandler {
+	return ImportUnsubscribesHandler{
+		importer:    importer,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ImportUnsubscribesHandler) RequestBody() interface{} {
+	return docs.RawBody{ContentType: "text/csv"}
+}
+
+func (h ImportUnsubscribesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	entries, err := parseUnsubscribesCSV(req.Body)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	token := context.Get("token").(*jwt.Token)
+	tokenIssuerURL, err := url.Parse(token.Claims["iss"].(string))
+	if err != nil {
+		h.errorWriter.Write(w, errors.New("Token issuer URL invalid"))
+		return
+	}
+	uaaHost := tokenIssuerURL.Scheme + "://" + tokenIssuerURL.Host
+
+	dryRun := req.URL.Query().Get("dry_run") == "true"
+
+	transaction := context.Get("database").(DatabaseInterface).Connection().Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	report, err := h.importer.Import(transaction, entries, uaaHost, dryRun)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, report)
+}
+
+func parseUnsubscribesCSV(body io.Reader) ([]services.UnsubscribeImportEntry, error) {
+	reader := csv.NewReader(body)
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, webutil.ParseError{}
+	}
+
+	if len(records) == 0 || !isUnsubscribesImportHeader(records[0]) {
+		return nil, webutil.ValidationError{Err: errors.New(`the CSV must start with a "user,client_id,kind_id" header row`)}
+	}
+
+	var entries []services.UnsubscribeImportEntry
+	for i, record := range records[1:] {
+		entries = append(entries, services.UnsubscribeImportEntry{
+			Line:     i + 2,
+			User:     strings.TrimSpace(record[0]),
+			ClientID: strings.TrimSpace(record[1]),
+			KindID:   strings.TrimSpace(record[2]),
+		})
+	}
+
+	return entries, nil
+}
+
+func isUnsubscribesImportHeader(record []string) bool {
+	if len(record) != len(unsubscribesImportHeader) {
+		return false
+	}
+
+	for i, column := range unsubscribesImportHeader {
+		if strings.TrimSpace(record[i]) != column {
+			return false
+		}
+	}
+
+	return true
+}
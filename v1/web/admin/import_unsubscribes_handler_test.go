@@ -0,0 +1,170 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ImportUnsubscribesHandler", func() {
+	var (
+		handler     admin.ImportUnsubscribesHandler
+		importer    *mocks.UnsubscribeImporter
+		errorWriter *mocks.ErrorWriter
+		transaction *mocks.Transaction
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		transaction = mocks.NewTransaction()
+		connection := mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		rawToken := helpers.BuildToken(map[string]interface{}{
+			"alg": "RS256",
+		}, map[string]interface{}{
+			"client_id": "mister-client",
+			"exp":       int64(3404281214),
+			"iss":       "https://uaa.example.com/oauth/token",
+		})
+		token, err := jwt.Parse(rawToken, func(*jwt.Token) (interface{}, error) {
+			return []byte(helpers.UAAPublicKey), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		context = stack.NewContext()
+		context.Set("token", token)
+		context.Set("database", database)
+
+		importer = mocks.NewUnsubscribeImporter()
+		importer.ImportCall.Returns.Report = services.UnsubscribeImportReport{
+			Rows: 2,
+			Changes: []services.UnsubscribeImportChange{
+				{UserID: "user-123", ClientID: "some-client", KindID: "some-kind"},
+			},
+			Unchanged: 1,
+		}
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = admin.NewImportUnsubscribesHandler(importer, errorWriter)
+	})
+
+	It("imports the unsubscribes from the CSV", func() {
+		request, err := http.NewRequest("POST", "/admin/unsubscribes/import", strings.NewReader("user,client_id,kind_id\nuser-123, some-client, some-kind\nsomeone@example.com,,\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"dry_run": false,
+			"rows": 2,
+			"unchanged": 1,
+			"changes": [
+				{"user_id": "user-123", "client_id": "some-client", "kind_id": "some-kind"}
+			]
+		}`))
+
+		Expect(importer.ImportCall.Receives.Connection).To(Equal(transaction))
+		Expect(importer.ImportCall.Receives.Entries).To(Equal([]services.UnsubscribeImportEntry{
+			{Line: 2, User: "user-123", ClientID: "some-client", KindID: "some-kind"},
+			{Line: 3, User: "someone@example.com"},
+		}))
+		Expect(importer.ImportCall.Receives.UAAHost).To(Equal("https://uaa.example.com"))
+		Expect(importer.ImportCall.Receives.DryRun).To(BeFalse())
+
+		Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+	})
+
+	It("passes the dry run flag to the importer", func() {
+		request, err := http.NewRequest("POST", "/admin/unsubscribes/import?dry_run=true", strings.NewReader("user,client_id,kind_id\nuser-123,,\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(importer.ImportCall.Receives.DryRun).To(BeTrue())
+	})
+
+	Context("when the CSV is malformed", func() {
+		It("writes a parse error", func() {
+			request, err := http.NewRequest("POST", "/admin/unsubscribes/import", strings.NewReader("user,client_id,kind_id\nuser-123\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+			Expect(importer.ImportCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the CSV is missing the header row", func() {
+		It("writes a validation error", func() {
+			request, err := http.NewRequest("POST", "/admin/unsubscribes/import", strings.NewReader("user-123,some-client,some-kind\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(importer.ImportCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the import fails", func() {
+		It("rolls back the transaction and writes the error", func() {
+			importer.ImportCall.Returns.Error = services.UnsubscribeImportError{Err: errors.New("line 2: user is required")}
+			request, err := http.NewRequest("POST", "/admin/unsubscribes/import", strings.NewReader("user,client_id,kind_id\n,,\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(services.UnsubscribeImportError{Err: errors.New("line 2: user is required")}))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be started", func() {
+		It("writes the error without importing", func() {
+			transaction.BeginCall.Returns.Error = errors.New("connection refused")
+			request, err := http.NewRequest("POST", "/admin/unsubscribes/import", strings.NewReader("user,client_id,kind_id\nuser-123,,\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("connection refused")))
+			Expect(importer.ImportCall.WasCalled).To(BeFalse())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be committed", func() {
+		It("writes a transaction commit error", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+			request, err := http.NewRequest("POST", "/admin/unsubscribes/import", strings.NewReader("user,client_id,kind_id\nuser-123,,\n"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.TransactionCommitError{Err: errors.New("commit failed")}))
+		})
+	})
+})
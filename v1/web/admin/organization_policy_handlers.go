@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var organizationPolicyPath = regexp.MustCompile(".*/admin/organizations/(.*)/policy")
+
+type organizationPoliciesRepo interface {
+	Find(conn models.ConnectionInterface, orgGUID string) (models.OrganizationPolicy, error)
+	Upsert(conn models.ConnectionInterface, policy models.OrganizationPolicy) (models.OrganizationPolicy, error)
+}
+
+type organizationPolicyDocument struct {
+	OrganizationGUID   string `json:"organization_guid"`
+	AuditCriticalSends bool   `json:"audit_critical_sends"`
+}
+
+type GetOrganizationPolicyHandler struct {
+	policies    organizationPoliciesRepo
+	errorWriter errorWriter
+}
+
+func NewGetOrganizationPolicyHandler(policies organizationPoliciesRepo, errWriter errorWriter) GetOrganizationPolicyHandler {
+	return GetOrganizationPolicyHandler{
+		policies:    policies,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetOrganizationPolicyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	orgGUID := organizationPolicyPath.FindStringSubmatch(req.URL.Path)[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	policy, err := h.policies.Find(connection, orgGUID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, organizationPolicyDocument{
+		OrganizationGUID:   policy.OrganizationGUID,
+		AuditCriticalSends: policy.AuditCriticalSends,
+	})
+}
+
+type UpdateOrganizationPolicyHandler struct {
+	policies    organizationPoliciesRepo
+	errorWriter errorWriter
+}
+
+func NewUpdateOrganizationPolicyHandler(policies organizationPoliciesRepo, errWriter errorWriter) UpdateOrganizationPolicyHandler {
+	return UpdateOrganizationPolicyHandler{
+		policies:    policies,
+		errorWriter: errWriter,
+	}
+}
+
+type organizationPolicyParams struct {
+	AuditCriticalSends bool `json:"audit_critical_sends"`
+}
+
+func (h UpdateOrganizationPolicyHandler) RequestBody() interface{} {
+	return organizationPolicyParams{}
+}
+
+func (h UpdateOrganizationPolicyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	orgGUID := organizationPolicyPath.FindStringSubmatch(req.URL.Path)[1]
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	var params organizationPolicyParams
+
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	policy, err := h.policies.Upsert(connection, models.OrganizationPolicy{
+		OrganizationGUID:   orgGUID,
+		AuditCriticalSends: params.AuditCriticalSends,
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, organizationPolicyDocument{
+		OrganizationGUID:   policy.OrganizationGUID,
+		AuditCriticalSends: policy.AuditCriticalSends,
+	})
+}
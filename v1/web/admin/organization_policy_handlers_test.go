@@ -0,0 +1,117 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Organization policy handlers", func() {
+	var (
+		policies    *mocks.OrganizationPoliciesRepo
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		policies = mocks.NewOrganizationPoliciesRepo()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+	})
+
+	Describe("GetOrganizationPolicyHandler", func() {
+		var handler admin.GetOrganizationPolicyHandler
+
+		BeforeEach(func() {
+			handler = admin.NewGetOrganizationPolicyHandler(policies, errorWriter)
+		})
+
+		It("returns the policy for the organization", func() {
+			policies.FindCall.Returns.Policy = models.OrganizationPolicy{
+				OrganizationGUID:   "some-org",
+				AuditCriticalSends: true,
+			}
+
+			request, err := http.NewRequest("GET", "/admin/organizations/some-org/policy", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{"organization_guid": "some-org", "audit_critical_sends": true}`))
+			Expect(policies.FindCall.Receives.Connection).To(Equal(connection))
+			Expect(policies.FindCall.Receives.OrganizationGUID).To(Equal("some-org"))
+		})
+
+		Context("when the repo errors", func() {
+			It("writes the error", func() {
+				policies.FindCall.Returns.Error = errors.New("database is down")
+
+				request, err := http.NewRequest("GET", "/admin/organizations/some-org/policy", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+			})
+		})
+	})
+
+	Describe("UpdateOrganizationPolicyHandler", func() {
+		var handler admin.UpdateOrganizationPolicyHandler
+
+		BeforeEach(func() {
+			policies.UpsertCall.Returns.Policy = models.OrganizationPolicy{
+				OrganizationGUID:   "some-org",
+				AuditCriticalSends: true,
+			}
+			handler = admin.NewUpdateOrganizationPolicyHandler(policies, errorWriter)
+		})
+
+		It("saves the policy for the organization", func() {
+			request, err := http.NewRequest("PUT", "/admin/organizations/some-org/policy", strings.NewReader(`{"audit_critical_sends": true}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{"organization_guid": "some-org", "audit_critical_sends": true}`))
+			Expect(policies.UpsertCall.Receives.Connection).To(Equal(connection))
+			Expect(policies.UpsertCall.Receives.Policy).To(Equal(models.OrganizationPolicy{
+				OrganizationGUID:   "some-org",
+				AuditCriticalSends: true,
+			}))
+		})
+
+		Context("when the body is not valid JSON", func() {
+			It("writes a parse error", func() {
+				request, err := http.NewRequest("PUT", "/admin/organizations/some-org/policy", strings.NewReader(`{"audit_critical_sends":`))
+				Expect(err).NotTo(HaveOccurred())
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+				Expect(policies.UpsertCall.WasCalled).To(BeFalse())
+			})
+		})
+	})
+})
@@ -0,0 +1,63 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type slaMonitor interface {
+	Status() ([]gobble.SLAStatus, error)
+}
+
+// GetQueueSLAHandler shows whether the oldest ready job of each priority
+// has waited longer than the queue SLA allows.
+type GetQueueSLAHandler struct {
+	monitor     slaMonitor
+	errorWriter errorWriter
+}
+
+func NewGetQueueSLAHandler(monitor slaMonitor, errWriter errorWriter) GetQueueSLAHandler {
+	return GetQueueSLAHandler{
+		monitor:     monitor,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetQueueSLAHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	statuses, err := h.monitor.Status()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	type priority struct {
+		Priority         string `json:"priority"`
+		MaxAgeSeconds    int64  `json:"max_age_seconds"`
+		OldestAgeSeconds int64  `json:"oldest_age_seconds"`
+		Compliant        bool   `json:"compliant"`
+	}
+
+	document := struct {
+		Compliant  bool       `json:"compliant"`
+		Priorities []priority `json:"priorities"`
+	}{
+		Compliant:  true,
+		Priorities: []priority{},
+	}
+
+	for _, status := range statuses {
+		document.Compliant = document.Compliant && !status.Breached()
+		document.Priorities = append(document.Priorities, priority{
+			Priority:         gobble.PriorityName(status.Priority),
+			MaxAgeSeconds:    int64(status.MaxAge / time.Second),
+			OldestAgeSeconds: int64(status.OldestAge / time.Second),
+			Compliant:        !status.Breached(),
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
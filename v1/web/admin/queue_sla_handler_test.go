@@ -0,0 +1,71 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetQueueSLAHandler", func() {
+	var (
+		handler     admin.GetQueueSLAHandler
+		monitor     *mocks.SLAMonitor
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		monitor = mocks.NewSLAMonitor()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/admin/queue/sla", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = admin.NewGetQueueSLAHandler(monitor, errorWriter)
+	})
+
+	It("reports the compliance of each monitored priority", func() {
+		monitor.StatusCall.Returns.Statuses = []gobble.SLAStatus{
+			{Priority: gobble.PriorityCritical, MaxAge: time.Minute, OldestAge: 20 * time.Second},
+			{Priority: gobble.PriorityBulk, MaxAge: time.Hour, OldestAge: 90 * time.Minute},
+		}
+
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"compliant": false,
+			"priorities": [
+				{"priority": "critical", "max_age_seconds": 60, "oldest_age_seconds": 20, "compliant": true},
+				{"priority": "bulk", "max_age_seconds": 3600, "oldest_age_seconds": 5400, "compliant": false}
+			]
+		}`))
+	})
+
+	It("is compliant when no SLA is configured", func() {
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"compliant": true, "priorities": []}`))
+	})
+
+	It("writes the error when the queue cannot be read", func() {
+		monitor.StatusCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+	})
+})
@@ -0,0 +1,51 @@
+package admin
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type queueStatsReader interface {
+	Stats() (gobble.QueueStats, error)
+}
+
+// GetQueueStatsHandler shows the backlog of the job queue: how many jobs are
+// waiting, how long the oldest has been ready, how many times jobs have been
+// retried, and how many jobs each worker holds.
+type GetQueueStatsHandler struct {
+	queue       queueStatsReader
+	errorWriter errorWriter
+}
+
+func NewGetQueueStatsHandler(queue queueStatsReader, errWriter errorWriter) GetQueueStatsHandler {
+	return GetQueueStatsHandler{
+		queue:       queue,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetQueueStatsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	stats, err := h.queue.Stats()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, struct {
+		Total            int            `json:"total"`
+		Pending          int            `json:"pending"`
+		OldestAgeSeconds int64          `json:"oldest_age_seconds"`
+		ByRetryCount     map[int]int    `json:"by_retry_count"`
+		ClaimsByWorker   map[string]int `json:"claims_by_worker"`
+	}{
+		Total:            stats.Total,
+		Pending:          stats.Pending,
+		OldestAgeSeconds: int64(stats.OldestAge / time.Second),
+		ByRetryCount:     stats.ByRetryCount,
+		ClaimsByWorker:   stats.ClaimsByWorker,
+	})
+}
@@ -0,0 +1,67 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetQueueStatsHandler", func() {
+	var (
+		handler     admin.GetQueueStatsHandler
+		queue       *mocks.Queue
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		queue = mocks.NewQueue()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/admin/queue/stats", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = admin.NewGetQueueStatsHandler(queue, errorWriter)
+	})
+
+	It("describes the backlog of the queue", func() {
+		queue.StatsCall.Returns.Stats = gobble.QueueStats{
+			Total:          7,
+			Pending:        5,
+			OldestAge:      90 * time.Second,
+			ByRetryCount:   map[int]int{0: 4, 3: 3},
+			ClaimsByWorker: map[string]int{"instance-a-1": 2},
+		}
+
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"total": 7,
+			"pending": 5,
+			"oldest_age_seconds": 90,
+			"by_retry_count": {"0": 4, "3": 3},
+			"claims_by_worker": {"instance-a-1": 2}
+		}`))
+	})
+
+	It("writes the error when the queue cannot be read", func() {
+		queue.StatsCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+	})
+})
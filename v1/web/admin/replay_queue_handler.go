@@ -0,0 +1,39 @@
+package admin
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type queueReplayer interface {
+	Replay(conn models.ConnectionInterface, dryRun bool) (services.QueueReplayReport, error)
+}
+
+type ReplayQueueHandler struct {
+	replayer    queueReplayer
+	errorWriter errorWriter
+}
+
+func NewReplayQueueHandler(replayer queueReplayer, errWriter errorWriter) ReplayQueueHandler {
+	return ReplayQueueHandler{
+		replayer:    replayer,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ReplayQueueHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	dryRun := req.URL.Query().Get("dry_run") == "true"
+
+	conn := context.Get("database").(DatabaseInterface).Connection()
+	report, err := h.replayer.Replay(conn, dryRun)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, report)
+}
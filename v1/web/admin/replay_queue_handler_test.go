@@ -0,0 +1,78 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReplayQueueHandler", func() {
+	var (
+		handler     admin.ReplayQueueHandler
+		replayer    *mocks.QueueReplayer
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		replayer = mocks.NewQueueReplayer()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = admin.NewReplayQueueHandler(replayer, errorWriter)
+	})
+
+	serve := func(path string) {
+		request, err := http.NewRequest("POST", path, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("replays the orphaned messages and reports how many were repaired", func() {
+		replayer.ReplayCall.Returns.Report = services.QueueReplayReport{
+			Orphaned:      3,
+			Repaired:      2,
+			Unrecoverable: 1,
+		}
+
+		serve("/admin/queue/replay")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"orphaned": 3, "repaired": 2, "unrecoverable": 1}`))
+		Expect(replayer.ReplayCall.Receives.Connection).To(Equal(connection))
+		Expect(replayer.ReplayCall.Receives.DryRun).To(BeFalse())
+	})
+
+	It("only counts the orphaned messages on a dry run", func() {
+		serve("/admin/queue/replay?dry_run=true")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(replayer.ReplayCall.Receives.DryRun).To(BeTrue())
+	})
+
+	It("writes the error when the replay fails", func() {
+		replayer.ReplayCall.Returns.Error = errors.New("queue unavailable")
+
+		serve("/admin/queue/replay")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("queue unavailable"))
+	})
+})
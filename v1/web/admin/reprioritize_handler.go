@@ -0,0 +1,80 @@
+package admin
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type errorWriter interface {
+	Write(writer http.ResponseWriter, err error)
+}
+
+type jobReprioritizer interface {
+	Reprioritize(filter services.ReprioritizeFilter, action string, delay time.Duration) (int, error)
+}
+
+type ReprioritizeHandler struct {
+	reprioritizer jobReprioritizer
+	errorWriter   errorWriter
+}
+
+func NewReprioritizeHandler(reprioritizer jobReprioritizer, errWriter errorWriter) ReprioritizeHandler {
+	return ReprioritizeHandler{
+		reprioritizer: reprioritizer,
+		errorWriter:   errWriter,
+	}
+}
+
+type reprioritizeParams struct {
+	ClientID     string `json:"client_id"`
+	KindID       string `json:"kind_id"`
+	Action       string `json:"action"`
+	DelaySeconds int    `json:"delay_seconds"`
+}
+
+func (h ReprioritizeHandler) RequestBody() interface{} {
+	return reprioritizeParams{}
+}
+
+func (h ReprioritizeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	var params reprioritizeParams
+
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	switch params.Action {
+	case services.ReprioritizeBump:
+	case services.ReprioritizeDefer:
+		if params.DelaySeconds <= 0 {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"delay_seconds" must be greater than zero when deferring jobs`)})
+			return
+		}
+	default:
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"action" must be one of "bump" or "defer"`)})
+		return
+	}
+
+	filter := services.ReprioritizeFilter{
+		ClientID: params.ClientID,
+		KindID:   params.KindID,
+	}
+
+	count, err := h.reprioritizer.Reprioritize(filter, params.Action, time.Duration(params.DelaySeconds)*time.Second)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, map[string]int{
+		"rescheduled": count,
+	})
+}
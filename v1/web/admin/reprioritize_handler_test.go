@@ -0,0 +1,95 @@
+package admin_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReprioritizeHandler", func() {
+	var (
+		handler       admin.ReprioritizeHandler
+		reprioritizer *mocks.JobReprioritizer
+		errorWriter   *mocks.ErrorWriter
+		writer        *httptest.ResponseRecorder
+		context       stack.Context
+	)
+
+	BeforeEach(func() {
+		reprioritizer = mocks.NewJobReprioritizer()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+		context = stack.NewContext()
+
+		handler = admin.NewReprioritizeHandler(reprioritizer, errorWriter)
+	})
+
+	serve := func(body string) {
+		request, err := http.NewRequest("POST", "/admin/queue/reprioritize", bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("bumps the matching jobs", func() {
+		reprioritizer.ReprioritizeCall.Returns.Count = 3
+
+		serve(`{"client_id": "some-client", "kind_id": "some-kind", "action": "bump"}`)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"rescheduled": 3}`))
+
+		Expect(reprioritizer.ReprioritizeCall.Receives.Filter).To(Equal(services.ReprioritizeFilter{
+			ClientID: "some-client",
+			KindID:   "some-kind",
+		}))
+		Expect(reprioritizer.ReprioritizeCall.Receives.Action).To(Equal("bump"))
+	})
+
+	It("defers the matching jobs by the given delay", func() {
+		serve(`{"client_id": "some-client", "action": "defer", "delay_seconds": 600}`)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(reprioritizer.ReprioritizeCall.Receives.Action).To(Equal("defer"))
+		Expect(reprioritizer.ReprioritizeCall.Receives.Delay).To(Equal(10 * time.Minute))
+	})
+
+	Context("failure cases", func() {
+		It("writes a parse error for malformed JSON", func() {
+			serve(`{"action": `)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+		})
+
+		It("writes a validation error for an unknown action", func() {
+			serve(`{"action": "banana"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		})
+
+		It("writes a validation error when deferring without a delay", func() {
+			serve(`{"action": "defer"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		})
+
+		It("writes the error returned by the reprioritizer", func() {
+			reprioritizer.ReprioritizeCall.Returns.Error = errors.New("queue is down")
+
+			serve(`{"action": "bump"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("queue is down")))
+		})
+	})
+})
@@ -0,0 +1,57 @@
+package admin
+
+import "github.com/ryanmoran/stack"
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type Routes struct {
+	RequestCounter                   stack.Middleware
+	RequestLogging                   stack.Middleware
+	NotificationsManageAuthenticator stack.Middleware
+	DatabaseAllocator                stack.Middleware
+
+	ErrorWriter          errorWriter
+	JobReprioritizer     jobReprioritizer
+	QueueReplayer        queueReplayer
+	QueueStats           queueStatsReader
+	QueueSLA             slaMonitor
+	WorkerPool           workerPool
+	UnsubscribeImporter  unsubscribeImporter
+	TemplatePackImporter templatePackImporter
+	OrganizationPolicies organizationPoliciesRepo
+	ClientSuspensions    clientSuspensionsRepo
+	ClientQuotas         clientQuotasRepo
+	CriticalApprover     criticalApprover
+	Clock                clock
+	ScheduledJobs        scheduledJobsRepo
+	SchedulerLeases      schedulerLeasesRepo
+	InstanceHeartbeats   instanceHeartbeatsRepo
+	SenderAuthentication senderAuthentication
+	Sender               string
+	SPFIncludes          []string
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("POST", "/admin/queue/reprioritize", NewReprioritizeHandler(r.JobReprioritizer, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator)
+	m.Handle("POST", "/admin/queue/replay", NewReplayQueueHandler(r.QueueReplayer, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/queue/stats", NewGetQueueStatsHandler(r.QueueStats, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator)
+	m.Handle("GET", "/admin/queue/sla", NewGetQueueSLAHandler(r.QueueSLA, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator)
+	m.Handle("GET", "/admin/workers", NewGetWorkerPoolHandler(r.WorkerPool, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator)
+	m.Handle("POST", "/admin/unsubscribes/import", NewImportUnsubscribesHandler(r.UnsubscribeImporter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/admin/template_packs/import", NewImportTemplatePackHandler(r.TemplatePackImporter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/organizations/{org_guid}/policy", NewGetOrganizationPolicyHandler(r.OrganizationPolicies, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/admin/organizations/{org_guid}/policy", NewUpdateOrganizationPolicyHandler(r.OrganizationPolicies, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/clients/{client_id}/suspension", NewGetClientSuspensionHandler(r.ClientSuspensions, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/admin/clients/{client_id}/suspension", NewDeleteClientSuspensionHandler(r.ClientSuspensions, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/clients/{client_id}/quota", NewGetClientQuotaHandler(r.ClientQuotas, r.Clock, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/admin/clients/{client_id}/quota", NewUpdateClientQuotaHandler(r.ClientQuotas, r.Clock, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/admin/clients/{client_id}/quota", NewDeleteClientQuotaHandler(r.ClientQuotas, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/critical_kinds", NewListCriticalApprovalsHandler(r.CriticalApprover, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/admin/critical_kinds/{client_id}/{kind_id}/approve", NewDecideCriticalApprovalHandler(r.CriticalApprover, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/admin/critical_kinds/{client_id}/{kind_id}/deny", NewDecideCriticalApprovalHandler(r.CriticalApprover, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/scheduler", NewGetSchedulerHandler(r.ScheduledJobs, r.SchedulerLeases, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/cluster", NewGetClusterHandler(r.InstanceHeartbeats, r.SchedulerLeases, r.Clock, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/sender_verification", NewGetSenderVerificationHandler(r.SenderAuthentication, r.Sender, r.SPFIncludes, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator)
+}
@@ -0,0 +1,118 @@
+package admin
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/cron"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type scheduledJobsRepo interface {
+	FindAll(conn models.ConnectionInterface) ([]models.ScheduledJob, error)
+}
+
+type schedulerLeasesRepo interface {
+	Find(conn models.ConnectionInterface, name string) (models.SchedulerLease, error)
+}
+
+// GetSchedulerHandler shows which instance runs the maintenance jobs, and
+// the schedule and last run of each job as that instance recorded them.
+type GetSchedulerHandler struct {
+	jobs        scheduledJobsRepo
+	leases      schedulerLeasesRepo
+	errorWriter errorWriter
+}
+
+func NewGetSchedulerHandler(jobs scheduledJobsRepo, leases schedulerLeasesRepo, errWriter errorWriter) GetSchedulerHandler {
+	return GetSchedulerHandler{
+		jobs:        jobs,
+		leases:      leases,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetSchedulerHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	type leader struct {
+		Instance  string    `json:"instance"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+
+	type job struct {
+		Name            string     `json:"name"`
+		Enabled         bool       `json:"enabled"`
+		IntervalSeconds int        `json:"interval_seconds"`
+		LastStatus      string     `json:"last_status"`
+		LastInstance    string     `json:"last_instance,omitempty"`
+		LastStartedAt   *time.Time `json:"last_started_at,omitempty"`
+		LastFinishedAt  *time.Time `json:"last_finished_at,omitempty"`
+		LastError       string     `json:"last_error,omitempty"`
+	}
+
+	document := struct {
+		Leader *leader `json:"leader"`
+		Jobs   []job   `json:"jobs"`
+	}{
+		Jobs: []job{},
+	}
+
+	lease, err := h.leases.Find(connection, cron.LeaseName)
+	switch err.(type) {
+	case nil:
+		document.Leader = &leader{
+			Instance:  lease.Holder,
+			ExpiresAt: lease.ExpiresAt,
+		}
+	case models.NotFoundError:
+	default:
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	jobs, err := h.jobs.FindAll(connection)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	for _, j := range jobs {
+		document.Jobs = append(document.Jobs, job{
+			Name:            j.Name,
+			Enabled:         j.Enabled,
+			IntervalSeconds: j.IntervalSeconds,
+			LastStatus:      lastRunStatus(j),
+			LastInstance:    j.LastInstance,
+			LastStartedAt:   nullTime(j.LastStartedAt),
+			LastFinishedAt:  nullTime(j.LastFinishedAt),
+			LastError:       j.LastError,
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
+
+func lastRunStatus(job models.ScheduledJob) string {
+	switch {
+	case !job.LastStartedAt.Valid:
+		return "never_run"
+	case !job.LastFinishedAt.Valid:
+		return "running"
+	case job.LastError != "":
+		return "failed"
+	default:
+		return "succeeded"
+	}
+}
+
+func nullTime(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+
+	return &t.Time
+}
@@ -0,0 +1,148 @@
+package admin_test
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetSchedulerHandler", func() {
+	var (
+		handler     admin.GetSchedulerHandler
+		jobs        *mocks.ScheduledJobsRepo
+		leases      *mocks.SchedulerLeasesRepo
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		startedAt := time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+
+		jobs = mocks.NewScheduledJobsRepo()
+		jobs.FindAllCall.Returns.Jobs = []models.ScheduledJob{
+			{
+				Name:            "digest_dispatcher",
+				Enabled:         true,
+				IntervalSeconds: 60,
+				LastInstance:    "instance-1",
+				LastStartedAt:   sql.NullTime{Time: startedAt, Valid: true},
+				LastFinishedAt:  sql.NullTime{Time: startedAt.Add(2 * time.Second), Valid: true},
+				LastError:       "smtp is down",
+			},
+			{
+				Name:            "retention_janitor",
+				Enabled:         true,
+				IntervalSeconds: 3600,
+				LastInstance:    "instance-1",
+				LastStartedAt:   sql.NullTime{Time: startedAt, Valid: true},
+			},
+			{
+				Name:            "rollups",
+				Enabled:         false,
+				IntervalSeconds: 300,
+			},
+		}
+
+		leases = mocks.NewSchedulerLeasesRepo()
+		leases.FindCall.Returns.Lease = models.SchedulerLease{
+			Name:      "scheduler",
+			Holder:    "instance-1",
+			ExpiresAt: startedAt.Add(90 * time.Second),
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+		handler = admin.NewGetSchedulerHandler(jobs, leases, errorWriter)
+
+		var err error
+		request, err = http.NewRequest("GET", "/admin/scheduler", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("returns the leader and the status of each job", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"leader": {
+				"instance": "instance-1",
+				"expires_at": "2026-10-17T12:01:30Z"
+			},
+			"jobs": [
+				{
+					"name": "digest_dispatcher",
+					"enabled": true,
+					"interval_seconds": 60,
+					"last_status": "failed",
+					"last_instance": "instance-1",
+					"last_started_at": "2026-10-17T12:00:00Z",
+					"last_finished_at": "2026-10-17T12:00:02Z",
+					"last_error": "smtp is down"
+				},
+				{
+					"name": "retention_janitor",
+					"enabled": true,
+					"interval_seconds": 3600,
+					"last_status": "running",
+					"last_instance": "instance-1",
+					"last_started_at": "2026-10-17T12:00:00Z"
+				},
+				{
+					"name": "rollups",
+					"enabled": false,
+					"interval_seconds": 300,
+					"last_status": "never_run"
+				}
+			]
+		}`))
+		Expect(leases.FindCall.Receives.Connection).To(Equal(connection))
+		Expect(leases.FindCall.Receives.Name).To(Equal("scheduler"))
+		Expect(jobs.FindAllCall.Receives.Connection).To(Equal(connection))
+	})
+
+	It("has no leader before any instance has run the jobs", func() {
+		leases.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("no lease")}
+		jobs.FindAllCall.Returns.Jobs = []models.ScheduledJob{}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{"leader": null, "jobs": []}`))
+	})
+
+	It("writes the error when the lease cannot be loaded", func() {
+		leases.FindCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+	})
+
+	It("writes the error when the jobs cannot be loaded", func() {
+		jobs.FindAllCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+	})
+})
@@ -0,0 +1,107 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var senderDomainPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)+$`)
+
+type senderAuthentication interface {
+	Check(domain string, expect mail.SenderExpectations) mail.SenderReport
+}
+
+type senderFindingDocument struct {
+	Check    string `json:"check"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// GetSenderVerificationHandler checks that a sending domain publishes the
+// SPF, DKIM and DMARC records that mail sent from this deployment needs to
+// authenticate. The domain of the configured sender is checked unless
+// another one is given.
+type GetSenderVerificationHandler struct {
+	authentication senderAuthentication
+	sender         string
+	spfIncludes    []string
+	errorWriter    errorWriter
+}
+
+func NewGetSenderVerificationHandler(authentication senderAuthentication, sender string, spfIncludes []string, errWriter errorWriter) GetSenderVerificationHandler {
+	return GetSenderVerificationHandler{
+		authentication: authentication,
+		sender:         sender,
+		spfIncludes:    spfIncludes,
+		errorWriter:    errWriter,
+	}
+}
+
+func (h GetSenderVerificationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	query := req.URL.Query()
+
+	domain := strings.ToLower(strings.TrimSuffix(query.Get("domain"), "."))
+	if domain == "" {
+		domain = senderDomain(h.sender)
+	}
+
+	if !senderDomainPattern.MatchString(domain) {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"domain" must be a domain name, such as "example.com"`)})
+		return
+	}
+
+	expect := mail.SenderExpectations{
+		SPFIncludes:   h.spfIncludes,
+		DKIMSelectors: query["dkim_selector"],
+	}
+	if includes, ok := query["spf_include"]; ok {
+		expect.SPFIncludes = includes
+	}
+
+	report := h.authentication.Check(domain, expect)
+
+	document := struct {
+		Domain   string                  `json:"domain"`
+		Verified bool                    `json:"verified"`
+		SPF      string                  `json:"spf"`
+		DKIM     map[string]string       `json:"dkim"`
+		DMARC    string                  `json:"dmarc"`
+		Findings []senderFindingDocument `json:"findings"`
+	}{
+		Domain:   report.Domain,
+		Verified: report.Verified(),
+		SPF:      report.SPF,
+		DKIM:     report.DKIM,
+		DMARC:    report.DMARC,
+		Findings: []senderFindingDocument{},
+	}
+
+	if document.DKIM == nil {
+		document.DKIM = map[string]string{}
+	}
+
+	for _, finding := range report.Findings {
+		document.Findings = append(document.Findings, senderFindingDocument{
+			Check:    finding.Check,
+			Severity: finding.Severity,
+			Message:  finding.Message,
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
+
+func senderDomain(sender string) string {
+	at := strings.LastIndex(sender, "@")
+	if at < 0 {
+		return ""
+	}
+
+	return strings.ToLower(strings.Trim(sender[at+1:], "<> "))
+}
@@ -0,0 +1,126 @@
+package admin_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetSenderVerificationHandler", func() {
+	var (
+		handler        admin.GetSenderVerificationHandler
+		authentication *mocks.SenderAuthentication
+		errorWriter    *mocks.ErrorWriter
+		writer         *httptest.ResponseRecorder
+		context        stack.Context
+	)
+
+	BeforeEach(func() {
+		authentication = mocks.NewSenderAuthentication()
+		authentication.CheckCall.Returns.Report = mail.SenderReport{
+			Domain: "example.com",
+			SPF:    "v=spf1 include:_spf.google.com ~all",
+			DKIM:   map[string]string{},
+			DMARC:  "v=DMARC1; p=none",
+			Findings: []mail.SenderFinding{
+				{
+					Check:    mail.SenderCheckSPF,
+					Severity: mail.SeverityError,
+					Message:  "the SPF record of example.com does not include amazonses.com",
+				},
+				{
+					Check:    mail.SenderCheckDMARC,
+					Severity: mail.SeverityWarning,
+					Message:  `the DMARC policy of example.com is "none"`,
+				},
+			},
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+		context = stack.NewContext()
+
+		handler = admin.NewGetSenderVerificationHandler(authentication, "Notifications <no-reply@Example.com>", []string{"amazonses.com"}, errorWriter)
+	})
+
+	It("checks the domain of the configured sender and reports the findings", func() {
+		request, err := http.NewRequest("GET", "/admin/sender_verification", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(authentication.CheckCall.Receives.Domain).To(Equal("example.com"))
+		Expect(authentication.CheckCall.Receives.Expect).To(Equal(mail.SenderExpectations{
+			SPFIncludes: []string{"amazonses.com"},
+		}))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"domain": "example.com",
+			"verified": false,
+			"spf": "v=spf1 include:_spf.google.com ~all",
+			"dkim": {},
+			"dmarc": "v=DMARC1; p=none",
+			"findings": [
+				{
+					"check": "spf",
+					"severity": "error",
+					"message": "the SPF record of example.com does not include amazonses.com"
+				},
+				{
+					"check": "dmarc",
+					"severity": "warning",
+					"message": "the DMARC policy of example.com is \"none\""
+				}
+			]
+		}`))
+	})
+
+	It("checks the given domain, DKIM selectors and SPF includes", func() {
+		request, err := http.NewRequest("GET", "/admin/sender_verification?domain=Mail.Example.org&dkim_selector=s1&dkim_selector=s2&spf_include=spf.mailer.net", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(authentication.CheckCall.Receives.Domain).To(Equal("mail.example.org"))
+		Expect(authentication.CheckCall.Receives.Expect).To(Equal(mail.SenderExpectations{
+			SPFIncludes:   []string{"spf.mailer.net"},
+			DKIMSelectors: []string{"s1", "s2"},
+		}))
+	})
+
+	It("reports a domain that passes every check as verified", func() {
+		authentication.CheckCall.Returns.Report = mail.SenderReport{Domain: "example.com"}
+		request, err := http.NewRequest("GET", "/admin/sender_verification", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"domain": "example.com",
+			"verified": true,
+			"spf": "",
+			"dkim": {},
+			"dmarc": "",
+			"findings": []
+		}`))
+	})
+
+	It("returns a validation error when the domain is not a domain name", func() {
+		request, err := http.NewRequest("GET", "/admin/sender_verification?domain=not%20a%20domain", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		Expect(authentication.CheckCall.CallCount).To(Equal(0))
+	})
+})
@@ -0,0 +1,55 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type workerPool interface {
+	Status() postal.WorkerPoolStatus
+}
+
+// GetWorkerPoolHandler shows how many delivery workers the instance that
+// answers is running, and the bounds its pool is resized within.
+type GetWorkerPoolHandler struct {
+	pool        workerPool
+	errorWriter errorWriter
+}
+
+func NewGetWorkerPoolHandler(pool workerPool, errWriter errorWriter) GetWorkerPoolHandler {
+	return GetWorkerPoolHandler{
+		pool:        pool,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetWorkerPoolHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	if h.pool == nil {
+		h.errorWriter.Write(w, models.NotFoundError{
+			Err:  errors.New("This instance does not run delivery workers"),
+			Code: "worker_pool_not_found",
+		})
+		return
+	}
+
+	status := h.pool.Status()
+
+	webutil.WriteJSON(w, http.StatusOK, struct {
+		Size            int  `json:"size"`
+		Min             int  `json:"min"`
+		Max             int  `json:"max"`
+		Autoscaling     bool `json:"autoscaling"`
+		SendRatePercent int  `json:"send_rate_percent,omitempty"`
+	}{
+		Size:            status.Size,
+		Min:             status.Min,
+		Max:             status.Max,
+		Autoscaling:     status.Autoscaling,
+		SendRatePercent: status.SendRatePercent,
+	})
+}
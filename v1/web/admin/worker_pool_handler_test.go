@@ -0,0 +1,82 @@
+package admin_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetWorkerPoolHandler", func() {
+	var (
+		pool        *mocks.WorkerPool
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		pool = mocks.NewWorkerPool()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		var err error
+		request, err = http.NewRequest("GET", "/admin/workers", nil)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("shows the size of the pool and its bounds", func() {
+		pool.StatusCall.Returns.Status = postal.WorkerPoolStatus{
+			Size:        14,
+			Min:         10,
+			Max:         40,
+			Autoscaling: true,
+		}
+
+		admin.NewGetWorkerPoolHandler(pool, errorWriter).ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"size": 14,
+			"min": 10,
+			"max": 40,
+			"autoscaling": true
+		}`))
+	})
+
+	It("shows the send rate of a pool that follows a curve", func() {
+		pool.StatusCall.Returns.Status = postal.WorkerPoolStatus{
+			Size:            2,
+			Min:             10,
+			Max:             10,
+			SendRatePercent: 20,
+		}
+
+		admin.NewGetWorkerPoolHandler(pool, errorWriter).ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"size": 2,
+			"min": 10,
+			"max": 10,
+			"autoscaling": false,
+			"send_rate_percent": 20
+		}`))
+	})
+
+	It("writes a not found error on an instance without workers", func() {
+		admin.NewGetWorkerPoolHandler(nil, errorWriter).ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(models.NotFoundError{
+			Err:  errors.New("This instance does not run delivery workers"),
+			Code: "worker_pool_not_found",
+		}))
+	})
+})
@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	DefaultPerPage = 50
+	MaxPerPage     = 500
+)
+
+type errorWriter interface {
+	Write(writer http.ResponseWriter, err error)
+}
+
+type auditEventsLister interface {
+	List(conn models.ConnectionInterface, filter models.AuditEventFilter, offset, limit int) ([]models.AuditEvent, error)
+	Count(conn models.ConnectionInterface, filter models.AuditEventFilter) (int, error)
+}
+
+type ListHandler struct {
+	events      auditEventsLister
+	errorWriter errorWriter
+}
+
+func NewListHandler(events auditEventsLister, errWriter errorWriter) ListHandler {
+	return ListHandler{
+		events:      events,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	query := req.URL.Query()
+
+	filter := models.AuditEventFilter{
+		Action:   query.Get("action"),
+		ClientID: query.Get("client_id"),
+		UserID:   query.Get("user_id"),
+	}
+
+	for name, t := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		var err error
+		*t, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf("%q must be an RFC 3339 timestamp, such as \"2015-03-20T12:00:00Z\"", name)})
+			return
+		}
+	}
+
+	page, err := positiveIntParam(query.Get("page"), 1)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"page" must be a positive integer`)})
+		return
+	}
+
+	perPage, err := positiveIntParam(query.Get("per_page"), DefaultPerPage)
+	if err != nil || perPage > MaxPerPage {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf(`"per_page" must be an integer between 1 and %d`, MaxPerPage)})
+		return
+	}
+
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	events, err := h.events.List(connection, filter, (page-1)*perPage, perPage)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	total, err := h.events.Count(connection, filter)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	type event struct {
+		Action        string            `json:"action"`
+		ClientID      string            `json:"client_id"`
+		UserID        string            `json:"user_id,omitempty"`
+		Method        string            `json:"method"`
+		Path          string            `json:"path"`
+		Details       map[string]string `json:"details,omitempty"`
+		VCAPRequestID string            `json:"vcap_request_id,omitempty"`
+		CreatedAt     time.Time         `json:"created_at"`
+	}
+
+	document := struct {
+		Events  []event `json:"audit_events"`
+		Total   int     `json:"total"`
+		Page    int     `json:"page"`
+		PerPage int     `json:"per_page"`
+	}{
+		Events:  []event{},
+		Total:   total,
+		Page:    page,
+		PerPage: perPage,
+	}
+
+	for _, e := range events {
+		var details map[string]string
+		if e.Details != "" {
+			json.Unmarshal([]byte(e.Details), &details)
+		}
+
+		document.Events = append(document.Events, event{
+			Action:        e.Action,
+			ClientID:      e.ClientID,
+			UserID:        e.UserID,
+			Method:        e.Method,
+			Path:          e.Path,
+			Details:       details,
+			VCAPRequestID: e.VCAPRequestID,
+			CreatedAt:     e.CreatedAt,
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
+
+func positiveIntParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 1 {
+		return 0, errors.New("must be positive")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,147 @@
+package audit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/audit"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListHandler", func() {
+	var (
+		handler     audit.ListHandler
+		events      *mocks.AuditEventsRepo
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		events = mocks.NewAuditEventsRepo()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		handler = audit.NewListHandler(events, errorWriter)
+	})
+
+	serve := func(url string) {
+		request, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("returns the requested page of matching events", func() {
+		events.ListCall.Returns.Events = []models.AuditEvent{
+			{
+				Action:        "notification.send_critical",
+				ClientID:      "some-client",
+				Method:        "POST",
+				Path:          "/users/some-user",
+				Details:       `{"kind_id":"outage","recipient":"some-user"}`,
+				VCAPRequestID: "some-request-id",
+				CreatedAt:     time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC),
+			},
+			{
+				Action:    "preferences.update",
+				ClientID:  "some-client",
+				UserID:    "some-user",
+				Method:    "PATCH",
+				Path:      "/user_preferences",
+				CreatedAt: time.Date(2026, 10, 17, 11, 0, 0, 0, time.UTC),
+			},
+		}
+		events.CountCall.Returns.Count = 12
+
+		serve("/audit_events?action=preferences.update&client_id=some-client&user_id=some-user&since=2026-10-01T00:00:00Z&until=2026-10-18T00:00:00Z&page=2&per_page=10")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"audit_events": [
+				{
+					"action": "notification.send_critical",
+					"client_id": "some-client",
+					"method": "POST",
+					"path": "/users/some-user",
+					"details": {"kind_id": "outage", "recipient": "some-user"},
+					"vcap_request_id": "some-request-id",
+					"created_at": "2026-10-17T12:00:00Z"
+				},
+				{
+					"action": "preferences.update",
+					"client_id": "some-client",
+					"user_id": "some-user",
+					"method": "PATCH",
+					"path": "/user_preferences",
+					"created_at": "2026-10-17T11:00:00Z"
+				}
+			],
+			"total": 12,
+			"page": 2,
+			"per_page": 10
+		}`))
+
+		filter := models.AuditEventFilter{
+			Action:   "preferences.update",
+			ClientID: "some-client",
+			UserID:   "some-user",
+			Since:    time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+			Until:    time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC),
+		}
+		Expect(events.ListCall.Receives.Connection).To(Equal(connection))
+		Expect(events.ListCall.Receives.Filter).To(Equal(filter))
+		Expect(events.ListCall.Receives.Offset).To(Equal(10))
+		Expect(events.ListCall.Receives.Limit).To(Equal(10))
+		Expect(events.CountCall.Receives.Filter).To(Equal(filter))
+	})
+
+	It("returns the first page of every event by default", func() {
+		serve("/audit_events")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"audit_events": [],
+			"total": 0,
+			"page": 1,
+			"per_page": 50
+		}`))
+		Expect(events.ListCall.Receives.Filter).To(Equal(models.AuditEventFilter{}))
+	})
+
+	DescribeTable("rejects invalid parameters",
+		func(url string) {
+			serve(url)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		},
+		Entry("since", "/audit_events?since=yesterday"),
+		Entry("until", "/audit_events?until=tomorrow"),
+		Entry("page", "/audit_events?page=0"),
+		Entry("per_page", "/audit_events?per_page=501"),
+	)
+
+	It("writes errors from the repo", func() {
+		events.ListCall.Returns.Error = errors.New("database is down")
+
+		serve("/audit_events")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+	})
+})
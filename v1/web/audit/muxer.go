@@ -0,0 +1,184 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+	"github.com/ryanmoran/stack"
+)
+
+// ActionKey and DetailsKey are the context keys that a handler sets to
+// record an event for a route that is only audited some of the time, such
+// as sends of critical notifications.
+const (
+	ActionKey  = "audit_action"
+	DetailsKey = "audit_details"
+)
+
+// auditedRoutes names the action recorded for each route that changes a
+// registration, template or preference, or that an operator uses to manage
+// the service. Routes with an empty action are recorded only when the
+// handler sets ActionKey.
+var auditedRoutes = map[string]string{
+	"PUT /registration":  "registration.update",
+	"PUT /notifications": "registration.update",
+	"PUT /clients/{client_id}/notifications/{notification_id}":          "notification.update",
+	"PUT /clients/{client_id}/template":                                 "template.assign",
+	"PUT /clients/{client_id}/notifications/{notification_id}/template": "template.assign",
+	"PUT /clients/{client_id}/registration_webhook":                     "registration_webhook.update",
+	"DELETE /clients/{client_id}/registration_webhook":                  "registration_webhook.delete",
+
+	"POST /templates":                                          "template.create",
+	"PUT /templates/{template_id}":                             "template.update",
+	"DELETE /templates/{template_id}":                          "template.delete",
+	"PUT /templates/{template_id}/translations/{locale}":       "template_translation.update",
+	"DELETE /templates/{template_id}/translations/{locale}":    "template_translation.delete",
+	"PUT /templates/{template_id}/versions/{version}/activate": "template_version.activate",
+	"PUT /default_template":                                    "default_template.update",
+	"PUT /digest_template":                                     "digest_template.update",
+	"PUT /template_partials/{name}":                            "template_partial.update",
+	"DELETE /template_partials/{name}":                         "template_partial.delete",
+
+	"PATCH /user_preferences":              "preferences.update",
+	"PATCH /user_preferences/{user_id}":    "preferences.update",
+	"POST /user_preferences/subscriptions": "preferences.subscribe",
+	"PUT /user_preferences/import":         "preferences.import",
+	"PUT /admin/preferences/import":        "preferences.import",
+	"GET /user_preferences/revert/{token}": "preferences.revert",
+	"POST /unsubscribe/{token}":            "preferences.unsubscribe",
+
+	"POST /admin/queue/reprioritize":                          "queue.reprioritize",
+	"POST /admin/queue/replay":                                "queue.replay",
+	"POST /admin/unsubscribes/import":                         "unsubscribes.import",
+	"PUT /admin/organizations/{org_guid}/policy":              "organization_policy.update",
+	"DELETE /admin/clients/{client_id}/suspension":            "client_suspension.delete",
+	"PUT /admin/critical_kinds/{client_id}/{kind_id}/approve": "critical_kind.approve",
+	"PUT /admin/critical_kinds/{client_id}/{kind_id}/deny":    "critical_kind.deny",
+	"DELETE /messages/{message_id}":                           "message.cancel",
+	"POST /messages/{message_id}/retry":                       "message.retry",
+
+	"POST /users/{user_id}":                         "",
+	"POST /spaces/{space_id}":                       "",
+	"POST /spaces/{space_id}/developers":            "",
+	"POST /spaces/{space_id}/managers":              "",
+	"POST /spaces/{space_id}/auditors":              "",
+	"POST /organizations/{org_id}":                  "",
+	"POST /organizations/{org_id}/managers":         "",
+	"POST /organizations/{org_id}/auditors":         "",
+	"POST /organizations/{org_id}/billing_managers": "",
+	"POST /everyone":                                "",
+	"POST /uaa_scopes/{scope}":                      "",
+	"POST /emails":                                  "",
+}
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+type auditEventsRepo interface {
+	Create(conn models.ConnectionInterface, event models.AuditEvent) (models.AuditEvent, error)
+}
+
+// Muxer registers routes with the muxer it wraps, recording an audit event
+// each time one of the audited routes succeeds.
+type Muxer struct {
+	muxer
+	events auditEventsRepo
+}
+
+func NewMuxer(m muxer, events auditEventsRepo) Muxer {
+	return Muxer{
+		muxer:  m,
+		events: events,
+	}
+}
+
+func (m Muxer) Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware) {
+	if action, ok := auditedRoutes[method+" "+path]; ok {
+		handler = Handler{
+			Handler: handler,
+			action:  action,
+			events:  m.events,
+		}
+	}
+
+	m.muxer.Handle(method, path, handler, middleware...)
+}
+
+// Handler records an audit event once the handler it wraps has responded
+// successfully.
+type Handler struct {
+	stack.Handler
+	action string
+	events auditEventsRepo
+}
+
+func (h Handler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+	h.Handler.ServeHTTP(recorder, req, context)
+
+	if recorder.status >= http.StatusMultipleChoices {
+		return
+	}
+
+	action := h.action
+	if action == "" {
+		action, _ = context.Get(ActionKey).(string)
+	}
+
+	database, ok := context.Get("database").(DatabaseInterface)
+	if action == "" || !ok {
+		return
+	}
+
+	event := models.AuditEvent{
+		Action: action,
+		Method: req.Method,
+		Path:   req.URL.Path,
+	}
+
+	if token, ok := context.Get("token").(*jwt.Token); ok {
+		event.ClientID, _ = token.Claims["client_id"].(string)
+		event.UserID, _ = token.Claims["user_id"].(string)
+	}
+
+	if details, ok := context.Get(DetailsKey).(map[string]string); ok {
+		encoded, err := json.Marshal(details)
+		if err == nil {
+			event.Details = string(encoded)
+		}
+	}
+
+	event.VCAPRequestID = webutil.VCAPRequestID(context)
+
+	// The change has been made and answered, so a failure to record it can
+	// only be reported.
+	_, err := h.events.Create(database.Connection(), event)
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.audit.failed", nil).Inc(1)
+		if logger, ok := context.Get("logger").(lager.Logger); ok {
+			logger.Error("audit-event-failed", err, lager.Data{"action": action})
+		}
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath, so that
+// streamed responses can still be flushed.
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
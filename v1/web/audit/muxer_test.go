@@ -0,0 +1,157 @@
+package audit_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/audit"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeHandler struct {
+	status  int
+	context map[string]interface{}
+}
+
+func (h fakeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	for key, value := range h.context {
+		context.Set(key, value)
+	}
+
+	w.WriteHeader(h.status)
+}
+
+type flushingHandler struct {
+	err *error
+}
+
+func (h flushingHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	w.WriteHeader(http.StatusOK)
+	*h.err = http.NewResponseController(w).Flush()
+}
+
+var _ = Describe("Muxer", func() {
+	var (
+		muxer      web.Muxer
+		events     *mocks.AuditEventsRepo
+		database   *mocks.Database
+		connection *mocks.Connection
+		context    stack.Context
+		writer     *httptest.ResponseRecorder
+	)
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		events = mocks.NewAuditEventsRepo()
+
+		connection = mocks.NewConnection()
+		database = mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("vcap_request_id", "some-request-id")
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client",
+			"user_id":   "some-user",
+		}})
+
+		writer = httptest.NewRecorder()
+	})
+
+	serve := func(method, path string, handler stack.Handler) {
+		audit.NewMuxer(muxer, events).Handle(method, "/templates/{template_id}", handler, middleware.RequestLogging{})
+
+		request, err := http.NewRequest(method, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Middleware).To(HaveLen(1))
+		s.Handler.ServeHTTP(writer, request, context)
+	}
+
+	It("lets the handler flush the response it streams", func() {
+		var flushErr error
+		serve("PUT", "/templates/some-template", flushingHandler{err: &flushErr})
+
+		Expect(flushErr).NotTo(HaveOccurred())
+		Expect(writer.Flushed).To(BeTrue())
+	})
+
+	It("records who changed what once an audited route succeeds", func() {
+		serve("PUT", "/templates/some-template", fakeHandler{status: http.StatusOK})
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(events.CreateCall.CallCount).To(Equal(1))
+		Expect(events.CreateCall.Receives.Connection).To(Equal(connection))
+		Expect(events.CreateCall.Receives.Event).To(Equal(models.AuditEvent{
+			Action:        "template.update",
+			ClientID:      "some-client",
+			UserID:        "some-user",
+			Method:        "PUT",
+			Path:          "/templates/some-template",
+			VCAPRequestID: "some-request-id",
+		}))
+	})
+
+	It("does not record requests that fail", func() {
+		serve("DELETE", "/templates/some-template", fakeHandler{status: http.StatusNotFound})
+
+		Expect(writer.Code).To(Equal(http.StatusNotFound))
+		Expect(events.CreateCall.CallCount).To(Equal(0))
+	})
+
+	It("does not wrap routes that are not audited", func() {
+		serve("GET", "/templates/some-template", fakeHandler{status: http.StatusOK})
+
+		Expect(events.CreateCall.CallCount).To(Equal(0))
+	})
+
+	It("still answers the request when the event cannot be recorded", func() {
+		events.CreateCall.Returns.Error = errors.New("database is down")
+
+		serve("PUT", "/templates/some-template", fakeHandler{status: http.StatusNoContent})
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(events.CreateCall.CallCount).To(Equal(1))
+	})
+
+	Context("when the handler decides whether the request is audited", func() {
+		It("records the action and details the handler sets", func() {
+			audit.NewMuxer(muxer, events).Handle("POST", "/users/{user_id}", fakeHandler{
+				status: http.StatusOK,
+				context: map[string]interface{}{
+					audit.ActionKey:  "notification.send_critical",
+					audit.DetailsKey: map[string]string{"kind_id": "outage"},
+				},
+			})
+
+			request, err := http.NewRequest("POST", "/users/some-user", nil)
+			Expect(err).NotTo(HaveOccurred())
+			muxer.Match(request).(stack.Stack).Handler.ServeHTTP(writer, request, context)
+
+			Expect(events.CreateCall.Receives.Event.Action).To(Equal("notification.send_critical"))
+			Expect(events.CreateCall.Receives.Event.Details).To(Equal(`{"kind_id":"outage"}`))
+			Expect(events.CreateCall.Receives.Event.Path).To(Equal("/users/some-user"))
+		})
+
+		It("records nothing when the handler does not set an action", func() {
+			audit.NewMuxer(muxer, events).Handle("POST", "/users/{user_id}", fakeHandler{status: http.StatusOK})
+
+			request, err := http.NewRequest("POST", "/users/some-user", nil)
+			Expect(err).NotTo(HaveOccurred())
+			muxer.Match(request).(stack.Stack).Handler.ServeHTTP(writer, request, context)
+
+			Expect(events.CreateCall.CallCount).To(Equal(0))
+		})
+	})
+})
@@ -0,0 +1,17 @@
+package audit
+
+import "github.com/ryanmoran/stack"
+
+type Routes struct {
+	RequestCounter                   stack.Middleware
+	RequestLogging                   stack.Middleware
+	NotificationsManageAuthenticator stack.Middleware
+	DatabaseAllocator                stack.Middleware
+
+	AuditEvents auditEventsLister
+	ErrorWriter errorWriter
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/audit_events", NewListHandler(r.AuditEvents, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+}
@@ -0,0 +1,44 @@
+package audit_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/audit"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var muxer web.Muxer
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		audit.Routes{
+			RequestCounter:                   middleware.RequestCounter{},
+			RequestLogging:                   middleware.RequestLogging{},
+			NotificationsManageAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.manage"}},
+			DatabaseAllocator:                middleware.DatabaseAllocator{},
+
+			AuditEvents: mocks.NewAuditEventsRepo(),
+			ErrorWriter: mocks.NewErrorWriter(),
+		}.Register(muxer)
+	})
+
+	It("routes GET /audit_events", func() {
+		request, err := http.NewRequest("GET", "/audit_events", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(audit.ListHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
+	})
+})
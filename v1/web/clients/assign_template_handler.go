@@ -5,8 +5,11 @@ import (
 	"net/http"
 	"regexp"
 
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
 
@@ -15,17 +18,24 @@ type errorWriter interface {
 }
 
 type assignsTemplates interface {
-	AssignToClient(connection collections.ConnectionInterface, clientID, templateID string) error
+	AssignToClient(connection collections.ConnectionInterface, zoneID, clientID, templateID string) error
+}
+
+type registrationAuditor interface {
+	Snapshot(conn services.ConnectionInterface, clientID string) (services.RegistrationSnapshot, error)
+	Report(conn services.ConnectionInterface, before services.RegistrationSnapshot, actor common.RegistrationActor) error
 }
 
 type AssignTemplateHandler struct {
 	templateAssigner assignsTemplates
+	auditor          registrationAuditor
 	errorWriter      errorWriter
 }
 
-func NewAssignTemplateHandler(assigner assignsTemplates, errWriter errorWriter) AssignTemplateHandler {
+func NewAssignTemplateHandler(assigner assignsTemplates, auditor registrationAuditor, errWriter errorWriter) AssignTemplateHandler {
 	return AssignTemplateHandler{
 		templateAssigner: assigner,
+		auditor:          auditor,
 		errorWriter:      errWriter,
 	}
 }
@@ -34,9 +44,13 @@ type TemplateAssignment struct {
 	Template string `json:"template"`
 }
 
+func (h AssignTemplateHandler) RequestBody() interface{} {
+	return TemplateAssignment{}
+}
+
 func (h AssignTemplateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	routeRegex := regexp.MustCompile("/clients/(.*)/template")
-	clientID := routeRegex.FindStringSubmatch(req.URL.Path)[1]
+	clientID := webutil.ZonedClientID(context, routeRegex.FindStringSubmatch(req.URL.Path)[1])
 
 	var templateAssignment TemplateAssignment
 	err := json.NewDecoder(req.Body).Decode(&templateAssignment)
@@ -45,8 +59,20 @@ func (h AssignTemplateHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	database := context.Get("database").(DatabaseInterface)
-	err = h.templateAssigner.AssignToClient(database.Connection(), clientID, templateAssignment.Template)
+	connection := context.Get("database").(DatabaseInterface).Connection()
+	before, err := h.auditor.Snapshot(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.templateAssigner.AssignToClient(connection, webutil.ZoneID(context), clientID, templateAssignment.Template)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.auditor.Report(connection, before, registrationActor(context))
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
@@ -54,3 +80,14 @@ func (h AssignTemplateHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+func registrationActor(context stack.Context) common.RegistrationActor {
+	token := context.Get("token").(*jwt.Token)
+	clientID, _ := token.Claims["client_id"].(string)
+	userID, _ := token.Claims["user_id"].(string)
+
+	return common.RegistrationActor{
+		ClientID: clientID,
+		UserID:   userID,
+	}
+}
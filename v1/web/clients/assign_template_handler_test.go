@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -20,6 +22,7 @@ var _ = Describe("AssignTemplateHandler", func() {
 	var (
 		handler          clients.AssignTemplateHandler
 		templateAssigner *mocks.TemplateAssigner
+		auditor          *mocks.RegistrationAuditor
 		errorWriter      *mocks.ErrorWriter
 		context          stack.Context
 		database         *mocks.Database
@@ -34,8 +37,11 @@ var _ = Describe("AssignTemplateHandler", func() {
 		database.ConnectionCall.Returns.Connection = connection
 		context = stack.NewContext()
 		context.Set("database", database)
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client", "user_id": "some-user"}})
 
-		handler = clients.NewAssignTemplateHandler(templateAssigner, errorWriter)
+		auditor = mocks.NewRegistrationAuditor()
+
+		handler = clients.NewAssignTemplateHandler(templateAssigner, auditor, errorWriter)
 	})
 
 	It("associates a template with a client", func() {
@@ -52,10 +58,51 @@ var _ = Describe("AssignTemplateHandler", func() {
 
 		Expect(w.Code).To(Equal(http.StatusNoContent))
 		Expect(templateAssigner.AssignToClientCall.Receives.Connection).To(Equal(connection))
+		Expect(templateAssigner.AssignToClientCall.Receives.ZoneID).To(Equal("uaa"))
 		Expect(templateAssigner.AssignToClientCall.Receives.ClientID).To(Equal("my-client"))
 		Expect(templateAssigner.AssignToClientCall.Receives.TemplateID).To(Equal("my-template"))
 	})
 
+	It("assigns the template to the client of the zone of the token", func() {
+		context.Set(webutil.ZoneIDKey, "tenant-a")
+
+		body, err := json.Marshal(map[string]string{
+			"template": "my-template",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/template", bytes.NewBuffer(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		Expect(templateAssigner.AssignToClientCall.Receives.ZoneID).To(Equal("tenant-a"))
+		Expect(templateAssigner.AssignToClientCall.Receives.ClientID).To(Equal("tenant-a:my-client"))
+	})
+
+	It("reports the template change to the registration webhook of the client", func() {
+		body, err := json.Marshal(map[string]string{
+			"template": "my-template",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/template", bytes.NewBuffer(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(auditor.SnapshotCall.Receives.Connection).To(Equal(connection))
+		Expect(auditor.SnapshotCall.Receives.ClientID).To(Equal("my-client"))
+		Expect(auditor.ReportCall.Receives.Connection).To(Equal(connection))
+		Expect(auditor.ReportCall.Receives.Actor).To(Equal(common.RegistrationActor{
+			ClientID: "admin-client",
+			UserID:   "some-user",
+		}))
+	})
+
 	It("delegates to the error writer when the assigner errors", func() {
 		templateAssigner.AssignToClientCall.Returns.Error = errors.New("banana")
 		body, err := json.Marshal(map[string]string{
@@ -0,0 +1,179 @@
+package clients
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	DefaultActivityRange = 7 * 24 * time.Hour
+	MaxActivityBuckets   = 1000
+)
+
+var kindActivityPath = regexp.MustCompile("/clients/(.*)/kinds/(.*)/activity")
+
+var activityIntervals = map[string]time.Duration{
+	"hour": time.Hour,
+	"day":  24 * time.Hour,
+	"week": 7 * 24 * time.Hour,
+}
+
+type kindActivityLister interface {
+	List(conn models.ConnectionInterface, clientID, kindID string, start, end time.Time) ([]models.KindActivity, error)
+}
+
+type kindFinder interface {
+	Find(conn models.ConnectionInterface, kindID, clientID string) (models.Kind, error)
+}
+
+type clock interface {
+	Now() time.Time
+}
+
+// KindActivityHandler reports how many messages of a kind were sent, how many
+// delivery attempts failed and how many users unsubscribed from it, bucketed
+// by hour, day or week. Buckets start on the hour, at midnight UTC, or at
+// midnight UTC on Mondays, so the start of the range is moved back to the
+// start of its bucket.
+type KindActivityHandler struct {
+	activity    kindActivityLister
+	kinds       kindFinder
+	clock       clock
+	errorWriter errorWriter
+}
+
+func NewKindActivityHandler(activity kindActivityLister, kinds kindFinder, clock clock, errWriter errorWriter) KindActivityHandler {
+	return KindActivityHandler{
+		activity:    activity,
+		kinds:       kinds,
+		clock:       clock,
+		errorWriter: errWriter,
+	}
+}
+
+type activityCounts struct {
+	Sent         int `json:"sent"`
+	Failed       int `json:"failed"`
+	Unsubscribed int `json:"unsubscribed"`
+	Opened       int `json:"opened"`
+	Clicked      int `json:"clicked"`
+}
+
+func (c *activityCounts) add(activity models.KindActivity) {
+	c.Sent += activity.Sent
+	c.Failed += activity.Failed
+	c.Unsubscribed += activity.Unsubscribed
+	c.Opened += activity.Opened
+	c.Clicked += activity.Clicked
+}
+
+func (h KindActivityHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	matches := kindActivityPath.FindStringSubmatch(req.URL.Path)
+	clientID, kindID := webutil.ZonedClientID(context, matches[1]), matches[2]
+	query := req.URL.Query()
+
+	if !mayReadClient(context, clientID) {
+		webutil.WriteError(w, http.StatusForbidden, "client_forbidden", "You are not authorized to read the activity of another client")
+		return
+	}
+
+	intervalName := query.Get("interval")
+	if intervalName == "" {
+		intervalName = "day"
+	}
+
+	interval, ok := activityIntervals[intervalName]
+	if !ok {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"interval" must be one of "hour", "day" or "week"`)})
+		return
+	}
+
+	until := h.clock.Now().UTC()
+	since := until.Add(-DefaultActivityRange)
+	for name, t := range map[string]*time.Time{"since": &since, "until": &until} {
+		value := query.Get(name)
+		if value == "" {
+			continue
+		}
+
+		var err error
+		*t, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf("%q must be an RFC 3339 timestamp, such as \"2015-03-20T12:00:00Z\"", name)})
+			return
+		}
+	}
+
+	since = since.UTC().Truncate(interval)
+	until = until.UTC()
+	if !since.Before(until) {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"since" must be before "until"`)})
+		return
+	}
+
+	count := int((until.Sub(since) + interval - 1) / interval)
+	if count > MaxActivityBuckets {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf("the range covers %d buckets, more than the %d allowed; use a longer interval or a shorter range", count, MaxActivityBuckets)})
+		return
+	}
+
+	connection := context.Get("database").(DatabaseInterface).ReadConnection()
+
+	_, err := h.kinds.Find(connection, kindID, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	activity, err := h.activity.List(connection, clientID, kindID, since, until)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	type bucket struct {
+		Start time.Time `json:"start"`
+		activityCounts
+	}
+
+	buckets := make([]bucket, count)
+	for i := range buckets {
+		buckets[i].Start = since.Add(time.Duration(i) * interval)
+	}
+
+	var totals activityCounts
+	for _, hour := range activity {
+		index := int(hour.Bucket.UTC().Sub(since) / interval)
+		if index < 0 || index >= count {
+			continue
+		}
+
+		buckets[index].add(hour)
+		totals.add(hour)
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, struct {
+		ClientID string         `json:"client_id"`
+		KindID   string         `json:"kind_id"`
+		Interval string         `json:"interval"`
+		Since    time.Time      `json:"since"`
+		Until    time.Time      `json:"until"`
+		Totals   activityCounts `json:"totals"`
+		Buckets  []bucket       `json:"buckets"`
+	}{
+		ClientID: matches[1],
+		KindID:   kindID,
+		Interval: intervalName,
+		Since:    since,
+		Until:    until,
+		Totals:   totals,
+		Buckets:  buckets,
+	})
+}
@@ -0,0 +1,175 @@
+package clients_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("KindActivityHandler", func() {
+	var (
+		handler     clients.KindActivityHandler
+		activity    *mocks.KindActivityRepo
+		kinds       *mocks.KindsRepo
+		errorWriter *mocks.ErrorWriter
+		replica     *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	serve := func(path string) {
+		request, err := http.NewRequest("GET", path, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	BeforeEach(func() {
+		replica = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ReadConnectionCall.Returns.Connection = replica
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client",
+			"scope":     []interface{}{"notifications.write"},
+		}})
+
+		activity = mocks.NewKindActivityRepo()
+		activity.ListCall.Returns.Activity = []models.KindActivity{
+			{Bucket: time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC), Sent: 10, Failed: 2, Opened: 6, Clicked: 2},
+			{Bucket: time.Date(2026, 10, 16, 17, 0, 0, 0, time.UTC), Sent: 5, Unsubscribed: 1},
+			{Bucket: time.Date(2026, 10, 18, 8, 0, 0, 0, time.UTC), Sent: 3, Failed: 1, Unsubscribed: 2, Opened: 1},
+		}
+
+		kinds = mocks.NewKindsRepo()
+		kinds.FindCall.Returns.Kinds = []models.Kind{{ID: "some-kind", ClientID: "some-client"}}
+
+		clock := mocks.NewClock()
+		clock.NowCall.Returns.Time = time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC)
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+		handler = clients.NewKindActivityHandler(activity, kinds, clock, errorWriter)
+	})
+
+	It("buckets the activity of the kind over the range", func() {
+		serve("/clients/some-client/kinds/some-kind/activity?since=2026-10-16T12:00:00Z&until=2026-10-19T00:00:00Z&interval=day")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"client_id": "some-client",
+			"kind_id": "some-kind",
+			"interval": "day",
+			"since": "2026-10-16T00:00:00Z",
+			"until": "2026-10-19T00:00:00Z",
+			"totals": {"sent": 18, "failed": 3, "unsubscribed": 3, "opened": 7, "clicked": 2},
+			"buckets": [
+				{"start": "2026-10-16T00:00:00Z", "sent": 15, "failed": 2, "unsubscribed": 1, "opened": 6, "clicked": 2},
+				{"start": "2026-10-17T00:00:00Z", "sent": 0, "failed": 0, "unsubscribed": 0, "opened": 0, "clicked": 0},
+				{"start": "2026-10-18T00:00:00Z", "sent": 3, "failed": 1, "unsubscribed": 2, "opened": 1, "clicked": 0}
+			]
+		}`))
+
+		Expect(kinds.FindCall.Receives.Connection).To(Equal(replica))
+		Expect(kinds.FindCall.Receives.KindID).To(Equal("some-kind"))
+		Expect(kinds.FindCall.Receives.ClientID).To(Equal("some-client"))
+
+		Expect(activity.ListCall.Receives.Connection).To(Equal(replica))
+		Expect(activity.ListCall.Receives.ClientID).To(Equal("some-client"))
+		Expect(activity.ListCall.Receives.KindID).To(Equal("some-kind"))
+		Expect(activity.ListCall.Receives.Start).To(Equal(time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC)))
+		Expect(activity.ListCall.Receives.End).To(Equal(time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC)))
+	})
+
+	It("defaults to daily buckets over the last week", func() {
+		activity.ListCall.Returns.Activity = nil
+
+		serve("/clients/some-client/kinds/some-kind/activity")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(activity.ListCall.Receives.Start).To(Equal(time.Date(2026, 10, 11, 0, 0, 0, 0, time.UTC)))
+		Expect(activity.ListCall.Receives.End).To(Equal(time.Date(2026, 10, 18, 10, 30, 0, 0, time.UTC)))
+		Expect(writer.Body).To(ContainSubstring(`"interval":"day"`))
+		Expect(writer.Body).To(ContainSubstring(`{"start":"2026-10-18T00:00:00Z","sent":0,"failed":0,"unsubscribed":0,"opened":0,"clicked":0}]`))
+	})
+
+	It("buckets by the hour", func() {
+		serve("/clients/some-client/kinds/some-kind/activity?since=2026-10-18T07:15:00Z&until=2026-10-18T09:00:00Z&interval=hour")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"client_id": "some-client",
+			"kind_id": "some-kind",
+			"interval": "hour",
+			"since": "2026-10-18T07:00:00Z",
+			"until": "2026-10-18T09:00:00Z",
+			"totals": {"sent": 3, "failed": 1, "unsubscribed": 2, "opened": 1, "clicked": 0},
+			"buckets": [
+				{"start": "2026-10-18T07:00:00Z", "sent": 0, "failed": 0, "unsubscribed": 0, "opened": 0, "clicked": 0},
+				{"start": "2026-10-18T08:00:00Z", "sent": 3, "failed": 1, "unsubscribed": 2, "opened": 1, "clicked": 0}
+			]
+		}`))
+	})
+
+	It("forbids reading the activity of another client", func() {
+		serve("/clients/other-client/kinds/some-kind/activity")
+
+		Expect(writer.Code).To(Equal(http.StatusForbidden))
+		Expect(activity.ListCall.Receives.ClientID).To(BeEmpty())
+	})
+
+	It("lets notifications.manage tokens read the activity of any client", func() {
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "admin",
+			"scope":     []interface{}{"notifications.manage"},
+		}})
+
+		serve("/clients/other-client/kinds/some-kind/activity")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(activity.ListCall.Receives.ClientID).To(Equal("other-client"))
+	})
+
+	It("writes the error when the kind cannot be found", func() {
+		kinds.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		serve("/clients/some-client/kinds/missing-kind/activity")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New("not found")}))
+	})
+
+	It("writes the error when the activity cannot be listed", func() {
+		activity.ListCall.Returns.Error = errors.New("database is down")
+
+		serve("/clients/some-client/kinds/some-kind/activity")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+	})
+
+	DescribeTable("writes a validation error for invalid parameters",
+		func(path string) {
+			serve(path)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(activity.ListCall.Receives.ClientID).To(BeEmpty())
+		},
+		Entry("interval", "/clients/some-client/kinds/some-kind/activity?interval=month"),
+		Entry("since", "/clients/some-client/kinds/some-kind/activity?since=yesterday"),
+		Entry("until", "/clients/some-client/kinds/some-kind/activity?until=tomorrow"),
+		Entry("an empty range", "/clients/some-client/kinds/some-kind/activity?since=2026-10-18T00:00:00Z&until=2026-10-17T00:00:00Z"),
+		Entry("too many buckets", "/clients/some-client/kinds/some-kind/activity?since=2026-01-01T00:00:00Z&interval=hour"),
+	)
+})
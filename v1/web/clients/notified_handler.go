@@ -0,0 +1,107 @@
+package clients
+
+import (
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+var notifiedPath = regexp.MustCompile("/clients/(.*)/notified")
+
+type receiptFinder interface {
+	Find(conn models.ConnectionInterface, userGUID, clientID, kindID string) (models.Receipt, error)
+}
+
+// NotifiedHandler tells a client whether a user has already been sent a
+// kind of notification, so that it does not trigger the same one twice.
+// Receipts only record the first and the latest send, so "since" is
+// answered against the latest one.
+type NotifiedHandler struct {
+	receipts    receiptFinder
+	errorWriter errorWriter
+}
+
+func NewNotifiedHandler(receipts receiptFinder, errWriter errorWriter) NotifiedHandler {
+	return NotifiedHandler{
+		receipts:    receipts,
+		errorWriter: errWriter,
+	}
+}
+
+func (h NotifiedHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := webutil.ZonedClientID(context, notifiedPath.FindStringSubmatch(req.URL.Path)[1])
+	query := req.URL.Query()
+
+	if !mayReadClient(context, clientID) {
+		webutil.WriteError(w, http.StatusForbidden, "client_forbidden", "You are not authorized to read the receipts of another client")
+		return
+	}
+
+	kindID := query.Get("kind")
+	if kindID == "" {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"kind" is a required parameter`)})
+		return
+	}
+
+	userGUID := query.Get("user_guid")
+	if userGUID == "" {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"user_guid" is a required parameter`)})
+		return
+	}
+
+	var since time.Time
+	if value := query.Get("since"); value != "" {
+		var err error
+		since, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"since" must be an RFC 3339 timestamp, such as "2015-03-20T12:00:00Z"`)})
+			return
+		}
+	}
+
+	document := struct {
+		Notified        bool       `json:"notified"`
+		Count           int        `json:"count"`
+		FirstNotifiedAt *time.Time `json:"first_notified_at,omitempty"`
+		LastNotifiedAt  *time.Time `json:"last_notified_at,omitempty"`
+	}{}
+
+	connection := context.Get("database").(DatabaseInterface).Connection()
+	receipt, err := h.receipts.Find(connection, userGUID, clientID, kindID)
+	switch err.(type) {
+	case nil:
+		document.Notified = !receipt.UpdatedAt.Before(since)
+		document.Count = receipt.Count
+		document.FirstNotifiedAt = &receipt.CreatedAt
+		document.LastNotifiedAt = &receipt.UpdatedAt
+	case models.NotFoundError:
+	default:
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
+
+// mayReadClient lets notifications.manage tokens read the receipts and
+// activity of any client, and every other token only those of its own.
+func mayReadClient(context stack.Context, clientID string) bool {
+	token := context.Get("token").(*jwt.Token)
+
+	if scopes, ok := token.Claims["scope"].([]interface{}); ok {
+		for _, scope := range scopes {
+			if scope == "notifications.manage" {
+				return true
+			}
+		}
+	}
+
+	tokenClientID, _ := token.Claims["client_id"].(string)
+	return tokenClientID == clientID
+}
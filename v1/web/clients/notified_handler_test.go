@@ -0,0 +1,182 @@
+package clients_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("NotifiedHandler", func() {
+	var (
+		handler     clients.NotifiedHandler
+		receipts    *mocks.ReceiptsRepo
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+			"client_id": "some-client",
+			"scope":     []interface{}{"notifications.write"},
+		}})
+
+		receipts = mocks.NewReceiptsRepo()
+		receipts.FindCall.Returns.Receipt = models.Receipt{
+			UserGUID:  "some-user",
+			ClientID:  "some-client",
+			KindID:    "some-kind",
+			Count:     3,
+			CreatedAt: time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC),
+			UpdatedAt: time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC),
+		}
+
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+		handler = clients.NewNotifiedHandler(receipts, errorWriter)
+	})
+
+	It("says when the user has already been sent the kind", func() {
+		request, err := http.NewRequest("GET", "/clients/some-client/notified?kind=some-kind&user_guid=some-user", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"notified": true,
+			"count": 3,
+			"first_notified_at": "2026-10-01T12:00:00Z",
+			"last_notified_at": "2026-10-15T12:00:00Z"
+		}`))
+		Expect(receipts.FindCall.Receives.Connection).To(Equal(connection))
+		Expect(receipts.FindCall.Receives.UserGUID).To(Equal("some-user"))
+		Expect(receipts.FindCall.Receives.ClientID).To(Equal("some-client"))
+		Expect(receipts.FindCall.Receives.KindID).To(Equal("some-kind"))
+	})
+
+	It("says when the user has never been sent the kind", func() {
+		receipts.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("no receipt")}
+
+		request, err := http.NewRequest("GET", "/clients/some-client/notified?kind=some-kind&user_guid=some-user", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"notified": false,
+			"count": 0
+		}`))
+	})
+
+	Context("when a time window is given", func() {
+		It("is notified when the latest send is within the window", func() {
+			request, err := http.NewRequest("GET", "/clients/some-client/notified?kind=some-kind&user_guid=some-user&since=2026-10-10T00:00:00Z", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(ContainSubstring(`"notified":true`))
+		})
+
+		It("is not notified when the latest send is before the window", func() {
+			request, err := http.NewRequest("GET", "/clients/some-client/notified?kind=some-kind&user_guid=some-user&since=2026-10-16T00:00:00Z", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"notified": false,
+				"count": 3,
+				"first_notified_at": "2026-10-01T12:00:00Z",
+				"last_notified_at": "2026-10-15T12:00:00Z"
+			}`))
+		})
+
+		It("writes a validation error when the window is not a timestamp", func() {
+			request, err := http.NewRequest("GET", "/clients/some-client/notified?kind=some-kind&user_guid=some-user&since=yesterday", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		})
+	})
+
+	It("writes a validation error when the kind is missing", func() {
+		request, err := http.NewRequest("GET", "/clients/some-client/notified?user_guid=some-user", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`"kind" is a required parameter`)}))
+	})
+
+	It("writes a validation error when the user is missing", func() {
+		request, err := http.NewRequest("GET", "/clients/some-client/notified?kind=some-kind", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`"user_guid" is a required parameter`)}))
+	})
+
+	It("writes any other error from the receipts repo", func() {
+		receipts.FindCall.Returns.Error = errors.New("database is down")
+
+		request, err := http.NewRequest("GET", "/clients/some-client/notified?kind=some-kind&user_guid=some-user", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+	})
+
+	Context("when the token belongs to another client", func() {
+		It("forbids the request", func() {
+			request, err := http.NewRequest("GET", "/clients/other-client/notified?kind=some-kind&user_guid=some-user", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusForbidden))
+			Expect(receipts.FindCall.Receives.ClientID).To(BeEmpty())
+		})
+
+		It("allows the request when the token can manage notifications", func() {
+			context.Set("token", &jwt.Token{Claims: map[string]interface{}{
+				"client_id": "admin-client",
+				"scope":     []interface{}{"notifications.manage"},
+			}})
+
+			request, err := http.NewRequest("GET", "/clients/other-client/notified?kind=some-kind&user_guid=some-user", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(receipts.FindCall.Receives.ClientID).To(Equal("other-client"))
+		})
+	})
+})
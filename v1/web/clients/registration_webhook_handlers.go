@@ -0,0 +1,136 @@
+package clients
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var registrationWebhookPath = regexp.MustCompile("/clients/(.*)/registration_webhook")
+
+type registrationWebhooksRepo interface {
+	Find(conn models.ConnectionInterface, clientID string) (models.RegistrationWebhook, error)
+	Upsert(conn models.ConnectionInterface, webhook models.RegistrationWebhook) (models.RegistrationWebhook, error)
+	Delete(conn models.ConnectionInterface, clientID string) error
+}
+
+type registrationWebhookDocument struct {
+	ClientID  string    `json:"client_id"`
+	URL       string    `json:"url"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type GetRegistrationWebhookHandler struct {
+	webhooks    registrationWebhooksRepo
+	errorWriter errorWriter
+}
+
+func NewGetRegistrationWebhookHandler(webhooks registrationWebhooksRepo, errWriter errorWriter) GetRegistrationWebhookHandler {
+	return GetRegistrationWebhookHandler{
+		webhooks:    webhooks,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetRegistrationWebhookHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := webutil.ZonedClientID(context, registrationWebhookPath.FindStringSubmatch(req.URL.Path)[1])
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	webhook, err := h.webhooks.Find(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, registrationWebhookDocument{
+		ClientID:  models.UnzonedClientID(webutil.ZoneID(context), webhook.ClientID),
+		URL:       webhook.URL,
+		UpdatedAt: webhook.UpdatedAt,
+	})
+}
+
+// UpdateRegistrationWebhookHandler sets the URL that is told about changes
+// to the registered kinds and templates of a client.
+type UpdateRegistrationWebhookHandler struct {
+	webhooks    registrationWebhooksRepo
+	errorWriter errorWriter
+}
+
+func NewUpdateRegistrationWebhookHandler(webhooks registrationWebhooksRepo, errWriter errorWriter) UpdateRegistrationWebhookHandler {
+	return UpdateRegistrationWebhookHandler{
+		webhooks:    webhooks,
+		errorWriter: errWriter,
+	}
+}
+
+type registrationWebhookParams struct {
+	URL string `json:"url"`
+}
+
+func (h UpdateRegistrationWebhookHandler) RequestBody() interface{} {
+	return registrationWebhookParams{}
+}
+
+func (h UpdateRegistrationWebhookHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := webutil.ZonedClientID(context, registrationWebhookPath.FindStringSubmatch(req.URL.Path)[1])
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	var params registrationWebhookParams
+
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	if !webutil.ValidCallbackURL(params.URL) {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"url" must be an absolute http or https URL of a public host`)})
+		return
+	}
+
+	webhook, err := h.webhooks.Upsert(connection, models.RegistrationWebhook{
+		ClientID: clientID,
+		URL:      params.URL,
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, registrationWebhookDocument{
+		ClientID:  models.UnzonedClientID(webutil.ZoneID(context), webhook.ClientID),
+		URL:       webhook.URL,
+		UpdatedAt: webhook.UpdatedAt,
+	})
+}
+
+type DeleteRegistrationWebhookHandler struct {
+	webhooks    registrationWebhooksRepo
+	errorWriter errorWriter
+}
+
+func NewDeleteRegistrationWebhookHandler(webhooks registrationWebhooksRepo, errWriter errorWriter) DeleteRegistrationWebhookHandler {
+	return DeleteRegistrationWebhookHandler{
+		webhooks:    webhooks,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DeleteRegistrationWebhookHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	clientID := webutil.ZonedClientID(context, registrationWebhookPath.FindStringSubmatch(req.URL.Path)[1])
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	err := h.webhooks.Delete(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,176 @@
+package clients_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Registration webhook handlers", func() {
+	var (
+		webhooks    *mocks.RegistrationWebhooksRepo
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		updatedAt   time.Time
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		updatedAt = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		webhooks = mocks.NewRegistrationWebhooksRepo()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+	})
+
+	Describe("GetRegistrationWebhookHandler", func() {
+		var handler clients.GetRegistrationWebhookHandler
+
+		BeforeEach(func() {
+			handler = clients.NewGetRegistrationWebhookHandler(webhooks, errorWriter)
+		})
+
+		It("returns the registration webhook of the client", func() {
+			webhooks.FindCall.Returns.Webhook = models.RegistrationWebhook{
+				ClientID:  "some-client",
+				URL:       "https://platform.example.com/registrations",
+				UpdatedAt: updatedAt,
+			}
+
+			request, err := http.NewRequest("GET", "/clients/some-client/registration_webhook", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "some-client",
+				"url": "https://platform.example.com/registrations",
+				"updated_at": "2026-10-17T12:00:00Z"
+			}`))
+			Expect(webhooks.FindCall.Receives.Connection).To(Equal(connection))
+			Expect(webhooks.FindCall.Receives.ClientID).To(Equal("some-client"))
+		})
+
+		It("finds the webhook of the client of the zone of the token", func() {
+			context.Set(webutil.ZoneIDKey, "tenant-a")
+			webhooks.FindCall.Returns.Webhook = models.RegistrationWebhook{
+				ClientID:  "tenant-a:some-client",
+				URL:       "https://platform.example.com/registrations",
+				UpdatedAt: updatedAt,
+			}
+
+			request, err := http.NewRequest("GET", "/clients/some-client/registration_webhook", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "some-client",
+				"url": "https://platform.example.com/registrations",
+				"updated_at": "2026-10-17T12:00:00Z"
+			}`))
+			Expect(webhooks.FindCall.Receives.ClientID).To(Equal("tenant-a:some-client"))
+		})
+
+		It("writes the error when the client has no registration webhook", func() {
+			webhooks.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("no webhook")}
+
+			request, err := http.NewRequest("GET", "/clients/some-client/registration_webhook", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("UpdateRegistrationWebhookHandler", func() {
+		var handler clients.UpdateRegistrationWebhookHandler
+
+		BeforeEach(func() {
+			handler = clients.NewUpdateRegistrationWebhookHandler(webhooks, errorWriter)
+		})
+
+		It("stores the registration webhook of the client", func() {
+			webhooks.UpsertCall.Returns.Webhook = models.RegistrationWebhook{
+				ClientID:  "some-client",
+				URL:       "https://platform.example.com/registrations",
+				UpdatedAt: updatedAt,
+			}
+
+			request, err := http.NewRequest("PUT", "/clients/some-client/registration_webhook", bytes.NewBufferString(`{"url": "https://platform.example.com/registrations"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"client_id": "some-client",
+				"url": "https://platform.example.com/registrations",
+				"updated_at": "2026-10-17T12:00:00Z"
+			}`))
+			Expect(webhooks.UpsertCall.Receives.Connection).To(Equal(connection))
+			Expect(webhooks.UpsertCall.Receives.Webhook).To(Equal(models.RegistrationWebhook{
+				ClientID: "some-client",
+				URL:      "https://platform.example.com/registrations",
+			}))
+		})
+
+		It("rejects a URL that webhooks cannot be posted to", func() {
+			request, err := http.NewRequest("PUT", "/clients/some-client/registration_webhook", bytes.NewBufferString(`{"url": "ftp://platform.example.com"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`"url" must be an absolute http or https URL of a public host`)}))
+		})
+
+		It("writes a parse error when the request body is invalid", func() {
+			request, err := http.NewRequest("PUT", "/clients/some-client/registration_webhook", bytes.NewBufferString(`{"url":`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ParseError{}))
+		})
+	})
+
+	Describe("DeleteRegistrationWebhookHandler", func() {
+		var handler clients.DeleteRegistrationWebhookHandler
+
+		BeforeEach(func() {
+			handler = clients.NewDeleteRegistrationWebhookHandler(webhooks, errorWriter)
+		})
+
+		It("deletes the registration webhook of the client", func() {
+			request, err := http.NewRequest("DELETE", "/clients/some-client/registration_webhook", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(webhooks.DeleteCall.Receives.Connection).To(Equal(connection))
+			Expect(webhooks.DeleteCall.Receives.ClientID).To(Equal("some-client"))
+		})
+	})
+})
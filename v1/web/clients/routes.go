@@ -7,15 +7,27 @@ type muxer interface {
 }
 
 type Routes struct {
-	RequestCounter                   stack.Middleware
-	RequestLogging                   stack.Middleware
-	NotificationsManageAuthenticator stack.Middleware
-	DatabaseAllocator                stack.Middleware
+	RequestCounter                          stack.Middleware
+	RequestLogging                          stack.Middleware
+	NotificationsManageAuthenticator        stack.Middleware
+	NotificationsWriteOrManageAuthenticator stack.Middleware
+	DatabaseAllocator                       stack.Middleware
 
-	ErrorWriter      errorWriter
-	TemplateAssigner assignsTemplates
+	ErrorWriter          errorWriter
+	TemplateAssigner     assignsTemplates
+	RegistrationAuditor  registrationAuditor
+	RegistrationWebhooks registrationWebhooksRepo
+	Receipts             receiptFinder
+	KindActivity         kindActivityLister
+	Kinds                kindFinder
+	Clock                clock
 }
 
 func (r Routes) Register(m muxer) {
-	m.Handle("PUT", "/clients/{client_id}/template", NewAssignTemplateHandler(r.TemplateAssigner, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/clients/{client_id}/template", NewAssignTemplateHandler(r.TemplateAssigner, r.RegistrationAuditor, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/clients/{client_id}/registration_webhook", NewGetRegistrationWebhookHandler(r.RegistrationWebhooks, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/clients/{client_id}/registration_webhook", NewUpdateRegistrationWebhookHandler(r.RegistrationWebhooks, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/clients/{client_id}/notified", NewNotifiedHandler(r.Receipts, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsWriteOrManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/clients/{client_id}/kinds/{kind_id}/activity", NewKindActivityHandler(r.KindActivity, r.Kinds, r.Clock, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsWriteOrManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/clients/{client_id}/registration_webhook", NewDeleteRegistrationWebhookHandler(r.RegistrationWebhooks, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
 }
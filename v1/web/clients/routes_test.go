@@ -20,13 +20,20 @@ var _ = Describe("Routes", func() {
 	BeforeEach(func() {
 		muxer = web.NewMuxer()
 		clients.Routes{
-			RequestCounter:                   middleware.RequestCounter{},
-			RequestLogging:                   middleware.RequestLogging{},
-			DatabaseAllocator:                middleware.DatabaseAllocator{},
-			NotificationsManageAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.manage"}},
+			RequestCounter:                          middleware.RequestCounter{},
+			RequestLogging:                          middleware.RequestLogging{},
+			DatabaseAllocator:                       middleware.DatabaseAllocator{},
+			NotificationsManageAuthenticator:        middleware.Authenticator{Scopes: []string{"notifications.manage"}},
+			NotificationsWriteOrManageAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.write", "notifications.manage"}},
 
-			ErrorWriter:      mocks.NewErrorWriter(),
-			TemplateAssigner: mocks.NewTemplateAssigner(),
+			ErrorWriter:          mocks.NewErrorWriter(),
+			TemplateAssigner:     mocks.NewTemplateAssigner(),
+			RegistrationAuditor:  mocks.NewRegistrationAuditor(),
+			RegistrationWebhooks: mocks.NewRegistrationWebhooksRepo(),
+			Receipts:             mocks.NewReceiptsRepo(),
+			KindActivity:         mocks.NewKindActivityRepo(),
+			Kinds:                mocks.NewKindsRepo(),
+			Clock:                mocks.NewClock(),
 		}.Register(muxer)
 	})
 
@@ -41,4 +48,62 @@ var _ = Describe("Routes", func() {
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
 	})
+
+	It("routes GET /clients/{client_id}/registration_webhook", func() {
+		request, err := http.NewRequest("GET", "/clients/some-client-id/registration_webhook", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(clients.GetRegistrationWebhookHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
+	})
+
+	It("routes PUT /clients/{client_id}/registration_webhook", func() {
+		request, err := http.NewRequest("PUT", "/clients/some-client-id/registration_webhook", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(clients.UpdateRegistrationWebhookHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
+	})
+
+	It("routes DELETE /clients/{client_id}/registration_webhook", func() {
+		request, err := http.NewRequest("DELETE", "/clients/some-client-id/registration_webhook", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(clients.DeleteRegistrationWebhookHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
+	})
+	It("routes GET /clients/{client_id}/notified", func() {
+		request, err := http.NewRequest("GET", "/clients/some-client-id/notified", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(clients.NotifiedHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write", "notifications.manage"}))
+	})
+	It("routes GET /clients/{client_id}/kinds/{kind_id}/activity", func() {
+		request, err := http.NewRequest("GET", "/clients/some-client-id/kinds/some-kind-id/activity", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(clients.KindActivityHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write", "notifications.manage"}))
+	})
 })
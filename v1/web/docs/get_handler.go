@@ -0,0 +1,30 @@
+package docs
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ryanmoran/stack"
+)
+
+type GetHandler struct {
+	catalog *Catalog
+}
+
+func NewGetHandler(catalog *Catalog) GetHandler {
+	return GetHandler{
+		catalog: catalog,
+	}
+}
+
+func (handler GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	output, err := json.Marshal(handler.catalog.Document("1"))
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -0,0 +1,34 @@
+package docs_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetHandler", func() {
+	Describe("ServeHTTP", func() {
+		It("returns a 200 response code and the OpenAPI document", func() {
+			catalog := docs.NewCatalog()
+			catalog.Add("GET", "/info", fakeHandler{})
+
+			writer := httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/api/docs/openapi.json", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			docs.NewGetHandler(catalog).ServeHTTP(writer, request, nil)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Header().Get("Content-Type")).To(Equal("application/json"))
+
+			expected, err := json.Marshal(catalog.Document("1"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(writer.Body.String()).To(MatchJSON(expected))
+		})
+	})
+})
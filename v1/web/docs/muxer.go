@@ -0,0 +1,78 @@
+package docs
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/ryanmoran/stack"
+)
+
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+}
+
+// requestBodyDescriber is implemented by the handlers of routes that take a
+// request body. RequestBody returns a value of the type the body decodes
+// into, or a RawBody for bodies that are not JSON.
+type requestBodyDescriber interface {
+	RequestBody() interface{}
+}
+
+// RawBody describes a request body that is read as is rather than decoded
+// from JSON, such as an uploaded file.
+type RawBody struct {
+	ContentType string
+}
+
+type route struct {
+	method string
+	path   string
+	scopes []string
+	body   interface{}
+}
+
+// Catalog lists the routes registered through a Muxer, in the order they
+// were registered, along with the scopes their authenticators require.
+type Catalog struct {
+	routes []route
+}
+
+func NewCatalog() *Catalog {
+	return &Catalog{}
+}
+
+func (c *Catalog) Add(method, path string, handler stack.Handler, middlewares ...stack.Middleware) {
+	r := route{
+		method: method,
+		path:   path,
+	}
+
+	if describer, ok := handler.(requestBodyDescriber); ok {
+		r.body = describer.RequestBody()
+	}
+
+	for _, m := range middlewares {
+		if authenticator, ok := m.(middleware.Authenticator); ok {
+			r.scopes = append(r.scopes, authenticator.Scopes...)
+		}
+	}
+
+	c.routes = append(c.routes, r)
+}
+
+// Muxer registers routes with the muxer it wraps, adding each one to the
+// catalog the OpenAPI document is generated from.
+type Muxer struct {
+	muxer
+	catalog *Catalog
+}
+
+func NewMuxer(m muxer, catalog *Catalog) Muxer {
+	return Muxer{
+		muxer:   m,
+		catalog: catalog,
+	}
+}
+
+func (m Muxer) Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware) {
+	m.catalog.Add(method, path, handler, middleware...)
+	m.muxer.Handle(method, path, handler, middleware...)
+}
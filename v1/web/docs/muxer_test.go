@@ -0,0 +1,75 @@
+package docs_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeHandler struct{}
+
+func (h fakeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {}
+
+type fakeBodyHandler struct {
+	fakeHandler
+	body interface{}
+}
+
+func (h fakeBodyHandler) RequestBody() interface{} {
+	return h.body
+}
+
+var _ = Describe("Muxer", func() {
+	var (
+		muxer   web.Muxer
+		catalog *docs.Catalog
+	)
+
+	BeforeEach(func() {
+		muxer = web.NewMuxer()
+		catalog = docs.NewCatalog()
+	})
+
+	It("registers the route with the muxer it wraps", func() {
+		docs.NewMuxer(muxer, catalog).Handle("GET", "/things/{thing_id}", fakeHandler{}, middleware.RequestCounter{})
+
+		request, err := http.NewRequest("GET", "/things/some-thing", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(Equal(fakeHandler{}))
+	})
+
+	It("adds the route and the scopes it requires to the catalog", func() {
+		documented := docs.NewMuxer(muxer, catalog)
+		documented.Handle("GET", "/things/{thing_id}", fakeHandler{}, middleware.RequestCounter{}, middleware.Authenticator{Scopes: []string{"things.read"}})
+		documented.Handle("POST", "/things", fakeHandler{}, middleware.RequestCounter{})
+
+		document := catalog.Document("1")
+		Expect(document.Paths).To(HaveLen(2))
+		Expect(document.Paths["/things/{thing_id}"]["get"].Security).To(Equal([]map[string][]string{
+			{"uaa": {"things.read"}},
+		}))
+		Expect(document.Paths["/things"]["post"].Security).To(BeNil())
+	})
+
+	It("adds the request body the handler describes to the catalog", func() {
+		type thingParams struct {
+			Name string `json:"name"`
+		}
+
+		documented := docs.NewMuxer(muxer, catalog)
+		documented.Handle("GET", "/things", fakeHandler{})
+		documented.Handle("POST", "/things", fakeBodyHandler{body: thingParams{}})
+
+		item := catalog.Document("1").Paths["/things"]
+		Expect(item["get"].RequestBody).To(BeNil())
+		Expect(item["post"].RequestBody.Content["application/json"].Schema.Properties).To(HaveKeyWithValue("name", docs.Schema{Type: "string"}))
+	})
+})
@@ -0,0 +1,229 @@
+package docs
+
+import (
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const securitySchemeName = "uaa"
+
+var (
+	pathParameterFormat = regexp.MustCompile(`{([^}:]+)(:[^}]*)?}`)
+	operationIDFormat   = regexp.MustCompile(`[^a-z0-9]+`)
+	timeType            = reflect.TypeOf(time.Time{})
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+)
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem holds the operations of a path, keyed by lowercase method.
+type PathItem map[string]Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string `json:"name"`
+	In       string `json:"in"`
+	Required bool   `json:"required"`
+	Schema   Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema Schema `json:"schema"`
+}
+
+type Response struct {
+	Description string `json:"description"`
+}
+
+type Schema struct {
+	Type                 string            `json:"type,omitempty"`
+	Format               string            `json:"format,omitempty"`
+	Properties           map[string]Schema `json:"properties,omitempty"`
+	Required             []string          `json:"required,omitempty"`
+	Items                *Schema           `json:"items,omitempty"`
+	AdditionalProperties *Schema           `json:"additionalProperties,omitempty"`
+}
+
+type Components struct {
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat"`
+}
+
+// Document describes every route in the catalog. Routes behind an
+// authenticator require a UAA bearer token, and list the scopes the token
+// must carry as the roles of their security requirement.
+func (c *Catalog) Document(version string) Document {
+	document := Document{
+		OpenAPI: "3.1.0",
+		Info: Info{
+			Title:   "Notifications",
+			Version: version,
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			SecuritySchemes: map[string]SecurityScheme{
+				securitySchemeName: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+				},
+			},
+		},
+	}
+
+	for _, r := range c.routes {
+		path := pathParameterFormat.ReplaceAllString(r.path, "{$1}")
+
+		item, ok := document.Paths[path]
+		if !ok {
+			item = PathItem{}
+			document.Paths[path] = item
+		}
+
+		item[strings.ToLower(r.method)] = newOperation(r, path)
+	}
+
+	return document
+}
+
+func newOperation(r route, path string) Operation {
+	operation := Operation{
+		OperationID: strings.Trim(operationIDFormat.ReplaceAllString(strings.ToLower(r.method+" "+path), "_"), "_"),
+		Parameters: []Parameter{
+			{
+				Name:   "X-NOTIFICATIONS-VERSION",
+				In:     "header",
+				Schema: Schema{Type: "integer"},
+			},
+		},
+		Responses: map[string]Response{
+			"default": {Description: "See V1_API.md for the responses of this route"},
+		},
+	}
+
+	for _, match := range pathParameterFormat.FindAllStringSubmatch(path, -1) {
+		operation.Parameters = append(operation.Parameters, Parameter{
+			Name:     match[1],
+			In:       "path",
+			Required: true,
+			Schema:   Schema{Type: "string"},
+		})
+	}
+
+	switch body := r.body.(type) {
+	case nil:
+	case RawBody:
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				body.ContentType: {Schema: Schema{Type: "string", Format: "binary"}},
+			},
+		}
+	default:
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]MediaType{
+				"application/json": {Schema: SchemaFor(reflect.TypeOf(body))},
+			},
+		}
+	}
+
+	if r.scopes != nil {
+		operation.Security = []map[string][]string{
+			{securitySchemeName: r.scopes},
+		}
+	}
+
+	return operation
+}
+
+// SchemaFor describes the JSON a value of the given type decodes from. Only
+// struct fields with a json tag are included: parameter structs keep the
+// state they derive from the request in untagged fields.
+func SchemaFor(t reflect.Type) Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t {
+	case timeType:
+		return Schema{Type: "string", Format: "date-time"}
+	case rawMessageType:
+		return Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return Schema{Type: "string"}
+	case reflect.Bool:
+		return Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		items := SchemaFor(t.Elem())
+		return Schema{Type: "array", Items: &items}
+	case reflect.Map:
+		values := SchemaFor(t.Elem())
+		return Schema{Type: "object", AdditionalProperties: &values}
+	case reflect.Struct:
+		return structSchema(t)
+	default:
+		return Schema{}
+	}
+}
+
+func structSchema(t reflect.Type) Schema {
+	schema := Schema{
+		Type:       "object",
+		Properties: map[string]Schema{},
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		schema.Properties[name] = SchemaFor(field.Type)
+		if field.Tag.Get("validate-required") == "true" {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	return schema
+}
@@ -0,0 +1,181 @@
+package docs_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type nullMuxer struct{}
+
+func (m nullMuxer) Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware) {
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+var _ = Describe("Catalog", func() {
+	Describe("Document", func() {
+		var catalog *docs.Catalog
+
+		BeforeEach(func() {
+			catalog = docs.NewCatalog()
+		})
+
+		It("describes each route with its path parameters", func() {
+			catalog.Add("GET", "/templates/{template_id}/translations/{locale}", fakeHandler{}, middleware.Authenticator{Scopes: []string{"notification_templates.read"}})
+			catalog.Add("DELETE", "/templates/{template_id}/translations/{locale}", fakeHandler{}, middleware.Authenticator{Scopes: []string{"notification_templates.write"}})
+
+			document := catalog.Document("1")
+			Expect(document.OpenAPI).To(Equal("3.1.0"))
+			Expect(document.Info.Version).To(Equal("1"))
+			Expect(document.Components.SecuritySchemes).To(HaveKey("uaa"))
+
+			item := document.Paths["/templates/{template_id}/translations/{locale}"]
+			Expect(item).To(HaveLen(2))
+
+			operation := item["get"]
+			Expect(operation.OperationID).To(Equal("get_templates_template_id_translations_locale"))
+			Expect(operation.Parameters).To(Equal([]docs.Parameter{
+				{Name: "X-NOTIFICATIONS-VERSION", In: "header", Schema: docs.Schema{Type: "integer"}},
+				{Name: "template_id", In: "path", Required: true, Schema: docs.Schema{Type: "string"}},
+				{Name: "locale", In: "path", Required: true, Schema: docs.Schema{Type: "string"}},
+			}))
+			Expect(operation.RequestBody).To(BeNil())
+			Expect(operation.Responses).To(HaveKey("default"))
+			Expect(operation.Security).To(Equal([]map[string][]string{
+				{"uaa": {"notification_templates.read"}},
+			}))
+		})
+
+		It("describes the body of routes that take a parameter struct", func() {
+			catalog.Add("PUT", "/clients/{client_id}/notifications/{notification_id}", fakeBodyHandler{body: notifications.NotificationUpdateParams{}})
+
+			operation := catalog.Document("1").Paths["/clients/{client_id}/notifications/{notification_id}"]["put"]
+			Expect(operation.RequestBody).NotTo(BeNil())
+			Expect(operation.RequestBody.Required).To(BeTrue())
+
+			schema := operation.RequestBody.Content["application/json"].Schema
+			Expect(schema.Type).To(Equal("object"))
+			Expect(schema.Required).To(ConsistOf("description", "critical", "template"))
+			Expect(schema.Properties).To(HaveKeyWithValue("transactional", docs.Schema{Type: "boolean"}))
+			Expect(schema.Properties["retry_policy"].Properties).To(HaveKeyWithValue("max_attempts", docs.Schema{Type: "integer"}))
+		})
+
+		It("describes raw request bodies by their content type", func() {
+			catalog.Add("POST", "/admin/unsubscribes/import", fakeBodyHandler{body: docs.RawBody{ContentType: "text/csv"}})
+
+			operation := catalog.Document("1").Paths["/admin/unsubscribes/import"]["post"]
+			Expect(operation.RequestBody).To(Equal(&docs.RequestBody{
+				Required: true,
+				Content: map[string]docs.MediaType{
+					"text/csv": {Schema: docs.Schema{Type: "string", Format: "binary"}},
+				},
+			}))
+		})
+
+		It("describes the body of every v1 route that takes one", func() {
+			// These routes read nothing from their request body.
+			bodyless := []string{
+				"post /admin/queue/replay",
+				"put /admin/critical_kinds/{client_id}/{kind_id}/approve",
+				"put /admin/critical_kinds/{client_id}/{kind_id}/deny",
+				"post /messages/{message_id}/retry",
+				"put /templates/{template_id}/versions/{version}/activate",
+			}
+
+			documented := docs.NewMuxer(nullMuxer{}, catalog)
+			admin.Routes{}.Register(documented)
+			clients.Routes{}.Register(documented)
+			messages.Routes{}.Register(documented)
+			notifications.Routes{}.Register(documented)
+			notify.Routes{}.Register(documented)
+			preferences.Routes{}.Register(documented)
+			templates.Routes{}.Register(documented)
+
+			var missing []string
+			for path, item := range catalog.Document("1").Paths {
+				for method, operation := range item {
+					if method != "post" && method != "put" && method != "patch" {
+						continue
+					}
+
+					route := method + " " + path
+					if operation.RequestBody == nil && !contains(bodyless, route) {
+						missing = append(missing, route)
+					}
+				}
+			}
+
+			Expect(missing).To(BeEmpty())
+		})
+
+		It("marshals to JSON", func() {
+			catalog.Add("POST", "/emails", fakeHandler{})
+
+			output, err := json.Marshal(catalog.Document("1"))
+			Expect(err).NotTo(HaveOccurred())
+
+			var document map[string]interface{}
+			Expect(json.Unmarshal(output, &document)).To(Succeed())
+			Expect(document).To(HaveKeyWithValue("openapi", "3.1.0"))
+			Expect(document["paths"]).To(HaveKey("/emails"))
+		})
+	})
+})
+
+var _ = Describe("SchemaFor", func() {
+	It("describes the tagged fields of a struct", func() {
+		type nested struct {
+			Count int `json:"count"`
+		}
+
+		type params struct {
+			Name       string            `json:"name,omitempty" validate-required:"true"`
+			Tags       []string          `json:"tags"`
+			Labels     map[string]string `json:"labels"`
+			Nested     *nested           `json:"nested"`
+			SentAt     time.Time         `json:"sent_at"`
+			Ratio      float64           `json:"ratio"`
+			Metadata   json.RawMessage   `json:"metadata"`
+			Ignored    string            `json:"-"`
+			Untagged   string
+			unexported string
+		}
+
+		Expect(docs.SchemaFor(reflect.TypeOf(params{}))).To(Equal(docs.Schema{
+			Type: "object",
+			Properties: map[string]docs.Schema{
+				"name":     {Type: "string"},
+				"tags":     {Type: "array", Items: &docs.Schema{Type: "string"}},
+				"labels":   {Type: "object", AdditionalProperties: &docs.Schema{Type: "string"}},
+				"nested":   {Type: "object", Properties: map[string]docs.Schema{"count": {Type: "integer"}}},
+				"sent_at":  {Type: "string", Format: "date-time"},
+				"ratio":    {Type: "number"},
+				"metadata": {},
+			},
+			Required: []string{"name"},
+		}))
+	})
+})
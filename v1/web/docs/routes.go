@@ -0,0 +1,14 @@
+package docs
+
+import "github.com/ryanmoran/stack"
+
+type Routes struct {
+	RequestLogging stack.Middleware
+	RequestCounter stack.Middleware
+
+	Catalog *Catalog
+}
+
+func (r Routes) Register(m muxer) {
+	m.Handle("GET", "/api/docs/openapi.json", NewGetHandler(r.Catalog), r.RequestLogging, r.RequestCounter)
+}
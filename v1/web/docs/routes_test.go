@@ -0,0 +1,42 @@
+package docs_test
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Routes", func() {
+	var (
+		muxer   web.Muxer
+		catalog *docs.Catalog
+	)
+
+	BeforeEach(func() {
+		catalog = docs.NewCatalog()
+
+		muxer = web.NewMuxer()
+		docs.Routes{
+			RequestCounter: middleware.RequestCounter{},
+			RequestLogging: middleware.RequestLogging{},
+
+			Catalog: catalog,
+		}.Register(muxer)
+	})
+
+	It("routes GET /api/docs/openapi.json", func() {
+		request, err := http.NewRequest("GET", "/api/docs/openapi.json", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(Equal(docs.NewGetHandler(catalog)))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{})
+	})
+})
@@ -0,0 +1,56 @@
+package info
+
+import (
+	"runtime"
+	"runtime/debug"
+)
+
+// Capabilities describes what this deployment of the service supports, so
+// that clients can adapt to it rather than assume the defaults.
+type Capabilities struct {
+	Features Features
+	Limits   Limits
+	Build    Build
+}
+
+type Features struct {
+	Channels         []string
+	ReadOnly         bool
+	UnsubscribeLinks bool
+	HTMLSanitizer    string
+}
+
+type Limits struct {
+	HTMLBodyBytes          int
+	ClientRateLimit        int
+	ClientRateLimitBurst   int
+	IdempotencyWindowHours int
+}
+
+type Build struct {
+	Version   string
+	Revision  string
+	GoVersion string
+}
+
+// ReadBuild reports the module version and VCS revision stamped into the
+// running binary by the Go toolchain.
+func ReadBuild() Build {
+	build := Build{
+		GoVersion: runtime.Version(),
+	}
+
+	buildInfo, ok := debug.ReadBuildInfo()
+	if !ok {
+		return build
+	}
+
+	build.Version = buildInfo.Main.Version
+	for _, setting := range buildInfo.Settings {
+		if setting.Key == "vcs.revision" {
+			build.Revision = setting.Value
+		}
+	}
+
+	return build
+}
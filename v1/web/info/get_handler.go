@@ -8,21 +8,49 @@ import (
 )
 
 type GetHandler struct {
+	capabilities Capabilities
 }
 
-func NewGetHandler() GetHandler {
-	return GetHandler{}
+func NewGetHandler(capabilities Capabilities) GetHandler {
+	return GetHandler{
+		capabilities: capabilities,
+	}
 }
 
 func (handler GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
-	w.WriteHeader(http.StatusOK)
+	channels := handler.capabilities.Features.Channels
+	if channels == nil {
+		channels = []string{}
+	}
+
 	output, err := json.Marshal(map[string]interface{}{
-		"version": 1,
+		"version":      1,
+		"api_versions": []int{1},
+		"features": map[string]interface{}{
+			"v2":                false,
+			"tracking":          false,
+			"channels":          channels,
+			"read_only":         handler.capabilities.Features.ReadOnly,
+			"unsubscribe_links": handler.capabilities.Features.UnsubscribeLinks,
+			"html_sanitizer":    handler.capabilities.Features.HTMLSanitizer,
+		},
+		"limits": map[string]interface{}{
+			"html_body_bytes":          handler.capabilities.Limits.HTMLBodyBytes,
+			"client_rate_limit":        handler.capabilities.Limits.ClientRateLimit,
+			"client_rate_limit_burst":  handler.capabilities.Limits.ClientRateLimitBurst,
+			"idempotency_window_hours": handler.capabilities.Limits.IdempotencyWindowHours,
+		},
+		"build": map[string]interface{}{
+			"version":    handler.capabilities.Build.Version,
+			"revision":   handler.capabilities.Build.Revision,
+			"go_version": handler.capabilities.Build.GoVersion,
+		},
 	})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	w.WriteHeader(http.StatusOK)
 	w.Write(output)
 }
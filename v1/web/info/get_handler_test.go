@@ -15,10 +15,28 @@ var _ = Describe("GetHandler", func() {
 		var handler info.GetHandler
 
 		BeforeEach(func() {
-			handler = info.NewGetHandler()
+			handler = info.NewGetHandler(info.Capabilities{
+				Features: info.Features{
+					Channels:         []string{"email"},
+					ReadOnly:         true,
+					UnsubscribeLinks: true,
+					HTMLSanitizer:    "strict",
+				},
+				Limits: info.Limits{
+					HTMLBodyBytes:          102400,
+					ClientRateLimit:        60,
+					ClientRateLimitBurst:   10,
+					IdempotencyWindowHours: 24,
+				},
+				Build: info.Build{
+					Version:   "v1.2.3",
+					Revision:  "abc123",
+					GoVersion: "go1.20",
+				},
+			})
 		})
 
-		It("returns a 200 response code and an empty JSON body", func() {
+		It("returns a 200 response code and describes the deployment", func() {
 			writer := httptest.NewRecorder()
 			request, err := http.NewRequest("GET", "/info", nil)
 			if err != nil {
@@ -29,8 +47,49 @@ var _ = Describe("GetHandler", func() {
 
 			Expect(writer.Code).To(Equal(http.StatusOK))
 			Expect(writer.Body.String()).To(MatchJSON(`{
-				"version": 1
+				"version": 1,
+				"api_versions": [1],
+				"features": {
+					"v2": false,
+					"tracking": false,
+					"channels": ["email"],
+					"read_only": true,
+					"unsubscribe_links": true,
+					"html_sanitizer": "strict"
+				},
+				"limits": {
+					"html_body_bytes": 102400,
+					"client_rate_limit": 60,
+					"client_rate_limit_burst": 10,
+					"idempotency_window_hours": 24
+				},
+				"build": {
+					"version": "v1.2.3",
+					"revision": "abc123",
+					"go_version": "go1.20"
+				}
 			}`))
 		})
+
+		It("reports an empty list of channels rather than null", func() {
+			handler = info.NewGetHandler(info.Capabilities{})
+
+			writer := httptest.NewRecorder()
+			request, err := http.NewRequest("GET", "/info", nil)
+			if err != nil {
+				panic(err)
+			}
+
+			handler.ServeHTTP(writer, request, nil)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(ContainSubstring(`"channels":[]`))
+		})
+	})
+
+	Describe("ReadBuild", func() {
+		It("reports the version of Go the binary was built with", func() {
+			Expect(info.ReadBuild().GoVersion).To(HavePrefix("go"))
+		})
 	})
 })
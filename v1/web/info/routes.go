@@ -9,8 +9,10 @@ type muxer interface {
 type Routes struct {
 	RequestLogging stack.Middleware
 	RequestCounter stack.Middleware
+
+	Capabilities Capabilities
 }
 
 func (r Routes) Register(m muxer) {
-	m.Handle("GET", "/info", NewGetHandler(), r.RequestLogging, r.RequestCounter)
+	m.Handle("GET", "/info", NewGetHandler(r.Capabilities), r.RequestLogging, r.RequestCounter)
 }
@@ -14,13 +14,23 @@ import (
 )
 
 var _ = Describe("Routes", func() {
-	var muxer web.Muxer
+	var (
+		muxer        web.Muxer
+		capabilities info.Capabilities
+	)
 
 	BeforeEach(func() {
+		capabilities = info.Capabilities{
+			Features: info.Features{Channels: []string{"email"}},
+			Limits:   info.Limits{HTMLBodyBytes: 1024},
+		}
+
 		muxer = web.NewMuxer()
 		info.Routes{
 			RequestCounter: middleware.RequestCounter{},
 			RequestLogging: middleware.RequestLogging{},
+
+			Capabilities: capabilities,
 		}.Register(muxer)
 	})
 
@@ -29,7 +39,7 @@ var _ = Describe("Routes", func() {
 		Expect(err).NotTo(HaveOccurred())
 
 		s := muxer.Match(request).(stack.Stack)
-		Expect(s.Handler).To(BeAssignableToTypeOf(info.GetHandler{}))
+		Expect(s.Handler).To(Equal(info.NewGetHandler(capabilities)))
 		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{})
 	})
 })
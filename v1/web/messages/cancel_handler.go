@@ -0,0 +1,37 @@
+package messages
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/ryanmoran/stack"
+)
+
+type messageCanceler interface {
+	Cancel(services.DatabaseInterface, string) error
+}
+
+type CancelHandler struct {
+	canceler    messageCanceler
+	errorWriter errorWriter
+}
+
+func NewCancelHandler(canceler messageCanceler, errWriter errorWriter) CancelHandler {
+	return CancelHandler{
+		canceler:    canceler,
+		errorWriter: errWriter,
+	}
+}
+
+func (h CancelHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	messageID := strings.Split(req.URL.Path, "/messages/")[1]
+
+	err := h.canceler.Cancel(context.Get("database").(DatabaseInterface), messageID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
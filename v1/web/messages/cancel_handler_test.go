@@ -0,0 +1,58 @@
+package messages_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("CancelHandler", func() {
+	var (
+		handler     messages.CancelHandler
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		canceler    *mocks.MessageCanceler
+		database    *mocks.Database
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		errorWriter = mocks.NewErrorWriter()
+		canceler = mocks.NewMessageCanceler()
+		writer = httptest.NewRecorder()
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		var err error
+		request, err = http.NewRequest("DELETE", "/messages/message-123", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = messages.NewCancelHandler(canceler, errorWriter)
+	})
+
+	It("cancels the message", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(canceler.CancelCall.Receives.Database).To(Equal(database))
+		Expect(canceler.CancelCall.Receives.MessageID).To(Equal("message-123"))
+	})
+
+	Context("when the message cannot be canceled", func() {
+		It("delegates to the error writer", func() {
+			canceler.CancelCall.Returns.Error = errors.New("already delivered")
+
+			handler.ServeHTTP(writer, request, context)
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("already delivered"))
+		})
+	})
+})
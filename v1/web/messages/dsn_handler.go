@@ -0,0 +1,64 @@
+package messages
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+// MaxDSNReportSize bounds the bounce messages DSNHandler reads. Reports
+// return only the headers of the original message, so they are small.
+const MaxDSNReportSize = 1 << 20
+
+type dsnRecorder interface {
+	Record(conn services.ConnectionInterface, report mail.DSN) (models.Message, error)
+}
+
+// DSNHandler takes the delivery status notifications that mail servers send
+// back to the envelope sender, as whole messages forwarded by the mailbox
+// that receives them, and applies each to the message it reports on.
+type DSNHandler struct {
+	recorder    dsnRecorder
+	errorWriter errorWriter
+}
+
+func NewDSNHandler(recorder dsnRecorder, errWriter errorWriter) DSNHandler {
+	return DSNHandler{
+		recorder:    recorder,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DSNHandler) RequestBody() interface{} {
+	return docs.RawBody{ContentType: "multipart/report"}
+}
+
+func (h DSNHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	report, err := mail.ParseDSN(http.MaxBytesReader(w, req.Body, MaxDSNReportSize))
+	if err != nil {
+		h.errorWriter.Write(w, services.DSNReportError{Err: err})
+		return
+	}
+
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	message, err := h.recorder.Record(connection, report)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	var document struct {
+		MessageID string `json:"message_id"`
+		Status    string `json:"status"`
+	}
+	document.MessageID = message.ID
+	document.Status = message.Status
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
@@ -0,0 +1,103 @@
+package messages_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+const successReport = "From: MAILER-DAEMON@mx.example.com\r\n" +
+	"Subject: Successful Mail Delivery Report\r\n" +
+	"Content-Type: multipart/report; report-type=delivery-status; boundary=\"report\"\r\n" +
+	"\r\n" +
+	"--report\r\n" +
+	"Content-Type: message/delivery-status\r\n" +
+	"\r\n" +
+	"Reporting-MTA: dns; mx.example.com\r\n" +
+	"Original-Envelope-Id: message-123\r\n" +
+	"\r\n" +
+	"Final-Recipient: rfc822; user@example.com\r\n" +
+	"Action: delivered\r\n" +
+	"Status: 2.0.0\r\n" +
+	"\r\n" +
+	"--report--\r\n"
+
+var _ = Describe("DSNHandler", func() {
+	var (
+		handler     messages.DSNHandler
+		recorder    *mocks.DSNRecorder
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		recorder = mocks.NewDSNRecorder()
+		recorder.RecordCall.Returns.Message = models.Message{ID: "message-123", Status: common.StatusConfirmed}
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = messages.NewDSNHandler(recorder, errorWriter)
+	})
+
+	It("applies the report to the message it is about", func() {
+		request, err := http.NewRequest("POST", "/messages/dsn", strings.NewReader(successReport))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{"message_id": "message-123", "status": "confirmed"}`))
+
+		Expect(recorder.RecordCall.Receives.Connection).To(Equal(connection))
+		Expect(recorder.RecordCall.Receives.Report).To(Equal(mail.DSN{
+			EnvelopeID: "message-123",
+			Recipients: []mail.DSNRecipient{{
+				FinalRecipient: "user@example.com",
+				Action:         mail.DSNActionDelivered,
+				Status:         "2.0.0",
+			}},
+		}))
+	})
+
+	It("rejects a body that is not a delivery status notification", func() {
+		request, err := http.NewRequest("POST", "/messages/dsn", strings.NewReader("Subject: hello\r\n\r\nHi\r\n"))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(services.DSNReportError{}))
+		Expect(recorder.RecordCall.CallCount).To(Equal(0))
+	})
+
+	It("delegates errors from recording the report to the error writer", func() {
+		recorder.RecordCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+		request, err := http.NewRequest("POST", "/messages/dsn", strings.NewReader(successReport))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New("not found")}))
+	})
+})
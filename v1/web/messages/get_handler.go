@@ -1,11 +1,12 @@
 package messages
 
 import (
-	"encoding/json"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -39,19 +40,38 @@ func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 	}
 
 	var document struct {
-		Status string `json:"status"`
+		Status             string            `json:"status"`
+		Reason             string            `json:"reason,omitempty"`
+		SMTPCode           int               `json:"smtp_code,omitempty"`
+		SMTPEnhancedStatus string            `json:"smtp_enhanced_status,omitempty"`
+		Simulated          bool              `json:"simulated,omitempty"`
+		Severity           string            `json:"severity,omitempty"`
+		WorkerID           string            `json:"worker_id,omitempty"`
+		ClaimedAt          *time.Time        `json:"claimed_at,omitempty"`
+		Opens              int               `json:"opens,omitempty"`
+		Clicks             int               `json:"clicks,omitempty"`
+		Metadata           map[string]string `json:"metadata,omitempty"`
 	}
 	document.Status = message.Status
+	document.Simulated = message.Simulated
+	document.Severity = message.Severity
+	document.Reason = message.Reason
+	document.SMTPCode = message.SMTPCode
+	document.SMTPEnhancedStatus = message.SMTPEnhancedStatus
+	document.WorkerID = message.WorkerID
+	document.ClaimedAt = claimedAt(message)
+	document.Opens = message.Opens
+	document.Clicks = message.Clicks
+	document.Metadata = message.Metadata
 
-	writeJSON(w, http.StatusOK, document)
+	webutil.WriteJSON(w, http.StatusOK, document)
 }
 
-func writeJSON(w http.ResponseWriter, status int, object interface{}) {
-	output, err := json.Marshal(object)
-	if err != nil {
-		panic(err) // No JSON we write into a response should ever panic
+// claimedAt leaves the claim time out for messages no worker has picked up.
+func claimedAt(message services.Message) *time.Time {
+	if message.ClaimedAt.IsZero() {
+		return nil
 	}
 
-	w.WriteHeader(status)
-	w.Write(output)
+	return &message.ClaimedAt
 }
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
@@ -61,6 +62,110 @@ var _ = Describe("GetHandler", func() {
 			Expect(messageFinder.FindCall.Receives.MessageID).To(Equal(messageID))
 		})
 
+		It("includes the worker that claimed the message", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status:    "delivered",
+				WorkerID:  "worker-1-some-instance-42",
+				ClaimedAt: time.Date(2015, 3, 20, 12, 4, 0, 0, time.UTC),
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "delivered",
+				"worker_id": "worker-1-some-instance-42",
+				"claimed_at": "2015-03-20T12:04:00Z"
+			}`))
+		})
+
+		It("includes how often a tracked message was opened and clicked", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status: "delivered",
+				Opens:  3,
+				Clicks: 1,
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "delivered",
+				"opens": 3,
+				"clicks": 1
+			}`))
+		})
+
+		It("includes why an undeliverable message was not sent", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status: "undeliverable",
+				Reason: "invalid_address",
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "undeliverable",
+				"reason": "invalid_address"
+			}`))
+		})
+
+		It("marks messages of a client in simulation mode", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status:    "delivered",
+				Simulated: true,
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "delivered",
+				"simulated": true
+			}`))
+		})
+
+		It("includes the severity of the notification", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status:   "delivered",
+				Severity: "warning",
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "delivered",
+				"severity": "warning"
+			}`))
+		})
+
+		It("includes the reply of the server that bounced the message", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status:             "hard_bounced",
+				SMTPCode:           550,
+				SMTPEnhancedStatus: "5.1.1",
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "hard_bounced",
+				"smtp_code": 550,
+				"smtp_enhanced_status": "5.1.1"
+			}`))
+		})
+
+		It("echoes the metadata of the notification", func() {
+			messageFinder.FindCall.Returns.Message = services.Message{
+				Status:   "delivered",
+				Metadata: map[string]string{"ticket": "INC-1234"},
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.Bytes()).To(MatchJSON(`{
+				"status": "delivered",
+				"metadata": {"ticket": "INC-1234"}
+			}`))
+		})
+
 		Context("When the finder errors", func() {
 			It("Delegates to the error writer", func() {
 				findError := errors.New("The finder returns a generic error")
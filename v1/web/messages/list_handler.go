@@ -0,0 +1,193 @@
+package messages
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	DefaultPerPage = 50
+	MaxPerPage     = 500
+)
+
+type messageLister interface {
+	List(database services.DatabaseInterface, filter models.MessageFilter, page, perPage int) (services.MessageList, error)
+	Each(database services.DatabaseInterface, filter models.MessageFilter, fn func(services.Message) error) error
+}
+
+type listedMessage struct {
+	ID                 string            `json:"id"`
+	ClientID           string            `json:"client_id"`
+	Recipient          string            `json:"recipient,omitempty"`
+	Simulated          bool              `json:"simulated,omitempty"`
+	Severity           string            `json:"severity,omitempty"`
+	Status             string            `json:"status"`
+	Reason             string            `json:"reason,omitempty"`
+	SMTPCode           int               `json:"smtp_code,omitempty"`
+	SMTPEnhancedStatus string            `json:"smtp_enhanced_status,omitempty"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	WorkerID           string            `json:"worker_id,omitempty"`
+	ClaimedAt          *time.Time        `json:"claimed_at,omitempty"`
+	Metadata           map[string]string `json:"metadata,omitempty"`
+}
+
+// newListedMessage lists the message with the client ID the UAA zone knows
+// its client by.
+func newListedMessage(zoneID string, m services.Message) listedMessage {
+	return listedMessage{
+		ID:                 m.ID,
+		ClientID:           models.UnzonedClientID(zoneID, m.ClientID),
+		Recipient:          m.Recipient,
+		Simulated:          m.Simulated,
+		Severity:           m.Severity,
+		Status:             m.Status,
+		Reason:             m.Reason,
+		SMTPCode:           m.SMTPCode,
+		SMTPEnhancedStatus: m.SMTPEnhancedStatus,
+		CreatedAt:          m.CreatedAt,
+		UpdatedAt:          m.UpdatedAt,
+		WorkerID:           m.WorkerID,
+		ClaimedAt:          claimedAt(m),
+		Metadata:           m.Metadata,
+	}
+}
+
+type ListHandler struct {
+	lister      messageLister
+	errorWriter errorWriter
+}
+
+func NewListHandler(lister messageLister, errWriter errorWriter) ListHandler {
+	return ListHandler{
+		lister:      lister,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	query := req.URL.Query()
+
+	filter := models.MessageFilter{
+		ClientID: query.Get("client_id"),
+		Status:   query.Get("status"),
+	}
+
+	var err error
+	filter.Since, err = sinceParam(query.Get("since"))
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	h.list(w, req, context, filter)
+}
+
+// list writes a page of the messages matching the filter, or every one of
+// them when the request asks for NDJSON.
+func (h ListHandler) list(w http.ResponseWriter, req *http.Request, context stack.Context, filter models.MessageFilter) {
+	query := req.URL.Query()
+
+	filter.ZoneID = webutil.ZoneID(context)
+	if filter.ClientID != "" {
+		filter.ClientID = webutil.ZonedClientID(context, filter.ClientID)
+	}
+
+	if webutil.WantsNDJSON(req) {
+		h.stream(w, context.Get("database").(DatabaseInterface), filter)
+		return
+	}
+
+	page, err := positiveIntParam(query.Get("page"), 1)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"page" must be a positive integer`)})
+		return
+	}
+
+	perPage, err := positiveIntParam(query.Get("per_page"), DefaultPerPage)
+	if err != nil || perPage > MaxPerPage {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf(`"per_page" must be an integer between 1 and %d`, MaxPerPage)})
+		return
+	}
+
+	list, err := h.lister.List(context.Get("database").(DatabaseInterface), filter, page, perPage)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	document := struct {
+		Messages []listedMessage `json:"messages"`
+		Total    int             `json:"total"`
+		Page     int             `json:"page"`
+		PerPage  int             `json:"per_page"`
+	}{
+		Messages: []listedMessage{},
+		Total:    list.Total,
+		Page:     page,
+		PerPage:  perPage,
+	}
+
+	for _, m := range list.Messages {
+		document.Messages = append(document.Messages, newListedMessage(filter.ZoneID, m))
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
+
+// stream writes every matching message, one per line, without paging.
+func (h ListHandler) stream(w http.ResponseWriter, database DatabaseInterface, filter models.MessageFilter) {
+	writer := webutil.NewNDJSONWriter(w)
+
+	err := h.lister.Each(database, filter, func(m services.Message) error {
+		return writer.Write(newListedMessage(filter.ZoneID, m))
+	})
+	if err != nil {
+		// Once lines have been sent the status can no longer change, so the
+		// response just ends early.
+		if !writer.Started() {
+			h.errorWriter.Write(w, err)
+		}
+		return
+	}
+
+	writer.Close()
+}
+
+func sinceParam(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	since, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, webutil.ValidationError{Err: errors.New(`"since" must be an RFC 3339 timestamp, such as "2015-03-20T12:00:00Z"`)}
+	}
+
+	return since, nil
+}
+
+func positiveIntParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 1 {
+		return 0, errors.New("must be positive")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,289 @@
+package messages_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ListHandler", func() {
+	var (
+		handler       messages.ListHandler
+		errorWriter   *mocks.ErrorWriter
+		writer        *httptest.ResponseRecorder
+		messageLister *mocks.MessageLister
+		database      *mocks.Database
+		context       stack.Context
+	)
+
+	BeforeEach(func() {
+		errorWriter = mocks.NewErrorWriter()
+		messageLister = mocks.NewMessageLister()
+		writer = httptest.NewRecorder()
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		handler = messages.NewListHandler(messageLister, errorWriter)
+	})
+
+	serve := func(url string) {
+		request, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("returns the requested page of matching messages", func() {
+		messageLister.ListCall.Returns.MessageList = services.MessageList{
+			Messages: []services.Message{
+				{
+					ID:        "message-123",
+					ClientID:  "some-client",
+					Status:    "failed",
+					CreatedAt: time.Date(2015, 3, 20, 12, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2015, 3, 20, 12, 5, 0, 0, time.UTC),
+				},
+			},
+			Total: 21,
+		}
+
+		serve("/messages?client_id=some-client&status=failed&since=2015-03-20T00:00:00Z&page=3&per_page=10")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"messages": [
+				{
+					"id": "message-123",
+					"client_id": "some-client",
+					"status": "failed",
+					"created_at": "2015-03-20T12:00:00Z",
+					"updated_at": "2015-03-20T12:05:00Z"
+				}
+			],
+			"total": 21,
+			"page": 3,
+			"per_page": 10
+		}`))
+
+		Expect(messageLister.ListCall.Receives.Database).To(Equal(database))
+		Expect(messageLister.ListCall.Receives.Filter).To(Equal(models.MessageFilter{
+			ClientID: "some-client",
+			Status:   "failed",
+			Since:    time.Date(2015, 3, 20, 0, 0, 0, 0, time.UTC),
+			ZoneID:   "uaa",
+		}))
+		Expect(messageLister.ListCall.Receives.Page).To(Equal(3))
+		Expect(messageLister.ListCall.Receives.PerPage).To(Equal(10))
+	})
+
+	It("defaults to the first page of every message", func() {
+		serve("/messages")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{"messages": [], "total": 0, "page": 1, "per_page": 50}`))
+		Expect(messageLister.ListCall.Receives.Filter).To(Equal(models.MessageFilter{ZoneID: "uaa"}))
+	})
+
+	It("lists only the messages of the zone of the token, under the client IDs the zone knows", func() {
+		context.Set(webutil.ZoneIDKey, "tenant-a")
+		messageLister.ListCall.Returns.MessageList = services.MessageList{
+			Messages: []services.Message{
+				{
+					ID:        "message-123",
+					ClientID:  "tenant-a:some-client",
+					Status:    "failed",
+					CreatedAt: time.Date(2015, 3, 20, 12, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2015, 3, 20, 12, 5, 0, 0, time.UTC),
+				},
+			},
+			Total: 1,
+		}
+
+		serve("/messages?client_id=some-client")
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"messages": [
+				{
+					"id": "message-123",
+					"client_id": "some-client",
+					"status": "failed",
+					"created_at": "2015-03-20T12:00:00Z",
+					"updated_at": "2015-03-20T12:05:00Z"
+				}
+			],
+			"total": 1,
+			"page": 1,
+			"per_page": 50
+		}`))
+		Expect(messageLister.ListCall.Receives.Filter).To(Equal(models.MessageFilter{
+			ClientID: "tenant-a:some-client",
+			ZoneID:   "tenant-a",
+		}))
+	})
+
+	It("includes the worker that claimed a message", func() {
+		messageLister.ListCall.Returns.MessageList = services.MessageList{
+			Messages: []services.Message{
+				{
+					ID:        "message-123",
+					ClientID:  "some-client",
+					Status:    "delivered",
+					CreatedAt: time.Date(2015, 3, 20, 12, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2015, 3, 20, 12, 5, 0, 0, time.UTC),
+					WorkerID:  "worker-1-some-instance-42",
+					ClaimedAt: time.Date(2015, 3, 20, 12, 4, 0, 0, time.UTC),
+				},
+			},
+			Total: 1,
+		}
+
+		serve("/messages")
+
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"messages": [
+				{
+					"id": "message-123",
+					"client_id": "some-client",
+					"status": "delivered",
+					"created_at": "2015-03-20T12:00:00Z",
+					"updated_at": "2015-03-20T12:05:00Z",
+					"worker_id": "worker-1-some-instance-42",
+					"claimed_at": "2015-03-20T12:04:00Z"
+				}
+			],
+			"total": 1,
+			"page": 1,
+			"per_page": 50
+		}`))
+	})
+
+	DescribeTable("rejects invalid query parameters",
+		func(query, message string) {
+			serve("/messages?" + query)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ValidationError{Err: errors.New(message)}))
+			Expect(messageLister.ListCall.Receives.Database).To(BeNil())
+		},
+		Entry("since", "since=yesterday", `"since" must be an RFC 3339 timestamp, such as "2015-03-20T12:00:00Z"`),
+		Entry("page", "page=0", `"page" must be a positive integer`),
+		Entry("per_page below one", "per_page=-1", `"per_page" must be an integer between 1 and 500`),
+		Entry("per_page above the maximum", "per_page=501", `"per_page" must be an integer between 1 and 500`),
+	)
+
+	It("delegates lister errors to the error writer", func() {
+		messageLister.ListCall.Returns.Error = errors.New("database is down")
+
+		serve("/messages")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+	})
+
+	Context("when newline delimited JSON is accepted", func() {
+		stream := func(url string) {
+			request, err := http.NewRequest("GET", url, nil)
+			Expect(err).NotTo(HaveOccurred())
+			request.Header.Set("Accept", "application/x-ndjson")
+
+			handler.ServeHTTP(writer, request, context)
+		}
+
+		It("streams every matching message on its own line, ignoring paging", func() {
+			messageLister.EachCall.Returns.Messages = []services.Message{
+				{
+					ID:        "message-123",
+					ClientID:  "some-client",
+					Status:    "undeliverable",
+					Reason:    "invalid_address",
+					CreatedAt: time.Date(2015, 3, 20, 12, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2015, 3, 20, 12, 5, 0, 0, time.UTC),
+				},
+				{
+					ID:        "message-456",
+					ClientID:  "some-client",
+					Status:    "delivered",
+					CreatedAt: time.Date(2015, 3, 20, 11, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2015, 3, 20, 11, 5, 0, 0, time.UTC),
+				},
+			}
+
+			stream("/messages?client_id=some-client&since=2015-03-20T00:00:00Z&page=3&per_page=0")
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+
+			lines := strings.Split(strings.TrimSuffix(writer.Body.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(MatchJSON(`{
+				"id": "message-123",
+				"client_id": "some-client",
+				"status": "undeliverable",
+				"reason": "invalid_address",
+				"created_at": "2015-03-20T12:00:00Z",
+				"updated_at": "2015-03-20T12:05:00Z"
+			}`))
+			Expect(lines[1]).To(MatchJSON(`{
+				"id": "message-456",
+				"client_id": "some-client",
+				"status": "delivered",
+				"created_at": "2015-03-20T11:00:00Z",
+				"updated_at": "2015-03-20T11:05:00Z"
+			}`))
+
+			Expect(messageLister.EachCall.Receives.Database).To(Equal(database))
+			Expect(messageLister.EachCall.Receives.Filter).To(Equal(models.MessageFilter{
+				ClientID: "some-client",
+				Since:    time.Date(2015, 3, 20, 0, 0, 0, 0, time.UTC),
+				ZoneID:   "uaa",
+			}))
+			Expect(messageLister.ListCall.Receives.Database).To(BeNil())
+		})
+
+		It("responds with an empty body when nothing matches", func() {
+			stream("/messages")
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+			Expect(writer.Body.String()).To(BeEmpty())
+		})
+
+		It("still validates the filter", func() {
+			stream("/messages?since=yesterday")
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ValidationError{Err: errors.New(`"since" must be an RFC 3339 timestamp, such as "2015-03-20T12:00:00Z"`)}))
+			Expect(messageLister.EachCall.Receives.Database).To(BeNil())
+		})
+
+		It("delegates errors to the error writer before anything is streamed", func() {
+			messageLister.EachCall.Returns.Error = errors.New("database is down")
+
+			stream("/messages")
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+		})
+
+		It("ends the stream early on errors after lines have been sent", func() {
+			messageLister.EachCall.Returns.Messages = []services.Message{{ID: "message-123"}}
+			messageLister.EachCall.Returns.Error = errors.New("database is down")
+
+			stream("/messages")
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(ContainSubstring("message-123"))
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+		})
+	})
+})
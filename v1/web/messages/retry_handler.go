@@ -0,0 +1,44 @@
+package messages
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type messageRetrier interface {
+	Retry(services.DatabaseInterface, string) error
+}
+
+type RetryHandler struct {
+	retrier     messageRetrier
+	errorWriter errorWriter
+}
+
+func NewRetryHandler(retrier messageRetrier, errWriter errorWriter) RetryHandler {
+	return RetryHandler{
+		retrier:     retrier,
+		errorWriter: errWriter,
+	}
+}
+
+func (h RetryHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	messageID := strings.TrimSuffix(strings.Split(req.URL.Path, "/messages/")[1], "/retry")
+
+	err := h.retrier.Retry(context.Get("database").(DatabaseInterface), messageID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	var document struct {
+		Status string `json:"status"`
+	}
+	document.Status = common.StatusQueued
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
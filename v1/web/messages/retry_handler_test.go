@@ -0,0 +1,59 @@
+package messages_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RetryHandler", func() {
+	var (
+		handler     messages.RetryHandler
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		retrier     *mocks.MessageRetrier
+		database    *mocks.Database
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		errorWriter = mocks.NewErrorWriter()
+		retrier = mocks.NewMessageRetrier()
+		writer = httptest.NewRecorder()
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		var err error
+		request, err = http.NewRequest("POST", "/messages/message-123/retry", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler = messages.NewRetryHandler(retrier, errorWriter)
+	})
+
+	It("retries the message and reports it as queued", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.Bytes()).To(MatchJSON(`{"status": "queued"}`))
+		Expect(retrier.RetryCall.Receives.Database).To(Equal(database))
+		Expect(retrier.RetryCall.Receives.MessageID).To(Equal("message-123"))
+	})
+
+	Context("when the message cannot be retried", func() {
+		It("delegates to the error writer", func() {
+			retrier.RetryCall.Returns.Error = errors.New("no retries left")
+
+			handler.ServeHTTP(writer, request, context)
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("no retries left"))
+		})
+	})
+})
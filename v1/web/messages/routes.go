@@ -10,12 +10,37 @@ type Routes struct {
 	RequestCounter                               stack.Middleware
 	RequestLogging                               stack.Middleware
 	NotificationsWriteOrEmailsWriteAuthenticator stack.Middleware
+	NotificationsManageAuthenticator             stack.Middleware
 	DatabaseAllocator                            stack.Middleware
 
-	MessageFinder messageFinder
-	ErrorWriter   errorWriter
+	// DefaultZoneManageAuthenticator guards the delivery reports, which may
+	// concern the messages of any zone, so that only the default zone may
+	// submit them.
+	DefaultZoneManageAuthenticator stack.Middleware
+
+	MessageFinder   messageFinder
+	MessageLister   messageLister
+	MessageCanceler messageCanceler
+	MessageRetrier  messageRetrier
+	DSNRecorder     dsnRecorder
+	ErrorWriter     errorWriter
+
+	// EngagementRecorder is only set when delivered mail carries tracking
+	// links.
+	EngagementRecorder engagementRecorder
 }
 
 func (r Routes) Register(m muxer) {
-	m.Handle("GET", "/messages/{message_id}", NewGetHandler(r.MessageFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsWriteOrEmailsWriteAuthenticator, r.DatabaseAllocator)
+	zoneGuard := NewZoneGuard(r.MessageFinder, r.ErrorWriter)
+
+	m.Handle("GET", "/messages", NewListHandler(r.MessageLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/messages", NewSearchHandler(r.MessageLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/messages/{message_id}", NewGetHandler(r.MessageFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsWriteOrEmailsWriteAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("DELETE", "/messages/{message_id}", NewCancelHandler(r.MessageCanceler, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("POST", "/messages/dsn", NewDSNHandler(r.DSNRecorder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.DefaultZoneManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/messages/{message_id}/retry", NewRetryHandler(r.MessageRetrier, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator, zoneGuard)
+
+	if r.EngagementRecorder != nil {
+		m.Handle("GET", "/t/{token}", NewTrackHandler(r.EngagementRecorder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.DatabaseAllocator)
+	}
 }
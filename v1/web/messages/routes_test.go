@@ -24,21 +24,115 @@ var _ = Describe("Routes", func() {
 			RequestLogging:    middleware.RequestLogging{},
 			DatabaseAllocator: middleware.DatabaseAllocator{},
 			NotificationsWriteOrEmailsWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.write", "emails.write"}},
+			NotificationsManageAuthenticator:             middleware.Authenticator{Scopes: []string{"notifications.manage"}},
+			DefaultZoneManageAuthenticator:               middleware.Authenticator{Scopes: []string{"notifications.manage"}, DefaultZoneOnly: true},
 
-			ErrorWriter:   mocks.NewErrorWriter(),
-			MessageFinder: mocks.NewMessageFinder(),
+			ErrorWriter:     mocks.NewErrorWriter(),
+			MessageFinder:   mocks.NewMessageFinder(),
+			MessageLister:   mocks.NewMessageLister(),
+			MessageCanceler: mocks.NewMessageCanceler(),
+			MessageRetrier:  mocks.NewMessageRetrier(),
+			DSNRecorder:     mocks.NewDSNRecorder(),
 		}.Register(muxer)
 	})
 
+	It("routes GET /messages", func() {
+		request, err := http.NewRequest("GET", "/messages?status=failed", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(messages.ListHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(ConsistOf([]string{"notifications.manage"}))
+	})
+
+	It("routes GET /admin/messages", func() {
+		request, err := http.NewRequest("GET", "/admin/messages?recipient=user-123", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(messages.SearchHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(ConsistOf([]string{"notifications.manage"}))
+	})
+
 	It("routes GET /messages/{message_id}", func() {
 		request, err := http.NewRequest("GET", "/messages/some-message-id", nil)
 		Expect(err).NotTo(HaveOccurred())
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(messages.GetHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, messages.ZoneGuard{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(ConsistOf([]string{"notifications.write", "emails.write"}))
 	})
+
+	It("routes DELETE /messages/{message_id}", func() {
+		request, err := http.NewRequest("DELETE", "/messages/some-message-id", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(messages.CancelHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, messages.ZoneGuard{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(ConsistOf([]string{"notifications.manage"}))
+	})
+
+	It("routes POST /messages/{message_id}/retry", func() {
+		request, err := http.NewRequest("POST", "/messages/some-message-id/retry", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(messages.RetryHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, messages.ZoneGuard{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(ConsistOf([]string{"notifications.manage"}))
+	})
+
+	It("routes POST /messages/dsn", func() {
+		request, err := http.NewRequest("POST", "/messages/dsn", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(messages.DSNHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(ConsistOf([]string{"notifications.manage"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	Describe("/t/{token}", func() {
+		It("is not routed when engagement tracking is disabled", func() {
+			request, err := http.NewRequest("GET", "/t/some-token", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(muxer.Match(request)).NotTo(BeAssignableToTypeOf(stack.Stack{}))
+		})
+
+		It("routes GET /t/{token} without authentication", func() {
+			muxer = web.NewMuxer()
+			messages.Routes{
+				RequestCounter:     middleware.RequestCounter{},
+				RequestLogging:     middleware.RequestLogging{},
+				DatabaseAllocator:  middleware.DatabaseAllocator{},
+				ErrorWriter:        mocks.NewErrorWriter(),
+				EngagementRecorder: mocks.NewEngagementRecorder(),
+			}.Register(muxer)
+
+			request, err := http.NewRequest("GET", "/t/some-token", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(messages.TrackHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.DatabaseAllocator{})
+		})
+	})
 })
@@ -0,0 +1,47 @@
+package messages
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+// SearchHandler lets operators look up the messages sent to a recipient or
+// by a client. Every search must be narrowed by at least one indexed field,
+// so it never scans the whole table.
+type SearchHandler struct {
+	ListHandler
+}
+
+func NewSearchHandler(lister messageLister, errWriter errorWriter) SearchHandler {
+	return SearchHandler{
+		ListHandler: NewListHandler(lister, errWriter),
+	}
+}
+
+func (h SearchHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	query := req.URL.Query()
+
+	filter := models.MessageFilter{
+		Recipient: query.Get("recipient"),
+		ClientID:  query.Get("client"),
+		Status:    query.Get("status"),
+	}
+
+	var err error
+	filter.Since, err = sinceParam(query.Get("since"))
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	if filter == (models.MessageFilter{}) {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`at least one of "recipient", "client", "status" or "since" is required`)})
+		return
+	}
+
+	h.list(w, req, context, filter)
+}
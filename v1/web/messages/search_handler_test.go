@@ -0,0 +1,116 @@
+package messages_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SearchHandler", func() {
+	var (
+		handler       messages.SearchHandler
+		errorWriter   *mocks.ErrorWriter
+		writer        *httptest.ResponseRecorder
+		messageLister *mocks.MessageLister
+		database      *mocks.Database
+		context       stack.Context
+	)
+
+	BeforeEach(func() {
+		errorWriter = mocks.NewErrorWriter()
+		messageLister = mocks.NewMessageLister()
+		writer = httptest.NewRecorder()
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		handler = messages.NewSearchHandler(messageLister, errorWriter)
+	})
+
+	serve := func(url string) {
+		request, err := http.NewRequest("GET", url, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("returns the messages sent to the recipient", func() {
+		messageLister.ListCall.Returns.MessageList = services.MessageList{
+			Messages: []services.Message{
+				{
+					ID:        "message-123",
+					ClientID:  "some-client",
+					Recipient: "user@example.com",
+					Status:    "failed",
+					Reason:    "mailbox full",
+					CreatedAt: time.Date(2015, 3, 20, 12, 0, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2015, 3, 20, 12, 5, 0, 0, time.UTC),
+				},
+			},
+			Total: 1,
+		}
+
+		serve("/admin/messages?recipient=user@example.com&client=some-client&status=failed&since=2015-03-20T00:00:00Z")
+
+		Expect(messageLister.ListCall.Receives.Database).To(Equal(database))
+		Expect(messageLister.ListCall.Receives.Filter).To(Equal(models.MessageFilter{
+			Recipient: "user@example.com",
+			ClientID:  "some-client",
+			Status:    "failed",
+			Since:     time.Date(2015, 3, 20, 0, 0, 0, 0, time.UTC),
+			ZoneID:    "uaa",
+		}))
+		Expect(messageLister.ListCall.Receives.Page).To(Equal(1))
+		Expect(messageLister.ListCall.Receives.PerPage).To(Equal(messages.DefaultPerPage))
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"messages": [
+				{
+					"id": "message-123",
+					"client_id": "some-client",
+					"recipient": "user@example.com",
+					"status": "failed",
+					"reason": "mailbox full",
+					"created_at": "2015-03-20T12:00:00Z",
+					"updated_at": "2015-03-20T12:05:00Z"
+				}
+			],
+			"total": 1,
+			"page": 1,
+			"per_page": 50
+		}`))
+	})
+
+	It("requires the search to be narrowed", func() {
+		serve("/admin/messages?page=2")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		Expect(messageLister.ListCall.Receives.Database).To(BeNil())
+	})
+
+	It("rejects a since that is not a timestamp", func() {
+		serve("/admin/messages?recipient=user-123&since=yesterday")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+	})
+
+	It("writes errors from the lister", func() {
+		messageLister.ListCall.Returns.Error = errors.New("boom")
+
+		serve("/admin/messages?client=some-client")
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("boom"))
+	})
+})
@@ -0,0 +1,68 @@
+package messages
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+)
+
+// trackingPixel is a transparent 1x1 GIF.
+var trackingPixel = []byte{
+	0x47, 0x49, 0x46, 0x38, 0x39, 0x61, 0x01, 0x00, 0x01, 0x00, 0x80, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0xff, 0xff, 0xff, 0x21, 0xf9, 0x04, 0x01, 0x00, 0x00, 0x00, 0x00, 0x2c, 0x00, 0x00, 0x00, 0x00,
+	0x01, 0x00, 0x01, 0x00, 0x00, 0x02, 0x02, 0x44, 0x01, 0x00, 0x3b,
+}
+
+type engagementRecorder interface {
+	Record(conn services.ConnectionInterface, token string) (common.TrackingEvent, error)
+}
+
+// TrackHandler serves the tracking links of delivered mail: the pixel that
+// records an open, and the redirect that records a click. The signed token
+// in the path is the only credential, and an event that cannot be counted is
+// still served, since the reader is not to blame.
+type TrackHandler struct {
+	recorder    engagementRecorder
+	errorWriter errorWriter
+}
+
+func NewTrackHandler(recorder engagementRecorder, errWriter errorWriter) TrackHandler {
+	return TrackHandler{
+		recorder:    recorder,
+		errorWriter: errWriter,
+	}
+}
+
+func (h TrackHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	token := regexp.MustCompile(".*/t/(.*)").FindStringSubmatch(req.URL.Path)[1]
+
+	event, err := h.recorder.Record(connection, token)
+	if err != nil {
+		if event.Event == "" {
+			h.errorWriter.Write(w, err)
+			return
+		}
+
+		context.Get("logger").(lager.Logger).Error("engagement-record-failed", err, lager.Data{
+			"message_id": event.MessageID,
+		})
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+
+	if event.Event == models.EngagementClick {
+		http.Redirect(w, req, event.Target, http.StatusFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "image/gif")
+	w.WriteHeader(http.StatusOK)
+	w.Write(trackingPixel)
+}
@@ -0,0 +1,106 @@
+package messages_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("TrackHandler", func() {
+	var (
+		handler     messages.TrackHandler
+		recorder    *mocks.EngagementRecorder
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		buffer      *bytes.Buffer
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("logger", logger)
+
+		var err error
+		request, err = http.NewRequest("GET", "/t/some-tracking-id=.some-signature", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		recorder = mocks.NewEngagementRecorder()
+		recorder.RecordCall.Returns.Event = common.TrackingEvent{Event: models.EngagementOpen, MessageID: "message-123"}
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = messages.NewTrackHandler(recorder, errorWriter)
+	})
+
+	It("records an open and serves the pixel", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("image/gif"))
+		Expect(writer.Header().Get("Cache-Control")).To(Equal("no-store"))
+		Expect(writer.Body.String()).To(HavePrefix("GIF89a"))
+
+		Expect(recorder.RecordCall.Receives.Connection).To(Equal(connection))
+		Expect(recorder.RecordCall.Receives.Token).To(Equal("some-tracking-id=.some-signature"))
+	})
+
+	It("records a click and redirects to its target", func() {
+		recorder.RecordCall.Returns.Event = common.TrackingEvent{
+			Event:     models.EngagementClick,
+			MessageID: "message-123",
+			Target:    "https://example.com/feeding",
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusFound))
+		Expect(writer.Header().Get("Location")).To(Equal("https://example.com/feeding"))
+	})
+
+	It("still redirects when the click cannot be counted", func() {
+		recorder.RecordCall.Returns.Event = common.TrackingEvent{
+			Event:     models.EngagementClick,
+			MessageID: "message-123",
+			Target:    "https://example.com/feeding",
+		}
+		recorder.RecordCall.Returns.Error = errors.New("database is down")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusFound))
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+		Expect(buffer).To(ContainSubstring("engagement-record-failed"))
+	})
+
+	It("delegates invalid links to the error writer", func() {
+		recorder.RecordCall.Returns.Event = common.TrackingEvent{}
+		recorder.RecordCall.Returns.Error = services.TrackingLinkError{Err: errors.New("The tracking link is invalid")}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(errorWriter.WriteCall.Receives.Error).To(Equal(services.TrackingLinkError{Err: errors.New("The tracking link is invalid")}))
+	})
+})
@@ -0,0 +1,53 @@
+package messages
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var messageIDPath = regexp.MustCompile(`^/messages/([^/]+)`)
+
+// ZoneGuard answers as if the message in the path did not exist when it was
+// sent by a client of another UAA zone than the token.
+type ZoneGuard struct {
+	finder      messageFinder
+	errorWriter errorWriter
+}
+
+func NewZoneGuard(finder messageFinder, errWriter errorWriter) ZoneGuard {
+	return ZoneGuard{
+		finder:      finder,
+		errorWriter: errWriter,
+	}
+}
+
+func (g ZoneGuard) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
+	zoneID := webutil.ZoneID(context)
+	if zoneID == models.DefaultZoneID {
+		return true
+	}
+
+	matches := messageIDPath.FindStringSubmatch(req.URL.Path)
+	if matches == nil {
+		return true
+	}
+	messageID := matches[1]
+
+	message, err := g.finder.Find(context.Get("database").(DatabaseInterface), messageID)
+	if err != nil {
+		// The handler reports the error as it would have without the guard.
+		return true
+	}
+
+	if !models.ClientInZone(zoneID, message.ClientID) {
+		g.errorWriter.Write(w, models.NotFoundError{Err: fmt.Errorf("Message with ID %q could not be found", messageID), Code: "message_not_found"})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,76 @@
+package messages_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ZoneGuard", func() {
+	var (
+		guard       messages.ZoneGuard
+		finder      *mocks.MessageFinder
+		errorWriter *mocks.ErrorWriter
+		database    *mocks.Database
+		context     stack.Context
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		finder = mocks.NewMessageFinder()
+		errorWriter = mocks.NewErrorWriter()
+		database = mocks.NewDatabase()
+		writer = httptest.NewRecorder()
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set(webutil.ZoneIDKey, "tenant-a")
+
+		var err error
+		request, err = http.NewRequest("POST", "/messages/message-123/retry", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		guard = messages.NewZoneGuard(finder, errorWriter)
+	})
+
+	It("lets through the messages of the clients of the zone of the token", func() {
+		finder.FindCall.Returns.Message = services.Message{ID: "message-123", ClientID: "tenant-a:some-client"}
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(finder.FindCall.Receives.Database).To(Equal(database))
+		Expect(finder.FindCall.Receives.MessageID).To(Equal("message-123"))
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+	})
+
+	It("reports the messages of other zones as not found", func() {
+		finder.FindCall.Returns.Message = services.Message{ID: "message-123", ClientID: "some-client"}
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeFalse())
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+
+	It("lets every message through to the default zone without finding it", func() {
+		context.Set(webutil.ZoneIDKey, "uaa")
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(finder.FindCall.Receives.MessageID).To(BeEmpty())
+	})
+
+	It("leaves the errors of finding the message to the handler", func() {
+		finder.FindCall.Returns.Error = errors.New("boom")
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+	})
+})
@@ -0,0 +1,187 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/pivotal-golang/lager"
+	"github.com/rcrowley/go-metrics"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	// anomalyBaselineDecay weighs each past minute in the baseline, giving
+	// it a memory of roughly an hour.
+	anomalyBaselineDecay = 59.0 / 60.0
+
+	// anomalyLearningMinutes is how much history a client needs before its
+	// baseline is trusted.
+	anomalyLearningMinutes = 10
+)
+
+type clientSuspensionsRepo interface {
+	Find(conn models.ConnectionInterface, clientID string) (models.ClientSuspension, error)
+	Create(conn models.ConnectionInterface, suspension models.ClientSuspension) (models.ClientSuspension, error)
+}
+
+type sendingHistory struct {
+	minute  time.Time
+	count   int
+	flagged bool
+
+	// weightedSum and weight form an exponentially weighted average of the
+	// requests per minute, corrected for the short history of new clients.
+	weightedSum float64
+	weight      float64
+	minutes     int
+}
+
+func (h *sendingHistory) baseline() float64 {
+	if h.weight == 0 {
+		return 0
+	}
+
+	return h.weightedSum / h.weight
+}
+
+// advance closes the minutes that have passed since the last request. A
+// minute that was flagged as anomalous is left out of the baseline so that
+// a spike does not raise the bar for the next one.
+func (h *sendingHistory) advance(now time.Time) {
+	elapsed := int(now.Sub(h.minute) / time.Minute)
+	if elapsed <= 0 {
+		return
+	}
+
+	if !h.flagged {
+		h.weightedSum = h.weightedSum*anomalyBaselineDecay + float64(h.count)*(1-anomalyBaselineDecay)
+		h.weight = h.weight*anomalyBaselineDecay + (1 - anomalyBaselineDecay)
+	}
+
+	idle := math.Pow(anomalyBaselineDecay, float64(elapsed-1))
+	h.weightedSum *= idle
+	h.weight = h.weight*idle + (1 - idle)
+
+	h.minute = h.minute.Add(time.Duration(elapsed) * time.Minute)
+	h.minutes += elapsed
+	h.count = 0
+	h.flagged = false
+}
+
+// AnomalyDetector flags clients whose requests in the current minute rise
+// far above their own baseline, as happens when leaked client credentials
+// are used to send spam. Every flagged spike is logged and counted. When
+// reauthorization is required the client is also suspended until an admin
+// lifts the suspension. Like the RateLimiter, each instance of the API keeps
+// its own history.
+type AnomalyDetector struct {
+	factor                 int
+	minRequests            int
+	requireReauthorization bool
+	suspensions            clientSuspensionsRepo
+	clock                  clock
+	mutex                  *sync.Mutex
+	histories              map[string]*sendingHistory
+}
+
+func NewAnomalyDetector(factor, minRequests int, requireReauthorization bool, suspensions clientSuspensionsRepo, clock clock) AnomalyDetector {
+	return AnomalyDetector{
+		factor:                 factor,
+		minRequests:            minRequests,
+		requireReauthorization: requireReauthorization,
+		suspensions:            suspensions,
+		clock:                  clock,
+		mutex:                  &sync.Mutex{},
+		histories:              map[string]*sendingHistory{},
+	}
+}
+
+func (ware AnomalyDetector) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
+	if ware.factor <= 0 {
+		return true
+	}
+
+	clientID, _ := context.Get("client_id").(string)
+	logger := context.Get("logger").(lager.Logger)
+
+	var connection models.ConnectionInterface
+	if ware.requireReauthorization {
+		connection = context.Get("database").(models.DatabaseInterface).Connection()
+
+		_, err := ware.suspensions.Find(connection, clientID)
+		if err == nil {
+			return ware.suspended(w)
+		}
+
+		if _, ok := err.(models.NotFoundError); !ok {
+			logger.Error("client-suspension-lookup-failed", err)
+		}
+	}
+
+	count, baseline, anomalous := ware.record(clientID)
+	if !anomalous {
+		return true
+	}
+
+	metrics.GetOrRegisterCounter("notifications.web.sending_anomaly", nil).Inc(1)
+
+	reason := fmt.Sprintf("%d requests in one minute against a baseline of %.1f per minute", count, baseline)
+	logger.Error("sending-anomaly-detected", errors.New(reason), lager.Data{
+		"client_id":              clientID,
+		"requests_per_minute":    count,
+		"baseline_per_minute":    baseline,
+		"requires_reauthorizing": ware.requireReauthorization,
+	})
+
+	if !ware.requireReauthorization {
+		return true
+	}
+
+	_, err := ware.suspensions.Create(connection, models.ClientSuspension{
+		ClientID: clientID,
+		Reason:   reason,
+	})
+	if err != nil {
+		logger.Error("client-suspension-failed", err)
+	}
+
+	return ware.suspended(w)
+}
+
+func (ware AnomalyDetector) record(clientID string) (int, float64, bool) {
+	ware.mutex.Lock()
+	defer ware.mutex.Unlock()
+
+	now := ware.clock.Now()
+	history, ok := ware.histories[clientID]
+	if !ok {
+		history = &sendingHistory{minute: now.Truncate(time.Minute)}
+		ware.histories[clientID] = history
+	}
+
+	history.advance(now)
+	history.count++
+
+	baseline := history.baseline()
+	if history.flagged || history.minutes < anomalyLearningMinutes || history.count < ware.minRequests {
+		return history.count, baseline, false
+	}
+
+	if float64(history.count) <= float64(ware.factor)*math.Max(baseline, 1) {
+		return history.count, baseline, false
+	}
+
+	history.flagged = true
+	return history.count, baseline, true
+}
+
+func (ware AnomalyDetector) suspended(w http.ResponseWriter) bool {
+	webutil.WriteError(w, http.StatusForbidden, "client_suspended", "Client is suspended after unusual sending activity and must be reauthorized by an admin")
+	return false
+}
@@ -0,0 +1,176 @@
+package middleware_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/pivotal-golang/lager"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AnomalyDetector", func() {
+	var (
+		ware        middleware.AnomalyDetector
+		clock       *mocks.Clock
+		suspensions *mocks.ClientSuspensionsRepo
+		conn        *mocks.Connection
+		request     *http.Request
+		context     stack.Context
+		buffer      *bytes.Buffer
+		start       time.Time
+	)
+
+	// sendSteadily makes perMinute requests in each of the given number of
+	// minutes, leaving the clock at the start of the following minute.
+	sendSteadily := func(minutes, perMinute int) {
+		for minute := 0; minute < minutes; minute++ {
+			clock.NowCall.Returns.Time = start.Add(time.Duration(minute) * time.Minute)
+			for i := 0; i < perMinute; i++ {
+				Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+			}
+		}
+		clock.NowCall.Returns.Time = start.Add(time.Duration(minutes) * time.Minute)
+	}
+
+	BeforeEach(func() {
+		var err error
+		request, err = http.NewRequest("POST", "/users/some-user", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		start = time.Date(2026, 10, 17, 12, 0, 0, 0, time.UTC)
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = start
+
+		buffer = bytes.NewBuffer([]byte{})
+		logger := lager.NewLogger("notifications")
+		logger.RegisterSink(lager.NewWriterSink(buffer, lager.DEBUG))
+
+		conn = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = conn
+
+		context = stack.NewContext()
+		context.Set("client_id", "compromised-client")
+		context.Set("logger", logger)
+		context.Set("database", database)
+
+		suspensions = mocks.NewClientSuspensionsRepo()
+		suspensions.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not suspended")}
+
+		ware = middleware.NewAnomalyDetector(5, 20, false, suspensions, clock)
+	})
+
+	It("does nothing when no factor is configured", func() {
+		ware = middleware.NewAnomalyDetector(0, 20, true, suspensions, clock)
+
+		sendSteadily(15, 100)
+
+		Expect(suspensions.FindCall.CallCount).To(Equal(0))
+		Expect(buffer.String()).NotTo(ContainSubstring("sending-anomaly-detected"))
+	})
+
+	It("allows steady sending", func() {
+		sendSteadily(30, 10)
+
+		Expect(buffer.String()).NotTo(ContainSubstring("sending-anomaly-detected"))
+	})
+
+	It("alerts once when a minute rises above the baseline by the factor", func() {
+		sendSteadily(15, 10)
+
+		for i := 0; i < 50; i++ {
+			Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		}
+		Expect(buffer.String()).NotTo(ContainSubstring("sending-anomaly-detected"))
+
+		for i := 0; i < 10; i++ {
+			Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		}
+
+		Expect(bytes.Count(buffer.Bytes(), []byte("sending-anomaly-detected"))).To(Equal(1))
+		Expect(buffer.String()).To(ContainSubstring(`"client_id":"compromised-client"`))
+		Expect(buffer.String()).To(ContainSubstring(`"requests_per_minute":51`))
+		Expect(suspensions.CreateCall.WasCalled).To(BeFalse())
+	})
+
+	It("does not alert while it is still learning the baseline of a client", func() {
+		sendSteadily(1, 10)
+
+		for i := 0; i < 500; i++ {
+			Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		}
+
+		Expect(buffer.String()).NotTo(ContainSubstring("sending-anomaly-detected"))
+	})
+
+	It("ignores spikes below the minimum number of requests", func() {
+		sendSteadily(15, 1)
+
+		for i := 0; i < 19; i++ {
+			Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		}
+
+		Expect(buffer.String()).NotTo(ContainSubstring("sending-anomaly-detected"))
+	})
+
+	It("tracks each client separately", func() {
+		sendSteadily(15, 10)
+
+		otherContext := stack.NewContext()
+		otherContext.Set("client_id", "new-client")
+		otherContext.Set("logger", context.Get("logger"))
+		for i := 0; i < 100; i++ {
+			Expect(ware.ServeHTTP(httptest.NewRecorder(), request, otherContext)).To(BeTrue())
+		}
+
+		Expect(buffer.String()).NotTo(ContainSubstring("sending-anomaly-detected"))
+	})
+
+	Context("when reauthorization is required", func() {
+		BeforeEach(func() {
+			ware = middleware.NewAnomalyDetector(5, 20, true, suspensions, clock)
+		})
+
+		It("suspends the client when a spike is detected", func() {
+			sendSteadily(15, 10)
+
+			for i := 0; i < 50; i++ {
+				Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+			}
+
+			writer := httptest.NewRecorder()
+			Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+			Expect(writer.Code).To(Equal(http.StatusForbidden))
+			Expect(writer.Body).To(MatchJSON(`{"errors":[{"code":"client_suspended","detail":"Client is suspended after unusual sending activity and must be reauthorized by an admin"}]}`))
+
+			Expect(suspensions.CreateCall.Receives.Connection).To(Equal(conn))
+			Expect(suspensions.CreateCall.Receives.Suspension.ClientID).To(Equal("compromised-client"))
+			Expect(suspensions.CreateCall.Receives.Suspension.Reason).To(Equal("51 requests in one minute against a baseline of 10.0 per minute"))
+		})
+
+		It("rejects requests from a suspended client", func() {
+			suspensions.FindCall.Returns.Error = nil
+
+			writer := httptest.NewRecorder()
+			Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+			Expect(writer.Code).To(Equal(http.StatusForbidden))
+			Expect(suspensions.FindCall.Receives.ClientID).To(Equal("compromised-client"))
+		})
+
+		It("lets requests through when suspensions cannot be looked up", func() {
+			suspensions.FindCall.Returns.Error = errors.New("database is down")
+
+			Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+			Expect(buffer.String()).To(ContainSubstring("client-suspension-lookup-failed"))
+		})
+	})
+})
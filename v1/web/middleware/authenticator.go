@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 )
@@ -15,6 +17,10 @@ type validator interface {
 type Authenticator struct {
 	Scopes    []string
 	Validator validator
+
+	// DefaultZoneOnly refuses tokens from any UAA zone but the default
+	// one, for routes that act on the whole deployment.
+	DefaultZoneOnly bool
 }
 
 func NewAuthenticator(validator validator, scopes ...string) Authenticator {
@@ -28,21 +34,37 @@ func (ware Authenticator) ServeHTTP(w http.ResponseWriter, req *http.Request, co
 	rawToken := ware.getToken(req)
 
 	if rawToken == "" {
-		return ware.Error(w, http.StatusUnauthorized, "Authorization header is invalid: missing")
+		return ware.Error(w, http.StatusUnauthorized, "invalid_token", "Authorization header is invalid: missing")
 	}
 
 	token, err := ware.Validator.Parse(rawToken)
 
 	if err != nil {
-		return ware.Error(w, http.StatusUnauthorized, "Authorization header is invalid: "+err.Error())
+		return ware.Error(w, http.StatusUnauthorized, "invalid_token", "Authorization header is invalid: "+err.Error())
 	}
 
 	if !ware.containsATokenScope(w, token) {
 		return false
 	}
 
+	zoneID, _ := token.Claims["zid"].(string)
+	if zoneID == "" {
+		zoneID = models.DefaultZoneID
+	}
+
+	if ware.DefaultZoneOnly && zoneID != models.DefaultZoneID {
+		return ware.Error(w, http.StatusForbidden, "insufficient_scope", "You are not authorized to perform the requested action outside of the default zone")
+	}
+
+	// The client ID is qualified by the zone of the token, so that everything
+	// kept by client ID is kept apart per zone.
+	if clientID, ok := token.Claims["client_id"].(string); ok {
+		token.Claims["client_id"] = models.ZonedClientID(zoneID, clientID)
+	}
+
 	context.Set("token", token)
 	context.Set("client_id", token.Claims["client_id"])
+	context.Set(webutil.ZoneIDKey, zoneID)
 
 	return true
 }
@@ -56,7 +78,7 @@ func (ware Authenticator) containsATokenScope(w http.ResponseWriter, token *jwt.
 		}
 	}
 
-	return ware.Error(w, http.StatusForbidden, "You are not authorized to perform the requested action")
+	return ware.Error(w, http.StatusForbidden, "insufficient_scope", "You are not authorized to perform the requested action")
 }
 
 func contains(elements interface{}, key string) bool {
@@ -68,9 +90,8 @@ func contains(elements interface{}, key string) bool {
 	return false
 }
 
-func (ware Authenticator) Error(w http.ResponseWriter, code int, message string) bool {
-	w.WriteHeader(code)
-	w.Write([]byte(`{"errors":["` + message + `"]}`))
+func (ware Authenticator) Error(w http.ResponseWriter, status int, code, message string) bool {
+	webutil.WriteError(w, status, code, message)
 	return false
 }
 
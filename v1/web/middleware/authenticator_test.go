@@ -9,6 +9,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
@@ -94,6 +95,36 @@ var _ = Describe("Authenticator", func() {
 			Expect(context.Get("client_id")).To(Equal("mister-client"))
 		})
 
+		It("sets the default zone on the context when the token has none", func() {
+			ware.ServeHTTP(writer, request, context)
+
+			Expect(context.Get(webutil.ZoneIDKey)).To(Equal("uaa"))
+		})
+
+		Context("when the token is from another zone", func() {
+			BeforeEach(func() {
+				expectedToken.Claims["zid"] = "tenant-a"
+			})
+
+			It("qualifies the client ID by the zone", func() {
+				ware.ServeHTTP(writer, request, context)
+
+				Expect(context.Get(webutil.ZoneIDKey)).To(Equal("tenant-a"))
+				Expect(context.Get("client_id")).To(Equal("tenant-a:mister-client"))
+				Expect(context.Get("token").(*jwt.Token).Claims["client_id"]).To(Equal("tenant-a:mister-client"))
+			})
+
+			It("refuses the request when the route is for the default zone only", func() {
+				ware.DefaultZoneOnly = true
+
+				returnValue := ware.ServeHTTP(writer, request, context)
+
+				Expect(returnValue).To(BeFalse())
+				Expect(writer.Code).To(Equal(http.StatusForbidden))
+				Expect(writer.Body.String()).To(ContainSubstring("outside of the default zone"))
+			})
+		})
+
 		Context("when the prefix to the token has different capitalization", func() {
 			It("still sets the token", func() {
 				request.Header.Set("Authorization", "bearer some-token")
@@ -127,13 +158,13 @@ var _ = Describe("Authenticator", func() {
 			Expect(returnValue).To(BeFalse())
 			Expect(writer.Code).To(Equal(http.StatusUnauthorized))
 
-			parsed := map[string][]string{}
+			parsed := map[string][]webutil.ErrorDetail{}
 			err := json.Unmarshal(writer.Body.Bytes(), &parsed)
 			if err != nil {
 				panic(err)
 			}
 
-			Expect(parsed["errors"]).To(ContainElement("Authorization header is invalid: missing"))
+			Expect(parsed["errors"]).To(ConsistOf(webutil.ErrorDetail{Code: "invalid_token", Detail: "Authorization header is invalid: missing"}))
 		})
 	})
 
@@ -171,13 +202,13 @@ var _ = Describe("Authenticator", func() {
 			Expect(returnValue).To(BeFalse())
 			Expect(writer.Code).To(Equal(http.StatusForbidden))
 
-			parsed := map[string][]string{}
+			parsed := map[string][]webutil.ErrorDetail{}
 			err := json.Unmarshal(writer.Body.Bytes(), &parsed)
 			if err != nil {
 				panic(err)
 			}
 
-			Expect(parsed["errors"]).To(ContainElement("You are not authorized to perform the requested action"))
+			Expect(parsed["errors"]).To(ConsistOf(webutil.ErrorDetail{Code: "insufficient_scope", Detail: "You are not authorized to perform the requested action"}))
 		})
 	})
 
@@ -214,13 +245,13 @@ var _ = Describe("Authenticator", func() {
 			Expect(returnValue).To(BeFalse())
 			Expect(writer.Code).To(Equal(http.StatusForbidden))
 
-			parsed := map[string][]string{}
+			parsed := map[string][]webutil.ErrorDetail{}
 			err := json.Unmarshal(writer.Body.Bytes(), &parsed)
 			if err != nil {
 				panic(err)
 			}
 
-			Expect(parsed["errors"]).To(ContainElement("You are not authorized to perform the requested action"))
+			Expect(parsed["errors"]).To(ConsistOf(webutil.ErrorDetail{Code: "insufficient_scope", Detail: "You are not authorized to perform the requested action"}))
 		})
 	})
 
@@ -248,13 +279,14 @@ var _ = Describe("Authenticator", func() {
 			Expect(returnValue).To(BeFalse())
 			Expect(writer.Code).To(Equal(http.StatusUnauthorized))
 
-			parsed := map[string][]string{}
+			parsed := map[string][]webutil.ErrorDetail{}
 			err := json.Unmarshal(writer.Body.Bytes(), &parsed)
 			if err != nil {
 				panic(err)
 			}
 
-			Expect(parsed["errors"][0]).To(ContainSubstring("Authorization header is invalid"))
+			Expect(parsed["errors"][0].Code).To(Equal("invalid_token"))
+			Expect(parsed["errors"][0].Detail).To(ContainSubstring("Authorization header is invalid"))
 		})
 	})
 })
@@ -11,19 +11,23 @@ import (
 )
 
 type DatabaseAllocator struct {
-	DB    *sql.DB
-	Trace bool
+	DB       *sql.DB
+	Replicas []*sql.DB
+	Trace    bool
 }
 
-func NewDatabaseAllocator(sqlDB *sql.DB, trace bool) DatabaseAllocator {
+func NewDatabaseAllocator(sqlDB *sql.DB, replicas []*sql.DB, trace bool) DatabaseAllocator {
 	return DatabaseAllocator{
-		DB:    sqlDB,
-		Trace: trace,
+		DB:       sqlDB,
+		Replicas: replicas,
+		Trace:    trace,
 	}
 }
 
 func (ware DatabaseAllocator) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
-	db := models.NewDatabase(ware.DB, models.Config{})
+	db := models.NewDatabase(ware.DB, models.Config{
+		Replicas: ware.Replicas,
+	})
 
 	logger := gorpCompatibleLogger{
 		logger: context.Get("logger").(lager.Logger).WithData(lager.Data{
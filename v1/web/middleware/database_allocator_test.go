@@ -34,7 +34,7 @@ var _ = Describe("Database Allocator", func() {
 		sqlDB, _, err = sqlmock.New()
 		Expect(err).NotTo(HaveOccurred())
 
-		ware = middleware.NewDatabaseAllocator(sqlDB, true)
+		ware = middleware.NewDatabaseAllocator(sqlDB, nil, true)
 
 		writer = httptest.NewRecorder()
 		request = &http.Request{}
@@ -63,6 +63,25 @@ var _ = Describe("Database Allocator", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("allocates a database that reads from the replicas", func() {
+		replicaDB, _, err := sqlmock.New()
+		Expect(err).NotTo(HaveOccurred())
+
+		ware = middleware.NewDatabaseAllocator(sqlDB, []*sql.DB{replicaDB}, false)
+
+		result := ware.ServeHTTP(writer, request, context)
+		Expect(result).To(BeTrue())
+
+		database := context.Get("database").(*db.DB)
+		Expect(database.Connection().GetDbMap().Db).To(Equal(sqlDB))
+
+		connection := database.ReadConnection().GetDbMap()
+		Expect(connection.Db).To(Equal(replicaDB))
+
+		_, err = connection.TableFor(reflect.TypeOf(models.Client{}), false)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
 	Context("when db logging is enabled", func() {
 		It("allocates a database that logs with the VCAP request ID", func() {
 			result := ware.ServeHTTP(writer, request, context)
@@ -88,7 +107,7 @@ var _ = Describe("Database Allocator", func() {
 
 	Context("when db logging is disabled", func() {
 		It("allocates a database that does not log", func() {
-			ware = middleware.NewDatabaseAllocator(sqlDB, false)
+			ware = middleware.NewDatabaseAllocator(sqlDB, nil, false)
 
 			result := ware.ServeHTTP(writer, request, context)
 			Expect(result).To(BeTrue())
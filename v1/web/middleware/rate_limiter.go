@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/rcrowley/go-metrics"
+	"github.com/ryanmoran/stack"
+)
+
+type tokenBucket struct {
+	tokens    float64
+	updatedAt time.Time
+}
+
+// RateLimiter enforces a per-client token bucket so that a single noisy
+// client cannot starve the others. Buckets live in memory, so each instance
+// of the API enforces the limit independently.
+type RateLimiter struct {
+	rate    float64
+	burst   float64
+	clock   clock
+	mutex   *sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+func NewRateLimiter(perMinute, burst int, clock clock) RateLimiter {
+	if burst <= 0 {
+		burst = perMinute
+	}
+
+	return RateLimiter{
+		rate:    float64(perMinute) / 60,
+		burst:   float64(burst),
+		clock:   clock,
+		mutex:   &sync.Mutex{},
+		buckets: map[string]*tokenBucket{},
+	}
+}
+
+func (ware RateLimiter) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
+	if ware.rate <= 0 {
+		return true
+	}
+
+	clientID, _ := context.Get("client_id").(string)
+
+	wait, ok := ware.take(clientID)
+	if ok {
+		return true
+	}
+
+	metrics.GetOrRegisterCounter("notifications.web.rate_limited", nil).Inc(1)
+
+	retryAfter := int(math.Ceil(wait.Seconds()))
+	if retryAfter < 1 {
+		retryAfter = 1
+	}
+
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	webutil.WriteError(w, http.StatusTooManyRequests, "rate_limited", "Rate limit exceeded, please retry later")
+
+	return false
+}
+
+func (ware RateLimiter) take(clientID string) (time.Duration, bool) {
+	ware.mutex.Lock()
+	defer ware.mutex.Unlock()
+
+	now := ware.clock.Now()
+	bucket, ok := ware.buckets[clientID]
+	if !ok {
+		bucket = &tokenBucket{tokens: ware.burst, updatedAt: now}
+		ware.buckets[clientID] = bucket
+	}
+
+	elapsed := now.Sub(bucket.updatedAt).Seconds()
+	if elapsed > 0 {
+		bucket.tokens = math.Min(ware.burst, bucket.tokens+elapsed*ware.rate)
+		bucket.updatedAt = now
+	}
+
+	if bucket.tokens >= 1 {
+		bucket.tokens--
+		return 0, true
+	}
+
+	return time.Duration((1 - bucket.tokens) / ware.rate * float64(time.Second)), false
+}
@@ -0,0 +1,102 @@
+package middleware_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("RateLimiter", func() {
+	var (
+		ware    middleware.RateLimiter
+		clock   *mocks.Clock
+		request *http.Request
+		context stack.Context
+		now     time.Time
+	)
+
+	BeforeEach(func() {
+		var err error
+		request, err = http.NewRequest("POST", "/users/some-user", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		now = time.Now()
+		clock = mocks.NewClock()
+		clock.NowCall.Returns.Time = now
+
+		context = stack.NewContext()
+		context.Set("client_id", "noisy-client")
+
+		ware = middleware.NewRateLimiter(60, 2, clock)
+	})
+
+	It("allows requests until the burst is used up", func() {
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+
+		writer := httptest.NewRecorder()
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+		Expect(writer.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(writer.Header().Get("Retry-After")).To(Equal("1"))
+		Expect(writer.Body).To(MatchJSON(`{"errors":[{"code":"rate_limited","detail":"Rate limit exceeded, please retry later"}]}`))
+	})
+
+	It("refills the bucket over time", func() {
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeFalse())
+
+		clock.NowCall.Returns.Time = now.Add(time.Second)
+
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeFalse())
+	})
+
+	It("limits each client separately", func() {
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeFalse())
+
+		otherContext := stack.NewContext()
+		otherContext.Set("client_id", "quiet-client")
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, otherContext)).To(BeTrue())
+	})
+
+	It("tells the client how long to wait for the next token", func() {
+		ware = middleware.NewRateLimiter(6, 1, clock)
+
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+
+		clock.NowCall.Returns.Time = now.Add(2500 * time.Millisecond)
+
+		writer := httptest.NewRecorder()
+		Expect(ware.ServeHTTP(writer, request, context)).To(BeFalse())
+		Expect(writer.Header().Get("Retry-After")).To(Equal("8"))
+	})
+
+	It("defaults the burst to the per-minute limit", func() {
+		ware = middleware.NewRateLimiter(3, 0, clock)
+
+		for i := 0; i < 3; i++ {
+			Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+		}
+		Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeFalse())
+	})
+
+	Context("when the limit is not positive", func() {
+		It("allows every request", func() {
+			ware = middleware.NewRateLimiter(0, 0, clock)
+
+			for i := 0; i < 100; i++ {
+				Expect(ware.ServeHTTP(httptest.NewRecorder(), request, context)).To(BeTrue())
+			}
+		})
+	})
+})
@@ -4,12 +4,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/pivotal-golang/lager"
 	"github.com/ryanmoran/stack"
 )
 
 const (
-	VCAPRequestIDKey    = "vcap_request_id"
+	VCAPRequestIDKey    = webutil.VCAPRequestIDKey
 	APIVersion          = "api_version"
 	RequestReceivedTime = "request_received_time"
 )
@@ -18,23 +19,30 @@ type clock interface {
 	Now() time.Time
 }
 
+type idGenerator interface {
+	Generate() (string, error)
+}
+
 type RequestLogging struct {
-	logger lager.Logger
-	clock  clock
+	logger      lager.Logger
+	clock       clock
+	idGenerator idGenerator
 }
 
-func NewRequestLogging(logger lager.Logger, clock clock) RequestLogging {
+func NewRequestLogging(logger lager.Logger, clock clock, idGenerator idGenerator) RequestLogging {
 	return RequestLogging{
-		logger: logger,
-		clock:  clock,
+		logger:      logger,
+		clock:       clock,
+		idGenerator: idGenerator,
 	}
 }
 
 func (r RequestLogging) ServeHTTP(response http.ResponseWriter, request *http.Request, context stack.Context) bool {
-	requestID := request.Header.Get("X-Vcap-Request-Id")
+	requestID := request.Header.Get(webutil.RequestIDHeader)
 	if requestID == "" {
-		requestID = "UNKNOWN"
+		requestID = r.generateID()
 	}
+	response.Header().Set(webutil.RequestIDHeader, requestID)
 
 	logData := lager.Data{
 		VCAPRequestIDKey: requestID,
@@ -58,3 +66,15 @@ func (r RequestLogging) ServeHTTP(response http.ResponseWriter, request *http.Re
 
 	return true
 }
+
+// generateID identifies a request that reached the service without passing
+// through the router, so that its logs can still be told apart.
+func (r RequestLogging) generateID() string {
+	requestID, err := r.idGenerator.Generate()
+	if err != nil {
+		r.logger.Error("request-id-generation-failed", err)
+		return "UNKNOWN"
+	}
+
+	return requestID
+}
@@ -3,6 +3,7 @@ package middleware_test
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"time"
@@ -32,6 +33,7 @@ var _ = Describe("RequestLogging", func() {
 		logger    lager.Logger
 		logWriter *bytes.Buffer
 		clock     *mocks.Clock
+		ids       *mocks.IDGenerator
 	)
 
 	BeforeEach(func() {
@@ -50,7 +52,10 @@ var _ = Describe("RequestLogging", func() {
 		clock = mocks.NewClock()
 		clock.NowCall.Returns.Time = time.Now()
 
-		ware = middleware.NewRequestLogging(logger, clock)
+		ids = mocks.NewIDGenerator()
+		ids.GenerateCall.Returns.IDs = []string{"generated-request-id"}
+
+		ware = middleware.NewRequestLogging(logger, clock, ids)
 	})
 
 	It("logs the request without an API version", func() {
@@ -114,6 +119,13 @@ var _ = Describe("RequestLogging", func() {
 		Expect(requestID).To(Equal("some-request-id"))
 	})
 
+	It("returns the request id in the response", func() {
+		ware.ServeHTTP(writer, request, context)
+
+		Expect(writer.Header().Get("X-Vcap-Request-Id")).To(Equal("some-request-id"))
+		Expect(ids.GenerateCall.CallCount).To(Equal(0))
+	})
+
 	It("adds the current time to the context", func() {
 		now := time.Now()
 		clock.NowCall.Returns.Time = now
@@ -126,13 +138,18 @@ var _ = Describe("RequestLogging", func() {
 		Expect(requestReceivedTime).To(Equal(now.UTC()))
 	})
 
-	Context("when the request id is unknown", func() {
-		It("generates a logger with a prefix that states the request id is unknown", func() {
+	Context("when the request has no id", func() {
+		BeforeEach(func() {
 			request.Header.Del("X-Vcap-Request-Id")
+		})
 
+		It("generates one for the context, the logs and the response", func() {
 			result := ware.ServeHTTP(writer, request, context)
 			Expect(result).To(BeTrue())
 
+			Expect(context.Get(middleware.VCAPRequestIDKey)).To(Equal("generated-request-id"))
+			Expect(writer.Header().Get("X-Vcap-Request-Id")).To(Equal("generated-request-id"))
+
 			logger := context.Get("logger").(lager.Logger)
 			logger.Info("hello")
 
@@ -141,6 +158,29 @@ var _ = Describe("RequestLogging", func() {
 			var line logLine
 			err := json.Unmarshal(lines[1], &line)
 			Expect(err).NotTo(HaveOccurred())
+			Expect(line.Data).To(HaveKeyWithValue("vcap_request_id", "generated-request-id"))
+		})
+
+		It("generates a logger with a prefix that states the request id is unknown when none can be generated", func() {
+			ids.GenerateCall.Returns.Error = errors.New("no entropy")
+
+			result := ware.ServeHTTP(writer, request, context)
+			Expect(result).To(BeTrue())
+
+			Expect(writer.Header().Get("X-Vcap-Request-Id")).To(Equal("UNKNOWN"))
+
+			logger := context.Get("logger").(lager.Logger)
+			logger.Info("hello")
+
+			lines := bytes.Split(logWriter.Bytes(), []byte("\n"))
+
+			var line logLine
+			err := json.Unmarshal(lines[0], &line)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(line.Message).To(Equal("my-app.request-id-generation-failed"))
+
+			err = json.Unmarshal(lines[2], &line)
+			Expect(err).NotTo(HaveOccurred())
 			Expect(line.Source).To(Equal("my-app"))
 			Expect(line.Message).To(Equal("my-app.request.hello"))
 			Expect(line.LogLevel).To(Equal(int(lager.DEBUG)))
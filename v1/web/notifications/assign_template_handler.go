@@ -15,23 +15,30 @@ type TemplateAssignment struct {
 }
 
 type assignsTemplates interface {
-	AssignToNotification(connection collections.ConnectionInterface, clientID, notificationID, templateID string) error
+	AssignToNotification(connection collections.ConnectionInterface, zoneID, clientID, notificationID, templateID string) error
 }
 
 type AssignTemplateHandler struct {
 	templateAssigner assignsTemplates
+	auditor          registrationAuditor
 	errorWriter      errorWriter
 }
 
-func NewAssignTemplateHandler(assigner assignsTemplates, errWriter errorWriter) AssignTemplateHandler {
+func NewAssignTemplateHandler(assigner assignsTemplates, auditor registrationAuditor, errWriter errorWriter) AssignTemplateHandler {
 	return AssignTemplateHandler{
 		templateAssigner: assigner,
+		auditor:          auditor,
 		errorWriter:      errWriter,
 	}
 }
 
+func (h AssignTemplateHandler) RequestBody() interface{} {
+	return TemplateAssignment{}
+}
+
 func (h AssignTemplateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	clientID, notificationID := h.parseURL(req.URL.Path)
+	clientID = webutil.ZonedClientID(context, clientID)
 
 	var templateAssignment TemplateAssignment
 	err := json.NewDecoder(req.Body).Decode(&templateAssignment)
@@ -40,8 +47,20 @@ func (h AssignTemplateHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	database := context.Get("database").(DatabaseInterface)
-	err = h.templateAssigner.AssignToNotification(database.Connection(), clientID, notificationID, templateAssignment.Template)
+	connection := context.Get("database").(DatabaseInterface).Connection()
+	before, err := h.auditor.Snapshot(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.templateAssigner.AssignToNotification(connection, webutil.ZoneID(context), clientID, notificationID, templateAssignment.Template)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.auditor.Report(connection, before, registrationActor(context))
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -20,6 +22,7 @@ var _ = Describe("AssignTemplateHandler", func() {
 	var (
 		handler          notifications.AssignTemplateHandler
 		templateAssigner *mocks.TemplateAssigner
+		auditor          *mocks.RegistrationAuditor
 		errorWriter      *mocks.ErrorWriter
 		context          stack.Context
 		database         *mocks.Database
@@ -34,8 +37,11 @@ var _ = Describe("AssignTemplateHandler", func() {
 		database.ConnectionCall.Returns.Connection = connection
 		context = stack.NewContext()
 		context.Set("database", database)
+		context.Set("token", &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client"}})
 
-		handler = notifications.NewAssignTemplateHandler(templateAssigner, errorWriter)
+		auditor = mocks.NewRegistrationAuditor()
+
+		handler = notifications.NewAssignTemplateHandler(templateAssigner, auditor, errorWriter)
 	})
 
 	It("associates a template with a notification", func() {
@@ -52,9 +58,49 @@ var _ = Describe("AssignTemplateHandler", func() {
 
 		Expect(w.Code).To(Equal(http.StatusNoContent))
 		Expect(templateAssigner.AssignToNotificationCall.Receives.Connection).To(Equal(connection))
+		Expect(templateAssigner.AssignToNotificationCall.Receives.ZoneID).To(Equal("uaa"))
 		Expect(templateAssigner.AssignToNotificationCall.Receives.ClientID).To(Equal("my-client"))
 		Expect(templateAssigner.AssignToNotificationCall.Receives.NotificationID).To(Equal("my-notification"))
 		Expect(templateAssigner.AssignToNotificationCall.Receives.TemplateID).To(Equal("my-template"))
+
+		Expect(auditor.SnapshotCall.Receives.Connection).To(Equal(connection))
+		Expect(auditor.SnapshotCall.Receives.ClientID).To(Equal("my-client"))
+		Expect(auditor.ReportCall.Receives.Connection).To(Equal(connection))
+		Expect(auditor.ReportCall.Receives.Actor).To(Equal(common.RegistrationActor{ClientID: "admin-client"}))
+	})
+
+	It("assigns the template to the client of the zone of the token", func() {
+		context.Set(webutil.ZoneIDKey, "tenant-a")
+
+		body, err := json.Marshal(map[string]string{
+			"template": "my-template",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/notifications/my-notification/template", bytes.NewBuffer(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		Expect(templateAssigner.AssignToNotificationCall.Receives.ZoneID).To(Equal("tenant-a"))
+		Expect(templateAssigner.AssignToNotificationCall.Receives.ClientID).To(Equal("tenant-a:my-client"))
+	})
+
+	It("does not report a registration change when the assigner errors", func() {
+		templateAssigner.AssignToNotificationCall.Returns.Error = errors.New("banana")
+		body, err := json.Marshal(map[string]string{
+			"template": "my-template",
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		w := httptest.NewRecorder()
+		request, err := http.NewRequest("PUT", "/clients/my-client/notifications/my-notification/template", bytes.NewBuffer(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(w, request, context)
+		Expect(auditor.ReportCall.WasCalled).To(BeFalse())
 	})
 
 	It("delegates to the error writer when the assigner errors", func() {
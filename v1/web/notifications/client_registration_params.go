@@ -6,20 +6,48 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	netmail "net/mail"
+	"regexp"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 )
 
+var hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]*[a-z0-9])?(\.[a-z0-9]([a-z0-9-]*[a-z0-9])?)*$`)
+
+var localePattern = regexp.MustCompile(`^[a-zA-Z]{2,8}([-_][a-zA-Z0-9]{1,8})*$`)
+
+// MaxSimulationSinks bounds how many copies of each message a client in
+// simulation mode can have sent.
+const MaxSimulationSinks = 10
+
 type ClientRegistrationParams struct {
 	SourceName    string                           `json:"source_name"`
+	CallbackURL   string                           `json:"callback_url"`
+	LinkDomains   map[string]string                `json:"link_domains"`
+	SenderName    string                           `json:"sender_name"`
 	Notifications map[string](*NotificationStruct) `json:"notifications"`
+
+	// SimulationSinks puts the client in simulation mode when it is not
+	// empty. See models.Client.
+	SimulationSinks []string `json:"simulation_sinks"`
+
+	// EngagementTracking opts the client in to open and click tracking.
+	EngagementTracking bool `json:"engagement_tracking"`
 }
 
 type NotificationStruct struct {
-	ID          string
-	Description string `json:"description"`
-	Critical    bool   `json:"critical"`
+	ID            string
+	Description   string       `json:"description"`
+	Critical      bool         `json:"critical"`
+	OptIn         bool         `json:"opt_in"`
+	Transactional bool         `json:"transactional"`
+	Severity      string       `json:"severity"`
+	RetryPolicy   *RetryPolicy `json:"retry_policy"`
+	Slack         *SlackRoute  `json:"slack"`
+
+	LocalizedDescriptions LocalizedDescriptions `json:"localized_descriptions"`
 }
 
 func NewClientRegistrationParams(body io.Reader) (ClientRegistrationParams, error) {
@@ -54,7 +82,8 @@ func strictValidateJSON(bytes []byte) error {
 	}
 
 	for key := range untypedClientRegistration {
-		if key == "source_name" {
+		if key == "source_name" || key == "callback_url" || key == "link_domains" || key == "sender_name" || key == "simulation_sinks" ||
+			key == "engagement_tracking" {
 			continue
 		} else if key == "notifications" {
 			if untypedClientRegistration[key] == nil {
@@ -67,7 +96,7 @@ func strictValidateJSON(bytes []byte) error {
 				}
 				notificationMap := notificationData.(map[string]interface{})
 				for propertyName := range notificationMap {
-					if propertyName == "description" || propertyName == "critical" {
+					if propertyName == "description" || propertyName == "critical" || propertyName == "opt_in" || propertyName == "transactional" || propertyName == "severity" || propertyName == "retry_policy" || propertyName == "slack" || propertyName == "localized_descriptions" {
 						continue
 					} else {
 						return webutil.SchemaError{Err: fmt.Errorf("%q is not a valid property", propertyName)}
@@ -87,6 +116,31 @@ func (clientRegistration ClientRegistrationParams) Validate() error {
 		errs = append(errs, `"source_name" is a required field`)
 	}
 
+	if clientRegistration.CallbackURL != "" && !webutil.ValidCallbackURL(clientRegistration.CallbackURL) {
+		errs = append(errs, `"callback_url" must be an absolute http or https URL of a public host`)
+	}
+
+	for platform, branded := range clientRegistration.LinkDomains {
+		if !hostnamePattern.MatchString(platform) || !hostnamePattern.MatchString(branded) {
+			errs = append(errs, fmt.Sprintf(`"link_domains" must map hostnames to hostnames, %q => %q is invalid`, platform, branded))
+		}
+	}
+
+	if len(clientRegistration.SenderName) > 255 || strings.ContainsAny(clientRegistration.SenderName, "\r\n") {
+		errs = append(errs, `"sender_name" must be a single line of at most 255 characters`)
+	}
+
+	if len(clientRegistration.SimulationSinks) > MaxSimulationSinks {
+		errs = append(errs, fmt.Sprintf(`"simulation_sinks" must list at most %d addresses`, MaxSimulationSinks))
+	}
+
+	for _, sink := range clientRegistration.SimulationSinks {
+		address, err := netmail.ParseAddress(sink)
+		if err != nil || address.Address != strings.TrimSpace(sink) {
+			errs = append(errs, fmt.Sprintf(`"simulation_sinks" must list email addresses, %q is invalid`, sink))
+		}
+	}
+
 	for id, value := range clientRegistration.Notifications {
 		if value == nil {
 			errs = append(errs, fmt.Sprintf(`notification "%+v" is empty`, id))
@@ -97,6 +151,21 @@ func (clientRegistration ClientRegistrationParams) Validate() error {
 		if value.Description == "" {
 			errs = append(errs, fmt.Sprintf(`notification "%+v" is missing required field "Description"`, id))
 		}
+		if value.Critical && value.OptIn {
+			errs = append(errs, fmt.Sprintf(`notification "%+v" cannot be both "critical" and "opt_in"`, id))
+		}
+		if value.Severity != "" && !models.ValidSeverity(value.Severity) {
+			errs = append(errs, fmt.Sprintf(`notification "%+v" must have a "severity" of "info", "warning" or "critical-action-required"`, id))
+		}
+		if value.RetryPolicy.validate() != nil {
+			errs = append(errs, fmt.Sprintf(`notification "%+v" has a negative "retry_policy" value`, id))
+		}
+		if err := value.Slack.validate(); err != nil {
+			errs = append(errs, fmt.Sprintf(`notification "%+v": %s`, id, err.(webutil.ValidationError).Err))
+		}
+		if err := value.LocalizedDescriptions.validate(); err != nil {
+			errs = append(errs, fmt.Sprintf(`notification "%+v": %s`, id, err.(webutil.ValidationError).Err))
+		}
 	}
 
 	if len(errs) > 0 {
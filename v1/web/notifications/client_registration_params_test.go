@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
@@ -17,14 +18,38 @@ var _ = Describe("ClientRegistrationParams", func() {
 	Describe("NewClientRegistrationParams", func() {
 		It("constructs parameters from a reader", func() {
 			body, err := json.Marshal(map[string]interface{}{
-				"source_name": "Raptor Containment Unit",
+				"source_name":  "Raptor Containment Unit",
+				"callback_url": "https://raptors.example.com/deliveries",
+				"sender_name":  "Raptor Containment",
+				"link_domains": map[string]string{
+					"login.sys.example.com": "login.raptors.example.com",
+				},
+				"simulation_sinks":    []string{"qa@raptors.example.com"},
+				"engagement_tracking": true,
 				"notifications": map[string]interface{}{
 					"perimeter_breach": map[string]interface{}{
-						"description": "Perimeter Breach",
-						"critical":    true,
+						"description":   "Perimeter Breach",
+						"critical":      true,
+						"transactional": true,
+						"localized_descriptions": map[string]string{
+							"fr": "Brèche du périmètre",
+						},
 					},
 					"feeding_time": map[string]interface{}{
 						"description": "Feeding Time",
+						"opt_in":      true,
+						"retry_policy": map[string]interface{}{
+							"max_attempts": 3,
+							"interval":     60,
+						},
+					},
+					"raptor_sighting": map[string]interface{}{
+						"description": "Raptor Sighting",
+						"slack": map[string]interface{}{
+							"webhook_url": "https://hooks.slack.com/services/T0/B0/x",
+							"channel":     "#containment",
+							"exclusive":   true,
+						},
 					},
 				},
 			})
@@ -34,16 +59,41 @@ var _ = Describe("ClientRegistrationParams", func() {
 			Expect(err).NotTo(HaveOccurred())
 
 			Expect(parameters.SourceName).To(Equal("Raptor Containment Unit"))
-			Expect(len(parameters.Notifications)).To(Equal(2))
+			Expect(parameters.CallbackURL).To(Equal("https://raptors.example.com/deliveries"))
+			Expect(parameters.SenderName).To(Equal("Raptor Containment"))
+			Expect(parameters.LinkDomains).To(Equal(map[string]string{
+				"login.sys.example.com": "login.raptors.example.com",
+			}))
+			Expect(parameters.SimulationSinks).To(Equal([]string{"qa@raptors.example.com"}))
+			Expect(parameters.EngagementTracking).To(BeTrue())
+			Expect(len(parameters.Notifications)).To(Equal(3))
 			Expect(parameters.Notifications).To(ContainElement(&notifications.NotificationStruct{
-				ID:          "perimeter_breach",
-				Description: "Perimeter Breach",
-				Critical:    true,
+				ID:            "perimeter_breach",
+				Description:   "Perimeter Breach",
+				Critical:      true,
+				Transactional: true,
+				LocalizedDescriptions: notifications.LocalizedDescriptions{
+					"fr": "Brèche du périmètre",
+				},
 			}))
 			Expect(parameters.Notifications).To(ContainElement(&notifications.NotificationStruct{
 				ID:          "feeding_time",
 				Description: "Feeding Time",
 				Critical:    false,
+				OptIn:       true,
+				RetryPolicy: &notifications.RetryPolicy{
+					MaxAttempts: 3,
+					Interval:    60,
+				},
+			}))
+			Expect(parameters.Notifications).To(ContainElement(&notifications.NotificationStruct{
+				ID:          "raptor_sighting",
+				Description: "Raptor Sighting",
+				Slack: &notifications.SlackRoute{
+					WebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+					Channel:    "#containment",
+					Exclusive:  true,
+				},
 			}))
 		})
 
@@ -105,6 +155,126 @@ var _ = Describe("ClientRegistrationParams", func() {
 			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New("\"source_name\" is a required field")}))
 		})
 
+		It("returns an error when the callback_url is not an absolute http or https URL", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName:  "jurassic_park",
+				CallbackURL: "raptors.example.com/deliveries",
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"callback_url" must be an absolute http or https URL of a public host`)}))
+		})
+
+		It("returns an error when a Slack route is invalid", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName: "jurassic_park",
+				Notifications: map[string]*notifications.NotificationStruct{
+					"raptor_sighting": {
+						ID:          "raptor_sighting",
+						Description: "Raptor Sighting",
+						Slack:       &notifications.SlackRoute{WebhookURL: "hooks.slack.com/services/T0/B0/x"},
+					},
+				},
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`notification "raptor_sighting": slack "webhook_url" must be an absolute http or https URL of a public host, of at most 2048 characters`)}))
+		})
+
+		It("returns an error when a localized description is not keyed by a locale", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName: "jurassic_park",
+				Notifications: map[string]*notifications.NotificationStruct{
+					"raptor_sighting": {
+						ID:                    "raptor_sighting",
+						Description:           "Raptor Sighting",
+						LocalizedDescriptions: notifications.LocalizedDescriptions{"french": "", "fr fr": "Raptor repéré"},
+					},
+				},
+			}
+
+			err := cr.Validate()
+			Expect(err).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(err.Error()).To(ContainSubstring(`notification "raptor_sighting": "localized_descriptions"`))
+		})
+
+		It("returns an error when a link domain is not a hostname", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName: "jurassic_park",
+				LinkDomains: map[string]string{
+					"login.sys.example.com": "https://login.raptors.example.com/",
+				},
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"link_domains" must map hostnames to hostnames, "login.sys.example.com" => "https://login.raptors.example.com/" is invalid`)}))
+		})
+
+		It("returns an error when the sender_name spans more than one line", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName: "jurassic_park",
+				SenderName: "Jurassic Park\r\nBcc: everyone@example.com",
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"sender_name" must be a single line of at most 255 characters`)}))
+		})
+
+		It("returns an error when a simulation sink is not an email address", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName:      "jurassic_park",
+				SimulationSinks: []string{"qa@raptors.example.com", "QA Team <qa@raptors.example.com>", "raptors"},
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"simulation_sinks" must list email addresses, "QA Team <qa@raptors.example.com>" is invalid, "simulation_sinks" must list email addresses, "raptors" is invalid`)}))
+		})
+
+		It("returns an error when there are too many simulation sinks", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName: "jurassic_park",
+			}
+			for i := 0; i <= notifications.MaxSimulationSinks; i++ {
+				cr.SimulationSinks = append(cr.SimulationSinks, fmt.Sprintf("qa-%d@raptors.example.com", i))
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"simulation_sinks" must list at most 10 addresses`)}))
+		})
+
+		It("returns an error when a notification is both critical and opt-in", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName: "jurassic_park",
+				Notifications: map[string](*notifications.NotificationStruct){
+					"perimeter_breach": {
+						ID:          "perimeter_breach",
+						Description: "Perimeter Breach",
+						Critical:    true,
+						OptIn:       true,
+					},
+				},
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`notification "perimeter_breach" cannot be both "critical" and "opt_in"`)}))
+		})
+
+		It("returns an error when a severity is not one of the severities", func() {
+			cr := notifications.ClientRegistrationParams{
+				SourceName: "jurassic_park",
+				Notifications: map[string](*notifications.NotificationStruct){
+					"perimeter_breach": {
+						ID:          "perimeter_breach",
+						Description: "Perimeter Breach",
+						Severity:    "urgent",
+					},
+				},
+			}
+
+			err := cr.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`notification "perimeter_breach" must have a "severity" of "info", "warning" or "critical-action-required"`)}))
+		})
+
 		It("returns an error if notification is missing a required field", func() {
 			cr := notifications.ClientRegistrationParams{
 				SourceName: "jurassic_park",
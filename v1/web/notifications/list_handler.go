@@ -1,11 +1,11 @@
 package notifications
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -14,7 +14,13 @@ type NotificationsByClient map[string]Client
 type Client struct {
 	Name          string                  `json:"name"`
 	Template      string                  `json:"template"`
+	CallbackURL   string                  `json:"callback_url,omitempty"`
+	LinkDomains   map[string]string       `json:"link_domains,omitempty"`
+	SenderName    string                  `json:"sender_name,omitempty"`
 	Notifications map[string]Notification `json:"notifications"`
+
+	SimulationSinks    []string `json:"simulation_sinks,omitempty"`
+	EngagementTracking bool     `json:"engagement_tracking,omitempty"`
 }
 
 type listsAllClientsAndNotifications interface {
@@ -22,9 +28,16 @@ type listsAllClientsAndNotifications interface {
 }
 
 type Notification struct {
-	Description string `json:"description"`
-	Template    string `json:"template"`
-	Critical    bool   `json:"critical"`
+	Description   string       `json:"description"`
+	Template      string       `json:"template"`
+	Critical      bool         `json:"critical"`
+	OptIn         bool         `json:"opt_in,omitempty"`
+	Transactional bool         `json:"transactional,omitempty"`
+	Severity      string       `json:"severity,omitempty"`
+	RetryPolicy   *RetryPolicy `json:"retry_policy,omitempty"`
+	Slack         *SlackRoute  `json:"slack,omitempty"`
+
+	LocalizedDescriptions LocalizedDescriptions `json:"localized_descriptions,omitempty"`
 }
 
 type ListHandler struct {
@@ -46,44 +59,104 @@ func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 		return
 	}
 
-	notificationsByClient := h.constructNotifications(clients, notifications)
+	zoneID := webutil.ZoneID(context)
+	clients = clientsInZone(zoneID, clients)
+	notificationsByClient := h.constructNotifications(zoneID, clients, notifications)
+
+	if webutil.WantsNDJSON(req) {
+		h.stream(w, zoneID, clients, notificationsByClient)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, notificationsByClient)
+}
+
+// stream writes each client on its own line, in the order the finder
+// returned them, with its ID alongside the fields of the JSON document.
+func (h ListHandler) stream(w http.ResponseWriter, zoneID string, clients []models.Client, notificationsByClient NotificationsByClient) {
+	writer := webutil.NewNDJSONWriter(w)
 
-	writeJSON(w, http.StatusOK, notificationsByClient)
+	for _, client := range clients {
+		err := writer.Write(struct {
+			ID string `json:"id"`
+			Client
+		}{
+			ID:     models.UnzonedClientID(zoneID, client.ID),
+			Client: notificationsByClient[models.UnzonedClientID(zoneID, client.ID)],
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	writer.Close()
 }
 
-func (h ListHandler) constructNotifications(clients []models.Client, notifications []models.Kind) NotificationsByClient {
+// clientsInZone leaves out the clients of other UAA zones than zoneID.
+func clientsInZone(zoneID string, clients []models.Client) []models.Client {
+	var inZone []models.Client
+	for _, client := range clients {
+		if models.ClientInZone(zoneID, client.ID) {
+			inZone = append(inZone, client)
+		}
+	}
+
+	return inZone
+}
+
+// constructNotifications keys the clients by the ID the UAA zone knows them
+// by.
+func (h ListHandler) constructNotifications(zoneID string, clients []models.Client, notifications []models.Kind) NotificationsByClient {
 	notificationsByClient := NotificationsByClient{}
 
 	for _, client := range clients {
 		clientWithNotifications := Client{
-			Name:     client.Description,
-			Template: client.TemplateToUse(),
+			Name:        client.Description,
+			Template:    client.TemplateToUse(),
+			CallbackURL: client.CallbackURL,
+			LinkDomains: client.LinkDomains,
+			SenderName:  client.SenderName,
+
+			SimulationSinks:    client.SimulationSinks,
+			EngagementTracking: client.EngagementTracking,
 		}
 
 		clientNotifications := make(map[string]Notification)
 		for _, notification := range notifications {
 			if notification.ClientID == client.ID {
-				clientNotifications[notification.ID] = Notification{
-					Description: notification.Description,
-					Template:    notification.TemplateToUse(),
-					Critical:    notification.Critical,
+				n := Notification{
+					Description:   notification.Description,
+					Template:      notification.TemplateToUse(),
+					Critical:      notification.Critical,
+					OptIn:         notification.OptIn,
+					Transactional: notification.Transactional,
+					Severity:      notification.Severity,
+
+					LocalizedDescriptions: LocalizedDescriptions(notification.LocalizedDescriptions),
+				}
+
+				if notification.RetryMaxAttempts > 0 || notification.RetryInterval > 0 {
+					n.RetryPolicy = &RetryPolicy{
+						MaxAttempts: notification.RetryMaxAttempts,
+						Interval:    notification.RetryInterval,
+					}
+				}
+
+				// The webhook URL is a credential, so only the routing is shown.
+				if notification.SlackWebhookURL != "" {
+					n.Slack = &SlackRoute{
+						Channel:   notification.SlackChannel,
+						Exclusive: notification.SlackExclusive,
+					}
 				}
+
+				clientNotifications[notification.ID] = n
 			}
 		}
 
 		clientWithNotifications.Notifications = clientNotifications
-		notificationsByClient[client.ID] = clientWithNotifications
+		notificationsByClient[models.UnzonedClientID(zoneID, client.ID)] = clientWithNotifications
 	}
 
 	return notificationsByClient
 }
-
-func writeJSON(w http.ResponseWriter, status int, object interface{}) {
-	output, err := json.Marshal(object)
-	if err != nil {
-		panic(err) // No JSON we write into a response should ever panic
-	}
-
-	w.WriteHeader(status)
-	w.Write(output)
-}
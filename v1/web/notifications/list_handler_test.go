@@ -4,10 +4,12 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -46,6 +48,12 @@ var _ = Describe("ListHandler", func() {
 				{
 					ID:          "client-123",
 					Description: "Jurassic Park",
+					CallbackURL: "https://jurassic.example.com/deliveries",
+					LinkDomains: models.LinkDomains{"login.sys.example.com": "login.jurassic.example.com"},
+					SenderName:  "Jurassic Park Security",
+
+					SimulationSinks:    models.SimulationSinks{"qa@jurassic.example.com"},
+					EngagementTracking: true,
 				},
 				{
 					ID:          "client-456",
@@ -55,21 +63,28 @@ var _ = Describe("ListHandler", func() {
 
 			notificationsFinder.AllClientsAndNotificationsCall.Returns.Kinds = []models.Kind{
 				{
-					ID:          "perimeter-breach",
-					Description: "very bad",
-					Critical:    true,
-					ClientID:    "client-123",
+					ID:            "perimeter-breach",
+					Description:   "very bad",
+					Critical:      true,
+					Transactional: true,
+					Severity:      models.SeverityCriticalActionRequired,
+					ClientID:      "client-123",
+
+					LocalizedDescriptions: models.LocalizedDescriptions{"fr": "très mauvais"},
 				},
 				{
-					ID:          "fence-broken",
-					Description: "even worse",
-					Critical:    true,
-					ClientID:    "client-123",
+					ID:              "fence-broken",
+					Description:     "even worse",
+					Critical:        true,
+					ClientID:        "client-123",
+					SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+					SlackChannel:    "#fences",
 				},
 				{
 					ID:          "perimeter-is-good",
 					Description: "very good",
 					Critical:    false,
+					OptIn:       true,
 					ClientID:    "client-456",
 				},
 				{
@@ -88,16 +103,25 @@ var _ = Describe("ListHandler", func() {
 				"client-123": {
 					"name": "Jurassic Park",
 					"template": "default",
+					"callback_url": "https://jurassic.example.com/deliveries",
+					"link_domains": {"login.sys.example.com": "login.jurassic.example.com"},
+					"sender_name": "Jurassic Park Security",
+					"simulation_sinks": ["qa@jurassic.example.com"],
+					"engagement_tracking": true,
 					"notifications": {
 						"perimeter-breach": {
 							"description": "very bad",
 							"template": "default",
-							"critical": true
+							"critical": true,
+							"transactional": true,
+							"severity": "critical-action-required",
+							"localized_descriptions": {"fr": "très mauvais"}
 						},
 						"fence-broken": {
 							"description": "even worse",
 							"template": "default",
-							"critical": true
+							"critical": true,
+							"slack": {"channel": "#fences", "exclusive": false}
 						}
 					}
 				},
@@ -108,7 +132,8 @@ var _ = Describe("ListHandler", func() {
 						"perimeter-is-good": {
 							"description": "very good",
 							"template": "default",
-							"critical": false
+							"critical": false,
+							"opt_in": true
 						},
 						"fence-works": {
 							"description": "even better",
@@ -122,6 +147,73 @@ var _ = Describe("ListHandler", func() {
 			Expect(notificationsFinder.AllClientsAndNotificationsCall.Receives.Database).To(Equal(database))
 		})
 
+		It("streams one client per line when newline delimited JSON is accepted", func() {
+			notificationsFinder.AllClientsAndNotificationsCall.Returns.Clients = []models.Client{
+				{ID: "client-456", Description: "Jurassic Park Ride"},
+				{ID: "client-123", Description: "Jurassic Park"},
+			}
+			notificationsFinder.AllClientsAndNotificationsCall.Returns.Kinds = []models.Kind{
+				{ID: "perimeter-breach", Description: "very bad", Critical: true, ClientID: "client-123"},
+			}
+			request.Header.Set("Accept", "application/x-ndjson")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+
+			lines := strings.Split(strings.TrimSuffix(writer.Body.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(MatchJSON(`{
+				"id": "client-456",
+				"name": "Jurassic Park Ride",
+				"template": "default",
+				"notifications": {}
+			}`))
+			Expect(lines[1]).To(MatchJSON(`{
+				"id": "client-123",
+				"name": "Jurassic Park",
+				"template": "default",
+				"notifications": {
+					"perimeter-breach": {
+						"description": "very bad",
+						"template": "default",
+						"critical": true
+					}
+				}
+			}`))
+		})
+
+		It("lists only the clients of the zone of the token, under the IDs the zone knows", func() {
+			context.Set(webutil.ZoneIDKey, "tenant-a")
+			notificationsFinder.AllClientsAndNotificationsCall.Returns.Clients = []models.Client{
+				{ID: "client-123", Description: "Jurassic Park"},
+				{ID: "tenant-a:client-123", Description: "Isla Sorna"},
+				{ID: "tenant-b:client-123", Description: "Isla Nublar"},
+			}
+			notificationsFinder.AllClientsAndNotificationsCall.Returns.Kinds = []models.Kind{
+				{ID: "perimeter-breach", Description: "very bad", ClientID: "client-123"},
+				{ID: "raptor-sighting", Description: "run", ClientID: "tenant-a:client-123"},
+			}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"client-123": {
+					"name": "Isla Sorna",
+					"template": "default",
+					"notifications": {
+						"raptor-sighting": {
+							"description": "run",
+							"template": "default",
+							"critical": false
+						}
+					}
+				}
+			}`))
+		})
+
 		Context("when the notifications finder errors", func() {
 			It("delegates to the error writer", func() {
 				notificationsFinder.AllClientsAndNotificationsCall.Returns.Error = errors.New("BANANA!!!")
@@ -3,6 +3,7 @@ package notifications
 import (
 	"errors"
 	"net/http"
+	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
@@ -18,16 +19,22 @@ type registrar interface {
 
 type PutHandler struct {
 	registrar   registrar
+	auditor     registrationAuditor
 	errorWriter errorWriter
 }
 
-func NewPutHandler(registrar registrar, errWriter errorWriter) PutHandler {
+func NewPutHandler(registrar registrar, auditor registrationAuditor, errWriter errorWriter) PutHandler {
 	return PutHandler{
 		registrar:   registrar,
+		auditor:     auditor,
 		errorWriter: errWriter,
 	}
 }
 
+func (h PutHandler) RequestBody() interface{} {
+	return ClientRegistrationParams{}
+}
+
 func (h PutHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	database := context.Get("database").(DatabaseInterface)
 	connection := database.Connection()
@@ -46,12 +53,25 @@ func (h PutHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 
 	generatedKinds := []models.Kind{}
 	for _, notification := range parameters.Notifications {
-		generatedKinds = append(generatedKinds, models.Kind{
-			ID:          notification.ID,
-			Description: notification.Description,
-			Critical:    notification.Critical,
-			TemplateID:  models.DoNotSetTemplateID,
-		})
+		kind := models.Kind{
+			ID:            notification.ID,
+			Description:   notification.Description,
+			Critical:      notification.Critical,
+			OptIn:         notification.OptIn,
+			Transactional: notification.Transactional,
+			Severity:      severityOrInfo(notification.Severity),
+			TemplateID:    models.DoNotSetTemplateID,
+		}
+
+		if notification.RetryPolicy != nil {
+			kind.RetryMaxAttempts = notification.RetryPolicy.MaxAttempts
+			kind.RetryInterval = notification.RetryPolicy.Interval
+		}
+
+		notification.Slack.apply(&kind)
+		notification.LocalizedDescriptions.apply(&kind)
+
+		generatedKinds = append(generatedKinds, kind)
 	}
 
 	token := context.Get("token").(*jwt.Token)
@@ -61,6 +81,12 @@ func (h PutHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 		ID:          clientID,
 		Description: parameters.SourceName,
 		TemplateID:  models.DoNotSetTemplateID,
+		CallbackURL: parameters.CallbackURL,
+		LinkDomains: linkDomains(parameters.LinkDomains),
+		SenderName:  strings.TrimSpace(parameters.SenderName),
+
+		SimulationSinks:    simulationSinks(parameters.SimulationSinks),
+		EngagementTracking: parameters.EngagementTracking,
 	}
 
 	kinds, err := h.ValidateCriticalScopes(token.Claims["scope"], generatedKinds, client)
@@ -69,6 +95,12 @@ func (h PutHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 		return
 	}
 
+	before, err := h.auditor.Snapshot(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
 	transaction := connection.Transaction()
 	transaction.Begin()
 
@@ -88,6 +120,13 @@ func (h PutHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 		}
 	}
 
+	err = h.auditor.Report(transaction, before, registrationActor(context))
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
 	err = transaction.Commit()
 	if err != nil {
 		h.errorWriter.Write(w, err)
@@ -116,3 +155,29 @@ func (h PutHandler) ValidateCriticalScopes(scopes interface{}, kinds []models.Ki
 
 	return validatedKinds, nil
 }
+
+func linkDomains(mappings map[string]string) models.LinkDomains {
+	if len(mappings) == 0 {
+		return nil
+	}
+
+	domains := models.LinkDomains{}
+	for platform, branded := range mappings {
+		domains[strings.ToLower(platform)] = strings.ToLower(branded)
+	}
+
+	return domains
+}
+
+func simulationSinks(addresses []string) models.SimulationSinks {
+	if len(addresses) == 0 {
+		return nil
+	}
+
+	sinks := models.SimulationSinks{}
+	for _, address := range addresses {
+		sinks = append(sinks, strings.TrimSpace(address))
+	}
+
+	return sinks
+}
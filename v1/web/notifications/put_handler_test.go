@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
@@ -30,6 +31,7 @@ var _ = Describe("PutHandler", func() {
 		conn        *mocks.Connection
 		transaction *mocks.Transaction
 		registrar   *mocks.Registrar
+		auditor     *mocks.RegistrationAuditor
 		client      models.Client
 		kinds       []models.Kind
 		context     stack.Context
@@ -46,16 +48,34 @@ var _ = Describe("PutHandler", func() {
 
 		errorWriter = mocks.NewErrorWriter()
 		registrar = mocks.NewRegistrar()
+		auditor = mocks.NewRegistrationAuditor()
 		writer = httptest.NewRecorder()
 		requestBody, err := json.Marshal(map[string]interface{}{
-			"source_name": "Raptor Containment Unit",
+			"source_name":  "Raptor Containment Unit",
+			"callback_url": "https://raptors.example.com/deliveries",
+			"sender_name":  " Raptor Containment ",
+			"link_domains": map[string]string{
+				"Login.Sys.Example.com": "login.raptors.example.com",
+			},
+			"simulation_sinks":    []string{" qa@raptors.example.com "},
+			"engagement_tracking": true,
 			"notifications": map[string]interface{}{
 				"perimeter_breach": map[string]interface{}{
-					"description": "Perimeter Breach",
-					"critical":    true,
+					"description":   "Perimeter Breach",
+					"critical":      true,
+					"transactional": true,
+					"severity":      "critical-action-required",
 				},
 				"feeding_time": map[string]interface{}{
 					"description": "Feeding Time",
+					"opt_in":      true,
+					"slack": map[string]interface{}{
+						"webhook_url": "https://hooks.slack.com/services/T0/B0/x",
+						"channel":     "#keepers",
+					},
+					"localized_descriptions": map[string]string{
+						"pt_BR": "Hora da alimentação",
+					},
 				},
 			},
 		})
@@ -87,23 +107,38 @@ var _ = Describe("PutHandler", func() {
 		client = models.Client{
 			ID:          "raptors",
 			Description: "Raptor Containment Unit",
+			CallbackURL: "https://raptors.example.com/deliveries",
+			LinkDomains: models.LinkDomains{
+				"login.sys.example.com": "login.raptors.example.com",
+			},
+			SenderName:         "Raptor Containment",
+			SimulationSinks:    models.SimulationSinks{"qa@raptors.example.com"},
+			EngagementTracking: true,
 		}
 
 		kinds = []models.Kind{
 			{
-				ID:          "perimeter_breach",
-				Description: "Perimeter Breach",
-				Critical:    true,
-				ClientID:    client.ID,
+				ID:            "perimeter_breach",
+				Description:   "Perimeter Breach",
+				Critical:      true,
+				Transactional: true,
+				Severity:      models.SeverityCriticalActionRequired,
+				ClientID:      client.ID,
 			},
 			{
-				ID:          "feeding_time",
-				Description: "Feeding Time",
-				ClientID:    client.ID,
+				ID:              "feeding_time",
+				Description:     "Feeding Time",
+				OptIn:           true,
+				Severity:        models.SeverityInfo,
+				ClientID:        client.ID,
+				SlackWebhookURL: "https://hooks.slack.com/services/T0/B0/x",
+				SlackChannel:    "#keepers",
+
+				LocalizedDescriptions: models.LocalizedDescriptions{"pt-br": "Hora da alimentação"},
 			},
 		}
 
-		handler = notifications.NewPutHandler(registrar, errorWriter)
+		handler = notifications.NewPutHandler(registrar, auditor, errorWriter)
 	})
 
 	Describe("Execute", func() {
@@ -131,6 +166,17 @@ var _ = Describe("PutHandler", func() {
 			Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
 		})
 
+		It("reports the change to the registration within the transaction", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(auditor.SnapshotCall.Receives.Connection).To(Equal(conn))
+			Expect(auditor.SnapshotCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(auditor.ReportCall.Receives.Connection).To(Equal(transaction))
+			Expect(auditor.ReportCall.Receives.Actor).To(Equal(common.RegistrationActor{ClientID: "raptors"}))
+
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
 		It("does not prune kinds if they are not in the request", func() {
 			requestBody, err := json.Marshal(map[string]interface{}{
 				"source_name": "Raptor Containment Unit",
@@ -246,6 +292,18 @@ var _ = Describe("PutHandler", func() {
 				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
 			})
 
+			It("delegates auditor errors to the ErrorWriter", func() {
+				auditor.ReportCall.Returns.Error = errors.New("queue is down")
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("queue is down")))
+
+				Expect(transaction.BeginCall.WasCalled).To(BeTrue())
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
 			It("delegates transaction errors to the ErrorWriter", func() {
 				transaction.CommitCall.Returns.Error = errors.New("transaction commit error")
 				handler.ServeHTTP(writer, request, context)
@@ -0,0 +1,24 @@
+package notifications
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+type registrationAuditor interface {
+	Snapshot(conn services.ConnectionInterface, clientID string) (services.RegistrationSnapshot, error)
+	Report(conn services.ConnectionInterface, before services.RegistrationSnapshot, actor common.RegistrationActor) error
+}
+
+func registrationActor(context stack.Context) common.RegistrationActor {
+	token := context.Get("token").(*jwt.Token)
+	clientID, _ := token.Claims["client_id"].(string)
+	userID, _ := token.Claims["user_id"].(string)
+
+	return common.RegistrationActor{
+		ClientID: clientID,
+		UserID:   userID,
+	}
+}
@@ -12,16 +12,22 @@ import (
 
 type RegistrationHandler struct {
 	registrar   registrar
+	auditor     registrationAuditor
 	errorWriter errorWriter
 }
 
-func NewRegistrationHandler(registrar registrar, errWriter errorWriter) RegistrationHandler {
+func NewRegistrationHandler(registrar registrar, auditor registrationAuditor, errWriter errorWriter) RegistrationHandler {
 	return RegistrationHandler{
 		registrar:   registrar,
+		auditor:     auditor,
 		errorWriter: errWriter,
 	}
 }
 
+func (h RegistrationHandler) RequestBody() interface{} {
+	return RegistrationParams{}
+}
+
 func (h RegistrationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	database := context.Get("database").(DatabaseInterface)
 	connection := database.Connection()
@@ -53,6 +59,12 @@ func (h RegistrationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request,
 		return
 	}
 
+	before, err := h.auditor.Snapshot(connection, clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
 	transaction := connection.Transaction()
 	transaction.Begin()
 
@@ -72,6 +84,13 @@ func (h RegistrationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request,
 		}
 	}
 
+	err = h.auditor.Report(transaction, before, registrationActor(context))
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
 	err = transaction.Commit()
 	if err != nil {
 		h.errorWriter.Write(w, err)
@@ -30,6 +30,7 @@ var _ = Describe("RegistrationHandler", func() {
 		conn        *mocks.Connection
 		transaction *mocks.Transaction
 		registrar   *mocks.Registrar
+		auditor     *mocks.RegistrationAuditor
 		client      models.Client
 		kinds       []models.Kind
 		context     stack.Context
@@ -44,6 +45,7 @@ var _ = Describe("RegistrationHandler", func() {
 
 		errorWriter = mocks.NewErrorWriter()
 		registrar = mocks.NewRegistrar()
+		auditor = mocks.NewRegistrationAuditor()
 		writer = httptest.NewRecorder()
 		requestBody, err := json.Marshal(map[string]interface{}{
 			"source_description": "Raptor Containment Unit",
@@ -101,7 +103,7 @@ var _ = Describe("RegistrationHandler", func() {
 			},
 		}
 
-		handler = notifications.NewRegistrationHandler(registrar, errorWriter)
+		handler = notifications.NewRegistrationHandler(registrar, auditor, errorWriter)
 	})
 
 	Describe("Execute", func() {
@@ -129,6 +131,15 @@ var _ = Describe("RegistrationHandler", func() {
 			Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
 		})
 
+		It("reports the change to the registration within the transaction", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(auditor.SnapshotCall.Receives.Connection).To(Equal(conn))
+			Expect(auditor.SnapshotCall.Receives.ClientID).To(Equal("raptors"))
+			Expect(auditor.ReportCall.Receives.Connection).To(Equal(transaction))
+			Expect(auditor.ReportCall.Receives.Actor.ClientID).To(Equal("raptors"))
+		})
+
 		It("does not trim kinds if they are not in the request", func() {
 			requestBody, err := json.Marshal(map[string]interface{}{
 				"source_description": "Raptor Containment Unit",
@@ -248,6 +259,17 @@ var _ = Describe("RegistrationHandler", func() {
 				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
 			})
 
+			It("delegates auditor errors to the ErrorWriter", func() {
+				auditor.ReportCall.Returns.Error = errors.New("queue is down")
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(errors.New("queue is down")))
+
+				Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+				Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			})
+
 			It("delegates transaction errors to the ErrorWriter", func() {
 				transaction.CommitCall.Returns.Error = errors.New("transaction commit error")
 				handler.ServeHTTP(writer, request, context)
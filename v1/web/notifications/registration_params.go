@@ -65,6 +65,10 @@ func (registration RegistrationParams) Validate() error {
 			kindErrors = append(kindErrors, `"kind.description" is a required field`)
 		}
 
+		if kind.Severity != "" && !models.ValidSeverity(kind.Severity) {
+			kindErrors = append(kindErrors, `"kind.severity" must be "info", "warning" or "critical-action-required"`)
+		}
+
 		if len(kindErrors) > 0 {
 			break
 		}
@@ -139,5 +139,25 @@ var _ = Describe("RegistrationParams", func() {
 			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New("\"kind.id\" is improperly formatted")}))
 		})
 
+		It("validates the severity of kinds", func() {
+			body, err := json.Marshal(map[string]interface{}{
+				"source_description": "the source description",
+				"kinds": []map[string]string{
+					{
+						"id":          "kind-id",
+						"description": "kind description",
+						"severity":    "urgent",
+					},
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			parameters, err := notifications.NewRegistrationParams(ioutil.NopCloser(bytes.NewBuffer(body)))
+			Expect(err).NotTo(HaveOccurred())
+
+			err = parameters.Validate()
+			Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"kind.severity" must be "info", "warning" or "critical-action-required"`)}))
+		})
+
 	})
 })
@@ -30,6 +30,7 @@ var _ = Describe("Routes", func() {
 			ErrorWriter:          mocks.NewErrorWriter(),
 			NotificationsFinder:  mocks.NewNotificationsFinder(),
 			NotificationsUpdater: &mocks.NotificationUpdater{},
+			RegistrationAuditor:  mocks.NewRegistrationAuditor(),
 		}.Register(muxer)
 	})
 
@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -19,16 +20,22 @@ type notificationsUpdater interface {
 
 type UpdateHandler struct {
 	updater     notificationsUpdater
+	auditor     registrationAuditor
 	errorWriter errorWriter
 }
 
-func NewUpdateHandler(updater notificationsUpdater, errWriter errorWriter) UpdateHandler {
+func NewUpdateHandler(updater notificationsUpdater, auditor registrationAuditor, errWriter errorWriter) UpdateHandler {
 	return UpdateHandler{
 		updater:     updater,
+		auditor:     auditor,
 		errorWriter: errWriter,
 	}
 }
 
+func (h UpdateHandler) RequestBody() interface{} {
+	return NotificationUpdateParams{}
+}
+
 func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	var updateParams NotificationUpdateParams
 
@@ -40,9 +47,22 @@ func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 
 	regex := regexp.MustCompile("/clients/(.*)/notifications/(.*)")
 	matches := regex.FindStringSubmatch(req.URL.Path)
-	clientID, notificationID := matches[1], matches[2]
+	clientID, notificationID := webutil.ZonedClientID(context, matches[1]), matches[2]
+
+	database := context.Get("database").(DatabaseInterface)
+	before, err := h.auditor.Snapshot(database.Connection(), clientID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.updater.Update(database, updateParams.ToModel(clientID, notificationID))
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
 
-	err = h.updater.Update(context.Get("database").(DatabaseInterface), updateParams.ToModel(clientID, notificationID))
+	err = h.auditor.Report(database.Connection(), before, registrationActor(context))
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
@@ -6,10 +6,12 @@ import (
 	"net/http"
 	"net/http/httptest"
 
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -24,8 +26,10 @@ var _ = Describe("UpdateHandler", func() {
 		request     *http.Request
 		context     stack.Context
 		updater     *mocks.NotificationUpdater
+		auditor     *mocks.RegistrationAuditor
 		errorWriter *mocks.ErrorWriter
 		database    *mocks.Database
+		connection  *mocks.Connection
 	)
 
 	Describe("ServeHTTP", func() {
@@ -37,11 +41,16 @@ var _ = Describe("UpdateHandler", func() {
 			request, err = http.NewRequest("PUT", "/clients/this-client/notifications/this-kind", bytes.NewBuffer(body))
 			Expect(err).NotTo(HaveOccurred())
 
+			connection = mocks.NewConnection()
 			database = mocks.NewDatabase()
+			database.ConnectionCall.Returns.Connection = connection
 			context = stack.NewContext()
 			context.Set("database", database)
+			context.Set("token", &jwt.Token{Claims: map[string]interface{}{"client_id": "admin-client", "user_id": "some-user"}})
 
-			handler = notifications.NewUpdateHandler(updater, errorWriter)
+			auditor = mocks.NewRegistrationAuditor()
+
+			handler = notifications.NewUpdateHandler(updater, auditor, errorWriter)
 		})
 
 		It("calls update on its updater with appropriate arguments", func() {
@@ -52,17 +61,38 @@ var _ = Describe("UpdateHandler", func() {
 			Expect(updater.UpdateCall.Receives.Notification).To(Equal(models.Kind{
 				Description: "test kind",
 				Critical:    false,
+				Severity:    models.SeverityInfo,
 				TemplateID:  "template-name",
 				ClientID:    "this-client",
 				ID:          "this-kind",
 			}))
 		})
 
+		It("reports the change to the registration of the client", func() {
+			handler.ServeHTTP(writer, request, context)
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+
+			Expect(auditor.SnapshotCall.Receives.Connection).To(Equal(connection))
+			Expect(auditor.SnapshotCall.Receives.ClientID).To(Equal("this-client"))
+			Expect(auditor.ReportCall.Receives.Connection).To(Equal(connection))
+			Expect(auditor.ReportCall.Receives.Actor).To(Equal(common.RegistrationActor{
+				ClientID: "admin-client",
+				UserID:   "some-user",
+			}))
+		})
+
 		Context("when an error occurs", func() {
 			It("propagates the error returned from the updater into the error writer", func() {
 				updater.UpdateCall.Returns.Error = errors.New("error occurred while updating notification")
 				handler.ServeHTTP(writer, request, context)
 				Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("error occurred while updating notification")))
+				Expect(auditor.ReportCall.WasCalled).To(BeFalse())
+			})
+
+			It("propagates the error returned from the auditor into the error writer", func() {
+				auditor.ReportCall.Returns.Error = errors.New("queue is down")
+				handler.ServeHTTP(writer, request, context)
+				Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("queue is down")))
 			})
 
 			It("writes a params validation error when the request is semantically invalid", func() {
@@ -1,7 +1,10 @@
 package notifications
 
 import (
+	"errors"
+	"fmt"
 	"io"
+	"strings"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
@@ -9,9 +12,97 @@ import (
 )
 
 type NotificationUpdateParams struct {
-	Description string `json:"description" validate-required:"true"`
-	Critical    bool   `json:"critical"    validate-required:"true"`
-	TemplateID  string `json:"template"    validate-required:"true"`
+	Description   string       `json:"description"  validate-required:"true"`
+	Critical      bool         `json:"critical"     validate-required:"true"`
+	OptIn         bool         `json:"opt_in"`
+	Transactional bool         `json:"transactional"`
+	Severity      string       `json:"severity"`
+	TemplateID    string       `json:"template"     validate-required:"true"`
+	RetryPolicy   *RetryPolicy `json:"retry_policy"`
+	Slack         *SlackRoute  `json:"slack"`
+
+	LocalizedDescriptions LocalizedDescriptions `json:"localized_descriptions"`
+}
+
+type RetryPolicy struct {
+	MaxAttempts int `json:"max_attempts"`
+	Interval    int `json:"interval"`
+}
+
+func (policy *RetryPolicy) validate() error {
+	if policy == nil {
+		return nil
+	}
+
+	if policy.MaxAttempts < 0 || policy.Interval < 0 {
+		return webutil.ValidationError{Err: errors.New("retry_policy values must not be negative")}
+	}
+
+	return nil
+}
+
+// SlackRoute posts a notification to a Slack incoming webhook, as well as
+// emailing users unless Exclusive is set. Channel overrides the channel of
+// webhooks that allow it.
+type SlackRoute struct {
+	WebhookURL string `json:"webhook_url,omitempty"`
+	Channel    string `json:"channel,omitempty"`
+	Exclusive  bool   `json:"exclusive"`
+}
+
+func (route *SlackRoute) validate() error {
+	if route == nil {
+		return nil
+	}
+
+	if !webutil.ValidCallbackURL(route.WebhookURL) || len(route.WebhookURL) > 2048 {
+		return webutil.ValidationError{Err: errors.New(`slack "webhook_url" must be an absolute http or https URL of a public host, of at most 2048 characters`)}
+	}
+
+	if len(route.Channel) > 80 || strings.ContainsAny(route.Channel, " \t\r\n") {
+		return webutil.ValidationError{Err: errors.New(`slack "channel" must be a channel name of at most 80 characters`)}
+	}
+
+	return nil
+}
+
+func (route *SlackRoute) apply(kind *models.Kind) {
+	if route == nil {
+		return
+	}
+
+	kind.SlackWebhookURL = route.WebhookURL
+	kind.SlackChannel = route.Channel
+	kind.SlackExclusive = route.Exclusive
+}
+
+// LocalizedDescriptions translates a notification's description for
+// preference UIs, keyed by locale.
+type LocalizedDescriptions map[string]string
+
+func (descriptions LocalizedDescriptions) validate() error {
+	for locale, description := range descriptions {
+		if !localePattern.MatchString(locale) {
+			return webutil.ValidationError{Err: fmt.Errorf(`"localized_descriptions" must be keyed by locales such as "fr" or "pt-BR", %q is invalid`, locale)}
+		}
+
+		if strings.TrimSpace(description) == "" {
+			return webutil.ValidationError{Err: fmt.Errorf(`"localized_descriptions" must not have an empty description for %q`, locale)}
+		}
+	}
+
+	return nil
+}
+
+func (descriptions LocalizedDescriptions) apply(kind *models.Kind) {
+	if len(descriptions) == 0 {
+		return
+	}
+
+	kind.LocalizedDescriptions = models.LocalizedDescriptions{}
+	for locale, description := range descriptions {
+		kind.LocalizedDescriptions[models.NormalizeLocale(locale)] = description
+	}
 }
 
 func NewNotificationParams(body io.Reader) (NotificationUpdateParams, error) {
@@ -27,15 +118,62 @@ func NewNotificationParams(body io.Reader) (NotificationUpdateParams, error) {
 			return params, webutil.ParseError{}
 		}
 	}
+
+	if params.Critical && params.OptIn {
+		return params, webutil.ValidationError{Err: errors.New("a notification cannot be both critical and opt_in")}
+	}
+
+	if params.Severity != "" && !models.ValidSeverity(params.Severity) {
+		return params, webutil.ValidationError{Err: errors.New(`"severity" must be "info", "warning" or "critical-action-required"`)}
+	}
+
+	err = params.RetryPolicy.validate()
+	if err != nil {
+		return params, err
+	}
+
+	err = params.Slack.validate()
+	if err != nil {
+		return params, err
+	}
+
+	err = params.LocalizedDescriptions.validate()
+	if err != nil {
+		return params, err
+	}
+
 	return params, nil
 }
 
+// severityOrInfo is the severity of a notification that is registered with
+// the given one, which is informational unless it says otherwise.
+func severityOrInfo(severity string) string {
+	if severity == "" {
+		return models.SeverityInfo
+	}
+
+	return severity
+}
+
 func (params NotificationUpdateParams) ToModel(clientID, notificationID string) models.Kind {
-	return models.Kind{
-		Description: params.Description,
-		Critical:    params.Critical,
-		TemplateID:  params.TemplateID,
-		ClientID:    clientID,
-		ID:          notificationID,
+	kind := models.Kind{
+		Description:   params.Description,
+		Critical:      params.Critical,
+		OptIn:         params.OptIn,
+		Transactional: params.Transactional,
+		Severity:      severityOrInfo(params.Severity),
+		TemplateID:    params.TemplateID,
+		ClientID:      clientID,
+		ID:            notificationID,
 	}
+
+	if params.RetryPolicy != nil {
+		kind.RetryMaxAttempts = params.RetryPolicy.MaxAttempts
+		kind.RetryInterval = params.RetryPolicy.Interval
+	}
+
+	params.Slack.apply(&kind)
+	params.LocalizedDescriptions.apply(&kind)
+
+	return kind
 }
@@ -1,8 +1,10 @@
 package notifications_test
 
 import (
+	"errors"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notifications"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 
@@ -30,6 +32,57 @@ var _ = Describe("Notification", func() {
 				})
 			})
 
+			Context("when the notification is both critical and opt-in", func() {
+				It("returns a validation error", func() {
+					body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "opt_in":true, "template":"my-awesome-template"}`)
+					_, err := notifications.NewNotificationParams(body)
+					Expect(err).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+				})
+			})
+
+			Context("when the severity is not one of the severities", func() {
+				It("returns a validation error", func() {
+					body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "template":"my-awesome-template", "severity":"urgent"}`)
+					_, err := notifications.NewNotificationParams(body)
+					Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"severity" must be "info", "warning" or "critical-action-required"`)}))
+				})
+			})
+
+			Context("when the retry policy has negative values", func() {
+				It("returns a validation error", func() {
+					body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "template":"my-awesome-template", "retry_policy":{"max_attempts":-1}}`)
+					_, err := notifications.NewNotificationParams(body)
+					Expect(err).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+				})
+			})
+
+			Context("when the Slack route is invalid", func() {
+				It("returns a validation error", func() {
+					for _, slack := range []string{
+						`{"channel":"#ops"}`,
+						`{"webhook_url":"ftp://hooks.slack.com/services/T0/B0/x"}`,
+						`{"webhook_url":"https://hooks.slack.com/services/T0/B0/x", "channel":"#ops room"}`,
+					} {
+						body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "template":"my-awesome-template", "slack":` + slack + `}`)
+						_, err := notifications.NewNotificationParams(body)
+						Expect(err).To(BeAssignableToTypeOf(webutil.ValidationError{}), slack)
+					}
+				})
+			})
+
+			Context("when the localized descriptions are invalid", func() {
+				It("returns a validation error", func() {
+					for _, descriptions := range []string{
+						`{"not a locale":"Réinitialisation"}`,
+						`{"fr":"  "}`,
+					} {
+						body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "template":"my-awesome-template", "localized_descriptions":` + descriptions + `}`)
+						_, err := notifications.NewNotificationParams(body)
+						Expect(err).To(BeAssignableToTypeOf(webutil.ValidationError{}), descriptions)
+					}
+				})
+			})
+
 			Context("when the json is malformed", func() {
 				It("returns a parse error", func() {
 					body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "template":"my-awesome-template}`)
@@ -52,6 +105,62 @@ var _ = Describe("Notification", func() {
 			Expect(notification.TemplateID).To(Equal("my-awesome-template"))
 			Expect(notification.ClientID).To(Equal("client-id"))
 			Expect(notification.ID).To(Equal("notification-id"))
+			Expect(notification.RetryMaxAttempts).To(Equal(0))
+			Expect(notification.RetryInterval).To(Equal(0))
+		})
+
+		It("includes the retry policy when one is given", func() {
+			body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "template":"my-awesome-template", "retry_policy":{"max_attempts":288, "interval":300}}`)
+			updateParams, err := notifications.NewNotificationParams(body)
+			Expect(err).NotTo(HaveOccurred())
+
+			notification := updateParams.ToModel("client-id", "notification-id")
+			Expect(notification.RetryMaxAttempts).To(Equal(288))
+			Expect(notification.RetryInterval).To(Equal(300))
+		})
+
+		It("includes the Slack route when one is given", func() {
+			body := strings.NewReader(`{"description":"my awesome notification", "critical":true, "template":"my-awesome-template", "slack":{"webhook_url":"https://hooks.slack.com/services/T0/B0/x", "channel":"#ops", "exclusive":true}}`)
+			updateParams, err := notifications.NewNotificationParams(body)
+			Expect(err).NotTo(HaveOccurred())
+
+			notification := updateParams.ToModel("client-id", "notification-id")
+			Expect(notification.SlackWebhookURL).To(Equal("https://hooks.slack.com/services/T0/B0/x"))
+			Expect(notification.SlackChannel).To(Equal("#ops"))
+			Expect(notification.SlackExclusive).To(BeTrue())
+		})
+
+		It("marks transactional notifications", func() {
+			body := strings.NewReader(`{"description":"password reset", "critical":true, "transactional":true, "template":"my-awesome-template"}`)
+			updateParams, err := notifications.NewNotificationParams(body)
+			Expect(err).NotTo(HaveOccurred())
+
+			notification := updateParams.ToModel("client-id", "notification-id")
+			Expect(notification.Transactional).To(BeTrue())
+		})
+
+		It("includes the severity, which is info unless one is given", func() {
+			body := strings.NewReader(`{"description":"password reset", "critical":true, "severity":"warning", "template":"my-awesome-template"}`)
+			updateParams, err := notifications.NewNotificationParams(body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updateParams.ToModel("client-id", "notification-id").Severity).To(Equal(models.SeverityWarning))
+
+			body = strings.NewReader(`{"description":"password reset", "critical":true, "template":"my-awesome-template"}`)
+			updateParams, err = notifications.NewNotificationParams(body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updateParams.ToModel("client-id", "notification-id").Severity).To(Equal(models.SeverityInfo))
+		})
+
+		It("includes the localized descriptions, keyed by normalized locale", func() {
+			body := strings.NewReader(`{"description":"password reset", "critical":true, "template":"my-awesome-template", "localized_descriptions":{"fr":"Réinitialisation du mot de passe", "pt_BR":"Redefinição de senha"}}`)
+			updateParams, err := notifications.NewNotificationParams(body)
+			Expect(err).NotTo(HaveOccurred())
+
+			notification := updateParams.ToModel("client-id", "notification-id")
+			Expect(notification.LocalizedDescriptions).To(Equal(models.LocalizedDescriptions{
+				"fr":    "Réinitialisation du mot de passe",
+				"pt-br": "Redefinição de senha",
+			}))
 		})
 	})
 })
@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+)
+
+// AudienceTypes are the kinds of audience a notify request can target,
+// named after the first segment of their paths.
+var AudienceTypes = []string{"users", "spaces", "organizations", "everyone", "uaa_scopes", "groups", "emails"}
+
+// Audience is who a notify request sends to. ID is the GUID of the user,
+// space or organization, the name of the scope or group, or the email
+// address. Role narrows a space or organization to the users with it, such
+// as "developers" or "OrgManager".
+type Audience struct {
+	Type string
+	ID   string
+	Role string
+}
+
+// AuthorizationRequest describes a notify request whose token has been
+// validated and whose client and kind have been found.
+type AuthorizationRequest struct {
+	ClientID string
+	Scopes   []string
+	Kind     models.Kind
+	Audience Audience
+}
+
+// Authorizer is where deployments add policy of their own to notify
+// requests. It is consulted before anything is registered or sent; a
+// webutil.AuthorizationDenial refuses the request with a 403, and any other
+// error fails it.
+type Authorizer interface {
+	Authorize(request AuthorizationRequest) error
+}
+
+// AudienceScopes is an Authorizer that lets only clients with one of the
+// listed scopes send to an audience type, for example only platform
+// operators to "everyone". Audience types it does not list are allowed.
+type AudienceScopes map[string][]string
+
+func (a AudienceScopes) Authorize(request AuthorizationRequest) error {
+	required, ok := a[request.Audience.Type]
+	if !ok {
+		return nil
+	}
+
+	for _, scope := range required {
+		for _, held := range request.Scopes {
+			if scope == held {
+				return nil
+			}
+		}
+	}
+
+	return webutil.AuthorizationDenial{
+		Code:   "audience_scope_required",
+		Reason: fmt.Sprintf("Sending to %s requires one of the scopes %s", request.Audience.Type, strings.Join(required, ", ")),
+	}
+}
+
+func audienceOf(req *http.Request, guid string, parameters NotifyParams) Audience {
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+
+	audience := Audience{
+		Type: segments[0],
+		ID:   guid,
+		Role: parameters.Role,
+	}
+	if audience.Type == "emails" {
+		audience.ID = parameters.To
+	}
+	if len(segments) > 2 {
+		audience.Role = segments[2]
+	}
+
+	return audience
+}
+
+func tokenScopes(claim interface{}) []string {
+	scopes := []string{}
+	elements, _ := claim.([]interface{})
+	for _, element := range elements {
+		if scope, ok := element.(string); ok {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	return scopes
+}
@@ -0,0 +1,49 @@
+package notify_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AudienceScopes", func() {
+	var authorizer notify.AudienceScopes
+
+	BeforeEach(func() {
+		authorizer = notify.AudienceScopes{
+			"everyone": {"notifications.admin", "cloud_controller.admin"},
+		}
+	})
+
+	It("allows a client with one of the scopes to send to the audience", func() {
+		err := authorizer.Authorize(notify.AuthorizationRequest{
+			ClientID: "ops-client",
+			Scopes:   []string{"notifications.write", "cloud_controller.admin"},
+			Audience: notify.Audience{Type: "everyone"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("denies a client without any of the scopes", func() {
+		err := authorizer.Authorize(notify.AuthorizationRequest{
+			ClientID: "app-client",
+			Scopes:   []string{"notifications.write"},
+			Audience: notify.Audience{Type: "everyone"},
+		})
+		Expect(err).To(Equal(webutil.AuthorizationDenial{
+			Code:   "audience_scope_required",
+			Reason: "Sending to everyone requires one of the scopes notifications.admin, cloud_controller.admin",
+		}))
+	})
+
+	It("allows audiences it does not list", func() {
+		err := authorizer.Authorize(notify.AuthorizationRequest{
+			ClientID: "app-client",
+			Scopes:   []string{"notifications.write"},
+			Audience: notify.Audience{Type: "users", ID: "user-123"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
@@ -4,11 +4,12 @@ import (
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
 const (
-	VCAPRequestIDKey    = "vcap_request_id"
+	VCAPRequestIDKey    = webutil.VCAPRequestIDKey
 	RequestReceivedTime = "request_received_time"
 )
 
@@ -38,8 +39,12 @@ func NewEmailHandler(notify notifyExecutor, errWriter errorWriter, strategy Disp
 	}
 }
 
+func (h EmailHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
 func (h EmailHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
-	vcapRequestID := context.Get(VCAPRequestIDKey).(string)
+	vcapRequestID := webutil.VCAPRequestID(context)
 	database := context.Get("database").(DatabaseInterface)
 	conn := database.Connection()
 
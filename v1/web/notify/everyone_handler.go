@@ -3,6 +3,7 @@ package notify
 import (
 	"net/http"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -20,9 +21,13 @@ func NewEveryoneHandler(notify notifyExecutor, errWriter errorWriter, strategy D
 	}
 }
 
+func (h EveryoneHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
 func (h EveryoneHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	connection := context.Get("database").(DatabaseInterface).Connection()
-	vcapRequestID := context.Get(VCAPRequestIDKey).(string)
+	vcapRequestID := webutil.VCAPRequestID(context)
 
 	output, err := h.notify.Execute(connection, req, context, "", h.strategy, GUIDValidator{}, vcapRequestID)
 	if err != nil {
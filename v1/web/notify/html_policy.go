@@ -0,0 +1,63 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+)
+
+type htmlSanitizer interface {
+	Sanitize(fragment string) (string, []string)
+	SanitizeAttributes(element, attributes string) (string, []string)
+}
+
+// HTMLPolicy cleans the HTML clients send before it is queued. Without a
+// Sanitizer the HTML is passed through untouched, and in Strict mode markup
+// that would have been removed fails the request instead.
+type HTMLPolicy struct {
+	Sanitizer htmlSanitizer
+	Strict    bool
+}
+
+func (p HTMLPolicy) Apply(parsed *HTML) error {
+	if p.Sanitizer == nil {
+		return nil
+	}
+
+	head, removed := p.Sanitizer.Sanitize(parsed.Head)
+
+	bodyAttributes, removedAttributes := p.Sanitizer.SanitizeAttributes("body", parsed.BodyAttributes)
+	removed = appendMissing(removed, removedAttributes)
+
+	bodyContent, removedContent := p.Sanitizer.Sanitize(parsed.BodyContent)
+	removed = appendMissing(removed, removedContent)
+
+	if p.Strict && len(removed) > 0 {
+		return webutil.ValidationError{Err: fmt.Errorf(`"html" contains markup that is not allowed: %s`, strings.Join(removed, ", "))}
+	}
+
+	parsed.Head = head
+	parsed.BodyAttributes = bodyAttributes
+	parsed.BodyContent = bodyContent
+
+	return nil
+}
+
+func appendMissing(list, additions []string) []string {
+	for _, addition := range additions {
+		found := false
+		for _, item := range list {
+			if item == addition {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			list = append(list, addition)
+		}
+	}
+
+	return list
+}
@@ -0,0 +1,59 @@
+package notify
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+)
+
+const (
+	IdempotencyKeyHeader    = "Idempotency-Key"
+	maxIdempotencyKeyLength = 255
+)
+
+type idempotencyKeysRepo interface {
+	Find(conn models.ConnectionInterface, clientID, key string) (models.IdempotencyKey, error)
+	Upsert(conn models.ConnectionInterface, idempotencyKey models.IdempotencyKey) (models.IdempotencyKey, error)
+}
+
+// requestHash identifies a request by its method, path and body, so a key
+// reused for a different request can be told apart from a retry.
+func requestHash(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s %s\n", req.Method, req.URL.Path)
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// previousResponse returns the response recorded for key when the same
+// request was already made within the idempotency window.
+func (h Notify) previousResponse(connection ConnectionInterface, clientID, key, hash string, received time.Time) ([]byte, bool, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, false, webutil.ValidationError{Err: fmt.Errorf("%s header must not be longer than %d characters", IdempotencyKeyHeader, maxIdempotencyKeyLength)}
+	}
+
+	previous, err := h.idempotencyKeys.Find(connection, clientID, key)
+	if err != nil {
+		if _, ok := err.(models.NotFoundError); ok {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	if received.Sub(previous.CreatedAt) >= h.idempotencyWindow {
+		return nil, false, nil
+	}
+
+	if previous.RequestHash != hash {
+		return nil, false, models.DuplicateError{Err: errors.New("Idempotency-Key has already been used for a different request")}
+	}
+
+	return []byte(previous.Response), true, nil
+}
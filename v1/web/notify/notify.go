@@ -1,15 +1,20 @@
 package notify
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/audit"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/dgrijalva/jwt-go"
 	"github.com/ryanmoran/stack"
@@ -20,22 +25,39 @@ type clientAndKindFinder interface {
 }
 
 type registrar interface {
-	Register(services.ConnectionInterface, models.Client, []models.Kind) error
+	Refresh(services.ConnectionInterface, models.Client, []models.Kind) error
 	Prune(services.ConnectionInterface, models.Client, []models.Kind) error
 }
 
 type Notify struct {
-	finder    clientAndKindFinder
-	registrar registrar
+	finder            clientAndKindFinder
+	registrar         registrar
+	idempotencyKeys   idempotencyKeysRepo
+	idempotencyWindow time.Duration
+	htmlPolicy        HTMLPolicy
+	authorizers       []Authorizer
 }
 
-func NewNotify(finder clientAndKindFinder, registrar registrar) Notify {
+// NewNotify builds the notify executor. Requests carrying an Idempotency-Key
+// header are answered with the original response when they repeat a request
+// made within idempotencyWindow; a zero window ignores the header.
+func NewNotify(finder clientAndKindFinder, registrar registrar, idempotencyKeys idempotencyKeysRepo, idempotencyWindow time.Duration, htmlPolicy HTMLPolicy) Notify {
 	return Notify{
-		finder:    finder,
-		registrar: registrar,
+		finder:            finder,
+		registrar:         registrar,
+		idempotencyKeys:   idempotencyKeys,
+		idempotencyWindow: idempotencyWindow,
+		htmlPolicy:        htmlPolicy,
 	}
 }
 
+// WithAuthorizers has each request checked by the authorizers, in order,
+// once its client and kind are known. The first to refuse it decides.
+func (h Notify) WithAuthorizers(authorizers ...Authorizer) Notify {
+	h.authorizers = authorizers
+	return h
+}
+
 type ValidatorInterface interface {
 	Validate(*NotifyParams) bool
 }
@@ -43,7 +65,12 @@ type ValidatorInterface interface {
 func (h Notify) Execute(connection ConnectionInterface, req *http.Request, context stack.Context,
 	guid string, strategy Dispatcher, validator ValidatorInterface, vcapRequestID string) ([]byte, error) {
 
-	parameters, err := NewNotifyParams(req.Body)
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		return []byte{}, err
+	}
+
+	parameters, err := NewNotifyParams(ioutil.NopCloser(bytes.NewReader(body)))
 	if err != nil {
 		return []byte{}, err
 	}
@@ -52,10 +79,18 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 		return []byte{}, webutil.ValidationError{Err: errors.New(strings.Join(parameters.Errors, ","))}
 	}
 
+	if err := h.htmlPolicy.Apply(&parameters.ParsedHTML); err != nil {
+		return []byte{}, err
+	}
+
 	requestReceivedTime, ok := context.Get(RequestReceivedTime).(time.Time)
 	if !ok {
 		panic("programmer error: missing RequestReceivedTime in http context")
 	}
+	if !parameters.ParsedDeadline.IsZero() && !parameters.ParsedDeadline.After(requestReceivedTime) {
+		return []byte{}, webutil.ValidationError{Err: errors.New(`"deadline" has already passed`)}
+	}
+
 	token := context.Get("token").(*jwt.Token) // TODO: (rm) get rid of the context object, just pass in the token
 	clientID := token.Claims["client_id"].(string)
 
@@ -65,6 +100,23 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 	}
 	uaaHost := tokenIssuerURL.Scheme + "://" + tokenIssuerURL.Host
 
+	idempotencyKey := req.Header.Get(IdempotencyKeyHeader)
+	if h.idempotencyWindow == 0 {
+		idempotencyKey = ""
+	}
+
+	hash := requestHash(req, body)
+	if idempotencyKey != "" {
+		output, found, err := h.previousResponse(connection, clientID, idempotencyKey, hash, requestReceivedTime)
+		if err != nil {
+			return []byte{}, err
+		}
+
+		if found {
+			return output, nil
+		}
+	}
+
 	client, kind, err := h.finder.ClientAndKind(context.Get("database").(DatabaseInterface), clientID, parameters.KindID)
 	if err != nil {
 		return []byte{}, err
@@ -74,24 +126,55 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 		return []byte{}, webutil.NewCriticalNotificationError(kind.ID)
 	}
 
-	err = h.registrar.Register(connection, client, []models.Kind{kind})
+	for _, authorizer := range h.authorizers {
+		err = authorizer.Authorize(AuthorizationRequest{
+			ClientID: clientID,
+			Scopes:   tokenScopes(token.Claims["scope"]),
+			Kind:     kind,
+			Audience: audienceOf(req, guid, parameters),
+		})
+		if err != nil {
+			return []byte{}, err
+		}
+	}
+
+	err = h.registrar.Refresh(connection, client, []models.Kind{kind})
 	if err != nil {
 		return []byte{}, err
 	}
 
+	callbackURL := parameters.CallbackURL
+	if callbackURL == "" {
+		callbackURL = client.CallbackURL
+	}
+
 	var responses []services.Response
 
+	span := tracing.Start("notifications.dispatch", tracing.KindInternal, tracing.FromContext(req.Context()))
+	span.SetAttribute("vcap_request_id", vcapRequestID)
+	span.SetAttribute("strategy", fmt.Sprintf("%T", strategy))
+	defer span.End()
+
 	responses, err = strategy.Dispatch(services.Dispatch{
-		GUID:       guid,
-		Connection: connection,
-		Role:       parameters.Role,
+		GUID:        guid,
+		Connection:  connection,
+		Role:        parameters.Role,
+		CallbackURL: callbackURL,
+		AppGUID:     parameters.AppGUID,
+		TraceParent: span.Context.Traceparent(),
+		Deadline:    parameters.ParsedDeadline,
+		Metadata:    parameters.Metadata,
+		Severity:    severityOf(parameters, kind),
 		Client: services.DispatchClient{
-			ID:          clientID,
-			Description: client.Description,
+			ID:              clientID,
+			Description:     client.Description,
+			SimulationSinks: client.SimulationSinks,
 		},
 		Kind: services.DispatchKind{
-			ID:          parameters.KindID,
-			Description: kind.Description,
+			ID:            parameters.KindID,
+			Description:   kind.Description,
+			Critical:      kind.Critical,
+			Transactional: kind.Transactional,
 		},
 		UAAHost: uaaHost,
 		VCAPRequest: services.DispatchVCAPRequest{
@@ -112,12 +195,39 @@ func (h Notify) Execute(connection ConnectionInterface, req *http.Request, conte
 		},
 	})
 	if err != nil {
+		span.RecordError(err)
 		return []byte{}, err
 	}
 
 	output, err := json.Marshal(responses)
 	if err != nil {
-		panic(err)
+		return []byte{}, err
+	}
+
+	if kind.Critical {
+		details := map[string]string{"kind_id": kind.ID}
+		switch {
+		case guid != "":
+			details["recipient"] = guid
+		case parameters.To != "":
+			details["recipient"] = parameters.To
+		}
+
+		context.Set(audit.ActionKey, "notification.send_critical")
+		context.Set(audit.DetailsKey, details)
+	}
+
+	if idempotencyKey != "" {
+		// The notifications are already queued, so failing to remember the
+		// key must not turn into an error that invites the client to retry.
+		_, err = h.idempotencyKeys.Upsert(connection, models.IdempotencyKey{
+			ClientID:    clientID,
+			Key:         idempotencyKey,
+			RequestHash: hash,
+			Response:    string(output),
+			CreatedAt:   requestReceivedTime.UTC().Truncate(time.Second),
+		})
+		span.RecordError(err)
 	}
 
 	return output, nil
@@ -131,3 +241,16 @@ func (h Notify) hasCriticalNotificationsWriteScope(elements interface{}) bool {
 	}
 	return false
 }
+
+// severityOf is the severity the notify request gives the notification, or
+// else that of its kind.
+func severityOf(parameters NotifyParams, kind models.Kind) string {
+	switch {
+	case parameters.Severity != "":
+		return parameters.Severity
+	case kind.Severity != "":
+		return kind.Severity
+	default:
+		return models.SeverityInfo
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"io"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/PuerkitoBio/goquery"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
@@ -27,7 +28,18 @@ type NotifyParams struct {
 	To      string `json:"to"`
 	Role    string `json:"role"`
 
+	CallbackURL string `json:"callback_url"`
+	AppGUID     string `json:"app_guid"`
+	Deadline    string `json:"deadline"`
+
+	// Metadata is echoed back in the status and webhooks of the messages.
+	Metadata map[string]string `json:"metadata"`
+
+	// Severity overrides the severity of the kind for this notification.
+	Severity string `json:"severity"`
+
 	ParsedHTML        HTML
+	ParsedDeadline    time.Time
 	KindDescription   string
 	SourceDescription string
 	Errors            []string
@@ -53,6 +65,8 @@ func NewNotifyParams(body io.ReadCloser) (NotifyParams, error) {
 		return notify, err
 	}
 
+	notify.parseDeadline()
+
 	return notify, nil
 }
 
@@ -70,6 +84,22 @@ func (notify *NotifyParams) parseRequestBody(body io.ReadCloser) error {
 	return nil
 }
 
+// parseDeadline reads the deadline, which is an absolute time so that it
+// means the same thing however long the notification spends queued. A
+// deadline that cannot be parsed is left for the validator to report.
+func (notify *NotifyParams) parseDeadline() {
+	if notify.Deadline == "" {
+		return
+	}
+
+	deadline, err := time.Parse(time.RFC3339, notify.Deadline)
+	if err != nil {
+		return
+	}
+
+	notify.ParsedDeadline = deadline.UTC()
+}
+
 func (notify *NotifyParams) FormatEmailAndExtractHTML() error {
 	notify.To = EmailFormatter{}.Format(notify.To)
 
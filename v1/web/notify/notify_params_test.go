@@ -4,6 +4,7 @@ import (
 	"io"
 	"io/ioutil"
 	"strings"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
 
@@ -87,6 +88,25 @@ var _ = Describe("NotifyParams", func() {
 			})
 		})
 
+		Describe("deadline parsing", func() {
+			It("parses an RFC 3339 deadline into UTC", func() {
+				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+					"deadline": "2015-06-08T16:00:00-07:00"
+				}`)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parameters.ParsedDeadline).To(Equal(time.Date(2015, time.June, 8, 23, 0, 0, 0, time.UTC)))
+			})
+
+			It("leaves a deadline that cannot be parsed unset", func() {
+				parameters, err := notify.NewNotifyParams(ioutil.NopCloser(strings.NewReader(`{
+					"deadline": "in an hour"
+				}`)))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(parameters.Deadline).To(Equal("in an hour"))
+				Expect(parameters.ParsedDeadline.IsZero()).To(BeTrue())
+			})
+		})
+
 		Describe("html parsing", func() {
 			Context("when a doctype is passed in", func() {
 				It("pulls out the doctype", func() {
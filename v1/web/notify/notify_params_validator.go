@@ -1,9 +1,22 @@
 package notify
 
-import "regexp"
+import (
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+)
 
 var kindIDFormat = regexp.MustCompile(`^[0-9a-zA-Z_\-.]+$`)
 
+// Metadata is stored with every message of a notification, so it is kept
+// small.
+const (
+	maxMetadataKeys      = 16
+	maxMetadataKeyLength = 64
+	maxMetadataSize      = 2048
+)
+
 type EmailValidator struct{}
 
 func (validator EmailValidator) Validate(notify *NotifyParams) bool {
@@ -21,6 +34,11 @@ func (validator EmailValidator) Validate(notify *NotifyParams) bool {
 		notify.Errors = append(notify.Errors, `"text" or "html" fields must be supplied`)
 	}
 
+	checkCallbackURLField(notify)
+	checkDeadlineField(notify)
+	checkMetadataField(notify)
+	checkSeverityField(notify)
+
 	return len(notify.Errors) == 0
 }
 
@@ -39,6 +57,11 @@ func (validator GUIDValidator) Validate(notify *NotifyParams) bool {
 		notify.Errors = append(notify.Errors, `"role" must be "OrgManager", "OrgAuditor", "BillingManager" or unset`)
 	}
 
+	checkCallbackURLField(notify)
+	checkDeadlineField(notify)
+	checkMetadataField(notify)
+	checkSeverityField(notify)
+
 	return len(notify.Errors) == 0
 }
 
@@ -46,6 +69,43 @@ func missingTextOrHTMLFields(notify *NotifyParams) bool {
 	return notify.Text == "" && notify.ParsedHTML.BodyContent == ""
 }
 
+func checkCallbackURLField(notify *NotifyParams) {
+	if notify.CallbackURL != "" && !webutil.ValidCallbackURL(notify.CallbackURL) {
+		notify.Errors = append(notify.Errors, `"callback_url" must be an absolute http or https URL of a public host`)
+	}
+}
+
+func checkDeadlineField(notify *NotifyParams) {
+	if notify.Deadline != "" && notify.ParsedDeadline.IsZero() {
+		notify.Errors = append(notify.Errors, `"deadline" must be an RFC 3339 timestamp`)
+	}
+}
+
+func checkMetadataField(notify *NotifyParams) {
+	if len(notify.Metadata) > maxMetadataKeys {
+		notify.Errors = append(notify.Errors, `"metadata" must have at most 16 keys`)
+	}
+
+	size := 0
+	for key, value := range notify.Metadata {
+		if len(key) > maxMetadataKeyLength || !kindIDFormat.MatchString(key) {
+			notify.Errors = append(notify.Errors, `"metadata" keys must be at most 64 letters, digits, "_", "-" or "."`)
+			return
+		}
+		size += len(key) + len(value)
+	}
+
+	if size > maxMetadataSize {
+		notify.Errors = append(notify.Errors, `"metadata" must not exceed 2048 bytes`)
+	}
+}
+
+func checkSeverityField(notify *NotifyParams) {
+	if notify.Severity != "" && !models.ValidSeverity(notify.Severity) {
+		notify.Errors = append(notify.Errors, `"severity" must be "info", "warning", "critical-action-required" or unset`)
+	}
+}
+
 func (validator GUIDValidator) invalidRoleField(roleName string) bool {
 	if roleName == "" {
 		return false
@@ -1,6 +1,10 @@
 package notify_test
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -124,6 +128,66 @@ var _ = Describe("Validator", func() {
 				Expect(len(params.Errors)).To(Equal(1))
 				Expect(params.Errors).To(ContainElement(`"role" must be "OrgManager", "OrgAuditor", "BillingManager" or unset`))
 			})
+
+			It("validates that the callback_url is an absolute http or https URL", func() {
+				params.CallbackURL = "https://example.com/callback"
+				Expect(validator.Validate(params)).To(BeTrue())
+
+				params.CallbackURL = "example.com/callback"
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"callback_url" must be an absolute http or https URL of a public host`))
+
+				params.Errors = []string{}
+				params.CallbackURL = "http://169.254.169.254/latest/meta-data"
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"callback_url" must be an absolute http or https URL of a public host`))
+			})
+
+			It("validates that the severity is one of the severities", func() {
+				for _, severity := range []string{"info", "warning", "critical-action-required", ""} {
+					params.Severity = severity
+					Expect(validator.Validate(params)).To(BeTrue())
+				}
+
+				params.Severity = "urgent"
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"severity" must be "info", "warning", "critical-action-required" or unset`))
+			})
+
+			It("validates that a deadline was parsed", func() {
+				params.Deadline = "2015-06-08T16:00:00Z"
+				params.ParsedDeadline = time.Date(2015, time.June, 8, 16, 0, 0, 0, time.UTC)
+				Expect(validator.Validate(params)).To(BeTrue())
+
+				params.Deadline = "in an hour"
+				params.ParsedDeadline = time.Time{}
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"deadline" must be an RFC 3339 timestamp`))
+			})
+
+			It("validates that the metadata is small and its keys well formed", func() {
+				params.Metadata = map[string]string{"ticket": "INC-1234", "incident.id": "42"}
+				Expect(validator.Validate(params)).To(BeTrue())
+
+				params.Metadata = map[string]string{"ticket id": "INC-1234"}
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"metadata" keys must be at most 64 letters, digits, "_", "-" or "."`))
+
+				params.Metadata = map[string]string{strings.Repeat("k", 65): "value"}
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"metadata" keys must be at most 64 letters, digits, "_", "-" or "."`))
+
+				params.Metadata = map[string]string{"ticket": strings.Repeat("v", 2048)}
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"metadata" must not exceed 2048 bytes`))
+
+				params.Metadata = map[string]string{}
+				for i := 0; i < 17; i++ {
+					params.Metadata[fmt.Sprintf("key-%d", i)] = "value"
+				}
+				Expect(validator.Validate(params)).To(BeFalse())
+				Expect(params.Errors).To(ConsistOf(`"metadata" must have at most 16 keys`))
+			})
 		})
 	})
 })
@@ -8,10 +8,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/cloudfoundry-incubator/notifications/sanitize"
 	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/audit"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/dgrijalva/jwt-go"
@@ -41,6 +44,7 @@ var _ = Describe("Notify", func() {
 				vcapRequestID   string
 				database        *mocks.Database
 				reqReceivedTime time.Time
+				idempotencyKeys *mocks.IdempotencyKeysRepo
 			)
 
 			BeforeEach(func() {
@@ -49,10 +53,11 @@ var _ = Describe("Notify", func() {
 					Description: "Health Monitor",
 				}
 				kind = models.Kind{
-					ID:          "test_email",
-					Description: "Instance Down",
-					ClientID:    "mister-client",
-					Critical:    true,
+					ID:            "test_email",
+					Description:   "Instance Down",
+					ClientID:      "mister-client",
+					Critical:      true,
+					Transactional: true,
 				}
 				finder = mocks.NewNotificationsFinder()
 				finder.ClientAndKindCall.Returns.Client = client
@@ -108,7 +113,10 @@ var _ = Describe("Notify", func() {
 				validator = mocks.NewValidator()
 				validator.ValidateCall.Returns.Valid = true
 
-				handler = notify.NewNotify(finder, registrar)
+				idempotencyKeys = mocks.NewIdempotencyKeysRepo()
+				idempotencyKeys.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+				handler = notify.NewNotify(finder, registrar, idempotencyKeys, 24*time.Hour, notify.HTMLPolicy{})
 			})
 
 			It("delegates to the strategy", func() {
@@ -116,16 +124,24 @@ var _ = Describe("Notify", func() {
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(strategy.DispatchCallsCount).To(Equal(1))
-				Expect(strategy.DispatchCalls[0].Receives.Dispatch).To(Equal(services.Dispatch{
+
+				dispatch := strategy.DispatchCalls[0].Receives.Dispatch
+				Expect(tracing.ParseTraceparent(dispatch.TraceParent).IsValid()).To(BeTrue())
+				dispatch.TraceParent = ""
+
+				Expect(dispatch).To(Equal(services.Dispatch{
 					GUID:       "space-001",
 					Connection: conn,
+					Severity:   models.SeverityInfo,
 					Client: services.DispatchClient{
 						ID:          "mister-client",
 						Description: "Health Monitor",
 					},
 					Kind: services.DispatchKind{
-						ID:          "test_email",
-						Description: "Instance Down",
+						ID:            "test_email",
+						Description:   "Instance Down",
+						Critical:      true,
+						Transactional: true,
 					},
 					UAAHost: "http://zone-uaa-host",
 					VCAPRequest: services.DispatchVCAPRequest{
@@ -146,6 +162,206 @@ var _ = Describe("Notify", func() {
 				}))
 			})
 
+			Context("when an HTML policy is configured", func() {
+				var policy notify.HTMLPolicy
+
+				BeforeEach(func() {
+					policy = notify.HTMLPolicy{Sanitizer: sanitize.NewSanitizer(sanitize.DefaultPolicy)}
+				})
+
+				It("dispatches the cleaned HTML", func() {
+					handler = notify.NewNotify(finder, registrar, idempotencyKeys, 24*time.Hour, policy)
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.Message.HTML).To(Equal(services.HTML{
+						BodyContent:    "<p>This is the HTML Body of the email</p>",
+						BodyAttributes: `class="hello"`,
+						Doctype:        "<!DOCTYPE html>",
+					}))
+				})
+
+				It("rejects the request in strict mode", func() {
+					policy.Strict = true
+					handler = notify.NewNotify(finder, registrar, idempotencyKeys, 24*time.Hour, policy)
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"html" contains markup that is not allowed: <script>`)}))
+					Expect(strategy.DispatchCallsCount).To(Equal(0))
+				})
+			})
+
+			It("marks the send of a critical notification for the audit log", func() {
+				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(context.Get(audit.ActionKey)).To(Equal("notification.send_critical"))
+				Expect(context.Get(audit.DetailsKey)).To(Equal(map[string]string{
+					"kind_id":   "test_email",
+					"recipient": "space-001",
+				}))
+			})
+
+			It("does not mark the send of other notifications", func() {
+				kind.Critical = false
+				finder.ClientAndKindCall.Returns.Kind = kind
+
+				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(context.Get(audit.ActionKey)).To(BeNil())
+			})
+
+			It("continues the trace of the request", func() {
+				parent := tracing.Start("HTTP POST", tracing.KindServer, tracing.SpanContext{})
+				request = request.WithContext(tracing.ContextWithSpan(request.Context(), parent))
+
+				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				dispatch := tracing.ParseTraceparent(strategy.DispatchCalls[0].Receives.Dispatch.TraceParent)
+				Expect(dispatch.TraceID).To(Equal(parent.Context.TraceID))
+				Expect(dispatch.SpanID).NotTo(Equal(parent.Context.SpanID))
+			})
+
+			Context("when a callback URL is given", func() {
+				It("passes the callback URL to the strategy", func() {
+					client.CallbackURL = "https://example.com/client-callback"
+					finder.ClientAndKindCall.Returns.Client = client
+
+					body, err := json.Marshal(map[string]string{
+						"kind_id":      "test_email",
+						"text":         "some text",
+						"callback_url": "https://example.com/request-callback",
+					})
+					Expect(err).NotTo(HaveOccurred())
+					request, err = http.NewRequest("POST", "/spaces/space-001", bytes.NewBuffer(body))
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.CallbackURL).To(Equal("https://example.com/request-callback"))
+				})
+			})
+
+			It("passes the app the notification is about to the strategy", func() {
+				body, err := json.Marshal(map[string]string{
+					"kind_id":  "test_email",
+					"text":     "{{.App.Name}} crashed",
+					"app_guid": "app-001",
+				})
+				Expect(err).NotTo(HaveOccurred())
+				request, err = http.NewRequest("POST", "/spaces/space-001", bytes.NewBuffer(body))
+				Expect(err).NotTo(HaveOccurred())
+
+				_, err = handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(strategy.DispatchCalls[0].Receives.Dispatch.AppGUID).To(Equal("app-001"))
+				Expect(strategy.DispatchCalls[0].Receives.Dispatch.Message.Text).To(Equal("{{.App.Name}} crashed"))
+			})
+
+			Context("when the client has registered a callback URL", func() {
+				It("uses it when the request does not give one", func() {
+					client.CallbackURL = "https://example.com/client-callback"
+					finder.ClientAndKindCall.Returns.Client = client
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.CallbackURL).To(Equal("https://example.com/client-callback"))
+				})
+			})
+
+			Context("when a deadline is given", func() {
+				It("passes the deadline to the strategy", func() {
+					body, err := json.Marshal(map[string]string{
+						"kind_id":  "test_email",
+						"text":     "Maintenance starts in 1 hour",
+						"deadline": "2015-06-08T15:30:00-07:00",
+					})
+					Expect(err).NotTo(HaveOccurred())
+					request, err = http.NewRequest("POST", "/spaces/space-001", bytes.NewBuffer(body))
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.Deadline).To(Equal(time.Date(2015, time.June, 8, 22, 30, 0, 0, time.UTC)))
+				})
+
+				It("refuses a deadline that passed before the request was received", func() {
+					body, err := json.Marshal(map[string]string{
+						"kind_id":  "test_email",
+						"text":     "Maintenance starts in 1 hour",
+						"deadline": "2015-06-08T14:00:00-07:00",
+					})
+					Expect(err).NotTo(HaveOccurred())
+					request, err = http.NewRequest("POST", "/spaces/space-001", bytes.NewBuffer(body))
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New(`"deadline" has already passed`)}))
+					Expect(strategy.DispatchCallsCount).To(Equal(0))
+				})
+			})
+
+			Context("when metadata is given", func() {
+				It("passes the metadata to the strategy", func() {
+					body, err := json.Marshal(map[string]interface{}{
+						"kind_id":  "test_email",
+						"text":     "Maintenance starts in 1 hour",
+						"metadata": map[string]string{"ticket": "INC-1234"},
+					})
+					Expect(err).NotTo(HaveOccurred())
+					request, err = http.NewRequest("POST", "/spaces/space-001", bytes.NewBuffer(body))
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.Metadata).To(Equal(map[string]string{"ticket": "INC-1234"}))
+				})
+			})
+
+			Context("when a severity is given", func() {
+				It("dispatches with it in place of the severity of the kind", func() {
+					kind.Severity = models.SeverityWarning
+					finder.ClientAndKindCall.Returns.Kind = kind
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.Severity).To(Equal(models.SeverityWarning))
+
+					body, err := json.Marshal(map[string]interface{}{
+						"kind_id":  "test_email",
+						"text":     "Your instance is down",
+						"severity": "critical-action-required",
+					})
+					Expect(err).NotTo(HaveOccurred())
+					request, err = http.NewRequest("POST", "/spaces/space-001", bytes.NewBuffer(body))
+					Expect(err).NotTo(HaveOccurred())
+
+					_, err = handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(strategy.DispatchCalls[1].Receives.Dispatch.Severity).To(Equal(models.SeverityCriticalActionRequired))
+				})
+			})
+
+			Context("when the client is in simulation mode", func() {
+				It("dispatches with the client's sink addresses", func() {
+					client.SimulationSinks = models.SimulationSinks{"qa@partner.example.com"}
+					finder.ClientAndKindCall.Returns.Client = client
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(strategy.DispatchCalls[0].Receives.Dispatch.Client.SimulationSinks).To(Equal([]string{"qa@partner.example.com"}))
+				})
+			})
+
 			It("registers the client and kind", func() {
 				_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
 				Expect(err).NotTo(HaveOccurred())
@@ -154,9 +370,156 @@ var _ = Describe("Notify", func() {
 				Expect(finder.ClientAndKindCall.Receives.ClientID).To(Equal("mister-client"))
 				Expect(finder.ClientAndKindCall.Receives.KindID).To(Equal("test_email"))
 
-				Expect(registrar.RegisterCall.Receives.Connection).To(Equal(conn))
-				Expect(registrar.RegisterCall.Receives.Client).To(Equal(client))
-				Expect(registrar.RegisterCall.Receives.Kinds).To(ConsistOf([]models.Kind{kind}))
+				Expect(registrar.RefreshCall.Receives.Connection).To(Equal(conn))
+				Expect(registrar.RefreshCall.Receives.Client).To(Equal(client))
+				Expect(registrar.RefreshCall.Receives.Kinds).To(ConsistOf([]models.Kind{kind}))
+			})
+
+			Context("when authorizers are configured", func() {
+				var first, second *mocks.Authorizer
+
+				BeforeEach(func() {
+					first = mocks.NewAuthorizer()
+					second = mocks.NewAuthorizer()
+					handler = handler.WithAuthorizers(first, second)
+				})
+
+				It("asks each of them about the client, kind and audience", func() {
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(first.AuthorizeCall.Receives.Request).To(Equal(notify.AuthorizationRequest{
+						ClientID: "mister-client",
+						Scopes:   []string{"notifications.write", "critical_notifications.write"},
+						Kind:     kind,
+						Audience: notify.Audience{
+							Type: "spaces",
+							ID:   "space-001",
+						},
+					}))
+					Expect(second.AuthorizeCall.CallCount).To(Equal(1))
+					Expect(strategy.DispatchCallsCount).To(Equal(1))
+				})
+
+				It("passes the role of a space or organization", func() {
+					request.URL.Path = "/spaces/space-001/developers"
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(first.AuthorizeCall.Receives.Request.Audience).To(Equal(notify.Audience{
+						Type: "spaces",
+						ID:   "space-001",
+						Role: "developers",
+					}))
+				})
+
+				It("refuses the request without registering or dispatching when one denies it", func() {
+					denial := webutil.AuthorizationDenial{Code: "ops_only", Reason: "Only operators may send this"}
+					first.AuthorizeCall.Returns.Error = denial
+
+					_, err := handler.Execute(conn, request, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).To(Equal(denial))
+
+					Expect(second.AuthorizeCall.CallCount).To(Equal(0))
+					Expect(registrar.RefreshCall.Receives.Connection).To(BeNil())
+					Expect(strategy.DispatchCallsCount).To(Equal(0))
+				})
+			})
+
+			Context("when the request has an Idempotency-Key header", func() {
+				var buildRequest func(path, body string) *http.Request
+
+				BeforeEach(func() {
+					buildRequest = func(path, body string) *http.Request {
+						req, err := http.NewRequest("POST", path, strings.NewReader(body))
+						Expect(err).NotTo(HaveOccurred())
+						req.Header.Set("Authorization", "Bearer "+rawToken)
+						req.Header.Set("Idempotency-Key", "retry-me")
+						return req
+					}
+
+					strategy.DispatchCalls = append(strategy.DispatchCalls, mocks.NewStrategyDispatchCall([]services.Response{
+						{NotificationID: "message-123", Status: "queued"},
+					}, nil))
+				})
+
+				It("records the response against the key", func() {
+					output, err := handler.Execute(conn, buildRequest("/spaces/space-001", `{"kind_id":"test_email","text":"hi"}`), context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(idempotencyKeys.FindCall.Receives.ClientID).To(Equal("mister-client"))
+					Expect(idempotencyKeys.FindCall.Receives.Key).To(Equal("retry-me"))
+
+					record := idempotencyKeys.UpsertCall.Receives.IdempotencyKey
+					Expect(idempotencyKeys.UpsertCall.Receives.Connection).To(Equal(conn))
+					Expect(record.ClientID).To(Equal("mister-client"))
+					Expect(record.Key).To(Equal("retry-me"))
+					Expect(record.RequestHash).To(HaveLen(64))
+					Expect(record.Response).To(Equal(string(output)))
+					Expect(record.CreatedAt).To(Equal(reqReceivedTime.UTC().Truncate(time.Second)))
+				})
+
+				It("returns the original response without dispatching again for a repeated request", func() {
+					original, err := handler.Execute(conn, buildRequest("/spaces/space-001", `{"kind_id":"test_email","text":"hi"}`), context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					idempotencyKeys.FindCall.Returns.IdempotencyKey = idempotencyKeys.UpsertCall.Receives.IdempotencyKey
+					idempotencyKeys.FindCall.Returns.Error = nil
+					idempotencyKeys.UpsertCall.WasCalled = false
+
+					repeated, err := handler.Execute(conn, buildRequest("/spaces/space-001", `{"kind_id":"test_email","text":"hi"}`), context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(repeated).To(Equal(original))
+
+					Expect(strategy.DispatchCallsCount).To(Equal(1))
+					Expect(idempotencyKeys.UpsertCall.WasCalled).To(BeFalse())
+				})
+
+				It("returns a duplicate error when the key was used for a different request", func() {
+					_, err := handler.Execute(conn, buildRequest("/spaces/space-001", `{"kind_id":"test_email","text":"hi"}`), context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+
+					idempotencyKeys.FindCall.Returns.IdempotencyKey = idempotencyKeys.UpsertCall.Receives.IdempotencyKey
+					idempotencyKeys.FindCall.Returns.Error = nil
+
+					_, err = handler.Execute(conn, buildRequest("/spaces/space-002", `{"kind_id":"test_email","text":"hi"}`), context, "space-002", strategy, validator, vcapRequestID)
+					Expect(err).To(BeAssignableToTypeOf(models.DuplicateError{}))
+					Expect(strategy.DispatchCallsCount).To(Equal(1))
+				})
+
+				It("dispatches again once the key is older than the window", func() {
+					idempotencyKeys.FindCall.Returns.IdempotencyKey = models.IdempotencyKey{
+						ClientID:  "mister-client",
+						Key:       "retry-me",
+						Response:  "[]",
+						CreatedAt: reqReceivedTime.Add(-25 * time.Hour),
+					}
+					idempotencyKeys.FindCall.Returns.Error = nil
+
+					_, err := handler.Execute(conn, buildRequest("/spaces/space-001", `{"kind_id":"test_email","text":"hi"}`), context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(strategy.DispatchCallsCount).To(Equal(1))
+					Expect(idempotencyKeys.UpsertCall.WasCalled).To(BeTrue())
+				})
+
+				It("ignores the header when the window is zero", func() {
+					handler = notify.NewNotify(finder, registrar, idempotencyKeys, 0, notify.HTMLPolicy{})
+
+					_, err := handler.Execute(conn, buildRequest("/spaces/space-001", `{"kind_id":"test_email","text":"hi"}`), context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(idempotencyKeys.FindCall.Receives.Key).To(BeEmpty())
+					Expect(idempotencyKeys.UpsertCall.WasCalled).To(BeFalse())
+				})
+
+				It("returns a validation error when the key is too long", func() {
+					req := buildRequest("/spaces/space-001", `{"kind_id":"test_email","text":"hi"}`)
+					req.Header.Set("Idempotency-Key", strings.Repeat("a", 256))
+
+					_, err := handler.Execute(conn, req, context, "space-001", strategy, validator, vcapRequestID)
+					Expect(err).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+					Expect(strategy.DispatchCallsCount).To(Equal(0))
+				})
 			})
 
 			Context("failure cases", func() {
@@ -212,7 +575,7 @@ var _ = Describe("Notify", func() {
 
 				Context("when the registrar returns errors", func() {
 					It("returns the error", func() {
-						registrar.RegisterCall.Returns.Error = errors.New("BOOM!")
+						registrar.RefreshCall.Returns.Error = errors.New("BOOM!")
 
 						_, err := handler.Execute(conn, request, context, "user-123", strategy, validator, vcapRequestID)
 						Expect(err).To(Equal(errors.New("BOOM!")))
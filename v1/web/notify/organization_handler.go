@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -21,10 +22,14 @@ func NewOrganizationHandler(notify notifyExecutor, errWriter errorWriter, strate
 	}
 }
 
+func (h OrganizationHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
 func (h OrganizationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	conn := context.Get("database").(DatabaseInterface).Connection()
 	orgGUID := strings.TrimPrefix(req.URL.Path, "/organizations/")
-	vcapRequestID := context.Get(VCAPRequestIDKey).(string)
+	vcapRequestID := webutil.VCAPRequestID(context)
 
 	output, err := h.notify.Execute(conn, req, context, orgGUID, h.strategy, GUIDValidator{}, vcapRequestID)
 	if err != nil {
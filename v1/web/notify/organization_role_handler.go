@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type OrganizationRoleHandler struct {
+	errorWriter errorWriter
+	notify      notifyExecutor
+	strategy    Dispatcher
+}
+
+func NewOrganizationRoleHandler(notify notifyExecutor, errWriter errorWriter, strategy Dispatcher) OrganizationRoleHandler {
+	return OrganizationRoleHandler{
+		errorWriter: errWriter,
+		notify:      notify,
+		strategy:    strategy,
+	}
+}
+
+func (h OrganizationRoleHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
+func (h OrganizationRoleHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	conn := context.Get("database").(DatabaseInterface).Connection()
+	orgGUID := strings.Split(strings.TrimPrefix(req.URL.Path, "/organizations/"), "/")[0]
+	vcapRequestID := webutil.VCAPRequestID(context)
+
+	output, err := h.notify.Execute(conn, req, context, orgGUID, h.strategy, GUIDValidator{}, vcapRequestID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
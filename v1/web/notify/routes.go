@@ -12,6 +12,13 @@ type Routes struct {
 	DatabaseAllocator               stack.Middleware
 	NotificationsWriteAuthenticator stack.Middleware
 	EmailsWriteAuthenticator        stack.Middleware
+	RateLimiter                     stack.Middleware
+	AnomalyDetector                 stack.Middleware
+
+	// AudienceWriteAuthenticator guards the routes whose recipients are
+	// looked up in the Cloud Controller and UAA of the deployment, which
+	// only the default zone may send to.
+	AudienceWriteAuthenticator stack.Middleware
 
 	Notify               notifyExecutor
 	ErrorWriter          errorWriter
@@ -20,14 +27,30 @@ type Routes struct {
 	OrganizationStrategy Dispatcher
 	EveryoneStrategy     Dispatcher
 	UAAScopeStrategy     Dispatcher
+	UAAGroupStrategy     Dispatcher
 	EmailStrategy        Dispatcher
+
+	OrganizationManagerStrategy        Dispatcher
+	OrganizationAuditorStrategy        Dispatcher
+	OrganizationBillingManagerStrategy Dispatcher
+
+	SpaceDeveloperStrategy Dispatcher
+	SpaceManagerStrategy   Dispatcher
+	SpaceAuditorStrategy   Dispatcher
 }
 
 func (r Routes) Register(m muxer) {
-	m.Handle("POST", "/users/{user_id}", NewUserHandler(r.Notify, r.ErrorWriter, r.UserStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("POST", "/spaces/{space_id}", NewSpaceHandler(r.Notify, r.ErrorWriter, r.SpaceStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("POST", "/organizations/{org_id}", NewOrganizationHandler(r.Notify, r.ErrorWriter, r.OrganizationStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("POST", "/everyone", NewEveryoneHandler(r.Notify, r.ErrorWriter, r.EveryoneStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("POST", "/uaa_scopes/{scope}", NewUAAScopeHandler(r.Notify, r.ErrorWriter, r.UAAScopeStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("POST", "/emails", NewEmailHandler(r.Notify, r.ErrorWriter, r.EmailStrategy), r.RequestLogging, r.RequestCounter, r.EmailsWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/users/{user_id}", NewUserHandler(r.Notify, r.ErrorWriter, r.UserStrategy), r.RequestLogging, r.RequestCounter, r.NotificationsWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/spaces/{space_id}", NewSpaceHandler(r.Notify, r.ErrorWriter, r.SpaceStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/spaces/{space_id}/developers", NewSpaceRoleHandler(r.Notify, r.ErrorWriter, r.SpaceDeveloperStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/spaces/{space_id}/managers", NewSpaceRoleHandler(r.Notify, r.ErrorWriter, r.SpaceManagerStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/spaces/{space_id}/auditors", NewSpaceRoleHandler(r.Notify, r.ErrorWriter, r.SpaceAuditorStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/organizations/{org_id}", NewOrganizationHandler(r.Notify, r.ErrorWriter, r.OrganizationStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/organizations/{org_id}/managers", NewOrganizationRoleHandler(r.Notify, r.ErrorWriter, r.OrganizationManagerStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/organizations/{org_id}/auditors", NewOrganizationRoleHandler(r.Notify, r.ErrorWriter, r.OrganizationAuditorStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/organizations/{org_id}/billing_managers", NewOrganizationRoleHandler(r.Notify, r.ErrorWriter, r.OrganizationBillingManagerStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/everyone", NewEveryoneHandler(r.Notify, r.ErrorWriter, r.EveryoneStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/uaa_scopes/{scope}", NewUAAScopeHandler(r.Notify, r.ErrorWriter, r.UAAScopeStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/groups/{group_name}", NewUAAGroupHandler(r.Notify, r.ErrorWriter, r.UAAGroupStrategy), r.RequestLogging, r.RequestCounter, r.AudienceWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
+	m.Handle("POST", "/emails", NewEmailHandler(r.Notify, r.ErrorWriter, r.EmailStrategy), r.RequestLogging, r.RequestCounter, r.EmailsWriteAuthenticator, r.RateLimiter, r.DatabaseAllocator, r.AnomalyDetector)
 }
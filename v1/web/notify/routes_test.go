@@ -27,13 +27,26 @@ var _ = Describe("Routes", func() {
 			OrganizationStrategy: mocks.NewStrategy(),
 			EveryoneStrategy:     mocks.NewStrategy(),
 			UAAScopeStrategy:     mocks.NewStrategy(),
+			UAAGroupStrategy:     mocks.NewStrategy(),
 			EmailStrategy:        mocks.NewStrategy(),
 
+			OrganizationManagerStrategy:        mocks.NewStrategy(),
+			OrganizationAuditorStrategy:        mocks.NewStrategy(),
+			OrganizationBillingManagerStrategy: mocks.NewStrategy(),
+
+			SpaceDeveloperStrategy: mocks.NewStrategy(),
+			SpaceManagerStrategy:   mocks.NewStrategy(),
+			SpaceAuditorStrategy:   mocks.NewStrategy(),
+
 			RequestCounter:                  middleware.RequestCounter{},
 			RequestLogging:                  middleware.RequestLogging{},
 			DatabaseAllocator:               middleware.DatabaseAllocator{},
 			NotificationsWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.write"}},
 			EmailsWriteAuthenticator:        middleware.Authenticator{Scopes: []string{"emails.write"}},
+			RateLimiter:                     middleware.RateLimiter{},
+			AnomalyDetector:                 middleware.AnomalyDetector{},
+
+			AudienceWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notifications.write"}, DefaultZoneOnly: true},
 		}.Register(muxer)
 	})
 
@@ -43,7 +56,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.UserHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
@@ -55,10 +68,50 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.SpaceHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	It("routes POST /spaces/{space_id}/developers", func() {
+		request, err := http.NewRequest("POST", "/spaces/{space_id}/developers", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.SpaceRoleHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	It("routes POST /spaces/{space_id}/managers", func() {
+		request, err := http.NewRequest("POST", "/spaces/{space_id}/managers", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.SpaceRoleHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	It("routes POST /spaces/{space_id}/auditors", func() {
+		request, err := http.NewRequest("POST", "/spaces/{space_id}/auditors", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.SpaceRoleHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
 	})
 
 	It("routes POST /organizations/{org_id}", func() {
@@ -67,10 +120,50 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.OrganizationHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	It("routes POST /organizations/{org_id}/managers", func() {
+		request, err := http.NewRequest("POST", "/organizations/{org_id}/managers", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.OrganizationRoleHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	It("routes POST /organizations/{org_id}/auditors", func() {
+		request, err := http.NewRequest("POST", "/organizations/{org_id}/auditors", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.OrganizationRoleHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	It("routes POST /organizations/{org_id}/billing_managers", func() {
+		request, err := http.NewRequest("POST", "/organizations/{org_id}/billing_managers", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.OrganizationRoleHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
 	})
 
 	It("routes POST /everyone", func() {
@@ -79,10 +172,11 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.EveryoneHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
 	})
 
 	It("routes POST /uaa_scopes/{scope}", func() {
@@ -91,10 +185,24 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.UAAScopeHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
+
+		authenticator := s.Middleware[2].(middleware.Authenticator)
+		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+	})
+
+	It("routes POST /groups/{group_name}", func() {
+		request, err := http.NewRequest("POST", "/groups/{group_name}", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		s := muxer.Match(request).(stack.Stack)
+		Expect(s.Handler).To(BeAssignableToTypeOf(notify.UAAGroupHandler{}))
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"notifications.write"}))
+		Expect(authenticator.DefaultZoneOnly).To(BeTrue())
 	})
 
 	It("routes POST /emails", func() {
@@ -103,7 +211,7 @@ var _ = Describe("Routes", func() {
 
 		s := muxer.Match(request).(stack.Stack)
 		Expect(s.Handler).To(BeAssignableToTypeOf(notify.EmailHandler{}))
-		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+		ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.RateLimiter{}, middleware.DatabaseAllocator{}, middleware.AnomalyDetector{})
 
 		authenticator := s.Middleware[2].(middleware.Authenticator)
 		Expect(authenticator.Scopes).To(Equal([]string{"emails.write"}))
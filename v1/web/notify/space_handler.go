@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -21,10 +22,14 @@ func NewSpaceHandler(notify notifyExecutor, errWriter errorWriter, strategy Disp
 	}
 }
 
+func (h SpaceHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
 func (h SpaceHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	conn := context.Get("database").(DatabaseInterface).Connection()
 	spaceGUID := strings.TrimPrefix(req.URL.Path, "/spaces/")
-	vcapRequestID := context.Get(VCAPRequestIDKey).(string)
+	vcapRequestID := webutil.VCAPRequestID(context)
 
 	output, err := h.notify.Execute(conn, req, context, spaceGUID, h.strategy, GUIDValidator{}, vcapRequestID)
 	if err != nil {
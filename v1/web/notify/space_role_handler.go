@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type SpaceRoleHandler struct {
+	errorWriter errorWriter
+	notify      notifyExecutor
+	strategy    Dispatcher
+}
+
+func NewSpaceRoleHandler(notify notifyExecutor, errWriter errorWriter, strategy Dispatcher) SpaceRoleHandler {
+	return SpaceRoleHandler{
+		errorWriter: errWriter,
+		notify:      notify,
+		strategy:    strategy,
+	}
+}
+
+func (h SpaceRoleHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
+func (h SpaceRoleHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	conn := context.Get("database").(DatabaseInterface).Connection()
+	spaceGUID := strings.Split(strings.TrimPrefix(req.URL.Path, "/spaces/"), "/")[0]
+	vcapRequestID := webutil.VCAPRequestID(context)
+
+	output, err := h.notify.Execute(conn, req, context, spaceGUID, h.strategy, GUIDValidator{}, vcapRequestID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
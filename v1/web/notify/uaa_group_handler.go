@@ -0,0 +1,42 @@
+package notify
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type UAAGroupHandler struct {
+	errorWriter errorWriter
+	notify      notifyExecutor
+	strategy    Dispatcher
+}
+
+func NewUAAGroupHandler(notify notifyExecutor, errWriter errorWriter, strategy Dispatcher) UAAGroupHandler {
+	return UAAGroupHandler{
+		errorWriter: errWriter,
+		notify:      notify,
+		strategy:    strategy,
+	}
+}
+
+func (h UAAGroupHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
+func (h UAAGroupHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	conn := context.Get("database").(DatabaseInterface).Connection()
+	group := strings.TrimPrefix(req.URL.Path, "/groups/")
+	vcapRequestID := webutil.VCAPRequestID(context)
+
+	output, err := h.notify.Execute(conn, req, context, group, h.strategy, GUIDValidator{}, vcapRequestID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	w.Write(output)
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -21,10 +22,14 @@ func NewUAAScopeHandler(notify notifyExecutor, errWriter errorWriter, strategy D
 	}
 }
 
+func (h UAAScopeHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
 func (h UAAScopeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	conn := context.Get("database").(DatabaseInterface).Connection()
 	scope := strings.TrimPrefix(req.URL.Path, "/uaa_scopes/")
-	vcapRequestID := context.Get(VCAPRequestIDKey).(string)
+	vcapRequestID := webutil.VCAPRequestID(context)
 
 	output, err := h.notify.Execute(conn, req, context, scope, h.strategy, GUIDValidator{}, vcapRequestID)
 	if err != nil {
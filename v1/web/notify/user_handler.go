@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -21,10 +22,14 @@ func NewUserHandler(notify notifyExecutor, errWriter errorWriter, strategy Dispa
 	}
 }
 
+func (h UserHandler) RequestBody() interface{} {
+	return NotifyParams{}
+}
+
 func (h UserHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	conn := context.Get("database").(DatabaseInterface).Connection()
 	userGUID := strings.TrimPrefix(req.URL.Path, "/users/")
-	vcapRequestID := context.Get(VCAPRequestIDKey).(string)
+	vcapRequestID := webutil.VCAPRequestID(context)
 
 	output, err := h.notify.Execute(conn, req, context, userGUID, h.strategy, GUIDValidator{}, vcapRequestID)
 	if err != nil {
@@ -0,0 +1,101 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	DefaultExportLimit = 100
+	MaxExportLimit     = 1000
+)
+
+type preferencesPorter interface {
+	Export(conn models.ConnectionInterface, userID string) (services.PreferencesExport, error)
+	ExportAll(conn models.ConnectionInterface, after string, limit int) ([]services.PreferencesExport, error)
+	Import(conn models.ConnectionInterface, exports []services.PreferencesExport) error
+}
+
+type ExportPreferencesHandler struct {
+	porter      preferencesPorter
+	errorWriter errorWriter
+}
+
+func NewExportPreferencesHandler(porter preferencesPorter, errWriter errorWriter) ExportPreferencesHandler {
+	return ExportPreferencesHandler{
+		porter:      porter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ExportPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	userID, ok := context.Get("token").(*jwt.Token).Claims["user_id"].(string)
+	if !ok {
+		h.errorWriter.Write(w, webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")})
+		return
+	}
+
+	export, err := h.porter.Export(context.Get("database").(DatabaseInterface).ReadConnection(), userID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, export)
+}
+
+// ExportAllPreferencesHandler pages through the preferences of every user
+// who has stored any, for moving them to another deployment.
+type ExportAllPreferencesHandler struct {
+	porter      preferencesPorter
+	errorWriter errorWriter
+}
+
+func NewExportAllPreferencesHandler(porter preferencesPorter, errWriter errorWriter) ExportAllPreferencesHandler {
+	return ExportAllPreferencesHandler{
+		porter:      porter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ExportAllPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	query := req.URL.Query()
+
+	limit := DefaultExportLimit
+	if value := query.Get("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > MaxExportLimit {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf(`"limit" must be an integer between 1 and %d`, MaxExportLimit)})
+			return
+		}
+	}
+
+	exports, err := h.porter.ExportAll(context.Get("database").(DatabaseInterface).ReadConnection(), query.Get("after"), limit)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	document := struct {
+		Users []services.PreferencesExport `json:"users"`
+		Next  string                       `json:"next,omitempty"`
+	}{
+		Users: exports,
+	}
+
+	// A short page is the last one; a full page may be followed by more.
+	if len(exports) == limit {
+		document.Next = exports[len(exports)-1].UserID
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, document)
+}
@@ -0,0 +1,160 @@
+package preferences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Exporting preferences", func() {
+	var (
+		porter      *mocks.PreferencesPorter
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	tokenFor := func(claims map[string]interface{}) *jwt.Token {
+		claims["exp"] = int64(3404281214)
+		token, err := jwt.Parse(helpers.BuildToken(map[string]interface{}{"alg": "RS256"}, claims), func(*jwt.Token) (interface{}, error) {
+			return []byte(helpers.UAAPublicKey), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		return token
+	}
+
+	BeforeEach(func() {
+		porter = mocks.NewPreferencesPorter()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ReadConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+	})
+
+	Describe("ExportPreferencesHandler", func() {
+		var handler preferences.ExportPreferencesHandler
+
+		BeforeEach(func() {
+			handler = preferences.NewExportPreferencesHandler(porter, errorWriter)
+			context.Set("token", tokenFor(map[string]interface{}{"user_id": "user-123"}))
+		})
+
+		It("exports the preferences of the user the token was issued to", func() {
+			porter.ExportCall.Returns.Export = services.PreferencesExport{
+				UserID:        "user-123",
+				Digest:        "daily",
+				Unsubscribes:  []services.PreferenceKind{{ClientID: "raptors", KindID: "sleepy"}},
+				Subscriptions: []services.PreferenceKind{},
+			}
+
+			request, err := http.NewRequest("GET", "/user_preferences/export", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"user_id": "user-123",
+				"global_unsubscribe": false,
+				"digest": "daily",
+				"unsubscribes": [{"client_id": "raptors", "kind_id": "sleepy"}],
+				"subscriptions": []
+			}`))
+			Expect(porter.ExportCall.Receives.Connection).To(Equal(connection))
+			Expect(porter.ExportCall.Receives.UserID).To(Equal("user-123"))
+		})
+
+		It("requires a user token", func() {
+			context.Set("token", tokenFor(map[string]interface{}{"client_id": "some-client"}))
+			request, err := http.NewRequest("GET", "/user_preferences/export", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.MissingUserTokenError{}))
+		})
+
+		It("writes errors from the porter", func() {
+			porter.ExportCall.Returns.Error = errors.New("database is down")
+			request, err := http.NewRequest("GET", "/user_preferences/export", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+		})
+	})
+
+	Describe("ExportAllPreferencesHandler", func() {
+		var handler preferences.ExportAllPreferencesHandler
+
+		BeforeEach(func() {
+			handler = preferences.NewExportAllPreferencesHandler(porter, errorWriter)
+			context.Set("token", tokenFor(map[string]interface{}{"client_id": "some-client"}))
+		})
+
+		It("returns a page of users and where the next one starts", func() {
+			porter.ExportAllCall.Returns.Exports = []services.PreferencesExport{
+				{UserID: "user-456", Digest: "immediate", Unsubscribes: []services.PreferenceKind{}, Subscriptions: []services.PreferenceKind{}},
+				{UserID: "user-789", GlobalUnsubscribe: true, Digest: "immediate", Unsubscribes: []services.PreferenceKind{}, Subscriptions: []services.PreferenceKind{}},
+			}
+
+			request, err := http.NewRequest("GET", "/admin/preferences/export?after=user-123&limit=2", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(MatchJSON(`{
+				"users": [
+					{"user_id": "user-456", "global_unsubscribe": false, "digest": "immediate", "unsubscribes": [], "subscriptions": []},
+					{"user_id": "user-789", "global_unsubscribe": true, "digest": "immediate", "unsubscribes": [], "subscriptions": []}
+				],
+				"next": "user-789"
+			}`))
+			Expect(porter.ExportAllCall.Receives.Connection).To(Equal(connection))
+			Expect(porter.ExportAllCall.Receives.After).To(Equal("user-123"))
+			Expect(porter.ExportAllCall.Receives.Limit).To(Equal(2))
+		})
+
+		It("leaves out the next user on the last page", func() {
+			porter.ExportAllCall.Returns.Exports = []services.PreferencesExport{}
+
+			request, err := http.NewRequest("GET", "/admin/preferences/export", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Body.String()).To(MatchJSON(`{"users": []}`))
+			Expect(porter.ExportAllCall.Receives.After).To(BeEmpty())
+			Expect(porter.ExportAllCall.Receives.Limit).To(Equal(preferences.DefaultExportLimit))
+		})
+
+		It("rejects a limit out of range", func() {
+			request, err := http.NewRequest("GET", "/admin/preferences/export?limit=1001", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`"limit" must be an integer between 1 and 1000`)}))
+		})
+	})
+})
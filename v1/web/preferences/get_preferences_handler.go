@@ -46,5 +46,8 @@ func (h GetPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	writeJSON(w, http.StatusOK, parsed)
+	parsed = parsed.InZone(webutil.ZoneID(context))
+	parsed.Localize(webutil.AcceptedLocales(req.Header.Get("Accept-Language")))
+
+	webutil.WriteJSON(w, http.StatusOK, parsed)
 }
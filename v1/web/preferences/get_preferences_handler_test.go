@@ -112,6 +112,48 @@ var _ = Describe("GetPreferencesHandler", func() {
 		Expect(parsed.Clients["starWarsClient"]["vader-kind"].Email).To(Equal(&TRUE))
 	})
 
+	It("shows only the clients of the zone of the token, under the IDs the zone knows", func() {
+		builder.Add(models.Preference{
+			ClientID: "tenant-a:raptorClient",
+			KindID:   "tenant-kind",
+			Email:    true,
+		})
+		context.Set(webutil.ZoneIDKey, "tenant-a")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+
+		parsed := services.PreferencesBuilder{}
+		err := json.Unmarshal(writer.Body.Bytes(), &parsed)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(parsed.Clients).To(HaveLen(1))
+		Expect(parsed.Clients["raptorClient"]).To(HaveKey("tenant-kind"))
+		Expect(parsed.Clients["raptorClient"]).NotTo(HaveKey("hungry-kind"))
+	})
+
+	It("describes kinds in the most preferred language they are translated into", func() {
+		builder.Add(models.Preference{
+			ClientID:              "raptorClient",
+			KindID:                "sleepy-kind",
+			KindDescription:       "sleepy raptors",
+			LocalizedDescriptions: models.LocalizedDescriptions{"es": "raptores dormidos", "fr": "raptors endormis"},
+		})
+		request.Header.Set("Accept-Language", "de, fr-FR;q=0.9, es;q=0.8")
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+
+		parsed := services.PreferencesBuilder{}
+		err := json.Unmarshal(writer.Body.Bytes(), &parsed)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(parsed.Clients["raptorClient"]["sleepy-kind"].KindDescription).To(Equal("raptors endormis"))
+		Expect(parsed.Clients["raptorClient"]["hungry-kind"].KindDescription).To(Equal("hungry-kind"))
+	})
+
 	Context("when there is an error returned from the finder", func() {
 		It("writes the error to the error writer", func() {
 			preferencesFinder.FindCall.Returns.Error = errors.New("boom!")
@@ -0,0 +1,143 @@
+package preferences
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+const (
+	DefaultUserMessagesPerPage = 50
+	MaxUserMessagesPerPage     = 500
+)
+
+type userMessagesFinder interface {
+	List(conn models.ConnectionInterface, filter models.UserMessageFilter, offset, limit int) ([]models.UserMessage, error)
+	Count(conn models.ConnectionInterface, filter models.UserMessageFilter) (int, error)
+}
+
+type userMessage struct {
+	MessageID         string    `json:"message_id"`
+	ClientID          string    `json:"client_id"`
+	KindID            string    `json:"kind_id"`
+	SourceDescription string    `json:"source_description"`
+	KindDescription   string    `json:"kind_description"`
+	Subject           string    `json:"subject"`
+	Severity          string    `json:"severity,omitempty"`
+	RequestReceived   time.Time `json:"request_received"`
+	DeliveredAt       time.Time `json:"delivered_at"`
+}
+
+type GetUserMessagesHandler struct {
+	messages    userMessagesFinder
+	errorWriter errorWriter
+}
+
+func NewGetUserMessagesHandler(messages userMessagesFinder, errWriter errorWriter) GetUserMessagesHandler {
+	return GetUserMessagesHandler{
+		messages:    messages,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetUserMessagesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	token := context.Get("token").(*jwt.Token)
+
+	if _, ok := token.Claims["user_id"]; !ok {
+		h.errorWriter.Write(w, webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")})
+		return
+	}
+
+	query := req.URL.Query()
+	filter := models.UserMessageFilter{
+		UserGUID: token.Claims["user_id"].(string),
+		ZoneID:   webutil.ZoneID(context),
+	}
+
+	if since := query.Get("since"); since != "" {
+		var err error
+		filter.Since, err = time.Parse(time.RFC3339, since)
+		if err != nil {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"since" must be an RFC 3339 timestamp, such as "2015-03-20T12:00:00Z"`)})
+			return
+		}
+	}
+
+	page, err := positiveIntParam(query.Get("page"), 1)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"page" must be a positive integer`)})
+		return
+	}
+
+	perPage, err := positiveIntParam(query.Get("per_page"), DefaultUserMessagesPerPage)
+	if err != nil || perPage > MaxUserMessagesPerPage {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf(`"per_page" must be an integer between 1 and %d`, MaxUserMessagesPerPage)})
+		return
+	}
+
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	total, err := h.messages.Count(connection, filter)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	messages, err := h.messages.List(connection, filter, (page-1)*perPage, perPage)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	response := struct {
+		Messages []userMessage `json:"messages"`
+		Total    int           `json:"total"`
+		Page     int           `json:"page"`
+		PerPage  int           `json:"per_page"`
+	}{
+		Messages: []userMessage{},
+		Total:    total,
+		Page:     page,
+		PerPage:  perPage,
+	}
+
+	for _, message := range messages {
+		response.Messages = append(response.Messages, userMessage{
+			MessageID:         message.MessageID,
+			ClientID:          models.UnzonedClientID(filter.ZoneID, message.ClientID),
+			KindID:            message.KindID,
+			SourceDescription: message.SourceDescription,
+			KindDescription:   message.KindDescription,
+			Subject:           message.Subject,
+			Severity:          message.Severity,
+			RequestReceived:   message.RequestReceived,
+			DeliveredAt:       message.DeliveredAt,
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, response)
+}
+
+func positiveIntParam(value string, defaultValue int) (int, error) {
+	if value == "" {
+		return defaultValue, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if n < 1 {
+		return 0, errors.New("must be positive")
+	}
+
+	return n, nil
+}
@@ -0,0 +1,187 @@
+package preferences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("GetUserMessagesHandler", func() {
+	var (
+		handler      preferences.GetUserMessagesHandler
+		writer       *httptest.ResponseRecorder
+		request      *http.Request
+		messagesRepo *mocks.UserMessagesRepo
+		errorWriter  *mocks.ErrorWriter
+		connection   *mocks.Connection
+		context      stack.Context
+	)
+
+	buildToken := func(claims map[string]interface{}) *jwt.Token {
+		token, err := jwt.Parse(helpers.BuildToken(map[string]interface{}{
+			"alg": "RS256",
+		}, claims), func(token *jwt.Token) (interface{}, error) {
+			return []byte(helpers.UAAPublicKey), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		return token
+	}
+
+	BeforeEach(func() {
+		var err error
+		request, err = http.NewRequest("GET", "/user_messages", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("token", buildToken(map[string]interface{}{
+			"user_id": "correct-user",
+			"exp":     int64(3404281214),
+			"scope":   []string{"notification_preferences.read"},
+		}))
+		context.Set("database", database)
+
+		messagesRepo = mocks.NewUserMessagesRepo()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = preferences.NewGetUserMessagesHandler(messagesRepo, errorWriter)
+	})
+
+	It("lists the messages sent to the user in the token", func() {
+		messagesRepo.CountCall.Returns.Count = 1
+		messagesRepo.ListCall.Returns.Messages = []models.UserMessage{
+			{
+				MessageID:         "message-123",
+				UserGUID:          "correct-user",
+				ClientID:          "raptors",
+				KindID:            "feeding-time",
+				Subject:           "Dinner is served",
+				Severity:          "warning",
+				SourceDescription: "Raptor Enclosure",
+				KindDescription:   "Feeding Time",
+				RequestReceived:   time.Date(2015, 6, 1, 12, 0, 0, 0, time.UTC),
+				DeliveredAt:       time.Date(2015, 6, 1, 12, 0, 5, 0, time.UTC),
+			},
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{
+			"messages": [
+				{
+					"message_id": "message-123",
+					"client_id": "raptors",
+					"kind_id": "feeding-time",
+					"source_description": "Raptor Enclosure",
+					"kind_description": "Feeding Time",
+					"subject": "Dinner is served",
+					"severity": "warning",
+					"request_received": "2015-06-01T12:00:00Z",
+					"delivered_at": "2015-06-01T12:00:05Z"
+				}
+			],
+			"total": 1,
+			"page": 1,
+			"per_page": 50
+		}`))
+
+		Expect(messagesRepo.ListCall.Receives.Connection).To(Equal(connection))
+		Expect(messagesRepo.ListCall.Receives.Filter).To(Equal(models.UserMessageFilter{UserGUID: "correct-user", ZoneID: models.DefaultZoneID}))
+		Expect(messagesRepo.ListCall.Receives.Offset).To(Equal(0))
+		Expect(messagesRepo.ListCall.Receives.Limit).To(Equal(50))
+		Expect(messagesRepo.CountCall.Receives.Filter).To(Equal(models.UserMessageFilter{UserGUID: "correct-user", ZoneID: models.DefaultZoneID}))
+	})
+
+	It("pages through the messages delivered since a time", func() {
+		request, err := http.NewRequest("GET", "/user_messages?since=2015-06-01T00:00:00Z&page=3&per_page=20", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(messagesRepo.ListCall.Receives.Filter).To(Equal(models.UserMessageFilter{
+			UserGUID: "correct-user",
+			Since:    time.Date(2015, 6, 1, 0, 0, 0, 0, time.UTC),
+			ZoneID:   models.DefaultZoneID,
+		}))
+		Expect(messagesRepo.ListCall.Receives.Offset).To(Equal(40))
+		Expect(messagesRepo.ListCall.Receives.Limit).To(Equal(20))
+	})
+
+	It("narrows the messages to the zone of the request", func() {
+		context.Set(webutil.ZoneIDKey, "zone-1")
+		messagesRepo.ListCall.Returns.Messages = []models.UserMessage{
+			{MessageID: "message-123", ClientID: "zone-1:raptors"},
+		}
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(messagesRepo.ListCall.Receives.Filter.ZoneID).To(Equal("zone-1"))
+		Expect(writer.Body).To(ContainSubstring(`"client_id":"raptors"`))
+	})
+
+	DescribeTable("rejects invalid paging parameters",
+		func(query, message string) {
+			request, err := http.NewRequest("GET", "/user_messages?"+query, nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(message)}))
+			Expect(messagesRepo.ListCall.Receives.Connection).To(BeNil())
+		},
+		Entry("since", "since=yesterday", `"since" must be an RFC 3339 timestamp, such as "2015-03-20T12:00:00Z"`),
+		Entry("page", "page=0", `"page" must be a positive integer`),
+		Entry("per_page", "per_page=501", `"per_page" must be an integer between 1 and 500`),
+	)
+
+	It("returns an empty list when the user has no messages", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body).To(MatchJSON(`{"messages": [], "total": 0, "page": 1, "per_page": 50}`))
+	})
+
+	Context("when the repo returns an error", func() {
+		It("writes the error to the error writer", func() {
+			messagesRepo.ListCall.Returns.Error = errors.New("boom!")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("boom!")))
+		})
+	})
+
+	Context("when the token does not contain a user_id claim", func() {
+		It("writes a MissingUserTokenError to the error writer", func() {
+			context.Set("token", buildToken(map[string]interface{}{
+				"client_id": "some-client",
+				"exp":       int64(3404281214),
+				"scope":     []string{"notification_preferences.read"},
+			}))
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")}))
+			Expect(messagesRepo.ListCall.Receives.Connection).To(BeNil())
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -28,5 +29,8 @@ func (h GetUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Re
 		return
 	}
 
-	writeJSON(w, http.StatusOK, parsed)
+	parsed = parsed.InZone(webutil.ZoneID(context))
+	parsed.Localize(webutil.AcceptedLocales(req.Header.Get("Accept-Language")))
+
+	webutil.WriteJSON(w, http.StatusOK, parsed)
 }
@@ -96,6 +96,21 @@ var _ = Describe("GetUserPreferencesHandler", func() {
 			}`))
 		})
 
+		It("describes kinds in the language the request accepts", func() {
+			builder.Add(models.Preference{
+				ClientID:              "starWarsClient",
+				KindID:                "vader-kind",
+				Email:                 true,
+				LocalizedDescriptions: models.LocalizedDescriptions{"de": "Vader-Art"},
+			})
+			request.Header.Set("Accept-Language", "de-AT")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(builder.Clients["starWarsClient"]["vader-kind"].KindDescription).To(Equal("Vader-Art"))
+		})
+
 		Context("when the finder returns an error", func() {
 			It("writes the error to the error writer", func() {
 				preferencesFinder.FindCall.Returns.Error = errors.New("wow!!")
@@ -0,0 +1,116 @@
+package preferences
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+type ImportPreferencesHandler struct {
+	porter      preferencesPorter
+	errorWriter errorWriter
+}
+
+func NewImportPreferencesHandler(porter preferencesPorter, errWriter errorWriter) ImportPreferencesHandler {
+	return ImportPreferencesHandler{
+		porter:      porter,
+		errorWriter: errWriter,
+	}
+}
+
+// ServeHTTP replaces the preferences of the user the token was issued to.
+// The user_id in the document is ignored, so that users can carry their
+// preferences to a deployment where they have another ID.
+func (h ImportPreferencesHandler) RequestBody() interface{} {
+	return services.PreferencesExport{}
+}
+
+func (h ImportPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	userID, ok := context.Get("token").(*jwt.Token).Claims["user_id"].(string)
+	if !ok {
+		h.errorWriter.Write(w, webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")})
+		return
+	}
+
+	var export services.PreferencesExport
+	err := json.NewDecoder(req.Body).Decode(&export)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+	export.UserID = userID
+
+	err = importPreferences(h.porter, context, []services.PreferencesExport{export})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ImportAllPreferencesHandler replaces the preferences of every user in a
+// document exported by ExportAllPreferencesHandler, in one transaction.
+type ImportAllPreferencesHandler struct {
+	porter      preferencesPorter
+	errorWriter errorWriter
+}
+
+func NewImportAllPreferencesHandler(porter preferencesPorter, errWriter errorWriter) ImportAllPreferencesHandler {
+	return ImportAllPreferencesHandler{
+		porter:      porter,
+		errorWriter: errWriter,
+	}
+}
+
+type importAllPreferencesDocument struct {
+	Users []services.PreferencesExport `json:"users"`
+}
+
+func (h ImportAllPreferencesHandler) RequestBody() interface{} {
+	return importAllPreferencesDocument{}
+}
+
+func (h ImportAllPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	var document importAllPreferencesDocument
+	err := json.NewDecoder(req.Body).Decode(&document)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	err = importPreferences(h.porter, context, document.Users)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, map[string]int{"users": len(document.Users)})
+}
+
+func importPreferences(porter preferencesPorter, context stack.Context, exports []services.PreferencesExport) error {
+	transaction := context.Get("database").(DatabaseInterface).Connection().Transaction()
+	err := transaction.Begin()
+	if err != nil {
+		return err
+	}
+
+	err = porter.Import(transaction, exports)
+	if err != nil {
+		transaction.Rollback()
+		return err
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		return models.TransactionCommitError{Err: err}
+	}
+
+	return nil
+}
@@ -0,0 +1,148 @@
+package preferences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Importing preferences", func() {
+	var (
+		porter      *mocks.PreferencesPorter
+		errorWriter *mocks.ErrorWriter
+		transaction *mocks.Transaction
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		porter = mocks.NewPreferencesPorter()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		transaction = mocks.NewTransaction()
+		connection := mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		token, err := jwt.Parse(helpers.BuildToken(map[string]interface{}{
+			"alg": "RS256",
+		}, map[string]interface{}{
+			"user_id": "user-123",
+			"exp":     int64(3404281214),
+		}), func(*jwt.Token) (interface{}, error) {
+			return []byte(helpers.UAAPublicKey), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		context = stack.NewContext()
+		context.Set("token", token)
+		context.Set("database", database)
+	})
+
+	Describe("ImportPreferencesHandler", func() {
+		var handler preferences.ImportPreferencesHandler
+
+		BeforeEach(func() {
+			handler = preferences.NewImportPreferencesHandler(porter, errorWriter)
+		})
+
+		It("replaces the preferences of the user the token was issued to", func() {
+			request, err := http.NewRequest("PUT", "/user_preferences/import", strings.NewReader(`{
+				"user_id": "someone-else",
+				"global_unsubscribe": true,
+				"digest": "hourly",
+				"unsubscribes": [{"client_id": "raptors", "kind_id": "sleepy"}]
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(porter.ImportCall.Receives.Connection).To(Equal(transaction))
+			Expect(porter.ImportCall.Receives.Exports).To(Equal([]services.PreferencesExport{{
+				UserID:            "user-123",
+				GlobalUnsubscribe: true,
+				Digest:            "hourly",
+				Unsubscribes:      []services.PreferenceKind{{ClientID: "raptors", KindID: "sleepy"}},
+			}}))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		It("rejects a body that is not JSON", func() {
+			request, err := http.NewRequest("PUT", "/user_preferences/import", strings.NewReader("banana"))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+			Expect(porter.ImportCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Describe("ImportAllPreferencesHandler", func() {
+		var handler preferences.ImportAllPreferencesHandler
+
+		BeforeEach(func() {
+			handler = preferences.NewImportAllPreferencesHandler(porter, errorWriter)
+		})
+
+		It("replaces the preferences of every user in one transaction", func() {
+			request, err := http.NewRequest("PUT", "/admin/preferences/import", strings.NewReader(`{
+				"users": [
+					{"user_id": "user-456", "digest": "daily"},
+					{"user_id": "user-789", "subscriptions": [{"client_id": "newsletters", "kind_id": "weekly"}]}
+				],
+				"next": "user-789"
+			}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(MatchJSON(`{"users": 2}`))
+			Expect(porter.ImportCall.Receives.Connection).To(Equal(transaction))
+			Expect(porter.ImportCall.Receives.Exports).To(Equal([]services.PreferencesExport{
+				{UserID: "user-456", Digest: "daily"},
+				{UserID: "user-789", Subscriptions: []services.PreferenceKind{{ClientID: "newsletters", KindID: "weekly"}}},
+			}))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		It("rolls back when the import is invalid", func() {
+			porter.ImportCall.Returns.Error = services.PreferencesImportError{Err: errors.New("user_id is required")}
+			request, err := http.NewRequest("PUT", "/admin/preferences/import", strings.NewReader(`{"users": [{}]}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(services.PreferencesImportError{Err: errors.New("user_id is required")}))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+
+		It("writes a transaction commit error when the commit fails", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+			request, err := http.NewRequest("PUT", "/admin/preferences/import", strings.NewReader(`{"users": []}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.TransactionCommitError{Err: errors.New("commit failed")}))
+		})
+	})
+})
@@ -0,0 +1,56 @@
+package preferences
+
+import (
+	"net/url"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/rcrowley/go-metrics"
+	"github.com/ryanmoran/stack"
+)
+
+type preferenceChangeNotifier interface {
+	Notify(conn services.ConnectionInterface, change services.PreferenceChange) error
+}
+
+type preferenceChanges interface {
+	preferenceChangeNotifier
+	preferenceReverter
+}
+
+func notifyPreferenceChange(notifier preferenceChangeNotifier, conn services.ConnectionInterface, context stack.Context,
+	userGUID string, previous services.PreferencesBuilder, preferences []models.Preference, globalUnsubscribe bool) {
+
+	token := context.Get("token").(*jwt.Token)
+	clientID, _ := token.Claims["client_id"].(string)
+	vcapRequestID := webutil.VCAPRequestID(context)
+	requestReceived, _ := context.Get("request_received_time").(time.Time)
+
+	var uaaHost string
+	if issuer, ok := token.Claims["iss"].(string); ok {
+		if issuerURL, err := url.Parse(issuer); err == nil {
+			uaaHost = issuerURL.Scheme + "://" + issuerURL.Host
+		}
+	}
+
+	// The preferences are already saved, so a failure to tell the user about
+	// them must not fail the request.
+	err := notifier.Notify(conn, services.PreferenceChange{
+		UserGUID:          userGUID,
+		ClientID:          clientID,
+		UAAHost:           uaaHost,
+		Previous:          previous,
+		Preferences:       preferences,
+		GlobalUnsubscribe: globalUnsubscribe,
+		VCAPRequest: services.DispatchVCAPRequest{
+			ID:          vcapRequestID,
+			ReceiptTime: requestReceived,
+		},
+	})
+	if err != nil {
+		metrics.GetOrRegisterCounter("notifications.preferences.change_notification.failed", nil).Inc(1)
+	}
+}
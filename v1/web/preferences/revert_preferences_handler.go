@@ -0,0 +1,108 @@
+package preferences
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/ryanmoran/stack"
+)
+
+type preferenceReverter interface {
+	Verify(token string) error
+	Revert(conn services.ConnectionInterface, token string) error
+}
+
+const revertConfirmationPage = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Undo notification preference change</title>
+</head>
+<body>
+<p>Your notification preferences were recently changed. If you did not make this change, you can restore your earlier preferences.</p>
+<form method="post">
+<button type="submit">Restore my preferences</button>
+</form>
+</body>
+</html>
+`
+
+// ConfirmRevertPreferencesHandler serves the link emailed to users when
+// their preferences change. Mail scanners follow links, so it only asks the
+// user to confirm; the form posts back to RevertPreferencesHandler.
+type ConfirmRevertPreferencesHandler struct {
+	reverter    preferenceReverter
+	errorWriter errorWriter
+}
+
+func NewConfirmRevertPreferencesHandler(reverter preferenceReverter, errWriter errorWriter) ConfirmRevertPreferencesHandler {
+	return ConfirmRevertPreferencesHandler{
+		reverter:    reverter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ConfirmRevertPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	err := h.reverter.Verify(revertToken(req))
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("Referrer-Policy", "no-referrer")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(revertConfirmationPage))
+}
+
+// RevertPreferencesHandler restores the preferences described by a revert
+// token. It is reached from an email client, so it needs no token: the
+// revert token in the path is the credential.
+type RevertPreferencesHandler struct {
+	reverter    preferenceReverter
+	errorWriter errorWriter
+}
+
+func NewRevertPreferencesHandler(reverter preferenceReverter, errWriter errorWriter) RevertPreferencesHandler {
+	return RevertPreferencesHandler{
+		reverter:    reverter,
+		errorWriter: errWriter,
+	}
+}
+
+func (h RevertPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	token := revertToken(req)
+
+	transaction := connection.Transaction()
+	err := transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.reverter.Revert(transaction, token)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("Your notification preferences have been restored.\n"))
+}
+
+func revertToken(req *http.Request) string {
+	return regexp.MustCompile(".*/user_preferences/revert/(.*)").FindStringSubmatch(req.URL.Path)[1]
+}
@@ -0,0 +1,138 @@
+package preferences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConfirmRevertPreferencesHandler", func() {
+	var (
+		handler     preferences.ConfirmRevertPreferencesHandler
+		reverter    *mocks.PreferenceChangeNotifier
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		var err error
+		request, err = http.NewRequest("GET", "/user_preferences/revert/some-revert-token=", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		reverter = mocks.NewPreferenceChangeNotifier()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = preferences.NewConfirmRevertPreferencesHandler(reverter, errorWriter)
+	})
+
+	It("asks the user to confirm the revert without making it", func() {
+		handler.ServeHTTP(writer, request, stack.NewContext())
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Header().Get("Content-Type")).To(Equal("text/html; charset=utf-8"))
+		Expect(writer.Header().Get("Cache-Control")).To(Equal("no-store"))
+		Expect(writer.Body.String()).To(ContainSubstring(`<form method="post">`))
+
+		Expect(reverter.VerifyCall.Receives.Token).To(Equal("some-revert-token="))
+		Expect(reverter.RevertCall.Receives.Token).To(BeEmpty())
+	})
+
+	Context("when the token cannot be used", func() {
+		It("writes the error", func() {
+			reverter.VerifyCall.Returns.Error = services.PreferenceRevertError{Err: errors.New("The revert link has expired")}
+
+			handler.ServeHTTP(writer, request, stack.NewContext())
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(services.PreferenceRevertError{Err: errors.New("The revert link has expired")}))
+			Expect(writer.Body.String()).To(BeEmpty())
+		})
+	})
+})
+
+var _ = Describe("RevertPreferencesHandler", func() {
+	var (
+		handler     preferences.RevertPreferencesHandler
+		reverter    *mocks.PreferenceChangeNotifier
+		errorWriter *mocks.ErrorWriter
+		transaction *mocks.Transaction
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		transaction = mocks.NewTransaction()
+		connection := mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		var err error
+		request, err = http.NewRequest("POST", "/user_preferences/revert/some-revert-token=", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		reverter = mocks.NewPreferenceChangeNotifier()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = preferences.NewRevertPreferencesHandler(reverter, errorWriter)
+	})
+
+	It("reverts the preferences described by the token", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(Equal("Your notification preferences have been restored.\n"))
+
+		Expect(reverter.RevertCall.Receives.Connection).To(Equal(transaction))
+		Expect(reverter.RevertCall.Receives.Token).To(Equal("some-revert-token="))
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+	})
+
+	Context("when the token cannot be used", func() {
+		It("rolls back and writes the error", func() {
+			reverter.RevertCall.Returns.Error = services.PreferenceRevertError{Err: errors.New("The revert link has expired")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(services.PreferenceRevertError{Err: errors.New("The revert link has expired")}))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be started", func() {
+		It("writes the error", func() {
+			transaction.BeginCall.Returns.Error = errors.New("connection refused")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("connection refused")))
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be committed", func() {
+		It("writes a transaction commit error", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.TransactionCommitError{Err: errors.New("commit failed")}))
+		})
+	})
+})
@@ -12,6 +12,8 @@ type muxer interface {
 
 type preferenceUpdater interface {
 	Update(connection services.ConnectionInterface, preferences []models.Preference, globallyUnsubscribe bool, userID string) error
+	SetDigest(connection services.ConnectionInterface, userID, frequency string) error
+	SetSeverities(connection services.ConnectionInterface, userID string, severities map[string]bool) error
 }
 
 type Routes struct {
@@ -23,16 +25,50 @@ type Routes struct {
 	NotificationPreferencesAdminAuthenticator stack.Middleware
 	NotificationPreferencesWriteAuthenticator stack.Middleware
 
-	ErrorWriter       errorWriter
-	PreferencesFinder preferencesFinder
-	PreferenceUpdater preferenceUpdater
+	// DefaultZonePreferencesAdminAuthenticator guards the routes that act on
+	// the preferences of every user, which only the default zone may use.
+	DefaultZonePreferencesAdminAuthenticator stack.Middleware
+
+	ErrorWriter        errorWriter
+	PreferencesFinder  preferencesFinder
+	PreferenceUpdater  preferenceUpdater
+	UserMessagesFinder userMessagesFinder
+	Subscriber         subscriber
+	PreferencesPorter  preferencesPorter
+
+	// PreferenceChanges is only set when users should be emailed about
+	// changes to their preferences.
+	PreferenceChanges preferenceChanges
+
+	// OneClickUnsubscriber is only set when delivered mail carries a
+	// List-Unsubscribe link.
+	OneClickUnsubscriber oneClickUnsubscriber
 }
 
 func (r Routes) Register(m muxer) {
 	m.Handle("OPTIONS", "/user_preferences", NewOptionsHandler(), r.RequestLogging, r.RequestCounter, r.CORS)
 	m.Handle("OPTIONS", "/user_preferences/{user_id}", NewOptionsHandler(), r.RequestLogging, r.RequestCounter, r.CORS)
+	m.Handle("OPTIONS", "/user_messages", NewOptionsHandler(), r.RequestLogging, r.RequestCounter, r.CORS)
 	m.Handle("GET", "/user_preferences", NewGetPreferencesHandler(r.PreferencesFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesReadAuthenticator, r.DatabaseAllocator)
-	m.Handle("PATCH", "/user_preferences", NewUpdatePreferencesHandler(r.PreferenceUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("PATCH", "/user_preferences", NewUpdatePreferencesHandler(r.PreferenceUpdater, r.PreferencesFinder, r.PreferenceChanges, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesWriteAuthenticator, r.DatabaseAllocator)
+	// Registered ahead of /user_preferences/{user_id}, which would otherwise
+	// take "messages" for a user ID.
+	m.Handle("GET", "/user_preferences/messages", NewGetUserMessagesHandler(r.UserMessagesFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/user_preferences/export", NewExportPreferencesHandler(r.PreferencesPorter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/user_preferences/import", NewImportPreferencesHandler(r.PreferencesPorter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/user_preferences/{user_id}", NewGetUserPreferencesHandler(r.PreferencesFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesAdminAuthenticator, r.DatabaseAllocator)
-	m.Handle("PATCH", "/user_preferences/{user_id}", NewUpdateUserPreferencesHandler(r.PreferenceUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("PATCH", "/user_preferences/{user_id}", NewUpdateUserPreferencesHandler(r.PreferenceUpdater, r.PreferencesFinder, r.PreferenceChanges, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/user_preferences/subscriptions", NewSubscribeHandler(r.Subscriber, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/user_messages", NewGetUserMessagesHandler(r.UserMessagesFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.CORS, r.NotificationPreferencesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/admin/preferences/export", NewExportAllPreferencesHandler(r.PreferencesPorter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.DefaultZonePreferencesAdminAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/admin/preferences/import", NewImportAllPreferencesHandler(r.PreferencesPorter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.DefaultZonePreferencesAdminAuthenticator, r.DatabaseAllocator)
+
+	if r.PreferenceChanges != nil {
+		m.Handle("GET", "/user_preferences/revert/{token}", NewConfirmRevertPreferencesHandler(r.PreferenceChanges, r.ErrorWriter), r.RequestLogging, r.RequestCounter)
+		m.Handle("POST", "/user_preferences/revert/{token}", NewRevertPreferencesHandler(r.PreferenceChanges, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.DatabaseAllocator)
+	}
+
+	if r.OneClickUnsubscriber != nil {
+		m.Handle("POST", "/unsubscribe/{token}", NewUnsubscribeHandler(r.OneClickUnsubscriber, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.DatabaseAllocator)
+	}
 }
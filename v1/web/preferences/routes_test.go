@@ -20,9 +20,12 @@ var _ = Describe("Routes", func() {
 	BeforeEach(func() {
 		muxer = web.NewMuxer()
 		preferences.Routes{
-			ErrorWriter:       mocks.NewErrorWriter(),
-			PreferencesFinder: mocks.NewPreferencesFinder(),
-			PreferenceUpdater: mocks.NewPreferenceUpdater(),
+			ErrorWriter:        mocks.NewErrorWriter(),
+			PreferencesFinder:  mocks.NewPreferencesFinder(),
+			PreferenceUpdater:  mocks.NewPreferenceUpdater(),
+			UserMessagesFinder: mocks.NewUserMessagesRepo(),
+			Subscriber:         mocks.NewSubscriber(),
+			PreferencesPorter:  mocks.NewPreferencesPorter(),
 
 			CORS:                                     middleware.CORS{},
 			RequestCounter:                           middleware.RequestCounter{},
@@ -31,6 +34,8 @@ var _ = Describe("Routes", func() {
 			NotificationPreferencesReadAuthenticator: middleware.Authenticator{Scopes: []string{"notification_preferences.read"}},
 			NotificationPreferencesAdminAuthenticator: middleware.Authenticator{Scopes: []string{"notification_preferences.admin"}},
 			NotificationPreferencesWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notification_preferences.write"}},
+
+			DefaultZonePreferencesAdminAuthenticator: middleware.Authenticator{Scopes: []string{"notification_preferences.admin"}, DefaultZoneOnly: true},
 		}.Register(muxer)
 	})
 
@@ -59,6 +64,18 @@ var _ = Describe("Routes", func() {
 			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.write"}))
 		})
 
+		It("routes POST /user_preferences/subscriptions", func() {
+			request, err := http.NewRequest("POST", "/user_preferences/subscriptions", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.SubscribeHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.CORS{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[3].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.write"}))
+		})
+
 		It("routes OPTIONS /user_preferences", func() {
 			request, err := http.NewRequest("OPTIONS", "/user_preferences", nil)
 			Expect(err).NotTo(HaveOccurred())
@@ -69,6 +86,58 @@ var _ = Describe("Routes", func() {
 		})
 	})
 
+	Describe("export and import", func() {
+		It("routes GET /user_preferences/export", func() {
+			request, err := http.NewRequest("GET", "/user_preferences/export", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.ExportPreferencesHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.CORS{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[3].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.read"}))
+		})
+
+		It("routes PUT /user_preferences/import", func() {
+			request, err := http.NewRequest("PUT", "/user_preferences/import", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.ImportPreferencesHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.CORS{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[3].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.write"}))
+		})
+
+		It("routes GET /admin/preferences/export", func() {
+			request, err := http.NewRequest("GET", "/admin/preferences/export", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.ExportAllPreferencesHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.admin"}))
+			Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+		})
+
+		It("routes PUT /admin/preferences/import", func() {
+			request, err := http.NewRequest("PUT", "/admin/preferences/import", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.ImportAllPreferencesHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.admin"}))
+			Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+		})
+	})
+
 	Describe("/user_preferences/{user_id}", func() {
 		It("routes GET /user_preferences/{user_id}", func() {
 			request, err := http.NewRequest("GET", "/user_preferences/some-user-id", nil)
@@ -103,4 +172,106 @@ var _ = Describe("Routes", func() {
 			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.CORS{})
 		})
 	})
+
+	Describe("/user_messages", func() {
+		It("routes GET /user_messages", func() {
+			request, err := http.NewRequest("GET", "/user_messages", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.GetUserMessagesHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.CORS{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[3].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.read"}))
+		})
+
+		It("routes GET /user_preferences/messages rather than taking it for a user ID", func() {
+			request, err := http.NewRequest("GET", "/user_preferences/messages", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.GetUserMessagesHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.CORS{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[3].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_preferences.read"}))
+		})
+
+		It("routes OPTIONS /user_messages", func() {
+			request, err := http.NewRequest("OPTIONS", "/user_messages", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.OptionsHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.CORS{})
+		})
+	})
+
+	Describe("/user_preferences/revert/{token}", func() {
+		It("is not routed when preference change notifications are disabled", func() {
+			request, err := http.NewRequest("GET", "/user_preferences/revert/some-token", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(muxer.Match(request)).NotTo(BeAssignableToTypeOf(stack.Stack{}))
+		})
+
+		Context("when preference change notifications are enabled", func() {
+			BeforeEach(func() {
+				muxer = web.NewMuxer()
+				preferences.Routes{
+					ErrorWriter:       mocks.NewErrorWriter(),
+					PreferenceChanges: mocks.NewPreferenceChangeNotifier(),
+					RequestCounter:    middleware.RequestCounter{},
+					RequestLogging:    middleware.RequestLogging{},
+					DatabaseAllocator: middleware.DatabaseAllocator{},
+				}.Register(muxer)
+			})
+
+			It("routes GET /user_preferences/revert/{token} to a confirmation without authentication", func() {
+				request, err := http.NewRequest("GET", "/user_preferences/revert/some-token", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				s := muxer.Match(request).(stack.Stack)
+				Expect(s.Handler).To(BeAssignableToTypeOf(preferences.ConfirmRevertPreferencesHandler{}))
+				ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{})
+			})
+
+			It("routes POST /user_preferences/revert/{token} without authentication", func() {
+				request, err := http.NewRequest("POST", "/user_preferences/revert/some-token", nil)
+				Expect(err).NotTo(HaveOccurred())
+
+				s := muxer.Match(request).(stack.Stack)
+				Expect(s.Handler).To(BeAssignableToTypeOf(preferences.RevertPreferencesHandler{}))
+				ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.DatabaseAllocator{})
+			})
+		})
+	})
+
+	Describe("/unsubscribe/{token}", func() {
+		It("is not routed when one-click unsubscribe is disabled", func() {
+			request, err := http.NewRequest("POST", "/unsubscribe/some-token", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(muxer.Match(request)).NotTo(BeAssignableToTypeOf(stack.Stack{}))
+		})
+
+		It("routes POST /unsubscribe/{token} without authentication", func() {
+			muxer = web.NewMuxer()
+			preferences.Routes{
+				ErrorWriter:          mocks.NewErrorWriter(),
+				OneClickUnsubscriber: mocks.NewOneClickUnsubscriber(),
+				RequestCounter:       middleware.RequestCounter{},
+				RequestLogging:       middleware.RequestLogging{},
+				DatabaseAllocator:    middleware.DatabaseAllocator{},
+			}.Register(muxer)
+
+			request, err := http.NewRequest("POST", "/unsubscribe/some-token", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(preferences.UnsubscribeHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.DatabaseAllocator{})
+		})
+	})
 })
@@ -0,0 +1,90 @@
+package preferences
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/cloudfoundry-incubator/notifications/valiant"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+)
+
+type subscriber interface {
+	Subscribe(conn services.ConnectionInterface, userID, clientID, kindID string) error
+}
+
+type SubscribeParams struct {
+	ClientID       string `json:"client_id"       validate-required:"true"`
+	NotificationID string `json:"notification_id" validate-required:"true"`
+}
+
+type SubscribeHandler struct {
+	subscriber  subscriber
+	errorWriter errorWriter
+}
+
+func NewSubscribeHandler(subscriber subscriber, errWriter errorWriter) SubscribeHandler {
+	return SubscribeHandler{
+		subscriber:  subscriber,
+		errorWriter: errWriter,
+	}
+}
+
+func (h SubscribeHandler) RequestBody() interface{} {
+	return SubscribeParams{}
+}
+
+func (h SubscribeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	token := context.Get("token").(*jwt.Token)
+	if _, ok := token.Claims["user_id"]; !ok {
+		h.errorWriter.Write(w, webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")})
+		return
+	}
+
+	userID := token.Claims["user_id"].(string)
+
+	var params SubscribeParams
+	err := valiant.NewValidator(req.Body).Validate(&params)
+	if err != nil {
+		switch err.(type) {
+		case valiant.RequiredFieldError:
+			h.errorWriter.Write(w, webutil.ValidationError{Err: err})
+		default:
+			h.errorWriter.Write(w, webutil.ParseError{})
+		}
+		return
+	}
+
+	transaction := connection.Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.subscriber.Subscribe(transaction, userID, webutil.ZonedClientID(context, params.ClientID), params.NotificationID)
+	if err != nil {
+		transaction.Rollback()
+
+		switch err.(type) {
+		case services.MissingKindOrClientError, services.NotOptInKindError:
+			h.errorWriter.Write(w, webutil.ValidationError{Err: err})
+		default:
+			h.errorWriter.Write(w, err)
+		}
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
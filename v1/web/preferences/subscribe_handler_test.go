@@ -0,0 +1,129 @@
+package preferences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/helpers"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SubscribeHandler", func() {
+	var (
+		handler     preferences.SubscribeHandler
+		subscriber  *mocks.Subscriber
+		errorWriter *mocks.ErrorWriter
+		transaction *mocks.Transaction
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+		context     stack.Context
+	)
+
+	buildToken := func(claims map[string]interface{}) *jwt.Token {
+		rawToken := helpers.BuildToken(map[string]interface{}{"alg": "RS256"}, claims)
+		token, err := jwt.Parse(rawToken, func(*jwt.Token) (interface{}, error) {
+			return []byte(helpers.UAAPublicKey), nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		return token
+	}
+
+	BeforeEach(func() {
+		transaction = mocks.NewTransaction()
+		connection := mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set("token", buildToken(map[string]interface{}{
+			"user_id": "correct-user",
+			"exp":     int64(3404281214),
+		}))
+
+		var err error
+		request, err = http.NewRequest("POST", "/user_preferences/subscriptions", strings.NewReader(`{
+			"client_id": "raptors",
+			"notification_id": "newsletter"
+		}`))
+		Expect(err).NotTo(HaveOccurred())
+
+		subscriber = mocks.NewSubscriber()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = preferences.NewSubscribeHandler(subscriber, errorWriter)
+	})
+
+	It("subscribes the user from the token to the notification", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+
+		Expect(subscriber.SubscribeCall.Receives.Connection).To(Equal(transaction))
+		Expect(subscriber.SubscribeCall.Receives.UserID).To(Equal("correct-user"))
+		Expect(subscriber.SubscribeCall.Receives.ClientID).To(Equal("raptors"))
+		Expect(subscriber.SubscribeCall.Receives.KindID).To(Equal("newsletter"))
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+	})
+
+	Context("when the user_id claim is not present in the token", func() {
+		It("writes a MissingUserTokenError", func() {
+			context.Set("token", buildToken(map[string]interface{}{
+				"exp": int64(3404281214),
+			}))
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")}))
+			Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the notification_id is missing", func() {
+		It("writes a validation error", func() {
+			request, err := http.NewRequest("POST", "/user_preferences/subscriptions", strings.NewReader(`{"client_id": "raptors"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(transaction.BeginCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the notification is not opt-in", func() {
+		It("rolls back and writes a validation error", func() {
+			subscriber.SubscribeCall.Returns.Error = services.NotOptInKindError{Err: errors.New("The kind 'newsletter' for the 'raptors' client is not opt-in")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ValidationError{Err: services.NotOptInKindError{Err: errors.New("The kind 'newsletter' for the 'raptors' client is not opt-in")}}))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be committed", func() {
+		It("writes a transaction commit error", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.TransactionCommitError{Err: errors.New("commit failed")}))
+		})
+	})
+})
@@ -0,0 +1,59 @@
+package preferences
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/ryanmoran/stack"
+)
+
+type oneClickUnsubscriber interface {
+	Unsubscribe(conn services.ConnectionInterface, token string) error
+}
+
+// UnsubscribeHandler serves the RFC 8058 one-click unsubscribe that mail
+// clients POST to the List-Unsubscribe link. Like the revert link, the
+// signed token in the path is the only credential.
+type UnsubscribeHandler struct {
+	unsubscriber oneClickUnsubscriber
+	errorWriter  errorWriter
+}
+
+func NewUnsubscribeHandler(unsubscriber oneClickUnsubscriber, errWriter errorWriter) UnsubscribeHandler {
+	return UnsubscribeHandler{
+		unsubscriber: unsubscriber,
+		errorWriter:  errWriter,
+	}
+}
+
+func (h UnsubscribeHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	connection := context.Get("database").(DatabaseInterface).Connection()
+
+	token := regexp.MustCompile(".*/unsubscribe/(.*)").FindStringSubmatch(req.URL.Path)[1]
+
+	transaction := connection.Transaction()
+	err := transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = h.unsubscriber.Unsubscribe(transaction, token)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("You have been unsubscribed.\n"))
+}
@@ -0,0 +1,94 @@
+package preferences_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/preferences"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("UnsubscribeHandler", func() {
+	var (
+		handler      preferences.UnsubscribeHandler
+		unsubscriber *mocks.OneClickUnsubscriber
+		errorWriter  *mocks.ErrorWriter
+		transaction  *mocks.Transaction
+		writer       *httptest.ResponseRecorder
+		request      *http.Request
+		context      stack.Context
+	)
+
+	BeforeEach(func() {
+		transaction = mocks.NewTransaction()
+		connection := mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		var err error
+		request, err = http.NewRequest("POST", "/unsubscribe/some-unsubscribe-id=.some-signature", strings.NewReader("List-Unsubscribe=One-Click"))
+		Expect(err).NotTo(HaveOccurred())
+
+		unsubscriber = mocks.NewOneClickUnsubscriber()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		handler = preferences.NewUnsubscribeHandler(unsubscriber, errorWriter)
+	})
+
+	It("unsubscribes using the token", func() {
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(Equal("You have been unsubscribed.\n"))
+
+		Expect(unsubscriber.UnsubscribeCall.Receives.Connection).To(Equal(transaction))
+		Expect(unsubscriber.UnsubscribeCall.Receives.Token).To(Equal("some-unsubscribe-id=.some-signature"))
+		Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+	})
+
+	Context("when the token cannot be used", func() {
+		It("rolls back and writes the error", func() {
+			unsubscriber.UnsubscribeCall.Returns.Error = services.UnsubscribeLinkError{Err: errors.New("The unsubscribe link is invalid")}
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(services.UnsubscribeLinkError{Err: errors.New("The unsubscribe link is invalid")}))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be started", func() {
+		It("writes the error", func() {
+			transaction.BeginCall.Returns.Error = errors.New("connection refused")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("connection refused")))
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+
+	Context("when the transaction cannot be committed", func() {
+		It("writes a transaction commit error", func() {
+			transaction.CommitCall.Returns.Error = errors.New("commit failed")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.TransactionCommitError{Err: errors.New("commit failed")}))
+		})
+	})
+})
@@ -14,16 +14,24 @@ import (
 
 type UpdatePreferencesHandler struct {
 	preferences preferenceUpdater
+	finder      preferencesFinder
+	changes     preferenceChangeNotifier
 	errorWriter errorWriter
 }
 
-func NewUpdatePreferencesHandler(preferences preferenceUpdater, errWriter errorWriter) UpdatePreferencesHandler {
+func NewUpdatePreferencesHandler(preferences preferenceUpdater, finder preferencesFinder, changes preferenceChangeNotifier, errWriter errorWriter) UpdatePreferencesHandler {
 	return UpdatePreferencesHandler{
 		preferences: preferences,
+		finder:      finder,
+		changes:     changes,
 		errorWriter: errWriter,
 	}
 }
 
+func (h UpdatePreferencesHandler) RequestBody() interface{} {
+	return services.PreferencesBuilder{}
+}
+
 func (h UpdatePreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	database := context.Get("database").(DatabaseInterface)
 	connection := database.Connection()
@@ -45,15 +53,30 @@ func (h UpdatePreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Req
 		return
 	}
 
-	preferences, err := builder.ToPreferences()
+	preferences, err := builder.Zoned(webutil.ZoneID(context)).ToPreferences()
 	if err != nil {
 		h.errorWriter.Write(w, webutil.ValidationError{Err: err})
 		return
 	}
 
+	var previous services.PreferencesBuilder
+	if h.changes != nil {
+		previous, err = h.finder.Find(database, userID)
+		if err != nil {
+			h.errorWriter.Write(w, err)
+			return
+		}
+	}
+
 	transaction := connection.Transaction()
 	transaction.Begin()
 	err = h.preferences.Update(transaction, preferences, builder.GlobalUnsubscribe, userID)
+	if err == nil && builder.Digest != "" {
+		err = h.preferences.SetDigest(transaction, userID, builder.Digest)
+	}
+	if err == nil && len(builder.Severities) > 0 {
+		err = h.preferences.SetSeverities(transaction, userID, builder.Severities)
+	}
 	if err != nil {
 		transaction.Rollback()
 
@@ -72,5 +95,9 @@ func (h UpdatePreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Req
 		return
 	}
 
+	if h.changes != nil {
+		notifyPreferenceChange(h.changes, connection, context, userID, previous, preferences, builder.GlobalUnsubscribe)
+	}
+
 	w.WriteHeader(http.StatusNoContent)
 }
@@ -92,7 +92,7 @@ var _ = Describe("UpdatePreferencesHandler", func() {
 			updater = mocks.NewPreferenceUpdater()
 			writer = httptest.NewRecorder()
 
-			handler = preferences.NewUpdatePreferencesHandler(updater, errorWriter)
+			handler = preferences.NewUpdatePreferencesHandler(updater, nil, nil, errorWriter)
 		})
 
 		It("Passes The Correct Arguments to PreferenceUpdater Execute", func() {
@@ -121,12 +121,102 @@ var _ = Describe("UpdatePreferencesHandler", func() {
 			Expect(updater.UpdateCall.Receives.UserID).To(Equal("correct-user"))
 		})
 
+		It("stores the preferences under the client IDs of the zone of the token", func() {
+			context.Set(webutil.ZoneIDKey, "tenant-a")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(updater.UpdateCall.Receives.Preferences).To(ContainElement(models.Preference{
+				ClientID: "tenant-a:dogs",
+				KindID:   "barking",
+				Email:    false,
+			}))
+		})
+
+		It("leaves the digest frequency alone when none is given", func() {
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(updater.SetDigestCall.WasCalled).To(BeFalse())
+		})
+
+		It("sets the digest frequency in the same transaction", func() {
+			body, err := json.Marshal(map[string]interface{}{
+				"global_unsubscribe": false,
+				"digest":             "daily",
+				"clients":            map[string]interface{}{},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer(body))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(reflect.ValueOf(updater.SetDigestCall.Receives.Connection).Pointer()).To(Equal(reflect.ValueOf(transaction).Pointer()))
+			Expect(updater.SetDigestCall.Receives.UserID).To(Equal("correct-user"))
+			Expect(updater.SetDigestCall.Receives.Frequency).To(Equal("daily"))
+		})
+
+		It("sets the severities the user receives in the same transaction", func() {
+			body, err := json.Marshal(map[string]interface{}{
+				"severities": map[string]bool{"warning": false},
+				"clients":    map[string]interface{}{},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer(body))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(reflect.ValueOf(updater.SetSeveritiesCall.Receives.Connection).Pointer()).To(Equal(reflect.ValueOf(transaction).Pointer()))
+			Expect(updater.SetSeveritiesCall.Receives.UserID).To(Equal("correct-user"))
+			Expect(updater.SetSeveritiesCall.Receives.Severities).To(Equal(map[string]bool{"warning": false}))
+		})
+
 		It("Returns a 204 status code when the Preference object does not error", func() {
 			handler.ServeHTTP(writer, request, context)
 
 			Expect(writer.Code).To(Equal(http.StatusNoContent))
 		})
 
+		Context("when preference change notifications are enabled", func() {
+			var (
+				finder   *mocks.PreferencesFinder
+				notifier *mocks.PreferenceChangeNotifier
+			)
+
+			BeforeEach(func() {
+				finder = mocks.NewPreferencesFinder()
+				finder.FindCall.Returns.PreferencesBuilder = services.NewPreferencesBuilder()
+				notifier = mocks.NewPreferenceChangeNotifier()
+
+				handler = preferences.NewUpdatePreferencesHandler(updater, finder, notifier, errorWriter)
+			})
+
+			It("notifies the user of the change after it is committed", func() {
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(writer.Code).To(Equal(http.StatusNoContent))
+				Expect(finder.FindCall.Receives.UserGUID).To(Equal("correct-user"))
+				Expect(notifier.NotifyCall.Receives.Connection).To(Equal(conn))
+				Expect(notifier.NotifyCall.Receives.Change.UserGUID).To(Equal("correct-user"))
+				Expect(notifier.NotifyCall.Receives.Change.Previous).To(Equal(services.NewPreferencesBuilder()))
+				Expect(notifier.NotifyCall.Receives.Change.GlobalUnsubscribe).To(BeTrue())
+				Expect(notifier.NotifyCall.Receives.Change.Preferences).To(HaveLen(3))
+			})
+
+			It("does not notify the user when the update fails", func() {
+				updater.UpdateCall.Returns.Error = errors.New("boom!")
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(notifier.NotifyCall.WasCalled).To(BeFalse())
+			})
+		})
+
 		Context("Failure cases", func() {
 			It("returns an error when the clients key is missing", func() {
 				jsonBody := `{"raptor-client": {"containment-unit-breach": {"email": false}}}`
@@ -261,6 +351,38 @@ var _ = Describe("UpdatePreferencesHandler", func() {
 				Expect(transaction.RollbackCall.WasCalled).To(BeFalse())
 			})
 
+			It("rejects an unknown digest frequency", func() {
+				requestBody, err := json.Marshal(map[string]interface{}{
+					"digest":  "weekly",
+					"clients": map[string]interface{}{},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer(requestBody))
+				Expect(err).NotTo(HaveOccurred())
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+				Expect(updater.SetDigestCall.WasCalled).To(BeFalse())
+			})
+
+			It("rejects unsubscribing from notifications that require action", func() {
+				requestBody, err := json.Marshal(map[string]interface{}{
+					"severities": map[string]bool{"critical-action-required": false},
+					"clients":    map[string]interface{}{},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				request, err = http.NewRequest("PATCH", "/user_preferences", bytes.NewBuffer(requestBody))
+				Expect(err).NotTo(HaveOccurred())
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+				Expect(updater.SetSeveritiesCall.WasCalled).To(BeFalse())
+			})
+
 			It("delegates transaction errors to the error writer", func() {
 				transaction.CommitCall.Returns.Error = errors.New("transaction error, oh no")
 				handler.ServeHTTP(writer, request, context)
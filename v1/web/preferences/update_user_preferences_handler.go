@@ -1,7 +1,6 @@
 package preferences
 
 import (
-	"encoding/json"
 	"net/http"
 	"regexp"
 
@@ -14,16 +13,24 @@ import (
 
 type UpdateUserPreferencesHandler struct {
 	preferences preferenceUpdater
+	finder      preferencesFinder
+	changes     preferenceChangeNotifier
 	errorWriter errorWriter
 }
 
-func NewUpdateUserPreferencesHandler(preferences preferenceUpdater, errWriter errorWriter) UpdateUserPreferencesHandler {
+func NewUpdateUserPreferencesHandler(preferences preferenceUpdater, finder preferencesFinder, changes preferenceChangeNotifier, errWriter errorWriter) UpdateUserPreferencesHandler {
 	return UpdateUserPreferencesHandler{
 		preferences: preferences,
+		finder:      finder,
+		changes:     changes,
 		errorWriter: errWriter,
 	}
 }
 
+func (h UpdateUserPreferencesHandler) RequestBody() interface{} {
+	return services.PreferencesBuilder{}
+}
+
 func (h UpdateUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	database := context.Get("database").(DatabaseInterface)
 	connection := database.Connection()
@@ -38,15 +45,30 @@ func (h UpdateUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http
 		return
 	}
 
-	preferences, err := builder.ToPreferences()
+	preferences, err := builder.Zoned(webutil.ZoneID(context)).ToPreferences()
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
 	}
 
+	var previous services.PreferencesBuilder
+	if h.changes != nil {
+		previous, err = h.finder.Find(database, userGUID)
+		if err != nil {
+			h.errorWriter.Write(w, err)
+			return
+		}
+	}
+
 	transaction := connection.Transaction()
 	transaction.Begin()
 	err = h.preferences.Update(transaction, preferences, builder.GlobalUnsubscribe, userGUID)
+	if err == nil && builder.Digest != "" {
+		err = h.preferences.SetDigest(transaction, userGUID, builder.Digest)
+	}
+	if err == nil && len(builder.Severities) > 0 {
+		err = h.preferences.SetSeverities(transaction, userGUID, builder.Severities)
+	}
 	if err != nil {
 		transaction.Rollback()
 
@@ -65,15 +87,9 @@ func (h UpdateUserPreferencesHandler) ServeHTTP(w http.ResponseWriter, req *http
 		return
 	}
 
-	w.WriteHeader(http.StatusNoContent)
-}
-
-func writeJSON(w http.ResponseWriter, status int, object interface{}) {
-	output, err := json.Marshal(object)
-	if err != nil {
-		panic(err) // No JSON we write into a response should ever panic
+	if h.changes != nil {
+		notifyPreferenceChange(h.changes, connection, context, userGUID, previous, preferences, builder.GlobalUnsubscribe)
 	}
 
-	w.WriteHeader(status)
-	w.Write(output)
+	w.WriteHeader(http.StatusNoContent)
 }
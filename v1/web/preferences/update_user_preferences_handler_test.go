@@ -92,7 +92,7 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 			errorWriter = mocks.NewErrorWriter()
 			writer = httptest.NewRecorder()
 
-			handler = preferences.NewUpdateUserPreferencesHandler(updater, errorWriter)
+			handler = preferences.NewUpdateUserPreferencesHandler(updater, nil, nil, errorWriter)
 		})
 
 		It("Passes the correct arguments to PreferenceUpdater Execute", func() {
@@ -121,12 +121,105 @@ var _ = Describe("UpdateUserPreferencesHandler", func() {
 			Expect(updater.UpdateCall.Receives.UserID).To(Equal(userGUID))
 		})
 
+		It("sets the digest frequency of the user", func() {
+			body, err := json.Marshal(map[string]interface{}{
+				"digest":  "hourly",
+				"clients": map[string]interface{}{},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PATCH", "/user_preferences/"+userGUID, bytes.NewBuffer(body))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(updater.SetDigestCall.Receives.UserID).To(Equal(userGUID))
+			Expect(updater.SetDigestCall.Receives.Frequency).To(Equal("hourly"))
+		})
+
+		It("sets the severities the user receives", func() {
+			body, err := json.Marshal(map[string]interface{}{
+				"severities": map[string]bool{"info": false},
+				"clients":    map[string]interface{}{},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			request, err = http.NewRequest("PATCH", "/user_preferences/"+userGUID, bytes.NewBuffer(body))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(updater.SetSeveritiesCall.Receives.UserID).To(Equal(userGUID))
+			Expect(updater.SetSeveritiesCall.Receives.Severities).To(Equal(map[string]bool{"info": false}))
+		})
+
 		It("Returns a 204 status code when the Preference object does not error", func() {
 			handler.ServeHTTP(writer, request, context)
 
 			Expect(writer.Code).To(Equal(http.StatusNoContent))
 		})
 
+		Context("when preference change notifications are enabled", func() {
+			var (
+				finder   *mocks.PreferencesFinder
+				notifier *mocks.PreferenceChangeNotifier
+				previous services.PreferencesBuilder
+			)
+
+			BeforeEach(func() {
+				previous = services.NewPreferencesBuilder()
+				previous.Add(models.Preference{
+					ClientID: "raptors",
+					KindID:   "door-opening",
+					Email:    true,
+				})
+
+				finder = mocks.NewPreferencesFinder()
+				finder.FindCall.Returns.PreferencesBuilder = previous
+				notifier = mocks.NewPreferenceChangeNotifier()
+
+				context.Set("vcap_request_id", "some-request-id")
+				handler = preferences.NewUpdateUserPreferencesHandler(updater, finder, notifier, errorWriter)
+			})
+
+			It("tells the user which client changed their preferences", func() {
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(writer.Code).To(Equal(http.StatusNoContent))
+				Expect(finder.FindCall.Receives.UserGUID).To(Equal(userGUID))
+
+				change := notifier.NotifyCall.Receives.Change
+				Expect(change.UserGUID).To(Equal(userGUID))
+				Expect(change.ClientID).To(Equal("mister-client"))
+				Expect(change.Previous).To(Equal(previous))
+				Expect(change.GlobalUnsubscribe).To(BeTrue())
+				Expect(change.VCAPRequest.ID).To(Equal("some-request-id"))
+			})
+
+			It("still succeeds when the user cannot be notified", func() {
+				notifier.NotifyCall.Returns.Error = errors.New("queue is down")
+
+				handler.ServeHTTP(writer, request, context)
+
+				Expect(writer.Code).To(Equal(http.StatusNoContent))
+				Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+			})
+
+			Context("when the previous preferences cannot be found", func() {
+				It("writes the error and leaves the preferences alone", func() {
+					finder.FindCall.Returns.Error = errors.New("database is down")
+
+					handler.ServeHTTP(writer, request, context)
+
+					Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("database is down")))
+					Expect(updater.UpdateCall.Receives.UserID).To(BeEmpty())
+					Expect(notifier.NotifyCall.WasCalled).To(BeFalse())
+				})
+			})
+		})
+
 		Context("Failure cases", func() {
 			Context("when global_unsubscribe is not set", func() {
 				It("returns an error when the clients key is missing", func() {
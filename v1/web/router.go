@@ -3,17 +3,26 @@ package web
 import (
 	"crypto/rand"
 	"database/sql"
+	"net"
 	"net/http"
 	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/httpclient"
+	"github.com/cloudfoundry-incubator/notifications/mail"
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/sanitize"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/util"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/admin"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/audit"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/clients"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/docs"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/info"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/messages"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/middleware"
@@ -35,18 +44,63 @@ type muxer interface {
 	ServeHTTP(w http.ResponseWriter, req *http.Request)
 }
 
+type fastLane interface {
+	Submit(job *gobble.Job) bool
+}
+
+type workerPool interface {
+	Status() postal.WorkerPoolStatus
+}
+
+type preferencesCache interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string, ttl time.Duration) error
+	Del(key string) error
+}
+
 type Config struct {
 	UAATokenValidator    *uaa.TokenValidator
 	UAAClientID          string
 	UAAClientSecret      string
 	DefaultUAAScopes     []string
-	VerifySSL            bool
+	HTTPClients          httpclient.Shared
 	CCHost               string
 	DBLoggingEnabled     bool
 	Logger               lager.Logger
 	CORSOrigin           string
 	SQLDB                *sql.DB
+	SQLReplicas          []*sql.DB
 	QueueWaitMaxDuration int
+	QueueRedisURL        string
+	ReadOnly             bool
+
+	SyncUserDeliveryTimeout      int
+	ClientRateLimit              int
+	ClientRateLimitBurst         int
+	CriticalKindApproval         bool
+	CriticalUnsubscribeGraceDays int
+	IdempotencyWindowHours       int
+	EnqueueBatchSize             int
+	EnqueueWorkers               int
+	SendingAnomalyFactor         int
+	SendingAnomalyMinRequests    int
+	SendingAnomalyReauthorize    bool
+	Sender                       string
+	Domain                       string
+	EncryptionKey                []byte
+	PreviousEncryptionKeys       [][]byte
+	PreferenceChangeRevertURL    string
+	UnsubscribeURL               string
+	TrackingURL                  string
+	HTMLSanitizerMode            string
+	MailTransport                string
+	HTMLSizeLimit                int
+	HTMLAllowedElements          sanitize.Policy
+	PreferencesCache             preferencesCache
+	FastLane                     fastLane
+	WorkerPool                   workerPool
+	QueueSLA                     map[int]time.Duration
+	NotifyAuthorizers            []notify.Authorizer
 }
 
 func NewRouter(mx muxer, config Config) http.Handler {
@@ -55,35 +109,97 @@ func NewRouter(mx muxer, config Config) http.Handler {
 
 	clientsRepo := models.NewClientsRepo()
 	kindsRepo := models.NewKindsRepo()
-	globalUnsubscribesRepo := models.NewGlobalUnsubscribesRepo()
 	preferencesRepo := models.NewPreferencesRepo()
-	unsubscribesRepo := models.NewUnsubscribesRepo()
+	criticalUnsubscribesRepo := models.NewCriticalUnsubscribesRepo()
+	criticalApprovalsRepo := models.NewCriticalApprovalsRepo()
 	messagesRepo := models.NewMessagesRepo(guidGenerator.Generate)
 	templatesRepo := models.NewTemplatesRepo()
+	organizationPoliciesRepo := models.NewOrganizationPoliciesRepo()
+	clientQuotasRepo := models.NewClientQuotasRepo()
+	clientSuspensionsRepo := models.NewClientSuspensionsRepo()
+	userMessagesRepo := models.NewUserMessagesRepo()
+	subscriptionsRepo := models.NewSubscriptionsRepo()
+	digestPreferencesRepo := models.NewDigestPreferencesRepo()
+	severityUnsubscribesRepo := models.NewSeverityUnsubscribesRepo()
+	suppressionsRepo := models.NewSuppressionsRepo()
+	payloadSamplesRepo := models.NewPayloadSamplesRepo()
+	registrationWebhooksRepo := models.NewRegistrationWebhooksRepo()
+	receiptsRepo := models.NewReceiptsRepo()
+	scheduledJobsRepo := models.NewScheduledJobsRepo()
+	schedulerLeasesRepo := models.NewSchedulerLeasesRepo()
+	instanceHeartbeatsRepo := models.NewInstanceHeartbeatsRepo()
+	auditEventsRepo := models.NewAuditEventsRepo()
+	kindActivityRepo := models.NewKindActivityRepo()
 
-	registrar := services.NewRegistrar(clientsRepo, kindsRepo)
+	var unsubscribesRepo services.UnsubscribesRepo = models.NewUnsubscribesRepo()
+	var globalUnsubscribesRepo services.GlobalUnsubscribesRepo = models.NewGlobalUnsubscribesRepo()
+	if config.PreferencesCache != nil {
+		unsubscribesRepo = models.NewCachedUnsubscribesRepo(unsubscribesRepo, config.PreferencesCache)
+		globalUnsubscribesRepo = models.NewCachedGlobalUnsubscribesRepo(globalUnsubscribesRepo, config.PreferencesCache)
+	}
+
+	criticalDowngrade := services.NewCriticalDowngrade(criticalUnsubscribesRepo, unsubscribesRepo, clock, time.Duration(config.CriticalUnsubscribeGraceDays)*24*time.Hour)
+	registrar := services.NewRegistrar(clientsRepo, kindsRepo, criticalDowngrade)
+	if config.CriticalKindApproval {
+		registrar = registrar.WithCriticalApproval(criticalApprovalsRepo)
+	}
+	criticalApprover := services.NewCriticalApprover(criticalApprovalsRepo, kindsRepo, criticalDowngrade)
 	notificationsFinder := services.NewNotificationsFinder(clientsRepo, kindsRepo)
-	preferencesFinder := services.NewPreferencesFinder(preferencesRepo, globalUnsubscribesRepo)
-	preferenceUpdater := services.NewPreferenceUpdater(globalUnsubscribesRepo, unsubscribesRepo, kindsRepo)
-	notificationsUpdater := services.NewNotificationsUpdater(kindsRepo)
+	preferencesFinder := services.NewPreferencesFinder(preferencesRepo, globalUnsubscribesRepo, digestPreferencesRepo, severityUnsubscribesRepo)
+	preferenceUpdater := services.NewPreferenceUpdater(globalUnsubscribesRepo, unsubscribesRepo, subscriptionsRepo, kindsRepo, digestPreferencesRepo, severityUnsubscribesRepo, kindActivityRepo, clock)
+	subscriber := services.NewSubscriber(kindsRepo, subscriptionsRepo, unsubscribesRepo)
+	notificationsUpdater := services.NewNotificationsUpdater(kindsRepo, criticalDowngrade)
 	messageFinder := services.NewMessageFinder(messagesRepo)
+	messageLister := services.NewMessageLister(messagesRepo)
 
 	templatesCollection := collections.NewTemplatesCollection(clientsRepo, kindsRepo, templatesRepo)
 
 	templateFinder := services.NewTemplateFinder(templatesRepo)
 	templateUpdater := services.NewTemplateUpdater(templatesRepo)
 	templateLister := services.NewTemplateLister(templatesRepo)
+	templateTranslator := services.NewTemplateTranslator(templatesRepo, models.NewTemplateTranslationsRepo())
+	templateVersioner := services.NewTemplateVersioner(templatesRepo)
+	templatePackImporter := services.NewTemplatePackImporter(templatesRepo, models.NewTemplatePartialsRepo())
+
+	cloak, err := common.NewKeyring(config.EncryptionKey, config.PreviousEncryptionKeys...)
+	if err != nil {
+		panic(err)
+	}
+
+	// Previews supply their own templates, so the packager never loads stored ones.
+	templatePreviewer := services.NewTemplatePreviewer(common.NewPackager(nil, cloak), cloak, config.Sender, config.Domain)
+	templateSampler := services.NewTemplateSampler(payloadSamplesRepo, clientsRepo, kindsRepo, templatesRepo, models.NewTemplatePartialsRepo(),
+		common.NewPackager(nil, cloak), cloak, config.Sender, config.Domain)
+
+	var htmlPolicy notify.HTMLPolicy
+	if config.HTMLSanitizerMode == sanitize.ModeClean || config.HTMLSanitizerMode == sanitize.ModeStrict {
+		htmlPolicy.Sanitizer = sanitize.NewSanitizer(config.HTMLAllowedElements)
+		htmlPolicy.Strict = config.HTMLSanitizerMode == sanitize.ModeStrict
+	}
 
-	notifyObj := notify.NewNotify(notificationsFinder, registrar)
+	notifyObj := notify.NewNotify(notificationsFinder, registrar, models.NewIdempotencyKeysRepo(), time.Duration(config.IdempotencyWindowHours)*time.Hour, htmlPolicy).
+		WithAuthorizers(config.NotifyAuthorizers...)
 
-	gobbleQueue := gobble.NewQueue(gobble.NewDatabase(config.SQLDB), clock, gobble.Config{
+	gobbleQueue, err := gobble.NewBackend(config.SQLDB, clock, gobble.Config{
 		WaitMaxDuration: time.Duration(config.QueueWaitMaxDuration) * time.Millisecond,
+		RedisURL:        config.QueueRedisURL,
 	})
+	if err != nil {
+		panic(err)
+	}
 
-	v1enqueuer := services.NewEnqueuer(gobbleQueue, messagesRepo, gobble.Initializer{})
+	v1enqueuer := services.NewEnqueuer(gobbleQueue, messagesRepo, gobble.Initializer{}, clientQuotasRepo, config.FastLane).
+		WithFanOut(config.EnqueueBatchSize, config.EnqueueWorkers).
+		WithPayloadSamples(payloadSamplesRepo).
+		WithLogger(config.Logger)
+	jobReprioritizer := services.NewJobReprioritizer(gobbleQueue, clock)
+	queueReplayer := services.NewQueueReplayer(messagesRepo, gobbleQueue, clock)
+	messageCanceler := services.NewMessageCanceler(messagesRepo)
+	messageRetrier := services.NewMessageRetrier(messagesRepo, gobbleQueue, clock)
+	registrationAuditor := services.NewRegistrationAuditor(clientsRepo, kindsRepo, registrationWebhooksRepo, gobbleQueue, gobble.Initializer{}, clock)
 
-	uaaClient := uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.VerifySSL, config.UAATokenValidator)
-	cloudController := cf.NewCloudController(config.CCHost, !config.VerifySSL)
+	uaaClient := uaa.NewZonedUAAClient(config.UAAClientID, config.UAAClientSecret, config.HTTPClients.Client("uaa"), config.UAATokenValidator)
+	cloudController := cf.NewCloudController(config.CCHost, config.HTTPClients.Client("cc"))
 	tokenLoader := uaa.NewTokenLoader(uaaClient)
 	spaceLoader := services.NewSpaceLoader(cloudController)
 	organizationLoader := services.NewOrganizationLoader(cloudController)
@@ -91,30 +207,85 @@ func NewRouter(mx muxer, config Config) http.Handler {
 	allUsers := services.NewAllUsers(uaaClient)
 
 	emailStrategy := services.NewEmailStrategy(v1enqueuer)
-	userStrategy := services.NewUserStrategy(v1enqueuer)
-	spaceStrategy := services.NewSpaceStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, v1enqueuer)
-	organizationStrategy := services.NewOrganizationStrategy(tokenLoader, organizationLoader, findsUserIDs, v1enqueuer)
-	everyoneStrategy := services.NewEveryoneStrategy(tokenLoader, allUsers, v1enqueuer)
-	uaaScopeStrategy := services.NewUAAScopeStrategy(tokenLoader, findsUserIDs, v1enqueuer, config.DefaultUAAScopes)
+	var userStrategy notify.Dispatcher = services.NewUserStrategy(v1enqueuer)
+	if config.SyncUserDeliveryTimeout > 0 {
+		userStrategy = services.NewSynchronousStrategy(userStrategy, messagesRepo, time.Duration(config.SyncUserDeliveryTimeout)*time.Millisecond)
+	}
+	spaceStrategy := services.NewSpaceStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo)
+	spaceDeveloperStrategy := services.NewSpaceDeveloperStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo)
+	spaceManagerStrategy := services.NewSpaceManagerStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo)
+	spaceAuditorStrategy := services.NewSpaceAuditorStrategy(tokenLoader, spaceLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo)
+	organizationManagerStrategy := services.NewOrganizationManagerStrategy(tokenLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo)
+	organizationStrategy := services.NewOrganizationAuditStrategy(
+		services.NewOrganizationStrategy(tokenLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo),
+		organizationManagerStrategy, organizationPoliciesRepo)
+	organizationAuditorStrategy := services.NewOrganizationAuditStrategy(
+		services.NewOrganizationAuditorStrategy(tokenLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo),
+		organizationManagerStrategy, organizationPoliciesRepo)
+	organizationBillingManagerStrategy := services.NewOrganizationAuditStrategy(
+		services.NewOrganizationBillingManagerStrategy(tokenLoader, organizationLoader, findsUserIDs, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo),
+		organizationManagerStrategy, organizationPoliciesRepo)
+	everyoneStrategy := services.NewEveryoneStrategy(tokenLoader, allUsers, v1enqueuer).WithUnsubscribeFiltering(suppressionsRepo)
+	uaaScopeStrategy := services.NewUAAScopeStrategy(tokenLoader, findsUserIDs, v1enqueuer, config.DefaultUAAScopes).WithUnsubscribeFiltering(suppressionsRepo)
+	uaaGroupStrategy := services.NewUAAGroupStrategy(tokenLoader, findsUserIDs, v1enqueuer, config.DefaultUAAScopes).WithUnsubscribeFiltering(suppressionsRepo)
+	appLoader := services.NewAppLoader(cloudController)
+	// Payloads are rendered before kinds routed to Slack are posted there,
+	// so that the post reads the same as the email.
+	payloadMetadata := func(strategy notify.Dispatcher, target string) notify.Dispatcher {
+		slack := services.NewSlackStrategy(strategy, kindsRepo, v1enqueuer)
+		return services.NewPayloadMetadataStrategy(slack, target, tokenLoader, spaceLoader, organizationLoader, appLoader)
+	}
+	unsubscribeImporter := services.NewUnsubscribeImporter(tokenLoader, uaaClient, kindsRepo, unsubscribesRepo, criticalUnsubscribesRepo, globalUnsubscribesRepo)
 
 	errorWriter := webutil.NewErrorWriter()
 
 	requestCounter := middleware.NewRequestCounter(mx.GetRouter())
-	requestLogging := middleware.NewRequestLogging(config.Logger, clock)
-	databaseAllocator := middleware.NewDatabaseAllocator(config.SQLDB, config.DBLoggingEnabled)
+	requestLogging := middleware.NewRequestLogging(config.Logger, clock, guidGenerator)
+	databaseAllocator := middleware.NewDatabaseAllocator(config.SQLDB, config.SQLReplicas, config.DBLoggingEnabled)
 	cors := middleware.NewCORS(config.CORSOrigin)
 	auth := func(scope ...string) middleware.Authenticator {
 		return middleware.NewAuthenticator(config.UAATokenValidator, scope...)
 	}
+	defaultZoneAuth := func(scope ...string) middleware.Authenticator {
+		authenticator := auth(scope...)
+		authenticator.DefaultZoneOnly = true
+		return authenticator
+	}
 
 	mx.GetRouter().Handle("/debug/metrics", exp.ExpHandler(metrics.DefaultRegistry)).Methods("GET")
 
+	catalog := docs.NewCatalog()
+	documented := docs.NewMuxer(audit.NewMuxer(mx, auditEventsRepo), catalog)
+
+	docs.Routes{
+		RequestCounter: requestCounter,
+		RequestLogging: requestLogging,
+
+		Catalog: catalog,
+	}.Register(documented)
+
 	info.Routes{
 		RequestCounter: requestCounter,
 		RequestLogging: requestLogging,
-	}.Register(mx)
 
-	preferences.Routes{
+		Capabilities: info.Capabilities{
+			Features: info.Features{
+				Channels:         []string{"email"},
+				ReadOnly:         config.ReadOnly,
+				UnsubscribeLinks: config.UnsubscribeURL != "",
+				HTMLSanitizer:    config.HTMLSanitizerMode,
+			},
+			Limits: info.Limits{
+				HTMLBodyBytes:          config.HTMLSizeLimit,
+				ClientRateLimit:        config.ClientRateLimit,
+				ClientRateLimitBurst:   config.ClientRateLimitBurst,
+				IdempotencyWindowHours: config.IdempotencyWindowHours,
+			},
+			Build: info.ReadBuild(),
+		},
+	}.Register(documented)
+
+	preferencesRoutes := preferences.Routes{
 		CORS:                                      cors,
 		RequestCounter:                            requestCounter,
 		RequestLogging:                            requestLogging,
@@ -122,31 +293,64 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		NotificationPreferencesReadAuthenticator:  auth("notification_preferences.read"),
 		NotificationPreferencesWriteAuthenticator: auth("notification_preferences.write"),
 		NotificationPreferencesAdminAuthenticator: auth("notification_preferences.admin"),
+		DefaultZonePreferencesAdminAuthenticator:  defaultZoneAuth("notification_preferences.admin"),
 
-		ErrorWriter:       errorWriter,
-		PreferencesFinder: preferencesFinder,
-		PreferenceUpdater: preferenceUpdater,
-	}.Register(mx)
+		ErrorWriter:        errorWriter,
+		PreferencesFinder:  preferencesFinder,
+		PreferenceUpdater:  preferenceUpdater,
+		UserMessagesFinder: userMessagesRepo,
+		Subscriber:         subscriber,
+		PreferencesPorter: services.NewPreferencesPorter(preferencesRepo, kindsRepo, globalUnsubscribesRepo, unsubscribesRepo,
+			subscriptionsRepo, digestPreferencesRepo),
+	}
+	unsubscribeTokens := common.NewUnsubscribeTokens(cloak, cloak.Keys()...)
+	if config.PreferenceChangeRevertURL != "" {
+		preferencesRoutes.PreferenceChanges = services.NewPreferenceChangeNotifier(registrar, services.NewUserStrategy(v1enqueuer),
+			preferenceUpdater, unsubscribeTokens, models.NewPreferenceRevertsRepo(), guidGenerator, clock, config.UAAClientID, config.PreferenceChangeRevertURL)
+	}
+	if config.UnsubscribeURL != "" {
+		preferencesRoutes.OneClickUnsubscriber = services.NewOneClickUnsubscriber(unsubscribeTokens,
+			kindsRepo, unsubscribesRepo, criticalUnsubscribesRepo, kindActivityRepo, clock)
+	}
+	preferencesRoutes.Register(documented)
 
 	clients.Routes{
-		RequestCounter:                   requestCounter,
-		RequestLogging:                   requestLogging,
-		DatabaseAllocator:                databaseAllocator,
-		NotificationsManageAuthenticator: auth("notifications.manage"),
+		RequestCounter:                          requestCounter,
+		RequestLogging:                          requestLogging,
+		DatabaseAllocator:                       databaseAllocator,
+		NotificationsManageAuthenticator:        auth("notifications.manage"),
+		NotificationsWriteOrManageAuthenticator: auth("notifications.write", "notifications.manage"),
 
-		ErrorWriter:      errorWriter,
-		TemplateAssigner: templatesCollection,
-	}.Register(mx)
+		ErrorWriter:          errorWriter,
+		TemplateAssigner:     templatesCollection,
+		RegistrationAuditor:  registrationAuditor,
+		RegistrationWebhooks: registrationWebhooksRepo,
+		Receipts:             receiptsRepo,
+		KindActivity:         kindActivityRepo,
+		Kinds:                kindsRepo,
+		Clock:                clock,
+	}.Register(documented)
 
-	messages.Routes{
+	messagesRoutes := messages.Routes{
 		RequestCounter:                               requestCounter,
 		RequestLogging:                               requestLogging,
 		DatabaseAllocator:                            databaseAllocator,
 		NotificationsWriteOrEmailsWriteAuthenticator: auth("notifications.write", "emails.write"),
+		NotificationsManageAuthenticator:             auth("notifications.manage"),
+		DefaultZoneManageAuthenticator:               defaultZoneAuth("notifications.manage"),
 
-		ErrorWriter:   errorWriter,
-		MessageFinder: messageFinder,
-	}.Register(mx)
+		ErrorWriter:     errorWriter,
+		MessageFinder:   messageFinder,
+		MessageLister:   messageLister,
+		MessageCanceler: messageCanceler,
+		MessageRetrier:  messageRetrier,
+		DSNRecorder:     services.NewDSNRecorder(messagesRepo),
+	}
+	if config.TrackingURL != "" {
+		messagesRoutes.EngagementRecorder = services.NewEngagementRecorder(common.NewTrackingTokens(cloak, cloak.Keys()...),
+			messagesRepo, kindActivityRepo, clock)
+	}
+	messagesRoutes.Register(documented)
 
 	templates.Routes{
 		RequestCounter:                          requestCounter,
@@ -155,6 +359,7 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		NotificationTemplatesReadAuthenticator:  auth("notification_templates.read"),
 		NotificationTemplatesWriteAuthenticator: auth("notification_templates.write"),
 		NotificationsManageAuthenticator:        auth("notifications.manage"),
+		SharedTemplatesWriteAuthenticator:       defaultZoneAuth("notification_templates.write"),
 
 		ErrorWriter:               errorWriter,
 		TemplateFinder:            templateFinder,
@@ -163,7 +368,13 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		TemplateDeleter:           templatesCollection,
 		TemplateLister:            templateLister,
 		TemplateAssociationLister: templatesCollection,
-	}.Register(mx)
+		TemplatePreviewer:         templatePreviewer,
+		TemplateSampler:           templateSampler,
+		TemplateTranslator:        templateTranslator,
+		TemplateVersioner:         templateVersioner,
+		TemplatePartials:          models.NewTemplatePartialsRepo(),
+		TemplateBundler:           services.NewTemplateBundler(templatesRepo, models.NewTemplateTranslationsRepo(), models.NewTemplatePartialsRepo()),
+	}.Register(documented)
 
 	notifications.Routes{
 		RequestCounter:                   requestCounter,
@@ -177,7 +388,40 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		NotificationsFinder:  notificationsFinder,
 		NotificationsUpdater: notificationsUpdater,
 		TemplateAssigner:     templatesCollection,
-	}.Register(mx)
+		RegistrationAuditor:  registrationAuditor,
+	}.Register(documented)
+
+	var spfIncludes []string
+	if include, ok := mail.TransportSPFIncludes[config.MailTransport]; ok {
+		spfIncludes = []string{include}
+	}
+
+	admin.Routes{
+		RequestCounter:                   requestCounter,
+		RequestLogging:                   requestLogging,
+		NotificationsManageAuthenticator: defaultZoneAuth("notifications.manage"),
+		DatabaseAllocator:                databaseAllocator,
+
+		ErrorWriter:          errorWriter,
+		JobReprioritizer:     jobReprioritizer,
+		QueueReplayer:        queueReplayer,
+		QueueStats:           gobbleQueue,
+		QueueSLA:             gobble.NewSLAMonitor(gobbleQueue, clock, config.QueueSLA),
+		WorkerPool:           config.WorkerPool,
+		UnsubscribeImporter:  unsubscribeImporter,
+		TemplatePackImporter: templatePackImporter,
+		OrganizationPolicies: organizationPoliciesRepo,
+		ClientSuspensions:    clientSuspensionsRepo,
+		CriticalApprover:     criticalApprover,
+		ClientQuotas:         clientQuotasRepo,
+		Clock:                clock,
+		ScheduledJobs:        scheduledJobsRepo,
+		SchedulerLeases:      schedulerLeasesRepo,
+		InstanceHeartbeats:   instanceHeartbeatsRepo,
+		SenderAuthentication: mail.NewSenderAuthentication(net.DefaultResolver),
+		Sender:               config.Sender,
+		SPFIncludes:          spfIncludes,
+	}.Register(documented)
 
 	notify.Routes{
 		RequestCounter:                  requestCounter,
@@ -185,16 +429,38 @@ func NewRouter(mx muxer, config Config) http.Handler {
 		DatabaseAllocator:               databaseAllocator,
 		NotificationsWriteAuthenticator: auth("notifications.write"),
 		EmailsWriteAuthenticator:        auth("emails.write"),
+		AudienceWriteAuthenticator:      defaultZoneAuth("notifications.write"),
+		RateLimiter:                     middleware.NewRateLimiter(config.ClientRateLimit, config.ClientRateLimitBurst, clock),
+		AnomalyDetector:                 middleware.NewAnomalyDetector(config.SendingAnomalyFactor, config.SendingAnomalyMinRequests, config.SendingAnomalyReauthorize, clientSuspensionsRepo, clock),
 
 		ErrorWriter:          errorWriter,
 		Notify:               notifyObj,
-		UserStrategy:         userStrategy,
-		SpaceStrategy:        spaceStrategy,
-		OrganizationStrategy: organizationStrategy,
-		EveryoneStrategy:     everyoneStrategy,
-		UAAScopeStrategy:     uaaScopeStrategy,
-		EmailStrategy:        emailStrategy,
-	}.Register(mx)
+		UserStrategy:         payloadMetadata(userStrategy, ""),
+		SpaceStrategy:        payloadMetadata(spaceStrategy, services.PayloadTargetSpace),
+		OrganizationStrategy: payloadMetadata(organizationStrategy, services.PayloadTargetOrganization),
+		EveryoneStrategy:     payloadMetadata(everyoneStrategy, ""),
+		UAAScopeStrategy:     payloadMetadata(uaaScopeStrategy, ""),
+		UAAGroupStrategy:     payloadMetadata(uaaGroupStrategy, ""),
+		EmailStrategy:        payloadMetadata(emailStrategy, ""),
+
+		OrganizationManagerStrategy:        payloadMetadata(organizationManagerStrategy, services.PayloadTargetOrganization),
+		OrganizationAuditorStrategy:        payloadMetadata(organizationAuditorStrategy, services.PayloadTargetOrganization),
+		OrganizationBillingManagerStrategy: payloadMetadata(organizationBillingManagerStrategy, services.PayloadTargetOrganization),
+
+		SpaceDeveloperStrategy: payloadMetadata(spaceDeveloperStrategy, services.PayloadTargetSpace),
+		SpaceManagerStrategy:   payloadMetadata(spaceManagerStrategy, services.PayloadTargetSpace),
+		SpaceAuditorStrategy:   payloadMetadata(spaceAuditorStrategy, services.PayloadTargetSpace),
+	}.Register(documented)
+
+	audit.Routes{
+		RequestCounter:                   requestCounter,
+		RequestLogging:                   requestLogging,
+		NotificationsManageAuthenticator: defaultZoneAuth("notifications.manage"),
+		DatabaseAllocator:                databaseAllocator,
+
+		AuditEvents: auditEventsRepo,
+		ErrorWriter: errorWriter,
+	}.Register(documented)
 
 	return mx
 }
@@ -0,0 +1,248 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"text/template"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var bundlePath = regexp.MustCompile(`/templates/(.*)/bundle`)
+
+type templateBundler interface {
+	Export(conn models.ConnectionInterface, templateID string) (services.TemplateBundle, error)
+	Import(conn models.ConnectionInterface, templateID string, bundle services.TemplateBundle) (services.TemplateBundle, error)
+}
+
+type bundleDocument struct {
+	Template struct {
+		ID       string          `json:"id"`
+		Name     string          `json:"name"`
+		Subject  string          `json:"subject"`
+		Text     string          `json:"text"`
+		HTML     string          `json:"html"`
+		Slack    string          `json:"slack,omitempty"`
+		Metadata json.RawMessage `json:"metadata"`
+	} `json:"template"`
+	Translations []bundleTranslationDocument `json:"translations"`
+	Partials     []bundlePartialDocument     `json:"partials"`
+}
+
+type bundleTranslationDocument struct {
+	Locale  string `json:"locale"`
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+}
+
+type bundlePartialDocument struct {
+	Name string `json:"name"`
+	Text string `json:"text"`
+	HTML string `json:"html"`
+}
+
+func newBundleDocument(bundle services.TemplateBundle) bundleDocument {
+	var document bundleDocument
+	document.Template.ID = bundle.Template.ID
+	document.Template.Name = bundle.Template.Name
+	document.Template.Subject = bundle.Template.Subject
+	document.Template.Text = bundle.Template.Text
+	document.Template.HTML = bundle.Template.HTML
+	document.Template.Slack = bundle.Template.Slack
+	document.Template.Metadata = json.RawMessage(bundle.Template.Metadata)
+	if len(document.Template.Metadata) == 0 {
+		document.Template.Metadata = json.RawMessage("{}")
+	}
+
+	document.Translations = []bundleTranslationDocument{}
+	for _, translation := range bundle.Translations {
+		document.Translations = append(document.Translations, bundleTranslationDocument{
+			Locale:  translation.Locale,
+			Subject: translation.Subject,
+			Text:    translation.Text,
+			HTML:    translation.HTML,
+		})
+	}
+
+	document.Partials = []bundlePartialDocument{}
+	for _, partial := range bundle.Partials {
+		document.Partials = append(document.Partials, bundlePartialDocument{
+			Name: partial.Name,
+			Text: partial.Text,
+			HTML: partial.HTML,
+		})
+	}
+
+	return document
+}
+
+// bundle checks the document the way the template, translation and partial
+// endpoints check each of its pieces.
+func (d bundleDocument) bundle() (services.TemplateBundle, error) {
+	if d.Template.Name == "" || d.Template.HTML == "" {
+		return services.TemplateBundle{}, webutil.ValidationError{Err: errors.New(`"template.name" and "template.html" are required`)}
+	}
+
+	metadata := d.Template.Metadata
+	if len(metadata) == 0 || string(metadata) == "null" {
+		metadata = json.RawMessage("{}")
+	}
+
+	var object map[string]interface{}
+	if json.Unmarshal(metadata, &object) != nil {
+		return services.TemplateBundle{}, webutil.ValidationError{Err: errors.New(`"template.metadata" must be an object`)}
+	}
+
+	params := TemplateParams{
+		Subject: d.Template.Subject,
+		Text:    d.Template.Text,
+		HTML:    d.Template.HTML,
+		Slack:   d.Template.Slack,
+	}
+	err := params.validateSyntax()
+	if err != nil {
+		return services.TemplateBundle{}, err
+	}
+	params.setDefaults()
+
+	bundle := services.TemplateBundle{
+		Template: models.Template{
+			Name:     d.Template.Name,
+			Subject:  params.Subject,
+			Text:     params.Text,
+			HTML:     params.HTML,
+			Slack:    params.Slack,
+			Metadata: string(metadata),
+		},
+	}
+
+	for _, translation := range d.Translations {
+		if translation.Locale == "" || translation.HTML == "" {
+			return services.TemplateBundle{}, webutil.ValidationError{Err: errors.New(`"locale" and "html" are required for each translation`)}
+		}
+
+		err := TemplateParams{Subject: translation.Subject, Text: translation.Text, HTML: translation.HTML}.validateSyntax()
+		if err != nil {
+			return services.TemplateBundle{}, err
+		}
+
+		bundle.Translations = append(bundle.Translations, models.TemplateTranslation{
+			Locale:  translation.Locale,
+			Subject: translation.Subject,
+			Text:    translation.Text,
+			HTML:    translation.HTML,
+		})
+	}
+
+	for _, partial := range d.Partials {
+		if !partialName.MatchString(partial.Name) {
+			return services.TemplateBundle{}, webutil.ValidationError{Err: errors.New("partial names may only contain lowercase letters, digits, \"-\" and \"_\"")}
+		}
+
+		for _, field := range []struct{ name, contents string }{{"Text", partial.Text}, {"HTML", partial.HTML}} {
+			_, err := template.New(partial.Name).Parse(field.contents)
+			if err != nil {
+				return services.TemplateBundle{}, webutil.ValidationError{Err: fmt.Errorf("%s syntax of partial %q is malformed please check your braces", field.name, partial.Name)}
+			}
+		}
+
+		bundle.Partials = append(bundle.Partials, models.TemplatePartial{
+			Name: partial.Name,
+			Text: partial.Text,
+			HTML: partial.HTML,
+		})
+	}
+
+	return bundle, nil
+}
+
+// GetBundleHandler exports a template with everything needed to render it
+// outside the service.
+type GetBundleHandler struct {
+	bundler     templateBundler
+	errorWriter errorWriter
+}
+
+func NewGetBundleHandler(bundler templateBundler, errWriter errorWriter) GetBundleHandler {
+	return GetBundleHandler{
+		bundler:     bundler,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetBundleHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	templateID := bundlePath.FindStringSubmatch(req.URL.Path)[1]
+
+	bundle, err := h.bundler.Export(context.Get("database").(DatabaseInterface).Connection(), templateID)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, newBundleDocument(bundle))
+}
+
+// PutBundleHandler imports a bundle as exported by GetBundleHandler. The
+// template and its partials are saved together or not at all.
+type PutBundleHandler struct {
+	bundler     templateBundler
+	errorWriter errorWriter
+}
+
+func NewPutBundleHandler(bundler templateBundler, errWriter errorWriter) PutBundleHandler {
+	return PutBundleHandler{
+		bundler:     bundler,
+		errorWriter: errWriter,
+	}
+}
+
+func (h PutBundleHandler) RequestBody() interface{} {
+	return bundleDocument{}
+}
+
+func (h PutBundleHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	templateID := bundlePath.FindStringSubmatch(req.URL.Path)[1]
+	defer req.Body.Close()
+
+	var document bundleDocument
+	err := json.NewDecoder(req.Body).Decode(&document)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	bundle, err := document.bundle()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	transaction := context.Get("database").(DatabaseInterface).Connection().Transaction()
+	err = transaction.Begin()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	bundle, err = h.bundler.Import(transaction, templateID, bundle)
+	if err != nil {
+		transaction.Rollback()
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	err = transaction.Commit()
+	if err != nil {
+		h.errorWriter.Write(w, models.TransactionCommitError{Err: err})
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, newBundleDocument(bundle))
+}
@@ -0,0 +1,198 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Bundle handlers", func() {
+	var (
+		bundler     *mocks.TemplateBundler
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		transaction *mocks.Transaction
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		bundle      services.TemplateBundle
+	)
+
+	BeforeEach(func() {
+		transaction = mocks.NewTransaction()
+		connection = mocks.NewConnection()
+		connection.TransactionCall.Returns.Transaction = transaction
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		bundler = mocks.NewTemplateBundler()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		bundle = services.TemplateBundle{
+			Template: models.Template{
+				ID:       "template-id",
+				Name:     "Raptor Alert",
+				Subject:  "{{.Subject}}",
+				Text:     `{{template "footer" .}}`,
+				HTML:     "<p>{{.HTML}}</p>",
+				Metadata: `{"team": "containment"}`,
+			},
+			Translations: []models.TemplateTranslation{
+				{TemplateID: "template-id", Locale: "fr", Subject: "Alerte", HTML: "<p>Alerte</p>"},
+			},
+			Partials: []models.TemplatePartial{
+				{Name: "footer", Text: "-- InGen", Version: 3},
+			},
+		}
+	})
+
+	Describe("GetBundleHandler", func() {
+		var handler templates.GetBundleHandler
+
+		BeforeEach(func() {
+			handler = templates.NewGetBundleHandler(bundler, errorWriter)
+		})
+
+		It("writes out the bundle of the template", func() {
+			bundler.ExportCall.Returns.Bundle = bundle
+
+			request, err := http.NewRequest("GET", "/templates/template-id/bundle", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"template": {
+					"id": "template-id",
+					"name": "Raptor Alert",
+					"subject": "{{.Subject}}",
+					"text": "{{template \"footer\" .}}",
+					"html": "<p>{{.HTML}}</p>",
+					"metadata": {"team": "containment"}
+				},
+				"translations": [
+					{"locale": "fr", "subject": "Alerte", "text": "", "html": "<p>Alerte</p>"}
+				],
+				"partials": [
+					{"name": "footer", "text": "-- InGen", "html": ""}
+				]
+			}`))
+
+			Expect(bundler.ExportCall.Receives.Connection).To(Equal(connection))
+			Expect(bundler.ExportCall.Receives.TemplateID).To(Equal("template-id"))
+		})
+
+		It("delegates errors to the error writer", func() {
+			bundler.ExportCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			request, err := http.NewRequest("GET", "/templates/template-id/bundle", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New("not found")}))
+		})
+	})
+
+	Describe("PutBundleHandler", func() {
+		var handler templates.PutBundleHandler
+
+		BeforeEach(func() {
+			handler = templates.NewPutBundleHandler(bundler, errorWriter)
+		})
+
+		put := func(body string) {
+			request, err := http.NewRequest("PUT", "/templates/template-id/bundle", strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+		}
+
+		It("imports the bundle in a transaction and writes out the result", func() {
+			bundler.ImportCall.Returns.Bundle = bundle
+
+			put(`{
+				"template": {"id": "other-id", "name": "Raptor Alert", "text": "{{template \"footer\" .}}", "html": "<p>{{.HTML}}</p>", "metadata": {"team": "containment"}},
+				"translations": [{"locale": "fr", "subject": "Alerte", "html": "<p>Alerte</p>"}],
+				"partials": [{"name": "footer", "text": "-- InGen"}]
+			}`)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(ContainSubstring(`"id":"template-id"`))
+
+			Expect(bundler.ImportCall.Receives.Connection).To(Equal(transaction))
+			Expect(bundler.ImportCall.Receives.TemplateID).To(Equal("template-id"))
+			Expect(bundler.ImportCall.Receives.Bundle).To(Equal(services.TemplateBundle{
+				Template: models.Template{
+					Name:     "Raptor Alert",
+					Subject:  "{{.Subject}}",
+					Text:     `{{template "footer" .}}`,
+					HTML:     "<p>{{.HTML}}</p>",
+					Metadata: `{"team": "containment"}`,
+				},
+				Translations: []models.TemplateTranslation{
+					{Locale: "fr", Subject: "Alerte", HTML: "<p>Alerte</p>"},
+				},
+				Partials: []models.TemplatePartial{
+					{Name: "footer", Text: "-- InGen"},
+				},
+			}))
+			Expect(transaction.CommitCall.WasCalled).To(BeTrue())
+		})
+
+		It("defaults the metadata to an empty object", func() {
+			put(`{"template": {"name": "Raptor Alert", "html": "<p>{{.HTML}}</p>"}}`)
+
+			Expect(bundler.ImportCall.Receives.Bundle.Template.Metadata).To(Equal("{}"))
+		})
+
+		DescribeTable("rejects invalid bundles",
+			func(body, message string) {
+				put(body)
+
+				Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ValidationError{Err: errors.New(message)}))
+				Expect(bundler.ImportCall.CallCount).To(Equal(0))
+			},
+			Entry("without a template name", `{"template": {"html": "<p></p>"}}`,
+				`"template.name" and "template.html" are required`),
+			Entry("with malformed template syntax", `{"template": {"name": "Alert", "html": "{{.HTML"}}`,
+				`HTML syntax is malformed please check your braces`),
+			Entry("with a translation without a locale", `{"template": {"name": "Alert", "html": "<p></p>"}, "translations": [{"html": "<p></p>"}]}`,
+				`"locale" and "html" are required for each translation`),
+			Entry("with a badly named partial", `{"template": {"name": "Alert", "html": "<p></p>"}, "partials": [{"name": "Footer"}]}`,
+				`partial names may only contain lowercase letters, digits, "-" and "_"`),
+			Entry("with malformed partial syntax", `{"template": {"name": "Alert", "html": "<p></p>"}, "partials": [{"name": "footer", "html": "{{"}]}`,
+				`HTML syntax of partial "footer" is malformed please check your braces`),
+		)
+
+		It("rejects bodies that are not JSON", func() {
+			put(`{`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+		})
+
+		It("rolls back when the bundle cannot be imported", func() {
+			bundler.ImportCall.Returns.Error = services.TemplateBundleError{Err: errors.New("template includes partial \"footer\", which is neither in the bundle nor saved")}
+
+			put(`{"template": {"name": "Raptor Alert", "html": "{{template \"footer\" .}}"}}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(services.TemplateBundleError{}))
+			Expect(transaction.RollbackCall.WasCalled).To(BeTrue())
+			Expect(transaction.CommitCall.WasCalled).To(BeFalse())
+		})
+	})
+})
@@ -1,7 +1,6 @@
 package templates
 
 import (
-	"encoding/json"
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
@@ -29,6 +28,10 @@ func NewCreateHandler(creator templateCreator, errWriter errorWriter) CreateHand
 	}
 }
 
+func (h CreateHandler) RequestBody() interface{} {
+	return TemplateParams{}
+}
+
 func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	templateParams, err := NewTemplateParams(req.Body)
 	if err != nil {
@@ -42,8 +45,10 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 		Name:     templateParams.Name,
 		Text:     templateParams.Text,
 		HTML:     templateParams.HTML,
+		Slack:    templateParams.Slack,
 		Subject:  templateParams.Subject,
 		Metadata: string(templateParams.Metadata),
+		ZoneID:   webutil.ZoneID(context),
 	})
 	if err != nil {
 		h.errorWriter.Write(w, webutil.TemplateCreateError{})
@@ -53,13 +58,3 @@ func (h CreateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, conte
 	w.WriteHeader(http.StatusCreated)
 	w.Write([]byte(`{"template_id":"` + template.ID + `"}`))
 }
-
-func writeJSON(w http.ResponseWriter, status int, object interface{}) {
-	output, err := json.Marshal(object)
-	if err != nil {
-		panic(err) // No JSON we write into a response should ever panic
-	}
-
-	w.WriteHeader(status)
-	w.Write(output)
-}
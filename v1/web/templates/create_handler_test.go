@@ -49,6 +49,7 @@ var _ = Describe("CreateHandler", func() {
 				"name":    "Emergency Template",
 				"text":    "Message to: {{.To}}. Raptor Alert.",
 				"html":    "<p>{{.ClientID}} you should run.</p>",
+				"slack":   "*{{.Subject}}* run.",
 				"subject": "Raptor Containment Unit Breached",
 			})
 			Expect(err).NotTo(HaveOccurred())
@@ -74,14 +75,24 @@ var _ = Describe("CreateHandler", func() {
 				Name:     "Emergency Template",
 				Text:     "Message to: {{.To}}. Raptor Alert.",
 				HTML:     "<p>{{.ClientID}} you should run.</p>",
+				Slack:    "*{{.Subject}}* run.",
 				Subject:  "Raptor Containment Unit Breached",
 				Metadata: "{}",
+				ZoneID:   "uaa",
 			}))
 
 			Expect(writer.Code).To(Equal(http.StatusCreated))
 			Expect(writer.Body.String()).To(MatchJSON(`{"template_id":"template-guid"}`))
 		})
 
+		It("creates the template in the zone of the token", func() {
+			context.Set(webutil.ZoneIDKey, "tenant-a")
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(creator.CreateCall.Receives.Template.ZoneID).To(Equal("tenant-a"))
+		})
+
 		Context("when an errors occurs", func() {
 			It("Writes a validation error to the errorwriter when the request is missing the name field", func() {
 				request, err = http.NewRequest("POST", "/templates", bytes.NewBuffer([]byte(`{"html": "<p>gobble</p>"}`)))
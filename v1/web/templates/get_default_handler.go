@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -13,20 +14,32 @@ type templateFinder interface {
 	FindByID(database services.DatabaseInterface, templateID string) (models.Template, error)
 }
 
+// GetDefaultHandler serves one of the built-in templates, which are
+// addressed by their own route rather than by ID.
 type GetDefaultHandler struct {
+	templateID  string
 	finder      templateFinder
 	errorWriter errorWriter
 }
 
 func NewGetDefaultHandler(finder templateFinder, errWriter errorWriter) GetDefaultHandler {
 	return GetDefaultHandler{
+		templateID:  models.DefaultTemplateID,
+		finder:      finder,
+		errorWriter: errWriter,
+	}
+}
+
+func NewGetDigestHandler(finder templateFinder, errWriter errorWriter) GetDefaultHandler {
+	return GetDefaultHandler{
+		templateID:  models.DigestTemplateID,
 		finder:      finder,
 		errorWriter: errWriter,
 	}
 }
 
 func (h GetDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
-	template, err := h.finder.FindByID(context.Get("database").(DatabaseInterface), models.DefaultTemplateID)
+	template, err := h.finder.FindByID(context.Get("database").(DatabaseInterface), h.templateID)
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
@@ -35,7 +48,8 @@ func (h GetDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, c
 	var metadata map[string]interface{}
 	err = json.Unmarshal([]byte(template.Metadata), &metadata)
 	if err != nil {
-		panic(err)
+		h.errorWriter.Write(w, err)
+		return
 	}
 
 	templateOutput := TemplateOutput{
@@ -43,8 +57,9 @@ func (h GetDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, c
 		Subject:  template.Subject,
 		HTML:     template.HTML,
 		Text:     template.Text,
+		Slack:    template.Slack,
 		Metadata: metadata,
 	}
 
-	writeJSON(w, http.StatusOK, templateOutput)
+	webutil.WriteJSON(w, http.StatusOK, templateOutput)
 }
@@ -73,4 +73,14 @@ var _ = Describe("GetDefaultHandler", func() {
 
 		Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("BANANA!!!")))
 	})
+
+	It("serves the digest template from its own route", func() {
+		handler = templates.NewGetDigestHandler(templateFinder, errorWriter)
+		request, err := http.NewRequest("GET", "/digest_template", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(httptest.NewRecorder(), request, context)
+
+		Expect(templateFinder.FindByIDCall.Receives.TemplateID).To(Equal(models.DigestTemplateID))
+	})
 })
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/ryanmoran/stack"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 )
 
 type TemplateOutput struct {
@@ -13,6 +15,7 @@ type TemplateOutput struct {
 	Subject  string                 `json:"subject"`
 	HTML     string                 `json:"html"`
 	Text     string                 `json:"text"`
+	Slack    string                 `json:"slack,omitempty"`
 	Metadata map[string]interface{} `json:"metadata"`
 }
 
@@ -49,8 +52,9 @@ func (h GetHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context
 		Subject:  template.Subject,
 		HTML:     template.HTML,
 		Text:     template.Text,
+		Slack:    template.Slack,
 		Metadata: metadata,
 	}
 
-	writeJSON(w, http.StatusOK, templateOutput)
+	webutil.WriteJSON(w, http.StatusOK, templateOutput)
 }
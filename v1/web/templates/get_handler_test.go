@@ -39,6 +39,7 @@ var _ = Describe("GetHandler", func() {
 				Subject:  "All about the {{.Subject}}",
 				Text:     "the template {{variable}}",
 				HTML:     "<p> the template {{variable}} </p>",
+				Slack:    "*{{.Subject}}*",
 				Metadata: `{"hello": "world"}`,
 			}
 			writer = httptest.NewRecorder()
@@ -74,11 +75,12 @@ var _ = Describe("GetHandler", func() {
 					panic(err)
 				}
 
-				Expect(template).To(HaveLen(5))
+				Expect(template).To(HaveLen(6))
 				Expect(template["name"]).To(Equal("The Name of The Template"))
 				Expect(template["subject"]).To(Equal("All about the {{.Subject}}"))
 				Expect(template["text"]).To(Equal("the template {{variable}}"))
 				Expect(template["html"]).To(Equal("<p> the template {{variable}} </p>"))
+				Expect(template["slack"]).To(Equal("*{{.Subject}}*"))
 				Expect(template["metadata"]).To(Equal(map[string]interface{}{"hello": "world"}))
 			})
 		})
@@ -5,6 +5,7 @@ import (
 	"regexp"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
@@ -40,7 +41,7 @@ func (h ListAssociationsHandler) ServeHTTP(w http.ResponseWriter, req *http.Requ
 	}
 
 	templateAssociationsDocument := h.mapToJSON(associations)
-	writeJSON(w, http.StatusOK, templateAssociationsDocument)
+	webutil.WriteJSON(w, http.StatusOK, templateAssociationsDocument)
 }
 
 func (h ListAssociationsHandler) parseTemplateID(path string) string {
@@ -4,11 +4,12 @@ import (
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 )
 
 type templateLister interface {
-	List(database services.DatabaseInterface) (templateSummaries map[string]services.TemplateSummary, err error)
+	List(database services.DatabaseInterface, zoneID string) (templateSummaries map[string]services.TemplateSummary, err error)
 }
 
 type ListHandler struct {
@@ -24,11 +25,11 @@ func NewListHandler(lister templateLister, errWriter errorWriter) ListHandler {
 }
 
 func (h ListHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
-	templates, err := h.lister.List(context.Get("database").(DatabaseInterface))
+	templates, err := h.lister.List(context.Get("database").(DatabaseInterface), webutil.ZoneID(context))
 	if err != nil {
 		h.errorWriter.Write(w, err)
 		return
 	}
 
-	writeJSON(w, http.StatusOK, templates)
+	webutil.WriteJSON(w, http.StatusOK, templates)
 }
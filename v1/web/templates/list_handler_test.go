@@ -10,6 +10,7 @@ import (
 	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
 	"github.com/ryanmoran/stack"
 
 	. "github.com/onsi/ginkgo/v2"
@@ -68,6 +69,14 @@ var _ = Describe("ListHandler", func() {
 			It("calls list on its lister", func() {
 				handler.ServeHTTP(writer, request, context)
 				Expect(lister.ListCall.Receives.Database).To(Equal(database))
+				Expect(lister.ListCall.Receives.ZoneID).To(Equal("uaa"))
+			})
+
+			It("lists the templates of the zone of the token", func() {
+				context.Set(webutil.ZoneIDKey, "tenant-a")
+
+				handler.ServeHTTP(writer, request, context)
+				Expect(lister.ListCall.Receives.ZoneID).To(Equal("tenant-a"))
 			})
 
 			It("writes out the lister's response", func() {
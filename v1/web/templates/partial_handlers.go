@@ -0,0 +1,190 @@
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"text/template"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/cloudfoundry-incubator/notifications/valiant"
+	"github.com/ryanmoran/stack"
+)
+
+var (
+	partialPath = regexp.MustCompile(`/template_partials/(.*)`)
+	partialName = regexp.MustCompile(`^[a-z0-9_-]+$`)
+)
+
+type partialLister interface {
+	FindAll(conn models.ConnectionInterface) ([]models.TemplatePartial, error)
+}
+
+type partialsRepo interface {
+	partialLister
+	Find(conn models.ConnectionInterface, name string) (models.TemplatePartial, error)
+	Upsert(conn models.ConnectionInterface, partial models.TemplatePartial) (models.TemplatePartial, error)
+	Destroy(conn models.ConnectionInterface, name string) error
+}
+
+type partialDocument struct {
+	Name      string    `json:"name"`
+	Text      string    `json:"text"`
+	HTML      string    `json:"html"`
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newPartialDocument(partial models.TemplatePartial) partialDocument {
+	return partialDocument{
+		Name:      partial.Name,
+		Text:      partial.Text,
+		HTML:      partial.HTML,
+		Version:   partial.Version,
+		UpdatedAt: partial.UpdatedAt,
+	}
+}
+
+type PutPartialHandler struct {
+	partials    partialsRepo
+	errorWriter errorWriter
+}
+
+func NewPutPartialHandler(partials partialsRepo, errWriter errorWriter) PutPartialHandler {
+	return PutPartialHandler{
+		partials:    partials,
+		errorWriter: errWriter,
+	}
+}
+
+type partialParams struct {
+	Text string `json:"text"`
+	HTML string `json:"html"`
+}
+
+func (h PutPartialHandler) RequestBody() interface{} {
+	return partialParams{}
+}
+
+func (h PutPartialHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	name := partialPath.FindStringSubmatch(req.URL.Path)[1]
+	defer req.Body.Close()
+
+	if !partialName.MatchString(name) {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New("partial names may only contain lowercase letters, digits, \"-\" and \"_\"")})
+		return
+	}
+
+	var params partialParams
+
+	err := valiant.NewValidator(req.Body).Validate(&params)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	if params.Text == "" && params.HTML == "" {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`either "text" or "html" must be provided`)})
+		return
+	}
+
+	for _, field := range []struct{ name, contents string }{{"Text", params.Text}, {"HTML", params.HTML}} {
+		_, err := template.New(name).Parse(field.contents)
+		if err != nil {
+			h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf("%s syntax is malformed please check your braces", field.name)})
+			return
+		}
+	}
+
+	partial, err := h.partials.Upsert(context.Get("database").(DatabaseInterface).Connection(), models.TemplatePartial{
+		Name: name,
+		Text: params.Text,
+		HTML: params.HTML,
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, newPartialDocument(partial))
+}
+
+type GetPartialHandler struct {
+	partials    partialsRepo
+	errorWriter errorWriter
+}
+
+func NewGetPartialHandler(partials partialsRepo, errWriter errorWriter) GetPartialHandler {
+	return GetPartialHandler{
+		partials:    partials,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetPartialHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	name := partialPath.FindStringSubmatch(req.URL.Path)[1]
+
+	partial, err := h.partials.Find(context.Get("database").(DatabaseInterface).Connection(), name)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, newPartialDocument(partial))
+}
+
+type ListPartialsHandler struct {
+	partials    partialsRepo
+	errorWriter errorWriter
+}
+
+func NewListPartialsHandler(partials partialsRepo, errWriter errorWriter) ListPartialsHandler {
+	return ListPartialsHandler{
+		partials:    partials,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListPartialsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	partials, err := h.partials.FindAll(context.Get("database").(DatabaseInterface).Connection())
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	documents := []partialDocument{}
+	for _, partial := range partials {
+		documents = append(documents, newPartialDocument(partial))
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, map[string][]partialDocument{"partials": documents})
+}
+
+// DeletePartialHandler removes a partial. Messages whose templates still
+// include it fail to render until it is put back.
+type DeletePartialHandler struct {
+	partials    partialsRepo
+	errorWriter errorWriter
+}
+
+func NewDeletePartialHandler(partials partialsRepo, errWriter errorWriter) DeletePartialHandler {
+	return DeletePartialHandler{
+		partials:    partials,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DeletePartialHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	name := partialPath.FindStringSubmatch(req.URL.Path)[1]
+
+	err := h.partials.Destroy(context.Get("database").(DatabaseInterface).Connection(), name)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,201 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Partial handlers", func() {
+	var (
+		partials    *mocks.TemplatePartialsRepo
+		errorWriter *mocks.ErrorWriter
+		connection  *mocks.Connection
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+		footer      models.TemplatePartial
+	)
+
+	BeforeEach(func() {
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		partials = mocks.NewTemplatePartialsRepo()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		footer = models.TemplatePartial{
+			Name:      "footer",
+			Text:      "-- {{.ClientID}}",
+			HTML:      "<footer>{{.ClientID}}</footer>",
+			Version:   2,
+			UpdatedAt: time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC),
+		}
+	})
+
+	Describe("PutPartialHandler", func() {
+		var handler templates.PutPartialHandler
+
+		BeforeEach(func() {
+			handler = templates.NewPutPartialHandler(partials, errorWriter)
+		})
+
+		put := func(path, body string) {
+			request, err := http.NewRequest("PUT", path, strings.NewReader(body))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+		}
+
+		It("saves the partial and writes it out", func() {
+			partials.UpsertCall.Returns.Partial = footer
+
+			put("/template_partials/footer", `{"text": "-- {{.ClientID}}", "html": "<footer>{{.ClientID}}</footer>"}`)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"name": "footer",
+				"text": "-- {{.ClientID}}",
+				"html": "<footer>{{.ClientID}}</footer>",
+				"version": 2,
+				"updated_at": "2026-10-17T09:30:00Z"
+			}`))
+			Expect(partials.UpsertCall.Receives.Connection).To(Equal(connection))
+			Expect(partials.UpsertCall.Receives.Partial).To(Equal(models.TemplatePartial{
+				Name: "footer",
+				Text: "-- {{.ClientID}}",
+				HTML: "<footer>{{.ClientID}}</footer>",
+			}))
+		})
+
+		It("writes a validation error when the name has other characters", func() {
+			put("/template_partials/Footer.html", `{"html": "<footer></footer>"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(partials.UpsertCall.Receives.Partial).To(Equal(models.TemplatePartial{}))
+		})
+
+		It("writes a validation error when neither text nor html is given", func() {
+			put("/template_partials/footer", `{}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New(`either "text" or "html" must be provided`)}))
+		})
+
+		It("writes a validation error when the html is malformed", func() {
+			put("/template_partials/footer", `{"html": "<footer>{{.ClientID</footer>"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New("HTML syntax is malformed please check your braces")}))
+		})
+
+		It("writes a parse error for malformed JSON", func() {
+			put("/template_partials/footer", `{"html": `)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+		})
+
+		It("writes the error returned by the repo", func() {
+			partials.UpsertCall.Returns.Error = errors.New("database is down")
+
+			put("/template_partials/footer", `{"html": "<footer></footer>"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+		})
+	})
+
+	Describe("GetPartialHandler", func() {
+		It("writes out the partial", func() {
+			partials.FindCall.Returns.Partial = footer
+
+			request, err := http.NewRequest("GET", "/template_partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			templates.NewGetPartialHandler(partials, errorWriter).ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body.String()).To(ContainSubstring(`"version":2`))
+			Expect(partials.FindCall.Receives.Name).To(Equal("footer"))
+		})
+
+		It("writes the error when the partial cannot be found", func() {
+			partials.FindCall.Returns.Error = models.NotFoundError{Err: errors.New("Partial \"footer\" could not be found")}
+
+			request, err := http.NewRequest("GET", "/template_partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			templates.NewGetPartialHandler(partials, errorWriter).ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+
+	Describe("ListPartialsHandler", func() {
+		It("writes out every partial", func() {
+			partials.FindAllCall.Returns.Partials = []models.TemplatePartial{footer}
+
+			request, err := http.NewRequest("GET", "/template_partials", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			templates.NewListPartialsHandler(partials, errorWriter).ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"partials": [
+					{
+						"name": "footer",
+						"text": "-- {{.ClientID}}",
+						"html": "<footer>{{.ClientID}}</footer>",
+						"version": 2,
+						"updated_at": "2026-10-17T09:30:00Z"
+					}
+				]
+			}`))
+		})
+
+		It("writes an empty list when there are no partials", func() {
+			request, err := http.NewRequest("GET", "/template_partials", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			templates.NewListPartialsHandler(partials, errorWriter).ServeHTTP(writer, request, context)
+
+			Expect(writer.Body).To(MatchJSON(`{"partials": []}`))
+		})
+	})
+
+	Describe("DeletePartialHandler", func() {
+		It("deletes the partial", func() {
+			request, err := http.NewRequest("DELETE", "/template_partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			templates.NewDeletePartialHandler(partials, errorWriter).ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(partials.DestroyCall.Receives.Connection).To(Equal(connection))
+			Expect(partials.DestroyCall.Receives.Name).To(Equal("footer"))
+		})
+
+		It("writes the error returned by the repo", func() {
+			partials.DestroyCall.Returns.Error = models.NotFoundError{Err: errors.New("Partial \"footer\" could not be found")}
+
+			request, err := http.NewRequest("DELETE", "/template_partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			templates.NewDeletePartialHandler(partials, errorWriter).ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+})
@@ -0,0 +1,108 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+type templatePreviewer interface {
+	Preview(templates common.Templates, variables services.PreviewVariables) (services.TemplatePreview, error)
+}
+
+type PreviewParams struct {
+	Subject   string `json:"subject"`
+	Text      string `json:"text"`
+	HTML      string `json:"html"`
+	Variables struct {
+		Subject           string `json:"subject"`
+		Text              string `json:"text"`
+		HTML              string `json:"html"`
+		KindID            string `json:"kind_id"`
+		KindDescription   string `json:"kind_description"`
+		SourceDescription string `json:"source_description"`
+		ClientID          string `json:"client_id"`
+		MessageID         string `json:"message_id"`
+		UserGUID          string `json:"user_guid"`
+		Email             string `json:"to"`
+		ReplyTo           string `json:"reply_to"`
+		Space             string `json:"space"`
+		Organization      string `json:"organization"`
+		OrganizationRole  string `json:"organization_role"`
+		Scope             string `json:"scope"`
+		Endorsement       string `json:"endorsement"`
+	} `json:"variables"`
+}
+
+type PreviewHandler struct {
+	previewer   templatePreviewer
+	partials    partialLister
+	errorWriter errorWriter
+}
+
+func NewPreviewHandler(previewer templatePreviewer, partials partialLister, errWriter errorWriter) PreviewHandler {
+	return PreviewHandler{
+		previewer:   previewer,
+		partials:    partials,
+		errorWriter: errWriter,
+	}
+}
+
+func (h PreviewHandler) RequestBody() interface{} {
+	return PreviewParams{}
+}
+
+func (h PreviewHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	var params PreviewParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	if params.Text == "" && params.HTML == "" {
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`either "text" or "html" must be provided`)})
+		return
+	}
+
+	if params.Subject == "" {
+		params.Subject = "{{.Subject}}"
+	}
+
+	templates := common.Templates{
+		Subject: params.Subject,
+		Text:    params.Text,
+		HTML:    params.HTML,
+	}
+
+	partials, err := h.partials.FindAll(context.Get("database").(DatabaseInterface).Connection())
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	for _, partial := range partials {
+		templates.Partials = append(templates.Partials, common.Partial{
+			Name: partial.Name,
+			Text: partial.Text,
+			HTML: partial.HTML,
+		})
+	}
+
+	preview, err := h.previewer.Preview(templates, services.PreviewVariables(params.Variables))
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, map[string]string{
+		"subject": preview.Subject,
+		"text":    preview.Text,
+		"html":    preview.HTML,
+	})
+}
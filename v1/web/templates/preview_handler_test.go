@@ -0,0 +1,145 @@
+package templates_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PreviewHandler", func() {
+	var (
+		handler     templates.PreviewHandler
+		previewer   *mocks.TemplatePreviewer
+		partials    *mocks.TemplatePartialsRepo
+		connection  *mocks.Connection
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		previewer = mocks.NewTemplatePreviewer()
+		previewer.PreviewCall.Returns.Preview = services.TemplatePreview{
+			Subject: "Raptor Alert",
+			Text:    "Run, user-123",
+			HTML:    "<p>Run, user-123</p>",
+		}
+		partials = mocks.NewTemplatePartialsRepo()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		connection = mocks.NewConnection()
+		database := mocks.NewDatabase()
+		database.ConnectionCall.Returns.Connection = connection
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		handler = templates.NewPreviewHandler(previewer, partials, errorWriter)
+	})
+
+	serve := func(body string) {
+		request, err := http.NewRequest("POST", "/templates/preview", bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("renders the templates with the sample variables", func() {
+		serve(`{
+			"subject": "{{.Subject}}",
+			"text": "{{.Text}}",
+			"html": "<p>{{.HTML}}</p>",
+			"variables": {
+				"subject": "Raptor Alert",
+				"text": "Run, user-123",
+				"html": "Run, user-123",
+				"kind_id": "raptors",
+				"user_guid": "user-123",
+				"to": "user-123@example.com"
+			}
+		}`)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"subject": "Raptor Alert",
+			"text": "Run, user-123",
+			"html": "<p>Run, user-123</p>"
+		}`))
+
+		Expect(previewer.PreviewCall.Receives.Templates).To(Equal(common.Templates{
+			Subject: "{{.Subject}}",
+			Text:    "{{.Text}}",
+			HTML:    "<p>{{.HTML}}</p>",
+		}))
+		Expect(previewer.PreviewCall.Receives.Variables).To(Equal(services.PreviewVariables{
+			Subject:  "Raptor Alert",
+			Text:     "Run, user-123",
+			HTML:     "Run, user-123",
+			KindID:   "raptors",
+			UserGUID: "user-123",
+			Email:    "user-123@example.com",
+		}))
+	})
+
+	It("defaults the subject template", func() {
+		serve(`{"html": "<p>{{.HTML}}</p>"}`)
+
+		Expect(previewer.PreviewCall.Receives.Templates.Subject).To(Equal("{{.Subject}}"))
+	})
+
+	It("renders the templates with the saved partials", func() {
+		partials.FindAllCall.Returns.Partials = []models.TemplatePartial{
+			{Name: "footer", Text: "-- {{.ClientID}}", HTML: "<footer>{{.ClientID}}</footer>", Version: 2},
+		}
+
+		serve(`{"html": "<p>{{.HTML}}</p>{{template \"footer\" .}}"}`)
+
+		Expect(partials.FindAllCall.Receives.Connection).To(Equal(connection))
+		Expect(previewer.PreviewCall.Receives.Templates.Partials).To(Equal([]common.Partial{
+			{Name: "footer", Text: "-- {{.ClientID}}", HTML: "<footer>{{.ClientID}}</footer>"},
+		}))
+	})
+
+	Context("failure cases", func() {
+		It("writes a parse error for malformed JSON", func() {
+			serve(`{"html": `)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+		})
+
+		It("writes a validation error when neither text nor html is given", func() {
+			serve(`{"subject": "hello"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		})
+
+		It("writes the error returned when the partials cannot be loaded", func() {
+			partials.FindAllCall.Returns.Error = errors.New("database is down")
+
+			serve(`{"html": "<p>{{.HTML}}</p>"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("database is down"))
+			Expect(previewer.PreviewCall.Receives.Templates).To(Equal(common.Templates{}))
+		})
+
+		It("writes the error returned by the previewer", func() {
+			previewer.PreviewCall.Returns.Error = services.TemplatePreviewError{Err: errors.New("template: compileTemplate:1: unclosed action")}
+
+			serve(`{"html": "<p>{{.HTML</p>"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(services.TemplatePreviewError{Err: errors.New("template: compileTemplate:1: unclosed action")}))
+		})
+	})
+})
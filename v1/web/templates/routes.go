@@ -14,6 +14,11 @@ type Routes struct {
 	NotificationTemplatesWriteAuthenticator stack.Middleware
 	NotificationsManageAuthenticator        stack.Middleware
 
+	// SharedTemplatesWriteAuthenticator guards the default and digest
+	// templates and the partials, which every zone renders with, so that
+	// only the default zone may change them.
+	SharedTemplatesWriteAuthenticator stack.Middleware
+
 	ErrorWriter               errorWriter
 	TemplateFinder            templateFinder
 	TemplateLister            templateLister
@@ -21,15 +26,39 @@ type Routes struct {
 	TemplateCreator           templateCreator
 	TemplateDeleter           templateDeleter
 	TemplateAssociationLister templateAssociationLister
+	TemplatePreviewer         templatePreviewer
+	TemplateSampler           templateSampler
+	TemplateTranslator        templateTranslator
+	TemplateVersioner         templateVersioner
+	TemplatePartials          partialsRepo
+	TemplateBundler           templateBundler
 }
 
 func (r Routes) Register(m muxer) {
+	sharedZoneGuard := NewZoneGuard(r.TemplateFinder, r.ErrorWriter, true)
+	zoneGuard := NewZoneGuard(r.TemplateFinder, r.ErrorWriter, false)
+
 	m.Handle("GET", "/default_template", NewGetDefaultHandler(r.TemplateFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
-	m.Handle("PUT", "/default_template", NewUpdateDefaultHandler(r.TemplateUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/default_template", NewUpdateDefaultHandler(r.TemplateUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SharedTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/digest_template", NewGetDigestHandler(r.TemplateFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/digest_template", NewUpdateDigestHandler(r.TemplateUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SharedTemplatesWriteAuthenticator, r.DatabaseAllocator)
 	m.Handle("GET", "/templates", NewListHandler(r.TemplateLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
 	m.Handle("POST", "/templates", NewCreateHandler(r.TemplateCreator, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("GET", "/templates/{template_id}", NewGetHandler(r.TemplateFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
-	m.Handle("PUT", "/templates/{template_id}", NewUpdateHandler(r.TemplateUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("DELETE", "/templates/{template_id}", NewDeleteHandler(r.TemplateDeleter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
-	m.Handle("GET", "/templates/{template_id}/associations", NewListAssociationsHandler(r.TemplateAssociationLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/templates/preview", NewPreviewHandler(r.TemplatePreviewer, r.TemplatePartials, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("POST", "/templates/preview/samples", NewSamplePreviewHandler(r.TemplateSampler, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/templates/{template_id}", NewGetHandler(r.TemplateFinder, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator, sharedZoneGuard)
+	m.Handle("PUT", "/templates/{template_id}", NewUpdateHandler(r.TemplateUpdater, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("DELETE", "/templates/{template_id}", NewDeleteHandler(r.TemplateDeleter, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("GET", "/templates/{template_id}/translations/{locale}", NewGetTranslationHandler(r.TemplateTranslator, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator, sharedZoneGuard)
+	m.Handle("PUT", "/templates/{template_id}/translations/{locale}", NewPutTranslationHandler(r.TemplateTranslator, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("DELETE", "/templates/{template_id}/translations/{locale}", NewDeleteTranslationHandler(r.TemplateTranslator, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("GET", "/templates/{template_id}/bundle", NewGetBundleHandler(r.TemplateBundler, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator, sharedZoneGuard)
+	m.Handle("PUT", "/templates/{template_id}/bundle", NewPutBundleHandler(r.TemplateBundler, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("GET", "/templates/{template_id}/versions", NewListVersionsHandler(r.TemplateVersioner, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator, sharedZoneGuard)
+	m.Handle("PUT", "/templates/{template_id}/versions/{version}/activate", NewActivateVersionHandler(r.TemplateVersioner, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesWriteAuthenticator, r.DatabaseAllocator, zoneGuard)
+	m.Handle("GET", "/template_partials", NewListPartialsHandler(r.TemplatePartials, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/template_partials/{name}", NewGetPartialHandler(r.TemplatePartials, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationTemplatesReadAuthenticator, r.DatabaseAllocator)
+	m.Handle("PUT", "/template_partials/{name}", NewPutPartialHandler(r.TemplatePartials, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SharedTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("DELETE", "/template_partials/{name}", NewDeletePartialHandler(r.TemplatePartials, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.SharedTemplatesWriteAuthenticator, r.DatabaseAllocator)
+	m.Handle("GET", "/templates/{template_id}/associations", NewListAssociationsHandler(r.TemplateAssociationLister, r.ErrorWriter), r.RequestLogging, r.RequestCounter, r.NotificationsManageAuthenticator, r.DatabaseAllocator, zoneGuard)
 }
@@ -27,6 +27,12 @@ var _ = Describe("Routes", func() {
 			TemplateDeleter:           mocks.NewTemplateDeleter(),
 			TemplateLister:            mocks.NewTemplateLister(),
 			TemplateAssociationLister: mocks.NewTemplateAssociationLister(),
+			TemplatePreviewer:         mocks.NewTemplatePreviewer(),
+			TemplateSampler:           mocks.NewTemplateSampler(),
+			TemplateTranslator:        mocks.NewTemplateTranslator(),
+			TemplateVersioner:         mocks.NewTemplateVersioner(),
+			TemplatePartials:          mocks.NewTemplatePartialsRepo(),
+			TemplateBundler:           mocks.NewTemplateBundler(),
 
 			RequestCounter:                          middleware.RequestCounter{},
 			RequestLogging:                          middleware.RequestLogging{},
@@ -34,6 +40,8 @@ var _ = Describe("Routes", func() {
 			NotificationsManageAuthenticator:        middleware.Authenticator{Scopes: []string{"notifications.manage"}},
 			NotificationTemplatesReadAuthenticator:  middleware.Authenticator{Scopes: []string{"notification_templates.read"}},
 			NotificationTemplatesWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notification_templates.write"}},
+
+			SharedTemplatesWriteAuthenticator: middleware.Authenticator{Scopes: []string{"notification_templates.write"}, DefaultZoneOnly: true},
 		}.Register(muxer)
 	})
 
@@ -63,6 +71,84 @@ var _ = Describe("Routes", func() {
 		})
 	})
 
+	Describe("/template_partials", func() {
+		It("routes GET /template_partials", func() {
+			request, err := http.NewRequest("GET", "/template_partials", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.ListPartialsHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes GET /template_partials/{name}", func() {
+			request, err := http.NewRequest("GET", "/template_partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.GetPartialHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes PUT /template_partials/{name}", func() {
+			request, err := http.NewRequest("PUT", "/template_partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.PutPartialHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+			Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+		})
+
+		It("routes DELETE /template_partials/{name}", func() {
+			request, err := http.NewRequest("DELETE", "/template_partials/footer", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.DeletePartialHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+			Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+		})
+	})
+
+	Describe("/templates/preview", func() {
+		It("routes POST /templates/preview", func() {
+			request, err := http.NewRequest("POST", "/templates/preview", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.PreviewHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes POST /templates/preview/samples", func() {
+			request, err := http.NewRequest("POST", "/templates/preview/samples", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.SamplePreviewHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+		})
+	})
+
 	Describe("/templates/{template_id}", func() {
 		It("routes GET /templates/{template_id}", func() {
 			request, err := http.NewRequest("GET", "/templates/{template_id}", nil)
@@ -70,7 +156,7 @@ var _ = Describe("Routes", func() {
 
 			s := muxer.Match(request).(stack.Stack)
 			Expect(s.Handler).To(BeAssignableToTypeOf(templates.GetHandler{}))
-			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
 
 			authenticator := s.Middleware[2].(middleware.Authenticator)
 			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
@@ -82,7 +168,7 @@ var _ = Describe("Routes", func() {
 
 			s := muxer.Match(request).(stack.Stack)
 			Expect(s.Handler).To(BeAssignableToTypeOf(templates.UpdateHandler{}))
-			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
 
 			authenticator := s.Middleware[2].(middleware.Authenticator)
 			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
@@ -94,7 +180,7 @@ var _ = Describe("Routes", func() {
 
 			s := muxer.Match(request).(stack.Stack)
 			Expect(s.Handler).To(BeAssignableToTypeOf(templates.DeleteHandler{}))
-			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
 
 			authenticator := s.Middleware[2].(middleware.Authenticator)
 			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
@@ -106,13 +192,103 @@ var _ = Describe("Routes", func() {
 
 			s := muxer.Match(request).(stack.Stack)
 			Expect(s.Handler).To(BeAssignableToTypeOf(templates.ListAssociationsHandler{}))
-			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
 
 			authenticator := s.Middleware[2].(middleware.Authenticator)
 			Expect(authenticator.Scopes).To(Equal([]string{"notifications.manage"}))
 		})
 	})
 
+	Describe("/templates/{template_id}/translations/{locale}", func() {
+		It("routes GET /templates/{template_id}/translations/{locale}", func() {
+			request, err := http.NewRequest("GET", "/templates/some-template-id/translations/fr-CA", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.GetTranslationHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes PUT /templates/{template_id}/translations/{locale}", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/translations/fr-CA", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.PutTranslationHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+		})
+
+		It("routes DELETE /templates/{template_id}/translations/{locale}", func() {
+			request, err := http.NewRequest("DELETE", "/templates/some-template-id/translations/fr-CA", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.DeleteTranslationHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+		})
+	})
+
+	Describe("/templates/{template_id}/bundle", func() {
+		It("routes GET /templates/{template_id}/bundle", func() {
+			request, err := http.NewRequest("GET", "/templates/some-template-id/bundle", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.GetBundleHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes PUT /templates/{template_id}/bundle", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/bundle", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.PutBundleHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+		})
+	})
+
+	Describe("/templates/{template_id}/versions", func() {
+		It("routes GET /templates/{template_id}/versions", func() {
+			request, err := http.NewRequest("GET", "/templates/some-template-id/versions", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.ListVersionsHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes PUT /templates/{template_id}/versions/{version}/activate", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/versions/2/activate", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.ActivateVersionHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{}, templates.ZoneGuard{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+		})
+	})
+
 	Describe("/default_template", func() {
 		It("routes GET /default_template", func() {
 			request, err := http.NewRequest("GET", "/default_template", nil)
@@ -136,6 +312,34 @@ var _ = Describe("Routes", func() {
 
 			authenticator := s.Middleware[2].(middleware.Authenticator)
 			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+			Expect(authenticator.DefaultZoneOnly).To(BeTrue())
+		})
+	})
+
+	Describe("/digest_template", func() {
+		It("routes GET /digest_template", func() {
+			request, err := http.NewRequest("GET", "/digest_template", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.GetDefaultHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.read"}))
+		})
+
+		It("routes PUT /digest_template", func() {
+			request, err := http.NewRequest("PUT", "/digest_template", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			s := muxer.Match(request).(stack.Stack)
+			Expect(s.Handler).To(BeAssignableToTypeOf(templates.UpdateDefaultHandler{}))
+			ExpectToContainMiddlewareStack(s.Middleware, middleware.RequestLogging{}, middleware.RequestCounter{}, middleware.Authenticator{}, middleware.DatabaseAllocator{})
+
+			authenticator := s.Middleware[2].(middleware.Authenticator)
+			Expect(authenticator.Scopes).To(Equal([]string{"notification_templates.write"}))
+			Expect(authenticator.DefaultZoneOnly).To(BeTrue())
 		})
 	})
 })
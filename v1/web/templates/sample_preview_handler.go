@@ -0,0 +1,126 @@
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+const defaultSampleCount = 10
+
+type templateSampler interface {
+	Preview(database services.DatabaseInterface, proposed common.Templates, clientID, kindID string, count int) ([]services.TemplateSamplePreview, error)
+}
+
+type SamplePreviewParams struct {
+	ClientID string `json:"client_id"`
+	KindID   string `json:"kind_id"`
+	Count    int    `json:"count"`
+	Subject  string `json:"subject"`
+	Text     string `json:"text"`
+	HTML     string `json:"html"`
+}
+
+type samplePreviewDocument struct {
+	MessageID     string              `json:"message_id"`
+	VCAPRequestID string              `json:"vcap_request_id"`
+	SampledAt     time.Time           `json:"sampled_at"`
+	Status        string              `json:"status"`
+	Error         string              `json:"error,omitempty"`
+	Diff          map[string][]string `json:"diff,omitempty"`
+}
+
+// SamplePreviewHandler renders proposed templates against the payloads of
+// notifications of a kind that were recently sent, to catch the ones a
+// template change would break before it is made.
+type SamplePreviewHandler struct {
+	sampler     templateSampler
+	errorWriter errorWriter
+}
+
+func NewSamplePreviewHandler(sampler templateSampler, errWriter errorWriter) SamplePreviewHandler {
+	return SamplePreviewHandler{
+		sampler:     sampler,
+		errorWriter: errWriter,
+	}
+}
+
+func (h SamplePreviewHandler) RequestBody() interface{} {
+	return SamplePreviewParams{}
+}
+
+func (h SamplePreviewHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	var params SamplePreviewParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		h.errorWriter.Write(w, webutil.ParseError{})
+		return
+	}
+
+	switch {
+	case params.ClientID == "" || params.KindID == "":
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`"client_id" and "kind_id" must be provided`)})
+		return
+	case params.Text == "" && params.HTML == "":
+		h.errorWriter.Write(w, webutil.ValidationError{Err: errors.New(`either "text" or "html" must be provided`)})
+		return
+	case params.Count < 0 || params.Count > models.PayloadSamplesKept:
+		h.errorWriter.Write(w, webutil.ValidationError{Err: fmt.Errorf(`"count" must be from 1 to %d`, models.PayloadSamplesKept)})
+		return
+	}
+
+	if params.Count == 0 {
+		params.Count = defaultSampleCount
+	}
+
+	if params.Subject == "" {
+		params.Subject = "{{.Subject}}"
+	}
+
+	proposed := common.Templates{
+		Subject: params.Subject,
+		Text:    params.Text,
+		HTML:    params.HTML,
+	}
+
+	clientID := webutil.ZonedClientID(context, params.ClientID)
+	previews, err := h.sampler.Preview(context.Get("database").(DatabaseInterface), proposed, clientID, params.KindID, params.Count)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	var changed, failed int
+	documents := []samplePreviewDocument{}
+	for _, preview := range previews {
+		switch preview.Status {
+		case services.SampleChanged:
+			changed++
+		case services.SampleFailed:
+			failed++
+		}
+
+		documents = append(documents, samplePreviewDocument{
+			MessageID:     preview.MessageID,
+			VCAPRequestID: preview.VCAPRequestID,
+			SampledAt:     preview.SampledAt,
+			Status:        preview.Status,
+			Error:         preview.Error,
+			Diff:          preview.Diff,
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"changed": changed,
+		"failed":  failed,
+		"samples": documents,
+	})
+}
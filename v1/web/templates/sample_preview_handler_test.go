@@ -0,0 +1,130 @@
+package templates_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/postal/common"
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("SamplePreviewHandler", func() {
+	var (
+		handler     templates.SamplePreviewHandler
+		sampler     *mocks.TemplateSampler
+		database    *mocks.Database
+		errorWriter *mocks.ErrorWriter
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		sampledAt := time.Date(2026, 10, 17, 9, 30, 0, 0, time.UTC)
+		sampler = mocks.NewTemplateSampler()
+		sampler.PreviewCall.Returns.Previews = []services.TemplateSamplePreview{
+			{MessageID: "message-1", VCAPRequestID: "request-1", SampledAt: sampledAt, Status: services.SampleUnchanged},
+			{MessageID: "message-2", VCAPRequestID: "request-2", SampledAt: sampledAt, Status: services.SampleChanged, Diff: map[string][]string{"text": {"-Run", "+Run, user-123"}}},
+			{MessageID: "message-3", VCAPRequestID: "request-3", SampledAt: sampledAt, Status: services.SampleFailed, Error: "index out of range"},
+		}
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		handler = templates.NewSamplePreviewHandler(sampler, errorWriter)
+	})
+
+	serve := func(body string) {
+		request, err := http.NewRequest("POST", "/templates/preview/samples", bytes.NewBufferString(body))
+		Expect(err).NotTo(HaveOccurred())
+
+		handler.ServeHTTP(writer, request, context)
+	}
+
+	It("reports how the templates render the samples of the kind", func() {
+		serve(`{"client_id": "raptors", "kind_id": "feeding-time", "count": 3, "subject": "Alert: {{.Subject}}", "text": "{{.Text}}"}`)
+
+		Expect(writer.Code).To(Equal(http.StatusOK))
+		Expect(writer.Body.String()).To(MatchJSON(`{
+			"changed": 1,
+			"failed": 1,
+			"samples": [
+				{"message_id": "message-1", "vcap_request_id": "request-1", "sampled_at": "2026-10-17T09:30:00Z", "status": "unchanged"},
+				{"message_id": "message-2", "vcap_request_id": "request-2", "sampled_at": "2026-10-17T09:30:00Z", "status": "changed", "diff": {"text": ["-Run", "+Run, user-123"]}},
+				{"message_id": "message-3", "vcap_request_id": "request-3", "sampled_at": "2026-10-17T09:30:00Z", "status": "failed", "error": "index out of range"}
+			]
+		}`))
+
+		Expect(sampler.PreviewCall.Receives.Database).To(Equal(database))
+		Expect(sampler.PreviewCall.Receives.Templates).To(Equal(common.Templates{
+			Subject: "Alert: {{.Subject}}",
+			Text:    "{{.Text}}",
+		}))
+		Expect(sampler.PreviewCall.Receives.ClientID).To(Equal("raptors"))
+		Expect(sampler.PreviewCall.Receives.KindID).To(Equal("feeding-time"))
+		Expect(sampler.PreviewCall.Receives.Count).To(Equal(3))
+	})
+
+	It("defaults the subject template and the number of samples", func() {
+		serve(`{"client_id": "raptors", "kind_id": "feeding-time", "html": "<p>{{.HTML}}</p>"}`)
+
+		Expect(sampler.PreviewCall.Receives.Templates.Subject).To(Equal("{{.Subject}}"))
+		Expect(sampler.PreviewCall.Receives.Count).To(Equal(10))
+	})
+
+	It("only samples the notifications of the clients of the zone", func() {
+		context.Set(webutil.ZoneIDKey, "tenant-a")
+
+		serve(`{"client_id": "raptors", "kind_id": "feeding-time", "text": "{{.Text}}"}`)
+
+		Expect(sampler.PreviewCall.Receives.ClientID).To(Equal(models.ZonedClientID("tenant-a", "raptors")))
+	})
+
+	Context("failure cases", func() {
+		It("writes a parse error for malformed JSON", func() {
+			serve(`{"text": `)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+		})
+
+		It("writes a validation error when the kind is not given", func() {
+			serve(`{"client_id": "raptors", "text": "{{.Text}}"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(sampler.PreviewCall.WasCalled).To(BeFalse())
+		})
+
+		It("writes a validation error when neither text nor html is given", func() {
+			serve(`{"client_id": "raptors", "kind_id": "feeding-time"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+		})
+
+		It("writes a validation error when more samples are asked for than are kept", func() {
+			serve(`{"client_id": "raptors", "kind_id": "feeding-time", "text": "{{.Text}}", "count": 101}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(`"count" must be from 1 to 100`))
+		})
+
+		It("writes the error returned by the sampler", func() {
+			sampler.PreviewCall.Returns.Error = models.NotFoundError{Err: errors.New("Notification with ID \"feeding-time\" could not be found")}
+
+			serve(`{"client_id": "raptors", "kind_id": "feeding-time", "text": "{{.Text}}"}`)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New("Notification with ID \"feeding-time\" could not be found")}))
+		})
+	})
+})
@@ -15,6 +15,7 @@ type TemplateParams struct {
 	Name     string          `json:"name" validate-required:"true"`
 	Text     string          `json:"text"`
 	HTML     string          `json:"html" validate-required:"true"`
+	Slack    string          `json:"slack"`
 	Subject  string          `json:"subject"`
 	Metadata json.RawMessage `json:"metadata"`
 }
@@ -54,6 +55,7 @@ func (t TemplateParams) validateSyntax() error {
 		"Subject": t.Subject,
 		"Text":    t.Text,
 		"HTML":    t.HTML,
+		"Slack":   t.Slack,
 	}
 
 	for field, contents := range toValidate {
@@ -71,6 +73,7 @@ func (t TemplateParams) ToModel() models.Template {
 		Name:     t.Name,
 		Text:     t.Text,
 		HTML:     t.HTML,
+		Slack:    t.Slack,
 		Subject:  t.Subject,
 		Metadata: string(t.Metadata),
 	}
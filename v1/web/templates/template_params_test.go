@@ -30,6 +30,7 @@ var _ = Describe("TemplateParams", func() {
 					"name":    `Foo Bar Baz`,
 					"text":    `its foobar of course`,
 					"html":    `<p>its foobar</p>`,
+					"slack":   `*its foobar*`,
 					"subject": `Stuff and Things`,
 					"metadata": map[string]interface{}{
 						"some_property": "some_value",
@@ -42,6 +43,7 @@ var _ = Describe("TemplateParams", func() {
 				Expect(parameters.Name).To(Equal("Foo Bar Baz"))
 				Expect(parameters.Text).To(Equal("its foobar of course"))
 				Expect(parameters.HTML).To(Equal("<p>its foobar</p>"))
+				Expect(parameters.Slack).To(Equal("*its foobar*"))
 				Expect(parameters.Subject).To(Equal("Stuff and Things"))
 				Expect(string(parameters.Metadata)).To(MatchJSON(`{"some_property": "some_value"}`))
 			})
@@ -101,6 +103,18 @@ var _ = Describe("TemplateParams", func() {
 						Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New("HTML syntax is malformed please check your braces")}))
 					})
 				})
+
+				Context("when slack template has invalid syntax", func() {
+					It("returns a validation error", func() {
+						body := buildTemplateRequestBody(templates.TemplateParams{
+							Name:  "Template name",
+							HTML:  "<p>fine</p>",
+							Slack: "{{.bad}",
+						})
+						_, err := templates.NewTemplateParams(ioutil.NopCloser(body))
+						Expect(err).To(MatchError(webutil.ValidationError{Err: errors.New("Slack syntax is malformed please check your braces")}))
+					})
+				})
 			})
 		})
 	})
@@ -111,6 +125,7 @@ var _ = Describe("TemplateParams", func() {
 				Name:     "The Foo to the Bar",
 				Text:     "its foobar of course",
 				HTML:     "<p>its foobar</p>",
+				Slack:    "*its foobar*",
 				Subject:  "Foobar Yah",
 				Metadata: json.RawMessage(`{"some_property": "some_value"}`),
 			}
@@ -119,6 +134,7 @@ var _ = Describe("TemplateParams", func() {
 			Expect(templateModel.Name).To(Equal("The Foo to the Bar"))
 			Expect(templateModel.Text).To(Equal("its foobar of course"))
 			Expect(templateModel.HTML).To(Equal("<p>its foobar</p>"))
+			Expect(templateModel.Slack).To(Equal("*its foobar*"))
 			Expect(templateModel.Subject).To(Equal("Foobar Yah"))
 			Expect(templateModel.Metadata).To(MatchJSON(`{"some_property": "some_value"}`))
 			Expect(templateModel.CreatedAt).To(BeZero())
@@ -0,0 +1,153 @@
+package templates
+
+import (
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/cloudfoundry-incubator/notifications/valiant"
+	"github.com/ryanmoran/stack"
+)
+
+var translationPath = regexp.MustCompile(`/templates/(.*)/translations/(.*)`)
+
+type templateTranslator interface {
+	Set(database services.DatabaseInterface, translation models.TemplateTranslation) (models.TemplateTranslation, error)
+	Find(database services.DatabaseInterface, templateID, locale string) (models.TemplateTranslation, error)
+	Delete(database services.DatabaseInterface, templateID, locale string) error
+}
+
+type translationDocument struct {
+	TemplateID string `json:"template_id"`
+	Locale     string `json:"locale"`
+	Subject    string `json:"subject"`
+	Text       string `json:"text"`
+	HTML       string `json:"html"`
+}
+
+func newTranslationDocument(translation models.TemplateTranslation) translationDocument {
+	return translationDocument{
+		TemplateID: translation.TemplateID,
+		Locale:     translation.Locale,
+		Subject:    translation.Subject,
+		Text:       translation.Text,
+		HTML:       translation.HTML,
+	}
+}
+
+type PutTranslationHandler struct {
+	translator  templateTranslator
+	errorWriter errorWriter
+}
+
+func NewPutTranslationHandler(translator templateTranslator, errWriter errorWriter) PutTranslationHandler {
+	return PutTranslationHandler{
+		translator:  translator,
+		errorWriter: errWriter,
+	}
+}
+
+type translationParams struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html" validate-required:"true"`
+}
+
+func (h PutTranslationHandler) RequestBody() interface{} {
+	return translationParams{}
+}
+
+func (h PutTranslationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	matches := translationPath.FindStringSubmatch(req.URL.Path)
+	defer req.Body.Close()
+
+	var params translationParams
+
+	err := valiant.NewValidator(req.Body).Validate(&params)
+	if err != nil {
+		switch err.(type) {
+		case valiant.RequiredFieldError:
+			h.errorWriter.Write(w, webutil.ValidationError{Err: err})
+		default:
+			h.errorWriter.Write(w, webutil.ParseError{})
+		}
+		return
+	}
+
+	templateParams := TemplateParams{
+		Subject: params.Subject,
+		Text:    params.Text,
+		HTML:    params.HTML,
+	}
+
+	err = templateParams.validateSyntax()
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+	templateParams.setDefaults()
+
+	translation, err := h.translator.Set(context.Get("database").(DatabaseInterface), models.TemplateTranslation{
+		TemplateID: matches[1],
+		Locale:     matches[2],
+		Subject:    templateParams.Subject,
+		Text:       templateParams.Text,
+		HTML:       templateParams.HTML,
+	})
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, newTranslationDocument(translation))
+}
+
+type GetTranslationHandler struct {
+	translator  templateTranslator
+	errorWriter errorWriter
+}
+
+func NewGetTranslationHandler(translator templateTranslator, errWriter errorWriter) GetTranslationHandler {
+	return GetTranslationHandler{
+		translator:  translator,
+		errorWriter: errWriter,
+	}
+}
+
+func (h GetTranslationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	matches := translationPath.FindStringSubmatch(req.URL.Path)
+
+	translation, err := h.translator.Find(context.Get("database").(DatabaseInterface), matches[1], matches[2])
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, newTranslationDocument(translation))
+}
+
+type DeleteTranslationHandler struct {
+	translator  templateTranslator
+	errorWriter errorWriter
+}
+
+func NewDeleteTranslationHandler(translator templateTranslator, errWriter errorWriter) DeleteTranslationHandler {
+	return DeleteTranslationHandler{
+		translator:  translator,
+		errorWriter: errWriter,
+	}
+}
+
+func (h DeleteTranslationHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	matches := translationPath.FindStringSubmatch(req.URL.Path)
+
+	err := h.translator.Delete(context.Get("database").(DatabaseInterface), matches[1], matches[2])
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,192 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Translation handlers", func() {
+	var (
+		translator  *mocks.TemplateTranslator
+		errorWriter *mocks.ErrorWriter
+		database    *mocks.Database
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		translator = mocks.NewTemplateTranslator()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+	})
+
+	Describe("PutTranslationHandler", func() {
+		var handler templates.PutTranslationHandler
+
+		BeforeEach(func() {
+			handler = templates.NewPutTranslationHandler(translator, errorWriter)
+		})
+
+		It("sets the translation and writes it out", func() {
+			translator.SetCall.Returns.Translation = models.TemplateTranslation{
+				TemplateID: "some-template-id",
+				Locale:     "fr-ca",
+				Subject:    "{{.Subject}}",
+				HTML:       "<p>Bonjour {{.HTML}}</p>",
+			}
+
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/translations/fr-CA", strings.NewReader(`{"html": "<p>Bonjour {{.HTML}}</p>"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"template_id": "some-template-id",
+				"locale": "fr-ca",
+				"subject": "{{.Subject}}",
+				"text": "",
+				"html": "<p>Bonjour {{.HTML}}</p>"
+			}`))
+
+			Expect(translator.SetCall.Receives.Database).To(Equal(database))
+			Expect(translator.SetCall.Receives.Translation).To(Equal(models.TemplateTranslation{
+				TemplateID: "some-template-id",
+				Locale:     "fr-CA",
+				Subject:    "{{.Subject}}",
+				HTML:       "<p>Bonjour {{.HTML}}</p>",
+			}))
+		})
+
+		It("writes a validation error when the html is missing", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/translations/fr", strings.NewReader(`{"text": "Bonjour"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(webutil.ValidationError{}))
+			Expect(translator.SetCall.Receives.Translation).To(Equal(models.TemplateTranslation{}))
+		})
+
+		It("writes a validation error when a template is malformed", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/translations/fr", strings.NewReader(`{"html": "<p>{{.HTML</p>"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(webutil.ValidationError{Err: errors.New("HTML syntax is malformed please check your braces")}))
+		})
+
+		It("writes a parse error when the body is not JSON", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/translations/fr", strings.NewReader(`{{{`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(webutil.ParseError{}))
+		})
+
+		It("writes errors from the translator", func() {
+			translator.SetCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			request, err := http.NewRequest("PUT", "/templates/missing/translations/fr", strings.NewReader(`{"html": "<p>Bonjour</p>"}`))
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(models.NotFoundError{Err: errors.New("not found")}))
+		})
+	})
+
+	Describe("GetTranslationHandler", func() {
+		var handler templates.GetTranslationHandler
+
+		BeforeEach(func() {
+			handler = templates.NewGetTranslationHandler(translator, errorWriter)
+		})
+
+		It("writes out the translation", func() {
+			translator.FindCall.Returns.Translation = models.TemplateTranslation{
+				TemplateID: "some-template-id",
+				Locale:     "fr",
+				Subject:    "Objet",
+				Text:       "Bonjour",
+				HTML:       "<p>Bonjour</p>",
+			}
+
+			request, err := http.NewRequest("GET", "/templates/some-template-id/translations/fr", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"template_id": "some-template-id",
+				"locale": "fr",
+				"subject": "Objet",
+				"text": "Bonjour",
+				"html": "<p>Bonjour</p>"
+			}`))
+			Expect(translator.FindCall.Receives.Database).To(Equal(database))
+			Expect(translator.FindCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(translator.FindCall.Receives.Locale).To(Equal("fr"))
+		})
+
+		It("writes errors from the translator", func() {
+			translator.FindCall.Returns.Error = errors.New("boom")
+
+			request, err := http.NewRequest("GET", "/templates/some-template-id/translations/fr", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("boom")))
+		})
+	})
+
+	Describe("DeleteTranslationHandler", func() {
+		var handler templates.DeleteTranslationHandler
+
+		BeforeEach(func() {
+			handler = templates.NewDeleteTranslationHandler(translator, errorWriter)
+		})
+
+		It("deletes the translation", func() {
+			request, err := http.NewRequest("DELETE", "/templates/some-template-id/translations/fr", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(translator.DeleteCall.Receives.Database).To(Equal(database))
+			Expect(translator.DeleteCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(translator.DeleteCall.Receives.Locale).To(Equal("fr"))
+		})
+
+		It("writes errors from the translator", func() {
+			translator.DeleteCall.Returns.Error = errors.New("boom")
+
+			request, err := http.NewRequest("DELETE", "/templates/some-template-id/translations/fr", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError(errors.New("boom")))
+		})
+	})
+})
@@ -13,17 +13,31 @@ type templateUpdater interface {
 }
 
 type UpdateDefaultHandler struct {
+	templateID  string
 	updater     templateUpdater
 	errorWriter errorWriter
 }
 
 func NewUpdateDefaultHandler(updater templateUpdater, errWriter errorWriter) UpdateDefaultHandler {
 	return UpdateDefaultHandler{
+		templateID:  models.DefaultTemplateID,
 		updater:     updater,
 		errorWriter: errWriter,
 	}
 }
 
+func NewUpdateDigestHandler(updater templateUpdater, errWriter errorWriter) UpdateDefaultHandler {
+	return UpdateDefaultHandler{
+		templateID:  models.DigestTemplateID,
+		updater:     updater,
+		errorWriter: errWriter,
+	}
+}
+
+func (h UpdateDefaultHandler) RequestBody() interface{} {
+	return TemplateParams{}
+}
+
 func (h UpdateDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	template, err := NewTemplateParams(req.Body)
 	if err != nil {
@@ -31,7 +45,7 @@ func (h UpdateDefaultHandler) ServeHTTP(w http.ResponseWriter, req *http.Request
 		return
 	}
 
-	err = h.updater.Update(context.Get("database").(DatabaseInterface), models.DefaultTemplateID, template.ToModel())
+	err = h.updater.Update(context.Get("database").(DatabaseInterface), h.templateID, template.ToModel())
 	if err != nil {
 		h.errorWriter.Write(w, err)
 	}
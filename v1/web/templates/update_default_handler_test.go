@@ -64,6 +64,15 @@ var _ = Describe("UpdateDefaultHandler", func() {
 		}))
 	})
 
+	It("updates the digest template from its own route", func() {
+		handler = templates.NewUpdateDigestHandler(updater, errorWriter)
+
+		handler.ServeHTTP(writer, request, context)
+
+		Expect(writer.Code).To(Equal(http.StatusNoContent))
+		Expect(updater.UpdateCall.Receives.TemplateID).To(Equal(models.DigestTemplateID))
+	})
+
 	Context("when the request is not valid", func() {
 		It("indicates that fields are missing", func() {
 			body := `{
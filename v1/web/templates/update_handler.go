@@ -19,6 +19,10 @@ func NewUpdateHandler(updater templateUpdater, errWriter errorWriter) UpdateHand
 	}
 }
 
+func (h UpdateHandler) RequestBody() interface{} {
+	return TemplateParams{}
+}
+
 func (h UpdateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
 	templateID := strings.Split(req.URL.String(), "/templates/")[1]
 
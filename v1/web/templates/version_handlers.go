@@ -0,0 +1,117 @@
+package templates
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var (
+	versionsPath        = regexp.MustCompile(`/templates/(.*)/versions$`)
+	activateVersionPath = regexp.MustCompile(`/templates/(.*)/versions/(.*)/activate$`)
+)
+
+type templateVersioner interface {
+	List(database services.DatabaseInterface, templateID string) (services.TemplateVersions, error)
+	Activate(database services.DatabaseInterface, templateID string, version int) (models.Template, error)
+}
+
+type versionDocument struct {
+	Version   int             `json:"version"`
+	Active    bool            `json:"active"`
+	Name      string          `json:"name"`
+	Subject   string          `json:"subject"`
+	Text      string          `json:"text"`
+	HTML      string          `json:"html"`
+	Slack     string          `json:"slack,omitempty"`
+	Metadata  json.RawMessage `json:"metadata"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func rawMetadata(metadata string) json.RawMessage {
+	if metadata == "" {
+		return json.RawMessage("{}")
+	}
+
+	return json.RawMessage(metadata)
+}
+
+type ListVersionsHandler struct {
+	versioner   templateVersioner
+	errorWriter errorWriter
+}
+
+func NewListVersionsHandler(versioner templateVersioner, errWriter errorWriter) ListVersionsHandler {
+	return ListVersionsHandler{
+		versioner:   versioner,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ListVersionsHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	matches := versionsPath.FindStringSubmatch(req.URL.Path)
+
+	versions, err := h.versioner.List(context.Get("database").(DatabaseInterface), matches[1])
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	documents := []versionDocument{}
+	for _, version := range versions.Versions {
+		documents = append(documents, versionDocument{
+			Version:   version.Version,
+			Active:    version.Version == versions.Active,
+			Name:      version.Name,
+			Subject:   version.Subject,
+			Text:      version.Text,
+			HTML:      version.HTML,
+			Slack:     version.Slack,
+			Metadata:  rawMetadata(version.Metadata),
+			CreatedAt: version.CreatedAt,
+		})
+	}
+
+	webutil.WriteJSON(w, http.StatusOK, map[string]interface{}{
+		"active_version": versions.Active,
+		"versions":       documents,
+	})
+}
+
+type ActivateVersionHandler struct {
+	versioner   templateVersioner
+	errorWriter errorWriter
+}
+
+func NewActivateVersionHandler(versioner templateVersioner, errWriter errorWriter) ActivateVersionHandler {
+	return ActivateVersionHandler{
+		versioner:   versioner,
+		errorWriter: errWriter,
+	}
+}
+
+func (h ActivateVersionHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	matches := activateVersionPath.FindStringSubmatch(req.URL.Path)
+
+	version, err := strconv.Atoi(matches[2])
+	if err != nil || version < 1 {
+		h.errorWriter.Write(w, models.NotFoundError{Err: fmt.Errorf("Version %q of template with ID %q could not be found", matches[2], matches[1]), Code: "template_version_not_found"})
+		return
+	}
+
+	_, err = h.versioner.Activate(context.Get("database").(DatabaseInterface), matches[1], version)
+	if err != nil {
+		h.errorWriter.Write(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,143 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/services"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Version handlers", func() {
+	var (
+		versioner   *mocks.TemplateVersioner
+		errorWriter *mocks.ErrorWriter
+		database    *mocks.Database
+		writer      *httptest.ResponseRecorder
+		context     stack.Context
+	)
+
+	BeforeEach(func() {
+		database = mocks.NewDatabase()
+		context = stack.NewContext()
+		context.Set("database", database)
+
+		versioner = mocks.NewTemplateVersioner()
+		errorWriter = mocks.NewErrorWriter()
+		writer = httptest.NewRecorder()
+	})
+
+	Describe("ListVersionsHandler", func() {
+		var handler templates.ListVersionsHandler
+
+		BeforeEach(func() {
+			handler = templates.NewListVersionsHandler(versioner, errorWriter)
+		})
+
+		It("writes out the versions of the template", func() {
+			createdAt := time.Date(2015, 6, 8, 14, 31, 11, 0, time.UTC)
+			versioner.ListCall.Returns.Versions = services.TemplateVersions{
+				Active: 1,
+				Versions: []models.TemplateVersion{
+					{TemplateID: "some-template-id", Version: 2, Name: "raptors", HTML: "<p>second</p>", Metadata: `{"cloudy":true}`, CreatedAt: createdAt},
+					{TemplateID: "some-template-id", Version: 1, Name: "raptors", HTML: "<p>first</p>", CreatedAt: createdAt},
+				},
+			}
+
+			request, err := http.NewRequest("GET", "/templates/some-template-id/versions", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusOK))
+			Expect(writer.Body).To(MatchJSON(`{
+				"active_version": 1,
+				"versions": [
+					{
+						"version": 2,
+						"active": false,
+						"name": "raptors",
+						"subject": "",
+						"text": "",
+						"html": "<p>second</p>",
+						"metadata": {"cloudy": true},
+						"created_at": "2015-06-08T14:31:11Z"
+					},
+					{
+						"version": 1,
+						"active": true,
+						"name": "raptors",
+						"subject": "",
+						"text": "",
+						"html": "<p>first</p>",
+						"metadata": {},
+						"created_at": "2015-06-08T14:31:11Z"
+					}
+				]
+			}`))
+
+			Expect(versioner.ListCall.Receives.Database).To(Equal(database))
+			Expect(versioner.ListCall.Receives.TemplateID).To(Equal("some-template-id"))
+		})
+
+		It("writes errors from the versioner", func() {
+			versioner.ListCall.Returns.Error = models.NotFoundError{Err: errors.New("not found")}
+
+			request, err := http.NewRequest("GET", "/templates/some-template-id/versions", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New("not found")}))
+		})
+	})
+
+	Describe("ActivateVersionHandler", func() {
+		var handler templates.ActivateVersionHandler
+
+		BeforeEach(func() {
+			handler = templates.NewActivateVersionHandler(versioner, errorWriter)
+		})
+
+		It("activates the version", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/versions/3/activate", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(writer.Code).To(Equal(http.StatusNoContent))
+			Expect(versioner.ActivateCall.Receives.Database).To(Equal(database))
+			Expect(versioner.ActivateCall.Receives.TemplateID).To(Equal("some-template-id"))
+			Expect(versioner.ActivateCall.Receives.Version).To(Equal(3))
+		})
+
+		It("writes a not found error when the version is not a number", func() {
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/versions/latest/activate", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(Equal(models.NotFoundError{Err: errors.New(`Version "latest" of template with ID "some-template-id" could not be found`), Code: "template_version_not_found"}))
+			Expect(versioner.ActivateCall.Receives.TemplateID).To(BeEmpty())
+		})
+
+		It("writes errors from the versioner", func() {
+			versioner.ActivateCall.Returns.Error = errors.New("boom")
+
+			request, err := http.NewRequest("PUT", "/templates/some-template-id/versions/3/activate", nil)
+			Expect(err).NotTo(HaveOccurred())
+
+			handler.ServeHTTP(writer, request, context)
+
+			Expect(errorWriter.WriteCall.Receives.Error).To(MatchError("boom"))
+		})
+	})
+})
@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+var templateIDPath = regexp.MustCompile(`^/templates/([^/]+)`)
+
+// ZoneGuard answers as if the template in the path did not exist when it
+// belongs to another UAA zone than the token. The default and digest
+// templates are let through to any zone only when shared is set, which is
+// for the routes that read them.
+type ZoneGuard struct {
+	finder      templateFinder
+	errorWriter errorWriter
+	shared      bool
+}
+
+func NewZoneGuard(finder templateFinder, errWriter errorWriter, shared bool) ZoneGuard {
+	return ZoneGuard{
+		finder:      finder,
+		errorWriter: errWriter,
+		shared:      shared,
+	}
+}
+
+func (g ZoneGuard) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) bool {
+	zoneID := webutil.ZoneID(context)
+	if zoneID == models.DefaultZoneID {
+		return true
+	}
+
+	matches := templateIDPath.FindStringSubmatch(req.URL.Path)
+	if matches == nil {
+		return true
+	}
+	templateID := matches[1]
+
+	template, err := g.finder.FindByID(context.Get("database").(DatabaseInterface), templateID)
+	if err != nil {
+		// The handler finds the template again, and reports the error as
+		// it would have without the guard.
+		return true
+	}
+
+	inZone := template.ZoneID == zoneID
+	if g.shared {
+		inZone = models.TemplateInZone(zoneID, template)
+	}
+
+	if !inZone {
+		g.errorWriter.Write(w, models.NotFoundError{Err: fmt.Errorf("Template with ID %q could not be found", templateID), Code: "template_not_found"})
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package templates_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/testing/mocks"
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/templates"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ZoneGuard", func() {
+	var (
+		guard       templates.ZoneGuard
+		finder      *mocks.TemplateFinder
+		errorWriter *mocks.ErrorWriter
+		database    *mocks.Database
+		context     stack.Context
+		writer      *httptest.ResponseRecorder
+		request     *http.Request
+	)
+
+	BeforeEach(func() {
+		finder = mocks.NewTemplateFinder()
+		errorWriter = mocks.NewErrorWriter()
+		database = mocks.NewDatabase()
+		writer = httptest.NewRecorder()
+
+		context = stack.NewContext()
+		context.Set("database", database)
+		context.Set(webutil.ZoneIDKey, "tenant-a")
+
+		var err error
+		request, err = http.NewRequest("PUT", "/templates/some-template/translations/fr", nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		guard = templates.NewZoneGuard(finder, errorWriter, false)
+	})
+
+	It("lets through the templates of the zone of the token", func() {
+		finder.FindByIDCall.Returns.Template = models.Template{ID: "some-template", ZoneID: "tenant-a"}
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(finder.FindByIDCall.Receives.Database).To(Equal(database))
+		Expect(finder.FindByIDCall.Receives.TemplateID).To(Equal("some-template"))
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+	})
+
+	It("reports the templates of other zones as not found", func() {
+		finder.FindByIDCall.Returns.Template = models.Template{ID: "some-template", ZoneID: "tenant-b"}
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeFalse())
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+	})
+
+	It("lets every template through to the default zone without finding it", func() {
+		context.Set(webutil.ZoneIDKey, "uaa")
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(finder.FindByIDCall.Receives.TemplateID).To(BeEmpty())
+	})
+
+	It("leaves the errors of finding the template to the handler", func() {
+		finder.FindByIDCall.Returns.Error = errors.New("boom")
+
+		Expect(guard.ServeHTTP(writer, request, context)).To(BeTrue())
+		Expect(errorWriter.WriteCall.Receives.Error).To(BeNil())
+	})
+
+	Context("for the shared templates", func() {
+		BeforeEach(func() {
+			finder.FindByIDCall.Returns.Template = models.Template{ID: models.DefaultTemplateID, ZoneID: "uaa"}
+		})
+
+		It("lets them through when the guard allows them", func() {
+			guard = templates.NewZoneGuard(finder, errorWriter, true)
+
+			Expect(guard.ServeHTTP(writer, request, context)).To(BeTrue())
+		})
+
+		It("reports them as not found otherwise", func() {
+			Expect(guard.ServeHTTP(writer, request, context)).To(BeFalse())
+			Expect(errorWriter.WriteCall.Receives.Error).To(BeAssignableToTypeOf(models.NotFoundError{}))
+		})
+	})
+})
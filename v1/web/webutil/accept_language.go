@@ -0,0 +1,55 @@
+package webutil
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// AcceptedLocales lists the locales of an Accept-Language header, most
+// preferred first. The wildcard and locales weighted q=0 are left out.
+func AcceptedLocales(header string) []string {
+	type weighted struct {
+		locale string
+		q      float64
+	}
+
+	var ranges []weighted
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(part, ";")
+
+		locale := strings.TrimSpace(fields[0])
+		if locale == "" || locale == "*" {
+			continue
+		}
+
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				parsed, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+				if err != nil {
+					parsed = 0
+				}
+				q = parsed
+			}
+		}
+
+		if q <= 0 {
+			continue
+		}
+
+		ranges = append(ranges, weighted{locale: locale, q: q})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].q > ranges[j].q
+	})
+
+	var locales []string
+	for _, r := range ranges {
+		locales = append(locales, r.locale)
+	}
+
+	return locales
+}
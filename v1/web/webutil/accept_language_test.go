@@ -0,0 +1,22 @@
+package webutil_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("AcceptedLocales", func() {
+	It("orders the locales by weight, keeping the header order for ties", func() {
+		Expect(webutil.AcceptedLocales("fr;q=0.5, en-US, de;q=0.8, en")).To(Equal([]string{"en-US", "en", "de", "fr"}))
+	})
+
+	It("leaves out the wildcard and locales that are not acceptable", func() {
+		Expect(webutil.AcceptedLocales("es, *;q=0.1, it;q=0, pt;q=nonsense")).To(Equal([]string{"es"}))
+	})
+
+	It("returns nothing for an empty header", func() {
+		Expect(webutil.AcceptedLocales("")).To(BeEmpty())
+	})
+})
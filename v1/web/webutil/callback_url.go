@@ -0,0 +1,29 @@
+package webutil
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/cloudfoundry-incubator/notifications/httpclient"
+)
+
+// ValidCallbackURL reports whether rawURL is an absolute http or https URL
+// that delivery webhooks can be posted to. URLs whose host is, or resolves
+// to, a loopback, private or link-local address are refused, so that
+// clients cannot have the workers post to the internal network.
+func ValidCallbackURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	return httpclient.CheckPublicHost(ctx, parsed.Hostname()) == nil
+}
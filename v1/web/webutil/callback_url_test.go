@@ -0,0 +1,31 @@
+package webutil_test
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ValidCallbackURL", func() {
+	It("accepts absolute http and https URLs", func() {
+		Expect(webutil.ValidCallbackURL("http://example.com/callback")).To(BeTrue())
+		Expect(webutil.ValidCallbackURL("https://example.com:8443/hooks?client=me")).To(BeTrue())
+	})
+
+	It("rejects relative URLs and other schemes", func() {
+		Expect(webutil.ValidCallbackURL("/callback")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("example.com/callback")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("ftp://example.com/callback")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("https://")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("%%")).To(BeFalse())
+	})
+
+	It("rejects URLs of internal hosts", func() {
+		Expect(webutil.ValidCallbackURL("http://localhost:8080/callback")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("http://127.0.0.1/callback")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("http://[::1]/callback")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("http://169.254.169.254/latest/meta-data")).To(BeFalse())
+		Expect(webutil.ValidCallbackURL("https://10.0.0.5/hooks")).To(BeFalse())
+	})
+})
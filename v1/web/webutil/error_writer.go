@@ -5,11 +5,26 @@ import (
 	"net/http"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
 )
 
+// ErrorDetail is one entry of the errors envelope that every error response
+// is written in. Code is stable so that clients can act on it; Detail is
+// meant for people and may change.
+type ErrorDetail struct {
+	Code   string `json:"code"`
+	Detail string `json:"detail"`
+}
+
+// An error with a more specific code than the one its type maps to, such as
+// a models.NotFoundError that says what could not be found.
+type coder interface {
+	ErrorCode() string
+}
+
 type ErrorWriter struct{}
 
 func NewErrorWriter() ErrorWriter {
@@ -17,24 +32,91 @@ func NewErrorWriter() ErrorWriter {
 }
 
 func (writer ErrorWriter) Write(w http.ResponseWriter, err error) {
+	status, code := classify(err)
+	if coded, ok := err.(coder); ok && coded.ErrorCode() != "" {
+		code = coded.ErrorCode()
+	}
+
+	WriteError(w, status, code, err.Error())
+}
+
+func classify(err error) (int, string) {
 	switch err.(type) {
-	case UAAScopesError, CriticalNotificationError, collections.TemplateAssignmentError, MissingUserTokenError, ValidationError:
-		w.WriteHeader(422)
+	case UAAScopesError:
+		return 422, "critical_registration_forbidden"
+	case CriticalNotificationError:
+		return 422, "critical_notification_forbidden"
+	case collections.TemplateAssignmentError:
+		return 422, "template_assignment_invalid"
+	case AuthorizationDenial:
+		return http.StatusForbidden, "authorization_denied"
+	case MissingUserTokenError:
+		return 422, "user_token_required"
+	case ValidationError:
+		return 422, "validation_failed"
+	case services.TemplatePreviewError:
+		return 422, "template_preview_failed"
+	case services.UnsubscribeImportError:
+		return 422, "unsubscribe_import_invalid"
+	case services.PreferencesImportError:
+		return 422, "preferences_import_invalid"
+	case services.PreferenceRevertError:
+		return 422, "preference_revert_invalid"
+	case services.UnsubscribeLinkError:
+		return 422, "unsubscribe_link_invalid"
+	case services.TrackingLinkError:
+		return 404, "tracking_link_invalid"
+	case services.DSNReportError:
+		return 422, "dsn_report_invalid"
+	case services.TemplateBundleError:
+		return 422, "template_bundle_invalid"
+	case services.PayloadTemplateError:
+		return 422, "payload_template_invalid"
 	case services.CCDownError:
-		w.WriteHeader(http.StatusBadGateway)
-	case services.CCNotFoundError, models.NotFoundError, cf.NotFoundError:
-		w.WriteHeader(http.StatusNotFound)
-	case ParseError, SchemaError:
-		w.WriteHeader(http.StatusBadRequest)
+		return http.StatusBadGateway, "cloud_controller_unavailable"
+	case services.CCNotFoundError, cf.NotFoundError:
+		return http.StatusNotFound, "cloud_controller_not_found"
+	case uaa.GroupNotFoundError:
+		return http.StatusNotFound, "uaa_group_not_found"
+	case models.NotFoundError:
+		return http.StatusNotFound, "not_found"
+	case ParseError:
+		return http.StatusBadRequest, "invalid_json"
+	case SchemaError:
+		return http.StatusBadRequest, "invalid_request"
 	case models.DuplicateError:
-		w.WriteHeader(http.StatusConflict)
+		return http.StatusConflict, "duplicate"
+	case services.MessageStateError:
+		return http.StatusConflict, "message_state_conflict"
 	case services.DefaultScopeError:
-		w.WriteHeader(http.StatusNotAcceptable)
+		return http.StatusNotAcceptable, "default_scope_forbidden"
+	case models.QuotaExceededError:
+		return http.StatusTooManyRequests, "quota_exceeded"
 	default:
-		w.WriteHeader(http.StatusInternalServerError)
+		return http.StatusInternalServerError, "internal_error"
 	}
+}
 
-	json.NewEncoder(w).Encode(map[string][]string{
-		"errors": []string{err.Error()},
+// WriteError writes a response with a single error in the envelope that the
+// ErrorWriter uses, for middleware and handlers that answer with a status of
+// their own rather than an error type.
+func WriteError(w http.ResponseWriter, status int, code, detail string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string][]ErrorDetail{
+		"errors": {{Code: code, Detail: detail}},
 	})
 }
+
+// WriteJSON writes object as the body of a response with the status. An
+// object that cannot be encoded is answered with an internal error instead.
+func WriteJSON(w http.ResponseWriter, status int, object interface{}) {
+	output, err := json.Marshal(object)
+	if err != nil {
+		WriteError(w, http.StatusInternalServerError, "internal_error", "The response could not be encoded: "+err.Error())
+		return
+	}
+
+	w.WriteHeader(status)
+	w.Write(output)
+}
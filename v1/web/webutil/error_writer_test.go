@@ -4,8 +4,10 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"time"
 
 	"github.com/cloudfoundry-incubator/notifications/cf"
+	"github.com/cloudfoundry-incubator/notifications/uaa"
 	"github.com/cloudfoundry-incubator/notifications/v1/collections"
 	"github.com/cloudfoundry-incubator/notifications/v1/models"
 	"github.com/cloudfoundry-incubator/notifications/v1/services"
@@ -30,7 +32,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, webutil.UAAScopesError{Err: errors.New("UAA Scopes Error: Client does not have authority to register critical notifications.")})
 		Expect(recorder.Code).To(Equal(422))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["UAA Scopes Error: Client does not have authority to register critical notifications."]
+			"errors": [{"code": "critical_registration_forbidden", "detail": "UAA Scopes Error: Client does not have authority to register critical notifications."}]
 		}`))
 	})
 
@@ -38,7 +40,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, services.CCDownError{Err: errors.New("Bad things happened!")})
 		Expect(recorder.Code).To(Equal(http.StatusBadGateway))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["Bad things happened!"]
+			"errors": [{"code": "cloud_controller_unavailable", "detail": "Bad things happened!"}]
 		}`))
 	})
 
@@ -46,7 +48,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, webutil.TemplateCreateError{})
 		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["Failed to create Template in the database"]
+			"errors": [{"code": "internal_error", "detail": "Failed to create Template in the database"}]
 		}`))
 	})
 
@@ -54,7 +56,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, models.TemplateUpdateError{Err: errors.New("Failed to update Template in the database")})
 		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["Failed to update Template in the database"]
+			"errors": [{"code": "internal_error", "detail": "Failed to update Template in the database"}]
 		}`))
 	})
 
@@ -62,7 +64,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, services.CCNotFoundError{Err: errors.New("Space could not be found")})
 		Expect(recorder.Code).To(Equal(http.StatusNotFound))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["Space could not be found"]
+			"errors": [{"code": "cloud_controller_not_found", "detail": "Space could not be found"}]
 		}`))
 	})
 
@@ -70,7 +72,15 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, cf.NotFoundError{Message: "Space could not be found"})
 		Expect(recorder.Code).To(Equal(http.StatusNotFound))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["CloudController Failure: Space could not be found"]
+			"errors": [{"code": "cloud_controller_not_found", "detail": "CloudController Failure: Space could not be found"}]
+		}`))
+	})
+
+	It("returns a 404 when the UAA group cannot be found", func() {
+		writer.Write(recorder, uaa.GroupNotFoundError{Group: "raptor.keepers"})
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "uaa_group_not_found", "detail": "UAA group \"raptor.keepers\" could not be found"}]
 		}`))
 	})
 
@@ -78,7 +88,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, webutil.ParseError{})
 		Expect(recorder.Code).To(Equal(400))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["Request body could not be parsed"]
+			"errors": [{"code": "invalid_json", "detail": "Request body could not be parsed"}]
 		}`))
 	})
 
@@ -86,7 +96,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, webutil.ValidationError{Err: errors.New("invalid json")})
 		Expect(recorder.Code).To(Equal(422))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["invalid json"]
+			"errors": [{"code": "validation_failed", "detail": "invalid json"}]
 		}`))
 	})
 
@@ -94,7 +104,22 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, webutil.NewCriticalNotificationError("raptors"))
 		Expect(recorder.Code).To(Equal(422))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["Insufficient privileges to send notification raptors"]
+			"errors": [{"code": "critical_notification_forbidden", "detail": "Insufficient privileges to send notification raptors"}]
+		}`))
+	})
+
+	It("returns a 403 with the code of an authorizer that denies a notify request", func() {
+		writer.Write(recorder, webutil.AuthorizationDenial{Code: "ops_only", Reason: "Only operators may notify everyone"})
+		Expect(recorder.Code).To(Equal(403))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "ops_only", "detail": "Only operators may notify everyone"}]
+		}`))
+
+		recorder = httptest.NewRecorder()
+		writer.Write(recorder, webutil.AuthorizationDenial{Reason: "Not allowed"})
+		Expect(recorder.Code).To(Equal(403))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "authorization_denied", "detail": "Not allowed"}]
 		}`))
 	})
 
@@ -102,7 +127,15 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, models.DuplicateError{Err: errors.New("duplicate record")})
 		Expect(recorder.Code).To(Equal(409))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["duplicate record"]
+			"errors": [{"code": "duplicate", "detail": "duplicate record"}]
+		}`))
+	})
+
+	It("returns a 409 when a message is in the wrong state", func() {
+		writer.Write(recorder, services.MessageStateError{Err: errors.New("Message \"some-id\" is delivered and can no longer be canceled")})
+		Expect(recorder.Code).To(Equal(409))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "message_state_conflict", "detail": "Message \"some-id\" is delivered and can no longer be canceled"}]
 		}`))
 	})
 
@@ -110,7 +143,16 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, models.NotFoundError{Err: errors.New("not found")})
 		Expect(recorder.Code).To(Equal(404))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["not found"]
+			"errors": [{"code": "not_found", "detail": "not found"}]
+		}`))
+	})
+
+	It("uses the code of a record that says what could not be found", func() {
+		writer.Write(recorder, models.NotFoundError{Err: errors.New(`Template with ID "missing" could not be found`), Code: "template_not_found"})
+		Expect(recorder.Code).To(Equal(404))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/json"))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "template_not_found", "detail": "Template with ID \"missing\" could not be found"}]
 		}`))
 	})
 
@@ -118,7 +160,79 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, services.DefaultScopeError{})
 		Expect(recorder.Code).To(Equal(406))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["You cannot send a notification to a default scope"]
+			"errors": [{"code": "default_scope_forbidden", "detail": "You cannot send a notification to a default scope"}]
+		}`))
+	})
+
+	It("returns a 422 when a notification payload cannot be rendered", func() {
+		writer.Write(recorder, services.PayloadTemplateError{Err: errors.New("the text of the notification could not be rendered")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "payload_template_invalid", "detail": "the text of the notification could not be rendered"}]
+		}`))
+	})
+
+	It("returns a 422 when a template preview cannot be rendered", func() {
+		writer.Write(recorder, services.TemplatePreviewError{Err: errors.New("template: compileTemplate:1: unclosed action")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "template_preview_failed", "detail": "template: compileTemplate:1: unclosed action"}]
+		}`))
+	})
+
+	It("returns a 422 when an unsubscribe import is invalid", func() {
+		writer.Write(recorder, services.UnsubscribeImportError{Err: errors.New("line 2: user is required")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "unsubscribe_import_invalid", "detail": "line 2: user is required"}]
+		}`))
+	})
+
+	It("returns a 422 when a preferences import is invalid", func() {
+		writer.Write(recorder, services.PreferencesImportError{Err: errors.New("user-123: the kind \"weekly\" for client \"newsletters\" is not registered")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "preferences_import_invalid", "detail": "user-123: the kind \"weekly\" for client \"newsletters\" is not registered"}]
+		}`))
+	})
+
+	It("returns a 422 when a preference revert link is invalid", func() {
+		writer.Write(recorder, services.PreferenceRevertError{Err: errors.New("The revert link has expired")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "preference_revert_invalid", "detail": "The revert link has expired"}]
+		}`))
+	})
+
+	It("returns a 422 when a template bundle cannot be imported", func() {
+		writer.Write(recorder, services.TemplateBundleError{Err: errors.New(`template includes partial "footer", which is neither in the bundle nor saved`)})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "template_bundle_invalid", "detail": "template includes partial \"footer\", which is neither in the bundle nor saved"}]
+		}`))
+	})
+
+	It("returns a 422 when a delivery status notification cannot be read", func() {
+		writer.Write(recorder, services.DSNReportError{Err: errors.New("The report has no recipient fields")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "dsn_report_invalid", "detail": "The report has no recipient fields"}]
+		}`))
+	})
+
+	It("returns a 404 when a tracking link is invalid", func() {
+		writer.Write(recorder, services.TrackingLinkError{Err: errors.New("The tracking link is invalid")})
+		Expect(recorder.Code).To(Equal(404))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "tracking_link_invalid", "detail": "The tracking link is invalid"}]
+		}`))
+	})
+
+	It("returns a 422 when an unsubscribe link is invalid", func() {
+		writer.Write(recorder, services.UnsubscribeLinkError{Err: errors.New("The unsubscribe link is invalid")})
+		Expect(recorder.Code).To(Equal(422))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "unsubscribe_link_invalid", "detail": "The unsubscribe link is invalid"}]
 		}`))
 	})
 
@@ -126,7 +240,7 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, collections.TemplateAssignmentError{Err: errors.New("The template could not be assigned")})
 		Expect(recorder.Code).To(Equal(422))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["The template could not be assigned"]
+			"errors": [{"code": "template_assignment_invalid", "detail": "The template could not be assigned"}]
 		}`))
 	})
 
@@ -134,7 +248,21 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, webutil.MissingUserTokenError{Err: errors.New("Missing user_id from token claims.")})
 		Expect(recorder.Code).To(Equal(422))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["Missing user_id from token claims."]
+			"errors": [{"code": "user_token_required", "detail": "Missing user_id from token claims."}]
+		}`))
+	})
+
+	It("returns a 429 when the client has used up its quota", func() {
+		writer.Write(recorder, models.QuotaExceededError{
+			ClientID:  "some-client",
+			Limit:     10,
+			Used:      9,
+			Requested: 2,
+			ResetsAt:  time.Date(2026, time.November, 1, 0, 0, 0, 0, time.UTC),
+		})
+		Expect(recorder.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "quota_exceeded", "detail": "Client \"some-client\" has sent 9 of its 10 notifications this month and cannot send 2 more until the quota resets at 2026-11-01T00:00:00Z"}]
 		}`))
 	})
 
@@ -142,7 +270,27 @@ var _ = Describe("ErrorWriter", func() {
 		writer.Write(recorder, errors.New("unknown error"))
 		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
 		Expect(recorder.Body).To(MatchJSON(`{
-			"errors": ["unknown error"]
+			"errors": [{"code": "internal_error", "detail": "unknown error"}]
+		}`))
+	})
+})
+
+var _ = Describe("WriteJSON", func() {
+	It("writes the object with the status", func() {
+		recorder := httptest.NewRecorder()
+		webutil.WriteJSON(recorder, http.StatusCreated, map[string]string{"id": "some-id"})
+
+		Expect(recorder.Code).To(Equal(http.StatusCreated))
+		Expect(recorder.Body).To(MatchJSON(`{"id": "some-id"}`))
+	})
+
+	It("writes an internal error instead of panicking when the object cannot be encoded", func() {
+		recorder := httptest.NewRecorder()
+		webutil.WriteJSON(recorder, http.StatusOK, map[string]interface{}{"channel": make(chan int)})
+
+		Expect(recorder.Code).To(Equal(http.StatusInternalServerError))
+		Expect(recorder.Body).To(MatchJSON(`{
+			"errors": [{"code": "internal_error", "detail": "The response could not be encoded: json: unsupported type: chan int"}]
 		}`))
 	})
 })
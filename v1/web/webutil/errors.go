@@ -57,3 +57,18 @@ func NewCriticalNotificationError(kindID string) CriticalNotificationError {
 func (e CriticalNotificationError) Error() string {
 	return e.Err.Error()
 }
+
+// AuthorizationDenial is returned by a notify Authorizer that refuses a
+// request. Code names the policy for clients, and Reason explains it.
+type AuthorizationDenial struct {
+	Code   string
+	Reason string
+}
+
+func (e AuthorizationDenial) Error() string {
+	return e.Reason
+}
+
+func (e AuthorizationDenial) ErrorCode() string {
+	return e.Code
+}
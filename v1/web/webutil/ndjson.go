@@ -0,0 +1,76 @@
+package webutil
+
+import (
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+const NDJSONContentType = "application/x-ndjson"
+
+// WantsNDJSON reports whether the request's Accept header asks for newline
+// delimited JSON rather than a single document.
+func WantsNDJSON(req *http.Request) bool {
+	for _, accepted := range strings.Split(req.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accepted))
+		if err == nil && mediaType == NDJSONContentType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NDJSONWriter writes a response one JSON document per line, flushing after
+// each so that large result sets reach the client as they are read instead
+// of being held in memory. The status line is only written with the first
+// line, so errors that happen before then can still be reported normally.
+type NDJSONWriter struct {
+	writer     http.ResponseWriter
+	controller *http.ResponseController
+	encoder    *json.Encoder
+	started    bool
+}
+
+func NewNDJSONWriter(w http.ResponseWriter) *NDJSONWriter {
+	return &NDJSONWriter{
+		writer:     w,
+		controller: http.NewResponseController(w),
+		encoder:    json.NewEncoder(w),
+	}
+}
+
+func (n *NDJSONWriter) Write(object interface{}) error {
+	n.start()
+
+	err := n.encoder.Encode(object)
+	if err != nil {
+		return err
+	}
+
+	// Writers that cannot flush still get every line, just buffered.
+	n.controller.Flush()
+
+	return nil
+}
+
+// Close writes the status line of a response that had no lines.
+func (n *NDJSONWriter) Close() {
+	n.start()
+}
+
+// Started reports whether any of the response has been written.
+func (n *NDJSONWriter) Started() bool {
+	return n.started
+}
+
+func (n *NDJSONWriter) start() {
+	if n.started {
+		return
+	}
+
+	n.writer.Header().Set("Content-Type", NDJSONContentType)
+	n.writer.WriteHeader(http.StatusOK)
+	n.started = true
+}
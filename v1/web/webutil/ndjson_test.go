@@ -0,0 +1,70 @@
+package webutil_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("WantsNDJSON", func() {
+	accepting := func(accept string) *http.Request {
+		request, err := http.NewRequest("GET", "/notifications", nil)
+		Expect(err).NotTo(HaveOccurred())
+		request.Header.Set("Accept", accept)
+
+		return request
+	}
+
+	It("is true when newline delimited JSON is among the accepted types", func() {
+		Expect(webutil.WantsNDJSON(accepting("application/x-ndjson"))).To(BeTrue())
+		Expect(webutil.WantsNDJSON(accepting("application/json;q=0.5, application/x-ndjson; charset=utf-8"))).To(BeTrue())
+	})
+
+	It("is false otherwise", func() {
+		Expect(webutil.WantsNDJSON(accepting(""))).To(BeFalse())
+		Expect(webutil.WantsNDJSON(accepting("application/json"))).To(BeFalse())
+		Expect(webutil.WantsNDJSON(accepting("*/*"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("NDJSONWriter", func() {
+	var (
+		recorder *httptest.ResponseRecorder
+		writer   *webutil.NDJSONWriter
+	)
+
+	BeforeEach(func() {
+		recorder = httptest.NewRecorder()
+		writer = webutil.NewNDJSONWriter(recorder)
+	})
+
+	It("writes each object on its own line and flushes it", func() {
+		Expect(writer.Started()).To(BeFalse())
+
+		Expect(writer.Write(map[string]string{"id": "first"})).To(Succeed())
+		Expect(recorder.Flushed).To(BeTrue())
+		Expect(writer.Write(map[string]string{"id": "second"})).To(Succeed())
+
+		Expect(writer.Started()).To(BeTrue())
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+		Expect(recorder.Body.String()).To(Equal("{\"id\":\"first\"}\n{\"id\":\"second\"}\n"))
+	})
+
+	It("writes an empty response when closed without lines", func() {
+		writer.Close()
+
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Header().Get("Content-Type")).To(Equal("application/x-ndjson"))
+		Expect(recorder.Body.String()).To(BeEmpty())
+	})
+
+	It("does not write anything until it is used", func() {
+		Expect(recorder.Header().Get("Content-Type")).To(BeEmpty())
+		Expect(recorder.Body.String()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,19 @@
+package webutil
+
+import "github.com/ryanmoran/stack"
+
+// RequestIDHeader carries the ID that correlates the logs, audit events and
+// deliveries of a request. The router sets it; when it does not, the request
+// logging middleware generates one. Either way it is returned in the response.
+const RequestIDHeader = "X-Vcap-Request-Id"
+
+// VCAPRequestIDKey is the context key under which the request logging
+// middleware sets the ID of the request.
+const VCAPRequestIDKey = "vcap_request_id"
+
+// VCAPRequestID returns the ID of the request, or an empty string when the
+// request was not logged.
+func VCAPRequestID(context stack.Context) string {
+	requestID, _ := context.Get(VCAPRequestIDKey).(string)
+	return requestID
+}
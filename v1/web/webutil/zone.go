@@ -0,0 +1,26 @@
+package webutil
+
+import (
+	"github.com/cloudfoundry-incubator/notifications/v1/models"
+	"github.com/ryanmoran/stack"
+)
+
+// ZoneIDKey is the context key under which the authenticator sets the UAA
+// zone of the token.
+const ZoneIDKey = "zone_id"
+
+// ZoneID returns the UAA zone of the request, which is the default zone when
+// the request was not authenticated.
+func ZoneID(context stack.Context) string {
+	if zoneID, ok := context.Get(ZoneIDKey).(string); ok && zoneID != "" {
+		return zoneID
+	}
+
+	return models.DefaultZoneID
+}
+
+// ZonedClientID qualifies a client ID given in the request, such as in its
+// path, by the zone of the request.
+func ZonedClientID(context stack.Context, clientID string) string {
+	return models.ZonedClientID(ZoneID(context), clientID)
+}
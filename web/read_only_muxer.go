@@ -0,0 +1,42 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/cloudfoundry-incubator/notifications/v1/web/webutil"
+	"github.com/ryanmoran/stack"
+)
+
+// sideEffectRoutes are served with GET but change state, so read-only
+// instances refuse them along with every other write.
+var sideEffectRoutes = map[string]bool{
+	"/t/{token}": true,
+}
+
+// ReadOnlyMuxer registers only the routes that read state. Every other
+// route answers 405 so that clients pointed at a read-only instance learn
+// why their request was refused.
+type ReadOnlyMuxer struct {
+	Muxer
+}
+
+func NewReadOnlyMuxer() ReadOnlyMuxer {
+	return ReadOnlyMuxer{NewMuxer()}
+}
+
+func (m ReadOnlyMuxer) Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware) {
+	switch {
+	case sideEffectRoutes[path]:
+	case method == "GET", method == "HEAD", method == "OPTIONS":
+		m.Muxer.Handle(method, path, handler, middleware...)
+		return
+	}
+
+	m.Muxer.Handle(method, path, readOnlyHandler{})
+}
+
+type readOnlyHandler struct{}
+
+func (readOnlyHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	webutil.WriteError(w, http.StatusMethodNotAllowed, "read_only", "This instance is read-only and does not accept changes")
+}
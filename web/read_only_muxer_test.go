@@ -0,0 +1,78 @@
+package web_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cloudfoundry-incubator/notifications/web"
+	"github.com/ryanmoran/stack"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type recordingHandler struct {
+	called *bool
+}
+
+func (h recordingHandler) ServeHTTP(w http.ResponseWriter, req *http.Request, context stack.Context) {
+	*h.called = true
+}
+
+var _ = Describe("ReadOnlyMuxer", func() {
+	var (
+		muxer  web.ReadOnlyMuxer
+		called bool
+	)
+
+	BeforeEach(func() {
+		called = false
+		muxer = web.NewReadOnlyMuxer()
+	})
+
+	serve := func(method, path string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(method, path, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		writer := httptest.NewRecorder()
+		muxer.ServeHTTP(writer, request)
+		return writer
+	}
+
+	It("serves routes that only read", func() {
+		muxer.Handle("GET", "/messages/{message_id}", recordingHandler{&called})
+
+		writer := serve("GET", "/messages/some-message-id")
+
+		Expect(called).To(BeTrue())
+		Expect(writer.Code).To(Equal(http.StatusOK))
+	})
+
+	It("refuses routes that write", func() {
+		muxer.Handle("POST", "/users/{user_id}", recordingHandler{&called})
+
+		writer := serve("POST", "/users/some-user-id")
+
+		Expect(called).To(BeFalse())
+		Expect(writer.Code).To(Equal(http.StatusMethodNotAllowed))
+		Expect(writer.Body.String()).To(MatchJSON(`{"errors": [{"code": "read_only", "detail": "This instance is read-only and does not accept changes"}]}`))
+	})
+
+	It("refuses GET routes that change state", func() {
+		muxer.Handle("GET", "/t/{token}", recordingHandler{&called})
+
+		writer := serve("GET", "/t/some-token")
+
+		Expect(called).To(BeFalse())
+		Expect(writer.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+
+	It("serves the confirmation page of a preference revert", func() {
+		muxer.Handle("GET", "/user_preferences/revert/{token}", recordingHandler{&called})
+
+		writer := serve("GET", "/user_preferences/revert/some-token")
+
+		Expect(called).To(BeTrue())
+		Expect(writer.Code).To(Equal(http.StatusOK))
+	})
+})
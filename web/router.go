@@ -4,20 +4,64 @@ import (
 	"net/http"
 
 	v1web "github.com/cloudfoundry-incubator/notifications/v1/web"
+	"github.com/gorilla/mux"
+	"github.com/ryanmoran/stack"
 )
 
+type muxer interface {
+	Handle(method, path string, handler stack.Handler, middleware ...stack.Middleware)
+	GetRouter() *mux.Router
+	ServeHTTP(w http.ResponseWriter, req *http.Request)
+}
+
 func NewRouter(config Config) http.Handler {
-	v1 := v1web.NewRouter(NewMuxer(), v1web.Config{
+	var mx muxer = NewMuxer()
+	if config.ReadOnly {
+		mx = NewReadOnlyMuxer()
+	}
+
+	v1 := v1web.NewRouter(mx, v1web.Config{
 		UAATokenValidator: config.UAATokenValidator,
 		UAAClientID:       config.UAAClientID,
 		UAAClientSecret:   config.UAAClientSecret,
 		DefaultUAAScopes:  config.DefaultUAAScopes,
 		DBLoggingEnabled:  config.DBLoggingEnabled,
 		Logger:            config.Logger,
-		VerifySSL:         !config.SkipVerifySSL,
+		HTTPClients:       config.HTTPClients,
 		CCHost:            config.CCHost,
 		CORSOrigin:        config.CORSOrigin,
 		SQLDB:             config.SQLDB,
+		SQLReplicas:       config.SQLReplicas,
+		QueueRedisURL:     config.QueueRedisURL,
+		ReadOnly:          config.ReadOnly,
+
+		SyncUserDeliveryTimeout:      config.SyncUserDeliveryTimeout,
+		ClientRateLimit:              config.ClientRateLimit,
+		ClientRateLimitBurst:         config.ClientRateLimitBurst,
+		CriticalKindApproval:         config.CriticalKindApproval,
+		CriticalUnsubscribeGraceDays: config.CriticalUnsubscribeGraceDays,
+		IdempotencyWindowHours:       config.IdempotencyWindowHours,
+		EnqueueBatchSize:             config.EnqueueBatchSize,
+		EnqueueWorkers:               config.EnqueueWorkers,
+		SendingAnomalyFactor:         config.SendingAnomalyFactor,
+		SendingAnomalyMinRequests:    config.SendingAnomalyMinRequests,
+		SendingAnomalyReauthorize:    config.SendingAnomalyReauthorize,
+		Sender:                       config.Sender,
+		Domain:                       config.Domain,
+		EncryptionKey:                config.EncryptionKey,
+		PreviousEncryptionKeys:       config.PreviousEncryptionKeys,
+		PreferenceChangeRevertURL:    config.PreferenceChangeRevertURL,
+		UnsubscribeURL:               config.UnsubscribeURL,
+		TrackingURL:                  config.TrackingURL,
+		HTMLSanitizerMode:            config.HTMLSanitizerMode,
+		MailTransport:                config.MailTransport,
+		HTMLSizeLimit:                config.HTMLSizeLimit,
+		HTMLAllowedElements:          config.HTMLAllowedElements,
+		PreferencesCache:             config.PreferencesCache,
+		FastLane:                     config.FastLane,
+		WorkerPool:                   config.WorkerPool,
+		QueueSLA:                     config.QueueSLA,
+		NotifyAuthorizers:            config.NotifyAuthorizers,
 	})
 
 	return VersionRouter{
@@ -3,23 +3,74 @@ package web
 import (
 	"database/sql"
 	"net/http"
+	"time"
 
 	"fmt"
 
 	"github.com/cloudfoundry-incubator/notifications/gobble"
+	"github.com/cloudfoundry-incubator/notifications/httpclient"
+	"github.com/cloudfoundry-incubator/notifications/postal"
+	"github.com/cloudfoundry-incubator/notifications/sanitize"
+	"github.com/cloudfoundry-incubator/notifications/tracing"
 	"github.com/cloudfoundry-incubator/notifications/uaa"
+	"github.com/cloudfoundry-incubator/notifications/v1/web/notify"
 	"github.com/pivotal-golang/lager"
 )
 
+type fastLane interface {
+	Submit(job *gobble.Job) bool
+}
+
+type workerPool interface {
+	Status() postal.WorkerPoolStatus
+}
+
+type preferencesCache interface {
+	Get(key string) (string, bool, error)
+	Set(key, value string, ttl time.Duration) error
+	Del(key string) error
+}
+
 type Config struct {
 	DBLoggingEnabled     bool
-	SkipVerifySSL        bool
+	HTTPClients          httpclient.Shared
 	Port                 int
 	CORSOrigin           string
 	QueueWaitMaxDuration int
+	QueueRedisURL        string
 	SQLDB                *sql.DB
+	SQLReplicas          []*sql.DB
 	Queue                gobble.QueueInterface
 	Logger               lager.Logger
+	ReadOnly             bool
+
+	SyncUserDeliveryTimeout      int
+	ClientRateLimit              int
+	ClientRateLimitBurst         int
+	CriticalKindApproval         bool
+	CriticalUnsubscribeGraceDays int
+	IdempotencyWindowHours       int
+	EnqueueBatchSize             int
+	EnqueueWorkers               int
+	SendingAnomalyFactor         int
+	SendingAnomalyMinRequests    int
+	SendingAnomalyReauthorize    bool
+	Sender                       string
+	Domain                       string
+	EncryptionKey                []byte
+	PreviousEncryptionKeys       [][]byte
+	PreferenceChangeRevertURL    string
+	UnsubscribeURL               string
+	TrackingURL                  string
+	HTMLSanitizerMode            string
+	MailTransport                string
+	HTMLSizeLimit                int
+	HTMLAllowedElements          sanitize.Policy
+	PreferencesCache             preferencesCache
+	FastLane                     fastLane
+	WorkerPool                   workerPool
+	QueueSLA                     map[int]time.Duration
+	NotifyAuthorizers            []notify.Authorizer
 
 	UAATokenValidator *uaa.TokenValidator
 	UAAHost           string
@@ -40,5 +91,10 @@ func (s Server) Run(config Config) {
 		"port": config.Port,
 	})
 
-	http.ListenAndServe(fmt.Sprintf(":%d", config.Port), NewRouter(config))
+	// These routes are reached without a UAA token; the token in the path is
+	// the credential.
+	handler := tracing.NewHandler(NewRouter(config)).
+		WithRedactedPaths("/unsubscribe/", "/user_preferences/revert/", "/t/")
+
+	http.ListenAndServe(fmt.Sprintf(":%d", config.Port), handler)
 }